@@ -0,0 +1,27 @@
+#!/bin/sh
+# Signs a binary built by GoReleaser for windows with Authenticode, in place.
+# Usage: sign-windows.sh <os> <binary>
+# Does nothing for other operating systems or unless WINDOWS_SIGN_PFX is set.
+set -eu
+
+os="$1"
+binary="$2"
+
+if [ "$os" != "windows" ] || [ -z "${WINDOWS_SIGN_PFX:-}" ]; then
+  exit 0
+fi
+
+pfx=$(mktemp)
+trap 'rm -f "$pfx" "$binary.signed"' EXIT
+printf '%s' "$WINDOWS_SIGN_PFX" | base64 -d > "$pfx"
+
+osslsigncode sign \
+  -pkcs12 "$pfx" \
+  -pass "${WINDOWS_SIGN_PASSWORD:-}" \
+  -n mcpchecker \
+  -i https://github.com/mcpchecker/mcpchecker \
+  -h sha256 \
+  -ts http://timestamp.digicert.com \
+  -in "$binary" \
+  -out "$binary.signed"
+mv "$binary.signed" "$binary"
@@ -127,6 +127,12 @@ func (b *AgentRefBuilder) Model(m string) *AgentRefBuilder {
 	return b
 }
 
+// ExtraArgs sets the extra arguments passed to the agent
+func (b *AgentRefBuilder) ExtraArgs(args ...string) *AgentRefBuilder {
+	b.ref.ExtraArgs = args
+	return b
+}
+
 // LLMJudgeConfigBuilder builds LLM judge configuration.
 // The LLM judge config uses environment variable keys, not direct values.
 // Use the Env* methods to set the environment variable key names.
@@ -193,16 +199,17 @@ func (b *TaskSetBuilder) Glob(pattern string) *TaskSetBuilder {
 
 // LabelSelector sets the label selector for filtering tasks
 func (b *TaskSetBuilder) LabelSelector(labels map[string]string) *TaskSetBuilder {
-	b.set.LabelSelector = labels
+	b.set.LabelSelector = eval.LabelSelectorFromMap(labels)
 	return b
 }
 
 // AddLabelSelector adds a single label to the label selector
 func (b *TaskSetBuilder) AddLabelSelector(key, value string) *TaskSetBuilder {
-	if b.set.LabelSelector == nil {
-		b.set.LabelSelector = make(map[string]string)
-	}
-	b.set.LabelSelector[key] = value
+	b.set.LabelSelector = append(b.set.LabelSelector, eval.LabelRequirement{
+		Key:      key,
+		Operator: eval.LabelOpIn,
+		Values:   []string{value},
+	})
 	return b
 }
 
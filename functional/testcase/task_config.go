@@ -359,11 +359,15 @@ func (tc *TaskConfigV2) PromptStep() *util.Step {
 
 // Build returns the task spec
 func (tc *TaskConfigV2) Build() *task.TaskSpec {
+	var prompt *task.Prompt
+	if tc.prompt != nil {
+		prompt = &task.Prompt{Step: *tc.prompt}
+	}
 	return &task.TaskSpec{
 		Setup:   tc.setup,
 		Cleanup: tc.cleanup,
 		Verify:  tc.verify,
-		Prompt:  tc.prompt,
+		Prompt:  prompt,
 	}
 }
 
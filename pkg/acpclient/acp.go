@@ -96,6 +96,7 @@ func (c *client) SessionUpdate(ctx context.Context, params acp.SessionNotificati
 	}
 
 	session.update(params.Update)
+	c.activity.Touch()
 
 	return nil
 }
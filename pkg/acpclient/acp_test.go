@@ -2,6 +2,8 @@ package acpclient
 
 import (
 	"context"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/coder/acp-go-sdk"
@@ -187,3 +189,25 @@ func newTestSession(allowedTools []*mcp.Tool) *session {
 	}
 	return NewSession(mgr, "")
 }
+
+type countingTransport struct {
+	closes int
+}
+
+func (t *countingTransport) Start(ctx context.Context) (io.Writer, io.Reader, error) {
+	return io.Discard, strings.NewReader(""), nil
+}
+
+func (t *countingTransport) Close(ctx context.Context) error {
+	t.closes++
+	return nil
+}
+
+func TestClient_CloseTransportOnce(t *testing.T) {
+	transport := &countingTransport{}
+	c := NewClient(context.Background(), &AcpConfig{Transport: transport})
+
+	require.NoError(t, c.Close(context.Background()))
+	require.NoError(t, c.Close(context.Background()))
+	assert.Equal(t, 1, transport.closes)
+}
@@ -2,12 +2,14 @@ package acpclient
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"slices"
 	"sync"
+	"time"
 
 	"github.com/coder/acp-go-sdk"
 	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
@@ -30,10 +32,20 @@ type Client interface {
 	Run(ctx context.Context, prompt string, servers mcpproxy.ServerManager) ([]acp.SessionUpdate, error)
 	// RunWithUsage is like Run but also returns usage data if the agent reports it.
 	RunWithUsage(ctx context.Context, prompt string, servers mcpproxy.ServerManager) (*RunResult, error)
+	// RunConversation is like RunWithUsage, but after each turn it prompts the
+	// same session with the next prompt returned by next, until next is done
+	RunConversation(ctx context.Context, prompt string, next NextPrompt, servers mcpproxy.ServerManager) (*RunResult, error)
 	// Close closes the client
 	Close(ctx context.Context) error
+	// ResourceUsage returns the resource usage of the agent subprocess after Close,
+	// or nil if the agent does not run as a subprocess or has not been closed
+	ResourceUsage() *util.ResourceUsage
 }
 
+// NextPrompt returns the prompt of the next turn of a conversation, given the
+// updates of the session so far, or false once the conversation is over.
+type NextPrompt func(ctx context.Context, updates []acp.SessionUpdate) (prompt string, ok bool, err error)
+
 func NewClient(ctx context.Context, cfg *AcpConfig, opts ...ClientOption) Client {
 	var o clientOptions
 	for _, opt := range opts {
@@ -42,6 +54,7 @@ func NewClient(ctx context.Context, cfg *AcpConfig, opts ...ClientOption) Client
 	return &client{
 		cfg:      cfg,
 		skills:   o.skills,
+		activity: o.activity,
 		sessions: make(map[acp.SessionId]*session),
 	}
 }
@@ -51,6 +64,11 @@ type client struct {
 	skills   SkillInfo
 	mu       sync.RWMutex
 	cmd      *exec.Cmd
+	started  time.Time
+	activity *util.Activity // records session updates as progress of the agent
+	untrack  func()
+	usage    *util.ResourceUsage
+	closed   bool
 	conn     *acp.ClientSideConnection
 	sessions map[acp.SessionId]*session
 }
@@ -85,24 +103,27 @@ func (c *client) Start(ctx context.Context) error {
 }
 
 func (c *client) Run(ctx context.Context, prompt string, servers mcpproxy.ServerManager) ([]acp.SessionUpdate, error) {
-	updates, _, err := c.run(ctx, prompt, servers)
+	updates, _, err := c.run(ctx, prompt, nil, servers)
 	return updates, err
 }
 
 func (c *client) RunWithUsage(ctx context.Context, prompt string, servers mcpproxy.ServerManager) (*RunResult, error) {
-	updates, promptResp, err := c.run(ctx, prompt, servers)
+	return c.RunConversation(ctx, prompt, nil, servers)
+}
+
+func (c *client) RunConversation(ctx context.Context, prompt string, next NextPrompt, servers mcpproxy.ServerManager) (*RunResult, error) {
+	updates, usage, err := c.run(ctx, prompt, next, servers)
 	if err != nil {
 		return nil, err
 	}
 
 	result := &RunResult{
 		Updates: updates,
+		Usage:   usage,
 	}
 
-	// Prefer usage from the PromptResponse Meta, as it contains the final
-	// authoritative token counts reported after the full agent loop completes.
-	// Fall back to scanning session update Meta fields.
-	result.Usage = ExtractUsageFromPromptResponse(promptResp)
+	// Fall back to scanning session update Meta fields if no turn reported
+	// usage in its PromptResponse.
 	if result.Usage == nil {
 		result.Usage = ExtractUsageFromMeta(updates)
 	}
@@ -110,24 +131,33 @@ func (c *client) RunWithUsage(ctx context.Context, prompt string, servers mcppro
 	return result, nil
 }
 
-func (c *client) run(ctx context.Context, prompt string, servers mcpproxy.ServerManager) ([]acp.SessionUpdate, acp.PromptResponse, error) {
+// run runs prompt in a new session, followed by the prompts of next, if any.
+// It returns the updates of the session and the sum of the usage reported in
+// the PromptResponse of each turn, which contains the final authoritative
+// token counts of the turn, or nil if no turn reported usage.
+func (c *client) run(ctx context.Context, prompt string, next NextPrompt, servers mcpproxy.ServerManager) ([]acp.SessionUpdate, *tokens.Usage, error) {
 	if c.conn == nil {
-		return nil, acp.PromptResponse{}, fmt.Errorf("acpclient.Client.Run must be called after acpclient.Client.Start")
+		return nil, nil, fmt.Errorf("acpclient.Client.Run must be called after acpclient.Client.Start")
 	}
 
-	tmpDir, err := os.MkdirTemp("", "mcpchecker-agent-")
-	if err != nil {
-		return nil, acp.PromptResponse{}, fmt.Errorf("failed to create temporary directory for agent execution: %w", err)
-	}
+	// In debug mode the working directory is kept in the debug directory
+	tmpDir := util.DebugDirFromContext(ctx).Sub("workdir").Path()
+	if tmpDir == "" {
+		var err error
+		tmpDir, err = os.MkdirTemp("", "mcpchecker-agent-")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create temporary directory for agent execution: %w", err)
+		}
 
-	defer func() {
-		_ = os.RemoveAll(tmpDir)
-	}()
+		defer func() {
+			_ = os.RemoveAll(tmpDir)
+		}()
+	}
 
 	// Mount skills into the temp directory if configured
 	if c.skills != nil {
 		if err := util.MountSkills(tmpDir, c.skills.GetMountPath(), c.skills.GetSourceDirs()); err != nil {
-			return nil, acp.PromptResponse{}, fmt.Errorf("failed to mount skills: %w", err)
+			return nil, nil, fmt.Errorf("failed to mount skills: %w", err)
 		}
 	}
 
@@ -137,7 +167,7 @@ func (c *client) run(ctx context.Context, prompt string, servers mcpproxy.Server
 		for _, srv := range servers.GetMcpServers() {
 			cfg, err := srv.GetConfig()
 			if err != nil {
-				return nil, acp.PromptResponse{}, fmt.Errorf("failed to get config for mcp server %q: %w", srv.GetName(), err)
+				return nil, nil, fmt.Errorf("failed to get config for mcp server %q: %w", srv.GetName(), err)
 			}
 
 			headers := make([]acp.HttpHeader, 0, len(cfg.Headers))
@@ -161,7 +191,7 @@ func (c *client) run(ctx context.Context, prompt string, servers mcpproxy.Server
 		McpServers: mcpServers,
 	})
 	if err != nil {
-		return nil, acp.PromptResponse{}, fmt.Errorf("failed to start new ACP session: %w", err)
+		return nil, nil, fmt.Errorf("failed to start new ACP session: %w", err)
 	}
 
 	// store the session
@@ -169,33 +199,79 @@ func (c *client) run(ctx context.Context, prompt string, servers mcpproxy.Server
 	c.sessions[session.SessionId] = NewSession(servers, tmpDir)
 	c.mu.Unlock()
 
-	// this runs the current prompt to completion
-	// if we were to support multi turn flows, we could run further prompts to the same session from here
-	promptResp, err := c.conn.Prompt(ctx, acp.PromptRequest{
-		SessionId: session.SessionId,
-		Prompt:    []acp.ContentBlock{acp.TextBlock(prompt)},
-	})
-	if err != nil {
-		return nil, acp.PromptResponse{}, fmt.Errorf("failed to send prompt to acp session: %w", err)
+	defer func() {
+		c.mu.Lock()
+		delete(c.sessions, session.SessionId)
+		c.mu.Unlock()
+	}()
+
+	// run each prompt to completion, then ask next for the prompt of the next turn
+	var usage *tokens.Usage
+	for {
+		promptResp, err := c.conn.Prompt(ctx, acp.PromptRequest{
+			SessionId: session.SessionId,
+			Prompt:    []acp.ContentBlock{acp.TextBlock(prompt)},
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to send prompt to acp session: %w", err)
+		}
+		if turnUsage := ExtractUsageFromPromptResponse(promptResp); turnUsage != nil {
+			if usage == nil {
+				usage = &tokens.Usage{}
+			}
+			usage.Add(turnUsage)
+		}
+
+		if next == nil {
+			break
+		}
+
+		var ok bool
+		prompt, ok, err = next(ctx, c.sessionUpdates(session.SessionId))
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			break
+		}
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	// return all the updates from this session
+	return c.sessionUpdates(session.SessionId), usage, nil
+}
 
-	// return all the updates from this session, remove it from storage it
-	res := slices.Clone(c.sessions[session.SessionId].updates)
-	delete(c.sessions, session.SessionId)
+// sessionUpdates returns a copy of the updates of a session so far.
+func (c *client) sessionUpdates(id acp.SessionId) []acp.SessionUpdate {
+	c.mu.RLock()
+	s := c.sessions[id]
+	c.mu.RUnlock()
 
-	return res, promptResp, nil
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.updates)
 }
 
+// Close stops the agent. Only the first call closes the transport or the
+// subprocess, later calls return nil.
 func (c *client) Close(ctx context.Context) error {
+	c.mu.Lock()
+	closed := c.closed
+	c.closed = true
+	c.mu.Unlock()
+	if closed {
+		return nil
+	}
+
 	if c.cfg.Transport != nil {
 		return c.cfg.Transport.Close(ctx)
 	}
 	return c.closeSubprocess(ctx)
 }
 
+func (c *client) ResourceUsage() *util.ResourceUsage {
+	return c.usage
+}
+
 func (c *client) startPipes(ctx context.Context) (stdin io.Writer, stdout io.Reader, err error) {
 	if c.cfg.Transport != nil {
 		return c.startTransport(ctx)
@@ -227,16 +303,19 @@ func (c *client) startSubprocess(ctx context.Context) (io.Writer, io.Reader, err
 	if err := c.cmd.Start(); err != nil {
 		return nil, nil, fmt.Errorf("failed to start acp client: %w", err)
 	}
+	c.started = time.Now()
+	c.untrack = c.activity.TrackProcess(c.cmd.Process.Pid)
 
 	return stdin, stdout, nil
 }
 
 func (c *client) closeSubprocess(ctx context.Context) error {
-	if c.cmd == nil || c.cmd.Process == nil || (c.cmd.ProcessState != nil && c.cmd.ProcessState.Exited()) {
+	if c.cmd == nil || c.cmd.Process == nil || c.cmd.ProcessState != nil {
 		return nil
 	}
+	c.untrack()
 
-	if err := c.cmd.Process.Kill(); err != nil {
+	if err := c.cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
 		return fmt.Errorf("failed to kill acp client process: %w", err)
 	}
 
@@ -249,6 +328,7 @@ func (c *client) closeSubprocess(ctx context.Context) error {
 	select {
 	case <-done:
 		// Process exited, pipes are closed, receive goroutine will exit
+		c.usage = util.NewResourceUsage(c.cmd.ProcessState, time.Since(c.started))
 		return nil
 	case <-ctx.Done():
 		// Context cancelled while waiting - process is already killed,
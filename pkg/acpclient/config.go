@@ -3,6 +3,8 @@ package acpclient
 import (
 	"context"
 	"io"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 // Transport provides the I/O streams for ACP communication.
@@ -37,7 +39,8 @@ type SkillInfo interface {
 type ClientOption func(*clientOptions)
 
 type clientOptions struct {
-	skills SkillInfo
+	skills   SkillInfo
+	activity *util.Activity
 }
 
 // WithSkills configures skill mounting for the ACP client.
@@ -46,3 +49,11 @@ func WithSkills(skills SkillInfo) ClientOption {
 		o.skills = skills
 	}
 }
+
+// WithActivity records the session updates of the agent as its progress in
+// activity, and tracks the agent process while it runs.
+func WithActivity(activity *util.Activity) ClientOption {
+	return func(o *clientOptions) {
+		o.activity = activity
+	}
+}
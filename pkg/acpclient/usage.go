@@ -91,6 +91,13 @@ func parseUsageFromMeta(meta any) *tokens.Usage {
 		usage.CachedWriteTokens = &v
 	}
 
+	// Retries made by the agent are reported next to the usage rather than inside it
+	if v, ok := m["retries"]; ok {
+		if n, ok := toInt64(v); ok {
+			usage.Retries = n
+		}
+	}
+
 	// Only return if we found meaningful data
 	if usage.TotalTokens > 0 || usage.InputTokens > 0 || usage.OutputTokens > 0 {
 		return usage
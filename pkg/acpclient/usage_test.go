@@ -240,6 +240,7 @@ func TestExtractUsageFromPromptResponse(t *testing.T) {
 		expectedInput  int64
 		expectedOutput int64
 		expectedTotal  int64
+		expectRetries  int64
 	}{
 		"nil meta": {
 			resp:      acp.PromptResponse{Meta: nil},
@@ -259,6 +260,22 @@ func TestExtractUsageFromPromptResponse(t *testing.T) {
 			expectedOutput: 200,
 			expectedTotal:  700,
 		},
+		"with retries": {
+			resp: acp.PromptResponse{
+				Meta: map[string]any{
+					"usage": map[string]any{
+						"input_tokens":  float64(500),
+						"output_tokens": float64(200),
+						"total_tokens":  float64(700),
+					},
+					"retries": float64(2),
+				},
+			},
+			expectedInput:  500,
+			expectedOutput: 200,
+			expectedTotal:  700,
+			expectRetries:  2,
+		},
 	}
 
 	for name, tc := range tt {
@@ -274,6 +291,7 @@ func TestExtractUsageFromPromptResponse(t *testing.T) {
 			assert.Equal(t, tc.expectedInput, result.InputTokens)
 			assert.Equal(t, tc.expectedOutput, result.OutputTokens)
 			assert.Equal(t, tc.expectedTotal, result.TotalTokens)
+			assert.Equal(t, tc.expectRetries, result.Retries)
 		})
 	}
 }
@@ -1,18 +1,29 @@
 package agent
 
 import (
+	"context"
+	"strings"
+
 	"github.com/coder/acp-go-sdk"
+	"github.com/mcpchecker/mcpchecker/pkg/acpclient"
 	"github.com/mcpchecker/mcpchecker/pkg/tokens"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 // acpResult is a shared AgentResult implementation for ACP-based runners.
 type acpResult struct {
 	updates     []acp.SessionUpdate
-	prompt      string
+	prompt      string // prompts of all turns, for multi-turn tasks
 	actualUsage *tokens.Usage
+
+	// resourceUsage is only set for agents that run as a subprocess
+	resourceUsage *util.ResourceUsage
 }
 
-var _ AgentResult = &acpResult{}
+var (
+	_ AgentResult           = &acpResult{}
+	_ ResourceUsageReporter = &acpResult{}
+)
 
 func (res *acpResult) GetOutput() []OutputStep {
 	return ExtractOutputSteps(res.updates)
@@ -34,6 +45,10 @@ func (res *acpResult) GetRawUpdates() any {
 	return res.updates
 }
 
+func (res *acpResult) GetResourceUsage() *util.ResourceUsage {
+	return res.resourceUsage
+}
+
 func (res *acpResult) GetTokenEstimate() tokens.Estimate {
 	estimate := tokens.ComputeEstimate(
 		res.prompt,
@@ -54,3 +69,23 @@ func (res *acpResult) GetTokenEstimate() tokens.Estimate {
 
 	return estimate
 }
+
+// conversationPrompts adapts next to the ACP client, appending the prompt of
+// each turn to prompts. It returns nil if next is nil.
+func conversationPrompts(next NextTurn, prompts *[]string) acpclient.NextPrompt {
+	if next == nil {
+		return nil
+	}
+	return func(ctx context.Context, updates []acp.SessionUpdate) (string, bool, error) {
+		prompt, ok, err := next(ctx, &acpResult{updates: updates, prompt: joinPrompts(*prompts)})
+		if ok {
+			*prompts = append(*prompts, prompt)
+		}
+		return prompt, ok, err
+	}
+}
+
+// joinPrompts returns the prompts of the turns of a conversation as one text.
+func joinPrompts(prompts []string) string {
+	return strings.Join(prompts, "\n\n")
+}
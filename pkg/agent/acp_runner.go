@@ -3,9 +3,11 @@ package agent
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/mcpchecker/mcpchecker/pkg/acpclient"
 	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 type acpRunner struct {
@@ -13,9 +15,13 @@ type acpRunner struct {
 	cfg        *acpclient.AcpConfig
 	mcpServers mcpproxy.ServerManager
 	skills     *SkillInfo
+	activity   *util.Activity
 }
 
-var _ Runner = &acpRunner{}
+var (
+	_ Runner             = &acpRunner{}
+	_ ConversationRunner = &acpRunner{}
+)
 
 func NewAcpRunner(cfg *acpclient.AcpConfig, name string) Runner {
 	return &acpRunner{
@@ -25,7 +31,16 @@ func NewAcpRunner(cfg *acpclient.AcpConfig, name string) Runner {
 }
 
 func (r *acpRunner) RunTask(ctx context.Context, prompt string) (AgentResult, error) {
-	client := acpclient.NewClient(ctx, r.cfg, r.skills.ClientOptions()...)
+	return r.RunConversation(ctx, prompt, nil)
+}
+
+func (r *acpRunner) RunConversation(ctx context.Context, prompt string, next NextTurn) (AgentResult, error) {
+	debug := util.DebugDirFromContext(ctx)
+	if r.cfg != nil && r.cfg.Cmd != "" {
+		debug.WriteFile("command.txt", []byte(strings.Join(append([]string{r.cfg.Cmd}, r.cfg.Args...), " ")+"\n"))
+	}
+
+	client := acpclient.NewClient(ctx, r.cfg, append(r.skills.ClientOptions(), acpclient.WithActivity(r.activity))...)
 	defer client.Close(ctx)
 
 	err := client.Start(ctx)
@@ -33,15 +48,21 @@ func (r *acpRunner) RunTask(ctx context.Context, prompt string) (AgentResult, er
 		return nil, fmt.Errorf("failed to start acp client: %w", err)
 	}
 
-	result, err := client.RunWithUsage(ctx, prompt, r.mcpServers)
+	prompts := []string{prompt}
+	result, err := client.RunConversation(ctx, prompt, conversationPrompts(next, &prompts), r.mcpServers)
 	if err != nil {
 		return nil, fmt.Errorf("failed to run acp agent: %w", err)
 	}
+	debug.WriteJSON("updates.json", result.Updates)
+
+	// Stop the agent now so that its resource usage is known
+	_ = client.Close(ctx)
 
 	return &acpResult{
-		updates:     result.Updates,
-		prompt:      prompt,
-		actualUsage: result.Usage,
+		updates:       result.Updates,
+		prompt:        joinPrompts(prompts),
+		actualUsage:   result.Usage,
+		resourceUsage: client.ResourceUsage(),
 	}, nil
 }
 
@@ -51,6 +72,7 @@ func (r *acpRunner) WithMcpServerInfo(mcpServers mcpproxy.ServerManager) Runner
 		cfg:        r.cfg,
 		mcpServers: mcpServers,
 		skills:     r.skills,
+		activity:   r.activity,
 	}
 }
 
@@ -60,6 +82,17 @@ func (r *acpRunner) WithSkillInfo(skills *SkillInfo) Runner {
 		cfg:        r.cfg,
 		mcpServers: r.mcpServers,
 		skills:     skills,
+		activity:   r.activity,
+	}
+}
+
+func (r *acpRunner) WithActivity(activity *util.Activity) Runner {
+	return &acpRunner{
+		name:       r.name,
+		cfg:        r.cfg,
+		mcpServers: r.mcpServers,
+		skills:     r.skills,
+		activity:   activity,
 	}
 }
 
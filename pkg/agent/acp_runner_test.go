@@ -159,6 +159,7 @@ func (m *mockServer) GetAllowedTools(_ context.Context) []*mcp.Tool { return m.a
 func (m *mockServer) GetInstructions() string                       { return "" }
 func (m *mockServer) Close() error                                  { return nil }
 func (m *mockServer) GetCallHistory() mcpproxy.CallHistory          { return mcpproxy.CallHistory{} }
+func (m *mockServer) ResetCallHistory() mcpproxy.CallHistory        { return mcpproxy.CallHistory{} }
 func (m *mockServer) WaitReady(_ context.Context) error             { return nil }
 
 // mockServerManager implements mcpproxy.ServerManager for testing
@@ -171,6 +172,7 @@ func (m *mockServerManager) GetMcpServers() []mcpproxy.Server         { return m
 func (m *mockServerManager) Start(_ context.Context) error            { return nil }
 func (m *mockServerManager) Close() error                             { return nil }
 func (m *mockServerManager) GetAllCallHistory() *mcpproxy.CallHistory { return nil }
+func (m *mockServerManager) ResetCallHistory() *mcpproxy.CallHistory  { return nil }
 func (m *mockServerManager) GetCallHistoryForServer(_ string) (mcpproxy.CallHistory, bool) {
 	return mcpproxy.CallHistory{}, false
 }
@@ -1,10 +1,11 @@
 package agent
 
 var builtinTypes = map[string]BuiltinAgent{
-	"llm-agent":    &LLMAgent{},
-	"openai-agent": &LLMAgent{}, // deprecated alias
-	"openai-acp":   &LLMAgent{}, // deprecated alias
-	"claude-code":  &ClaudeCodeAgent{},
+	"llm-agent":       &LLMAgent{},
+	"openai-agent":    &LLMAgent{}, // deprecated alias
+	"openai-acp":      &LLMAgent{}, // deprecated alias
+	"claude-code":     &ClaudeCodeAgent{},
+	"claude-code-acp": &ClaudeCodeAcpAgent{},
 }
 
 // GetBuiltinType retrieves a builtin agent by name
@@ -34,6 +34,11 @@ func TestGetBuiltinType(t *testing.T) {
 			shouldExist:  true,
 			expectedName: "claude-code",
 		},
+		"claude-code-acp exists": {
+			agentType:    "claude-code-acp",
+			shouldExist:  true,
+			expectedName: "claude-code-acp",
+		},
 		"non-existent agent": {
 			agentType:   "non-existent",
 			shouldExist: false,
@@ -63,8 +68,9 @@ func TestListBuiltinTypes(t *testing.T) {
 
 	// Check that expected agents are present
 	expectedAgents := map[string]bool{
-		"llm-agent":   false,
-		"claude-code": false,
+		"llm-agent":       false,
+		"claude-code":     false,
+		"claude-code-acp": false,
 	}
 
 	for _, agent := range agents {
@@ -142,16 +148,45 @@ func TestClaudeCodeAgent(t *testing.T) {
 		require.NotNil(t, spec)
 
 		assert.Equal(t, "claude-code", spec.Metadata.Name)
-		require.NotNil(t, spec.AcpConfig)
-		assert.Equal(t, &acpclient.AcpConfig{Cmd: "claude-agent-acp"}, spec.AcpConfig)
+		assert.Nil(t, spec.AcpConfig)
+		assert.Equal(t, &BuiltinRef{Type: "claude-code"}, spec.Builtin)
+		assert.Empty(t, spec.Model)
+		assert.Equal(t, &AgentSkillsConfig{MountPath: ".claude/skills", ToolName: "Skill"}, spec.Skills)
 	})
 
-	t.Run("GetDefaults with model (ignored)", func(t *testing.T) {
-		spec, err := agent.GetDefaults("some-model")
+	t.Run("GetDefaults with model", func(t *testing.T) {
+		spec, err := agent.GetDefaults("opus")
 		require.NoError(t, err)
 		require.NotNil(t, spec)
 
 		assert.Equal(t, "claude-code", spec.Metadata.Name)
-		require.NotNil(t, spec.AcpConfig)
+		assert.Equal(t, "opus", spec.Model)
 	})
+
+	t.Run("runs the claude cli", func(t *testing.T) {
+		spec, err := agent.GetDefaults("")
+		require.NoError(t, err)
+
+		runner, err := NewRunnerForSpec(spec)
+		require.NoError(t, err)
+		assert.IsType(t, &claudeCodeRunner{}, runner)
+		assert.Equal(t, "claude-code", runner.AgentName())
+	})
+}
+
+func TestClaudeCodeAcpAgent(t *testing.T) {
+	agent := &ClaudeCodeAcpAgent{}
+
+	assert.Equal(t, "claude-code-acp", agent.Name())
+	assert.False(t, agent.RequiresModel())
+
+	spec, err := agent.GetDefaults("")
+	require.NoError(t, err)
+	assert.Equal(t, "claude-code-acp", spec.Metadata.Name)
+	assert.Equal(t, &acpclient.AcpConfig{Cmd: "claude-agent-acp"}, spec.AcpConfig)
+	assert.Equal(t, &AgentSkillsConfig{MountPath: ".claude/skills", ToolName: "Skill"}, spec.Skills)
+
+	runner, err := NewRunnerForSpec(spec)
+	require.NoError(t, err)
+	assert.IsType(t, &acpRunner{}, runner)
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/mcpchecker/mcpchecker/pkg/acpclient"
 )
 
+// ClaudeCodeAgent runs the Claude Code CLI in print mode, see claudeCodeRunner.
 type ClaudeCodeAgent struct{}
 
 func (a *ClaudeCodeAgent) Name() string {
@@ -22,8 +23,8 @@ func (a *ClaudeCodeAgent) RequiresModel() bool {
 }
 
 func (a *ClaudeCodeAgent) ValidateEnvironment() error {
-	if _, err := exec.LookPath("claude-agent-acp"); err != nil {
-		return fmt.Errorf("'claude-agent-acp' binary not found in PATH (install with: npm install -g @agentclientprotocol/claude-agent-acp): %w", err)
+	if _, err := exec.LookPath(claudeCodeCmd); err != nil {
+		return fmt.Errorf("'%s' binary not found in PATH (install with: npm install -g @anthropic-ai/claude-code): %w", claudeCodeCmd, err)
 	}
 	return nil
 }
@@ -33,6 +34,46 @@ func (a *ClaudeCodeAgent) GetDefaults(model string) (*AgentSpec, error) {
 		Metadata: AgentMetadata{
 			Name: "claude-code",
 		},
+		Builtin: &BuiltinRef{
+			Type: "claude-code",
+		},
+		// An empty model leaves it to Claude Code
+		Model: model,
+		Skills: &AgentSkillsConfig{
+			MountPath: ".claude/skills",
+			ToolName:  "Skill",
+		},
+	}, nil
+}
+
+// ClaudeCodeAcpAgent runs Claude Code through its ACP adapter.
+type ClaudeCodeAcpAgent struct{}
+
+func (a *ClaudeCodeAcpAgent) Name() string {
+	return "claude-code-acp"
+}
+
+func (a *ClaudeCodeAcpAgent) Description() string {
+	return "Anthropic's Claude Code through its ACP adapter"
+}
+
+func (a *ClaudeCodeAcpAgent) RequiresModel() bool {
+	return false // Claude Code manages its own model selection
+}
+
+func (a *ClaudeCodeAcpAgent) ValidateEnvironment() error {
+	if _, err := exec.LookPath("claude-agent-acp"); err != nil {
+		return fmt.Errorf("'claude-agent-acp' binary not found in PATH (install with: npm install -g @agentclientprotocol/claude-agent-acp): %w", err)
+	}
+	return nil
+}
+
+func (a *ClaudeCodeAcpAgent) GetDefaults(model string) (*AgentSpec, error) {
+	return &AgentSpec{
+		Metadata: AgentMetadata{
+			Name: "claude-code-acp",
+		},
+		Model: model,
 		AcpConfig: &acpclient.AcpConfig{
 			Cmd: "claude-agent-acp",
 		},
@@ -0,0 +1,398 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/coder/acp-go-sdk"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/tokens"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// claudeCodeCmd is the Claude Code CLI run by builtin.claude-code
+const claudeCodeCmd = "claude"
+
+// claudeCodeRunner runs Claude Code in print mode, with the MCP servers of the
+// task passed with --mcp-config and its tools allowed with --allowedTools. The
+// JSON events Claude Code streams are turned into ACP session updates, so that
+// its result is read like that of an ACP agent.
+type claudeCodeRunner struct {
+	spec     *AgentSpec
+	cmd      string
+	mcpInfo  McpServerInfo
+	skills   *SkillInfo
+	activity *util.Activity
+}
+
+var (
+	_ Runner             = &claudeCodeRunner{}
+	_ ConversationRunner = &claudeCodeRunner{}
+)
+
+func newClaudeCodeRunner(spec *AgentSpec) *claudeCodeRunner {
+	return &claudeCodeRunner{
+		spec: spec,
+		cmd:  claudeCodeCmd,
+	}
+}
+
+func (r *claudeCodeRunner) RunTask(ctx context.Context, prompt string) (AgentResult, error) {
+	return r.RunConversation(ctx, prompt, nil)
+}
+
+// RunConversation runs each turn of the conversation as a Claude Code process,
+// resuming the session of the first turn.
+func (r *claudeCodeRunner) RunConversation(ctx context.Context, prompt string, next NextTurn) (AgentResult, error) {
+	debug := util.DebugDirFromContext(ctx)
+
+	// The agent runs in an empty directory to isolate it from source code. In
+	// debug mode it is created in the debug directory, which is always kept.
+	workdir := debug.Sub("workdir").Path()
+	if workdir == "" {
+		var err error
+		workdir, err = os.MkdirTemp("", "mcpchecker-agent-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary directory for agent execution: %w", err)
+		}
+		defer os.RemoveAll(workdir)
+	}
+
+	if r.skills != nil {
+		if err := util.MountSkills(workdir, r.skills.MountPath, r.skills.SourceDirs); err != nil {
+			return nil, fmt.Errorf("failed to mount skills: %w", err)
+		}
+	}
+
+	args, err := r.args(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	env := r.spec.Env.Apply(os.Environ())
+	debug.WriteFile("env.txt", []byte(strings.Join(redactEnv(env), "\n")+"\n"))
+
+	result := &acpResult{}
+	var prompts, commands []string
+	var output bytes.Buffer
+	var sessionID string
+	for {
+		turnArgs := args
+		if sessionID != "" {
+			turnArgs = append(slices.Clone(args), "--resume", sessionID)
+		}
+		prompts = append(prompts, prompt)
+		commands = append(commands, strings.Join(append([]string{r.cmd}, turnArgs...), " "))
+
+		sessionID, err = r.runTurn(ctx, workdir, turnArgs, env, prompt, &output, result)
+		debug.WriteFile("command.txt", []byte(strings.Join(commands, "\n")+"\n"))
+		debug.WriteFile("output.log", output.Bytes())
+		if err != nil {
+			return nil, err
+		}
+
+		if next == nil {
+			break
+		}
+		var ok bool
+		prompt, ok, err = next(ctx, &acpResult{updates: result.updates, prompt: joinPrompts(prompts)})
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		if sessionID == "" {
+			return nil, fmt.Errorf("failed to run claude code: no session id to resume the conversation")
+		}
+	}
+	debug.WriteJSON("updates.json", result.updates)
+
+	result.prompt = joinPrompts(prompts)
+	return result, nil
+}
+
+// args returns the arguments of the claude command, except the session to
+// resume. The prompt is passed on stdin.
+func (r *claudeCodeRunner) args(ctx context.Context) ([]string, error) {
+	args := []string{"--print", "--output-format", "stream-json", "--verbose"}
+
+	if r.mcpInfo != nil {
+		files, err := r.mcpInfo.GetMcpServerFiles()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the mcp server files: %w", err)
+		}
+		if len(files) > 0 {
+			args = append(args, "--mcp-config")
+			args = append(args, files...)
+		}
+		args = append(args, "--strict-mcp-config")
+	}
+
+	var allowedTools []string
+	if r.mcpInfo != nil {
+		for _, s := range r.mcpInfo.GetMcpServers() {
+			for _, t := range s.GetAllowedTools(ctx) {
+				allowedTools = append(allowedTools, fmt.Sprintf("mcp__%s__%s", s.GetName(), t.Name))
+			}
+		}
+	}
+	if r.skills != nil && r.spec.Skills != nil {
+		allowedTools = append(allowedTools, r.spec.Skills.ToolName)
+	}
+	if len(allowedTools) > 0 {
+		args = append(args, "--allowedTools", strings.Join(allowedTools, ","))
+	}
+
+	if r.spec.Model != "" {
+		args = append(args, "--model", r.spec.Model)
+	}
+	return append(args, r.spec.ExtraArgs...), nil
+}
+
+// runTurn runs a turn of the conversation, appending the output of claude to
+// output and the updates and usage it reported to result. It returns the id of
+// the session, to resume it in the next turn.
+func (r *claudeCodeRunner) runTurn(ctx context.Context, workdir string, args, env []string, prompt string, output *bytes.Buffer, result *acpResult) (string, error) {
+	cmd := exec.CommandContext(ctx, r.cmd, args...)
+	cmd.Dir = workdir
+	cmd.Env = env
+	cmd.Stdin = strings.NewReader(prompt)
+	// Processes the agent started may keep its output open after it is killed,
+	// for example when the task times out or the agent stalls
+	cmd.WaitDelay = agentWaitDelay
+
+	// Output counts as progress of the agent for stall detection
+	activity := r.activity
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = activity.Writer(&stdout)
+	cmd.Stderr = activity.Writer(&stderr)
+
+	start := time.Now()
+	runErr := activity.Run(cmd)
+	usage := util.NewResourceUsage(cmd.ProcessState, time.Since(start))
+	if result.resourceUsage == nil {
+		result.resourceUsage = usage
+	} else {
+		result.resourceUsage.Add(usage)
+	}
+	output.Write(stdout.Bytes())
+	output.Write(stderr.Bytes())
+
+	events, err := parseClaudeCodeEvents(&stdout)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the output of claude code: %w", err)
+	}
+	result.updates = append(result.updates, events.updates...)
+	if events.usage != nil {
+		if result.actualUsage == nil {
+			result.actualUsage = &tokens.Usage{}
+		}
+		result.actualUsage.Add(events.usage)
+	}
+
+	if runErr != nil {
+		return "", fmt.Errorf("failed to run claude code: %w\n\noutput: %s%s", runErr, stderr.String(), events.errorMessage())
+	}
+	if events.result == nil {
+		return "", fmt.Errorf("failed to run claude code: no result reported\n\noutput: %s", stderr.String())
+	}
+	if events.result.IsError {
+		return "", fmt.Errorf("claude code failed: %s%s", events.result.Subtype, events.errorMessage())
+	}
+
+	return events.result.SessionID, nil
+}
+
+func (r *claudeCodeRunner) WithMcpServerInfo(mcpServers mcpproxy.ServerManager) Runner {
+	return &claudeCodeRunner{
+		spec:     r.spec,
+		cmd:      r.cmd,
+		mcpInfo:  mcpServers,
+		skills:   r.skills,
+		activity: r.activity,
+	}
+}
+
+func (r *claudeCodeRunner) WithSkillInfo(skills *SkillInfo) Runner {
+	return &claudeCodeRunner{
+		spec:     r.spec,
+		cmd:      r.cmd,
+		mcpInfo:  r.mcpInfo,
+		skills:   skills,
+		activity: r.activity,
+	}
+}
+
+func (r *claudeCodeRunner) WithActivity(activity *util.Activity) Runner {
+	return &claudeCodeRunner{
+		spec:     r.spec,
+		cmd:      r.cmd,
+		mcpInfo:  r.mcpInfo,
+		skills:   r.skills,
+		activity: activity,
+	}
+}
+
+func (r *claudeCodeRunner) AgentName() string {
+	return r.spec.Metadata.Name
+}
+
+// claudeCodeEvent is a line of the stream-json output of claude --print
+type claudeCodeEvent struct {
+	// Type is "system", "assistant", "user" or "result"
+	Type      string `json:"type"`
+	Subtype   string `json:"subtype,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
+
+	// Message is set for assistant and user events
+	Message *struct {
+		// Content is a string for prompts, and content blocks otherwise
+		Content json.RawMessage `json:"content"`
+	} `json:"message,omitempty"`
+
+	// The fields of result events
+	IsError bool             `json:"is_error,omitempty"`
+	Result  string           `json:"result,omitempty"`
+	Usage   *claudeCodeUsage `json:"usage,omitempty"`
+}
+
+// claudeCodeContent is a content block of a message of a claudeCodeEvent
+type claudeCodeContent struct {
+	// Type is "text", "thinking", "tool_use" or "tool_result"
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Thinking string `json:"thinking,omitempty"`
+
+	// ID, Name and Input are set for tool_use blocks
+	ID    string `json:"id,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Input any    `json:"input,omitempty"`
+
+	// ToolUseID, Content and IsError are set for tool_result blocks
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   any    `json:"content,omitempty"`
+	IsError   bool   `json:"is_error,omitempty"`
+}
+
+// claudeCodeUsage is the token usage reported by the result of a turn. Input
+// tokens don't include the tokens read from or written to the prompt cache.
+type claudeCodeUsage struct {
+	InputTokens              int64 `json:"input_tokens"`
+	OutputTokens             int64 `json:"output_tokens"`
+	CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+}
+
+// claudeCodeEvents is the output of a turn of Claude Code
+type claudeCodeEvents struct {
+	updates []acp.SessionUpdate
+	usage   *tokens.Usage
+	result  *claudeCodeEvent
+}
+
+// errorMessage returns the result message of a failed turn to append to an
+// error, if any.
+func (e *claudeCodeEvents) errorMessage() string {
+	if e.result == nil || e.result.Result == "" {
+		return ""
+	}
+	return ": " + e.result.Result
+}
+
+// parseClaudeCodeEvents turns the stream-json output of claude --print into
+// session updates: text into agent message chunks, thinking into agent thought
+// chunks, tool uses into tool calls and tool results into updates of their
+// tool call. Lines that are not JSON objects are skipped.
+func parseClaudeCodeEvents(r io.Reader) (*claudeCodeEvents, error) {
+	events := &claudeCodeEvents{}
+
+	scanner := bufio.NewScanner(r)
+	// Tool results and messages may be much longer than the default limit
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+
+		var event claudeCodeEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return nil, fmt.Errorf("invalid event %q: %w", line, err)
+		}
+
+		switch event.Type {
+		case "assistant", "user":
+			if event.Message == nil {
+				continue
+			}
+			var content []claudeCodeContent
+			if err := json.Unmarshal(event.Message.Content, &content); err != nil {
+				// The prompt is echoed as a string
+				continue
+			}
+			for _, block := range content {
+				if update, ok := block.sessionUpdate(); ok {
+					events.updates = append(events.updates, update)
+				}
+			}
+		case "result":
+			events.result = &event
+			if event.Usage != nil {
+				events.usage = event.Usage.tokens()
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// sessionUpdate returns the session update of a content block, or false for
+// blocks without one.
+func (c *claudeCodeContent) sessionUpdate() (acp.SessionUpdate, bool) {
+	switch c.Type {
+	case "text":
+		return acp.UpdateAgentMessageText(c.Text), c.Text != ""
+	case "thinking":
+		return acp.UpdateAgentThoughtText(c.Thinking), c.Thinking != ""
+	case "tool_use":
+		return acp.StartToolCall(acp.ToolCallId(c.ID), c.Name,
+			acp.WithStartStatus(acp.ToolCallStatusPending),
+			acp.WithStartRawInput(c.Input),
+		), true
+	case "tool_result":
+		status := acp.ToolCallStatusCompleted
+		if c.IsError {
+			status = acp.ToolCallStatusFailed
+		}
+		return acp.UpdateToolCall(acp.ToolCallId(c.ToolUseID),
+			acp.WithUpdateStatus(status),
+			acp.WithUpdateRawOutput(c.Content),
+		), true
+	}
+	return acp.SessionUpdate{}, false
+}
+
+// tokens returns the usage with the cached tokens counted as input tokens.
+func (u *claudeCodeUsage) tokens() *tokens.Usage {
+	input := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+	return &tokens.Usage{
+		InputTokens:       input,
+		OutputTokens:      u.OutputTokens,
+		TotalTokens:       input + u.OutputTokens,
+		CachedReadTokens:  &u.CacheReadInputTokens,
+		CachedWriteTokens: &u.CacheCreationInputTokens,
+	}
+}
@@ -0,0 +1,270 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/tokens"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// claudeCodeEventsOutput is the stream-json output of a turn of claude
+// --print that calls a tool.
+const claudeCodeEventsOutput = `{"type":"system","subtype":"init","session_id":"session-1","tools":["mcp__kubernetes__pods_list"]}
+{"type":"user","message":{"role":"user","content":"List the pods"}}
+{"type":"assistant","message":{"content":[{"type":"thinking","thinking":"I should list the pods."},{"type":"tool_use","id":"toolu_1","name":"mcp__kubernetes__pods_list","input":{"namespace":"default"}}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"toolu_1","content":[{"type":"text","text":"nginx"}]}]}}
+{"type":"assistant","message":{"content":[{"type":"text","text":"The only pod is nginx."}]}}
+{"type":"result","subtype":"success","is_error":false,"result":"The only pod is nginx.","session_id":"session-1","usage":{"input_tokens":10,"cache_creation_input_tokens":5,"cache_read_input_tokens":20,"output_tokens":7}}
+`
+
+func TestParseClaudeCodeEvents(t *testing.T) {
+	cacheRead, cacheWrite := int64(20), int64(5)
+
+	tests := map[string]struct {
+		output      string
+		steps       []OutputStep
+		toolCalls   []ToolCallSummary
+		usage       *tokens.Usage
+		result      string
+		errContains string
+	}{
+		"tool call": {
+			output: claudeCodeEventsOutput,
+			steps: []OutputStep{
+				{Type: "thinking", Content: "I should list the pods."},
+				{Type: "tool_call", ToolCall: &ToolCallSummary{
+					Title:     "mcp__kubernetes__pods_list",
+					Status:    "completed",
+					RawInput:  map[string]any{"namespace": "default"},
+					RawOutput: []any{map[string]any{"type": "text", "text": "nginx"}},
+				}},
+				{Type: "message", Content: "The only pod is nginx."},
+			},
+			toolCalls: []ToolCallSummary{{
+				Title:     "mcp__kubernetes__pods_list",
+				Status:    "completed",
+				RawInput:  map[string]any{"namespace": "default"},
+				RawOutput: []any{map[string]any{"type": "text", "text": "nginx"}},
+			}},
+			usage: &tokens.Usage{
+				InputTokens:       35,
+				OutputTokens:      7,
+				TotalTokens:       42,
+				CachedReadTokens:  &cacheRead,
+				CachedWriteTokens: &cacheWrite,
+			},
+			result: "The only pod is nginx.",
+		},
+		"failed tool call": {
+			output: `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"toolu_1","name":"mcp__kubernetes__pods_delete","input":{}}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"permission denied","is_error":true}]}}
+`,
+			steps: []OutputStep{
+				{Type: "tool_call", ToolCall: &ToolCallSummary{
+					Title:     "mcp__kubernetes__pods_delete",
+					Status:    "failed",
+					RawInput:  map[string]any{},
+					RawOutput: "permission denied",
+				}},
+			},
+			toolCalls: []ToolCallSummary{{
+				Title:     "mcp__kubernetes__pods_delete",
+				Status:    "failed",
+				RawInput:  map[string]any{},
+				RawOutput: "permission denied",
+			}},
+		},
+		"lines that are not events are skipped": {
+			output: "warning: something\n\n" + `{"type":"assistant","message":{"content":[{"type":"text","text":"Done"}]}}` + "\n",
+			steps:  []OutputStep{{Type: "message", Content: "Done"}},
+		},
+		"invalid event": {
+			output:      `{"type":`,
+			errContains: "invalid event",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			events, err := parseClaudeCodeEvents(strings.NewReader(tc.output))
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+
+			result := &acpResult{updates: events.updates}
+			assert.Equal(t, tc.steps, result.GetOutput())
+			if tc.toolCalls != nil {
+				assert.Equal(t, tc.toolCalls, result.GetToolCalls())
+			}
+			assert.Equal(t, tc.usage, events.usage)
+			if tc.result != "" {
+				require.NotNil(t, events.result)
+				assert.Equal(t, tc.result, events.result.Result)
+			}
+		})
+	}
+}
+
+// claudeCodeServerManager is a mockServerManager with MCP server files
+type claudeCodeServerManager struct {
+	mockServerManager
+	files []string
+}
+
+func (m *claudeCodeServerManager) GetMcpServerFiles() ([]string, error) { return m.files, nil }
+
+func TestClaudeCodeRunnerArgs(t *testing.T) {
+	mcpServers := &claudeCodeServerManager{
+		mockServerManager: mockServerManager{servers: []mcpproxy.Server{
+			&mockServer{name: "kubernetes", allowedTools: []*mcp.Tool{{Name: "pods_list"}, {Name: "pods_get"}}},
+		}},
+		files: []string{"/tmp/mcp.json"},
+	}
+	skills := &AgentSkillsConfig{MountPath: ".claude/skills", ToolName: "Skill"}
+
+	tests := map[string]struct {
+		spec       *AgentSpec
+		mcpServers mcpproxy.ServerManager
+		skills     *SkillInfo
+		args       []string
+	}{
+		"without mcp servers": {
+			spec: &AgentSpec{},
+			args: []string{"--print", "--output-format", "stream-json", "--verbose"},
+		},
+		"mcp servers": {
+			spec:       &AgentSpec{},
+			mcpServers: mcpServers,
+			args: []string{"--print", "--output-format", "stream-json", "--verbose",
+				"--mcp-config", "/tmp/mcp.json", "--strict-mcp-config",
+				"--allowedTools", "mcp__kubernetes__pods_list,mcp__kubernetes__pods_get"},
+		},
+		"skills, model and extra args": {
+			spec:       &AgentSpec{Skills: skills, Model: "opus", ExtraArgs: []string{"--max-turns", "10"}},
+			mcpServers: mcpServers,
+			skills:     &SkillInfo{MountPath: ".claude/skills"},
+			args: []string{"--print", "--output-format", "stream-json", "--verbose",
+				"--mcp-config", "/tmp/mcp.json", "--strict-mcp-config",
+				"--allowedTools", "mcp__kubernetes__pods_list,mcp__kubernetes__pods_get,Skill",
+				"--model", "opus", "--max-turns", "10"},
+		},
+		"skills not mounted": {
+			spec: &AgentSpec{Skills: skills},
+			args: []string{"--print", "--output-format", "stream-json", "--verbose"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var runner Runner = newClaudeCodeRunner(tc.spec)
+			if tc.mcpServers != nil {
+				runner = runner.WithMcpServerInfo(tc.mcpServers)
+			}
+			if tc.skills != nil {
+				runner = runner.WithSkillInfo(tc.skills)
+			}
+
+			args, err := runner.(*claudeCodeRunner).args(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tc.args, args)
+		})
+	}
+}
+
+func TestClaudeCodeRunnerRunConversation(t *testing.T) {
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	promptsFile := filepath.Join(dir, "prompts")
+	events := filepath.Join(dir, "events.jsonl")
+	require.NoError(t, os.WriteFile(events, []byte(claudeCodeEventsOutput), 0o644))
+
+	claude := filepath.Join(dir, "claude")
+	script := `#!/bin/sh
+echo "$@" >> ` + argsFile + `
+cat >> ` + promptsFile + `
+echo >> ` + promptsFile + `
+# the ids of the tool calls of each turn are unique
+turn=$(wc -l < ` + argsFile + `)
+sed "s/toolu_1/toolu_$turn/" ` + events + `
+`
+	require.NoError(t, os.WriteFile(claude, []byte(script), 0o755))
+
+	runner := newClaudeCodeRunner(&AgentSpec{Metadata: AgentMetadata{Name: "claude-code"}})
+	runner.cmd = claude
+
+	turns := 0
+	next := func(_ context.Context, result AgentResult) (string, bool, error) {
+		turns++
+		assert.Len(t, result.GetToolCalls(), turns)
+		return "Delete the pod", turns < 2, nil
+	}
+
+	result, err := runner.RunConversation(context.Background(), "List the pods", next)
+	require.NoError(t, err)
+
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	assert.Equal(t, "--print --output-format stream-json --verbose\n"+
+		"--print --output-format stream-json --verbose --resume session-1\n", string(args))
+
+	prompts, err := os.ReadFile(promptsFile)
+	require.NoError(t, err)
+	assert.Equal(t, "List the pods\nDelete the pod\n", string(prompts))
+
+	assert.Equal(t, 2, turns)
+	assert.Len(t, result.GetToolCalls(), 2)
+	assert.Equal(t, "The only pod is nginx.", FinalMessageFromSteps(result.GetOutput()))
+
+	estimate := result.GetTokenEstimate()
+	assert.Equal(t, tokens.SourceActual, estimate.Source)
+	assert.Equal(t, int64(2*35), estimate.InputTokens)
+	assert.Equal(t, int64(2*7), estimate.OutputTokens)
+
+	reporter, ok := result.(ResourceUsageReporter)
+	require.True(t, ok)
+	assert.NotNil(t, reporter.GetResourceUsage())
+}
+
+func TestClaudeCodeRunnerErrors(t *testing.T) {
+	tests := map[string]struct {
+		script      string
+		errContains string
+	}{
+		"exit status": {
+			script:      "echo 'not logged in' >&2\nexit 1",
+			errContains: "not logged in",
+		},
+		"error result": {
+			script:      `echo '{"type":"result","subtype":"error_max_turns","is_error":true,"result":"reached max turns","session_id":"session-1"}'`,
+			errContains: "claude code failed: error_max_turns: reached max turns",
+		},
+		"no result": {
+			script:      `echo '{"type":"system","subtype":"init","session_id":"session-1"}'`,
+			errContains: "no result reported",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			claude := filepath.Join(t.TempDir(), "claude")
+			require.NoError(t, os.WriteFile(claude, []byte("#!/bin/sh\n"+tc.script+"\n"), 0o755))
+
+			runner := newClaudeCodeRunner(&AgentSpec{})
+			runner.cmd = claude
+
+			_, err := runner.RunTask(context.Background(), "List the pods")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errContains)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package agent
 
 import (
 	"fmt"
+	"math"
 	"os"
 
 	"github.com/mcpchecker/mcpchecker/pkg/acpclient"
@@ -20,6 +21,18 @@ type AgentSpec struct {
 	AcpConfig     *acpclient.AcpConfig `json:"acp,omitempty"` // if builtin and acp are both set, default to acp
 	Commands      AgentCommands        `json:"commands"`
 	Skills        *AgentSkillsConfig   `json:"skills,omitempty"`
+	Env           *EnvPolicy           `json:"env,omitempty"` // environment passed to the agent command; inherits everything if unset
+
+	// Extends is the spec this spec is merged over: "builtin.<type>" for a
+	// builtin agent, or the path of another agent file, relative to this one
+	Extends string `json:"extends,omitempty"`
+
+	// Model and ExtraArgs are passed to command templates as {{ .Model }} and
+	// {{ .ExtraArgs }}, each argument quoted for the shell. ExtraArgs are appended
+	// to acp.args for ACP agents.
+	// Both can be overridden by the agent ref of an eval config.
+	Model     string   `json:"model,omitempty"`
+	ExtraArgs []string `json:"extraArgs,omitempty"`
 }
 
 // AgentSkillsConfig defines agent-specific skill loading behavior
@@ -38,6 +51,7 @@ type AgentSkillsConfig struct {
 type AgentRef struct {
 	// Type specifies the agent type:
 	// - "builtin.claude-code" for Claude Code
+	// - "builtin.claude-code-acp" for Claude Code through its ACP adapter
 	// - "builtin.llm-agent" for LLM agents (supports openai, anthropic, gemini, etc.)
 	// - "file" for custom agent configuration files
 	Type string `json:"type"`
@@ -45,8 +59,14 @@ type AgentRef struct {
 	// Path to agent configuration file (required when type is "file")
 	Path string `json:"path,omitempty"`
 
-	// Model in "provider:model-id" format (required for builtin.llm-agent)
+	// Model in "provider:model-id" format (required for builtin.llm-agent),
+	// or the model passed to Claude Code for builtin.claude-code. For agent
+	// files, it overrides the model of builtin.llm-agent or the model passed
+	// to command templates and Claude Code.
 	Model string `json:"model,omitempty"`
+
+	// ExtraArgs are appended to the extra arguments of the agent
+	ExtraArgs []string `json:"extraArgs,omitempty"`
 }
 
 // BuiltinRef references a built-in agent type with optional model
@@ -95,11 +115,64 @@ type AgentCommands struct {
 	// the prompt will be in {{ .Prompt }}
 	// the servers will be in {{ .McpServerFileArgs }}
 	// the allowed tools will be in {{ .AllowedToolArgs }}
+	// the model and extra arguments will be in {{ .Model }} and {{ .ExtraArgs }}
 	RunPrompt string `json:"runPrompt"`
 
 	// An optional command to get the version of the agent
 	// useful for generic agents such as claude code that may autoupdate/have different versions on different machines
 	GetVersion *string `json:"getVersion,omitempty"`
+
+	// Run the agent command in a pseudo-terminal instead of with piped output,
+	// for agent CLIs that render some of their output, such as errors, only in
+	// their TUI. When the command fails, the final screen of the terminal is
+	// saved with the artifacts of the task
+	Terminal *TerminalConfig `json:"terminal,omitempty"`
+}
+
+// TerminalConfig configures the pseudo-terminal an agent command runs in
+type TerminalConfig struct {
+	// Columns and Rows are the size of the terminal. They default to 120 by 40
+	Columns int `json:"columns,omitempty"`
+	Rows    int `json:"rows,omitempty"`
+
+	// Term is the TERM the command sees. It defaults to xterm-256color
+	Term string `json:"term,omitempty"`
+
+	// Record saves an asciinema recording of the terminal, terminal.cast,
+	// next to its final screen
+	Record bool `json:"record,omitempty"`
+}
+
+const (
+	defaultTerminalColumns = 120
+	defaultTerminalRows    = 40
+	defaultTerminalTerm    = "xterm-256color"
+)
+
+// Size returns the size of the terminal, with the defaults applied.
+func (t *TerminalConfig) Size() (columns, rows int) {
+	columns, rows = defaultTerminalColumns, defaultTerminalRows
+	if t.Columns > 0 {
+		columns = t.Columns
+	}
+	if t.Rows > 0 {
+		rows = t.Rows
+	}
+	return columns, rows
+}
+
+// Validate checks the size of the terminal. A nil TerminalConfig is valid.
+func (t *TerminalConfig) Validate() error {
+	if t == nil {
+		return nil
+	}
+	if t.Columns < 0 || t.Columns > math.MaxUint16 {
+		return fmt.Errorf("commands.terminal.columns must be between 0 and %d, got %d", math.MaxUint16, t.Columns)
+	}
+	if t.Rows < 0 || t.Rows > math.MaxUint16 {
+		return fmt.Errorf("commands.terminal.rows must be between 0 and %d, got %d", math.MaxUint16, t.Rows)
+	}
+	return nil
 }
 
 func Read(data []byte) (*AgentSpec, error) {
@@ -123,6 +196,14 @@ func Read(data []byte) (*AgentSpec, error) {
 		}
 	}
 
+	if err := spec.Env.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid env policy: %w", err)
+	}
+
+	if err := spec.Commands.Terminal.Validate(); err != nil {
+		return nil, err
+	}
+
 	return spec, nil
 }
 
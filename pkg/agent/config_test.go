@@ -52,6 +52,31 @@ func TestFromFile(t *testing.T) {
 				},
 			},
 		},
+		"agent with env policy": {
+			file: "agent-with-env.yaml",
+			expected: &AgentSpec{
+				TypeMeta: util.TypeMeta{
+					Kind: KindAgent,
+				},
+				Metadata: AgentMetadata{
+					Name: "sandboxed",
+				},
+				Env: &EnvPolicy{
+					Inherit: []string{"PATH", "HOME", "ANTHROPIC_*"},
+					Deny:    []string{"ANTHROPIC_ADMIN_KEY"},
+					Set:     map[string]string{"CI": "true"},
+				},
+				Commands: AgentCommands{
+					ArgTemplateMcpServer:    "{{ .File }}",
+					ArgTemplateAllowedTools: "{{ .ToolName }}",
+					RunPrompt:               "agent {{ .Prompt }}",
+				},
+			},
+		},
+		"invalid env pattern": {
+			file:      "agent-invalid-env.yaml",
+			expectErr: true,
+		},
 	}
 
 	for tn, tc := range tt {
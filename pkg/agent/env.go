@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// EnvPolicy controls which environment variables are passed to the agent command.
+// Without a policy the agent inherits the full environment of mcpchecker.
+//
+// Inherit and Deny entries are variable names, and may use glob patterns
+// (e.g. "AWS_*"). Variables in Set are always passed and are not subject to Deny.
+type EnvPolicy struct {
+	// Inherit lists the variables to pass through from the environment.
+	// If empty, all variables are inherited.
+	Inherit []string `json:"inherit,omitempty"`
+
+	// Set lists variables to set explicitly, overriding inherited values
+	Set map[string]string `json:"set,omitempty"`
+
+	// Deny lists variables that are never inherited, even if they match Inherit
+	Deny []string `json:"deny,omitempty"`
+}
+
+// Validate checks that all Inherit and Deny patterns are valid globs and that
+// the names in Set are valid.
+func (p *EnvPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	for _, pattern := range slices.Concat(p.Inherit, p.Deny) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid env pattern %q: %w", pattern, err)
+		}
+	}
+	for name := range p.Set {
+		if name == "" || strings.Contains(name, "=") {
+			return fmt.Errorf("invalid env var name %q", name)
+		}
+	}
+	return nil
+}
+
+// Apply returns the environment for the agent command, given the environment of
+// mcpchecker in os.Environ format. A nil policy returns environ unchanged.
+func (p *EnvPolicy) Apply(environ []string) []string {
+	if p == nil {
+		return environ
+	}
+
+	env := make([]string, 0, len(environ)+len(p.Set))
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := p.Set[name]; ok {
+			continue
+		}
+		if len(p.Inherit) > 0 && !matchesAny(name, p.Inherit) {
+			continue
+		}
+		if matchesAny(name, p.Deny) {
+			continue
+		}
+		env = append(env, kv)
+	}
+
+	names := make([]string, 0, len(p.Set))
+	for name := range p.Set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, name+"="+p.Set[name])
+	}
+
+	return env
+}
+
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// sensitiveEnvMarkers are substrings of variable names whose values are redacted
+var sensitiveEnvMarkers = []string{"KEY", "TOKEN", "SECRET", "PASSWORD", "PASSWD", "CREDENTIAL", "AUTH"}
+
+// redactEnv returns env sorted by name, with the values of variables that look
+// like secrets replaced by "***".
+func redactEnv(env []string) []string {
+	redacted := make([]string, 0, len(env))
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		upper := strings.ToUpper(name)
+		for _, marker := range sensitiveEnvMarkers {
+			if strings.Contains(upper, marker) {
+				value = "***"
+				break
+			}
+		}
+		redacted = append(redacted, name+"="+value)
+	}
+	sort.Strings(redacted)
+	return redacted
+}
@@ -0,0 +1,113 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvPolicyApply(t *testing.T) {
+	environ := []string{
+		"PATH=/usr/bin",
+		"HOME=/home/user",
+		"OPENAI_API_KEY=sk-openai",
+		"ANTHROPIC_API_KEY=sk-ant",
+		"ANTHROPIC_ADMIN_KEY=sk-admin",
+		"CI=false",
+	}
+
+	tests := map[string]struct {
+		policy   *EnvPolicy
+		expected []string
+	}{
+		"nil policy inherits everything": {
+			policy:   nil,
+			expected: environ,
+		},
+		"deny only": {
+			policy: &EnvPolicy{Deny: []string{"*_KEY"}},
+			expected: []string{
+				"PATH=/usr/bin",
+				"HOME=/home/user",
+				"CI=false",
+			},
+		},
+		"inherit with glob and deny": {
+			policy: &EnvPolicy{
+				Inherit: []string{"PATH", "ANTHROPIC_*"},
+				Deny:    []string{"ANTHROPIC_ADMIN_KEY"},
+			},
+			expected: []string{
+				"PATH=/usr/bin",
+				"ANTHROPIC_API_KEY=sk-ant",
+			},
+		},
+		"set overrides inherited and ignores deny": {
+			policy: &EnvPolicy{
+				Inherit: []string{"PATH"},
+				Deny:    []string{"CI", "TOKEN"},
+				Set:     map[string]string{"CI": "true", "TOKEN": "scoped"},
+			},
+			expected: []string{
+				"PATH=/usr/bin",
+				"CI=true",
+				"TOKEN=scoped",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.policy.Apply(environ))
+		})
+	}
+}
+
+func TestEnvPolicyValidate(t *testing.T) {
+	tests := map[string]struct {
+		policy    *EnvPolicy
+		expectErr bool
+	}{
+		"nil policy": {},
+		"valid policy": {
+			policy: &EnvPolicy{Inherit: []string{"AWS_*"}, Deny: []string{"AWS_SECRET_ACCESS_KEY"}, Set: map[string]string{"CI": "1"}},
+		},
+		"invalid pattern": {
+			policy:    &EnvPolicy{Inherit: []string{"AWS_[*"}},
+			expectErr: true,
+		},
+		"invalid set name": {
+			policy:    &EnvPolicy{Set: map[string]string{"A=B": "1"}},
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.policy.Validate()
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRedactEnv(t *testing.T) {
+	got := redactEnv([]string{
+		"PATH=/usr/bin",
+		"OPENAI_API_KEY=sk-openai",
+		"GITHUB_TOKEN=ghp_123",
+		"DB_PASSWORD=hunter2",
+		"EMPTY=",
+	})
+
+	assert.Equal(t, []string{
+		"DB_PASSWORD=***",
+		"EMPTY=",
+		"GITHUB_TOKEN=***",
+		"OPENAI_API_KEY=***",
+		"PATH=/usr/bin",
+	}, got)
+}
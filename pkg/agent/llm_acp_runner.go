@@ -11,25 +11,32 @@ import (
 	"github.com/mcpchecker/mcpchecker/pkg/acpclient"
 	"github.com/mcpchecker/mcpchecker/pkg/llmagent"
 	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 type llmACPRunner struct {
 	model      string
+	opts       runnerOptions
 	mcpServers mcpproxy.ServerManager
 	skills     *SkillInfo
+	activity   *util.Activity
 }
 
-var _ Runner = &llmACPRunner{}
+var (
+	_ Runner             = &llmACPRunner{}
+	_ ConversationRunner = &llmACPRunner{}
+)
 
 // NewLLMACPRunner creates a runner that uses the llmagent package with ACP protocol.
 // The model string is in "provider:model-id" format (e.g. "openai:gpt-4o").
-func NewLLMACPRunner(model string) (Runner, error) {
+func NewLLMACPRunner(model string, opts ...RunnerOption) (Runner, error) {
 	if model == "" {
 		return nil, fmt.Errorf("model is required for llm-agent")
 	}
 
 	return &llmACPRunner{
 		model: model,
+		opts:  newRunnerOptions(opts),
 	}, nil
 }
 
@@ -40,21 +47,45 @@ func (r *llmACPRunner) AgentName() string {
 func (r *llmACPRunner) WithMcpServerInfo(mcpServers mcpproxy.ServerManager) Runner {
 	return &llmACPRunner{
 		model:      r.model,
+		opts:       r.opts,
 		mcpServers: mcpServers,
 		skills:     r.skills,
+		activity:   r.activity,
 	}
 }
 
 func (r *llmACPRunner) WithSkillInfo(skills *SkillInfo) Runner {
 	return &llmACPRunner{
 		model:      r.model,
+		opts:       r.opts,
 		mcpServers: r.mcpServers,
 		skills:     skills,
+		activity:   r.activity,
+	}
+}
+
+func (r *llmACPRunner) WithActivity(activity *util.Activity) Runner {
+	return &llmACPRunner{
+		model:      r.model,
+		opts:       r.opts,
+		mcpServers: r.mcpServers,
+		skills:     r.skills,
+		activity:   activity,
 	}
 }
 
 func (r *llmACPRunner) RunTask(ctx context.Context, prompt string) (AgentResult, error) {
-	agent, err := llmagent.New(ctx, llmagent.Config{Model: r.model})
+	return r.RunConversation(ctx, prompt, nil)
+}
+
+func (r *llmACPRunner) RunConversation(ctx context.Context, prompt string, next NextTurn) (AgentResult, error) {
+	agent, err := llmagent.New(ctx, llmagent.Config{
+		Model:          r.model,
+		RateLimiter:    r.opts.rateLimiter,
+		Retry:          r.opts.modelRetry,
+		HealthObserver: r.opts.healthObserver,
+		Temperature:    r.opts.temperature,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create LLM agent: %w", err)
 	}
@@ -63,21 +94,23 @@ func (r *llmACPRunner) RunTask(ctx context.Context, prompt string) (AgentResult,
 
 	client := acpclient.NewClient(ctx, &acpclient.AcpConfig{
 		Transport: transport,
-	}, r.skills.ClientOptions()...)
+	}, append(r.skills.ClientOptions(), acpclient.WithActivity(r.activity))...)
 
 	if err := client.Start(ctx); err != nil {
 		return nil, fmt.Errorf("failed to start ACP client: %w", err)
 	}
 	defer client.Close(ctx)
 
-	result, err := client.RunWithUsage(ctx, prompt, r.mcpServers)
+	prompts := []string{prompt}
+	result, err := client.RunConversation(ctx, prompt, conversationPrompts(next, &prompts), r.mcpServers)
 	if err != nil {
 		return nil, fmt.Errorf("failed to run LLM agent: %w", err)
 	}
+	util.DebugDirFromContext(ctx).WriteJSON("updates.json", result.Updates)
 
 	return &acpResult{
 		updates:     result.Updates,
-		prompt:      prompt,
+		prompt:      joinPrompts(prompts),
 		actualUsage: result.Usage,
 	}, nil
 }
@@ -1,15 +1,77 @@
 package agent
 
-import "fmt"
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
 
-// LoadWithBuiltins loads an agent spec from a file and merges with builtin defaults if specified
+// LoadWithBuiltins loads an agent spec from a file and merges it over the spec
+// it extends: the defaults of a builtin agent, referenced with
+// "extends: builtin.<type>" or "builtin.type", or another agent file,
+// referenced with "extends: <path>". The command templates of the merged spec
+// are validated, see validateCommands.
 func LoadWithBuiltins(yamlPath string) (*AgentSpec, error) {
+	spec, err := loadExtended(yamlPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateCommands(spec); err != nil {
+		return nil, fmt.Errorf("invalid agent %q: %w", yamlPath, err)
+	}
+
+	return spec, nil
+}
+
+// loadExtended loads the agent spec at yamlPath, where seen holds the absolute
+// paths of the agent files extending it, to detect cycles.
+func loadExtended(yamlPath string, seen []string) (*AgentSpec, error) {
 	// Load YAML
 	spec, err := FromFile(yamlPath)
 	if err != nil {
 		return nil, err
 	}
 
+	if spec.Extends == "" {
+		return withBuiltinDefaults(spec)
+	}
+
+	if builtinType, ok := strings.CutPrefix(spec.Extends, builtinPrefix); ok {
+		if spec.Builtin == nil {
+			spec.Builtin = &BuiltinRef{}
+		}
+		if spec.Builtin.Type != "" && spec.Builtin.Type != builtinType {
+			return nil, fmt.Errorf("agent extends %q but builtin.type is %q", spec.Extends, spec.Builtin.Type)
+		}
+		spec.Builtin.Type = builtinType
+		return withBuiltinDefaults(spec)
+	}
+
+	basePath := spec.Extends
+	if !filepath.IsAbs(basePath) {
+		basePath = filepath.Join(filepath.Dir(yamlPath), basePath)
+	}
+	absPath, err := filepath.Abs(yamlPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve agent file %q: %w", yamlPath, err)
+	}
+	if slices.Contains(seen, absPath) {
+		return nil, fmt.Errorf("agent file %q extends itself", yamlPath)
+	}
+
+	base, err := loadExtended(basePath, append(seen, absPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent %q extended by %q: %w", spec.Extends, yamlPath, err)
+	}
+
+	return mergeAgentSpecs(base, spec), nil
+}
+
+// withBuiltinDefaults merges spec over the defaults of its builtin agent type,
+// if it references one.
+func withBuiltinDefaults(spec *AgentSpec) (*AgentSpec, error) {
 	// If no builtin reference, return as-is
 	if spec.Builtin == nil {
 		return spec, nil
@@ -83,7 +145,8 @@ func mergeAgentSpecs(defaults, overrides *AgentSpec) *AgentSpec {
 		overrides.Commands.RunPrompt != "" ||
 		overrides.Commands.AllowedToolsJoinSeparator != nil ||
 		overrides.Commands.GetVersion != nil ||
-		overrides.Commands.UseVirtualHome != nil
+		overrides.Commands.UseVirtualHome != nil ||
+		overrides.Commands.Terminal != nil
 
 	if commandsSpecified {
 		// Override individual command fields if they are non-empty
@@ -106,6 +169,38 @@ func mergeAgentSpecs(defaults, overrides *AgentSpec) *AgentSpec {
 		if overrides.Commands.UseVirtualHome != nil {
 			result.Commands.UseVirtualHome = overrides.Commands.UseVirtualHome
 		}
+		if overrides.Commands.Terminal != nil {
+			result.Commands.Terminal = overrides.Commands.Terminal
+		}
+	}
+
+	// Merge ACP configuration: the command and arguments are overridden separately
+	if overrides.AcpConfig != nil {
+		if result.AcpConfig == nil {
+			result.AcpConfig = overrides.AcpConfig
+		} else {
+			acpConfig := *result.AcpConfig
+			if overrides.AcpConfig.Cmd != "" {
+				acpConfig.Cmd = overrides.AcpConfig.Cmd
+			}
+			if overrides.AcpConfig.Args != nil {
+				acpConfig.Args = overrides.AcpConfig.Args
+			}
+			result.AcpConfig = &acpConfig
+		}
+	}
+
+	if overrides.Skills != nil {
+		result.Skills = overrides.Skills
+	}
+	if overrides.Env != nil {
+		result.Env = overrides.Env
+	}
+	if overrides.Model != "" {
+		result.Model = overrides.Model
+	}
+	if overrides.ExtraArgs != nil {
+		result.ExtraArgs = overrides.ExtraArgs
 	}
 
 	return &result
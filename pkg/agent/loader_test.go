@@ -3,8 +3,10 @@ package agent
 import (
 	"os/exec"
 	"testing"
+	"time"
 
 	"github.com/mcpchecker/mcpchecker/pkg/acpclient"
+	"github.com/mcpchecker/mcpchecker/pkg/llmagent"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -23,11 +25,12 @@ func TestLoadWithBuiltins(t *testing.T) {
 			file: "builtin-claude-code.yaml",
 			validate: func(t *testing.T, spec *AgentSpec) {
 				assert.Equal(t, "claude-code", spec.Metadata.Name)
-				require.NotNil(t, spec.AcpConfig)
-				assert.Equal(t, "claude-agent-acp", spec.AcpConfig.Cmd)
+				assert.Nil(t, spec.AcpConfig)
+				require.NotNil(t, spec.Builtin)
+				assert.Equal(t, "claude-code", spec.Builtin.Type)
 			},
 			shouldSkip: func() bool {
-				_, err := exec.LookPath("claude-agent-acp")
+				_, err := exec.LookPath("claude")
 				return err != nil
 			}(),
 		},
@@ -62,6 +65,50 @@ func TestLoadWithBuiltins(t *testing.T) {
 				assert.Equal(t, "2.0.x", *spec.Metadata.Version)
 			},
 		},
+		"extends builtin": {
+			file: "extends-builtin.yaml",
+			validate: func(t *testing.T, spec *AgentSpec) {
+				assert.Equal(t, "gpt-4o", spec.Metadata.Name)
+				require.NotNil(t, spec.Builtin)
+				assert.Equal(t, "llm-agent", spec.Builtin.Type)
+				assert.Equal(t, "openai:gpt-4o", spec.Builtin.Model)
+			},
+		},
+		"extends agent file": {
+			file: "extends-file.yaml",
+			validate: func(t *testing.T, spec *AgentSpec) {
+				assert.Equal(t, "shell-opus", spec.Metadata.Name)
+				// Version and commands come from the extended file
+				require.NotNil(t, spec.Metadata.Version)
+				assert.Equal(t, "1.0.0", *spec.Metadata.Version)
+				assert.Equal(t, "mcp__{{ .ServerName }}__{{ .ToolName }}", spec.Commands.ArgTemplateAllowedTools)
+				assert.Contains(t, spec.Commands.RunPrompt, "echo --mcp-config")
+				assert.Equal(t, "opus", spec.Model)
+				assert.Equal(t, []string{"--verbose"}, spec.ExtraArgs)
+			},
+		},
+		"extends builtin with conflicting builtin type": {
+			file:        "extends-conflict.yaml",
+			expectErr:   true,
+			errContains: `builtin.type is "claude-code"`,
+		},
+		"extends cycle": {
+			file:        "extends-cycle-a.yaml",
+			expectErr:   true,
+			errContains: "extends itself",
+		},
+		"runPrompt binary not in PATH": {
+			file: "agent-missing-binary.yaml",
+			validate: func(t *testing.T, spec *AgentSpec) {
+				// The binary is only needed to run the agent
+				assert.Equal(t, "missing-binary", spec.Metadata.Name)
+			},
+		},
+		"runPrompt with unknown template field": {
+			file:        "agent-invalid-template.yaml",
+			expectErr:   true,
+			errContains: "failed to execute commands.runPrompt",
+		},
 		"invalid builtin type": {
 			file:        "builtin-invalid-type.yaml",
 			expectErr:   true,
@@ -151,6 +198,64 @@ func TestNewRunnerForSpec(t *testing.T) {
 				assert.Equal(t, "acp-priority", runner.AgentName())
 			},
 		},
+		"acp config gets extra args": {
+			spec: &AgentSpec{
+				Metadata:  AgentMetadata{Name: "acp-extra-args"},
+				AcpConfig: &acpclient.AcpConfig{Cmd: "acp-cmd", Args: []string{"--arg1"}},
+				ExtraArgs: []string{"--model", "opus"},
+			},
+			validate: func(t *testing.T, runner Runner) {
+				acp, ok := runner.(*acpRunner)
+				require.True(t, ok, "expected runner to be *acpRunner")
+				assert.Equal(t, []string{"--arg1", "--model", "opus"}, acp.cfg.Args)
+			},
+		},
+		"acp config rejects a model": {
+			spec: &AgentSpec{
+				Metadata:  AgentMetadata{Name: "acp-model"},
+				AcpConfig: &acpclient.AcpConfig{Cmd: "acp-cmd"},
+				Model:     "opus",
+			},
+			expectErr:   true,
+			errContains: "can't be passed to ACP agent",
+		},
+		"shell agent rejects a model its runPrompt ignores": {
+			spec: &AgentSpec{
+				Metadata: AgentMetadata{Name: "shell-agent"},
+				Commands: AgentCommands{RunPrompt: "echo {{ .Prompt }}"},
+				Model:    "sonnet",
+			},
+			expectErr:   true,
+			errContains: "doesn't use {{ .Model }}",
+		},
+		"shell agent accepts a model its runPrompt uses": {
+			spec: &AgentSpec{
+				Metadata: AgentMetadata{Name: "shell-agent"},
+				Commands: AgentCommands{RunPrompt: "echo --model {{ .Model }} {{ .Prompt }}"},
+				Model:    "sonnet",
+			},
+			validate: func(t *testing.T, runner Runner) {
+				_, ok := runner.(*agentSpecRunner)
+				assert.True(t, ok, "expected runner to be *agentSpecRunner")
+			},
+		},
+		"shell agent with runPrompt binary not in PATH": {
+			spec: &AgentSpec{
+				Metadata: AgentMetadata{Name: "missing-binary"},
+				Commands: AgentCommands{RunPrompt: "mcpchecker-no-such-agent {{ .Prompt }}"},
+			},
+			expectErr:   true,
+			errContains: `runs "mcpchecker-no-such-agent", which was not found in PATH`,
+		},
+		"llm agent rejects extra args": {
+			spec: &AgentSpec{
+				Metadata:  AgentMetadata{Name: "llm"},
+				Builtin:   &BuiltinRef{Type: "llm-agent", Model: "openai:gpt-4o"},
+				ExtraArgs: []string{"--verbose"},
+			},
+			expectErr:   true,
+			errContains: "extraArgs are not supported",
+		},
 		"spec without acp or builtin returns agentSpecRunner": {
 			spec: &AgentSpec{
 				Metadata: AgentMetadata{Name: "shell-agent"},
@@ -227,6 +332,38 @@ func TestMergeAgentSpecs(t *testing.T) {
 		assert.Equal(t, "{{ .File }}", result.Commands.ArgTemplateMcpServer)
 	})
 
+	t.Run("override terminal", func(t *testing.T) {
+		base := &AgentSpec{
+			Commands: AgentCommands{RunPrompt: "agent {{ .Prompt }}"},
+		}
+		override := &AgentSpec{
+			Commands: AgentCommands{Terminal: &TerminalConfig{Record: true}},
+		}
+		result := mergeAgentSpecs(base, override)
+
+		assert.Equal(t, &TerminalConfig{Record: true}, result.Commands.Terminal)
+		assert.Equal(t, "agent {{ .Prompt }}", result.Commands.RunPrompt)
+	})
+
+	t.Run("override acp config", func(t *testing.T) {
+		base := &AgentSpec{
+			AcpConfig: &acpclient.AcpConfig{Cmd: "claude-agent-acp", Args: []string{"--base"}},
+			Skills:    &AgentSkillsConfig{MountPath: ".claude/skills", ToolName: "Skill"},
+		}
+		override := &AgentSpec{
+			AcpConfig: &acpclient.AcpConfig{Args: []string{"--override"}},
+			Model:     "opus",
+		}
+		result := mergeAgentSpecs(base, override)
+
+		require.NotNil(t, result.AcpConfig)
+		assert.Equal(t, "claude-agent-acp", result.AcpConfig.Cmd)
+		assert.Equal(t, []string{"--override"}, result.AcpConfig.Args)
+		assert.Equal(t, []string{"--base"}, base.AcpConfig.Args, "base should not be modified")
+		assert.Equal(t, base.Skills, result.Skills)
+		assert.Equal(t, "opus", result.Model)
+	})
+
 	t.Run("override preserves base when override is empty", func(t *testing.T) {
 		base := &AgentSpec{
 			Metadata: AgentMetadata{Name: "base"},
@@ -246,3 +383,65 @@ func TestMergeAgentSpecs(t *testing.T) {
 		assert.Equal(t, "base command", result.Commands.RunPrompt)
 	})
 }
+
+func TestResolveAgentRefOverrides(t *testing.T) {
+	t.Run("agent file", func(t *testing.T) {
+		spec, err := ResolveAgentRef(&AgentRef{
+			Type:      "file",
+			Path:      basePath + "/extends-file.yaml",
+			Model:     "sonnet",
+			ExtraArgs: []string{"--max-turns", "5"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "sonnet", spec.Model)
+		assert.Equal(t, []string{"--verbose", "--max-turns", "5"}, spec.ExtraArgs)
+	})
+
+	t.Run("builtin llm-agent file", func(t *testing.T) {
+		spec, err := ResolveAgentRef(&AgentRef{
+			Type:  "file",
+			Path:  basePath + "/extends-builtin.yaml",
+			Model: "anthropic:claude-sonnet-4",
+		})
+		require.NoError(t, err)
+		require.NotNil(t, spec.Builtin)
+		assert.Equal(t, "anthropic:claude-sonnet-4", spec.Builtin.Model)
+		assert.Empty(t, spec.Model)
+	})
+
+	t.Run("builtin", func(t *testing.T) {
+		spec, err := ResolveAgentRef(&AgentRef{
+			Type:      "builtin.llm-agent",
+			Model:     "openai:gpt-4o",
+			ExtraArgs: []string{"--verbose"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "openai:gpt-4o", spec.Builtin.Model)
+		assert.Equal(t, []string{"--verbose"}, spec.ExtraArgs)
+	})
+}
+
+func TestNewRunnerForSpecRunnerOptions(t *testing.T) {
+	limiter, err := llmagent.NewRateLimiter(&llmagent.RateLimitConfig{MaxConcurrent: 1})
+	require.NoError(t, err)
+	maxRetries := 4
+	retry := &llmagent.RetryConfig{MaxRetries: &maxRetries}
+	observer := &nopHealthObserver{}
+
+	runner, err := NewRunnerForSpec(&AgentSpec{
+		Builtin: &BuiltinRef{Type: "llm-agent", Model: "openai:gpt-4o"},
+	}, WithRateLimiter(limiter), WithModelRetry(retry), WithHealthObserver(observer))
+	require.NoError(t, err)
+
+	// The options are kept by the runners of the task runs
+	runner = runner.WithMcpServerInfo(nil).WithSkillInfo(nil)
+	llmRunner, ok := runner.(*llmACPRunner)
+	require.True(t, ok, "expected runner to be *llmACPRunner")
+	assert.Same(t, limiter, llmRunner.opts.rateLimiter)
+	assert.Same(t, retry, llmRunner.opts.modelRetry)
+	assert.Same(t, observer, llmRunner.opts.healthObserver)
+}
+
+type nopHealthObserver struct{}
+
+func (*nopHealthObserver) ObserveRequest(time.Duration, error) {}
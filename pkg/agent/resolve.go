@@ -18,7 +18,12 @@ func ResolveAgentRef(ref *AgentRef) (*AgentSpec, error) {
 		if ref.Path == "" {
 			return nil, fmt.Errorf("path must be specified when agent type is 'file'")
 		}
-		return LoadWithBuiltins(ref.Path)
+		spec, err := LoadWithBuiltins(ref.Path)
+		if err != nil {
+			return nil, err
+		}
+		applyRefOverrides(spec, ref)
+		return spec, nil
 	}
 
 	if !strings.HasPrefix(ref.Type, builtinPrefix) {
@@ -43,6 +48,32 @@ func ResolveAgentRef(ref *AgentRef) (*AgentSpec, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get defaults for builtin agent %q: %w", builtinType, err)
 	}
+	agentSpec.ExtraArgs = append(agentSpec.ExtraArgs, ref.ExtraArgs...)
 
 	return agentSpec, nil
 }
+
+// applyRefOverrides applies the parameters of an agent ref to the spec of an
+// agent file. The model replaces the model of builtin.llm-agent, or the model
+// passed to command templates for other agents.
+func applyRefOverrides(spec *AgentSpec, ref *AgentRef) {
+	if ref.Model != "" {
+		if usesBuiltinModel(spec) {
+			spec.Builtin.Model = ref.Model
+		} else {
+			spec.Model = ref.Model
+		}
+	}
+	spec.ExtraArgs = append(spec.ExtraArgs, ref.ExtraArgs...)
+}
+
+// usesBuiltinModel reports whether spec runs a builtin agent that takes its
+// model from builtin.model, such as builtin.llm-agent.
+func usesBuiltinModel(spec *AgentSpec) bool {
+	// ACP config takes precedence over the builtin type, see NewRunnerForSpec
+	if spec.Builtin == nil || spec.AcpConfig != nil {
+		return false
+	}
+	builtinAgent, ok := GetBuiltinType(spec.Builtin.Type)
+	return ok && builtinAgent.RequiresModel()
+}
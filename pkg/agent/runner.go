@@ -5,12 +5,15 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
+	"regexp"
+	"slices"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/coder/acp-go-sdk"
 	"github.com/mcpchecker/mcpchecker/pkg/acpclient"
+	"github.com/mcpchecker/mcpchecker/pkg/llmagent"
 	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
 	"github.com/mcpchecker/mcpchecker/pkg/tokenizer"
 	"github.com/mcpchecker/mcpchecker/pkg/tokens"
@@ -21,9 +24,25 @@ type Runner interface {
 	RunTask(ctx context.Context, prompt string) (AgentResult, error)
 	WithMcpServerInfo(mcpServers mcpproxy.ServerManager) Runner
 	WithSkillInfo(skills *SkillInfo) Runner
+	// WithActivity returns a runner that records the progress of the agent
+	// in activity, so that a stalled agent can be detected. A nil activity
+	// records nothing.
+	WithActivity(activity *util.Activity) Runner
 	AgentName() string
 }
 
+// NextTurn returns the prompt of the next turn of a multi-turn task, given the
+// result of the conversation so far, or false once the conversation is over.
+type NextTurn func(ctx context.Context, result AgentResult) (prompt string, ok bool, err error)
+
+// ConversationRunner is implemented by the runners of agents that can be
+// prompted again in the same session, which multi-turn tasks require.
+// RunConversation runs prompt, then the prompts returned by next, and returns
+// the result of the whole conversation.
+type ConversationRunner interface {
+	RunConversation(ctx context.Context, prompt string, next NextTurn) (AgentResult, error)
+}
+
 // SkillInfo contains skill mounting information for the agent runner.
 // Implements acpclient.SkillInfo.
 type SkillInfo struct {
@@ -363,16 +382,54 @@ type AgentResult interface {
 	GetTokenEstimate() tokens.Estimate
 }
 
+// ResourceUsageReporter is implemented by the results of agents that run as a
+// subprocess. GetResourceUsage returns nil if the usage is unknown.
+type ResourceUsageReporter interface {
+	GetResourceUsage() *util.ResourceUsage
+}
+
+// mcpServerTemplateData is passed to commands.argTemplateMcpServer
+type mcpServerTemplateData struct {
+	File string
+	URL  string
+}
+
+// allowedToolTemplateData is passed to commands.argTemplateAllowedTools
+type allowedToolTemplateData struct {
+	ServerName string
+	ToolName   string
+}
+
+// modelTemplateRef matches a use of {{ .Model }} in a command template
+var modelTemplateRef = regexp.MustCompile(`\{\{[^}]*\.Model\b`)
+
+// runPromptTemplateData is passed to commands.runPrompt
+type runPromptTemplateData struct {
+	McpServerFileArgs string
+	AllowedToolArgs   string
+	Prompt            string
+	Model             string
+	ExtraArgs         string
+}
+
+// agentWaitDelay is how long the output of an agent command is still read
+// after it exits or is killed
+const agentWaitDelay = 10 * time.Second
+
 type agentSpecRunner struct {
 	*AgentSpec
-	mcpInfo McpServerInfo
-	skills  *SkillInfo
+	mcpInfo  McpServerInfo
+	skills   *SkillInfo
+	activity *util.Activity
 }
 
 type agentSpecRunnerResult struct {
 	commandOutput string
+	resourceUsage *util.ResourceUsage
 }
 
+var _ ResourceUsageReporter = &agentSpecRunnerResult{}
+
 func (a *agentSpecRunnerResult) GetOutput() []OutputStep {
 	return []OutputStep{{Type: "message", Content: a.commandOutput}}
 }
@@ -389,19 +446,87 @@ func (a *agentSpecRunnerResult) GetTokenEstimate() tokens.Estimate {
 	return tokens.Estimate{Error: "token estimation not supported for shell runner"}
 }
 
-func NewRunnerForSpec(spec *AgentSpec) (Runner, error) {
+func (a *agentSpecRunnerResult) GetResourceUsage() *util.ResourceUsage {
+	return a.resourceUsage
+}
+
+// RunnerOption configures the runners created by NewRunnerForSpec.
+type RunnerOption func(*runnerOptions)
+
+type runnerOptions struct {
+	rateLimiter    *llmagent.RateLimiter
+	modelRetry     *llmagent.RetryConfig
+	healthObserver llmagent.HealthObserver
+	temperature    *float64
+}
+
+// WithRateLimiter makes builtin LLM agents send their model requests through
+// limiter, which the agents and judges of a run share.
+func WithRateLimiter(limiter *llmagent.RateLimiter) RunnerOption {
+	return func(o *runnerOptions) {
+		o.rateLimiter = limiter
+	}
+}
+
+// WithModelRetry makes builtin LLM agents retry their failed model requests
+// as cfg configures.
+func WithModelRetry(cfg *llmagent.RetryConfig) RunnerOption {
+	return func(o *runnerOptions) {
+		o.modelRetry = cfg
+	}
+}
+
+// WithHealthObserver makes builtin LLM agents report their model requests to
+// observer.
+func WithHealthObserver(observer llmagent.HealthObserver) RunnerOption {
+	return func(o *runnerOptions) {
+		o.healthObserver = observer
+	}
+}
+
+// WithTemperature makes builtin LLM agents sample their models at temperature.
+func WithTemperature(temperature float64) RunnerOption {
+	return func(o *runnerOptions) {
+		o.temperature = &temperature
+	}
+}
+
+func newRunnerOptions(opts []RunnerOption) runnerOptions {
+	var o runnerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// NewRunnerForSpec creates the runner of spec. The options only apply to
+// builtin LLM agents, other agents call their models themselves.
+func NewRunnerForSpec(spec *AgentSpec, opts ...RunnerOption) (Runner, error) {
 	if spec == nil {
 		return nil, fmt.Errorf("cannot create a Runner for a nil AgentSpec")
 	}
 
 	// check first for acp config
 	if spec.AcpConfig != nil {
-		return NewAcpRunner(spec.AcpConfig, spec.Metadata.Name), nil
+		// ACP has no way to choose the model of the agent
+		if spec.Model != "" {
+			return nil, fmt.Errorf("model %q can't be passed to ACP agent %q: choose the model in acp.args or with extraArgs instead", spec.Model, spec.Metadata.Name)
+		}
+
+		acpConfig := spec.AcpConfig
+		if len(spec.ExtraArgs) > 0 {
+			copied := *acpConfig
+			copied.Args = append(slices.Clone(acpConfig.Args), spec.ExtraArgs...)
+			acpConfig = &copied
+		}
+		return NewAcpRunner(acpConfig, spec.Metadata.Name), nil
 	}
 
-	// Check if this is an LLM agent (or a deprecated alias)
+	// Check if this is an LLM agent (or a deprecated alias) or Claude Code
 	if spec.Builtin != nil {
 		switch spec.Builtin.Type {
+		case "claude-code":
+			return newClaudeCodeRunner(spec), nil
 		case "llm-agent", "openai-agent", "openai-acp":
 			model := spec.Builtin.Model
 
@@ -419,11 +544,23 @@ func NewRunnerForSpec(spec *AgentSpec) (Runner, error) {
 				}
 			}
 
+			if len(spec.ExtraArgs) > 0 {
+				return nil, fmt.Errorf("extraArgs are not supported by builtin type %q", spec.Builtin.Type)
+			}
+
 			migrateLegacyEnvVars(spec.Builtin)
-			return NewLLMACPRunner(model)
+			return NewLLMACPRunner(model, opts...)
 		}
 	}
 
+	if spec.Model != "" && !modelTemplateRef.MatchString(spec.Commands.RunPrompt) {
+		return nil, fmt.Errorf("model %q can't be passed to agent %q: commands.runPrompt doesn't use {{ .Model }}", spec.Model, spec.Metadata.Name)
+	}
+
+	if err := checkAgentInstalled(spec); err != nil {
+		return nil, err
+	}
+
 	// Use the standard shell-based runner for all other agents
 	return &agentSpecRunner{
 		AgentSpec: spec,
@@ -461,37 +598,29 @@ func migrateLegacyEnvVars(ref *BuiltinRef) {
 }
 
 func (a *agentSpecRunner) RunTask(ctx context.Context, prompt string) (AgentResult, error) {
-	debugDir := ""
-	if os.Getenv("MCPCHECKER_DEBUG") != "" {
-		if dir, err := os.MkdirTemp("", "mcpchecker-debug-"); err == nil {
-			debugDir = dir
-		} else {
-			fmt.Fprintf(os.Stderr, "Warning: failed to create debug directory: %v\n", err)
+	debug := util.DebugDirFromContext(ctx)
+
+	// Create an empty directory for agent execution to isolate it from source code.
+	// In debug mode it is created in the debug directory, which is always kept.
+	tempDir := debug.Sub("workdir").Path()
+	if tempDir == "" {
+		var err error
+		tempDir, err = os.MkdirTemp("", "mcpchecker-agent-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary directory for agent execution: %w", err)
 		}
 	}
-
-	// Create an empty temporary directory for agent execution to isolate it from source code
-	tempDir, err := os.MkdirTemp("", "mcpchecker-agent-")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temporary directory for agent execution: %w", err)
-	}
 	executionSucceeded := false
 	defer func() {
-		// Clean up temp directory unless execution failed OR MCPCHECKER_DEBUG is set
-		// In that case, preserve it for debugging
-		shouldPreserve := !executionSucceeded || os.Getenv("MCPCHECKER_DEBUG") != ""
-		if !shouldPreserve {
+		// The directory is kept in debug mode. Otherwise it is cleaned up unless
+		// execution failed, in which case it is preserved for debugging.
+		if debug != nil {
+			return
+		}
+		if executionSucceeded {
 			_ = os.RemoveAll(tempDir)
 		} else {
-			var reason string
-			if !executionSucceeded && os.Getenv("MCPCHECKER_DEBUG") != "" {
-				reason = "execution failed and MCPCHECKER_DEBUG is set"
-			} else if !executionSucceeded {
-				reason = "execution failed"
-			} else {
-				reason = "MCPCHECKER_DEBUG is set"
-			}
-			fmt.Fprintf(os.Stderr, "Preserving temporary directory %s because %s\n", tempDir, reason)
+			fmt.Fprintf(os.Stderr, "Preserving temporary directory %s because execution failed\n", tempDir)
 		}
 	}()
 
@@ -535,10 +664,7 @@ func (a *agentSpecRunner) RunTask(ctx context.Context, prompt string) (AgentResu
 			return nil, fmt.Errorf("failed to get config for server %s: %w", servers[i].GetName(), err)
 		}
 
-		tmp := struct {
-			File string
-			URL  string
-		}{
+		tmp := mcpServerTemplateData{
 			File: f,
 			URL:  serverCfg.URL,
 		}
@@ -555,10 +681,7 @@ func (a *agentSpecRunner) RunTask(ctx context.Context, prompt string) (AgentResu
 	var allowedTools []string
 	for _, s := range a.mcpInfo.GetMcpServers() {
 		for _, t := range s.GetAllowedTools(ctx) {
-			tmp := struct {
-				ServerName string
-				ToolName   string
-			}{
+			tmp := allowedToolTemplateData{
 				ServerName: s.GetName(),
 				ToolName:   t.Name,
 			}
@@ -579,14 +702,15 @@ func (a *agentSpecRunner) RunTask(ctx context.Context, prompt string) (AgentResu
 		allowedToolsSeparator = *a.Commands.AllowedToolsJoinSeparator
 	}
 
-	tmp := struct {
-		McpServerFileArgs string
-		AllowedToolArgs   string
-		Prompt            string
-	}{
+	// Extra arguments are quoted, so that each reaches the agent as one
+	// argument, as for ACP agents
+	shell := util.DefaultShell()
+	tmp := runPromptTemplateData{
 		McpServerFileArgs: strings.Join(serverFiles, " "),
 		AllowedToolArgs:   strings.Join(allowedTools, allowedToolsSeparator),
 		Prompt:            prompt,
+		Model:             a.Model,
+		ExtraArgs:         shell.QuoteArgs(a.ExtraArgs),
 	}
 
 	formatted := bytes.NewBuffer(nil)
@@ -595,45 +719,79 @@ func (a *agentSpecRunner) RunTask(ctx context.Context, prompt string) (AgentResu
 		return nil, fmt.Errorf("failed to execute runPrompt: %w", err)
 	}
 
-	shell, ok := os.LookupEnv("SHELL")
-	if !ok {
-		shell = "/usr/bin/bash"
-	}
-
-	cmd := exec.CommandContext(ctx, shell, "-c", formatted.String())
+	cmd := shell.Command(ctx, formatted.String())
 	cmd.Dir = tempDir
-	envVars := os.Environ()
-	if debugDir != "" {
-		envVars = append(envVars, fmt.Sprintf("MCPCHECKER_DEBUG_DIR=%s", debugDir))
-		envVars = append(envVars, "MCPCHECKER_DEBUG=1")
+	envVars := a.Env.Apply(os.Environ())
+	if a.Commands.Terminal != nil {
+		envVars = terminalEnv(envVars, a.Commands.Terminal, a.Env)
+	}
+	if debug != nil {
+		// Agent scripts may write their own artifacts to MCPCHECKER_DEBUG_DIR
+		envVars = append(envVars, fmt.Sprintf("MCPCHECKER_DEBUG_DIR=%s", debug.Path()))
+		envVars = append(envVars, util.DebugEnv+"=1")
 	}
 	cmd.Env = envVars
-
-	res, err := cmd.CombinedOutput()
-	if err != nil {
-		debugSuffix := ""
-		if debugDir != "" {
-			debugSuffix = fmt.Sprintf("\n\ndebug artifacts preserved at: %s", debugDir)
+	// Processes the agent started may keep its output open after it is killed,
+	// for example when the task times out or the agent stalls
+	cmd.WaitDelay = agentWaitDelay
+
+	debug.WriteFile("command.txt", []byte(formatted.String()+"\n"))
+	debug.WriteFile("env.txt", []byte(strings.Join(redactEnv(envVars), "\n")+"\n"))
+
+	// Output counts as progress of the agent for stall detection
+	activity := a.activity
+	start := time.Now()
+	var res []byte
+	var terminal *terminalRun
+	if a.Commands.Terminal != nil {
+		terminal, err = runInTerminal(cmd, a.Commands.Terminal, activity)
+		if terminal != nil {
+			res = terminal.output
+		}
+	} else {
+		var output bytes.Buffer
+		w := activity.Writer(&output)
+		cmd.Stdout, cmd.Stderr = w, w
+		err = activity.Run(cmd)
+		res = output.Bytes()
+	}
+	resourceUsage := util.NewResourceUsage(cmd.ProcessState, time.Since(start))
+	debug.WriteFile("output.log", res)
+	if terminal != nil {
+		debug.WriteFile("terminal-screen.txt", []byte(terminal.screen))
+		if terminal.recording != nil {
+			debug.WriteFile("terminal.cast", terminal.recording)
 		}
-		// executionSucceeded remains false, so tempDir will be preserved
-		tempDirSuffix := fmt.Sprintf("\n\ntemporary directory preserved at: %s", tempDir)
-		return nil, fmt.Errorf("failed to run command: %s -c %q: %w.\n\noutput: %s%s%s", shell, formatted.String(), err, res, debugSuffix, tempDirSuffix)
 	}
-
-	executionSucceeded = true
-
-	if debugDir != "" {
-		_ = os.RemoveAll(debugDir)
+	if err != nil {
+		// executionSucceeded remains false, so tempDir will be preserved
+		suffix := fmt.Sprintf("\n\ntemporary directory preserved at: %s", tempDir)
+		if debug != nil {
+			suffix = fmt.Sprintf("\n\ndebug artifacts preserved at: %s", debug.Path())
+		}
+		if terminal != nil {
+			if debug == nil {
+				// Without debug mode the screen is saved in the preserved directory
+				artifacts, _ := util.NewDebugDir(tempDir)
+				artifacts.WriteFile("terminal-screen.txt", []byte(terminal.screen))
+				if terminal.recording != nil {
+					artifacts.WriteFile("terminal.cast", terminal.recording)
+				}
+			}
+			return nil, fmt.Errorf("failed to run command with %s in a terminal: %q: %w.\n\nfinal terminal screen:\n%s%s", shell.Path, formatted.String(), err, terminal.screen, suffix)
+		}
+		return nil, fmt.Errorf("failed to run command with %s: %q: %w.\n\noutput: %s%s", shell.Path, formatted.String(), err, res, suffix)
 	}
-
 	output := string(res)
-	// If MCPCHECKER_DEBUG is set, append temp directory info to output so it appears in JSON log
-	if os.Getenv("MCPCHECKER_DEBUG") != "" {
-		output += fmt.Sprintf("\n\ntemporary directory preserved at: %s", tempDir)
+	if terminal != nil {
+		output = plainTerminalOutput(res)
 	}
 
+	executionSucceeded = true
+
 	return &agentSpecRunnerResult{
 		commandOutput: output,
+		resourceUsage: resourceUsage,
 	}, nil
 }
 
@@ -642,6 +800,7 @@ func (a *agentSpecRunner) WithMcpServerInfo(mcpServers mcpproxy.ServerManager) R
 		AgentSpec: a.AgentSpec,
 		mcpInfo:   mcpServers,
 		skills:    a.skills,
+		activity:  a.activity,
 	}
 }
 
@@ -650,6 +809,16 @@ func (a *agentSpecRunner) WithSkillInfo(skills *SkillInfo) Runner {
 		AgentSpec: a.AgentSpec,
 		mcpInfo:   a.mcpInfo,
 		skills:    skills,
+		activity:  a.activity,
+	}
+}
+
+func (a *agentSpecRunner) WithActivity(activity *util.Activity) Runner {
+	return &agentSpecRunner{
+		AgentSpec: a.AgentSpec,
+		mcpInfo:   a.mcpInfo,
+		skills:    a.skills,
+		activity:  activity,
 	}
 }
 
@@ -0,0 +1,202 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// terminalDrainTimeout is how long the output of the terminal is still read
+// after the command exited, in case processes it started keep the terminal
+// open.
+const terminalDrainTimeout = 2 * time.Second
+
+// terminalEnv returns environ with TERM, COLUMNS and LINES set to describe
+// the terminal of cfg, instead of the terminal mcpchecker runs in. Variables
+// that policy sets explicitly are kept.
+func terminalEnv(environ []string, cfg *TerminalConfig, policy *EnvPolicy) []string {
+	columns, rows := cfg.Size()
+	term := cfg.Term
+	if term == "" {
+		term = defaultTerminalTerm
+	}
+	vars := map[string]string{
+		"TERM":    term,
+		"COLUMNS": strconv.Itoa(columns),
+		"LINES":   strconv.Itoa(rows),
+	}
+	if policy != nil {
+		for name := range policy.Set {
+			delete(vars, name)
+		}
+	}
+
+	env := make([]string, 0, len(environ)+len(vars))
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := vars[name]; !ok {
+			env = append(env, kv)
+		}
+	}
+	for _, name := range []string{"TERM", "COLUMNS", "LINES"} {
+		if value, ok := vars[name]; ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+// terminalEscapes matches the escape sequences terminal programs write: CSI
+// sequences, OSC, DCS and similar strings, charset designations and other
+// two byte escapes.
+var terminalEscapes = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[PX^_][^\x1b]*\x1b\\|\x1b[()*+#%].|\x1b[0-~]`)
+
+// plainTerminalOutput returns the text of the output of a terminal, without
+// escape sequences and with the CRLF line endings of the terminal turned into
+// line feeds.
+func plainTerminalOutput(output []byte) string {
+	text := terminalEscapes.ReplaceAll(output, nil)
+	return string(bytes.ReplaceAll(text, []byte("\r\n"), []byte("\n")))
+}
+
+// terminalRun is the output of a command run in a pseudo-terminal.
+type terminalRun struct {
+	// output is everything written to the terminal, escape sequences included
+	output []byte
+	// screen is the text on the screen of the terminal when the command exited
+	screen string
+	// recording is an asciicast v2 recording of the terminal, if enabled
+	recording []byte
+}
+
+// runInTerminal runs cmd in a pseudo-terminal configured by cfg and waits for
+// it to exit. Like exec.Cmd.CombinedOutput, the output is returned along with
+// the error of the command. Output and the process are reported to activity.
+func runInTerminal(cmd *exec.Cmd, cfg *TerminalConfig, activity *util.Activity) (*terminalRun, error) {
+	columns, rows := cfg.Size()
+
+	var output bytes.Buffer
+	screen := newTerminalScreen(columns, rows)
+	writers := []io.Writer{&output, screen}
+	var recorder *asciicastRecorder
+	if cfg.Record {
+		recorder = newAsciicastRecorder(columns, rows, time.Now())
+		writers = append(writers, recorder)
+	}
+
+	ptmx, err := util.StartInTerminal(cmd, columns, rows)
+	if err != nil {
+		if errors.Is(err, util.ErrTerminalUnsupported) {
+			return nil, fmt.Errorf("commands.terminal cannot be used: %w", err)
+		}
+		return nil, err
+	}
+
+	untrack := activity.TrackProcess(cmd.Process.Pid)
+	defer untrack()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// Reading fails with EIO on Linux once the terminal is closed on the
+		// command side, which is the end of the output
+		_, _ = io.Copy(activity.Writer(io.MultiWriter(writers...)), ptmx)
+	}()
+
+	err = cmd.Wait()
+	select {
+	case <-done:
+	case <-time.After(terminalDrainTimeout):
+	}
+	_ = ptmx.Close()
+	<-done
+
+	run := &terminalRun{
+		output: output.Bytes(),
+		screen: screen.String(),
+	}
+	if recorder != nil {
+		run.recording = recorder.Bytes()
+	}
+	return run, err
+}
+
+// asciicastRecorder records what is written to it as the output events of an
+// asciicast v2 recording, which asciinema can play back.
+type asciicastRecorder struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	start time.Time
+	now   func() time.Time
+	// partial holds the bytes of a UTF-8 sequence split across writes, since
+	// the events of a recording are strings
+	partial []byte
+}
+
+type asciicastHeader struct {
+	Version   int   `json:"version"`
+	Width     int   `json:"width"`
+	Height    int   `json:"height"`
+	Timestamp int64 `json:"timestamp"`
+}
+
+func newAsciicastRecorder(columns, rows int, start time.Time) *asciicastRecorder {
+	r := &asciicastRecorder{start: start, now: time.Now}
+	header, _ := json.Marshal(asciicastHeader{Version: 2, Width: columns, Height: rows, Timestamp: start.Unix()})
+	r.buf.Write(header)
+	r.buf.WriteByte('\n')
+	return r
+}
+
+// Write implements io.Writer. It never fails.
+func (r *asciicastRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data := append(r.partial, p...)
+	r.partial = nil
+	if cut := incompleteRuneStart(data); cut < len(data) {
+		r.partial = append([]byte(nil), data[cut:]...)
+		data = data[:cut]
+	}
+	if len(data) == 0 {
+		return len(p), nil
+	}
+
+	elapsed := r.now().Sub(r.start).Seconds()
+	text, _ := json.Marshal(string(data))
+	fmt.Fprintf(&r.buf, "[%s, \"o\", %s]\n", strconv.FormatFloat(elapsed, 'f', 6, 64), text)
+	return len(p), nil
+}
+
+// Bytes returns the recording.
+func (r *asciicastRecorder) Bytes() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return bytes.Clone(r.buf.Bytes())
+}
+
+// incompleteRuneStart returns the index of the UTF-8 sequence that data ends
+// in the middle of, or len(data) if it ends with a complete one.
+func incompleteRuneStart(data []byte) int {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(data)
+}
@@ -0,0 +1,436 @@
+package agent
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type screenParserState int
+
+const (
+	stateGround screenParserState = iota
+	stateEscape
+	stateCharset
+	stateCSI
+	stateOSC
+	stateString
+	stateStringEscape
+)
+
+// terminalScreen keeps the screen of a terminal that output is written to,
+// so that the final frame of a TUI can be saved as text. It understands the
+// cursor movement, erase, scroll and alternate screen sequences TUIs draw
+// with. Colors and other attributes are dropped.
+type terminalScreen struct {
+	columns, rows int
+
+	main, alt [][]rune
+	altActive bool
+
+	row, col int
+	// wrapPending is set once a character is written to the last column. The
+	// cursor only moves to the next line when the next character is written.
+	wrapPending bool
+
+	savedRow, savedCol int
+
+	// scrollTop and scrollBottom are the scrolling region, set with DECSTBM
+	scrollTop, scrollBottom int
+
+	state  screenParserState
+	params []byte
+	// partial holds the bytes of a UTF-8 sequence split across writes
+	partial []byte
+}
+
+func newTerminalScreen(columns, rows int) *terminalScreen {
+	s := &terminalScreen{columns: columns, rows: rows}
+	s.main = newScreenBuffer(columns, rows)
+	s.scrollBottom = rows - 1
+	return s
+}
+
+func newScreenBuffer(columns, rows int) [][]rune {
+	lines := make([][]rune, rows)
+	for i := range lines {
+		lines[i] = blankLine(columns)
+	}
+	return lines
+}
+
+func blankLine(columns int) []rune {
+	line := make([]rune, columns)
+	for i := range line {
+		line[i] = ' '
+	}
+	return line
+}
+
+// lines returns the buffer being displayed.
+func (s *terminalScreen) lines() [][]rune {
+	if s.altActive {
+		return s.alt
+	}
+	return s.main
+}
+
+// Write implements io.Writer. It never fails.
+func (s *terminalScreen) Write(p []byte) (int, error) {
+	data := p
+	if len(s.partial) > 0 {
+		data = append(s.partial, p...)
+		s.partial = nil
+	}
+
+	for len(data) > 0 {
+		b := data[0]
+		if b < utf8.RuneSelf || s.state != stateGround {
+			s.handleByte(b)
+			data = data[1:]
+			continue
+		}
+		if !utf8.FullRune(data) {
+			s.partial = append([]byte(nil), data...)
+			break
+		}
+		r, size := utf8.DecodeRune(data)
+		s.put(r)
+		data = data[size:]
+	}
+	return len(p), nil
+}
+
+func (s *terminalScreen) handleByte(b byte) {
+	switch s.state {
+	case stateGround:
+		s.handleGround(b)
+	case stateEscape:
+		s.handleEscape(b)
+	case stateCharset:
+		s.state = stateGround
+	case stateCSI:
+		switch {
+		case b >= 0x40 && b <= 0x7e:
+			s.handleCSI(b)
+			s.state = stateGround
+		case b == 0x1b:
+			s.state = stateEscape
+		case b == 0x18 || b == 0x1a:
+			s.state = stateGround
+		default:
+			s.params = append(s.params, b)
+		}
+	case stateOSC:
+		// OSC sequences, such as window titles, end with BEL or ST
+		switch b {
+		case 0x07:
+			s.state = stateGround
+		case 0x1b:
+			s.state = stateStringEscape
+		}
+	case stateString:
+		if b == 0x1b {
+			s.state = stateStringEscape
+		}
+	case stateStringEscape:
+		s.state = stateGround
+		if b != '\\' {
+			s.handleEscape(b)
+		}
+	}
+}
+
+func (s *terminalScreen) handleGround(b byte) {
+	switch b {
+	case 0x1b:
+		s.state = stateEscape
+	case '\r':
+		s.col = 0
+		s.wrapPending = false
+	case '\n', '\v', '\f':
+		s.lineFeed()
+	case '\b':
+		if s.col > 0 {
+			s.col--
+		}
+		s.wrapPending = false
+	case '\t':
+		s.col = min((s.col/8+1)*8, s.columns-1)
+		s.wrapPending = false
+	default:
+		if b >= 0x20 && b != 0x7f {
+			s.put(rune(b))
+		}
+	}
+}
+
+func (s *terminalScreen) handleEscape(b byte) {
+	s.state = stateGround
+	switch b {
+	case '[':
+		s.state = stateCSI
+		s.params = s.params[:0]
+	case ']':
+		s.state = stateOSC
+	case 'P', 'X', '^', '_':
+		s.state = stateString
+	case '(', ')', '*', '+', '#', '%':
+		s.state = stateCharset
+	case '7':
+		s.saveCursor()
+	case '8':
+		s.restoreCursor()
+	case 'D':
+		s.lineFeed()
+	case 'E':
+		s.col = 0
+		s.lineFeed()
+	case 'M':
+		s.reverseIndex()
+	case 'c':
+		*s = *newTerminalScreen(s.columns, s.rows)
+	}
+}
+
+func (s *terminalScreen) handleCSI(final byte) {
+	params := string(s.params)
+	private := strings.HasPrefix(params, "?")
+	args := parseCSIParams(strings.TrimLeft(params, "?<=>"))
+	arg := func(i, def int) int {
+		if i < len(args) && args[i] > 0 {
+			return args[i]
+		}
+		return def
+	}
+
+	if private {
+		if final == 'h' || final == 'l' {
+			for _, mode := range args {
+				switch mode {
+				case 47, 1047, 1049:
+					s.setAltScreen(final == 'h', mode == 1049)
+				}
+			}
+		}
+		return
+	}
+
+	s.wrapPending = false
+	switch final {
+	case 'A':
+		s.row = max(s.row-arg(0, 1), 0)
+	case 'B', 'e':
+		s.row = min(s.row+arg(0, 1), s.rows-1)
+	case 'C', 'a':
+		s.col = min(s.col+arg(0, 1), s.columns-1)
+	case 'D':
+		s.col = max(s.col-arg(0, 1), 0)
+	case 'E':
+		s.row = min(s.row+arg(0, 1), s.rows-1)
+		s.col = 0
+	case 'F':
+		s.row = max(s.row-arg(0, 1), 0)
+		s.col = 0
+	case 'G', '`':
+		s.col = clamp(arg(0, 1)-1, 0, s.columns-1)
+	case 'd':
+		s.row = clamp(arg(0, 1)-1, 0, s.rows-1)
+	case 'H', 'f':
+		s.row = clamp(arg(0, 1)-1, 0, s.rows-1)
+		s.col = clamp(arg(1, 1)-1, 0, s.columns-1)
+	case 'J':
+		s.eraseDisplay(arg(0, 0))
+	case 'K':
+		s.eraseLine(arg(0, 0))
+	case 'X':
+		s.clear(s.row, s.col, min(s.col+arg(0, 1), s.columns))
+	case 'P':
+		line := s.lines()[s.row]
+		n := min(arg(0, 1), s.columns-s.col)
+		copy(line[s.col:], line[s.col+n:])
+		s.clear(s.row, s.columns-n, s.columns)
+	case '@':
+		line := s.lines()[s.row]
+		n := min(arg(0, 1), s.columns-s.col)
+		copy(line[s.col+n:], line[s.col:])
+		s.clear(s.row, s.col, s.col+n)
+	case 'L':
+		if s.row >= s.scrollTop && s.row <= s.scrollBottom {
+			s.scrollDown(s.row, s.scrollBottom, arg(0, 1))
+		}
+	case 'M':
+		if s.row >= s.scrollTop && s.row <= s.scrollBottom {
+			s.scrollUp(s.row, s.scrollBottom, arg(0, 1))
+		}
+	case 'S':
+		s.scrollUp(s.scrollTop, s.scrollBottom, arg(0, 1))
+	case 'T':
+		s.scrollDown(s.scrollTop, s.scrollBottom, arg(0, 1))
+	case 'r':
+		top, bottom := arg(0, 1)-1, arg(1, s.rows)-1
+		if top < bottom && bottom < s.rows {
+			s.scrollTop, s.scrollBottom = top, bottom
+			s.row, s.col = 0, 0
+		}
+	case 's':
+		s.saveCursor()
+	case 'u':
+		s.restoreCursor()
+	}
+}
+
+// parseCSIParams parses the numeric parameters of a CSI sequence. Missing
+// parameters are 0, as are sub-parameters after ':'.
+func parseCSIParams(params string) []int {
+	if params == "" {
+		return nil
+	}
+	fields := strings.Split(params, ";")
+	args := make([]int, len(fields))
+	for i, field := range fields {
+		field, _, _ = strings.Cut(field, ":")
+		args[i], _ = strconv.Atoi(field)
+	}
+	return args
+}
+
+func (s *terminalScreen) put(r rune) {
+	if s.wrapPending {
+		s.col = 0
+		s.lineFeed()
+	}
+	s.lines()[s.row][s.col] = r
+	if s.col == s.columns-1 {
+		s.wrapPending = true
+	} else {
+		s.col++
+	}
+}
+
+func (s *terminalScreen) lineFeed() {
+	s.wrapPending = false
+	switch {
+	case s.row == s.scrollBottom:
+		s.scrollUp(s.scrollTop, s.scrollBottom, 1)
+	case s.row < s.rows-1:
+		s.row++
+	}
+}
+
+func (s *terminalScreen) reverseIndex() {
+	s.wrapPending = false
+	switch {
+	case s.row == s.scrollTop:
+		s.scrollDown(s.scrollTop, s.scrollBottom, 1)
+	case s.row > 0:
+		s.row--
+	}
+}
+
+// scrollUp moves lines top to bottom up by n, blanking the lines at the bottom.
+func (s *terminalScreen) scrollUp(top, bottom, n int) {
+	lines := s.lines()
+	n = min(n, bottom-top+1)
+	copy(lines[top:bottom+1], lines[top+n:bottom+1])
+	for i := bottom - n + 1; i <= bottom; i++ {
+		lines[i] = blankLine(s.columns)
+	}
+}
+
+// scrollDown moves lines top to bottom down by n, blanking the lines at the top.
+func (s *terminalScreen) scrollDown(top, bottom, n int) {
+	lines := s.lines()
+	n = min(n, bottom-top+1)
+	copy(lines[top+n:bottom+1], lines[top:bottom+1-n])
+	for i := top; i < top+n; i++ {
+		lines[i] = blankLine(s.columns)
+	}
+}
+
+func (s *terminalScreen) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		s.clear(s.row, s.col, s.columns)
+		for i := s.row + 1; i < s.rows; i++ {
+			s.clear(i, 0, s.columns)
+		}
+	case 1:
+		for i := 0; i < s.row; i++ {
+			s.clear(i, 0, s.columns)
+		}
+		s.clear(s.row, 0, s.col+1)
+	case 2, 3:
+		for i := 0; i < s.rows; i++ {
+			s.clear(i, 0, s.columns)
+		}
+	}
+}
+
+func (s *terminalScreen) eraseLine(mode int) {
+	switch mode {
+	case 0:
+		s.clear(s.row, s.col, s.columns)
+	case 1:
+		s.clear(s.row, 0, s.col+1)
+	case 2:
+		s.clear(s.row, 0, s.columns)
+	}
+}
+
+// clear blanks the columns from to to of row.
+func (s *terminalScreen) clear(row, from, to int) {
+	line := s.lines()[row]
+	for i := from; i < to && i < s.columns; i++ {
+		line[i] = ' '
+	}
+}
+
+func (s *terminalScreen) saveCursor() {
+	s.savedRow, s.savedCol = s.row, s.col
+}
+
+func (s *terminalScreen) restoreCursor() {
+	s.row, s.col = s.savedRow, s.savedCol
+	s.wrapPending = false
+}
+
+// setAltScreen switches to the alternate screen, which starts out blank, or
+// back to the main screen. Mode 1049 saves and restores the cursor as well.
+func (s *terminalScreen) setAltScreen(on, withCursor bool) {
+	if on == s.altActive {
+		return
+	}
+	if on {
+		if withCursor {
+			s.saveCursor()
+		}
+		s.alt = newScreenBuffer(s.columns, s.rows)
+		s.altActive = true
+		return
+	}
+	s.altActive = false
+	if withCursor {
+		s.restoreCursor()
+	}
+}
+
+// String returns the text on the screen, without trailing spaces and
+// trailing blank lines.
+func (s *terminalScreen) String() string {
+	lines := make([]string, 0, s.rows)
+	for _, line := range s.lines() {
+		lines = append(lines, strings.TrimRight(string(line), " "))
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
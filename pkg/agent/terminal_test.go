@@ -0,0 +1,195 @@
+package agent
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerminalScreen(t *testing.T) {
+	tests := map[string]struct {
+		columns, rows int
+		output        string
+		want          string
+	}{
+		"plain lines": {
+			output: "hello\r\nworld\r\n",
+			want:   "hello\nworld\n",
+		},
+		"carriage return overwrites": {
+			output: "loading...\rdone      ",
+			want:   "done\n",
+		},
+		"colors are dropped": {
+			output: "\x1b[1;31mError:\x1b[0m quota exceeded",
+			want:   "Error: quota exceeded\n",
+		},
+		"cursor position and erase line": {
+			output: "first\r\nsecond\x1b[1;1Hlast\x1b[K",
+			want:   "last\nsecond\n",
+		},
+		"erase display": {
+			output: "spinner\x1b[2J\x1b[HError: not logged in",
+			want:   "Error: not logged in\n",
+		},
+		"wraps long lines": {
+			columns: 5,
+			output:  "abcdefgh",
+			want:    "abcde\nfgh\n",
+		},
+		"scrolls": {
+			rows:   2,
+			output: "one\r\ntwo\r\nthree",
+			want:   "two\nthree\n",
+		},
+		"scroll region": {
+			rows:   3,
+			output: "header\x1b[2;3r\x1b[2;1Ha\r\nb\r\nc",
+			want:   "header\nb\nc\n",
+		},
+		"alternate screen is shown until left": {
+			output: "shell\x1b[?1049h\x1b[HTUI frame: Error 401",
+			want:   "TUI frame: Error 401\n",
+		},
+		"leaving the alternate screen restores the main screen": {
+			output: "shell\x1b[?1049hTUI\x1b[?1049l",
+			want:   "shell\n",
+		},
+		"window title is skipped": {
+			output: "\x1b]0;agent\x07ready",
+			want:   "ready\n",
+		},
+		"utf-8": {
+			output: "✗ failed",
+			want:   "✗ failed\n",
+		},
+		"empty": {
+			output: "\x1b[2J",
+			want:   "",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			columns, rows := tc.columns, tc.rows
+			if columns == 0 {
+				columns = 80
+			}
+			if rows == 0 {
+				rows = 10
+			}
+			screen := newTerminalScreen(columns, rows)
+			// Write byte by byte, so that sequences are split across writes
+			for _, b := range []byte(tc.output) {
+				_, _ = screen.Write([]byte{b})
+			}
+			assert.Equal(t, tc.want, screen.String())
+		})
+	}
+}
+
+func TestAsciicastRecorder(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	recorder := newAsciicastRecorder(80, 24, start)
+	recorder.now = func() time.Time { return start.Add(1500 * time.Millisecond) }
+
+	check := []byte("✓")
+	_, _ = recorder.Write(append([]byte("ok "), check[:1]...))
+	_, _ = recorder.Write(append(check[1:], "\r\n"...))
+
+	assert.Equal(t, `{"version":2,"width":80,"height":24,"timestamp":1700000000}
+[1.500000, "o", "ok "]
+[1.500000, "o", "✓\r\n"]
+`, string(recorder.Bytes()))
+}
+
+func TestRunInTerminal(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("pseudo-terminals are not supported on " + runtime.GOOS)
+	}
+
+	cmd := exec.Command("sh", "-c", `test -t 1 && printf 'progress\r\033[KError: \033[31mrate limited\033[0m\n'; exit 3`)
+	run, err := runInTerminal(cmd, &TerminalConfig{Record: true}, nil)
+
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 3, exitErr.ExitCode())
+	assert.Equal(t, "Error: rate limited\n", run.screen)
+	assert.Contains(t, string(run.output), "rate limited")
+	assert.True(t, strings.HasPrefix(string(run.recording), `{"version":2,"width":120,"height":40,`))
+}
+
+func TestTerminalConfigValidate(t *testing.T) {
+	tests := map[string]struct {
+		config  *TerminalConfig
+		wantErr string
+	}{
+		"nil":           {},
+		"defaults":      {config: &TerminalConfig{}},
+		"size":          {config: &TerminalConfig{Columns: 200, Rows: 50}},
+		"negative":      {config: &TerminalConfig{Columns: -1}, wantErr: "commands.terminal.columns"},
+		"too many rows": {config: &TerminalConfig{Rows: 70000}, wantErr: "commands.terminal.rows"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRunInTerminalSize(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("pseudo-terminals are not supported on " + runtime.GOOS)
+	}
+
+	cmd := exec.Command("sh", "-c", "stty size")
+	run, err := runInTerminal(cmd, &TerminalConfig{Columns: 100, Rows: 30}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "30 100\n", plainTerminalOutput(run.output))
+}
+
+func TestTerminalEnv(t *testing.T) {
+	environ := []string{"PATH=/usr/bin", "TERM=dumb", "COLUMNS=80"}
+
+	tests := map[string]struct {
+		config *TerminalConfig
+		policy *EnvPolicy
+		want   []string
+	}{
+		"defaults": {
+			config: &TerminalConfig{},
+			want:   []string{"PATH=/usr/bin", "TERM=xterm-256color", "COLUMNS=120", "LINES=40"},
+		},
+		"configured": {
+			config: &TerminalConfig{Columns: 200, Rows: 50, Term: "screen"},
+			want:   []string{"PATH=/usr/bin", "TERM=screen", "COLUMNS=200", "LINES=50"},
+		},
+		"set by the env policy": {
+			config: &TerminalConfig{},
+			policy: &EnvPolicy{Set: map[string]string{"TERM": "dumb"}},
+			want:   []string{"PATH=/usr/bin", "TERM=dumb", "COLUMNS=120", "LINES=40"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, terminalEnv(environ, tc.config, tc.policy))
+		})
+	}
+}
+
+func TestPlainTerminalOutput(t *testing.T) {
+	output := "\x1b]0;agent\x07\x1b[?25l\x1b[1;32m✓\x1b[0m done\r\n\x1b(BError: \x1b[38;5;196mdenied\x1b[m\r\n\x1b=\x1b[?25h"
+	assert.Equal(t, "✓ done\nError: denied\n", plainTerminalOutput([]byte(output)))
+}
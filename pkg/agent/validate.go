@@ -0,0 +1,196 @@
+package agent
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// shellBuiltins are the builtins and keywords of POSIX shells that runPrompt
+// may start with. They are not looked up in PATH.
+var shellBuiltins = map[string]bool{
+	"!": true, ".": true, ":": true, "[": true, "[[": true, "{": true, "(": true,
+	"case": true, "cd": true, "command": true, "echo": true, "eval": true,
+	"exec": true, "export": true, "false": true, "for": true, "function": true,
+	"if": true, "local": true, "printf": true, "read": true, "set": true,
+	"source": true, "test": true, "trap": true, "true": true, "until": true,
+	"while": true,
+}
+
+// validateCommands checks the command templates of agents that run through
+// commands.runPrompt by executing them with placeholder data, so that template
+// errors or an unusable allowedToolsJoinSeparator are reported when the agent
+// is loaded instead of when the first task runs. Whether the agent binary is
+// installed is checked when its runner is created, see checkAgentInstalled,
+// so that agent files can be loaded on machines without the agent.
+func validateCommands(spec *AgentSpec) error {
+	if !usesShellCommands(spec) {
+		return nil
+	}
+	_, err := formatPlaceholderCommand(spec)
+	return err
+}
+
+// checkAgentInstalled checks that the program the runPrompt command of spec
+// starts with exists.
+func checkAgentInstalled(spec *AgentSpec) error {
+	// Builtins and syntax of other shells are not known, so only POSIX
+	// commands are checked
+	if util.DefaultShell().Kind != util.ShellPosix {
+		return nil
+	}
+	command, err := formatPlaceholderCommand(spec)
+	if err != nil {
+		return err
+	}
+	return checkCommandInstalled(command)
+}
+
+// formatPlaceholderCommand executes the command templates of spec with
+// placeholder data and returns the formatted runPrompt command.
+func formatPlaceholderCommand(spec *AgentSpec) (string, error) {
+	cmds := spec.Commands
+	if strings.TrimSpace(cmds.RunPrompt) == "" {
+		return "", fmt.Errorf("commands.runPrompt is required for agents without acp or builtin configuration")
+	}
+
+	mcpServerArg, err := executeCommandTemplate("argTemplateMcpServer", cmds.ArgTemplateMcpServer, mcpServerTemplateData{
+		File: "/tmp/mcp-server.json",
+		URL:  "http://localhost:8080/mcp",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	allowedToolArg, err := executeCommandTemplate("argTemplateAllowedTools", cmds.ArgTemplateAllowedTools, allowedToolTemplateData{
+		ServerName: "server",
+		ToolName:   "tool",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	separator := " "
+	if cmds.AllowedToolsJoinSeparator != nil {
+		separator = *cmds.AllowedToolsJoinSeparator
+		if err := validateSeparator(separator, allowedToolArg); err != nil {
+			return "", err
+		}
+	}
+
+	return executeCommandTemplate("runPrompt", cmds.RunPrompt, runPromptTemplateData{
+		McpServerFileArgs: mcpServerArg,
+		AllowedToolArgs:   strings.Join([]string{allowedToolArg, allowedToolArg}, separator),
+		Prompt:            "prompt",
+		Model:             spec.Model,
+		ExtraArgs:         util.DefaultShell().QuoteArgs(spec.ExtraArgs),
+	})
+}
+
+// usesShellCommands reports whether spec is run by the shell runner, see
+// NewRunnerForSpec.
+func usesShellCommands(spec *AgentSpec) bool {
+	if spec.AcpConfig != nil || usesBuiltinModel(spec) {
+		return false
+	}
+	return spec.Builtin == nil || spec.Builtin.Type != "claude-code"
+}
+
+// executeCommandTemplate parses and executes the command template called name
+// with data, the way RunTask does.
+func executeCommandTemplate(name, text string, data any) (string, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse commands.%s: %w", name, err)
+	}
+
+	formatted := bytes.NewBuffer(nil)
+	if err := tmpl.Execute(formatted, data); err != nil {
+		return "", fmt.Errorf("failed to execute commands.%s: %w", name, err)
+	}
+	return formatted.String(), nil
+}
+
+// validateSeparator checks that separator keeps the allowed tools apart in
+// the command line, given toolArg, a formatted allowed tool.
+func validateSeparator(separator, toolArg string) error {
+	if strings.ContainsAny(separator, "\r\n") {
+		return fmt.Errorf("commands.allowedToolsJoinSeparator must not contain line breaks, got %q", separator)
+	}
+	if separator != "" || toolArg == "" {
+		return nil
+	}
+
+	first, _ := utf8.DecodeRuneInString(toolArg)
+	last, _ := utf8.DecodeLastRuneInString(toolArg)
+	if isWordRune(first) && isWordRune(last) {
+		return fmt.Errorf("commands.allowedToolsJoinSeparator is empty, so allowed tools formatted as %q would run together", toolArg)
+	}
+	return nil
+}
+
+// checkCommandInstalled checks that the program command starts with exists.
+func checkCommandInstalled(command string) error {
+	name := commandName(command)
+	if name == "" {
+		return nil
+	}
+
+	if strings.Contains(name, "/") {
+		// Relative paths are resolved in the working directory of the agent
+		if !filepath.IsAbs(name) {
+			return nil
+		}
+		if _, err := os.Stat(name); err != nil {
+			return fmt.Errorf("commands.runPrompt runs %q, which does not exist: %w", name, err)
+		}
+		return nil
+	}
+
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("commands.runPrompt runs %q, which was not found in PATH", name)
+	}
+	return nil
+}
+
+// commandName returns the program the shell command starts with, skipping
+// variable assignments. It returns "" if the command starts with a shell
+// builtin, a comment or an expansion, which can't be checked before running it.
+func commandName(command string) string {
+	for _, field := range strings.Fields(command) {
+		if isEnvAssignment(field) {
+			continue
+		}
+		if shellBuiltins[field] || strings.HasPrefix(field, "#") || strings.ContainsAny(field, "$`'\"\\(){}<>|;&*?~") {
+			return ""
+		}
+		return field
+	}
+	return ""
+}
+
+// isEnvAssignment reports whether field is a NAME=value variable assignment.
+func isEnvAssignment(field string) bool {
+	name, _, ok := strings.Cut(field, "=")
+	if !ok || name == "" || unicode.IsDigit(rune(name[0])) {
+		return false
+	}
+	for _, r := range name {
+		if r != '_' && !isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
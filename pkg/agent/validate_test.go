@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/acpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCommands(t *testing.T) {
+	empty := ""
+	comma := ","
+	newline := "\n"
+
+	tests := map[string]struct {
+		spec        *AgentSpec
+		errContains string
+	}{
+		"shell builtin": {
+			spec: &AgentSpec{Commands: AgentCommands{
+				ArgTemplateAllowedTools: "{{ .ToolName }}",
+				RunPrompt:               "echo {{ .AllowedToolArgs }} {{ .Prompt }}",
+			}},
+		},
+		"script with variable assignments": {
+			spec: &AgentSpec{Commands: AgentCommands{
+				RunPrompt: "set -e\nPROMPT={{ printf \"%q\" .Prompt }}\nagent \"$PROMPT\"",
+			}},
+		},
+		"missing binary is only checked when running": {
+			spec: &AgentSpec{Commands: AgentCommands{
+				RunPrompt: "mcpchecker-no-such-agent {{ .Prompt }}",
+			}},
+		},
+		"empty runPrompt": {
+			spec:        &AgentSpec{},
+			errContains: "commands.runPrompt is required",
+		},
+		"template parse error": {
+			spec: &AgentSpec{Commands: AgentCommands{
+				ArgTemplateMcpServer: "{{ .File",
+				RunPrompt:            "echo {{ .Prompt }}",
+			}},
+			errContains: "failed to parse commands.argTemplateMcpServer",
+		},
+		"unknown template field": {
+			spec: &AgentSpec{Commands: AgentCommands{
+				ArgTemplateAllowedTools: "{{ .Tool }}",
+				RunPrompt:               "echo {{ .Prompt }}",
+			}},
+			errContains: "failed to execute commands.argTemplateAllowedTools",
+		},
+		"comma separator": {
+			spec: &AgentSpec{Commands: AgentCommands{
+				ArgTemplateAllowedTools:   "{{ .ToolName }}",
+				AllowedToolsJoinSeparator: &comma,
+				RunPrompt:                 "echo {{ .AllowedToolArgs }}",
+			}},
+		},
+		"empty separator with delimited tools": {
+			spec: &AgentSpec{Commands: AgentCommands{
+				ArgTemplateAllowedTools:   "{{ .ToolName }},",
+				AllowedToolsJoinSeparator: &empty,
+				RunPrompt:                 "echo {{ .AllowedToolArgs }}",
+			}},
+		},
+		"empty separator": {
+			spec: &AgentSpec{Commands: AgentCommands{
+				ArgTemplateAllowedTools:   "{{ .ToolName }}",
+				AllowedToolsJoinSeparator: &empty,
+				RunPrompt:                 "echo {{ .AllowedToolArgs }}",
+			}},
+			errContains: "would run together",
+		},
+		"separator with line break": {
+			spec: &AgentSpec{Commands: AgentCommands{
+				ArgTemplateAllowedTools:   "{{ .ToolName }}",
+				AllowedToolsJoinSeparator: &newline,
+				RunPrompt:                 "echo {{ .AllowedToolArgs }}",
+			}},
+			errContains: "must not contain line breaks",
+		},
+		"acp agent is not validated": {
+			spec: &AgentSpec{AcpConfig: &acpclient.AcpConfig{Cmd: "mcpchecker-no-such-agent"}},
+		},
+		"claude code builtin is not validated": {
+			spec: &AgentSpec{Builtin: &BuiltinRef{Type: "claude-code"}},
+		},
+		"llm-agent is not validated": {
+			spec: &AgentSpec{Builtin: &BuiltinRef{Type: "llm-agent", Model: "openai:gpt-4o"}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateCommands(tc.spec)
+			if tc.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errContains)
+		})
+	}
+}
+
+func TestCheckAgentInstalled(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+
+	binary := filepath.Join(t.TempDir(), "agent")
+	require.NoError(t, os.WriteFile(binary, []byte("#!/bin/sh\n"), 0o755))
+
+	tests := map[string]struct {
+		runPrompt   string
+		errContains string
+	}{
+		"shell builtin": {
+			runPrompt: "echo {{ .Prompt }}",
+		},
+		"script with variable assignments": {
+			runPrompt: "set -e\nPROMPT={{ printf \"%q\" .Prompt }}\nagent \"$PROMPT\"",
+		},
+		"absolute binary path": {
+			runPrompt: binary + " --prompt {{ .Prompt }}",
+		},
+		"environment assignment before binary": {
+			runPrompt: "DEBUG=1 " + binary + " {{ .Prompt }}",
+		},
+		"missing absolute binary": {
+			runPrompt:   binary + "-missing {{ .Prompt }}",
+			errContains: "which does not exist",
+		},
+		"missing binary in PATH": {
+			runPrompt:   "DEBUG=1 mcpchecker-no-such-agent {{ .Prompt }}",
+			errContains: `runs "mcpchecker-no-such-agent", which was not found in PATH`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := checkAgentInstalled(&AgentSpec{Commands: AgentCommands{RunPrompt: tc.runPrompt}})
+			if tc.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errContains)
+		})
+	}
+}
+
+func TestCommandName(t *testing.T) {
+	assert.Equal(t, "claude", commandName("claude --print hello"))
+	assert.Equal(t, "claude", commandName("  FOO=bar BAZ=1 claude --print hello"))
+	assert.Equal(t, "./agent", commandName("./agent"))
+	assert.Empty(t, commandName("set -euo pipefail\nclaude"))
+	assert.Empty(t, commandName("# run the agent\nclaude"))
+	assert.Empty(t, commandName("$AGENT --print hello"))
+	assert.Empty(t, commandName("\"${AGENT}\" --print hello"))
+	assert.Empty(t, commandName(""))
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/spf13/cobra"
+)
+
+// NewAblationCmd creates the ablation command
+func NewAblationCmd() *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:   "ablation <results-file>",
+		Short: "Compare task runs with required tools to runs with the full tool catalogue",
+		Long: `Compare the variants of each task of a run made with 'mcpchecker check --catalogue-ablation'.
+
+For every task with toolsUsed or requireAny assertions, shows the pass rate and the
+average token usage of the runs where the agent was only allowed the tools of the
+assertions ("required") and of the runs where it was allowed every tool ("full"),
+and the change from required to full.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			evalResults, err := results.Load(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to load results file: %w", err)
+			}
+
+			deltas := results.CatalogueAblation(evalResults)
+
+			switch outputFormat {
+			case "json":
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(deltas)
+			case "text":
+				outputTextAblation(deltas)
+			default:
+				return fmt.Errorf("unknown output format: %s", outputFormat)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, json)")
+
+	return cmd
+}
+
+// printCatalogueAblation prints the catalogue ablation of the results of a run
+// made by the check command.
+func printCatalogueAblation(evalResults []*eval.EvalResult) {
+	fmt.Println()
+	outputTextAblation(results.CatalogueAblation(evalResults))
+}
+
+// outputTextAblation prints the pass rate and token changes of each task from
+// its required tools variant to its full catalogue variant. Used by both the
+// check and ablation commands.
+func outputTextAblation(deltas []results.CatalogueDelta) {
+	bold := color.New(color.Bold)
+
+	_, _ = bold.Println("=== Tool Catalogue Ablation ===")
+	fmt.Println()
+
+	if len(deltas) == 0 {
+		fmt.Println("No tasks were run with both required tools and the full catalogue.")
+		return
+	}
+
+	fmt.Printf("%-32s %-11s %-11s %s\n", "", "Required", "Full", "Change")
+	for _, d := range deltas {
+		_, _ = bold.Println(d.TaskName)
+
+		fmt.Printf("  %-30s %d/%-9d %d/%-9d ", "Pass rate:",
+			d.Required.Passed, d.Required.Runs, d.Full.Passed, d.Full.Runs)
+		printChange(os.Stdout, d.PassRateDelta)
+
+		fmt.Printf("  %-30s %-11s %-11s ", "Avg tokens:",
+			formatTokenCountOrNA(d.Required.AvgTokens, d.Required.TasksWithTokens),
+			formatTokenCountOrNA(d.Full.AvgTokens, d.Full.TasksWithTokens))
+		if d.Required.TasksWithTokens == 0 || d.Full.TasksWithTokens == 0 {
+			fmt.Println("N/A")
+		} else if d.TokensDelta > 0 {
+			fmt.Printf("+%s\n", formatTokenCount(d.TokensDelta))
+		} else {
+			fmt.Println(formatTokenCount(d.TokensDelta))
+		}
+	}
+	fmt.Println()
+}
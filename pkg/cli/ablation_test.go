@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+func TestAblationCommand(t *testing.T) {
+	evalResults := []*eval.EvalResult{
+		{TaskName: "list-pods", TaskPassed: true, AllAssertionsPassed: true, AllowedToolsMode: eval.AllowedToolsAssertions},
+		{TaskName: "list-pods", AllowedToolsMode: eval.AllowedToolsAll},
+	}
+	resultsFile := createTestResultsFile(t, evalResults)
+
+	for _, format := range []string{"text", "json"} {
+		cmd := NewAblationCmd()
+		cmd.SetArgs([]string{resultsFile, "--output", format})
+		cmd.SetOut(new(bytes.Buffer))
+
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("ablation command with --output %s failed: %v", format, err)
+		}
+	}
+}
+
+func TestAblationCommandUnknownFormat(t *testing.T) {
+	resultsFile := createTestResultsFile(t, sampleResults())
+
+	cmd := NewAblationCmd()
+	cmd.SetArgs([]string{resultsFile, "--output", "markdown"})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error for an unknown output format")
+	}
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// outputTextAttempts prints the runs of each task that ran more than once,
+// with its pass@1 and pass@k and whether it is flaky, followed by the
+// averages. Used by the check, summary and view commands.
+func outputTextAttempts(w io.Writer, summary *eval.AttemptsSummary) {
+	green := color.New(color.FgGreen)
+	red := color.New(color.FgRed)
+	yellow := color.New(color.FgYellow)
+
+	passAtK := fmt.Sprintf("pass@%d", summary.K)
+	fmt.Fprintf(w, "%-40s %-12s %-10s %-8s %s\n", "Task", "Passed", "Runs", "pass@1", passAtK)
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+
+	for _, t := range summary.Tasks {
+		name := t.TaskName
+		if t.Environment != "" {
+			name += "@" + t.Environment
+		}
+
+		passed := fmt.Sprintf("%d/%d", t.Passed, len(t.Outcomes))
+		fmt.Fprintf(w, "%-40s ", name)
+		switch {
+		case t.Flaky:
+			_, _ = yellow.Fprintf(w, "%-12s ", passed)
+		case t.Passed == 0:
+			_, _ = red.Fprintf(w, "%-12s ", passed)
+		default:
+			_, _ = green.Fprintf(w, "%-12s ", passed)
+		}
+		fmt.Fprintf(w, "%-10s %-8s %-6s", formatOutcomes(t.Outcomes), formatRate(t.PassAt1), formatRate(t.PassAtK))
+		if t.Flaky {
+			_, _ = yellow.Fprint(w, " flaky")
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "pass@1: %s, %s: %s, flakiness: %s (%d/%d tasks flaky)\n",
+		formatRate(summary.PassAt1), passAtK, formatRate(summary.PassAtK),
+		formatRate(summary.FlakinessRate), summary.FlakyTasks, len(summary.Tasks))
+}
+
+// formatOutcomes formats the outcomes of the runs of a task, e.g. "✓✗✓".
+func formatOutcomes(outcomes []bool) string {
+	var b strings.Builder
+	for _, passed := range outcomes {
+		if passed {
+			b.WriteString("✓")
+		} else {
+			b.WriteString("✗")
+		}
+	}
+	return b.String()
+}
+
+func formatRate(rate float64) string {
+	return fmt.Sprintf("%.1f%%", rate*100)
+}
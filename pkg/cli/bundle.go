@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/spf13/cobra"
+)
+
+// NewBundleCmd creates the bundle command
+func NewBundleCmd() *cobra.Command {
+	var output string
+	var include []string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "bundle <eval-config-file>",
+		Short: "Pack an eval and the files it references into a portable bundle",
+		Long: `Pack an eval config and every file it references into a single gzipped
+tarball, which another machine can run with 'mcpchecker check bundle.tgz'.
+
+The bundle contains the eval config, its MCP config, agent and judge files,
+step libraries, skills and lockfile, extensions that are files relative to the
+eval config, and the task files of its task sets with their prompt, reply and
+script files. A manifest records the hash of each file, which is checked when
+the bundle is run, the packages of the extensions and the lockfile.
+
+Files are stored relative to the closest directory that contains all of them,
+so references between them must be relative paths. Files that scripts read
+on their own, such as helpers they source, are not found: add them with
+--include. Tasks of git sources and extension packages are fetched when the
+bundle runs, as pinned by the lockfile. Secrets are not bundled; the eval
+config keeps its secret references.
+
+Example:
+  mcpchecker bundle eval.yaml
+  mcpchecker bundle eval.yaml -o k8s-eval.tgz --include scripts/lib`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			var buf bytes.Buffer
+			manifest, err := eval.WriteBundle(&buf, args[0], include, version())
+			if err != nil {
+				return fmt.Errorf("failed to bundle eval: %w", err)
+			}
+			if output == "" {
+				output = manifest.Name + ".tgz"
+			}
+
+			if dryRun {
+				for _, file := range manifest.Files {
+					fmt.Fprintln(out, file.Path)
+				}
+				fmt.Fprintf(out, "\nWould write %d file(s) to %s\n", len(manifest.Files), output)
+				return nil
+			}
+
+			if dir := filepath.Dir(output); dir != "." {
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					return fmt.Errorf("failed to create directory for %s: %w", output, err)
+				}
+			}
+			if err := os.WriteFile(output, buf.Bytes(), 0644); err != nil {
+				return fmt.Errorf("failed to write bundle: %w", err)
+			}
+			fmt.Fprintf(out, "Bundled %d file(s) into %s\n", len(manifest.Files), output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Path of the bundle (default: <eval-name>.tgz)")
+	cmd.Flags().StringArrayVar(&include, "include", nil, "Extra file or directory to bundle (repeatable)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the files that would be bundled without writing the bundle")
+
+	return cmd
+}
+
+// extractBundle extracts the bundle at path into a temporary directory and
+// returns the path of its eval config and a function that removes the
+// directory.
+func extractBundle(path string) (string, func(), error) {
+	dir, err := os.MkdirTemp("", "mcpchecker-bundle-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+
+	manifest, err := eval.ExtractBundle(path, dir)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	if v := version(); manifest.MCPCheckerVersion != "" && manifest.MCPCheckerVersion != v {
+		fmt.Fprintf(os.Stderr, "Note: bundle %s was created by mcpchecker %s, running with %s\n", path, manifest.MCPCheckerVersion, v)
+	}
+	return filepath.Join(dir, filepath.FromSlash(manifest.Eval)), cleanup, nil
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBundleCmd(t *testing.T) {
+	dir := writeMigrateFiles(t, map[string]string{
+		"eval.yaml": `kind: Eval
+metadata:
+  name: bundle-cmd
+config:
+  taskSets:
+    - path: tasks/task.yaml
+`,
+		"tasks/task.yaml": currentTask,
+	})
+	bundlePath := filepath.Join(dir, "out", "bundle.tgz")
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := NewBundleCmd()
+		cmd.SetArgs(args)
+		out := new(bytes.Buffer)
+		cmd.SetOut(out)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("bundle failed: %v", err)
+		}
+		return out.String()
+	}
+
+	out := run(filepath.Join(dir, "eval.yaml"), "-o", bundlePath, "--dry-run")
+	if !strings.Contains(out, "eval.yaml\ntasks/task.yaml\n") || !strings.Contains(out, "Would write 2 file(s)") {
+		t.Errorf("unexpected dry run output:\n%s", out)
+	}
+	if _, err := os.Stat(bundlePath); !os.IsNotExist(err) {
+		t.Errorf("dry run wrote the bundle")
+	}
+
+	out = run(filepath.Join(dir, "eval.yaml"), "-o", bundlePath)
+	if !strings.Contains(out, "Bundled 2 file(s) into "+bundlePath) {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	configFile, cleanup, err := extractBundle(bundlePath)
+	if err != nil {
+		t.Fatalf("failed to extract bundle: %v", err)
+	}
+	extractedDir := filepath.Dir(configFile)
+	if _, err := os.Stat(filepath.Join(extractedDir, "tasks", "task.yaml")); err != nil {
+		t.Errorf("bundle is missing the task: %v", err)
+	}
+	cleanup()
+	if _, err := os.Stat(extractedDir); !os.IsNotExist(err) {
+		t.Errorf("cleanup left %s behind", extractedDir)
+	}
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/resolver"
+	"github.com/spf13/cobra"
+)
+
+// NewCacheCmd creates the cache parent command
+func NewCacheCmd() *cobra.Command {
+	var cacheDir string
+
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Commands for managing the shared extension binary cache",
+		Long: `Commands for managing the shared extension binary cache.
+
+Extension binaries of specific versions are downloaded once and kept in the
+user cache directory ($XDG_CACHE_HOME/mcpchecker/extensions on Linux), where
+every mcpchecker run of the user, including parallel ones, reuses them. The
+Sigstore signature of each binary is verified on every run, and a cached binary
+is only used when it matches the verified one.`,
+	}
+
+	cacheCmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", "", "Cache directory (defaults to the user cache directory)")
+
+	cacheCmd.AddCommand(newCacheListCmd(&cacheDir))
+	cacheCmd.AddCommand(newCacheCleanCmd(&cacheDir))
+
+	return cacheCmd
+}
+
+func newCacheListCmd(cacheDir *string) *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:          "list",
+		Short:        "List the cached extension binaries",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, err := resolver.NewBinaryCache(*cacheDir)
+			if err != nil {
+				return err
+			}
+
+			entries, err := cache.List()
+			if err != nil {
+				return err
+			}
+
+			switch outputFormat {
+			case "json":
+				if entries == nil {
+					entries = []resolver.CacheEntry{}
+				}
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(entries)
+			case "text":
+				outputTextCacheEntries(cmd.OutOrStdout(), cache.Dir, entries)
+				return nil
+			default:
+				return fmt.Errorf("unknown output format: %s", outputFormat)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, json)")
+
+	return cmd
+}
+
+func newCacheCleanCmd(cacheDir *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "clean [package[@version]...]",
+		Short: "Remove cached extension binaries",
+		Long: `Remove cached extension binaries.
+
+Without arguments the whole cache is removed. Otherwise only the binaries and
+downloaded release archives of the given packages, such as github.com/owner/repo
+or github.com/owner/repo@v1.0.0, are removed.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, err := resolver.NewBinaryCache(*cacheDir)
+			if err != nil {
+				return err
+			}
+
+			removed, err := cache.Clean(args...)
+			if err != nil {
+				return err
+			}
+
+			var size int64
+			for _, e := range removed {
+				size += e.Size
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Removed %d cached binaries (%s)\n", len(removed), formatBytes(size))
+			return nil
+		},
+	}
+}
+
+func outputTextCacheEntries(w io.Writer, dir string, entries []resolver.CacheEntry) {
+	if len(entries) == 0 {
+		_, _ = fmt.Fprintf(w, "No cached binaries in %s\n", dir)
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "PACKAGE\tVERSION\tPLATFORM\tSIZE\tSHA256\tCACHED")
+	for _, e := range entries {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.12s\t%s\n",
+			e.Package, e.Version, e.Platform, formatBytes(e.Size), e.SHA256, e.CachedAt.Local().Format("2006-01-02 15:04"))
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/resolver"
+)
+
+func createTestCache(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	src := filepath.Join(t.TempDir(), "myext")
+	if err := os.WriteFile(src, []byte("binary"), 0o755); err != nil {
+		t.Fatalf("failed to write binary: %v", err)
+	}
+
+	cache := &resolver.BinaryCache{Dir: dir}
+	for _, version := range []string{"v1.0.0", "v2.0.0"} {
+		if _, err := cache.Put("github.com/myorg/myext", version, "linux-amd64", src); err != nil {
+			t.Fatalf("failed to cache binary: %v", err)
+		}
+	}
+	return dir
+}
+
+func runCacheCmd(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	cmd := NewCacheCmd()
+	cmd.SetArgs(args)
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(new(bytes.Buffer))
+
+	err := cmd.Execute()
+	return buf.String(), err
+}
+
+func TestCacheListCommand(t *testing.T) {
+	dir := createTestCache(t)
+
+	out, err := runCacheCmd(t, "list", "--cache-dir", dir)
+	if err != nil {
+		t.Fatalf("cache list failed: %v", err)
+	}
+	if !strings.Contains(out, "github.com/myorg/myext") || !strings.Contains(out, "v2.0.0") {
+		t.Errorf("expected cached binaries in output, got:\n%s", out)
+	}
+
+	out, err = runCacheCmd(t, "list", "--cache-dir", dir, "-o", "json")
+	if err != nil {
+		t.Fatalf("cache list -o json failed: %v", err)
+	}
+	var entries []resolver.CacheEntry
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected 2 entries, got %d", len(entries))
+	}
+}
+
+func TestCacheListCommandEmpty(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing")
+
+	out, err := runCacheCmd(t, "list", "--cache-dir", dir)
+	if err != nil {
+		t.Fatalf("cache list failed: %v", err)
+	}
+	if !strings.Contains(out, "No cached binaries") {
+		t.Errorf("expected empty cache message, got:\n%s", out)
+	}
+
+	out, err = runCacheCmd(t, "list", "--cache-dir", dir, "-o", "json")
+	if err != nil {
+		t.Fatalf("cache list -o json failed: %v", err)
+	}
+	if strings.TrimSpace(out) != "[]" {
+		t.Errorf("expected an empty JSON array, got %q", out)
+	}
+}
+
+func TestCacheCleanCommand(t *testing.T) {
+	dir := createTestCache(t)
+
+	out, err := runCacheCmd(t, "clean", "--cache-dir", dir, "github.com/myorg/myext@v1.0.0")
+	if err != nil {
+		t.Fatalf("cache clean failed: %v", err)
+	}
+	if !strings.Contains(out, "Removed 1 cached binaries") {
+		t.Errorf("unexpected output: %s", out)
+	}
+
+	entries, err := (&resolver.BinaryCache{Dir: dir}).List()
+	if err != nil {
+		t.Fatalf("failed to list cache: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Version != "v2.0.0" {
+		t.Errorf("expected only v2.0.0 to remain, got %+v", entries)
+	}
+
+	if _, err := runCacheCmd(t, "clean", "--cache-dir", dir); err != nil {
+		t.Fatalf("cache clean failed: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected cache directory to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/spf13/cobra"
+)
+
+// NewCalibrateCmd creates the calibrate command
+func NewCalibrateCmd() *cobra.Command {
+	var labelsFile string
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:   "calibrate --labels <labels-file> <results-file>",
+		Short: "Compare LLM judge verdicts to human labels",
+		Long: `Compare the pass/fail verdicts of the LLM judge in a results file to the
+verdicts of humans, to measure how far the judge can be trusted before using it
+to gate runs.
+
+Reports the agreement of the judge with the labels, its precision and recall
+for passing and failing verdicts, the confusion of its verdicts by failure
+category, and the runs it disagrees on.
+
+The labels file lists a verdict per task, or per run of a task:
+
+  labels:
+    - task: check-nginx     # task ID or name
+      passed: true
+    - task: scale-deploy
+      run: 1                # 0-indexed run, overrides the task label
+      passed: false
+      note: the replica count is wrong
+
+Example:
+  mcpchecker calibrate --labels labels.yaml mcpchecker-k8s-out.json`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			evalResults, err := results.Load(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to load results file: %w", err)
+			}
+
+			labels, err := results.LoadJudgeLabels(labelsFile)
+			if err != nil {
+				return err
+			}
+
+			calibration := results.Calibrate(evalResults, labels)
+
+			switch outputFormat {
+			case "json":
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(calibration)
+			case "text":
+				outputTextCalibration(cmd.OutOrStdout(), calibration)
+			default:
+				return fmt.Errorf("unknown output format: %s", outputFormat)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&labelsFile, "labels", "", "YAML or JSON file with the human verdicts of the task runs")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, json)")
+	_ = cmd.MarkFlagRequired("labels")
+
+	return cmd
+}
+
+func outputTextCalibration(w io.Writer, c *results.Calibration) {
+	bold := color.New(color.Bold)
+	red := color.New(color.FgRed)
+
+	_, _ = bold.Fprintln(w, "=== Judge Calibration ===")
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "Labeled runs:   %d", c.Labeled)
+	if c.Unlabeled > 0 {
+		fmt.Fprintf(w, " (%d judged runs without a label)", c.Unlabeled)
+	}
+	fmt.Fprintln(w)
+
+	if c.Labeled == 0 {
+		fmt.Fprintln(w, "No judged run has a label.")
+		printUnmatchedLabels(w, c)
+		return
+	}
+
+	cm := c.Confusion
+	fmt.Fprintf(w, "Agreement:      %.1f%% (%d/%d)\n", c.Agreement*100, cm.TruePass+cm.TrueFail, cm.Total())
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "%-14s %-12s %s\n", "", "Human pass", "Human fail")
+	fmt.Fprintf(w, "%-14s %-12d %d\n", "Judge pass", cm.TruePass, cm.FalsePass)
+	fmt.Fprintf(w, "%-14s %-12d %d\n", "Judge fail", cm.FalseFail, cm.TrueFail)
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "Pass verdicts:  precision %.1f%%, recall %.1f%%\n", c.Pass.Precision*100, c.Pass.Recall*100)
+	fmt.Fprintf(w, "Fail verdicts:  precision %.1f%%, recall %.1f%%\n", c.Fail.Precision*100, c.Fail.Recall*100)
+	fmt.Fprintln(w)
+
+	_, _ = bold.Fprintln(w, "By failure category:")
+	categories := make([]string, 0, len(c.Categories))
+	for category := range c.Categories {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		cc := c.Categories[category]
+		fmt.Fprintf(w, "  %-22s %d/%d agree", category, cc.TruePass+cc.TrueFail, cc.Total())
+		if cc.FalsePass > 0 {
+			fmt.Fprintf(w, ", %d false pass", cc.FalsePass)
+		}
+		if cc.FalseFail > 0 {
+			fmt.Fprintf(w, ", %d false fail", cc.FalseFail)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if len(c.Disagreements) > 0 {
+		fmt.Fprintln(w)
+		_, _ = bold.Fprintln(w, "Disagreements:")
+		for _, d := range c.Disagreements {
+			verdict := "judge passed, human failed"
+			if !d.JudgePassed {
+				verdict = fmt.Sprintf("judge failed (%s), human passed", d.FailureCategory)
+			}
+			_, _ = red.Fprintf(w, "  %s (run %d): %s\n", d.TaskName, d.RunIndex, verdict)
+			if d.Note != "" {
+				fmt.Fprintf(w, "    Note: %s\n", d.Note)
+			}
+		}
+	}
+
+	printUnmatchedLabels(w, c)
+}
+
+func printUnmatchedLabels(w io.Writer, c *results.Calibration) {
+	if len(c.UnmatchedLabels) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Labels matching no judged run: %v\n", c.UnmatchedLabels)
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+)
+
+func TestOutputTextCalibration(t *testing.T) {
+	c := &results.Calibration{
+		Labeled:   4,
+		Unlabeled: 1,
+		Agreement: 0.75,
+		Confusion: results.Confusion{TruePass: 2, FalseFail: 1, TrueFail: 1},
+		Pass:      results.ClassMetrics{Precision: 1, Recall: 2.0 / 3},
+		Fail:      results.ClassMetrics{Precision: 0.5, Recall: 1},
+		Categories: map[string]*results.Confusion{
+			"n/a":                 {TruePass: 2},
+			"missing_information": {FalseFail: 1, TrueFail: 1},
+		},
+		Disagreements: []results.Disagreement{
+			{TaskName: "scale-deploy", RunIndex: 1, FailureCategory: "missing_information", Note: "the replicas are listed"},
+		},
+		UnmatchedLabels: []string{"removed-task"},
+	}
+
+	var out bytes.Buffer
+	outputTextCalibration(&out, c)
+
+	for _, expected := range []string{
+		"Labeled runs:   4 (1 judged runs without a label)",
+		"Agreement:      75.0% (3/4)",
+		"Pass verdicts:  precision 100.0%, recall 66.7%",
+		"Fail verdicts:  precision 50.0%, recall 100.0%",
+		"missing_information    1/2 agree, 1 false fail",
+		"n/a                    2/2 agree",
+		"scale-deploy (run 1): judge failed (missing_information), human passed",
+		"Note: the replicas are listed",
+		"Labels matching no judged run: [removed-task]",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("output should contain %q, got:\n%s", expected, out.String())
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/spf13/cobra"
+)
+
+// NewCanaryCmd creates the canary command
+func NewCanaryCmd() *cobra.Command {
+	var outputFormat string
+	var baseline, candidate string
+	var failOnRegression bool
+
+	cmd := &cobra.Command{
+		Use:   "canary <results-file>",
+		Short: "Compare the runs of a canary run against the baseline and the candidate server",
+		Long: `Compare the runs of each task of a run made with a canary config, against the
+baseline and against the candidate version of an MCP server, like 'mcpchecker
+result diff' compares two results files: the tasks that newly fail or newly pass
+with the candidate, the tasks with the same outcome whose assertions or token
+usage changed, and the overall task pass rate, assertion pass rate and token
+usage.
+
+The baseline and candidate servers are read from the summary of the results
+file, and can be set with --baseline and --candidate for results files
+without one. With --fail-on-regression the command exits with an error if a
+task newly fails with the candidate or its task pass rate is lower, so an
+upgrade of the server can be blocked before it rolls out.
+
+Example:
+  mcpchecker result canary results.json
+  mcpchecker result canary results.json --fail-on-regression`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, err := results.LoadOutput(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to load results file: %w", err)
+			}
+
+			canary := &eval.CanaryConfig{}
+			if output.Summary != nil && output.Summary.Canary != nil {
+				*canary = *output.Summary.Canary
+			}
+			if baseline != "" {
+				canary.Baseline = baseline
+			}
+			if candidate != "" {
+				canary.Candidate = candidate
+			}
+			if canary.Baseline == "" || canary.Candidate == "" {
+				return fmt.Errorf("%s is not the results file of a canary run: set --baseline and --candidate", args[0])
+			}
+
+			diff := compareCanary(canary, output.Results)
+			if err := writeDiff(cmd.OutOrStdout(), outputFormat, diff); err != nil {
+				return err
+			}
+
+			if failOnRegression && diff.Regressed {
+				return diff.regressionError(" with " + canary.Candidate)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, markdown, json)")
+	cmd.Flags().StringVar(&baseline, "baseline", "", "Environment of the runs against the baseline server (default from the results summary)")
+	cmd.Flags().StringVar(&candidate, "candidate", "", "Environment of the runs against the candidate server (default from the results summary)")
+	cmd.Flags().BoolVar(&failOnRegression, "fail-on-regression", false, "Exit with an error if a task newly fails with the candidate or the task pass rate dropped")
+
+	return cmd
+}
+
+// compareCanary compares the runs of a canary run against the candidate
+// server to those against the baseline server. The servers take the place of
+// the results files in the comparison.
+func compareCanary(canary *eval.CanaryConfig, evalResults []*eval.EvalResult) DiffResult {
+	baseline, candidate := results.CanaryVersions(canary, evalResults)
+	return calculateDiff(canary.Baseline, canary.Candidate, baseline, candidate, results.DefaultAlpha)
+}
+
+// printCanary prints the comparison of the runs of a canary run made by the
+// check command.
+func printCanary(canary *eval.CanaryConfig, evalResults []*eval.EvalResult) {
+	fmt.Println()
+	outputTextDiff(os.Stdout, compareCanary(canary, evalResults))
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+func canaryResults() []*eval.EvalResult {
+	return []*eval.EvalResult{
+		{TaskID: "list-pods", TaskName: "list-pods", TaskPassed: true, AllAssertionsPassed: true, Environment: "kubernetes-v1"},
+		{TaskID: "create-pod", TaskName: "create-pod", TaskPassed: true, AllAssertionsPassed: true, Environment: "kubernetes-v1"},
+		{TaskID: "list-pods", TaskName: "list-pods", TaskPassed: true, AllAssertionsPassed: true, Environment: "kubernetes-v2"},
+		{TaskID: "create-pod", TaskName: "create-pod", TaskPassed: false, TaskError: "pod not created", Environment: "kubernetes-v2"},
+	}
+}
+
+func TestCanaryCommand(t *testing.T) {
+	dir := t.TempDir()
+	canaryFile := filepath.Join(dir, "canary.json")
+	data, err := json.Marshal(&eval.EvalOutput{
+		Summary: &eval.EvalSummary{
+			Canary: &eval.CanaryConfig{Server: "kubernetes", Baseline: "kubernetes-v1", Candidate: "kubernetes-v2"},
+		},
+		Results: canaryResults(),
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal results: %v", err)
+	}
+	if err := os.WriteFile(canaryFile, data, 0644); err != nil {
+		t.Fatalf("failed to write results file: %v", err)
+	}
+	legacyFile := createTestResultsFile(t, canaryResults())
+
+	tests := map[string]struct {
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		"text": {
+			args: []string{canaryFile},
+			want: []string{
+				"=== Evaluation Diff: kubernetes-v1 → kubernetes-v2 ===",
+				"Regressions (1):",
+				"✗ create-pod: PASSED → FAILED",
+				"Tasks:       2/2        1/2        -50.0%",
+			},
+		},
+		"fail on regression": {
+			args:    []string{canaryFile, "--fail-on-regression"},
+			wantErr: "regression: 1 task(s) newly failing with kubernetes-v2, task pass rate 100.0% → 50.0%",
+		},
+		"versions from flags": {
+			args: []string{legacyFile, "--baseline", "kubernetes-v2", "--candidate", "kubernetes-v1"},
+			want: []string{"✓ create-pod: FAILED → PASSED"},
+		},
+		"not a canary run": {
+			args:    []string{legacyFile},
+			wantErr: legacyFile + " is not the results file of a canary run: set --baseline and --candidate",
+		},
+		"unknown output": {
+			args:    []string{canaryFile, "--output", "xml"},
+			wantErr: "unknown output format: xml",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := NewCanaryCmd()
+			cmd.SetArgs(tc.args)
+			buf := new(bytes.Buffer)
+			cmd.SetOut(buf)
+			cmd.SetErr(new(bytes.Buffer))
+
+			err := cmd.Execute()
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("canary command failed: %v", err)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
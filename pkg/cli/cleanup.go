@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/spf13/cobra"
+)
+
+// NewCleanupCmd creates the cleanup command
+func NewCleanupCmd() *cobra.Command {
+	var from string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "cleanup <eval-config-file>",
+		Short: "Run the cleanup of task runs that a crash left behind",
+		Long: `Run the cleanup steps of the task runs of an earlier 'mcpchecker check' whose
+cleanup never ran, because mcpchecker crashed or was killed while they ran.
+
+The runs are read from --from, the results journal of the run or its results
+file. Each task run journals the outputs of its setup steps before its agent
+runs, so the cleanup steps are run with the same {steps.*} references as they
+would have been during the run. Runs whose result was journaled with cleanup
+output are skipped.
+
+Then the deleteGenerated* operations of the extensions that the tasks require,
+such as deleteGeneratedNamespaces, are run without arguments. Extensions use
+them to delete what they generated for the run, which they tell by the run ID
+in MCPCHECKER_RUN_ID, even where the setup outputs were never journaled.
+They are skipped with a warning for journals without a run ID.
+
+The extensions and MCP servers of the eval config are used, with the run ID of
+the journal. Task files are read again from the paths they had during the run.
+
+Example:
+  mcpchecker cleanup eval.yaml
+  mcpchecker cleanup eval.yaml --from mcpchecker-k8s-journal.ndjson --dry-run`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spec, err := eval.FromFile(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to load eval config: %w", err)
+			}
+			if from == "" {
+				from = "mcpchecker-" + spec.Metadata.Name + journalSuffix
+			}
+
+			entries, err := loadCleanupEntries(from)
+			if err != nil {
+				return err
+			}
+			runID := journalRunID(entries)
+			runs := eval.OrphanedRuns(entries)
+
+			out := cmd.OutOrStdout()
+			if len(runs) == 0 {
+				fmt.Fprintf(out, "No task runs without cleanup in %s\n", from)
+				return nil
+			}
+			if dryRun {
+				for _, run := range runs {
+					fmt.Fprintf(out, "Would clean up %s\n", describeRun(run))
+				}
+				return nil
+			}
+
+			replay, err := eval.ReplayCleanup(cmd.Context(), spec, runID, runs)
+			if err != nil {
+				return err
+			}
+			printCleanupReplay(out, replay)
+
+			if failed := replay.Failed(); failed > 0 {
+				return fmt.Errorf("%d cleanups failed", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "Results journal or results file of the run to clean up after (default: mcpchecker-<eval-name>-journal.ndjson)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the task runs that would be cleaned up without cleaning up")
+
+	return cmd
+}
+
+// loadCleanupEntries reads the journal at path. A results file is read as the
+// JournalResult entries of its results.
+func loadCleanupEntries(path string) ([]eval.JournalEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if eval.IsJournal(data) {
+		entries, err := eval.ReadJournal(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse results journal: %w", err)
+		}
+		return entries, nil
+	}
+
+	output, err := results.ParseOutput(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load results file: %w", err)
+	}
+	entries := []eval.JournalEntry{{Type: eval.JournalStart, Summary: output.Summary}}
+	for _, result := range output.Results {
+		entries = append(entries, eval.JournalEntry{Type: eval.JournalResult, Result: result})
+	}
+	return entries, nil
+}
+
+// journalRunID returns the ID of the run that entries were written by, or an
+// empty string for runs that predate run IDs.
+func journalRunID(entries []eval.JournalEntry) string {
+	for _, entry := range entries {
+		if entry.RunID != "" {
+			return entry.RunID
+		}
+		if entry.Summary != nil && entry.Summary.RunID != "" {
+			return entry.Summary.RunID
+		}
+	}
+	return ""
+}
+
+// describeRun names a task run, with the variant of the task it ran.
+func describeRun(run *eval.EvalResult) string {
+	name := run.TaskName
+	if run.RunIndex > 0 {
+		name += fmt.Sprintf(" [run %d]", run.RunIndex+1)
+	}
+	if run.AllowedToolsMode != "" {
+		name += fmt.Sprintf(" [tools: %s]", run.AllowedToolsMode)
+	}
+	if run.PromptVariant > 0 {
+		name += fmt.Sprintf(" [prompt variant %d]", run.PromptVariant)
+	}
+	if run.Locale != "" {
+		name += fmt.Sprintf(" [locale: %s]", run.Locale)
+	}
+	return name
+}
+
+// printCleanupReplay prints the outcome of each cleanup and operation.
+func printCleanupReplay(out io.Writer, replay *eval.CleanupReplay) {
+	green := color.New(color.FgGreen)
+	red := color.New(color.FgRed)
+	yellow := color.New(color.FgYellow)
+
+	for _, run := range replay.Runs {
+		switch {
+		case run.Error != "":
+			_, _ = red.Fprintf(out, "✗ %s: %s\n", describeRun(run.Run), run.Error)
+		case run.Output != nil && !run.Output.Success:
+			_, _ = red.Fprintf(out, "✗ %s: %s\n", describeRun(run.Run), run.Output.Error)
+		default:
+			_, _ = green.Fprintf(out, "✓ %s\n", describeRun(run.Run))
+		}
+	}
+
+	for _, op := range replay.Generated {
+		name := op.Extension
+		if op.Operation != "" {
+			name += "." + op.Operation
+		}
+		if op.Error != "" {
+			_, _ = red.Fprintf(out, "✗ %s: %s\n", name, op.Error)
+			continue
+		}
+		_, _ = green.Fprintf(out, "✓ %s", name)
+		if op.Message != "" {
+			fmt.Fprintf(out, ": %s", op.Message)
+		}
+		fmt.Fprintln(out)
+	}
+
+	for _, warning := range replay.Warnings {
+		_, _ = yellow.Fprintf(out, "⚠ %s\n", warning)
+	}
+}
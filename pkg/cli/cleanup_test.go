@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+func TestLoadCleanupEntries(t *testing.T) {
+	dir := t.TempDir()
+	setup := &task.PhaseOutput{Success: true}
+
+	journalPath := filepath.Join(dir, "mcpchecker-test-journal.ndjson")
+	writeJournalLines(t, journalPath,
+		eval.JournalEntry{Type: eval.JournalStart, RunID: "run-1", Summary: &eval.EvalSummary{RunID: "run-1"}},
+		eval.JournalEntry{Type: eval.JournalSetup, RunID: "run-1", Result: &eval.EvalResult{TaskName: "crashed", TaskPath: "a.yaml", SetupOutput: setup}},
+	)
+
+	resultsPath := filepath.Join(dir, "mcpchecker-test-out.json")
+	data, err := json.Marshal(&eval.EvalOutput{
+		Summary: &eval.EvalSummary{RunID: "run-2"},
+		Results: []*eval.EvalResult{{TaskName: "no-cleanup", TaskPath: "b.yaml", SetupOutput: setup}},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal results: %v", err)
+	}
+	if err := os.WriteFile(resultsPath, data, 0644); err != nil {
+		t.Fatalf("failed to write results: %v", err)
+	}
+
+	tests := map[string]struct {
+		path      string
+		wantRunID string
+		wantRun   string
+	}{
+		"journal":      {path: journalPath, wantRunID: "run-1", wantRun: "crashed"},
+		"results file": {path: resultsPath, wantRunID: "run-2", wantRun: "no-cleanup"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			entries, err := loadCleanupEntries(tc.path)
+			if err != nil {
+				t.Fatalf("loadCleanupEntries failed: %v", err)
+			}
+			if got := journalRunID(entries); got != tc.wantRunID {
+				t.Errorf("run ID = %q, want %q", got, tc.wantRunID)
+			}
+			runs := eval.OrphanedRuns(entries)
+			if len(runs) != 1 || runs[0].TaskName != tc.wantRun {
+				t.Fatalf("expected orphaned run %q, got %v", tc.wantRun, runs)
+			}
+		})
+	}
+}
+
+func TestDescribeRun(t *testing.T) {
+	run := &eval.EvalResult{TaskName: "create-pod", RunIndex: 2, AllowedToolsMode: "all", Locale: "de"}
+	want := "create-pod [run 3] [tools: all] [locale: de]"
+	if got := describeRun(run); got != want {
+		t.Errorf("describeRun() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewCompareCmd creates the compare command, a shorthand for result diff that
+// takes the results files as arguments
+func NewCompareCmd() *cobra.Command {
+	var opts diffOptions
+
+	cmd := &cobra.Command{
+		Use:   "compare <base-results-file> <head-results-file>",
+		Short: "Compare two results files task by task",
+		Long: `Compare two results files, for example from different models or branches,
+task by task. This is 'mcpchecker result diff --base <base-results-file>
+--current <head-results-file>' with the results files as arguments, and takes
+the same flags; see 'mcpchecker result diff --help'.
+
+Example:
+  mcpchecker compare results-main.json results-pr.json
+  mcpchecker compare results-main.json results-pr.json --output json --fail-on-regression`,
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.run(cmd, args[0], args[1])
+		},
+	}
+
+	opts.addFlags(cmd)
+
+	return cmd
+}
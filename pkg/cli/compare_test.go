@@ -0,0 +1,269 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/mcpchecker/mcpchecker/pkg/tokens"
+)
+
+func compareBaseResults() []*eval.EvalResult {
+	return []*eval.EvalResult{
+		{
+			TaskName:   "regressed",
+			TaskPassed: true,
+			AssertionResults: &eval.CompositeAssertionResult{
+				ToolsUsed:    &eval.SingleAssertionResult{Passed: true},
+				MinToolCalls: &eval.SingleAssertionResult{Passed: true},
+			},
+			AllAssertionsPassed: true,
+			TokenEstimate:       &tokens.Estimate{TotalTokens: 10000},
+		},
+		{
+			TaskName:            "improved",
+			TaskPassed:          false,
+			TaskError:           "verification failed",
+			AllAssertionsPassed: true,
+		},
+		{
+			TaskName:   "cheaper",
+			TaskPassed: true,
+			AssertionResults: &eval.CompositeAssertionResult{
+				ToolsUsed: &eval.SingleAssertionResult{Passed: true},
+			},
+			AllAssertionsPassed: true,
+			TokenEstimate:       &tokens.Estimate{TotalTokens: 8000},
+		},
+		{TaskName: "unchanged", TaskPassed: true, AllAssertionsPassed: true},
+		{TaskName: "removed", TaskPassed: true, AllAssertionsPassed: true},
+	}
+}
+
+func compareHeadResults() []*eval.EvalResult {
+	return []*eval.EvalResult{
+		{
+			TaskName:   "regressed",
+			TaskPassed: true,
+			AssertionResults: &eval.CompositeAssertionResult{
+				ToolsUsed:    &eval.SingleAssertionResult{Passed: true},
+				MinToolCalls: &eval.SingleAssertionResult{Passed: false, Reason: "expected at least 2 calls, got 1"},
+			},
+			AllAssertionsPassed: false,
+			TokenEstimate:       &tokens.Estimate{TotalTokens: 12000},
+		},
+		{TaskName: "improved", TaskPassed: true, AllAssertionsPassed: true},
+		{
+			TaskName:   "cheaper",
+			TaskPassed: true,
+			AssertionResults: &eval.CompositeAssertionResult{
+				ToolsUsed: &eval.SingleAssertionResult{Passed: true},
+			},
+			AllAssertionsPassed: true,
+			TokenEstimate:       &tokens.Estimate{TotalTokens: 6000},
+		},
+		{TaskName: "unchanged", TaskPassed: true, AllAssertionsPassed: true},
+		{TaskName: "added", TaskPassed: false},
+	}
+}
+
+func taskNames(tasks []TaskDiff) []string {
+	var names []string
+	for _, t := range tasks {
+		names = append(names, t.TaskName)
+	}
+	return names
+}
+
+func TestCalculateDiffChanges(t *testing.T) {
+	c := calculateDiff("base.json", "head.json", compareBaseResults(), compareHeadResults(), results.DefaultAlpha)
+
+	tests := map[string]struct {
+		got  []TaskDiff
+		want []string
+	}{
+		"regressions":  {got: c.Regressions, want: []string{"regressed"}},
+		"improvements": {got: c.Improvements, want: []string{"improved"}},
+		"changed":      {got: c.Changed, want: []string{"cheaper"}},
+		"new":          {got: c.New, want: []string{"added"}},
+		"removed":      {got: c.Removed, want: []string{"removed"}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := taskNames(tc.got); !slices.Equal(got, tc.want) {
+				t.Errorf("tasks = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	regressed := c.Regressions[0]
+	if !slices.Equal(regressed.NewlyFailingAssertions, []string{"MinToolCalls"}) {
+		t.Errorf("NewlyFailingAssertions = %v, want [MinToolCalls]", regressed.NewlyFailingAssertions)
+	}
+	if regressed.tokenChange() != 2000 {
+		t.Errorf("tokenChange() = %d, want 2000", regressed.tokenChange())
+	}
+	if regressed.FailureReason != "expected at least 2 calls, got 1" {
+		t.Errorf("FailureReason = %q", regressed.FailureReason)
+	}
+	if !c.Regressed {
+		t.Error("Regressed = false, want true")
+	}
+}
+
+func TestCalculateDiffNoRegression(t *testing.T) {
+	c := calculateDiff("base.json", "head.json", compareBaseResults()[1:], compareHeadResults()[1:4], results.DefaultAlpha)
+	if c.Regressed {
+		t.Errorf("Regressed = true, want false: %+v", c)
+	}
+}
+
+// repeatedRuns returns runs of task, of which the first passed pass.
+func repeatedRuns(task string, runs, passed int) []*eval.EvalResult {
+	rs := make([]*eval.EvalResult, runs)
+	for i := range rs {
+		rs[i] = &eval.EvalResult{TaskName: task, TaskPassed: i < passed, AllAssertionsPassed: true}
+	}
+	return rs
+}
+
+func TestCalculateDiffRepeatedRunsRegression(t *testing.T) {
+	tests := map[string]struct {
+		base, head      []*eval.EvalResult
+		wantRegressed   bool
+		wantWorse       []string
+		wantRegressions []string
+	}{
+		"flaky task is not a regression": {
+			base:            repeatedRuns("flaky", 10, 10),
+			head:            repeatedRuns("flaky", 10, 9),
+			wantRegressions: []string{"flaky"},
+		},
+		"significant drop is a regression": {
+			base:            repeatedRuns("broken", 10, 10),
+			head:            repeatedRuns("broken", 10, 2),
+			wantRegressed:   true,
+			wantWorse:       []string{"broken"},
+			wantRegressions: []string{"broken"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := calculateDiff("base.json", "head.json", tc.base, tc.head, results.DefaultAlpha)
+			if !c.Significance.Repeated {
+				t.Fatal("Significance.Repeated = false, want the comparison of the repeated runs")
+			}
+			if c.Regressed != tc.wantRegressed {
+				t.Errorf("Regressed = %v, want %v", c.Regressed, tc.wantRegressed)
+			}
+			var worse []string
+			for _, w := range c.SignificantlyWorse {
+				worse = append(worse, w.TaskName)
+			}
+			if !slices.Equal(worse, tc.wantWorse) {
+				t.Errorf("SignificantlyWorse = %v, want %v", worse, tc.wantWorse)
+			}
+			if got := taskNames(c.Regressions); !slices.Equal(got, tc.wantRegressions) {
+				t.Errorf("Regressions = %v, want %v", got, tc.wantRegressions)
+			}
+
+			// The runs of a task are compared once, not run by run
+			task := c.Regressions[0]
+			if task.BaseRuns != len(tc.base) || task.HeadRuns != len(tc.head) || task.HeadRunsPassed != countPassedRuns(tc.head) {
+				t.Errorf("runs = %d/%d → %d/%d", task.BaseRunsPassed, task.BaseRuns, task.HeadRunsPassed, task.HeadRuns)
+			}
+		})
+	}
+}
+
+func TestCompareCommand(t *testing.T) {
+	baseFile := createTestResultsFile(t, compareBaseResults())
+	headFile := createTestResultsFile(t, compareHeadResults())
+
+	tests := map[string]struct {
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		"text": {
+			args: []string{baseFile, headFile},
+			want: []string{
+				"Regressions (1):",
+				"✗ regressed: PASSED → FAILED",
+				"assertions: 2/2 → 1/2, MinToolCalls now fails",
+				"tokens: 10.0K → 12.0K (+2.0K (+20.0%))",
+				"Improvements (1):",
+				"~ cheaper: PASSED",
+				"tokens: 8.0K → 6.0K (-2.0K (-25.0%))",
+				"+ added: FAILED",
+				"- removed",
+				"Tokens:      18.0K       18.0K       0",
+			},
+		},
+		"fail on regression": {
+			args:    []string{baseFile, headFile, "--fail-on-regression"},
+			wantErr: "regression: 1 task(s) newly failing, task pass rate 80.0% → 80.0%",
+		},
+		"no regression": {
+			args: []string{baseFile, baseFile, "--fail-on-regression"},
+			want: []string{"Tasks:       4/5        4/5        0.0%"},
+		},
+		"unknown output": {
+			args:    []string{baseFile, headFile, "--output", "xml"},
+			wantErr: "unknown output format: xml",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := NewCompareCmd()
+			cmd.SetArgs(tc.args)
+			buf := new(bytes.Buffer)
+			cmd.SetOut(buf)
+			cmd.SetErr(new(bytes.Buffer))
+
+			err := cmd.Execute()
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("compare command failed: %v", err)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestCompareCommandJSON(t *testing.T) {
+	baseFile := createTestResultsFile(t, compareBaseResults())
+	headFile := createTestResultsFile(t, compareHeadResults())
+
+	cmd := NewCompareCmd()
+	cmd.SetArgs([]string{baseFile, headFile, "--output", "json"})
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("compare command failed: %v", err)
+	}
+
+	var c DiffResult
+	if err := json.Unmarshal(buf.Bytes(), &c); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+	if !c.Regressed || len(c.Regressions) != 1 || *c.Regressions[0].HeadTokens != 12000 {
+		t.Errorf("unexpected comparison: %s", buf.String())
+	}
+}
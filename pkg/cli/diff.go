@@ -1,7 +1,10 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"slices"
 
 	"github.com/fatih/color"
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
@@ -9,32 +12,131 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// DiffResult holds the comparison between two evaluation runs
+// DiffResult holds the comparison between two evaluation runs. A task run
+// more than once passed if all its runs passed.
 type DiffResult struct {
-	BaseStats           results.Stats
-	HeadStats           results.Stats
-	Regressions         []TaskDiff
-	Improvements        []TaskDiff
-	New                 []TaskDiff
-	Removed             []TaskDiff
-	TokenDataIncomplete bool // true if any task has incomplete token data
+	BaseStats results.Stats `json:"base"`
+	HeadStats results.Stats `json:"head"`
+
+	// Regressions and Improvements are the tasks in both runs whose outcome
+	// changed
+	Regressions  []TaskDiff `json:"regressions"`
+	Improvements []TaskDiff `json:"improvements"`
+	// Changed are the tasks in both runs with the same outcome whose
+	// assertions or token usage changed
+	Changed             []TaskDiff `json:"changed"`
+	New                 []TaskDiff `json:"new"`
+	Removed             []TaskDiff `json:"removed"`
+	TokenDataIncomplete bool       `json:"tokenDataIncomplete,omitempty"` // true if any task has incomplete token data
+
+	// Significance compares the pass rates of repeated runs of the tasks
+	Significance results.RunComparison `json:"significance"`
+	// SignificantlyWorse are the tasks run more than once whose pass rate
+	// dropped significantly
+	SignificantlyWorse []results.PassRateComparison `json:"significantlyWorse,omitempty"`
+
+	// Regressed is true if a task newly fails or the task pass rate dropped.
+	// When tasks were run more than once, it is only true if the pass rate of
+	// a task or the overall pass rate dropped significantly.
+	Regressed bool `json:"regressed"`
 }
 
-// TaskDiff holds the diff for a single task
+// TaskDiff holds the diff for a single task. The assertions and tokens of a
+// task run more than once are summed over its runs.
 type TaskDiff struct {
-	TaskName           string
-	BasePassed         bool
-	HeadPassed         bool
-	BaseAssertions     int
-	HeadAssertions     int
-	BaseAssertionTotal int
-	HeadAssertionTotal int
-	FailureReason      string
+	TaskID             string `json:"taskId,omitempty"`
+	TaskName           string `json:"taskName"`
+	BasePassed         bool   `json:"basePassed"`
+	HeadPassed         bool   `json:"headPassed"`
+	BaseAssertions     int    `json:"baseAssertionsPassed"`
+	HeadAssertions     int    `json:"headAssertionsPassed"`
+	BaseAssertionTotal int    `json:"baseAssertionsTotal"`
+	HeadAssertionTotal int    `json:"headAssertionsTotal"`
+	FailureReason      string `json:"failureReason,omitempty"`
+
+	// BaseRuns and HeadRuns are the number of runs of the task, and
+	// BaseRunsPassed and HeadRunsPassed the number that passed
+	BaseRuns       int `json:"baseRuns"`
+	BaseRunsPassed int `json:"baseRunsPassed"`
+	HeadRuns       int `json:"headRuns"`
+	HeadRunsPassed int `json:"headRunsPassed"`
+
+	// NewlyFailingAssertions and NewlyPassingAssertions name the assertions
+	// evaluated in both runs whose outcome changed
+	NewlyFailingAssertions []string `json:"newlyFailingAssertions,omitempty"`
+	NewlyPassingAssertions []string `json:"newlyPassingAssertions,omitempty"`
+
+	// BaseTokens and HeadTokens are the total tokens of the task, and nil
+	// without token data
+	BaseTokens *int64 `json:"baseTokens,omitempty"`
+	HeadTokens *int64 `json:"headTokens,omitempty"`
+}
+
+// diffOptions are the flags shared by the diff and compare commands
+type diffOptions struct {
+	outputFormat     string
+	overridesFiles   []string
+	failOnRegression bool
+	alpha            float64
+}
+
+func (o *diffOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.outputFormat, "output", "o", "text", "Output format (text, markdown, json)")
+	cmd.Flags().StringArrayVar(&o.overridesFiles, "overrides", nil, "Overrides log written by 'mcpchecker review' to apply to both results files (repeatable)")
+	cmd.Flags().BoolVar(&o.failOnRegression, "fail-on-regression", false, "Exit with an error if a task newly fails or the task pass rate dropped")
+	cmd.Flags().Float64Var(&o.alpha, "alpha", results.DefaultAlpha, "Significance level for comparing repeated runs; confidence intervals are at the 1-alpha level")
+}
+
+// run compares the results files baseFile and currentFile and writes the
+// diff to the output of cmd.
+func (o *diffOptions) run(cmd *cobra.Command, baseFile, currentFile string) error {
+	baseResults, err := loadResultsWithOverrides(baseFile, o.overridesFiles)
+	if err != nil {
+		return fmt.Errorf("failed to load base results: %w", err)
+	}
+
+	currentResults, err := loadResultsWithOverrides(currentFile, o.overridesFiles)
+	if err != nil {
+		return fmt.Errorf("failed to load current results: %w", err)
+	}
+
+	if o.alpha <= 0 || o.alpha >= 1 {
+		return fmt.Errorf("--alpha must be between 0 and 1, got %g", o.alpha)
+	}
+
+	diff := calculateDiff(baseFile, currentFile, baseResults, currentResults, o.alpha)
+	if err := writeDiff(cmd.OutOrStdout(), o.outputFormat, diff); err != nil {
+		return err
+	}
+
+	if o.failOnRegression && diff.Regressed {
+		return diff.regressionError("")
+	}
+	return nil
+}
+
+// writeDiff writes diff to w in format.
+func writeDiff(w io.Writer, format string, diff DiffResult) error {
+	switch format {
+	case "text":
+		outputTextDiff(w, diff)
+	case "markdown":
+		outputMarkdownDiff(w, diff)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(diff); err != nil {
+			return fmt.Errorf("failed to encode diff: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown output format: %s", format)
+	}
+	return nil
 }
 
 // NewDiffCmd creates the diff command
 func NewDiffCmd() *cobra.Command {
-	var outputFormat string
+	var opts diffOptions
 	var baseFile string
 	var currentFile string
 
@@ -43,43 +145,36 @@ func NewDiffCmd() *cobra.Command {
 		Short: "Compare two evaluation results",
 		Long: `Compare evaluation results between two runs (e.g., main vs PR).
 
-Shows regressions, improvements, new tasks, removed tasks, and overall pass rate changes.
+Shows regressions, improvements, tasks with the same outcome whose assertions
+or token usage changed, new tasks, removed tasks, and overall pass rate and
+token changes. Tasks are matched by task ID, falling back to the task name.
 Useful for posting on pull requests to show impact of changes.
 
+With --fail-on-regression the command exits with an error if a task newly
+fails or the task pass rate dropped, so CI can block merges that make the eval
+worse.
+
+When tasks were run more than once (check --runs), a task passes if all its
+runs passed. The diff also shows confidence intervals of the task pass rates
+and, for every task whose pass rate changed, whether the change is significant
+according to Fisher's exact test, so that real regressions can be told apart
+from noise: --fail-on-regression then only fails if the pass rate of a task,
+or the overall pass rate, dropped significantly.
+
 Example:
   mcpchecker result diff --base results-main.json --current results-pr.json
-  mcpchecker result diff --base results-main.json --current results-pr.json --output markdown`,
+  mcpchecker result diff --base results-main.json --current results-pr.json --output markdown
+  mcpchecker result diff --base results-main.json --current results-pr.json --output json --fail-on-regression`,
 		Args:         cobra.NoArgs,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			baseResults, err := results.Load(baseFile)
-			if err != nil {
-				return fmt.Errorf("failed to load base results: %w", err)
-			}
-
-			currentResults, err := results.Load(currentFile)
-			if err != nil {
-				return fmt.Errorf("failed to load current results: %w", err)
-			}
-
-			diff := calculateDiff(baseFile, currentFile, baseResults, currentResults)
-
-			switch outputFormat {
-			case "text":
-				outputTextDiff(diff)
-			case "markdown":
-				outputMarkdownDiff(diff)
-			default:
-				return fmt.Errorf("unknown output format: %s", outputFormat)
-			}
-
-			return nil
+			return opts.run(cmd, baseFile, currentFile)
 		},
 	}
 
 	cmd.Flags().StringVar(&baseFile, "base", "", "Base results file (e.g., main branch)")
 	cmd.Flags().StringVar(&currentFile, "current", "", "Current results file (e.g., PR branch)")
-	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, markdown)")
+	opts.addFlags(cmd)
 
 	_ = cmd.MarkFlagRequired("base")
 	_ = cmd.MarkFlagRequired("current")
@@ -96,170 +191,482 @@ func hasTokenErrors(results []*eval.EvalResult) bool {
 	return false
 }
 
-func calculateDiff(baseFile, currentFile string, baseResults, currentResults []*eval.EvalResult) DiffResult {
+func calculateDiff(baseFile, currentFile string, baseResults, currentResults []*eval.EvalResult, alpha float64) DiffResult {
 	diff := DiffResult{
 		BaseStats:           results.CalculateStats(baseFile, baseResults),
 		HeadStats:           results.CalculateStats(currentFile, currentResults),
 		Regressions:         make([]TaskDiff, 0),
 		Improvements:        make([]TaskDiff, 0),
+		Changed:             make([]TaskDiff, 0),
 		New:                 make([]TaskDiff, 0),
 		Removed:             make([]TaskDiff, 0),
 		TokenDataIncomplete: hasTokenErrors(baseResults) || hasTokenErrors(currentResults),
+		Significance:        results.CompareRuns(baseResults, currentResults, alpha),
+	}
+
+	for _, pair := range pairResults(baseResults, currentResults) {
+		taskDiff := diffTask(pair)
+		switch {
+		case len(pair.Base) == 0:
+			diff.New = append(diff.New, taskDiff)
+		case len(pair.Head) == 0:
+			diff.Removed = append(diff.Removed, taskDiff)
+		case taskDiff.BasePassed && !taskDiff.HeadPassed:
+			diff.Regressions = append(diff.Regressions, taskDiff)
+		case !taskDiff.BasePassed && taskDiff.HeadPassed:
+			diff.Improvements = append(diff.Improvements, taskDiff)
+		case taskDiff.changed():
+			diff.Changed = append(diff.Changed, taskDiff)
+		}
+	}
+
+	// With repeated runs a task that passed every run in one file and not in
+	// the other can be noise, so only significant drops are regressions
+	if diff.Significance.Repeated {
+		for _, t := range diff.Significance.Tasks {
+			if t.Significant && t.Change() < 0 {
+				diff.SignificantlyWorse = append(diff.SignificantlyWorse, t)
+			}
+		}
+		overall := diff.Significance.Overall
+		diff.Regressed = len(diff.SignificantlyWorse) > 0 || (overall.Significant && overall.Change() < 0)
+		return diff
 	}
 
-	baseMap := make(map[string]*eval.EvalResult)
-	for _, r := range baseResults {
-		baseMap[r.TaskName] = r
+	diff.Regressed = len(diff.Regressions) > 0 || diff.HeadStats.TaskPassRate < diff.BaseStats.TaskPassRate
+	return diff
+}
+
+func diffTask(pair resultPair) TaskDiff {
+	task := pair.task()
+	t := TaskDiff{
+		TaskID:     task.TaskID,
+		TaskName:   task.TaskName,
+		BasePassed: runsPassed(pair.Base),
+		HeadPassed: runsPassed(pair.Head),
+		BaseRuns:   len(pair.Base),
+		HeadRuns:   len(pair.Head),
+	}
+	t.BaseRunsPassed = countPassedRuns(pair.Base)
+	t.HeadRunsPassed = countPassedRuns(pair.Head)
+	t.BaseAssertions, t.BaseAssertionTotal = runsAssertions(pair.Base)
+	t.HeadAssertions, t.HeadAssertionTotal = runsAssertions(pair.Head)
+	t.BaseTokens = runsTokens(pair.Base)
+	t.HeadTokens = runsTokens(pair.Head)
+	if len(pair.Head) > 0 && !t.HeadPassed {
+		t.FailureReason = runsFailureReason(pair.Head)
+	}
+
+	if len(pair.Base) > 0 && len(pair.Head) > 0 {
+		baseOutcomes := runsAssertionOutcomes(pair.Base)
+		for name, headPassed := range runsAssertionOutcomes(pair.Head) {
+			basePassed, ok := baseOutcomes[name]
+			switch {
+			case !ok || basePassed == headPassed:
+			case headPassed:
+				t.NewlyPassingAssertions = append(t.NewlyPassingAssertions, name)
+			default:
+				t.NewlyFailingAssertions = append(t.NewlyFailingAssertions, name)
+			}
+		}
+		slices.Sort(t.NewlyPassingAssertions)
+		slices.Sort(t.NewlyFailingAssertions)
 	}
 
-	currentMap := make(map[string]*eval.EvalResult)
-	for _, r := range currentResults {
-		currentMap[r.TaskName] = r
+	return t
+}
+
+// regressionError describes the regression of diff, with what adding context
+// to the failing tasks.
+func (diff DiffResult) regressionError(with string) error {
+	if diff.Significance.Repeated {
+		overall := diff.Significance.Overall
+		return fmt.Errorf("regression: %d task(s) significantly worse%s, task pass rate %.1f%% → %.1f%%",
+			len(diff.SignificantlyWorse), with, overall.BasePassRate*100, overall.HeadPassRate*100)
 	}
+	return fmt.Errorf("regression: %d task(s) newly failing%s, task pass rate %.1f%% → %.1f%%",
+		len(diff.Regressions), with, diff.BaseStats.TaskPassRate*100, diff.HeadStats.TaskPassRate*100)
+}
+
+// changed reports whether the assertions or token usage of the task changed.
+func (t TaskDiff) changed() bool {
+	return t.BaseAssertions != t.HeadAssertions ||
+		t.BaseAssertionTotal != t.HeadAssertionTotal ||
+		len(t.NewlyFailingAssertions) > 0 || len(t.NewlyPassingAssertions) > 0 ||
+		t.tokenChange() != 0
+}
+
+// tokenChange returns the change in total tokens, or 0 if either side has no
+// token data.
+func (t TaskDiff) tokenChange() int64 {
+	if t.BaseTokens == nil || t.HeadTokens == nil {
+		return 0
+	}
+	return *t.HeadTokens - *t.BaseTokens
+}
+
+// resultPair holds the runs of a task in two results files, in file order.
+// Base is empty for tasks that are new in the current file, and Head for
+// removed tasks.
+type resultPair struct {
+	Base []*eval.EvalResult
+	Head []*eval.EvalResult
+}
+
+// task returns the first run of the task, preferring the current file.
+func (p resultPair) task() *eval.EvalResult {
+	if len(p.Head) > 0 {
+		return p.Head[0]
+	}
+	return p.Base[0]
+}
 
-	for _, current := range currentResults {
-		base, exists := baseMap[current.TaskName]
-		if !exists {
-			diff.New = append(diff.New, TaskDiff{
-				TaskName:           current.TaskName,
-				HeadPassed:         current.TaskPassed && current.AllAssertionsPassed,
-				HeadAssertions:     results.PassedAssertions(current),
-				HeadAssertionTotal: results.TotalAssertions(current),
-			})
-			continue
+// pairResults groups the runs of each task by results.TaskKey and matches the
+// current tasks to the base tasks, in the order the current tasks first appear
+// followed by the removed ones.
+func pairResults(baseResults, currentResults []*eval.EvalResult) []resultPair {
+	group := func(rs []*eval.EvalResult) (map[string][]*eval.EvalResult, []string) {
+		runs := make(map[string][]*eval.EvalResult)
+		var order []string
+		for _, r := range rs {
+			key := results.TaskKey(r)
+			if _, ok := runs[key]; !ok {
+				order = append(order, key)
+			}
+			runs[key] = append(runs[key], r)
 		}
+		return runs, order
+	}
+	baseRuns, baseOrder := group(baseResults)
+	currentRuns, currentOrder := group(currentResults)
 
-		basePassed := base.TaskPassed && base.AllAssertionsPassed
-		currentPassed := current.TaskPassed && current.AllAssertionsPassed
+	// Tasks are matched by task ID, falling back to the name for results
+	// from before the task had an ID
+	findBase := func(key string, current *eval.EvalResult) (string, bool) {
+		if _, ok := baseRuns[key]; ok {
+			return key, true
+		}
+		if base, ok := baseRuns[current.TaskName]; ok && base[0].TaskID == "" {
+			return current.TaskName, true
+		}
+		return "", false
+	}
 
-		taskDiff := TaskDiff{
-			TaskName:           current.TaskName,
-			BasePassed:         basePassed,
-			HeadPassed:         currentPassed,
-			BaseAssertions:     results.PassedAssertions(base),
-			HeadAssertions:     results.PassedAssertions(current),
-			BaseAssertionTotal: results.TotalAssertions(base),
-			HeadAssertionTotal: results.TotalAssertions(current),
-			FailureReason:      results.FailureReason(current),
+	var pairs []resultPair
+	matched := make(map[string]bool) // keys of base tasks that are still present
+	for _, key := range currentOrder {
+		current := currentRuns[key]
+		pair := resultPair{Head: current}
+		if baseKey, ok := findBase(key, current[0]); ok {
+			matched[baseKey] = true
+			pair.Base = baseRuns[baseKey]
 		}
+		pairs = append(pairs, pair)
+	}
 
-		if basePassed && !currentPassed {
-			diff.Regressions = append(diff.Regressions, taskDiff)
-		} else if !basePassed && currentPassed {
-			diff.Improvements = append(diff.Improvements, taskDiff)
+	for _, key := range baseOrder {
+		if !matched[key] {
+			pairs = append(pairs, resultPair{Base: baseRuns[key]})
 		}
 	}
 
-	for _, base := range baseResults {
-		if _, exists := currentMap[base.TaskName]; !exists {
-			diff.Removed = append(diff.Removed, TaskDiff{
-				TaskName:           base.TaskName,
-				BasePassed:         base.TaskPassed && base.AllAssertionsPassed,
-				BaseAssertions:     results.PassedAssertions(base),
-				BaseAssertionTotal: results.TotalAssertions(base),
-			})
+	return pairs
+}
+
+// runsPassed reports whether the task and all its assertions passed in every
+// run. It is false without runs.
+func runsPassed(runs []*eval.EvalResult) bool {
+	return len(runs) > 0 && countPassedRuns(runs) == len(runs)
+}
+
+// countPassedRuns returns the number of runs in which the task and all its
+// assertions passed.
+func countPassedRuns(runs []*eval.EvalResult) int {
+	passed := 0
+	for _, r := range runs {
+		if r.Passed() {
+			passed++
 		}
 	}
+	return passed
+}
 
-	return diff
+// runsAssertions returns the passed and total assertions of all the runs.
+func runsAssertions(runs []*eval.EvalResult) (passed, total int) {
+	for _, r := range runs {
+		passed += results.PassedAssertions(r)
+		total += results.TotalAssertions(r)
+	}
+	return passed, total
+}
+
+// runsFailureReason returns the failure reason of the first failed run.
+func runsFailureReason(runs []*eval.EvalResult) string {
+	for _, r := range runs {
+		if !r.Passed() {
+			return results.FailureReason(r)
+		}
+	}
+	return ""
 }
 
-func outputTextDiff(diff DiffResult) {
+// runsTokens returns the total tokens of the runs with token data, or nil if
+// none has any.
+func runsTokens(runs []*eval.EvalResult) *int64 {
+	var total *int64
+	for _, r := range runs {
+		if r.TokenEstimate != nil {
+			if total == nil {
+				total = new(int64)
+			}
+			*total += r.TokenEstimate.TotalTokens
+		}
+	}
+	return total
+}
+
+// runsAssertionOutcomes returns whether each assertion passed in every run
+// that evaluated it.
+func runsAssertionOutcomes(runs []*eval.EvalResult) map[string]bool {
+	outcomes := make(map[string]bool)
+	for _, r := range runs {
+		for name, passed := range results.AssertionOutcomes(r) {
+			if previous, ok := outcomes[name]; ok {
+				passed = passed && previous
+			}
+			outcomes[name] = passed
+		}
+	}
+	return outcomes
+}
+
+func outputTextDiff(w io.Writer, diff DiffResult) {
 	green := color.New(color.FgGreen)
 	red := color.New(color.FgRed)
 	yellow := color.New(color.FgYellow)
 	bold := color.New(color.Bold)
 
-	_, _ = bold.Println("=== Evaluation Diff ===")
-	fmt.Println()
+	_, _ = bold.Fprintf(w, "=== Evaluation Diff: %s → %s ===\n\n", diff.BaseStats.ResultsFile, diff.HeadStats.ResultsFile)
 
 	// Regressions
 	if len(diff.Regressions) > 0 {
-		_, _ = red.Printf("Regressions (%d):\n", len(diff.Regressions))
+		_, _ = red.Fprintf(w, "Regressions (%d):\n", len(diff.Regressions))
 		for _, r := range diff.Regressions {
-			_, _ = red.Printf("  ✗ %s: PASSED → FAILED\n", r.TaskName)
+			_, _ = red.Fprintf(w, "  ✗ %s: PASSED → FAILED\n", r.TaskName)
+			printTaskDetails(w, r)
 			if r.FailureReason != "" {
-				fmt.Printf("      %s\n", r.FailureReason)
+				fmt.Fprintf(w, "      %s\n", r.FailureReason)
 			}
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
 	}
 
 	// Improvements
 	if len(diff.Improvements) > 0 {
-		_, _ = green.Printf("Improvements (%d):\n", len(diff.Improvements))
+		_, _ = green.Fprintf(w, "Improvements (%d):\n", len(diff.Improvements))
 		for _, r := range diff.Improvements {
-			_, _ = green.Printf("  ✓ %s: FAILED → PASSED\n", r.TaskName)
+			_, _ = green.Fprintf(w, "  ✓ %s: FAILED → PASSED\n", r.TaskName)
+			printTaskDetails(w, r)
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
+	}
+
+	// Tasks with the same outcome whose assertions or tokens changed
+	if len(diff.Changed) > 0 {
+		_, _ = yellow.Fprintf(w, "Changed (%d):\n", len(diff.Changed))
+		for _, r := range diff.Changed {
+			fmt.Fprintf(w, "  ~ %s: %s\n", r.TaskName, formatOutcome(r.HeadPassed))
+			printTaskDetails(w, r)
+		}
+		fmt.Fprintln(w)
 	}
 
 	// New tasks
 	if len(diff.New) > 0 {
-		_, _ = yellow.Printf("New Tasks (%d):\n", len(diff.New))
+		_, _ = yellow.Fprintf(w, "New Tasks (%d):\n", len(diff.New))
 		for _, r := range diff.New {
 			if r.HeadPassed {
-				_, _ = green.Printf("  + %s: PASSED\n", r.TaskName)
+				_, _ = green.Fprintf(w, "  + %s: PASSED\n", r.TaskName)
 			} else {
-				_, _ = red.Printf("  + %s: FAILED\n", r.TaskName)
+				_, _ = red.Fprintf(w, "  + %s: FAILED\n", r.TaskName)
 			}
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
 	}
 
 	// Removed tasks
 	if len(diff.Removed) > 0 {
-		_, _ = yellow.Printf("Removed Tasks (%d):\n", len(diff.Removed))
+		_, _ = yellow.Fprintf(w, "Removed Tasks (%d):\n", len(diff.Removed))
 		for _, r := range diff.Removed {
-			fmt.Printf("  - %s\n", r.TaskName)
+			fmt.Fprintf(w, "  - %s\n", r.TaskName)
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
 	}
 
 	// Summary table
-	_, _ = bold.Println("=== Summary ===")
-	fmt.Println()
+	_, _ = bold.Fprintln(w, "=== Summary ===")
+	fmt.Fprintln(w)
 
 	taskChange := diff.HeadStats.TaskPassRate - diff.BaseStats.TaskPassRate
 	assertionChange := diff.HeadStats.AssertionPassRate - diff.BaseStats.AssertionPassRate
 
-	fmt.Printf("             Base        Head        Change\n")
-	fmt.Printf("Tasks:       %d/%-8d %d/%-8d ",
+	fmt.Fprintf(w, "             Base        Head        Change\n")
+	fmt.Fprintf(w, "Tasks:       %d/%-8d %d/%-8d ",
 		diff.BaseStats.TasksPassed, diff.BaseStats.TasksTotal,
 		diff.HeadStats.TasksPassed, diff.HeadStats.TasksTotal)
-	printChange(taskChange)
+	printChange(w, taskChange)
+	if diff.Significance.Repeated {
+		overall := diff.Significance.Overall
+		fmt.Fprintf(w, "%-13s%-12s%-12s%s\n", fmt.Sprintf("%.0f%% CI:", (1-diff.Significance.Alpha)*100),
+			formatInterval(overall.BaseInterval), formatInterval(overall.HeadInterval), formatSignificance(overall))
+	}
 
-	fmt.Printf("Assertions:  %d/%-8d %d/%-8d ",
+	fmt.Fprintf(w, "Assertions:  %d/%-8d %d/%-8d ",
 		diff.BaseStats.AssertionsPassed, diff.BaseStats.AssertionsTotal,
 		diff.HeadStats.AssertionsPassed, diff.HeadStats.AssertionsTotal)
-	printChange(assertionChange)
+	printChange(w, assertionChange)
 
 	// Token stats (only show if at least one side has actual token data)
 	if diff.BaseStats.TasksWithTokens > 0 || diff.HeadStats.TasksWithTokens > 0 {
-		fmt.Println()
-		_, _ = bold.Println("=== Token Usage ===")
-		fmt.Println()
-		fmt.Printf("             Base        Head        Change\n")
-		fmt.Printf("Tokens:      %-11s %-11s ", formatTokenCountOrNA(diff.BaseStats.TotalTokens, diff.BaseStats.TasksWithTokens), formatTokenCountOrNA(diff.HeadStats.TotalTokens, diff.HeadStats.TasksWithTokens))
-		printTokenChangeWithCoverage(diff.BaseStats.TotalTokens, diff.BaseStats.TasksWithTokens, diff.HeadStats.TotalTokens, diff.HeadStats.TasksWithTokens)
-		fmt.Printf("MCP Schema:  %-11s %-11s ", formatTokenCountOrNA(diff.BaseStats.McpSchemaTokens, diff.BaseStats.TasksWithTokens), formatTokenCountOrNA(diff.HeadStats.McpSchemaTokens, diff.HeadStats.TasksWithTokens))
-		printTokenChangeWithCoverage(diff.BaseStats.McpSchemaTokens, diff.BaseStats.TasksWithTokens, diff.HeadStats.McpSchemaTokens, diff.HeadStats.TasksWithTokens)
+		fmt.Fprintln(w)
+		_, _ = bold.Fprintln(w, "=== Token Usage ===")
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "             Base        Head        Change\n")
+		fmt.Fprintf(w, "Tokens:      %-11s %-11s ", formatTokenCountOrNA(diff.BaseStats.TotalTokens, diff.BaseStats.TasksWithTokens), formatTokenCountOrNA(diff.HeadStats.TotalTokens, diff.HeadStats.TasksWithTokens))
+		printTokenChangeWithCoverage(w, diff.BaseStats.TotalTokens, diff.BaseStats.TasksWithTokens, diff.HeadStats.TotalTokens, diff.HeadStats.TasksWithTokens)
+		fmt.Fprintf(w, "MCP Schema:  %-11s %-11s ", formatTokenCountOrNA(diff.BaseStats.McpSchemaTokens, diff.BaseStats.TasksWithTokens), formatTokenCountOrNA(diff.HeadStats.McpSchemaTokens, diff.HeadStats.TasksWithTokens))
+		printTokenChangeWithCoverage(w, diff.BaseStats.McpSchemaTokens, diff.BaseStats.TasksWithTokens, diff.HeadStats.McpSchemaTokens, diff.HeadStats.TasksWithTokens)
 		if diff.TokenDataIncomplete {
-			yellow.Println("\n⚠️  Token counts may be incomplete due to errors during token estimation")
+			yellow.Fprintln(w, "\n⚠️  Token counts may be incomplete due to errors during token estimation")
+		}
+	}
+
+	if diff.Significance.Repeated {
+		outputTextSignificance(w, diff.Significance)
+	}
+
+	if diff.Regressed {
+		fmt.Fprintln(w)
+		_, _ = red.Fprintln(w, "✗ Regression")
+	}
+}
+
+// printTaskDetails prints the run, assertion and token changes of a task.
+func printTaskDetails(w io.Writer, t TaskDiff) {
+	if t.BaseRuns > 1 || t.HeadRuns > 1 {
+		fmt.Fprintf(w, "      runs passed: %d/%d → %d/%d\n", t.BaseRunsPassed, t.BaseRuns, t.HeadRunsPassed, t.HeadRuns)
+	}
+	if t.BaseAssertionTotal > 0 || t.HeadAssertionTotal > 0 {
+		line := fmt.Sprintf("assertions: %d/%d → %d/%d", t.BaseAssertions, t.BaseAssertionTotal, t.HeadAssertions, t.HeadAssertionTotal)
+		for _, name := range t.NewlyFailingAssertions {
+			line += fmt.Sprintf(", %s now fails", name)
+		}
+		for _, name := range t.NewlyPassingAssertions {
+			line += fmt.Sprintf(", %s now passes", name)
 		}
+		fmt.Fprintf(w, "      %s\n", line)
+	}
+	if t.BaseTokens != nil && t.HeadTokens != nil {
+		fmt.Fprintf(w, "      tokens: %s → %s (%s)\n", formatTokenCount(*t.BaseTokens), formatTokenCount(*t.HeadTokens),
+			formatTokenDelta(*t.BaseTokens, *t.HeadTokens))
 	}
 }
 
-func printChange(change float64) {
+func formatOutcome(passed bool) string {
+	if passed {
+		return "PASSED"
+	}
+	return "FAILED"
+}
+
+// formatTokenDelta formats the change from base to head tokens.
+func formatTokenDelta(base, head int64) string {
+	switch {
+	case base == head:
+		return "0"
+	case base == 0:
+		return "+" + formatTokenCount(head)
+	}
+
+	diff := head - base
+	sign := ""
+	if diff > 0 {
+		sign = "+"
+	}
+	return fmt.Sprintf("%s%s (%s%.1f%%)", sign, formatTokenCount(diff), sign, float64(diff)/float64(base)*100)
+}
+
+// outputTextSignificance prints the tasks whose pass rate changed across
+// repeated runs, and whether each change is significant.
+func outputTextSignificance(w io.Writer, c results.RunComparison) {
+	green := color.New(color.FgGreen)
+	red := color.New(color.FgRed)
+	bold := color.New(color.Bold)
+
+	fmt.Fprintln(w)
+	_, _ = bold.Fprintln(w, "=== Significance ===")
+	fmt.Fprintln(w)
+
+	changed := changedPassRates(c.Tasks)
+	if len(changed) == 0 {
+		fmt.Fprintln(w, "No task pass rate changed.")
+		return
+	}
+
+	significant := 0
+	for _, t := range changed {
+		line := fmt.Sprintf("%s: %d/%d → %d/%d (%s), %s", t.TaskName,
+			t.BasePassed, t.BaseRuns, t.HeadPassed, t.HeadRuns, formatPercentChange(t.Change()), formatSignificance(t))
+		switch {
+		case !t.Significant:
+			fmt.Fprintf(w, "  ~ %s\n", line)
+		case t.Change() < 0:
+			significant++
+			_, _ = red.Fprintf(w, "  ✗ %s\n", line)
+		default:
+			significant++
+			_, _ = green.Fprintf(w, "  ✓ %s\n", line)
+		}
+	}
+	fmt.Fprintf(w, "\n%d of %d changed task(s) significant at α=%g; changes marked ~ may be noise\n", significant, len(changed), c.Alpha)
+}
+
+// changedPassRates returns the tasks whose pass rate changed.
+func changedPassRates(tasks []results.PassRateComparison) []results.PassRateComparison {
+	var changed []results.PassRateComparison
+	for _, t := range tasks {
+		if t.Change() != 0 {
+			changed = append(changed, t)
+		}
+	}
+	return changed
+}
+
+func formatInterval(i results.Interval) string {
+	return fmt.Sprintf("[%.0f%%, %.0f%%]", i.Low*100, i.High*100)
+}
+
+func formatPercentChange(change float64) string {
+	return fmt.Sprintf("%+.1f%%", change*100)
+}
+
+func formatSignificance(c results.PassRateComparison) string {
+	if c.Significant {
+		return fmt.Sprintf("p=%.3f, significant", c.PValue)
+	}
+	return fmt.Sprintf("p=%.3f, not significant", c.PValue)
+}
+
+func printChange(w io.Writer, change float64) {
 	green := color.New(color.FgGreen)
 	red := color.New(color.FgRed)
 
 	if change > 0 {
-		_, _ = green.Printf("+%.1f%%\n", change*100)
+		_, _ = green.Fprintf(w, "+%.1f%%\n", change*100)
 	} else if change < 0 {
-		_, _ = red.Printf("%.1f%%\n", change*100)
+		_, _ = red.Fprintf(w, "%.1f%%\n", change*100)
 	} else {
-		fmt.Println("0.0%")
+		fmt.Fprintln(w, "0.0%")
 	}
 }
 
@@ -289,25 +696,25 @@ func formatTokenCountOrNA(tokens int64, tasksWithTokens int) string {
 }
 
 // printTokenChangeWithCoverage handles token change display when one or both sides may lack token data.
-func printTokenChangeWithCoverage(baseTokens int64, baseTasksWithTokens int, headTokens int64, headTasksWithTokens int) {
+func printTokenChangeWithCoverage(w io.Writer, baseTokens int64, baseTasksWithTokens int, headTokens int64, headTasksWithTokens int) {
 	green := color.New(color.FgGreen)
 	red := color.New(color.FgRed)
 
 	// If neither has token data, nothing to compare
 	if baseTasksWithTokens == 0 && headTasksWithTokens == 0 {
-		fmt.Println("-")
+		fmt.Fprintln(w, "-")
 		return
 	}
 
 	// If only head has data, can't compare meaningfully
 	if baseTasksWithTokens == 0 {
-		fmt.Println("(no base data)")
+		fmt.Fprintln(w, "(no base data)")
 		return
 	}
 
 	// If only base has data, can't compare meaningfully
 	if headTasksWithTokens == 0 {
-		fmt.Println("(no head data)")
+		fmt.Fprintln(w, "(no head data)")
 		return
 	}
 
@@ -315,97 +722,148 @@ func printTokenChangeWithCoverage(baseTokens int64, baseTasksWithTokens int, hea
 	diff := headTokens - baseTokens
 	if baseTokens == 0 {
 		if headTokens > 0 {
-			_, _ = red.Printf("+%s\n", formatTokenCount(headTokens))
+			_, _ = red.Fprintf(w, "+%s\n", formatTokenCount(headTokens))
 		} else {
-			fmt.Println("0")
+			fmt.Fprintln(w, "0")
 		}
 		return
 	}
 
 	pctChange := float64(diff) / float64(baseTokens) * 100
 	if diff > 0 {
-		_, _ = red.Printf("+%s (+%.1f%%)\n", formatTokenCount(diff), pctChange)
+		_, _ = red.Fprintf(w, "+%s (+%.1f%%)\n", formatTokenCount(diff), pctChange)
 	} else if diff < 0 {
-		_, _ = green.Printf("%s (%.1f%%)\n", formatTokenCount(diff), pctChange)
+		_, _ = green.Fprintf(w, "%s (%.1f%%)\n", formatTokenCount(diff), pctChange)
 	} else {
-		fmt.Println("0")
+		fmt.Fprintln(w, "0")
 	}
 }
 
-func outputMarkdownDiff(diff DiffResult) {
+func outputMarkdownDiff(w io.Writer, diff DiffResult) {
 	taskChange := diff.HeadStats.TaskPassRate - diff.BaseStats.TaskPassRate
 	assertionChange := diff.HeadStats.AssertionPassRate - diff.BaseStats.AssertionPassRate
 
-	fmt.Println("### 📊 Evaluation Results")
-	fmt.Println()
-	fmt.Println("| Metric | Base | Head | Change |")
-	fmt.Println("|--------|------|------|--------|")
-	fmt.Printf("| Tasks | %d/%d (%.1f%%) | %d/%d (%.1f%%) | %s |\n",
+	fmt.Fprintln(w, "### 📊 Evaluation Results")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Metric | Base | Head | Change |")
+	fmt.Fprintln(w, "|--------|------|------|--------|")
+	fmt.Fprintf(w, "| Tasks | %d/%d (%.1f%%) | %d/%d (%.1f%%) | %s |\n",
 		diff.BaseStats.TasksPassed, diff.BaseStats.TasksTotal, diff.BaseStats.TaskPassRate*100,
 		diff.HeadStats.TasksPassed, diff.HeadStats.TasksTotal, diff.HeadStats.TaskPassRate*100,
 		formatChangeMarkdown(taskChange))
-	fmt.Printf("| Assertions | %d/%d (%.1f%%) | %d/%d (%.1f%%) | %s |\n",
+	fmt.Fprintf(w, "| Assertions | %d/%d (%.1f%%) | %d/%d (%.1f%%) | %s |\n",
 		diff.BaseStats.AssertionsPassed, diff.BaseStats.AssertionsTotal, diff.BaseStats.AssertionPassRate*100,
 		diff.HeadStats.AssertionsPassed, diff.HeadStats.AssertionsTotal, diff.HeadStats.AssertionPassRate*100,
 		formatChangeMarkdown(assertionChange))
+	if diff.Significance.Repeated {
+		overall := diff.Significance.Overall
+		fmt.Fprintf(w, "| Tasks %.0f%% CI | %s | %s | %s |\n", (1-diff.Significance.Alpha)*100,
+			formatInterval(overall.BaseInterval), formatInterval(overall.HeadInterval), formatSignificance(overall))
+	}
 
 	// Token stats (only show if at least one side has token data)
 	if diff.BaseStats.TasksWithTokens > 0 || diff.HeadStats.TasksWithTokens > 0 {
-		fmt.Printf("| Tokens | %s | %s | %s |\n",
+		fmt.Fprintf(w, "| Tokens | %s | %s | %s |\n",
 			formatTokenCountOrNA(diff.BaseStats.TotalTokens, diff.BaseStats.TasksWithTokens),
 			formatTokenCountOrNA(diff.HeadStats.TotalTokens, diff.HeadStats.TasksWithTokens),
 			formatTokenChangeMarkdownWithCoverage(diff.BaseStats.TotalTokens, diff.BaseStats.TasksWithTokens, diff.HeadStats.TotalTokens, diff.HeadStats.TasksWithTokens))
-		fmt.Printf("| MCP Schema | %s | %s | %s |\n",
+		fmt.Fprintf(w, "| MCP Schema | %s | %s | %s |\n",
 			formatTokenCountOrNA(diff.BaseStats.McpSchemaTokens, diff.BaseStats.TasksWithTokens),
 			formatTokenCountOrNA(diff.HeadStats.McpSchemaTokens, diff.HeadStats.TasksWithTokens),
 			formatTokenChangeMarkdownWithCoverage(diff.BaseStats.McpSchemaTokens, diff.BaseStats.TasksWithTokens, diff.HeadStats.McpSchemaTokens, diff.HeadStats.TasksWithTokens))
 		if diff.TokenDataIncomplete {
-			fmt.Println("\n> ⚠️ Token counts may be incomplete due to errors during token estimation")
+			fmt.Fprintln(w, "\n> ⚠️ Token counts may be incomplete due to errors during token estimation")
 		}
 	}
 
 	// Regressions
 	if len(diff.Regressions) > 0 {
-		fmt.Println()
-		fmt.Printf("#### ❌ Regressions (%d)\n", len(diff.Regressions))
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "#### ❌ Regressions (%d)\n", len(diff.Regressions))
 		for _, r := range diff.Regressions {
-			fmt.Printf("- `%s`: PASSED → FAILED", r.TaskName)
+			fmt.Fprintf(w, "- `%s`: PASSED → FAILED", r.TaskName)
 			if r.FailureReason != "" {
-				fmt.Printf(" - %s", r.FailureReason)
+				fmt.Fprintf(w, " - %s", r.FailureReason)
 			}
-			fmt.Println()
+			fmt.Fprintln(w)
 		}
 	}
 
 	// Improvements
 	if len(diff.Improvements) > 0 {
-		fmt.Println()
-		fmt.Printf("#### ✅ Improvements (%d)\n", len(diff.Improvements))
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "#### ✅ Improvements (%d)\n", len(diff.Improvements))
 		for _, r := range diff.Improvements {
-			fmt.Printf("- `%s`: FAILED → PASSED\n", r.TaskName)
+			fmt.Fprintf(w, "- `%s`: FAILED → PASSED\n", r.TaskName)
+		}
+	}
+
+	// Tasks with the same outcome whose assertions or tokens changed
+	if len(diff.Changed) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "#### 🔀 Changed (%d)\n", len(diff.Changed))
+		for _, r := range diff.Changed {
+			fmt.Fprintf(w, "- `%s`: %s, assertions %d/%d → %d/%d", r.TaskName, formatOutcome(r.HeadPassed),
+				r.BaseAssertions, r.BaseAssertionTotal, r.HeadAssertions, r.HeadAssertionTotal)
+			if r.BaseTokens != nil && r.HeadTokens != nil {
+				fmt.Fprintf(w, ", tokens %s", formatTokenChangeMarkdown(*r.BaseTokens, *r.HeadTokens))
+			}
+			fmt.Fprintln(w)
 		}
 	}
 
 	// New tasks
 	if len(diff.New) > 0 {
-		fmt.Println()
-		fmt.Printf("#### 🆕 New Tasks (%d)\n", len(diff.New))
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "#### 🆕 New Tasks (%d)\n", len(diff.New))
 		for _, r := range diff.New {
 			status := "PASSED"
 			if !r.HeadPassed {
 				status = "FAILED"
 			}
-			fmt.Printf("- `%s`: %s\n", r.TaskName, status)
+			fmt.Fprintf(w, "- `%s`: %s\n", r.TaskName, status)
 		}
 	}
 
 	// Removed tasks
 	if len(diff.Removed) > 0 {
-		fmt.Println()
-		fmt.Printf("#### 🗑️ Removed Tasks (%d)\n", len(diff.Removed))
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "#### 🗑️ Removed Tasks (%d)\n", len(diff.Removed))
 		for _, r := range diff.Removed {
-			fmt.Printf("- `%s`\n", r.TaskName)
+			fmt.Fprintf(w, "- `%s`\n", r.TaskName)
+		}
+	}
+
+	if diff.Significance.Repeated {
+		outputMarkdownSignificance(w, diff.Significance)
+	}
+
+	if diff.Regressed {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "**✗ Regression**")
+	}
+}
+
+// outputMarkdownSignificance prints a table of the tasks whose pass rate
+// changed across repeated runs, and whether each change is significant.
+func outputMarkdownSignificance(w io.Writer, c results.RunComparison) {
+	changed := changedPassRates(c.Tasks)
+	if len(changed) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "#### 📈 Pass Rate Changes (α=%g)\n", c.Alpha)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Task | Base | Head | Change | p-value |")
+	fmt.Fprintln(w, "|------|------|------|--------|---------|")
+	for _, t := range changed {
+		verdict := "may be noise"
+		if t.Significant {
+			verdict = "**significant**"
 		}
+		fmt.Fprintf(w, "| `%s` | %d/%d | %d/%d | %s | %.3f (%s) |\n", t.TaskName,
+			t.BasePassed, t.BaseRuns, t.HeadPassed, t.HeadRuns, formatChangeMarkdown(t.Change()), t.PValue, verdict)
 	}
 }
 
@@ -2,10 +2,13 @@ package cli
 
 import (
 	"bytes"
+	"encoding/json"
+	"io"
 	"strings"
 	"testing"
 
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
 	"github.com/mcpchecker/mcpchecker/pkg/tokens"
 )
 
@@ -47,6 +50,30 @@ func TestDiffCommandMarkdown(t *testing.T) {
 	}
 }
 
+func TestDiffCommandFailOnRegression(t *testing.T) {
+	baseFile := createTestResultsFile(t, sampleResultsImproved())
+	currentFile := createTestResultsFile(t, sampleResults())
+
+	cmd := NewDiffCmd()
+	cmd.SetArgs([]string{"--base", baseFile, "--current", currentFile, "--output", "json", "--fail-on-regression"})
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(new(bytes.Buffer))
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "regression: 1 task(s) newly failing") {
+		t.Fatalf("error = %v, want a regression", err)
+	}
+
+	var diff DiffResult
+	if err := json.Unmarshal(buf.Bytes(), &diff); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+	if !diff.Regressed || len(diff.Regressions) != 1 {
+		t.Errorf("unexpected diff: %s", buf.String())
+	}
+}
+
 func TestDiffCommandBaseNotFound(t *testing.T) {
 	currentResults := sampleResults()
 	currentFile := createTestResultsFile(t, currentResults)
@@ -83,7 +110,7 @@ func TestCalculateDiff(t *testing.T) {
 	baseResults := sampleResults()
 	headResults := sampleResultsImproved()
 
-	diff := calculateDiff("base.json", "head.json", baseResults, headResults)
+	diff := calculateDiff("base.json", "head.json", baseResults, headResults, results.DefaultAlpha)
 
 	// Check base stats
 	if diff.BaseStats.TasksTotal != 3 {
@@ -111,7 +138,7 @@ func TestCalculateDiffRegressions(t *testing.T) {
 	baseResults := sampleResultsImproved()
 	headResults := sampleResults()
 
-	diff := calculateDiff("base.json", "head.json", baseResults, headResults)
+	diff := calculateDiff("base.json", "head.json", baseResults, headResults, results.DefaultAlpha)
 
 	// Should have 1 regression (task-2 fails in head)
 	if len(diff.Regressions) != 1 {
@@ -125,9 +152,9 @@ func TestCalculateDiffRegressions(t *testing.T) {
 }
 
 func TestCalculateDiffNoChanges(t *testing.T) {
-	results := sampleResults()
+	evalResults := sampleResults()
 
-	diff := calculateDiff("base.json", "head.json", results, results)
+	diff := calculateDiff("base.json", "head.json", evalResults, evalResults, results.DefaultAlpha)
 
 	if len(diff.Regressions) != 0 {
 		t.Errorf("len(Regressions) = %d, want 0", len(diff.Regressions))
@@ -149,7 +176,7 @@ func TestCalculateDiffNoChanges(t *testing.T) {
 func TestCalculateDiffEmptyBase(t *testing.T) {
 	headResults := sampleResults()
 
-	diff := calculateDiff("base.json", "head.json", []*eval.EvalResult{}, headResults)
+	diff := calculateDiff("base.json", "head.json", []*eval.EvalResult{}, headResults, results.DefaultAlpha)
 
 	// All tasks in head should be "new"
 	if len(diff.New) != 3 {
@@ -160,7 +187,7 @@ func TestCalculateDiffEmptyBase(t *testing.T) {
 func TestCalculateDiffEmptyHead(t *testing.T) {
 	baseResults := sampleResults()
 
-	diff := calculateDiff("base.json", "head.json", baseResults, []*eval.EvalResult{})
+	diff := calculateDiff("base.json", "head.json", baseResults, []*eval.EvalResult{}, results.DefaultAlpha)
 
 	// All tasks in base should be "removed"
 	if len(diff.Removed) != 3 {
@@ -303,7 +330,7 @@ func TestCalculateDiffWithTokens(t *testing.T) {
 		},
 	}
 
-	diff := calculateDiff("base.json", "head.json", baseResults, headResults)
+	diff := calculateDiff("base.json", "head.json", baseResults, headResults, results.DefaultAlpha)
 
 	// Base: 10000 + 15000 = 25000
 	if diff.BaseStats.TotalTokens != 25000 {
@@ -370,3 +397,102 @@ func sampleResultsImproved() []*eval.EvalResult {
 		},
 	}
 }
+
+func TestCalculateDiffMatchesByTaskID(t *testing.T) {
+	baseResults := []*eval.EvalResult{
+		{TaskID: "pods-create", TaskName: "create pod", TaskPassed: false},
+		{TaskName: "list pods", TaskPassed: true, AllAssertionsPassed: true},
+		{TaskID: "old-id", TaskName: "delete pod", TaskPassed: true, AllAssertionsPassed: true},
+	}
+	headResults := []*eval.EvalResult{
+		// Renamed, but the ID is unchanged
+		{TaskID: "pods-create", TaskName: "create a pod", TaskPassed: true, AllAssertionsPassed: true},
+		// ID added since the base run, matched by name
+		{TaskID: "pods-list", TaskName: "list pods", TaskPassed: false},
+		// Same name but a different ID is a different task
+		{TaskID: "new-id", TaskName: "delete pod", TaskPassed: true, AllAssertionsPassed: true},
+	}
+
+	diff := calculateDiff("base.json", "head.json", baseResults, headResults, results.DefaultAlpha)
+
+	if len(diff.Improvements) != 1 || diff.Improvements[0].TaskID != "pods-create" {
+		t.Errorf("Improvements = %+v, want pods-create", diff.Improvements)
+	}
+	if len(diff.Regressions) != 1 || diff.Regressions[0].TaskName != "list pods" {
+		t.Errorf("Regressions = %+v, want list pods", diff.Regressions)
+	}
+	if len(diff.New) != 1 || diff.New[0].TaskID != "new-id" {
+		t.Errorf("New = %+v, want new-id", diff.New)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].TaskID != "old-id" {
+		t.Errorf("Removed = %+v, want old-id", diff.Removed)
+	}
+}
+
+func TestCalculateDiffGroupsRepeatedRuns(t *testing.T) {
+	baseResults := append(repeatedRuns("flaky", 3, 3), repeatedRuns("removed", 2, 2)...)
+	headResults := append(repeatedRuns("flaky", 3, 2), repeatedRuns("added", 2, 2)...)
+
+	diff := calculateDiff("base.json", "head.json", baseResults, headResults, results.DefaultAlpha)
+
+	if len(diff.Regressions) != 1 || diff.Regressions[0].TaskName != "flaky" {
+		t.Errorf("Regressions = %+v, want flaky once", diff.Regressions)
+	}
+	if len(diff.New) != 1 || !diff.New[0].HeadPassed {
+		t.Errorf("New = %+v, want added once", diff.New)
+	}
+	if len(diff.Removed) != 1 {
+		t.Errorf("Removed = %+v, want removed once", diff.Removed)
+	}
+}
+
+func TestOutputDiffSignificance(t *testing.T) {
+	runs := func(name string, passed, failed int) []*eval.EvalResult {
+		var evalResults []*eval.EvalResult
+		for i := 0; i < passed+failed; i++ {
+			evalResults = append(evalResults, &eval.EvalResult{TaskName: name, TaskPassed: i < passed, AllAssertionsPassed: i < passed})
+		}
+		return evalResults
+	}
+	base := append(runs("regressed", 5, 0), runs("flaky", 3, 2)...)
+	head := append(runs("regressed", 0, 5), runs("flaky", 2, 3)...)
+
+	diff := calculateDiff("base.json", "head.json", base, head, results.DefaultAlpha)
+
+	tests := map[string]struct {
+		output func(io.Writer, DiffResult)
+		want   []string
+	}{
+		"text": {
+			output: outputTextDiff,
+			want: []string{
+				"95% CI:      [49%, 94%]  [6%, 51%]   p=0.023, significant",
+				"regressed: 5/5 → 0/5 (-100.0%), p=0.008, significant",
+				"flaky: 3/5 → 2/5 (-20.0%), p=1.000, not significant",
+				"1 of 2 changed task(s) significant",
+			},
+		},
+		"markdown": {
+			output: outputMarkdownDiff,
+			want: []string{
+				"| Tasks 95% CI | [49%, 94%] | [6%, 51%] | p=0.023, significant |",
+				"| `regressed` | 5/5 | 0/5 | 🔴 -100.0% | 0.008 (**significant**) |",
+				"| `flaky` | 3/5 | 2/5 | 🔴 -20.0% | 1.000 (may be noise) |",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tc.output(&buf, diff)
+			output := buf.String()
+
+			for _, want := range tc.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("output missing %q:\n%s", want, output)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/spf13/cobra"
+)
+
+// NewExportCmd creates the export command
+func NewExportCmd() *cobra.Command {
+	var anonymize bool
+	var salt string
+	var outputFile string
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "export <results-file>",
+		Short: "Export a results file for sharing",
+		Long: `Export a results file, e.g. to share benchmark results outside of the
+organization that ran them.
+
+With --anonymize, prompts, agent and tool output, tool call arguments and
+results, error messages, hostnames, URLs and file paths are replaced by salted
+hashes ("anon:<hash>"). Task, tool and server names, models, pass/fail results,
+failure categories, token counts, sizes and timings are kept, so aggregate
+metrics can still be computed and compared. Raw agent updates are dropped.
+
+Equal values get equal hashes with the same salt, so exports made with the same
+--salt can be correlated. Without --salt a random salt is used.
+
+With --format csv or parquet, the results are exported as a flat table with a
+row per task run instead, for analysis in tools such as pandas: the task, its
+difficulty and labels, the agent and model, whether the run passed, the score
+(fraction of assertions passed), tokens, duration and tool call counts.
+
+The results file may be gzip-compressed or a run journal. The export is written
+to stdout, or to --output. JSON exports are gzip-compressed if the name of the
+output file ends in .gz.
+
+Example:
+  mcpchecker export --anonymize -o shared.json mcpchecker-k8s-out.json
+  mcpchecker export --format parquet -o runs.parquet mcpchecker-k8s-out.json`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var writeTable func(io.Writer, []results.Row) error
+			switch format {
+			case "json":
+			case "csv":
+				writeTable = results.WriteCSV
+			case "parquet":
+				writeTable = results.WriteParquet
+			default:
+				return fmt.Errorf("unknown format: %s", format)
+			}
+
+			output, err := results.LoadOutput(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to load results file: %w", err)
+			}
+
+			if anonymize {
+				if salt == "" {
+					b := make([]byte, 16)
+					if _, err := rand.Read(b); err != nil {
+						return fmt.Errorf("failed to generate salt: %w", err)
+					}
+					salt = hex.EncodeToString(b)
+				}
+				output, err = results.Anonymize(output, salt)
+				if err != nil {
+					return err
+				}
+			} else if cmd.Flags().Changed("salt") {
+				return fmt.Errorf("--salt requires --anonymize")
+			}
+
+			if writeTable != nil {
+				return exportTable(cmd.OutOrStdout(), outputFile, results.Rows(output), writeTable)
+			}
+			if outputFile != "" {
+				return saveOutputToFile(output, outputFile)
+			}
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(output)
+		},
+	}
+
+	cmd.Flags().BoolVar(&anonymize, "anonymize", false, "Hash prompts, outputs, tool payloads, hostnames and file paths")
+	cmd.Flags().StringVar(&salt, "salt", "", "Salt of the hashes of anonymized values (default: random)")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "File to write the export to (default: stdout)")
+	cmd.Flags().StringVar(&format, "format", "json", "Export format (json, csv, parquet)")
+
+	return cmd
+}
+
+// exportTable writes rows with write to outputFile, or to stdout if it is empty.
+func exportTable(stdout io.Writer, outputFile string, rows []results.Row, write func(io.Writer, []results.Row) error) error {
+	if outputFile == "" {
+		return write(stdout, rows)
+	}
+
+	file, err := os.Create(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	if err := write(file, rows); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputFile, err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task/importer"
+	"github.com/spf13/cobra"
+)
+
+// NewImportCmd creates the import command
+func NewImportCmd() *cobra.Command {
+	var format string
+	var outDir string
+	var opts importer.Options
+	var dryRun bool
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "import <file|dir>",
+		Short: "Translate the tasks of agent benchmarks into mcpchecker tasks",
+		Long: `Translate the tasks of public agent benchmarks into mcpchecker tasks, with
+a task file per benchmark task in --out. Given a directory, the JSON files
+under it are read, recursively; files and values that are not tasks of the
+format are ignored.
+
+Formats:
+  agentbench  Tasks of the OS interaction environment of AgentBench. Init
+              scripts become setup scripts, and the expected answer or check
+              scripts a verify script, which gets the agent's response as the
+              answer. Check script files are read from --scripts-dir.
+  tau-bench   Tasks of tau-bench, exported as JSON, and of tau2-bench. The
+              instructions of the simulated user become the prompt, and the
+              outputs, assertions and expected actions llmJudge verify steps.
+
+Benchmark tasks that can't be translated, such as AgentBench tasks that start
+background processes, are listed and skipped.
+
+Translated tasks run on the machine of mcpchecker, not in the environment of
+the benchmark: review their setup scripts before running them, and give the
+agent MCP servers with the tools the tasks need.
+
+Example:
+  mcpchecker import --format agentbench AgentBench/data/os_interaction/data/dev.json --scripts-dir AgentBench/data/os_interaction/scripts/dev --out tasks/agentbench
+  mcpchecker import --format tau-bench tau2-bench/data/tau2/domains/airline/tasks.json --out tasks/airline`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := importer.Import(format, args[0], opts)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			verb := "Imported"
+			if dryRun {
+				verb = "Would import"
+			}
+
+			for _, t := range result.Tasks {
+				path := filepath.Join(outDir, t.Name+".yaml")
+				if !dryRun {
+					if err := writeImportedTask(path, t.Data, force); err != nil {
+						return err
+					}
+				}
+				fmt.Fprintf(out, "%s %s from %s\n", verb, path, t.Source)
+			}
+			for _, skipped := range result.Skipped {
+				fmt.Fprintf(out, "Skipped %s: %s\n", skipped.Source, skipped.Reason)
+			}
+
+			if len(result.Tasks) == 0 && len(result.Skipped) == 0 {
+				fmt.Fprintf(out, "No %s tasks found in %s\n", format, args[0])
+			} else {
+				fmt.Fprintf(out, "\n%s %d task(s), skipped %d\n", verb, len(result.Tasks), len(result.Skipped))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "", "Format of the benchmark tasks (one of: "+strings.Join(importer.Formats(), ", ")+")")
+	cmd.Flags().StringVar(&outDir, "out", ".", "Directory to write the tasks to")
+	cmd.Flags().StringVar(&opts.ScriptsDir, "scripts-dir", "", "Directory that script files of the benchmark are relative to (default: the directory of each task file)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the tasks that would be imported without writing them")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite existing task files")
+	_ = cmd.MarkFlagRequired("format")
+
+	return cmd
+}
+
+// writeImportedTask writes the task data to path, unless a file exists there
+// and force is not set.
+func writeImportedTask(path string, data []byte, force bool) error {
+	if !force {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists, use --force to overwrite it", path)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write task %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+const tauBenchTasks = `[
+  {"user_id": "a", "instruction": "Cancel order #W1", "actions": [{"name": "cancel_pending_order", "kwargs": {"order_id": "#W1"}}], "outputs": []},
+  {"user_id": "b", "instruction": "Say hello", "actions": [], "outputs": []}
+]`
+
+func runImport(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := NewImportCmd()
+	cmd.SetArgs(args)
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestImportCmd(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "tasks_test.json")
+	if err := os.WriteFile(src, []byte(tauBenchTasks), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outDir := filepath.Join(dir, "tasks")
+	taskPath := filepath.Join(outDir, "tasks-test-0.yaml")
+
+	out, err := runImport(t, "--format", "tau-bench", "--out", outDir, "--dry-run", src)
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if !strings.Contains(out, "Would import "+taskPath) || !strings.Contains(out, "Skipped "+src+"[1]: task has no actions or outputs to verify") {
+		t.Errorf("unexpected dry run output:\n%s", out)
+	}
+	if _, err := os.Stat(outDir); !os.IsNotExist(err) {
+		t.Errorf("dry run wrote tasks")
+	}
+
+	out, err = runImport(t, "--format", "tau-bench", "--out", outDir, src)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if !strings.Contains(out, "Imported 1 task(s), skipped 1") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	cfg, err := task.FromFile(taskPath)
+	if err != nil {
+		t.Fatalf("imported task is invalid: %v", err)
+	}
+	if cfg.Spec.Prompt.Inline != "Cancel order #W1" {
+		t.Errorf("prompt = %q", cfg.Spec.Prompt.Inline)
+	}
+
+	if _, err := runImport(t, "--format", "tau-bench", "--out", outDir, src); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected an error for an existing task, got %v", err)
+	}
+	if _, err := runImport(t, "--format", "tau-bench", "--out", outDir, "--force", src); err != nil {
+		t.Errorf("import with --force failed: %v", err)
+	}
+}
+
+func TestImportCmdRequiresFormat(t *testing.T) {
+	if _, err := runImport(t, t.TempDir()); err == nil || !strings.Contains(err.Error(), `"format" not set`) {
+		t.Errorf("expected an error without --format, got %v", err)
+	}
+}
@@ -0,0 +1,349 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
+	"github.com/spf13/cobra"
+)
+
+// initOptions are the answers that an eval config is generated from.
+type initOptions struct {
+	Name     string
+	Agent    string
+	Model    string
+	Judge    bool
+	TasksDir string
+
+	// McpConfigFile is the path of the MCP config, relative to the eval config
+	McpConfigFile string
+
+	// Servers are the names of the enabled servers of the MCP config, sorted
+	Servers []string
+}
+
+// NewInitCmd creates the init command
+func NewInitCmd() *cobra.Command {
+	var dir string
+	var opts initOptions
+	var yes bool
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "init <mcp-config-file>",
+		Short: "Generate an eval config and task scaffolding for an MCP config",
+		Long: `Generate an eval.yaml for the MCP servers of an existing MCP config file
+(JSON or YAML, as used by Claude Code, Cursor and other MCP clients), with a
+starter task that exercises the first server.
+
+init asks for the eval name, the agent, whether to use an LLM judge and the
+task directory. Answers given as flags are not asked for, and --yes accepts
+the defaults for the rest.
+
+Example:
+  mcpchecker init .mcp.json
+  mcpchecker init mcp-config.yaml --dir evals --agent llm-agent --model openai:gpt-5 --yes`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mcpConfigFile := args[0]
+
+			config, err := mcpclient.ParseConfigFile(mcpConfigFile)
+			if err != nil {
+				return fmt.Errorf("failed to load MCP config: %w", err)
+			}
+			opts.Servers = slices.Sorted(maps.Keys(config.GetEnabledServers()))
+			if len(opts.Servers) == 0 {
+				return fmt.Errorf("no enabled servers in MCP config %s", mcpConfigFile)
+			}
+			opts.McpConfigFile, err = initRelativePath(dir, mcpConfigFile)
+			if err != nil {
+				return err
+			}
+
+			if opts.Name == "" {
+				opts.Name = defaultEvalName(dir)
+			}
+			if !yes {
+				changed := func(name string) bool { return cmd.Flags().Changed(name) }
+				if err := askInitOptions(cmd.InOrStdin(), cmd.OutOrStdout(), &opts, changed); err != nil {
+					return err
+				}
+			}
+			if err := opts.validate(); err != nil {
+				return err
+			}
+
+			files, err := opts.files()
+			if err != nil {
+				return err
+			}
+			if !force {
+				for _, name := range slices.Sorted(maps.Keys(files)) {
+					if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+						return fmt.Errorf("%s already exists (use --force to overwrite)", filepath.Join(dir, name))
+					}
+				}
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintln(out)
+			for _, name := range slices.Sorted(maps.Keys(files)) {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					return fmt.Errorf("failed to create directory for %s: %w", path, err)
+				}
+				if err := os.WriteFile(path, files[name], 0644); err != nil {
+					return fmt.Errorf("failed to write %s: %w", path, err)
+				}
+				fmt.Fprintf(out, "Created %s\n", path)
+			}
+
+			fmt.Fprintln(out, "\nNext steps:")
+			if opts.Judge {
+				fmt.Fprintln(out, "  - Set JUDGE_BASE_URL, JUDGE_API_KEY and JUDGE_MODEL_NAME for the LLM judge")
+			}
+			fmt.Fprintf(out, "  - Edit the starter task in %s, or add more tasks next to it\n", filepath.Join(dir, opts.TasksDir))
+			fmt.Fprintf(out, "  - Run the eval with: mcpchecker check %s\n", filepath.Join(dir, "eval.yaml"))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", ".", "Directory to write eval.yaml and the tasks to")
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Name of the eval (default: the name of --dir)")
+	cmd.Flags().StringVar(&opts.Agent, "agent", "claude-code", "Builtin agent to evaluate (claude-code, llm-agent)")
+	cmd.Flags().StringVar(&opts.Model, "model", "", "Model of the llm-agent, as provider:model (e.g. openai:gpt-5)")
+	cmd.Flags().BoolVar(&opts.Judge, "judge", true, "Verify the starter task with an LLM judge configured from JUDGE_* environment variables")
+	cmd.Flags().StringVar(&opts.TasksDir, "tasks-dir", "tasks", "Directory for tasks, relative to --dir")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Don't ask questions; use the flags and defaults")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite existing files")
+
+	return cmd
+}
+
+// askInitOptions asks for the options whose flags were not changed, reading
+// answers from in. An empty answer keeps the default, and so does the end of
+// in, so that init can run without a terminal.
+func askInitOptions(in io.Reader, out io.Writer, opts *initOptions, changed func(flag string) bool) error {
+	scanner := bufio.NewScanner(in)
+	// ask returns false if in ended
+	ask := func(prompt, def string) (string, bool) {
+		if def != "" {
+			prompt += " [" + def + "]"
+		}
+		fmt.Fprint(out, prompt+": ")
+		if !scanner.Scan() {
+			return def, false
+		}
+		if answer := strings.TrimSpace(scanner.Text()); answer != "" {
+			return answer, true
+		}
+		return def, true
+	}
+
+	fmt.Fprintf(out, "MCP servers: %s\n\n", strings.Join(opts.Servers, ", "))
+
+	if !changed("name") {
+		opts.Name, _ = ask("Eval name", opts.Name)
+	}
+	if !changed("agent") {
+		for {
+			answer, ok := ask("Agent (claude-code, llm-agent)", opts.Agent)
+			_, err := builtinAgent(answer)
+			if err == nil {
+				opts.Agent = answer
+				break
+			}
+			if !ok {
+				return err
+			}
+			fmt.Fprintln(out, err)
+		}
+	}
+	if b, err := builtinAgent(opts.Agent); err == nil && b.RequiresModel() && !changed("model") {
+		opts.Model, _ = ask("Model (provider:model, e.g. openai:gpt-5)", opts.Model)
+	}
+	if !changed("judge") {
+		def := "Y/n"
+		if !opts.Judge {
+			def = "y/N"
+		}
+		answer, _ := ask("Verify with an LLM judge configured from JUDGE_* environment variables?", def)
+		switch strings.ToLower(answer) {
+		case "y", "yes":
+			opts.Judge = true
+		case "n", "no":
+			opts.Judge = false
+		}
+	}
+	if !changed("tasks-dir") {
+		opts.TasksDir, _ = ask("Task directory", opts.TasksDir)
+	}
+
+	return scanner.Err()
+}
+
+// builtinAgent returns the builtin agent named name, with or without the
+// builtin. prefix.
+func builtinAgent(name string) (agent.BuiltinAgent, error) {
+	b, ok := agent.GetBuiltinType(strings.TrimPrefix(name, "builtin."))
+	if !ok {
+		return nil, fmt.Errorf("unknown agent %q: must be claude-code or llm-agent", name)
+	}
+	return b, nil
+}
+
+func (o *initOptions) validate() error {
+	if o.Name == "" {
+		return fmt.Errorf("an eval name is required (--name)")
+	}
+	b, err := builtinAgent(o.Agent)
+	if err != nil {
+		return err
+	}
+	if b.RequiresModel() && o.Model == "" {
+		return fmt.Errorf("agent %s requires a model (--model provider:model)", b.Name())
+	}
+	if o.TasksDir == "" || filepath.IsAbs(o.TasksDir) {
+		return fmt.Errorf("invalid task directory %q: must be relative to the eval config", o.TasksDir)
+	}
+	return nil
+}
+
+var nonNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// starterTaskName returns the name of the starter task of server.
+func starterTaskName(server string) string {
+	name := strings.Trim(nonNameChars.ReplaceAllString(strings.ToLower(server), "-"), "-")
+	if name == "" {
+		name = "server"
+	}
+	return "list-" + name + "-tools"
+}
+
+// files returns the generated files by their path relative to the eval config.
+func (o *initOptions) files() (map[string][]byte, error) {
+	b, err := builtinAgent(o.Agent)
+	if err != nil {
+		return nil, err
+	}
+	data := struct {
+		*initOptions
+		AgentType string
+		TaskName  string
+		TaskGlob  string
+		Server    string
+	}{
+		initOptions: o,
+		AgentType:   "builtin." + b.Name(),
+		TaskName:    starterTaskName(o.Servers[0]),
+		TaskGlob:    filepath.ToSlash(filepath.Join(o.TasksDir, "*", "*.yaml")),
+		Server:      o.Servers[0],
+	}
+
+	files := make(map[string][]byte)
+	for name, tmpl := range map[string]*template.Template{
+		"eval.yaml": initEvalTemplate,
+		filepath.Join(o.TasksDir, data.TaskName, data.TaskName+".yaml"): initTaskTemplate,
+	} {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to generate %s: %w", name, err)
+		}
+		files[name] = buf.Bytes()
+	}
+	return files, nil
+}
+
+// initRelativePath returns path relative to dir, or its absolute path if it
+// can't be made relative.
+func initRelativePath(dir, path string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(absDir, absPath); err == nil {
+		return filepath.ToSlash(rel), nil
+	}
+	return absPath, nil
+}
+
+// defaultEvalName returns the name of dir, for the default name of its eval.
+func defaultEvalName(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "my-eval"
+	}
+	name := strings.Trim(nonNameChars.ReplaceAllString(strings.ToLower(filepath.Base(abs)), "-"), "-")
+	if name == "" {
+		return "my-eval"
+	}
+	return name
+}
+
+var initFuncs = template.FuncMap{"quote": strconv.Quote}
+
+var initEvalTemplate = template.Must(template.New("eval").Funcs(initFuncs).Parse(`kind: Eval
+metadata:
+  name: {{ quote .Name }}
+config:
+  agent:
+    type: {{ quote .AgentType }}
+{{- if .Model }}
+    model: {{ quote .Model }}
+{{- end }}
+  mcpConfigFile: {{ quote .McpConfigFile }}
+{{- if .Judge }}
+  llmJudge:
+    env:
+      baseUrlKey: JUDGE_BASE_URL
+      apiKeyKey: JUDGE_API_KEY
+      modelNameKey: JUDGE_MODEL_NAME
+{{- end }}
+  taskSets:
+    - glob: {{ quote .TaskGlob }}
+      assertions:
+        # The agent must call at least one tool of the MCP servers
+        minToolCalls: 1
+{{- if eq (len .Servers) 1 }}
+        toolsUsed:
+          - server: {{ quote .Server }}
+            toolPattern: ".*"
+{{- end }}
+`))
+
+var initTaskTemplate = template.Must(template.New("task").Funcs(initFuncs).Parse(`kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: {{ quote .TaskName }}
+  difficulty: easy
+spec:
+  prompt:
+    inline: {{ quote (printf "List the tools of the %s MCP server, then call one of them that only reads data and summarize what it returned." .Server) }}
+{{- if .Judge }}
+  verify:
+    - llmJudge:
+        contains: {{ quote (printf "A summary of data returned by a tool of the %s MCP server" .Server) }}
+{{- else }}
+  # Add verify steps that check the outcome of the task, e.g.
+  # verify:
+  #   - script:
+  #       inline: test -f /tmp/expected-output
+{{- end }}
+`))
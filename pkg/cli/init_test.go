@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+func writeInitMCPConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mcp.json")
+	config := `{
+  "mcpServers": {
+    "kubernetes": {"type": "http", "url": "http://localhost:8080/mcp"},
+    "old": {"command": "old-server", "disabled": true}
+  }
+}`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// loadInitEval loads the eval config generated in dir and the tasks its task
+// set matches.
+func loadInitEval(t *testing.T, dir string) (*eval.EvalSpec, []*task.TaskConfig) {
+	t.Helper()
+	spec, err := eval.FromFile(filepath.Join(dir, "eval.yaml"))
+	if err != nil {
+		t.Fatalf("generated eval config is invalid: %v", err)
+	}
+	if len(spec.Config.TaskSets) != 1 {
+		t.Fatalf("expected one task set, got %d", len(spec.Config.TaskSets))
+	}
+	glob := spec.Config.TaskSets[0].Glob
+	if !filepath.IsAbs(glob) {
+		glob = filepath.Join(dir, glob)
+	}
+	paths, err := filepath.Glob(glob)
+	if err != nil || len(paths) != 1 {
+		t.Fatalf("expected the task set to match the starter task, got %v (%v)", paths, err)
+	}
+	taskConfig, err := task.FromFile(paths[0])
+	if err != nil {
+		t.Fatalf("generated task is invalid: %v", err)
+	}
+	return spec, []*task.TaskConfig{taskConfig}
+}
+
+func TestInitInteractive(t *testing.T) {
+	mcpConfig := writeInitMCPConfig(t)
+	dir := filepath.Join(t.TempDir(), "evals")
+
+	cmd := NewInitCmd()
+	cmd.SetArgs([]string{mcpConfig, "--dir", dir})
+	// An unknown agent is asked for again
+	cmd.SetIn(strings.NewReader("demo\ncodex\nllm-agent\nopenai:gpt-5\nn\nsuite\n"))
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "MCP servers: kubernetes\n") {
+		t.Errorf("expected the enabled servers to be listed:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), `unknown agent "codex"`) {
+		t.Errorf("expected the unknown agent to be reported:\n%s", out.String())
+	}
+
+	spec, tasks := loadInitEval(t, dir)
+	if spec.Metadata.Name != "demo" {
+		t.Errorf("name = %q, want demo", spec.Metadata.Name)
+	}
+	if spec.Config.Agent.Type != "builtin.llm-agent" || spec.Config.Agent.Model != "openai:gpt-5" {
+		t.Errorf("agent = %+v, want builtin.llm-agent with openai:gpt-5", spec.Config.Agent)
+	}
+	if spec.Config.LLMJudge != nil {
+		t.Errorf("expected no LLM judge, got %+v", spec.Config.LLMJudge)
+	}
+	if !strings.HasSuffix(filepath.ToSlash(spec.Config.McpConfigFile), "/mcp.json") {
+		t.Errorf("mcpConfigFile = %q, want the MCP config", spec.Config.McpConfigFile)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "suite", "list-kubernetes-tools", "list-kubernetes-tools.yaml")); err != nil {
+		t.Errorf("expected the starter task in the task directory: %v", err)
+	}
+	if tasks[0].Spec.Verify != nil {
+		t.Errorf("expected no verify steps without a judge, got %+v", tasks[0].Spec.Verify)
+	}
+}
+
+func TestInitDefaults(t *testing.T) {
+	mcpConfig := writeInitMCPConfig(t)
+	dir := filepath.Join(t.TempDir(), "My Evals")
+
+	cmd := NewInitCmd()
+	cmd.SetArgs([]string{mcpConfig, "--dir", dir, "--yes"})
+	cmd.SetOut(new(bytes.Buffer))
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+
+	spec, tasks := loadInitEval(t, dir)
+	if spec.Metadata.Name != "my-evals" {
+		t.Errorf("name = %q, want the name of the directory", spec.Metadata.Name)
+	}
+	if spec.Config.Agent.Type != "builtin.claude-code" {
+		t.Errorf("agent type = %q, want builtin.claude-code", spec.Config.Agent.Type)
+	}
+	if spec.Config.LLMJudge == nil {
+		t.Error("expected an LLM judge")
+	}
+	if tasks[0].Metadata.Name != "list-kubernetes-tools" {
+		t.Errorf("task name = %q, want list-kubernetes-tools", tasks[0].Metadata.Name)
+	}
+	if len(tasks[0].Spec.Verify) != 1 {
+		t.Errorf("expected the starter task to be verified by the judge, got %+v", tasks[0].Spec.Verify)
+	}
+}
+
+func TestInitErrors(t *testing.T) {
+	mcpConfig := writeInitMCPConfig(t)
+	existing := t.TempDir()
+	if err := os.WriteFile(filepath.Join(existing, "eval.yaml"), []byte("kind: Eval\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	invalidConfig := filepath.Join(t.TempDir(), "mcp.json")
+	if err := os.WriteFile(invalidConfig, []byte(`{"servers": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"existing eval config": {
+			args:    []string{mcpConfig, "--dir", existing, "--yes"},
+			wantErr: "already exists (use --force to overwrite)",
+		},
+		"llm-agent without a model": {
+			args:    []string{mcpConfig, "--dir", t.TempDir(), "--agent", "llm-agent", "--yes"},
+			wantErr: "agent llm-agent requires a model",
+		},
+		"unknown agent": {
+			args:    []string{mcpConfig, "--dir", t.TempDir(), "--agent", "codex", "--yes"},
+			wantErr: `unknown agent "codex"`,
+		},
+		"invalid MCP config": {
+			args:    []string{invalidConfig, "--yes"},
+			wantErr: "failed to load MCP config",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := NewInitCmd()
+			cmd.SetArgs(tc.args)
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+			err := cmd.Execute()
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
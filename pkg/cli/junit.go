@@ -28,6 +28,7 @@ type junitTestSuite struct {
 	Tests    int             `xml:"tests,attr"`
 	Failures int             `xml:"failures,attr"`
 	Errors   int             `xml:"errors,attr"`
+	Skipped  int             `xml:"skipped,attr"`
 	Cases    []junitTestCase `xml:"testcase"`
 }
 
@@ -36,9 +37,14 @@ type junitTestCase struct {
 	Classname string        `xml:"classname,attr"`
 	Failure   *junitFailure `xml:"failure,omitempty"`
 	Error     *junitError   `xml:"error,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
 	SystemOut string        `xml:"system-out,omitempty"`
 }
 
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
 type junitFailure struct {
 	Message string `xml:"message,attr"`
 	Type    string `xml:"type,attr"`
@@ -83,26 +89,15 @@ Example:
 				return fmt.Errorf("no tasks matched filter %q", taskFilter)
 			}
 
-			opts := viewOptions{
-				showTimeline:   true,
-				maxEvents:      defaultMaxEvents,
-				maxOutputLines: defaultMaxOutputLines,
-				maxLineLength:  defaultMaxLineLength,
+			if outputFile != "" {
+				return writeJUnitFile(outputFile, filtered)
 			}
-			data, err := convertJUnit(filtered, opts)
+			data, err := convertJUnit(filtered, junitViewOptions())
 			if err != nil {
 				return err
 			}
-
-			if outputFile != "" {
-				if err := os.WriteFile(outputFile, data, 0o644); err != nil {
-					return fmt.Errorf("failed to write output file %q: %w", outputFile, err)
-				}
-			} else {
-				_, err = cmd.OutOrStdout().Write(data)
-				return err
-			}
-			return nil
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
 		},
 	}
 
@@ -111,6 +106,29 @@ Example:
 	return cmd
 }
 
+// junitViewOptions returns the options of the output rendered in JUnit test
+// cases.
+func junitViewOptions() viewOptions {
+	return viewOptions{
+		showTimeline:   true,
+		maxEvents:      defaultMaxEvents,
+		maxOutputLines: defaultMaxOutputLines,
+		maxLineLength:  defaultMaxLineLength,
+	}
+}
+
+// writeJUnitFile writes eval results to path as JUnit XML.
+func writeJUnitFile(path string, evalResults []*eval.EvalResult) error {
+	data, err := convertJUnit(evalResults, junitViewOptions())
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write output file %q: %w", path, err)
+	}
+	return nil
+}
+
 // convertJUnit converts eval results to JUnit XML format.
 func convertJUnit(evalResults []*eval.EvalResult, opts viewOptions) ([]byte, error) {
 	suite := buildJUnitSuite(evalResults, opts)
@@ -139,12 +157,27 @@ func buildJUnitSuite(evalResults []*eval.EvalResult, opts viewOptions) junitTest
 		}
 
 		switch {
+		case results.SkipReason(result) != "":
+			suite.Skipped++
+			tc.Skipped = &junitSkipped{Message: result.TaskError}
+
+		case results.XFailed(result):
+			// Expected failures are reported as skipped, like pytest does
+			suite.Skipped++
+			msg := "expected failure"
+			if result.ExpectFailureReason != "" {
+				msg += ": " + result.ExpectFailureReason
+			}
+			tc.Skipped = &junitSkipped{Message: sanitizeXMLString(msg)}
+
 		case !result.TaskPassed:
 			// Execution error (agent error, timeout, verification failure)
 			suite.Errors++
 			msg := result.TaskError
 			errType := "ExecutionError"
-			if result.AgentExecutionError {
+			if result.JudgeError {
+				errType = "JudgeError"
+			} else if result.AgentExecutionError {
 				errType = "AgentExecutionError"
 			} else if result.TimedOut {
 				errType = "Timeout"
@@ -193,11 +226,12 @@ func renderEvalResult(result *eval.EvalResult, opts viewOptions) string {
 	// Also include output from scripts such as setup, verify and cleanup which may contain useful information about failures.
 	// This is not included in the default "result view" output but can be helpful in CI contexts.
 	containsOutput := func(phase *task.PhaseOutput) bool { return phase != nil && len(phase.Steps) > 0 }
-	if containsOutput(result.SetupOutput) || containsOutput(result.VerifyOutput) || containsOutput(result.CleanupOutput) {
+	if containsOutput(result.SetupOutput) || containsOutput(result.VerifyOutput) || containsOutput(result.CleanupOutput) || containsOutput(result.CleanupVerifyOutput) {
 		fmt.Fprintf(&buf, "Steps output:\n")
 		printPhaseOutput(&buf, "Setup", result.SetupOutput)
 		printPhaseOutput(&buf, "Verify", result.VerifyOutput)
 		printPhaseOutput(&buf, "Cleanup", result.CleanupOutput)
+		printPhaseOutput(&buf, "CleanupVerify", result.CleanupVerifyOutput)
 	}
 
 	return sanitizeXMLString(buf.String())
@@ -220,6 +254,12 @@ func printPhaseOutput(w io.Writer, label string, phase *task.PhaseOutput) {
 			fmt.Fprintf(w, "--- %s (stderr) ---\n", label)
 			fmt.Fprintf(w, "%s\n", indentBlock(step.Error, "  "))
 		}
+		if len(step.Logs) > 0 {
+			fmt.Fprintf(w, "--- %s (logs) ---\n", label)
+			for _, log := range step.Logs {
+				fmt.Fprintf(w, "  [%s] %s\n", log.Level, log.Message)
+			}
+		}
 	}
 }
 
@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
 )
 
 func TestJUnitCommand(t *testing.T) {
@@ -241,6 +243,30 @@ func TestBuildJUnitSuiteTimeout(t *testing.T) {
 	}
 }
 
+func TestBuildJUnitSuiteSkipped(t *testing.T) {
+	results := []*eval.EvalResult{
+		{
+			TaskName:   "skipped",
+			TaskPath:   "/path/to/task.yaml",
+			TaskError:  "skipped: missing MCP server \"github\"",
+			Skipped:    true,
+			SkipReason: eval.SkipReasonRequirementsUnmet,
+		},
+	}
+
+	suite := buildJUnitSuite(results, viewOptions{})
+
+	if suite.Skipped != 1 || suite.Errors != 0 {
+		t.Errorf("Skipped = %d, Errors = %d, want 1 and 0", suite.Skipped, suite.Errors)
+	}
+	if suite.Cases[0].Skipped == nil {
+		t.Fatal("skipped test case should have a Skipped element")
+	}
+	if suite.Cases[0].Skipped.Message != results[0].TaskError {
+		t.Errorf("Skipped.Message = %q, want %q", suite.Cases[0].Skipped.Message, results[0].TaskError)
+	}
+}
+
 func TestBuildJUnitSuiteNilAssertionResults(t *testing.T) {
 	results := []*eval.EvalResult{
 		{
@@ -305,6 +331,30 @@ func TestBuildJUnitSuiteSystemOut(t *testing.T) {
 	}
 }
 
+func TestPrintPhaseOutputLogs(t *testing.T) {
+	phase := &task.PhaseOutput{
+		Steps: []*steps.StepOutput{{
+			Type:  "kubernetes.apply",
+			Error: "apply failed",
+			Logs: []steps.StepLog{
+				{Level: "info", Message: "applying manifest"},
+				{Level: "error", Message: "namespace not found"},
+			},
+		}},
+	}
+
+	var buf bytes.Buffer
+	printPhaseOutput(&buf, "Setup", phase)
+
+	out := buf.String()
+	if !strings.Contains(out, "--- Setup (logs) ---") {
+		t.Errorf("output should contain a logs section, got:\n%s", out)
+	}
+	if !strings.Contains(out, "[error] namespace not found") {
+		t.Errorf("output should contain the extension logs, got:\n%s", out)
+	}
+}
+
 func TestConvertJUnitXMLStructure(t *testing.T) {
 	results := sampleResults()
 
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/spf13/cobra"
+)
+
+// NewMetricsCmd creates the metrics command
+func NewMetricsCmd() *cobra.Command {
+	var labels []string
+	var outputFile string
+
+	cmd := &cobra.Command{
+		Use:   "metrics <results-file>",
+		Short: "Print the metrics of a results file as a flat JSON document",
+		Long: `Print the metrics of a results file as a flat JSON document of gauges, each
+with its name, labels and value, for dashboards that pull data, such as
+Grafana with a JSON datasource, or telegraf with the json_v2 parser.
+
+Metrics of the whole eval are named mcpchecker_eval_*, and metrics of each task
+mcpchecker_task_*, labeled with the task and its difficulty. All metrics are
+labeled with the agent and model of the run, and with --label.
+
+The results file may be gzip-compressed or a run journal. The document is
+written to stdout, or to --output, e.g. in a directory served to Grafana.
+
+Example:
+  mcpchecker metrics mcpchecker-k8s-out.json
+  mcpchecker metrics --label env=nightly -o /srv/metrics/k8s.json mcpchecker-k8s-out.json`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			extraLabels := make(map[string]string, len(labels))
+			for _, label := range labels {
+				key, value, ok := strings.Cut(label, "=")
+				if !ok || key == "" {
+					return fmt.Errorf("invalid label %q: must be key=value", label)
+				}
+				extraLabels[key] = value
+			}
+
+			output, err := results.LoadOutput(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to load results file: %w", err)
+			}
+
+			snapshot := results.Metrics(output, extraLabels)
+			if outputFile == "" {
+				return writeMetrics(cmd.OutOrStdout(), snapshot)
+			}
+
+			file, err := os.Create(outputFile)
+			if err != nil {
+				return fmt.Errorf("failed to create file: %w", err)
+			}
+			defer file.Close()
+			if err := writeMetrics(file, snapshot); err != nil {
+				return fmt.Errorf("failed to write %s: %w", outputFile, err)
+			}
+			return file.Close()
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&labels, "label", nil, "Add a key=value label to all metrics (repeatable)")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "File to write the metrics to (default: stdout)")
+
+	return cmd
+}
+
+func writeMetrics(w io.Writer, snapshot results.MetricsSnapshot) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(snapshot)
+}
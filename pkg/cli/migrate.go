@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// NewMigrateCmd creates the migrate parent command
+func NewMigrateCmd() *cobra.Command {
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Commands for migrating configs from deprecated formats",
+		Long:  `Commands for migrating configs from deprecated formats to the current ones.`,
+	}
+
+	migrateCmd.AddCommand(NewMigrateTaskCmd())
+
+	return migrateCmd
+}
+
+// NewMigrateTaskCmd creates the migrate task command
+func NewMigrateTaskCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "task <file|dir>",
+		Short: "Rewrite v1alpha1 tasks in the current task format",
+		Long: `Rewrite tasks in the deprecated mcpchecker/v1alpha1 format, with a single
+script per phase under steps, in the mcpchecker/v1alpha2 format, with lists
+of setup, verify and cleanup steps under spec. Tasks are rewritten in place.
+
+Given a directory, all tasks in its YAML files are migrated, recursively.
+Files that are not tasks, and tasks already in the current format, are left
+unchanged.
+
+Comments are not kept, and keys are written in alphabetical order, so review
+the rewritten files before committing them.
+
+Example:
+  mcpchecker migrate task tasks/
+  mcpchecker migrate task tasks/create-pod.yaml --dry-run`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := args[0]
+			info, err := os.Stat(root)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			verb := "Migrated"
+			if dryRun {
+				verb = "Would migrate"
+			}
+
+			if !info.IsDir() {
+				migrated, err := migrateTaskFile(root, dryRun)
+				if err != nil {
+					return err
+				}
+				if migrated {
+					fmt.Fprintf(out, "%s %s\n", verb, root)
+				} else {
+					fmt.Fprintf(out, "%s is already in the %s format\n", root, util.APIVersionV1Alpha2)
+				}
+				return nil
+			}
+
+			var count int
+			err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() || (filepath.Ext(path) != ".yaml" && filepath.Ext(path) != ".yml") {
+					return nil
+				}
+				migrated, err := migrateTaskFile(path, dryRun)
+				if errors.Is(err, util.ErrWrongKind) {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				if migrated {
+					fmt.Fprintf(out, "%s %s\n", verb, path)
+					count++
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			if count == 0 {
+				fmt.Fprintf(out, "No %s tasks found in %s\n", util.APIVersionV1Alpha1, root)
+			} else {
+				fmt.Fprintf(out, "\n%s %d task(s)\n", verb, count)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the tasks that would be migrated without rewriting them")
+
+	return cmd
+}
+
+// migrateTaskFile rewrites the task at path in the current format unless
+// dryRun is set, returning whether it was in the v1alpha1 format.
+func migrateTaskFile(path string, dryRun bool) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read task %s: %w", path, err)
+	}
+
+	migrated, ok, err := task.Migrate(data)
+	if err != nil {
+		return false, fmt.Errorf("failed to migrate task %s: %w", path, err)
+	}
+	if !ok || dryRun {
+		return ok, nil
+	}
+
+	if _, err := task.Read(migrated, filepath.Dir(path)); err != nil {
+		return false, fmt.Errorf("migrated task %s is invalid: %w", path, err)
+	}
+	if err := os.WriteFile(path, migrated, info.Mode().Perm()); err != nil {
+		return false, fmt.Errorf("failed to write task %s: %w", path, err)
+	}
+	return true, nil
+}
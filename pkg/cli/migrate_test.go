@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+const legacyTask = `kind: Task
+metadata:
+  name: create-pod
+  difficulty: easy
+  labels:
+    suite: kubernetes
+steps:
+  setup:
+    file: setup.sh
+  verify:
+    contains: a running nginx pod
+  cleanup:
+    inline: kubectl delete pod web
+  prompt:
+    inline: Create an nginx pod named web
+`
+
+const currentTask = `kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: current
+spec:
+  prompt:
+    inline: Do something
+`
+
+func writeMigrateFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func runMigrateTask(t *testing.T, args ...string) string {
+	t.Helper()
+	cmd := NewMigrateTaskCmd()
+	cmd.SetArgs(args)
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("migrate task failed: %v", err)
+	}
+	return out.String()
+}
+
+func TestMigrateTaskDir(t *testing.T) {
+	dir := writeMigrateFiles(t, map[string]string{
+		"eval.yaml":                  "kind: Eval\nmetadata:\n  name: suite\n",
+		"tasks/create-pod/task.yaml": legacyTask,
+		"tasks/current/task.yaml":    currentTask,
+		"tasks/create-pod/setup.sh":  "kubectl create ns test\n",
+	})
+
+	out := runMigrateTask(t, dir)
+	legacyPath := filepath.Join(dir, "tasks", "create-pod", "task.yaml")
+	if !strings.Contains(out, "Migrated "+legacyPath+"\n") || !strings.Contains(out, "Migrated 1 task(s)") {
+		t.Errorf("expected only the legacy task to be migrated:\n%s", out)
+	}
+
+	migrated, err := task.FromFile(legacyPath)
+	if err != nil {
+		t.Fatalf("migrated task is invalid: %v", err)
+	}
+	if migrated.GetAPIVersion() != util.APIVersionV1Alpha2 {
+		t.Errorf("apiVersion = %q, want %q", migrated.GetAPIVersion(), util.APIVersionV1Alpha2)
+	}
+	if migrated.Metadata.Name != "create-pod" || migrated.Metadata.Labels["suite"] != "kubernetes" {
+		t.Errorf("expected the metadata to be kept, got %+v", migrated.Metadata)
+	}
+	spec := migrated.Spec
+	if len(spec.Setup) != 1 || string(spec.Setup[0].Config["script"]) != `{"file":"setup.sh"}` {
+		t.Errorf("setup = %v, want a script step with the relative setup.sh", spec.Setup)
+	}
+	if len(spec.Verify) != 1 || spec.Verify[0].Config["llmJudge"] == nil {
+		t.Errorf("verify = %v, want an llmJudge step", spec.Verify)
+	}
+	if len(spec.Cleanup) != 1 || string(spec.Cleanup[0].Config["script"]) != `{"inline":"kubectl delete pod web"}` {
+		t.Errorf("cleanup = %v, want an inline script step", spec.Cleanup)
+	}
+	if spec.Prompt.Inline != "Create an nginx pod named web" {
+		t.Errorf("prompt = %+v", spec.Prompt)
+	}
+
+	current, err := os.ReadFile(filepath.Join(dir, "tasks", "current", "task.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(current) != currentTask {
+		t.Errorf("expected the current task to be left unchanged, got:\n%s", current)
+	}
+
+	out = runMigrateTask(t, dir)
+	if !strings.Contains(out, "No mcpchecker/v1alpha1 tasks found") {
+		t.Errorf("expected nothing left to migrate:\n%s", out)
+	}
+}
+
+func TestMigrateTaskFile(t *testing.T) {
+	dir := writeMigrateFiles(t, map[string]string{
+		"legacy.yaml":  legacyTask,
+		"current.yaml": currentTask,
+		"eval.yaml":    "kind: Eval\nmetadata:\n  name: suite\n",
+	})
+	legacyPath := filepath.Join(dir, "legacy.yaml")
+
+	out := runMigrateTask(t, legacyPath, "--dry-run")
+	if out != "Would migrate "+legacyPath+"\n" {
+		t.Errorf("unexpected dry run output:\n%s", out)
+	}
+	data, err := os.ReadFile(legacyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != legacyTask {
+		t.Errorf("expected a dry run to leave the task unchanged, got:\n%s", data)
+	}
+
+	out = runMigrateTask(t, filepath.Join(dir, "current.yaml"))
+	if !strings.Contains(out, "is already in the mcpchecker/v1alpha2 format") {
+		t.Errorf("expected the current task to be reported as current:\n%s", out)
+	}
+
+	cmd := NewMigrateTaskCmd()
+	cmd.SetArgs([]string{filepath.Join(dir, "eval.yaml")})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "wrong kind") {
+		t.Errorf("expected migrating an eval config to fail, got %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
+	"github.com/mcpchecker/mcpchecker/pkg/mockagent"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// envMockScenario can be used instead of --scenario, which is convenient in agent
+// files because commands run from a temporary working directory.
+const envMockScenario = "MCPCHECKER_MOCK_SCENARIO"
+
+// NewMockAgentCmd creates the mock-agent command, a scripted agent for deterministic pipelines.
+func NewMockAgentCmd() *cobra.Command {
+	var (
+		scenarioPath  string
+		mcpConfigPath string
+		prompt        string
+		verbose       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "mock-agent",
+		Short: "Run a scripted agent from a YAML scenario",
+		Long: `Run a deterministic, scripted agent. The agent matches the prompt against the
+behaviors in a MockScenario file, makes the scripted tool calls against the MCP
+servers in the given config, and prints the final message.
+
+Use it as a file-type agent to validate eval configs, assertions, and judges in CI
+without calling a model:
+
+  kind: Agent
+  metadata:
+    name: mock
+  commands:
+    argTemplateMcpServer: "{{ .File }}"
+    runPrompt: mcpchecker mock-agent --mcp-config {{ .McpServerFileArgs }} --prompt "{{ .Prompt }}"
+
+Agent commands run in a temporary directory, so pass an absolute --scenario path
+or set ` + envMockScenario + `.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if scenarioPath == "" {
+				scenarioPath = os.Getenv(envMockScenario)
+			}
+			if scenarioPath == "" {
+				return fmt.Errorf("a scenario is required via --scenario or %s", envMockScenario)
+			}
+
+			scenario, err := mockagent.FromFile(scenarioPath)
+			if err != nil {
+				return err
+			}
+
+			var mcpConfig *mcpclient.MCPConfig
+			if mcpConfigPath != "" {
+				mcpConfig, err = mcpclient.ParseConfigFile(mcpConfigPath)
+				if err != nil {
+					return fmt.Errorf("failed to load mcp config: %w", err)
+				}
+			}
+
+			logOut := io.Discard
+			if verbose {
+				logOut = cmd.ErrOrStderr()
+			}
+
+			return mockagent.New(scenario, cmd.OutOrStdout(), logOut).Run(cmd.Context(), mcpConfig, prompt)
+		},
+	}
+
+	cmd.Flags().StringVar(&scenarioPath, "scenario", "", "Path to the MockScenario file (env: "+envMockScenario+")")
+	cmd.Flags().StringVar(&mcpConfigPath, "mcp-config", "", "Path to the MCP server config file")
+	cmd.Flags().StringVarP(&prompt, "prompt", "p", "", "Prompt to respond to")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Log tool calls to stderr")
+
+	cmd.AddCommand(newMockAgentFuzzCmd())
+
+	return cmd
+}
+
+// newMockAgentFuzzCmd creates the mock-agent fuzz command, which sends random schema-valid
+// tool call sequences through the proxy to find crashes and overly lenient assertions.
+func newMockAgentFuzzCmd() *cobra.Command {
+	var (
+		mcpConfigPath  string
+		assertionsPath string
+		outPath        string
+		seed           uint64
+		iterations     int
+		maxCalls       int
+		callTimeout    time.Duration
+		verbose        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "fuzz",
+		Short: "Send random, schema-valid tool calls to MCP servers through the proxy",
+		Long: `Generate random tool call sequences whose arguments are valid against each tool's
+input schema, and run them through the mcpchecker proxy against the configured MCP
+servers. The same --seed always produces the same sequences.
+
+A sequence fails when a call returns a protocol or transport error, times out, is
+not recorded by the proxy, or makes an assertion panic. Tool error results are
+valid responses and do not fail a sequence. The first failing sequence is
+minimized and written to --out as a MockScenario that the mock agent can replay.
+
+With --assertions, the assertions are evaluated for every sequence and the number
+of sequences each one passed is reported. An assertion that passes for random
+calls is likely too lenient.`,
+		Example: `  # Fuzz with a fixed seed
+  mcpchecker mock-agent fuzz --mcp-config mcp.json --seed 42
+
+  # Check how lenient a task's assertions are
+  mcpchecker mock-agent fuzz --mcp-config mcp.json --assertions assertions.yaml`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("seed") {
+				seed = uint64(time.Now().UnixNano())
+			}
+
+			mcpConfig, err := mcpclient.ParseConfigFile(mcpConfigPath)
+			if err != nil {
+				return fmt.Errorf("failed to load mcp config: %w", err)
+			}
+
+			opts := mockagent.FuzzOptions{
+				Seed:        seed,
+				Iterations:  iterations,
+				MaxCalls:    maxCalls,
+				CallTimeout: callTimeout,
+			}
+			if assertionsPath != "" {
+				opts.Assertions, err = loadTaskAssertions(assertionsPath)
+				if err != nil {
+					return err
+				}
+			}
+
+			logOut := io.Discard
+			if verbose {
+				logOut = cmd.ErrOrStderr()
+			}
+
+			ctx := cmd.Context()
+			manager, err := mcpclient.NewManager(ctx, mcpConfig)
+			if err != nil {
+				return fmt.Errorf("failed to connect to mcp servers: %w", err)
+			}
+			defer func() {
+				closeCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+				defer cancel()
+				_ = manager.Close(closeCtx)
+			}()
+
+			fuzzer, err := mockagent.NewFuzzer(ctx, manager, opts, logOut)
+			if err != nil {
+				return err
+			}
+
+			report, err := fuzzer.Run(ctx)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			printFuzzReport(out, report)
+
+			if report.Failure == nil {
+				return nil
+			}
+
+			if outPath == "" {
+				outPath = fmt.Sprintf("mock-scenario-fuzz-%d.yaml", report.Seed)
+			}
+			if err := report.Failure.Scenario(report.Seed).WriteFile(outPath); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "\nReproduction scenario written to %s\n", outPath)
+
+			return fmt.Errorf("fuzzing found a failure: %s", report.Failure.Reason)
+		},
+	}
+
+	cmd.Flags().StringVar(&mcpConfigPath, "mcp-config", "", "Path to the MCP server config file")
+	cmd.Flags().StringVar(&assertionsPath, "assertions", "", "Path to a YAML file of task assertions to evaluate for each sequence")
+	cmd.Flags().StringVarP(&outPath, "out", "o", "", "Path for the reproduction scenario (default mock-scenario-fuzz-<seed>.yaml)")
+	cmd.Flags().Uint64Var(&seed, "seed", 0, "Seed for generating call sequences (default: random, printed in the report)")
+	cmd.Flags().IntVar(&iterations, "iterations", mockagent.DefaultFuzzIterations, "Number of call sequences to run")
+	cmd.Flags().IntVar(&maxCalls, "max-calls", mockagent.DefaultFuzzMaxCalls, "Maximum number of tool calls per sequence")
+	cmd.Flags().DurationVar(&callTimeout, "call-timeout", mockagent.DefaultFuzzCallTimeout, "Timeout for each tool call")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Log each sequence to stderr")
+	_ = cmd.MarkFlagRequired("mcp-config")
+
+	return cmd
+}
+
+// loadTaskAssertions reads a YAML file containing task assertions.
+func loadTaskAssertions(path string) (*eval.TaskAssertions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read assertions file: %w", err)
+	}
+
+	assertions := &eval.TaskAssertions{}
+	if err := yaml.Unmarshal(data, assertions); err != nil {
+		return nil, fmt.Errorf("failed to parse assertions file: %w", err)
+	}
+
+	return assertions, nil
+}
+
+func printFuzzReport(w io.Writer, report *mockagent.FuzzReport) {
+	fmt.Fprintf(w, "Seed: %d\n", report.Seed)
+	fmt.Fprintf(w, "Sequences: %d (%d tool calls, %d tool error results)\n",
+		report.Iterations, report.ToolCalls, report.ToolErrors)
+
+	if len(report.SkippedTools) > 0 {
+		fmt.Fprintln(w, "\nSkipped tools:")
+		for _, name := range slices.Sorted(maps.Keys(report.SkippedTools)) {
+			fmt.Fprintf(w, "  %s: %s\n", name, report.SkippedTools[name])
+		}
+	}
+
+	if len(report.AssertionPasses) > 0 {
+		fmt.Fprintln(w, "\nAssertions passed by random sequences:")
+		for _, name := range slices.Sorted(maps.Keys(report.AssertionPasses)) {
+			passes := report.AssertionPasses[name]
+			fmt.Fprintf(w, "  %s: %d/%d", name, passes, report.Iterations)
+			if report.Iterations > 0 && passes == report.Iterations {
+				fmt.Fprint(w, " (always passes, may be too lenient)")
+			}
+			fmt.Fprintln(w)
+		}
+	}
+
+	if f := report.Failure; f != nil {
+		fmt.Fprintf(w, "\nFailure in sequence %d: %s\n", f.Iteration, f.Reason)
+		fmt.Fprintf(w, "Minimized from %d to %d calls:\n", f.OriginalCalls, len(f.Calls))
+		for _, c := range f.Calls {
+			fmt.Fprintf(w, "  %s::%s\n", c.Server, c.Tool)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+const defaultPager = "less"
+
+// writePaged writes content to w. When enabled and w is an interactive terminal,
+// the content is piped through the user's pager instead ($MCPCHECKER_PAGER, then
+// $PAGER, defaulting to less). If the pager cannot be started, the content is
+// written directly.
+func writePaged(w io.Writer, content []byte, enabled bool) error {
+	f, ok := w.(*os.File)
+	if !enabled || !ok || !isatty.IsTerminal(f.Fd()) {
+		_, err := w.Write(content)
+		return err
+	}
+
+	pager := resolvePager()
+	if pager == "" || pager == "cat" {
+		_, err := w.Write(content)
+		return err
+	}
+
+	args := strings.Fields(pager)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(content)
+	cmd.Stdout = f
+	cmd.Stderr = os.Stderr
+	// Like git, let less quit on short output, keep colors, and leave the screen intact
+	// unless the user has configured it otherwise.
+	if _, set := os.LookupEnv("LESS"); !set {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+
+	if err := cmd.Start(); err != nil {
+		_, err := w.Write(content)
+		return err
+	}
+
+	return cmd.Wait()
+}
+
+// resolvePager returns the pager command configured in the environment.
+func resolvePager() string {
+	if pager, ok := os.LookupEnv("MCPCHECKER_PAGER"); ok {
+		return strings.TrimSpace(pager)
+	}
+	if pager := strings.TrimSpace(os.Getenv("PAGER")); pager != "" {
+		return pager
+	}
+	return defaultPager
+}
@@ -0,0 +1,359 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/coder/acp-go-sdk"
+	"github.com/fatih/color"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// replayRuneDelay is the time it takes to type out one character of a
+	// message or thought at speed 1
+	replayRuneDelay = 10 * time.Millisecond
+	// replayMaxTypingTime caps the time it takes to type out one chunk of a
+	// message or thought at speed 1, so long chunks are typed out faster
+	replayMaxTypingTime = 2 * time.Second
+	// replayToolCallDelay is the pause after a tool call starts at speed 1
+	replayToolCallDelay = 800 * time.Millisecond
+	// replayToolResultDelay is the pause after a tool call ends at speed 1
+	replayToolResultDelay = 400 * time.Millisecond
+)
+
+// NewReplayCmd creates the replay command
+func NewReplayCmd() *cobra.Command {
+	var taskFilter string
+	var run int
+	var speed float64
+	var maxOutputLines int
+
+	cmd := &cobra.Command{
+		Use:   "replay <results-file>",
+		Short: "Play back the session of the agent in a task run in the terminal",
+		Long: `Play back the session of the agent in a task run as it happened: its thoughts
+and messages are typed out and its tool calls appear in turn with their input
+and output, so long sessions can be followed without reading the results JSON.
+
+The session is played back from the raw updates of the agent, which 'check'
+records with --capture-raw. Without them, the recorded output steps of the
+agent are played back, one step at a time.
+
+--speed sets the playback speed: 2 plays twice as fast, 0.5 at half the speed,
+and 0 prints the session without pauses. --task selects the tasks whose name
+contains its value, and --run a single run of tasks run several times
+(1-indexed). Without --run, every run is played back in turn.
+
+Example:
+  mcpchecker replay --task create-pod mcpchecker-k8s-out.json
+  mcpchecker replay --task create-pod --run 2 --speed 3 mcpchecker-k8s-out.json`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if speed < 0 {
+				return fmt.Errorf("--speed must not be negative")
+			}
+
+			output, err := results.LoadOutput(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to load results file: %w", err)
+			}
+
+			var runs []*eval.EvalResult
+			for _, r := range results.Filter(output.Results, taskFilter) {
+				if run > 0 && r.RunIndex+1 != run {
+					continue
+				}
+				runs = append(runs, r)
+			}
+			if len(runs) == 0 {
+				if run > 0 {
+					return fmt.Errorf("no run %d of a task matching %q in %s", run, taskFilter, args[0])
+				}
+				return fmt.Errorf("no task matching %q in %s", taskFilter, args[0])
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			player := &replayPlayer{
+				ctx:            ctx,
+				w:              cmd.OutOrStdout(),
+				speed:          speed,
+				maxOutputLines: maxOutputLines,
+			}
+			for i, r := range runs {
+				if i > 0 {
+					fmt.Fprintln(player.w)
+				}
+				frames, err := replayFrames(r)
+				if err != nil {
+					return err
+				}
+				if err := player.play(r, frames); err != nil {
+					if errors.Is(err, context.Canceled) {
+						// Interrupted by the user
+						return nil
+					}
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&taskFilter, "task", "", "Only play back tasks whose name contains this value")
+	cmd.Flags().IntVar(&run, "run", 0, "Only play back this run of each task, 1-indexed (default: all runs)")
+	cmd.Flags().Float64Var(&speed, "speed", 1, "Playback speed; 0 prints the session without pauses")
+	cmd.Flags().IntVar(&maxOutputLines, "max-output-lines", 10, "Maximum lines of tool output to show per tool call (0 for no limit)")
+	_ = cmd.MarkFlagRequired("task")
+
+	return cmd
+}
+
+// replayFrame is a step of the playback of a session: a chunk of a thought or
+// message of the agent or of a prompt, or the start or end of a tool call.
+type replayFrame struct {
+	Kind   string // "user", "thinking", "message", "tool_call" or "tool_result"
+	Text   string
+	Title  string
+	Status string
+	Input  string
+	Output string
+}
+
+// replayFrames returns the frames of the session of the agent in r, from its
+// raw updates if they were captured, or else from its output steps.
+func replayFrames(r *eval.EvalResult) ([]replayFrame, error) {
+	raw, err := r.RawUpdates.Decode()
+	if err != nil {
+		return nil, fmt.Errorf("task %s: %w", r.TaskName, err)
+	}
+	if len(raw) > 0 {
+		return rawUpdateFrames(raw), nil
+	}
+
+	if r.AgentOutput == nil || r.AgentOutput.AgentDetails == nil {
+		return nil, nil
+	}
+	var frames []replayFrame
+	for _, step := range r.AgentOutput.AgentDetails.OutputSteps {
+		switch {
+		case step.Type == "tool_call" && step.ToolCall != nil:
+			frames = append(frames,
+				replayFrame{Kind: "tool_call", Title: step.ToolCall.Title, Input: formatRawValue(step.ToolCall.RawInput)},
+				replayFrame{Kind: "tool_result", Title: step.ToolCall.Title, Status: step.ToolCall.Status, Output: formatRawValue(step.ToolCall.RawOutput)},
+			)
+		case step.Type == "thinking" || step.Type == "message":
+			frames = append(frames, replayFrame{Kind: step.Type, Text: step.Content})
+		}
+	}
+	return frames, nil
+}
+
+// rawUpdateFrames returns the frames of the ACP session updates in raw, in the
+// order the agent sent them. Updates that aren't ACP session updates, or don't
+// show anything, are skipped.
+func rawUpdateFrames(raw []json.RawMessage) []replayFrame {
+	var frames []replayFrame
+	titles := make(map[acp.ToolCallId]string)
+	ended := make(map[acp.ToolCallId]bool)
+
+	for _, data := range raw {
+		var update acp.SessionUpdate
+		if err := json.Unmarshal(data, &update); err != nil {
+			continue
+		}
+
+		switch {
+		case update.UserMessageChunk != nil && update.UserMessageChunk.Content.Text != nil:
+			frames = append(frames, replayFrame{Kind: "user", Text: update.UserMessageChunk.Content.Text.Text})
+		case update.AgentThoughtChunk != nil && update.AgentThoughtChunk.Content.Text != nil:
+			frames = append(frames, replayFrame{Kind: "thinking", Text: update.AgentThoughtChunk.Content.Text.Text})
+		case update.AgentMessageChunk != nil && update.AgentMessageChunk.Content.Text != nil:
+			frames = append(frames, replayFrame{Kind: "message", Text: update.AgentMessageChunk.Content.Text.Text})
+		case update.ToolCall != nil:
+			call := update.ToolCall
+			if _, started := titles[call.ToolCallId]; !started {
+				titles[call.ToolCallId] = call.Title
+				frames = append(frames, replayFrame{Kind: "tool_call", Title: call.Title, Input: formatRawValue(call.RawInput)})
+			}
+			if isFinalToolStatus(call.Status) && !ended[call.ToolCallId] {
+				ended[call.ToolCallId] = true
+				frames = append(frames, replayFrame{Kind: "tool_result", Title: call.Title, Status: string(call.Status), Output: formatRawValue(call.RawOutput)})
+			}
+		case update.ToolCallUpdate != nil:
+			call := update.ToolCallUpdate
+			if call.Title != nil {
+				titles[call.ToolCallId] = *call.Title
+			}
+			if call.Status == nil || !isFinalToolStatus(*call.Status) || ended[call.ToolCallId] {
+				continue
+			}
+			ended[call.ToolCallId] = true
+			frames = append(frames, replayFrame{Kind: "tool_result", Title: titles[call.ToolCallId], Status: string(*call.Status), Output: formatRawValue(call.RawOutput)})
+		}
+	}
+	return frames
+}
+
+// isFinalToolStatus reports whether a tool call with status has ended.
+func isFinalToolStatus(status acp.ToolCallStatus) bool {
+	return status == acp.ToolCallStatusCompleted || status == acp.ToolCallStatusFailed
+}
+
+// replayPlayer prints the frames of sessions with the pauses of their
+// playback. Pauses are scaled by 1/speed, and skipped with speed 0.
+type replayPlayer struct {
+	ctx            context.Context
+	w              io.Writer
+	speed          float64
+	maxOutputLines int
+
+	// kind is the kind of the last frame printed, so consecutive chunks of
+	// a message or thought are printed as one
+	kind string
+}
+
+// play prints the header of r, its frames and its outcome.
+func (p *replayPlayer) play(r *eval.EvalResult, frames []replayFrame) error {
+	title := r.TaskName
+	if r.TotalRuns > 1 {
+		title += fmt.Sprintf(" (run %d/%d)", r.RunIndex+1, r.TotalRuns)
+	}
+	color.New(color.Bold).Fprintf(p.w, "=== %s ===\n", title)
+	if prompt := strings.TrimSpace(resultPrompt(r)); prompt != "" {
+		color.New(color.FgCyan, color.Bold).Fprintln(p.w, "Prompt:")
+		fmt.Fprintln(p.w, indentBlock(prompt, "  "))
+	}
+	p.kind = ""
+
+	if len(frames) == 0 {
+		color.New(color.FgYellow).Fprintln(p.w, "\nNo agent session was recorded for this run")
+	}
+	for _, frame := range frames {
+		if err := p.playFrame(frame); err != nil {
+			return err
+		}
+	}
+	if p.kind == "user" || p.kind == "thinking" || p.kind == "message" {
+		fmt.Fprintln(p.w)
+	}
+
+	status, attr := resultStatus(r)
+	fmt.Fprintln(p.w)
+	color.New(attr, color.Bold).Fprintf(p.w, "Result: %s\n", status)
+	if taskErr := strings.TrimSpace(r.TaskError); taskErr != "" {
+		fmt.Fprintf(p.w, "  %s\n", taskErr)
+	}
+	return nil
+}
+
+func (p *replayPlayer) playFrame(frame replayFrame) error {
+	switch frame.Kind {
+	case "user", "thinking", "message":
+		if frame.Kind != p.kind {
+			if p.kind == "user" || p.kind == "thinking" || p.kind == "message" {
+				fmt.Fprintln(p.w)
+			}
+			fmt.Fprintln(p.w)
+			switch frame.Kind {
+			case "user":
+				color.New(color.FgCyan, color.Bold).Fprintln(p.w, "User:")
+			case "thinking":
+				color.New(color.Faint, color.Bold).Fprintln(p.w, "Thinking:")
+			case "message":
+				color.New(color.Bold).Fprintln(p.w, "Agent:")
+			}
+		}
+		p.kind = frame.Kind
+		text := color.New()
+		if frame.Kind == "thinking" {
+			text = color.New(color.Faint)
+		}
+		return p.typeOut(text, frame.Text)
+
+	case "tool_call":
+		if p.kind == "user" || p.kind == "thinking" || p.kind == "message" {
+			fmt.Fprintln(p.w)
+		}
+		p.kind = frame.Kind
+		fmt.Fprintln(p.w)
+		color.New(color.FgBlue, color.Bold).Fprintf(p.w, "→ %s\n", frame.Title)
+		if frame.Input != "" {
+			fmt.Fprintf(p.w, "  input: %s\n", truncateString(frame.Input, 200))
+		}
+		return p.pause(replayToolCallDelay)
+
+	case "tool_result":
+		p.kind = frame.Kind
+		mark, attr := "✓", color.FgGreen
+		if frame.Status == string(acp.ToolCallStatusFailed) {
+			mark, attr = "✗", color.FgRed
+		}
+		status := frame.Status
+		if status == "" {
+			status = "done"
+		}
+		color.New(attr).Fprintf(p.w, "  %s %s\n", mark, status)
+		if frame.Output != "" {
+			fmt.Fprintln(p.w, indentBlock(limitMultiline(frame.Output, p.maxOutputLines, 0), "    "))
+		}
+		return p.pause(replayToolResultDelay)
+	}
+	return nil
+}
+
+// typeOut writes text a few characters at a time, taking replayRuneDelay per
+// character and at most replayMaxTypingTime, scaled by the speed.
+func (p *replayPlayer) typeOut(c *color.Color, text string) error {
+	runes := []rune(text)
+	if p.speed == 0 || len(runes) == 0 {
+		c.Fprint(p.w, text)
+		return p.ctx.Err()
+	}
+
+	// Write pieces of several characters, so pauses stay long enough to
+	// sleep, and larger pieces for long texts
+	piece := 4
+	if pieces := int(replayMaxTypingTime / (replayRuneDelay * 4)); len(runes) > pieces*piece {
+		piece = (len(runes) + pieces - 1) / pieces
+	}
+	for start := 0; start < len(runes); start += piece {
+		end := min(start+piece, len(runes))
+		c.Fprint(p.w, string(runes[start:end]))
+		if err := p.pause(replayRuneDelay * 4); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pause waits for d scaled by the speed, or returns the error of the context
+// if it is done.
+func (p *replayPlayer) pause(d time.Duration) error {
+	if err := p.ctx.Err(); err != nil {
+		return err
+	}
+	if p.speed == 0 {
+		return nil
+	}
+	timer := time.NewTimer(time.Duration(float64(d) / p.speed))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	}
+}
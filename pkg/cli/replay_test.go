@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+const replayRawUpdates = `[
+  {"sessionUpdate": "agent_thought_chunk", "content": {"type": "text", "text": "I should "}},
+  {"sessionUpdate": "agent_thought_chunk", "content": {"type": "text", "text": "list the pods."}},
+  {"sessionUpdate": "tool_call", "toolCallId": "1", "title": "pods_list", "kind": "read", "status": "pending", "rawInput": {"namespace": "default"}},
+  {"sessionUpdate": "tool_call_update", "toolCallId": "1", "status": "in_progress"},
+  {"sessionUpdate": "tool_call_update", "toolCallId": "1", "status": "completed", "rawOutput": "web\napi\ndb"},
+  {"sessionUpdate": "tool_call", "toolCallId": "2", "title": "pods_delete", "status": "failed", "rawOutput": "forbidden"},
+  {"sessionUpdate": "agent_message_chunk", "content": {"type": "text", "text": "There are "}},
+  {"sessionUpdate": "agent_message_chunk", "content": {"type": "text", "text": "3 pods."}}
+]`
+
+func runReplay(t *testing.T, results []*eval.EvalResult, args ...string) (string, error) {
+	t.Helper()
+	cmd := NewReplayCmd()
+	cmd.SetArgs(append([]string{createTestResultsFile(t, results)}, args...))
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestReplayRawUpdates(t *testing.T) {
+	results := []*eval.EvalResult{{
+		TaskName:            "list-pods",
+		TaskPassed:          true,
+		AllAssertionsPassed: true,
+		TaskOutput:          "There are 3 pods.",
+		RawUpdates:          &eval.RawUpdates{Data: json.RawMessage(replayRawUpdates), Count: 8},
+	}}
+
+	out, err := runReplay(t, results, "--task", "list", "--speed", "0", "--max-output-lines", "2")
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+
+	want := `=== list-pods ===
+
+Thinking:
+I should list the pods.
+
+→ pods_list
+  input: {"namespace":"default"}
+  ✓ completed
+    web
+    api
+    … (+1 lines)
+
+→ pods_delete
+  ✗ failed
+    forbidden
+
+Agent:
+There are 3 pods.
+
+Result: PASSED
+`
+	if out != want {
+		t.Errorf("unexpected replay:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestReplayOutputSteps(t *testing.T) {
+	out, err := runReplay(t, transcriptResults(), "--task", "create", "--speed", "100")
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"=== create-pod (run 1/2) ===\nPrompt:\n  Create a pod named web in <ns>\n",
+		"Thinking:\nI should call pods_create.\n\nAgent:\nCreating the pod.\n",
+		"→ pods_create\n  input: {\"name\":\"web\"}\n  ✓ completed\n",
+		"Agent:\nCreated the pod.\n\nResult: PASSED\n",
+		"=== create-pod (run 2/2) ===\n",
+		"No agent session was recorded for this run\n",
+		"  agent timed out\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("replay is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestReplayErrors(t *testing.T) {
+	tests := map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"no matching task": {
+			args:    []string{"--task", "scale"},
+			wantErr: `no task matching "scale"`,
+		},
+		"negative speed": {
+			args:    []string{"--task", "create", "--speed", "-1"},
+			wantErr: "--speed must not be negative",
+		},
+		"task is required": {
+			args:    nil,
+			wantErr: `required flag(s) "task" not set`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := runReplay(t, transcriptResults(), tc.args...)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
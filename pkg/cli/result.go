@@ -18,6 +18,9 @@ These commands operate on the JSON result files produced by 'mcpchecker check'.`
 	resultCmd.AddCommand(NewVerifyCmd())
 	resultCmd.AddCommand(NewSummaryCmd())
 	resultCmd.AddCommand(NewDiffCmd())
+	resultCmd.AddCommand(NewAblationCmd())
+	resultCmd.AddCommand(NewCanaryCmd())
+	resultCmd.AddCommand(NewRobustnessCmd())
 	resultCmd.AddCommand(NewConvertCmd())
 
 	return resultCmd
@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/spf13/cobra"
+)
+
+// NewReviewCmd creates the review command
+func NewReviewCmd() *cobra.Command {
+	var outputFile string
+	var overridesFile string
+	var reviewer string
+
+	cmd := &cobra.Command{
+		Use:   "review <results-file>",
+		Short: "Review the task runs failed by the LLM judge",
+		Long: `Step through the task runs that failed only because of the LLM judge, and
+accept its verdict or override it to pass, with a note.
+
+The verdicts are appended to an overrides log and the results are written to
+an amended results file, in which the runs overridden to pass count as passed.
+Runs already in the overrides log are not reviewed again, so a review can be
+stopped and resumed. 'result diff' and 'result verify' apply overrides logs
+given with --overrides to the results they load.
+
+Example:
+  mcpchecker review mcpchecker-k8s-out.json
+  mcpchecker result diff --base mcpchecker-k8s-out.json --current results-pr.json --overrides mcpchecker-k8s-overrides.json`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resultsFile := args[0]
+			base := reviewBaseName(resultsFile)
+			if outputFile == "" {
+				outputFile = base + "-reviewed.json"
+				if strings.HasSuffix(resultsFile, ".gz") {
+					outputFile += ".gz"
+				}
+			}
+			if overridesFile == "" {
+				overridesFile = base + "-overrides.json"
+			}
+			if reviewer == "" {
+				reviewer = os.Getenv("USER")
+			}
+
+			output, err := results.LoadOutput(resultsFile)
+			if err != nil {
+				return fmt.Errorf("failed to load results file: %w", err)
+			}
+
+			// Runs in an existing log were reviewed in an earlier session
+			log := &results.OverridesLog{}
+			if _, err := os.Stat(overridesFile); err == nil {
+				log, err = results.LoadOverrides(overridesFile)
+				if err != nil {
+					return err
+				}
+				log.Apply(output.Results)
+			}
+
+			reviewed, err := reviewJudgeFailures(cmd.InOrStdin(), cmd.OutOrStdout(), output.Results, log, reviewer, time.Now)
+			if err != nil {
+				return err
+			}
+
+			if err := log.Save(overridesFile); err != nil {
+				return err
+			}
+			if err := saveOutputToFile(output, outputFile); err != nil {
+				return fmt.Errorf("failed to save amended results: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "\nReviewed %d task run(s)\nOverrides log: %s\nAmended results: %s\n", reviewed, overridesFile, outputFile)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Amended results file (default: <results-file>-reviewed.json)")
+	cmd.Flags().StringVar(&overridesFile, "overrides", "", "Overrides log to append the verdicts to (default: <results-file>-overrides.json)")
+	cmd.Flags().StringVar(&reviewer, "reviewer", "", "Name of the reviewer recorded with the verdicts (default: $USER)")
+
+	return cmd
+}
+
+// reviewBaseName returns the results file name without its .json and .gz extensions.
+func reviewBaseName(resultsFile string) string {
+	base := strings.TrimSuffix(resultsFile, ".gz")
+	return strings.TrimSuffix(base, ".json")
+}
+
+// loadResultsWithOverrides loads a results file and applies the verdicts of the
+// overrides logs at overridesFiles to its results.
+func loadResultsWithOverrides(resultsFile string, overridesFiles []string) ([]*eval.EvalResult, error) {
+	evalResults, err := results.Load(resultsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range overridesFiles {
+		log, err := results.LoadOverrides(path)
+		if err != nil {
+			return nil, err
+		}
+		log.Apply(evalResults)
+	}
+
+	return evalResults, nil
+}
+
+// reviewJudgeFailures asks the reviewer for a verdict on each judge-failed run
+// of evalResults that hasn't been reviewed yet, reading answers from in. The
+// verdicts are applied to the results and appended to log. It returns the
+// number of runs reviewed, stopping early when the reviewer quits or in ends.
+func reviewJudgeFailures(in io.Reader, out io.Writer, evalResults []*eval.EvalResult, log *results.OverridesLog, reviewer string, now func() time.Time) (int, error) {
+	var pending []*eval.EvalResult
+	for _, r := range evalResults {
+		if r.JudgeOverride == nil && results.JudgeFailed(r) {
+			pending = append(pending, r)
+		}
+	}
+
+	if len(pending) == 0 {
+		fmt.Fprintln(out, "No task runs left to review")
+		return 0, nil
+	}
+
+	bold := color.New(color.Bold)
+	scanner := bufio.NewScanner(in)
+	ask := func(prompt string) (string, bool) {
+		fmt.Fprint(out, prompt)
+		if !scanner.Scan() {
+			return "", false
+		}
+		return strings.TrimSpace(scanner.Text()), true
+	}
+
+	reviewed := 0
+	for i, r := range pending {
+		fmt.Fprintln(out)
+		_, _ = bold.Fprintf(out, "[%d/%d] %s", i+1, len(pending), r.TaskName)
+		if r.TotalRuns > 1 {
+			fmt.Fprintf(out, " (run %d/%d)", r.RunIndex+1, r.TotalRuns)
+		}
+		fmt.Fprintln(out)
+		printJudgeFailure(out, r)
+
+		action, ok := askReviewAction(ask, out)
+		if !ok {
+			return reviewed, scanner.Err()
+		}
+		switch action {
+		case reviewSkip:
+			continue
+		case reviewQuit:
+			return reviewed, nil
+		}
+
+		note, ok := ask("Note: ")
+		if !ok {
+			return reviewed, scanner.Err()
+		}
+
+		verdict := eval.JudgeOverride{
+			Passed:   action == reviewOverride,
+			Note:     note,
+			Reviewer: reviewer,
+			Time:     now().UTC(),
+		}
+		log.Overrides = append(log.Overrides, results.NewOverride(r, verdict))
+		results.ApplyOverride(r, verdict)
+		reviewed++
+	}
+
+	return reviewed, nil
+}
+
+type reviewAction string
+
+const (
+	reviewAccept   reviewAction = "accept"
+	reviewOverride reviewAction = "override"
+	reviewSkip     reviewAction = "skip"
+	reviewQuit     reviewAction = "quit"
+)
+
+// askReviewAction asks until the reviewer picks an action. It returns false if
+// the input ended.
+func askReviewAction(ask func(prompt string) (string, bool), out io.Writer) (reviewAction, bool) {
+	for {
+		choice, ok := ask("[a]ccept verdict, [o]verride to pass, [s]kip, [q]uit: ")
+		if !ok {
+			return "", false
+		}
+		choice = strings.ToLower(choice)
+		for _, action := range []reviewAction{reviewAccept, reviewOverride, reviewSkip, reviewQuit} {
+			if choice == string(action) || choice == string(action[:1]) {
+				return action, true
+			}
+		}
+		fmt.Fprintf(out, "Unknown choice %q\n", choice)
+	}
+}
+
+// printJudgeFailure prints what the reviewer needs to judge a run: the prompt
+// and output of the agent and the verdict of the judge.
+func printJudgeFailure(out io.Writer, r *eval.EvalResult) {
+	if prompt := resultPrompt(r); prompt != "" {
+		fmt.Fprintf(out, "Prompt:\n%s\n", indentBlock(prompt, "  "))
+	}
+	fmt.Fprintf(out, "Agent output:\n%s\n", indentBlock(strings.TrimRight(r.TaskOutput, "\n"), "  "))
+	for _, step := range r.VerifyOutput.Steps {
+		if step != nil && step.Type == "llmJudge" && !step.Success {
+			fmt.Fprintf(out, "Judge: %s\n", step.Error)
+		}
+	}
+	if r.TaskJudgeReason != "" {
+		fmt.Fprintf(out, "Judge reason:\n%s\n", indentBlock(strings.TrimRight(r.TaskJudgeReason, "\n"), "  "))
+	}
+	if r.JudgeAuditFile != "" {
+		fmt.Fprintf(out, "Judge audit log: %s\n", r.JudgeAuditFile)
+	}
+}
+
+// formatJudgeOverride describes the verdict of a reviewer for result views.
+func formatJudgeOverride(o *eval.JudgeOverride) string {
+	s := "judge verdict accepted"
+	if o.Passed {
+		s = "judge verdict overridden to pass"
+	}
+	if o.Reviewer != "" {
+		s += " by " + o.Reviewer
+	}
+	if o.Note != "" {
+		s += ": " + o.Note
+	}
+	return s
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+func judgeFailedEvalResult(name string) *eval.EvalResult {
+	return &eval.EvalResult{
+		TaskName:        name,
+		TaskOutput:      "output of " + name,
+		TaskError:       "one or more verification steps failed",
+		ErrorKind:       task.ErrorKindVerify,
+		TaskJudgeReason: "the response does not mention the replicas",
+		VerifyOutput: &task.PhaseOutput{Steps: []*steps.StepOutput{
+			{Type: "llmJudge", Success: false, Error: "llm judge failed for reason 'missing_information'"},
+		}},
+	}
+}
+
+func TestReviewJudgeFailures(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := map[string]struct {
+		input          string
+		expectReviewed int
+		expectPassed   []bool
+		expectNotes    []string
+	}{
+		"override, accept and skip": {
+			input:          "o\nreplicas are in the table\nbogus\na\nagreed\ns\n",
+			expectReviewed: 2,
+			expectPassed:   []bool{true, false, false},
+			expectNotes:    []string{"replicas are in the table", "agreed"},
+		},
+		"quit": {
+			input:          "a\n\nq\n",
+			expectReviewed: 1,
+			expectPassed:   []bool{false, false, false},
+			expectNotes:    []string{""},
+		},
+		"input ends": {
+			input:          "o\n",
+			expectReviewed: 0,
+			expectPassed:   []bool{false, false, false},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			evalResults := []*eval.EvalResult{
+				judgeFailedEvalResult("first"),
+				{TaskName: "passing", TaskPassed: true},
+				judgeFailedEvalResult("second"),
+				judgeFailedEvalResult("third"),
+			}
+			log := &results.OverridesLog{}
+			var out bytes.Buffer
+
+			reviewed, err := reviewJudgeFailures(strings.NewReader(tc.input), &out, evalResults, log, "alex", func() time.Time { return now })
+			if err != nil {
+				t.Fatalf("reviewJudgeFailures() error = %v", err)
+			}
+			if reviewed != tc.expectReviewed {
+				t.Errorf("reviewed = %d, want %d", reviewed, tc.expectReviewed)
+			}
+
+			for i, r := range []*eval.EvalResult{evalResults[0], evalResults[2], evalResults[3]} {
+				if r.TaskPassed != tc.expectPassed[i] {
+					t.Errorf("%s passed = %v, want %v", r.TaskName, r.TaskPassed, tc.expectPassed[i])
+				}
+			}
+
+			if len(log.Overrides) != len(tc.expectNotes) {
+				t.Fatalf("got %d overrides, want %d", len(log.Overrides), len(tc.expectNotes))
+			}
+			for i, o := range log.Overrides {
+				if o.Note != tc.expectNotes[i] || o.Reviewer != "alex" || !o.Time.Equal(now) {
+					t.Errorf("override %d = %+v", i, o)
+				}
+			}
+
+			if !strings.Contains(out.String(), "the response does not mention the replicas") {
+				t.Errorf("output should show the judge reason, got:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestReviewJudgeFailuresResumes(t *testing.T) {
+	evalResults := []*eval.EvalResult{judgeFailedEvalResult("first")}
+	log := &results.OverridesLog{Overrides: []results.Override{
+		results.NewOverride(evalResults[0], eval.JudgeOverride{Passed: true}),
+	}}
+	log.Apply(evalResults)
+
+	var out bytes.Buffer
+	reviewed, err := reviewJudgeFailures(strings.NewReader(""), &out, evalResults, log, "", time.Now)
+	if err != nil {
+		t.Fatalf("reviewJudgeFailures() error = %v", err)
+	}
+	if reviewed != 0 || !strings.Contains(out.String(), "No task runs left to review") {
+		t.Errorf("reviewed = %d, output = %q", reviewed, out.String())
+	}
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/spf13/cobra"
+)
+
+// NewRobustnessCmd creates the robustness command
+func NewRobustnessCmd() *cobra.Command {
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:   "robustness <results-file>",
+		Short: "Compare task runs with paraphrases of their prompts",
+		Long: `Compare the prompt variants of each task of a run made with 'mcpchecker check --prompt-variants'.
+
+For every task run with more than one prompt, shows the pass rate of the runs
+with the original prompt and with each paraphrase, and the mean, standard
+deviation and spread of those pass rates. Tasks whose pass rate changes with the
+wording of the prompt are sensitive to it.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			evalResults, err := results.Load(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to load results file: %w", err)
+			}
+
+			sensitivities := results.PromptRobustness(evalResults)
+
+			switch outputFormat {
+			case "json":
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(sensitivities)
+			case "text":
+				outputTextRobustness(cmd.OutOrStdout(), sensitivities)
+			default:
+				return fmt.Errorf("unknown output format: %s", outputFormat)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, json)")
+
+	return cmd
+}
+
+// printPromptRobustness prints the prompt robustness of the results of a run
+// made by the check command.
+func printPromptRobustness(evalResults []*eval.EvalResult) {
+	fmt.Println()
+	outputTextRobustness(os.Stdout, results.PromptRobustness(evalResults))
+}
+
+// outputTextRobustness prints the pass rate of each prompt variant of each
+// task and their spread. Used by both the check and robustness commands.
+func outputTextRobustness(w io.Writer, sensitivities []results.PromptSensitivity) {
+	bold := color.New(color.Bold)
+
+	_, _ = bold.Fprintln(w, "=== Prompt Robustness ===")
+	fmt.Fprintln(w)
+
+	if len(sensitivities) == 0 {
+		fmt.Fprintln(w, "No tasks were run with more than one prompt.")
+		return
+	}
+
+	for _, s := range sensitivities {
+		name := s.TaskName
+		if s.AllowedToolsMode != "" {
+			name += fmt.Sprintf(" (allowedTools: %s)", s.AllowedToolsMode)
+		}
+		_, _ = bold.Fprintln(w, name)
+
+		for _, v := range s.Variants {
+			prompt := "original"
+			if v.Variant > 0 {
+				prompt = truncateString(normalizeWhitespace(v.Paraphrase), 60)
+			}
+			fmt.Fprintf(w, "  #%-3d %d/%-5d %6.1f%%  %s\n", v.Variant, v.Passed, v.Runs, v.PassRate*100, prompt)
+		}
+
+		fmt.Fprintf(w, "  Pass rate: mean %.1f%%, std dev %.1f%%, spread %.1f%%\n",
+			s.MeanPassRate*100, s.StdDev*100, s.Spread*100)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+)
+
+func TestOutputTextRobustness(t *testing.T) {
+	sensitivities := []results.PromptSensitivity{{
+		TaskName:         "list-pods",
+		AllowedToolsMode: eval.AllowedToolsAll,
+		Variants: []results.PromptVariantRuns{
+			{Variant: 0, Runs: 2, Passed: 2, PassRate: 1},
+			{Variant: 1, Paraphrase: "Show me\nevery pod", Runs: 2, Passed: 1, PassRate: 0.5},
+		},
+		MeanPassRate: 0.75,
+		StdDev:       0.25,
+		Spread:       0.5,
+	}}
+
+	var out bytes.Buffer
+	outputTextRobustness(&out, sensitivities)
+
+	for _, expected := range []string{
+		"list-pods (allowedTools: all)",
+		"#0   2/2      100.0%  original",
+		"#1   1/2       50.0%  Show me every pod",
+		"Pass rate: mean 75.0%, std dev 25.0%, spread 50.0%",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("output should contain %q, got:\n%s", expected, out.String())
+		}
+	}
+
+	out.Reset()
+	outputTextRobustness(&out, nil)
+	if !strings.Contains(out.String(), "No tasks were run with more than one prompt.") {
+		t.Errorf("unexpected output without variants:\n%s", out.String())
+	}
+}
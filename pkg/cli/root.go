@@ -15,8 +15,26 @@ It runs agents through defined tasks and validates their behavior using assertio
 	}
 
 	// Add subcommands
+	rootCmd.AddCommand(NewInitCmd())
 	rootCmd.AddCommand(NewEvalCmd())
 	rootCmd.AddCommand(NewResultCmd())
+	rootCmd.AddCommand(NewCompareCmd())
+	rootCmd.AddCommand(NewVerifyResultsCmd())
+	rootCmd.AddCommand(NewExportCmd())
+	rootCmd.AddCommand(NewBundleCmd())
+	rootCmd.AddCommand(NewMetricsCmd())
+	rootCmd.AddCommand(NewTranscriptCmd())
+	rootCmd.AddCommand(NewReplayCmd())
+	rootCmd.AddCommand(NewReviewCmd())
+	rootCmd.AddCommand(NewCalibrateCmd())
+	rootCmd.AddCommand(NewTailCmd())
+	rootCmd.AddCommand(NewCleanupCmd())
+	rootCmd.AddCommand(NewServeCmd())
+	rootCmd.AddCommand(NewCacheCmd())
+	rootCmd.AddCommand(NewMigrateCmd())
+	rootCmd.AddCommand(NewImportCmd())
+	rootCmd.AddCommand(NewMockAgentCmd())
+	rootCmd.AddCommand(NewSelfUpdateCmd())
 	rootCmd.AddCommand(NewVersionCmd())
 
 	return rootCmd
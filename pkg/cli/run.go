@@ -1,17 +1,26 @@
 package cli
 
 import (
+	"compress/gzip"
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
 	"fmt"
+	"io"
+	"maps"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/mcpchecker/mcpchecker/pkg/results/sink"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
 	"github.com/mcpchecker/mcpchecker/pkg/util"
 	"github.com/spf13/cobra"
 )
@@ -21,24 +30,67 @@ func NewEvalCmd() *cobra.Command {
 	var outputFormat string
 	var verbose bool
 	var run string
+	var skip string
+	var skipPaths []string
 	var labelSelector string
 	var parallelWorkers int
+	var parallelOutput string
+	var adaptiveParallel bool
+	var failFastOrder bool
+	var failFast bool
+	var maxFailures int
+	var sampleSize int
+	var samplePercent float64
+	var sampleSeed uint64
+	var sampleBy string
 	var runs int
 	var mcpConfigFile string
 	var defaultTaskTimeout string
 	var taskTimeout string
 	var defaultCleanupTimeout string
 	var cleanupTimeout string
+	var stallTimeout string
+	var verifyCleanup bool
+	var journalFile string
+	var noJournal bool
+	var captureRaw bool
+	var captureRawGzip bool
+	var captureRawMaxBytes int
+	var judgeAuditDir string
+	var compress bool
+	var strictRequires bool
+	var allowedTools string
+	var catalogueAblation bool
+	var poolProxies bool
+	var promptVariants int
+	var locale string
+	var signKey string
+	var sigstore bool
+	var attest bool
+	var meta []string
+	var noCIMeta bool
+	var runID string
 
 	cmd := &cobra.Command{
-		Use:   "check [eval-config-file]",
+		Use:   "check [eval-config-file|bundle]",
 		Short: "Run an evaluation",
-		Long:  `Run an evaluation using the specified eval configuration file.`,
-		Args:  cobra.ExactArgs(1),
+		Long: `Run an evaluation using the specified eval configuration file, or a bundle
+created with 'mcpchecker bundle' (a .tgz or .tar.gz file).`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			startTime := time.Now()
 			configFile := args[0]
 
+			// Bundles are run from a temporary directory they are extracted to
+			if eval.IsBundle(configFile) {
+				bundleConfig, cleanup, err := extractBundle(configFile)
+				if err != nil {
+					return fmt.Errorf("failed to extract bundle: %w", err)
+				}
+				defer cleanup()
+				configFile = bundleConfig
+			}
+
 			// Load eval spec
 			spec, err := eval.FromFile(configFile)
 			if err != nil {
@@ -72,24 +124,149 @@ func NewEvalCmd() *cobra.Command {
 				}
 			}
 
+			// Results are journaled as they complete so a crash doesn't lose finished tasks
+			if noJournal {
+				journalFile = ""
+			} else if journalFile == "" {
+				journalFile = "mcpchecker-" + spec.Metadata.Name + journalSuffix
+			}
+
+			// Explicit annotations take precedence over the CI environment
+			metadata, err := eval.ParseMetadata(meta)
+			if err != nil {
+				return err
+			}
+			if !noCIMeta {
+				for key, value := range eval.CIMetadata(os.Getenv) {
+					if _, ok := metadata[key]; !ok {
+						if metadata == nil {
+							metadata = make(map[string]string)
+						}
+						metadata[key] = value
+					}
+				}
+			}
+
+			// verify-results rejects unsigned attestations, so they aren't written
+			if attest && signKey == "" && !sigstore {
+				return fmt.Errorf("--attest requires --sign-key or --sigstore to sign the attestation")
+			}
+
+			// The key is loaded up front so that a bad key doesn't waste a run
+			var signingKey ed25519.PrivateKey
+			if signKey != "" {
+				signingKey, err = results.LoadPrivateKey(signKey)
+				if err != nil {
+					return fmt.Errorf("failed to load signing key: %w", err)
+				}
+			}
+
+			var rawCapture *eval.RawCapture
+			if captureRaw {
+				if captureRawMaxBytes < 0 {
+					return fmt.Errorf("--capture-raw-max-bytes cannot be negative")
+				}
+				rawCapture = &eval.RawCapture{Gzip: captureRawGzip, MaxBytes: captureRawMaxBytes}
+			}
+
+			var sample *eval.SampleConfig
+			if sampleSize != 0 || samplePercent != 0 {
+				// A random seed is recorded in the results, so the sample can be run again
+				if !cmd.Flags().Changed("sample-seed") {
+					sampleSeed = rand.Uint64()
+				}
+				sample = &eval.SampleConfig{Size: sampleSize, Percent: samplePercent, Seed: sampleSeed, By: sampleBy}
+			}
+
+			if failFast {
+				if cmd.Flags().Changed("max-failures") && maxFailures != 1 {
+					return fmt.Errorf("--fail-fast and --max-failures %d can't both be set", maxFailures)
+				}
+				maxFailures = 1
+			}
+
+			// The results of the previous run are read before this run replaces them
+			resultsFile := fmt.Sprintf("mcpchecker-%s-out.json", spec.Metadata.Name)
+			var failedFirst map[string]bool
+			if failFastOrder {
+				failedFirst, err = previouslyFailedTasks(resultsFile)
+				if err != nil {
+					return err
+				}
+				if failedFirst == nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "No previous results in %s, scheduling tasks by priority only\n", resultsFile)
+				}
+			}
+
+			// Records still queued when the run fails are sent on return
+			var resultsSink eval.ResultsSink
+			if spec.Config.ResultsSink != nil {
+				s, err := sink.New(context.Background(), spec.Config.ResultsSink, spec.Metadata.Name)
+				if err != nil {
+					return fmt.Errorf("failed to create results sink: %w", err)
+				}
+				defer func() {
+					if err := s.Close(); err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: results sink: %v\n", err)
+					}
+				}()
+				resultsSink = s
+			}
+
+			// The output of parallel tasks only needs to be kept apart if they
+			// run concurrently
+			outputMode, err := parseParallelOutput(parallelOutput)
+			if err != nil {
+				return err
+			}
+			if parallelWorkers <= 1 {
+				outputMode = ""
+			}
+			display := newProgressDisplay(verbose, outputMode)
+
 			// Create runner
 			runner, err := eval.NewRunner(spec, eval.RunnerOptions{
 				ParallelWorkers:   parallelWorkers,
 				Runs:              runs,
-				RunsExplicitlySet: cmd.Flags().Changed("runs"),
+				RunsExplicitlySet: cmd.Flags().Changed("runs") || cmd.Flags().Changed("repeat"),
 
 				DefaultTaskTimeout:    defaultTaskTimeout,
 				TaskTimeout:           taskTimeout,
 				DefaultCleanupTimeout: defaultCleanupTimeout,
 				CleanupTimeout:        cleanupTimeout,
+				StallTimeout:          stallTimeout,
+				VerifyCleanup:         verifyCleanup,
+
+				JournalFile: journalFile,
+				CaptureRaw:  rawCapture,
+
+				JudgeAuditDir: judgeAuditDir,
+
+				SkipPattern: skip,
+				SkipPaths:   skipPaths,
+
+				StrictRequires: strictRequires,
+				AllowedTools:   eval.AllowedToolsMode(allowedTools),
+
+				CatalogueAblation: catalogueAblation,
+				PoolProxies:       poolProxies,
+				PromptVariants:    promptVariants,
+				Locale:            locale,
+				Build:             buildInfo(),
+				Metadata:          metadata,
+				TaskOutput:        display.taskOutput,
+
+				AdaptiveParallelism: adaptiveParallel,
+				Sample:              sample,
+				FailedFirst:         failedFirst,
+				MaxFailures:         maxFailures,
+				ResultsSink:         resultsSink,
+				RunID:               runID,
 			})
 			if err != nil {
 				return fmt.Errorf("failed to create eval runner: %w", err)
 			}
 
-			// Create progress display
-			display := newProgressDisplay(verbose)
-
 			// Run with progress
 			ctx := context.Background()
 			ctx = util.WithVerbose(ctx, verbose)
@@ -99,18 +276,39 @@ func NewEvalCmd() *cobra.Command {
 			}
 
 			// Save results to JSON file (includes summary metadata)
-			outputFile := fmt.Sprintf("mcpchecker-%s-out.json", spec.Metadata.Name)
+			outputFile := resultsFile
+			if compress || (spec.Config.Output != nil && spec.Config.Output.Compress) {
+				outputFile += ".gz"
+			}
+			output.Summary.Provenance = eval.NewProvenance(ctx, spec, version(), output.Summary)
 			if err := saveOutputToFile(output, outputFile); err != nil {
 				return fmt.Errorf("failed to save results to file: %w", err)
 			}
 			if outputFormat == "text" {
 				fmt.Printf("\n📄 Results saved to: %s\n", outputFile)
 			}
+			if err := signResults(ctx, outputFile, signingKey, sigstore, outputFormat == "text"); err != nil {
+				return err
+			}
+			if attest {
+				if err := attestResults(ctx, outputFile, configFile, spec.Metadata.Name, output, signingKey, sigstore, outputFormat == "text"); err != nil {
+					return err
+				}
+			}
 
 			// Display results
 			if err := displayResults(output, outputFormat); err != nil {
 				return fmt.Errorf("failed to display results: %w", err)
 			}
+			if catalogueAblation && outputFormat == "text" {
+				printCatalogueAblation(output.Results)
+			}
+			if output.Summary.PromptVariants != nil && outputFormat == "text" {
+				printPromptRobustness(output.Results)
+			}
+			if output.Summary.Canary != nil && outputFormat == "text" {
+				printCanary(output.Summary.Canary, output.Results)
+			}
 
 			// Print elapsed time (only for text output to keep JSON machine-readable)
 			if outputFormat == "text" {
@@ -118,6 +316,23 @@ func NewEvalCmd() *cobra.Command {
 				fmt.Printf("⏱️  Completed in %s\n", formatDuration(elapsed))
 			}
 
+			// The gates were printed with the results, so only their names are
+			// repeated in the error
+			if failed := eval.FailedGates(output.Summary.Gates); len(failed) > 0 {
+				names := make([]string, 0, len(failed))
+				for _, gate := range failed {
+					names = append(names, gate.Name)
+				}
+				cmd.SilenceUsage = true
+				cmd.SilenceErrors = true
+				return fmt.Errorf("%d of %d gates failed: %s", len(failed), len(output.Summary.Gates), strings.Join(names, "; "))
+			}
+
+			if leaking := countLeakingRuns(output.Results); verifyCleanup && leaking > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d task runs left resources behind after cleanup", leaking)
+			}
+
 			return nil
 		},
 	}
@@ -125,14 +340,48 @@ func NewEvalCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, json)")
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
 	cmd.Flags().StringVarP(&run, "run", "r", "", "Regular expression to match task names to run (unanchored, like go test -run)")
-	cmd.Flags().StringVarP(&labelSelector, "label-selector", "l", "", "Filter taskSets by labels (e.g., suite=k8s,suite=helm for OR; suite=k8s,difficulty=easy for AND)")
+	cmd.Flags().StringVar(&skip, "skip", "", "Regular expression to match task names to skip, applied after --run")
+	cmd.Flags().StringArrayVar(&skipPaths, "skip-path", nil, "Glob matching task files or directories to skip, relative to the current directory (repeatable)")
+	cmd.Flags().IntVar(&sampleSize, "sample", 0, "Run a random sample of this many tasks, stratified by --sample-by, e.g. for quick smoke runs")
+	cmd.Flags().Float64Var(&samplePercent, "sample-percent", 0, "Run a random sample of this percentage of the tasks, stratified by --sample-by")
+	cmd.Flags().Uint64Var(&sampleSeed, "sample-seed", 0, "Seed of the sample; the same seed samples the same tasks (default: random, recorded in the results)")
+	cmd.Flags().StringVar(&sampleBy, "sample-by", eval.SampleByDifficulty, "What the sample is stratified by: 'difficulty', 'label:<key>' or 'none'")
+	cmd.Flags().BoolVar(&strictRequires, "strict-requires", false, "Fail the run if a task requires an extension or MCP server missing from the eval config, instead of skipping the task")
+	cmd.Flags().StringVar(&allowedTools, "allowed-tools", "", "Tools the agent is allowed to call: 'all', or 'assertions' for the tools of the toolsUsed and requireAny assertions of each task (overrides allowedTools in the eval config)")
+	cmd.Flags().BoolVar(&catalogueAblation, "catalogue-ablation", false, "Run each task with tool assertions twice, with only the tools of its assertions and with all tools, and report the pass rate and token changes (see 'result ablation')")
+	cmd.Flags().IntVar(&promptVariants, "prompt-variants", 0, "Also run each task with this many paraphrases of its prompt, written by the model of promptVariants in the eval config, and report the pass rate variance of each task (see 'result robustness')")
+	cmd.Flags().StringVar(&locale, "locale", "", "Locale of the prompt to run for tasks with a prompt per locale; tasks without one are skipped (overrides locale in the eval config, default \"en\")")
+	cmd.Flags().BoolVar(&poolProxies, "pool-proxies", false, "Keep the MCP proxy servers running across tasks instead of starting new ones for every task (same as poolProxies in the eval config)")
+	cmd.Flags().StringVarP(&labelSelector, "label-selector", "l", "", "Filter taskSets by labels (e.g., suite=k8s,difficulty=easy; 'suite in (k8s,helm),difficulty!=hard'; '!deprecated')")
 	cmd.Flags().IntVarP(&parallelWorkers, "parallel", "p", 1, "Number of parallel workers for tasks marked as parallel (1 = sequential)")
+	cmd.Flags().BoolVar(&adaptiveParallel, "adaptive-parallel", false, "Run parallel tasks with fewer workers while model calls are rate limited or slow, and with up to --parallel again once they are healthy (also set by adaptiveParallelism in the eval config)")
+	cmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop the run after the first failed task run, cancelling the tasks in flight and skipping the others (same as --max-failures 1)")
+	cmd.Flags().IntVar(&maxFailures, "max-failures", 0, "Stop the run after this many failed task runs, cancelling the tasks in flight and skipping the others (0 = no limit)")
+	cmd.Flags().BoolVar(&failFastOrder, "fail-fast-order", false, "Schedule parallel tasks that failed in the previous results of this eval (mcpchecker-<eval-name>-out.json) first, so failures show up sooner")
+	cmd.Flags().StringVar(&parallelOutput, "parallel-output", string(parallelOutputGrouped), "How the output of tasks running in parallel is shown: 'grouped' to show the output of each task together when it completes, or 'stream' to show it as it comes, each line prefixed with the task name")
 	cmd.Flags().IntVarP(&runs, "runs", "n", 1, "Number of times to run each task (for consistency testing)")
+	cmd.Flags().IntVar(&runs, "repeat", 1, "Number of times to run each task, reporting pass@1, pass@k and flakiness (same as --runs, overrides repeat in task sets)")
+	cmd.MarkFlagsMutuallyExclusive("runs", "repeat")
 	cmd.Flags().StringVar(&mcpConfigFile, "mcp-config-file", "", "Path to MCP config file (overrides value in eval config)")
 	cmd.Flags().StringVar(&defaultTaskTimeout, "default-task-timeout", "", "Default timeout for tasks without their own (e.g., '15m', '1h')")
 	cmd.Flags().StringVar(&taskTimeout, "task-timeout", "", "Hard override timeout for ALL tasks (e.g., '15m', '1h')")
 	cmd.Flags().StringVar(&defaultCleanupTimeout, "default-cleanup-timeout", "", "Default cleanup timeout for tasks without their own (e.g., '2m')")
 	cmd.Flags().StringVar(&cleanupTimeout, "cleanup-timeout", "", "Hard override cleanup timeout for ALL tasks (e.g., '2m')")
+	cmd.Flags().StringVar(&stallTimeout, "stall-timeout", "", "Stop the agent of a task once it has made no progress, such as output or ACP updates, for this long, overriding the stall timeout of ALL tasks (e.g., '5m')")
+	cmd.Flags().BoolVar(&verifyCleanup, "verify-cleanup", false, "After the cleanup of each task, run its cleanupVerify steps and report what they find left behind as leaked resources, failing the run if there are any")
+	cmd.Flags().StringVar(&journalFile, "journal", "", "Path of the NDJSON journal that results are appended to as tasks complete (default: mcpchecker-<eval-name>-journal.ndjson)")
+	cmd.Flags().BoolVar(&noJournal, "no-journal", false, "Don't write a results journal during the run")
+	cmd.Flags().BoolVar(&compress, "compress", false, "Write the results file gzip-compressed, as .json.gz (also set by output.compress in the eval config)")
+	cmd.Flags().BoolVar(&captureRaw, "capture-raw", false, "Persist the raw session updates of the agent on each result, for offline analysis")
+	cmd.Flags().BoolVar(&captureRawGzip, "capture-raw-gzip", false, "Gzip-compress the raw updates persisted with --capture-raw")
+	cmd.Flags().IntVar(&captureRawMaxBytes, "capture-raw-max-bytes", eval.DefaultRawUpdatesMaxBytes, "Limit on the uncompressed size of the raw updates of a task run; later updates are dropped (0 = no limit)")
+	cmd.Flags().StringVar(&judgeAuditDir, "judge-audit-dir", "", "Directory to write the exact prompts and raw responses of the LLM judge calls of each task run to, for reviewing verdicts")
+	cmd.Flags().StringArrayVar(&meta, "meta", nil, "Annotate the run with key=value metadata, recorded in the results summary (repeatable)")
+	cmd.Flags().StringVar(&runID, "run-id", "", "ID of the run, recorded in the results, the journal, the debug directory and the error files, and passed to the agent and MCP servers (default: a new ULID)")
+	cmd.Flags().BoolVar(&noCIMeta, "no-ci-meta", false, "Don't record the CI system, repository, branch, pull request, commit and run URL detected from the environment as metadata")
+	cmd.Flags().StringVar(&signKey, "sign-key", "", "Sign the results file with this ed25519 private key (PKCS #8 PEM), writing the signature to <results-file>.sig (see 'verify-results')")
+	cmd.Flags().BoolVar(&sigstore, "sigstore", false, "Sign the results file keylessly with cosign, writing the sigstore bundle to <results-file>.sigstore.json (see 'verify-results')")
+	cmd.Flags().BoolVar(&attest, "attest", false, "Write an in-toto attestation of the inputs and outputs of the run to <results-file>.intoto.json, signed like the results file; requires --sign-key or --sigstore (see 'verify-results')")
 
 	return cmd
 }
@@ -141,21 +390,33 @@ func NewEvalCmd() *cobra.Command {
 type progressDisplay struct {
 	mu      sync.Mutex
 	verbose bool
+	out     io.Writer
 	green   *color.Color
 	red     *color.Color
 	yellow  *color.Color
 	cyan    *color.Color
 	bold    *color.Color
+
+	// parallelOutput keeps the output of parallel tasks apart; empty if
+	// tasks don't run in parallel. Grouped output is buffered in groups
+	// until the task completes.
+	parallelOutput parallelOutputMode
+	groups         []*taskOutputGroup
+
+	// runID is the ID of the run, set when it starts
+	runID string
 }
 
-func newProgressDisplay(verbose bool) *progressDisplay {
+func newProgressDisplay(verbose bool, parallelOutput parallelOutputMode) *progressDisplay {
 	return &progressDisplay{
-		verbose: verbose,
-		green:   color.New(color.FgGreen),
-		red:     color.New(color.FgRed),
-		yellow:  color.New(color.FgYellow),
-		cyan:    color.New(color.FgCyan),
-		bold:    color.New(color.Bold),
+		verbose:        verbose,
+		out:            os.Stdout,
+		green:          color.New(color.FgGreen),
+		red:            color.New(color.FgRed),
+		yellow:         color.New(color.FgYellow),
+		cyan:           color.New(color.FgCyan),
+		bold:           color.New(color.Bold),
+		parallelOutput: parallelOutput,
 	}
 }
 
@@ -172,90 +433,141 @@ func (d *progressDisplay) handleProgress(event eval.ProgressEvent) {
 	defer d.mu.Unlock()
 
 	prefix := taskPrefix(event.Task)
+	w := d.taskWriter(event.Task)
 
 	switch event.Type {
 	case eval.EventEvalStart:
-		d.bold.Println("\n=== Starting Evaluation ===")
+		d.bold.Fprintln(w, "\n=== Starting Evaluation ===")
 		if event.Summary != nil {
+			d.runID = event.Summary.RunID
 			d.printSummary(event.Summary)
 		}
 
 	case eval.EventTaskStart:
-		fmt.Println()
+		fmt.Fprintln(w)
 		runInfo := ""
 		if event.Task.TotalRuns > 1 {
 			runInfo = fmt.Sprintf(" [run %d/%d]", event.Task.RunIndex+1, event.Task.TotalRuns)
 		}
+		if event.Task.AllowedToolsMode != "" {
+			runInfo += fmt.Sprintf(" [allowedTools: %s]", event.Task.AllowedToolsMode)
+		}
+		if event.Task.Environment != "" {
+			runInfo += fmt.Sprintf(" [environment: %s]", event.Task.Environment)
+		}
 		if event.Task.Parallel {
 			if event.Task.Difficulty != "" {
-				d.cyan.Printf("[%s]%s Starting (parallel, %s)\n", event.Task.TaskName, runInfo, event.Task.Difficulty)
+				d.cyan.Fprintf(w, "[%s]%s Starting (parallel, %s)\n", event.Task.TaskName, runInfo, event.Task.Difficulty)
 			} else {
-				d.cyan.Printf("[%s]%s Starting (parallel)\n", event.Task.TaskName, runInfo)
+				d.cyan.Fprintf(w, "[%s]%s Starting (parallel)\n", event.Task.TaskName, runInfo)
 			}
 		} else {
-			d.cyan.Printf("Task: %s%s\n", event.Task.TaskName, runInfo)
+			d.cyan.Fprintf(w, "Task: %s%s\n", event.Task.TaskName, runInfo)
 			if event.Task.Difficulty != "" {
-				fmt.Printf("  Difficulty: %s\n", event.Task.Difficulty)
+				fmt.Fprintf(w, "  Difficulty: %s\n", event.Task.Difficulty)
 			}
 		}
 
+	case eval.EventTaskDeprecated:
+		fmt.Fprintln(w)
+		d.yellow.Fprintf(w, "Task: %s (deprecated, skipped)\n", event.Task.TaskName)
+
+	case eval.EventTaskSkippedOverBudget:
+		fmt.Fprintln(w)
+		d.yellow.Fprintf(w, "Task: %s (run budget exceeded, skipped)\n", event.Task.TaskName)
+
+	case eval.EventTaskSkipped:
+		fmt.Fprintln(w)
+		d.yellow.Fprintf(w, "Task: %s (%s, skipped)\n", event.Task.TaskName, event.Task.SkipMessage)
+
+	case eval.EventTaskWarning, eval.EventWarning:
+		d.yellow.Fprintf(w, "WARNING: %s\n", event.Message)
+
 	case eval.EventTaskSetup:
 		if d.verbose {
-			fmt.Printf("%s→ Setting up task environment...\n", prefix)
+			fmt.Fprintf(w, "%s→ Setting up task environment...\n", prefix)
 		}
 
 	case eval.EventTaskRunning:
-		fmt.Printf("%s→ Running agent...\n", prefix)
+		fmt.Fprintf(w, "%s→ Running agent...\n", prefix)
 
 	case eval.EventTaskVerifying:
-		fmt.Printf("%s→ Verifying results...\n", prefix)
+		fmt.Fprintf(w, "%s→ Verifying results...\n", prefix)
 
 	case eval.EventTaskAssertions:
 		if d.verbose {
-			fmt.Printf("%s→ Evaluating assertions...\n", prefix)
+			fmt.Fprintf(w, "%s→ Evaluating assertions...\n", prefix)
 		}
 
 	case eval.EventTaskTimeout:
-		d.red.Printf("%s⏱ Task timed out\n", prefix)
+		d.red.Fprintf(w, "%s⏱ Task timed out\n", prefix)
 		if event.Task.TaskError != "" {
-			fmt.Printf("%s  Error: %s\n", prefix, event.Task.TaskError)
+			fmt.Fprintf(w, "%s  Error: %s\n", prefix, event.Task.TaskError)
+		}
+		// Tasks that time out during setup don't complete
+		if event.Task.ErrorKind == task.ErrorKindSetup {
+			d.flush(event.Task)
+		}
+
+	case eval.EventTaskStalled:
+		d.red.Fprintf(w, "%s⏸ Agent stalled\n", prefix)
+		if event.Task.TaskError != "" {
+			fmt.Fprintf(w, "%s  Error: %s\n", prefix, event.Task.TaskError)
 		}
 
 	case eval.EventTaskError:
 		task := event.Task
-		d.red.Printf("%s✗ Task failed during setup\n", prefix)
+		d.red.Fprintf(w, "%s✗ Task failed during setup\n", prefix)
 		if task.TaskError != "" {
-			fmt.Printf("%s  Error: %s\n", prefix, task.TaskError)
+			fmt.Fprintf(w, "%s  Error: %s\n", prefix, task.TaskError)
 		}
+		d.flush(task)
 
 	case eval.EventTaskComplete:
 		task := event.Task
-		if task.TaskPassed && task.AllAssertionsPassed {
-			d.green.Printf("%s✓ Task passed\n", prefix)
+		if results.XPassed(task) {
+			d.yellow.Fprintf(w, "%s! Task passed unexpectedly (XPASS), it is expected to fail\n", prefix)
+		} else if results.XFailed(task) {
+			d.yellow.Fprintf(w, "%sx Task failed as expected (XFAIL)\n", prefix)
+			if task.TaskError != "" {
+				fmt.Fprintf(w, "%s  Error: %s\n", prefix, task.TaskError)
+			}
+		} else if task.TaskPassed && task.AllAssertionsPassed {
+			d.green.Fprintf(w, "%s✓ Task passed\n", prefix)
 		} else if task.TaskPassed && !task.AllAssertionsPassed {
-			d.yellow.Printf("%s~ Task passed but assertions failed\n", prefix)
+			d.yellow.Fprintf(w, "%s~ Task passed but assertions failed\n", prefix)
 		} else {
-			if task.AgentExecutionError {
-				d.red.Printf("%s✗ Agent failed to run\n", prefix)
+			if task.JudgeError {
+				d.yellow.Fprintf(w, "%s✗ Judge error (no verdict)\n", prefix)
+				if task.TaskJudgeError != "" {
+					fmt.Fprintf(w, "%s  Error: %s\n", prefix, task.TaskJudgeError)
+				}
+			} else if task.AgentExecutionError {
+				d.red.Fprintf(w, "%s✗ Agent failed to run\n", prefix)
 				if task.TaskError != "" || task.TaskOutput != "" {
-					errorFile, err := saveErrorToFile(task.TaskName, task.TaskError, task.TaskOutput)
+					errorFile, err := saveErrorToFile(d.runID, task.TaskName, task.TaskError, task.TaskOutput)
 					if err != nil {
-						fmt.Printf("%s  Error: %s\n", prefix, task.TaskError)
+						fmt.Fprintf(w, "%s  Error: %s\n", prefix, task.TaskError)
 					} else {
-						fmt.Printf("%s  Error details saved to: %s\n", prefix, errorFile)
+						fmt.Fprintf(w, "%s  Error details saved to: %s\n", prefix, errorFile)
 					}
 				}
 			} else {
-				d.red.Printf("%s✗ Task failed\n", prefix)
+				d.red.Fprintf(w, "%s✗ Task failed\n", prefix)
 				if task.TaskError != "" {
-					fmt.Printf("%s  Error: %s\n", prefix, task.TaskError)
+					fmt.Fprintf(w, "%s  Error: %s\n", prefix, task.TaskError)
 				}
 			}
 		}
+		if usage := event.Usage; usage != nil && usage.TotalTokens() > 0 {
+			fmt.Fprintf(w, "%s  %s\n", prefix, formatUsage(usage))
+		}
+		d.flush(task)
 
 	case eval.EventEvalComplete:
-		fmt.Println()
-		d.bold.Println("=== Evaluation Complete ===")
+		d.flushAll()
+		fmt.Fprintln(w)
+		d.bold.Fprintln(w, "=== Evaluation Complete ===")
 	}
 }
 
@@ -263,6 +575,9 @@ func (d *progressDisplay) printSummary(s *eval.EvalSummary) {
 	fmt.Println()
 	d.bold.Println("=== Evaluation Configuration Summary ===")
 
+	if s.RunID != "" {
+		fmt.Printf("Run ID:         %s\n", s.RunID)
+	}
 	if s.Agent != nil {
 		fmt.Printf("Agent:          %s\n", s.Agent.Type)
 		if s.Agent.Name != "" {
@@ -305,6 +620,21 @@ func (d *progressDisplay) printSummary(s *eval.EvalSummary) {
 		}
 	}
 
+	if s.Canary != nil {
+		fmt.Printf("Canary:         %s: %s → %s\n", s.Canary.Server, s.Canary.Baseline, s.Canary.Candidate)
+	}
+
+	for _, env := range s.Environments {
+		fmt.Printf("Environment:    %s\n", env.Name)
+		for _, srv := range env.MCPServers {
+			if srv.URL != "" {
+				fmt.Printf("  MCP Server:     %s: %s\n", srv.Name, srv.URL)
+			} else if srv.Command != "" {
+				fmt.Printf("  MCP Server:     %s: %s (stdio)\n", srv.Name, srv.Command)
+			}
+		}
+	}
+
 	if s.Evals != nil {
 		fmt.Printf("Evals:          %d matched", len(s.Evals.Names))
 		if len(s.Evals.Names) > 0 && len(s.Evals.Names) <= 10 {
@@ -312,14 +642,28 @@ func (d *progressDisplay) printSummary(s *eval.EvalSummary) {
 		}
 		fmt.Println()
 
+		if states := formatStateCounts(s.Evals.States); states != "" {
+			fmt.Printf("Task States:    %s\n", states)
+		}
+
 		for _, ts := range s.Evals.TaskSets {
+			recursive := ""
+			if ts.Recursive {
+				recursive = " (recursive)"
+			}
 			if ts.Glob != "" {
-				fmt.Printf("  Glob:           %s\n", ts.Glob)
+				fmt.Printf("  Glob:           %s%s\n", ts.Glob, recursive)
 			} else if ts.Path != "" {
-				fmt.Printf("  Path:           %s\n", ts.Path)
+				fmt.Printf("  Path:           %s%s\n", ts.Path, recursive)
+			}
+			for _, exclude := range ts.Exclude {
+				fmt.Printf("  Exclude:        %s\n", exclude)
+			}
+			if len(ts.LabelSelector) > 0 {
+				fmt.Printf("  Label Selector: %s\n", ts.LabelSelector)
 			}
-			for k, v := range ts.LabelSelector {
-				fmt.Printf("  Label Selector: %s=%s\n", k, v)
+			if ts.Environment != "" {
+				fmt.Printf("  Environment:    %s\n", ts.Environment)
 			}
 		}
 	}
@@ -343,6 +687,16 @@ func (d *progressDisplay) printSummary(s *eval.EvalSummary) {
 	if s.Runs > 1 {
 		fmt.Printf("Runs:           %d per task\n", s.Runs)
 	}
+	if s.Sample != nil {
+		fmt.Printf("Sample:         %d of %d tasks (seed %d, by %s)\n", s.Sample.Selected, s.Sample.Total, s.Sample.Seed, s.Sample.By)
+	}
+	if len(s.Metadata) > 0 {
+		pairs := make([]string, 0, len(s.Metadata))
+		for _, key := range slices.Sorted(maps.Keys(s.Metadata)) {
+			pairs = append(pairs, key+"="+s.Metadata[key])
+		}
+		fmt.Printf("Metadata:       %s\n", strings.Join(pairs, ", "))
+	}
 
 	d.bold.Println("===============================")
 }
@@ -355,14 +709,39 @@ func displayResults(output *eval.EvalOutput, format string) error {
 		return encoder.Encode(output)
 
 	case "text":
-		return displayTextResults(output.Results)
+		var runID string
+		if output.Summary != nil {
+			runID = output.Summary.RunID
+		}
+		if err := displayTextResults(runID, output.Results); err != nil {
+			return err
+		}
+		if output.Summary != nil {
+			displayBudget(output.Summary.Budget)
+			displayAbort(output.Summary.Abort)
+			displayGates(output.Summary.Gates)
+			displayAdaptiveParallelism(output.Summary.AdaptiveParallelism)
+		}
+		return nil
 
 	default:
 		return fmt.Errorf("unknown output format: %s", format)
 	}
 }
 
-func displayTextResults(results []*eval.EvalResult) error {
+// countLeakingRuns returns the number of runs whose cleanupVerify steps found
+// resources left behind by cleanup.
+func countLeakingRuns(evalResults []*eval.EvalResult) int {
+	leaking := 0
+	for _, result := range evalResults {
+		if len(result.LeakedResources) > 0 {
+			leaking++
+		}
+	}
+	return leaking
+}
+
+func displayTextResults(runID string, evalResults []*eval.EvalResult) error {
 	green := color.New(color.FgGreen)
 	red := color.New(color.FgRed)
 	yellow := color.New(color.FgYellow)
@@ -372,21 +751,40 @@ func displayTextResults(results []*eval.EvalResult) error {
 	bold.Println("=== Results Summary ===")
 	fmt.Println()
 
-	totalTasks := len(results)
+	totalTasks := len(evalResults)
 	tasksPassed := 0
+	tasksQuarantined := 0
+	expectedFailures := results.CountExpectedFailures(evalResults)
+	tasksJudgeErrored := 0
+	tasksSkippedOverBudget := 0
+	tasksSkipped := 0
+	tasksLeaking := countLeakingRuns(evalResults)
+	errorKinds := countErrorKinds(evalResults)
 	totalAssertions := 0
 	passedAssertions := 0
 	verificationFailedButAssertionsPassed := 0
 	verificationFailedButAssertionsPassedTotal := 0
 	verificationFailedButAssertionsPassedCount := 0
 
-	for _, result := range results {
+	for _, result := range evalResults {
 		if result.TaskPassed {
 			tasksPassed++
 		}
+		if result.State == task.StateQuarantined {
+			tasksQuarantined++
+		}
+		if result.JudgeError {
+			tasksJudgeErrored++
+		}
+		if result.SkippedOverBudget {
+			tasksSkippedOverBudget++
+		}
+		if result.Skipped && !result.SkipReason.CountsAsNotPassed() {
+			tasksSkipped++
+		}
 
 		// Track cases where verification failed but assertions passed
-		if !result.TaskPassed && result.AllAssertionsPassed && !result.AgentExecutionError {
+		if !result.TaskPassed && result.AllAssertionsPassed && !result.AgentExecutionError && !result.JudgeError && !result.SkippedOverBudget && !result.Skipped {
 			verificationFailedButAssertionsPassed++
 		}
 
@@ -396,7 +794,7 @@ func displayTextResults(results []*eval.EvalResult) error {
 			passedAssertions += result.AssertionResults.PassedAssertions()
 
 			// Track assertions for verification-failed tasks
-			if !result.TaskPassed && !result.AgentExecutionError {
+			if !result.TaskPassed && !result.AgentExecutionError && !result.JudgeError {
 				verificationFailedButAssertionsPassedTotal += result.AssertionResults.TotalAssertions()
 				verificationFailedButAssertionsPassedCount += result.AssertionResults.PassedAssertions()
 			}
@@ -408,19 +806,42 @@ func displayTextResults(results []*eval.EvalResult) error {
 		if result.Difficulty != "" {
 			fmt.Printf("  Difficulty: %s\n", result.Difficulty)
 		}
+		if result.State != "" {
+			fmt.Printf("  State: %s\n", result.State)
+		}
+		if result.ExpectFailureReason != "" {
+			fmt.Printf("  Expected Failure: %s\n", result.ExpectFailureReason)
+		}
 
-		if result.TaskPassed {
+		if results.XPassed(result) {
+			yellow.Printf("  Task Status: XPASS (passed, but expected to fail)\n")
+		} else if result.TaskPassed {
 			green.Printf("  Task Status: PASSED\n")
+		} else if results.XFailed(result) {
+			yellow.Printf("  Task Status: XFAIL (failed as expected)\n")
+			if result.TaskError != "" {
+				fmt.Printf("  Error: %s\n", result.TaskError)
+			}
 		} else {
 			if result.TimedOut {
 				red.Printf("  Task Status: FAILED (Timed out)\n")
 				if result.TaskError != "" {
 					fmt.Printf("  Error: %s\n", result.TaskError)
 				}
+			} else if result.SkippedOverBudget {
+				yellow.Printf("  Task Status: SKIPPED (run budget exceeded)\n")
+			} else if result.Skipped {
+				yellow.Printf("  Task Status: SKIPPED (%s)\n", result.SkipReason)
+				if result.SkipMessage != "" {
+					fmt.Printf("  Reason: %s\n", result.SkipMessage)
+				}
+			} else if result.JudgeError {
+				yellow.Printf("  Task Status: JUDGE ERROR (no verdict)\n")
+				fmt.Printf("  Error: %s\n", result.TaskJudgeError)
 			} else if result.AgentExecutionError {
 				red.Printf("  Task Status: FAILED (Agent execution error)\n")
 				if result.TaskError != "" || result.TaskOutput != "" {
-					errorFile, err := saveErrorToFile(result.TaskName, result.TaskError, result.TaskOutput)
+					errorFile, err := saveErrorToFile(runID, result.TaskName, result.TaskError, result.TaskOutput)
 					if err != nil {
 						// If we can't save to file, fall back to printing inline
 						fmt.Printf("  Error: %s\n", result.TaskError)
@@ -451,6 +872,12 @@ func displayTextResults(results []*eval.EvalResult) error {
 				printFailedAssertions(result.AssertionResults)
 			}
 		}
+		if len(result.LeakedResources) > 0 {
+			red.Printf("  Leaked Resources: %d\n", len(result.LeakedResources))
+			for _, leak := range result.LeakedResources {
+				fmt.Printf("    - %s\n", strings.ReplaceAll(leak, "\n", "\n      "))
+			}
+		}
 
 		fmt.Println()
 	}
@@ -458,10 +885,10 @@ func displayTextResults(results []*eval.EvalResult) error {
 	bold.Println("=== Overall Statistics ===")
 	fmt.Printf("Total Tasks: %d\n", totalTasks)
 
-	if tasksPassed == totalTasks {
-		green.Printf("Tasks Passed: %d/%d\n", tasksPassed, totalTasks)
+	if countedTasks := totalTasks - tasksSkipped; tasksPassed == countedTasks {
+		green.Printf("Tasks Passed: %d/%d\n", tasksPassed, countedTasks)
 	} else {
-		yellow.Printf("Tasks Passed: %d/%d\n", tasksPassed, totalTasks)
+		yellow.Printf("Tasks Passed: %d/%d\n", tasksPassed, countedTasks)
 	}
 
 	if totalAssertions > 0 {
@@ -472,6 +899,31 @@ func displayTextResults(results []*eval.EvalResult) error {
 		}
 	}
 
+	if tasksQuarantined > 0 {
+		yellow.Printf("Quarantined Tasks: %d (excluded from verify thresholds)\n", tasksQuarantined)
+	}
+	if expectedFailures.XFailed > 0 {
+		yellow.Printf("Expected Failures: %d (XFAIL, excluded from verify thresholds)\n", expectedFailures.XFailed)
+	}
+	if expectedFailures.XPassed > 0 {
+		yellow.Printf("Unexpected Passes: %d (XPASS, remove expectFailure from these tasks)\n", expectedFailures.XPassed)
+	}
+	if tasksJudgeErrored > 0 {
+		yellow.Printf("Judge Errors: %d (the judge could not produce a verdict)\n", tasksJudgeErrored)
+	}
+	if tasksSkippedOverBudget > 0 {
+		yellow.Printf("Skipped Over Budget: %d (not started because the run budget was exceeded)\n", tasksSkippedOverBudget)
+	}
+	if tasksSkipped > 0 {
+		yellow.Printf("Skipped Tasks: %d (excluded from pass rates)\n", tasksSkipped)
+	}
+	if tasksLeaking > 0 {
+		red.Printf("Leaked Resources: %d task runs left resources behind after cleanup\n", tasksLeaking)
+	}
+	if errorKinds.Total() > 0 {
+		fmt.Printf("Failures by Kind: %s\n", errorKinds)
+	}
+
 	// Show stats for verification-failed tasks
 	if verificationFailedButAssertionsPassed > 0 {
 		fmt.Println()
@@ -487,7 +939,7 @@ func displayTextResults(results []*eval.EvalResult) error {
 	var totalTokens int64
 	var totalMcpSchemaTokens int64
 	hasTokenErrors := false
-	for _, result := range results {
+	for _, result := range evalResults {
 		if result.TokenEstimate != nil {
 			totalTokens += result.TokenEstimate.TotalTokens
 			totalMcpSchemaTokens += result.TokenEstimate.McpSchemaTokens
@@ -501,14 +953,84 @@ func displayTextResults(results []*eval.EvalResult) error {
 	// Group by difficulty
 	fmt.Println()
 	bold.Println("=== Statistics by Difficulty ===")
-	displayStatsByDifficulty(results, green, yellow)
+	displayStatsByDifficulty(evalResults, green, yellow)
+
+	displayStatsByEnvironment(evalResults, green, yellow)
 
 	// Show consistency summary for multi-run
-	displayConsistencySummary(results)
+	displayConsistencySummary(evalResults)
 
 	return nil
 }
 
+// displayBudget prints how much of the run budget was used, if one was configured.
+func displayBudget(budget *eval.BudgetSummary) {
+	if budget == nil {
+		return
+	}
+
+	fmt.Println()
+	color.New(color.Bold).Println("=== Run Budget ===")
+	if budget.MaxTokens > 0 {
+		fmt.Printf("Tokens: %d / %d\n", budget.UsedTokens, budget.MaxTokens)
+	} else {
+		fmt.Printf("Tokens: %d\n", budget.UsedTokens)
+	}
+	if budget.MaxCostUSD > 0 {
+		fmt.Printf("Estimated Cost: $%.2f / $%.2f\n", budget.UsedCostUSD, budget.MaxCostUSD)
+	}
+	if budget.Exceeded {
+		color.New(color.FgYellow).Printf("Budget exceeded: %d task runs skipped\n", budget.SkippedRuns)
+	}
+}
+
+// displayAbort prints why the run was aborted early, if it was.
+func displayAbort(abort *eval.AbortSummary) {
+	if abort == nil || !abort.Aborted {
+		return
+	}
+
+	fmt.Println()
+	color.New(color.Bold).Println("=== Run Aborted ===")
+	color.New(color.FgYellow).Printf("Aborted after %d failed task runs (limit %d): %d task runs cancelled, %d skipped\n",
+		abort.Failures, abort.MaxFailures, abort.CancelledRuns, abort.SkippedRuns)
+}
+
+// displayGates prints whether the gates of the eval config were met.
+func displayGates(gates []eval.GateResult) {
+	if len(gates) == 0 {
+		return
+	}
+
+	fmt.Println()
+	color.New(color.Bold).Println("=== Gates ===")
+	green := color.New(color.FgGreen)
+	red := color.New(color.FgRed)
+	for _, gate := range gates {
+		switch {
+		case gate.Runs == 0:
+			fmt.Printf("- %s: no task runs\n", gate.Name)
+		case gate.Met:
+			green.Printf("✓ %s: %.2f%% >= %.2f%% (%d/%d)\n", gate.Name, gate.PassRate*100, gate.MinPassRate*100, gate.Passed, gate.Runs)
+		default:
+			red.Printf("✗ %s: %.2f%% < %.2f%% (%d/%d)\n", gate.Name, gate.PassRate*100, gate.MinPassRate*100, gate.Passed, gate.Runs)
+		}
+	}
+}
+
+func displayAdaptiveParallelism(s *eval.AdaptiveParallelismSummary) {
+	if s == nil || len(s.Changes) < 2 {
+		return
+	}
+
+	fmt.Println()
+	color.New(color.Bold).Println("=== Parallel Workers ===")
+	start := s.Changes[0].Time
+	for _, c := range s.Changes {
+		fmt.Printf("+%-8s %d (%s)\n", formatDuration(c.Time.Sub(start)), c.Workers, c.Reason)
+	}
+}
+
 func displayStatsByDifficulty(results []*eval.EvalResult, green *color.Color, yellow *color.Color) {
 	// Group results by difficulty
 	type difficultyStats struct {
@@ -521,6 +1043,11 @@ func displayStatsByDifficulty(results []*eval.EvalResult, green *color.Color, ye
 	statsByDifficulty := make(map[string]*difficultyStats)
 
 	for _, result := range results {
+		// Skipped runs are left out of pass rates, as in the overall statistics
+		if result.Skipped && !result.SkipReason.CountsAsNotPassed() {
+			continue
+		}
+
 		difficulty := result.Difficulty
 		if difficulty == "" {
 			difficulty = "unspecified"
@@ -600,6 +1127,37 @@ func displayStatsByDifficulty(results []*eval.EvalResult, green *color.Color, ye
 	}
 }
 
+// displayStatsByEnvironment prints the task and assertion pass rates of each
+// environment that tasks ran against, if any did.
+func displayStatsByEnvironment(evalResults []*eval.EvalResult, green *color.Color, yellow *color.Color) {
+	statsByEnvironment := results.CalculateStatsByEnvironment("", evalResults)
+	if len(statsByEnvironment) == 0 {
+		return
+	}
+
+	fmt.Println()
+	color.New(color.Bold).Println("=== Statistics by Environment ===")
+	for _, name := range slices.Sorted(maps.Keys(statsByEnvironment)) {
+		stats := statsByEnvironment[name]
+		fmt.Printf("\n%s:\n", name)
+
+		counted := stats.TasksTotal - stats.TasksSkipped
+		if stats.TasksPassed == counted {
+			green.Printf("  Tasks: %d/%d (%.2f%%)\n", stats.TasksPassed, counted, stats.TaskPassRate*100)
+		} else {
+			yellow.Printf("  Tasks: %d/%d (%.2f%%)\n", stats.TasksPassed, counted, stats.TaskPassRate*100)
+		}
+
+		if stats.AssertionsTotal > 0 {
+			if stats.AssertionsPassed == stats.AssertionsTotal {
+				green.Printf("  Assertions: %d/%d\n", stats.AssertionsPassed, stats.AssertionsTotal)
+			} else {
+				yellow.Printf("  Assertions: %d/%d\n", stats.AssertionsPassed, stats.AssertionsTotal)
+			}
+		}
+	}
+}
+
 func printFailedAssertions(results *eval.CompositeAssertionResult) {
 	printSingleAssertion("ToolsUsed", results.ToolsUsed)
 	printSingleAssertion("RequireAny", results.RequireAny)
@@ -612,6 +1170,9 @@ func printFailedAssertions(results *eval.CompositeAssertionResult) {
 	printSingleAssertion("PromptsNotUsed", results.PromptsNotUsed)
 	printSingleAssertion("CallOrder", results.CallOrder)
 	printSingleAssertion("NoDuplicateCalls", results.NoDuplicateCalls)
+	printSingleAssertion("ToolOutputs", results.ToolOutputs)
+	printSingleAssertion("ReadOnly", results.ReadOnly)
+	printSingleAssertion("ToolErrors", results.ToolErrors)
 }
 
 func printSingleAssertion(name string, result *eval.SingleAssertionResult) {
@@ -623,30 +1184,135 @@ func printSingleAssertion(name string, result *eval.SingleAssertionResult) {
 	}
 }
 
-func saveOutputToFile(output *eval.EvalOutput, filename string) error {
+// saveOutputToFile writes output as JSON to filename, gzip-compressed if filename
+// ends with .gz
+func saveOutputToFile(output *eval.EvalOutput, filename string) (retErr error) {
 	file, err := os.Create(filename)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
-	defer file.Close()
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && retErr == nil {
+			retErr = fmt.Errorf("failed to close file: %w", closeErr)
+		}
+	}()
+
+	var w io.Writer = file
+	var zw *gzip.Writer
+	if strings.HasSuffix(filename, ".gz") {
+		zw = gzip.NewWriter(file)
+		w = zw
+	}
 
-	encoder := json.NewEncoder(file)
+	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
 	if err := encoder.Encode(output); err != nil {
 		return fmt.Errorf("failed to encode results: %w", err)
 	}
 
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("failed to compress results: %w", err)
+		}
+	}
+	return nil
+}
+
+// previouslyFailedTasks returns the keys of the tasks with a failed run in the
+// results file, or in its compressed form, whichever was written last. It
+// returns nil if neither exists.
+func previouslyFailedTasks(resultsFile string) (map[string]bool, error) {
+	var path string
+	var modTime time.Time
+	for _, candidate := range []string{resultsFile, resultsFile + ".gz"} {
+		info, err := os.Stat(candidate)
+		if err == nil && info.ModTime().After(modTime) {
+			path, modTime = candidate, info.ModTime()
+		}
+	}
+	if path == "" {
+		return nil, nil
+	}
+
+	output, err := results.LoadOutput(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load previous results: %w", err)
+	}
+	failed := make(map[string]bool)
+	for _, r := range output.Results {
+		if !r.TaskPassed && results.CountsTowardsPassRate(r) {
+			failed[results.TaskKey(r)] = true
+		}
+	}
+	return failed, nil
+}
+
+// signResults signs the results file with key and/or with sigstore, if
+// requested.
+func signResults(ctx context.Context, outputFile string, key ed25519.PrivateKey, sigstore, print bool) error {
+	if key != nil {
+		sigPath, err := results.SignFile(outputFile, key)
+		if err != nil {
+			return fmt.Errorf("failed to sign results: %w", err)
+		}
+		if print {
+			fmt.Printf("🔏 Signature saved to: %s\n", sigPath)
+		}
+	}
+	if sigstore {
+		bundle, err := results.SignFileSigstore(ctx, outputFile)
+		if err != nil {
+			return fmt.Errorf("failed to sign results: %w", err)
+		}
+		if print {
+			fmt.Printf("🔏 Sigstore bundle saved to: %s\n", bundle)
+		}
+	}
+	return nil
+}
+
+// attestResults writes the attestation of the results file, signed with key
+// and/or with sigstore, if requested.
+func attestResults(ctx context.Context, outputFile, configFile, evalName string, output *eval.EvalOutput, key ed25519.PrivateKey, sigstore, print bool) error {
+	statement, err := results.NewAttestation(outputFile, configFile, evalName, output)
+	if err != nil {
+		return fmt.Errorf("failed to attest results: %w", err)
+	}
+	path, err := results.WriteAttestation(outputFile, statement, key)
+	if err != nil {
+		return fmt.Errorf("failed to attest results: %w", err)
+	}
+	if print {
+		fmt.Printf("🧾 Attestation saved to: %s\n", path)
+	}
+	if sigstore {
+		bundle, err := results.SignFileSigstore(ctx, path)
+		if err != nil {
+			return fmt.Errorf("failed to sign attestation: %w", err)
+		}
+		if print {
+			fmt.Printf("🔏 Sigstore bundle saved to: %s\n", bundle)
+		}
+	}
 	return nil
 }
 
-// saveErrorToFile saves task error and output to a file and returns the filename
-func saveErrorToFile(taskName, taskError, taskOutput string) (string, error) {
+// saveErrorToFile saves task error and output to a file and returns the filename.
+// The file name includes the run ID, so runs in the same directory don't
+// overwrite each other's error files.
+func saveErrorToFile(runID, taskName, taskError, taskOutput string) (string, error) {
 	// Create a safe filename from task name
 	safeTaskName := strings.ReplaceAll(taskName, "/", "-")
 	safeTaskName = strings.ReplaceAll(safeTaskName, " ", "-")
 	filename := fmt.Sprintf("%s-error.txt", safeTaskName)
+	if runID != "" {
+		filename = fmt.Sprintf("%s-%s-error.txt", safeTaskName, runID)
+	}
 
 	content := ""
+	if runID != "" {
+		content += fmt.Sprintf("Run ID: %s\n\n", runID)
+	}
 	if taskError != "" {
 		content += fmt.Sprintf("=== Error ===\n%s\n", taskError)
 	}
@@ -666,6 +1332,35 @@ func saveErrorToFile(taskName, taskError, taskOutput string) (string, error) {
 	return absPath, nil
 }
 
+// formatUsage renders the tokens of a task run and of the eval so far, with
+// their estimated cost if pricing is configured, e.g.
+// "Tokens: 12.3K, ~$0.04 (total so far: 145.0K, ~$1.23)".
+func formatUsage(u *eval.UsageUpdate) string {
+	run, total := formatTokenCount(u.Tokens()), formatTokenCount(u.TotalTokens())
+	if u.Priced {
+		run += fmt.Sprintf(", ~$%.2f", u.CostUSD)
+		total += fmt.Sprintf(", ~$%.2f", u.TotalCostUSD)
+	}
+	return fmt.Sprintf("Tokens: %s (total so far: %s)", run, total)
+}
+
+// formatStateCounts renders per-state task counts in a fixed order, e.g.
+// "10 active, 2 quarantined, 1 deprecated". Returns "" when only active tasks exist.
+func formatStateCounts(states map[string]int) string {
+	if states[task.StateQuarantined] == 0 && states[task.StateDeprecated] == 0 {
+		return ""
+	}
+
+	var parts []string
+	for _, state := range []string{task.StateActive, task.StateQuarantined, task.StateDeprecated} {
+		if n := states[state]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, state))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
 // formatDuration formats a duration in a human-readable way
 func formatDuration(d time.Duration) string {
 	if d < time.Second {
@@ -684,61 +1379,15 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%dh%dm%ds", hours, minutes, seconds)
 }
 
-// displayConsistencySummary shows pass rates when tasks are run multiple times
+// displayConsistencySummary shows the runs, pass@1, pass@k and flakiness of
+// each task when tasks are run multiple times
 func displayConsistencySummary(results []*eval.EvalResult) {
-	// Check if any task has multiple runs
-	hasMultiRun := false
-	for _, r := range results {
-		if r.TotalRuns > 1 {
-			hasMultiRun = true
-			break
-		}
-	}
-
-	if !hasMultiRun {
+	attempts := eval.SummarizeAttempts(results)
+	if attempts == nil {
 		return
 	}
 
-	bold := color.New(color.Bold)
-	green := color.New(color.FgGreen)
-	yellow := color.New(color.FgYellow)
-
-	// Aggregate by task path
-	type taskAgg struct {
-		taskName  string
-		passCount int
-		totalRuns int
-	}
-	agg := make(map[string]*taskAgg)
-
-	for _, r := range results {
-		key := r.TaskPath
-		if agg[key] == nil {
-			agg[key] = &taskAgg{taskName: r.TaskName}
-		}
-		a := agg[key]
-		a.totalRuns++
-		if r.TaskPassed {
-			a.passCount++
-		}
-	}
-
 	fmt.Println()
-	bold.Println("=== Consistency Summary ===")
-	fmt.Printf("%-40s %s\n", "Task", "Pass Rate")
-	fmt.Println(strings.Repeat("-", 55))
-
-	for _, a := range agg {
-		passRate := float64(a.passCount) / float64(a.totalRuns) * 100
-		status := fmt.Sprintf("%d/%d (%.1f%%)", a.passCount, a.totalRuns, passRate)
-		if a.passCount == a.totalRuns {
-			fmt.Printf("%-40s ", a.taskName)
-			green.Printf("%s\n", status)
-		} else if a.passCount == 0 {
-			fmt.Printf("%-40s ", a.taskName)
-			yellow.Printf("%s\n", status)
-		} else {
-			fmt.Printf("%-40s %s\n", a.taskName, status)
-		}
-	}
+	color.New(color.Bold).Println("=== Consistency Summary ===")
+	outputTextAttempts(os.Stdout, attempts)
 }
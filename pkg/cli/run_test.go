@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+func TestPreviouslyFailedTasks(t *testing.T) {
+	resultsFile := filepath.Join(t.TempDir(), "mcpchecker-test-out.json")
+
+	failed, err := previouslyFailedTasks(resultsFile)
+	if err != nil {
+		t.Fatalf("previouslyFailedTasks() without results: %v", err)
+	}
+	if failed != nil {
+		t.Errorf("previouslyFailedTasks() without results = %v, want nil", failed)
+	}
+
+	output := &eval.EvalOutput{Results: []*eval.EvalResult{
+		{TaskName: "passed", TaskPassed: true},
+		{TaskName: "failed"},
+		{TaskName: "renamed", TaskID: "stable-id"},
+		{TaskName: "flaky", TaskPassed: true},
+		{TaskName: "flaky"},
+		{TaskName: "skipped", SkipReason: eval.SkipReasonRequirementsUnmet},
+	}}
+	if err := saveOutputToFile(output, resultsFile+".gz"); err != nil {
+		t.Fatal(err)
+	}
+
+	failed, err = previouslyFailedTasks(resultsFile)
+	if err != nil {
+		t.Fatalf("previouslyFailedTasks(): %v", err)
+	}
+	got := slices.Sorted(maps.Keys(failed))
+	want := []string{"failed", "flaky", "stable-id"}
+	if !slices.Equal(got, want) {
+		t.Errorf("previouslyFailedTasks() = %v, want %v", got, want)
+	}
+}
+
+func TestSaveErrorToFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	path, err := saveErrorToFile("01JAB3K6W9ZQ8X7Y5V4T3S2R1P", "k8s/create pod", "agent crashed", "partial output")
+	if err != nil {
+		t.Fatalf("saveErrorToFile(): %v", err)
+	}
+	if want := filepath.Join(dir, "k8s-create-pod-01JAB3K6W9ZQ8X7Y5V4T3S2R1P-error.txt"); path != want {
+		t.Errorf("saveErrorToFile() = %q, want %q", path, want)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Run ID: 01JAB3K6W9ZQ8X7Y5V4T3S2R1P", "agent crashed", "partial output"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected the error file to contain %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestFormatUsage(t *testing.T) {
+	tests := map[string]struct {
+		usage *eval.UsageUpdate
+		want  string
+	}{
+		"without pricing": {
+			usage: &eval.UsageUpdate{InputTokens: 10_000, OutputTokens: 2_300, TotalInputTokens: 120_000, TotalOutputTokens: 25_000},
+			want:  "Tokens: 12.3K (total so far: 145.0K)",
+		},
+		"with pricing": {
+			usage: &eval.UsageUpdate{
+				InputTokens: 900, OutputTokens: 100, TotalInputTokens: 1_800_000, TotalOutputTokens: 200_000,
+				CostUSD: 0.0042, TotalCostUSD: 8.4, Priced: true,
+			},
+			want: "Tokens: 1.0K, ~$0.00 (total so far: 2.0M, ~$8.40)",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := formatUsage(tc.usage); got != tc.want {
+				t.Errorf("formatUsage() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEvalCmdAttestRequiresSigning(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	configFile := filepath.Join(dir, "eval.yaml")
+	config := "kind: Eval\nmetadata:\n  name: test\nconfig:\n  taskSets: []\n"
+	if err := os.WriteFile(configFile, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewEvalCmd()
+	cmd.SetArgs([]string{configFile, "--attest"})
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--attest requires --sign-key or --sigstore") {
+		t.Errorf("Execute() = %v, want an error requiring --sign-key or --sigstore", err)
+	}
+}
+
+func TestDisplayStatsByDifficultyExcludesSkipped(t *testing.T) {
+	results := []*eval.EvalResult{
+		{TaskName: "a", Difficulty: "easy", TaskPassed: true},
+		{TaskName: "b", Difficulty: "easy", Skipped: true, SkipReason: eval.SkipReasonRequirementsUnmet},
+		{TaskName: "c", Difficulty: "easy", SkippedOverBudget: true, Skipped: true, SkipReason: eval.SkipReasonBudgetExceeded},
+	}
+
+	oldStdout, oldOutput := os.Stdout, color.Output
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout, color.Output = w, w
+	displayStatsByDifficulty(results, color.New(), color.New())
+	w.Close()
+	os.Stdout, color.Output = oldStdout, oldOutput
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The run skipped over budget still counts as not passed
+	if !strings.Contains(string(out), "Tasks: 1/2") {
+		t.Errorf("displayStatsByDifficulty() = %q, want Tasks: 1/2", out)
+	}
+}
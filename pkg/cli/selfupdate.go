@@ -0,0 +1,419 @@
+package cli
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/genmcp/gen-mcp/pkg/utils/binarycache"
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	releasesAPIURL      = "https://api.github.com/repos/mcpchecker/mcpchecker/releases"
+	releasesDownloadURL = "https://github.com/mcpchecker/mcpchecker/releases/download"
+
+	// Release archives are signed keylessly by the release workflow, run for
+	// a release tag, and nightly archives by the nightly workflow, run on the
+	// main branch
+	releaseSigstoreIdentityRegexp = `^https://github\.com/mcpchecker/mcpchecker/\.github/workflows/release\.ya?ml@refs/tags/.*$`
+	nightlySigstoreIdentityRegexp = `^https://github\.com/mcpchecker/mcpchecker/\.github/workflows/nightly\.ya?ml@refs/heads/main$`
+	releaseSigstoreOIDCIssuer     = "https://token.actions.githubusercontent.com"
+
+	// nightlyTag is the tag of the nightly release
+	nightlyTag = "nightly"
+
+	releaseChecksumsFile = "checksums.txt"
+)
+
+// NewSelfUpdateCmd creates the selfupdate command
+func NewSelfUpdateCmd() *cobra.Command {
+	var target string
+	var check bool
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "selfupdate",
+		Short: "Update mcpchecker to the latest release",
+		Long: `Update the mcpchecker binary to the latest release, or to the release given
+with --version, from GitHub releases.
+
+The release archive for this platform is downloaded, checked against the
+SHA-256 checksums of the release and its sigstore signature, which must have
+been made by the mcpchecker release workflows, and the binary is replaced
+atomically: a failed update leaves the current binary in place.
+
+Binaries installed with a package manager (Homebrew, deb or rpm) should be
+updated with the package manager instead, so they are not replaced unless
+--force is set. Neither are development builds.
+
+Set GITHUB_TOKEN to look up releases with a higher GitHub API rate limit.
+
+Example:
+  mcpchecker selfupdate --check
+  mcpchecker selfupdate
+  mcpchecker selfupdate --version v0.9.0`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			out := cmd.OutOrStdout()
+			u := newUpdater()
+
+			if target == "" {
+				latest, err := u.latestVersion(ctx)
+				if err != nil {
+					return err
+				}
+				target = latest
+			}
+
+			if !force && !updateAvailable(Version, target, cmd.Flags().Changed("version")) {
+				fmt.Fprintf(out, "mcpchecker %s is up to date\n", Version)
+				return nil
+			}
+			if check {
+				fmt.Fprintf(out, "Update available: %s -> %s\n", Version, target)
+				return nil
+			}
+			if !semver.IsValid(Version) && !force {
+				return fmt.Errorf("mcpchecker %s is a development build, use --force to replace it with %s", version(), target)
+			}
+
+			exe, err := executablePath()
+			if err != nil {
+				return err
+			}
+			if manager := packageManagerOf(exe); manager != "" && !force {
+				return fmt.Errorf("%s was installed with %s, update it with %s instead (or use --force)", exe, manager, manager)
+			}
+
+			next, err := u.download(ctx, target, filepath.Dir(exe))
+			if err != nil {
+				return err
+			}
+			defer func() { _ = os.Remove(next) }()
+
+			if err := replaceExecutable(exe, next); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Updated mcpchecker from %s to %s\n", Version, target)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&target, "version", "", "Release to install, e.g. v0.9.0 or nightly (default: the latest release)")
+	cmd.Flags().BoolVar(&check, "check", false, "Only report whether an update is available")
+	cmd.Flags().BoolVar(&force, "force", false, "Install the release even if it is not newer, over development builds and over binaries installed with a package manager")
+
+	return cmd
+}
+
+// updater downloads and verifies mcpchecker releases.
+type updater struct {
+	client      *http.Client
+	apiURL      string
+	downloadURL string
+	goos        string
+	goarch      string
+
+	// verifySignature checks the sigstore bundle of the archive of the
+	// release tag
+	verifySignature func(tag, archive, bundle string) error
+}
+
+func newUpdater() *updater {
+	return &updater{
+		client:          &http.Client{Timeout: 5 * time.Minute},
+		apiURL:          releasesAPIURL,
+		downloadURL:     releasesDownloadURL,
+		goos:            runtime.GOOS,
+		goarch:          runtime.GOARCH,
+		verifySignature: verifyReleaseSignature,
+	}
+}
+
+// verifyReleaseSignature checks that the sigstore bundle of archive was made
+// by the mcpchecker workflow that publishes the release tag.
+func verifyReleaseSignature(tag, archive, bundle string) error {
+	verifier, err := binarycache.NewSigstoreVerifier(&binarycache.Config{
+		SigstoreIdentityRegexp: sigstoreIdentityRegexp(tag),
+		SigstoreOIDCIssuer:     releaseSigstoreOIDCIssuer,
+	})
+	if err != nil {
+		return err
+	}
+	return verifier.VerifyBlob(archive, bundle)
+}
+
+// sigstoreIdentityRegexp returns the identity of the workflow that signs the
+// archives of the release tag.
+func sigstoreIdentityRegexp(tag string) string {
+	if tag == nightlyTag {
+		return nightlySigstoreIdentityRegexp
+	}
+	return releaseSigstoreIdentityRegexp
+}
+
+// latestVersion returns the tag of the latest release, which excludes
+// pre-releases and nightly builds.
+func (u *updater) latestVersion(ctx context.Context) (string, error) {
+	body, err := u.get(ctx, u.apiURL+"/latest")
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the latest release: %w", err)
+	}
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.Unmarshal(body, &release); err != nil {
+		return "", fmt.Errorf("failed to parse the latest release: %w", err)
+	}
+	if release.TagName == "" {
+		return "", fmt.Errorf("the latest release has no tag")
+	}
+	return release.TagName, nil
+}
+
+// download downloads the binary of release tag for the platform of u to a
+// new file in dir, after checking the checksum and signature of its archive,
+// and returns its path.
+func (u *updater) download(ctx context.Context, tag, dir string) (string, error) {
+	tmp, err := os.MkdirTemp("", "mcpchecker-update-")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	archive := fmt.Sprintf("mcpchecker-%s-%s.zip", u.goos, u.goarch)
+	checksums, err := u.get(ctx, u.releaseURL(tag, releaseChecksumsFile))
+	if err != nil {
+		return "", fmt.Errorf("failed to download the checksums of %s: %w", tag, err)
+	}
+	want, err := releaseChecksum(checksums, archive)
+	if err != nil {
+		return "", fmt.Errorf("release %s: %w", tag, err)
+	}
+
+	archivePath := filepath.Join(tmp, archive)
+	if err := u.downloadFile(ctx, u.releaseURL(tag, archive), archivePath); err != nil {
+		return "", fmt.Errorf("failed to download %s of %s: %w", archive, tag, err)
+	}
+	got, err := fileSHA256(archivePath)
+	if err != nil {
+		return "", err
+	}
+	if got != want {
+		return "", fmt.Errorf("checksum mismatch for %s of %s: expected %s, got %s", archive, tag, want, got)
+	}
+
+	bundlePath := archivePath + ".bundle"
+	if err := u.downloadFile(ctx, u.releaseURL(tag, archive+".bundle"), bundlePath); err != nil {
+		return "", fmt.Errorf("failed to download the signature of %s of %s: %w", archive, tag, err)
+	}
+	if err := u.verifySignature(tag, archivePath, bundlePath); err != nil {
+		return "", fmt.Errorf("signature of %s of %s is invalid: %w", archive, tag, err)
+	}
+
+	binary := "mcpchecker"
+	if u.goos == "windows" {
+		binary += ".exe"
+	}
+	return extractZipFile(archivePath, binary, dir)
+}
+
+func (u *updater) releaseURL(tag, file string) string {
+	return fmt.Sprintf("%s/%s/%s", u.downloadURL, tag, file)
+}
+
+func (u *updater) request(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" && strings.HasPrefix(url, u.apiURL) {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("GET %s returned status %d", url, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+func (u *updater) get(ctx context.Context, url string) ([]byte, error) {
+	resp, err := u.request(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return io.ReadAll(resp.Body)
+}
+
+func (u *updater) downloadFile(ctx context.Context, url, dest string) error {
+	resp, err := u.request(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// releaseChecksum returns the SHA-256 checksum of file in the checksums file
+// of a release, in the format of sha256sum.
+func releaseChecksum(checksums []byte, file string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == file {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum for %s in %s", file, releaseChecksumsFile)
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// extractZipFile extracts the file named name from the zip archive to a new
+// executable file in dir, and returns its path.
+func extractZipFile(archive, name, dir string) (string, error) {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", filepath.Base(archive), err)
+	}
+	defer func() { _ = r.Close() }()
+
+	for _, f := range r.File {
+		if path.Base(f.Name) != name || f.FileInfo().IsDir() {
+			continue
+		}
+
+		src, err := f.Open()
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = src.Close() }()
+
+		dest, err := os.CreateTemp(dir, ".mcpchecker-update-*")
+		if err != nil {
+			return "", fmt.Errorf("failed to write the new binary next to the current one: %w", err)
+		}
+		if _, err := io.Copy(dest, src); err != nil {
+			_ = dest.Close()
+			_ = os.Remove(dest.Name())
+			return "", err
+		}
+		if err := dest.Close(); err != nil {
+			_ = os.Remove(dest.Name())
+			return "", err
+		}
+		if err := os.Chmod(dest.Name(), 0755); err != nil {
+			_ = os.Remove(dest.Name())
+			return "", err
+		}
+		return dest.Name(), nil
+	}
+	return "", fmt.Errorf("%s not found in %s", name, filepath.Base(archive))
+}
+
+// replaceExecutable replaces the executable at exe with next by renaming it,
+// so exe is either the old or the new binary at any time. Running binaries
+// can't be replaced on Windows, but they can be renamed, so the old binary is
+// moved aside to exe.old first.
+func replaceExecutable(exe, next string) error {
+	if runtime.GOOS != "windows" {
+		if err := os.Rename(next, exe); err != nil {
+			return fmt.Errorf("failed to replace %s: %w", exe, err)
+		}
+		return nil
+	}
+
+	old := exe + ".old"
+	// Left over by the previous update
+	_ = os.Remove(old)
+	if err := os.Rename(exe, old); err != nil {
+		return fmt.Errorf("failed to move %s aside: %w", exe, err)
+	}
+	if err := os.Rename(next, exe); err != nil {
+		_ = os.Rename(old, exe)
+		return fmt.Errorf("failed to replace %s: %w", exe, err)
+	}
+	return nil
+}
+
+// executablePath returns the path of the running binary, with symlinks
+// resolved so the binary rather than the link is replaced.
+func executablePath() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the mcpchecker binary: %w", err)
+	}
+	return filepath.EvalSymlinks(exe)
+}
+
+// packageManagerOf returns the package manager that installed the binary at
+// exe, or an empty string if it was installed by hand.
+func packageManagerOf(exe string) string {
+	slashed := filepath.ToSlash(exe)
+	switch {
+	case strings.Contains(slashed, "/Caskroom/"), strings.Contains(slashed, "/Cellar/"):
+		return "Homebrew"
+	case strings.HasPrefix(slashed, "/usr/bin/"):
+		return "the system package manager"
+	}
+	return ""
+}
+
+// updateAvailable reports whether target should replace the current version.
+// A release given explicitly replaces any other version, while the latest
+// release only replaces older ones.
+func updateAvailable(current, target string, explicit bool) bool {
+	if current == target {
+		return false
+	}
+	if explicit || !semver.IsValid(current) || !semver.IsValid(target) {
+		return true
+	}
+	return semver.Compare(current, target) < 0
+}
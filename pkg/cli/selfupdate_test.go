@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// newReleaseServer serves a release feed whose latest release is tag, with
+// the archive of the mcpchecker binary for linux/amd64 and its checksums.
+func newReleaseServer(t *testing.T, tag string, binary []byte, checksum string) *updater {
+	t.Helper()
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	w, err := zw.Create("mcpchecker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(binary); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if checksum == "" {
+		sum := sha256.Sum256(archive.Bytes())
+		checksum = hex.EncodeToString(sum[:])
+	}
+
+	files := map[string]string{
+		"/api/latest": fmt.Sprintf(`{"tag_name": %q}`, tag),
+		"/download/" + tag + "/checksums.txt": checksum + "  mcpchecker-linux-amd64.zip\n" +
+			strings.Repeat("0", 64) + "  mcpchecker-darwin-arm64.zip\n",
+		"/download/" + tag + "/mcpchecker-linux-amd64.zip":        archive.String(),
+		"/download/" + tag + "/mcpchecker-linux-amd64.zip.bundle": "{}",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+
+	return &updater{
+		client:          server.Client(),
+		apiURL:          server.URL + "/api",
+		downloadURL:     server.URL + "/download",
+		goos:            "linux",
+		goarch:          "amd64",
+		verifySignature: func(tag, archive, bundle string) error { return nil },
+	}
+}
+
+func TestUpdaterDownload(t *testing.T) {
+	u := newReleaseServer(t, "v1.2.0", []byte("new binary"), "")
+	ctx := context.Background()
+
+	latest, err := u.latestVersion(ctx)
+	if err != nil || latest != "v1.2.0" {
+		t.Fatalf("latestVersion() = %q, %v, want v1.2.0", latest, err)
+	}
+
+	var verified string
+	u.verifySignature = func(tag, archive, bundle string) error {
+		verified = tag + " " + filepath.Base(archive) + " " + filepath.Base(bundle)
+		return nil
+	}
+	dir := t.TempDir()
+	next, err := u.download(ctx, latest, dir)
+	if err != nil {
+		t.Fatalf("download() failed: %v", err)
+	}
+	if verified != "v1.2.0 mcpchecker-linux-amd64.zip mcpchecker-linux-amd64.zip.bundle" {
+		t.Errorf("expected the signature of the archive to be verified, got %q", verified)
+	}
+	if filepath.Dir(next) != dir {
+		t.Errorf("expected the new binary in %s, got %s", dir, next)
+	}
+	data, err := os.ReadFile(next)
+	if err != nil || string(data) != "new binary" {
+		t.Errorf("new binary = %q, %v", data, err)
+	}
+
+	exe := filepath.Join(dir, "mcpchecker")
+	if err := os.WriteFile(exe, []byte("old binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := replaceExecutable(exe, next); err != nil {
+		t.Fatalf("replaceExecutable() failed: %v", err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "new binary" {
+		t.Errorf("expected the binary to be replaced, got %q", data)
+	}
+}
+
+func TestUpdaterDownloadErrors(t *testing.T) {
+	tests := map[string]struct {
+		checksum  string
+		tag       string
+		signature error
+		wantErr   string
+	}{
+		"checksum mismatch": {
+			checksum: strings.Repeat("a", 64),
+			wantErr:  "checksum mismatch for mcpchecker-linux-amd64.zip of v1.2.0",
+		},
+		"invalid signature": {
+			signature: errors.New("certificate identity mismatch"),
+			wantErr:   "signature of mcpchecker-linux-amd64.zip of v1.2.0 is invalid: certificate identity mismatch",
+		},
+		"unknown release": {
+			tag:     "v9.9.9",
+			wantErr: "failed to download the checksums of v9.9.9",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			u := newReleaseServer(t, "v1.2.0", []byte("new binary"), tc.checksum)
+			u.verifySignature = func(tag, archive, bundle string) error { return tc.signature }
+			tag := tc.tag
+			if tag == "" {
+				tag = "v1.2.0"
+			}
+
+			dir := t.TempDir()
+			_, err := u.download(context.Background(), tag, dir)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+				t.Errorf("expected nothing to be written next to the binary, got %v", entries)
+			}
+		})
+	}
+}
+
+func TestUpdateAvailable(t *testing.T) {
+	tests := map[string]struct {
+		current  string
+		target   string
+		explicit bool
+		want     bool
+	}{
+		"older":                 {current: "v1.1.0", target: "v1.2.0", want: true},
+		"same":                  {current: "v1.2.0", target: "v1.2.0"},
+		"newer":                 {current: "v1.3.0-rc.1", target: "v1.2.0"},
+		"explicit downgrade":    {current: "v1.3.0", target: "v1.2.0", explicit: true, want: true},
+		"development build":     {current: "development", target: "v1.2.0", want: true},
+		"explicit same nightly": {current: "nightly", target: "nightly", explicit: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := updateAvailable(tc.current, tc.target, tc.explicit); got != tc.want {
+				t.Errorf("updateAvailable(%q, %q, %v) = %v, want %v", tc.current, tc.target, tc.explicit, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSigstoreIdentityRegexp(t *testing.T) {
+	tests := map[string]struct {
+		tag        string
+		identities map[string]bool
+	}{
+		"release": {
+			tag: "v1.2.0",
+			identities: map[string]bool{
+				"https://github.com/mcpchecker/mcpchecker/.github/workflows/release.yaml@refs/tags/v1.2.0":                  true,
+				"https://github.com/mcpchecker/mcpchecker/.github/workflows/release.yml@refs/tags/v1.2.0":                   true,
+				"https://github.com/mcpchecker/mcpchecker/.github/workflows/ci.yaml@refs/tags/v1.2.0":                       false,
+				"https://github.com/mcpchecker/mcpchecker/.github/workflows/release.yaml@refs/heads/main":                   false,
+				"https://github.com/mcpchecker/mcpchecker/.github/workflows/nightly.yaml@refs/heads/main":                   false,
+				"https://github.com/mcpchecker/mcpchecker-fork/.github/workflows/release.yaml@refs/tags/v1":                 false,
+				"https://evil.example/https://github.com/mcpchecker/mcpchecker/.github/workflows/release.yaml@refs/tags/v1": false,
+			},
+		},
+		"nightly": {
+			tag: "nightly",
+			identities: map[string]bool{
+				"https://github.com/mcpchecker/mcpchecker/.github/workflows/nightly.yaml@refs/heads/main":      true,
+				"https://github.com/mcpchecker/mcpchecker/.github/workflows/nightly.yaml@refs/heads/feature":   false,
+				"https://github.com/mcpchecker/mcpchecker/.github/workflows/nightly.yaml@refs/tags/nightly":    false,
+				"https://github.com/mcpchecker/mcpchecker/.github/workflows/release.yaml@refs/tags/v1.2.0":     false,
+				"https://github.com/mcpchecker/mcpchecker-fork/.github/workflows/nightly.yaml@refs/heads/main": false,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			re := regexp.MustCompile(sigstoreIdentityRegexp(tc.tag))
+			for identity, want := range tc.identities {
+				if got := re.MatchString(identity); got != want {
+					t.Errorf("MatchString(%q) = %v, want %v", identity, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPackageManagerOf(t *testing.T) {
+	tests := map[string]string{
+		"/opt/homebrew/Caskroom/mcpchecker/0.9.0/mcpchecker": "Homebrew",
+		"/usr/bin/mcpchecker":                                "the system package manager",
+		"/usr/local/bin/mcpchecker":                          "",
+		"/home/ci/bin/mcpchecker":                            "",
+	}
+
+	for exe, want := range tests {
+		if got := packageManagerOf(exe); got != want {
+			t.Errorf("packageManagerOf(%q) = %q, want %q", exe, got, want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// NewServeCmd creates the serve command
+func NewServeCmd() *cobra.Command {
+	var addr string
+	var recordFile string
+	var mcpConfigFile string
+	var maxConcurrent int
+	var token string
+
+	cmd := &cobra.Command{
+		Use:   "serve <eval-config-file|bundle>",
+		Short: "Serve the agent of an eval as an OpenAI-compatible chat completions API",
+		Long: `Serve an OpenAI-compatible API at /v1/chat/completions that runs the agent of
+an eval config with its MCP servers, through the same MCP proxy as 'check', for
+each request. Load testing tools, playgrounds and other OpenAI clients can then
+drive the agent pipeline that evals use.
+
+The agent gets a single prompt: the content of the user message, or, for
+requests with several messages, a transcript of them that ends with the last
+user message. The model and sampling parameters of requests are ignored, and
+/v1/models lists the agent as the only model. Streaming requests get the final
+message of the agent in one chunk once it is done.
+
+Each request is recorded as a line of the record file, with the prompt, the
+output and tool calls of the agent, the calls the MCP proxy recorded and the
+token estimate. No tasks run: setup, verification and cleanup steps are not
+run.
+
+With --token, requests must send the token as a bearer token, the API key of
+OpenAI clients. The agent runs with the MCP servers of the eval for anyone who
+can reach the API, so serving on an address other than a loopback one, such as
+0.0.0.0, requires a token. Requests from browsers, which send an Origin header,
+completion requests that aren't application/json and, without a token,
+requests for a host other than a loopback address are rejected.
+
+Example:
+  mcpchecker serve eval.yaml --addr 127.0.0.1:8080
+  curl http://127.0.0.1:8080/v1/chat/completions -H "Content-Type: application/json" \
+    -d '{"messages": [{"role": "user", "content": "List the pods"}]}'`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkServeAddr(addr, token); err != nil {
+				return err
+			}
+			util.RegisterSecret(token)
+
+			configFile := args[0]
+			if eval.IsBundle(configFile) {
+				bundleConfig, cleanup, err := extractBundle(configFile)
+				if err != nil {
+					return fmt.Errorf("failed to extract bundle: %w", err)
+				}
+				defer cleanup()
+				configFile = bundleConfig
+			}
+
+			spec, err := eval.FromFile(configFile)
+			if err != nil {
+				return fmt.Errorf("failed to load eval config: %w", err)
+			}
+			if mcpConfigFile != "" {
+				if spec.Config.McpConfigFile, err = filepath.Abs(mcpConfigFile); err != nil {
+					return fmt.Errorf("failed to resolve mcp config file: %w", err)
+				}
+			}
+
+			if recordFile == "" {
+				recordFile = fmt.Sprintf("mcpchecker-%s-chat.ndjson", spec.Metadata.Name)
+			}
+			record, err := os.OpenFile(recordFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				return fmt.Errorf("failed to open record file: %w", err)
+			}
+			defer record.Close()
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			server, err := eval.NewChatServer(ctx, spec, eval.ChatServerOptions{
+				Record:        record,
+				MaxConcurrent: maxConcurrent,
+				Token:         token,
+			})
+			if err != nil {
+				return err
+			}
+			defer server.Close()
+
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				return fmt.Errorf("failed to listen: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Serving agent %q at http://%s/v1, recording to %s\n", server.Model(), listener.Addr(), recordFile)
+
+			return serveChat(ctx, listener, server.Handler())
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", "127.0.0.1:8080", "Address to listen on")
+	cmd.Flags().StringVar(&recordFile, "record", "", "File the requests are appended to (default: mcpchecker-<eval-name>-chat.ndjson)")
+	cmd.Flags().StringVar(&mcpConfigFile, "mcp-config-file", "", "Path to MCP config file (overrides value in eval config)")
+	cmd.Flags().StringVar(&token, "token", "", "Bearer token that requests must authenticate with (required to listen on non-loopback addresses)")
+	cmd.Flags().IntVar(&maxConcurrent, "max-concurrent", 0, "Maximum number of requests that run the agent at once; others wait (0 for no limit)")
+
+	return cmd
+}
+
+// checkServeAddr refuses to serve the API on addr without a token unless addr
+// only accepts connections from the local machine.
+func checkServeAddr(addr, token string) error {
+	if token != "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid --addr %q: %w", addr, err)
+	}
+	if !util.IsLoopbackHost(host) {
+		return fmt.Errorf("refusing to serve on %s without --token: anyone who can reach it could run the agent with the MCP servers of the eval", addr)
+	}
+	return nil
+}
+
+// serveChat serves handler on listener until ctx is done, then waits for the
+// requests in progress to finish.
+func serveChat(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	httpServer := &http.Server{Handler: handler}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("server shutdown failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeChat(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serveChat(ctx, listener, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "ok")
+		}))
+	}()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/v1/models")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "ok" {
+		t.Errorf("body = %q", body)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("serveChat returned %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("serveChat did not return after the context was done")
+	}
+}
+
+func TestCheckServeAddr(t *testing.T) {
+	tests := map[string]struct {
+		addr      string
+		token     string
+		expectErr string
+	}{
+		"loopback":                 {addr: "127.0.0.1:8080"},
+		"localhost":                {addr: "localhost:8080"},
+		"ipv6 loopback":            {addr: "[::1]:8080"},
+		"all interfaces":           {addr: "0.0.0.0:8080", expectErr: "refusing to serve on 0.0.0.0:8080 without --token"},
+		"no host":                  {addr: ":8080", expectErr: "without --token"},
+		"all interfaces and token": {addr: "0.0.0.0:8080", token: "t0ken"},
+		"no port":                  {addr: "127.0.0.1", expectErr: "invalid --addr"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := checkServeAddr(tc.addr, tc.token)
+			if tc.expectErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tc.expectErr)
+			}
+		})
+	}
+}
@@ -3,11 +3,19 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
 	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 	"github.com/spf13/cobra"
 )
 
@@ -16,6 +24,10 @@ type SummaryOutput struct {
 	Tasks                  []TaskSummary `json:"tasks"`
 	TasksTotal             int           `json:"tasksTotal"`
 	TasksPassed            int           `json:"tasksPassed"`
+	TasksQuarantined       int           `json:"tasksQuarantined,omitempty"`
+	TasksJudgeErrored      int           `json:"tasksJudgeErrored,omitempty"`
+	TasksSkippedOverBudget int           `json:"tasksSkippedOverBudget,omitempty"`
+	TasksSkipped           int           `json:"tasksSkipped,omitempty"` // skipped for another reason, and left out of TaskPassRate
 	TaskPassRate           float64       `json:"taskPassRate"`
 	AssertionsTotal        int           `json:"assertionsTotal"`
 	AssertionsPassed       int           `json:"assertionsPassed"`
@@ -26,11 +38,38 @@ type SummaryOutput struct {
 	AgentTotalOutputTokens int64         `json:"agentTotalOutputTokens"`
 	JudgeTotalInputTokens  int64         `json:"judgeTotalInputTokens"`
 	JudgeTotalOutputTokens int64         `json:"judgeTotalOutputTokens"`
+
+	// AgentResourceUsage sums the wall and CPU time of agent processes and keeps the peak RSS
+	AgentResourceUsage *util.ResourceUsage `json:"agentResourceUsage,omitempty"`
+
+	// McpTraffic totals the sizes of the MCP calls of all tasks by server
+	McpTraffic map[string]*mcpproxy.CallSizes `json:"mcpTraffic,omitempty"`
+
+	// ErrorKinds counts the runs that did not pass by the kind of their error
+	ErrorKinds results.ErrorCounts `json:"errorKinds"`
+
+	// Metadata is the run metadata of the results file
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// ExpectedFailures counts the runs of tasks that are expected to fail,
+	// which are left out of verify thresholds, by their outcome
+	ExpectedFailures results.ExpectedFailureCounts `json:"expectedFailures"`
+
+	// Environments are the statistics of each environment of the eval config
+	// that tasks ran against
+	Environments map[string]results.Stats `json:"environments,omitempty"`
+
+	// Attempts scores the tasks that ran more than once with pass@1, pass@k
+	// and flakiness
+	Attempts *eval.AttemptsSummary `json:"attempts,omitempty"`
 }
 
 type TaskSummary struct {
 	Name              string   `json:"name"`
+	State             string   `json:"state,omitempty"`
 	TaskPassed        bool     `json:"taskPassed"`
+	JudgeError        bool     `json:"judgeError,omitempty"`
+	SkippedOverBudget bool     `json:"skippedOverBudget,omitempty"`
 	AssertionsPassed  bool     `json:"assertionsPassed"`
 	TaskError         string   `json:"taskError,omitempty"`
 	FailedAssertions  []string `json:"failedAssertions,omitempty"`
@@ -41,12 +80,32 @@ type TaskSummary struct {
 	AgentOutputTokens int64    `json:"agentOutputTokens"`
 	JudgeInputTokens  int64    `json:"judgeInputTokens"`
 	JudgeOutputTokens int64    `json:"judgeOutputTokens"`
+
+	ResourceUsage *util.ResourceUsage `json:"resourceUsage,omitempty"`
+
+	// McpTraffic totals the sizes of the MCP calls of the task by server
+	McpTraffic map[string]*mcpproxy.CallSizes `json:"mcpTraffic,omitempty"`
+
+	// ErrorKind is the kind of error the run failed with, if it did not pass
+	ErrorKind task.ErrorKind `json:"errorKind,omitempty"`
+
+	// SkipReason is why the run was skipped, if it was not started
+	SkipReason eval.SkipReason `json:"skipReason,omitempty"`
+
+	// ExpectFailure is set if the task is expected to fail
+	ExpectFailure bool `json:"expectFailure,omitempty"`
+
+	// Environment is the environment the task ran against, if any
+	Environment string `json:"environment,omitempty"`
 }
 
 func NewSummaryCmd() *cobra.Command {
 	var taskFilter string
 	var outputFormat string
 	var githubOutput bool
+	var gitlabOutput bool
+	var jenkinsOutput bool
+	var junitFile string
 
 	cmd := &cobra.Command{
 		Use:   "summary <results-file>",
@@ -56,25 +115,48 @@ func NewSummaryCmd() *cobra.Command {
 Supports multiple output formats:
   - text (default): Human-readable summary with colors
   - json: Machine-readable JSON output
-  - --github-output: GitHub Actions format (key=value)`,
+  - --github-output: GitHub Actions format (key=value)
+  - --gitlab-output: GitLab CI dotenv report (MCPCHECKER_KEY=value)
+  - --jenkins-output: Java properties for readProperties in Jenkins pipelines
+
+With --junit-file, a JUnit XML report of the results is also written, for
+the native test reports of GitLab and Jenkins, and its path is included in
+the CI output as junit-report.`,
 		Args:         cobra.ExactArgs(1),
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			resultsFile := args[0]
 
-			evalResults, err := results.Load(resultsFile)
+			output, err := results.LoadOutput(resultsFile)
 			if err != nil {
 				return fmt.Errorf("failed to load results file: %w", err)
 			}
 
+			evalResults := output.Results
 			if taskFilter != "" {
 				evalResults = results.Filter(evalResults, taskFilter)
 			}
 
 			summary := buildSummaryOutput(resultsFile, evalResults)
+			if output.Summary != nil {
+				summary.Metadata = output.Summary.Metadata
+			}
 
-			if githubOutput {
-				outputGitHubSummary(summary)
+			if junitFile != "" {
+				if err := writeJUnitFile(junitFile, evalResults); err != nil {
+					return err
+				}
+			}
+
+			switch {
+			case githubOutput:
+				outputGitHubSummary(summary, junitFile)
+				return nil
+			case gitlabOutput:
+				outputGitLabSummary(summary, junitFile)
+				return nil
+			case jenkinsOutput:
+				outputJenkinsSummary(summary, junitFile)
 				return nil
 			}
 
@@ -94,6 +176,10 @@ Supports multiple output formats:
 	cmd.Flags().StringVar(&taskFilter, "task", "", "Filter results by task name")
 	cmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Output format (text, json)")
 	cmd.Flags().BoolVar(&githubOutput, "github-output", false, "Output in GitHub Actions format (key=value)")
+	cmd.Flags().BoolVar(&gitlabOutput, "gitlab-output", false, "Output as a GitLab CI dotenv report (MCPCHECKER_KEY=value)")
+	cmd.Flags().BoolVar(&jenkinsOutput, "jenkins-output", false, "Output as Java properties for readProperties in Jenkins pipelines, with a one line summary")
+	cmd.Flags().StringVar(&junitFile, "junit-file", "", "Also write a JUnit XML report of the results to this file")
+	cmd.MarkFlagsMutuallyExclusive("github-output", "gitlab-output", "jenkins-output")
 
 	return cmd
 }
@@ -107,18 +193,40 @@ func buildSummaryOutput(resultsFile string, evalResults []*eval.EvalResult) Summ
 
 	for _, result := range evalResults {
 		taskSummary := TaskSummary{
-			Name:             result.TaskName,
-			TaskPassed:       result.TaskPassed,
-			AssertionsPassed: result.AllAssertionsPassed,
+			Name:              result.TaskName,
+			Environment:       result.Environment,
+			State:             result.State,
+			TaskPassed:        result.TaskPassed,
+			JudgeError:        result.JudgeError,
+			SkippedOverBudget: result.SkippedOverBudget,
+			AssertionsPassed:  result.AllAssertionsPassed,
+			SkipReason:        results.SkipReason(result),
+			ExpectFailure:     result.ExpectFailure,
 		}
 
 		if result.TaskPassed {
 			summary.TasksPassed++
 		}
+		if result.State == task.StateQuarantined {
+			summary.TasksQuarantined++
+		}
+		if result.JudgeError {
+			summary.TasksJudgeErrored++
+		}
+		if result.SkippedOverBudget {
+			summary.TasksSkippedOverBudget++
+		}
+		if !results.CountsTowardsPassRate(result) {
+			summary.TasksSkipped++
+		}
 
 		// Collect task error
 		if !result.TaskPassed {
-			if result.AgentExecutionError {
+			taskSummary.ErrorKind = results.ErrorKind(result)
+			summary.ErrorKinds.Add(taskSummary.ErrorKind)
+			if result.JudgeError {
+				taskSummary.TaskError = "Judge error: " + result.TaskJudgeError
+			} else if result.AgentExecutionError {
 				taskSummary.TaskError = "Agent execution failed"
 			} else if result.TaskError != "" {
 				taskSummary.TaskError = result.TaskError
@@ -162,12 +270,39 @@ func buildSummaryOutput(resultsFile string, evalResults []*eval.EvalResult) Summ
 			summary.JudgeTotalOutputTokens += result.JudgeTokenUsage.OutputTokens
 		}
 
+		// Collect agent process resource usage
+		if result.ResourceUsage != nil {
+			taskSummary.ResourceUsage = result.ResourceUsage
+			if summary.AgentResourceUsage == nil {
+				summary.AgentResourceUsage = &util.ResourceUsage{}
+			}
+			summary.AgentResourceUsage.Add(result.ResourceUsage)
+		}
+
+		// Collect MCP request and response sizes
+		if traffic := result.CallHistory.SizesByServer(); traffic != nil {
+			taskSummary.McpTraffic = traffic
+			if summary.McpTraffic == nil {
+				summary.McpTraffic = make(map[string]*mcpproxy.CallSizes)
+			}
+			for server, sizes := range traffic {
+				if summary.McpTraffic[server] == nil {
+					summary.McpTraffic[server] = &mcpproxy.CallSizes{}
+				}
+				summary.McpTraffic[server].Add(sizes)
+			}
+		}
+
 		summary.Tasks = append(summary.Tasks, taskSummary)
 	}
 
+	summary.ExpectedFailures = results.CountExpectedFailures(evalResults)
+	summary.Environments = results.CalculateStatsByEnvironment(resultsFile, evalResults)
+	summary.Attempts = eval.SummarizeAttempts(evalResults)
+
 	// Calculate pass rates
-	if summary.TasksTotal > 0 {
-		summary.TaskPassRate = float64(summary.TasksPassed) / float64(summary.TasksTotal)
+	if counted := summary.TasksTotal - summary.TasksSkipped; counted > 0 {
+		summary.TaskPassRate = float64(summary.TasksPassed) / float64(counted)
 	}
 	if summary.AssertionsTotal > 0 {
 		summary.AssertionPassRate = float64(summary.AssertionsPassed) / float64(summary.AssertionsTotal)
@@ -176,60 +311,158 @@ func buildSummaryOutput(resultsFile string, evalResults []*eval.EvalResult) Summ
 	return summary
 }
 
+// countErrorKinds counts the failed runs in evalResults by the kind of their error.
+func countErrorKinds(evalResults []*eval.EvalResult) results.ErrorCounts {
+	var counts results.ErrorCounts
+	for _, result := range evalResults {
+		if !result.TaskPassed {
+			counts.Add(results.ErrorKind(result))
+		}
+	}
+	return counts
+}
+
 func outputTextSummary(evalResults []*eval.EvalResult, summary SummaryOutput) {
-	green := color.New(color.FgGreen)
-	red := color.New(color.FgRed)
-	yellow := color.New(color.FgYellow)
 	bold := color.New(color.Bold)
 
 	bold.Println("=== Evaluation Summary ===")
 	fmt.Println()
 
+	if len(summary.Metadata) > 0 {
+		for _, key := range slices.Sorted(maps.Keys(summary.Metadata)) {
+			fmt.Printf("  %s: %s\n", key, summary.Metadata[key])
+		}
+		fmt.Println()
+	}
+
 	for i, result := range evalResults {
-		taskSummary := summary.Tasks[i]
+		printTaskSummary(result, summary.Tasks[i])
+	}
 
-		// Determine overall status
-		passed := result.TaskPassed && result.AllAssertionsPassed
+	fmt.Println()
+	printSummaryTotals(summary)
 
-		// Count task assertions
-		var taskAssertionsPassed, taskAssertionsTotal int
-		if result.AssertionResults != nil {
-			taskAssertionsPassed = result.AssertionResults.PassedAssertions()
-			taskAssertionsTotal = result.AssertionResults.TotalAssertions()
-		}
+	if summary.Attempts != nil {
+		fmt.Println()
+		bold.Println("=== Attempts ===")
+		outputTextAttempts(os.Stdout, summary.Attempts)
+	}
+}
 
-		// Print task line
-		if passed {
-			green.Printf("  ✓ %s", result.TaskName)
-		} else if result.TaskPassed && !result.AllAssertionsPassed {
-			yellow.Printf("  ~ %s", result.TaskName)
-		} else {
-			red.Printf("  ✗ %s", result.TaskName)
-		}
+// printTaskSummary prints the status line of a task run, followed by its
+// failure details. Used by both the summary and tail commands.
+func printTaskSummary(result *eval.EvalResult, taskSummary TaskSummary) {
+	green := color.New(color.FgGreen)
+	red := color.New(color.FgRed)
+	yellow := color.New(color.FgYellow)
 
-		// Print assertion count if any
-		if taskAssertionsTotal > 0 {
-			fmt.Printf(" (assertions: %d/%d)", taskAssertionsPassed, taskAssertionsTotal)
-		}
-		fmt.Println()
+	// Determine overall status
+	passed := result.TaskPassed && result.AllAssertionsPassed
 
-		// Print failure details
-		if taskSummary.TaskError != "" {
-			fmt.Printf("      %s\n", taskSummary.TaskError)
-		}
+	// Count task assertions
+	var taskAssertionsPassed, taskAssertionsTotal int
+	if result.AssertionResults != nil {
+		taskAssertionsPassed = result.AssertionResults.PassedAssertions()
+		taskAssertionsTotal = result.AssertionResults.TotalAssertions()
+	}
 
-		// Print failed assertions
-		for _, failure := range taskSummary.FailedAssertions {
-			red.Printf("      - %s\n", failure)
-		}
+	// Print task line
+	if results.XPassed(result) {
+		yellow.Printf("  ! %s", result.TaskName)
+	} else if results.XFailed(result) {
+		yellow.Printf("  x %s", result.TaskName)
+	} else if passed {
+		green.Printf("  ✓ %s", result.TaskName)
+	} else if taskSummary.SkipReason != "" {
+		yellow.Printf("  - %s", result.TaskName)
+	} else if result.JudgeError {
+		yellow.Printf("  ? %s", result.TaskName)
+	} else if result.TaskPassed && !result.AllAssertionsPassed {
+		yellow.Printf("  ~ %s", result.TaskName)
+	} else {
+		red.Printf("  ✗ %s", result.TaskName)
+	}
+
+	if result.Environment != "" {
+		fmt.Printf(" @%s", result.Environment)
+	}
+	if result.TotalRuns > 1 {
+		fmt.Printf(" [run %d/%d]", result.RunIndex+1, result.TotalRuns)
 	}
 
-	// Print totals
+	// Print assertion count if any
+	if taskAssertionsTotal > 0 {
+		fmt.Printf(" (assertions: %d/%d)", taskAssertionsPassed, taskAssertionsTotal)
+	}
+	if result.State != "" {
+		yellow.Printf(" [%s]", result.State)
+	}
+	if results.XPassed(result) {
+		yellow.Printf(" [XPASS]")
+	} else if results.XFailed(result) {
+		yellow.Printf(" [XFAIL]")
+	}
 	fmt.Println()
+
+	// Print failure details
+	if taskSummary.TaskError != "" {
+		fmt.Printf("      %s\n", taskSummary.TaskError)
+	}
+
+	// Print failed assertions
+	for _, failure := range taskSummary.FailedAssertions {
+		red.Printf("      - %s\n", failure)
+	}
+
+	// Print the size of MCP responses of failed runs, as oversized tool
+	// results are a common cause of failures
+	if !passed && taskSummary.SkipReason == "" && len(taskSummary.McpTraffic) > 0 {
+		var total mcpproxy.CallSizes
+		for _, sizes := range taskSummary.McpTraffic {
+			total.Add(sizes)
+		}
+		fmt.Printf("      MCP responses: %s in %d calls (largest %s)\n",
+			formatBytes(total.ResponseBytes), total.Calls, formatBytes(total.MaxResponseBytes))
+	}
+}
+
+// printSummaryTotals prints the pass rates, token usage and resource usage of a
+// summary. Used by both the summary and tail commands.
+func printSummaryTotals(summary SummaryOutput) {
 	fmt.Printf("Tasks:      %d/%d passed (%.2f%%)\n",
-		summary.TasksPassed, summary.TasksTotal, summary.TaskPassRate*100)
+		summary.TasksPassed, summary.TasksTotal-summary.TasksSkipped, summary.TaskPassRate*100)
 	fmt.Printf("Assertions: %d/%d passed (%.2f%%)\n",
 		summary.AssertionsPassed, summary.AssertionsTotal, summary.AssertionPassRate*100)
+	if summary.TasksQuarantined > 0 {
+		fmt.Printf("Quarantined: %d (excluded from verify thresholds)\n", summary.TasksQuarantined)
+	}
+	if summary.ExpectedFailures.XFailed > 0 {
+		fmt.Printf("XFAIL:      %d (failed as expected, excluded from verify thresholds)\n", summary.ExpectedFailures.XFailed)
+	}
+	if summary.ExpectedFailures.XPassed > 0 {
+		fmt.Printf("XPASS:      %d (passed unexpectedly, remove expectFailure from these tasks)\n", summary.ExpectedFailures.XPassed)
+	}
+	if summary.TasksJudgeErrored > 0 {
+		fmt.Printf("Judge errors: %d (no verdict, counted as not passed)\n", summary.TasksJudgeErrored)
+	}
+	if summary.TasksSkippedOverBudget > 0 {
+		fmt.Printf("Over budget: %d (skipped, counted as not passed)\n", summary.TasksSkippedOverBudget)
+	}
+	if summary.TasksSkipped > 0 {
+		fmt.Printf("Skipped:    %d (excluded from pass rates)\n", summary.TasksSkipped)
+	}
+	if summary.ErrorKinds.Total() > 0 {
+		fmt.Printf("Failures:   %s\n", summary.ErrorKinds)
+	}
+	if len(summary.Environments) > 0 {
+		fmt.Printf("Environments:\n")
+		for _, name := range slices.Sorted(maps.Keys(summary.Environments)) {
+			stats := summary.Environments[name]
+			fmt.Printf("  %s: %d/%d tasks passed (%.2f%%), %d/%d assertions passed\n", name,
+				stats.TasksPassed, stats.TasksTotal-stats.TasksSkipped, stats.TaskPassRate*100,
+				stats.AssertionsPassed, stats.AssertionsTotal)
+		}
+	}
 	// Check if any task had token errors
 	hasTokenErrors := false
 	for _, task := range summary.Tasks {
@@ -251,6 +484,47 @@ func outputTextSummary(evalResults []*eval.EvalResult, summary SummaryOutput) {
 		fmt.Printf("  Input:  %d tokens\n", summary.JudgeTotalInputTokens)
 		fmt.Printf("  Output: %d tokens\n", summary.JudgeTotalOutputTokens)
 	}
+
+	if len(summary.McpTraffic) > 0 {
+		fmt.Printf("MCP traffic:\n")
+		for _, server := range slices.Sorted(maps.Keys(summary.McpTraffic)) {
+			sizes := summary.McpTraffic[server]
+			fmt.Printf("  %s: %d calls, requests %s, responses %s (largest %s)",
+				server, sizes.Calls, formatBytes(sizes.RequestBytes), formatBytes(sizes.ResponseBytes), formatBytes(sizes.MaxResponseBytes))
+			if sizes.RequestTokens > 0 || sizes.ResponseTokens > 0 {
+				fmt.Printf(", ~%d/~%d tokens", sizes.RequestTokens, sizes.ResponseTokens)
+			}
+			fmt.Println()
+		}
+	}
+
+	if usage := summary.AgentResourceUsage; usage != nil {
+		fmt.Printf("Agent resources:\n")
+		fmt.Printf("  Wall time: %s\n", formatMillis(usage.WallTimeMs))
+		fmt.Printf("  CPU time:  %s (user=%s, sys=%s)\n", formatMillis(usage.CPUTimeMs()), formatMillis(usage.UserCPUMs), formatMillis(usage.SystemCPUMs))
+		if usage.MaxRSSBytes > 0 {
+			fmt.Printf("  Peak RSS:  %s\n", formatBytes(usage.MaxRSSBytes))
+		}
+	}
+}
+
+// formatMillis formats a duration in milliseconds, e.g. "1m2.5s".
+func formatMillis(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
+
+// formatBytes formats a byte count using binary units, e.g. "512.0 MiB".
+func formatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
 }
 
 func outputJSONSummary(summary SummaryOutput) error {
@@ -275,18 +549,93 @@ func printTokenSummary(totalTokens, mcpSchemaTokens int64, hasErrors bool) {
 	}
 }
 
-func outputGitHubSummary(summary SummaryOutput) {
-	fmt.Printf("results-file=%s\n", summary.ResultsFile)
-	fmt.Printf("tasks-total=%d\n", summary.TasksTotal)
-	fmt.Printf("tasks-passed=%d\n", summary.TasksPassed)
-	fmt.Printf("task-pass-rate=%.4f\n", summary.TaskPassRate)
-	fmt.Printf("assertions-total=%d\n", summary.AssertionsTotal)
-	fmt.Printf("assertions-passed=%d\n", summary.AssertionsPassed)
-	fmt.Printf("assertion-pass-rate=%.4f\n", summary.AssertionPassRate)
-	fmt.Printf("tokens-estimated=%d\n", summary.TotalTokensEstimate)
-	fmt.Printf("mcp-schema-tokens=%d\n", summary.TotalMcpSchemaTokens)
-	fmt.Printf("agent-input-tokens=%d\n", summary.AgentTotalInputTokens)
-	fmt.Printf("agent-output-tokens=%d\n", summary.AgentTotalOutputTokens)
-	fmt.Printf("judge-input-tokens=%d\n", summary.JudgeTotalInputTokens)
-	fmt.Printf("judge-output-tokens=%d\n", summary.JudgeTotalOutputTokens)
+// ciOutput is a named value of a summary for CI systems, e.g. tasks-total.
+type ciOutput struct {
+	key, value string
+}
+
+// ciOutputs returns the values of summary that are output for CI systems,
+// with the path of the JUnit report written with it, if any.
+func ciOutputs(summary SummaryOutput, junitFile string) []ciOutput {
+	outputs := []ciOutput{
+		{"results-file", summary.ResultsFile},
+		{"tasks-total", strconv.Itoa(summary.TasksTotal)},
+		{"tasks-passed", strconv.Itoa(summary.TasksPassed)},
+		{"tasks-quarantined", strconv.Itoa(summary.TasksQuarantined)},
+		{"tasks-judge-errored", strconv.Itoa(summary.TasksJudgeErrored)},
+		{"tasks-skipped-over-budget", strconv.Itoa(summary.TasksSkippedOverBudget)},
+		{"tasks-skipped", strconv.Itoa(summary.TasksSkipped)},
+		{"tasks-xfailed", strconv.Itoa(summary.ExpectedFailures.XFailed)},
+		{"tasks-xpassed", strconv.Itoa(summary.ExpectedFailures.XPassed)},
+		{"tasks-setup-errored", strconv.Itoa(summary.ErrorKinds.Setup)},
+		{"tasks-agent-errored", strconv.Itoa(summary.ErrorKinds.Agent)},
+		{"tasks-verify-failed", strconv.Itoa(summary.ErrorKinds.Verify)},
+		{"tasks-infra-errored", strconv.Itoa(summary.ErrorKinds.Infra)},
+		{"task-pass-rate", fmt.Sprintf("%.4f", summary.TaskPassRate)},
+		{"assertions-total", strconv.Itoa(summary.AssertionsTotal)},
+		{"assertions-passed", strconv.Itoa(summary.AssertionsPassed)},
+		{"assertion-pass-rate", fmt.Sprintf("%.4f", summary.AssertionPassRate)},
+		{"tokens-estimated", strconv.FormatInt(summary.TotalTokensEstimate, 10)},
+		{"mcp-schema-tokens", strconv.FormatInt(summary.TotalMcpSchemaTokens, 10)},
+		{"agent-input-tokens", strconv.FormatInt(summary.AgentTotalInputTokens, 10)},
+		{"agent-output-tokens", strconv.FormatInt(summary.AgentTotalOutputTokens, 10)},
+		{"judge-input-tokens", strconv.FormatInt(summary.JudgeTotalInputTokens, 10)},
+		{"judge-output-tokens", strconv.FormatInt(summary.JudgeTotalOutputTokens, 10)},
+	}
+
+	var usage util.ResourceUsage
+	usage.Add(summary.AgentResourceUsage)
+	outputs = append(outputs,
+		ciOutput{"agent-wall-time-ms", strconv.FormatInt(usage.WallTimeMs, 10)},
+		ciOutput{"agent-cpu-time-ms", strconv.FormatInt(usage.CPUTimeMs(), 10)},
+		ciOutput{"agent-peak-rss-bytes", strconv.FormatInt(usage.MaxRSSBytes, 10)},
+	)
+
+	var traffic mcpproxy.CallSizes
+	for _, sizes := range summary.McpTraffic {
+		traffic.Add(sizes)
+	}
+	outputs = append(outputs,
+		ciOutput{"mcp-request-bytes", strconv.FormatInt(traffic.RequestBytes, 10)},
+		ciOutput{"mcp-response-bytes", strconv.FormatInt(traffic.ResponseBytes, 10)},
+		ciOutput{"mcp-max-response-bytes", strconv.FormatInt(traffic.MaxResponseBytes, 10)},
+	)
+
+	if junitFile != "" {
+		outputs = append(outputs, ciOutput{"junit-report", junitFile})
+	}
+	return outputs
+}
+
+// outputGitHubSummary prints the summary as GitHub Actions step outputs, to
+// append to $GITHUB_OUTPUT.
+func outputGitHubSummary(summary SummaryOutput, junitFile string) {
+	for _, o := range ciOutputs(summary, junitFile) {
+		fmt.Printf("%s=%s\n", o.key, o.value)
+	}
+}
+
+// outputGitLabSummary prints the summary as a GitLab CI dotenv report, whose
+// variables are named like MCPCHECKER_TASKS_TOTAL.
+func outputGitLabSummary(summary SummaryOutput, junitFile string) {
+	for _, o := range ciOutputs(summary, junitFile) {
+		key := "MCPCHECKER_" + strings.ToUpper(strings.ReplaceAll(o.key, "-", "_"))
+		// dotenv values can't span lines
+		fmt.Printf("%s=%s\n", key, strings.ReplaceAll(o.value, "\n", " "))
+	}
+}
+
+// outputJenkinsSummary prints the summary as a Java properties file, to read
+// with readProperties in a Jenkins pipeline. The summary property is a one
+// line description of the run, e.g. for currentBuild.description.
+func outputJenkinsSummary(summary SummaryOutput, junitFile string) {
+	description := fmt.Sprintf("%d/%d tasks passed (%.2f%%), %d/%d assertions passed (%.2f%%)",
+		summary.TasksPassed, summary.TasksTotal-summary.TasksSkipped, summary.TaskPassRate*100,
+		summary.AssertionsPassed, summary.AssertionsTotal, summary.AssertionPassRate*100)
+	outputs := append(ciOutputs(summary, junitFile), ciOutput{"summary", description})
+
+	escape := strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+	for _, o := range outputs {
+		fmt.Printf("%s=%s\n", o.key, escape.Replace(o.value))
+	}
 }
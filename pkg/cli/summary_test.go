@@ -3,11 +3,16 @@ package cli
 import (
 	"bytes"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
 	"github.com/mcpchecker/mcpchecker/pkg/tokens"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 func TestSummaryCommand(t *testing.T) {
@@ -74,6 +79,39 @@ func TestSummaryCommandGitHubOutput(t *testing.T) {
 	}
 }
 
+func TestSummaryCommandJUnitFile(t *testing.T) {
+	filePath := createTestResultsFile(t, sampleResults())
+	junitFile := filepath.Join(t.TempDir(), "junit.xml")
+
+	cmd := NewSummaryCmd()
+	cmd.SetArgs([]string{filePath, "--gitlab-output", "--junit-file", junitFile})
+	cmd.SetOut(new(bytes.Buffer))
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("summary command with --junit-file failed: %v", err)
+	}
+	data, err := os.ReadFile(junitFile)
+	if err != nil {
+		t.Fatalf("failed to read JUnit report: %v", err)
+	}
+	if !strings.Contains(string(data), "<testsuites>") {
+		t.Errorf("expected a JUnit report, got:\n%s", data)
+	}
+}
+
+func TestSummaryCommandCIOutputsAreExclusive(t *testing.T) {
+	filePath := createTestResultsFile(t, sampleResults())
+
+	cmd := NewSummaryCmd()
+	cmd.SetArgs([]string{filePath, "--github-output", "--gitlab-output"})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "none of the others can be") {
+		t.Errorf("expected the CI outputs to be mutually exclusive, got %v", err)
+	}
+}
+
 func TestSummaryCommandEmptyResults(t *testing.T) {
 	filePath := createTestResultsFile(t, []*eval.EvalResult{})
 
@@ -190,6 +228,146 @@ func TestOutputTextSummaryAgentExecutionError(t *testing.T) {
 	outputTextSummary(results, summary)
 }
 
+func TestBuildSummaryOutputJudgeErrors(t *testing.T) {
+	results := []*eval.EvalResult{
+		{TaskName: "passed", TaskPassed: true, AllAssertionsPassed: true},
+		{TaskName: "failed", TaskError: "one or more verification steps failed", AllAssertionsPassed: true},
+		{TaskName: "judge-error", JudgeError: true, TaskJudgeError: "429 too many requests", AllAssertionsPassed: true},
+	}
+
+	summary := buildSummaryOutput("test.json", results)
+
+	if summary.TasksPassed != 1 {
+		t.Errorf("TasksPassed = %d, want 1", summary.TasksPassed)
+	}
+
+	if summary.TasksJudgeErrored != 1 {
+		t.Errorf("TasksJudgeErrored = %d, want 1", summary.TasksJudgeErrored)
+	}
+
+	if summary.Tasks[1].JudgeError {
+		t.Error("failed task should not be marked as a judge error")
+	}
+
+	if !summary.Tasks[2].JudgeError {
+		t.Error("judge error task should be marked as a judge error")
+	}
+
+	if want := "Judge error: 429 too many requests"; summary.Tasks[2].TaskError != want {
+		t.Errorf("TaskError = %q, want %q", summary.Tasks[2].TaskError, want)
+	}
+}
+
+func TestBuildSummaryOutputEnvironments(t *testing.T) {
+	results := []*eval.EvalResult{
+		{TaskName: "task-1", Environment: "dev", TaskPassed: true, AllAssertionsPassed: true},
+		{TaskName: "task-1", Environment: "prod", TaskError: "verification failed"},
+	}
+
+	summary := buildSummaryOutput("test.json", results)
+
+	if summary.Tasks[1].Environment != "prod" {
+		t.Errorf("Tasks[1].Environment = %q, want prod", summary.Tasks[1].Environment)
+	}
+	if len(summary.Environments) != 2 {
+		t.Fatalf("len(Environments) = %d, want 2", len(summary.Environments))
+	}
+	if dev := summary.Environments["dev"]; dev.TasksPassed != 1 || dev.TasksTotal != 1 {
+		t.Errorf("Environments[dev] = %+v, want 1/1 passed", dev)
+	}
+	if prod := summary.Environments["prod"]; prod.TasksPassed != 0 || prod.TasksTotal != 1 {
+		t.Errorf("Environments[prod] = %+v, want 0/1 passed", prod)
+	}
+
+	if summary := buildSummaryOutput("test.json", sampleResults()); summary.Environments != nil {
+		t.Errorf("Environments = %v, want nil without environments", summary.Environments)
+	}
+}
+
+func TestBuildSummaryOutputAttempts(t *testing.T) {
+	results := []*eval.EvalResult{
+		{TaskName: "task-1", TaskPath: "task-1.yaml", TaskPassed: true, AllAssertionsPassed: true, RunIndex: 0, TotalRuns: 2},
+		{TaskName: "task-1", TaskPath: "task-1.yaml", RunIndex: 1, TotalRuns: 2},
+	}
+
+	summary := buildSummaryOutput("test.json", results)
+	if summary.Attempts == nil {
+		t.Fatal("Attempts = nil, want the attempts of task-1")
+	}
+	if summary.Attempts.K != 2 || summary.Attempts.PassAt1 != 0.5 || summary.Attempts.PassAtK != 1 || summary.Attempts.FlakyTasks != 1 {
+		t.Errorf("Attempts = %+v, want pass@1 0.5, pass@2 1 and 1 flaky task", summary.Attempts)
+	}
+
+	if summary := buildSummaryOutput("test.json", sampleResults()); summary.Attempts != nil {
+		t.Errorf("Attempts = %+v, want nil without repeated tasks", summary.Attempts)
+	}
+}
+
+func TestBuildSummaryOutputErrorKinds(t *testing.T) {
+	results := []*eval.EvalResult{
+		{TaskName: "passed", TaskPassed: true, AllAssertionsPassed: true},
+		{TaskName: "setup-failed", TaskError: "setup[0] failed", ErrorKind: task.ErrorKindSetup},
+		{TaskName: "agent-failed", TaskError: "failed to run agent", ErrorKind: task.ErrorKindAgent, AgentExecutionError: true},
+		{TaskName: "judge-error", JudgeError: true, ErrorKind: task.ErrorKindInfra},
+	}
+
+	summary := buildSummaryOutput("test.json", results)
+
+	if summary.ErrorKinds.Setup != 1 || summary.ErrorKinds.Agent != 1 || summary.ErrorKinds.Verify != 0 || summary.ErrorKinds.Infra != 1 {
+		t.Errorf("ErrorKinds = %+v, want setup=1, agent=1, infra=1", summary.ErrorKinds)
+	}
+	if summary.Tasks[0].ErrorKind != "" {
+		t.Errorf("passed task ErrorKind = %q, want empty", summary.Tasks[0].ErrorKind)
+	}
+	if summary.Tasks[1].ErrorKind != task.ErrorKindSetup {
+		t.Errorf("Tasks[1].ErrorKind = %q, want %q", summary.Tasks[1].ErrorKind, task.ErrorKindSetup)
+	}
+}
+
+func TestBuildSummaryOutputSkipped(t *testing.T) {
+	results := []*eval.EvalResult{
+		{TaskName: "passed", TaskPassed: true, AllAssertionsPassed: true},
+		{TaskName: "requirements", Skipped: true, SkipReason: eval.SkipReasonRequirementsUnmet, TaskError: "skipped: missing MCP server \"github\""},
+		{TaskName: "budget", Skipped: true, SkipReason: eval.SkipReasonBudgetExceeded, SkippedOverBudget: true},
+	}
+
+	summary := buildSummaryOutput("test.json", results)
+
+	if summary.TasksSkipped != 1 {
+		t.Errorf("TasksSkipped = %d, want 1", summary.TasksSkipped)
+	}
+	if summary.TasksSkippedOverBudget != 1 {
+		t.Errorf("TasksSkippedOverBudget = %d, want 1", summary.TasksSkippedOverBudget)
+	}
+	// The run skipped over budget counts as not passed, the other one is left out
+	if want := 0.5; summary.TaskPassRate != want {
+		t.Errorf("TaskPassRate = %f, want %f", summary.TaskPassRate, want)
+	}
+	if summary.Tasks[1].SkipReason != eval.SkipReasonRequirementsUnmet {
+		t.Errorf("Tasks[1].SkipReason = %q, want %q", summary.Tasks[1].SkipReason, eval.SkipReasonRequirementsUnmet)
+	}
+	if summary.Tasks[1].ErrorKind != "" {
+		t.Errorf("skipped task ErrorKind = %q, want empty", summary.Tasks[1].ErrorKind)
+	}
+}
+
+func TestBuildSummaryOutputExpectedFailures(t *testing.T) {
+	results := []*eval.EvalResult{
+		{TaskName: "passed", TaskPassed: true, AllAssertionsPassed: true},
+		{TaskName: "xfailed", ExpectFailure: true, TaskError: "verification failed"},
+		{TaskName: "xpassed", ExpectFailure: true, TaskPassed: true, AllAssertionsPassed: true},
+	}
+
+	summary := buildSummaryOutput("test.json", results)
+
+	if summary.ExpectedFailures.XFailed != 1 || summary.ExpectedFailures.XPassed != 1 {
+		t.Errorf("ExpectedFailures = %+v, want 1 XFAIL and 1 XPASS", summary.ExpectedFailures)
+	}
+	if !summary.Tasks[1].ExpectFailure || summary.Tasks[0].ExpectFailure {
+		t.Errorf("ExpectFailure should only be set on the tasks expected to fail")
+	}
+}
+
 func TestBuildSummaryOutputWithTokenUsage(t *testing.T) {
 	results := []*eval.EvalResult{
 		{
@@ -253,6 +431,111 @@ func TestBuildSummaryOutputWithTokenUsage(t *testing.T) {
 	}
 }
 
+func TestBuildSummaryOutputWithResourceUsage(t *testing.T) {
+	results := []*eval.EvalResult{
+		{
+			TaskName:      "task-1",
+			ResourceUsage: &util.ResourceUsage{WallTimeMs: 1000, UserCPUMs: 300, SystemCPUMs: 100, MaxRSSBytes: 200 << 20},
+		},
+		{
+			TaskName: "task-2",
+		},
+		{
+			TaskName:      "task-3",
+			ResourceUsage: &util.ResourceUsage{WallTimeMs: 2500, UserCPUMs: 700, SystemCPUMs: 50, MaxRSSBytes: 100 << 20},
+		},
+	}
+
+	summary := buildSummaryOutput("test.json", results)
+
+	expected := &util.ResourceUsage{WallTimeMs: 3500, UserCPUMs: 1000, SystemCPUMs: 150, MaxRSSBytes: 200 << 20}
+	if *summary.AgentResourceUsage != *expected {
+		t.Errorf("AgentResourceUsage = %+v, want %+v", *summary.AgentResourceUsage, *expected)
+	}
+	if summary.Tasks[1].ResourceUsage != nil {
+		t.Errorf("Tasks[1].ResourceUsage = %+v, want nil", summary.Tasks[1].ResourceUsage)
+	}
+	if summary.Tasks[2].ResourceUsage.WallTimeMs != 2500 {
+		t.Errorf("Tasks[2].ResourceUsage.WallTimeMs = %d, want 2500", summary.Tasks[2].ResourceUsage.WallTimeMs)
+	}
+
+	// Results without resource usage don't get a summary entry
+	if got := buildSummaryOutput("test.json", results[1:2]).AgentResourceUsage; got != nil {
+		t.Errorf("AgentResourceUsage = %+v, want nil", got)
+	}
+}
+
+func TestBuildSummaryOutputWithMcpTraffic(t *testing.T) {
+	toolCall := func(server string, requestBytes, responseBytes int64) *mcpproxy.ToolCall {
+		return &mcpproxy.ToolCall{
+			CallRecord: mcpproxy.CallRecord{ServerName: server, RequestBytes: requestBytes, ResponseBytes: responseBytes},
+			Tokens:     mcpproxy.NewTokenCount(requestBytes/4, responseBytes/4),
+		}
+	}
+
+	results := []*eval.EvalResult{
+		{
+			TaskName: "task-1",
+			CallHistory: &mcpproxy.CallHistory{
+				ToolCalls: []*mcpproxy.ToolCall{toolCall("kube", 40, 400), toolCall("kube", 80, 4000)},
+				ResourceReads: []*mcpproxy.ResourceRead{
+					{CallRecord: mcpproxy.CallRecord{ServerName: "docs", RequestBytes: 20, ResponseBytes: 100}},
+				},
+			},
+		},
+		{
+			TaskName: "task-2",
+		},
+		{
+			TaskName: "task-3",
+			CallHistory: &mcpproxy.CallHistory{
+				ToolCalls: []*mcpproxy.ToolCall{toolCall("kube", 40, 800)},
+			},
+		},
+	}
+
+	summary := buildSummaryOutput("test.json", results)
+
+	expectedKube := mcpproxy.CallSizes{Calls: 3, RequestBytes: 160, ResponseBytes: 5200, RequestTokens: 40, ResponseTokens: 1300, MaxResponseBytes: 4000}
+	if got := summary.McpTraffic["kube"]; got == nil || *got != expectedKube {
+		t.Errorf("McpTraffic[kube] = %+v, want %+v", got, expectedKube)
+	}
+	expectedDocs := mcpproxy.CallSizes{Calls: 1, RequestBytes: 20, ResponseBytes: 100, MaxResponseBytes: 100}
+	if got := summary.McpTraffic["docs"]; got == nil || *got != expectedDocs {
+		t.Errorf("McpTraffic[docs] = %+v, want %+v", got, expectedDocs)
+	}
+
+	if got := summary.Tasks[0].McpTraffic["kube"]; got == nil || got.ResponseBytes != 4400 {
+		t.Errorf("Tasks[0].McpTraffic[kube] = %+v, want 4400 response bytes", got)
+	}
+	if summary.Tasks[1].McpTraffic != nil {
+		t.Errorf("Tasks[1].McpTraffic = %+v, want nil", summary.Tasks[1].McpTraffic)
+	}
+	if got := summary.Tasks[2].McpTraffic["kube"]; got == nil || got.Calls != 1 {
+		t.Errorf("Tasks[2].McpTraffic[kube] = %+v, want 1 call", got)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[string]struct {
+		bytes    int64
+		expected string
+	}{
+		"bytes":     {bytes: 512, expected: "512 B"},
+		"kibibytes": {bytes: 1536, expected: "1.5 KiB"},
+		"mebibytes": {bytes: 200 << 20, expected: "200.0 MiB"},
+		"gibibytes": {bytes: 3 << 30, expected: "3.0 GiB"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := formatBytes(tc.bytes); got != tc.expected {
+				t.Errorf("formatBytes(%d) = %q, want %q", tc.bytes, got, tc.expected)
+			}
+		})
+	}
+}
+
 func TestOutputGitHubSummaryContent(t *testing.T) {
 	results := []*eval.EvalResult{
 		{
@@ -273,6 +556,12 @@ func TestOutputGitHubSummaryContent(t *testing.T) {
 				InputTokens:  200,
 				OutputTokens: 100,
 			},
+			ResourceUsage: &util.ResourceUsage{WallTimeMs: 1000, UserCPUMs: 300, SystemCPUMs: 100},
+			CallHistory: &mcpproxy.CallHistory{
+				ToolCalls: []*mcpproxy.ToolCall{
+					{CallRecord: mcpproxy.CallRecord{ServerName: "kube", RequestBytes: 50, ResponseBytes: 700}},
+				},
+			},
 		},
 	}
 
@@ -283,7 +572,7 @@ func TestOutputGitHubSummaryContent(t *testing.T) {
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 
-	outputGitHubSummary(summary)
+	outputGitHubSummary(summary, "")
 
 	w.Close()
 	os.Stdout = oldStdout
@@ -305,6 +594,12 @@ func TestOutputGitHubSummaryContent(t *testing.T) {
 		"agent-output-tokens=400",
 		"judge-input-tokens=200",
 		"judge-output-tokens=100",
+		"agent-wall-time-ms=1000",
+		"agent-cpu-time-ms=400",
+		"agent-peak-rss-bytes=0",
+		"mcp-request-bytes=50",
+		"mcp-response-bytes=700",
+		"mcp-max-response-bytes=700",
 	}
 
 	for _, expected := range expectedLines {
@@ -313,3 +608,61 @@ func TestOutputGitHubSummaryContent(t *testing.T) {
 		}
 	}
 }
+
+func TestOutputCISummaries(t *testing.T) {
+	summary := buildSummaryOutput(`C:\results\test.json`, []*eval.EvalResult{
+		{TaskName: "a", TaskPassed: true, AllAssertionsPassed: true},
+		{TaskName: "b", TaskError: "agent crashed", ErrorKind: task.ErrorKindAgent},
+	})
+
+	tests := map[string]struct {
+		output    func(SummaryOutput, string)
+		wantLines []string
+	}{
+		"gitlab": {
+			output: outputGitLabSummary,
+			wantLines: []string{
+				`MCPCHECKER_RESULTS_FILE=C:\results\test.json`,
+				"MCPCHECKER_TASKS_TOTAL=2",
+				"MCPCHECKER_TASKS_PASSED=1",
+				"MCPCHECKER_TASKS_AGENT_ERRORED=1",
+				"MCPCHECKER_TASK_PASS_RATE=0.5000",
+				"MCPCHECKER_MCP_MAX_RESPONSE_BYTES=0",
+				"MCPCHECKER_JUNIT_REPORT=junit.xml",
+			},
+		},
+		"jenkins": {
+			output: outputJenkinsSummary,
+			wantLines: []string{
+				`results-file=C:\\results\\test.json`,
+				"tasks-total=2",
+				"task-pass-rate=0.5000",
+				"junit-report=junit.xml",
+				"summary=1/2 tasks passed (50.00%), 0/0 assertions passed (0.00%)",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			oldStdout := os.Stdout
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			tc.output(summary, "junit.xml")
+
+			w.Close()
+			os.Stdout = oldStdout
+
+			var buf bytes.Buffer
+			buf.ReadFrom(r)
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+			for _, want := range tc.wantLines {
+				if !slices.Contains(lines, want) {
+					t.Errorf("output is missing line %q\nGot:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/spf13/cobra"
+)
+
+// journalSuffix is the file name suffix of results journals written by check
+const journalSuffix = "-journal.ndjson"
+
+func NewTailCmd() *cobra.Command {
+	var noFollow bool
+	var interval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "tail <journal-file|results-dir>",
+		Short: "Follow the results journal of an in-progress run",
+		Long: `Follow the results journal written by 'mcpchecker check' and print each task
+result as it completes, using the same formatting as 'mcpchecker result summary'.
+
+The argument is either a journal file or a directory, in which case the most
+recently modified *-journal.ndjson file in it is followed. If the journal does
+not exist yet, tail waits for it to be created.
+
+Totals are printed once the run completes, or when tail is interrupted.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			return tailJournal(ctx, args[0], !noFollow, interval)
+		},
+	}
+
+	cmd.Flags().BoolVar(&noFollow, "no-follow", false, "Print the results journaled so far and exit")
+	cmd.Flags().DurationVar(&interval, "interval", time.Second, "How often to check the journal for new results")
+
+	return cmd
+}
+
+// tailJournal prints the results in the journal at target as they are written.
+// Without follow, it prints the results written so far and returns.
+func tailJournal(ctx context.Context, target string, follow bool, interval time.Duration) error {
+	tail := &journalTail{target: target}
+	var evalResults []*eval.EvalResult
+
+	printTotals := func(complete bool) {
+		fmt.Println()
+		if !complete {
+			color.New(color.FgYellow).Printf("Run in progress: %d results so far\n", len(evalResults))
+		}
+		printSummaryTotals(buildSummaryOutput(tail.path, evalResults))
+	}
+
+	for {
+		entries, err := tail.poll()
+		if err != nil {
+			return err
+		}
+		if !follow && !tail.opened {
+			return fmt.Errorf("no results journal found at %s", target)
+		}
+
+		for _, entry := range entries {
+			switch entry.Type {
+			case eval.JournalStart:
+				if len(evalResults) > 0 {
+					fmt.Println()
+					color.New(color.FgYellow).Println("Journal was restarted by a new run")
+				}
+				evalResults = nil
+				color.New(color.Bold).Println("=== Evaluation Summary ===")
+				fmt.Println()
+			case eval.JournalResult:
+				if entry.Result == nil {
+					continue
+				}
+				evalResults = append(evalResults, entry.Result)
+				summary := buildSummaryOutput(tail.path, []*eval.EvalResult{entry.Result})
+				printTaskSummary(entry.Result, summary.Tasks[0])
+			case eval.JournalComplete:
+				printTotals(true)
+				return nil
+			}
+		}
+
+		if !follow {
+			printTotals(false)
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			printTotals(false)
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// journalTail reads the entries appended to a journal since the last poll.
+type journalTail struct {
+	// target is the journal file or the directory to look for one in
+	target string
+	// path is the journal file being followed, empty until one is found
+	path    string
+	opened  bool
+	offset  int64
+	pending []byte
+}
+
+// poll returns the complete entries appended since the last call. It returns no
+// entries while the journal does not exist yet. If the journal was truncated by
+// a new run, it is read again from the start.
+func (t *journalTail) poll() ([]eval.JournalEntry, error) {
+	if t.path == "" {
+		path, err := findJournal(t.target)
+		if err != nil || path == "" {
+			return nil, err
+		}
+		t.path = path
+	}
+
+	f, err := os.Open(t.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+	t.opened = true
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat journal: %w", err)
+	}
+	if info.Size() < t.offset {
+		t.offset = 0
+		t.pending = nil
+	}
+
+	if _, err := f.Seek(t.offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek journal: %w", err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	t.offset += int64(len(data))
+	t.pending = append(t.pending, data...)
+
+	// Only parse complete lines; the last one may still be being written
+	end := bytes.LastIndexByte(t.pending, '\n')
+	if end < 0 {
+		return nil, nil
+	}
+	lines := t.pending[:end+1]
+	t.pending = append([]byte(nil), t.pending[end+1:]...)
+
+	var entries []eval.JournalEntry
+	for _, line := range bytes.Split(lines, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var entry eval.JournalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse journal entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// findJournal returns target if it is not a directory, or else the most recently
+// modified journal in it. It returns an empty path if the directory has none.
+func findJournal(target string) (string, error) {
+	info, err := os.Stat(target)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && !info.IsDir()) {
+		return target, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %w", target, err)
+	}
+
+	dirEntries, err := os.ReadDir(target)
+	if err != nil {
+		return "", fmt.Errorf("failed to read directory %s: %w", target, err)
+	}
+
+	var newest string
+	var newestTime time.Time
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), journalSuffix) {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		if newest == "" || info.ModTime().After(newestTime) {
+			newest = filepath.Join(target, dirEntry.Name())
+			newestTime = info.ModTime()
+		}
+	}
+	return newest, nil
+}
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+func writeJournalLines(t *testing.T, path string, entries ...eval.JournalEntry) {
+	t.Helper()
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	defer f.Close()
+
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			t.Fatalf("failed to marshal entry: %v", err)
+		}
+		if _, err := f.Write(append(data, '\n')); err != nil {
+			t.Fatalf("failed to write entry: %v", err)
+		}
+	}
+}
+
+func TestJournalTailPoll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcpchecker-test-journal.ndjson")
+	tail := &journalTail{target: path}
+
+	// The journal does not exist yet
+	entries, err := tail.poll()
+	if err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	if len(entries) != 0 || tail.opened {
+		t.Fatalf("expected no entries before the journal exists, got %d", len(entries))
+	}
+
+	writeJournalLines(t, path,
+		eval.JournalEntry{Type: eval.JournalStart, Summary: &eval.EvalSummary{}},
+		eval.JournalEntry{Type: eval.JournalResult, Result: &eval.EvalResult{TaskName: "task-1"}},
+	)
+
+	// A partially written line is held back until it is complete
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	f.WriteString(`{"type":"result","result":{"taskName":`)
+
+	entries, err = tail.poll()
+	if err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	f.WriteString("\"task-2\"}}\n")
+	f.Close()
+
+	entries, err = tail.poll()
+	if err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Result == nil || entries[0].Result.TaskName != "task-2" {
+		t.Fatalf("expected the completed task-2 entry, got %+v", entries)
+	}
+
+	// A new run truncates the journal, which is then read from the start
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatalf("failed to truncate journal: %v", err)
+	}
+	writeJournalLines(t, path, eval.JournalEntry{Type: eval.JournalStart})
+
+	entries, err = tail.poll()
+	if err != nil {
+		t.Fatalf("poll failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Type != eval.JournalStart {
+		t.Fatalf("expected the start entry of the new run, got %+v", entries)
+	}
+}
+
+func TestFindJournal(t *testing.T) {
+	dir := t.TempDir()
+
+	path, err := findJournal(dir)
+	if err != nil {
+		t.Fatalf("findJournal failed: %v", err)
+	}
+	if path != "" {
+		t.Errorf("expected no journal in empty dir, got %q", path)
+	}
+
+	older := filepath.Join(dir, "mcpchecker-a-journal.ndjson")
+	newer := filepath.Join(dir, "mcpchecker-b-journal.ndjson")
+	for _, name := range []string{older, newer, filepath.Join(dir, "mcpchecker-c-out.json")} {
+		if err := os.WriteFile(name, nil, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(older, past, past); err != nil {
+		t.Fatalf("failed to set mtime: %v", err)
+	}
+
+	path, err = findJournal(dir)
+	if err != nil {
+		t.Fatalf("findJournal failed: %v", err)
+	}
+	if path != newer {
+		t.Errorf("findJournal() = %q, want %q", path, newer)
+	}
+
+	// A file path is used as is
+	path, err = findJournal(older)
+	if err != nil {
+		t.Fatalf("findJournal failed: %v", err)
+	}
+	if path != older {
+		t.Errorf("findJournal() = %q, want %q", path, older)
+	}
+}
+
+func TestTailCommandNoFollow(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mcpchecker-test-journal.ndjson")
+	writeJournalLines(t, path,
+		eval.JournalEntry{Type: eval.JournalStart, Summary: &eval.EvalSummary{}},
+		eval.JournalEntry{Type: eval.JournalResult, Result: &eval.EvalResult{TaskName: "task-1", TaskPassed: true}},
+	)
+
+	cmd := NewTailCmd()
+	cmd.SetArgs([]string{dir, "--no-follow"})
+	cmd.SetOut(new(bytes.Buffer))
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("tail command failed: %v", err)
+	}
+}
+
+func TestTailCommandFollowUntilComplete(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mcpchecker-test-journal.ndjson")
+
+	// Write the journal elsewhere and move it in place once tail is waiting for it
+	staged := filepath.Join(dir, "staged")
+	writeJournalLines(t, staged,
+		eval.JournalEntry{Type: eval.JournalStart, Summary: &eval.EvalSummary{}},
+		eval.JournalEntry{Type: eval.JournalResult, Result: &eval.EvalResult{TaskName: "task-1"}},
+		eval.JournalEntry{Type: eval.JournalComplete},
+	)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		os.Rename(staged, path)
+	}()
+
+	cmd := NewTailCmd()
+	cmd.SetArgs([]string{path, "--interval", "5ms"})
+	cmd.SetOut(new(bytes.Buffer))
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Execute() }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("tail command failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("tail did not stop after the run completed")
+	}
+}
+
+func TestTailCommandMissingJournal(t *testing.T) {
+	cmd := NewTailCmd()
+	cmd.SetArgs([]string{filepath.Join(t.TempDir(), "missing.ndjson"), "--no-follow"})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error for missing journal")
+	}
+}
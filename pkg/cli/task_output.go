@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// parallelOutputMode is how the output of tasks running in parallel is kept
+// apart
+type parallelOutputMode string
+
+const (
+	// parallelOutputGrouped buffers the output of each task and shows it
+	// together when the task completes, like go test -v -p N
+	parallelOutputGrouped parallelOutputMode = "grouped"
+
+	// parallelOutputStream shows the output of tasks as it comes, each line
+	// prefixed with the task name
+	parallelOutputStream parallelOutputMode = "stream"
+)
+
+func parseParallelOutput(s string) (parallelOutputMode, error) {
+	switch mode := parallelOutputMode(s); mode {
+	case parallelOutputGrouped, parallelOutputStream:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid --parallel-output %q: must be %q or %q", s, parallelOutputGrouped, parallelOutputStream)
+	}
+}
+
+// taskOutputGroup is the buffered output of a task run
+type taskOutputGroup struct {
+	task *eval.EvalResult
+	buf  bytes.Buffer
+}
+
+// taskWriter returns the writer for the progress output of task. d.mu must
+// be held while writing to it.
+func (d *progressDisplay) taskWriter(task *eval.EvalResult) io.Writer {
+	if task == nil || !task.Parallel || d.parallelOutput != parallelOutputGrouped {
+		return d.out
+	}
+	for _, g := range d.groups {
+		if g.task == task {
+			return &g.buf
+		}
+	}
+	g := &taskOutputGroup{task: task}
+	d.groups = append(d.groups, g)
+	return &g.buf
+}
+
+// flush writes the buffered output of task, if any. d.mu must be held.
+func (d *progressDisplay) flush(task *eval.EvalResult) {
+	for i, g := range d.groups {
+		if g.task == task {
+			_, _ = g.buf.WriteTo(d.out)
+			d.groups = append(d.groups[:i], d.groups[i+1:]...)
+			return
+		}
+	}
+}
+
+// flushAll writes the buffered output of all tasks, in the order they
+// started. d.mu must be held.
+func (d *progressDisplay) flushAll() {
+	for _, g := range d.groups {
+		_, _ = g.buf.WriteTo(d.out)
+	}
+	d.groups = nil
+}
+
+// taskOutput returns the writer for the verbose output that the runner
+// writes during task, as eval.RunnerOptions.TaskOutput.
+func (d *progressDisplay) taskOutput(task *eval.EvalResult) io.Writer {
+	w := &taskOutputWriter{display: d, task: task, lineStart: true}
+	if task.Parallel && d.parallelOutput == parallelOutputStream {
+		w.prefix = []byte(taskPrefix(task))
+	}
+	return w
+}
+
+// taskOutputWriter writes the output of a task through the progress display,
+// so that it is kept apart from the output of other tasks
+type taskOutputWriter struct {
+	display   *progressDisplay
+	task      *eval.EvalResult
+	prefix    []byte
+	lineStart bool
+}
+
+func (w *taskOutputWriter) Write(p []byte) (int, error) {
+	w.display.mu.Lock()
+	defer w.display.mu.Unlock()
+
+	out := w.display.taskWriter(w.task)
+	if len(w.prefix) == 0 {
+		return out.Write(p)
+	}
+
+	// Prefix each line with the task name
+	var buf bytes.Buffer
+	for rest := p; len(rest) > 0; {
+		if w.lineStart {
+			buf.Write(w.prefix)
+		}
+		line := rest
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			line = rest[:i+1]
+		}
+		buf.Write(line)
+		rest = rest[len(line):]
+		w.lineStart = line[len(line)-1] == '\n'
+	}
+	if _, err := out.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
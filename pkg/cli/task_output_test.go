@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// runInterleaved sends the progress events and verbose output of two
+// parallel tasks, interleaving them as concurrent tasks would.
+func runInterleaved(d *progressDisplay) {
+	a := &eval.EvalResult{TaskName: "task-a", Parallel: true}
+	b := &eval.EvalResult{TaskName: "task-b", Parallel: true}
+	outA, outB := d.taskOutput(a), d.taskOutput(b)
+
+	d.handleProgress(eval.ProgressEvent{Type: eval.EventTaskStart, Task: a})
+	d.handleProgress(eval.ProgressEvent{Type: eval.EventTaskStart, Task: b})
+	d.handleProgress(eval.ProgressEvent{Type: eval.EventTaskRunning, Task: a})
+	fmt.Fprintf(outA, "  → Agent 'a' is working…\n")
+	d.handleProgress(eval.ProgressEvent{Type: eval.EventTaskRunning, Task: b})
+	fmt.Fprintf(outB, "  → Agent ")
+	fmt.Fprintf(outB, "'b' is working…\n")
+
+	b.TaskPassed, b.AllAssertionsPassed = true, true
+	d.handleProgress(eval.ProgressEvent{Type: eval.EventTaskComplete, Task: b})
+	a.TaskError = "boom"
+	d.handleProgress(eval.ProgressEvent{Type: eval.EventTaskError, Task: a})
+}
+
+func TestProgressDisplayGroupedOutput(t *testing.T) {
+	var out bytes.Buffer
+	d := newProgressDisplay(true, parallelOutputGrouped)
+	d.out = &out
+
+	runInterleaved(d)
+
+	want := `
+[task-b] Starting (parallel)
+[task-b] → Running agent...
+  → Agent 'b' is working…
+[task-b] ✓ Task passed
+
+[task-a] Starting (parallel)
+[task-a] → Running agent...
+  → Agent 'a' is working…
+[task-a] ✗ Task failed during setup
+[task-a]   Error: boom
+`
+	if got := out.String(); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+	if len(d.groups) != 0 {
+		t.Errorf("%d task groups left after the tasks completed", len(d.groups))
+	}
+}
+
+func TestProgressDisplayStreamOutput(t *testing.T) {
+	var out bytes.Buffer
+	d := newProgressDisplay(true, parallelOutputStream)
+	d.out = &out
+
+	runInterleaved(d)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	want := []string{
+		"[task-a] Starting (parallel)",
+		"",
+		"[task-b] Starting (parallel)",
+		"[task-a] → Running agent...",
+		"[task-a]   → Agent 'a' is working…",
+		"[task-b] → Running agent...",
+		"[task-b]   → Agent 'b' is working…",
+		"[task-b] ✓ Task passed",
+		"[task-a] ✗ Task failed during setup",
+		"[task-a]   Error: boom",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("output =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestParseParallelOutput(t *testing.T) {
+	tests := map[string]struct {
+		value   string
+		want    parallelOutputMode
+		wantErr bool
+	}{
+		"grouped": {value: "grouped", want: parallelOutputGrouped},
+		"stream":  {value: "stream", want: parallelOutputStream},
+		"invalid": {value: "interleaved", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := parseParallelOutput(tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseParallelOutput(%q) error = %v, wantErr %v", tc.value, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("parseParallelOutput(%q) = %q, want %q", tc.value, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDiffCells bounds the size of the LCS table used by unifiedDiff. Larger inputs are
+// rendered as a full replacement rather than a minimal diff.
+const maxDiffCells = 4_000_000
+
+// diffOp is a single line in a line-based diff.
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	text string
+}
+
+// unifiedDiff returns a unified diff between expected and actual, showing context
+// unchanged lines around each change. It returns an empty string when the inputs match.
+func unifiedDiff(expected, actual string, context int) string {
+	if expected == actual {
+		return ""
+	}
+
+	a := splitDiffLines(expected)
+	b := splitDiffLines(actual)
+	ops := diffLines(a, b)
+
+	var out strings.Builder
+	out.WriteString("--- expected\n")
+	out.WriteString("+++ actual\n")
+
+	for _, h := range diffHunks(ops, context) {
+		out.WriteString(h)
+	}
+
+	return strings.TrimRight(out.String(), "\n")
+}
+
+// splitDiffLines splits s into lines, ignoring a single trailing newline.
+func splitDiffLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// diffLines computes a line diff of a and b using the longest common subsequence.
+func diffLines(a, b []string) []diffOp {
+	// Trim the common prefix and suffix to keep the table small
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{kind: ' ', text: line})
+	}
+
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+
+	if (len(midA)+1)*(len(midB)+1) > maxDiffCells {
+		for _, line := range midA {
+			ops = append(ops, diffOp{kind: '-', text: line})
+		}
+		for _, line := range midB {
+			ops = append(ops, diffOp{kind: '+', text: line})
+		}
+	} else {
+		ops = append(ops, lcsDiff(midA, midB)...)
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{kind: ' ', text: line})
+	}
+
+	return ops
+}
+
+// lcsDiff builds the diff of a and b from a dynamic-programming LCS table.
+func lcsDiff(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	table := make([][]int, n+1)
+	for i := range table {
+		table[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{kind: ' ', text: a[i]})
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			ops = append(ops, diffOp{kind: '-', text: a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: '+', text: b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{kind: '-', text: a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{kind: '+', text: b[j]})
+	}
+
+	return ops
+}
+
+// diffHunks groups diff operations into unified diff hunks with the given context.
+func diffHunks(ops []diffOp, context int) []string {
+	var changes []int
+	for i, op := range ops {
+		if op.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	// Line numbers (1-based) in expected/actual at the start of each op
+	oldLine := make([]int, len(ops)+1)
+	newLine := make([]int, len(ops)+1)
+	oldLine[0], newLine[0] = 1, 1
+	for i, op := range ops {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if op.kind != '+' {
+			oldLine[i+1]++
+		}
+		if op.kind != '-' {
+			newLine[i+1]++
+		}
+	}
+
+	var hunks []string
+	for start := 0; start < len(changes); {
+		end := start
+		for end+1 < len(changes) && changes[end+1]-changes[end] <= 2*context+1 {
+			end++
+		}
+
+		from := max(0, changes[start]-context)
+		to := min(len(ops), changes[end]+context+1)
+
+		var h strings.Builder
+		fmt.Fprintf(&h, "@@ -%s +%s @@\n",
+			hunkRange(oldLine[from], oldLine[to]-oldLine[from]),
+			hunkRange(newLine[from], newLine[to]-newLine[from]))
+		for _, op := range ops[from:to] {
+			h.WriteByte(op.kind)
+			h.WriteString(op.text)
+			h.WriteByte('\n')
+		}
+		hunks = append(hunks, h.String())
+
+		start = end + 1
+	}
+
+	return hunks
+}
+
+// hunkRange formats a hunk line range. Empty ranges refer to the line before start.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		context  int
+		want     string
+	}{
+		{
+			name:     "identical",
+			expected: "a\nb\n",
+			actual:   "a\nb\n",
+			context:  3,
+			want:     "",
+		},
+		{
+			name:     "single line change",
+			expected: "a\nb\nc",
+			actual:   "a\nx\nc",
+			context:  3,
+			want:     "--- expected\n+++ actual\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c",
+		},
+		{
+			name:     "insertion into empty",
+			expected: "",
+			actual:   "a",
+			context:  3,
+			want:     "--- expected\n+++ actual\n@@ -0,0 +1 @@\n+a",
+		},
+		{
+			name:     "separate hunks",
+			expected: "1\n2\n3\n4\n5\n6\n7\n8",
+			actual:   "1\nX\n3\n4\n5\n6\nY\n8",
+			context:  1,
+			want:     "--- expected\n+++ actual\n@@ -1,3 +1,3 @@\n 1\n-2\n+X\n 3\n@@ -6,3 +6,3 @@\n 6\n-7\n+Y\n 8",
+		},
+		{
+			name:     "nearby changes share a hunk",
+			expected: "1\n2\n3\n4",
+			actual:   "X\n2\n3\nY",
+			context:  1,
+			want:     "--- expected\n+++ actual\n@@ -1,4 +1,4 @@\n-1\n+X\n 2\n 3\n-4\n+Y",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unifiedDiff(tt.expected, tt.actual, tt.context)
+			if got != tt.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
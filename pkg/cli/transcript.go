@@ -0,0 +1,431 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/spf13/cobra"
+)
+
+// NewTranscriptCmd creates the transcript command
+func NewTranscriptCmd() *cobra.Command {
+	var taskFilter string
+	var run int
+	var format string
+	var outputFile string
+
+	cmd := &cobra.Command{
+		Use:   "transcript <results-file>",
+		Short: "Render the conversation of a task run as Markdown or HTML",
+		Long: `Render the conversation of the agent in a task run as a readable transcript,
+for sharing in issues and design docs: the prompt, the thinking of the agent,
+its messages, its tool calls with their input and output, and its final answer,
+followed by the outcome of the run.
+
+Thinking and tool input and output are collapsible, using <details> elements,
+which GitHub and GitLab also render in Markdown.
+
+--task selects the tasks whose name contains its value, and --run a single run
+of tasks run several times (1-indexed). Without --run, every run is rendered.
+
+The results file may be gzip-compressed or a run journal. The transcript is
+written to stdout, or to --output.
+
+Example:
+  mcpchecker transcript --task create-pod mcpchecker-k8s-out.json > transcript.md
+  mcpchecker transcript --task create-pod --run 2 --format html -o transcript.html mcpchecker-k8s-out.json`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var render func(io.Writer, []transcript) error
+			switch format {
+			case "md", "markdown":
+				render = renderMarkdownTranscripts
+			case "html":
+				render = renderHTMLTranscripts
+			default:
+				return fmt.Errorf("unknown format: %s", format)
+			}
+
+			output, err := results.LoadOutput(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to load results file: %w", err)
+			}
+
+			var transcripts []transcript
+			for _, r := range results.Filter(output.Results, taskFilter) {
+				if run > 0 && r.RunIndex+1 != run {
+					continue
+				}
+				transcripts = append(transcripts, newTranscript(output.Summary, r))
+			}
+			if len(transcripts) == 0 {
+				if run > 0 {
+					return fmt.Errorf("no run %d of a task matching %q in %s", run, taskFilter, args[0])
+				}
+				return fmt.Errorf("no task matching %q in %s", taskFilter, args[0])
+			}
+
+			if outputFile == "" {
+				return render(cmd.OutOrStdout(), transcripts)
+			}
+			file, err := os.Create(outputFile)
+			if err != nil {
+				return fmt.Errorf("failed to create file: %w", err)
+			}
+			defer file.Close()
+			if err := render(file, transcripts); err != nil {
+				return fmt.Errorf("failed to write %s: %w", outputFile, err)
+			}
+			return file.Close()
+		},
+	}
+
+	cmd.Flags().StringVar(&taskFilter, "task", "", "Only render tasks whose name contains this value")
+	cmd.Flags().IntVar(&run, "run", 0, "Only render this run of each task, 1-indexed (default: all runs)")
+	cmd.Flags().StringVar(&format, "format", "md", "Transcript format (md, html)")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "File to write the transcript to (default: stdout)")
+	_ = cmd.MarkFlagRequired("task")
+
+	return cmd
+}
+
+// transcript is a task run prepared for rendering.
+type transcript struct {
+	Title    string
+	Status   string
+	Agent    string
+	Model    string
+	Duration string
+	Prompt   string
+	Entries  []transcriptEntry
+	// Answer is the final message of the agent
+	Answer string
+
+	Error            string
+	ExpectFailure    string
+	JudgeReason      string
+	FailedAssertions []string
+}
+
+// transcriptEntry is a step of the conversation: the thinking or a message
+// of the agent, or one of its tool calls.
+type transcriptEntry struct {
+	Kind string // "thinking", "message" or "tool_call"
+	Text string
+	Tool *transcriptTool
+}
+
+type transcriptTool struct {
+	Title, Kind, Status string
+	Input, Output       string
+}
+
+func newTranscript(summary *eval.EvalSummary, r *eval.EvalResult) transcript {
+	t := transcript{
+		Title:         r.TaskName,
+		Prompt:        resultPrompt(r),
+		Error:         strings.TrimSpace(r.TaskError),
+		ExpectFailure: r.ExpectFailureReason,
+		JudgeReason:   strings.TrimSpace(r.TaskJudgeReason),
+	}
+	if r.AssertionResults != nil {
+		t.FailedAssertions = results.CollectFailedAssertions(r.AssertionResults)
+	}
+	t.Status, _ = resultStatus(r)
+	if r.TotalRuns > 1 {
+		t.Title += fmt.Sprintf(" (run %d/%d)", r.RunIndex+1, r.TotalRuns)
+	}
+	if summary != nil && summary.Agent != nil {
+		t.Agent = summary.Agent.Type
+		if summary.Agent.Name != "" {
+			t.Agent = fmt.Sprintf("%s (%s)", summary.Agent.Name, summary.Agent.Type)
+		}
+		t.Model = summary.Agent.Model
+	}
+	if r.DurationMs > 0 {
+		t.Duration = formatDuration(time.Duration(r.DurationMs) * time.Millisecond)
+	}
+
+	var steps []transcriptEntry
+	if r.AgentOutput != nil && r.AgentOutput.AgentDetails != nil {
+		for _, step := range r.AgentOutput.AgentDetails.OutputSteps {
+			switch {
+			case step.Type == "tool_call" && step.ToolCall != nil:
+				steps = append(steps, transcriptEntry{Kind: step.Type, Tool: &transcriptTool{
+					Title:  step.ToolCall.Title,
+					Kind:   step.ToolCall.Kind,
+					Status: step.ToolCall.Status,
+					Input:  formatToolValue(step.ToolCall.RawInput),
+					Output: formatToolValue(step.ToolCall.RawOutput),
+				}})
+			case step.Type == "thinking" || step.Type == "message":
+				if text := strings.TrimSpace(step.Content); text != "" {
+					steps = append(steps, transcriptEntry{Kind: step.Type, Text: text})
+				}
+			}
+		}
+	}
+
+	// The last message of the agent is its final answer
+	for i := len(steps) - 1; i >= 0; i-- {
+		if steps[i].Kind == "message" {
+			t.Answer = steps[i].Text
+			steps = append(steps[:i], steps[i+1:]...)
+			break
+		}
+	}
+	if t.Answer == "" {
+		t.Answer = strings.TrimSpace(r.TaskOutput)
+	}
+	t.Entries = steps
+
+	return t
+}
+
+// formatToolValue formats the raw input or output of a tool call: strings
+// as they are, and other values as indented JSON.
+func formatToolValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func renderMarkdownTranscripts(w io.Writer, transcripts []transcript) error {
+	var b strings.Builder
+	for i, t := range transcripts {
+		if i > 0 {
+			b.WriteString("\n---\n\n")
+		}
+		writeMarkdownTranscript(&b, t)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMarkdownTranscript(b *strings.Builder, t transcript) {
+	fmt.Fprintf(b, "# %s\n\n", t.Title)
+	fmt.Fprintf(b, "- **Status:** %s\n", t.Status)
+	if t.Agent != "" {
+		fmt.Fprintf(b, "- **Agent:** %s\n", t.Agent)
+	}
+	if t.Model != "" {
+		fmt.Fprintf(b, "- **Model:** %s\n", t.Model)
+	}
+	if t.Duration != "" {
+		fmt.Fprintf(b, "- **Duration:** %s\n", t.Duration)
+	}
+
+	b.WriteString("\n## Prompt\n\n")
+	if t.Prompt != "" {
+		b.WriteString(markdownFence(t.Prompt, "text"))
+	} else {
+		b.WriteString("_Not recorded_\n")
+	}
+
+	if len(t.Entries) > 0 {
+		b.WriteString("\n## Conversation\n")
+		for _, entry := range t.Entries {
+			b.WriteString("\n")
+			switch entry.Kind {
+			case "thinking":
+				b.WriteString("<details>\n<summary>Thinking</summary>\n\n")
+				b.WriteString(markdownQuote(entry.Text))
+				b.WriteString("\n</details>\n")
+			case "message":
+				b.WriteString("**Agent:**\n\n")
+				b.WriteString(entry.Text + "\n")
+			case "tool_call":
+				fmt.Fprintf(b, "**Tool call:** `%s`", strings.ReplaceAll(entry.Tool.Title, "`", "'"))
+				if details := toolCallDetails(entry.Tool); details != "" {
+					fmt.Fprintf(b, " (%s)", details)
+				}
+				b.WriteString("\n")
+				for _, part := range []struct{ label, text string }{{"Input", entry.Tool.Input}, {"Output", entry.Tool.Output}} {
+					if part.text == "" {
+						continue
+					}
+					fmt.Fprintf(b, "\n<details>\n<summary>%s</summary>\n\n", part.label)
+					b.WriteString(markdownFence(part.text, ""))
+					b.WriteString("\n</details>\n")
+				}
+			}
+		}
+	}
+
+	b.WriteString("\n## Final Answer\n\n")
+	if t.Answer != "" {
+		b.WriteString(t.Answer + "\n")
+	} else {
+		b.WriteString("_No answer_\n")
+	}
+
+	if t.Error != "" || t.ExpectFailure != "" || t.JudgeReason != "" || len(t.FailedAssertions) > 0 {
+		b.WriteString("\n## Outcome\n\n")
+		if t.ExpectFailure != "" {
+			fmt.Fprintf(b, "Expected to fail: %s\n\n", t.ExpectFailure)
+		}
+		if t.Error != "" {
+			b.WriteString("Error:\n\n")
+			b.WriteString(markdownFence(t.Error, "text"))
+			b.WriteString("\n")
+		}
+		if t.JudgeReason != "" {
+			b.WriteString("Judge:\n\n")
+			b.WriteString(markdownQuote(t.JudgeReason))
+			b.WriteString("\n")
+		}
+		if len(t.FailedAssertions) > 0 {
+			b.WriteString("Failed assertions:\n\n")
+			for _, failure := range t.FailedAssertions {
+				fmt.Fprintf(b, "- %s\n", failure)
+			}
+			b.WriteString("\n")
+		}
+	}
+}
+
+// toolCallDetails returns the kind and status of a tool call, e.g. "execute,
+// failed".
+func toolCallDetails(tool *transcriptTool) string {
+	var details []string
+	for _, detail := range []string{tool.Kind, tool.Status} {
+		if detail != "" {
+			details = append(details, detail)
+		}
+	}
+	return strings.Join(details, ", ")
+}
+
+// markdownFence returns text as a fenced code block, with a fence longer
+// than any run of backticks in text.
+func markdownFence(text, lang string) string {
+	longest, run := 0, 0
+	for _, c := range text {
+		if c == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	return fence + lang + "\n" + text + "\n" + fence + "\n"
+}
+
+// markdownQuote returns text as a block quote.
+func markdownQuote(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+var transcriptHTML = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"toolCallDetails": toolCallDetails,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{with index . 0}}{{.Title}}{{end}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 56rem; margin: 2rem auto; padding: 0 1rem; color: #1f2328; line-height: 1.5; }
+pre { background: #f6f8fa; padding: 0.75rem; border-radius: 6px; overflow-x: auto; white-space: pre-wrap; word-break: break-word; }
+.meta { list-style: none; padding: 0; }
+.entry { margin: 1rem 0; }
+.message, .answer { white-space: pre-wrap; }
+.thinking { color: #59636e; white-space: pre-wrap; border-left: 3px solid #d1d9e0; padding-left: 0.75rem; }
+.tool { border: 1px solid #d1d9e0; border-radius: 6px; padding: 0.5rem 0.75rem; }
+.tool code { font-weight: 600; }
+summary { cursor: pointer; color: #59636e; }
+hr { margin: 3rem 0; }
+</style>
+</head>
+<body>
+{{range $i, $t := .}}{{if $i}}<hr>
+{{end}}<section>
+<h1>{{$t.Title}}</h1>
+<ul class="meta">
+<li><strong>Status:</strong> {{$t.Status}}</li>
+{{- if $t.Agent}}
+<li><strong>Agent:</strong> {{$t.Agent}}</li>
+{{- end}}
+{{- if $t.Model}}
+<li><strong>Model:</strong> {{$t.Model}}</li>
+{{- end}}
+{{- if $t.Duration}}
+<li><strong>Duration:</strong> {{$t.Duration}}</li>
+{{- end}}
+</ul>
+<h2>Prompt</h2>
+{{if $t.Prompt}}<pre>{{$t.Prompt}}</pre>{{else}}<p><em>Not recorded</em></p>{{end}}
+{{- if $t.Entries}}
+<h2>Conversation</h2>
+{{- range $t.Entries}}
+<div class="entry">
+{{- if eq .Kind "thinking"}}
+<details><summary>Thinking</summary><div class="thinking">{{.Text}}</div></details>
+{{- else if eq .Kind "message"}}
+<strong>Agent:</strong>
+<div class="message">{{.Text}}</div>
+{{- else}}
+<div class="tool"><strong>Tool call:</strong> <code>{{.Tool.Title}}</code>{{with toolCallDetails .Tool}} ({{.}}){{end}}
+{{- if .Tool.Input}}
+<details><summary>Input</summary><pre>{{.Tool.Input}}</pre></details>
+{{- end}}
+{{- if .Tool.Output}}
+<details><summary>Output</summary><pre>{{.Tool.Output}}</pre></details>
+{{- end}}
+</div>
+{{- end}}
+</div>
+{{- end}}
+{{- end}}
+<h2>Final Answer</h2>
+{{if $t.Answer}}<div class="answer">{{$t.Answer}}</div>{{else}}<p><em>No answer</em></p>{{end}}
+{{- if or $t.Error $t.ExpectFailure $t.JudgeReason $t.FailedAssertions}}
+<h2>Outcome</h2>
+{{- if $t.ExpectFailure}}
+<p>Expected to fail: {{$t.ExpectFailure}}</p>
+{{- end}}
+{{- if $t.Error}}
+<p>Error:</p>
+<pre>{{$t.Error}}</pre>
+{{- end}}
+{{- if $t.JudgeReason}}
+<p>Judge:</p>
+<blockquote>{{$t.JudgeReason}}</blockquote>
+{{- end}}
+{{- if $t.FailedAssertions}}
+<p>Failed assertions:</p>
+<ul>
+{{- range $t.FailedAssertions}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+</section>
+{{end}}</body>
+</html>
+`))
+
+func renderHTMLTranscripts(w io.Writer, transcripts []transcript) error {
+	return transcriptHTML.Execute(w, transcripts)
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+func transcriptResults() []*eval.EvalResult {
+	return []*eval.EvalResult{
+		{
+			TaskName:            "create-pod",
+			RunIndex:            0,
+			TotalRuns:           2,
+			TaskPassed:          true,
+			AllAssertionsPassed: true,
+			DurationMs:          1500,
+			TaskOutput:          "Created the pod.",
+			AgentOutput: &task.PhaseOutput{
+				Success: true,
+				AgentDetails: &task.AgentDetails{
+					Prompt: "Create a pod named web in <ns>",
+					OutputSteps: []agent.OutputStep{
+						{Type: "thinking", Content: "I should call pods_create."},
+						{Type: "message", Content: "Creating the pod."},
+						{Type: "tool_call", ToolCall: &agent.ToolCallSummary{
+							Title:     "pods_create",
+							Kind:      "execute",
+							Status:    "completed",
+							RawInput:  map[string]any{"name": "web"},
+							RawOutput: "pod/web created\n```",
+						}},
+						{Type: "message", Content: "Created the pod."},
+					},
+				},
+			},
+		},
+		{
+			TaskName:        "create-pod",
+			RunIndex:        1,
+			TotalRuns:       2,
+			TaskError:       "agent timed out",
+			TaskJudgeReason: "The pod was not created.",
+		},
+		{
+			TaskName:   "delete-pod",
+			TaskPassed: true,
+		},
+	}
+}
+
+func TestTranscriptMarkdown(t *testing.T) {
+	filePath := createTestResultsFile(t, transcriptResults())
+
+	cmd := NewTranscriptCmd()
+	cmd.SetArgs([]string{filePath, "--task", "create", "--run", "1"})
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("transcript command failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# create-pod (run 1/2)\n",
+		"- **Status:** PASSED\n",
+		"- **Duration:** 1.5s\n",
+		"## Prompt\n\n```text\nCreate a pod named web in <ns>\n```\n",
+		"<summary>Thinking</summary>\n\n> I should call pods_create.\n",
+		"**Agent:**\n\nCreating the pod.\n",
+		"**Tool call:** `pods_create` (execute, completed)\n",
+		"<summary>Input</summary>\n\n```\n{\n  \"name\": \"web\"\n}\n```\n",
+		// The fence is longer than the backticks in the output
+		"<summary>Output</summary>\n\n````\npod/web created\n```\n````\n",
+		"## Final Answer\n\nCreated the pod.\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("transcript is missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "Created the pod.") != 1 {
+		t.Errorf("expected the final answer to be rendered once:\n%s", out)
+	}
+	if strings.Contains(out, "run 2/2") || strings.Contains(out, "delete-pod") || strings.Contains(out, "## Outcome") {
+		t.Errorf("expected only the first run of create-pod:\n%s", out)
+	}
+}
+
+func TestTranscriptHTML(t *testing.T) {
+	filePath := createTestResultsFile(t, transcriptResults())
+	outputFile := filepath.Join(t.TempDir(), "transcript.html")
+
+	cmd := NewTranscriptCmd()
+	cmd.SetArgs([]string{filePath, "--task", "create-pod", "--format", "html", "-o", outputFile})
+	cmd.SetOut(new(bytes.Buffer))
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("transcript command failed: %v", err)
+	}
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("failed to read transcript: %v", err)
+	}
+	out := string(data)
+
+	for _, want := range []string{
+		"<h1>create-pod (run 1/2)</h1>",
+		"<pre>Create a pod named web in &lt;ns&gt;</pre>",
+		"<details><summary>Thinking</summary>",
+		"<code>pods_create</code> (execute, completed)",
+		"<details><summary>Output</summary><pre>pod/web created\n```</pre></details>",
+		"<h1>create-pod (run 2/2)</h1>",
+		"<strong>Status:</strong> FAILED",
+		"<pre>agent timed out</pre>",
+		"<blockquote>The pod was not created.</blockquote>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("transcript is missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "<section>") != 2 {
+		t.Errorf("expected a section per run of create-pod:\n%s", out)
+	}
+}
+
+func TestTranscriptErrors(t *testing.T) {
+	filePath := createTestResultsFile(t, transcriptResults())
+
+	tests := map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"no matching task": {
+			args:    []string{filePath, "--task", "scale"},
+			wantErr: `no task matching "scale"`,
+		},
+		"no matching run": {
+			args:    []string{filePath, "--task", "delete", "--run", "2"},
+			wantErr: `no run 2 of a task matching "delete"`,
+		},
+		"unknown format": {
+			args:    []string{filePath, "--task", "create", "--format", "pdf"},
+			wantErr: "unknown format: pdf",
+		},
+		"task is required": {
+			args:    []string{filePath},
+			wantErr: `required flag(s) "task" not set`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := NewTranscriptCmd()
+			cmd.SetArgs(tc.args)
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+			err := cmd.Execute()
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
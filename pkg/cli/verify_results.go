@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+	"github.com/spf13/cobra"
+)
+
+// NewVerifyResultsCmd creates the verify-results command
+func NewVerifyResultsCmd() *cobra.Command {
+	var keyFile string
+	var signatureFile string
+	var identity string
+	var issuer string
+	var attestationFile string
+	var evalFile string
+
+	cmd := &cobra.Command{
+		Use:   "verify-results <results-file>",
+		Short: "Verify the signature and show the provenance of a results file",
+		Long: `Verify that a results file signed by 'mcpchecker check --sign-key' or
+'mcpchecker check --sigstore' has not been modified since it was signed, and
+show the provenance recorded in it.
+
+Signatures made with --sign-key are verified with the matching public key
+(--key). Sigstore bundles are verified with cosign against the identity and
+OIDC issuer of the signing certificate.
+
+If the run was attested with 'mcpchecker check --attest', the attestation
+(<results-file>.intoto.json) is verified the same way, checked to be about
+this results file, and the inputs and pass rates it records are shown. With
+--eval, the task set, lockfile and agent and judge configs next to the eval
+config are hashed again and must match the attested inputs.
+
+Exits with code 0 if the signature is valid, code 1 otherwise.`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resultsFile := args[0]
+
+			useSigstore := identity != "" || issuer != ""
+			if !useSigstore && keyFile == "" {
+				if _, err := os.Stat(resultsFile + results.SigstoreBundleSuffix); err == nil {
+					useSigstore = true
+				}
+			}
+
+			var method string
+			var key ed25519.PublicKey
+			if useSigstore {
+				if signatureFile == "" {
+					signatureFile = resultsFile + results.SigstoreBundleSuffix
+				}
+				if err := results.VerifyFileSigstore(cmd.Context(), resultsFile, signatureFile, identity, issuer); err != nil {
+					return fmt.Errorf("verification failed: %w", err)
+				}
+				method = fmt.Sprintf("sigstore (%s, issued by %s)", identity, issuer)
+			} else {
+				if keyFile == "" {
+					return fmt.Errorf("--key is required to verify ed25519 signatures")
+				}
+				var err error
+				key, err = results.LoadPublicKey(keyFile)
+				if err != nil {
+					return err
+				}
+				if signatureFile == "" {
+					signatureFile = resultsFile + results.SignatureSuffix
+				}
+				if err := results.VerifyFile(resultsFile, signatureFile, key); err != nil {
+					return fmt.Errorf("verification failed: %w", err)
+				}
+				method = "ed25519 (" + keyFile + ")"
+			}
+
+			output, err := results.LoadOutput(resultsFile)
+			if err != nil {
+				return fmt.Errorf("failed to load results file: %w", err)
+			}
+
+			var provenance *eval.Provenance
+			if output.Summary != nil {
+				provenance = output.Summary.Provenance
+			}
+			outputProvenance(method, signatureFile, provenance)
+
+			if attestationFile == "" {
+				if _, err := os.Stat(resultsFile + results.AttestationSuffix); err != nil {
+					if evalFile != "" {
+						return fmt.Errorf("no attestation found at %s to check the inputs of", resultsFile+results.AttestationSuffix)
+					}
+					return nil
+				}
+				attestationFile = resultsFile + results.AttestationSuffix
+			}
+			return verifyAttestation(cmd, resultsFile, attestationFile, evalFile, key, useSigstore, identity, issuer)
+		},
+	}
+
+	cmd.Flags().StringVar(&keyFile, "key", "", "ed25519 public key (PKIX PEM) to verify a --sign-key signature with")
+	cmd.Flags().StringVar(&signatureFile, "signature", "", "Signature or sigstore bundle (default: <results-file>.sig or <results-file>.sigstore.json)")
+	cmd.Flags().StringVar(&identity, "certificate-identity", "", "Identity the sigstore signing certificate must have been issued to, e.g. an email address or workflow URL")
+	cmd.Flags().StringVar(&attestationFile, "attestation", "", "Attestation of the run (default: <results-file>.intoto.json, if it exists)")
+	cmd.Flags().StringVar(&evalFile, "eval", "", "Eval config whose task set, lockfile and agent and judge configs must match the inputs of the attestation")
+	cmd.Flags().StringVar(&issuer, "certificate-oidc-issuer", "", "OIDC issuer of the sigstore signing certificate, e.g. https://token.actions.githubusercontent.com")
+
+	return cmd
+}
+
+// verifyAttestation verifies the attestation of the results file like the
+// results file itself, then prints it and, with evalFile, checks its inputs.
+func verifyAttestation(cmd *cobra.Command, resultsFile, attestationFile, evalFile string, key ed25519.PublicKey, useSigstore bool, identity, issuer string) error {
+	if useSigstore {
+		if err := results.VerifyFileSigstore(cmd.Context(), attestationFile, attestationFile+results.SigstoreBundleSuffix, identity, issuer); err != nil {
+			return fmt.Errorf("attestation verification failed: %w", err)
+		}
+	}
+	statement, signed, err := results.ReadAttestation(attestationFile, key)
+	if err != nil {
+		return fmt.Errorf("attestation verification failed: %w", err)
+	}
+	if !signed && !useSigstore {
+		return fmt.Errorf("attestation verification failed: %s is not signed", attestationFile)
+	}
+	if err := statement.VerifySubject(resultsFile); err != nil {
+		return fmt.Errorf("attestation verification failed: %w", err)
+	}
+
+	outputAttestation(attestationFile, &statement.Predicate)
+
+	if evalFile == "" {
+		return nil
+	}
+	if diffs := results.CheckInputs(&statement.Predicate.Inputs, evalFile); len(diffs) > 0 {
+		return fmt.Errorf("inputs of %s do not match the attestation:\n  %s", evalFile, strings.Join(diffs, "\n  "))
+	}
+	_, _ = color.New(color.FgGreen).Printf("Inputs match:  %s\n", evalFile)
+	return nil
+}
+
+func outputAttestation(attestationFile string, p *results.RunPredicate) {
+	bold := color.New(color.Bold)
+
+	fmt.Println()
+	_, _ = bold.Println("=== Attestation ===")
+	fmt.Printf("File:         %s\n", attestationFile)
+	if p.Eval != "" {
+		fmt.Printf("Eval:         %s\n", p.Eval)
+	}
+	fmt.Printf("Eval config:  %s (sha256:%s)\n", p.Inputs.EvalConfig.Name, p.Inputs.EvalConfig.Digest["sha256"])
+	if p.Inputs.Lockfile != nil {
+		fmt.Printf("Lockfile:     sha256:%s\n", p.Inputs.Lockfile.Digest["sha256"])
+	}
+	fmt.Printf("Task set:     %d tasks (sha256:%s)\n", len(p.Inputs.Tasks), p.Inputs.TaskSetDigest["sha256"])
+	for _, c := range []struct {
+		label string
+		input *results.ComponentInput
+	}{{"Agent", p.Inputs.Agent}, {"Judge", p.Inputs.Judge}} {
+		if c.input == nil {
+			continue
+		}
+		line := c.input.Type
+		if c.input.Model != "" {
+			line += " (" + c.input.Model + ")"
+		}
+		if c.input.Config != nil {
+			line += fmt.Sprintf(", config %s (sha256:%s)", c.input.Config.Name, c.input.Config.Digest["sha256"])
+		}
+		fmt.Printf("%-14s%s\n", c.label+":", line)
+	}
+	fmt.Printf("Tasks passed: %d/%d (%.1f%%)\n", p.Outputs.TasksPassed, p.Outputs.TasksTotal, p.Outputs.TaskPassRate*100)
+	fmt.Printf("Assertions:   %.1f%% passed\n", p.Outputs.AssertionPassRate*100)
+}
+
+func outputProvenance(method, signatureFile string, p *eval.Provenance) {
+	green := color.New(color.FgGreen)
+	yellow := color.New(color.FgYellow)
+	bold := color.New(color.Bold)
+
+	_, _ = green.Printf("Signature valid: %s\n", method)
+	fmt.Printf("Signature file:  %s\n", signatureFile)
+	fmt.Println()
+
+	if p == nil {
+		_, _ = yellow.Println("No provenance recorded in the results file")
+		return
+	}
+
+	_, _ = bold.Println("=== Provenance ===")
+	fmt.Printf("mcpchecker:   %s\n", p.MCPCheckerVersion)
+	if p.TaskRepoCommit != "" {
+		commit := p.TaskRepoCommit
+		if p.TaskRepoDirty {
+			commit += " (with uncommitted changes)"
+		}
+		fmt.Printf("Task repo:    %s\n", commit)
+	}
+	if p.AgentModel != "" {
+		fmt.Printf("Agent model:  %s\n", p.AgentModel)
+	}
+	if p.JudgeModel != "" {
+		fmt.Printf("Judge model:  %s\n", p.JudgeModel)
+	}
+	if p.LockfileHash != "" {
+		fmt.Printf("Lockfile:     %s\n", p.LockfileHash)
+	}
+	if !p.CreatedAt.IsZero() {
+		fmt.Printf("Created:      %s\n", p.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+	}
+}
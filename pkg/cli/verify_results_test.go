@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+)
+
+func TestVerifyResultsCmd(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPath := filepath.Join(t.TempDir(), "key.pub")
+	if err := os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		args        func(resultsFile string) []string
+		tamper      bool
+		errContains string
+	}{
+		"valid signature": {
+			args: func(resultsFile string) []string { return []string{resultsFile, "--key", pubPath} },
+		},
+		"modified results": {
+			args:        func(resultsFile string) []string { return []string{resultsFile, "--key", pubPath} },
+			tamper:      true,
+			errContains: "verification failed: results file was modified after signing",
+		},
+		"missing key": {
+			args:        func(resultsFile string) []string { return []string{resultsFile} },
+			errContains: "--key is required",
+		},
+		"missing signature": {
+			args: func(resultsFile string) []string {
+				return []string{resultsFile, "--key", pubPath, "--signature", resultsFile + ".missing"}
+			},
+			errContains: "failed to read signature",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			resultsFile := createTestResultsFile(t, sampleResults())
+			if _, err := results.SignFile(resultsFile, priv); err != nil {
+				t.Fatalf("SignFile() error = %v", err)
+			}
+			if tc.tamper {
+				data, err := os.ReadFile(resultsFile)
+				if err != nil {
+					t.Fatal(err)
+				}
+				data = []byte(strings.Replace(string(data), `"taskPassed": false`, `"taskPassed": true`, 1))
+				if err := os.WriteFile(resultsFile, data, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cmd := NewVerifyResultsCmd()
+			cmd.SetArgs(tc.args(resultsFile))
+			err := cmd.Execute()
+			if tc.errContains == "" {
+				if err != nil {
+					t.Errorf("verify-results error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errContains) {
+				t.Errorf("verify-results error = %v, want containing %q", err, tc.errContains)
+			}
+		})
+	}
+}
+
+func TestVerifyResultsCmdAttestation(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	pubPath := filepath.Join(dir, "key.pub")
+	if err := os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	configFile := filepath.Join(dir, "eval.yaml")
+	taskFile := filepath.Join(dir, "task.yaml")
+	for _, path := range []string{configFile, taskFile} {
+		if err := os.WriteFile(path, []byte("kind: "+filepath.Base(path)+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := map[string]struct {
+		unsigned    bool
+		modifyTask  bool
+		errContains string
+	}{
+		"valid attestation": {},
+		"unsigned attestation": {
+			unsigned:    true,
+			errContains: "is not signed",
+		},
+		"changed inputs": {
+			modifyTask:  true,
+			errContains: "task task.yaml changed",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := os.WriteFile(taskFile, []byte("kind: Task\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			resultsFile := createTestResultsFile(t, sampleResults())
+			if _, err := results.SignFile(resultsFile, priv); err != nil {
+				t.Fatalf("SignFile() error = %v", err)
+			}
+			output, err := results.LoadOutput(resultsFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range output.Results {
+				r.TaskPath = taskFile
+			}
+			statement, err := results.NewAttestation(resultsFile, configFile, "test", output)
+			if err != nil {
+				t.Fatalf("NewAttestation() error = %v", err)
+			}
+			key := priv
+			if tc.unsigned {
+				key = nil
+			}
+			if _, err := results.WriteAttestation(resultsFile, statement, key); err != nil {
+				t.Fatalf("WriteAttestation() error = %v", err)
+			}
+			if tc.modifyTask {
+				if err := os.WriteFile(taskFile, []byte("kind: Task\nspec: {}\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cmd := NewVerifyResultsCmd()
+			cmd.SetArgs([]string{resultsFile, "--key", pubPath, "--eval", configFile})
+			err = cmd.Execute()
+			if tc.errContains == "" {
+				if err != nil {
+					t.Errorf("verify-results error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errContains) {
+				t.Errorf("verify-results error = %v, want containing %q", err, tc.errContains)
+			}
+		})
+	}
+}
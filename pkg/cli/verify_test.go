@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
 )
 
 // createTestResultsFile creates a temporary results file for testing
@@ -202,3 +203,40 @@ func TestVerifyCommandAllPassed(t *testing.T) {
 	}
 }
 
+
+func TestVerifyCommandExcludesQuarantined(t *testing.T) {
+	evalResults := sampleResults()
+	// Quarantine the only failing task so the remaining tasks all pass
+	evalResults[2].State = task.StateQuarantined
+	filePath := createTestResultsFile(t, evalResults)
+
+	cmd := NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--task", "1.0", "--assertion", "0.5"})
+
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+
+	err := cmd.Execute()
+	if err != nil {
+		t.Errorf("verify command should ignore quarantined tasks, got error: %v", err)
+	}
+}
+
+func TestVerifyCommandExcludesExpectedFailures(t *testing.T) {
+	evalResults := sampleResults()
+	// The only failing task is expected to fail, and another one passes unexpectedly
+	evalResults[2].ExpectFailure = true
+	evalResults[0].ExpectFailure = true
+	filePath := createTestResultsFile(t, evalResults)
+
+	cmd := NewVerifyCmd()
+	cmd.SetArgs([]string{filePath, "--task", "1.0", "--assertion", "0.5"})
+
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+
+	err := cmd.Execute()
+	if err != nil {
+		t.Errorf("verify command should ignore expected failures, got error: %v", err)
+	}
+}
@@ -1,9 +1,14 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"regexp"
+	"runtime"
+	"runtime/debug"
 
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
 	"github.com/spf13/cobra"
 )
 
@@ -17,13 +22,25 @@ var (
 var semverRegex = regexp.MustCompile(`^v\d+\.\d+\.\d+$`)
 
 func NewVersionCmd() *cobra.Command {
-	return &cobra.Command{
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "Print version information",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(buildInfo())
+			}
 			fmt.Printf("mcpchecker version %s\n", version())
+			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the version, git commit, Go version and platform as JSON")
+
+	return cmd
 }
 
 func version() string {
@@ -34,3 +51,25 @@ func version() string {
 		return Version
 	}
 }
+
+// buildInfo returns the build of this binary, as recorded in results. The
+// commit falls back to the VCS revision stamped by go build, for builds
+// without ldflags.
+func buildInfo() *eval.BuildInfo {
+	info := &eval.BuildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if info.Commit == "" {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range bi.Settings {
+				if setting.Key == "vcs.revision" {
+					info.Commit = setting.Value
+				}
+			}
+		}
+	}
+	return info
+}
@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestBuildInfo(t *testing.T) {
+	oldVersion, oldCommit := Version, Commit
+	t.Cleanup(func() { Version, Commit = oldVersion, oldCommit })
+
+	Version, Commit = "v1.2.3", "abc1234"
+	info := buildInfo()
+
+	if info.Version != "v1.2.3" || info.Commit != "abc1234" {
+		t.Errorf("buildInfo() version = %q, commit = %q, want v1.2.3, abc1234", info.Version, info.Commit)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("buildInfo().GoVersion = %q, want %q", info.GoVersion, runtime.Version())
+	}
+	if want := runtime.GOOS + "/" + runtime.GOARCH; info.Platform != want {
+		t.Errorf("buildInfo().Platform = %q, want %q", info.Platform, want)
+	}
+}
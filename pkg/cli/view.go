@@ -1,21 +1,24 @@
 package cli
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
 	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
 	"github.com/mcpchecker/mcpchecker/pkg/results"
 	"github.com/mcpchecker/mcpchecker/pkg/task"
 	"github.com/mcpchecker/mcpchecker/pkg/tokens"
-	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 	"github.com/spf13/cobra"
 )
 
@@ -23,12 +26,17 @@ const (
 	defaultMaxEvents      = 40
 	defaultMaxOutputLines = 6
 	defaultMaxLineLength  = 100
+	diffContextLines      = 3
 )
 
 // NewViewCmd creates the view command for rendering eval results.
 func NewViewCmd() *cobra.Command {
 	var (
 		taskFilter     string
+		failedOnly     bool
+		phase          string
+		jsonPath       string
+		noPager        bool
 		showTimeline   = true
 		maxEvents      = defaultMaxEvents
 		maxOutputLines = defaultMaxOutputLines
@@ -40,42 +48,78 @@ func NewViewCmd() *cobra.Command {
 		Short: "Pretty-print evaluation results from a JSON file",
 		Long: `Render the JSON output produced by "mcpchecker check" in a human-friendly format.
 
+When writing to a terminal, output is sent through a pager ($MCPCHECKER_PAGER,
+$PAGER, or less). Use --no-pager to disable this.
+
 Examples:
   mcpchecker result view mcpchecker-netedge-selector-mismatch-out.json
-  mcpchecker result view --task netedge-selector-mismatch --max-events 15 results.json`,
+  mcpchecker result view --task netedge-selector-mismatch --max-events 15 results.json
+  mcpchecker result view --failed-only --phase verify results.json
+  mcpchecker result view --json-path assertionResults.toolsUsed.reason results.json`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateViewPhase(phase); err != nil {
+				return err
+			}
+
 			evalResults, err := results.Load(args[0])
 			if err != nil {
 				return err
 			}
 
 			filtered := results.Filter(evalResults, taskFilter)
+			if failedOnly {
+				filtered = filterFailed(filtered)
+			}
 			if len(filtered) == 0 {
-				if taskFilter == "" {
+				switch {
+				case failedOnly && taskFilter == "":
+					return errors.New("no failed tasks found in results")
+				case taskFilter == "":
 					return errors.New("no tasks found in results")
 				}
 				return fmt.Errorf("no tasks matched filter %q", taskFilter)
 			}
 
+			var buf bytes.Buffer
 			for idx, result := range filtered {
+				if jsonPath != "" {
+					if err := printJSONPath(&buf, result, jsonPath); err != nil {
+						return err
+					}
+					continue
+				}
+
 				if idx > 0 {
-					fmt.Println()
+					fmt.Fprintln(&buf)
 				}
-				printEvalResult(cmd.OutOrStdout(), result, viewOptions{
+				printEvalResult(&buf, result, viewOptions{
 					showTimeline:   showTimeline,
+					phase:          phase,
 					maxEvents:      maxEvents,
 					maxOutputLines: maxOutputLines,
 					maxLineLength:  maxLineLength,
 				})
 			}
 
-			return nil
+			// The runs of tasks that ran more than once are broken down after
+			// the runs themselves, including the runs --failed-only hides
+			if attempts := eval.SummarizeAttempts(results.Filter(evalResults, taskFilter)); attempts != nil && jsonPath == "" {
+				fmt.Fprintln(&buf)
+				color.New(color.Bold).Fprintln(&buf, "Attempts:")
+				outputTextAttempts(&buf, attempts)
+			}
+
+			return writePaged(cmd.OutOrStdout(), buf.Bytes(), !noPager)
 		},
 	}
 
 	cmd.Flags().StringVar(&taskFilter, "task", "", "Only show results for tasks whose name contains this value")
-	cmd.Flags().BoolVar(&showTimeline, "timeline", showTimeline, "Include a condensed agent timeline derived from taskOutput")
+	cmd.Flags().BoolVar(&failedOnly, "failed-only", false, "Only show tasks that failed or have failed assertions")
+	cmd.Flags().StringVar(&phase, "phase", "", "Only show output from a single phase (setup, agent, verify, cleanup, cleanupVerify)")
+	cmd.Flags().StringVar(&jsonPath, "json-path", "", "Print a single field from each result (e.g. 'assertionResults.toolsUsed' or 'callHistory.ToolCalls[0]')")
+	cmd.Flags().BoolVar(&noPager, "no-pager", false, "Do not pipe output through a pager")
+	cmd.Flags().BoolVar(&showTimeline, "timeline", showTimeline, "Include a condensed agent timeline derived from the agent's structured output (or taskOutput when unavailable)")
 	cmd.Flags().IntVar(&maxEvents, "max-events", maxEvents, "Maximum number of timeline entries (thought/command/tool/etc.) to display (0 = unlimited)")
 	cmd.Flags().IntVar(&maxOutputLines, "max-output-lines", maxOutputLines, "Maximum lines to display for command output in the timeline")
 	cmd.Flags().IntVar(&maxLineLength, "max-line-length", maxLineLength, "Maximum characters per line when formatting timeline output")
@@ -83,48 +127,80 @@ Examples:
 	return cmd
 }
 
+// viewPhases lists the phases accepted by the --phase flag.
+var viewPhases = []string{"setup", "agent", "verify", "cleanup", "cleanupVerify"}
+
 // viewOptions controls which portions of a result are rendered and how much detail is shown.
 type viewOptions struct {
 	showTimeline   bool
+	phase          string
 	maxEvents      int
 	maxOutputLines int
 	maxLineLength  int
 }
 
+// validateViewPhase checks that phase is empty or one of the known task phases.
+func validateViewPhase(phase string) error {
+	if phase == "" {
+		return nil
+	}
+	for _, p := range viewPhases {
+		if phase == p {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid phase %q: must be one of %s", phase, strings.Join(viewPhases, ", "))
+}
+
+// filterFailed returns the results that did not fully pass.
+func filterFailed(evalResults []*eval.EvalResult) []*eval.EvalResult {
+	failed := make([]*eval.EvalResult, 0, len(evalResults))
+	for _, r := range evalResults {
+		if !r.TaskPassed || !r.AllAssertionsPassed {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
 // printEvalResult writes a formatted summary of a single evaluation result.
 func printEvalResult(w io.Writer, result *eval.EvalResult, opts viewOptions) {
 	bold := color.New(color.Bold)
-	green := color.New(color.FgGreen)
-	red := color.New(color.FgRed)
 	yellow := color.New(color.FgYellow)
 
 	bold.Fprintf(w, "Task: %s\n", result.TaskName)
 	fmt.Fprintf(w, "  Path: %s\n", result.TaskPath)
+	if result.TotalRuns > 1 {
+		fmt.Fprintf(w, "  Run: %d/%d\n", result.RunIndex+1, result.TotalRuns)
+	}
 	if result.Difficulty != "" {
 		fmt.Fprintf(w, "  Difficulty: %s\n", result.Difficulty)
 	}
 
-	status := "PASSED"
-	statusColor := green
-
-	switch {
-	case result.AgentExecutionError:
-		status = "FAILED (agent error)"
-		statusColor = red
-	case !result.TaskPassed:
-		status = "FAILED"
-		statusColor = red
-	case result.TaskPassed && !result.AllAssertionsPassed:
-		status = "PASSED (assertions failed)"
-		statusColor = yellow
+	status, statusColor := resultStatus(result)
+	color.New(statusColor).Fprintf(w, "  Status: %s\n", status)
+	if result.ExpectFailureReason != "" {
+		fmt.Fprintf(w, "  Expected Failure: %s\n", result.ExpectFailureReason)
+	}
+	if result.JudgeOverride != nil {
+		fmt.Fprintf(w, "  Review: %s\n", formatJudgeOverride(result.JudgeOverride))
 	}
-
-	statusColor.Fprintf(w, "  Status: %s\n", status)
 	if trimmed := strings.TrimSpace(result.TaskError); trimmed != "" {
 		printMultilineField(w, "Error", trimmed)
 	}
+	if len(result.LeakedResources) > 0 {
+		printMultilineField(w, "Leaked Resources", strings.Join(result.LeakedResources, "\n"))
+	}
 
-	if prompt := loadTaskPrompt(result.TaskPath); prompt != "" {
+	if opts.phase != "" {
+		printPhase(w, result, opts)
+		return
+	}
+
+	if result.Locale != "" {
+		fmt.Fprintf(w, "  Locale: %s\n", result.Locale)
+	}
+	if prompt := resultPrompt(result); prompt != "" {
 		printMultilineField(w, "Prompt", prompt)
 	}
 
@@ -132,10 +208,11 @@ func printEvalResult(w io.Writer, result *eval.EvalResult, opts viewOptions) {
 	printTokenEstimate(w, result.TokenEstimate)
 	printActualAgentTokenUsage(w, result.TokenEstimate)
 	printJudgeTokenUsage(w, result.JudgeTokenUsage)
+	printResourceUsage(w, result.ResourceUsage)
 	printCallHistory(w, result.CallHistory, opts)
 
 	if opts.showTimeline {
-		timeline := summarizeTaskOutput(result.TaskOutput, opts.maxEvents, opts.maxOutputLines, opts.maxLineLength)
+		timeline := buildTimeline(result, opts)
 		if len(timeline) > 0 {
 			fmt.Fprintln(w, "  Timeline:")
 			for _, line := range timeline {
@@ -145,6 +222,175 @@ func printEvalResult(w io.Writer, result *eval.EvalResult, opts viewOptions) {
 	}
 }
 
+// resultStatus returns the status of result, e.g. "FAILED (agent error)",
+// with the color it is shown in.
+func resultStatus(result *eval.EvalResult) (string, color.Attribute) {
+	switch {
+	case results.XPassed(result):
+		return "XPASS (passed, but expected to fail)", color.FgYellow
+	case results.XFailed(result):
+		return "XFAIL (failed as expected)", color.FgYellow
+	case result.SkippedOverBudget:
+		return "SKIPPED (over budget)", color.FgYellow
+	case result.Skipped:
+		return fmt.Sprintf("SKIPPED (%s)", result.SkipReason), color.FgYellow
+	case result.JudgeError:
+		return "JUDGE ERROR (no verdict)", color.FgYellow
+	case result.AgentExecutionError:
+		return "FAILED (agent error)", color.FgRed
+	case !result.TaskPassed:
+		return "FAILED", color.FgRed
+	case !result.AllAssertionsPassed:
+		return "PASSED (assertions failed)", color.FgYellow
+	}
+	return "PASSED", color.FgGreen
+}
+
+// printPhase writes the output of the phase selected in opts.
+func printPhase(w io.Writer, result *eval.EvalResult, opts viewOptions) {
+	var output *task.PhaseOutput
+	switch opts.phase {
+	case "setup":
+		output = result.SetupOutput
+	case "agent":
+		output = result.AgentOutput
+	case "verify":
+		output = result.VerifyOutput
+	case "cleanup":
+		output = result.CleanupOutput
+	case "cleanupVerify":
+		output = result.CleanupVerifyOutput
+	}
+
+	label := strings.ToUpper(opts.phase[:1]) + opts.phase[1:]
+	if output == nil {
+		fmt.Fprintf(w, "  %s: no output recorded\n", label)
+		return
+	}
+
+	status := "succeeded"
+	if !output.Success {
+		status = "failed"
+	}
+	fmt.Fprintf(w, "  %s: %s\n", label, status)
+	if trimmed := strings.TrimSpace(output.Error); trimmed != "" {
+		printMultilineField(w, "Phase Error", trimmed)
+	}
+
+	if opts.phase == "agent" {
+		for _, line := range buildTimeline(result, opts) {
+			printTimelineLine(w, line)
+		}
+		return
+	}
+
+	printPhaseOutput(w, label, output)
+}
+
+// printJSONPath writes the value at path within result. Strings are written
+// verbatim; other values are written as indented JSON.
+func printJSONPath(w io.Writer, result *eval.EvalResult, path string) error {
+	value, err := extractJSONPath(result, path)
+	if err != nil {
+		return fmt.Errorf("task %s: %w", result.TaskName, err)
+	}
+
+	if str, ok := value.(string); ok {
+		fmt.Fprintln(w, str)
+		return nil
+	}
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("task %s: failed to encode %q: %w", result.TaskName, path, err)
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
+
+// extractJSONPath resolves a dotted path with optional [index] suffixes (for example
+// "callHistory.ToolCalls[0].name") against the JSON encoding of v.
+// A leading "$" or "." is accepted and ignored.
+func extractJSONPath(v any, path string) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var current any
+	if err := json.Unmarshal(data, &current); err != nil {
+		return nil, err
+	}
+
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return current, nil
+	}
+
+	for _, segment := range strings.Split(path, ".") {
+		name, indexes, err := parseJSONPathSegment(segment)
+		if err != nil {
+			return nil, err
+		}
+
+		if name != "" {
+			obj, ok := current.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("cannot read field %q of non-object in path %q", name, path)
+			}
+			current, ok = obj[name]
+			if !ok {
+				return nil, fmt.Errorf("field %q not found in path %q", name, path)
+			}
+		}
+
+		for _, idx := range indexes {
+			arr, ok := current.([]any)
+			if !ok {
+				return nil, fmt.Errorf("cannot index non-array with [%d] in path %q", idx, path)
+			}
+			if idx < 0 || idx >= len(arr) {
+				return nil, fmt.Errorf("index [%d] out of range (length %d) in path %q", idx, len(arr), path)
+			}
+			current = arr[idx]
+		}
+	}
+
+	return current, nil
+}
+
+// parseJSONPathSegment splits a path segment such as "items[0][2]" into its field name and indexes.
+func parseJSONPathSegment(segment string) (string, []int, error) {
+	open := strings.Index(segment, "[")
+	if open < 0 {
+		if segment == "" {
+			return "", nil, errors.New("empty segment in json path")
+		}
+		return segment, nil, nil
+	}
+
+	name := segment[:open]
+	rest := segment[open:]
+	var indexes []int
+	for rest != "" {
+		if rest[0] != '[' {
+			return "", nil, fmt.Errorf("invalid json path segment %q", segment)
+		}
+		end := strings.Index(rest, "]")
+		if end < 0 {
+			return "", nil, fmt.Errorf("unterminated index in json path segment %q", segment)
+		}
+		idx, err := strconv.Atoi(rest[1:end])
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid index in json path segment %q: %w", segment, err)
+		}
+		indexes = append(indexes, idx)
+		rest = rest[end+1:]
+	}
+
+	return name, indexes, nil
+}
+
 // printTokenEstimate writes agent token usage estimates.
 func printTokenEstimate(w io.Writer, estimate *tokens.Estimate) {
 	if estimate == nil || estimate.TotalTokens == 0 {
@@ -206,14 +452,33 @@ func printActualAgentTokenUsage(w io.Writer, estimate *tokens.Estimate) {
 
 	agentTokenUsage := estimate.Actual
 	if agentTokenUsage.InputTokens > 0 || agentTokenUsage.OutputTokens > 0 {
-		fmt.Fprintf(w, "  Agent Tokens: %d (in=%d, out=%d)\n", agentTokenUsage.TotalTokens, agentTokenUsage.InputTokens, agentTokenUsage.OutputTokens)
+		fmt.Fprintf(w, "  Agent Tokens: %d (in=%d, out=%d)%s\n", agentTokenUsage.TotalTokens, agentTokenUsage.InputTokens, agentTokenUsage.OutputTokens, formatRetries(agentTokenUsage.Retries))
 	}
 }
 
 func printJudgeTokenUsage(w io.Writer, judgeTokenUsage *tokens.Usage) {
 	if judgeTokenUsage != nil && (judgeTokenUsage.InputTokens > 0 || judgeTokenUsage.OutputTokens > 0) {
-		fmt.Fprintf(w, "  Judge Tokens: %d (in=%d, out=%d)\n", judgeTokenUsage.TotalTokens, judgeTokenUsage.InputTokens, judgeTokenUsage.OutputTokens)
+		fmt.Fprintf(w, "  Judge Tokens: %d (in=%d, out=%d)%s\n", judgeTokenUsage.TotalTokens, judgeTokenUsage.InputTokens, judgeTokenUsage.OutputTokens, formatRetries(judgeTokenUsage.Retries))
+	}
+}
+
+func printResourceUsage(w io.Writer, usage *util.ResourceUsage) {
+	if usage == nil {
+		return
+	}
+	fmt.Fprintf(w, "  Agent Resources: wall=%s, cpu=%s (user=%s, sys=%s)", formatMillis(usage.WallTimeMs), formatMillis(usage.CPUTimeMs()), formatMillis(usage.UserCPUMs), formatMillis(usage.SystemCPUMs))
+	if usage.MaxRSSBytes > 0 {
+		fmt.Fprintf(w, ", max_rss=%s", formatBytes(usage.MaxRSSBytes))
+	}
+	fmt.Fprintln(w)
+}
+
+// formatRetries returns a suffix noting how many model requests were retried, if any.
+func formatRetries(retries int64) string {
+	if retries == 0 {
+		return ""
 	}
+	return fmt.Sprintf(", %d retries", retries)
 }
 
 // printAssertions writes assertion counts and any failing assertion reasons.
@@ -255,6 +520,36 @@ func printAssertions(w io.Writer, results *eval.CompositeAssertionResult, warn *
 		for _, detail := range res.Details {
 			fmt.Fprintf(w, "      %s\n", detail)
 		}
+		if res.Expected != "" || res.Actual != "" {
+			printExpectedDiff(w, res.Expected, res.Actual)
+		}
+	}
+}
+
+// printExpectedDiff writes a colored unified diff between an assertion's expected and actual values.
+func printExpectedDiff(w io.Writer, expected, actual string) {
+	diff := unifiedDiff(expected, actual, diffContextLines)
+	if diff == "" {
+		return
+	}
+
+	red := color.New(color.FgRed)
+	green := color.New(color.FgGreen)
+	cyan := color.New(color.FgCyan)
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			fmt.Fprintf(w, "      %s\n", line)
+		case strings.HasPrefix(line, "@@"):
+			cyan.Fprintf(w, "      %s\n", line)
+		case strings.HasPrefix(line, "-"):
+			red.Fprintf(w, "      %s\n", line)
+		case strings.HasPrefix(line, "+"):
+			green.Fprintf(w, "      %s\n", line)
+		default:
+			fmt.Fprintf(w, "      %s\n", line)
+		}
 	}
 }
 
@@ -303,7 +598,7 @@ func printToolCallDetails(w io.Writer, calls []*mcpproxy.ToolCall, opts viewOpti
 		header := fmt.Sprintf("      • %s::%s (%s)", call.ServerName, call.ToolName, status)
 		fmt.Fprintln(w, header)
 
-		snippet := strings.TrimSpace(extractToolText(call))
+		snippet := strings.TrimSpace(call.ResultText())
 		if snippet == "" {
 			continue
 		}
@@ -320,42 +615,6 @@ func printToolCallDetails(w io.Writer, calls []*mcpproxy.ToolCall, opts viewOpti
 	}
 }
 
-// extractToolText flattens the mixed content of a tool call into readable text.
-func extractToolText(call *mcpproxy.ToolCall) string {
-	if call == nil || call.Result == nil {
-		return ""
-	}
-
-	var builder strings.Builder
-	for _, content := range call.Result.Content {
-		switch v := content.(type) {
-		case *mcp.TextContent:
-			builder.WriteString(v.Text)
-			if !strings.HasSuffix(v.Text, "\n") {
-				builder.WriteString("\n")
-			}
-		case *mcp.ResourceLink:
-			data, err := json.MarshalIndent(v, "", "  ")
-			if err != nil {
-				builder.WriteString(fmt.Sprintf("[ResourceLink marshal error: %v]\n", err))
-				continue
-			}
-			builder.Write(data)
-			builder.WriteString("\n")
-		case *mcp.EmbeddedResource:
-			data, err := json.MarshalIndent(v, "", "  ")
-			if err != nil {
-				builder.WriteString(fmt.Sprintf("[EmbeddedResource marshal error: %v]\n", err))
-				continue
-			}
-			builder.Write(data)
-			builder.WriteString("\n")
-		}
-	}
-
-	return builder.String()
-}
-
 // summarizeToolCalls groups tool calls by server and success outcome into a compact string.
 func summarizeToolCalls(calls []*mcpproxy.ToolCall) string {
 	if len(calls) == 0 {
@@ -434,7 +693,125 @@ type todoEntry struct {
 	Completed bool   `json:"completed"`
 }
 
+// buildTimeline returns the timeline entries for a result. Structured agent details
+// are preferred; the raw taskOutput is only parsed when they are missing.
+func buildTimeline(result *eval.EvalResult, opts viewOptions) []string {
+	if result.AgentOutput != nil && result.AgentOutput.AgentDetails != nil {
+		summaries := summarizeAgentDetails(result.AgentOutput.AgentDetails, result.TaskOutput, opts.maxOutputLines, opts.maxLineLength)
+		if len(summaries) > 0 {
+			return limitTimelineEvents(summaries, opts.maxEvents)
+		}
+	}
+
+	return summarizeTaskOutput(result.TaskOutput, opts.maxEvents, opts.maxOutputLines, opts.maxLineLength)
+}
+
+// summarizeAgentDetails converts structured agent output into timeline entries.
+// OutputSteps carry the full chronology; when only ToolCalls were recorded, the tool
+// calls are listed in order followed by the final message.
+func summarizeAgentDetails(details *task.AgentDetails, finalMessage string, maxOutputLines, maxLineLength int) []string {
+	if len(details.OutputSteps) > 0 {
+		summaries := make([]string, 0, len(details.OutputSteps))
+		for _, step := range details.OutputSteps {
+			if summary := formatOutputStep(step, maxOutputLines, maxLineLength); summary != "" {
+				summaries = append(summaries, summary)
+			}
+		}
+		return summaries
+	}
+
+	if len(details.ToolCalls) == 0 {
+		return nil
+	}
+
+	summaries := make([]string, 0, len(details.ToolCalls)+1)
+	for i := range details.ToolCalls {
+		summaries = append(summaries, formatToolCallSummary(&details.ToolCalls[i], maxOutputLines, maxLineLength))
+	}
+	if msg := normalizeWhitespace(finalMessage); msg != "" {
+		summaries = append(summaries, fmt.Sprintf("assistant: %s", wrapText(msg, maxLineLength)))
+	}
+
+	return summaries
+}
+
+// formatOutputStep converts a single structured output step into a timeline entry.
+func formatOutputStep(step agent.OutputStep, maxOutputLines, maxLineLength int) string {
+	switch step.Type {
+	case "thinking":
+		text := normalizeWhitespace(step.Content)
+		if text == "" {
+			return ""
+		}
+		return fmt.Sprintf("thought: %s", wrapText(text, maxLineLength))
+	case "message":
+		text := normalizeWhitespace(step.Content)
+		if text == "" {
+			return ""
+		}
+		return fmt.Sprintf("assistant: %s", wrapText(text, maxLineLength))
+	case "tool_call":
+		if step.ToolCall == nil {
+			return "tool call"
+		}
+		return formatToolCallSummary(step.ToolCall, maxOutputLines, maxLineLength)
+	default:
+		return fmt.Sprintf("%s event", step.Type)
+	}
+}
+
+// formatToolCallSummary renders a tool call with its status, input, and a bounded preview of its output.
+func formatToolCallSummary(call *agent.ToolCallSummary, maxOutputLines, maxLineLength int) string {
+	summary := "tool call"
+	if call.Title != "" {
+		summary = fmt.Sprintf("tool call: %s", call.Title)
+	}
+	if call.Status != "" {
+		summary = fmt.Sprintf("%s (%s)", summary, call.Status)
+	}
+	summary = wrapText(summary, maxLineLength)
+
+	if input := formatRawValue(call.RawInput); input != "" {
+		summary = fmt.Sprintf("%s\n      input: %s", summary, truncateString(input, maxLineLength))
+	}
+	if output := formatRawValue(call.RawOutput); output != "" {
+		if block := limitMultiline(output, maxOutputLines, maxLineLength); block != "" {
+			summary = fmt.Sprintf("%s\n%s", summary, indentBlock(block, "      "))
+		}
+	}
+
+	return summary
+}
+
+// formatRawValue renders a raw tool input/output value as text. Strings are used
+// verbatim and other values are encoded as compact JSON.
+func formatRawValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(val)
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprintf("%v", val)
+		}
+		return string(data)
+	}
+}
+
+// limitTimelineEvents caps the number of timeline entries, noting how many were omitted.
+func limitTimelineEvents(summaries []string, maxEvents int) []string {
+	if maxEvents > 0 && len(summaries) > maxEvents {
+		extra := len(summaries) - maxEvents
+		summaries = append(summaries[:maxEvents], fmt.Sprintf("… %d additional events omitted", extra))
+	}
+
+	return summaries
+}
+
 // summarizeTaskOutput condenses raw agent event lines into human-readable timeline entries.
+// It is the fallback used when a result carries no structured agent details.
 func summarizeTaskOutput(raw string, maxEvents, maxOutputLines, maxLineLength int) []string {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
@@ -448,12 +825,7 @@ func summarizeTaskOutput(raw string, maxEvents, maxOutputLines, maxLineLength in
 		summaries = summarizePlaintextTaskOutput(raw, maxOutputLines, maxLineLength)
 	}
 
-	if maxEvents > 0 && len(summaries) > maxEvents {
-		extra := len(summaries) - maxEvents
-		summaries = append(summaries[:maxEvents], fmt.Sprintf("… %d additional events omitted", extra))
-	}
-
-	return summaries
+	return limitTimelineEvents(summaries, maxEvents)
 }
 
 // formatEvent converts an agent event into a concise timeline string, if applicable.
@@ -1005,18 +1377,53 @@ func wrapText(s string, width int) string {
 	return strings.Join(lines, "\n")
 }
 
-// loadTaskPrompt returns the prompt text defined in the task manifest, if present.
-func loadTaskPrompt(taskPath string) string {
+// resultPrompt returns the prompt the agent of result was prompted with: the
+// prompt recorded by the run, or for results written before prompts were
+// recorded, its paraphrase in prompt robustness runs, and the prompt of the
+// task manifest in the locale of the result otherwise.
+func resultPrompt(result *eval.EvalResult) string {
+	if result.AgentOutput != nil && result.AgentOutput.AgentDetails != nil && result.AgentOutput.AgentDetails.Prompt != "" {
+		return strings.TrimSpace(result.AgentOutput.AgentDetails.Prompt)
+	}
+	if result.PromptParaphrase != "" {
+		return strings.TrimSpace(result.PromptParaphrase)
+	}
+	return loadTaskPrompt(result.TaskPath, result.TaskName, result.Locale)
+}
+
+// loadTaskPrompt returns the prompt text defined in the task manifest, if
+// present, selecting the prompt of locale for tasks with a prompt per locale
+// and the prompt of the instance named taskName for task templates.
+func loadTaskPrompt(taskPath, taskName, locale string) string {
 	if taskPath == "" {
 		return ""
 	}
 
 	taskConfig, err := task.FromFile(taskPath)
-	if err != nil || taskConfig == nil || taskConfig.Spec == nil || taskConfig.Spec.Prompt == nil || taskConfig.Spec.Prompt.IsEmpty() {
+	if err != nil || taskConfig == nil || taskConfig.Spec == nil {
+		return ""
+	}
+	if len(taskConfig.Spec.Parameters) > 0 {
+		if taskConfig = taskInstance(taskConfig, taskName); taskConfig == nil {
+			return ""
+		}
+	}
+	if taskConfig.Spec.Prompt.IsEmpty() {
 		return ""
 	}
 
-	text, err := taskConfig.Spec.Prompt.GetValue()
+	prompt := taskConfig.Spec.Prompt
+	if prompt.HasLocales() {
+		if locale == "" {
+			locale = task.DefaultLocale
+		}
+		var ok bool
+		if prompt, ok = prompt.ForLocale(locale); !ok {
+			return ""
+		}
+	}
+
+	text, err := prompt.GetValue()
 	if err != nil {
 		return ""
 	}
@@ -1024,6 +1431,20 @@ func loadTaskPrompt(taskPath string) string {
 	return strings.TrimSpace(text)
 }
 
+// taskInstance returns the instance of the task template named name, or nil.
+func taskInstance(template *task.TaskConfig, name string) *task.TaskConfig {
+	instances, err := template.Instances()
+	if err != nil {
+		return nil
+	}
+	for _, instance := range instances {
+		if instance.Metadata.Name == name {
+			return instance
+		}
+	}
+	return nil
+}
+
 // printMultilineField writes a label/value pair, indenting multi-line values neatly.
 func printMultilineField(w io.Writer, label, value string) {
 	value = strings.TrimRight(value, "\n")
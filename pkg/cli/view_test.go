@@ -5,7 +5,11 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
 	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
 )
 
 func TestSummarizeTaskOutput(t *testing.T) {
@@ -177,6 +181,31 @@ func TestViewCommandWithTaskFilter(t *testing.T) {
 	}
 }
 
+func TestViewCommandAttempts(t *testing.T) {
+	filePath := createTestResultsFile(t, []*eval.EvalResult{
+		{TaskName: "task-1", TaskPath: "task-1.yaml", TaskPassed: true, AllAssertionsPassed: true, RunIndex: 0, TotalRuns: 2},
+		{TaskName: "task-1", TaskPath: "task-1.yaml", RunIndex: 1, TotalRuns: 2},
+	})
+
+	cmd := NewViewCmd()
+	cmd.SetArgs([]string{filePath, "--failed-only"})
+
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("view command failed: %v", err)
+	}
+	for _, want := range []string{"Run: 2/2", "Attempts:", "✓✗", "flaky", "pass@1: 50.0%, pass@2: 100.0%, flakiness: 100.0% (1/1 tasks flaky)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "Run: 1/2") {
+		t.Errorf("--failed-only should hide the passed run:\n%s", buf.String())
+	}
+}
+
 func TestViewCommandFileNotFound(t *testing.T) {
 	cmd := NewViewCmd()
 	cmd.SetArgs([]string{"/nonexistent/path/results.json"})
@@ -259,3 +288,209 @@ func TestSummarizeToolCalls(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildTimeline(t *testing.T) {
+	opts := viewOptions{showTimeline: true, maxOutputLines: 6, maxLineLength: 100}
+
+	tests := []struct {
+		name          string
+		result        *eval.EvalResult
+		maxEvents     int
+		expectedItems []string
+	}{
+		{
+			name: "structured output steps",
+			result: &eval.EvalResult{
+				TaskOutput: "Thinking:\nthis text should be ignored",
+				AgentOutput: &task.PhaseOutput{
+					AgentDetails: &task.AgentDetails{
+						OutputSteps: []agent.OutputStep{
+							{Type: "thinking", Content: "I need to list pods."},
+							{Type: "tool_call", ToolCall: &agent.ToolCallSummary{
+								Title:     "pods_list",
+								Status:    "completed",
+								RawInput:  map[string]any{"namespace": "default"},
+								RawOutput: "pod-1 Running",
+							}},
+							{Type: "message", Content: "All pods are running."},
+						},
+					},
+				},
+			},
+			expectedItems: []string{
+				"thought: I need to list pods.",
+				"tool call: pods_list (completed)\n      input: {\"namespace\":\"default\"}\n      pod-1 Running",
+				"assistant: All pods are running.",
+			},
+		},
+		{
+			name: "tool calls only",
+			result: &eval.EvalResult{
+				TaskOutput: "Done.",
+				AgentOutput: &task.PhaseOutput{
+					AgentDetails: &task.AgentDetails{
+						ToolCalls: []agent.ToolCallSummary{
+							{Title: "pods_get", Status: "failed"},
+						},
+					},
+				},
+			},
+			expectedItems: []string{
+				"tool call: pods_get (failed)",
+				"assistant: Done.",
+			},
+		},
+		{
+			name: "falls back to task output",
+			result: &eval.EvalResult{
+				TaskOutput: "Thinking:\nShort thought.\n",
+			},
+			expectedItems: []string{
+				"thought: Short thought.",
+			},
+		},
+		{
+			name: "event limit applies to structured steps",
+			result: &eval.EvalResult{
+				AgentOutput: &task.PhaseOutput{
+					AgentDetails: &task.AgentDetails{
+						OutputSteps: []agent.OutputStep{
+							{Type: "thinking", Content: "one"},
+							{Type: "thinking", Content: "two"},
+							{Type: "thinking", Content: "three"},
+						},
+					},
+				},
+			},
+			maxEvents: 1,
+			expectedItems: []string{
+				"thought: one",
+				"… 2 additional events omitted",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := opts
+			o.maxEvents = tt.maxEvents
+			got := buildTimeline(tt.result, o)
+			if len(got) != len(tt.expectedItems) {
+				t.Fatalf("buildTimeline() returned %d entries, want %d.\nGot:\n%v", len(got), len(tt.expectedItems), strings.Join(got, "\n---\n"))
+			}
+			for i, want := range tt.expectedItems {
+				if got[i] != want {
+					t.Errorf("entry %d = %q, want %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractJSONPath(t *testing.T) {
+	result := &eval.EvalResult{
+		TaskName:   "task-1",
+		TaskPassed: true,
+		AssertionResults: &eval.CompositeAssertionResult{
+			ToolsUsed: &eval.SingleAssertionResult{Passed: false, Reason: "Tool not called"},
+		},
+		CallHistory: &mcpproxy.CallHistory{
+			ToolCalls: []*mcpproxy.ToolCall{
+				{CallRecord: mcpproxy.CallRecord{ServerName: "server1", Success: true}, ToolName: "tool1"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    any
+		wantErr bool
+	}{
+		{name: "top level field", path: "taskName", want: "task-1"},
+		{name: "leading dollar", path: "$.taskPassed", want: true},
+		{name: "nested field", path: "assertionResults.toolsUsed.reason", want: "Tool not called"},
+		{name: "array index", path: "callHistory.ToolCalls[0].name", want: "tool1"},
+		{name: "missing field", path: "assertionResults.nope", wantErr: true},
+		{name: "index out of range", path: "callHistory.ToolCalls[3]", wantErr: true},
+		{name: "index on object", path: "assertionResults[0]", wantErr: true},
+		{name: "bad index", path: "callHistory.ToolCalls[x]", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractJSONPath(result, tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("extractJSONPath(%q) expected error, got %v", tt.path, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extractJSONPath(%q) failed: %v", tt.path, err)
+			}
+			if got != tt.want {
+				t.Errorf("extractJSONPath(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestViewCommandFailedOnly(t *testing.T) {
+	filePath := createTestResultsFile(t, sampleResults())
+
+	cmd := NewViewCmd()
+	cmd.SetArgs([]string{filePath, "--failed-only", "--json-path", "taskName"})
+
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("view command with --failed-only failed: %v", err)
+	}
+
+	got := strings.Fields(buf.String())
+	want := []string{"task-2", "task-3"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("view --failed-only printed %v, want %v", got, want)
+	}
+}
+
+func TestViewCommandPhase(t *testing.T) {
+	evalResults := sampleResults()
+	evalResults[0].VerifyOutput = &task.PhaseOutput{
+		Success: true,
+		Steps:   []*steps.StepOutput{{Success: true, Message: "verify passed"}},
+	}
+	filePath := createTestResultsFile(t, evalResults[:1])
+
+	cmd := NewViewCmd()
+	cmd.SetArgs([]string{filePath, "--phase", "verify"})
+
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("view command with --phase failed: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "verify passed") {
+		t.Errorf("expected verify output in phase view, got:\n%s", output)
+	}
+	if strings.Contains(output, "Assertions") {
+		t.Errorf("phase view should not include assertions, got:\n%s", output)
+	}
+}
+
+func TestViewCommandInvalidPhase(t *testing.T) {
+	filePath := createTestResultsFile(t, sampleResults())
+
+	cmd := NewViewCmd()
+	cmd.SetArgs([]string{filePath, "--phase", "teardown"})
+	cmd.SetOut(new(bytes.Buffer))
+
+	if err := cmd.Execute(); err == nil {
+		t.Error("view command should fail with an invalid phase")
+	}
+}
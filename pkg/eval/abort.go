@@ -0,0 +1,114 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+// AbortSummary reports whether a run was aborted early after too many failed
+// task runs (check --fail-fast or --max-failures).
+type AbortSummary struct {
+	MaxFailures int  `json:"maxFailures"`
+	Failures    int  `json:"failures"`
+	Aborted     bool `json:"aborted"`
+
+	// SkippedRuns is the number of task runs that were not started because
+	// the run was aborted
+	SkippedRuns int `json:"skippedRuns,omitempty"`
+
+	// CancelledRuns is the number of task runs that were cancelled while
+	// running because the run was aborted
+	CancelledRuns int `json:"cancelledRuns,omitempty"`
+}
+
+// abortTracker counts failed task runs and aborts the run once maxFailures
+// is reached, cancelling the task runs in flight. It is safe for concurrent
+// use, and a nil tracker never aborts.
+type abortTracker struct {
+	maxFailures int
+	cancel      context.CancelFunc
+
+	mu            sync.Mutex
+	failures      int
+	aborted       bool
+	skippedRuns   int
+	cancelledRuns int
+}
+
+// newAbortTracker returns a tracker that calls cancel once maxFailures task
+// runs failed, or nil if maxFailures is 0.
+func newAbortTracker(maxFailures int, cancel context.CancelFunc) *abortTracker {
+	if maxFailures <= 0 {
+		return nil
+	}
+	return &abortTracker{maxFailures: maxFailures, cancel: cancel}
+}
+
+// record counts a completed run if it failed. Skipped runs, runs of
+// quarantined tasks and expected failures never count, since they can't gate
+// a run.
+func (a *abortTracker) record(result *EvalResult) {
+	if a == nil || result.TaskPassed || result.Skipped || result.State == task.StateQuarantined || result.ExpectFailure {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.failures++
+	if !a.aborted && a.failures >= a.maxFailures {
+		a.aborted = true
+		a.cancel()
+	}
+}
+
+// isAborted reports whether the run was aborted.
+func (a *abortTracker) isAborted() bool {
+	if a == nil {
+		return false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.aborted
+}
+
+// skip records that a run was not started, and returns the skipped result for it.
+func (a *abortTracker) skip(tc taskConfig) *EvalResult {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.skippedRuns++
+	return newSkippedResult(tc, SkipReasonAborted, a.messageLocked("run aborted"))
+}
+
+// cancelled records that a run was cancelled by the abort, and returns the
+// skipped result that replaces its result.
+func (a *abortTracker) cancelled(tc taskConfig) *EvalResult {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cancelledRuns++
+	return newSkippedResult(tc, SkipReasonAborted, a.messageLocked("run cancelled"))
+}
+
+func (a *abortTracker) messageLocked(what string) string {
+	return fmt.Sprintf("%s after %d failed task run(s)", what, a.failures)
+}
+
+// summary returns the abort state, or nil for a nil tracker.
+func (a *abortTracker) summary() *AbortSummary {
+	if a == nil {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return &AbortSummary{
+		MaxFailures:   a.maxFailures,
+		Failures:      a.failures,
+		Aborted:       a.aborted,
+		SkippedRuns:   a.skippedRuns,
+		CancelledRuns: a.cancelledRuns,
+	}
+}
@@ -0,0 +1,116 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAbortTracker(t *testing.T) {
+	failed := &EvalResult{TaskName: "failed"}
+	passed := &EvalResult{TaskName: "passed", TaskPassed: true}
+	skipped := &EvalResult{TaskName: "skipped", Skipped: true, SkipReason: SkipReasonRequirementsUnmet}
+	quarantined := &EvalResult{TaskName: "quarantined", State: task.StateQuarantined}
+	xfailed := &EvalResult{TaskName: "xfailed", ExpectFailure: true}
+
+	tests := map[string]struct {
+		maxFailures   int
+		results       []*EvalResult
+		expectNil     bool
+		expectAborted bool
+		expectFailed  int
+	}{
+		"no limit": {
+			results:   []*EvalResult{failed},
+			expectNil: true,
+		},
+		"fail fast": {
+			maxFailures:   1,
+			results:       []*EvalResult{passed, failed},
+			expectAborted: true,
+			expectFailed:  1,
+		},
+		"under the limit": {
+			maxFailures:  3,
+			results:      []*EvalResult{failed, passed, failed},
+			expectFailed: 2,
+		},
+		"limit reached": {
+			maxFailures:   2,
+			results:       []*EvalResult{failed, failed, failed},
+			expectAborted: true,
+			expectFailed:  3,
+		},
+		"skipped, quarantined and expected failures don't count": {
+			maxFailures: 1,
+			results:     []*EvalResult{skipped, quarantined, xfailed},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			tracker := newAbortTracker(tc.maxFailures, cancel)
+			if tc.expectNil {
+				assert.Nil(t, tracker)
+				tracker.record(failed)
+				assert.False(t, tracker.isAborted())
+				assert.Nil(t, tracker.summary())
+				return
+			}
+			require.NotNil(t, tracker)
+
+			for _, result := range tc.results {
+				tracker.record(result)
+			}
+
+			assert.Equal(t, tc.expectAborted, tracker.isAborted())
+			assert.Equal(t, tc.expectAborted, ctx.Err() != nil, "the tasks are cancelled when the run is aborted")
+			summary := tracker.summary()
+			assert.Equal(t, tc.maxFailures, summary.MaxFailures)
+			assert.Equal(t, tc.expectFailed, summary.Failures)
+			assert.Equal(t, tc.expectAborted, summary.Aborted)
+		})
+	}
+}
+
+func TestExecuteTaskSkipsAfterAbort(t *testing.T) {
+	var events []ProgressEvent
+	runner := &evalRunner{
+		spec:              &EvalSpec{},
+		runs:              2,
+		runsExplicitlySet: true,
+		abort:             newAbortTracker(1, func() {}),
+		progressCallback:  func(e ProgressEvent) { events = append(events, e) },
+	}
+	runner.abort.record(&EvalResult{TaskName: "failed"})
+
+	tc := taskConfig{
+		path: "task.yaml",
+		spec: &task.TaskConfig{Metadata: task.TaskMetadata{Name: "not-started", Difficulty: "easy"}},
+	}
+
+	// The agent runner is never used because both runs are skipped
+	results := runner.executeTask(context.Background(), nil, tc)
+
+	require.Len(t, results, 2)
+	for i, result := range results {
+		assert.True(t, result.Skipped)
+		assert.Equal(t, SkipReasonAborted, result.SkipReason)
+		assert.Equal(t, "skipped: run aborted after 1 failed task run(s)", result.TaskError)
+		assert.False(t, result.TaskPassed)
+		assert.False(t, result.SkipReason.CountsAsNotPassed())
+		assert.Equal(t, "not-started", result.TaskName)
+		assert.Equal(t, i, result.RunIndex)
+		assert.Equal(t, 2, result.TotalRuns)
+	}
+
+	require.Len(t, events, 2)
+	assert.Equal(t, EventTaskSkipped, events[0].Type)
+	assert.Equal(t, 2, runner.abort.summary().SkippedRuns)
+}
@@ -0,0 +1,268 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/llmagent"
+)
+
+const (
+	// DefaultLatencySpikeFactor is how many times slower than usual a model
+	// request must be to count as a latency spike
+	DefaultLatencySpikeFactor = 3.0
+
+	// DefaultAdaptiveInterval is how long model calls must be healthy before
+	// another parallel worker is added
+	DefaultAdaptiveInterval = 30 * time.Second
+
+	// adaptiveDecreaseInterval keeps a burst of rate limited requests from
+	// removing more than one step of workers
+	adaptiveDecreaseInterval = 5 * time.Second
+
+	// latencyWarmup is the number of healthy requests whose latency is
+	// averaged before latency spikes are detected
+	latencyWarmup = 5
+)
+
+// AdaptiveParallelismConfig makes parallel tasks run with fewer workers while
+// the model calls of agents and the LLM judge are rate limited or slow, and
+// with more again, up to --parallel, once they are healthy.
+type AdaptiveParallelismConfig struct {
+	// MinWorkers is the lowest number of parallel workers (default: 1)
+	MinWorkers int `json:"minWorkers,omitempty"`
+
+	// LatencySpikeFactor is how many times slower than usual a model request
+	// must be to count as a latency spike (default: 3)
+	LatencySpikeFactor float64 `json:"latencySpikeFactor,omitempty"`
+
+	// Interval is how long model calls must be healthy before another worker
+	// is added, e.g. "30s" (default: 30s)
+	Interval string `json:"interval,omitempty"`
+}
+
+// Validate checks that the settings are not negative and that the interval
+// is a duration.
+func (c *AdaptiveParallelismConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.MinWorkers < 0 {
+		return fmt.Errorf("minWorkers must be >= 0, got %d", c.MinWorkers)
+	}
+	if c.LatencySpikeFactor != 0 && c.LatencySpikeFactor <= 1 {
+		return fmt.Errorf("latencySpikeFactor must be > 1, got %g", c.LatencySpikeFactor)
+	}
+	if _, err := c.interval(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *AdaptiveParallelismConfig) interval() (time.Duration, error) {
+	if c.Interval == "" {
+		return DefaultAdaptiveInterval, nil
+	}
+	d, err := time.ParseDuration(c.Interval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval %q: %w", c.Interval, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("interval must be positive, got %s", c.Interval)
+	}
+	return d, nil
+}
+
+// AdaptiveParallelismSummary reports how many parallel workers were applied
+// over the run.
+type AdaptiveParallelismSummary struct {
+	MinWorkers int `json:"minWorkers"`
+	MaxWorkers int `json:"maxWorkers"`
+
+	// Changes lists the number of workers over time, starting with
+	// MaxWorkers when the parallel tasks started
+	Changes []ParallelismChange `json:"changes"`
+}
+
+// ParallelismChange is a change of the number of parallel workers.
+type ParallelismChange struct {
+	Time    time.Time `json:"time"`
+	Workers int       `json:"workers"`
+	Reason  string    `json:"reason"`
+}
+
+// adaptiveParallelism limits the number of tasks running in parallel. The
+// limit is halved when model requests are rate limited, lowered by one on
+// latency spikes, and raised by one after each interval without either. It is
+// safe for concurrent use.
+type adaptiveParallelism struct {
+	min, max    int
+	spikeFactor float64
+	interval    time.Duration
+	now         func() time.Time
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	limit   int
+	running int
+
+	// healthySince is when model requests were last rate limited or slow,
+	// or when the limit last changed
+	healthySince time.Time
+	lastDecrease time.Time
+
+	// latency is the moving average of the latency of healthy requests
+	latency  time.Duration
+	requests int
+
+	started bool
+	changes []ParallelismChange
+}
+
+var _ llmagent.HealthObserver = &adaptiveParallelism{}
+
+// newAdaptiveParallelism returns a limiter of at most maxWorkers parallel tasks.
+func newAdaptiveParallelism(cfg *AdaptiveParallelismConfig, maxWorkers int) (*adaptiveParallelism, error) {
+	if cfg == nil {
+		cfg = &AdaptiveParallelismConfig{}
+	}
+	interval, err := cfg.interval()
+	if err != nil {
+		return nil, err
+	}
+
+	a := &adaptiveParallelism{
+		min:         max(1, min(cfg.MinWorkers, maxWorkers)),
+		max:         maxWorkers,
+		spikeFactor: cfg.LatencySpikeFactor,
+		interval:    interval,
+		now:         time.Now,
+		limit:       maxWorkers,
+	}
+	if a.spikeFactor == 0 {
+		a.spikeFactor = DefaultLatencySpikeFactor
+	}
+	a.cond = sync.NewCond(&a.mu)
+	return a, nil
+}
+
+// start records the initial number of workers when the first group of
+// parallel tasks starts. Later groups continue with the current limit.
+func (a *adaptiveParallelism) start() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.started {
+		return
+	}
+	a.started = true
+	a.setLimit(a.limit, "start")
+}
+
+// acquire blocks until another task may run.
+func (a *adaptiveParallelism) acquire() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for a.running >= a.limit {
+		a.cond.Wait()
+	}
+	a.running++
+}
+
+// release is called when a task acquired with acquire has finished.
+func (a *adaptiveParallelism) release() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.running--
+	a.maybeIncrease()
+	a.cond.Broadcast()
+}
+
+// ObserveRequest adapts the limit to a model request of an agent or the judge.
+func (a *adaptiveParallelism) ObserveRequest(latency time.Duration, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch {
+	case llmagent.IsRateLimited(err):
+		a.decrease(a.limit/2, "rate limited")
+	case err != nil:
+		// Other errors say nothing about the load of the provider
+	case a.requests >= latencyWarmup && float64(latency) > a.spikeFactor*float64(a.latency):
+		a.decrease(a.limit-1, fmt.Sprintf("latency spike (%s, usually %s)", latency.Round(time.Millisecond), a.latency.Round(time.Millisecond)))
+	default:
+		a.requests++
+		if a.requests == 1 {
+			a.latency = latency
+		} else {
+			a.latency += (latency - a.latency) / 5
+		}
+		a.maybeIncrease()
+	}
+}
+
+// observeResult adapts the limit to a finished task run, whose agent may have
+// failed on rate limits outside of the model calls that are observed, e.g.
+// for agents running as a subprocess.
+func (a *adaptiveParallelism) observeResult(result *EvalResult) {
+	if !result.AgentExecutionError && !result.JudgeError {
+		return
+	}
+	msg := strings.ToLower(result.TaskError + " " + result.TaskJudgeError)
+	if strings.Contains(msg, "rate limit") || strings.Contains(msg, "too many requests") || strings.Contains(msg, "429") {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		a.decrease(a.limit/2, "task failed on rate limits")
+	}
+}
+
+// decrease lowers the limit to limit, unless it was lowered just before. a.mu
+// must be held.
+func (a *adaptiveParallelism) decrease(limit int, reason string) {
+	now := a.now()
+	a.healthySince = now
+	if !a.lastDecrease.IsZero() && now.Sub(a.lastDecrease) < adaptiveDecreaseInterval {
+		return
+	}
+	limit = max(limit, a.min)
+	if limit >= a.limit {
+		return
+	}
+	a.lastDecrease = now
+	a.setLimit(limit, reason)
+}
+
+// maybeIncrease raises the limit by one if model calls were healthy for an
+// interval. a.mu must be held.
+func (a *adaptiveParallelism) maybeIncrease() {
+	if a.limit >= a.max || a.now().Sub(a.healthySince) < a.interval {
+		return
+	}
+	a.setLimit(a.limit+1, "healthy")
+	a.cond.Broadcast()
+}
+
+func (a *adaptiveParallelism) setLimit(limit int, reason string) {
+	a.limit = limit
+	a.healthySince = a.now()
+	a.changes = append(a.changes, ParallelismChange{Time: a.healthySince, Workers: limit, Reason: reason})
+}
+
+// summary reports the applied limits, or nil if a is nil or no parallel
+// tasks ran.
+func (a *adaptiveParallelism) summary() *AdaptiveParallelismSummary {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.changes) == 0 {
+		return nil
+	}
+	return &AdaptiveParallelismSummary{
+		MinWorkers: a.min,
+		MaxWorkers: a.max,
+		Changes:    append([]ParallelismChange(nil), a.changes...),
+	}
+}
@@ -0,0 +1,253 @@
+package eval
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"charm.land/fantasy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveParallelismConfigValidate(t *testing.T) {
+	tests := map[string]struct {
+		cfg         *AdaptiveParallelismConfig
+		errContains string
+	}{
+		"nil config":     {},
+		"defaults":       {cfg: &AdaptiveParallelismConfig{}},
+		"all set":        {cfg: &AdaptiveParallelismConfig{MinWorkers: 2, LatencySpikeFactor: 2.5, Interval: "1m"}},
+		"negative min":   {cfg: &AdaptiveParallelismConfig{MinWorkers: -1}, errContains: "minWorkers"},
+		"spike factor 1": {cfg: &AdaptiveParallelismConfig{LatencySpikeFactor: 1}, errContains: "latencySpikeFactor"},
+		"bad interval":   {cfg: &AdaptiveParallelismConfig{Interval: "soon"}, errContains: "invalid interval"},
+		"zero interval":  {cfg: &AdaptiveParallelismConfig{Interval: "0s"}, errContains: "must be positive"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestAdaptive(t *testing.T, cfg *AdaptiveParallelismConfig, maxWorkers int) (*adaptiveParallelism, *fakeClock) {
+	t.Helper()
+	a, err := newAdaptiveParallelism(cfg, maxWorkers)
+	require.NoError(t, err)
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	a.now = clock.Now
+	a.start()
+	return a, clock
+}
+
+func workers(s *AdaptiveParallelismSummary) []int {
+	var w []int
+	for _, c := range s.Changes {
+		w = append(w, c.Workers)
+	}
+	return w
+}
+
+func TestAdaptiveParallelism(t *testing.T) {
+	rateLimited := &fantasy.ProviderError{StatusCode: http.StatusTooManyRequests}
+
+	tests := map[string]struct {
+		cfg         *AdaptiveParallelismConfig
+		maxWorkers  int
+		run         func(a *adaptiveParallelism, clock *fakeClock)
+		wantWorkers []int
+		wantReason  string
+	}{
+		"healthy": {
+			maxWorkers: 4,
+			run: func(a *adaptiveParallelism, clock *fakeClock) {
+				clock.Advance(time.Minute)
+				a.ObserveRequest(time.Second, nil)
+			},
+			wantWorkers: []int{4},
+		},
+		"started once per run": {
+			maxWorkers: 4,
+			run: func(a *adaptiveParallelism, clock *fakeClock) {
+				clock.Advance(time.Second)
+				a.start()
+			},
+			wantWorkers: []int{4},
+		},
+		"rate limited halves": {
+			maxWorkers: 8,
+			run: func(a *adaptiveParallelism, clock *fakeClock) {
+				a.ObserveRequest(time.Second, rateLimited)
+			},
+			wantWorkers: []int{8, 4},
+			wantReason:  "rate limited",
+		},
+		"burst of rate limits lowers once": {
+			maxWorkers: 8,
+			run: func(a *adaptiveParallelism, clock *fakeClock) {
+				for range 5 {
+					a.ObserveRequest(time.Second, rateLimited)
+					clock.Advance(time.Second)
+				}
+				clock.Advance(5 * time.Second)
+				a.ObserveRequest(time.Second, rateLimited)
+			},
+			wantWorkers: []int{8, 4, 2},
+		},
+		"not below min workers": {
+			cfg:        &AdaptiveParallelismConfig{MinWorkers: 3},
+			maxWorkers: 4,
+			run: func(a *adaptiveParallelism, clock *fakeClock) {
+				a.ObserveRequest(time.Second, rateLimited)
+				clock.Advance(time.Minute)
+				a.ObserveRequest(time.Second, rateLimited)
+			},
+			wantWorkers: []int{4, 3},
+		},
+		"other errors are ignored": {
+			maxWorkers: 4,
+			run: func(a *adaptiveParallelism, clock *fakeClock) {
+				a.ObserveRequest(time.Second, &fantasy.ProviderError{StatusCode: http.StatusInternalServerError})
+				a.ObserveRequest(time.Second, errors.New("connection reset"))
+			},
+			wantWorkers: []int{4},
+		},
+		"latency spike": {
+			maxWorkers: 4,
+			run: func(a *adaptiveParallelism, clock *fakeClock) {
+				for range latencyWarmup {
+					a.ObserveRequest(time.Second, nil)
+				}
+				a.ObserveRequest(2*time.Second, nil)
+				a.ObserveRequest(4*time.Second, nil)
+			},
+			wantWorkers: []int{4, 3},
+			wantReason:  "latency spike (4s, usually 1.2s)",
+		},
+		"no latency spike during warmup": {
+			maxWorkers: 4,
+			run: func(a *adaptiveParallelism, clock *fakeClock) {
+				a.ObserveRequest(time.Second, nil)
+				a.ObserveRequest(10*time.Second, nil)
+			},
+			wantWorkers: []int{4},
+		},
+		"ramps up when healthy": {
+			cfg:        &AdaptiveParallelismConfig{Interval: "10s"},
+			maxWorkers: 4,
+			run: func(a *adaptiveParallelism, clock *fakeClock) {
+				a.ObserveRequest(time.Second, rateLimited)
+				clock.Advance(5 * time.Second)
+				a.ObserveRequest(time.Second, nil)
+				clock.Advance(5 * time.Second)
+				a.ObserveRequest(time.Second, nil)
+				clock.Advance(10 * time.Second)
+				a.acquire()
+				a.release()
+				clock.Advance(10 * time.Second)
+				a.ObserveRequest(time.Second, nil)
+			},
+			wantWorkers: []int{4, 2, 3, 4},
+			wantReason:  "healthy",
+		},
+		"rate limits restart the interval": {
+			cfg:        &AdaptiveParallelismConfig{Interval: "10s"},
+			maxWorkers: 4,
+			run: func(a *adaptiveParallelism, clock *fakeClock) {
+				a.ObserveRequest(time.Second, rateLimited)
+				clock.Advance(8 * time.Second)
+				a.ObserveRequest(time.Second, rateLimited)
+				clock.Advance(8 * time.Second)
+				a.ObserveRequest(time.Second, nil)
+			},
+			wantWorkers: []int{4, 2, 1},
+		},
+		"task failed on rate limits": {
+			maxWorkers: 4,
+			run: func(a *adaptiveParallelism, clock *fakeClock) {
+				a.observeResult(&EvalResult{AgentExecutionError: true, TaskError: "agent exited: API Error: 429 Too Many Requests"})
+			},
+			wantWorkers: []int{4, 2},
+			wantReason:  "task failed on rate limits",
+		},
+		"task failed otherwise": {
+			maxWorkers: 4,
+			run: func(a *adaptiveParallelism, clock *fakeClock) {
+				a.observeResult(&EvalResult{AgentExecutionError: true, TaskError: "agent exited with code 1"})
+				a.observeResult(&EvalResult{TaskError: "rate limit assertion failed"})
+			},
+			wantWorkers: []int{4},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			a, clock := newTestAdaptive(t, tc.cfg, tc.maxWorkers)
+			tc.run(a, clock)
+
+			summary := a.summary()
+			require.NotNil(t, summary)
+			assert.Equal(t, tc.maxWorkers, summary.MaxWorkers)
+			assert.Equal(t, tc.wantWorkers, workers(summary))
+			if tc.wantReason != "" {
+				assert.Equal(t, tc.wantReason, summary.Changes[len(summary.Changes)-1].Reason)
+			}
+		})
+	}
+}
+
+func TestAdaptiveParallelismLimitsRunningTasks(t *testing.T) {
+	a, _ := newTestAdaptive(t, nil, 4)
+	a.ObserveRequest(time.Second, &fantasy.ProviderError{StatusCode: http.StatusTooManyRequests})
+
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.acquire()
+			defer a.release()
+
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+}
+
+func TestAdaptiveParallelismSummaryWithoutParallelTasks(t *testing.T) {
+	var nilAdaptive *adaptiveParallelism
+	assert.Nil(t, nilAdaptive.summary())
+
+	a, err := newAdaptiveParallelism(nil, 4)
+	require.NoError(t, err)
+	assert.Nil(t, a.summary())
+}
@@ -0,0 +1,106 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+)
+
+// AllowedToolsMode sets which tools of the MCP servers the agent is allowed to call.
+type AllowedToolsMode string
+
+const (
+	// AllowedToolsAll allows every tool the MCP config allows (the default),
+	// for measuring agents against the open tool catalogue
+	AllowedToolsAll AllowedToolsMode = "all"
+	// AllowedToolsAssertions only allows the tools matched by the toolsUsed and
+	// requireAny assertions of the task, for measuring agents that are guided to
+	// the relevant tools. Tasks without such assertions allow every tool.
+	AllowedToolsAssertions AllowedToolsMode = "assertions"
+)
+
+// Validate checks that the mode is known.
+func (m AllowedToolsMode) Validate() error {
+	switch m {
+	case "", AllowedToolsAll, AllowedToolsAssertions:
+		return nil
+	}
+	return fmt.Errorf("invalid allowedTools %q: must be %q or %q", m, AllowedToolsAll, AllowedToolsAssertions)
+}
+
+// allowedToolAssertions returns the toolsUsed and requireAny assertions of all
+// the assertion sets of a task.
+func allowedToolAssertions(sets []*TaskAssertions) []ToolAssertion {
+	var assertions []ToolAssertion
+	for _, set := range sets {
+		if set == nil {
+			continue
+		}
+		assertions = append(assertions, set.ToolsUsed...)
+		assertions = append(assertions, set.RequireAny...)
+	}
+	return assertions
+}
+
+// allowedToolsFor returns the allowedTools mode to run tc with.
+func (r *evalRunner) allowedToolsFor(tc taskConfig) AllowedToolsMode {
+	if tc.allowedTools != "" {
+		return tc.allowedTools
+	}
+	return r.allowedTools
+}
+
+// restrictedTools returns the assertions matching the tools tc may call, or
+// nil if it may call every tool.
+func (r *evalRunner) restrictedTools(tc taskConfig) []ToolAssertion {
+	if r.allowedToolsFor(tc) != AllowedToolsAssertions {
+		return nil
+	}
+	return allowedToolAssertions(tc.assertions)
+}
+
+// catalogueVariants returns the variants of tc to run. Catalogue ablation
+// runs tasks with tool assertions with only those tools allowed and with all
+// tools allowed; other tasks only run with all tools.
+func (r *evalRunner) catalogueVariants(tc taskConfig) []taskConfig {
+	if !r.catalogueAblation {
+		return []taskConfig{tc}
+	}
+
+	full := tc
+	full.allowedTools = AllowedToolsAll
+	if len(allowedToolAssertions(tc.assertions)) == 0 {
+		return []taskConfig{full}
+	}
+
+	required := tc
+	required.allowedTools = AllowedToolsAssertions
+	return []taskConfig{required, full}
+}
+
+// allowedToolsFilter returns the filter of the tools of the proxy servers of
+// a task run that only exposes the tools matched by assertions. Call history
+// is recorded as usual.
+func allowedToolsFilter(assertions []ToolAssertion) mcpproxy.ToolFilter {
+	return func(server, tool string) bool {
+		for _, assertion := range assertions {
+			if matchesTool(server, tool, assertion) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// allowedToolNames lists the allowed tools of the servers of manager as
+// "server/tool".
+func allowedToolNames(ctx context.Context, manager mcpproxy.ServerManager) []string {
+	var names []string
+	for _, s := range manager.GetMcpServers() {
+		for _, t := range s.GetAllowedTools(ctx) {
+			names = append(names, s.GetName()+"/"+t.Name)
+		}
+	}
+	return names
+}
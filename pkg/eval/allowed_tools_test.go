@@ -0,0 +1,162 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowedToolsFilter(t *testing.T) {
+	tools := [][2]string{
+		{"kubernetes", "pods_list"}, {"kubernetes", "pods_get"}, {"kubernetes", "pods_delete"},
+		{"kubernetes", "namespaces_list"}, {"helm", "helm_list"},
+	}
+
+	tests := map[string]struct {
+		assertions []ToolAssertion
+		want       []string
+	}{
+		"tool": {
+			assertions: []ToolAssertion{{Server: "kubernetes", Tool: "pods_list"}},
+			want:       []string{"kubernetes/pods_list"},
+		},
+		"tool pattern": {
+			assertions: []ToolAssertion{{Server: "kubernetes", ToolPattern: "^pods_(list|get)$"}},
+			want:       []string{"kubernetes/pods_list", "kubernetes/pods_get"},
+		},
+		"whole server": {
+			assertions: []ToolAssertion{{Server: "helm"}},
+			want:       []string{"helm/helm_list"},
+		},
+		"several servers": {
+			assertions: []ToolAssertion{{Server: "kubernetes", Tool: "namespaces_list"}, {Server: "helm", Tool: "helm_list"}},
+			want:       []string{"kubernetes/namespaces_list", "helm/helm_list"},
+		},
+		"unknown tool": {
+			assertions: []ToolAssertion{{Server: "kubernetes", Tool: "nodes_list"}},
+			want:       nil,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			filter := allowedToolsFilter(tc.assertions)
+			var allowed []string
+			for _, tool := range tools {
+				if filter(tool[0], tool[1]) {
+					allowed = append(allowed, tool[0]+"/"+tool[1])
+				}
+			}
+			assert.Equal(t, tc.want, allowed)
+		})
+	}
+}
+
+func TestAllowedToolAssertions(t *testing.T) {
+	sets := []*TaskAssertions{
+		{
+			ToolsUsed:    []ToolAssertion{{Server: "kubernetes", Tool: "pods_list"}},
+			ToolsNotUsed: []ToolAssertion{{Server: "kubernetes", Tool: "pods_delete"}},
+		},
+		nil,
+		{RequireAny: []ToolAssertion{{Server: "helm"}}},
+	}
+
+	assert.Equal(t, []ToolAssertion{
+		{Server: "kubernetes", Tool: "pods_list"},
+		{Server: "helm"},
+	}, allowedToolAssertions(sets))
+	assert.Empty(t, allowedToolAssertions(nil))
+}
+
+func TestAllowedToolsMode(t *testing.T) {
+	assert.NoError(t, AllowedToolsMode("").Validate())
+	assert.NoError(t, AllowedToolsAll.Validate())
+	assert.NoError(t, AllowedToolsAssertions.Validate())
+	assert.Error(t, AllowedToolsMode("guided").Validate())
+
+	spec := &EvalSpec{Config: EvalConfig{AllowedTools: AllowedToolsAssertions}}
+
+	r, err := NewRunner(spec)
+	require.NoError(t, err)
+	assert.Equal(t, AllowedToolsAssertions, r.(*evalRunner).allowedTools)
+
+	r, err = NewRunner(spec, RunnerOptions{AllowedTools: AllowedToolsAll})
+	require.NoError(t, err)
+	assert.Equal(t, AllowedToolsAll, r.(*evalRunner).allowedTools)
+
+	_, err = NewRunner(spec, RunnerOptions{AllowedTools: "guided"})
+	assert.Error(t, err)
+}
+
+func TestCatalogueVariants(t *testing.T) {
+	guided := taskConfig{
+		spec:       &task.TaskConfig{Metadata: task.TaskMetadata{Name: "guided"}},
+		assertions: []*TaskAssertions{{ToolsUsed: []ToolAssertion{{Server: "kubernetes", Tool: "pods_list"}}}},
+	}
+	unguided := taskConfig{
+		spec:       &task.TaskConfig{Metadata: task.TaskMetadata{Name: "unguided"}},
+		assertions: []*TaskAssertions{{MinToolCalls: new(int)}},
+	}
+
+	r := &evalRunner{allowedTools: AllowedToolsAssertions}
+	require.Len(t, r.catalogueVariants(guided), 1)
+	assert.Equal(t, AllowedToolsAssertions, r.allowedToolsFor(r.catalogueVariants(guided)[0]))
+
+	r.catalogueAblation = true
+	variants := r.catalogueVariants(guided)
+	require.Len(t, variants, 2)
+	assert.Equal(t, AllowedToolsAssertions, r.allowedToolsFor(variants[0]))
+	assert.Equal(t, AllowedToolsAll, r.allowedToolsFor(variants[1]))
+
+	// Only the proxy servers of the required variant hide the other tools
+	assert.Equal(t, []ToolAssertion{{Server: "kubernetes", Tool: "pods_list"}}, r.restrictedTools(variants[0]))
+	assert.Nil(t, r.restrictedTools(variants[1]))
+
+	variants = r.catalogueVariants(unguided)
+	require.Len(t, variants, 1)
+	assert.Equal(t, AllowedToolsAll, r.allowedToolsFor(variants[0]))
+}
+
+func TestExecuteTaskCatalogueAblation(t *testing.T) {
+	runner := &evalRunner{
+		spec:              &EvalSpec{},
+		runs:              2,
+		runsExplicitlySet: true,
+		catalogueAblation: true,
+		budget:            newBudgetTracker(&BudgetConfig{MaxTokens: 10}),
+		progressCallback:  func(ProgressEvent) {},
+	}
+	runner.budget.record(&EvalResult{TokenEstimate: &tokens.Estimate{InputTokens: 10}})
+
+	tc := taskConfig{
+		path:       "task.yaml",
+		spec:       &task.TaskConfig{Metadata: task.TaskMetadata{Name: "list-pods"}},
+		assertions: []*TaskAssertions{{RequireAny: []ToolAssertion{{Server: "kubernetes"}}}},
+	}
+
+	// Both variants are recorded, each with its own runs
+	results := runner.executeTask(t.Context(), nil, tc)
+	require.Len(t, results, 4)
+	for i, result := range results {
+		want := AllowedToolsAssertions
+		if i >= 2 {
+			want = AllowedToolsAll
+		}
+		assert.Equal(t, want, result.AllowedToolsMode)
+		assert.Equal(t, i%2, result.RunIndex)
+		assert.Equal(t, 2, result.TotalRuns)
+	}
+}
+
+func TestNewRunnerCatalogueAblation(t *testing.T) {
+	r, err := NewRunner(&EvalSpec{}, RunnerOptions{CatalogueAblation: true})
+	require.NoError(t, err)
+	assert.True(t, r.(*evalRunner).catalogueAblation)
+
+	_, err = NewRunner(&EvalSpec{}, RunnerOptions{CatalogueAblation: true, AllowedTools: AllowedToolsAll})
+	assert.Error(t, err)
+}
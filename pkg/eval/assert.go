@@ -10,6 +10,7 @@ import (
 
 	"github.com/mcpchecker/mcpchecker/pkg/agent"
 	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 const (
@@ -24,12 +25,19 @@ const (
 	assertionTypePromptsNotUsed   = "promptsNotUsed"
 	assertionTypeCallOrder        = "callOrder"
 	assertionTypeNoDuplicateCalls = "noDuplicateCalls"
+	assertionTypeToolOutputs      = "toolOutputs"
+	assertionTypeToolErrors       = "toolErrors"
 )
 
 type SingleAssertionResult struct {
 	Passed  bool     `json:"passed"`
 	Reason  string   `json:"reason,omitempty"`
 	Details []string `json:"details,omitempty"`
+
+	// Expected and Actual hold the compared values for assertions that check content,
+	// so failures can be rendered as a diff.
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
 }
 
 func (s *SingleAssertionResult) Succeeded() bool {
@@ -52,8 +60,11 @@ type CompositeAssertionResult struct {
 	PromptsNotUsed   *SingleAssertionResult `json:"promptsNotUsed,omitempty"`
 	CallOrder        *SingleAssertionResult `json:"callOrder,omitempty"`
 	NoDuplicateCalls *SingleAssertionResult `json:"noDuplicateCalls,omitempty"`
+	ToolOutputs      *SingleAssertionResult `json:"toolOutputs,omitempty"`
 	SkillsLoaded     *SingleAssertionResult `json:"skillsLoaded,omitempty"`
 	SkillsNotLoaded  *SingleAssertionResult `json:"skillsNotLoaded,omitempty"`
+	ReadOnly         *SingleAssertionResult `json:"readOnly,omitempty"`
+	ToolErrors       *SingleAssertionResult `json:"toolErrors,omitempty"`
 }
 
 // allFields returns all assertion result pointers for iteration.
@@ -63,8 +74,9 @@ func (c *CompositeAssertionResult) allFields() []*SingleAssertionResult {
 		c.ToolsUsed, c.RequireAny, c.ToolsNotUsed,
 		c.MinToolCalls, c.MaxToolCalls, c.ResourcesRead,
 		c.ResourcesNotRead, c.PromptsUsed, c.PromptsNotUsed,
-		c.CallOrder, c.NoDuplicateCalls,
-		c.SkillsLoaded, c.SkillsNotLoaded,
+		c.CallOrder, c.NoDuplicateCalls, c.ToolOutputs,
+		c.SkillsLoaded, c.SkillsNotLoaded, c.ReadOnly,
+		c.ToolErrors,
 	}
 }
 
@@ -160,7 +172,15 @@ func NewCompositeAssertionEvaluator(assertions *TaskAssertions) CompositeAsserti
 	}
 
 	if assertions.NoDuplicateCalls {
-		evaluators = append(evaluators, NewNoDuplicateCallsEvaluator())
+		evaluators = append(evaluators, NewNoDuplicateCallsEvaluator(assertions.DuplicateCallArguments))
+	}
+
+	if len(assertions.ToolOutputs) > 0 {
+		evaluators = append(evaluators, NewToolOutputsEvaluator(assertions.ToolOutputs))
+	}
+
+	if len(assertions.ToolErrors) > 0 {
+		evaluators = append(evaluators, NewToolErrorsEvaluator(assertions.ToolErrors))
 	}
 
 	return &assertionEvaluator{
@@ -196,6 +216,10 @@ func (a *assertionEvaluator) Evaluate(history *mcpproxy.CallHistory) *CompositeA
 			res.CallOrder = got
 		case assertionTypeNoDuplicateCalls:
 			res.NoDuplicateCalls = got
+		case assertionTypeToolOutputs:
+			res.ToolOutputs = got
+		case assertionTypeToolErrors:
+			res.ToolErrors = got
 		default:
 		}
 	}
@@ -382,8 +406,8 @@ func (e *resourcesReadEvaluator) Evaluate(history *mcpproxy.CallHistory) *Single
 		if !found {
 			return &SingleAssertionResult{
 				Passed: false,
-				Reason: fmt.Sprintf("Required resource not read: server=%s, uri=%s, pattern=%s",
-					assertion.Server, assertion.URI, assertion.URIPattern,
+				Reason: fmt.Sprintf("Required resource not read: server=%s, uri=%s, pattern=%s, template=%s",
+					assertion.Server, assertion.URI, assertion.URIPattern, assertion.URITemplate,
 				),
 			}
 		}
@@ -450,8 +474,8 @@ func (e *promptsUsedEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAs
 		if !found {
 			return &SingleAssertionResult{
 				Passed: false,
-				Reason: fmt.Sprintf("Required prompt not used: server=%s, prompt=%s, pattern=%s",
-					assertion.Server, assertion.Prompt, assertion.PromptPattern,
+				Reason: fmt.Sprintf("Required prompt not used: server=%s, prompt=%s, pattern=%s%s",
+					assertion.Server, assertion.Prompt, assertion.PromptPattern, formatPromptArguments(assertion.Arguments),
 				),
 			}
 		}
@@ -573,33 +597,243 @@ func (e *callOrderEvaluator) Type() string {
 	return assertionTypeCallOrder
 }
 
-type noDuplicateCallsEvaluator struct{}
+type noDuplicateCallsEvaluator struct {
+	arguments DuplicateArguments
+}
 
-func NewNoDuplicateCallsEvaluator() SingleAssertionEvaluator {
-	return &noDuplicateCallsEvaluator{}
+// NewNoDuplicateCallsEvaluator returns an evaluator that fails if a tool was called
+// more than once with the same arguments, compared as set by arguments. An empty
+// mode compares the arguments exactly.
+func NewNoDuplicateCallsEvaluator(arguments DuplicateArguments) SingleAssertionEvaluator {
+	return &noDuplicateCallsEvaluator{
+		arguments: arguments,
+	}
 }
 
 func (e *noDuplicateCallsEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAssertionResult {
-	seen := make(map[string]struct{})
+	type duplicateGroup struct {
+		call  *mcpproxy.ToolCall
+		count int
+	}
 
+	var groups []*duplicateGroup
+	seen := make(map[string]*duplicateGroup)
 	for _, call := range history.ToolCalls {
-		key := fmt.Sprintf("%s:%s:%v", call.ServerName, call.ToolName, call.Request.Params.Arguments)
+		if call == nil {
+			continue
+		}
+
+		key := fmt.Sprintf("%s:%s", call.ServerName, call.ToolName)
+		if e.arguments != DuplicateArgumentsIgnore {
+			key += ":" + e.argumentsKey(call)
+		}
+
+		group, ok := seen[key]
+		if !ok {
+			group = &duplicateGroup{call: call}
+			seen[key] = group
+			groups = append(groups, group)
+		}
+		group.count++
+	}
+
+	var names, details []string
+	for _, group := range groups {
+		if group.count < 2 {
+			continue
+		}
+		name := fmt.Sprintf("%s.%s", group.call.ServerName, group.call.ToolName)
+		names = append(names, name)
+		if e.arguments == DuplicateArgumentsIgnore {
+			details = append(details, fmt.Sprintf("%s called %d times", name, group.count))
+		} else {
+			details = append(details, fmt.Sprintf("%s called %d times with arguments %s", name, group.count, e.argumentsKey(group.call)))
+		}
+	}
+
+	if len(names) > 0 {
+		return &SingleAssertionResult{
+			Passed:  false,
+			Reason:  fmt.Sprintf("Duplicate call detected: %s", strings.Join(names, ", ")),
+			Details: details,
+		}
+	}
 
-		if _, ok := seen[key]; ok {
+	return &SingleAssertionResult{Passed: true}
+}
+
+// argumentsKey returns the arguments of call in the form they are compared in.
+func (e *noDuplicateCallsEvaluator) argumentsKey(call *mcpproxy.ToolCall) string {
+	if call.Request == nil || call.Request.Params == nil {
+		return ""
+	}
+	args := call.Request.Params.Arguments
+	if e.arguments != DuplicateArgumentsNormalized {
+		return string(args)
+	}
+
+	var value any
+	if err := json.Unmarshal(args, &value); err != nil {
+		return string(args)
+	}
+	normalized, err := json.Marshal(value)
+	if err != nil {
+		return string(args)
+	}
+	return string(normalized)
+}
+
+func (e *noDuplicateCallsEvaluator) Type() string {
+	return assertionTypeNoDuplicateCalls
+}
+
+type toolOutputsEvaluator struct {
+	assertions []ToolOutputAssertion
+}
+
+func NewToolOutputsEvaluator(assertions []ToolOutputAssertion) SingleAssertionEvaluator {
+	return &toolOutputsEvaluator{
+		assertions: assertions,
+	}
+}
+
+func (e *toolOutputsEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAssertionResult {
+	for _, assertion := range e.assertions {
+		match := ToolAssertion{Server: assertion.Server, Tool: assertion.Tool, ToolPattern: assertion.ToolPattern}
+		expected := strings.TrimSpace(assertion.Expected)
+		if expected == "" {
+			// Every output contains an empty snippet, so it would only check that the tool was called
 			return &SingleAssertionResult{
 				Passed: false,
-				Reason: fmt.Sprintf("Duplicate call detected: %s.%s", call.ServerName, call.ToolName),
+				Reason: fmt.Sprintf("Tool output assertion has no expected snippet: server=%s, tool=%s, pattern=%s",
+					assertion.Server, assertion.Tool, assertion.ToolPattern,
+				),
+			}
+		}
+
+		var lastCall *mcpproxy.ToolCall
+		found := false
+		for _, call := range history.ToolCalls {
+			if !matchesToolAssertion(call, match) {
+				continue
+			}
+			lastCall = call
+			if strings.Contains(call.ResultText(), expected) {
+				found = true
+				break
 			}
 		}
 
-		seen[key] = struct{}{}
+		if lastCall == nil {
+			return &SingleAssertionResult{
+				Passed: false,
+				Reason: fmt.Sprintf("Tool not called: server=%s, tool=%s, pattern=%s",
+					assertion.Server, assertion.Tool, assertion.ToolPattern,
+				),
+			}
+		}
+
+		if !found {
+			// Report the most recent matching call, which is usually the one the agent relied on
+			return &SingleAssertionResult{
+				Passed: false,
+				Reason: fmt.Sprintf("Tool output did not contain expected snippet: server=%s, tool=%s",
+					lastCall.ServerName, lastCall.ToolName,
+				),
+				Expected: expected,
+				Actual:   strings.TrimSpace(lastCall.ResultText()),
+			}
+		}
 	}
 
 	return &SingleAssertionResult{Passed: true}
 }
 
-func (e *noDuplicateCallsEvaluator) Type() string {
-	return assertionTypeNoDuplicateCalls
+func (e *toolOutputsEvaluator) Type() string {
+	return assertionTypeToolOutputs
+}
+
+type toolErrorsEvaluator struct {
+	assertions []ToolErrorAssertion
+}
+
+func NewToolErrorsEvaluator(assertions []ToolErrorAssertion) SingleAssertionEvaluator {
+	return &toolErrorsEvaluator{
+		assertions: assertions,
+	}
+}
+
+func (e *toolErrorsEvaluator) Evaluate(history *mcpproxy.CallHistory) *SingleAssertionResult {
+	for _, assertion := range e.assertions {
+		match := ToolAssertion{Server: assertion.Server, Tool: assertion.Tool, ToolPattern: assertion.ToolPattern}
+
+		var lastCall *mcpproxy.ToolCall
+		found := false
+		for _, call := range history.ToolCalls {
+			if !matchesToolAssertion(call, match) {
+				continue
+			}
+			lastCall = call
+			if matchesToolError(call, assertion) {
+				found = true
+				break
+			}
+		}
+
+		if lastCall == nil {
+			return &SingleAssertionResult{
+				Passed: false,
+				Reason: fmt.Sprintf("Tool not called: server=%s, tool=%s, pattern=%s",
+					assertion.Server, assertion.Tool, assertion.ToolPattern,
+				),
+			}
+		}
+
+		if !found {
+			// Report the most recent matching call, which is usually the one the agent relied on
+			if !lastCall.Failed() {
+				return &SingleAssertionResult{
+					Passed: false,
+					Reason: fmt.Sprintf("Tool call succeeded but was expected to fail: server=%s, tool=%s",
+						lastCall.ServerName, lastCall.ToolName,
+					),
+				}
+			}
+			return &SingleAssertionResult{
+				Passed: false,
+				Reason: fmt.Sprintf("Tool call did not fail with the expected error: server=%s, tool=%s",
+					lastCall.ServerName, lastCall.ToolName,
+				),
+				Expected: formatToolError(assertion.Code, assertion.Contains),
+				Actual:   formatToolError(&lastCall.ErrorCode, strings.TrimSpace(lastCall.ErrorText())),
+			}
+		}
+	}
+
+	return &SingleAssertionResult{Passed: true}
+}
+
+func (e *toolErrorsEvaluator) Type() string {
+	return assertionTypeToolErrors
+}
+
+// matchesToolError reports whether call failed with the error the assertion expects.
+func matchesToolError(call *mcpproxy.ToolCall, assertion ToolErrorAssertion) bool {
+	if !call.Failed() {
+		return false
+	}
+	if assertion.Code != nil && call.ErrorCode != *assertion.Code {
+		return false
+	}
+	return strings.Contains(call.ErrorText(), strings.TrimSpace(assertion.Contains))
+}
+
+// formatToolError formats an error code and message for failure diffs.
+func formatToolError(code *int64, message string) string {
+	if code == nil || *code == 0 {
+		return message
+	}
+	return fmt.Sprintf("code %d: %s", *code, message)
 }
 
 func matchesToolAssertion(call *mcpproxy.ToolCall, assertion ToolAssertion) bool {
@@ -607,7 +841,12 @@ func matchesToolAssertion(call *mcpproxy.ToolCall, assertion ToolAssertion) bool
 		return false
 	}
 
-	if call.ServerName != assertion.Server {
+	return matchesTool(call.ServerName, call.ToolName, assertion)
+}
+
+// matchesTool reports whether the tool called toolName of serverName matches assertion.
+func matchesTool(serverName, toolName string, assertion ToolAssertion) bool {
+	if serverName != assertion.Server {
 		return false
 	}
 
@@ -616,12 +855,12 @@ func matchesToolAssertion(call *mcpproxy.ToolCall, assertion ToolAssertion) bool
 		return true
 	}
 
-	if assertion.Tool != "" && call.ToolName == assertion.Tool {
+	if assertion.Tool != "" && toolName == assertion.Tool {
 		return true
 	}
 
 	if assertion.ToolPattern != "" {
-		matched, _ := regexp.MatchString(assertion.ToolPattern, call.ToolName)
+		matched, _ := regexp.MatchString(assertion.ToolPattern, toolName)
 		return matched
 	}
 
@@ -637,8 +876,8 @@ func matchesResourceAssertion(call *mcpproxy.ResourceRead, assertion ResourceAss
 		return false
 	}
 
-	// if no URI or pattern specified, match any resource from this server
-	if assertion.URI == "" && assertion.URIPattern == "" {
+	// if no URI, pattern or template specified, match any resource from this server
+	if assertion.URI == "" && assertion.URIPattern == "" && assertion.URITemplate == "" {
 		return true
 	}
 
@@ -651,6 +890,10 @@ func matchesResourceAssertion(call *mcpproxy.ResourceRead, assertion ResourceAss
 		return matched
 	}
 
+	if assertion.URITemplate != "" {
+		return matchesURITemplate(assertion.URITemplate, call.URI)
+	}
+
 	return false
 }
 
@@ -663,6 +906,10 @@ func matchesPromptAssertion(call *mcpproxy.PromptGet, assertion PromptAssertion)
 		return false
 	}
 
+	if !matchesPromptArguments(call, assertion.Arguments) {
+		return false
+	}
+
 	// if no prompt or pattern specified, match any prompt from this server
 	if assertion.Prompt == "" && assertion.PromptPattern == "" {
 		return true
@@ -680,6 +927,47 @@ func matchesPromptAssertion(call *mcpproxy.PromptGet, assertion PromptAssertion)
 	return false
 }
 
+// matchesPromptArguments reports whether the prompt was called with every
+// expected argument, with a value matching its wildcard pattern.
+func matchesPromptArguments(call *mcpproxy.PromptGet, expected map[string]string) bool {
+	if len(expected) == 0 {
+		return true
+	}
+
+	var args map[string]string
+	if call.Request != nil && call.Request.Params != nil {
+		args = call.Request.Params.Arguments
+	}
+
+	for name, pattern := range expected {
+		value, ok := args[name]
+		if !ok || !matchesWildcard(pattern, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// formatPromptArguments formats prompt arguments for failure messages, in a
+// stable order.
+func formatPromptArguments(args map[string]string) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+"="+args[name])
+	}
+	return ", arguments=" + strings.Join(parts, ",")
+}
+
 // Merge combines results from another CompositeAssertionResult.
 // For each field, if either result has a value, the merged result uses the first non-nil.
 // If both have values and both failed, the failure messages are combined into Details.
@@ -737,8 +1025,11 @@ func (c *CompositeAssertionResult) Merge(other *CompositeAssertionResult) *Compo
 		PromptsNotUsed:   mergeField(c.PromptsNotUsed, other.PromptsNotUsed),
 		CallOrder:        mergeField(c.CallOrder, other.CallOrder),
 		NoDuplicateCalls: mergeField(c.NoDuplicateCalls, other.NoDuplicateCalls),
+		ToolOutputs:      mergeField(c.ToolOutputs, other.ToolOutputs),
 		SkillsLoaded:     mergeField(c.SkillsLoaded, other.SkillsLoaded),
 		SkillsNotLoaded:  mergeField(c.SkillsNotLoaded, other.SkillsNotLoaded),
+		ReadOnly:         mergeField(c.ReadOnly, other.ReadOnly),
+		ToolErrors:       mergeField(c.ToolErrors, other.ToolErrors),
 	}
 }
 
@@ -758,6 +1049,61 @@ func collectSkillInputs(toolCalls []agent.ToolCallSummary, toolName string) []st
 	return inputs
 }
 
+// ToolAnnotations holds the MCP annotations of tools, keyed by server name and tool name.
+type ToolAnnotations map[string]map[string]*mcp.ToolAnnotations
+
+// evaluateReadOnly checks that every tool call went to a read-only tool. Tools
+// are classified by the assertion's lists first, then by their readOnlyHint
+// annotation; tools that are neither listed nor annotated count as writes.
+func evaluateReadOnly(assertion *ReadOnlyAssertion, history *mcpproxy.CallHistory, annotations ToolAnnotations) *SingleAssertionResult {
+	if history == nil {
+		return &SingleAssertionResult{Passed: true}
+	}
+
+	var writes []string
+	counts := make(map[string]int)
+	for _, call := range history.ToolCalls {
+		if call == nil || isReadOnlyTool(call, assertion, annotations) {
+			continue
+		}
+		key := fmt.Sprintf("server=%s, tool=%s", call.ServerName, call.ToolName)
+		if counts[key] == 0 {
+			writes = append(writes, key)
+		}
+		counts[key]++
+	}
+
+	if len(writes) == 0 {
+		return &SingleAssertionResult{Passed: true}
+	}
+
+	details := make([]string, 0, len(writes))
+	for _, key := range writes {
+		details = append(details, fmt.Sprintf("%s (%d calls)", key, counts[key]))
+	}
+	return &SingleAssertionResult{
+		Passed:  false,
+		Reason:  fmt.Sprintf("Write tools called: %s", strings.Join(writes, "; ")),
+		Details: details,
+	}
+}
+
+func isReadOnlyTool(call *mcpproxy.ToolCall, assertion *ReadOnlyAssertion, annotations ToolAnnotations) bool {
+	for _, a := range assertion.WriteTools {
+		if matchesToolAssertion(call, a) {
+			return false
+		}
+	}
+	for _, a := range assertion.ReadTools {
+		if matchesToolAssertion(call, a) {
+			return true
+		}
+	}
+
+	toolAnnotations := annotations[call.ServerName][call.ToolName]
+	return toolAnnotations != nil && toolAnnotations.ReadOnlyHint
+}
+
 // evaluateSkillsLoaded checks that all required skills were loaded by the agent.
 func evaluateSkillsLoaded(assertions []SkillAssertion, toolCalls []agent.ToolCallSummary, toolName string) *SingleAssertionResult {
 	skillInputs := collectSkillInputs(toolCalls, toolName)
@@ -760,6 +760,33 @@ func TestResourcesReadEvaluator(t *testing.T) {
 			},
 			expectPass: false,
 		},
+		"template wildcard match passes": {
+			assertions: []ResourceAssertion{{Server: "s1", URITemplate: "k8s://pods/*"}},
+			history: &mcpproxy.CallHistory{
+				ResourceReads: []*mcpproxy.ResourceRead{
+					{CallRecord: mcpproxy.CallRecord{ServerName: "s1"}, URI: "k8s://pods/nginx-7d9f8b-x2k4q"},
+				},
+			},
+			expectPass: true,
+		},
+		"template variable match passes": {
+			assertions: []ResourceAssertion{{Server: "s1", URITemplate: "k8s://namespaces/{namespace}/pods/{name}"}},
+			history: &mcpproxy.CallHistory{
+				ResourceReads: []*mcpproxy.ResourceRead{
+					{CallRecord: mcpproxy.CallRecord{ServerName: "s1"}, URI: "k8s://namespaces/test-abc123/pods/nginx"},
+				},
+			},
+			expectPass: true,
+		},
+		"template no match fails": {
+			assertions: []ResourceAssertion{{Server: "s1", URITemplate: "k8s://pods/*"}},
+			history: &mcpproxy.CallHistory{
+				ResourceReads: []*mcpproxy.ResourceRead{
+					{CallRecord: mcpproxy.CallRecord{ServerName: "s1"}, URI: "k8s://pods/default/nginx"},
+				},
+			},
+			expectPass: false,
+		},
 	}
 
 	for tn, tc := range tt {
@@ -911,6 +938,45 @@ func TestPromptsUsedEvaluator(t *testing.T) {
 			},
 			expectPass: true,
 		},
+		"arguments match passes": {
+			assertions: []PromptAssertion{{Server: "s1", Prompt: "greeting", Arguments: map[string]string{"name": "test-*"}}},
+			history: &mcpproxy.CallHistory{
+				PromptGets: []*mcpproxy.PromptGet{
+					{
+						CallRecord: mcpproxy.CallRecord{ServerName: "s1"},
+						Name:       "greeting",
+						Request: &mcp.GetPromptRequest{
+							Params: &mcp.GetPromptParams{Name: "greeting", Arguments: map[string]string{"name": "test-abc123", "lang": "en"}},
+						},
+					},
+				},
+			},
+			expectPass: true,
+		},
+		"arguments mismatch fails": {
+			assertions: []PromptAssertion{{Server: "s1", Prompt: "greeting", Arguments: map[string]string{"name": "prod-*"}}},
+			history: &mcpproxy.CallHistory{
+				PromptGets: []*mcpproxy.PromptGet{
+					{
+						CallRecord: mcpproxy.CallRecord{ServerName: "s1"},
+						Name:       "greeting",
+						Request: &mcp.GetPromptRequest{
+							Params: &mcp.GetPromptParams{Name: "greeting", Arguments: map[string]string{"name": "test-abc123"}},
+						},
+					},
+				},
+			},
+			expectPass: false,
+		},
+		"missing argument fails": {
+			assertions: []PromptAssertion{{Server: "s1", Prompt: "greeting", Arguments: map[string]string{"name": "*"}}},
+			history: &mcpproxy.CallHistory{
+				PromptGets: []*mcpproxy.PromptGet{
+					{CallRecord: mcpproxy.CallRecord{ServerName: "s1"}, Name: "greeting"},
+				},
+			},
+			expectPass: false,
+		},
 		"pattern no match fails": {
 			assertions: []PromptAssertion{{Server: "s1", PromptPattern: "^hello.*"}},
 			history: &mcpproxy.CallHistory{
@@ -1297,7 +1363,7 @@ func TestNoDuplicateCallsEvaluator(t *testing.T) {
 
 	for tn, tc := range tt {
 		t.Run(tn, func(t *testing.T) {
-			eval := NewNoDuplicateCallsEvaluator()
+			eval := NewNoDuplicateCallsEvaluator("")
 			result := eval.Evaluate(tc.history)
 
 			assert.Equal(t, tc.expectPass, result.Passed)
@@ -1306,6 +1372,81 @@ func TestNoDuplicateCallsEvaluator(t *testing.T) {
 	}
 }
 
+func TestToolOutputsEvaluator(t *testing.T) {
+	makeCall := func(server, tool, text string) *mcpproxy.ToolCall {
+		return &mcpproxy.ToolCall{
+			CallRecord: mcpproxy.CallRecord{ServerName: server},
+			ToolName:   tool,
+			Result: &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: text}},
+			},
+		}
+	}
+
+	tt := map[string]struct {
+		assertions     []ToolOutputAssertion
+		history        *mcpproxy.CallHistory
+		expectPass     bool
+		expectExpected string
+		expectActual   string
+	}{
+		"snippet found passes": {
+			assertions: []ToolOutputAssertion{{Server: "k8s", Tool: "pods_list", Expected: "nginx   1/1\n"}},
+			history: &mcpproxy.CallHistory{
+				ToolCalls: []*mcpproxy.ToolCall{makeCall("k8s", "pods_list", "NAME    READY\nnginx   1/1\n")},
+			},
+			expectPass: true,
+		},
+		"any matching call may contain snippet": {
+			assertions: []ToolOutputAssertion{{Server: "k8s", ToolPattern: "^pods_", Expected: "Running"}},
+			history: &mcpproxy.CallHistory{
+				ToolCalls: []*mcpproxy.ToolCall{
+					makeCall("k8s", "pods_list", "Pending"),
+					makeCall("k8s", "pods_get", "Running"),
+				},
+			},
+			expectPass: true,
+		},
+		"tool not called fails": {
+			assertions: []ToolOutputAssertion{{Server: "k8s", Tool: "pods_list", Expected: "nginx"}},
+			history:    &mcpproxy.CallHistory{},
+			expectPass: false,
+		},
+		"mismatch records expected and last actual": {
+			assertions: []ToolOutputAssertion{{Server: "k8s", Tool: "pods_list", Expected: "nginx   1/1"}},
+			history: &mcpproxy.CallHistory{
+				ToolCalls: []*mcpproxy.ToolCall{
+					makeCall("k8s", "pods_list", "first"),
+					makeCall("other", "pods_list", "nginx   1/1"),
+					makeCall("k8s", "pods_list", "nginx   0/1\n"),
+				},
+			},
+			expectPass:     false,
+			expectExpected: "nginx   1/1",
+			expectActual:   "nginx   0/1",
+		},
+		"empty snippet fails": {
+			assertions: []ToolOutputAssertion{{Server: "k8s", Tool: "pods_list", Expected: " \n"}},
+			history: &mcpproxy.CallHistory{
+				ToolCalls: []*mcpproxy.ToolCall{makeCall("k8s", "pods_list", "nginx   1/1")},
+			},
+			expectPass: false,
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			eval := NewToolOutputsEvaluator(tc.assertions)
+			result := eval.Evaluate(tc.history)
+
+			assert.Equal(t, tc.expectPass, result.Passed)
+			assert.Equal(t, tc.expectExpected, result.Expected)
+			assert.Equal(t, tc.expectActual, result.Actual)
+			assert.Equal(t, assertionTypeToolOutputs, eval.Type())
+		})
+	}
+}
+
 func TestMatchesToolAssertion(t *testing.T) {
 	tt := map[string]struct {
 		call      *mcpproxy.ToolCall
@@ -1474,6 +1615,160 @@ func TestMatchesPromptAssertion(t *testing.T) {
 	}
 }
 
+func TestNoDuplicateCallsArguments(t *testing.T) {
+	call := func(tool, args string) *mcpproxy.ToolCall {
+		return &mcpproxy.ToolCall{
+			CallRecord: mcpproxy.CallRecord{ServerName: "s1"},
+			ToolName:   tool,
+			Request: &mcp.CallToolRequest{
+				Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(args)},
+			},
+		}
+	}
+	history := &mcpproxy.CallHistory{
+		ToolCalls: []*mcpproxy.ToolCall{
+			call("get", `{"name":"a","ns":"x"}`),
+			call("get", `{"ns": "x", "name": "a"}`),
+			call("get", `{"name":"b","ns":"x"}`),
+			call("list", `{}`),
+			call("list", `{}`),
+			call("list", `{}`),
+		},
+	}
+
+	tt := map[string]struct {
+		arguments     DuplicateArguments
+		expectReason  string
+		expectDetails []string
+	}{
+		"exact": {
+			arguments:     DuplicateArgumentsExact,
+			expectReason:  "Duplicate call detected: s1.list",
+			expectDetails: []string{"s1.list called 3 times with arguments {}"},
+		},
+		"default is exact": {
+			arguments:     "",
+			expectReason:  "Duplicate call detected: s1.list",
+			expectDetails: []string{"s1.list called 3 times with arguments {}"},
+		},
+		"normalized": {
+			arguments:    DuplicateArgumentsNormalized,
+			expectReason: "Duplicate call detected: s1.get, s1.list",
+			expectDetails: []string{
+				`s1.get called 2 times with arguments {"name":"a","ns":"x"}`,
+				"s1.list called 3 times with arguments {}",
+			},
+		},
+		"ignore": {
+			arguments:    DuplicateArgumentsIgnore,
+			expectReason: "Duplicate call detected: s1.get, s1.list",
+			expectDetails: []string{
+				"s1.get called 3 times",
+				"s1.list called 3 times",
+			},
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			result := NewNoDuplicateCallsEvaluator(tc.arguments).Evaluate(history)
+
+			assert.False(t, result.Passed)
+			assert.Equal(t, tc.expectReason, result.Reason)
+			assert.Equal(t, tc.expectDetails, result.Details)
+		})
+	}
+}
+
+func TestToolErrorsEvaluator(t *testing.T) {
+	code := int64(-32602)
+	protocolError := &mcpproxy.ToolCall{
+		CallRecord: mcpproxy.CallRecord{ServerName: "k8s", Success: false, Error: "invalid params: name is required", ErrorCode: code},
+		ToolName:   "pods_create",
+	}
+	toolError := &mcpproxy.ToolCall{
+		CallRecord: mcpproxy.CallRecord{ServerName: "k8s", Success: true},
+		ToolName:   "namespaces_delete",
+		Result: &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{&mcp.TextContent{Text: "namespace kube-system is protected"}},
+		},
+	}
+	success := &mcpproxy.ToolCall{
+		CallRecord: mcpproxy.CallRecord{ServerName: "k8s", Success: true},
+		ToolName:   "namespaces_delete",
+		Result:     &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "deleted"}}},
+	}
+
+	tt := map[string]struct {
+		assertions   []ToolErrorAssertion
+		calls        []*mcpproxy.ToolCall
+		expectPass   bool
+		expectReason string
+		expectActual string
+	}{
+		"tool error with expected message passes": {
+			assertions: []ToolErrorAssertion{{Server: "k8s", Tool: "namespaces_delete", Contains: "is protected"}},
+			calls:      []*mcpproxy.ToolCall{toolError},
+			expectPass: true,
+		},
+		"protocol error with expected code passes": {
+			assertions: []ToolErrorAssertion{{Server: "k8s", Tool: "pods_create", Code: &code, Contains: "name is required"}},
+			calls:      []*mcpproxy.ToolCall{protocolError},
+			expectPass: true,
+		},
+		"any error passes without contains": {
+			assertions: []ToolErrorAssertion{{Server: "k8s", ToolPattern: "^namespaces_"}},
+			calls:      []*mcpproxy.ToolCall{success, toolError},
+			expectPass: true,
+		},
+		"tool not called fails": {
+			assertions:   []ToolErrorAssertion{{Server: "k8s", Tool: "pods_delete"}},
+			calls:        []*mcpproxy.ToolCall{toolError},
+			expectPass:   false,
+			expectReason: "Tool not called",
+		},
+		"successful call fails": {
+			assertions:   []ToolErrorAssertion{{Server: "k8s", Tool: "namespaces_delete"}},
+			calls:        []*mcpproxy.ToolCall{success},
+			expectPass:   false,
+			expectReason: "Tool call succeeded but was expected to fail",
+		},
+		"wrong message fails": {
+			assertions:   []ToolErrorAssertion{{Server: "k8s", Tool: "namespaces_delete", Contains: "not found"}},
+			calls:        []*mcpproxy.ToolCall{toolError},
+			expectPass:   false,
+			expectReason: "Tool call did not fail with the expected error",
+			expectActual: "namespace kube-system is protected",
+		},
+		"wrong code fails": {
+			assertions:   []ToolErrorAssertion{{Server: "k8s", Tool: "pods_create", Code: int64Ptr(-32601)}},
+			calls:        []*mcpproxy.ToolCall{protocolError},
+			expectPass:   false,
+			expectReason: "Tool call did not fail with the expected error",
+			expectActual: "code -32602: invalid params: name is required",
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			eval := NewToolErrorsEvaluator(tc.assertions)
+			result := eval.Evaluate(&mcpproxy.CallHistory{ToolCalls: tc.calls})
+
+			assert.Equal(t, tc.expectPass, result.Passed)
+			assert.Equal(t, assertionTypeToolErrors, eval.Type())
+			if tc.expectReason != "" {
+				assert.Contains(t, result.Reason, tc.expectReason)
+			}
+			assert.Equal(t, tc.expectActual, result.Actual)
+		})
+	}
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
 func intPtr(i int) *int {
 	return &i
 }
@@ -1510,6 +1805,7 @@ func TestCompositeAssertionResult_Merge(t *testing.T) {
 			PromptsNotUsed:   passed,
 			CallOrder:        passed,
 			NoDuplicateCalls: passed,
+			ToolOutputs:      passed,
 		}
 		result := a.Merge(b)
 
@@ -1524,6 +1820,7 @@ func TestCompositeAssertionResult_Merge(t *testing.T) {
 		assert.Equal(t, passed, result.PromptsNotUsed)
 		assert.Equal(t, passed, result.CallOrder)
 		assert.Equal(t, passed, result.NoDuplicateCalls)
+		assert.Equal(t, passed, result.ToolOutputs)
 	})
 
 	t.Run("failure takes precedence over pass", func(t *testing.T) {
@@ -1618,59 +1915,59 @@ func TestCompositeAssertionResult_Merge(t *testing.T) {
 
 func TestNewCompositeAssertionEvaluator(t *testing.T) {
 	tt := map[string]struct {
-		assertions            *TaskAssertions
+		assertions             *TaskAssertions
 		expectedEvaluatorCount int
 	}{
 		"empty assertions creates no evaluators": {
-			assertions:            &TaskAssertions{},
+			assertions:             &TaskAssertions{},
 			expectedEvaluatorCount: 0,
 		},
 		"single toolsUsed assertion": {
-			assertions:            &TaskAssertions{ToolsUsed: []ToolAssertion{{Server: "s1"}}},
+			assertions:             &TaskAssertions{ToolsUsed: []ToolAssertion{{Server: "s1"}}},
 			expectedEvaluatorCount: 1,
 		},
 		"single requireAny assertion": {
-			assertions:            &TaskAssertions{RequireAny: []ToolAssertion{{Server: "s1"}}},
+			assertions:             &TaskAssertions{RequireAny: []ToolAssertion{{Server: "s1"}}},
 			expectedEvaluatorCount: 1,
 		},
 		"single toolsNotUsed assertion": {
-			assertions:            &TaskAssertions{ToolsNotUsed: []ToolAssertion{{Server: "s1"}}},
+			assertions:             &TaskAssertions{ToolsNotUsed: []ToolAssertion{{Server: "s1"}}},
 			expectedEvaluatorCount: 1,
 		},
 		"single minToolCalls assertion": {
-			assertions:            &TaskAssertions{MinToolCalls: intPtr(1)},
+			assertions:             &TaskAssertions{MinToolCalls: intPtr(1)},
 			expectedEvaluatorCount: 1,
 		},
 		"single maxToolCalls assertion": {
-			assertions:            &TaskAssertions{MaxToolCalls: intPtr(10)},
+			assertions:             &TaskAssertions{MaxToolCalls: intPtr(10)},
 			expectedEvaluatorCount: 1,
 		},
 		"single resourcesRead assertion": {
-			assertions:            &TaskAssertions{ResourcesRead: []ResourceAssertion{{Server: "s1"}}},
+			assertions:             &TaskAssertions{ResourcesRead: []ResourceAssertion{{Server: "s1"}}},
 			expectedEvaluatorCount: 1,
 		},
 		"single resourcesNotRead assertion": {
-			assertions:            &TaskAssertions{ResourcesNotRead: []ResourceAssertion{{Server: "s1"}}},
+			assertions:             &TaskAssertions{ResourcesNotRead: []ResourceAssertion{{Server: "s1"}}},
 			expectedEvaluatorCount: 1,
 		},
 		"single promptsUsed assertion": {
-			assertions:            &TaskAssertions{PromptsUsed: []PromptAssertion{{Server: "s1"}}},
+			assertions:             &TaskAssertions{PromptsUsed: []PromptAssertion{{Server: "s1"}}},
 			expectedEvaluatorCount: 1,
 		},
 		"single promptsNotUsed assertion": {
-			assertions:            &TaskAssertions{PromptsNotUsed: []PromptAssertion{{Server: "s1"}}},
+			assertions:             &TaskAssertions{PromptsNotUsed: []PromptAssertion{{Server: "s1"}}},
 			expectedEvaluatorCount: 1,
 		},
 		"single callOrder assertion": {
-			assertions:            &TaskAssertions{CallOrder: []CallOrderAssertion{{Type: "tool", Server: "s1", Name: "t1"}}},
+			assertions:             &TaskAssertions{CallOrder: []CallOrderAssertion{{Type: "tool", Server: "s1", Name: "t1"}}},
 			expectedEvaluatorCount: 1,
 		},
 		"single noDuplicateCalls assertion": {
-			assertions:            &TaskAssertions{NoDuplicateCalls: true},
+			assertions:             &TaskAssertions{NoDuplicateCalls: true},
 			expectedEvaluatorCount: 1,
 		},
 		"noDuplicateCalls false creates no evaluator": {
-			assertions:            &TaskAssertions{NoDuplicateCalls: false},
+			assertions:             &TaskAssertions{NoDuplicateCalls: false},
 			expectedEvaluatorCount: 0,
 		},
 		"all assertion types": {
@@ -1760,3 +2057,77 @@ func TestMatchesSkillAssertion(t *testing.T) {
 		})
 	}
 }
+
+func TestEvaluateReadOnly(t *testing.T) {
+	annotations := ToolAnnotations{
+		"k8s": {
+			"pods_list":   {ReadOnlyHint: true},
+			"pods_delete": {ReadOnlyHint: false},
+			"pods_exec":   nil,
+		},
+	}
+	call := func(server, tool string) *mcpproxy.ToolCall {
+		return &mcpproxy.ToolCall{CallRecord: mcpproxy.CallRecord{ServerName: server}, ToolName: tool}
+	}
+
+	tt := map[string]struct {
+		assertion     *ReadOnlyAssertion
+		calls         []*mcpproxy.ToolCall
+		expectPass    bool
+		expectDetails []string
+	}{
+		"no calls passes": {
+			assertion:  &ReadOnlyAssertion{},
+			expectPass: true,
+		},
+		"annotated read-only tools pass": {
+			assertion:  &ReadOnlyAssertion{},
+			calls:      []*mcpproxy.ToolCall{call("k8s", "pods_list"), call("k8s", "pods_list")},
+			expectPass: true,
+		},
+		"write tool fails with counts": {
+			assertion:     &ReadOnlyAssertion{},
+			calls:         []*mcpproxy.ToolCall{call("k8s", "pods_list"), call("k8s", "pods_delete"), call("k8s", "pods_delete")},
+			expectPass:    false,
+			expectDetails: []string{"server=k8s, tool=pods_delete (2 calls)"},
+		},
+		"unannotated tool counts as write": {
+			assertion:     &ReadOnlyAssertion{},
+			calls:         []*mcpproxy.ToolCall{call("k8s", "pods_exec"), call("other", "unknown")},
+			expectPass:    false,
+			expectDetails: []string{"server=k8s, tool=pods_exec (1 calls)", "server=other, tool=unknown (1 calls)"},
+		},
+		"read tools list overrides annotations": {
+			assertion:  &ReadOnlyAssertion{ReadTools: []ToolAssertion{{Server: "k8s", ToolPattern: "^pods_(exec|log)$"}}},
+			calls:      []*mcpproxy.ToolCall{call("k8s", "pods_exec")},
+			expectPass: true,
+		},
+		"write tools list overrides annotations": {
+			assertion:     &ReadOnlyAssertion{WriteTools: []ToolAssertion{{Server: "k8s", Tool: "pods_list"}}},
+			calls:         []*mcpproxy.ToolCall{call("k8s", "pods_list")},
+			expectPass:    false,
+			expectDetails: []string{"server=k8s, tool=pods_list (1 calls)"},
+		},
+		"write tools take precedence over read tools": {
+			assertion: &ReadOnlyAssertion{
+				ReadTools:  []ToolAssertion{{Server: "k8s"}},
+				WriteTools: []ToolAssertion{{Server: "k8s", Tool: "pods_delete"}},
+			},
+			calls:         []*mcpproxy.ToolCall{call("k8s", "pods_exec"), call("k8s", "pods_delete")},
+			expectPass:    false,
+			expectDetails: []string{"server=k8s, tool=pods_delete (1 calls)"},
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			result := evaluateReadOnly(tc.assertion, &mcpproxy.CallHistory{ToolCalls: tc.calls}, annotations)
+
+			assert.Equal(t, tc.expectPass, result.Passed)
+			assert.Equal(t, tc.expectDetails, result.Details)
+			if !tc.expectPass {
+				assert.Contains(t, result.Reason, "Write tools called")
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package eval
+
+import (
+	"fmt"
+)
+
+// TaskAttempts is the outcome of the runs of a task that ran more than once.
+type TaskAttempts struct {
+	TaskID      string `json:"taskId,omitempty"`
+	TaskName    string `json:"taskName"`
+	Environment string `json:"environment,omitempty"`
+
+	// Outcomes tells whether each run of the task passed, in run order, of
+	// which Passed passed
+	Outcomes []bool `json:"outcomes"`
+	Passed   int    `json:"passed"`
+
+	// PassAt1 is the chance that a run of the task passes, and PassAtK that at
+	// least one of k runs does, estimated from its runs
+	PassAt1 float64 `json:"passAt1"`
+	PassAtK float64 `json:"passAtK"`
+
+	// Flaky is set if some runs of the task passed and others did not
+	Flaky bool `json:"flaky"`
+}
+
+// AttemptsSummary scores the tasks of a run that ran more than once, with
+// --repeat or repeat in task sets.
+type AttemptsSummary struct {
+	// K is the fewest runs of a task, which pass@k is estimated for
+	K int `json:"k"`
+
+	// PassAt1 and PassAtK are the averages of those of the tasks
+	PassAt1 float64 `json:"passAt1"`
+	PassAtK float64 `json:"passAtK"`
+
+	// FlakyTasks is the number of flaky tasks, FlakinessRate their share of
+	// the tasks
+	FlakyTasks    int     `json:"flakyTasks"`
+	FlakinessRate float64 `json:"flakinessRate"`
+
+	Tasks []TaskAttempts `json:"tasks"`
+}
+
+// AttemptStats scores the runs of a task that ran more than once, recorded
+// on each of its results.
+type AttemptStats struct {
+	// K is the number of runs pass@k is estimated for, the fewest runs of a
+	// task in the run
+	K int `json:"k"`
+
+	PassAt1 float64 `json:"passAt1"`
+	PassAtK float64 `json:"passAtK"`
+	Flaky   bool    `json:"flaky"`
+}
+
+// SummarizeAttempts scores the tasks of results that ran more than once, in
+// the order they first appear. Variants of a task, such as its runs against
+// each environment, are scored apart. A run passes if the task and all its
+// assertions passed, see EvalResult.Passed. Skipped runs that don't count as
+// not passed are left out. It returns nil if no task ran more than once.
+func SummarizeAttempts(results []*EvalResult) *AttemptsSummary {
+	summary, _ := scoreAttempts(results)
+	return summary
+}
+
+// RecordAttempts scores results like SummarizeAttempts, and sets the Attempts
+// of the results of each task that ran more than once to its scores.
+func RecordAttempts(results []*EvalResult) *AttemptsSummary {
+	summary, tasks := scoreAttempts(results)
+	if summary == nil {
+		return nil
+	}
+
+	for _, result := range results {
+		if !countsAsAttempt(result) {
+			continue
+		}
+		if i, ok := tasks[attemptKey(result)]; ok {
+			t := summary.Tasks[i]
+			result.Attempts = &AttemptStats{K: summary.K, PassAt1: t.PassAt1, PassAtK: t.PassAtK, Flaky: t.Flaky}
+		}
+	}
+	return summary
+}
+
+// scoreAttempts scores the tasks of results that ran more than once, also
+// returning the index in the summary of each scored task by its attemptKey.
+func scoreAttempts(results []*EvalResult) (*AttemptsSummary, map[string]int) {
+	var order []string
+	byTask := make(map[string]*TaskAttempts)
+	for _, result := range results {
+		if !countsAsAttempt(result) {
+			continue
+		}
+
+		key := attemptKey(result)
+		attempts, ok := byTask[key]
+		if !ok {
+			attempts = &TaskAttempts{TaskID: result.TaskID, TaskName: result.TaskName, Environment: result.Environment}
+			byTask[key] = attempts
+			order = append(order, key)
+		}
+		passed := result.Passed()
+		attempts.Outcomes = append(attempts.Outcomes, passed)
+		if passed {
+			attempts.Passed++
+		}
+	}
+
+	summary := &AttemptsSummary{}
+	tasks := make(map[string]int)
+	for _, key := range order {
+		attempts := byTask[key]
+		if n := len(attempts.Outcomes); n > 1 {
+			if summary.K == 0 || n < summary.K {
+				summary.K = n
+			}
+			tasks[key] = len(summary.Tasks)
+			summary.Tasks = append(summary.Tasks, *attempts)
+		}
+	}
+	if len(summary.Tasks) == 0 {
+		return nil, nil
+	}
+
+	for i := range summary.Tasks {
+		t := &summary.Tasks[i]
+		n := len(t.Outcomes)
+		t.PassAt1 = float64(t.Passed) / float64(n)
+		t.PassAtK = passAtK(n, t.Passed, summary.K)
+		t.Flaky = t.Passed > 0 && t.Passed < n
+
+		summary.PassAt1 += t.PassAt1
+		summary.PassAtK += t.PassAtK
+		if t.Flaky {
+			summary.FlakyTasks++
+		}
+	}
+	count := float64(len(summary.Tasks))
+	summary.PassAt1 /= count
+	summary.PassAtK /= count
+	summary.FlakinessRate = float64(summary.FlakyTasks) / count
+
+	return summary, tasks
+}
+
+// countsAsAttempt reports whether result is scored as a run of its task.
+func countsAsAttempt(result *EvalResult) bool {
+	return !result.Skipped || result.SkipReason.CountsAsNotPassed()
+}
+
+// attemptKey identifies the task of a result within a run: the runs of a task
+// share it, while its variants don't.
+func attemptKey(result *EvalResult) string {
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%d\x00%s\x00%s", result.TaskPath, result.TaskName, result.AllowedToolsMode, result.PromptVariant, result.Locale, result.Environment)
+}
+
+// passAtK estimates the chance that at least one of k runs of a task passes
+// from n runs of which c passed, as 1 - C(n-c, k) / C(n, k), without
+// computing the binomial coefficients, which overflow for many runs.
+func passAtK(n, c, k int) float64 {
+	if n-c < k {
+		return 1
+	}
+	fail := 1.0
+	for i := n - c + 1; i <= n; i++ {
+		fail *= 1 - float64(k)/float64(i)
+	}
+	return 1 - fail
+}
@@ -0,0 +1,141 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeAttempts(t *testing.T) {
+	run := func(name string, passed bool) *EvalResult {
+		return &EvalResult{TaskPath: name + ".yaml", TaskName: name, TaskPassed: passed, AllAssertionsPassed: passed}
+	}
+	inEnvironment := func(r *EvalResult, environment string) *EvalResult {
+		r.Environment = environment
+		return r
+	}
+
+	tests := map[string]struct {
+		results  []*EvalResult
+		expected *AttemptsSummary
+	}{
+		"single runs": {
+			results: []*EvalResult{run("a", true), run("b", false)},
+		},
+		"repeated tasks": {
+			results: []*EvalResult{
+				run("a", true), run("a", false), run("a", false), run("a", false),
+				run("b", true), run("b", true), run("b", true),
+				run("c", false), run("c", false), run("c", false),
+				run("d", true),
+			},
+			expected: &AttemptsSummary{
+				K:             3,
+				PassAt1:       (0.25 + 1 + 0) / 3,
+				PassAtK:       (0.75 + 1 + 0) / 3,
+				FlakyTasks:    1,
+				FlakinessRate: 1.0 / 3,
+				Tasks: []TaskAttempts{
+					// 1 - C(3,3)/C(4,3)
+					{TaskName: "a", Outcomes: []bool{true, false, false, false}, Passed: 1, PassAt1: 0.25, PassAtK: 0.75, Flaky: true},
+					{TaskName: "b", Outcomes: []bool{true, true, true}, Passed: 3, PassAt1: 1, PassAtK: 1},
+					{TaskName: "c", Outcomes: []bool{false, false, false}, Passed: 0, PassAt1: 0, PassAtK: 0},
+				},
+			},
+		},
+		"environments are scored apart": {
+			results: []*EvalResult{
+				inEnvironment(run("a", true), "dev"), inEnvironment(run("a", true), "dev"),
+				inEnvironment(run("a", true), "prod"), inEnvironment(run("a", false), "prod"),
+			},
+			expected: &AttemptsSummary{
+				K:             2,
+				PassAt1:       0.75,
+				PassAtK:       1,
+				FlakyTasks:    1,
+				FlakinessRate: 0.5,
+				Tasks: []TaskAttempts{
+					{TaskName: "a", Environment: "dev", Outcomes: []bool{true, true}, Passed: 2, PassAt1: 1, PassAtK: 1},
+					{TaskName: "a", Environment: "prod", Outcomes: []bool{true, false}, Passed: 1, PassAt1: 0.5, PassAtK: 1, Flaky: true},
+				},
+			},
+		},
+		"runs with failed assertions did not pass": {
+			results: []*EvalResult{
+				run("a", true),
+				{TaskPath: "a.yaml", TaskName: "a", TaskPassed: true},
+			},
+			expected: &AttemptsSummary{
+				K:             2,
+				PassAt1:       0.5,
+				PassAtK:       1,
+				FlakyTasks:    1,
+				FlakinessRate: 1,
+				Tasks: []TaskAttempts{
+					{TaskName: "a", Outcomes: []bool{true, false}, Passed: 1, PassAt1: 0.5, PassAtK: 1, Flaky: true},
+				},
+			},
+		},
+		"skipped runs are left out": {
+			results: []*EvalResult{
+				run("a", true),
+				{TaskPath: "a.yaml", TaskName: "a", Skipped: true, SkipReason: SkipReasonRequirementsUnmet},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			summary := SummarizeAttempts(tc.results)
+			if tc.expected == nil {
+				assert.Nil(t, summary)
+				return
+			}
+			require.NotNil(t, summary)
+			assert.Equal(t, tc.expected.K, summary.K)
+			assert.InDelta(t, tc.expected.PassAt1, summary.PassAt1, 1e-9)
+			assert.InDelta(t, tc.expected.PassAtK, summary.PassAtK, 1e-9)
+			assert.Equal(t, tc.expected.FlakyTasks, summary.FlakyTasks)
+			assert.InDelta(t, tc.expected.FlakinessRate, summary.FlakinessRate, 1e-9)
+			assert.Equal(t, tc.expected.Tasks, summary.Tasks)
+		})
+	}
+}
+
+func TestRecordAttempts(t *testing.T) {
+	run := func(name string, passed bool) *EvalResult {
+		return &EvalResult{TaskPath: name + ".yaml", TaskName: name, TaskPassed: passed, AllAssertionsPassed: passed}
+	}
+	results := []*EvalResult{run("a", true), run("a", false), run("b", true)}
+
+	summary := RecordAttempts(results)
+	require.NotNil(t, summary)
+	assert.Len(t, summary.Tasks, 1)
+
+	expected := &AttemptStats{K: 2, PassAt1: 0.5, PassAtK: 1, Flaky: true}
+	assert.Equal(t, expected, results[0].Attempts)
+	assert.Equal(t, expected, results[1].Attempts)
+	assert.Nil(t, results[2].Attempts, "tasks that ran once are not scored")
+}
+
+func TestPassAtK(t *testing.T) {
+	tests := map[string]struct {
+		n, c, k  int
+		expected float64
+	}{
+		"all passed":            {n: 5, c: 5, k: 1, expected: 1},
+		"none passed":           {n: 5, c: 0, k: 3, expected: 0},
+		"pass@1 is the rate":    {n: 4, c: 1, k: 1, expected: 0.25},
+		"k equals n":            {n: 3, c: 1, k: 3, expected: 1},
+		"fewer failures than k": {n: 5, c: 3, k: 3, expected: 1},
+		// 1 - C(3,2)/C(5,2) = 1 - 3/10
+		"unbiased estimate": {n: 5, c: 2, k: 2, expected: 0.7},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.InDelta(t, tc.expected, passAtK(tc.n, tc.c, tc.k), 1e-9)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package eval
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mcpchecker/mcpchecker/pkg/tokens"
+)
+
+// BudgetConfig caps the total agent and judge usage of a run. Once a limit is
+// reached, tasks that have not started yet are skipped.
+type BudgetConfig struct {
+	// MaxTokens is the maximum number of input and output tokens (0 = unlimited)
+	MaxTokens int64 `json:"maxTokens,omitempty"`
+
+	// MaxCostUSD is the maximum estimated cost in USD (0 = unlimited). Requires pricing.
+	MaxCostUSD float64 `json:"maxCostUSD,omitempty"`
+
+	// Pricing is used to estimate cost from token counts
+	Pricing *TokenPricing `json:"pricing,omitempty"`
+}
+
+// TokenPricing is the price in USD per million tokens.
+type TokenPricing struct {
+	InputPerMillionTokens  float64 `json:"inputPerMillionTokens"`
+	OutputPerMillionTokens float64 `json:"outputPerMillionTokens"`
+}
+
+// cost returns the estimated cost in USD of the tokens.
+func (p *TokenPricing) cost(inputTokens, outputTokens int64) float64 {
+	return float64(inputTokens)/1e6*p.InputPerMillionTokens +
+		float64(outputTokens)/1e6*p.OutputPerMillionTokens
+}
+
+// Validate checks that the limits are not negative and that a cost limit has pricing.
+func (c *BudgetConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.MaxTokens < 0 {
+		return fmt.Errorf("maxTokens must be >= 0, got %d", c.MaxTokens)
+	}
+	if c.MaxCostUSD < 0 {
+		return fmt.Errorf("maxCostUSD must be >= 0, got %g", c.MaxCostUSD)
+	}
+	if c.MaxCostUSD > 0 && c.Pricing == nil {
+		return fmt.Errorf("maxCostUSD requires pricing to be set")
+	}
+	if c.Pricing != nil && (c.Pricing.InputPerMillionTokens < 0 || c.Pricing.OutputPerMillionTokens < 0) {
+		return fmt.Errorf("pricing must be >= 0")
+	}
+	return nil
+}
+
+// BudgetSummary reports the run budget and how much of it was used.
+type BudgetSummary struct {
+	MaxTokens  int64   `json:"maxTokens,omitempty"`
+	MaxCostUSD float64 `json:"maxCostUSD,omitempty"`
+
+	UsedTokens int64 `json:"usedTokens"`
+	// UsedCostUSD is only set when pricing is configured
+	UsedCostUSD float64 `json:"usedCostUSD,omitempty"`
+
+	Exceeded bool `json:"exceeded"`
+	// SkippedRuns is the number of task runs that were not started because the budget was exceeded
+	SkippedRuns int `json:"skippedRuns,omitempty"`
+}
+
+// budgetTracker accumulates usage across tasks. It is safe for concurrent use,
+// and a nil tracker never reports the budget as exceeded.
+type budgetTracker struct {
+	cfg *BudgetConfig
+
+	mu           sync.Mutex
+	inputTokens  int64
+	outputTokens int64
+	skippedRuns  int
+}
+
+func newBudgetTracker(cfg *BudgetConfig) *budgetTracker {
+	if cfg == nil || (cfg.MaxTokens == 0 && cfg.MaxCostUSD == 0) {
+		return nil
+	}
+	return &budgetTracker{cfg: cfg}
+}
+
+// record adds the agent and judge usage of a completed run.
+func (b *budgetTracker) record(result *EvalResult) {
+	if b == nil || result == nil {
+		return
+	}
+
+	input, output := resultTokens(result)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inputTokens += input
+	b.outputTokens += output
+}
+
+// resultTokens returns the agent and judge tokens of a run.
+func resultTokens(result *EvalResult) (input, output int64) {
+	if est := result.TokenEstimate; est != nil {
+		// Prefer usage reported by the agent over the tokenizer estimate
+		if est.Source == tokens.SourceActual && est.Actual != nil {
+			input += est.Actual.InputTokens
+			output += est.Actual.OutputTokens
+		} else {
+			input += est.InputTokens
+			output += est.OutputTokens
+		}
+	}
+	if result.JudgeTokenUsage != nil {
+		input += result.JudgeTokenUsage.InputTokens
+		output += result.JudgeTokenUsage.OutputTokens
+	}
+	return input, output
+}
+
+// skip records that a run was not started, and returns the skipped result for it.
+func (b *budgetTracker) skip(tc taskConfig) *EvalResult {
+	b.mu.Lock()
+	b.skippedRuns++
+	b.mu.Unlock()
+
+	result := newSkippedResult(tc, SkipReasonBudgetExceeded, "run budget exceeded")
+	result.SkippedOverBudget = true
+	return result
+}
+
+// exceeded reports whether the tokens or estimated cost used so far reached a limit.
+func (b *budgetTracker) exceeded() bool {
+	if b == nil {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.exceededLocked()
+}
+
+func (b *budgetTracker) exceededLocked() bool {
+	if b.cfg.MaxTokens > 0 && b.inputTokens+b.outputTokens >= b.cfg.MaxTokens {
+		return true
+	}
+	return b.cfg.MaxCostUSD > 0 && b.costLocked() >= b.cfg.MaxCostUSD
+}
+
+func (b *budgetTracker) costLocked() float64 {
+	if b.cfg.Pricing == nil {
+		return 0
+	}
+	return b.cfg.Pricing.cost(b.inputTokens, b.outputTokens)
+}
+
+// summary returns the current budget state, or nil for a nil tracker.
+func (b *budgetTracker) summary() *BudgetSummary {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return &BudgetSummary{
+		MaxTokens:   b.cfg.MaxTokens,
+		MaxCostUSD:  b.cfg.MaxCostUSD,
+		UsedTokens:  b.inputTokens + b.outputTokens,
+		UsedCostUSD: b.costLocked(),
+		Exceeded:    b.exceededLocked(),
+		SkippedRuns: b.skippedRuns,
+	}
+}
@@ -0,0 +1,162 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudgetConfigValidate(t *testing.T) {
+	tests := map[string]struct {
+		cfg         *BudgetConfig
+		errContains string
+	}{
+		"nil config": {},
+		"token limit": {
+			cfg: &BudgetConfig{MaxTokens: 1000},
+		},
+		"cost limit with pricing": {
+			cfg: &BudgetConfig{MaxCostUSD: 5, Pricing: &TokenPricing{InputPerMillionTokens: 3, OutputPerMillionTokens: 15}},
+		},
+		"negative tokens": {
+			cfg:         &BudgetConfig{MaxTokens: -1},
+			errContains: "maxTokens",
+		},
+		"negative cost": {
+			cfg:         &BudgetConfig{MaxCostUSD: -1},
+			errContains: "maxCostUSD",
+		},
+		"cost limit without pricing": {
+			cfg:         &BudgetConfig{MaxCostUSD: 5},
+			errContains: "requires pricing",
+		},
+		"negative pricing": {
+			cfg:         &BudgetConfig{MaxTokens: 10, Pricing: &TokenPricing{InputPerMillionTokens: -1}},
+			errContains: "pricing",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestBudgetTracker(t *testing.T) {
+	actual := &EvalResult{
+		TokenEstimate: &tokens.Estimate{
+			Source:       tokens.SourceActual,
+			InputTokens:  1,
+			OutputTokens: 1,
+			Actual:       &tokens.Usage{InputTokens: 600_000, OutputTokens: 100_000},
+		},
+		JudgeTokenUsage: &tokens.Usage{InputTokens: 50_000, OutputTokens: 10_000},
+	}
+	estimated := &EvalResult{
+		TokenEstimate: &tokens.Estimate{InputTokens: 200_000, OutputTokens: 40_000},
+	}
+	pricing := &TokenPricing{InputPerMillionTokens: 3, OutputPerMillionTokens: 15}
+
+	tests := map[string]struct {
+		cfg            *BudgetConfig
+		results        []*EvalResult
+		expectNil      bool
+		expectExceeded bool
+		expectTokens   int64
+		expectCost     float64
+	}{
+		"no limits": {
+			cfg:       &BudgetConfig{Pricing: pricing},
+			expectNil: true,
+		},
+		"under token limit": {
+			cfg:          &BudgetConfig{MaxTokens: 1_000_000},
+			results:      []*EvalResult{actual},
+			expectTokens: 760_000,
+		},
+		"token limit reached with estimates": {
+			cfg:            &BudgetConfig{MaxTokens: 1_000_000},
+			results:        []*EvalResult{actual, estimated},
+			expectExceeded: true,
+			expectTokens:   1_000_000,
+		},
+		"cost limit reached": {
+			// 850k input * $3/M + 150k output * $15/M = $4.80
+			cfg:            &BudgetConfig{MaxCostUSD: 4.5, Pricing: pricing},
+			results:        []*EvalResult{actual, estimated},
+			expectExceeded: true,
+			expectTokens:   1_000_000,
+			expectCost:     4.8,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tracker := newBudgetTracker(tc.cfg)
+			if tc.expectNil {
+				assert.Nil(t, tracker)
+				assert.False(t, tracker.exceeded())
+				assert.Nil(t, tracker.summary())
+				return
+			}
+			require.NotNil(t, tracker)
+
+			for _, result := range tc.results {
+				tracker.record(result)
+			}
+
+			assert.Equal(t, tc.expectExceeded, tracker.exceeded())
+			summary := tracker.summary()
+			assert.Equal(t, tc.expectTokens, summary.UsedTokens)
+			assert.InDelta(t, tc.expectCost, summary.UsedCostUSD, 1e-9)
+			assert.Equal(t, tc.expectExceeded, summary.Exceeded)
+		})
+	}
+}
+
+func TestExecuteTaskSkipsOverBudget(t *testing.T) {
+	var events []ProgressEvent
+	runner := &evalRunner{
+		spec:              &EvalSpec{},
+		runs:              2,
+		runsExplicitlySet: true,
+		budget:            newBudgetTracker(&BudgetConfig{MaxTokens: 10}),
+		progressCallback:  func(e ProgressEvent) { events = append(events, e) },
+	}
+	runner.budget.record(&EvalResult{TokenEstimate: &tokens.Estimate{InputTokens: 10}})
+
+	tc := taskConfig{
+		path: "task.yaml",
+		spec: &task.TaskConfig{Metadata: task.TaskMetadata{Name: "over-budget", Difficulty: "easy"}},
+	}
+
+	// The agent runner is never used because both runs are skipped
+	results := runner.executeTask(context.Background(), nil, tc)
+
+	require.Len(t, results, 2)
+	for i, result := range results {
+		assert.True(t, result.SkippedOverBudget)
+		assert.True(t, result.Skipped)
+		assert.Equal(t, SkipReasonBudgetExceeded, result.SkipReason)
+		assert.Equal(t, "skipped: run budget exceeded", result.TaskError)
+		assert.False(t, result.TaskPassed)
+		assert.Equal(t, "over-budget", result.TaskName)
+		assert.Equal(t, i, result.RunIndex)
+		assert.Equal(t, 2, result.TotalRuns)
+	}
+
+	require.Len(t, events, 2)
+	assert.Equal(t, EventTaskSkippedOverBudget, events[0].Type)
+	assert.Equal(t, 2, runner.budget.summary().SkippedRuns)
+}
@@ -0,0 +1,440 @@
+package eval
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/lockfile"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+const (
+	// BundleManifestName is the name of the manifest of a bundle, at its root
+	BundleManifestName = "mcpchecker-bundle.json"
+
+	// BundleVersion is the version of the bundle format
+	BundleVersion = 1
+)
+
+// BundleManifest describes the contents of a bundle: an eval config with the
+// files it references, packed in a gzipped tarball that can be run on another
+// machine.
+type BundleManifest struct {
+	Version           int       `json:"version"`
+	MCPCheckerVersion string    `json:"mcpcheckerVersion"`
+	CreatedAt         time.Time `json:"createdAt"`
+
+	// Name is the name of the eval
+	Name string `json:"name"`
+	// Eval is the path of the eval config in the bundle
+	Eval string `json:"eval"`
+
+	// Files are the files of the bundle, other than the manifest
+	Files []BundleFile `json:"files"`
+
+	// Extensions are the packages of the extensions of the eval, which are
+	// not bundled unless they are files relative to the eval config
+	Extensions map[string]string `json:"extensions,omitempty"`
+	// Lockfile pins the versions of the extensions and task sources, if the
+	// eval has a lockfile
+	Lockfile *lockfile.Lockfile `json:"lockfile,omitempty"`
+}
+
+// BundleFile is a file of a bundle.
+type BundleFile struct {
+	// Path is the path of the file in the bundle, with forward slashes
+	Path string `json:"path"`
+	// SHA256 is the hash of the content of the file, as sha256:<hex>
+	SHA256 string `json:"sha256"`
+	// Mode is the permission bits of the file
+	Mode fs.FileMode `json:"mode"`
+}
+
+// IsBundle reports whether path names a bundle rather than an eval config.
+func IsBundle(path string) bool {
+	return strings.HasSuffix(path, ".tgz") || strings.HasSuffix(path, ".tar.gz")
+}
+
+// BundleFiles returns the files that running the eval of the config at path
+// reads: the config, the MCP configs of the eval and its environments, agent
+// and judge files, step libraries, skills, the lockfile, extensions that are
+// files relative to the config, and the local task files with their prompt,
+// reply and script files. Tasks of git sources are fetched when the eval runs,
+// and are not included.
+func BundleFiles(spec *EvalSpec, path string) ([]string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{absPath}
+	add := func(paths ...string) {
+		for _, p := range paths {
+			if p != "" {
+				files = append(files, p)
+			}
+		}
+	}
+
+	cfg := spec.Config
+	add(cfg.McpConfigFile)
+	if cfg.Agent != nil && cfg.Agent.Type == "file" {
+		add(cfg.Agent.Path)
+	}
+	if cfg.LLMJudge != nil {
+		if cfg.LLMJudge.AgentRef != nil && cfg.LLMJudge.AgentRef.Type == "file" {
+			add(cfg.LLMJudge.AgentRef.Path)
+		}
+		if cfg.LLMJudge.Prompts != nil {
+			add(cfg.LLMJudge.Prompts.SystemFile, cfg.LLMJudge.Prompts.UserFile)
+		}
+	}
+
+	lockPath := filepath.Join(spec.BasePath(), lockfile.FileName)
+	if _, err := os.Stat(lockPath); err == nil {
+		add(lockPath)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Extensions)) {
+		if ext := cfg.Extensions[name]; ext != nil {
+			add(localExtensionFile(ext.Package, spec.BasePath()))
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Environments)) {
+		env := cfg.Environments[name]
+		add(env.McpConfigFile)
+		for _, alias := range slices.Sorted(maps.Keys(env.Extensions)) {
+			if ext := env.Extensions[alias]; ext != nil {
+				add(localExtensionFile(ext.Package, spec.BasePath()))
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.StepLibraries)) {
+		lib, err := task.LibraryFromFile(cfg.StepLibraries[name])
+		if err != nil {
+			return nil, err
+		}
+		add(cfg.StepLibraries[name])
+		add(lib.Files()...)
+	}
+
+	if cfg.Skills != nil {
+		for _, src := range cfg.Skills.Sources {
+			skillFiles, err := walkFiles(src.Path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read skills at %s: %w", src.Path, err)
+			}
+			add(skillFiles...)
+		}
+	}
+
+	for i := range cfg.TaskSets {
+		ts := &cfg.TaskSets[i]
+		if ts.Source != "" {
+			continue
+		}
+		paths, err := ts.matchPaths()
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			taskSpec, err := task.FromFile(p)
+			if errors.Is(err, util.ErrWrongKind) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to load task at path %s: %w", p, err)
+			}
+			add(p)
+			add(taskSpec.Files()...)
+		}
+	}
+
+	seen := make(map[string]bool, len(files))
+	unique := files[:0]
+	for _, file := range files {
+		if file, err = filepath.Abs(file); err != nil {
+			return nil, err
+		}
+		if !seen[file] {
+			seen[file] = true
+			unique = append(unique, file)
+		}
+	}
+	return unique, nil
+}
+
+// localExtensionFile returns the file of an extension package that is a path
+// relative to basePath, or an empty string for other packages.
+func localExtensionFile(pkg, basePath string) string {
+	path := strings.TrimPrefix(pkg, "file://")
+	if strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") || (path != pkg && !filepath.IsAbs(path) && !strings.HasPrefix(path, "~/")) {
+		return filepath.Join(basePath, path)
+	}
+	return ""
+}
+
+// WriteBundle writes the bundle of the eval config at path, with the files
+// and any extra files or directories, to w. The files are stored relative to
+// the closest directory that contains all of them, so that the relative paths
+// between them are kept.
+func WriteBundle(w io.Writer, path string, extra []string, version string) (*BundleManifest, error) {
+	spec, err := FromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	files, err := BundleFiles(spec, path)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range extra {
+		extraFiles, err := walkFiles(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range extraFiles {
+			if file, err = filepath.Abs(file); err != nil {
+				return nil, err
+			}
+			if !slices.Contains(files, file) {
+				files = append(files, file)
+			}
+		}
+	}
+
+	root := commonDir(files)
+	manifest := &BundleManifest{
+		Version:           BundleVersion,
+		MCPCheckerVersion: version,
+		CreatedAt:         time.Now().UTC(),
+		Name:              spec.Metadata.Name,
+	}
+	for _, name := range slices.Sorted(maps.Keys(spec.Config.Extensions)) {
+		if ext := spec.Config.Extensions[name]; ext != nil && ext.Package != "" {
+			if manifest.Extensions == nil {
+				manifest.Extensions = make(map[string]string)
+			}
+			manifest.Extensions[name] = ext.Package
+		}
+	}
+	if lf, err := lockfile.FromFile(filepath.Join(spec.BasePath(), lockfile.FileName)); err == nil {
+		manifest.Lockfile = lf
+	}
+
+	contents := make(map[string][]byte, len(files))
+	for i, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return nil, err
+		}
+		rel = filepath.ToSlash(rel)
+		sum := sha256.Sum256(data)
+		manifest.Files = append(manifest.Files, BundleFile{
+			Path:   rel,
+			SHA256: "sha256:" + hex.EncodeToString(sum[:]),
+			Mode:   info.Mode().Perm(),
+		})
+		contents[rel] = data
+		if i == 0 {
+			manifest.Eval = rel
+		}
+	}
+	slices.SortFunc(manifest.Files, func(a, b BundleFile) int { return strings.Compare(a.Path, b.Path) })
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	writeEntry := func(name string, mode fs.FileMode, data []byte) error {
+		hdr := &tar.Header{
+			Name:    name,
+			Mode:    int64(mode),
+			Size:    int64(len(data)),
+			ModTime: manifest.CreatedAt,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	if err := writeEntry(BundleManifestName, 0644, manifestData); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	for _, file := range manifest.Files {
+		if err := writeEntry(file.Path, file.Mode, contents[file.Path]); err != nil {
+			return nil, fmt.Errorf("failed to write bundle: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return manifest, nil
+}
+
+// ExtractBundle extracts the bundle at path into dir, checking its files
+// against the hashes of its manifest. The eval config of the bundle is at
+// filepath.Join(dir, manifest.Eval).
+func ExtractBundle(path, dir string) (*BundleManifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle %s: %w", path, err)
+	}
+	tr := tar.NewReader(gz)
+
+	var manifest *BundleManifest
+	hashes := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle %s: %w", path, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("bundle entry %s is not a regular file", hdr.Name)
+		}
+		name, err := bundlePath(hdr.Name)
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle entry %s: %w", hdr.Name, err)
+		}
+
+		if name == BundleManifestName {
+			manifest = &BundleManifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return nil, fmt.Errorf("invalid bundle manifest: %w", err)
+			}
+			continue
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(target, data, fs.FileMode(hdr.Mode).Perm()); err != nil {
+			return nil, fmt.Errorf("failed to extract %s: %w", name, err)
+		}
+		sum := sha256.Sum256(data)
+		hashes[name] = "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("%s is not an mcpchecker bundle: it has no %s", path, BundleManifestName)
+	}
+	if manifest.Version > BundleVersion {
+		return nil, fmt.Errorf("bundle version %d is not supported by this version of mcpchecker (at most %d)", manifest.Version, BundleVersion)
+	}
+	for _, file := range manifest.Files {
+		hash, ok := hashes[file.Path]
+		if !ok {
+			return nil, fmt.Errorf("bundle is missing %s", file.Path)
+		}
+		if hash != file.SHA256 {
+			return nil, fmt.Errorf("bundle file %s does not match its hash in the manifest", file.Path)
+		}
+		delete(hashes, file.Path)
+	}
+	if len(hashes) > 0 {
+		return nil, fmt.Errorf("bundle file %s is not in the manifest", slices.Sorted(maps.Keys(hashes))[0])
+	}
+	if _, err := bundlePath(manifest.Eval); err != nil || !slices.ContainsFunc(manifest.Files, func(f BundleFile) bool { return f.Path == manifest.Eval }) {
+		return nil, fmt.Errorf("bundle manifest names eval config %q, which is not in the bundle", manifest.Eval)
+	}
+	return manifest, nil
+}
+
+// bundlePath checks that name is a relative path inside the bundle and
+// returns it cleaned.
+func bundlePath(name string) (string, error) {
+	clean := filepath.ToSlash(filepath.Clean(filepath.FromSlash(name)))
+	if name == "" || filepath.IsAbs(filepath.FromSlash(name)) || strings.HasPrefix(name, "/") || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("bundle entry %q is outside the bundle", name)
+	}
+	return clean, nil
+}
+
+// commonDir returns the closest directory that contains all of the absolute
+// paths files, the first of which is the eval config.
+func commonDir(files []string) string {
+	root := filepath.Dir(files[0])
+	for _, file := range files[1:] {
+		for !isWithin(root, file) {
+			parent := filepath.Dir(root)
+			if parent == root {
+				break
+			}
+			root = parent
+		}
+	}
+	return root
+}
+
+// isWithin reports whether path is inside dir.
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// walkFiles returns path, if it is a file, or the files under it.
+func walkFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files, err
+}
@@ -0,0 +1,223 @@
+package eval
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeBundleFiles writes files, keyed by their path relative to a temporary
+// directory, and returns the directory.
+func writeBundleFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		mode := os.FileMode(0644)
+		if filepath.Ext(name) == ".sh" || filepath.Base(name) == "ext" {
+			mode = 0755
+		}
+		require.NoError(t, os.WriteFile(path, []byte(content), mode))
+	}
+	return dir
+}
+
+var bundleEvalFiles = map[string]string{
+	"evals/eval.yaml": `kind: Eval
+metadata:
+  name: bundle-test
+config:
+  mcpConfigFile: ../mcp.json
+  extensions:
+    local:
+      package: ./bin/ext
+    remote:
+      package: github.com/org/ext@v1.0.0
+  stepLibraries:
+    common: libs/common.yaml
+  taskSets:
+    - glob: ../tasks/*.yaml
+`,
+	"evals/bin/ext":         "#!/bin/sh\n",
+	"evals/mcpchecker.lock": "version: 1\n",
+	"evals/libs/common.yaml": `kind: StepLibrary
+apiVersion: mcpchecker/v1alpha2
+spec:
+  sequences:
+    check:
+      steps:
+        - script:
+            file: check.sh
+`,
+	"evals/libs/check.sh": "exit 0\n",
+	"mcp.json":            `{"mcpServers": {}}`,
+	"tasks/a.yaml": `kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: a
+spec:
+  setup:
+    - script:
+        file: setup.sh
+  prompt:
+    file: prompt.md
+`,
+	"tasks/prompt.md":       "Do something",
+	"tasks/setup.sh":        "exit 0\n",
+	"tasks/other-eval.yaml": "kind: Eval\nmetadata:\n  name: other\nconfig: {}\n",
+	"unrelated.txt":         "not bundled",
+	"extra/helper.sh":       "exit 0\n",
+}
+
+func TestWriteBundle(t *testing.T) {
+	dir := writeBundleFiles(t, bundleEvalFiles)
+
+	var buf bytes.Buffer
+	manifest, err := WriteBundle(&buf, filepath.Join(dir, "evals/eval.yaml"), []string{filepath.Join(dir, "extra")}, "v1.2.3")
+	require.NoError(t, err)
+
+	var paths []string
+	for _, file := range manifest.Files {
+		paths = append(paths, file.Path)
+	}
+	assert.Equal(t, []string{
+		"evals/bin/ext",
+		"evals/eval.yaml",
+		"evals/libs/check.sh",
+		"evals/libs/common.yaml",
+		"evals/mcpchecker.lock",
+		"extra/helper.sh",
+		"mcp.json",
+		"tasks/a.yaml",
+		"tasks/prompt.md",
+		"tasks/setup.sh",
+	}, paths)
+	assert.Equal(t, "evals/eval.yaml", manifest.Eval)
+	assert.Equal(t, "bundle-test", manifest.Name)
+	assert.Equal(t, "v1.2.3", manifest.MCPCheckerVersion)
+	assert.Equal(t, map[string]string{"local": "./bin/ext", "remote": "github.com/org/ext@v1.0.0"}, manifest.Extensions)
+	require.NotNil(t, manifest.Lockfile)
+	assert.Equal(t, 1, manifest.Lockfile.Version)
+
+	bundlePath := filepath.Join(t.TempDir(), "bundle.tgz")
+	require.NoError(t, os.WriteFile(bundlePath, buf.Bytes(), 0644))
+
+	out := t.TempDir()
+	extracted, err := ExtractBundle(bundlePath, out)
+	require.NoError(t, err)
+	assert.Equal(t, manifest.Files, extracted.Files)
+
+	spec, err := FromFile(filepath.Join(out, extracted.Eval))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(out, "mcp.json"), spec.Config.McpConfigFile)
+
+	info, err := os.Stat(filepath.Join(out, "evals/bin/ext"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm(), "file modes are kept")
+
+	data, err := os.ReadFile(filepath.Join(out, "tasks/prompt.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "Do something", string(data))
+}
+
+func TestWriteBundleMissingFile(t *testing.T) {
+	files := map[string]string{}
+	for name, content := range bundleEvalFiles {
+		files[name] = content
+	}
+	delete(files, "tasks/prompt.md")
+	dir := writeBundleFiles(t, files)
+
+	_, err := WriteBundle(&bytes.Buffer{}, filepath.Join(dir, "evals/eval.yaml"), nil, "v1.2.3")
+	assert.ErrorContains(t, err, "prompt.md")
+}
+
+// tarEntry is an entry of a tarball written by writeTarball.
+type tarEntry struct {
+	name string
+	data string
+}
+
+func writeTarball(t *testing.T, entries ...tarEntry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, entry := range entries {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: entry.name, Mode: 0644, Size: int64(len(entry.data))}))
+		_, err := tw.Write([]byte(entry.data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	path := filepath.Join(t.TempDir(), "bundle.tgz")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+	return path
+}
+
+func TestExtractBundleErrors(t *testing.T) {
+	manifest := func(files ...BundleFile) string {
+		data, err := json.Marshal(&BundleManifest{Version: BundleVersion, Eval: "eval.yaml", Files: files})
+		require.NoError(t, err)
+		return string(data)
+	}
+	evalFile := BundleFile{Path: "eval.yaml", SHA256: "sha256:6d6d2d4bbe8fdc4b1d9cdb4c3a1dfac1e4bde6e4d5b2f1ed7a98b0b2a7bd0ba4"}
+
+	tests := map[string]struct {
+		entries []tarEntry
+		wantErr string
+	}{
+		"no manifest": {
+			entries: []tarEntry{{name: "eval.yaml", data: "kind: Eval"}},
+			wantErr: "is not an mcpchecker bundle",
+		},
+		"path outside the bundle": {
+			entries: []tarEntry{{name: "../eval.yaml", data: "kind: Eval"}},
+			wantErr: "is outside the bundle",
+		},
+		"modified file": {
+			entries: []tarEntry{
+				{name: BundleManifestName, data: manifest(evalFile)},
+				{name: "eval.yaml", data: "kind: Eval"},
+			},
+			wantErr: "does not match its hash",
+		},
+		"missing file": {
+			entries: []tarEntry{{name: BundleManifestName, data: manifest(evalFile)}},
+			wantErr: "bundle is missing eval.yaml",
+		},
+		"file not in the manifest": {
+			entries: []tarEntry{
+				{name: BundleManifestName, data: manifest()},
+				{name: "extra.sh", data: "exit 0"},
+			},
+			wantErr: "extra.sh is not in the manifest",
+		},
+		"newer version": {
+			entries: []tarEntry{{name: BundleManifestName, data: `{"version": 99}`}},
+			wantErr: "bundle version 99 is not supported",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ExtractBundle(writeTarball(t, tc.entries...), t.TempDir())
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestIsBundle(t *testing.T) {
+	assert.True(t, IsBundle("eval.tgz"))
+	assert.True(t, IsBundle("dir/eval.tar.gz"))
+	assert.False(t, IsBundle("eval.yaml"))
+}
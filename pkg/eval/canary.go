@@ -0,0 +1,154 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
+)
+
+// CanaryConfig runs every task against two versions of an MCP server of the
+// MCP config, such as kubernetes-v1 and kubernetes-v2, with the same agent,
+// to validate an upgrade of the server before rolling it out. Tasks see
+// each version under the name Server, and run once with each version as
+// the environment named after it.
+type CanaryConfig struct {
+	// Server is the name that tasks and assertions use for the server
+	Server string `json:"server"`
+
+	// Baseline and Candidate name the MCP servers of the MCP config with the
+	// current and the new version of the server
+	Baseline  string `json:"baseline"`
+	Candidate string `json:"candidate"`
+}
+
+// Validate checks that the canary config names the server and two different
+// versions of it.
+func (c *CanaryConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Server == "" {
+		return fmt.Errorf("server is required")
+	}
+	if c.Baseline == "" || c.Candidate == "" {
+		return fmt.Errorf("baseline and candidate are required")
+	}
+	if c.Baseline == c.Candidate {
+		return fmt.Errorf("baseline and candidate must be different servers, got %q", c.Baseline)
+	}
+	return nil
+}
+
+// isVersion reports whether name is the baseline or the candidate server.
+func (c *CanaryConfig) isVersion(name string) bool {
+	return c != nil && name != "" && (name == c.Baseline || name == c.Candidate)
+}
+
+// canaryTasks returns each of tasks twice, to run against the baseline and
+// then the candidate server.
+func canaryTasks(tasks []taskConfig, canary *CanaryConfig) []taskConfig {
+	if canary == nil {
+		return tasks
+	}
+
+	canaried := make([]taskConfig, 0, 2*len(tasks))
+	for _, version := range []string{canary.Baseline, canary.Candidate} {
+		for _, tc := range tasks {
+			tc.environment = version
+			canaried = append(canaried, tc)
+		}
+	}
+	return canaried
+}
+
+// setUpCanaryEnvironment sets up the environment of the version of the
+// canary server, which has the dependencies of the run with only that
+// version of the server, named as the server.
+func (r *evalRunner) setUpCanaryEnvironment(ctx context.Context, version string) (*environment, error) {
+	canary := r.spec.Config.Canary
+
+	mcpManager, ok := r.deps.McpManager()
+	if !ok {
+		return nil, fmt.Errorf("canary: MCP config is required")
+	}
+	if _, ok := mcpManager.Get(version); !ok {
+		return nil, fmt.Errorf("canary: MCP server %q is not configured", version)
+	}
+	if _, ok := mcpManager.Get(canary.Server); ok && !canary.isVersion(canary.Server) {
+		return nil, fmt.Errorf("canary: server %q is already an MCP server of the MCP config", canary.Server)
+	}
+
+	mcpConfig, err := loadMcpConfig(r.spec)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := *r.deps
+	deps.McpClients = newCanaryManager(mcpManager, canary, version)
+	env := &environment{
+		name:      version,
+		mcpConfig: canaryMcpConfig(mcpConfig, canary, version),
+		deps:      &deps,
+		close:     func() {},
+	}
+
+	if r.poolProxies {
+		env.proxyPool, err = r.startProxyPool(ctx, deps.McpClients)
+		if err != nil {
+			return nil, fmt.Errorf("canary %q: %w", version, err)
+		}
+		pool := env.proxyPool
+		env.close = func() { _ = pool.Close() }
+	}
+
+	return env, nil
+}
+
+// canaryManager is the MCP clients of a run with only one version of the
+// canary server, named as the server.
+type canaryManager struct {
+	clients map[string]*mcpclient.Client
+}
+
+var _ mcpclient.Manager = &canaryManager{}
+
+func newCanaryManager(manager mcpclient.Manager, canary *CanaryConfig, version string) *canaryManager {
+	clients := manager.GetAll()
+	client := clients[version]
+	delete(clients, canary.Baseline)
+	delete(clients, canary.Candidate)
+	clients[canary.Server] = client
+	return &canaryManager{clients: clients}
+}
+
+func (m *canaryManager) Get(name string) (*mcpclient.Client, bool) {
+	c, ok := m.clients[name]
+	return c, ok
+}
+
+func (m *canaryManager) GetAll() map[string]*mcpclient.Client {
+	return maps.Clone(m.clients)
+}
+
+// Close does nothing, as the clients are those of the run, which closes them.
+func (m *canaryManager) Close(context.Context) error {
+	return nil
+}
+
+// canaryMcpConfig returns the MCP config cfg with only the version of the
+// canary server, named as the server.
+func canaryMcpConfig(cfg *mcpclient.MCPConfig, canary *CanaryConfig, version string) *mcpclient.MCPConfig {
+	if cfg == nil {
+		return nil
+	}
+	servers := maps.Clone(cfg.MCPServers)
+	server := servers[version]
+	delete(servers, canary.Baseline)
+	delete(servers, canary.Candidate)
+	if server != nil {
+		servers[canary.Server] = server
+	}
+	return &mcpclient.MCPConfig{MCPServers: servers}
+}
@@ -0,0 +1,195 @@
+package eval
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCanary(t *testing.T) {
+	tests := map[string]struct {
+		yaml        string
+		canary      *CanaryConfig
+		errContains string
+	}{
+		"canary": {
+			yaml: `kind: Eval
+config:
+  canary:
+    server: kubernetes
+    baseline: kubernetes-v1
+    candidate: kubernetes-v2
+`,
+			canary: &CanaryConfig{Server: "kubernetes", Baseline: "kubernetes-v1", Candidate: "kubernetes-v2"},
+		},
+		"missing server": {
+			yaml: `kind: Eval
+config:
+  canary:
+    baseline: kubernetes-v1
+    candidate: kubernetes-v2
+`,
+			errContains: "invalid canary: server is required",
+		},
+		"missing candidate": {
+			yaml: `kind: Eval
+config:
+  canary:
+    server: kubernetes
+    baseline: kubernetes-v1
+`,
+			errContains: "invalid canary: baseline and candidate are required",
+		},
+		"same versions": {
+			yaml: `kind: Eval
+config:
+  canary:
+    server: kubernetes
+    baseline: kubernetes-v1
+    candidate: kubernetes-v1
+`,
+			errContains: `invalid canary: baseline and candidate must be different servers, got "kubernetes-v1"`,
+		},
+		"with environments": {
+			yaml: `kind: Eval
+config:
+  canary:
+    server: kubernetes
+    baseline: kubernetes-v1
+    candidate: kubernetes-v2
+  environments:
+    dev: {}
+`,
+			errContains: "invalid canary: can't be combined with environments",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec, err := Read([]byte(tc.yaml), t.TempDir())
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.canary, spec.Config.Canary)
+		})
+	}
+}
+
+func TestCanaryTasks(t *testing.T) {
+	tasks := []taskConfig{
+		{spec: &task.TaskConfig{Metadata: task.TaskMetadata{Name: "a"}}},
+		{spec: &task.TaskConfig{Metadata: task.TaskMetadata{Name: "b"}}},
+	}
+
+	assert.Equal(t, tasks, canaryTasks(tasks, nil))
+
+	canaried := canaryTasks(tasks, &CanaryConfig{Server: "kubernetes", Baseline: "v1", Candidate: "v2"})
+	require.Len(t, canaried, 4)
+	var keys []string
+	for _, tc := range canaried {
+		keys = append(keys, tc.key())
+	}
+	assert.Equal(t, []string{"a@v1", "b@v1", "a@v2", "b@v2"}, keys)
+	assert.Empty(t, tasks[0].environment, "the tasks should not be modified")
+}
+
+func TestSetUpCanaryEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	mcpConfigFile := filepath.Join(dir, "mcp.json")
+	require.NoError(t, os.WriteFile(mcpConfigFile, []byte(`{
+  "mcpServers": {
+    "kubernetes-v1": {"type": "http", "url": "http://localhost:8080/mcp"},
+    "kubernetes-v2": {"type": "http", "url": "http://localhost:8081/mcp"},
+    "github": {"type": "http", "url": "http://localhost:8082/mcp"}
+  }
+}`), 0644))
+
+	v1, v2, github := &mcpclient.Client{}, &mcpclient.Client{}, &mcpclient.Client{}
+	mcpManager := &canaryManager{clients: map[string]*mcpclient.Client{
+		"kubernetes-v1": v1,
+		"kubernetes-v2": v2,
+		"github":        github,
+	}}
+
+	tests := map[string]struct {
+		canary      CanaryConfig
+		version     string
+		client      *mcpclient.Client
+		url         string
+		errContains string
+	}{
+		"baseline": {
+			canary:  CanaryConfig{Server: "kubernetes", Baseline: "kubernetes-v1", Candidate: "kubernetes-v2"},
+			version: "kubernetes-v1",
+			client:  v1,
+			url:     "http://localhost:8080/mcp",
+		},
+		"candidate": {
+			canary:  CanaryConfig{Server: "kubernetes", Baseline: "kubernetes-v1", Candidate: "kubernetes-v2"},
+			version: "kubernetes-v2",
+			client:  v2,
+			url:     "http://localhost:8081/mcp",
+		},
+		"server named as a version": {
+			canary:  CanaryConfig{Server: "kubernetes-v1", Baseline: "kubernetes-v1", Candidate: "kubernetes-v2"},
+			version: "kubernetes-v2",
+			client:  v2,
+			url:     "http://localhost:8081/mcp",
+		},
+		"version not configured": {
+			canary:      CanaryConfig{Server: "kubernetes", Baseline: "kubernetes-v1", Candidate: "kubernetes-v3"},
+			version:     "kubernetes-v3",
+			errContains: `canary: MCP server "kubernetes-v3" is not configured`,
+		},
+		"server already configured": {
+			canary:      CanaryConfig{Server: "github", Baseline: "kubernetes-v1", Candidate: "kubernetes-v2"},
+			version:     "kubernetes-v1",
+			errContains: `canary: server "github" is already an MCP server of the MCP config`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			judge := &fakeJudge{}
+			runner := &evalRunner{
+				spec: &EvalSpec{Config: EvalConfig{McpConfigFile: mcpConfigFile, Canary: &tc.canary}},
+				deps: &steps.Dependencies{McpClients: mcpManager, Judge: judge},
+			}
+
+			env, err := runner.setUpEnvironment(context.Background(), tc.version)
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			defer env.close()
+
+			assert.Equal(t, tc.version, env.name)
+			assert.Equal(t, judge, env.deps.Judge)
+
+			envManager, ok := env.deps.McpManager()
+			require.True(t, ok)
+			assert.Equal(t, map[string]*mcpclient.Client{tc.canary.Server: tc.client, "github": github}, envManager.GetAll())
+			client, ok := envManager.Get(tc.canary.Server)
+			assert.True(t, ok)
+			assert.Same(t, tc.client, client)
+
+			require.Len(t, env.mcpConfig.MCPServers, 2)
+			assert.Equal(t, tc.url, env.mcpConfig.MCPServers[tc.canary.Server].URL)
+
+			require.NoError(t, envManager.Close(context.Background()))
+			assert.Len(t, mcpManager.GetAll(), 3, "the clients of the run should stay open")
+		})
+	}
+}
@@ -0,0 +1,488 @@
+package eval
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/mcpchecker/mcpchecker/pkg/llmagent"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/tokens"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// ChatServerOptions configure a ChatServer.
+type ChatServerOptions struct {
+	// Record receives a ChatRecord per completion, as a line of JSON. Nil
+	// discards them.
+	Record io.Writer
+	// MaxConcurrent limits the completions that run at once. Requests over
+	// the limit wait for a completion to finish. 0 means no limit.
+	MaxConcurrent int
+	// Token is the bearer token that requests must authenticate with, as
+	// the API key of OpenAI clients. Empty accepts every request.
+	Token string
+}
+
+// ChatMessage is a message of an OpenAI chat completion request or response.
+// Content is a string, or an array of content parts of which the text parts
+// are used.
+type ChatMessage struct {
+	Role    string          `json:"role,omitempty"`
+	Content json.RawMessage `json:"content,omitempty"`
+}
+
+// ChatRecord records a completion served by a ChatServer: the request, the
+// prompt the agent was run with, its output and the MCP calls it made.
+type ChatRecord struct {
+	ID            string                  `json:"id"`
+	Time          time.Time               `json:"time"`
+	DurationMs    int64                   `json:"durationMs"`
+	Messages      []ChatMessage           `json:"messages"`
+	Prompt        string                  `json:"prompt,omitempty"`
+	Output        string                  `json:"output"`
+	Error         string                  `json:"error,omitempty"`
+	OutputSteps   []agent.OutputStep      `json:"outputSteps,omitempty"`
+	ToolCalls     []agent.ToolCallSummary `json:"toolCalls,omitempty"`
+	CallHistory   *mcpproxy.CallHistory   `json:"callHistory,omitempty"`
+	TokenEstimate *tokens.Estimate        `json:"tokenEstimate,omitempty"`
+}
+
+// ChatServer serves an OpenAI-compatible chat completions API that runs the
+// agent of an eval config, with its MCP servers behind the same proxy as in
+// eval runs, for each completion. Tools that speak the OpenAI API, such as
+// load testing tools and playgrounds, can then drive the agent pipeline that
+// evals use. Each completion is recorded as a ChatRecord.
+type ChatServer struct {
+	agent        agent.Runner
+	mcpManager   mcpclient.Manager
+	proxyOptions []mcpproxy.ServerOption
+	sem          chan struct{}
+	token        string
+	closers      []func()
+
+	recordMu sync.Mutex
+	record   io.Writer
+}
+
+// NewChatServer connects to the MCP servers of spec and creates its agent.
+// Close disconnects from the MCP servers.
+func NewChatServer(ctx context.Context, spec *EvalSpec, opts ChatServerOptions) (*ChatServer, error) {
+	if spec.Config.Agent == nil {
+		return nil, fmt.Errorf("agent must be specified in eval config")
+	}
+	agentSpec, err := agent.ResolveAgentRef(spec.Config.Agent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agent spec: %w", err)
+	}
+	// The conversations share a limiter, like the tasks of a run
+	rateLimiter, err := llmagent.NewRateLimiter(spec.Config.RateLimit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate limit config: %w", err)
+	}
+
+	runner, err := agent.NewRunnerForSpec(agentSpec,
+		agent.WithRateLimiter(rateLimiter), agent.WithModelRetry(spec.Config.ModelRetry))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent runner from spec: %w", err)
+	}
+	runner, err = withSkills(runner, agentSpec, spec.Config.Skills)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &ChatServer{
+		agent:  runner,
+		record: opts.Record,
+		token:  opts.Token,
+	}
+	if opts.MaxConcurrent > 0 {
+		s.sem = make(chan struct{}, opts.MaxConcurrent)
+	}
+
+	mcpConfig, err := loadMcpConfig(spec)
+	if err != nil {
+		return nil, err
+	}
+	if mcpConfig == nil {
+		return s, nil
+	}
+
+	mcpManager, err := mcpclient.NewManager(ctx, mcpConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MCP servers: %w", err)
+	}
+	s.closers = append(s.closers, func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		_ = mcpManager.Close(closeCtx)
+	})
+	if s.mcpManager, err = mcpproxy.NewShadowedManager(mcpManager, spec.Config.Shadow); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	listener, err := mcpproxy.NewListener(spec.Config.Proxy)
+	if err != nil {
+		s.Close()
+		return nil, fmt.Errorf("failed to set up mcp proxy listener: %w", err)
+	}
+	s.closers = append(s.closers, func() { _ = listener.Close() })
+	s.proxyOptions = []mcpproxy.ServerOption{mcpproxy.WithListener(listener)}
+
+	if cfg := spec.Config.SummarizeToolResults; cfg != nil {
+		summarizer, err := llmagent.NewSummarizer(ctx, llmagent.Config{
+			Model:        cfg.Model,
+			SystemPrompt: cfg.Prompt,
+			RateLimiter:  rateLimiter,
+			Retry:        spec.Config.ModelRetry,
+		})
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("failed to create tool result summarizer: %w", err)
+		}
+		s.proxyOptions = append(s.proxyOptions, mcpproxy.WithSummarizer(summarizer, cfg.ThresholdTokens))
+	}
+	return s, nil
+}
+
+// Model returns the name the server reports as its model, the name of the
+// agent.
+func (s *ChatServer) Model() string {
+	return s.agent.AgentName()
+}
+
+// Close disconnects from the MCP servers.
+func (s *ChatServer) Close() {
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		s.closers[i]()
+	}
+	s.closers = nil
+}
+
+// Handler returns the HTTP handler of the API, which serves
+// /v1/chat/completions and /v1/models, to requests with the bearer token of
+// the server if it has one.
+//
+// Browsers can reach a server on a loopback address from any web page, so
+// requests from browsers, which have an Origin header, and completion requests
+// that aren't JSON, which browsers can send cross-site without a preflight,
+// are rejected. A server without a token, which only listens on loopback
+// addresses, also rejects requests for another host, which DNS rebinding
+// would send.
+func (s *ChatServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/chat/completions", s.handleCompletion)
+	mux.HandleFunc("GET /v1/models", s.handleModels)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Origin") != "" {
+			writeChatError(w, http.StatusForbidden, "invalid_request_error", "requests from browsers are not allowed")
+			return
+		}
+		if s.token == "" && !isLoopbackRequestHost(r.Host) {
+			writeChatError(w, http.StatusForbidden, "invalid_request_error", fmt.Sprintf("host %q is not a loopback address", r.Host))
+			return
+		}
+		if s.token != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeChatError(w, http.StatusUnauthorized, "invalid_request_error", "missing or invalid bearer token")
+				return
+			}
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackRequestHost reports whether host, the Host of a request with an
+// optional port, is a loopback address.
+func isLoopbackRequestHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return util.IsLoopbackHost(host)
+}
+
+// chatCompletionRequest are the fields of a chat completion request that are
+// used. The model and sampling parameters are ignored: the agent decides them.
+type chatCompletionRequest struct {
+	Messages []ChatMessage `json:"messages"`
+	Stream   bool          `json:"stream,omitempty"`
+}
+
+type chatChoice struct {
+	Index        int          `json:"index"`
+	Message      *ChatMessage `json:"message,omitempty"`
+	Delta        *ChatMessage `json:"delta,omitempty"`
+	FinishReason *string      `json:"finish_reason"`
+}
+
+type chatUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+type chatCompletion struct {
+	ID      string       `json:"id"`
+	Object  string       `json:"object"`
+	Created int64        `json:"created"`
+	Model   string       `json:"model"`
+	Choices []chatChoice `json:"choices"`
+	Usage   *chatUsage   `json:"usage,omitempty"`
+}
+
+func (s *ChatServer) handleModels(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"object": "list",
+		"data": []map[string]any{{
+			"id":       s.Model(),
+			"object":   "model",
+			"owned_by": "mcpchecker",
+		}},
+	})
+}
+
+func (s *ChatServer) handleCompletion(w http.ResponseWriter, r *http.Request) {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		writeChatError(w, http.StatusUnsupportedMediaType, "invalid_request_error", "Content-Type must be application/json")
+		return
+	}
+	var req chatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeChatError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	prompt, err := chatPrompt(req.Messages)
+	if err != nil {
+		writeChatError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	record := s.Complete(r.Context(), req.Messages, prompt)
+	if record.Error != "" {
+		// The error may include the output of the agent, like the record
+		writeChatError(w, http.StatusInternalServerError, "server_error", util.RedactSecrets(record.Error))
+		return
+	}
+
+	content, _ := json.Marshal(record.Output)
+	stop := "stop"
+	completion := chatCompletion{
+		ID:      "chatcmpl-" + record.ID,
+		Object:  "chat.completion",
+		Created: record.Time.Unix(),
+		Model:   s.Model(),
+	}
+	if est := record.TokenEstimate; est != nil {
+		completion.Usage = &chatUsage{
+			PromptTokens:     est.InputTokens,
+			CompletionTokens: est.OutputTokens,
+			TotalTokens:      est.TotalTokens,
+		}
+	}
+
+	if !req.Stream {
+		completion.Choices = []chatChoice{{
+			Message:      &ChatMessage{Role: "assistant", Content: content},
+			FinishReason: &stop,
+		}}
+		writeJSON(w, http.StatusOK, completion)
+		return
+	}
+
+	// The agent doesn't stream its final message, so it is sent as a single
+	// chunk, followed by a chunk that ends the completion
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	completion.Object = "chat.completion.chunk"
+	usage := completion.Usage
+	completion.Usage = nil
+	completion.Choices = []chatChoice{{Delta: &ChatMessage{Role: "assistant", Content: content}}}
+	writeEvent(w, completion)
+	completion.Choices = []chatChoice{{Delta: &ChatMessage{}, FinishReason: &stop}}
+	completion.Usage = usage
+	writeEvent(w, completion)
+	fmt.Fprint(w, "data: [DONE]\n\n")
+}
+
+// Complete runs the agent with prompt, through new proxy servers of the MCP
+// servers, and records and returns the result. messages are the messages of
+// the request the prompt was made from.
+func (s *ChatServer) Complete(ctx context.Context, messages []ChatMessage, prompt string) *ChatRecord {
+	record := &ChatRecord{
+		ID:       uuid.NewString(),
+		Messages: messages,
+		Prompt:   prompt,
+	}
+
+	if s.sem != nil {
+		select {
+		case s.sem <- struct{}{}:
+			defer func() { <-s.sem }()
+		case <-ctx.Done():
+			record.Time = time.Now()
+			record.Error = ctx.Err().Error()
+			s.writeRecord(record)
+			return record
+		}
+	}
+
+	record.Time = time.Now()
+	err := s.runAgent(ctx, record)
+	record.DurationMs = time.Since(record.Time).Milliseconds()
+	if err != nil {
+		record.Error = err.Error()
+	}
+	s.writeRecord(record)
+	return record
+}
+
+func (s *ChatServer) runAgent(ctx context.Context, record *ChatRecord) (err error) {
+	defer task.RecoverPanic(&err)
+
+	manager := mcpproxy.NewEmptyServerManager()
+	if s.mcpManager != nil {
+		manager, err = mcpproxy.NewServerManager(ctx, s.mcpManager, s.proxyOptions...)
+		if err != nil {
+			return fmt.Errorf("failed to create mcp proxy server manager: %w", err)
+		}
+		if err := manager.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start mcp proxy servers: %w", err)
+		}
+	}
+	defer manager.Close()
+
+	result, err := s.agent.WithMcpServerInfo(manager).RunTask(ctx, record.Prompt)
+	record.CallHistory = manager.GetAllCallHistory()
+	if err != nil {
+		return fmt.Errorf("failed to run agent: %w", err)
+	}
+
+	record.OutputSteps = result.GetOutput()
+	record.Output = agent.FinalMessageFromSteps(record.OutputSteps)
+	record.ToolCalls = result.GetToolCalls()
+	estimate := result.GetTokenEstimate()
+	record.TokenEstimate = &estimate
+	return nil
+}
+
+func (s *ChatServer) writeRecord(record *ChatRecord) {
+	if s.record == nil {
+		return
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	data = append(util.RedactSecretBytes(data), '\n')
+
+	s.recordMu.Lock()
+	defer s.recordMu.Unlock()
+	_, _ = s.record.Write(data)
+}
+
+// chatPrompt returns the prompt of the agent for messages. The agent gets a
+// single prompt, so a request with more than one message, such as a system
+// message or earlier turns of a conversation, is written as a transcript that
+// ends with the last user message.
+func chatPrompt(messages []ChatMessage) (string, error) {
+	if len(messages) == 0 {
+		return "", fmt.Errorf("messages must not be empty")
+	}
+	if last := messages[len(messages)-1]; last.Role != "user" {
+		return "", fmt.Errorf("the last message must be a user message, got %q", last.Role)
+	}
+
+	parts := make([]string, 0, len(messages))
+	for i, msg := range messages {
+		text, err := messageText(msg.Content)
+		if err != nil {
+			return "", fmt.Errorf("message %d: %w", i, err)
+		}
+		if len(messages) == 1 {
+			return text, nil
+		}
+
+		var speaker string
+		switch msg.Role {
+		case "system", "developer":
+			speaker = "System"
+		case "user":
+			speaker = "User"
+		case "assistant":
+			speaker = "Assistant"
+		default:
+			return "", fmt.Errorf("message %d: unsupported role %q", i, msg.Role)
+		}
+		parts = append(parts, speaker+": "+text)
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+// messageText returns the text of the content of a message, which is either a
+// string or an array of content parts.
+func messageText(content json.RawMessage) (string, error) {
+	if len(content) == 0 || string(content) == "null" {
+		return "", nil
+	}
+
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return text, nil
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(content, &parts); err != nil {
+		return "", errors.New("content must be a string or an array of content parts")
+	}
+	var texts []string
+	for _, part := range parts {
+		if part.Type != "text" {
+			return "", fmt.Errorf("unsupported content part type %q", part.Type)
+		}
+		texts = append(texts, part.Text)
+	}
+	return strings.Join(texts, "\n"), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeEvent(w http.ResponseWriter, v any) {
+	data, _ := json.Marshal(v)
+	fmt.Fprintf(w, "data: %s\n\n", data)
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// writeChatError writes an error in the format of the OpenAI API.
+func writeChatError(w http.ResponseWriter, status int, kind, message string) {
+	writeJSON(w, status, map[string]any{
+		"error": map[string]any{
+			"message": message,
+			"type":    kind,
+		},
+	})
+}
@@ -0,0 +1,268 @@
+package eval
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatPrompt(t *testing.T) {
+	tests := map[string]struct {
+		messages string
+		want     string
+		wantErr  string
+	}{
+		"single user message": {
+			messages: `[{"role": "user", "content": "List the pods"}]`,
+			want:     "List the pods",
+		},
+		"content parts": {
+			messages: `[{"role": "user", "content": [{"type": "text", "text": "List"}, {"type": "text", "text": "the pods"}]}]`,
+			want:     "List\nthe pods",
+		},
+		"conversation": {
+			messages: `[
+				{"role": "system", "content": "Be brief"},
+				{"role": "user", "content": "List the pods"},
+				{"role": "assistant", "content": "nginx"},
+				{"role": "user", "content": "Delete it"}
+			]`,
+			want: "System: Be brief\n\nUser: List the pods\n\nAssistant: nginx\n\nUser: Delete it",
+		},
+		"no messages": {
+			messages: `[]`,
+			wantErr:  "messages must not be empty",
+		},
+		"last message not from the user": {
+			messages: `[{"role": "user", "content": "Hi"}, {"role": "assistant", "content": "Hello"}]`,
+			wantErr:  `the last message must be a user message, got "assistant"`,
+		},
+		"unsupported role": {
+			messages: `[{"role": "tool", "content": "{}"}, {"role": "user", "content": "Hi"}]`,
+			wantErr:  `message 0: unsupported role "tool"`,
+		},
+		"image content": {
+			messages: `[{"role": "user", "content": [{"type": "image_url", "image_url": {"url": "x"}}]}]`,
+			wantErr:  `unsupported content part type "image_url"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var messages []ChatMessage
+			require.NoError(t, json.Unmarshal([]byte(tc.messages), &messages))
+
+			got, err := chatPrompt(messages)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+// failingAgentRunner is a fakeAgentRunner whose runs fail
+type failingAgentRunner struct {
+	fakeAgentRunner
+}
+
+func (f *failingAgentRunner) RunTask(context.Context, string) (agent.AgentResult, error) {
+	return nil, errors.New("agent crashed: invalid token chat-s3cr3t-token")
+}
+
+func (f *failingAgentRunner) WithMcpServerInfo(mcpproxy.ServerManager) agent.Runner {
+	return f
+}
+
+func (f *failingAgentRunner) WithActivity(*util.Activity) agent.Runner {
+	return f
+}
+
+func postCompletion(t *testing.T, url, body string) (*http.Response, string) {
+	t.Helper()
+	resp, err := http.Post(url+"/v1/chat/completions", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(data)
+}
+
+func TestChatServer(t *testing.T) {
+	var record bytes.Buffer
+	s := &ChatServer{agent: &fakeAgentRunner{}, record: &record}
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	resp, body := postCompletion(t, server.URL, `{"model": "any", "messages": [{"role": "user", "content": "List the pods"}]}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+	var completion chatCompletion
+	require.NoError(t, json.Unmarshal([]byte(body), &completion))
+	assert.Equal(t, "chat.completion", completion.Object)
+	assert.Equal(t, "fake-agent", completion.Model)
+	require.Len(t, completion.Choices, 1)
+	assert.JSONEq(t, `"done"`, string(completion.Choices[0].Message.Content))
+	assert.Equal(t, "stop", *completion.Choices[0].FinishReason)
+
+	var got ChatRecord
+	require.NoError(t, json.Unmarshal(record.Bytes(), &got))
+	assert.Equal(t, "chatcmpl-"+got.ID, completion.ID)
+	assert.Equal(t, "List the pods", got.Prompt)
+	assert.Equal(t, "done", got.Output)
+	assert.NotNil(t, got.CallHistory)
+
+	resp, body = postCompletion(t, server.URL, `{"stream": true, "messages": [{"role": "user", "content": "List the pods"}]}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	var events []string
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			events = append(events, data)
+		}
+	}
+	require.Len(t, events, 3)
+	require.NoError(t, json.Unmarshal([]byte(events[0]), &completion))
+	assert.Equal(t, "chat.completion.chunk", completion.Object)
+	assert.JSONEq(t, `"done"`, string(completion.Choices[0].Delta.Content))
+	assert.Equal(t, "[DONE]", events[2])
+
+	resp, body = postCompletion(t, server.URL, `{"messages": []}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, body, "messages must not be empty")
+
+	resp, err := http.Get(server.URL + "/v1/models")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var models struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&models))
+	require.Len(t, models.Data, 1)
+	assert.Equal(t, "fake-agent", models.Data[0].ID)
+}
+
+func TestChatServerToken(t *testing.T) {
+	s := &ChatServer{agent: &fakeAgentRunner{}, token: "serve-t0ken"}
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	tests := map[string]struct {
+		authorization string
+		status        int
+	}{
+		"valid token": {authorization: "Bearer serve-t0ken", status: http.StatusOK},
+		"wrong token": {authorization: "Bearer other", status: http.StatusUnauthorized},
+		"no token":    {status: http.StatusUnauthorized},
+		"basic auth":  {authorization: "Basic serve-t0ken", status: http.StatusUnauthorized},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, server.URL+"/v1/models", nil)
+			require.NoError(t, err)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tc.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestChatServerRejectsCrossSiteRequests(t *testing.T) {
+	s := &ChatServer{agent: &fakeAgentRunner{}}
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	tests := map[string]struct {
+		contentType string
+		origin      string
+		host        string
+		status      int
+	}{
+		"json":                 {contentType: "application/json", status: http.StatusOK},
+		"json with charset":    {contentType: "application/json; charset=utf-8", status: http.StatusOK},
+		"localhost host":       {contentType: "application/json", host: "localhost:8080", status: http.StatusOK},
+		"text/plain":           {contentType: "text/plain", status: http.StatusUnsupportedMediaType},
+		"form":                 {contentType: "application/x-www-form-urlencoded", status: http.StatusUnsupportedMediaType},
+		"no content type":      {status: http.StatusUnsupportedMediaType},
+		"origin":               {contentType: "application/json", origin: "https://example.com", status: http.StatusForbidden},
+		"null origin":          {contentType: "application/json", origin: "null", status: http.StatusForbidden},
+		"rebinded host":        {contentType: "application/json", host: "attacker.example.com:8080", status: http.StatusForbidden},
+		"non-loopback ip host": {contentType: "application/json", host: "10.0.0.1", status: http.StatusForbidden},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, server.URL+"/v1/chat/completions",
+				strings.NewReader(`{"messages": [{"role": "user", "content": "List the pods"}]}`))
+			require.NoError(t, err)
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			if tc.origin != "" {
+				req.Header.Set("Origin", tc.origin)
+			}
+			if tc.host != "" {
+				req.Host = tc.host
+			}
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tc.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestChatServerTokenAllowsOtherHosts(t *testing.T) {
+	s := &ChatServer{agent: &fakeAgentRunner{}, token: "serve-t0ken"}
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/v1/models", nil)
+	require.NoError(t, err)
+	req.Host = "evals.example.com"
+	req.Header.Set("Authorization", "Bearer serve-t0ken")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestChatServerAgentError(t *testing.T) {
+	util.RegisterSecret("chat-s3cr3t-token")
+
+	var record bytes.Buffer
+	s := &ChatServer{agent: &failingAgentRunner{}, record: &record}
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	resp, body := postCompletion(t, server.URL, `{"messages": [{"role": "user", "content": "List the pods"}]}`)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Contains(t, body, "failed to run agent: agent crashed: invalid token ***")
+	assert.NotContains(t, body, "chat-s3cr3t-token")
+
+	var got ChatRecord
+	require.NoError(t, json.Unmarshal(record.Bytes(), &got))
+	assert.Equal(t, "failed to run agent: agent crashed: invalid token ***", got.Error)
+}
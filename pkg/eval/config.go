@@ -1,17 +1,23 @@
 package eval
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	gopath "path"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"sigs.k8s.io/yaml"
 
 	"github.com/mcpchecker/mcpchecker/pkg/agent"
 	"github.com/mcpchecker/mcpchecker/pkg/extension"
+	"github.com/mcpchecker/mcpchecker/pkg/llmagent"
 	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/secrets"
 	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
@@ -47,6 +53,10 @@ type EvalConfig struct {
 	// Sources defines cross-repo eval sources keyed by name
 	Sources map[string]*SourceSpec `json:"sources,omitempty"`
 
+	// StepLibraries maps names to the files of step libraries, whose step
+	// sequences tasks run with use: <name>/<sequence> steps
+	StepLibraries map[string]string `json:"stepLibraries,omitempty"`
+
 	// MCP configuration
 	McpConfigFile string                       `json:"mcpConfigFile"`
 	LLMJudge      *llmjudge.LLMJudgeEvalConfig `json:"llmJudge"`
@@ -58,6 +68,71 @@ type EvalConfig struct {
 	// Individual tasks can override these via spec.limits.
 	DefaultTaskLimits *util.Limits `json:"defaultTaskLimits,omitempty"`
 
+	// RateLimit limits model API calls made by builtin LLM agents and the LLM judge,
+	// shared across all parallel tasks
+	RateLimit *llmagent.RateLimitConfig `json:"rateLimit,omitempty"`
+
+	// ModelRetry controls retries of model API calls made by builtin LLM agents and
+	// the LLM judge after transient provider errors. LLMJudge.Retry replaces it for
+	// the judge when set.
+	ModelRetry *llmagent.RetryConfig `json:"modelRetry,omitempty"`
+
+	// Budget caps the total agent and judge token usage or estimated cost of a run
+	Budget *BudgetConfig `json:"budget,omitempty"`
+
+	// AdaptiveParallelism lowers the number of parallel workers while model
+	// calls are rate limited or slow, and raises it again once they are healthy
+	AdaptiveParallelism *AdaptiveParallelismConfig `json:"adaptiveParallelism,omitempty"`
+
+	// Output limits the size of the results file
+	Output *OutputConfig `json:"output,omitempty"`
+
+	// AllowedTools sets which tools the agent is allowed to call: "all" (the
+	// default) or "assertions", the tools of the toolsUsed and requireAny
+	// assertions of each task
+	AllowedTools AllowedToolsMode `json:"allowedTools,omitempty"`
+
+	// PoolProxies keeps the MCP proxy servers running for the whole run instead
+	// of starting new ones for every task, recording the calls of each task apart
+	PoolProxies bool `json:"poolProxies,omitempty"`
+
+	// Proxy sets the address the MCP proxy servers listen on and optional TLS,
+	// for agents running in containers or remote sandboxes
+	Proxy *mcpproxy.ListenConfig `json:"proxy,omitempty"`
+
+	// SummarizeToolResults makes the MCP proxy servers return a summary by a
+	// model instead of large tool results, keeping the original in the history
+	SummarizeToolResults *mcpproxy.SummarizeConfig `json:"summarizeToolResults,omitempty"`
+
+	// Shadow mirrors the tool calls to MCP servers to shadow servers, to
+	// validate new implementations of the servers against the traffic of the
+	// run without affecting its results
+	Shadow []mcpproxy.ShadowConfig `json:"shadow,omitempty"`
+
+	// PromptVariants configures prompt robustness runs, which run every task
+	// with paraphrases of its prompt written by a model
+	PromptVariants *PromptVariantsConfig `json:"promptVariants,omitempty"`
+
+	// Locale selects the prompt of tasks with a prompt per locale ("en" by
+	// default). Tasks without a prompt for it are skipped.
+	Locale string `json:"locale,omitempty"`
+
+	// Gates require minimum task pass rates by difficulty and labels, checked
+	// after the run
+	Gates []GateConfig `json:"gates,omitempty"`
+
+	// ResultsSink streams a record of each task run to a data warehouse
+	ResultsSink *ResultsSinkConfig `json:"resultsSink,omitempty"`
+
+	// Environments are named targets, each with its own MCP servers,
+	// environment variables and extensions, that task sets can select to run
+	// their tasks against
+	Environments map[string]*EnvironmentConfig `json:"environments,omitempty"`
+
+	// Canary runs every task against the baseline and the candidate version
+	// of an MCP server, to compare their outcomes
+	Canary *CanaryConfig `json:"canary,omitempty"`
+
 	// Advanced mode: different assertion sets
 	TaskSets []TaskSet `json:"taskSets,omitempty"`
 }
@@ -93,9 +168,27 @@ type TaskSet struct {
 	// Mutually exclusive with absolute local paths.
 	Source string `json:"source,omitempty"`
 
-	// Optional label selector - filters tasks by labels
-	// All specified labels must match (AND logic)
-	LabelSelector map[string]string `json:"labelSelector,omitempty"`
+	// Recursive matches the file name of Glob in all subdirectories of the
+	// directories it matches, or, with Path, every YAML file under the directory
+	Recursive bool `json:"recursive,omitempty"`
+
+	// Exclude lists globs of task files to leave out. A glob matching a
+	// directory excludes every task file under it.
+	Exclude []string `json:"exclude,omitempty"`
+
+	// Optional label selector - filters tasks by labels, either as a map of
+	// labels that must all match or as a set-based selector string
+	LabelSelector LabelSelector `json:"labelSelector,omitempty"`
+
+	// Environment names the entry of EvalConfig.Environments the tasks run
+	// against. Task sets selecting different environments run the same tasks
+	// once in each.
+	Environment string `json:"environment,omitempty"`
+
+	// Repeat runs each task of the task set this many times, instead of the
+	// runs of its metadata, to score pass@k and flakiness. The --runs and
+	// --repeat flags override it.
+	Repeat int `json:"repeat,omitempty"`
 
 	Assertions *TaskAssertions `json:"assertions,omitempty"`
 }
@@ -120,8 +213,17 @@ type TaskAssertions struct {
 	// Order assertions
 	CallOrder []CallOrderAssertion `json:"callOrder,omitempty"`
 
+	// Output assertions
+	ToolOutputs []ToolOutputAssertion `json:"toolOutputs,omitempty"`
+	ToolErrors  []ToolErrorAssertion  `json:"toolErrors,omitempty"`
+
 	// Efficiency assertions
 	NoDuplicateCalls bool `json:"noDuplicateCalls,omitempty"`
+	// DuplicateCallArguments sets how NoDuplicateCalls compares the arguments of calls
+	DuplicateCallArguments DuplicateArguments `json:"duplicateCallArguments,omitempty"`
+
+	// Side effect assertions
+	ReadOnly *ReadOnlyAssertion `json:"readOnly,omitempty"`
 
 	// Skill assertions - evaluated against agent tool calls
 	SkillsLoaded    []SkillAssertion `json:"skillsLoaded,omitempty"`
@@ -147,13 +249,97 @@ type ToolAssertion struct {
 	ToolPattern string `json:"toolPattern,omitempty"` // regex pattern
 }
 
+// ToolErrorAssertion checks that a matching tool call failed with the expected
+// error, for negative tasks where the server should reject an invalid operation.
+// A call failed if the server returned a JSON-RPC error or a tool result with
+// isError set.
+type ToolErrorAssertion struct {
+	Server string `json:"server"`
+
+	// Exactly one of Tool or ToolPattern should be set
+	// If neither is set, matches any tool from the server
+	Tool        string `json:"tool,omitempty"`
+	ToolPattern string `json:"toolPattern,omitempty"` // regex pattern
+
+	// Contains is a snippet the error message must contain (optional)
+	Contains string `json:"contains,omitempty"`
+
+	// Code is the JSON-RPC error code the call must have failed with (optional).
+	// Tool results with isError set have no code.
+	Code *int64 `json:"code,omitempty"`
+}
+
+// DuplicateArguments sets how the arguments of two calls to the same tool are
+// compared to decide whether they are duplicates.
+type DuplicateArguments string
+
+const (
+	// DuplicateArgumentsExact compares the arguments as sent by the agent (the default)
+	DuplicateArgumentsExact DuplicateArguments = "exact"
+	// DuplicateArgumentsNormalized compares the arguments as JSON values, ignoring
+	// key order and whitespace
+	DuplicateArgumentsNormalized DuplicateArguments = "normalized"
+	// DuplicateArgumentsIgnore treats any repeated call to the same tool as a duplicate
+	DuplicateArgumentsIgnore DuplicateArguments = "ignore"
+)
+
+// ReadOnlyAssertion checks that the agent only called read-only tools, for tasks
+// where it should diagnose a problem without modifying anything. A tool is
+// read-only if its MCP annotations have readOnlyHint set, unless it is listed
+// in WriteTools. ReadTools marks tools without annotations as read-only.
+//
+// In eval configs, "readOnly: true" classifies tools by their annotations only.
+type ReadOnlyAssertion struct {
+	// ReadTools are treated as read-only, whatever their annotations
+	ReadTools []ToolAssertion `json:"readTools,omitempty"`
+
+	// WriteTools are treated as writes, whatever their annotations. They take
+	// precedence over ReadTools.
+	WriteTools []ToolAssertion `json:"writeTools,omitempty"`
+}
+
+// UnmarshalJSON decodes the assertion from an object or from true.
+func (a *ReadOnlyAssertion) UnmarshalJSON(data []byte) error {
+	var enabled bool
+	if err := json.Unmarshal(data, &enabled); err == nil {
+		if !enabled {
+			return fmt.Errorf("readOnly must be true or an object")
+		}
+		*a = ReadOnlyAssertion{}
+		return nil
+	}
+
+	type readOnlyAssertion ReadOnlyAssertion
+	var decoded readOnlyAssertion
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("readOnly must be true or an object: %w", err)
+	}
+	*a = ReadOnlyAssertion(decoded)
+	return nil
+}
+
+// ToolOutputAssertion checks that a matching tool call returned the expected output.
+// Expected is a golden snippet that must appear in the text content of the result;
+// leading and trailing whitespace is ignored, and it must not be empty.
+type ToolOutputAssertion struct {
+	Server string `json:"server"`
+
+	// Exactly one of Tool or ToolPattern should be set
+	// If neither is set, matches any tool from the server
+	Tool        string `json:"tool,omitempty"`
+	ToolPattern string `json:"toolPattern,omitempty"` // regex pattern
+
+	Expected string `json:"expected"`
+}
+
 type ResourceAssertion struct {
 	Server string `json:"server"`
 
-	// Exactly one of URI or URIPattern should be set
-	// If neither is set, matches any resource from the server
-	URI        string `json:"uri,omitempty"`
-	URIPattern string `json:"uriPattern,omitempty"` // regex pattern
+	// Exactly one of URI, URIPattern or URITemplate should be set
+	// If none is set, matches any resource from the server
+	URI         string `json:"uri,omitempty"`
+	URIPattern  string `json:"uriPattern,omitempty"`  // regex pattern
+	URITemplate string `json:"uriTemplate,omitempty"` // URI template with {var} placeholders and * wildcards
 }
 
 type PromptAssertion struct {
@@ -163,6 +349,10 @@ type PromptAssertion struct {
 	// If neither is set, matches any prompt from the server
 	Prompt        string `json:"prompt,omitempty"`
 	PromptPattern string `json:"promptPattern,omitempty"`
+
+	// Arguments the prompt must have been called with. Values may contain *
+	// wildcards; arguments that are not listed are ignored.
+	Arguments map[string]string `json:"arguments,omitempty"`
 }
 
 type CallOrderAssertion struct {
@@ -174,7 +364,14 @@ type CallOrderAssertion struct {
 func Read(data []byte, basePath string) (*EvalSpec, error) {
 	spec := &EvalSpec{}
 
-	err := yaml.Unmarshal(data, spec)
+	// Secret references may stand in for any value, so they are resolved
+	// before the config is decoded
+	data, err := secrets.NewResolver(basePath).ResolveYAML(context.Background(), data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+
+	err = yaml.Unmarshal(data, spec)
 	if err != nil {
 		return nil, err
 	}
@@ -197,9 +394,95 @@ func Read(data []byte, basePath string) (*EvalSpec, error) {
 			return nil, fmt.Errorf("failed to resolve llm judge agent ref file path: %w", err)
 		}
 	}
+	if spec.Config.LLMJudge != nil && spec.Config.LLMJudge.Prompts != nil {
+		if err := util.ResolveRelativePath(&spec.Config.LLMJudge.Prompts.SystemFile, basePath); err != nil {
+			return nil, fmt.Errorf("failed to resolve llm judge system prompt path: %w", err)
+		}
+		if err := util.ResolveRelativePath(&spec.Config.LLMJudge.Prompts.UserFile, basePath); err != nil {
+			return nil, fmt.Errorf("failed to resolve llm judge user prompt path: %w", err)
+		}
+	}
 	if err := util.ResolveRelativePath(&spec.Config.McpConfigFile, basePath); err != nil {
 		return nil, fmt.Errorf("failed to resolve mcp config file path: %w", err)
 	}
+	for name, path := range spec.Config.StepLibraries {
+		if err := util.ResolveRelativePath(&path, basePath); err != nil {
+			return nil, fmt.Errorf("failed to resolve step library %q path: %w", name, err)
+		}
+		spec.Config.StepLibraries[name] = path
+	}
+
+	if err := spec.Config.RateLimit.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rateLimit: %w", err)
+	}
+	if err := spec.Config.ModelRetry.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid modelRetry: %w", err)
+	}
+	if err := spec.Config.Budget.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid budget: %w", err)
+	}
+	if err := spec.Config.AdaptiveParallelism.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid adaptiveParallelism: %w", err)
+	}
+	if err := spec.Config.Output.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid output: %w", err)
+	}
+	if err := spec.Config.AllowedTools.Validate(); err != nil {
+		return nil, err
+	}
+	if err := spec.Config.Proxy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid proxy: %w", err)
+	}
+	if err := spec.Config.SummarizeToolResults.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid summarizeToolResults: %w", err)
+	}
+	if err := validateShadows(spec.Config.Shadow); err != nil {
+		return nil, err
+	}
+	if err := spec.Config.PromptVariants.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid promptVariants: %w", err)
+	}
+	if err := spec.Config.ResultsSink.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid resultsSink: %w", err)
+	}
+	if err := spec.Config.Canary.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid canary: %w", err)
+	}
+	if spec.Config.Canary != nil && len(spec.Config.Environments) > 0 {
+		return nil, fmt.Errorf("invalid canary: can't be combined with environments")
+	}
+	for i := range spec.Config.Gates {
+		if err := spec.Config.Gates[i].Validate(); err != nil {
+			return nil, fmt.Errorf("invalid gates: %w", err)
+		}
+	}
+	if spec.Config.PromptVariants != nil {
+		if err := util.ResolveRelativePath(&spec.Config.PromptVariants.CacheDir, basePath); err != nil {
+			return nil, fmt.Errorf("failed to resolve promptVariants cacheDir: %w", err)
+		}
+	}
+	if spec.Config.Proxy != nil && spec.Config.Proxy.TLS != nil {
+		tlsCfg := spec.Config.Proxy.TLS
+		for _, path := range []*string{&tlsCfg.CertFile, &tlsCfg.KeyFile, &tlsCfg.CAFile} {
+			if err := util.ResolveRelativePath(path, basePath); err != nil {
+				return nil, fmt.Errorf("failed to resolve proxy tls file path: %w", err)
+			}
+		}
+	}
+
+	for name, env := range spec.Config.Environments {
+		if env == nil {
+			// An environment may be no more than a name for its task sets
+			env = &EnvironmentConfig{}
+			spec.Config.Environments[name] = env
+		}
+		if err := env.resolvePaths(basePath); err != nil {
+			return nil, fmt.Errorf("failed to resolve environment %q mcp config file path: %w", name, err)
+		}
+		if err := validateShadows(env.Shadow); err != nil {
+			return nil, fmt.Errorf("environment %q: %w", name, err)
+		}
+	}
 
 	// Validate source specs
 	for name, src := range spec.Config.Sources {
@@ -243,11 +526,63 @@ func Read(data []byte, basePath string) (*EvalSpec, error) {
 				return nil, fmt.Errorf("failed to resolve task set glob at index %d: %w", i, err)
 			}
 		}
+
+		if err := ts.Assertions.validate(); err != nil {
+			return nil, fmt.Errorf("taskSet[%d]: %w", i, err)
+		}
+
+		if _, ok := spec.Config.Environments[ts.Environment]; ts.Environment != "" && !ok {
+			return nil, fmt.Errorf("taskSet[%d]: unknown environment %q", i, ts.Environment)
+		}
+
+		if ts.Repeat < 0 {
+			return nil, fmt.Errorf("taskSet[%d]: repeat must be >= 0, got %d", i, ts.Repeat)
+		}
+
+		for j := range ts.Exclude {
+			if _, err := filepath.Match(ts.Exclude[j], ""); err != nil {
+				return nil, fmt.Errorf("taskSet[%d]: invalid exclude pattern %q: %w", i, ts.Exclude[j], err)
+			}
+			if ts.Source != "" {
+				continue
+			}
+			if err := util.ResolveRelativePath(&ts.Exclude[j], basePath); err != nil {
+				return nil, fmt.Errorf("failed to resolve task set exclude at index %d: %w", i, err)
+			}
+		}
 	}
 
 	return spec, nil
 }
 
+// validate checks the duplicate call argument mode, the URI templates of
+// resource assertions and that tool output assertions expect some output.
+func (a *TaskAssertions) validate() error {
+	if a == nil {
+		return nil
+	}
+	switch a.DuplicateCallArguments {
+	case "", DuplicateArgumentsExact, DuplicateArgumentsNormalized, DuplicateArgumentsIgnore:
+	default:
+		return fmt.Errorf("invalid duplicateCallArguments %q: must be %q, %q or %q", a.DuplicateCallArguments,
+			DuplicateArgumentsExact, DuplicateArgumentsNormalized, DuplicateArgumentsIgnore)
+	}
+	for _, assertion := range slices.Concat(a.ResourcesRead, a.ResourcesNotRead) {
+		if assertion.URITemplate == "" {
+			continue
+		}
+		if _, err := compileURITemplate(assertion.URITemplate); err != nil {
+			return fmt.Errorf("invalid resource assertion: %w", err)
+		}
+	}
+	for _, assertion := range a.ToolOutputs {
+		if strings.TrimSpace(assertion.Expected) == "" {
+			return fmt.Errorf("invalid tool output assertion for server %q: expected is required", assertion.Server)
+		}
+	}
+	return nil
+}
+
 // taskPath returns the set path value and its kind ("path" or "glob").
 func (ts *TaskSet) taskPath() (string, string) {
 	if ts.Path != "" {
@@ -282,6 +617,15 @@ func (ts *TaskSet) validateSource(sources map[string]*SourceSpec) error {
 		}
 	}
 
+	for _, exclude := range ts.Exclude {
+		if gopath.IsAbs(exclude) || filepath.IsAbs(exclude) {
+			return fmt.Errorf("has source %q but exclude %q is absolute; sourced task sets must use relative paths", ts.Source, exclude)
+		}
+		if err := validateNoPathEscape(exclude); err != nil {
+			return fmt.Errorf("exclude escapes source repo root: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -301,6 +645,27 @@ func validateSourceSpec(name string, src *SourceSpec) error {
 	return nil
 }
 
+// validateShadows checks the shadow configs, and that each server is mirrored
+// to one shadow server at most, which isn't mirrored itself.
+func validateShadows(shadows []mcpproxy.ShadowConfig) error {
+	servers := make(map[string]bool, len(shadows))
+	for i := range shadows {
+		if err := shadows[i].Validate(); err != nil {
+			return fmt.Errorf("invalid shadow[%d]: %w", i, err)
+		}
+		if servers[shadows[i].Server] {
+			return fmt.Errorf("invalid shadow[%d]: server %q is already mirrored", i, shadows[i].Server)
+		}
+		servers[shadows[i].Server] = true
+	}
+	for i := range shadows {
+		if servers[shadows[i].Shadow] {
+			return fmt.Errorf("invalid shadow[%d]: shadow server %q is mirrored itself", i, shadows[i].Shadow)
+		}
+	}
+	return nil
+}
+
 // validateNoPathEscape checks that a relative path does not escape above the root via "../"
 func validateNoPathEscape(p string) error {
 	cleaned := gopath.Clean(p)
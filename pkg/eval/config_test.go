@@ -8,6 +8,10 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 const (
@@ -31,6 +35,23 @@ func TestReadJudgeRefPathResolution(t *testing.T) {
 	assert.Equal(t, filepath.Join(basePath, "agents/judge.yaml"), spec.Config.LLMJudge.AgentRef.Path)
 }
 
+func TestReadJudgePromptsPathResolution(t *testing.T) {
+	basePath, err := os.Getwd()
+	require.NoError(t, err)
+	basePath = filepath.Join(basePath, testDataPath)
+
+	data, err := os.ReadFile(filepath.Join(basePath, "judge-prompts-relative.yaml"))
+	require.NoError(t, err)
+
+	spec, err := Read(data, basePath)
+	require.NoError(t, err)
+
+	require.NotNil(t, spec.Config.LLMJudge)
+	require.NotNil(t, spec.Config.LLMJudge.Prompts)
+	assert.Equal(t, filepath.Join(basePath, "prompts/judge-system.tmpl"), spec.Config.LLMJudge.Prompts.SystemFile)
+	assert.Equal(t, "/abs/judge-user.tmpl", spec.Config.LLMJudge.Prompts.UserFile)
+}
+
 func TestReadSourceSpec(t *testing.T) {
 	basePath, err := os.Getwd()
 	require.NoError(t, err)
@@ -121,6 +142,11 @@ func TestReadSourceSpec(t *testing.T) {
 			expectErr:   true,
 			errContains: "escapes source repo root",
 		},
+		"sourced taskSet with exclude escaping repo root": {
+			file:        "source-exclude-escape.yaml",
+			expectErr:   true,
+			errContains: "exclude escapes source repo root",
+		},
 		"sourced taskSet with glob escaping repo root": {
 			file:        "source-glob-escape.yaml",
 			expectErr:   true,
@@ -153,3 +179,506 @@ func TestReadSourceSpec(t *testing.T) {
 		})
 	}
 }
+
+func TestReadTaskSetExclude(t *testing.T) {
+	basePath := t.TempDir()
+
+	tests := map[string]struct {
+		yaml            string
+		expectedExclude []string
+		errContains     string
+	}{
+		"relative excludes are resolved against the eval file": {
+			yaml: `kind: Eval
+config:
+  taskSets:
+    - glob: tasks/*.yaml
+      recursive: true
+      exclude:
+        - tasks/broken
+        - /abs/tasks/*.yaml
+`,
+			expectedExclude: []string{filepath.Join(basePath, "tasks/broken"), "/abs/tasks/*.yaml"},
+		},
+		"invalid exclude pattern": {
+			yaml: `kind: Eval
+config:
+  taskSets:
+    - glob: tasks/*.yaml
+      exclude:
+        - "tasks/[broken"
+`,
+			errContains: "invalid exclude pattern",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec, err := Read([]byte(tc.yaml), basePath)
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, spec.Config.TaskSets, 1)
+			assert.True(t, spec.Config.TaskSets[0].Recursive)
+			assert.Equal(t, tc.expectedExclude, spec.Config.TaskSets[0].Exclude)
+		})
+	}
+}
+
+func TestReadTaskSetRepeat(t *testing.T) {
+	tests := map[string]struct {
+		yaml        string
+		repeat      int
+		errContains string
+	}{
+		"repeat": {
+			yaml: `kind: Eval
+config:
+  taskSets:
+    - glob: tasks/*.yaml
+      repeat: 5
+`,
+			repeat: 5,
+		},
+		"negative repeat": {
+			yaml: `kind: Eval
+config:
+  taskSets:
+    - glob: tasks/*.yaml
+      repeat: -1
+`,
+			errContains: "taskSet[0]: repeat must be >= 0, got -1",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec, err := Read([]byte(tc.yaml), t.TempDir())
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, spec.Config.TaskSets, 1)
+			assert.Equal(t, tc.repeat, spec.Config.TaskSets[0].Repeat)
+		})
+	}
+}
+
+func TestReadProxy(t *testing.T) {
+	basePath := t.TempDir()
+
+	tests := map[string]struct {
+		yaml        string
+		expectedTLS *mcpproxy.ListenTLSConfig
+		errContains string
+	}{
+		"relative tls files are resolved against the eval file": {
+			yaml: `kind: Eval
+config:
+  proxy:
+    host: 0.0.0.0
+    token: proxy-t0ken
+    tls:
+      certFile: certs/proxy.pem
+      keyFile: /abs/proxy-key.pem
+      caFile: certs/ca.pem
+`,
+			expectedTLS: &mcpproxy.ListenTLSConfig{
+				CertFile: filepath.Join(basePath, "certs/proxy.pem"),
+				KeyFile:  "/abs/proxy-key.pem",
+				CAFile:   filepath.Join(basePath, "certs/ca.pem"),
+			},
+		},
+		"invalid port range": {
+			yaml: `kind: Eval
+config:
+  proxy:
+    minPort: 30010
+    maxPort: 30000
+`,
+			errContains: "invalid proxy",
+		},
+		"all interfaces without token": {
+			yaml: `kind: Eval
+config:
+  proxy:
+    host: 0.0.0.0
+`,
+			errContains: "a token is required",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec, err := Read([]byte(tc.yaml), basePath)
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, spec.Config.Proxy)
+			assert.Equal(t, tc.expectedTLS, spec.Config.Proxy.TLS)
+		})
+	}
+}
+
+func TestReadStepLibraries(t *testing.T) {
+	basePath := t.TempDir()
+
+	spec, err := Read([]byte(`kind: Eval
+config:
+  stepLibraries:
+    common: libs/common.yaml
+    shared: /abs/shared.yaml
+`), basePath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"common": filepath.Join(basePath, "libs/common.yaml"),
+		"shared": "/abs/shared.yaml",
+	}, spec.Config.StepLibraries)
+}
+
+func TestReadSummarizeToolResults(t *testing.T) {
+	tests := map[string]struct {
+		yaml        string
+		expected    *mcpproxy.SummarizeConfig
+		errContains string
+	}{
+		"valid": {
+			yaml: `kind: Eval
+config:
+  summarizeToolResults:
+    model: openai:gpt-5-mini
+    thresholdTokens: 2000
+`,
+			expected: &mcpproxy.SummarizeConfig{Model: "openai:gpt-5-mini", ThresholdTokens: 2000},
+		},
+		"missing threshold": {
+			yaml: `kind: Eval
+config:
+  summarizeToolResults:
+    model: openai:gpt-5-mini
+`,
+			errContains: "invalid summarizeToolResults",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec, err := Read([]byte(tc.yaml), t.TempDir())
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, spec.Config.SummarizeToolResults)
+		})
+	}
+}
+
+func TestReadShadow(t *testing.T) {
+	tests := map[string]struct {
+		yaml        string
+		expected    []mcpproxy.ShadowConfig
+		errContains string
+	}{
+		"valid": {
+			yaml: `kind: Eval
+config:
+  shadow:
+    - server: kubernetes
+      shadow: kubernetes-next
+      record: true
+      timeout: 10s
+`,
+			expected: []mcpproxy.ShadowConfig{{Server: "kubernetes", Shadow: "kubernetes-next", Record: true, Timeout: "10s"}},
+		},
+		"missing shadow": {
+			yaml: `kind: Eval
+config:
+  shadow:
+    - server: kubernetes
+`,
+			errContains: "invalid shadow[0]: server and shadow are required",
+		},
+		"server mirrored twice": {
+			yaml: `kind: Eval
+config:
+  shadow:
+    - server: kubernetes
+      shadow: kubernetes-next
+    - server: kubernetes
+      shadow: kubernetes-rewrite
+`,
+			errContains: `invalid shadow[1]: server "kubernetes" is already mirrored`,
+		},
+		"shadow server mirrored": {
+			yaml: `kind: Eval
+config:
+  shadow:
+    - server: kubernetes
+      shadow: kubernetes-next
+    - server: kubernetes-next
+      shadow: kubernetes-rewrite
+`,
+			errContains: `invalid shadow[0]: shadow server "kubernetes-next" is mirrored itself`,
+		},
+		"invalid environment shadow": {
+			yaml: `kind: Eval
+config:
+  environments:
+    prod:
+      shadow:
+        - server: kubernetes
+          shadow: kubernetes
+`,
+			errContains: `environment "prod": invalid shadow[0]: shadow must be a different server than "kubernetes"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec, err := Read([]byte(tc.yaml), t.TempDir())
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, spec.Config.Shadow)
+		})
+	}
+}
+
+func TestReadPromptVariants(t *testing.T) {
+	tests := map[string]struct {
+		yaml        string
+		expected    *PromptVariantsConfig
+		errContains string
+	}{
+		"valid": {
+			yaml: `kind: Eval
+config:
+  promptVariants:
+    model: openai:gpt-5-mini
+    count: 3
+    seed: 42
+    cacheDir: paraphrases
+`,
+			expected: &PromptVariantsConfig{Model: "openai:gpt-5-mini", Count: 3, Seed: 42, CacheDir: "paraphrases"},
+		},
+		"missing model": {
+			yaml: `kind: Eval
+config:
+  promptVariants:
+    count: 3
+`,
+			errContains: "invalid promptVariants",
+		},
+		"negative count": {
+			yaml: `kind: Eval
+config:
+  promptVariants:
+    model: openai:gpt-5-mini
+    count: -1
+`,
+			errContains: "count must not be negative",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			basePath := t.TempDir()
+			spec, err := Read([]byte(tc.yaml), basePath)
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			tc.expected.CacheDir = filepath.Join(basePath, tc.expected.CacheDir)
+			assert.Equal(t, tc.expected, spec.Config.PromptVariants)
+		})
+	}
+}
+
+func TestReadTaskSetAssertionTemplates(t *testing.T) {
+	tests := map[string]struct {
+		yaml        string
+		errContains string
+	}{
+		"valid uri template and prompt arguments": {
+			yaml: `kind: Eval
+config:
+  taskSets:
+    - glob: tasks/*.yaml
+      assertions:
+        resourcesRead:
+          - server: k8s
+            uriTemplate: "k8s://namespaces/{namespace}/pods/*"
+        promptsUsed:
+          - server: k8s
+            prompt: debug-pod
+            arguments:
+              namespace: "test-*"
+`,
+		},
+		"invalid duplicate call arguments": {
+			yaml: `kind: Eval
+config:
+  taskSets:
+    - glob: tasks/*.yaml
+      assertions:
+        noDuplicateCalls: true
+        duplicateCallArguments: loose
+`,
+			errContains: "invalid duplicateCallArguments",
+		},
+		"invalid uri template": {
+			yaml: `kind: Eval
+config:
+  taskSets:
+    - glob: tasks/*.yaml
+      assertions:
+        resourcesNotRead:
+          - server: k8s
+            uriTemplate: "k8s://secrets/{name"
+`,
+			errContains: "invalid resource assertion",
+		},
+		"empty tool output": {
+			yaml: `kind: Eval
+config:
+  taskSets:
+    - glob: tasks/*.yaml
+      assertions:
+        toolOutputs:
+          - server: k8s
+            tool: pods_list
+            expected: "  "
+`,
+			errContains: `invalid tool output assertion for server "k8s": expected is required`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec, err := Read([]byte(tc.yaml), t.TempDir())
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assertions := spec.Config.TaskSets[0].Assertions
+			require.NotNil(t, assertions)
+			assert.Equal(t, "k8s://namespaces/{namespace}/pods/*", assertions.ResourcesRead[0].URITemplate)
+			assert.Equal(t, map[string]string{"namespace": "test-*"}, assertions.PromptsUsed[0].Arguments)
+		})
+	}
+}
+
+func TestReadOnlyAssertionUnmarshal(t *testing.T) {
+	tests := map[string]struct {
+		yaml        string
+		expected    *ReadOnlyAssertion
+		errContains string
+	}{
+		"true uses annotations only": {
+			yaml:     `readOnly: true`,
+			expected: &ReadOnlyAssertion{},
+		},
+		"object with tool lists": {
+			yaml: `readOnly:
+  readTools:
+    - server: k8s
+      tool: pods_log
+  writeTools:
+    - server: k8s
+      toolPattern: ".*_delete"
+`,
+			expected: &ReadOnlyAssertion{
+				ReadTools:  []ToolAssertion{{Server: "k8s", Tool: "pods_log"}},
+				WriteTools: []ToolAssertion{{Server: "k8s", ToolPattern: ".*_delete"}},
+			},
+		},
+		"false is rejected": {
+			yaml:        `readOnly: false`,
+			errContains: "readOnly must be true or an object",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var assertions TaskAssertions
+			err := yaml.Unmarshal([]byte(tc.yaml), &assertions)
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, assertions.ReadOnly)
+		})
+	}
+}
+
+func TestReadSecretRef(t *testing.T) {
+	t.Setenv("MCPCHECKER_TEST_KUBE_TOKEN", "kube-token-1f3a")
+
+	tests := map[string]struct {
+		yaml        string
+		expectEnv   map[string]string
+		errContains string
+	}{
+		"env secret": {
+			yaml: `kind: Eval
+config:
+  extensions:
+    kubernetes:
+      package: https://github.com/mcpchecker/kubernetes-extension@v0.0.1
+      env:
+        KUBE_TOKEN:
+          secretRef:
+            provider: env
+            name: MCPCHECKER_TEST_KUBE_TOKEN
+        NAMESPACE: default
+`,
+			expectEnv: map[string]string{"KUBE_TOKEN": "kube-token-1f3a", "NAMESPACE": "default"},
+		},
+		"missing secret": {
+			yaml: `kind: Eval
+config:
+  extensions:
+    kubernetes:
+      env:
+        KUBE_TOKEN:
+          secretRef:
+            provider: env
+            name: MCPCHECKER_TEST_UNSET
+`,
+			errContains: "config.extensions.kubernetes.env.KUBE_TOKEN: failed to resolve secret env:MCPCHECKER_TEST_UNSET",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec, err := Read([]byte(tc.yaml), t.TempDir())
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectEnv, spec.Config.Extensions["kubernetes"].Env)
+			assert.Equal(t, "token: ***", util.RedactSecrets("token: kube-token-1f3a"))
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// EnvironmentConfig is a named target that task sets can run their tasks
+// against, such as a dev and a prod-like cluster, so that one eval can run
+// the same tasks against several targets. Its settings apply on top of the
+// eval config to the tasks of the task sets that select it.
+type EnvironmentConfig struct {
+	// McpConfigFile is the MCP config of the environment, used instead of the
+	// one of the eval config
+	McpConfigFile string `json:"mcpConfigFile,omitempty"`
+
+	// Env sets environment variables for the MCP servers, extensions, agent
+	// and steps of the environment. MCP servers configured from the
+	// environment (MCP_URL and the like) read them too.
+	Env map[string]string `json:"env,omitempty"`
+
+	// Extensions are added to the extensions of the eval config, replacing
+	// those with the same alias
+	Extensions map[string]*extension.ExtensionSpec `json:"extensions,omitempty"`
+
+	// Shadow mirrors the tool calls to the MCP servers of the environment to
+	// shadow servers, replacing the shadow of the eval config, whose shadows
+	// of servers the environment doesn't have are ignored
+	Shadow []mcpproxy.ShadowConfig `json:"shadow,omitempty"`
+}
+
+// environment holds the dependencies that the tasks of an environment run
+// with during a run.
+type environment struct {
+	name      string
+	env       map[string]string
+	mcpConfig *mcpclient.MCPConfig
+	deps      *steps.Dependencies
+	proxyPool *mcpproxy.ServerPool
+	close     func()
+}
+
+// setUpEnvironment connects to the MCP servers of the environment name and
+// registers its extensions. Its LLM judge is the one of the run. The
+// versions of the canary server are environments too.
+func (r *evalRunner) setUpEnvironment(ctx context.Context, name string) (*environment, error) {
+	if r.spec.Config.Canary.isVersion(name) {
+		return r.setUpCanaryEnvironment(ctx, name)
+	}
+
+	cfg := r.spec.Config.Environments[name]
+	if cfg == nil {
+		return nil, fmt.Errorf("unknown environment %q", name)
+	}
+
+	// MCP servers start, and their config is read from the environment, with
+	// the variables of the environment
+	defer setEnv(cfg.Env)()
+
+	env := &environment{
+		name:  name,
+		env:   cfg.Env,
+		deps:  &steps.Dependencies{},
+		close: func() {},
+	}
+	if judge, ok := r.deps.LLMJudge(); ok {
+		env.deps.Judge = judge
+	}
+
+	var err error
+	if cfg.McpConfigFile != "" {
+		env.mcpConfig, err = mcpclient.ParseConfigFile(cfg.McpConfigFile)
+		if err != nil {
+			return nil, fmt.Errorf("environment %q: failed to load MCP config from file: %w", name, err)
+		}
+	} else if env.mcpConfig, err = loadMcpConfig(r.spec); err != nil {
+		return nil, fmt.Errorf("environment %q: %w", name, err)
+	}
+	if env.mcpConfig == nil && r.spec.Config.Skills == nil {
+		return nil, fmt.Errorf("environment %q: at least one of MCP config or skills must be configured", name)
+	}
+	if r.runID != "" {
+		addRunIDHeader(env.mcpConfig, r.runID)
+	}
+
+	var closers []func()
+	env.close = func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+	}
+
+	if env.mcpConfig != nil {
+		mcpManager, err := mcpclient.NewManager(ctx, env.mcpConfig)
+		if err != nil {
+			return nil, fmt.Errorf("environment %q: failed to connect to MCP servers: %w", name, err)
+		}
+		closers = append(closers, func() {
+			closeCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			_ = mcpManager.Close(closeCtx)
+		})
+		shadows := r.spec.Config.Shadow
+		if cfg.Shadow != nil {
+			shadows = cfg.Shadow
+		}
+		if env.deps.McpClients, err = mcpproxy.NewShadowedManager(mcpManager, shadows); err != nil {
+			env.close()
+			return nil, fmt.Errorf("environment %q: %w", name, err)
+		}
+	}
+
+	extensions := maps.Clone(r.spec.Config.Extensions)
+	if extensions == nil {
+		extensions = make(map[string]*extension.ExtensionSpec)
+	}
+	maps.Copy(extensions, cfg.Extensions)
+	extManager, err := r.newExtensionManager(extensions)
+	if err != nil {
+		env.close()
+		return nil, fmt.Errorf("environment %q: %w", name, err)
+	}
+	closers = append(closers, func() {
+		// Extensions stop with the variables they started with
+		defer setEnv(cfg.Env)()
+		shutdownExtensions(extManager)
+	})
+	env.deps.Extensions = extManager
+
+	if mcpManager, ok := env.deps.McpManager(); ok && r.poolProxies {
+		env.proxyPool, err = r.startProxyPool(ctx, mcpManager)
+		if err != nil {
+			env.close()
+			return nil, fmt.Errorf("environment %q: %w", name, err)
+		}
+		pool := env.proxyPool
+		closers = append(closers, func() { _ = pool.Close() })
+	}
+
+	return env, nil
+}
+
+// setUpEnvironments sets up the environments of tasks, returning them by
+// name and a function that closes them.
+func (r *evalRunner) setUpEnvironments(ctx context.Context, tasks []taskConfig) (map[string]*environment, func(), error) {
+	environments := make(map[string]*environment)
+	closeAll := func() {
+		for _, env := range environments {
+			env.close()
+		}
+	}
+
+	for _, tc := range tasks {
+		if tc.environment == "" || environments[tc.environment] != nil {
+			continue
+		}
+		env, err := r.setUpEnvironment(ctx, tc.environment)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		environments[tc.environment] = env
+	}
+
+	return environments, closeAll, nil
+}
+
+// useEnvironment makes the tasks that r runs use the dependencies and
+// variables of env until the returned function is called. A nil env is the
+// eval config itself, which r uses already.
+func (r *evalRunner) useEnvironment(env *environment) (restore func()) {
+	if env == nil {
+		return func() {}
+	}
+
+	deps, proxyPool := r.deps, r.proxyPool
+	r.deps, r.proxyPool = env.deps, env.proxyPool
+	restoreEnv := setEnv(env.env)
+	return func() {
+		restoreEnv()
+		r.deps, r.proxyPool = deps, proxyPool
+	}
+}
+
+// environmentTasks are the tasks of a run that run in an environment, "" for
+// the eval config itself
+type environmentTasks struct {
+	environment string
+	tasks       []taskConfig
+}
+
+// groupTasksByEnvironment groups tasks by the environment they run in, in the
+// order the environments first appear. The order of the tasks in each
+// environment is kept.
+func groupTasksByEnvironment(tasks []taskConfig) []environmentTasks {
+	var groups []environmentTasks
+	index := make(map[string]int)
+	for _, tc := range tasks {
+		i, ok := index[tc.environment]
+		if !ok {
+			i = len(groups)
+			index[tc.environment] = i
+			groups = append(groups, environmentTasks{environment: tc.environment})
+		}
+		groups[i].tasks = append(groups[i].tasks, tc)
+	}
+	return groups
+}
+
+// setEnv sets the environment variables of env, returning a function that
+// restores their previous values.
+func setEnv(env map[string]string) (restore func()) {
+	type previous struct {
+		value string
+		had   bool
+	}
+	saved := make(map[string]previous, len(env))
+	for name, value := range env {
+		v, had := os.LookupEnv(name)
+		saved[name] = previous{value: v, had: had}
+		_ = os.Setenv(name, value)
+	}
+
+	return func() {
+		for name, p := range saved {
+			if p.had {
+				_ = os.Setenv(name, p.value)
+			} else {
+				_ = os.Unsetenv(name)
+			}
+		}
+	}
+}
+
+// resolvePaths resolves the MCP config file of the environment relative to
+// basePath.
+func (c *EnvironmentConfig) resolvePaths(basePath string) error {
+	return util.ResolveRelativePath(&c.McpConfigFile, basePath)
+}
+
+// environmentSummaries describes environments, sorted by name.
+func environmentSummaries(ctx context.Context, environments map[string]*environment) []EnvironmentSummary {
+	names := slices.Sorted(maps.Keys(environments))
+
+	var summaries []EnvironmentSummary
+	for _, name := range names {
+		env := environments[name]
+		summary := EnvironmentSummary{Name: name}
+		if env.mcpConfig != nil {
+			mcpManager, _ := env.deps.McpManager()
+			summary.MCPServers = mcpServerSummaries(ctx, env.mcpConfig, mcpManager)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
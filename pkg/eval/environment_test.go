@@ -0,0 +1,227 @@
+package eval
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadEnvironments(t *testing.T) {
+	basePath := t.TempDir()
+
+	tests := map[string]struct {
+		yaml         string
+		environments map[string]*EnvironmentConfig
+		errContains  string
+	}{
+		"mcp config files are resolved against the eval file": {
+			yaml: `kind: Eval
+config:
+  environments:
+    dev:
+      mcpConfigFile: dev/mcp.json
+      env:
+        KUBECONFIG: /tmp/dev.kubeconfig
+    prod:
+      mcpConfigFile: /abs/prod.json
+  taskSets:
+    - glob: tasks/*.yaml
+      environment: dev
+    - glob: tasks/*.yaml
+      environment: prod
+`,
+			environments: map[string]*EnvironmentConfig{
+				"dev": {
+					McpConfigFile: filepath.Join(basePath, "dev/mcp.json"),
+					Env:           map[string]string{"KUBECONFIG": "/tmp/dev.kubeconfig"},
+				},
+				"prod": {McpConfigFile: "/abs/prod.json"},
+			},
+		},
+		"empty environment": {
+			yaml: `kind: Eval
+config:
+  environments:
+    staging:
+  taskSets:
+    - glob: tasks/*.yaml
+      environment: staging
+`,
+			environments: map[string]*EnvironmentConfig{"staging": {}},
+		},
+		"unknown environment": {
+			yaml: `kind: Eval
+config:
+  environments:
+    dev: {}
+  taskSets:
+    - glob: tasks/*.yaml
+      environment: prod
+`,
+			errContains: `taskSet[0]: unknown environment "prod"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec, err := Read([]byte(tc.yaml), basePath)
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.environments, spec.Config.Environments)
+		})
+	}
+}
+
+func TestCollectTaskConfigsEnvironments(t *testing.T) {
+	runner := &evalRunner{
+		progressCallback: NoopProgressCallback,
+		spec: &EvalSpec{
+			Config: EvalConfig{
+				Environments: map[string]*EnvironmentConfig{"dev": {}, "prod": {}},
+				TaskSets: []TaskSet{
+					{Path: "../task/testdata/create-pod-inline.yaml", Environment: "dev"},
+					{Path: "../task/testdata/create-pod-inline.yaml", Environment: "prod"},
+					{Path: "../task/testdata/create-pod-inline.yaml", Environment: "prod", Assertions: &TaskAssertions{NoDuplicateCalls: true}},
+				},
+			},
+		},
+	}
+
+	configs, err := runner.collectTaskConfigs(regexp.MustCompile(".*"))
+	require.NoError(t, err)
+	require.Len(t, configs, 2, "the task should run once in each environment")
+
+	assert.Equal(t, "dev", configs[0].environment)
+	assert.Empty(t, configs[0].assertions)
+	assert.Equal(t, "prod", configs[1].environment)
+	assert.Len(t, configs[1].assertions, 1, "task sets of the same environment should merge their assertions")
+
+	name := configs[0].spec.Metadata.Key()
+	assert.Equal(t, name+"@dev", configs[0].key())
+	assert.Equal(t, name+"@prod", configs[1].key())
+}
+
+func TestGroupTasksByEnvironment(t *testing.T) {
+	makeTask := func(name, environment string) taskConfig {
+		return taskConfig{
+			spec:        &task.TaskConfig{Metadata: task.TaskMetadata{Name: name}},
+			environment: environment,
+		}
+	}
+
+	groups := groupTasksByEnvironment([]taskConfig{
+		makeTask("a", "prod"),
+		makeTask("b", ""),
+		makeTask("c", "dev"),
+		makeTask("d", "prod"),
+		makeTask("e", ""),
+	})
+
+	names := func(tasks []taskConfig) []string {
+		var names []string
+		for _, tc := range tasks {
+			names = append(names, tc.spec.Metadata.Name)
+		}
+		return names
+	}
+
+	require.Len(t, groups, 3)
+	assert.Equal(t, "prod", groups[0].environment)
+	assert.Equal(t, []string{"a", "d"}, names(groups[0].tasks))
+	assert.Equal(t, "", groups[1].environment)
+	assert.Equal(t, []string{"b", "e"}, names(groups[1].tasks))
+	assert.Equal(t, "dev", groups[2].environment)
+	assert.Equal(t, []string{"c"}, names(groups[2].tasks))
+
+	assert.Nil(t, groupTasksByEnvironment(nil))
+}
+
+func TestSetEnv(t *testing.T) {
+	t.Setenv("MCPCHECKER_TEST_SET", "before")
+	require.NoError(t, os.Unsetenv("MCPCHECKER_TEST_UNSET"))
+
+	restore := setEnv(map[string]string{
+		"MCPCHECKER_TEST_SET":   "during",
+		"MCPCHECKER_TEST_UNSET": "during",
+	})
+	assert.Equal(t, "during", os.Getenv("MCPCHECKER_TEST_SET"))
+	assert.Equal(t, "during", os.Getenv("MCPCHECKER_TEST_UNSET"))
+
+	restore()
+	assert.Equal(t, "before", os.Getenv("MCPCHECKER_TEST_SET"))
+	_, ok := os.LookupEnv("MCPCHECKER_TEST_UNSET")
+	assert.False(t, ok)
+}
+
+func TestSetUpEnvironment(t *testing.T) {
+	skills := &SkillsConfig{Sources: []SkillSource{{Type: "path", Path: "skills"}}}
+
+	tests := map[string]struct {
+		config      EvalConfig
+		errContains string
+	}{
+		"skills without mcp servers": {
+			config: EvalConfig{
+				Skills:       skills,
+				Environments: map[string]*EnvironmentConfig{"dev": {Env: map[string]string{"MCPCHECKER_TEST_ENV": "dev"}}},
+			},
+		},
+		"unknown environment": {
+			config:      EvalConfig{Skills: skills},
+			errContains: `unknown environment "dev"`,
+		},
+		"neither mcp servers nor skills": {
+			config: EvalConfig{
+				Environments: map[string]*EnvironmentConfig{"dev": {}},
+			},
+			errContains: `environment "dev": at least one of MCP config or skills must be configured`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			judge := &fakeJudge{}
+			runner := &evalRunner{
+				spec: &EvalSpec{Config: tc.config},
+				deps: &steps.Dependencies{Judge: judge},
+			}
+
+			env, err := runner.setUpEnvironment(context.Background(), "dev")
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			defer env.close()
+
+			_, ok := os.LookupEnv("MCPCHECKER_TEST_ENV")
+			assert.False(t, ok, "the variables of the environment should only be set while it is used")
+
+			_, hasMcp := env.deps.McpManager()
+			assert.False(t, hasMcp)
+			_, hasExtensions := env.deps.ExtensionManager()
+			assert.True(t, hasExtensions)
+			assert.Equal(t, judge, env.deps.Judge, "environments should share the judge of the run")
+
+			restore := runner.useEnvironment(env)
+			assert.Equal(t, env.deps, runner.deps)
+			assert.Equal(t, "dev", os.Getenv("MCPCHECKER_TEST_ENV"))
+			restore()
+			assert.Equal(t, judge, runner.deps.Judge)
+			_, ok = os.LookupEnv("MCPCHECKER_TEST_ENV")
+			assert.False(t, ok)
+		})
+	}
+}
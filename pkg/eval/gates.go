@@ -0,0 +1,135 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+// GateConfig requires a minimum task pass rate of the task runs it selects by
+// difficulty and labels, e.g. easy tasks >= 95% or suite=networking >= 70%.
+// Gates are evaluated after the run, and check fails if one isn't met.
+type GateConfig struct {
+	// Name identifies the gate in the output (default: its selection, e.g.
+	// "difficulty=easy,suite=networking")
+	Name string `json:"name,omitempty"`
+
+	// Difficulty selects the runs of tasks with this difficulty
+	Difficulty string `json:"difficulty,omitempty"`
+
+	// LabelSelector selects the runs of tasks whose labels match, as a map of
+	// labels or a selector string
+	LabelSelector LabelSelector `json:"labelSelector,omitempty"`
+
+	// MinPassRate is the minimum task pass rate of the selected runs, from 0
+	// to 1
+	MinPassRate float64 `json:"minPassRate"`
+}
+
+// Validate checks that the minimum pass rate is between 0 and 1.
+func (g *GateConfig) Validate() error {
+	if g.MinPassRate < 0 || g.MinPassRate > 1 {
+		return fmt.Errorf("gate %s: minPassRate must be between 0 and 1, got %g", g.name(), g.MinPassRate)
+	}
+	return nil
+}
+
+// name returns the name of the gate, or its selection if it has none.
+func (g *GateConfig) name() string {
+	if g.Name != "" {
+		return g.Name
+	}
+	var parts []string
+	if g.Difficulty != "" {
+		parts = append(parts, "difficulty="+g.Difficulty)
+	}
+	if len(g.LabelSelector) > 0 {
+		parts = append(parts, g.LabelSelector.String())
+	}
+	if len(parts) == 0 {
+		return "all tasks"
+	}
+	return strings.Join(parts, ",")
+}
+
+// GateResult is the outcome of a gate.
+type GateResult struct {
+	Name        string  `json:"name"`
+	MinPassRate float64 `json:"minPassRate"`
+	PassRate    float64 `json:"passRate"`
+
+	// Runs is the number of selected task runs that count towards the pass
+	// rate, of which Passed passed
+	Runs   int `json:"runs"`
+	Passed int `json:"passed"`
+
+	// Met is set if the pass rate reached MinPassRate, or if no runs were
+	// selected
+	Met bool `json:"met"`
+}
+
+// evaluateGates computes the pass rate of the runs selected by each gate.
+// Like verify thresholds, gates leave out runs of quarantined tasks, expected
+// failures and skipped runs that don't count as not passed. labels holds the
+// labels of the tasks by path.
+func evaluateGates(gates []GateConfig, results []*EvalResult, labels map[string]map[string]string) []GateResult {
+	if len(gates) == 0 {
+		return nil
+	}
+
+	outcomes := make([]GateResult, 0, len(gates))
+	for _, gate := range gates {
+		outcome := GateResult{Name: gate.name(), MinPassRate: gate.MinPassRate}
+		for _, result := range results {
+			if !countsTowardsGates(result) {
+				continue
+			}
+			if gate.Difficulty != "" && result.Difficulty != gate.Difficulty {
+				continue
+			}
+			if !gate.LabelSelector.Matches(labels[result.TaskPath]) {
+				continue
+			}
+			outcome.Runs++
+			if result.TaskPassed {
+				outcome.Passed++
+			}
+		}
+		outcome.Met = true
+		if outcome.Runs > 0 {
+			outcome.PassRate = float64(outcome.Passed) / float64(outcome.Runs)
+			outcome.Met = outcome.PassRate >= gate.MinPassRate
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes
+}
+
+// taskLabels returns the labels of the tasks by path.
+func taskLabels(tasks []taskConfig) map[string]map[string]string {
+	labels := make(map[string]map[string]string, len(tasks))
+	for _, tc := range tasks {
+		labels[tc.path] = tc.spec.Metadata.Labels
+	}
+	return labels
+}
+
+// countsTowardsGates reports whether a run counts towards the pass rate of gates.
+func countsTowardsGates(result *EvalResult) bool {
+	if result.State == task.StateQuarantined || result.ExpectFailure {
+		return false
+	}
+	return !result.Skipped || result.SkipReason.CountsAsNotPassed()
+}
+
+// FailedGates returns the gates that were not met.
+func FailedGates(gates []GateResult) []GateResult {
+	var failed []GateResult
+	for _, gate := range gates {
+		if !gate.Met {
+			failed = append(failed, gate)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,125 @@
+package eval
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGateConfig(t *testing.T) {
+	tests := map[string]struct {
+		json        string
+		name        string
+		errContains string
+	}{
+		"difficulty": {
+			json: `{"difficulty": "easy", "minPassRate": 0.95}`,
+			name: "difficulty=easy",
+		},
+		"label map": {
+			json: `{"labelSelector": {"suite": "networking"}, "minPassRate": 0.7}`,
+			name: "suite=networking",
+		},
+		"difficulty and label selector": {
+			json: `{"difficulty": "hard", "labelSelector": "suite in (k8s,helm)", "minPassRate": 0.5}`,
+			name: "difficulty=hard,suite in (k8s,helm)",
+		},
+		"named": {
+			json: `{"name": "core", "labelSelector": "!flaky", "minPassRate": 1}`,
+			name: "core",
+		},
+		"all tasks": {
+			json: `{"minPassRate": 0.8}`,
+			name: "all tasks",
+		},
+		"pass rate above 1": {
+			json:        `{"difficulty": "easy", "minPassRate": 95}`,
+			errContains: "gate difficulty=easy: minPassRate must be between 0 and 1",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gate GateConfig
+			require.NoError(t, json.Unmarshal([]byte(tc.json), &gate))
+
+			err := gate.Validate()
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.name, gate.name())
+		})
+	}
+}
+
+func TestEvaluateGates(t *testing.T) {
+	labels := map[string]map[string]string{
+		"net-1.yaml": {"suite": "networking"},
+		"net-2.yaml": {"suite": "networking"},
+		"k8s.yaml":   {"suite": "k8s"},
+	}
+	results := []*EvalResult{
+		{TaskPath: "net-1.yaml", Difficulty: "easy", TaskPassed: true},
+		{TaskPath: "net-1.yaml", Difficulty: "easy", TaskPassed: true},
+		{TaskPath: "net-2.yaml", Difficulty: "hard"},
+		{TaskPath: "k8s.yaml", Difficulty: "easy", TaskPassed: true},
+		{TaskPath: "k8s.yaml", Difficulty: "easy"},
+		// Left out of gates
+		{TaskPath: "k8s.yaml", Difficulty: "easy", State: task.StateQuarantined},
+		{TaskPath: "k8s.yaml", Difficulty: "easy", ExpectFailure: true},
+		{TaskPath: "k8s.yaml", Difficulty: "easy", Skipped: true, SkipReason: SkipReasonAborted},
+	}
+
+	tests := map[string]struct {
+		gate   GateConfig
+		expect GateResult
+	}{
+		"difficulty met": {
+			gate:   GateConfig{Difficulty: "easy", MinPassRate: 0.75},
+			expect: GateResult{Name: "difficulty=easy", MinPassRate: 0.75, PassRate: 0.75, Runs: 4, Passed: 3, Met: true},
+		},
+		"difficulty not met": {
+			gate:   GateConfig{Difficulty: "easy", MinPassRate: 0.8},
+			expect: GateResult{Name: "difficulty=easy", MinPassRate: 0.8, PassRate: 0.75, Runs: 4, Passed: 3},
+		},
+		"label": {
+			gate:   GateConfig{LabelSelector: LabelSelector{{Key: "suite", Operator: LabelOpIn, Values: []string{"networking"}}}, MinPassRate: 0.7},
+			expect: GateResult{Name: "suite=networking", MinPassRate: 0.7, PassRate: 2.0 / 3, Runs: 3, Passed: 2},
+		},
+		"zero min pass rate": {
+			gate:   GateConfig{Name: "hard", Difficulty: "hard", MinPassRate: 0},
+			expect: GateResult{Name: "hard", PassRate: 0, Runs: 1, Met: true},
+		},
+		"no runs": {
+			gate:   GateConfig{Difficulty: "medium", MinPassRate: 1},
+			expect: GateResult{Name: "difficulty=medium", MinPassRate: 1, Met: true},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			outcomes := evaluateGates([]GateConfig{tc.gate}, results, labels)
+			require.Len(t, outcomes, 1)
+			assert.InDelta(t, tc.expect.PassRate, outcomes[0].PassRate, 1e-9)
+			outcomes[0].PassRate = tc.expect.PassRate
+			assert.Equal(t, tc.expect, outcomes[0])
+		})
+	}
+
+	assert.Nil(t, evaluateGates(nil, results, labels))
+}
+
+func TestFailedGates(t *testing.T) {
+	gates := []GateResult{{Name: "easy", Met: true}, {Name: "hard"}, {Name: "networking"}}
+	failed := FailedGates(gates)
+	require.Len(t, failed, 2)
+	assert.Equal(t, "hard", failed[0].Name)
+	assert.Equal(t, "networking", failed[1].Name)
+	assert.Empty(t, FailedGates(gates[:1]))
+}
@@ -0,0 +1,150 @@
+package eval
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// JournalEntryType identifies the kind of a journal line.
+type JournalEntryType string
+
+const (
+	// JournalStart is the first entry of a journal and carries the run summary
+	JournalStart JournalEntryType = "start"
+	// JournalSetup carries a task run whose setup ran, with the outputs of
+	// its setup steps, so that its cleanup can be replayed if the run crashes
+	// before its result is journaled
+	JournalSetup JournalEntryType = "setup"
+	// JournalResult carries one completed task run
+	JournalResult JournalEntryType = "result"
+	// JournalComplete is written once all tasks have finished
+	JournalComplete JournalEntryType = "complete"
+)
+
+// JournalEntry is a single line of a results journal.
+type JournalEntry struct {
+	Type    JournalEntryType `json:"type"`
+	Time    time.Time        `json:"time"`
+	RunID   string           `json:"runId,omitempty"`
+	Summary *EvalSummary     `json:"summary,omitempty"`
+	Result  *EvalResult      `json:"result,omitempty"`
+}
+
+// Journal appends entries to an NDJSON file as a run progresses, so completed
+// results survive a crash and can be followed while the run is in progress.
+// It is safe for concurrent use, and a nil Journal discards all entries.
+type Journal struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// CreateJournal creates (or truncates) the journal file at path.
+func CreateJournal(path string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create journal file: %w", err)
+	}
+	return &Journal{file: file}, nil
+}
+
+// Write appends entry to the journal as one line and flushes it to disk.
+func (j *Journal) Write(entry JournalEntry) error {
+	if j == nil {
+		return nil
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+	data = append(util.RedactSecretBytes(data), '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// A single write keeps lines whole for readers tailing the file
+	if _, err := j.file.Write(data); err != nil {
+		return fmt.Errorf("failed to write journal entry: %w", err)
+	}
+	return j.file.Sync()
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	return j.file.Close()
+}
+
+// IsJournal reports whether data looks like a results journal rather than a
+// results JSON file.
+func IsJournal(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte(`{"type":`))
+}
+
+// ReadJournal reads all complete entries from a journal. A trailing partial
+// line, as left by a crash mid-write, is ignored.
+func ReadJournal(r io.Reader) ([]JournalEntry, error) {
+	var entries []JournalEntry
+
+	reader := bufio.NewReader(r)
+	for lineNum := 1; ; lineNum++ {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// Without a trailing newline the last line may be incomplete
+			if entry, ok := parseJournalLine(line); ok {
+				entries = append(entries, entry)
+			}
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read journal: %w", err)
+		}
+
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse journal line %d: %w", lineNum, err)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+func parseJournalLine(line []byte) (JournalEntry, bool) {
+	var entry JournalEntry
+	if len(bytes.TrimSpace(line)) == 0 || json.Unmarshal(line, &entry) != nil {
+		return JournalEntry{}, false
+	}
+	return entry, true
+}
+
+// OutputFromJournal rebuilds an EvalOutput from journal entries. The output is
+// partial if the journal has no JournalComplete entry.
+func OutputFromJournal(entries []JournalEntry) *EvalOutput {
+	output := &EvalOutput{Results: make([]*EvalResult, 0, len(entries))}
+	for _, entry := range entries {
+		switch entry.Type {
+		case JournalStart:
+			output.Summary = entry.Summary
+		case JournalResult:
+			if entry.Result != nil {
+				output.Results = append(output.Results, entry.Result)
+			}
+		}
+	}
+	return output
+}
@@ -0,0 +1,173 @@
+package eval
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalWriteAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.ndjson")
+
+	journal, err := CreateJournal(path)
+	require.NoError(t, err)
+
+	require.NoError(t, journal.Write(JournalEntry{Type: JournalStart, Summary: &EvalSummary{ParallelWorkers: 4}}))
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, journal.Write(JournalEntry{
+				Type:   JournalResult,
+				Result: &EvalResult{TaskName: fmt.Sprintf("task-%d", i), TaskOutput: strings.Repeat("x", 8192)},
+			}))
+		}()
+	}
+	wg.Wait()
+
+	// Entries written so far are on disk before the journal is closed
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, IsJournal(data))
+
+	entries, err := ReadJournal(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, entries, 11)
+	assert.Equal(t, JournalStart, entries[0].Type)
+	assert.False(t, entries[0].Time.IsZero())
+
+	require.NoError(t, journal.Write(JournalEntry{Type: JournalComplete}))
+	require.NoError(t, journal.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	entries, err = ReadJournal(f)
+	require.NoError(t, err)
+	require.Len(t, entries, 12)
+
+	output := OutputFromJournal(entries)
+	require.NotNil(t, output.Summary)
+	assert.Equal(t, 4, output.Summary.ParallelWorkers)
+	assert.Len(t, output.Results, 10)
+}
+
+func TestNilJournal(t *testing.T) {
+	var journal *Journal
+	assert.NoError(t, journal.Write(JournalEntry{Type: JournalComplete}))
+	assert.NoError(t, journal.Close())
+}
+
+func TestReadJournal(t *testing.T) {
+	tests := map[string]struct {
+		input         string
+		expectedTypes []JournalEntryType
+		errContains   string
+	}{
+		"empty": {},
+		"complete journal": {
+			input:         "{\"type\":\"start\"}\n{\"type\":\"result\",\"result\":{\"taskName\":\"t1\"}}\n{\"type\":\"complete\"}\n",
+			expectedTypes: []JournalEntryType{JournalStart, JournalResult, JournalComplete},
+		},
+		"blank lines are skipped": {
+			input:         "{\"type\":\"start\"}\n\n{\"type\":\"complete\"}\n",
+			expectedTypes: []JournalEntryType{JournalStart, JournalComplete},
+		},
+		"partial last line is ignored": {
+			input:         "{\"type\":\"start\"}\n{\"type\":\"res",
+			expectedTypes: []JournalEntryType{JournalStart},
+		},
+		"complete last line without newline": {
+			input:         "{\"type\":\"start\"}\n{\"type\":\"complete\"}",
+			expectedTypes: []JournalEntryType{JournalStart, JournalComplete},
+		},
+		"corrupt line": {
+			input:       "{\"type\":\"start\"}\nnot json\n{\"type\":\"complete\"}\n",
+			errContains: "line 2",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			entries, err := ReadJournal(strings.NewReader(tc.input))
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+
+			var types []JournalEntryType
+			for _, entry := range entries {
+				types = append(types, entry.Type)
+			}
+			assert.Equal(t, tc.expectedTypes, types)
+		})
+	}
+}
+
+func TestExecuteTaskWritesJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.ndjson")
+	journal, err := CreateJournal(path)
+	require.NoError(t, err)
+	defer journal.Close()
+
+	runner := &evalRunner{
+		spec:              &EvalSpec{},
+		runs:              2,
+		runsExplicitlySet: true,
+		budget:            newBudgetTracker(&BudgetConfig{MaxTokens: 10}),
+		progressCallback:  func(ProgressEvent) {},
+		journal:           journal,
+	}
+	runner.budget.record(&EvalResult{TokenEstimate: &tokens.Estimate{InputTokens: 10}})
+
+	tc := taskConfig{
+		path: "task.yaml",
+		spec: &task.TaskConfig{Metadata: task.TaskMetadata{Name: "over-budget"}},
+	}
+
+	// Skipped runs are journaled the same as completed ones
+	results := runner.executeTask(t.Context(), nil, tc)
+	require.Len(t, results, 2)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	entries, err := ReadJournal(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	for i, entry := range entries {
+		assert.Equal(t, JournalResult, entry.Type)
+		assert.Equal(t, i, entry.Result.RunIndex)
+		assert.Equal(t, 2, entry.Result.TotalRuns)
+	}
+}
+
+func TestWriteJournalWarnsOnError(t *testing.T) {
+	journal, err := CreateJournal(filepath.Join(t.TempDir(), "journal.ndjson"))
+	require.NoError(t, err)
+	require.NoError(t, journal.Close())
+
+	var events []ProgressEvent
+	runner := &evalRunner{
+		progressCallback: func(e ProgressEvent) { events = append(events, e) },
+		journal:          journal,
+	}
+
+	// A journal that can't be written doesn't fail the run, but is reported
+	runner.writeJournal(JournalEntry{Type: JournalComplete})
+	require.Len(t, events, 1)
+	assert.Equal(t, EventWarning, events[0].Type)
+	assert.Contains(t, events[0].Message, "failed to write journal entry")
+}
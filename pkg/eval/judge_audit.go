@@ -0,0 +1,47 @@
+package eval
+
+import (
+	"path/filepath"
+
+	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// JudgeAuditFileName is the name of the file that the judge calls of a task run
+// are written to, in a directory per task run of the judge audit directory.
+const JudgeAuditFileName = "judge-audit.json"
+
+// JudgeAudit is the audit log of the LLM judge calls of a task run, written
+// when RunnerOptions.JudgeAuditDir is set (check --judge-audit-dir).
+type JudgeAudit struct {
+	TaskID           string                `json:"taskId,omitempty"`
+	TaskName         string                `json:"taskName"`
+	TaskPath         string                `json:"taskPath"`
+	RunIndex         int                   `json:"runIndex"`
+	AllowedToolsMode AllowedToolsMode      `json:"allowedToolsMode,omitempty"`
+	Calls            []llmjudge.AuditEntry `json:"calls"`
+}
+
+// writeJudgeAudit writes the judge calls of log to a new directory name of dir
+// and returns the path of the file, or an empty string if the judge wasn't
+// called. Like debug artifacts, audit logs are best effort.
+func writeJudgeAudit(dir *util.DebugDir, name string, result *EvalResult, log *llmjudge.AuditLog) string {
+	calls := log.Entries()
+	if len(calls) == 0 {
+		return ""
+	}
+
+	sub := dir.NewSub(name)
+	if sub == nil {
+		return ""
+	}
+	sub.WriteJSON(JudgeAuditFileName, &JudgeAudit{
+		TaskID:           result.TaskID,
+		TaskName:         result.TaskName,
+		TaskPath:         result.TaskPath,
+		RunIndex:         result.RunIndex,
+		AllowedToolsMode: result.AllowedToolsMode,
+		Calls:            calls,
+	})
+	return filepath.Join(sub.Path(), JudgeAuditFileName)
+}
@@ -0,0 +1,44 @@
+package eval
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJudgeAudit(t *testing.T) {
+	dir, err := util.NewDebugDir(t.TempDir())
+	require.NoError(t, err)
+
+	result := &EvalResult{TaskName: "check-nginx", TaskPath: "tasks/nginx.yaml", RunIndex: 1}
+
+	// Runs without judge calls don't get an audit log
+	assert.Empty(t, writeJudgeAudit(dir, "check-nginx-run1", result, llmjudge.NewAuditLog()))
+
+	log := llmjudge.NewAuditLog()
+	log.Record(llmjudge.AuditEntry{Attempt: 1, SystemPrompt: "system", UserPrompt: "user"})
+
+	path := writeJudgeAudit(dir, "check-nginx-run1", result, log)
+	assert.Equal(t, filepath.Join(dir.Path(), "check-nginx-run1", JudgeAuditFileName), path)
+
+	// Writing the same run again, e.g. to a reused directory, keeps the first log
+	second := writeJudgeAudit(dir, "check-nginx-run1", result, log)
+	assert.Equal(t, filepath.Join(dir.Path(), "check-nginx-run1-2", JudgeAuditFileName), second)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var audit JudgeAudit
+	require.NoError(t, json.Unmarshal(data, &audit))
+	assert.Equal(t, "check-nginx", audit.TaskName)
+	assert.Equal(t, 1, audit.RunIndex)
+	require.Len(t, audit.Calls, 1)
+	assert.Equal(t, "system", audit.Calls[0].SystemPrompt)
+	assert.Equal(t, "user", audit.Calls[0].UserPrompt)
+}
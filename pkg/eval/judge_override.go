@@ -0,0 +1,14 @@
+package eval
+
+import "time"
+
+// JudgeOverride is the verdict of a human reviewer on a task run that the LLM
+// judge failed, recorded on results amended by 'mcpchecker review'.
+type JudgeOverride struct {
+	// Passed is the verdict of the reviewer: false if they accepted the
+	// failing verdict of the judge, true if they overrode it
+	Passed   bool      `json:"passed"`
+	Note     string    `json:"note,omitempty"`
+	Reviewer string    `json:"reviewer,omitempty"`
+	Time     time.Time `json:"time"`
+}
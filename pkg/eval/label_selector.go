@@ -1,163 +1,375 @@
 package eval
 
 import (
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 )
 
-// ParseLabelSelector parses a comma-separated label selector string into a multi-value map.
-// Format: "key1=value1,key2=value2,key1=value3".
-// Different keys use AND semantics; duplicate keys use OR semantics.
-// Example: "suite=kubernetes,suite=helm,difficulty=easy" means
-// (suite=kubernetes OR suite=helm) AND difficulty=easy.
-func ParseLabelSelector(selector string) (map[string][]string, error) {
-	selector = strings.TrimSpace(selector)
-	if selector == "" {
-		return nil, nil
+// LabelOperator is the operator of a single label selector requirement.
+type LabelOperator string
+
+const (
+	// LabelOpIn matches if the label is set to one of the values ("key=value", "key in (a,b)")
+	LabelOpIn LabelOperator = "in"
+	// LabelOpNotIn matches if the label is unset or set to none of the values ("key!=value", "key notin (a,b)")
+	LabelOpNotIn LabelOperator = "notin"
+	// LabelOpExists matches if the label is set ("key")
+	LabelOpExists LabelOperator = "exists"
+	// LabelOpDoesNotExist matches if the label is unset ("!key")
+	LabelOpDoesNotExist LabelOperator = "!"
+)
+
+// LabelRequirement is a single requirement of a label selector.
+type LabelRequirement struct {
+	Key      string
+	Operator LabelOperator
+	Values   []string
+}
+
+// Matches reports whether labels satisfy the requirement.
+func (r LabelRequirement) Matches(labels map[string]string) bool {
+	value, exists := labels[r.Key]
+	switch r.Operator {
+	case LabelOpIn:
+		return exists && slices.Contains(r.Values, value)
+	case LabelOpNotIn:
+		return !exists || !slices.Contains(r.Values, value)
+	case LabelOpExists:
+		return exists
+	case LabelOpDoesNotExist:
+		return !exists
 	}
+	return false
+}
 
-	labels := make(map[string][]string)
-	for _, pair := range strings.Split(selector, ",") {
-		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid label selector format, expected key=value, got: %s", pair)
+// String returns the requirement in selector syntax.
+func (r LabelRequirement) String() string {
+	switch r.Operator {
+	case LabelOpIn:
+		if len(r.Values) == 1 {
+			return r.Key + "=" + r.Values[0]
 		}
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-		if key == "" || value == "" {
-			return nil, fmt.Errorf("label selector key and value cannot be empty")
+		return r.Key + " in (" + strings.Join(r.Values, ",") + ")"
+	case LabelOpNotIn:
+		if len(r.Values) == 1 {
+			return r.Key + "!=" + r.Values[0]
 		}
-		labels[key] = appendUnique(labels[key], value)
+		return r.Key + " notin (" + strings.Join(r.Values, ",") + ")"
+	case LabelOpDoesNotExist:
+		return "!" + r.Key
+	default:
+		return r.Key
+	}
+}
+
+// LabelSelector selects tasks by their labels. All requirements must match
+// (AND logic), and an empty selector matches every task.
+//
+// In eval configs a selector is either a map of labels that must all match, or a
+// string in the syntax accepted by ParseLabelSelector.
+type LabelSelector []LabelRequirement
+
+// LabelSelectorFromMap returns a selector that matches tasks with all the given labels.
+func LabelSelectorFromMap(labels map[string]string) LabelSelector {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
-	return labels, nil
+	selector := make(LabelSelector, 0, len(keys))
+	for _, key := range keys {
+		selector = append(selector, LabelRequirement{Key: key, Operator: LabelOpIn, Values: []string{labels[key]}})
+	}
+	return selector
 }
 
-func appendUnique(slice []string, val string) []string {
-	for _, s := range slice {
-		if s == val {
-			return slice
+// Matches reports whether labels satisfy all requirements of the selector.
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		if !r.Matches(labels) {
+			return false
 		}
 	}
-	return append(slice, val)
+	return true
 }
 
-// ApplyLabelSelectorFilter applies a CLI-provided label selector to an EvalSpec
-// by merging it into each taskSet's LabelSelector.
-// Different keys use AND semantics; duplicate keys use OR semantics.
-// Example: "suite=kubernetes,suite=helm" keeps taskSets matching either suite.
-//
-// For taskSets that don't already have a key set, the taskSet is expanded into
-// one copy per value so that tasks are filtered correctly at the task level.
-//
-// This is intentionally kept in the eval package so filtering logic is consolidated
-// outside of the CLI layer.
-func ApplyLabelSelectorFilter(spec *EvalSpec, selector string) error {
-	if spec == nil {
-		return fmt.Errorf("eval spec cannot be nil")
+// String returns the selector in the syntax accepted by ParseLabelSelector.
+func (s LabelSelector) String() string {
+	parts := make([]string, 0, len(s))
+	for _, r := range s {
+		parts = append(parts, r.String())
 	}
+	return strings.Join(parts, ",")
+}
 
-	labels, err := ParseLabelSelector(selector)
-	if err != nil {
-		return err
+// asMap returns the selector as a map of labels if it only has single-value
+// equality requirements on distinct keys.
+func (s LabelSelector) asMap() (map[string]string, bool) {
+	labels := make(map[string]string, len(s))
+	for _, r := range s {
+		if r.Operator != LabelOpIn || len(r.Values) != 1 {
+			return nil, false
+		}
+		if _, dup := labels[r.Key]; dup {
+			return nil, false
+		}
+		labels[r.Key] = r.Values[0]
+	}
+	return labels, true
+}
+
+// MarshalJSON encodes the selector as a map of labels when possible, and as a
+// selector string otherwise.
+func (s LabelSelector) MarshalJSON() ([]byte, error) {
+	if labels, ok := s.asMap(); ok {
+		return json.Marshal(labels)
 	}
-	if len(labels) == 0 {
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON decodes a selector from a map of labels or a selector string.
+func (s *LabelSelector) UnmarshalJSON(data []byte) error {
+	var expr string
+	if err := json.Unmarshal(data, &expr); err == nil {
+		selector, err := ParseLabelSelector(expr)
+		if err != nil {
+			return err
+		}
+		*s = selector
 		return nil
 	}
 
-	var filteredTaskSets []TaskSet
-	for _, ts := range spec.Config.TaskSets {
-		if ts.LabelSelector == nil {
-			ts.LabelSelector = make(map[string]string)
-		}
-
-		// Check compatibility: if the taskSet already has a value for a key,
-		// it must be one of the requested values.
-		compatible := true
-		for key, values := range labels {
-			if existing, exists := ts.LabelSelector[key]; exists {
-				if !containsValue(values, existing) {
-					compatible = false
-					break
+	var labels map[string]string
+	if err := json.Unmarshal(data, &labels); err != nil {
+		return fmt.Errorf("label selector must be a map of labels or a selector string: %w", err)
+	}
+	if labels == nil {
+		*s = nil
+		return nil
+	}
+	*s = LabelSelectorFromMap(labels)
+	return nil
+}
+
+// satisfiable reports whether some set of labels can match the selector. It is
+// used to drop taskSets whose own selector contradicts the CLI selector.
+func (s LabelSelector) satisfiable() bool {
+	byKey := make(map[string][]LabelRequirement)
+	for _, r := range s {
+		byKey[r.Key] = append(byKey[r.Key], r)
+	}
+
+	for key, reqs := range byKey {
+		var candidates []string
+		restricted, exists, notExists := false, false, false
+		for _, r := range reqs {
+			switch r.Operator {
+			case LabelOpIn:
+				if !restricted {
+					candidates = slices.Clone(r.Values)
+					restricted = true
+				} else {
+					candidates = slices.DeleteFunc(candidates, func(v string) bool {
+						return !slices.Contains(r.Values, v)
+					})
 				}
+			case LabelOpExists:
+				exists = true
+			case LabelOpDoesNotExist:
+				notExists = true
 			}
 		}
-		if !compatible {
+
+		if !restricted {
+			if exists && notExists {
+				return false
+			}
 			continue
 		}
 
-		// Expand: for keys not already set on the taskSet, create a copy per value.
-		expanded := expandTaskSet(ts, labels)
-		filteredTaskSets = append(filteredTaskSets, expanded...)
+		// The key must be set to one of the candidates that passes every requirement
+		if !slices.ContainsFunc(candidates, func(v string) bool {
+			return LabelSelector(reqs).Matches(map[string]string{key: v})
+		}) {
+			return false
+		}
 	}
 
-	if len(filteredTaskSets) == 0 {
-		return fmt.Errorf("no taskSets match label selector: %s", selector)
-	}
+	return true
+}
 
-	spec.Config.TaskSets = filteredTaskSets
+// setRequirementPattern matches "key in (a,b)" and "key notin (a,b)"
+var setRequirementPattern = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
 
-	return nil
-}
+// ParseLabelSelector parses a Kubernetes-style label selector of comma-separated
+// requirements, all of which must match:
+//
+//	key=value, key==value   label is set to value
+//	key!=value              label is unset or set to another value
+//	key in (v1,v2)          label is set to one of the values
+//	key notin (v1,v2)       label is unset or set to none of the values
+//	key                     label is set
+//	!key                    label is unset
+//
+// Unlike Kubernetes, repeated equality requirements on the same key match any
+// of their values: "suite=kubernetes,suite=helm,difficulty=easy" means
+// (suite=kubernetes OR suite=helm) AND difficulty=easy.
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	selector = strings.TrimSpace(selector)
+	if selector == "" {
+		return nil, nil
+	}
 
-// expandTaskSet creates copies of a taskSet for each combination of unset label values.
-// Keys already present on the taskSet are left as-is.
-func expandTaskSet(ts TaskSet, labels map[string][]string) []TaskSet {
-	result := []TaskSet{ts}
+	parts, err := splitRequirements(selector)
+	if err != nil {
+		return nil, err
+	}
 
-	for key, values := range labels {
-		if _, exists := ts.LabelSelector[key]; exists {
-			// Already set — no expansion needed for this key
-			continue
+	var result LabelSelector
+	inIndex := make(map[string]int) // key -> index of its "in" requirement in result
+	for _, part := range parts {
+		r, err := parseRequirement(part)
+		if err != nil {
+			return nil, err
 		}
 
-		// Expand current result set: for each existing copy, create one per value
-		var expanded []TaskSet
-		for _, r := range result {
-			for _, val := range values {
-				copy := copyTaskSet(r)
-				copy.LabelSelector[key] = val
-				expanded = append(expanded, copy)
+		if r.Operator == LabelOpIn {
+			if i, ok := inIndex[r.Key]; ok {
+				for _, v := range r.Values {
+					result[i].Values = appendUnique(result[i].Values, v)
+				}
+				continue
 			}
+			inIndex[r.Key] = len(result)
 		}
-		result = expanded
+		result = append(result, r)
 	}
 
-	return result
+	return result, nil
 }
 
-func copyTaskSet(ts TaskSet) TaskSet {
-	newSelector := make(map[string]string, len(ts.LabelSelector))
-	for k, v := range ts.LabelSelector {
-		newSelector[k] = v
+// splitRequirements splits a selector on the commas that are not inside a value set.
+func splitRequirements(selector string) ([]string, error) {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("invalid label selector %q: unbalanced parentheses", selector)
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, selector[start:i])
+				start = i + 1
+			}
+		}
 	}
-	ts.LabelSelector = newSelector
-	return ts
+	if depth != 0 {
+		return nil, fmt.Errorf("invalid label selector %q: unbalanced parentheses", selector)
+	}
+	return append(parts, selector[start:]), nil
 }
 
-func containsValue(values []string, target string) bool {
-	for _, v := range values {
-		if v == target {
-			return true
+func parseRequirement(part string) (LabelRequirement, error) {
+	part = strings.TrimSpace(part)
+	if part == "" {
+		return LabelRequirement{}, fmt.Errorf("label selector requirement cannot be empty")
+	}
+
+	if m := setRequirementPattern.FindStringSubmatch(part); m != nil {
+		var values []string
+		for _, v := range strings.Split(m[3], ",") {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				return LabelRequirement{}, fmt.Errorf("invalid label selector requirement %q: values cannot be empty", part)
+			}
+			values = appendUnique(values, v)
 		}
+		return newRequirement(part, m[1], LabelOperator(m[2]), values)
 	}
-	return false
+
+	if eq := strings.Index(part, "="); eq >= 0 {
+		key, value, op := part[:eq], part[eq+1:], LabelOpIn
+		if strings.HasSuffix(key, "!") {
+			key, op = strings.TrimSuffix(key, "!"), LabelOpNotIn
+		} else if strings.HasPrefix(value, "=") {
+			value = value[1:]
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return LabelRequirement{}, fmt.Errorf("label selector key and value cannot be empty")
+		}
+		return newRequirement(part, key, op, []string{value})
+	}
+
+	if key, ok := strings.CutPrefix(part, "!"); ok {
+		return newRequirement(part, key, LabelOpDoesNotExist, nil)
+	}
+	return newRequirement(part, part, LabelOpExists, nil)
 }
 
-// matchesLabelSelector checks if the task labels match the label selector.
-// All labels in the selector must match (AND logic).
-// Returns true if selector is empty or nil.
-func matchesLabelSelector(taskLabels, selector map[string]string) bool {
-	if len(selector) == 0 {
-		return true
+func newRequirement(part, key string, op LabelOperator, values []string) (LabelRequirement, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return LabelRequirement{}, fmt.Errorf("label selector key and value cannot be empty")
+	}
+	if strings.ContainsAny(key, " \t()!=") {
+		return LabelRequirement{}, fmt.Errorf("invalid label selector requirement %q: invalid key %q", part, key)
 	}
+	return LabelRequirement{Key: key, Operator: op, Values: values}, nil
+}
 
-	for key, value := range selector {
-		taskValue, exists := taskLabels[key]
-		if !exists || taskValue != value {
-			return false
+func appendUnique(slice []string, val string) []string {
+	if slices.Contains(slice, val) {
+		return slice
+	}
+	return append(slice, val)
+}
+
+// ApplyLabelSelectorFilter applies a CLI-provided label selector to an EvalSpec
+// by adding its requirements to each taskSet's LabelSelector, so that tasks must
+// match both. TaskSets whose own selector contradicts the CLI selector (e.g.
+// suite=helm with -l suite=kubernetes) are dropped.
+//
+// This is intentionally kept in the eval package so filtering logic is consolidated
+// outside of the CLI layer.
+func ApplyLabelSelectorFilter(spec *EvalSpec, selector string) error {
+	if spec == nil {
+		return fmt.Errorf("eval spec cannot be nil")
+	}
+
+	requirements, err := ParseLabelSelector(selector)
+	if err != nil {
+		return err
+	}
+	if len(requirements) == 0 {
+		return nil
+	}
+
+	var filteredTaskSets []TaskSet
+	for _, ts := range spec.Config.TaskSets {
+		combined := slices.Concat(ts.LabelSelector, requirements)
+		if !combined.satisfiable() {
+			continue
 		}
+		ts.LabelSelector = combined
+		filteredTaskSets = append(filteredTaskSets, ts)
 	}
 
-	return true
+	if len(filteredTaskSets) == 0 {
+		return fmt.Errorf("no taskSets match label selector: %s", selector)
+	}
+
+	spec.Config.TaskSets = filteredTaskSets
+
+	return nil
 }
@@ -1,27 +1,59 @@
 package eval
 
 import (
+	"encoding/json"
+	"reflect"
 	"testing"
 )
 
 func TestParseLabelSelector(t *testing.T) {
+	in := func(key string, values ...string) LabelRequirement {
+		return LabelRequirement{Key: key, Operator: LabelOpIn, Values: values}
+	}
+	notIn := func(key string, values ...string) LabelRequirement {
+		return LabelRequirement{Key: key, Operator: LabelOpNotIn, Values: values}
+	}
+
 	tests := []struct {
-		name    string
+		name     string
 		selector string
-		want    map[string][]string
-		wantErr bool
+		want     LabelSelector
+		wantErr  bool
 	}{
 		{"empty string", "", nil, false},
-		{"single label", "suite=kubernetes", map[string][]string{"suite": {"kubernetes"}}, false},
-		{"different keys (AND)", "suite=kubernetes,difficulty=easy", map[string][]string{"suite": {"kubernetes"}, "difficulty": {"easy"}}, false},
-		{"same key multiple values (OR)", "suite=kubernetes,suite=helm", map[string][]string{"suite": {"kubernetes", "helm"}}, false},
-		{"mixed AND and OR", "suite=kubernetes,suite=helm,difficulty=easy", map[string][]string{"suite": {"kubernetes", "helm"}, "difficulty": {"easy"}}, false},
-		{"whitespace around pairs", " suite=kubernetes , suite=helm ", map[string][]string{"suite": {"kubernetes", "helm"}}, false},
-		{"duplicate same value deduped", "suite=kubernetes,suite=kubernetes", map[string][]string{"suite": {"kubernetes"}}, false},
-		{"missing value", "suite", nil, true},
+		{"single label", "suite=kubernetes", LabelSelector{in("suite", "kubernetes")}, false},
+		{"double equals", "suite==kubernetes", LabelSelector{in("suite", "kubernetes")}, false},
+		{"different keys (AND)", "suite=kubernetes,difficulty=easy", LabelSelector{in("suite", "kubernetes"), in("difficulty", "easy")}, false},
+		{"same key multiple values (OR)", "suite=kubernetes,suite=helm", LabelSelector{in("suite", "kubernetes", "helm")}, false},
+		{"mixed AND and OR", "suite=kubernetes,suite=helm,difficulty=easy", LabelSelector{in("suite", "kubernetes", "helm"), in("difficulty", "easy")}, false},
+		{"whitespace around pairs", " suite=kubernetes , suite=helm ", LabelSelector{in("suite", "kubernetes", "helm")}, false},
+		{"duplicate same value deduped", "suite=kubernetes,suite=kubernetes", LabelSelector{in("suite", "kubernetes")}, false},
+		{"not equals", "difficulty!=hard", LabelSelector{notIn("difficulty", "hard")}, false},
+		{"in set", "suite in (kubernetes, helm)", LabelSelector{in("suite", "kubernetes", "helm")}, false},
+		{"notin set", "suite notin (istio,kiali)", LabelSelector{notIn("suite", "istio", "kiali")}, false},
+		{"in set merged with equality", "suite in (kubernetes,helm),suite=istio", LabelSelector{in("suite", "kubernetes", "helm", "istio")}, false},
+		{"exists", "requires", LabelSelector{{Key: "requires", Operator: LabelOpExists}}, false},
+		{"does not exist", "!requires", LabelSelector{{Key: "requires", Operator: LabelOpDoesNotExist}}, false},
+		{
+			"combined",
+			"suite in (kubernetes,helm),difficulty!=hard,!deprecated,category",
+			LabelSelector{
+				in("suite", "kubernetes", "helm"),
+				notIn("difficulty", "hard"),
+				{Key: "deprecated", Operator: LabelOpDoesNotExist},
+				{Key: "category", Operator: LabelOpExists},
+			},
+			false,
+		},
+		{"value with equals", "key=val=ue", LabelSelector{in("key", "val=ue")}, false},
 		{"empty key", "=value", nil, true},
 		{"empty value", "suite=", nil, true},
-		{"value with equals", "key=val=ue", map[string][]string{"key": {"val=ue"}}, false},
+		{"empty not equals value", "suite!=", nil, true},
+		{"empty requirement", "suite=kubernetes,,difficulty=easy", nil, true},
+		{"empty set", "suite in ()", nil, true},
+		{"unbalanced parentheses", "suite in (kubernetes,helm", nil, true},
+		{"key with spaces", "my suite", nil, true},
+		{"empty does not exist key", "!", nil, true},
 	}
 
 	for _, tt := range tests {
@@ -31,23 +63,113 @@ func TestParseLabelSelector(t *testing.T) {
 				t.Errorf("ParseLabelSelector(%q) error = %v, wantErr %v", tt.selector, err, tt.wantErr)
 				return
 			}
-			if !tt.wantErr {
-				if len(got) != len(tt.want) {
-					t.Errorf("ParseLabelSelector(%q) = %v, want %v", tt.selector, got, tt.want)
-					return
-				}
-				for k, wantVals := range tt.want {
-					gotVals := got[k]
-					if len(gotVals) != len(wantVals) {
-						t.Errorf("ParseLabelSelector(%q)[%q] = %v, want %v", tt.selector, k, gotVals, wantVals)
-						continue
-					}
-					for i, v := range wantVals {
-						if gotVals[i] != v {
-							t.Errorf("ParseLabelSelector(%q)[%q][%d] = %q, want %q", tt.selector, k, i, gotVals[i], v)
-						}
-					}
-				}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLabelSelector(%q) = %v, want %v", tt.selector, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabelSelectorMatches(t *testing.T) {
+	labels := map[string]string{"suite": "kubernetes", "difficulty": "easy"}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"", true},
+		{"suite=kubernetes", true},
+		{"suite=helm", false},
+		{"suite=helm,suite=kubernetes", true},
+		{"suite!=helm", true},
+		{"suite!=kubernetes", false},
+		{"requires!=istio", true},
+		{"suite in (helm,kubernetes)", true},
+		{"suite in (helm,istio)", false},
+		{"suite notin (helm,istio)", true},
+		{"suite notin (kubernetes)", false},
+		{"requires notin (istio)", true},
+		{"difficulty", true},
+		{"requires", false},
+		{"!requires", true},
+		{"!difficulty", false},
+		{"suite in (kubernetes,helm),difficulty!=hard,!deprecated", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			selector, err := ParseLabelSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("ParseLabelSelector(%q) failed: %v", tt.selector, err)
+			}
+			if got := selector.Matches(labels); got != tt.want {
+				t.Errorf("%q.Matches(%v) = %v, want %v", tt.selector, labels, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabelSelectorJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		want     LabelSelector
+		wantJSON string
+		wantErr  bool
+	}{
+		{
+			name:     "map of labels",
+			input:    `{"suite":"kubernetes","difficulty":"easy"}`,
+			want:     LabelSelectorFromMap(map[string]string{"suite": "kubernetes", "difficulty": "easy"}),
+			wantJSON: `{"difficulty":"easy","suite":"kubernetes"}`,
+		},
+		{
+			name:     "equality selector string",
+			input:    `"suite=kubernetes"`,
+			want:     LabelSelector{{Key: "suite", Operator: LabelOpIn, Values: []string{"kubernetes"}}},
+			wantJSON: `{"suite":"kubernetes"}`,
+		},
+		{
+			name:  "set-based selector string",
+			input: `"suite in (kubernetes,helm),!deprecated"`,
+			want: LabelSelector{
+				{Key: "suite", Operator: LabelOpIn, Values: []string{"kubernetes", "helm"}},
+				{Key: "deprecated", Operator: LabelOpDoesNotExist},
+			},
+			wantJSON: `"suite in (kubernetes,helm),!deprecated"`,
+		},
+		{
+			name:    "invalid selector string",
+			input:   `"suite in (kubernetes"`,
+			wantErr: true,
+		},
+		{
+			name:    "wrong type",
+			input:   `["suite=kubernetes"]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got LabelSelector
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal(%s) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal(%s) = %v, want %v", tt.input, got, tt.want)
+			}
+
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if string(data) != tt.wantJSON {
+				t.Errorf("Marshal() = %s, want %s", data, tt.wantJSON)
 			}
 		})
 	}
@@ -84,8 +206,8 @@ func TestApplyLabelSelectorFilter(t *testing.T) {
 		{
 			name: "single label matches one",
 			spec: makeSpec(
-				TaskSet{LabelSelector: map[string]string{"suite": "k8s"}},
-				TaskSet{LabelSelector: map[string]string{"suite": "helm"}},
+				TaskSet{LabelSelector: LabelSelectorFromMap(map[string]string{"suite": "k8s"})},
+				TaskSet{LabelSelector: LabelSelectorFromMap(map[string]string{"suite": "helm"})},
 			),
 			selector:     "suite=k8s",
 			wantTaskSets: 1,
@@ -93,9 +215,9 @@ func TestApplyLabelSelectorFilter(t *testing.T) {
 		{
 			name: "OR on same key matches multiple taskSets",
 			spec: makeSpec(
-				TaskSet{LabelSelector: map[string]string{"suite": "k8s"}},
-				TaskSet{LabelSelector: map[string]string{"suite": "helm"}},
-				TaskSet{LabelSelector: map[string]string{"suite": "istio"}},
+				TaskSet{LabelSelector: LabelSelectorFromMap(map[string]string{"suite": "k8s"})},
+				TaskSet{LabelSelector: LabelSelectorFromMap(map[string]string{"suite": "helm"})},
+				TaskSet{LabelSelector: LabelSelectorFromMap(map[string]string{"suite": "istio"})},
 			),
 			selector:     "suite=k8s,suite=helm",
 			wantTaskSets: 2,
@@ -103,9 +225,9 @@ func TestApplyLabelSelectorFilter(t *testing.T) {
 		{
 			name: "AND across keys narrows results",
 			spec: makeSpec(
-				TaskSet{LabelSelector: map[string]string{"suite": "k8s", "difficulty": "easy"}},
-				TaskSet{LabelSelector: map[string]string{"suite": "k8s", "difficulty": "hard"}},
-				TaskSet{LabelSelector: map[string]string{"suite": "helm"}},
+				TaskSet{LabelSelector: LabelSelectorFromMap(map[string]string{"suite": "k8s", "difficulty": "easy"})},
+				TaskSet{LabelSelector: LabelSelectorFromMap(map[string]string{"suite": "k8s", "difficulty": "hard"})},
+				TaskSet{LabelSelector: LabelSelectorFromMap(map[string]string{"suite": "helm"})},
 			),
 			selector:     "suite=k8s,difficulty=easy",
 			wantTaskSets: 1,
@@ -113,9 +235,9 @@ func TestApplyLabelSelectorFilter(t *testing.T) {
 		{
 			name: "OR and AND combined",
 			spec: makeSpec(
-				TaskSet{LabelSelector: map[string]string{"suite": "k8s", "difficulty": "easy"}},
-				TaskSet{LabelSelector: map[string]string{"suite": "helm", "difficulty": "easy"}},
-				TaskSet{LabelSelector: map[string]string{"suite": "istio", "difficulty": "easy"}},
+				TaskSet{LabelSelector: LabelSelectorFromMap(map[string]string{"suite": "k8s", "difficulty": "easy"})},
+				TaskSet{LabelSelector: LabelSelectorFromMap(map[string]string{"suite": "helm", "difficulty": "easy"})},
+				TaskSet{LabelSelector: LabelSelectorFromMap(map[string]string{"suite": "istio", "difficulty": "easy"})},
 			),
 			selector:     "suite=k8s,suite=helm,difficulty=easy",
 			wantTaskSets: 2,
@@ -123,13 +245,13 @@ func TestApplyLabelSelectorFilter(t *testing.T) {
 		{
 			name: "no matches",
 			spec: makeSpec(
-				TaskSet{LabelSelector: map[string]string{"suite": "helm"}},
+				TaskSet{LabelSelector: LabelSelectorFromMap(map[string]string{"suite": "helm"})},
 			),
 			selector: "suite=k8s",
 			wantErr:  true,
 		},
 		{
-			name: "taskSet without labels expands for single value",
+			name: "taskSet without labels gets single value",
 			spec: makeSpec(
 				TaskSet{},
 			),
@@ -137,17 +259,47 @@ func TestApplyLabelSelectorFilter(t *testing.T) {
 			wantTaskSets: 1,
 		},
 		{
-			name: "taskSet without labels expands for OR values",
+			name: "taskSet without labels gets OR values as one set",
 			spec: makeSpec(
 				TaskSet{},
 			),
 			selector:     "suite=k8s,suite=helm",
+			wantTaskSets: 1,
+		},
+		{
+			name: "in set matches multiple taskSets",
+			spec: makeSpec(
+				TaskSet{LabelSelector: LabelSelectorFromMap(map[string]string{"suite": "k8s"})},
+				TaskSet{LabelSelector: LabelSelectorFromMap(map[string]string{"suite": "helm"})},
+				TaskSet{LabelSelector: LabelSelectorFromMap(map[string]string{"suite": "istio"})},
+			),
+			selector:     "suite in (k8s,helm)",
+			wantTaskSets: 2,
+		},
+		{
+			name: "notin drops excluded taskSets",
+			spec: makeSpec(
+				TaskSet{LabelSelector: LabelSelectorFromMap(map[string]string{"suite": "k8s"})},
+				TaskSet{LabelSelector: LabelSelectorFromMap(map[string]string{"suite": "helm"})},
+				TaskSet{},
+			),
+			selector:     "suite notin (helm)",
 			wantTaskSets: 2,
 		},
+		{
+			name: "does not exist drops taskSets requiring the label",
+			spec: makeSpec(
+				TaskSet{LabelSelector: LabelSelectorFromMap(map[string]string{"requires": "istio"})},
+				TaskSet{LabelSelector: LabelSelector{{Key: "requires", Operator: LabelOpExists}}},
+				TaskSet{},
+			),
+			selector:     "!requires",
+			wantTaskSets: 1,
+		},
 		{
 			name:     "invalid selector format",
 			spec:     makeSpec(TaskSet{}),
-			selector: "invalid",
+			selector: "suite in (k8s",
 			wantErr:  true,
 		},
 	}
@@ -167,3 +319,39 @@ func TestApplyLabelSelectorFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyLabelSelectorFilterCombinesRequirements(t *testing.T) {
+	spec := &EvalSpec{
+		Config: EvalConfig{
+			TaskSets: []TaskSet{
+				{Glob: "tasks/*.yaml", LabelSelector: LabelSelectorFromMap(map[string]string{"suite": "k8s"})},
+			},
+		},
+	}
+
+	if err := ApplyLabelSelectorFilter(spec, "difficulty!=hard,!deprecated"); err != nil {
+		t.Fatalf("ApplyLabelSelectorFilter() error = %v", err)
+	}
+
+	got := spec.Config.TaskSets[0].LabelSelector.String()
+	want := "suite=k8s,difficulty!=hard,!deprecated"
+	if got != want {
+		t.Errorf("combined selector = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		labels map[string]string
+		want   bool
+	}{
+		{map[string]string{"suite": "k8s", "difficulty": "easy"}, true},
+		{map[string]string{"suite": "k8s"}, true},
+		{map[string]string{"suite": "k8s", "difficulty": "hard"}, false},
+		{map[string]string{"suite": "k8s", "deprecated": "true"}, false},
+		{map[string]string{"suite": "helm"}, false},
+	}
+	for _, tt := range tests {
+		if got := spec.Config.TaskSets[0].LabelSelector.Matches(tt.labels); got != tt.want {
+			t.Errorf("Matches(%v) = %v, want %v", tt.labels, got, tt.want)
+		}
+	}
+}
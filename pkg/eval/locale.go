@@ -0,0 +1,47 @@
+package eval
+
+import (
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+// selectedLocale returns the locale that selects the prompt of tasks with a
+// prompt per locale.
+func (r *evalRunner) selectedLocale() string {
+	if r.locale != "" {
+		return r.locale
+	}
+	return task.DefaultLocale
+}
+
+// localizeTasks resolves the prompt of the tasks with a prompt per locale to
+// the prompt of the selected locale, and splits out the tasks without one.
+// Tasks with a single prompt run as they are, whatever the locale.
+func (r *evalRunner) localizeTasks(tasks []taskConfig) ([]taskConfig, []taskConfig) {
+	locale := r.selectedLocale()
+	localized := make([]taskConfig, 0, len(tasks))
+	var unavailable []taskConfig
+
+	for _, tc := range tasks {
+		if tc.spec.Spec == nil || !tc.spec.Spec.Prompt.HasLocales() {
+			localized = append(localized, tc)
+			continue
+		}
+
+		prompt, ok := tc.spec.Spec.Prompt.ForLocale(locale)
+		if !ok {
+			unavailable = append(unavailable, tc)
+			continue
+		}
+
+		spec := *tc.spec
+		taskSpec := *spec.Spec
+		taskSpec.Prompt = prompt
+		spec.Spec = &taskSpec
+
+		tc.spec = &spec
+		tc.locale = locale
+		localized = append(localized, tc)
+	}
+
+	return localized, unavailable
+}
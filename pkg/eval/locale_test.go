@@ -0,0 +1,102 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+func localizedTask(name string, locales map[string]string) taskConfig {
+	prompt := &task.Prompt{Locales: make(map[string]*util.Step)}
+	for locale, text := range locales {
+		prompt.Locales[locale] = &util.Step{Inline: text}
+	}
+	return taskConfig{
+		path: name + ".yaml",
+		spec: &task.TaskConfig{
+			Metadata: task.TaskMetadata{Name: name},
+			Spec:     &task.TaskSpec{Prompt: prompt},
+		},
+	}
+}
+
+func TestLocalizeTasks(t *testing.T) {
+	single := promptTask("single", "List the pods")
+	both := localizedTask("both", map[string]string{"en": "List the pods", "de": "Liste die Pods auf"})
+	english := localizedTask("english", map[string]string{"en": "List the nodes"})
+
+	tests := map[string]struct {
+		locale            string
+		expectRunnable    []string
+		expectPrompts     []string
+		expectLocales     []string
+		expectUnavailable []string
+	}{
+		"default locale": {
+			expectRunnable: []string{"single", "both", "english"},
+			expectPrompts:  []string{"List the pods", "List the pods", "List the nodes"},
+			expectLocales:  []string{"", "en", "en"},
+		},
+		"selected locale": {
+			locale:            "de",
+			expectRunnable:    []string{"single", "both"},
+			expectPrompts:     []string{"List the pods", "Liste die Pods auf"},
+			expectLocales:     []string{"", "de"},
+			expectUnavailable: []string{"english"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &evalRunner{locale: tc.locale}
+			runnable, unavailable := r.localizeTasks([]taskConfig{single, both, english})
+
+			require.Len(t, runnable, len(tc.expectRunnable))
+			for i, rtc := range runnable {
+				assert.Equal(t, tc.expectRunnable[i], rtc.spec.Metadata.Name)
+				assert.Equal(t, tc.expectLocales[i], rtc.locale)
+				prompt, err := rtc.spec.Spec.Prompt.GetValue()
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectPrompts[i], prompt)
+			}
+
+			var unavailableNames []string
+			for _, utc := range unavailable {
+				unavailableNames = append(unavailableNames, utc.spec.Metadata.Name)
+			}
+			assert.Equal(t, tc.expectUnavailable, unavailableNames)
+		})
+	}
+
+	assert.True(t, both.spec.Spec.Prompt.HasLocales(), "the task itself should keep its locales")
+}
+
+func TestNewRunnerLocale(t *testing.T) {
+	spec := &EvalSpec{Config: EvalConfig{Locale: "de"}}
+
+	r, err := NewRunner(spec)
+	require.NoError(t, err)
+	assert.Equal(t, "de", r.(*evalRunner).selectedLocale())
+
+	r, err = NewRunner(spec, RunnerOptions{Locale: "ja"})
+	require.NoError(t, err)
+	assert.Equal(t, "ja", r.(*evalRunner).selectedLocale())
+
+	r, err = NewRunner(&EvalSpec{})
+	require.NoError(t, err)
+	assert.Equal(t, task.DefaultLocale, r.(*evalRunner).selectedLocale())
+}
+
+func TestSkipTaskRecordsLocale(t *testing.T) {
+	r := &evalRunner{runs: 1, progressCallback: func(ProgressEvent) {}}
+	tc := localizedTask("english", map[string]string{"en": "List the nodes"})
+
+	results := r.skipTask(tc, SkipReasonLocaleUnavailable, `no prompt for locale "de" (has en)`)
+	require.Len(t, results, 1)
+	assert.Equal(t, SkipReasonLocaleUnavailable, results[0].SkipReason)
+	assert.False(t, results[0].SkipReason.CountsAsNotPassed())
+}
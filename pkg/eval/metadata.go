@@ -0,0 +1,119 @@
+package eval
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Keys of the run metadata captured from CI environments
+const (
+	MetadataCI          = "ci"
+	MetadataRepository  = "repository"
+	MetadataBranch      = "branch"
+	MetadataPullRequest = "pullRequest"
+	MetadataCommit      = "commit"
+	MetadataRunURL      = "runURL"
+)
+
+var githubPullRef = regexp.MustCompile(`^refs/pull/(\d+)/`)
+
+// ParseMetadata parses key=value annotations, as given with check --meta.
+func ParseMetadata(annotations []string) (map[string]string, error) {
+	if len(annotations) == 0 {
+		return nil, nil
+	}
+
+	metadata := make(map[string]string, len(annotations))
+	for _, annotation := range annotations {
+		key, value, ok := strings.Cut(annotation, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid metadata %q: must be key=value", annotation)
+		}
+		metadata[key] = value
+	}
+	return metadata, nil
+}
+
+// CIMetadata returns the run metadata of the CI environment that getenv
+// reads, such as the branch and pull request number, or nil outside of a
+// known CI system. GitHub Actions, GitLab CI, Prow, Buildkite and Jenkins are
+// detected.
+func CIMetadata(getenv func(string) string) map[string]string {
+	var ci, repository, branch, pullRequest, commit, runURL string
+
+	switch {
+	case getenv("GITHUB_ACTIONS") == "true":
+		ci = "github-actions"
+		repository = getenv("GITHUB_REPOSITORY")
+		branch = firstNonEmpty(getenv("GITHUB_HEAD_REF"), getenv("GITHUB_REF_NAME"))
+		if m := githubPullRef.FindStringSubmatch(getenv("GITHUB_REF")); m != nil {
+			pullRequest = m[1]
+		}
+		commit = getenv("GITHUB_SHA")
+		if server, runID := getenv("GITHUB_SERVER_URL"), getenv("GITHUB_RUN_ID"); server != "" && repository != "" && runID != "" {
+			runURL = fmt.Sprintf("%s/%s/actions/runs/%s", server, repository, runID)
+		}
+
+	case getenv("GITLAB_CI") == "true":
+		ci = "gitlab"
+		repository = getenv("CI_PROJECT_PATH")
+		branch = firstNonEmpty(getenv("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME"), getenv("CI_COMMIT_REF_NAME"))
+		pullRequest = getenv("CI_MERGE_REQUEST_IID")
+		commit = getenv("CI_COMMIT_SHA")
+		runURL = getenv("CI_PIPELINE_URL")
+
+	case getenv("PROW_JOB_ID") != "":
+		ci = "prow"
+		if org, repo := getenv("REPO_OWNER"), getenv("REPO_NAME"); org != "" && repo != "" {
+			repository = org + "/" + repo
+		}
+		branch = getenv("PULL_BASE_REF")
+		pullRequest = getenv("PULL_NUMBER")
+		commit = firstNonEmpty(getenv("PULL_PULL_SHA"), getenv("PULL_BASE_SHA"))
+
+	case getenv("BUILDKITE") == "true":
+		ci = "buildkite"
+		repository = getenv("BUILDKITE_REPO")
+		branch = getenv("BUILDKITE_BRANCH")
+		if pr := getenv("BUILDKITE_PULL_REQUEST"); pr != "false" {
+			pullRequest = pr
+		}
+		commit = getenv("BUILDKITE_COMMIT")
+		runURL = getenv("BUILDKITE_BUILD_URL")
+
+	case getenv("JENKINS_URL") != "":
+		ci = "jenkins"
+		branch = firstNonEmpty(getenv("CHANGE_BRANCH"), getenv("BRANCH_NAME"), getenv("GIT_BRANCH"))
+		pullRequest = getenv("CHANGE_ID")
+		commit = getenv("GIT_COMMIT")
+		runURL = getenv("BUILD_URL")
+
+	default:
+		return nil
+	}
+
+	metadata := map[string]string{MetadataCI: ci}
+	for key, value := range map[string]string{
+		MetadataRepository:  repository,
+		MetadataBranch:      branch,
+		MetadataPullRequest: pullRequest,
+		MetadataCommit:      commit,
+		MetadataRunURL:      runURL,
+	} {
+		if value != "" {
+			metadata[key] = value
+		}
+	}
+	return metadata
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
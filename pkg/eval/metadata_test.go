@@ -0,0 +1,133 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMetadata(t *testing.T) {
+	tests := map[string]struct {
+		annotations []string
+		expected    map[string]string
+		errContains string
+	}{
+		"none": {},
+		"pairs": {
+			annotations: []string{"model-family=gpt", "note=a=b", "empty="},
+			expected:    map[string]string{"model-family": "gpt", "note": "a=b", "empty": ""},
+		},
+		"missing value": {
+			annotations: []string{"branch"},
+			errContains: `invalid metadata "branch": must be key=value`,
+		},
+		"missing key": {
+			annotations: []string{"=main"},
+			errContains: `invalid metadata "=main"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			metadata, err := ParseMetadata(tc.annotations)
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, metadata)
+		})
+	}
+}
+
+func TestCIMetadata(t *testing.T) {
+	tests := map[string]struct {
+		env      map[string]string
+		expected map[string]string
+	}{
+		"no CI": {
+			env: map[string]string{"HOME": "/home/user"},
+		},
+		"github pull request": {
+			env: map[string]string{
+				"GITHUB_ACTIONS":    "true",
+				"GITHUB_REPOSITORY": "mcpchecker/mcpchecker",
+				"GITHUB_HEAD_REF":   "fix-judge",
+				"GITHUB_REF_NAME":   "42/merge",
+				"GITHUB_REF":        "refs/pull/42/merge",
+				"GITHUB_SHA":        "3f9c2e7",
+				"GITHUB_SERVER_URL": "https://github.com",
+				"GITHUB_RUN_ID":     "1234",
+			},
+			expected: map[string]string{
+				"ci":          "github-actions",
+				"repository":  "mcpchecker/mcpchecker",
+				"branch":      "fix-judge",
+				"pullRequest": "42",
+				"commit":      "3f9c2e7",
+				"runURL":      "https://github.com/mcpchecker/mcpchecker/actions/runs/1234",
+			},
+		},
+		"github push": {
+			env: map[string]string{
+				"GITHUB_ACTIONS":  "true",
+				"GITHUB_REF_NAME": "main",
+				"GITHUB_REF":      "refs/heads/main",
+			},
+			expected: map[string]string{"ci": "github-actions", "branch": "main"},
+		},
+		"gitlab merge request": {
+			env: map[string]string{
+				"GITLAB_CI":                           "true",
+				"CI_PROJECT_PATH":                     "group/evals",
+				"CI_MERGE_REQUEST_SOURCE_BRANCH_NAME": "feature",
+				"CI_COMMIT_REF_NAME":                  "main",
+				"CI_MERGE_REQUEST_IID":                "7",
+				"CI_COMMIT_SHA":                       "abc",
+				"CI_PIPELINE_URL":                     "https://gitlab.com/group/evals/-/pipelines/9",
+			},
+			expected: map[string]string{
+				"ci":          "gitlab",
+				"repository":  "group/evals",
+				"branch":      "feature",
+				"pullRequest": "7",
+				"commit":      "abc",
+				"runURL":      "https://gitlab.com/group/evals/-/pipelines/9",
+			},
+		},
+		"prow": {
+			env: map[string]string{
+				"PROW_JOB_ID":   "f00",
+				"REPO_OWNER":    "openshift",
+				"REPO_NAME":     "evals",
+				"PULL_BASE_REF": "main",
+				"PULL_NUMBER":   "12",
+				"PULL_PULL_SHA": "def",
+			},
+			expected: map[string]string{
+				"ci":          "prow",
+				"repository":  "openshift/evals",
+				"branch":      "main",
+				"pullRequest": "12",
+				"commit":      "def",
+			},
+		},
+		"buildkite without pull request": {
+			env: map[string]string{
+				"BUILDKITE":              "true",
+				"BUILDKITE_BRANCH":       "main",
+				"BUILDKITE_PULL_REQUEST": "false",
+			},
+			expected: map[string]string{"ci": "buildkite", "branch": "main"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			getenv := func(key string) string { return tc.env[key] }
+			assert.Equal(t, tc.expected, CIMetadata(getenv))
+		})
+	}
+}
@@ -0,0 +1,385 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/client"
+	extprotocol "github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// GeneratedCleanupPrefix starts the names of the extension operations that
+// delete everything the extension generated for a run, such as
+// deleteGeneratedNamespaces. They take no arguments, and tell the resources
+// of the run by its ID, from RunIDEnv.
+const GeneratedCleanupPrefix = "deleteGenerated"
+
+// generatedCleanupTimeout is how long a deleteGenerated* operation may take
+const generatedCleanupTimeout = 5 * time.Minute
+
+// setupRecord returns the part of result that the cleanup of the run can be
+// replayed from: which run it is and the outputs of its setup steps.
+func setupRecord(result *EvalResult) *EvalResult {
+	record := &EvalResult{
+		TaskID:           result.TaskID,
+		TaskName:         result.TaskName,
+		TaskPath:         result.TaskPath,
+		RunIndex:         result.RunIndex,
+		Environment:      result.Environment,
+		AllowedToolsMode: result.AllowedToolsMode,
+		PromptVariant:    result.PromptVariant,
+		Locale:           result.Locale,
+		SetupOutput:      result.SetupOutput,
+	}
+	// The setup output is shared with result, so a copy is redacted
+	return util.RedactedCopy(record)
+}
+
+// runKey identifies a run of a task within an eval run.
+func runKey(result *EvalResult) string {
+	return fmt.Sprintf("%s\x00%s\x00%d\x00%s\x00%d\x00%s\x00%s", result.TaskPath, result.TaskName, result.RunIndex, result.AllowedToolsMode, result.PromptVariant, result.Locale, result.Environment)
+}
+
+// OrphanedRuns returns the task runs of a journal whose setup ran but whose
+// cleanup did not: runs with a JournalSetup entry but no result, which a crash
+// interrupted, and results whose setup succeeded without cleanup running. A
+// results file can be passed as its JournalResult entries.
+func OrphanedRuns(entries []JournalEntry) []*EvalResult {
+	completed := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Type == JournalResult && entry.Result != nil {
+			completed[runKey(entry.Result)] = true
+		}
+	}
+
+	var orphaned []*EvalResult
+	for _, entry := range entries {
+		run := entry.Result
+		if run == nil {
+			continue
+		}
+		switch entry.Type {
+		case JournalSetup:
+			if !completed[runKey(run)] {
+				orphaned = append(orphaned, run)
+			}
+		case JournalResult:
+			if run.SetupOutput != nil && run.SetupOutput.Success && run.CleanupOutput == nil {
+				orphaned = append(orphaned, run)
+			}
+		}
+	}
+	return orphaned
+}
+
+// CleanupReplay is the outcome of replaying the cleanup of orphaned runs.
+type CleanupReplay struct {
+	Runs      []*RunCleanup       `json:"runs"`
+	Generated []*GeneratedCleanup `json:"generated,omitempty"`
+	Warnings  []string            `json:"warnings,omitempty"`
+}
+
+// RunCleanup is the outcome of the cleanup steps of an orphaned run.
+type RunCleanup struct {
+	Run    *EvalResult       `json:"run"`
+	Output *task.PhaseOutput `json:"output,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// GeneratedCleanup is the outcome of a deleteGenerated* operation of an
+// extension.
+type GeneratedCleanup struct {
+	Extension string `json:"extension"`
+	Operation string `json:"operation"`
+	Message   string `json:"message,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Failed returns the number of cleanups and operations that failed.
+func (c *CleanupReplay) Failed() int {
+	failed := 0
+	for _, run := range c.Runs {
+		if run.Error != "" || (run.Output != nil && !run.Output.Success) {
+			failed++
+		}
+	}
+	for _, op := range c.Generated {
+		if op.Error != "" {
+			failed++
+		}
+	}
+	return failed
+}
+
+// ReplayCleanup replays the cleanup of runs, the orphaned runs of the eval run
+// with ID runID, with the extensions and MCP servers of spec, or those of the
+// environment a run ran in. The cleanup steps of the task of each run are run
+// with the outputs its setup steps recorded, and then the deleteGenerated*
+// operations of the extensions the tasks require, which delete what the
+// extensions generated for the run even where no outputs were recorded. They
+// are skipped with a warning without a runID, since extensions couldn't tell
+// the resources of the run from those of other runs.
+func ReplayCleanup(ctx context.Context, spec *EvalSpec, runID string, runs []*EvalResult) (*CleanupReplay, error) {
+	r := &evalRunner{spec: spec, runID: runID}
+
+	// Scripts, MCP servers and extensions tell the resources of the run by
+	// its ID, like during the run
+	if runID != "" {
+		defer setRunIDEnv(runID)()
+	}
+
+	mcpConfig, err := r.loadMcpConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	r.deps = &steps.Dependencies{}
+	if mcpConfig != nil {
+		if runID != "" {
+			addRunIDHeader(mcpConfig, runID)
+		}
+		mcpManager, err := mcpclient.NewManager(ctx, mcpConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to MCP servers: %w", err)
+		}
+		defer func() {
+			closeCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			_ = mcpManager.Close(closeCtx)
+		}()
+		if r.deps.McpClients, err = mcpproxy.NewShadowedManager(mcpManager, spec.Config.Shadow); err != nil {
+			return nil, err
+		}
+	}
+
+	extManager, err := r.newExtensionManager(spec.Config.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	defer shutdownExtensions(extManager)
+	r.deps.Extensions = extManager
+
+	// Runs are replayed in the environment they ran in, those of the eval
+	// config first
+	var environments []string
+	byEnvironment := make(map[string][]*EvalResult)
+	for _, run := range runs {
+		if _, ok := byEnvironment[run.Environment]; !ok && run.Environment != "" {
+			environments = append(environments, run.Environment)
+		}
+		byEnvironment[run.Environment] = append(byEnvironment[run.Environment], run)
+	}
+
+	replay, err := r.replayCleanup(ctx, byEnvironment[""])
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range environments {
+		envReplay, err := r.replayEnvironmentCleanup(ctx, name, byEnvironment[name])
+		if err != nil {
+			return nil, err
+		}
+		replay.Runs = append(replay.Runs, envReplay.Runs...)
+		replay.Generated = append(replay.Generated, envReplay.Generated...)
+		replay.Warnings = append(replay.Warnings, envReplay.Warnings...)
+	}
+	return replay, nil
+}
+
+// replayEnvironmentCleanup replays the cleanup of runs, which ran in the
+// environment name, with the dependencies of the environment.
+func (r *evalRunner) replayEnvironmentCleanup(ctx context.Context, name string, runs []*EvalResult) (*CleanupReplay, error) {
+	env, err := r.setUpEnvironment(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer env.close()
+	defer r.useEnvironment(env)()
+
+	return r.replayCleanup(ctx, runs)
+}
+
+// replayCleanup replays the cleanup of runs with the dependencies of r.
+func (r *evalRunner) replayCleanup(ctx context.Context, runs []*EvalResult) (*CleanupReplay, error) {
+	libraries, err := task.LoadLibraries(r.spec.Config.StepLibraries)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := &CleanupReplay{}
+	var extensions []string
+	for _, run := range runs {
+		cleanup, required := r.replayRunCleanup(ctx, run, libraries)
+		replay.Runs = append(replay.Runs, cleanup)
+		for _, name := range required {
+			if !slices.Contains(extensions, name) {
+				extensions = append(extensions, name)
+			}
+		}
+	}
+
+	slices.Sort(extensions)
+	if r.runID == "" && len(extensions) > 0 {
+		replay.Warnings = append(replay.Warnings, fmt.Sprintf("skipped the %s* operations of extensions %s: the run has no run ID, so they would delete what the extensions generated for every run", GeneratedCleanupPrefix, strings.Join(extensions, ", ")))
+		return replay, nil
+	}
+	for _, name := range extensions {
+		replay.Generated = append(replay.Generated, r.deleteGenerated(ctx, name)...)
+	}
+	return replay, nil
+}
+
+// replayRunCleanup runs the cleanup steps of the task of run, returning their
+// outcome and the extensions that the task requires.
+func (r *evalRunner) replayRunCleanup(ctx context.Context, run *EvalResult, libraries map[string]*task.StepLibrary) (*RunCleanup, []string) {
+	cleanup := &RunCleanup{Run: run}
+
+	spec, err := loadRunTask(run)
+	if err == nil {
+		err = spec.ExpandSteps(libraries)
+	}
+	if err != nil {
+		cleanup.Error = fmt.Sprintf("failed to load task: %v", err)
+		return cleanup, nil
+	}
+
+	var extensions []string
+	if spec.Spec != nil {
+		for _, req := range spec.Spec.Requires {
+			if req.Extension != nil {
+				extensions = append(extensions, *req.Extension)
+			}
+		}
+	}
+
+	// The prompt isn't used, but task runners need the one of the locale
+	localizer := &evalRunner{locale: run.Locale}
+	localized, _ := localizer.localizeTasks([]taskConfig{{path: run.TaskPath, spec: spec}})
+	if len(localized) == 0 {
+		cleanup.Error = fmt.Sprintf("task has no prompt for locale %q", run.Locale)
+		return cleanup, extensions
+	}
+	tc := localized[0]
+
+	taskRunner, err := task.NewTaskRunner(ctx, tc.spec, r.deps)
+	if err != nil {
+		cleanup.Error = fmt.Sprintf("failed to create task runner: %v", err)
+		return cleanup, extensions
+	}
+	taskRunner.RestoreSetup(run.SetupOutput)
+
+	cleanupTimeout, hasCleanupTimeout, err := r.resolveCleanupTimeout(tc)
+	if err != nil {
+		cleanup.Error = err.Error()
+		return cleanup, extensions
+	}
+	if hasCleanupTimeout {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cleanupTimeout)
+		defer cancel()
+	}
+
+	cleanup.Output, err = taskRunner.Cleanup(ctx)
+	if err != nil {
+		cleanup.Error = err.Error()
+	}
+	return cleanup, extensions
+}
+
+// loadRunTask loads the task that run ran, which is the instance of a task
+// template of the same name for templates.
+func loadRunTask(run *EvalResult) (*task.TaskConfig, error) {
+	template, err := task.FromFile(run.TaskPath)
+	if err != nil {
+		return nil, err
+	}
+	if template.Spec == nil || len(template.Spec.Parameters) == 0 {
+		return template, nil
+	}
+	instances, err := template.Instances()
+	if err != nil {
+		return nil, err
+	}
+	for _, instance := range instances {
+		if instance.Metadata.Name == run.TaskName {
+			return instance, nil
+		}
+	}
+	return nil, fmt.Errorf("%s has no task named %q", run.TaskPath, run.TaskName)
+}
+
+// deleteGenerated runs the deleteGenerated* operations of the extension name.
+func (r *evalRunner) deleteGenerated(ctx context.Context, name string) []*GeneratedCleanup {
+	extManager, _ := r.deps.ExtensionManager()
+	if extManager == nil || !extManager.Has(name) {
+		return []*GeneratedCleanup{{Extension: name, Error: "extension is not in the eval config"}}
+	}
+	ext, err := extManager.Get(ctx, name)
+	if err != nil {
+		return []*GeneratedCleanup{{Extension: name, Error: fmt.Sprintf("failed to start extension: %v", err)}}
+	}
+
+	var operations []string
+	for operation := range ext.Manifest().Operations {
+		if strings.HasPrefix(operation, GeneratedCleanupPrefix) {
+			operations = append(operations, operation)
+		}
+	}
+	slices.Sort(operations)
+
+	var cleanups []*GeneratedCleanup
+	for _, operation := range operations {
+		cleanups = append(cleanups, runGeneratedCleanup(ctx, ext, name, operation, r.spec.BasePath()))
+	}
+	return cleanups
+}
+
+// runGeneratedCleanup runs the deleteGenerated* operation of ext in workdir.
+func runGeneratedCleanup(ctx context.Context, ext client.Client, name, operation, workdir string) *GeneratedCleanup {
+	cleanup := &GeneratedCleanup{Extension: name, Operation: operation}
+
+	args := map[string]any{}
+	params, err := ext.Manifest().Operations[operation].GetParams()
+	if err == nil {
+		err = params.Validate(args)
+	}
+	if err != nil {
+		cleanup.Error = fmt.Sprintf("operation can't be called without arguments: %v", err)
+		return cleanup
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, generatedCleanupTimeout)
+	defer cancel()
+	res, err := ext.Execute(ctx, &extprotocol.ExecuteParams{
+		Operation: operation,
+		Args:      args,
+		Context: extprotocol.ExecuteContext{
+			Workdir: workdir,
+			Phase:   "cleanup",
+			Timeout: generatedCleanupTimeout.String(),
+		},
+	}, nil)
+	switch {
+	case err != nil:
+		cleanup.Error = err.Error()
+	case !res.Success:
+		cleanup.Error = res.Error
+		if cleanup.Error == "" {
+			cleanup.Error = res.Message
+		}
+		if cleanup.Error == "" {
+			cleanup.Error = "operation failed"
+		}
+	default:
+		cleanup.Message = res.Message
+	}
+	return cleanup
+}
@@ -0,0 +1,268 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	extprotocol "github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupRecordDoesNotRedactResult(t *testing.T) {
+	util.RegisterSecret("orphan-s3cr3t-value")
+
+	result := &EvalResult{
+		TaskName:    "create-pod",
+		SetupOutput: &task.PhaseOutput{Success: true, Error: "token orphan-s3cr3t-value"},
+	}
+
+	record := setupRecord(result)
+	assert.Equal(t, "token ***", record.SetupOutput.Error)
+	assert.Equal(t, "token orphan-s3cr3t-value", result.SetupOutput.Error, "the result of the run is not changed")
+}
+
+func TestOrphanedRuns(t *testing.T) {
+	setupDone := &task.PhaseOutput{Success: true}
+	cleanupDone := &task.PhaseOutput{Success: true}
+
+	tests := map[string]struct {
+		entries []JournalEntry
+		want    []string
+	}{
+		"setup without a result": {
+			entries: []JournalEntry{
+				{Type: JournalStart},
+				{Type: JournalSetup, Result: &EvalResult{TaskName: "a", TaskPath: "a.yaml"}},
+			},
+			want: []string{"a"},
+		},
+		"setup with a result": {
+			entries: []JournalEntry{
+				{Type: JournalSetup, Result: &EvalResult{TaskName: "a", TaskPath: "a.yaml"}},
+				{Type: JournalResult, Result: &EvalResult{TaskName: "a", TaskPath: "a.yaml", SetupOutput: setupDone, CleanupOutput: cleanupDone}},
+			},
+		},
+		"other runs of the task completed": {
+			entries: []JournalEntry{
+				{Type: JournalSetup, Result: &EvalResult{TaskName: "a", TaskPath: "a.yaml"}},
+				{Type: JournalSetup, Result: &EvalResult{TaskName: "a", TaskPath: "a.yaml", RunIndex: 1}},
+				{Type: JournalSetup, Result: &EvalResult{TaskName: "a", TaskPath: "a.yaml", Locale: "de"}},
+				{Type: JournalResult, Result: &EvalResult{TaskName: "a", TaskPath: "a.yaml", RunIndex: 1, CleanupOutput: cleanupDone}},
+			},
+			want: []string{"a", "a"},
+		},
+		"runs in other environments": {
+			entries: []JournalEntry{
+				{Type: JournalSetup, Result: &EvalResult{TaskName: "a", TaskPath: "a.yaml", Environment: "dev"}},
+				{Type: JournalSetup, Result: &EvalResult{TaskName: "a", TaskPath: "a.yaml", Environment: "prod"}},
+				{Type: JournalResult, Result: &EvalResult{TaskName: "a", TaskPath: "a.yaml", Environment: "dev", CleanupOutput: cleanupDone}},
+			},
+			want: []string{"a"},
+		},
+		"instances of a task template": {
+			entries: []JournalEntry{
+				{Type: JournalSetup, Result: &EvalResult{TaskName: "a[image=nginx]", TaskPath: "a.yaml"}},
+				{Type: JournalSetup, Result: &EvalResult{TaskName: "a[image=redis]", TaskPath: "a.yaml"}},
+				{Type: JournalResult, Result: &EvalResult{TaskName: "a[image=nginx]", TaskPath: "a.yaml", CleanupOutput: cleanupDone}},
+			},
+			want: []string{"a[image=redis]"},
+		},
+		"result without cleanup": {
+			entries: []JournalEntry{
+				{Type: JournalStart},
+				{Type: JournalResult, Result: &EvalResult{TaskName: "a", TaskPath: "a.yaml", SetupOutput: setupDone}},
+				{Type: JournalResult, Result: &EvalResult{TaskName: "b", TaskPath: "b.yaml", SetupOutput: setupDone, CleanupOutput: cleanupDone}},
+			},
+			want: []string{"a"},
+		},
+		"skipped result": {
+			entries: []JournalEntry{
+				{Type: JournalResult, Result: &EvalResult{TaskName: "a", TaskPath: "a.yaml", Skipped: true}},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			for _, run := range OrphanedRuns(tc.entries) {
+				got = append(got, run.TaskName)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRunTaskJournalsSetup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.ndjson")
+	journal, err := CreateJournal(path)
+	require.NoError(t, err)
+	defer journal.Close()
+
+	ext := &fakeExtensionClient{
+		manifest: &extprotocol.InitializeResult{
+			Name:       "testExt",
+			Version:    "1.0.0",
+			Operations: map[string]*extprotocol.Operation{"createNamespace": {}},
+		},
+	}
+	extManager := newFakeExtensionManager()
+	extManager.extensions["testExt"] = ext
+
+	runner := &evalRunner{
+		spec:             &EvalSpec{},
+		progressCallback: NoopProgressCallback,
+		deps: &steps.Dependencies{
+			McpClients: &fakeMcpManager{},
+			Extensions: extManager,
+		},
+		journal: journal,
+		runID:   "run-1",
+	}
+
+	extName := "testExt"
+	tc := taskConfig{
+		path:     "test.yaml",
+		runIndex: 1,
+		spec: &task.TaskConfig{
+			Metadata: task.TaskMetadata{Name: "journal-setup-test"},
+			Spec: &task.TaskSpec{
+				Requires: []task.Requirements{{Extension: &extName}},
+				Setup: []*steps.StepConfig{{
+					ID:     "ns",
+					Config: map[string]json.RawMessage{"testExt.createNamespace": json.RawMessage(`{}`)},
+				}},
+				Prompt: &task.Prompt{Step: util.Step{Inline: "do something"}},
+			},
+		},
+	}
+
+	_, err = runner.runTask(t.Context(), &fakeAgentRunner{}, tc)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	entries, err := ReadJournal(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	entry := entries[0]
+	assert.Equal(t, JournalSetup, entry.Type)
+	assert.Equal(t, "run-1", entry.RunID)
+	require.NotNil(t, entry.Result)
+	assert.Equal(t, "journal-setup-test", entry.Result.TaskName)
+	assert.Equal(t, 1, entry.Result.RunIndex)
+	require.NotNil(t, entry.Result.SetupOutput)
+	assert.True(t, entry.Result.SetupOutput.Success)
+	assert.Nil(t, entry.Result.AgentOutput, "only the setup is journaled")
+
+	// The run completed, so its cleanup is not replayed
+	assert.Empty(t, OrphanedRuns(append(entries, JournalEntry{Type: JournalResult, Result: &EvalResult{
+		TaskName: "journal-setup-test",
+		TaskPath: "test.yaml",
+		RunIndex: 1,
+	}})))
+}
+
+func TestReplayCleanup(t *testing.T) {
+	dir := t.TempDir()
+	taskPath := filepath.Join(dir, "task.yaml")
+	require.NoError(t, os.WriteFile(taskPath, []byte(`kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: replay-test
+spec:
+  requires:
+    - extension: testExt
+  setup:
+    - id: ns
+      testExt.createNamespace: {}
+  cleanup:
+    - script:
+        inline: test "$NAMESPACE" = test-abc
+        env:
+          NAMESPACE: "{steps.ns.name}"
+  prompt:
+    inline: Do something
+`), 0644))
+
+	ext := &fakeExtensionClient{
+		manifest: &extprotocol.InitializeResult{
+			Name:    "testExt",
+			Version: "1.0.0",
+			Operations: map[string]*extprotocol.Operation{
+				"createNamespace":           {},
+				"deleteGeneratedNamespaces": {},
+				"deleteGeneratedUsers": {
+					Params: jsonschema.Schema{Type: "object", Required: []string{"prefix"}},
+				},
+			},
+		},
+	}
+	extManager := newFakeExtensionManager()
+	extManager.extensions["testExt"] = ext
+
+	runner := &evalRunner{
+		spec:  &EvalSpec{},
+		runID: "run-1",
+		deps: &steps.Dependencies{
+			McpClients: &fakeMcpManager{},
+			Extensions: extManager,
+		},
+	}
+
+	runs := []*EvalResult{
+		{
+			TaskName: "replay-test",
+			TaskPath: taskPath,
+			SetupOutput: &task.PhaseOutput{
+				Success: true,
+				Steps: []*steps.StepOutput{{
+					ID:      "ns",
+					Type:    "testExt.createNamespace",
+					Success: true,
+					Outputs: map[string]string{"name": "test-abc"},
+				}},
+			},
+		},
+		{TaskName: "missing", TaskPath: filepath.Join(dir, "missing.yaml")},
+	}
+
+	replay, err := runner.replayCleanup(t.Context(), runs)
+	require.NoError(t, err)
+
+	require.Len(t, replay.Runs, 2)
+	assert.Empty(t, replay.Runs[0].Error)
+	require.NotNil(t, replay.Runs[0].Output)
+	assert.True(t, replay.Runs[0].Output.Success, "cleanup uses the journaled setup outputs")
+	assert.Contains(t, replay.Runs[1].Error, "failed to load task")
+
+	require.Len(t, replay.Generated, 2)
+	assert.Equal(t, "deleteGeneratedNamespaces", replay.Generated[0].Operation)
+	assert.Empty(t, replay.Generated[0].Error)
+	assert.Equal(t, "deleteGeneratedUsers", replay.Generated[1].Operation)
+	assert.Contains(t, replay.Generated[1].Error, "can't be called without arguments")
+
+	assert.Equal(t, []string{"deleteGeneratedNamespaces"}, ext.executed)
+	assert.Equal(t, 2, replay.Failed())
+	assert.Empty(t, replay.Warnings)
+
+	// Without a run ID, the operations would delete what every run generated
+	ext.executed = nil
+	runner.runID = ""
+	replay, err = runner.replayCleanup(t.Context(), runs)
+	require.NoError(t, err)
+	assert.Empty(t, replay.Generated)
+	assert.Empty(t, ext.executed)
+	require.Len(t, replay.Warnings, 1)
+	assert.Contains(t, replay.Warnings[0], "skipped the deleteGenerated* operations of extensions testExt")
+	assert.Equal(t, 1, replay.Failed())
+}
@@ -5,12 +5,16 @@ import "net/url"
 // EvalOutput wraps evaluation results with configuration summary metadata.
 // This is the top-level structure written to the JSON output file.
 type EvalOutput struct {
-	Summary *EvalSummary `json:"summary"`
+	Summary *EvalSummary  `json:"summary"`
 	Results []*EvalResult `json:"results"`
 }
 
 // EvalSummary captures the resolved configuration used for an evaluation run.
 type EvalSummary struct {
+	// RunID identifies the run, and is also recorded on its journal entries,
+	// its debug directory and its error files
+	RunID string `json:"runId,omitempty"`
+
 	Agent           *AgentSummary      `json:"agent"`
 	Judge           *JudgeSummary      `json:"judge,omitempty"`
 	MCPServers      []MCPServerSummary `json:"mcpServers,omitempty"`
@@ -19,6 +23,73 @@ type EvalSummary struct {
 	Timeout         *TimeoutSummary    `json:"timeout,omitempty"`
 	ParallelWorkers int                `json:"parallelWorkers"`
 	Runs            int                `json:"runs"`
+	Budget          *BudgetSummary     `json:"budget,omitempty"`
+
+	// AllowedTools is set if the agent was restricted to the tools of the
+	// assertions of each task
+	AllowedTools AllowedToolsMode `json:"allowedTools,omitempty"`
+
+	// CatalogueAblation is set if tasks were run with every allowedTools mode,
+	// recorded as allowedToolsMode on each result
+	CatalogueAblation bool `json:"catalogueAblation,omitempty"`
+
+	// PromptVariants is set if tasks were run with paraphrases of their
+	// prompts, recorded as promptVariant on each result
+	PromptVariants *PromptVariantsSummary `json:"promptVariants,omitempty"`
+
+	// Locale is the locale selected for tasks with a prompt per locale, if
+	// one was selected instead of the default
+	Locale string `json:"locale,omitempty"`
+
+	// Metadata annotates the run, e.g. with the CI branch and pull request,
+	// for filtering and grouping results
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Build is the mcpchecker build that ran the eval
+	Build *BuildInfo `json:"build,omitempty"`
+
+	// Provenance records where the results came from
+	Provenance *Provenance `json:"provenance,omitempty"`
+
+	// AdaptiveParallelism reports the number of parallel workers over the
+	// run, if it was adapted to the health of model calls
+	AdaptiveParallelism *AdaptiveParallelismSummary `json:"adaptiveParallelism,omitempty"`
+
+	// Sample is set if only a sample of the tasks was run
+	Sample *SampleSummary `json:"sample,omitempty"`
+
+	// Abort reports whether the run was aborted early after too many failed
+	// task runs, if a limit was set
+	Abort *AbortSummary `json:"abort,omitempty"`
+
+	// Gates are the outcomes of the gates of the eval config
+	Gates []GateResult `json:"gates,omitempty"`
+
+	// Environments describes the environments of the eval config that tasks
+	// ran against, sorted by name
+	Environments []EnvironmentSummary `json:"environments,omitempty"`
+
+	// Canary is the canary config of the run, whose tasks ran against the
+	// baseline and the candidate server as the environments named after them
+	Canary *CanaryConfig `json:"canary,omitempty"`
+
+	// Attempts scores the tasks that ran more than once with pass@1, pass@k
+	// and flakiness
+	Attempts *AttemptsSummary `json:"attempts,omitempty"`
+}
+
+// EnvironmentSummary describes an environment of the eval config.
+type EnvironmentSummary struct {
+	Name       string             `json:"name"`
+	MCPServers []MCPServerSummary `json:"mcpServers,omitempty"`
+}
+
+// BuildInfo describes an mcpchecker build.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"` // GOOS/GOARCH
 }
 
 // SkillSummary describes a configured skill source.
@@ -65,13 +136,21 @@ type ToolSummary struct {
 type EvalsSummary struct {
 	Names    []string         `json:"names"`
 	TaskSets []TaskSetSummary `json:"taskSets,omitempty"`
+
+	// States counts matched tasks per lifecycle state (active, quarantined, deprecated).
+	// Deprecated tasks are counted here but are not run and do not appear in Names.
+	States map[string]int `json:"states,omitempty"`
 }
 
 // TaskSetSummary describes a single task set configuration.
 type TaskSetSummary struct {
-	Glob          string            `json:"glob,omitempty"`
-	Path          string            `json:"path,omitempty"`
-	LabelSelector map[string]string `json:"labelSelector,omitempty"`
+	Glob          string        `json:"glob,omitempty"`
+	Path          string        `json:"path,omitempty"`
+	Recursive     bool          `json:"recursive,omitempty"`
+	Exclude       []string      `json:"exclude,omitempty"`
+	LabelSelector LabelSelector `json:"labelSelector,omitempty"`
+	Environment   string        `json:"environment,omitempty"`
+	Repeat        int           `json:"repeat,omitempty"`
 }
 
 // TimeoutSummary describes the timeout configuration.
@@ -80,6 +159,8 @@ type TimeoutSummary struct {
 	Task           string `json:"task,omitempty"`
 	DefaultCleanup string `json:"defaultCleanup,omitempty"`
 	Cleanup        string `json:"cleanup,omitempty"`
+	DefaultStall   string `json:"defaultStall,omitempty"`
+	Stall          string `json:"stall,omitempty"`
 }
 
 // sanitizeURL strips query parameters and userinfo from a URL to avoid
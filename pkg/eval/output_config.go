@@ -0,0 +1,118 @@
+package eval
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// OutputConfig controls the size of the results file of a run.
+type OutputConfig struct {
+	// Compress writes the results file gzip-compressed, with a .json.gz extension
+	Compress bool `json:"compress,omitempty"`
+
+	// MaxTaskOutputBytes truncates the agent output recorded on each result (0 = unlimited)
+	MaxTaskOutputBytes int `json:"maxTaskOutputBytes,omitempty"`
+
+	// MaxToolResultBytes truncates the text content of each recorded tool call result (0 = unlimited)
+	MaxToolResultBytes int `json:"maxToolResultBytes,omitempty"`
+}
+
+// Validate checks that the limits are not negative.
+func (c *OutputConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.MaxTaskOutputBytes < 0 {
+		return fmt.Errorf("maxTaskOutputBytes must be >= 0, got %d", c.MaxTaskOutputBytes)
+	}
+	if c.MaxToolResultBytes < 0 {
+		return fmt.Errorf("maxToolResultBytes must be >= 0, got %d", c.MaxToolResultBytes)
+	}
+	return nil
+}
+
+// truncate shortens the fields of result that exceed the configured limits. It
+// runs once assertions were evaluated, so it only affects what is persisted.
+func (c *OutputConfig) truncate(result *EvalResult) {
+	if c == nil || result == nil {
+		return
+	}
+
+	if c.MaxTaskOutputBytes > 0 {
+		var truncated bool
+		result.TaskOutput, truncated = truncateText(result.TaskOutput, c.MaxTaskOutputBytes)
+		result.Truncated = result.Truncated || truncated
+
+		if result.AgentOutput != nil {
+			for _, step := range result.AgentOutput.Steps {
+				if step == nil {
+					continue
+				}
+				step.Message, truncated = truncateText(step.Message, c.MaxTaskOutputBytes)
+				result.Truncated = result.Truncated || truncated
+			}
+		}
+	}
+
+	if c.MaxToolResultBytes > 0 && result.CallHistory != nil {
+		for i, call := range result.CallHistory.ToolCalls {
+			if call == nil || call.Result == nil {
+				continue
+			}
+			toolResult, truncated := truncateToolResult(call.Result, c.MaxToolResultBytes)
+			if !truncated {
+				continue
+			}
+			// Copy the call instead of modifying it, since the proxy recorder owns it
+			copied := *call
+			copied.Result = toolResult
+			result.CallHistory.ToolCalls[i] = &copied
+			result.Truncated = true
+		}
+	}
+}
+
+// truncateToolResult returns a copy of res with the text content truncated to
+// maxBytes, or res itself if nothing had to be truncated.
+func truncateToolResult(res *mcp.CallToolResult, maxBytes int) (*mcp.CallToolResult, bool) {
+	var content []mcp.Content
+	for i, c := range res.Content {
+		text, ok := c.(*mcp.TextContent)
+		if !ok {
+			continue
+		}
+		truncated, ok := truncateText(text.Text, maxBytes)
+		if !ok {
+			continue
+		}
+		if content == nil {
+			content = append([]mcp.Content(nil), res.Content...)
+		}
+		copied := *text
+		copied.Text = truncated
+		content[i] = &copied
+	}
+	if content == nil {
+		return res, false
+	}
+
+	copied := *res
+	copied.Content = content
+	return &copied, true
+}
+
+// truncateText shortens s to at most maxBytes bytes, cutting at a rune boundary,
+// and appends a note with the number of bytes left out.
+func truncateText(s string, maxBytes int) (string, bool) {
+	if len(s) <= maxBytes {
+		return s, false
+	}
+
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s\n... [truncated %d bytes]", s[:cut], len(s)-cut), true
+}
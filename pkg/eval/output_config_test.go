@@ -0,0 +1,109 @@
+package eval
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateText(t *testing.T) {
+	tests := map[string]struct {
+		input         string
+		maxBytes      int
+		want          string
+		wantTruncated bool
+	}{
+		"shorter than limit": {
+			input:    "hello",
+			maxBytes: 10,
+			want:     "hello",
+		},
+		"exactly the limit": {
+			input:    "hello",
+			maxBytes: 5,
+			want:     "hello",
+		},
+		"longer than limit": {
+			input:         "hello world",
+			maxBytes:      5,
+			want:          "hello\n... [truncated 6 bytes]",
+			wantTruncated: true,
+		},
+		"cuts at rune boundary": {
+			input:         "héllo",
+			maxBytes:      2,
+			want:          "h\n... [truncated 5 bytes]",
+			wantTruncated: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, truncated := truncateText(tc.input, tc.maxBytes)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantTruncated, truncated)
+		})
+	}
+}
+
+func TestOutputConfigTruncate(t *testing.T) {
+	longText := strings.Repeat("x", 100)
+	original := &mcpproxy.ToolCall{
+		ToolName: "pods_list",
+		Result: &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: longText},
+				&mcp.ImageContent{MIMEType: "image/png"},
+			},
+		},
+	}
+	result := &EvalResult{
+		TaskOutput: longText,
+		AgentOutput: &task.PhaseOutput{
+			Steps: []*steps.StepOutput{{Message: longText}},
+		},
+		CallHistory: &mcpproxy.CallHistory{ToolCalls: []*mcpproxy.ToolCall{original}},
+	}
+
+	cfg := &OutputConfig{MaxTaskOutputBytes: 10, MaxToolResultBytes: 20}
+	cfg.truncate(result)
+
+	assert.True(t, result.Truncated)
+	assert.True(t, strings.HasPrefix(result.TaskOutput, strings.Repeat("x", 10)+"\n... [truncated 90 bytes]"))
+	assert.Equal(t, result.TaskOutput, result.AgentOutput.Steps[0].Message)
+
+	call := result.CallHistory.ToolCalls[0]
+	require.Len(t, call.Result.Content, 2)
+	assert.Equal(t, strings.Repeat("x", 20)+"\n... [truncated 80 bytes]", call.Result.Content[0].(*mcp.TextContent).Text)
+	assert.IsType(t, &mcp.ImageContent{}, call.Result.Content[1])
+	assert.Equal(t, "pods_list", call.ToolName)
+
+	// The recorded call is left untouched
+	assert.Equal(t, longText, original.Result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestOutputConfigTruncateWithinLimits(t *testing.T) {
+	result := &EvalResult{TaskOutput: "short"}
+
+	cfg := &OutputConfig{MaxTaskOutputBytes: 10, MaxToolResultBytes: 10}
+	cfg.truncate(result)
+	assert.False(t, result.Truncated)
+	assert.Equal(t, "short", result.TaskOutput)
+
+	var nilCfg *OutputConfig
+	nilCfg.truncate(result)
+	assert.False(t, result.Truncated)
+}
+
+func TestOutputConfigValidate(t *testing.T) {
+	assert.NoError(t, (*OutputConfig)(nil).Validate())
+	assert.NoError(t, (&OutputConfig{Compress: true, MaxTaskOutputBytes: 10}).Validate())
+	assert.Error(t, (&OutputConfig{MaxTaskOutputBytes: -1}).Validate())
+	assert.Error(t, (&OutputConfig{MaxToolResultBytes: -1}).Validate())
+}
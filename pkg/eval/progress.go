@@ -11,6 +11,7 @@ type ProgressEvent struct {
 	Message string
 	Task    *EvalResult  // Populated for task-related events
 	Summary *EvalSummary // Populated for EventEvalStart
+	Usage   *UsageUpdate // Populated for EventTaskComplete
 }
 
 // ProgressEventType represents the type of progress event
@@ -25,8 +26,24 @@ const (
 	EventTaskAssertions ProgressEventType = "task_assertions"
 	EventTaskComplete   ProgressEventType = "task_complete"
 	EventTaskTimeout    ProgressEventType = "task_timeout"
+	EventTaskStalled    ProgressEventType = "task_stalled"
 	EventTaskError      ProgressEventType = "task_error"
+	EventTaskDeprecated ProgressEventType = "task_deprecated"
 	EventEvalComplete   ProgressEventType = "eval_complete"
+
+	// EventTaskSkippedOverBudget is sent instead of running a task once the run budget is exceeded
+	EventTaskSkippedOverBudget ProgressEventType = "task_skipped_over_budget"
+
+	// EventTaskSkipped is sent for each run of a task that is skipped for another reason, see EvalResult.SkipReason
+	EventTaskSkipped ProgressEventType = "task_skipped"
+
+	// EventTaskWarning is sent with a warning about a task in Message, such as
+	// its use of a deprecated format, when the task is loaded
+	EventTaskWarning ProgressEventType = "task_warning"
+
+	// EventWarning is sent with a warning about the run in Message, such as a
+	// failure to write its journal, that doesn't fail the run
+	EventWarning ProgressEventType = "warning"
 )
 
 // NoopProgressCallback is a progress callback that does nothing
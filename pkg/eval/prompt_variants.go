@@ -0,0 +1,241 @@
+package eval
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/mcpchecker/mcpchecker/pkg/llmagent"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// PromptVariantsConfig configures prompt robustness runs, which run every task
+// with paraphrases of its prompt as well as with the original, to measure how
+// sensitive the agent and the MCP servers are to the wording of prompts.
+type PromptVariantsConfig struct {
+	// Model writing the paraphrases, in "provider:model-id" format
+	Model string `json:"model"`
+
+	// Count is the number of paraphrases of each prompt. Prompt robustness
+	// runs are off if it is 0, unless check --prompt-variants sets it.
+	Count int `json:"count,omitempty"`
+
+	// Seed selects the set of paraphrases. Paraphrases are cached by model,
+	// seed, count and prompt, so runs with the same seed reuse them.
+	Seed int64 `json:"seed,omitempty"`
+
+	// CacheDir is the directory of the paraphrase cache, the user cache
+	// directory if empty
+	CacheDir string `json:"cacheDir,omitempty"`
+
+	// Prompt replaces the default system prompt of the model
+	Prompt string `json:"prompt,omitempty"`
+}
+
+// Validate checks that a model is set and that the count is not negative.
+func (c *PromptVariantsConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Model == "" {
+		return fmt.Errorf("model is required")
+	}
+	if c.Count < 0 {
+		return fmt.Errorf("count must not be negative, got %d", c.Count)
+	}
+	return nil
+}
+
+// PromptVariantsSummary describes the prompt variants of a prompt robustness run.
+type PromptVariantsSummary struct {
+	Model string `json:"model"`
+	Count int    `json:"count"`
+	Seed  int64  `json:"seed"`
+}
+
+// Paraphraser writes paraphrases of task prompts.
+type Paraphraser interface {
+	Paraphrase(ctx context.Context, prompt string, count int, seed int64) ([]string, error)
+}
+
+// DefaultParaphraseCacheDir returns the directory of the paraphrase cache,
+// $XDG_CACHE_HOME/mcpchecker/paraphrases or the platform equivalent.
+func DefaultParaphraseCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine cache directory: %w", err)
+	}
+	return filepath.Join(dir, "mcpchecker", "paraphrases"), nil
+}
+
+// ParaphraseCache is an on-disk cache of the paraphrases of prompts, which
+// makes prompt robustness runs reproducible: models don't repeat their
+// replies, so the paraphrases of a seed are only written once.
+type ParaphraseCache struct {
+	Dir string
+}
+
+// paraphraseCacheEntry is a file of the paraphrase cache.
+type paraphraseCacheEntry struct {
+	Model       string   `json:"model"`
+	Seed        int64    `json:"seed"`
+	Prompt      string   `json:"prompt"`
+	Paraphrases []string `json:"paraphrases"`
+}
+
+// entryPath returns the file of the paraphrases of prompt, keyed by everything
+// that changes them.
+func (c *ParaphraseCache) entryPath(cfg *PromptVariantsConfig, prompt string) string {
+	h := sha256.New()
+	for _, part := range []string{cfg.Model, strconv.FormatInt(cfg.Seed, 10), strconv.Itoa(cfg.Count), cfg.Prompt, prompt} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return filepath.Join(c.Dir, hex.EncodeToString(h.Sum(nil))+".json")
+}
+
+// Get returns the cached paraphrases of prompt for cfg.
+func (c *ParaphraseCache) Get(cfg *PromptVariantsConfig, prompt string) ([]string, bool) {
+	data, err := os.ReadFile(c.entryPath(cfg, prompt))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry paraphraseCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || len(entry.Paraphrases) != cfg.Count {
+		return nil, false
+	}
+	return entry.Paraphrases, true
+}
+
+// Put caches the paraphrases of prompt for cfg. The entry is written to a
+// temporary file and renamed, so parallel tasks never read partial entries.
+func (c *ParaphraseCache) Put(cfg *PromptVariantsConfig, prompt string, paraphrases []string) error {
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create paraphrase cache directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(paraphraseCacheEntry{
+		Model:       cfg.Model,
+		Seed:        cfg.Seed,
+		Prompt:      prompt,
+		Paraphrases: paraphrases,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(c.Dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create paraphrase cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write paraphrase cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write paraphrase cache entry: %w", err)
+	}
+	return os.Rename(tmp.Name(), c.entryPath(cfg, prompt))
+}
+
+// cachedParaphraser returns the paraphrases of the cache, and caches the ones
+// written by its Paraphraser.
+type cachedParaphraser struct {
+	paraphraser Paraphraser
+	cache       *ParaphraseCache
+	cfg         *PromptVariantsConfig
+}
+
+// paraphrase returns the paraphrases of prompt. Failing to cache them is passed
+// to warn rather than returned, since the paraphrases can still be used.
+func (p *cachedParaphraser) paraphrase(ctx context.Context, prompt string, warn func(string)) ([]string, error) {
+	if paraphrases, ok := p.cache.Get(p.cfg, prompt); ok {
+		return paraphrases, nil
+	}
+
+	paraphrases, err := p.paraphraser.Paraphrase(ctx, prompt, p.cfg.Count, p.cfg.Seed)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.cache.Put(p.cfg, prompt, paraphrases); err != nil {
+		warn(fmt.Sprintf("failed to cache paraphrases: %v", err))
+	}
+	return paraphrases, nil
+}
+
+// newParaphraser creates the paraphraser of a prompt robustness run, calling
+// the model of llmCfg.
+func newParaphraser(ctx context.Context, cfg *PromptVariantsConfig, llmCfg llmagent.Config) (*cachedParaphraser, error) {
+	dir := cfg.CacheDir
+	if dir == "" {
+		var err error
+		dir, err = DefaultParaphraseCacheDir()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	paraphraser, err := llmagent.NewParaphraser(ctx, llmCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create prompt paraphraser: %w", err)
+	}
+
+	return &cachedParaphraser{paraphraser: paraphraser, cache: &ParaphraseCache{Dir: dir}, cfg: cfg}, nil
+}
+
+// promptVariants returns the variants of each of tcs to run: the original
+// prompt followed by its paraphrases, in prompt robustness runs. If the prompt
+// can't be paraphrased, the task only runs with the original.
+func (r *evalRunner) promptVariants(ctx context.Context, tcs []taskConfig) []taskConfig {
+	if r.paraphraser == nil || len(tcs) == 0 || tcs[0].spec.Spec == nil || tcs[0].spec.Spec.Prompt.IsEmpty() {
+		return tcs
+	}
+
+	name := tcs[0].spec.Metadata.Name
+	warn := func(message string) {
+		r.progressCallback(ProgressEvent{Type: EventTaskWarning, Message: fmt.Sprintf("task %s: %s", name, message)})
+	}
+	prompt, err := tcs[0].spec.Spec.Prompt.GetValue()
+	if err != nil {
+		warn(fmt.Sprintf("failed to read prompt to paraphrase: %v", err))
+		return tcs
+	}
+
+	paraphrases, err := r.paraphraser.paraphrase(ctx, prompt, warn)
+	if err != nil {
+		warn(fmt.Sprintf("failed to paraphrase prompt, running the original only: %v", err))
+		return tcs
+	}
+
+	variants := make([]taskConfig, 0, len(tcs)*(len(paraphrases)+1))
+	for _, tc := range tcs {
+		variants = append(variants, tc)
+		for i, paraphrase := range paraphrases {
+			variants = append(variants, withPromptParaphrase(tc, i+1, paraphrase))
+		}
+	}
+	return variants
+}
+
+// withPromptParaphrase returns the variant of tc that prompts the agent with
+// paraphrase instead of its prompt.
+func withPromptParaphrase(tc taskConfig, variant int, paraphrase string) taskConfig {
+	spec := *tc.spec
+	taskSpec := *spec.Spec
+	taskSpec.Prompt = &task.Prompt{Step: util.Step{Inline: paraphrase}}
+	spec.Spec = &taskSpec
+
+	tc.spec = &spec
+	tc.promptVariant = variant
+	tc.promptParaphrase = paraphrase
+	return tc
+}
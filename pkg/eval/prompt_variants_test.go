@@ -0,0 +1,176 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/tokens"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// fakeParaphraser numbers the paraphrases of a prompt and counts its calls.
+type fakeParaphraser struct {
+	calls int
+	err   error
+}
+
+func (p *fakeParaphraser) Paraphrase(_ context.Context, prompt string, count int, seed int64) ([]string, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	paraphrases := make([]string, count)
+	for i := range paraphrases {
+		paraphrases[i] = fmt.Sprintf("%s (seed %d, paraphrase %d)", prompt, seed, i+1)
+	}
+	return paraphrases, nil
+}
+
+func promptTask(name, prompt string) taskConfig {
+	return taskConfig{
+		path: "task.yaml",
+		spec: &task.TaskConfig{
+			Metadata: task.TaskMetadata{Name: name},
+			Spec:     &task.TaskSpec{Prompt: &task.Prompt{Step: util.Step{Inline: prompt}}},
+		},
+	}
+}
+
+func TestParaphraseCache(t *testing.T) {
+	cache := &ParaphraseCache{Dir: t.TempDir()}
+	cfg := &PromptVariantsConfig{Model: "openai:gpt-5", Count: 2, Seed: 1}
+
+	_, ok := cache.Get(cfg, "List the pods")
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Put(cfg, "List the pods", []string{"a", "b"}))
+	paraphrases, ok := cache.Get(cfg, "List the pods")
+	require.True(t, ok)
+	assert.Equal(t, []string{"a", "b"}, paraphrases)
+
+	// Any change of model, seed, count, system prompt or prompt is a new entry
+	for name, other := range map[string]*PromptVariantsConfig{
+		"seed":   {Model: "openai:gpt-5", Count: 2, Seed: 2},
+		"count":  {Model: "openai:gpt-5", Count: 3, Seed: 1},
+		"model":  {Model: "openai:gpt-4", Count: 2, Seed: 1},
+		"prompt": {Model: "openai:gpt-5", Count: 2, Seed: 1, Prompt: "rewrite"},
+	} {
+		_, ok := cache.Get(other, "List the pods")
+		assert.False(t, ok, name)
+	}
+	_, ok = cache.Get(cfg, "List the nodes")
+	assert.False(t, ok)
+}
+
+func TestPromptVariants(t *testing.T) {
+	fake := &fakeParaphraser{}
+	cfg := &PromptVariantsConfig{Model: "openai:gpt-5", Count: 2, Seed: 7}
+	r := &evalRunner{
+		paraphraser:      &cachedParaphraser{paraphraser: fake, cache: &ParaphraseCache{Dir: t.TempDir()}, cfg: cfg},
+		progressCallback: NoopProgressCallback,
+	}
+
+	tc := promptTask("list-pods", "List the pods")
+	required, full := tc, tc
+	required.allowedTools = AllowedToolsAssertions
+	full.allowedTools = AllowedToolsAll
+
+	variants := r.promptVariants(t.Context(), []taskConfig{required, full})
+	require.Len(t, variants, 6)
+	for i, variant := range variants {
+		assert.Equal(t, i%3, variant.promptVariant)
+		if i < 3 {
+			assert.Equal(t, AllowedToolsAssertions, variant.allowedTools)
+		} else {
+			assert.Equal(t, AllowedToolsAll, variant.allowedTools)
+		}
+
+		prompt, err := variant.spec.Spec.Prompt.GetValue()
+		require.NoError(t, err)
+		if variant.promptVariant == 0 {
+			assert.Equal(t, "List the pods", prompt)
+			assert.Empty(t, variant.promptParaphrase)
+		} else {
+			want := fmt.Sprintf("List the pods (seed 7, paraphrase %d)", variant.promptVariant)
+			assert.Equal(t, want, prompt)
+			assert.Equal(t, want, variant.promptParaphrase)
+		}
+	}
+	assert.Equal(t, "List the pods", tc.spec.Spec.Prompt.Inline, "the task itself should keep its prompt")
+	assert.Equal(t, 1, fake.calls)
+
+	// Later runs reuse the cached paraphrases
+	variants = r.promptVariants(t.Context(), []taskConfig{tc})
+	require.Len(t, variants, 3)
+	assert.Equal(t, 1, fake.calls)
+}
+
+func TestPromptVariantsFallBackToOriginal(t *testing.T) {
+	tc := promptTask("list-pods", "List the pods")
+
+	var events []ProgressEvent
+	r := &evalRunner{progressCallback: func(e ProgressEvent) { events = append(events, e) }}
+	assert.Len(t, r.promptVariants(t.Context(), []taskConfig{tc}), 1, "runs without prompt variants run the original only")
+	assert.Empty(t, events)
+
+	fake := &fakeParaphraser{err: fmt.Errorf("rate limited")}
+	cfg := &PromptVariantsConfig{Model: "openai:gpt-5", Count: 2}
+	r.paraphraser = &cachedParaphraser{paraphraser: fake, cache: &ParaphraseCache{Dir: t.TempDir()}, cfg: cfg}
+	variants := r.promptVariants(t.Context(), []taskConfig{tc})
+	require.Len(t, variants, 1)
+	assert.Equal(t, 0, variants[0].promptVariant)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, EventTaskWarning, events[0].Type)
+	assert.Equal(t, "task list-pods: failed to paraphrase prompt, running the original only: rate limited", events[0].Message)
+}
+
+func TestExecuteTaskPromptVariants(t *testing.T) {
+	cfg := &PromptVariantsConfig{Model: "openai:gpt-5", Count: 1}
+	runner := &evalRunner{
+		spec:              &EvalSpec{},
+		runs:              2,
+		runsExplicitlySet: true,
+		paraphraser:       &cachedParaphraser{paraphraser: &fakeParaphraser{}, cache: &ParaphraseCache{Dir: t.TempDir()}, cfg: cfg},
+		budget:            newBudgetTracker(&BudgetConfig{MaxTokens: 10}),
+		progressCallback:  func(ProgressEvent) {},
+	}
+	runner.budget.record(&EvalResult{TokenEstimate: &tokens.Estimate{InputTokens: 10}})
+
+	// Both prompts are recorded, each with its own runs
+	results := runner.executeTask(t.Context(), nil, promptTask("list-pods", "List the pods"))
+	require.Len(t, results, 4)
+	for i, result := range results {
+		assert.Equal(t, i/2, result.PromptVariant)
+		assert.Equal(t, i%2, result.RunIndex)
+		if result.PromptVariant > 0 {
+			assert.Equal(t, "List the pods (seed 0, paraphrase 1)", result.PromptParaphrase)
+		} else {
+			assert.Empty(t, result.PromptParaphrase)
+		}
+	}
+}
+
+func TestNewRunnerPromptVariants(t *testing.T) {
+	spec := &EvalSpec{Config: EvalConfig{PromptVariants: &PromptVariantsConfig{Model: "openai:gpt-5", Count: 2}}}
+
+	r, err := NewRunner(spec)
+	require.NoError(t, err)
+	assert.Equal(t, 2, r.(*evalRunner).promptVariantsConfig.Count)
+
+	r, err = NewRunner(spec, RunnerOptions{PromptVariants: 5})
+	require.NoError(t, err)
+	assert.Equal(t, 5, r.(*evalRunner).promptVariantsConfig.Count)
+	assert.Equal(t, 2, spec.Config.PromptVariants.Count, "the eval config should not change")
+
+	_, err = NewRunner(&EvalSpec{}, RunnerOptions{PromptVariants: 3})
+	assert.ErrorContains(t, err, "promptVariants.model")
+
+	_, err = NewRunner(spec, RunnerOptions{PromptVariants: -1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+package eval
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/lockfile"
+)
+
+// Provenance records where the results of a run came from, so that published
+// results can be traced to the exact tasks, tools and models that produced
+// them. Signing the results file makes it tamper-evident.
+type Provenance struct {
+	// MCPCheckerVersion is the version of mcpchecker that ran the eval
+	MCPCheckerVersion string `json:"mcpcheckerVersion"`
+
+	// TaskRepoCommit is the git commit of the repository of the eval config,
+	// if it is in one
+	TaskRepoCommit string `json:"taskRepoCommit,omitempty"`
+
+	// TaskRepoDirty is set if tracked files of the repository had
+	// uncommitted changes
+	TaskRepoDirty bool `json:"taskRepoDirty,omitempty"`
+
+	AgentModel string `json:"agentModel,omitempty"`
+	JudgeModel string `json:"judgeModel,omitempty"`
+
+	// LockfileHash is the SHA-256 of the lockfile next to the eval config,
+	// as sha256:<hex>, if there is one
+	LockfileHash string `json:"lockfileHash,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// NewProvenance returns the provenance of a run of spec by the given version
+// of mcpchecker, with the models of summary.
+func NewProvenance(ctx context.Context, spec *EvalSpec, version string, summary *EvalSummary) *Provenance {
+	p := &Provenance{
+		MCPCheckerVersion: version,
+		CreatedAt:         time.Now().UTC(),
+	}
+	if summary != nil && summary.Agent != nil {
+		p.AgentModel = summary.Agent.Model
+	}
+	if summary != nil && summary.Judge != nil {
+		p.JudgeModel = summary.Judge.Model
+	}
+
+	dir := spec.BasePath()
+	if dir == "" {
+		dir = "."
+	}
+
+	if commit, err := git(ctx, dir, "rev-parse", "HEAD"); err == nil {
+		p.TaskRepoCommit = commit
+		status, err := git(ctx, dir, "status", "--porcelain", "--untracked-files=no")
+		p.TaskRepoDirty = err == nil && status != ""
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, lockfile.FileName)); err == nil {
+		sum := sha256.Sum256(data)
+		p.LockfileHash = "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	return p
+}
+
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...).Output()
+	return strings.TrimSpace(string(out)), err
+}
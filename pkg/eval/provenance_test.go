@@ -0,0 +1,57 @@
+package eval
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mcpchecker/mcpchecker/pkg/lockfile"
+)
+
+func TestNewProvenance(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in PATH")
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "eval.yaml"), []byte("kind: Eval\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, lockfile.FileName), []byte("version: 1\n"), 0644))
+
+	summary := &EvalSummary{
+		Agent: &AgentSummary{Type: "builtin.llm-agent", Model: "openai:gpt-5"},
+		Judge: &JudgeSummary{Model: "anthropic:claude-sonnet-4-5"},
+	}
+	spec := &EvalSpec{basePath: dir}
+
+	// Outside of a repository there is no commit
+	p := NewProvenance(context.Background(), spec, "v1.2.3", summary)
+	assert.Equal(t, "v1.2.3", p.MCPCheckerVersion)
+	assert.Equal(t, "openai:gpt-5", p.AgentModel)
+	assert.Equal(t, "anthropic:claude-sonnet-4-5", p.JudgeModel)
+	assert.Equal(t, "sha256:09bfcc6a14b83e2192b8673677725c84883ee9cd0c70e45c9ec09daa8f2b2847", p.LockfileHash)
+	assert.Empty(t, p.TaskRepoCommit)
+	assert.False(t, p.CreatedAt.IsZero())
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "tasks")
+
+	p = NewProvenance(context.Background(), spec, "v1.2.3", summary)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{40}$`), p.TaskRepoCommit)
+	assert.False(t, p.TaskRepoDirty)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "eval.yaml"), []byte("kind: Eval\nconfig: {}\n"), 0644))
+	p = NewProvenance(context.Background(), spec, "v1.2.3", summary)
+	assert.True(t, p.TaskRepoDirty)
+}
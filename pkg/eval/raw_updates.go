@@ -0,0 +1,117 @@
+package eval
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// DefaultRawUpdatesMaxBytes is the default limit on the size of the raw updates
+// captured for a single task run
+const DefaultRawUpdatesMaxBytes = 10 << 20
+
+// RawCapture configures how the raw session updates of agents are persisted on results.
+type RawCapture struct {
+	// Gzip compresses the captured updates
+	Gzip bool
+	// MaxBytes limits the uncompressed JSON size of the updates of a run. Updates
+	// past the limit are dropped. Zero means no limit.
+	MaxBytes int
+}
+
+// RawUpdates holds the raw session updates of an agent run, as reported by the
+// agent (for ACP agents, the ACP session updates). Use Decode to read them.
+type RawUpdates struct {
+	// Data is the JSON array of updates, unless they are compressed
+	Data json.RawMessage `json:"data,omitempty"`
+	// Gzip is the gzip-compressed JSON array of updates, base64 encoded in results files
+	Gzip []byte `json:"gzip,omitempty"`
+	// Count is the number of captured updates
+	Count int `json:"count"`
+	// Dropped is the number of updates left out because of the size limit
+	Dropped int `json:"dropped,omitempty"`
+}
+
+// captureRawUpdates encodes the raw updates of an agent result. It returns nil
+// if the agent reported no updates.
+func captureRawUpdates(updates any, opts RawCapture) (*RawUpdates, error) {
+	if updates == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(updates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode raw updates: %w", err)
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		// Not a list of updates; capture the value as a single update
+		items = []json.RawMessage{data}
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	// Keep the leading updates that fit in the limit, counting the array brackets and commas
+	kept, size := 0, 2
+	for _, item := range items {
+		if opts.MaxBytes > 0 && size+len(item)+1 > opts.MaxBytes {
+			break
+		}
+		size += len(item) + 1
+		kept++
+	}
+
+	raw := &RawUpdates{Count: kept, Dropped: len(items) - kept}
+	data, err = json.Marshal(items[:kept])
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode raw updates: %w", err)
+	}
+
+	if !opts.Gzip {
+		raw.Data = data
+		return raw, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress raw updates: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress raw updates: %w", err)
+	}
+	raw.Gzip = buf.Bytes()
+	return raw, nil
+}
+
+// Decode returns the captured updates, decompressing them if needed.
+func (r *RawUpdates) Decode() ([]json.RawMessage, error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	data := []byte(r.Data)
+	if len(r.Gzip) > 0 {
+		zr, err := gzip.NewReader(bytes.NewReader(r.Gzip))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress raw updates: %w", err)
+		}
+		defer zr.Close()
+		if data, err = io.ReadAll(zr); err != nil {
+			return nil, fmt.Errorf("failed to decompress raw updates: %w", err)
+		}
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var updates []json.RawMessage
+	if err := json.Unmarshal(data, &updates); err != nil {
+		return nil, fmt.Errorf("failed to parse raw updates: %w", err)
+	}
+	return updates, nil
+}
@@ -0,0 +1,117 @@
+package eval
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rawUpdate struct {
+	Kind string `json:"kind"`
+	Text string `json:"text"`
+}
+
+func TestCaptureRawUpdates(t *testing.T) {
+	updates := []rawUpdate{
+		{Kind: "message", Text: "hello"},
+		{Kind: "tool_call", Text: "pods_list"},
+		{Kind: "message", Text: "done"},
+	}
+
+	tests := map[string]struct {
+		updates     any
+		opts        RawCapture
+		wantNil     bool
+		wantCount   int
+		wantDropped int
+	}{
+		"no updates": {
+			updates: nil,
+			wantNil: true,
+		},
+		"empty updates": {
+			updates: []rawUpdate{},
+			wantNil: true,
+		},
+		"plain": {
+			updates:   updates,
+			wantCount: 3,
+		},
+		"gzip": {
+			updates:   updates,
+			opts:      RawCapture{Gzip: true},
+			wantCount: 3,
+		},
+		"size limit drops later updates": {
+			updates:     updates,
+			opts:        RawCapture{MaxBytes: 80},
+			wantCount:   2,
+			wantDropped: 1,
+		},
+		"size limit with gzip": {
+			updates:     updates,
+			opts:        RawCapture{Gzip: true, MaxBytes: 80},
+			wantCount:   2,
+			wantDropped: 1,
+		},
+		"single value": {
+			updates:   rawUpdate{Kind: "message", Text: "only"},
+			wantCount: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			raw, err := captureRawUpdates(tc.updates, tc.opts)
+			require.NoError(t, err)
+			if tc.wantNil {
+				assert.Nil(t, raw)
+				return
+			}
+			require.NotNil(t, raw)
+			assert.Equal(t, tc.wantCount, raw.Count)
+			assert.Equal(t, tc.wantDropped, raw.Dropped)
+			if tc.opts.Gzip {
+				assert.Empty(t, raw.Data)
+				assert.NotEmpty(t, raw.Gzip)
+			} else {
+				assert.NotEmpty(t, raw.Data)
+				assert.Empty(t, raw.Gzip)
+			}
+
+			decoded, err := raw.Decode()
+			require.NoError(t, err)
+			assert.Len(t, decoded, tc.wantCount)
+
+			var first rawUpdate
+			require.NoError(t, json.Unmarshal(decoded[0], &first))
+			assert.NotEmpty(t, first.Kind)
+		})
+	}
+}
+
+func TestRawUpdatesRoundTrip(t *testing.T) {
+	raw, err := captureRawUpdates([]rawUpdate{{Kind: "message", Text: "hello"}}, RawCapture{Gzip: true})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(EvalResult{TaskName: "task", RawUpdates: raw})
+	require.NoError(t, err)
+
+	var result EvalResult
+	require.NoError(t, json.Unmarshal(data, &result))
+	require.NotNil(t, result.RawUpdates)
+
+	decoded, err := result.RawUpdates.Decode()
+	require.NoError(t, err)
+	require.Len(t, decoded, 1)
+	assert.JSONEq(t, `{"kind":"message","text":"hello"}`, string(decoded[0]))
+}
+
+func TestRawUpdatesDecodeNil(t *testing.T) {
+	var raw *RawUpdates
+	decoded, err := raw.Decode()
+	require.NoError(t, err)
+	assert.Nil(t, decoded)
+}
@@ -0,0 +1,88 @@
+package eval
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
+	"github.com/oklog/ulid"
+)
+
+const (
+	// RunIDEnv is the environment variable that the run ID is set in for the
+	// agent, the MCP servers, the extensions and the scripts of the tasks
+	RunIDEnv = "MCPCHECKER_RUN_ID"
+
+	// RunIDHeader is the HTTP header that the run ID is sent to HTTP MCP
+	// servers in, unless their config sets it
+	RunIDHeader = "Mcpchecker-Run-Id"
+
+	maxRunIDLength = 128
+)
+
+var runIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// NewRunID returns a new run ID, a ULID, which sorts by the time it was
+// created.
+func NewRunID() string {
+	return ulid.MustNew(ulid.Now(), rand.Reader).String()
+}
+
+// ValidateRunID checks that id can be used as a run ID. Run IDs are used in
+// file names, so they are limited to letters, digits, dots, dashes and
+// underscores.
+func ValidateRunID(id string) error {
+	if len(id) > maxRunIDLength {
+		return fmt.Errorf("run ID must be at most %d characters, got %d", maxRunIDLength, len(id))
+	}
+	if !runIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid run ID %q: must start with a letter or digit and contain only letters, digits, '.', '-' and '_'", id)
+	}
+	return nil
+}
+
+// setRunIDEnv sets RunIDEnv to runID for the processes that the run starts,
+// returning a function that restores its previous value.
+func setRunIDEnv(runID string) (restore func()) {
+	previous, had := os.LookupEnv(RunIDEnv)
+	_ = os.Setenv(RunIDEnv, runID)
+	return func() {
+		if had {
+			_ = os.Setenv(RunIDEnv, previous)
+		} else {
+			_ = os.Unsetenv(RunIDEnv)
+		}
+	}
+}
+
+// addRunIDHeader adds the RunIDHeader header with runID to the HTTP servers
+// of cfg whose headers don't set it already.
+func addRunIDHeader(cfg *mcpclient.MCPConfig, runID string) {
+	if cfg == nil {
+		return
+	}
+	for _, server := range cfg.MCPServers {
+		if server == nil || !server.IsHttp() {
+			continue
+		}
+		if hasHeader(server.Headers, RunIDHeader) {
+			continue
+		}
+		if server.Headers == nil {
+			server.Headers = make(map[string]string)
+		}
+		server.Headers[RunIDHeader] = runID
+	}
+}
+
+func hasHeader(headers map[string]string, name string) bool {
+	for key := range headers {
+		if http.CanonicalHeaderKey(key) == http.CanonicalHeaderKey(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package eval
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRunID(t *testing.T) {
+	first, second := NewRunID(), NewRunID()
+
+	assert.Len(t, first, 26)
+	assert.NotEqual(t, first, second)
+	assert.NoError(t, ValidateRunID(first))
+}
+
+func TestValidateRunID(t *testing.T) {
+	tests := map[string]struct {
+		id      string
+		wantErr bool
+	}{
+		"ulid":             {id: "01JAB3K6W9ZQ8X7Y5V4T3S2R1P"},
+		"ci build":         {id: "gh-1234.5_attempt-2"},
+		"empty":            {id: "", wantErr: true},
+		"path separator":   {id: "runs/1", wantErr: true},
+		"leading dot":      {id: "..", wantErr: true},
+		"space":            {id: "run 1", wantErr: true},
+		"too long":         {id: string(make([]byte, 129)), wantErr: true},
+		"leading dash":     {id: "-run", wantErr: true},
+		"non-ascii letter": {id: "rün", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateRunID(tc.id)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewRunnerRunID(t *testing.T) {
+	r, err := NewRunner(&EvalSpec{}, RunnerOptions{RunID: "nightly-42"})
+	require.NoError(t, err)
+	summary := r.(*evalRunner).buildSummary(context.Background(), nil, nil, &fakeJudge{}, nil)
+	assert.Equal(t, "nightly-42", summary.RunID)
+
+	r, err = NewRunner(&EvalSpec{})
+	require.NoError(t, err)
+	assert.NoError(t, ValidateRunID(r.(*evalRunner).runID), "expected a run ID to be generated")
+
+	_, err = NewRunner(&EvalSpec{}, RunnerOptions{RunID: "../escape"})
+	assert.ErrorContains(t, err, "invalid run ID")
+}
+
+func TestAddRunIDHeader(t *testing.T) {
+	cfg := &mcpclient.MCPConfig{MCPServers: map[string]*mcpclient.ServerConfig{
+		"http":   {Type: mcpclient.TransportTypeHttp, URL: "http://localhost:8080/mcp"},
+		"custom": {URL: "http://localhost:8081/mcp", Headers: map[string]string{"mcpchecker-run-id": "mine"}},
+		"stdio":  {Command: "server"},
+	}}
+
+	addRunIDHeader(cfg, "run-1")
+
+	assert.Equal(t, map[string]string{RunIDHeader: "run-1"}, cfg.MCPServers["http"].Headers)
+	assert.Equal(t, map[string]string{"mcpchecker-run-id": "mine"}, cfg.MCPServers["custom"].Headers)
+	assert.Nil(t, cfg.MCPServers["stdio"].Headers)
+
+	addRunIDHeader(nil, "run-1")
+}
+
+func TestSetRunIDEnv(t *testing.T) {
+	t.Setenv(RunIDEnv, "outer")
+
+	restore := setRunIDEnv("inner")
+	assert.Equal(t, "inner", os.Getenv(RunIDEnv))
+
+	restore()
+	assert.Equal(t, "outer", os.Getenv(RunIDEnv))
+}
+
+func TestWriteJournalSetsRunID(t *testing.T) {
+	sink := &recordingSink{}
+	r, err := NewRunner(&EvalSpec{}, RunnerOptions{RunID: "run-1", ResultsSink: sink})
+	require.NoError(t, err)
+
+	r.(*evalRunner).writeJournal(JournalEntry{Type: JournalStart})
+	r.(*evalRunner).writeJournal(JournalEntry{Type: JournalResult, Result: &EvalResult{TaskName: "a"}})
+
+	require.Len(t, sink.entries, 2)
+	for _, entry := range sink.entries {
+		assert.Equal(t, "run-1", entry.RunID)
+	}
+}
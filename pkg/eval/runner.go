@@ -4,54 +4,147 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"regexp"
+	"runtime/debug"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/mcpchecker/mcpchecker/pkg/extension"
 	"github.com/mcpchecker/mcpchecker/pkg/extension/client"
 	"github.com/mcpchecker/mcpchecker/pkg/extension/resolver"
+	"github.com/mcpchecker/mcpchecker/pkg/llmagent"
 	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
 	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
 	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
 	"github.com/mcpchecker/mcpchecker/pkg/task"
 	"github.com/mcpchecker/mcpchecker/pkg/tokens"
 	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 type EvalResult struct {
+	TaskID              string                    `json:"taskId,omitempty"`
 	TaskName            string                    `json:"taskName"`
 	TaskPath            string                    `json:"taskPath"`
 	TaskPassed          bool                      `json:"taskPassed"`
 	TaskOutput          string                    `json:"taskOutput"`
 	TaskError           string                    `json:"taskError,omitempty"`
+	ErrorKind           task.ErrorKind            `json:"errorKind,omitempty"` // Why the run failed: setup, agent, verify or infra
 	TimedOut            bool                      `json:"timedOut,omitempty"`
+	Stalled             bool                      `json:"stalled,omitempty"`          // True if the agent was stopped because it made no progress for the stall timeout
+	StallDiagnostics    string                    `json:"stallDiagnostics,omitempty"` // State of the agent processes when the agent stalled
+	PanicStack          string                    `json:"panicStack,omitempty"`       // Stack of the panic that failed the run, if any
 	TaskJudgeReason     string                    `json:"taskJudgeReason,omitempty"`
 	TaskJudgeError      string                    `json:"taskJudgeError,omitempty"`
+	JudgeOverride       *JudgeOverride            `json:"judgeOverride,omitempty"`       // Verdict of a human reviewer on a failing judge verdict
 	AgentExecutionError bool                      `json:"agentExecutionError,omitempty"` // True if agent failed to execute
+	JudgeError          bool                      `json:"judgeError,omitempty"`          // True if the LLM judge could not produce a verdict
+	SkippedOverBudget   bool                      `json:"skippedOverBudget,omitempty"`   // True if the run was not started because the run budget was exceeded
 	Difficulty          string                    `json:"difficulty"`
+	State               string                    `json:"state,omitempty"` // Task lifecycle state; omitted for active tasks
 	Parallel            bool                      `json:"parallel,omitempty"`
 	RunIndex            int                       `json:"runIndex,omitempty"`  // 0-indexed run number (for multi-run)
 	TotalRuns           int                       `json:"totalRuns,omitempty"` // Total runs for this task (for multi-run)
 	AssertionResults    *CompositeAssertionResult `json:"assertionResults"`
 	AllAssertionsPassed bool                      `json:"allAssertionsPassed"`
 	CallHistory         *mcpproxy.CallHistory     `json:"callHistory"`
+	DebugDir            string                    `json:"debugDir,omitempty"`       // Debug artifacts of the run; only set when MCPCHECKER_DEBUG is set
+	JudgeAuditFile      string                    `json:"judgeAuditFile,omitempty"` // Audit log of the judge calls of the run; only set with RunnerOptions.JudgeAuditDir
+	Truncated           bool                      `json:"truncated,omitempty"`      // True if fields were shortened to the limits of the output config
+
+	// Skipped is set if the run was not started, with SkipReason saying why and
+	// SkipMessage giving details. Skipped runs never pass, but only count as not
+	// passed in pass rates if SkipReason.CountsAsNotPassed.
+	Skipped     bool       `json:"skipped,omitempty"`
+	SkipReason  SkipReason `json:"skipReason,omitempty"`
+	SkipMessage string     `json:"skipMessage,omitempty"`
+
+	// AllowedTools are the tools the agent was allowed to call, as
+	// "server/tool", if they were restricted with allowedTools: assertions
+	AllowedTools []string `json:"allowedTools,omitempty"`
+	// AllowedToolsMode is the variant of the task run by catalogue ablation runs
+	AllowedToolsMode AllowedToolsMode `json:"allowedToolsMode,omitempty"`
+
+	// PromptVariant is the variant of the prompt run by prompt robustness
+	// runs: 0 for the original prompt, or the number of the paraphrase in
+	// PromptParaphrase that the agent was prompted with
+	PromptVariant    int    `json:"promptVariant,omitempty"`
+	PromptParaphrase string `json:"promptParaphrase,omitempty"`
+
+	// Locale is the locale of the prompt the agent was prompted with, for
+	// tasks with a prompt per locale
+	Locale string `json:"locale,omitempty"`
+
+	// Environment is the environment of the eval config the task ran against,
+	// for task sets that select one
+	Environment string `json:"environment,omitempty"`
+
+	// ExpectFailure is set for runs of tasks that are expected to fail, with
+	// the reason they are expected to. Their failures are expected failures
+	// (XFAIL) that don't gate a run, and their passes unexpected passes (XPASS).
+	ExpectFailure       bool   `json:"expectFailure,omitempty"`
+	ExpectFailureReason string `json:"expectFailureReason,omitempty"`
+
+	// Labels are the labels of the task
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Parameters are the values of the parameters of tasks expanded from a
+	// task template
+	Parameters map[string]any `json:"parameters,omitempty"`
+
+	// DurationMs is the wall time of the run, from the start of setup to the
+	// end of cleanup
+	DurationMs int64 `json:"durationMs,omitempty"`
 
 	// TokenEstimate contains token count estimates from agent execution.
 	// Uses tiktoken (cl100k_base encoding). Excludes system prompt and cache tokens.
 	TokenEstimate *tokens.Estimate `json:"tokenEstimate,omitempty"`
 
+	// ResourceUsage contains the CPU, memory and wall time of the agent process,
+	// for agents that run as a subprocess.
+	ResourceUsage *util.ResourceUsage `json:"resourceUsage,omitempty"`
+
 	// JudgeTokenUsage contains token usage from LLM judge.
 	JudgeTokenUsage *tokens.Usage `json:"judgeTokenUsage,omitempty"`
 
+	// StepOutputs contains the outputs of setup and verify steps, keyed by step ID.
+	StepOutputs map[string]map[string]string `json:"stepOutputs,omitempty"`
+
+	// RawUpdates are the raw session updates of the agent, only captured when
+	// RunnerOptions.CaptureRaw is set (check --capture-raw)
+	RawUpdates *RawUpdates `json:"rawUpdates,omitempty"`
+
 	// Phase outputs from task execution
 	SetupOutput   *task.PhaseOutput `json:"setupOutput,omitempty"`
 	AgentOutput   *task.PhaseOutput `json:"agentOutput,omitempty"`
 	VerifyOutput  *task.PhaseOutput `json:"verifyOutput,omitempty"`
 	CleanupOutput *task.PhaseOutput `json:"cleanupOutput,omitempty"`
+
+	// CleanupVerifyOutput is the output of the cleanupVerify steps of the
+	// task, which only run with RunnerOptions.VerifyCleanup (check
+	// --verify-cleanup)
+	CleanupVerifyOutput *task.PhaseOutput `json:"cleanupVerifyOutput,omitempty"`
+	// LeakedResources are the resources that cleanup left behind, as reported
+	// by the failing cleanupVerify steps. They don't fail the run.
+	LeakedResources []string `json:"leakedResources,omitempty"`
+
+	// Attempts scores the runs of the task with pass@1, pass@k and
+	// flakiness, if it ran more than once
+	Attempts *AttemptStats `json:"attempts,omitempty"`
+}
+
+// Passed reports whether the task and all its assertions passed, the outcome
+// that pass rates, attempts and result diffs count a run as passed by.
+func (r *EvalResult) Passed() bool {
+	return r.TaskPassed && r.AllAssertionsPassed
 }
 
 type EvalRunner interface {
@@ -70,6 +163,84 @@ type RunnerOptions struct {
 	TaskTimeout           string // Hard override for ALL task timeouts
 	DefaultCleanupTimeout string // Overrides eval config defaultTaskLimits.cleanupTimeout for tasks without their own
 	CleanupTimeout        string // Hard override for ALL cleanup timeouts
+	StallTimeout          string // Hard override for ALL stall timeouts
+
+	// VerifyCleanup runs the cleanupVerify steps of each task after its
+	// cleanup, recording what cleanup left behind as EvalResult.LeakedResources
+	VerifyCleanup bool
+
+	// JournalFile, if set, is an NDJSON file that each result is appended to as it completes
+	JournalFile string
+
+	// CaptureRaw, if set, persists the raw session updates of agents on results
+	CaptureRaw *RawCapture
+
+	// JudgeAuditDir, if set, is a directory that the prompts and responses of
+	// the LLM judge calls of each task run are written to
+	JudgeAuditDir string
+
+	// Exclusions (CLI flags), applied after the task name pattern
+	SkipPattern string   // Regular expression; tasks whose name matches are not run
+	SkipPaths   []string // Globs; task files matching one, or inside a matching directory, are not run
+
+	// StrictRequires fails the run if a task requires an extension or MCP server
+	// that is not in the eval config, instead of skipping the task
+	StrictRequires bool
+
+	// AllowedTools overrides the allowedTools mode of the eval config
+	AllowedTools AllowedToolsMode
+
+	// CatalogueAblation runs every task with tool assertions twice: once with
+	// only the tools of its assertions allowed and once with all tools allowed
+	CatalogueAblation bool
+
+	// PoolProxies keeps the MCP proxy servers running across tasks, like
+	// poolProxies in the eval config
+	PoolProxies bool
+
+	// PromptVariants overrides the number of paraphrases of the prompt of
+	// each task of promptVariants in the eval config, which sets the model
+	PromptVariants int
+
+	// Locale overrides the locale of the eval config, which selects the
+	// prompt of tasks with a prompt per locale
+	Locale string
+
+	// Build is the mcpchecker build, recorded in the summary
+	Build *BuildInfo
+
+	// Metadata annotates the run, recorded in the summary
+	Metadata map[string]string
+
+	// TaskOutput, if set, returns the writer for the verbose output of a task
+	// run, e.g. to keep the output of parallel tasks apart
+	TaskOutput func(result *EvalResult) io.Writer
+
+	// AdaptiveParallelism adapts the number of parallel workers to the health
+	// of model calls, with the defaults if adaptiveParallelism is not set in
+	// the eval config
+	AdaptiveParallelism bool
+
+	// Sample, if set, runs a stratified random sample of the tasks instead of
+	// all of them
+	Sample *SampleConfig
+
+	// FailedFirst holds the keys of tasks that failed in an earlier run, which
+	// are scheduled before the other parallel tasks
+	FailedFirst map[string]bool
+
+	// MaxFailures, if set, aborts the run once this many task runs failed:
+	// task runs in flight are cancelled, and the others are skipped
+	MaxFailures int
+
+	// ResultsSink, if set, receives the journal entries of the run, e.g. a
+	// sink created by sink.New for the resultsSink of the eval config
+	ResultsSink ResultsSink
+
+	// RunID identifies the run in the results, the journal, the debug
+	// directory and the environment of the agent and MCP servers. A new one
+	// is generated with NewRunID if it is not set.
+	RunID string
 }
 
 type evalRunner struct {
@@ -79,12 +250,79 @@ type evalRunner struct {
 	runs              int
 	runsExplicitlySet bool
 	skillToolName     string // agent-specific tool name for skill assertions (e.g., "Skill")
+	budget            *budgetTracker
+	usage             *usageTracker
+	deps              *steps.Dependencies // shared managers and judge, set for the duration of a run
+	journalFile       string
+	journal           *Journal       // nil when journaling is disabled
+	resultsSink       ResultsSink    // nil when no sink is set
+	debug             *util.DebugDir // nil unless MCPCHECKER_DEBUG is set
+	captureRaw        *RawCapture    // nil unless raw agent updates are persisted
+	judgeAuditDir     string
+	judgeAudit        *util.DebugDir // nil unless judge calls are audited
+	skipMatcher       *regexp.Regexp
+	skipPaths         []string
+	strictRequires    bool
+	allowedTools      AllowedToolsMode
+	catalogueAblation bool
+
+	// promptVariantsConfig configures prompt robustness runs, which are on if
+	// its count is set. paraphraser is set for the duration of such runs.
+	promptVariantsConfig *PromptVariantsConfig
+	paraphraser          *cachedParaphraser
+
+	// locale selects the prompt of tasks with a prompt per locale,
+	// task.DefaultLocale if empty
+	locale string
+
+	// runID identifies the run, and is recorded in the summary and the
+	// journal entries
+	runID string
+
+	// build and metadata are recorded in the summary
+	build    *BuildInfo
+	metadata map[string]string
+
+	// taskOutput returns the writer for the verbose output of a task run
+	taskOutput func(result *EvalResult) io.Writer
+
+	// adaptiveConfig adapts the number of parallel workers if set, through
+	// adaptive, which is set for the duration of a run
+	adaptiveConfig *AdaptiveParallelismConfig
+	adaptive       *adaptiveParallelism
+
+	// rateLimiter limits the model requests of the builtin LLM agents, judge
+	// and summarizer of a run, set for the duration of the run
+	rateLimiter *llmagent.RateLimiter
+
+	// sample selects the tasks that run, if set
+	sample *SampleConfig
+
+	// failedFirst holds the keys of parallel tasks to schedule first
+	failedFirst map[string]bool
+
+	// maxFailures aborts the run through abort, which is set for the
+	// duration of a run
+	maxFailures int
+	abort       *abortTracker
+
+	// poolProxies shares MCP proxy servers across tasks, through proxyPool,
+	// which is set for the duration of a run
+	poolProxies bool
+	proxyPool   *mcpproxy.ServerPool
+
+	// proxyOptions configure the MCP proxy servers during Run
+	proxyOptions []mcpproxy.ServerOption
 
 	// Timeout overrides from CLI
 	defaultTaskTimeout    string
 	taskTimeout           string
 	defaultCleanupTimeout string
 	cleanupTimeout        string
+	stallTimeout          string
+
+	// verifyCleanup runs the cleanupVerify steps of tasks after their cleanup
+	verifyCleanup bool
 }
 
 var _ EvalRunner = &evalRunner{}
@@ -93,6 +331,41 @@ type taskConfig struct {
 	path       string
 	spec       *task.TaskConfig
 	assertions []*TaskAssertions // multiple assertion sets from matching TaskSets, evaluated independently
+
+	// allowedTools overrides the allowedTools mode of the runner for a variant
+	// of the task in catalogue ablation runs
+	allowedTools AllowedToolsMode
+
+	// promptVariant is the number of the paraphrase of the prompt of the task
+	// that the variant runs in prompt robustness runs, 0 for the original
+	promptVariant    int
+	promptParaphrase string
+
+	// locale is the locale of the prompt of tasks with a prompt per locale
+	locale string
+
+	// runIndex is the number of the run of the task, for tasks run several times
+	runIndex int
+
+	// repeat is the number of runs of the task set by its task sets, 0 if
+	// none sets it
+	repeat int
+
+	// environment is the entry of the environments of the eval config the
+	// task runs against, "" for the eval config itself
+	environment string
+
+	// auditLog records the judge calls of the run, if they are audited
+	auditLog *llmjudge.AuditLog
+}
+
+// key returns the key of the task, which tells the runs of the task in each
+// environment apart like results.TaskKey.
+func (tc taskConfig) key() string {
+	if tc.environment == "" {
+		return tc.spec.Metadata.Key()
+	}
+	return tc.spec.Metadata.Key() + "@" + tc.environment
 }
 
 // NewRunner creates a new EvalRunner from an EvalSpec
@@ -120,6 +393,16 @@ func NewRunner(spec *EvalSpec, opts ...RunnerOptions) (EvalRunner, error) {
 		parallelWorkers:   workers,
 		runs:              runs,
 		runsExplicitlySet: runsExplicitlySet,
+		allowedTools:      spec.Config.AllowedTools,
+		poolProxies:       spec.Config.PoolProxies,
+		locale:            spec.Config.Locale,
+		adaptiveConfig:    spec.Config.AdaptiveParallelism,
+		runID:             NewRunID(),
+	}
+
+	if spec.Config.PromptVariants != nil {
+		cfg := *spec.Config.PromptVariants
+		r.promptVariantsConfig = &cfg
 	}
 
 	if len(opts) > 0 {
@@ -127,15 +410,97 @@ func NewRunner(spec *EvalSpec, opts ...RunnerOptions) (EvalRunner, error) {
 		r.taskTimeout = opts[0].TaskTimeout
 		r.defaultCleanupTimeout = opts[0].DefaultCleanupTimeout
 		r.cleanupTimeout = opts[0].CleanupTimeout
+		r.stallTimeout = opts[0].StallTimeout
+		r.verifyCleanup = opts[0].VerifyCleanup
+		r.journalFile = opts[0].JournalFile
+		r.resultsSink = opts[0].ResultsSink
+		r.captureRaw = opts[0].CaptureRaw
+		r.judgeAuditDir = opts[0].JudgeAuditDir
+		r.strictRequires = opts[0].StrictRequires
+
+		if opts[0].RunID != "" {
+			if err := ValidateRunID(opts[0].RunID); err != nil {
+				return nil, err
+			}
+			r.runID = opts[0].RunID
+		}
+
+		if opts[0].AllowedTools != "" {
+			if err := opts[0].AllowedTools.Validate(); err != nil {
+				return nil, err
+			}
+			r.allowedTools = opts[0].AllowedTools
+		}
+
+		if opts[0].CatalogueAblation && opts[0].AllowedTools != "" {
+			return nil, fmt.Errorf("catalogue ablation runs tasks with every allowedTools mode, so allowedTools can't be set")
+		}
+		r.catalogueAblation = opts[0].CatalogueAblation
+		r.poolProxies = r.poolProxies || opts[0].PoolProxies
+		r.build = opts[0].Build
+		r.metadata = opts[0].Metadata
+		r.taskOutput = opts[0].TaskOutput
+		if opts[0].AdaptiveParallelism && r.adaptiveConfig == nil {
+			r.adaptiveConfig = &AdaptiveParallelismConfig{}
+		}
+		if opts[0].Sample != nil {
+			if err := opts[0].Sample.Validate(); err != nil {
+				return nil, err
+			}
+			sample := *opts[0].Sample
+			if sample.By == "" {
+				sample.By = SampleByDifficulty
+			}
+			r.sample = &sample
+		}
+		r.failedFirst = opts[0].FailedFirst
+		if opts[0].MaxFailures < 0 {
+			return nil, fmt.Errorf("max failures must not be negative, got %d", opts[0].MaxFailures)
+		}
+		r.maxFailures = opts[0].MaxFailures
+		if opts[0].Locale != "" {
+			r.locale = opts[0].Locale
+		}
+
+		if opts[0].PromptVariants < 0 {
+			return nil, fmt.Errorf("prompt variants must not be negative, got %d", opts[0].PromptVariants)
+		}
+		if opts[0].PromptVariants > 0 {
+			if r.promptVariantsConfig == nil {
+				return nil, fmt.Errorf("prompt variants need a model to write the paraphrases: set promptVariants.model in the eval config")
+			}
+			r.promptVariantsConfig.Count = opts[0].PromptVariants
+		}
+
+		if opts[0].SkipPattern != "" {
+			skipMatcher, err := regexp.Compile(opts[0].SkipPattern)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compile regexp for task name skip: %w", err)
+			}
+			r.skipMatcher = skipMatcher
+		}
+
+		for _, pattern := range opts[0].SkipPaths {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid skip path pattern %q: %w", pattern, err)
+			}
+		}
+		r.skipPaths = opts[0].SkipPaths
 	}
 
 	return r, nil
 }
 
 func (r *evalRunner) loadMcpConfig() (*mcpclient.MCPConfig, error) {
+	return loadMcpConfig(r.spec)
+}
+
+// loadMcpConfig loads the MCP config of spec, from its MCP config file or the
+// environment. It returns nil if neither configures MCP servers.
+func loadMcpConfig(spec *EvalSpec) (*mcpclient.MCPConfig, error) {
 	// Priority 1: Config file
-	if r.spec.Config.McpConfigFile != "" {
-		config, err := mcpclient.ParseConfigFile(r.spec.Config.McpConfigFile)
+	if spec.Config.McpConfigFile != "" {
+		config, err := mcpclient.ParseConfigFile(spec.Config.McpConfigFile)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load MCP config from file: %w", err)
 		}
@@ -155,6 +520,29 @@ func (r *evalRunner) loadMcpConfig() (*mcpclient.MCPConfig, error) {
 	return nil, nil
 }
 
+// newExtensionManager returns a manager of extensions, such as those of the
+// eval config. They are started when they are first used.
+func (r *evalRunner) newExtensionManager(extensions map[string]*extension.ExtensionSpec) (client.ExtensionManager, error) {
+	resolver := resolver.GetResolver(resolver.Options{
+		BasePath: r.spec.BasePath(),
+	})
+
+	extManager := client.NewManager(resolver, client.ExtensionOptions{})
+	for alias, ext := range extensions {
+		if err := extManager.Register(alias, ext); err != nil {
+			return nil, fmt.Errorf("failed to register extension %s: %w", alias, err)
+		}
+	}
+	return extManager, nil
+}
+
+// shutdownExtensions stops the extensions that extManager started.
+func shutdownExtensions(extManager client.ExtensionManager) {
+	cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	_ = extManager.ShutdownAll(cleanupCtx)
+}
+
 func (r *evalRunner) loadAgentSpec() (*agent.AgentSpec, error) {
 	if r.spec.Config.Agent == nil {
 		return nil, fmt.Errorf("agent must be specified in eval config")
@@ -163,12 +551,40 @@ func (r *evalRunner) loadAgentSpec() (*agent.AgentSpec, error) {
 	return agent.ResolveAgentRef(r.spec.Config.Agent)
 }
 
+// withSkills returns runner with the skills of the eval config mounted, if
+// any are configured.
+func withSkills(runner agent.Runner, agentSpec *agent.AgentSpec, skills *SkillsConfig) (agent.Runner, error) {
+	if skills == nil {
+		return runner, nil
+	}
+	if agentSpec.Skills == nil {
+		return nil, fmt.Errorf("eval config defines skills but agent %q has no skills configuration", agentSpec.Metadata.Name)
+	}
+
+	var sourceDirs []string
+	for _, src := range skills.Sources {
+		if src.Type == "path" {
+			sourceDirs = append(sourceDirs, src.Path)
+		}
+	}
+
+	if len(sourceDirs) > 0 {
+		runner = runner.WithSkillInfo(&agent.SkillInfo{
+			MountPath:  agentSpec.Skills.MountPath,
+			SourceDirs: sourceDirs,
+		})
+	}
+	return runner, nil
+}
+
 func (r *evalRunner) Run(ctx context.Context, taskPattern string) (*EvalOutput, error) {
 	return r.RunWithProgress(ctx, taskPattern, NoopProgressCallback)
 }
 
 func (r *evalRunner) RunWithProgress(ctx context.Context, taskPattern string, callback ProgressCallback) (*EvalOutput, error) {
-	r.progressCallback = callback
+	r.progressCallback = func(event ProgressEvent) {
+		callback(*util.RedactedCopy(&event))
+	}
 
 	if taskPattern == "" {
 		taskPattern = "." // match everything (any character matches all task names)
@@ -184,10 +600,19 @@ func (r *evalRunner) RunWithProgress(ctx context.Context, taskPattern string, ca
 		return nil, err
 	}
 
-	if mcpConfig == nil && r.spec.Config.Skills == nil {
+	// Environments configure their own MCP servers, so the eval config only
+	// needs them for the tasks that run against it
+	if mcpConfig == nil && r.spec.Config.Skills == nil && len(r.spec.Config.Environments) == 0 {
 		return nil, fmt.Errorf("at least one of MCP config or skills must be configured")
 	}
 
+	// The run ID is passed to everything the run starts, so their logs can
+	// be correlated with the run
+	defer setRunIDEnv(r.runID)()
+	addRunIDHeader(mcpConfig, r.runID)
+
+	r.deps = &steps.Dependencies{}
+
 	// Create a single shared MCP manager for the entire evaluation run.
 	// Individual tasks create their own proxy servers on top of these shared
 	// client connections for recording/isolation.
@@ -201,7 +626,10 @@ func (r *evalRunner) RunWithProgress(ctx context.Context, taskPattern string, ca
 			defer cancel()
 			_ = mcpManager.Close(closeCtx)
 		}()
-		ctx = mcpclient.ManagerToContext(ctx, mcpManager)
+		// Only the proxy servers call the shadow servers
+		if r.deps.McpClients, err = mcpproxy.NewShadowedManager(mcpManager, r.spec.Config.Shadow); err != nil {
+			return nil, err
+		}
 	}
 
 	agentSpec, err := r.loadAgentSpec()
@@ -209,67 +637,148 @@ func (r *evalRunner) RunWithProgress(ctx context.Context, taskPattern string, ca
 		return nil, fmt.Errorf("failed to load agent spec: %w", err)
 	}
 
-	runner, err := agent.NewRunnerForSpec(agentSpec)
+	// A single limiter is shared by every task so parallel runs stay within provider quotas
+	r.rateLimiter, err = llmagent.NewRateLimiter(r.spec.Config.RateLimit)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate limit config: %w", err)
+	}
+	defer func() { r.rateLimiter = nil }()
+
+	// The model requests of the run adapt the number of parallel workers
+	if r.adaptiveConfig != nil && r.parallelWorkers > 1 {
+		r.adaptive, err = newAdaptiveParallelism(r.adaptiveConfig, r.parallelWorkers)
+		if err != nil {
+			return nil, fmt.Errorf("invalid adaptive parallelism config: %w", err)
+		}
+		defer func() { r.adaptive = nil }()
+	}
+
+	runner, err := agent.NewRunnerForSpec(agentSpec, r.runnerOptions()...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create agent runner from spec: %w", err)
 	}
 
 	// Wire skills into the agent runner if configured
-	if r.spec.Config.Skills != nil && agentSpec.Skills == nil {
-		return nil, fmt.Errorf("eval config defines skills but agent %q has no skills configuration", agentSpec.Metadata.Name)
+	runner, err = withSkills(runner, agentSpec, r.spec.Config.Skills)
+	if err != nil {
+		return nil, err
 	}
-	if r.spec.Config.Skills != nil && agentSpec.Skills != nil {
+	if r.spec.Config.Skills != nil {
 		r.skillToolName = agentSpec.Skills.ToolName
-
-		var sourceDirs []string
-		for _, src := range r.spec.Config.Skills.Sources {
-			if src.Type == "path" {
-				sourceDirs = append(sourceDirs, src.Path)
-			}
-		}
-
-		if len(sourceDirs) > 0 {
-			runner = runner.WithSkillInfo(&agent.SkillInfo{
-				MountPath:  agentSpec.Skills.MountPath,
-				SourceDirs: sourceDirs,
-			})
-		}
 	}
 
-	judge, err := llmjudge.NewLLMJudge(r.spec.Config.LLMJudge)
+	judge, err := llmjudge.NewLLMJudge(r.spec.Config.LLMJudge, r.runnerOptions()...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create llm judge from spec: %w", err)
 	}
 	defer judge.Close()
 
-	resolver := resolver.GetResolver(resolver.Options{
-		BasePath: r.spec.BasePath(),
-	})
-
 	// Create a shared extension manager for all tasks
-	extManager := client.NewManager(resolver, client.ExtensionOptions{})
-	defer func() {
-		cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-		_ = extManager.ShutdownAll(cleanupCtx)
-	}()
+	extManager, err := r.newExtensionManager(r.spec.Config.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	defer shutdownExtensions(extManager)
 
-	for alias, ext := range r.spec.Config.Extensions {
-		if err := extManager.Register(alias, ext); err != nil {
-			return nil, fmt.Errorf("failed to register extension %s: %w", alias, err)
+	r.deps.Extensions = extManager
+	r.deps.Judge = judge
+
+	if cfg := r.promptVariantsConfig; cfg != nil && cfg.Count > 0 {
+		r.paraphraser, err = newParaphraser(ctx, cfg, r.modelConfig(cfg.Model, cfg.Prompt))
+		if err != nil {
+			return nil, err
 		}
+		defer func() { r.paraphraser = nil }()
 	}
 
-	ctx = client.ManagerToContext(ctx, extManager)
-	ctx = llmjudge.WithJudge(ctx, judge)
+	// Proxies are set up once model calls are rate limited, as they may call a
+	// model to summarize tool results
+	if mcpManager, ok := r.deps.McpManager(); ok || len(r.spec.Config.Environments) > 0 {
+		closeProxies, err := r.setUpProxies(ctx, mcpManager)
+		if err != nil {
+			return nil, err
+		}
+		defer closeProxies()
+	}
 
 	taskConfigs, err := r.collectTaskConfigs(taskMatcher)
 	if err != nil {
 		return nil, err
 	}
+	if mcpConfig == nil && r.spec.Config.Skills == nil {
+		for _, tc := range taskConfigs {
+			if tc.environment == "" {
+				return nil, fmt.Errorf("at least one of MCP config or skills must be configured")
+			}
+		}
+	}
+
+	taskConfigs, deprecated := partitionDeprecatedTasks(taskConfigs)
+
+	// The sample is drawn from the tasks that would run
+	var sampleSummary *SampleSummary
+	if r.sample != nil {
+		total := len(taskConfigs)
+		taskConfigs = sampleTasks(taskConfigs, r.sample)
+		sampleSummary = &SampleSummary{SampleConfig: *r.sample, Selected: len(taskConfigs), Total: total}
+	}
+
+	// In canary mode every task runs against each version of the server. The
+	// summary lists the matched tasks once, the versions are reported as the
+	// canary and its environments.
+	matched := taskConfigs
+	taskConfigs = canaryTasks(taskConfigs, r.spec.Config.Canary)
+
+	// Only the environments of the tasks that would run are set up
+	environments, closeEnvironments, err := r.setUpEnvironments(ctx, taskConfigs)
+	if err != nil {
+		return nil, err
+	}
+	defer closeEnvironments()
+
+	// Tasks that can't run with this eval config are skipped rather than failed
+	runnable, unmet := partitionUnmetRequirements(taskConfigs, r.deps, environments)
+	if r.strictRequires && len(unmet) > 0 {
+		return nil, unmetRequirementsError(unmet)
+	}
+	runnable, unlocalized := r.localizeTasks(runnable)
 
 	// Build summary from resolved configuration
-	summary := r.buildSummary(ctx, agentSpec, mcpConfig, judge, taskConfigs)
+	summary := r.buildSummary(ctx, agentSpec, mcpConfig, judge, matched)
+	summary.Evals.States = countTaskStates(matched, deprecated)
+	summary.Sample = sampleSummary
+	summary.Environments = environmentSummaries(ctx, environments)
+	summary.Canary = r.spec.Config.Canary
+
+	if r.journalFile != "" {
+		r.journal, err = CreateJournal(r.journalFile)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = r.journal.Close()
+			r.journal = nil
+		}()
+	}
+	r.writeJournal(JournalEntry{Type: JournalStart, Summary: summary})
+
+	r.debug = nil
+	if util.DebugEnabled() {
+		r.debug, err = util.NewDebugRoot(r.runID)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "Writing debug artifacts to %s\n", r.debug.Path())
+		r.debug.WriteJSON("summary.json", summary)
+	}
+
+	r.judgeAudit = nil
+	if r.judgeAuditDir != "" {
+		r.judgeAudit, err = util.NewDebugDir(r.judgeAuditDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create judge audit directory: %w", err)
+		}
+	}
 
 	r.progressCallback(ProgressEvent{
 		Type:    EventEvalStart,
@@ -277,31 +786,76 @@ func (r *evalRunner) RunWithProgress(ctx context.Context, taskPattern string, ca
 		Summary: summary,
 	})
 
-	// Group tasks by parallel support
-	groups := groupTasksByParallelSupport(taskConfigs)
+	for _, tc := range deprecated {
+		r.progressCallback(ProgressEvent{
+			Type:    EventTaskDeprecated,
+			Message: fmt.Sprintf("Skipping deprecated task: %s", tc.spec.Metadata.Name),
+			Task: &EvalResult{
+				TaskID:     tc.spec.Metadata.ID,
+				TaskName:   tc.spec.Metadata.Name,
+				TaskPath:   tc.path,
+				Difficulty: tc.spec.Metadata.Difficulty,
+				State:      task.StateDeprecated,
+			},
+		})
+	}
+
+	r.budget = newBudgetTracker(r.spec.Config.Budget)
+	r.usage = newUsageTracker(r.spec.Config.Budget)
 
 	results := make([]*EvalResult, 0, len(taskConfigs))
+	for _, u := range unmet {
+		message := "missing " + strings.Join(u.missing, ", ")
+		results = append(results, r.skipTask(u.tc, SkipReasonRequirementsUnmet, message)...)
+	}
+	for _, tc := range unlocalized {
+		message := fmt.Sprintf("no prompt for locale %q (has %s)", r.selectedLocale(), strings.Join(tc.spec.Spec.Prompt.LocaleNames(), ", "))
+		results = append(results, r.skipTask(tc, SkipReasonLocaleUnavailable, message)...)
+	}
+
+	// Tasks run with a context of their own, which is cancelled if the run is
+	// aborted after too many failures
+	taskCtx, cancelTasks := context.WithCancel(ctx)
+	defer cancelTasks()
+	r.abort = newAbortTracker(r.maxFailures, cancelTasks)
+
+	// The tasks of each environment run together, with its dependencies and
+	// variables, and are grouped by parallel support
+	for _, envTasks := range groupTasksByEnvironment(runnable) {
+		restore := r.useEnvironment(environments[envTasks.environment])
+
+		for _, group := range groupTasksByParallelSupport(envTasks.tasks, r.failedFirst) {
+			// Determine worker limit: use configured workers for parallel tasks, 1 for sequential
+			workerLimit := 1
+			if group.parallel && r.parallelWorkers > 1 {
+				workerLimit = r.parallelWorkers
+			}
 
-	for _, group := range groups {
-		// Determine worker limit: use configured workers for parallel tasks, 1 for sequential
-		workerLimit := 1
-		if group.parallel && r.parallelWorkers > 1 {
-			workerLimit = r.parallelWorkers
+			groupResults := r.runTaskGroup(taskCtx, runner, group.tasks, workerLimit)
+			results = append(results, groupResults...)
 		}
 
-		groupResults := r.runTaskGroup(ctx, runner, group.tasks, workerLimit)
-		results = append(results, groupResults...)
+		restore()
 	}
+	sortResultsByTask(results, taskConfigs)
+
+	summary.Budget = r.budget.summary()
+	summary.Abort = r.abort.summary()
+	summary.Gates = evaluateGates(r.spec.Config.Gates, results, taskLabels(taskConfigs))
+	summary.Attempts = RecordAttempts(results)
+	summary.AdaptiveParallelism = r.adaptive.summary()
+	r.writeJournal(JournalEntry{Type: JournalComplete})
 
 	r.progressCallback(ProgressEvent{
 		Type:    EventEvalComplete,
 		Message: "Evaluation complete",
 	})
 
-	return &EvalOutput{
+	output := &EvalOutput{
 		Summary: summary,
 		Results: results,
-	}, nil
+	}
+	return util.RedactedCopy(output), nil
 }
 
 func (r *evalRunner) buildSummary(
@@ -312,9 +866,21 @@ func (r *evalRunner) buildSummary(
 	taskConfigs []taskConfig,
 ) *EvalSummary {
 	summary := &EvalSummary{
+		RunID:           r.runID,
 		ParallelWorkers: r.parallelWorkers,
 		Runs:            r.runs,
 	}
+	if r.catalogueAblation {
+		summary.CatalogueAblation = true
+	} else if r.allowedTools == AllowedToolsAssertions {
+		summary.AllowedTools = r.allowedTools
+	}
+	summary.Locale = r.locale
+	summary.Build = r.build
+	summary.Metadata = r.metadata
+	if cfg := r.promptVariantsConfig; cfg != nil && cfg.Count > 0 {
+		summary.PromptVariants = &PromptVariantsSummary{Model: cfg.Model, Count: cfg.Count, Seed: cfg.Seed}
+	}
 
 	// Agent — include ref-level info plus resolved spec details
 	if r.spec.Config.Agent != nil {
@@ -358,40 +924,8 @@ func (r *evalRunner) buildSummary(
 
 	// MCP servers (sorted by name for deterministic output)
 	if mcpConfig != nil {
-		mcpManager, _ := mcpclient.ManagerFromContext(ctx)
-
-		servers := mcpConfig.GetEnabledServers()
-		names := make([]string, 0, len(servers))
-		for name := range servers {
-			names = append(names, name)
-		}
-		sort.Strings(names)
-
-		for _, name := range names {
-			server := servers[name]
-			serverType := "stdio"
-			if server.IsHttp() {
-				serverType = "http"
-			}
-			serverSummary := MCPServerSummary{
-				Name:    name,
-				Type:    serverType,
-				URL:     sanitizeURL(server.URL),
-				Command: server.Command,
-			}
-			if mcpManager != nil {
-				if c, ok := mcpManager.Get(name); ok {
-					for _, tool := range c.GetAllowedTools(ctx) {
-						serverSummary.Tools = append(serverSummary.Tools, ToolSummary{
-							Name:        tool.Name,
-							Description: tool.Description,
-							InputSchema: tool.InputSchema,
-						})
-					}
-				}
-			}
-			summary.MCPServers = append(summary.MCPServers, serverSummary)
-		}
+		mcpManager, _ := r.deps.McpManager()
+		summary.MCPServers = mcpServerSummaries(ctx, mcpConfig, mcpManager)
 	}
 
 	// Skills
@@ -410,7 +944,11 @@ func (r *evalRunner) buildSummary(
 		taskSetSummaries = append(taskSetSummaries, TaskSetSummary{
 			Glob:          ts.Glob,
 			Path:          ts.Path,
+			Recursive:     ts.Recursive,
+			Exclude:       ts.Exclude,
 			LabelSelector: ts.LabelSelector,
+			Environment:   ts.Environment,
+			Repeat:        ts.Repeat,
 		})
 	}
 
@@ -430,6 +968,7 @@ func (r *evalRunner) buildSummary(
 		DefaultTask:    r.defaultTaskTimeout,
 		Cleanup:        r.cleanupTimeout,
 		DefaultCleanup: r.defaultCleanupTimeout,
+		Stall:          r.stallTimeout,
 	}
 	if r.spec.Config.DefaultTaskLimits != nil {
 		if timeout.DefaultTask == "" && r.spec.Config.DefaultTaskLimits.Timeout != "" {
@@ -438,33 +977,76 @@ func (r *evalRunner) buildSummary(
 		if timeout.DefaultCleanup == "" && r.spec.Config.DefaultTaskLimits.CleanupTimeout != "" {
 			timeout.DefaultCleanup = r.spec.Config.DefaultTaskLimits.CleanupTimeout
 		}
+		timeout.DefaultStall = r.spec.Config.DefaultTaskLimits.StallTimeout
 	}
-	if timeout.Task != "" || timeout.DefaultTask != "" || timeout.Cleanup != "" || timeout.DefaultCleanup != "" {
+	if *timeout != (TimeoutSummary{}) {
 		summary.Timeout = timeout
 	}
 
 	return summary
 }
 
+// mcpServerSummaries describes the enabled MCP servers of mcpConfig, sorted by
+// name, with the tools that mcpManager allows if it is connected to them.
+func mcpServerSummaries(ctx context.Context, mcpConfig *mcpclient.MCPConfig, mcpManager mcpclient.Manager) []MCPServerSummary {
+	servers := mcpConfig.GetEnabledServers()
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var summaries []MCPServerSummary
+	for _, name := range names {
+		server := servers[name]
+		serverType := "stdio"
+		if server.IsHttp() {
+			serverType = "http"
+		}
+		serverSummary := MCPServerSummary{
+			Name:    name,
+			Type:    serverType,
+			URL:     sanitizeURL(server.URL),
+			Command: server.Command,
+		}
+		if mcpManager != nil {
+			if c, ok := mcpManager.Get(name); ok {
+				for _, tool := range c.GetAllowedTools(ctx) {
+					serverSummary.Tools = append(serverSummary.Tools, ToolSummary{
+						Name:        tool.Name,
+						Description: tool.Description,
+						InputSchema: tool.InputSchema,
+					})
+				}
+			}
+		}
+		summaries = append(summaries, serverSummary)
+	}
+	return summaries
+}
+
 func (r *evalRunner) collectTaskConfigs(rx *regexp.Regexp) ([]taskConfig, error) {
 	taskConfigs := make([]taskConfig, 0)
-	seen := make(map[string]int) // maps canonical path to index in taskConfigs for merging assertions
+	seen := make(map[string]int)    // maps canonical path (and instance name) to index in taskConfigs for merging assertions
+	keys := make(map[string]string) // maps task key (ID or name) to the path of the task that has it
 
-	for _, ts := range r.spec.Config.TaskSets {
-		var paths []string
-		var err error
+	libraries, err := task.LoadLibraries(r.spec.Config.StepLibraries)
+	if err != nil {
+		return nil, err
+	}
 
-		if ts.Glob != "" {
-			paths, err = filepath.Glob(ts.Glob)
-			if err != nil {
-				return nil, fmt.Errorf("failed to glob %s: %w", ts.Glob, err)
-			}
-		} else if ts.Path != "" {
-			paths = []string{ts.Path}
+	for _, ts := range r.spec.Config.TaskSets {
+		paths, err := ts.matchPaths()
+		if err != nil {
+			return nil, err
 		}
 
 		for _, path := range paths {
-			taskSpec, err := task.FromFile(path)
+			if matchesPathOrParent(path, r.skipPaths) {
+				continue
+			}
+
+			template, err := task.FromFile(path)
 			if err != nil {
 				// Skip files that are not tasks (e.g., eval.yaml files in the same directory)
 				if errors.Is(err, util.ErrWrongKind) {
@@ -472,14 +1054,14 @@ func (r *evalRunner) collectTaskConfigs(rx *regexp.Regexp) ([]taskConfig, error)
 				}
 				return nil, fmt.Errorf("failed to load task at path %s: %w", path, err)
 			}
-
-			if !rx.MatchString(taskSpec.Metadata.Name) {
-				continue
+			if warning := template.DeprecationWarning(); warning != "" {
+				r.progressCallback(ProgressEvent{Type: EventTaskWarning, Message: warning})
 			}
 
-			// Filter by label selector if specified
-			if !matchesLabelSelector(taskSpec.Metadata.Labels, ts.LabelSelector) {
-				continue
+			// Task templates expand into a task per combination of their parameters
+			instances, err := template.Instances()
+			if err != nil {
+				return nil, fmt.Errorf("failed to load task at path %s: %w", path, err)
 			}
 
 			// Canonicalize path for deduplication (resolves ./foo vs foo, symlinks, etc.)
@@ -494,30 +1076,127 @@ func (r *evalRunner) collectTaskConfigs(rx *regexp.Regexp) ([]taskConfig, error)
 			// Keep display path clean but relative (avoids leaking machine-specific paths in results)
 			displayPath := filepath.Clean(path)
 
-			// If task already exists, append assertions to evaluate independently
-			if idx, exists := seen[canonicalPath]; exists {
-				if ts.Assertions != nil {
-					taskConfigs[idx].assertions = append(taskConfigs[idx].assertions, ts.Assertions)
+			for _, taskSpec := range instances {
+				if !rx.MatchString(taskSpec.Metadata.Name) {
+					continue
+				}
+				if r.skipMatcher != nil && r.skipMatcher.MatchString(taskSpec.Metadata.Name) {
+					continue
+				}
+
+				// Filter by label selector if specified
+				if !ts.LabelSelector.Matches(taskSpec.Metadata.Labels) {
+					continue
+				}
+
+				if err := taskSpec.ExpandSteps(libraries); err != nil {
+					return nil, fmt.Errorf("failed to expand steps of task at path %s: %w", path, err)
+				}
+
+				// Instances of a task template share its path, and a task runs
+				// once in each environment
+				seenKey := canonicalPath
+				if taskSpec.Parameters() != nil {
+					seenKey += "\x00" + taskSpec.Metadata.Name
+				}
+				seenKey += "\x00" + ts.Environment
+
+				// If task already exists, append assertions to evaluate independently,
+				// and run it as often as the task set that repeats it most
+				if idx, exists := seen[seenKey]; exists {
+					if ts.Assertions != nil {
+						taskConfigs[idx].assertions = append(taskConfigs[idx].assertions, ts.Assertions)
+					}
+					taskConfigs[idx].repeat = max(taskConfigs[idx].repeat, ts.Repeat)
+					continue
+				}
+
+				if err := r.checkTaskKey(keys, taskSpec, ts.Environment, displayPath); err != nil {
+					return nil, err
 				}
-				continue
-			}
 
-			seen[canonicalPath] = len(taskConfigs)
-			var assertions []*TaskAssertions
-			if ts.Assertions != nil {
-				assertions = []*TaskAssertions{ts.Assertions}
+				seen[seenKey] = len(taskConfigs)
+				var assertions []*TaskAssertions
+				if ts.Assertions != nil {
+					assertions = []*TaskAssertions{ts.Assertions}
+				}
+				taskConfigs = append(taskConfigs, taskConfig{
+					path:        displayPath,
+					spec:        taskSpec,
+					assertions:  assertions,
+					environment: ts.Environment,
+					repeat:      ts.Repeat,
+				})
 			}
-			taskConfigs = append(taskConfigs, taskConfig{
-				path:       displayPath,
-				spec:       taskSpec,
-				assertions: assertions,
-			})
 		}
 	}
 
 	return taskConfigs, nil
 }
 
+// checkTaskKey records the key of a task and checks that it is unique within
+// its environment. Tasks with the same ID are an error, since results are keyed
+// by ID. Tasks without an ID that share a name only produce a warning, to keep
+// existing evals working.
+func (r *evalRunner) checkTaskKey(keys map[string]string, spec *task.TaskConfig, environment, path string) error {
+	key := spec.Metadata.Key()
+	other, exists := keys[key+"\x00"+environment]
+	if !exists {
+		keys[key+"\x00"+environment] = path
+		return nil
+	}
+
+	if spec.Metadata.ID != "" {
+		return fmt.Errorf("duplicate task id %q: %s and %s", key, other, path)
+	}
+	r.progressCallback(ProgressEvent{
+		Type:    EventTaskWarning,
+		Message: fmt.Sprintf("tasks %s and %s are both keyed %q; set unique names or ids so their results can be told apart", other, path, key),
+	})
+	return nil
+}
+
+// partitionDeprecatedTasks splits out tasks in the deprecated state, which are
+// skipped rather than run. The relative order of the remaining tasks is preserved.
+func partitionDeprecatedTasks(tasks []taskConfig) ([]taskConfig, []taskConfig) {
+	runnable := make([]taskConfig, 0, len(tasks))
+	var deprecated []taskConfig
+
+	for _, tc := range tasks {
+		if tc.spec.Metadata.GetState() == task.StateDeprecated {
+			deprecated = append(deprecated, tc)
+			continue
+		}
+		runnable = append(runnable, tc)
+	}
+
+	return runnable, deprecated
+}
+
+// countTaskStates returns the number of matched tasks in each lifecycle state.
+func countTaskStates(runnable, deprecated []taskConfig) map[string]int {
+	counts := make(map[string]int)
+	for _, tc := range runnable {
+		counts[tc.spec.Metadata.GetState()]++
+	}
+	if len(deprecated) > 0 {
+		counts[task.StateDeprecated] = len(deprecated)
+	}
+
+	return counts
+}
+
+// resultState returns the state recorded on an EvalResult for a task.
+// Active tasks are left empty to keep result files unchanged for the common case.
+func resultState(tc taskConfig) string {
+	state := tc.spec.Metadata.GetState()
+	if state == task.StateActive {
+		return ""
+	}
+
+	return state
+}
+
 // taskGroup represents a batch of tasks to run together
 type taskGroup struct {
 	tasks    []taskConfig
@@ -525,8 +1204,9 @@ type taskGroup struct {
 }
 
 // groupTasksByParallelSupport separates tasks into sequential and parallel groups.
-// Sequential tasks run first (in order), then all parallel tasks run together as one batch.
-func groupTasksByParallelSupport(tasks []taskConfig) []taskGroup {
+// Sequential tasks run first (in order), then all parallel tasks run together as one batch,
+// ordered by scheduleParallelTasks.
+func groupTasksByParallelSupport(tasks []taskConfig, failedFirst map[string]bool) []taskGroup {
 	if len(tasks) == 0 {
 		return nil
 	}
@@ -555,7 +1235,7 @@ func groupTasksByParallelSupport(tasks []taskConfig) []taskGroup {
 	// Add all parallel tasks as one group
 	if len(parallel) > 0 {
 		groups = append(groups, taskGroup{
-			tasks:    parallel,
+			tasks:    scheduleParallelTasks(parallel, failedFirst),
 			parallel: true,
 		})
 	}
@@ -563,6 +1243,21 @@ func groupTasksByParallelSupport(tasks []taskConfig) []taskGroup {
 	return groups
 }
 
+// scheduleParallelTasks orders parallel tasks so that gating signals arrive
+// sooner: tasks that failed in an earlier run first, then tasks by descending
+// priority, keeping the order of the tasks otherwise.
+func scheduleParallelTasks(tasks []taskConfig, failedFirst map[string]bool) []taskConfig {
+	scheduled := append([]taskConfig(nil), tasks...)
+	sort.SliceStable(scheduled, func(i, j int) bool {
+		a, b := scheduled[i], scheduled[j]
+		if aFailed, bFailed := failedFirst[a.key()], failedFirst[b.key()]; aFailed != bFailed {
+			return aFailed
+		}
+		return a.spec.Metadata.Priority > b.spec.Metadata.Priority
+	})
+	return scheduled
+}
+
 // runTaskGroup runs a group of tasks with the specified worker limit.
 // Both MCP client connections and extension manager are shared via context;
 // per-task proxy servers handle call recording and isolation.
@@ -578,16 +1273,38 @@ func (r *evalRunner) runTaskGroup(
 	var wg sync.WaitGroup
 	sem := make(chan struct{}, workerLimit)
 
+	// The number of workers of parallel groups may be adapted
+	adaptive := r.adaptive
+	if workerLimit > 1 && adaptive != nil {
+		adaptive.start()
+	} else {
+		adaptive = nil
+	}
+
 	for _, tc := range tasks {
+		// The semaphore is acquired before the task's goroutine starts, so
+		// that tasks start in their scheduled order
+		if adaptive != nil {
+			adaptive.acquire()
+		} else {
+			sem <- struct{}{}
+		}
+
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-
-			// Acquire semaphore
-			sem <- struct{}{}
-			defer func() { <-sem }()
+			if adaptive != nil {
+				defer adaptive.release()
+			} else {
+				defer func() { <-sem }()
+			}
 
 			taskResults := r.executeTask(ctx, agentRunner, tc)
+			if adaptive != nil {
+				for _, result := range taskResults {
+					adaptive.observeResult(result)
+				}
+			}
 
 			mu.Lock()
 			allResults = append(allResults, taskResults...)
@@ -601,11 +1318,14 @@ func (r *evalRunner) runTaskGroup(
 }
 
 // getRunsForTask determines the number of runs for a specific task.
-// Priority: CLI --runs (if explicitly set) > task metadata runs > default (1)
+// Priority: CLI --runs (if explicitly set) > task set repeat > task metadata runs > default (1)
 func (r *evalRunner) getRunsForTask(tc taskConfig) int {
 	if r.runsExplicitlySet {
 		return r.runs
 	}
+	if tc.repeat > 0 {
+		return tc.repeat
+	}
 	if tc.spec.Metadata.Runs > 0 {
 		return tc.spec.Metadata.Runs
 	}
@@ -659,6 +1379,35 @@ func (r *evalRunner) resolveTaskTimeout(tc taskConfig) (time.Duration, bool, err
 	return 0, false, nil
 }
 
+// resolveStallTimeout determines the stall timeout for a specific task.
+// Precedence (highest to lowest):
+//  1. CLI --stall-timeout (hard override)
+//  2. task spec.limits.stallTimeout (per-task)
+//  3. eval config.defaultTaskLimits.stallTimeout
+//  4. no stall detection (returns 0, nil)
+func (r *evalRunner) resolveStallTimeout(tc taskConfig) (time.Duration, error) {
+	if r.stallTimeout != "" {
+		d, err := time.ParseDuration(r.stallTimeout)
+		if err != nil || d <= 0 {
+			return 0, fmt.Errorf("invalid --stall-timeout %q: must be a positive duration", r.stallTimeout)
+		}
+		return d, nil
+	}
+
+	if tc.spec.Spec != nil && tc.spec.Spec.Limits != nil {
+		d, ok, err := tc.spec.Spec.Limits.GetStallTimeout()
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return d, nil
+		}
+	}
+
+	d, _, err := r.spec.Config.DefaultTaskLimits.GetStallTimeout()
+	return d, err
+}
+
 // resolveCleanupTimeout determines the effective cleanup timeout for a specific task.
 // Follows the same precedence pattern as resolveTaskTimeout.
 func (r *evalRunner) resolveCleanupTimeout(tc taskConfig) (time.Duration, bool, error) {
@@ -708,19 +1457,105 @@ func (r *evalRunner) executeTask(
 	agentRunner agent.Runner,
 	tc taskConfig,
 ) []*EvalResult {
+	// Prompts aren't paraphrased for tasks that won't run
+	variants := r.catalogueVariants(tc)
+	if !r.abort.isAborted() {
+		variants = r.promptVariants(ctx, variants)
+	}
 	runs := r.getRunsForTask(tc)
-	results := make([]*EvalResult, 0, runs)
-
-	for runIdx := 0; runIdx < runs; runIdx++ {
-		result := r.executeSingleRun(ctx, agentRunner, tc)
-		result.RunIndex = runIdx
-		result.TotalRuns = runs
-		results = append(results, result)
+	results := make([]*EvalResult, 0, runs*len(variants))
+
+	// Variants run one after the other, like runs, since they share the
+	// resources of the task
+	for _, variant := range variants {
+		for runIdx := 0; runIdx < runs; runIdx++ {
+			var result *EvalResult
+			var debug *util.DebugDir
+			var auditLog *llmjudge.AuditLog
+			debugName := fmt.Sprintf("%s-run%d", variant.spec.Metadata.Key(), runIdx)
+			if variant.allowedTools != "" {
+				debugName += "-" + string(variant.allowedTools)
+			}
+			if variant.promptVariant > 0 {
+				debugName += fmt.Sprintf("-prompt%d", variant.promptVariant)
+			}
+			if variant.environment != "" {
+				debugName += "-" + variant.environment
+			}
+			if r.abort.isAborted() {
+				result = r.abort.skip(variant)
+				r.progressCallback(ProgressEvent{
+					Type:    EventTaskSkipped,
+					Message: fmt.Sprintf("Skipping task: %s (%s)", variant.spec.Metadata.Name, result.SkipMessage),
+					Task:    result,
+				})
+			} else if r.budget.exceeded() {
+				result = r.budget.skip(variant)
+				r.progressCallback(ProgressEvent{
+					Type:    EventTaskSkippedOverBudget,
+					Message: fmt.Sprintf("Skipping task over budget: %s", variant.spec.Metadata.Name),
+					Task:    result,
+				})
+			} else {
+				debug = r.debug.NewSub(debugName)
+				runCtx := util.WithDebugDir(ctx, debug)
+				if r.judgeAudit != nil {
+					auditLog = llmjudge.NewAuditLog()
+				}
+				run := variant
+				run.runIndex = runIdx
+				run.auditLog = auditLog
+				result = r.executeSingleRun(runCtx, agentRunner, run)
+				result.DebugDir = debug.Path()
+				r.budget.record(result)
+
+				// Runs that failed because the abort cancelled them say
+				// nothing about the task
+				if !result.TaskPassed && ctx.Err() != nil && r.abort.isAborted() {
+					result = r.abort.cancelled(variant)
+					result.DebugDir = debug.Path()
+				} else {
+					r.abort.record(result)
+				}
+			}
+			result.RunIndex = runIdx
+			result.TotalRuns = runs
+			result.AllowedToolsMode = variant.allowedTools
+			result.PromptVariant = variant.promptVariant
+			result.Environment = variant.environment
+			result.PromptParaphrase = variant.promptParaphrase
+			if auditLog != nil {
+				result.JudgeAuditFile = writeJudgeAudit(r.judgeAudit, debugName, result, auditLog)
+			}
+			result = util.RedactedCopy(result)
+			debug.WriteJSON("result.json", result)
+			r.spec.Config.Output.truncate(result)
+			r.writeJournal(JournalEntry{Type: JournalResult, Result: result})
+			results = append(results, result)
+		}
 	}
 
 	return results
 }
 
+// writeJournal appends entry to the run journal and sends it to the results
+// sink, if any. Their errors are reported as warnings but don't fail the run,
+// since the final results file is still written.
+func (r *evalRunner) writeJournal(entry JournalEntry) {
+	entry.RunID = r.runID
+	if err := r.journal.Write(entry); err != nil {
+		r.progressCallback(ProgressEvent{Type: EventWarning, Message: err.Error()})
+	}
+	if r.resultsSink != nil {
+		if entry.Time.IsZero() {
+			entry.Time = time.Now()
+		}
+		if err := r.resultsSink.Write(entry); err != nil {
+			r.progressCallback(ProgressEvent{Type: EventWarning, Message: fmt.Sprintf("results sink: %v", err)})
+		}
+	}
+}
+
 // executeSingleRun runs a single task execution.
 // MCP client connections and extension manager are shared via context;
 // per-task proxy servers handle call recording and isolation.
@@ -730,17 +1565,29 @@ func (r *evalRunner) executeSingleRun(
 	agentRunner agent.Runner,
 	tc taskConfig,
 ) *EvalResult {
+	start := time.Now()
 	result, err := r.runTask(ctx, agentRunner, tc)
 	if err != nil && result == nil {
-		return &EvalResult{
-			TaskName:   tc.spec.Metadata.Name,
-			TaskPath:   tc.path,
-			Difficulty: tc.spec.Metadata.Difficulty,
-			Parallel:   tc.spec.Metadata.Parallel,
-			TaskPassed: false,
-			TaskError:  err.Error(),
+		result = &EvalResult{
+			TaskID:      tc.spec.Metadata.ID,
+			TaskName:    tc.spec.Metadata.Name,
+			TaskPath:    tc.path,
+			Difficulty:  tc.spec.Metadata.Difficulty,
+			State:       resultState(tc),
+			Parallel:    tc.spec.Metadata.Parallel,
+			TaskPassed:  false,
+			TaskError:   err.Error(),
+			ErrorKind:   task.ErrorKindOf(err),
+			Locale:      tc.locale,
+			Environment: tc.environment,
+			Labels:      tc.spec.Metadata.Labels,
+			Parameters:  tc.spec.Parameters(),
+
+			ExpectFailure:       tc.spec.Metadata.ExpectFailure,
+			ExpectFailureReason: tc.spec.Metadata.ExpectFailureReason,
 		}
 	}
+	result.DurationMs = time.Since(start).Milliseconds()
 
 	return result
 }
@@ -749,19 +1596,45 @@ func (r *evalRunner) runTask(
 	ctx context.Context,
 	agentRunner agent.Runner,
 	tc taskConfig,
-) (*EvalResult, error) {
+) (res *EvalResult, err error) {
 	result := &EvalResult{
-		TaskName:   tc.spec.Metadata.Name,
-		TaskPath:   tc.path,
-		Difficulty: tc.spec.Metadata.Difficulty,
-		Parallel:   tc.spec.Metadata.Parallel,
-	}
+		TaskID:           tc.spec.Metadata.ID,
+		TaskName:         tc.spec.Metadata.Name,
+		TaskPath:         tc.path,
+		Difficulty:       tc.spec.Metadata.Difficulty,
+		State:            resultState(tc),
+		Parallel:         tc.spec.Metadata.Parallel,
+		AllowedToolsMode: tc.allowedTools,
+		PromptVariant:    tc.promptVariant,
+		PromptParaphrase: tc.promptParaphrase,
+		Locale:           tc.locale,
+		Labels:           tc.spec.Metadata.Labels,
+		Parameters:       tc.spec.Parameters(),
+		RunIndex:         tc.runIndex,
+		Environment:      tc.environment,
+
+		ExpectFailure:       tc.spec.Metadata.ExpectFailure,
+		ExpectFailureReason: tc.spec.Metadata.ExpectFailureReason,
+	}
+	if r.taskOutput != nil {
+		ctx = util.WithOutput(ctx, r.taskOutput(result))
+	}
+
+	// Panics of steps and agents fail their phase. Any other panic fails the
+	// run instead of crashing mcpchecker, once the deferred cleanup has run.
+	defer func() {
+		if v := recover(); v != nil {
+			r.recordPanic(result, v)
+			res, err = result, nil
+		}
+	}()
 
 	// Resolve timeouts
 	taskTimeout, hasTaskTimeout, err := r.resolveTaskTimeout(tc)
 	if err != nil {
 		result.TaskPassed = false
 		result.TaskError = err.Error()
+		result.ErrorKind = task.ErrorKindSetup
 		return result, nil
 	}
 
@@ -769,6 +1642,15 @@ func (r *evalRunner) runTask(
 	if err != nil {
 		result.TaskPassed = false
 		result.TaskError = err.Error()
+		result.ErrorKind = task.ErrorKindSetup
+		return result, nil
+	}
+
+	stallTimeout, err := r.resolveStallTimeout(tc)
+	if err != nil {
+		result.TaskPassed = false
+		result.TaskError = err.Error()
+		result.ErrorKind = task.ErrorKindSetup
 		return result, nil
 	}
 
@@ -793,12 +1675,37 @@ func (r *evalRunner) runTask(
 	}
 
 	taskRunner, manager, cleanup, err := r.setupTaskResources(taskCtx, tc, result)
+	if cleanup != nil {
+		// Journal the setup, so that its cleanup can be replayed with
+		// `mcpchecker cleanup` if mcpchecker crashes before the result is
+		// journaled
+		r.writeJournal(JournalEntry{Type: JournalSetup, Result: setupRecord(result)})
+
+		// Defer cleanup with its own timeout context, independent of task timeout.
+		// WithoutCancel detaches it from the parent's deadline/cancellation.
+		defer func() {
+			cleanupBase := context.WithoutCancel(ctx)
+
+			var cleanupCtx context.Context
+			var cleanupCancel context.CancelFunc
+			if hasCleanupTimeout {
+				cleanupCtx, cleanupCancel = context.WithTimeout(cleanupBase, cleanupTimeout)
+			} else {
+				cleanupCtx = cleanupBase
+				cleanupCancel = func() {}
+			}
+			defer cleanupCancel()
+			cleanup(cleanupCtx)
+		}()
+	}
 	if err != nil {
 		result.TaskPassed = false
+		result.PanicStack = task.PanicStack(err)
 		// Check if the error was caused by timeout
 		if hasTaskTimeout && taskCtx.Err() == context.DeadlineExceeded {
 			result.TimedOut = true
 			result.TaskError = fmt.Sprintf("task exceeded timeout of %s during setup", taskTimeout)
+			result.ErrorKind = task.ErrorKindSetup
 			r.progressCallback(ProgressEvent{
 				Type:    EventTaskTimeout,
 				Message: fmt.Sprintf("Task %s timed out after %s", tc.spec.Metadata.Name, taskTimeout),
@@ -806,6 +1713,7 @@ func (r *evalRunner) runTask(
 			})
 		} else {
 			result.TaskError = err.Error()
+			result.ErrorKind = errorKind(err, task.ErrorKindSetup)
 			r.progressCallback(ProgressEvent{
 				Type:    EventTaskError,
 				Message: fmt.Sprintf("Task setup failed: %s", tc.spec.Metadata.Name),
@@ -815,31 +1723,24 @@ func (r *evalRunner) runTask(
 		return result, nil
 	}
 
-	// Defer cleanup with its own timeout context, independent of task timeout.
-	// WithoutCancel preserves all context values (managers, judges, etc.)
-	// while detaching from the parent's deadline/cancellation.
-	defer func() {
-		cleanupBase := context.WithoutCancel(ctx)
-
-		var cleanupCtx context.Context
-		var cleanupCancel context.CancelFunc
-		if hasCleanupTimeout {
-			cleanupCtx, cleanupCancel = context.WithTimeout(cleanupBase, cleanupTimeout)
-		} else {
-			cleanupCtx = cleanupBase
-			cleanupCancel = func() {}
-		}
-		defer cleanupCancel()
-		cleanup(cleanupCtx)
-	}()
+	if r.restrictedTools(tc) != nil {
+		result.AllowedTools = allowedToolNames(taskCtx, manager)
+	}
 
-	r.executeTaskSteps(taskCtx, taskRunner, agentRunner, manager, result)
+	r.executeTaskSteps(taskCtx, taskRunner, agentRunner, manager, stallTimeout, result)
 
 	// Check if executeTaskSteps was terminated by timeout
 	if hasTaskTimeout && taskCtx.Err() == context.DeadlineExceeded && !result.TimedOut {
 		result.TimedOut = true
 		result.TaskPassed = false
 		result.TaskError = fmt.Sprintf("task exceeded timeout of %s", taskTimeout)
+		if result.ErrorKind == "" {
+			// The agent or the verification steps were interrupted
+			result.ErrorKind = task.ErrorKindAgent
+			if result.VerifyOutput != nil {
+				result.ErrorKind = task.ErrorKindVerify
+			}
+		}
 		r.progressCallback(ProgressEvent{
 			Type:    EventTaskTimeout,
 			Message: fmt.Sprintf("Task %s timed out after %s", tc.spec.Metadata.Name, taskTimeout),
@@ -854,9 +1755,10 @@ func (r *evalRunner) runTask(
 		Task:    result,
 	})
 
-	r.evaluateTaskAssertions(tc, manager, result)
+	r.evaluateTaskAssertions(ctx, tc, manager, result)
 
 	result.CallHistory = manager.GetAllCallHistory()
+	writeProxyTraffic(util.DebugDirFromContext(ctx), manager)
 
 	// Compute per-call token counts on CallHistory records
 	callHistoryErr := mcpproxy.ComputeCallHistoryTokens(result.CallHistory)
@@ -891,47 +1793,183 @@ func (r *evalRunner) runTask(
 		Type:    EventTaskComplete,
 		Message: fmt.Sprintf("Completed task: %s (passed: %v)", tc.spec.Metadata.Name, result.TaskPassed),
 		Task:    result,
+		Usage:   r.usage.record(result),
 	})
 
 	return result, nil
 }
 
+// runnerOptions returns the options of the agent runners of the run, which
+// share its rate limiter, retries and health observer.
+func (r *evalRunner) runnerOptions() []agent.RunnerOption {
+	opts := []agent.RunnerOption{
+		agent.WithRateLimiter(r.rateLimiter),
+		agent.WithModelRetry(r.spec.Config.ModelRetry),
+	}
+	if r.adaptive != nil {
+		opts = append(opts, agent.WithHealthObserver(r.adaptive))
+	}
+	return opts
+}
+
+// modelConfig returns the config of a model the run calls besides its agents,
+// with the rate limiter, retries and health observer of the run.
+func (r *evalRunner) modelConfig(model, systemPrompt string) llmagent.Config {
+	cfg := llmagent.Config{
+		Model:        model,
+		SystemPrompt: systemPrompt,
+		RateLimiter:  r.rateLimiter,
+		Retry:        r.spec.Config.ModelRetry,
+	}
+	if r.adaptive != nil {
+		cfg.HealthObserver = r.adaptive
+	}
+	return cfg
+}
+
+// setUpProxies prepares the options of the MCP proxy servers of the run, and
+// starts the shared proxy servers if they are pooled. The returned function
+// stops them.
+// The options are shared by the proxy servers of every environment, so that
+// they all pass the run ID to agents.
+func (r *evalRunner) setUpProxies(ctx context.Context, mcpManager mcpclient.Manager) (func(), error) {
+	listener, err := mcpproxy.NewListener(r.spec.Config.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up mcp proxy listener: %w", err)
+	}
+	r.proxyOptions = []mcpproxy.ServerOption{mcpproxy.WithListener(listener)}
+	if r.runID != "" {
+		r.proxyOptions = append(r.proxyOptions, mcpproxy.WithHeaders(map[string]string{RunIDHeader: r.runID}))
+	}
+	cleanup := func() {
+		_ = listener.Close()
+		r.proxyOptions = nil
+	}
+
+	if cfg := r.spec.Config.SummarizeToolResults; cfg != nil {
+		summarizer, err := llmagent.NewSummarizer(ctx, r.modelConfig(cfg.Model, cfg.Prompt))
+		if err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to create tool result summarizer: %w", err)
+		}
+		r.proxyOptions = append(r.proxyOptions, mcpproxy.WithSummarizer(summarizer, cfg.ThresholdTokens))
+	}
+
+	if !r.poolProxies || mcpManager == nil {
+		return cleanup, nil
+	}
+
+	pool, err := r.startProxyPool(ctx, mcpManager)
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+	r.proxyPool = pool
+
+	return func() {
+		_ = pool.Close()
+		r.proxyPool = nil
+		cleanup()
+	}, nil
+}
+
+// startProxyPool starts the proxy servers that tasks share, instead of
+// starting their own, for the MCP servers of mcpManager.
+func (r *evalRunner) startProxyPool(ctx context.Context, mcpManager mcpclient.Manager) (*mcpproxy.ServerPool, error) {
+	pool, err := mcpproxy.NewServerPool(ctx, mcpManager, r.proxyOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mcp proxy server pool: %w", err)
+	}
+	if err := pool.Start(ctx); err != nil {
+		_ = pool.Close()
+		return nil, fmt.Errorf("failed to start mcp proxy server pool: %w", err)
+	}
+	return pool, nil
+}
+
+// writeProxyTraffic records the calls that went through each MCP proxy server
+// of a task in the proxy directory of debug.
+func writeProxyTraffic(debug *util.DebugDir, manager mcpproxy.ServerManager) {
+	if debug == nil {
+		return
+	}
+	proxyDebug := debug.Sub("proxy")
+	for _, server := range manager.GetMcpServers() {
+		if history, ok := manager.GetCallHistoryForServer(server.GetName()); ok {
+			proxyDebug.WriteJSON(server.GetName()+".json", history)
+		}
+	}
+}
+
+// runDeps returns the dependencies of the steps of a task run: the shared
+// dependencies of the run, with the audit log of the run if it has one.
+func (r *evalRunner) runDeps(tc taskConfig) *steps.Dependencies {
+	if tc.auditLog == nil {
+		return r.deps
+	}
+	deps := *r.deps
+	deps.JudgeAudit = tc.auditLog
+	return &deps
+}
+
 func (r *evalRunner) setupTaskResources(
 	ctx context.Context,
 	tc taskConfig,
 	result *EvalResult,
 ) (task.TaskRunner, mcpproxy.ServerManager, func(context.Context), error) {
-	taskRunner, err := task.NewTaskRunner(ctx, tc.spec)
+	taskRunner, err := task.NewTaskRunner(ctx, tc.spec, r.runDeps(tc))
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to create task runner for task '%s': %w", tc.spec.Metadata.Name, err)
+		return nil, nil, nil, &task.SetupError{Err: fmt.Errorf("failed to create task runner for task '%s': %w", tc.spec.Metadata.Name, err)}
 	}
 
 	var manager mcpproxy.ServerManager
-	mcpManager, ok := mcpclient.ManagerFromContext(ctx)
+	mcpManager, ok := r.deps.McpManager()
 	if ok {
-		manager, err = mcpproxy.NewServerManager(ctx, mcpManager)
-		if err != nil {
-			return nil, nil, nil, fmt.Errorf("failed to create mcp proxy server manager: %w", err)
+		// The proxy servers of a run restricted to the tools of its assertions
+		// only expose those tools, so they can't be shared through the pool
+		restricted := r.restrictedTools(tc)
+		// The MCP servers see the time of the task's clock, like its steps
+		clock := taskRunner.Clock()
+		if r.proxyPool != nil && restricted == nil {
+			manager = r.proxyPool.Acquire(clock)
+		} else {
+			opts := append(slices.Clip(r.proxyOptions), mcpproxy.WithClock(clock))
+			if restricted != nil {
+				opts = append(opts, mcpproxy.WithToolFilter(allowedToolsFilter(restricted)))
+			}
+			manager, err = mcpproxy.NewServerManager(ctx, mcpManager, opts...)
+			if err != nil {
+				return nil, nil, nil, &task.InfraError{Err: fmt.Errorf("failed to create mcp proxy server manager: %w", err)}
+			}
 		}
 
 		if err := manager.Start(ctx); err != nil {
-			return nil, nil, nil, fmt.Errorf("failed to start mcp proxy servers: %w", err)
+			return nil, nil, nil, &task.InfraError{Err: fmt.Errorf("failed to start mcp proxy servers: %w", err)}
 		}
 	} else {
 		manager = mcpproxy.NewEmptyServerManager()
 	}
 
-	setupOutput, err := taskRunner.Setup(ctx)
-	result.SetupOutput = setupOutput
-	if err != nil {
-		manager.Close()
-		return nil, nil, nil, fmt.Errorf("failed to setup task: %w", err)
-	}
-
 	cleanup := func(cleanupCtx context.Context) {
+		defer manager.Close()
 		cleanupOutput, _ := taskRunner.Cleanup(cleanupCtx)
 		result.CleanupOutput = cleanupOutput
+		if r.verifyCleanup {
+			r.checkCleanup(cleanupCtx, taskRunner, result)
+		}
+	}
+
+	setupOutput, err := taskRunner.Setup(ctx)
+	result.SetupOutput = setupOutput
+	if err != nil {
+		err = fmt.Errorf("failed to setup task: %w", err)
+		if task.PanicStack(err) != "" {
+			// The steps before the panicking one may have created resources
+			// that cleanup removes
+			return nil, nil, cleanup, err
+		}
 		manager.Close()
+		return nil, nil, nil, err
 	}
 
 	return taskRunner, manager, cleanup, nil
@@ -942,6 +1980,7 @@ func (r *evalRunner) executeTaskSteps(
 	taskRunner task.TaskRunner,
 	agentRunner agent.Runner,
 	manager mcpproxy.ServerManager,
+	stallTimeout time.Duration,
 	result *EvalResult,
 ) {
 	r.progressCallback(ProgressEvent{
@@ -953,14 +1992,34 @@ func (r *evalRunner) executeTaskSteps(
 	agentRunner = agentRunner.WithMcpServerInfo(manager)
 
 	if util.IsVerbose(ctx) {
-		fmt.Printf("  → Agent '%s' is working…\n", agentRunner.AgentName())
+		fmt.Fprintf(util.OutputFromContext(ctx), "  → Agent '%s' is working…\n", agentRunner.AgentName())
 	}
-	agentOutput, err := taskRunner.RunAgent(ctx, agentRunner)
+	agentCtx, watchdog := watchForStall(ctx, stallTimeout)
+	agentOutput, err := taskRunner.RunAgent(agentCtx, agentRunner.WithActivity(watchdog.agentActivity()))
+	stalled := watchdog.stop()
 	result.AgentOutput = agentOutput
+	if stalled {
+		// The agent was stopped, so verification is skipped and the run goes
+		// straight to cleanup
+		result.TaskPassed = false
+		result.Stalled = true
+		result.TaskError = fmt.Sprintf("agent made no progress for %s and was stopped", stallTimeout)
+		result.ErrorKind = task.ErrorKindAgent
+		result.AgentExecutionError = true
+		result.StallDiagnostics = watchdog.diagnostics
+		r.progressCallback(ProgressEvent{
+			Type:    EventTaskStalled,
+			Message: fmt.Sprintf("Task %s stalled: no agent progress for %s", result.TaskName, stallTimeout),
+			Task:    result,
+		})
+		return
+	}
 	if err != nil {
 		result.TaskPassed = false
 		result.TaskError = err.Error()
+		result.ErrorKind = errorKind(err, task.ErrorKindAgent)
 		result.AgentExecutionError = true
+		result.PanicStack = task.PanicStack(err)
 		if agentOutput != nil && agentOutput.AgentDetails != nil {
 			result.TaskOutput = agent.FinalMessageFromSteps(agentOutput.AgentDetails.OutputSteps)
 		}
@@ -971,9 +2030,18 @@ func (r *evalRunner) executeTaskSteps(
 		result.TaskOutput = agent.FinalMessageFromSteps(agentOutput.AgentDetails.OutputSteps)
 	}
 
-	// Extract token estimate from agent details
+	// Extract token estimate and resource usage from agent details
 	if agentOutput != nil && agentOutput.AgentDetails != nil {
 		result.TokenEstimate = agentOutput.AgentDetails.TokenEstimate
+		result.ResourceUsage = agentOutput.AgentDetails.ResourceUsage
+
+		if r.captureRaw != nil {
+			rawUpdates, err := captureRawUpdates(agentOutput.AgentDetails.RawUpdates, *r.captureRaw)
+			if err != nil {
+				r.progressCallback(ProgressEvent{Type: EventTaskWarning, Message: fmt.Sprintf("task %s: %v", result.TaskName, err)})
+			}
+			result.RawUpdates = rawUpdates
+		}
 	}
 
 	r.progressCallback(ProgressEvent{
@@ -984,6 +2052,7 @@ func (r *evalRunner) executeTaskSteps(
 
 	verifyOutput, err := taskRunner.Verify(ctx)
 	result.VerifyOutput = verifyOutput
+	result.StepOutputs = collectStepOutputs(result.SetupOutput, verifyOutput)
 
 	// Aggregate judge usage from verify phase steps
 	if verifyOutput != nil {
@@ -999,12 +2068,23 @@ func (r *evalRunner) executeTaskSteps(
 		}
 	}
 
-	if err != nil {
+	var judgeErr *llmjudge.JudgeError
+	if err != nil && errors.As(err, &judgeErr) {
+		result.TaskPassed = false
+		result.JudgeError = true
+		result.TaskJudgeError = judgeErr.Error()
+		result.TaskError = fmt.Sprintf("llm judge error: %s", judgeErr.Error())
+		// The task could not be judged, which is not a failure of the agent
+		result.ErrorKind = task.ErrorKindInfra
+	} else if err != nil {
 		result.TaskPassed = false
 		result.TaskError = fmt.Sprintf("verification failed: %s", err.Error())
+		result.ErrorKind = errorKind(err, task.ErrorKindVerify)
+		result.PanicStack = task.PanicStack(err)
 	} else if verifyOutput != nil && !verifyOutput.Success {
 		result.TaskPassed = false
 		result.TaskError = "one or more verification steps failed"
+		result.ErrorKind = task.ErrorKindVerify
 	} else {
 		result.TaskPassed = true
 	}
@@ -1013,6 +2093,82 @@ func (r *evalRunner) executeTaskSteps(
 	r.extractJudgeResults(verifyOutput, result)
 }
 
+// checkCleanup runs the cleanupVerify steps of taskRunner, recording the
+// resources they report as left behind on result.
+func (r *evalRunner) checkCleanup(ctx context.Context, taskRunner task.TaskRunner, result *EvalResult) {
+	output, _ := taskRunner.VerifyCleanup(ctx)
+	if output == nil || len(output.Steps) == 0 {
+		return
+	}
+	result.CleanupVerifyOutput = output
+	result.LeakedResources = leakedResources(output)
+}
+
+// leakedResources describes the failing steps of the output of the
+// cleanupVerify steps, by their ID and what they reported.
+func leakedResources(output *task.PhaseOutput) []string {
+	var leaked []string
+	for _, step := range output.Steps {
+		if step == nil || step.Success {
+			continue
+		}
+		detail := strings.TrimSpace(step.Error)
+		if detail == "" {
+			detail = strings.TrimSpace(step.Message)
+		}
+		if detail == "" {
+			leaked = append(leaked, step.ID)
+		} else {
+			leaked = append(leaked, fmt.Sprintf("%s: %s", step.ID, detail))
+		}
+	}
+	return leaked
+}
+
+// recordPanic fails result with the panic v, which happened outside of the
+// steps and the agent of the run, and reports the run as complete.
+func (r *evalRunner) recordPanic(result *EvalResult, v any) {
+	result.TaskPassed = false
+	result.TaskError = fmt.Sprintf("panic: %v", v)
+	result.PanicStack = string(debug.Stack())
+	result.ErrorKind = task.ErrorKindInfra
+	r.progressCallback(ProgressEvent{
+		Type:    EventTaskComplete,
+		Message: fmt.Sprintf("Completed task: %s (passed: false)", result.TaskName),
+		Task:    result,
+		Usage:   r.usage.record(result),
+	})
+}
+
+// errorKind returns the kind of err, or fallback if err is not a typed task error.
+func errorKind(err error, fallback task.ErrorKind) task.ErrorKind {
+	if kind := task.ErrorKindOf(err); kind != "" {
+		return kind
+	}
+	return fallback
+}
+
+// collectStepOutputs returns the outputs of the successful steps in phases, keyed
+// by step ID, or nil if no step produced outputs.
+func collectStepOutputs(phases ...*task.PhaseOutput) map[string]map[string]string {
+	var outputs map[string]map[string]string
+	for _, phase := range phases {
+		if phase == nil {
+			continue
+		}
+		for _, step := range phase.Steps {
+			if step == nil || !step.Success || step.ID == "" || len(step.Outputs) == 0 {
+				continue
+			}
+			if outputs == nil {
+				outputs = make(map[string]map[string]string)
+			}
+			outputs[step.ID] = step.Outputs
+		}
+	}
+	return outputs
+}
+
 func (r *evalRunner) extractJudgeResults(verifyOutput *task.PhaseOutput, result *EvalResult) {
 	if verifyOutput == nil {
 		return
@@ -1023,15 +2179,15 @@ func (r *evalRunner) extractJudgeResults(verifyOutput *task.PhaseOutput, result
 		if step == nil || step.Type != "llmJudge" {
 			continue
 		}
-		// The judge's reason is in Message for both pass and fail
+		// The judge's reason is in Message for both pass and fail. Judge errors (API
+		// failures) return no step output and are recorded from the verify error instead.
 		result.TaskJudgeReason = step.Message
-		// If there was a judge error (API failure), it would have caused an error return
-		// so we don't need to check for TaskJudgeError here - the verify phase would have failed
 		break // Only capture first llmJudge result
 	}
 }
 
 func (r *evalRunner) evaluateTaskAssertions(
+	ctx context.Context,
 	tc taskConfig,
 	manager mcpproxy.ServerManager,
 	result *EvalResult,
@@ -1044,6 +2200,7 @@ func (r *evalRunner) evaluateTaskAssertions(
 
 	// Evaluate each assertion set independently and combine results
 	callHistory := manager.GetAllCallHistory()
+	var annotations ToolAnnotations
 	var combinedResults *CompositeAssertionResult
 	allPassed := true
 
@@ -1063,6 +2220,14 @@ func (r *evalRunner) evaluateTaskAssertions(
 		// Evaluate skill assertions against agent tool calls
 		r.evaluateSkillAssertions(assertions, agentToolCalls, assertionResults)
 
+		// The read-only assertion classifies calls by the annotations of the servers' tools
+		if assertions.ReadOnly != nil {
+			if annotations == nil {
+				annotations = collectToolAnnotations(ctx, manager)
+			}
+			assertionResults.ReadOnly = evaluateReadOnly(assertions.ReadOnly, callHistory, annotations)
+		}
+
 		if combinedResults == nil {
 			combinedResults = assertionResults
 		} else {
@@ -1078,6 +2243,20 @@ func (r *evalRunner) evaluateTaskAssertions(
 	result.AllAssertionsPassed = allPassed
 }
 
+// collectToolAnnotations returns the annotations of the allowed tools of the
+// manager's MCP servers.
+func collectToolAnnotations(ctx context.Context, manager mcpproxy.ServerManager) ToolAnnotations {
+	annotations := make(ToolAnnotations)
+	for _, server := range manager.GetMcpServers() {
+		tools := make(map[string]*mcp.ToolAnnotations)
+		for _, tool := range server.GetAllowedTools(ctx) {
+			tools[tool.Name] = tool.Annotations
+		}
+		annotations[server.GetName()] = tools
+	}
+	return annotations
+}
+
 func (r *evalRunner) evaluateSkillAssertions(
 	assertions *TaskAssertions,
 	toolCalls []agent.ToolCallSummary,
@@ -3,7 +3,10 @@ package eval
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"testing"
 	"time"
@@ -12,6 +15,7 @@ import (
 	extSpec "github.com/mcpchecker/mcpchecker/pkg/extension"
 	"github.com/mcpchecker/mcpchecker/pkg/extension/client"
 	extprotocol "github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
+	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
 	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
 	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
 	"github.com/mcpchecker/mcpchecker/pkg/steps"
@@ -241,7 +245,7 @@ func TestMatchesLabelSelector(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			result := matchesLabelSelector(tc.taskLabels, tc.selector)
+			result := LabelSelectorFromMap(tc.selector).Matches(tc.taskLabels)
 			assert.Equal(t, tc.expected, result)
 		})
 	}
@@ -549,7 +553,7 @@ func TestGroupTasksByParallelSupport(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			groups := groupTasksByParallelSupport(tc.tasks)
+			groups := groupTasksByParallelSupport(tc.tasks, nil)
 
 			seqCount := 0
 			parCount := 0
@@ -578,10 +582,61 @@ func TestGroupTasksByParallelSupport(t *testing.T) {
 	}
 }
 
+func TestScheduleParallelTasks(t *testing.T) {
+	makeTask := func(name string, priority int) taskConfig {
+		return taskConfig{
+			path: name + ".yaml",
+			spec: &task.TaskConfig{
+				Metadata: task.TaskMetadata{
+					Name:     name,
+					Parallel: true,
+					Priority: priority,
+				},
+			},
+		}
+	}
+	tasks := []taskConfig{
+		makeTask("a", 0),
+		makeTask("b", 10),
+		makeTask("c", 0),
+		makeTask("d", -5),
+		makeTask("e", 10),
+	}
+
+	tests := map[string]struct {
+		failedFirst map[string]bool
+		expected    []string
+	}{
+		"by priority": {
+			expected: []string{"b", "e", "a", "c", "d"},
+		},
+		"failed first": {
+			failedFirst: map[string]bool{"d": true, "c": true},
+			expected:    []string{"c", "d", "b", "e", "a"},
+		},
+		"failed tasks not in the run": {
+			failedFirst: map[string]bool{"gone": true},
+			expected:    []string{"b", "e", "a", "c", "d"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var names []string
+			for _, scheduled := range scheduleParallelTasks(tasks, tc.failedFirst) {
+				names = append(names, scheduled.spec.Metadata.Name)
+			}
+			assert.Equal(t, tc.expected, names)
+			assert.Equal(t, "a", tasks[0].spec.Metadata.Name, "the tasks must not be reordered in place")
+		})
+	}
+}
+
 func TestGetRunsForTask(t *testing.T) {
-	makeTask := func(runs int) taskConfig {
+	makeTask := func(runs, repeat int) taskConfig {
 		return taskConfig{
-			path: "test.yaml",
+			path:   "test.yaml",
+			repeat: repeat,
 			spec: &task.TaskConfig{
 				Metadata: task.TaskMetadata{
 					Name: "test-task",
@@ -595,6 +650,7 @@ func TestGetRunsForTask(t *testing.T) {
 		runnerRuns        int
 		runsExplicitlySet bool
 		taskRuns          int
+		taskSetRepeat     int
 		expected          int
 	}{
 		"default - no CLI, no task runs": {
@@ -627,6 +683,20 @@ func TestGetRunsForTask(t *testing.T) {
 			taskRuns:          3,
 			expected:          1,
 		},
+		"task set repeat overrides task runs": {
+			runnerRuns:        1,
+			runsExplicitlySet: false,
+			taskRuns:          3,
+			taskSetRepeat:     5,
+			expected:          5,
+		},
+		"CLI explicitly set overrides task set repeat": {
+			runnerRuns:        2,
+			runsExplicitlySet: true,
+			taskRuns:          3,
+			taskSetRepeat:     5,
+			expected:          2,
+		},
 	}
 
 	for name, tc := range tests {
@@ -635,7 +705,7 @@ func TestGetRunsForTask(t *testing.T) {
 				runs:              tc.runnerRuns,
 				runsExplicitlySet: tc.runsExplicitlySet,
 			}
-			task := makeTask(tc.taskRuns)
+			task := makeTask(tc.taskRuns, tc.taskSetRepeat)
 
 			result := runner.getRunsForTask(task)
 			assert.Equal(t, tc.expected, result)
@@ -688,6 +758,7 @@ func TestCollectTaskConfigsDeduplication(t *testing.T) {
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
 			runner := &evalRunner{
+				progressCallback: NoopProgressCallback,
 				spec: &EvalSpec{
 					Config: EvalConfig{
 						TaskSets: tc.taskSets,
@@ -708,6 +779,7 @@ func TestCollectTaskConfigsAssertionSets(t *testing.T) {
 	maxCalls := 10
 
 	runner := &evalRunner{
+		progressCallback: NoopProgressCallback,
 		spec: &EvalSpec{
 			Config: EvalConfig{
 				TaskSets: []TaskSet{
@@ -754,6 +826,7 @@ func TestCollectTaskConfigsAssertionSets(t *testing.T) {
 
 func TestCollectTaskConfigsNilAssertions(t *testing.T) {
 	runner := &evalRunner{
+		progressCallback: NoopProgressCallback,
 		spec: &EvalSpec{
 			Config: EvalConfig{
 				TaskSets: []TaskSet{
@@ -771,6 +844,257 @@ func TestCollectTaskConfigsNilAssertions(t *testing.T) {
 	assert.Len(t, configs[0].assertions, 0, "nil assertions should not be added to slice")
 }
 
+func TestCollectTaskConfigsRepeat(t *testing.T) {
+	runner := &evalRunner{
+		progressCallback: NoopProgressCallback,
+		spec: &EvalSpec{
+			Config: EvalConfig{
+				TaskSets: []TaskSet{
+					{Path: "../task/testdata/create-pod-inline.yaml", Repeat: 3},
+					{Path: "../task/testdata/create-pod-inline.yaml", Repeat: 5},
+					{Path: "../task/testdata/create-pod-inline.yaml"},
+				},
+			},
+		},
+	}
+
+	configs, err := runner.collectTaskConfigs(regexp.MustCompile(".*"))
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Equal(t, 5, configs[0].repeat, "the task should run as often as the task set repeating it most")
+}
+
+func TestCollectTaskConfigsExpandsSteps(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "common.yaml"), []byte(`kind: StepLibrary
+apiVersion: mcpchecker/v1alpha2
+spec:
+  sequences:
+    greet:
+      params:
+        name: {}
+      steps:
+        - id: hello
+          script:
+            inline: echo hello {params.name}
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "task.yaml"), []byte(`kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: uses-library
+spec:
+  setup:
+    - id: greet
+      use: common/greet
+      with:
+        name: world
+  prompt:
+    inline: Do something
+`), 0644))
+
+	runner := &evalRunner{
+		progressCallback: NoopProgressCallback,
+		spec: &EvalSpec{
+			Config: EvalConfig{
+				StepLibraries: map[string]string{"common": filepath.Join(dir, "common.yaml")},
+				TaskSets:      []TaskSet{{Path: filepath.Join(dir, "task.yaml")}},
+			},
+		},
+	}
+
+	configs, err := runner.collectTaskConfigs(regexp.MustCompile(".*"))
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	setup := configs[0].spec.Spec.Setup
+	require.Len(t, setup, 1)
+	assert.Equal(t, "greet_hello", setup[0].ID)
+	assert.JSONEq(t, `{"inline": "echo hello world"}`, string(setup[0].Config["script"]))
+
+	// Tasks can't use libraries that the eval config doesn't list
+	runner.spec.Config.StepLibraries = nil
+	_, err = runner.collectTaskConfigs(regexp.MustCompile(".*"))
+	assert.ErrorContains(t, err, `unknown step library "common"`)
+}
+
+func TestCollectTaskConfigsExpandsTemplates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "task.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: deploy
+spec:
+  parameters:
+    image: [nginx, redis]
+  prompt:
+    inline: Deploy {params.image}
+`), 0644))
+
+	runner := &evalRunner{
+		progressCallback: NoopProgressCallback,
+		spec: &EvalSpec{
+			Config: EvalConfig{
+				// The task set listed twice runs each instance once
+				TaskSets: []TaskSet{{Path: path}, {Path: path}},
+			},
+		},
+	}
+
+	configs, err := runner.collectTaskConfigs(regexp.MustCompile(".*"))
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+	assert.Equal(t, "deploy[image=nginx]", configs[0].spec.Metadata.Name)
+	assert.Equal(t, "Deploy nginx", configs[0].spec.Spec.Prompt.Inline)
+	assert.Equal(t, "deploy[image=redis]", configs[1].spec.Metadata.Name)
+	assert.Equal(t, map[string]any{"image": "redis"}, configs[1].spec.Parameters())
+	assert.Equal(t, path, configs[1].path)
+
+	configs, err = runner.collectTaskConfigs(regexp.MustCompile(`image=redis`))
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Equal(t, "deploy[image=redis]", configs[0].spec.Metadata.Name)
+}
+
+func TestCollectTaskConfigsSkip(t *testing.T) {
+	tests := map[string]struct {
+		opts          RunnerOptions
+		expectedPaths []string
+		expectErr     bool
+	}{
+		"no skips": {
+			expectedPaths: []string{
+				"../task/testdata/create-pod-inline-no-verify.yaml",
+				"../task/testdata/create-pod-inline.yaml",
+				"../task/testdata/task-with-limits.yaml",
+			},
+		},
+		"skip by task name": {
+			opts:          RunnerOptions{SkipPattern: "pod"},
+			expectedPaths: []string{"../task/testdata/task-with-limits.yaml"},
+		},
+		"skip by file glob": {
+			opts:          RunnerOptions{SkipPaths: []string{"../task/testdata/create-pod-*"}},
+			expectedPaths: []string{"../task/testdata/task-with-limits.yaml"},
+		},
+		"skip by directory": {
+			opts: RunnerOptions{SkipPaths: []string{"../task"}},
+		},
+		"skip name and path combined": {
+			opts: RunnerOptions{
+				SkipPattern: "limits",
+				SkipPaths:   []string{"../task/testdata/create-pod-inline.yaml"},
+			},
+			expectedPaths: []string{"../task/testdata/create-pod-inline-no-verify.yaml"},
+		},
+		"invalid skip pattern": {
+			opts:      RunnerOptions{SkipPattern: "("},
+			expectErr: true,
+		},
+		"invalid skip path": {
+			opts:      RunnerOptions{SkipPaths: []string{"[unclosed"}},
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec := &EvalSpec{
+				Config: EvalConfig{
+					TaskSets: []TaskSet{{Glob: "../task/testdata/*.yaml"}},
+				},
+			}
+			runner, err := NewRunner(spec, tc.opts)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			configs, err := runner.(*evalRunner).collectTaskConfigs(regexp.MustCompile(".*"))
+			require.NoError(t, err)
+
+			var paths []string
+			for _, c := range configs {
+				paths = append(paths, filepath.ToSlash(c.path))
+			}
+			assert.Equal(t, tc.expectedPaths, paths)
+		})
+	}
+}
+
+func TestCollectTaskConfigsTaskIDs(t *testing.T) {
+	writeTask := func(t *testing.T, dir, file, id, name string) {
+		t.Helper()
+		data := fmt.Sprintf(`kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  id: %q
+  name: %q
+spec:
+  verify:
+    - script:
+        inline: echo ok
+  prompt:
+    inline: Do something
+`, id, name)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(data), 0644))
+	}
+
+	tests := map[string]struct {
+		tasks        [][3]string // file, id, name
+		errContains  string
+		wantWarnings int
+	}{
+		"unique ids": {
+			tasks: [][3]string{{"a.yaml", "a", "same name"}, {"b.yaml", "b", "same name"}},
+		},
+		"duplicate names without ids only warn": {
+			tasks:        [][3]string{{"a.yaml", "", "same name"}, {"b.yaml", "", "same name"}},
+			wantWarnings: 1,
+		},
+		"duplicate ids": {
+			tasks:       [][3]string{{"a.yaml", "pods", "create pod"}, {"b.yaml", "pods", "delete pod"}},
+			errContains: `duplicate task id "pods"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, task := range tc.tasks {
+				writeTask(t, dir, task[0], task[1], task[2])
+			}
+
+			var warnings []ProgressEvent
+			runner := &evalRunner{
+				progressCallback: func(e ProgressEvent) {
+					if e.Type == EventTaskWarning {
+						warnings = append(warnings, e)
+					}
+				},
+				spec: &EvalSpec{
+					Config: EvalConfig{
+						TaskSets: []TaskSet{{Glob: filepath.Join(dir, "*.yaml")}},
+					},
+				},
+			}
+
+			configs, err := runner.collectTaskConfigs(regexp.MustCompile(".*"))
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, configs, len(tc.tasks))
+			require.Len(t, warnings, tc.wantWarnings)
+			for _, w := range warnings {
+				assert.Contains(t, w.Message, `both keyed "same name"`)
+			}
+		})
+	}
+}
+
 func TestResolveTaskTimeout(t *testing.T) {
 	tests := map[string]struct {
 		taskTimeout        string
@@ -1031,6 +1355,10 @@ func (f *fakeAgentRunner) WithSkillInfo(_ *agent.SkillInfo) agent.Runner {
 	return f
 }
 
+func (f *fakeAgentRunner) WithActivity(_ *util.Activity) agent.Runner {
+	return f
+}
+
 func (f *fakeAgentRunner) AgentName() string {
 	return "fake-agent"
 }
@@ -1067,12 +1395,25 @@ func (f *fakeExtensionManager) ShutdownAll(_ context.Context) error { return nil
 // fakeExtensionClient implements client.Client for testing
 type fakeExtensionClient struct {
 	manifest *extprotocol.InitializeResult
+	// panicOn is an operation that panics when executed
+	panicOn string
+	// failOn is an operation that fails when executed
+	failOn string
+	// executed records the operations that were executed
+	executed []string
 }
 
 func (f *fakeExtensionClient) Start(_ context.Context, _ *extprotocol.InitializeParams) error {
 	return nil
 }
-func (f *fakeExtensionClient) Execute(_ context.Context, _ *extprotocol.ExecuteParams) (*extprotocol.ExecuteResult, error) {
+func (f *fakeExtensionClient) Execute(_ context.Context, params *extprotocol.ExecuteParams, _ client.LogSink) (*extprotocol.ExecuteResult, error) {
+	f.executed = append(f.executed, params.Operation)
+	if params.Operation == f.panicOn {
+		panic("assignment to entry in nil map")
+	}
+	if params.Operation == f.failOn {
+		return &extprotocol.ExecuteResult{Success: false, Message: "namespace test-abc still exists"}, nil
+	}
 	return &extprotocol.ExecuteResult{Success: true, Message: "cleaned up"}, nil
 }
 func (f *fakeExtensionClient) Manifest() *extprotocol.InitializeResult {
@@ -1080,12 +1421,12 @@ func (f *fakeExtensionClient) Manifest() *extprotocol.InitializeResult {
 }
 func (f *fakeExtensionClient) Shutdown(_ context.Context) error { return nil }
 
-// setupTestContext creates a context with fake MCP and extension managers injected
-func setupTestContext() context.Context {
-	ctx := context.Background()
-	ctx = mcpclient.ManagerToContext(ctx, &fakeMcpManager{})
-	ctx = client.ManagerToContext(ctx, newFakeExtensionManager())
-	return ctx
+// setupTestDeps creates dependencies with fake MCP and extension managers
+func setupTestDeps() *steps.Dependencies {
+	return &steps.Dependencies{
+		McpClients: &fakeMcpManager{},
+		Extensions: newFakeExtensionManager(),
+	}
 }
 
 func TestRunTaskTimeout(t *testing.T) {
@@ -1108,7 +1449,7 @@ func TestRunTaskTimeout(t *testing.T) {
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			ctx := setupTestContext()
+			ctx := context.Background()
 
 			runner := &evalRunner{
 				spec: &EvalSpec{
@@ -1116,6 +1457,7 @@ func TestRunTaskTimeout(t *testing.T) {
 				},
 				taskTimeout:      tc.taskTimeout,
 				progressCallback: NoopProgressCallback,
+				deps:             setupTestDeps(),
 			}
 
 			taskCfg := taskConfig{
@@ -1125,7 +1467,7 @@ func TestRunTaskTimeout(t *testing.T) {
 						Name: "timeout-test",
 					},
 					Spec: &task.TaskSpec{
-						Prompt: &util.Step{Inline: "do something"},
+						Prompt: &task.Prompt{Step: util.Step{Inline: "do something"}},
 					},
 				},
 			}
@@ -1140,6 +1482,7 @@ func TestRunTaskTimeout(t *testing.T) {
 			if tc.expectTimeout {
 				assert.False(t, result.TaskPassed)
 				assert.Contains(t, result.TaskError, "task exceeded timeout")
+				assert.Equal(t, task.ErrorKindAgent, result.ErrorKind)
 			} else {
 				assert.True(t, result.TaskPassed)
 			}
@@ -1148,7 +1491,7 @@ func TestRunTaskTimeout(t *testing.T) {
 }
 
 func TestRunTaskCleanupRunsAfterTimeout(t *testing.T) {
-	ctx := setupTestContext()
+	ctx := context.Background()
 
 	runner := &evalRunner{
 		spec: &EvalSpec{
@@ -1156,6 +1499,7 @@ func TestRunTaskCleanupRunsAfterTimeout(t *testing.T) {
 		},
 		taskTimeout:      "100ms",
 		progressCallback: NoopProgressCallback,
+		deps:             setupTestDeps(),
 	}
 
 	taskCfg := taskConfig{
@@ -1165,7 +1509,7 @@ func TestRunTaskCleanupRunsAfterTimeout(t *testing.T) {
 				Name: "cleanup-after-timeout",
 			},
 			Spec: &task.TaskSpec{
-				Prompt: &util.Step{Inline: "do something"},
+				Prompt: &task.Prompt{Step: util.Step{Inline: "do something"}},
 			},
 		},
 	}
@@ -1184,7 +1528,123 @@ func TestRunTaskCleanupRunsAfterTimeout(t *testing.T) {
 	assert.True(t, result.CleanupOutput.Success, "cleanup with no steps should succeed")
 }
 
-func TestCleanupContextHasManagers(t *testing.T) {
+// fakeJudge implements llmjudge.LLMJudge with a fixed verdict or error.
+type fakeJudge struct {
+	result *llmjudge.LLMJudgeResult
+	err    error
+}
+
+func (f *fakeJudge) EvaluateText(_ context.Context, _ *llmjudge.LLMJudgeStepConfig, _, _ string, _ []agent.ToolCallSummary, _ *llmjudge.AuditLog) (*llmjudge.LLMJudgeResult, error) {
+	return f.result, f.err
+}
+func (f *fakeJudge) ModelName() string { return "fake-judge" }
+func (f *fakeJudge) Close() error      { return nil }
+
+func TestRunTaskJudgeError(t *testing.T) {
+	tests := map[string]struct {
+		judge           *fakeJudge
+		expectPassed    bool
+		expectJudgeErr  bool
+		expectTaskError string
+		expectErrorKind task.ErrorKind
+	}{
+		"judge passes": {
+			judge:        &fakeJudge{result: &llmjudge.LLMJudgeResult{Passed: true, Reason: "ok"}},
+			expectPassed: true,
+		},
+		"judge verdict fails": {
+			judge:           &fakeJudge{result: &llmjudge.LLMJudgeResult{Passed: false, Reason: "wrong", FailureCategory: "semantic_mismatch"}},
+			expectTaskError: "one or more verification steps failed",
+			expectErrorKind: task.ErrorKindVerify,
+		},
+		"judge call errors": {
+			judge:           &fakeJudge{err: errors.New("429 too many requests")},
+			expectJudgeErr:  true,
+			expectTaskError: "llm judge error: ",
+			expectErrorKind: task.ErrorKindInfra,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			deps := setupTestDeps()
+			deps.Judge = tc.judge
+
+			runner := &evalRunner{
+				spec: &EvalSpec{
+					Config: EvalConfig{},
+				},
+				progressCallback: NoopProgressCallback,
+				deps:             deps,
+			}
+
+			taskCfg := taskConfig{
+				path: "test.yaml",
+				spec: &task.TaskConfig{
+					Metadata: task.TaskMetadata{
+						Name: "judge-test",
+					},
+					Spec: &task.TaskSpec{
+						Prompt: &task.Prompt{Step: util.Step{Inline: "do something"}},
+						Verify: []*steps.StepConfig{{
+							Config: map[string]json.RawMessage{"llmJudge": json.RawMessage(`{"contains": "done"}`)},
+						}},
+					},
+				},
+			}
+
+			result, err := runner.runTask(ctx, &fakeAgentRunner{delay: time.Millisecond}, taskCfg)
+			require.NoError(t, err)
+			require.NotNil(t, result)
+
+			assert.Equal(t, tc.expectPassed, result.TaskPassed)
+			assert.Equal(t, tc.expectJudgeErr, result.JudgeError)
+			assert.Equal(t, tc.expectErrorKind, result.ErrorKind)
+			if tc.expectJudgeErr {
+				assert.Contains(t, result.TaskJudgeError, "429 too many requests")
+			}
+			if tc.expectTaskError != "" {
+				assert.Contains(t, result.TaskError, tc.expectTaskError)
+			}
+		})
+	}
+}
+
+func TestCollectStepOutputs(t *testing.T) {
+	tests := map[string]struct {
+		phases []*task.PhaseOutput
+		want   map[string]map[string]string
+	}{
+		"no phases": {},
+		"no outputs": {
+			phases: []*task.PhaseOutput{{Steps: []*steps.StepOutput{{ID: "setup_0", Success: true}}}},
+		},
+		"setup and verify outputs": {
+			phases: []*task.PhaseOutput{
+				{Steps: []*steps.StepOutput{{ID: "create_ns", Success: true, Outputs: map[string]string{"namespace": "ns-1"}}}},
+				nil,
+				{Steps: []*steps.StepOutput{
+					{ID: "count_pods", Success: true, Outputs: map[string]string{"count": "3"}},
+					{ID: "verify_1", Success: false, Outputs: map[string]string{"count": "0"}},
+					nil,
+				}},
+			},
+			want: map[string]map[string]string{
+				"create_ns":  {"namespace": "ns-1"},
+				"count_pods": {"count": "3"},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, collectStepOutputs(tc.phases...))
+		})
+	}
+}
+
+func TestCleanupHasDependencies(t *testing.T) {
 	extManager := newFakeExtensionManager()
 	extManager.extensions["testExt"] = &fakeExtensionClient{
 		manifest: &extprotocol.InitializeResult{
@@ -1199,14 +1659,16 @@ func TestCleanupContextHasManagers(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	ctx = mcpclient.ManagerToContext(ctx, &fakeMcpManager{})
-	ctx = client.ManagerToContext(ctx, extManager)
 
 	runner := &evalRunner{
 		spec: &EvalSpec{
 			Config: EvalConfig{},
 		},
 		progressCallback: NoopProgressCallback,
+		deps: &steps.Dependencies{
+			McpClients: &fakeMcpManager{},
+			Extensions: extManager,
+		},
 	}
 
 	extName := "testExt"
@@ -1227,7 +1689,7 @@ func TestCleanupContextHasManagers(t *testing.T) {
 						},
 					},
 				},
-				Prompt: &util.Step{Inline: "do something"},
+				Prompt: &task.Prompt{Step: util.Step{Inline: "do something"}},
 			},
 		},
 	}
@@ -1239,7 +1701,282 @@ func TestCleanupContextHasManagers(t *testing.T) {
 	require.NotNil(t, result)
 
 	// Cleanup must have run successfully — the extension manager should be
-	// available in the cleanup context so the extension step can execute.
+	// available to cleanup steps after the agent phase so the extension step can execute.
 	require.NotNil(t, result.CleanupOutput, "cleanup output should be set")
 	assert.True(t, result.CleanupOutput.Success, "cleanup should succeed; got error: %s", result.CleanupOutput.Error)
 }
+
+// panickingAgentRunner is an agent.Runner whose agent panics
+type panickingAgentRunner struct {
+	fakeAgentRunner
+}
+
+func (f *panickingAgentRunner) RunTask(context.Context, string) (agent.AgentResult, error) {
+	panic("nil pointer dereference")
+}
+
+func (f *panickingAgentRunner) WithMcpServerInfo(_ mcpproxy.ServerManager) agent.Runner {
+	return f
+}
+
+func (f *panickingAgentRunner) WithActivity(_ *util.Activity) agent.Runner {
+	return f
+}
+
+func TestRunTaskPanicRunsCleanup(t *testing.T) {
+	tests := map[string]struct {
+		panicOn          string
+		agentRunner      agent.Runner
+		progressCallback ProgressCallback
+		wantError        string
+		wantKind         task.ErrorKind
+	}{
+		"setup step": {
+			panicOn:     "doSetup",
+			agentRunner: &fakeAgentRunner{},
+			wantError:   "panic: assignment to entry in nil map",
+			wantKind:    task.ErrorKindSetup,
+		},
+		"agent": {
+			agentRunner: &panickingAgentRunner{},
+			wantError:   "panic: nil pointer dereference",
+			wantKind:    task.ErrorKindAgent,
+		},
+		"verify step": {
+			panicOn:     "doVerify",
+			agentRunner: &fakeAgentRunner{},
+			wantError:   "panic: assignment to entry in nil map",
+			wantKind:    task.ErrorKindVerify,
+		},
+		"outside of steps": {
+			agentRunner: &fakeAgentRunner{},
+			progressCallback: func(event ProgressEvent) {
+				if event.Type == EventTaskVerifying {
+					panic("closed channel")
+				}
+			},
+			wantError: "panic: closed channel",
+			wantKind:  task.ErrorKindInfra,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ext := &fakeExtensionClient{
+				manifest: &extprotocol.InitializeResult{
+					Name:    "testExt",
+					Version: "1.0.0",
+					Operations: map[string]*extprotocol.Operation{
+						"doSetup":   {},
+						"doVerify":  {},
+						"doCleanup": {},
+					},
+				},
+				panicOn: tc.panicOn,
+			}
+			extManager := newFakeExtensionManager()
+			extManager.extensions["testExt"] = ext
+
+			progressCallback := tc.progressCallback
+			if progressCallback == nil {
+				progressCallback = NoopProgressCallback
+			}
+			runner := &evalRunner{
+				spec:             &EvalSpec{},
+				progressCallback: progressCallback,
+				deps: &steps.Dependencies{
+					McpClients: &fakeMcpManager{},
+					Extensions: extManager,
+				},
+			}
+
+			extStep := func(operation string) []*steps.StepConfig {
+				return []*steps.StepConfig{{
+					Config: map[string]json.RawMessage{"testExt." + operation: json.RawMessage(`{}`)},
+				}}
+			}
+			extName := "testExt"
+			taskCfg := taskConfig{
+				path: "test.yaml",
+				spec: &task.TaskConfig{
+					Metadata: task.TaskMetadata{Name: "panic-test"},
+					Spec: &task.TaskSpec{
+						Requires: []task.Requirements{{Extension: &extName}},
+						Setup:    extStep("doSetup"),
+						Verify:   extStep("doVerify"),
+						Cleanup:  extStep("doCleanup"),
+						Prompt:   &task.Prompt{Step: util.Step{Inline: "do something"}},
+					},
+				},
+			}
+
+			result, err := runner.runTask(context.Background(), tc.agentRunner, taskCfg)
+			require.NoError(t, err)
+			require.NotNil(t, result)
+
+			assert.False(t, result.TaskPassed)
+			assert.Contains(t, result.TaskError, tc.wantError)
+			assert.Equal(t, tc.wantKind, result.ErrorKind)
+			assert.Contains(t, result.PanicStack, "goroutine")
+
+			require.NotNil(t, result.CleanupOutput, "cleanup should run after a panic")
+			assert.True(t, result.CleanupOutput.Success)
+			assert.Contains(t, ext.executed, "doCleanup")
+		})
+	}
+}
+
+func TestRunTaskVerifyCleanup(t *testing.T) {
+	tests := map[string]struct {
+		verifyCleanup bool
+		failOn        string
+		wantLeaked    []string
+		wantOutput    bool
+	}{
+		"not enabled": {
+			failOn: "checkGone",
+		},
+		"nothing left behind": {
+			verifyCleanup: true,
+			wantOutput:    true,
+		},
+		"leaked resource": {
+			verifyCleanup: true,
+			failOn:        "checkGone",
+			wantLeaked:    []string{"ns_gone: namespace test-abc still exists"},
+			wantOutput:    true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ext := &fakeExtensionClient{
+				manifest: &extprotocol.InitializeResult{
+					Name:    "testExt",
+					Version: "1.0.0",
+					Operations: map[string]*extprotocol.Operation{
+						"doCleanup": {},
+						"checkGone": {},
+					},
+				},
+				failOn: tc.failOn,
+			}
+			extManager := newFakeExtensionManager()
+			extManager.extensions["testExt"] = ext
+
+			runner := &evalRunner{
+				spec:             &EvalSpec{},
+				progressCallback: NoopProgressCallback,
+				deps: &steps.Dependencies{
+					McpClients: &fakeMcpManager{},
+					Extensions: extManager,
+				},
+				verifyCleanup: tc.verifyCleanup,
+			}
+
+			extName := "testExt"
+			taskCfg := taskConfig{
+				path: "test.yaml",
+				spec: &task.TaskConfig{
+					Metadata: task.TaskMetadata{Name: "verify-cleanup-test"},
+					Spec: &task.TaskSpec{
+						Requires: []task.Requirements{{Extension: &extName}},
+						Cleanup: []*steps.StepConfig{{
+							Config: map[string]json.RawMessage{"testExt.doCleanup": json.RawMessage(`{}`)},
+						}},
+						CleanupVerify: []*steps.StepConfig{{
+							ID:     "ns_gone",
+							Config: map[string]json.RawMessage{"testExt.checkGone": json.RawMessage(`{}`)},
+						}},
+						Prompt: &task.Prompt{Step: util.Step{Inline: "do something"}},
+					},
+				},
+			}
+
+			result, err := runner.runTask(context.Background(), &fakeAgentRunner{}, taskCfg)
+			require.NoError(t, err)
+
+			assert.True(t, result.TaskPassed, "leaks don't fail the run")
+			assert.Equal(t, tc.wantLeaked, result.LeakedResources)
+			if tc.wantOutput {
+				require.NotNil(t, result.CleanupVerifyOutput)
+				assert.Equal(t, tc.wantLeaked == nil, result.CleanupVerifyOutput.Success)
+				assert.Equal(t, []string{"doCleanup", "checkGone"}, ext.executed)
+			} else {
+				assert.Nil(t, result.CleanupVerifyOutput)
+				assert.Equal(t, []string{"doCleanup"}, ext.executed)
+			}
+		})
+	}
+}
+
+func TestPartitionDeprecatedTasks(t *testing.T) {
+	makeTask := func(name, state string) taskConfig {
+		return taskConfig{
+			path: name + ".yaml",
+			spec: &task.TaskConfig{
+				Metadata: task.TaskMetadata{
+					Name:  name,
+					State: state,
+				},
+			},
+		}
+	}
+
+	tasks := []taskConfig{
+		makeTask("a", ""),
+		makeTask("b", task.StateQuarantined),
+		makeTask("c", task.StateDeprecated),
+		makeTask("d", task.StateActive),
+	}
+
+	runnable, deprecated := partitionDeprecatedTasks(tasks)
+	require.Len(t, runnable, 3)
+	require.Len(t, deprecated, 1)
+	assert.Equal(t, "c", deprecated[0].spec.Metadata.Name)
+
+	counts := countTaskStates(runnable, deprecated)
+	assert.Equal(t, map[string]int{
+		task.StateActive:      2,
+		task.StateQuarantined: 1,
+		task.StateDeprecated:  1,
+	}, counts)
+
+	assert.Equal(t, "", resultState(runnable[0]))
+	assert.Equal(t, task.StateQuarantined, resultState(runnable[1]))
+}
+
+func TestNewRunnerBuildAndMetadata(t *testing.T) {
+	build := &BuildInfo{Version: "v1.2.3", Commit: "abc1234", GoVersion: "go1.26.3", Platform: "linux/amd64"}
+
+	metadata := map[string]string{"branch": "main"}
+
+	r, err := NewRunner(&EvalSpec{}, RunnerOptions{Build: build, Metadata: metadata})
+	require.NoError(t, err)
+
+	summary := r.(*evalRunner).buildSummary(context.Background(), nil, nil, &fakeJudge{}, nil)
+	assert.Equal(t, build, summary.Build)
+	assert.Equal(t, metadata, summary.Metadata)
+}
+
+func TestCollectTaskConfigsWarnsDeprecatedFormat(t *testing.T) {
+	var events []ProgressEvent
+	runner := &evalRunner{
+		progressCallback: func(e ProgressEvent) { events = append(events, e) },
+		spec: &EvalSpec{
+			Config: EvalConfig{
+				TaskSets: []TaskSet{
+					{Path: "../task/testdata/create-pod-inline.yaml"},
+					{Path: "../task/testdata/task-with-limits.yaml"},
+				},
+			},
+		},
+	}
+
+	_, err := runner.collectTaskConfigs(regexp.MustCompile(".*"))
+	require.NoError(t, err)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, EventTaskWarning, events[0].Type)
+	assert.Contains(t, events[0].Message, "create-pod-inline.yaml uses the deprecated mcpchecker/v1alpha1 format")
+}
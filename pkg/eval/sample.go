@@ -0,0 +1,184 @@
+package eval
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+)
+
+const (
+	// SampleByDifficulty stratifies samples by task difficulty
+	SampleByDifficulty = "difficulty"
+
+	// SampleByNone samples tasks regardless of their difficulty and labels
+	SampleByNone = "none"
+
+	// sampleByLabelPrefix prefixes the label key that samples are stratified
+	// by, as in label:suite
+	sampleByLabelPrefix = "label:"
+)
+
+// SampleConfig selects a random subset of the tasks of a run, e.g. for quick
+// smoke runs of a large suite. The sample is stratified, so that every
+// difficulty or label value is represented in proportion to its tasks.
+type SampleConfig struct {
+	// Size is the number of tasks to sample
+	Size int `json:"size,omitempty"`
+
+	// Percent is the percentage of the tasks to sample, rounded up
+	Percent float64 `json:"percent,omitempty"`
+
+	// Seed selects the sample; the same seed selects the same tasks from the
+	// same suite
+	Seed uint64 `json:"seed"`
+
+	// By is what the sample is stratified by: "difficulty" (the default),
+	// "label:<key>" for the values of a label, or "none"
+	By string `json:"by,omitempty"`
+}
+
+// Validate checks that either the size or the percentage is set, and that
+// the stratification is known.
+func (c *SampleConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	switch {
+	case c.Size != 0 && c.Percent != 0:
+		return fmt.Errorf("sample size and percentage can't both be set")
+	case c.Size < 0:
+		return fmt.Errorf("sample size must be positive, got %d", c.Size)
+	case c.Percent < 0 || c.Percent > 100:
+		return fmt.Errorf("sample percentage must be between 0 and 100, got %g", c.Percent)
+	case c.Size == 0 && c.Percent == 0:
+		return fmt.Errorf("sample size or percentage must be set")
+	}
+	switch {
+	case c.By == "", c.By == SampleByDifficulty, c.By == SampleByNone:
+	case strings.HasPrefix(c.By, sampleByLabelPrefix) && len(c.By) > len(sampleByLabelPrefix):
+	default:
+		return fmt.Errorf("invalid sample stratification %q: must be %q, %q or %q", c.By, SampleByDifficulty, sampleByLabelPrefix+"<key>", SampleByNone)
+	}
+	return nil
+}
+
+// SampleSummary reports how the tasks of a run were sampled.
+type SampleSummary struct {
+	SampleConfig
+
+	// Selected is the number of sampled tasks, out of Total
+	Selected int `json:"selected"`
+	Total    int `json:"total"`
+}
+
+// stratum returns the value of tc that samples are stratified by.
+func (c *SampleConfig) stratum(tc taskConfig) string {
+	switch {
+	case c.By == SampleByNone:
+		return ""
+	case strings.HasPrefix(c.By, sampleByLabelPrefix):
+		return tc.spec.Metadata.Labels[strings.TrimPrefix(c.By, sampleByLabelPrefix)]
+	default:
+		return tc.spec.Metadata.Difficulty
+	}
+}
+
+// size returns the number of tasks to sample out of total.
+func (c *SampleConfig) size(total int) int {
+	n := c.Size
+	if c.Percent > 0 {
+		n = int(math.Ceil(float64(total) * c.Percent / 100))
+	}
+	return min(max(n, 1), total)
+}
+
+// sampleTasks returns a stratified random sample of tasks, in their original
+// order. Every stratum gets at least one task if the sample is large enough
+// for it, and the rest of the sample is spread over the strata in proportion
+// to their size.
+func sampleTasks(tasks []taskConfig, cfg *SampleConfig) []taskConfig {
+	n := cfg.size(len(tasks))
+	if n >= len(tasks) {
+		return tasks
+	}
+
+	// Strata are ordered by value, and shuffled with a seeded source, so that
+	// the sample only depends on the seed and the tasks
+	strata := make(map[string][]int)
+	for i, tc := range tasks {
+		key := cfg.stratum(tc)
+		strata[key] = append(strata[key], i)
+	}
+	keys := slices.Sorted(maps.Keys(strata))
+	rng := rand.New(rand.NewPCG(cfg.Seed, 0))
+	for _, key := range keys {
+		indexes := strata[key]
+		rng.Shuffle(len(indexes), func(i, j int) { indexes[i], indexes[j] = indexes[j], indexes[i] })
+	}
+
+	counts := allocateSample(keys, strata, n)
+
+	var selected []int
+	for _, key := range keys {
+		selected = append(selected, strata[key][:counts[key]]...)
+	}
+	slices.Sort(selected)
+
+	sample := make([]taskConfig, 0, len(selected))
+	for _, i := range selected {
+		sample = append(sample, tasks[i])
+	}
+	return sample
+}
+
+// allocateSample splits a sample of n tasks over the strata with the largest
+// remainder method, after giving each stratum one task if n allows it.
+func allocateSample(keys []string, strata map[string][]int, n int) map[string]int {
+	counts := make(map[string]int, len(keys))
+	available := make(map[string]int, len(keys))
+	total := 0
+	for _, key := range keys {
+		available[key] = len(strata[key])
+		total += len(strata[key])
+	}
+
+	if n >= len(keys) {
+		for _, key := range keys {
+			counts[key] = 1
+			available[key]--
+		}
+		n -= len(keys)
+		total -= len(keys)
+	}
+	if n == 0 || total == 0 {
+		return counts
+	}
+
+	type remainder struct {
+		key  string
+		frac float64
+	}
+	remainders := make([]remainder, 0, len(keys))
+	allocated := 0
+	for _, key := range keys {
+		share := float64(n) * float64(available[key]) / float64(total)
+		whole := int(share)
+		counts[key] += whole
+		allocated += whole
+		remainders = append(remainders, remainder{key: key, frac: share - float64(whole)})
+	}
+	slices.SortStableFunc(remainders, func(a, b remainder) int {
+		return cmp.Compare(b.frac, a.frac)
+	})
+	for i := 0; allocated < n; i = (i + 1) % len(remainders) {
+		if key := remainders[i].key; counts[key] < len(strata[key]) {
+			counts[key]++
+			allocated++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,141 @@
+package eval
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleConfigValidate(t *testing.T) {
+	tests := map[string]struct {
+		cfg         *SampleConfig
+		errContains string
+	}{
+		"nil config":      {},
+		"size":            {cfg: &SampleConfig{Size: 10}},
+		"percent":         {cfg: &SampleConfig{Percent: 12.5, By: SampleByNone}},
+		"by label":        {cfg: &SampleConfig{Size: 1, By: "label:suite"}},
+		"neither":         {cfg: &SampleConfig{}, errContains: "must be set"},
+		"both":            {cfg: &SampleConfig{Size: 1, Percent: 10}, errContains: "can't both be set"},
+		"negative size":   {cfg: &SampleConfig{Size: -1}, errContains: "must be positive"},
+		"percent too big": {cfg: &SampleConfig{Percent: 150}, errContains: "between 0 and 100"},
+		"unknown by":      {cfg: &SampleConfig{Size: 1, By: "suite"}, errContains: "invalid sample stratification"},
+		"label without key": {
+			cfg:         &SampleConfig{Size: 1, By: "label:"},
+			errContains: "invalid sample stratification",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+// sampleSuite returns 6 easy, 3 medium and 1 hard task, labelled with a
+// suite in turns
+func sampleSuite() []taskConfig {
+	var tasks []taskConfig
+	for i, difficulty := range []string{"easy", "easy", "easy", "easy", "easy", "easy", "medium", "medium", "medium", "hard"} {
+		tasks = append(tasks, taskConfig{
+			path: fmt.Sprintf("tasks/task-%d.yaml", i),
+			spec: &task.TaskConfig{Metadata: task.TaskMetadata{
+				Name:       fmt.Sprintf("task-%d", i),
+				Difficulty: difficulty,
+				Labels:     map[string]string{"suite": []string{"k8s", "helm"}[i%2]},
+			}},
+		})
+	}
+	return tasks
+}
+
+func countBy(tasks []taskConfig, key func(taskConfig) string) map[string]int {
+	counts := make(map[string]int)
+	for _, tc := range tasks {
+		counts[key(tc)]++
+	}
+	return counts
+}
+
+func TestSampleTasks(t *testing.T) {
+	difficulty := func(tc taskConfig) string { return tc.spec.Metadata.Difficulty }
+	suite := func(tc taskConfig) string { return tc.spec.Metadata.Labels["suite"] }
+
+	tests := map[string]struct {
+		cfg    *SampleConfig
+		key    func(taskConfig) string
+		expect map[string]int
+	}{
+		"every difficulty is represented": {
+			cfg:    &SampleConfig{Size: 3, By: SampleByDifficulty},
+			key:    difficulty,
+			expect: map[string]int{"easy": 1, "medium": 1, "hard": 1},
+		},
+		"proportional to the difficulties": {
+			cfg:    &SampleConfig{Size: 6, By: SampleByDifficulty},
+			key:    difficulty,
+			expect: map[string]int{"easy": 3, "medium": 2, "hard": 1},
+		},
+		"percent rounds up": {
+			cfg:    &SampleConfig{Percent: 35, By: SampleByDifficulty},
+			key:    difficulty,
+			expect: map[string]int{"easy": 2, "medium": 1, "hard": 1},
+		},
+		"smaller than the strata": {
+			cfg:    &SampleConfig{Size: 2, By: SampleByDifficulty},
+			key:    difficulty,
+			expect: map[string]int{"easy": 1, "medium": 1},
+		},
+		"by label": {
+			cfg:    &SampleConfig{Size: 4, By: "label:suite"},
+			key:    suite,
+			expect: map[string]int{"k8s": 2, "helm": 2},
+		},
+		"larger than the suite": {
+			cfg:    &SampleConfig{Size: 20, By: SampleByDifficulty},
+			key:    difficulty,
+			expect: map[string]int{"easy": 6, "medium": 3, "hard": 1},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			sample := sampleTasks(sampleSuite(), tc.cfg)
+			assert.Equal(t, tc.expect, countBy(sample, tc.key))
+		})
+	}
+}
+
+func TestSampleTasksSeed(t *testing.T) {
+	names := func(tasks []taskConfig) []string {
+		var names []string
+		for _, tc := range tasks {
+			names = append(names, tc.spec.Metadata.Name)
+		}
+		return names
+	}
+
+	first := names(sampleTasks(sampleSuite(), &SampleConfig{Size: 5, Seed: 42}))
+	again := names(sampleTasks(sampleSuite(), &SampleConfig{Size: 5, Seed: 42}))
+	assert.Equal(t, first, again, "the same seed must select the same tasks")
+	assert.IsIncreasing(t, first, "the sample must keep the order of the tasks")
+
+	differs := false
+	for seed := range uint64(10) {
+		if fmt.Sprint(names(sampleTasks(sampleSuite(), &SampleConfig{Size: 5, Seed: seed + 43}))) != fmt.Sprint(first) {
+			differs = true
+			break
+		}
+	}
+	assert.True(t, differs, "other seeds must select other tasks")
+}
@@ -0,0 +1,70 @@
+package eval
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Results sink types
+const (
+	ResultsSinkHTTP     = "http"
+	ResultsSinkBigQuery = "bigquery"
+)
+
+// ResultsSinkConfig streams a flat record of each task run to a data
+// warehouse as the run progresses, so results can be analyzed without
+// processing results files.
+type ResultsSinkConfig struct {
+	// Type is "http", to POST records as NDJSON to URL, or "bigquery", to
+	// stream them into a BigQuery table
+	Type string `json:"type"`
+
+	// URL is the bulk endpoint of http sinks
+	URL string `json:"url,omitempty"`
+
+	// Headers are added to the requests of http sinks, e.g. for
+	// authentication with a secretRef
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Project, Dataset and Table identify the table of bigquery sinks, which
+	// is created if it doesn't exist
+	Project string `json:"project,omitempty"`
+	Dataset string `json:"dataset,omitempty"`
+	Table   string `json:"table,omitempty"`
+
+	// BatchSize is the number of records sent at once (default: 50). The
+	// remaining records are sent when the run completes.
+	BatchSize int `json:"batchSize,omitempty"`
+}
+
+// Validate checks that the sink has a known type and the settings it needs.
+func (c *ResultsSinkConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.BatchSize < 0 {
+		return fmt.Errorf("batchSize must be >= 0, got %d", c.BatchSize)
+	}
+
+	switch c.Type {
+	case ResultsSinkHTTP:
+		u, err := url.Parse(c.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("http sinks require an http or https url, got %q", c.URL)
+		}
+	case ResultsSinkBigQuery:
+		if c.Project == "" || c.Dataset == "" || c.Table == "" {
+			return fmt.Errorf("bigquery sinks require project, dataset and table")
+		}
+	default:
+		return fmt.Errorf("unknown type %q, must be %s or %s", c.Type, ResultsSinkHTTP, ResultsSinkBigQuery)
+	}
+	return nil
+}
+
+// ResultsSink receives the entries of the journal of a run as the run
+// progresses: the summary when it starts, each result when it completes, and
+// the completion of the run. It must be safe for concurrent use.
+type ResultsSink interface {
+	Write(entry JournalEntry) error
+}
@@ -0,0 +1,105 @@
+package eval
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultsSinkConfigValidate(t *testing.T) {
+	tests := map[string]struct {
+		config      *ResultsSinkConfig
+		errContains string
+	}{
+		"not set": {},
+		"http": {
+			config: &ResultsSinkConfig{Type: ResultsSinkHTTP, URL: "https://warehouse.example.com/ingest", BatchSize: 10},
+		},
+		"http without url": {
+			config:      &ResultsSinkConfig{Type: ResultsSinkHTTP},
+			errContains: "http sinks require an http or https url",
+		},
+		"http with another scheme": {
+			config:      &ResultsSinkConfig{Type: ResultsSinkHTTP, URL: "ftp://warehouse.example.com"},
+			errContains: "http sinks require an http or https url",
+		},
+		"bigquery": {
+			config: &ResultsSinkConfig{Type: ResultsSinkBigQuery, Project: "data", Dataset: "evals", Table: "runs"},
+		},
+		"bigquery without table": {
+			config:      &ResultsSinkConfig{Type: ResultsSinkBigQuery, Project: "data", Dataset: "evals"},
+			errContains: "bigquery sinks require project, dataset and table",
+		},
+		"unknown type": {
+			config:      &ResultsSinkConfig{Type: "jdbc"},
+			errContains: `unknown type "jdbc"`,
+		},
+		"negative batch size": {
+			config:      &ResultsSinkConfig{Type: ResultsSinkHTTP, URL: "https://warehouse.example.com", BatchSize: -1},
+			errContains: "batchSize must be >= 0",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+type failingSink struct{}
+
+func (failingSink) Write(JournalEntry) error {
+	return errors.New("connection refused")
+}
+
+type recordingSink struct {
+	mu      sync.Mutex
+	entries []JournalEntry
+}
+
+func (s *recordingSink) Write(entry JournalEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func TestWriteJournalSendsToResultsSink(t *testing.T) {
+	sink := &recordingSink{}
+	runner := &evalRunner{resultsSink: sink}
+
+	runner.writeJournal(JournalEntry{Type: JournalStart, Summary: &EvalSummary{}})
+	runner.writeJournal(JournalEntry{Type: JournalResult, Result: &EvalResult{TaskName: "task"}})
+	runner.writeJournal(JournalEntry{Type: JournalComplete})
+
+	require.Len(t, sink.entries, 3)
+	assert.Equal(t, JournalStart, sink.entries[0].Type)
+	assert.Equal(t, "task", sink.entries[1].Result.TaskName)
+	assert.Equal(t, JournalComplete, sink.entries[2].Type)
+	for _, entry := range sink.entries {
+		assert.False(t, entry.Time.IsZero(), "entries are timestamped")
+	}
+}
+
+func TestWriteJournalWarnsOnResultsSinkError(t *testing.T) {
+	var events []ProgressEvent
+	runner := &evalRunner{
+		progressCallback: func(e ProgressEvent) { events = append(events, e) },
+		resultsSink:      failingSink{},
+	}
+
+	runner.writeJournal(JournalEntry{Type: JournalComplete})
+	require.Len(t, events, 1)
+	assert.Equal(t, EventWarning, events[0].Type)
+	assert.Equal(t, "results sink: connection refused", events[0].Message)
+}
@@ -0,0 +1,142 @@
+package eval
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+// SkipReason is why a task run was skipped instead of run.
+type SkipReason string
+
+const (
+	// SkipReasonRequirementsUnmet is used when the eval config lacks an MCP server or extension the task requires
+	SkipReasonRequirementsUnmet SkipReason = "requirementsUnmet"
+	// SkipReasonBudgetExceeded is used when the run budget was exceeded before the run started
+	SkipReasonBudgetExceeded SkipReason = "budgetExceeded"
+	// SkipReasonLocaleUnavailable is used when the task has a prompt per locale but none for the selected locale
+	SkipReasonLocaleUnavailable SkipReason = "localeUnavailable"
+	// SkipReasonAborted is used when the run was aborted after too many failures before or while the task ran
+	SkipReasonAborted SkipReason = "aborted"
+)
+
+// CountsAsNotPassed reports whether runs skipped for this reason are counted
+// as not passed in pass rates. Runs skipped over budget were meant to run, so
+// they are; all other skipped runs are left out of pass rates.
+func (r SkipReason) CountsAsNotPassed() bool {
+	return r == SkipReasonBudgetExceeded
+}
+
+// newSkippedResult returns the result of a run of tc that was skipped for reason.
+func newSkippedResult(tc taskConfig, reason SkipReason, message string) *EvalResult {
+	return &EvalResult{
+		TaskID:      tc.spec.Metadata.ID,
+		TaskName:    tc.spec.Metadata.Name,
+		TaskPath:    tc.path,
+		Difficulty:  tc.spec.Metadata.Difficulty,
+		State:       resultState(tc),
+		Parallel:    tc.spec.Metadata.Parallel,
+		TaskPassed:  false,
+		TaskError:   "skipped: " + message,
+		Skipped:     true,
+		SkipReason:  reason,
+		SkipMessage: message,
+		Locale:      tc.locale,
+		Environment: tc.environment,
+		Labels:      tc.spec.Metadata.Labels,
+		Parameters:  tc.spec.Parameters(),
+
+		ExpectFailure:       tc.spec.Metadata.ExpectFailure,
+		ExpectFailureReason: tc.spec.Metadata.ExpectFailureReason,
+	}
+}
+
+// skipTask records every run of tc as skipped for reason, without running it.
+func (r *evalRunner) skipTask(tc taskConfig, reason SkipReason, message string) []*EvalResult {
+	runs := r.getRunsForTask(tc)
+	results := make([]*EvalResult, 0, runs)
+
+	for runIdx := 0; runIdx < runs; runIdx++ {
+		result := newSkippedResult(tc, reason, message)
+		result.RunIndex = runIdx
+		result.TotalRuns = runs
+		r.progressCallback(ProgressEvent{
+			Type:    EventTaskSkipped,
+			Message: fmt.Sprintf("Skipping task: %s (%s)", tc.spec.Metadata.Name, message),
+			Task:    result,
+		})
+		r.writeJournal(JournalEntry{Type: JournalResult, Result: result})
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// sortResultsByTask orders results like tasks, the tasks they are the results
+// of, so that the results of skipped tasks are among those of the tasks that
+// ran. The results of each task keep their order.
+func sortResultsByTask(results []*EvalResult, tasks []taskConfig) {
+	position := make(map[string]int, len(tasks))
+	for i, tc := range tasks {
+		key := taskResultKey(tc.path, tc.spec.Metadata.Name, tc.allowedTools, tc.promptVariant, tc.environment)
+		if _, ok := position[key]; !ok {
+			position[key] = i
+		}
+	}
+
+	slices.SortStableFunc(results, func(a, b *EvalResult) int {
+		return position[resultTaskKey(a)] - position[resultTaskKey(b)]
+	})
+}
+
+// resultTaskKey identifies the task of a result like taskResultKey. The locale
+// is left out, as it is only set on tasks once they are localized.
+func resultTaskKey(result *EvalResult) string {
+	return taskResultKey(result.TaskPath, result.TaskName, result.AllowedToolsMode, result.PromptVariant, result.Environment)
+}
+
+func taskResultKey(path, name string, allowedTools AllowedToolsMode, promptVariant int, environment string) string {
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%d\x00%s", path, name, allowedTools, promptVariant, environment)
+}
+
+// unmetTask is a task that requires extensions or MCP servers that the eval
+// config does not provide.
+type unmetTask struct {
+	tc      taskConfig
+	missing []string
+}
+
+// partitionUnmetRequirements separates the tasks whose requirements are
+// provided by deps, or by the environment they run in, from those that can't
+// run.
+func partitionUnmetRequirements(tasks []taskConfig, deps *steps.Dependencies, environments map[string]*environment) ([]taskConfig, []unmetTask) {
+	runnable := make([]taskConfig, 0, len(tasks))
+	var unmet []unmetTask
+
+	for _, tc := range tasks {
+		taskDeps := deps
+		if env := environments[tc.environment]; env != nil {
+			taskDeps = env.deps
+		}
+		if missing := task.MissingRequirements(tc.spec, taskDeps); len(missing) > 0 {
+			unmet = append(unmet, unmetTask{tc: tc, missing: missing})
+			continue
+		}
+		runnable = append(runnable, tc)
+	}
+
+	return runnable, unmet
+}
+
+// unmetRequirementsError lists the tasks with unmet requirements, for runs
+// with strict requirements.
+func unmetRequirementsError(unmet []unmetTask) error {
+	lines := make([]string, 0, len(unmet))
+	for _, u := range unmet {
+		lines = append(lines, fmt.Sprintf("  %s: missing %s", u.tc.spec.Metadata.Name, strings.Join(u.missing, ", ")))
+	}
+	return fmt.Errorf("%d task(s) require extensions or MCP servers that are not in the eval config:\n%s", len(unmet), strings.Join(lines, "\n"))
+}
@@ -0,0 +1,116 @@
+package eval
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionUnmetRequirements(t *testing.T) {
+	makeTask := func(name string, requires ...task.Requirements) taskConfig {
+		return taskConfig{
+			path: name + ".yaml",
+			spec: &task.TaskConfig{
+				Metadata: task.TaskMetadata{Name: name},
+				Spec:     &task.TaskSpec{Requires: requires},
+			},
+		}
+	}
+	extension := func(name string) task.Requirements { return task.Requirements{Extension: &name} }
+	mcpServer := func(name string) task.Requirements { return task.Requirements{McpServer: &name} }
+
+	deps := setupTestDeps()
+	deps.Extensions.(*fakeExtensionManager).extensions["kubernetes"] = &fakeExtensionClient{}
+
+	tasks := []taskConfig{
+		makeTask("no-requirements"),
+		makeTask("registered-extension", extension("kubernetes")),
+		makeTask("missing-server", mcpServer("github")),
+		makeTask("missing-both", extension("helm"), mcpServer("github")),
+	}
+
+	runnable, unmet := partitionUnmetRequirements(tasks, deps, nil)
+	require.Len(t, runnable, 2)
+	assert.Equal(t, "no-requirements", runnable[0].spec.Metadata.Name)
+	assert.Equal(t, "registered-extension", runnable[1].spec.Metadata.Name)
+
+	require.Len(t, unmet, 2)
+	assert.Equal(t, []string{`mcpServer "github"`}, unmet[0].missing)
+	assert.Equal(t, []string{`extension "helm"`, `mcpServer "github"`}, unmet[1].missing)
+
+	err := unmetRequirementsError(unmet)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 task(s) require")
+	assert.Contains(t, err.Error(), `missing-both: missing extension "helm", mcpServer "github"`)
+}
+
+func TestSkipTask(t *testing.T) {
+	var events []ProgressEvent
+	runner := &evalRunner{
+		spec:              &EvalSpec{},
+		runs:              2,
+		runsExplicitlySet: true,
+		progressCallback:  func(e ProgressEvent) { events = append(events, e) },
+	}
+	tc := taskConfig{
+		path: "task.yaml",
+		spec: &task.TaskConfig{Metadata: task.TaskMetadata{Name: "needs-github", State: task.StateQuarantined}},
+	}
+
+	results := runner.skipTask(tc, SkipReasonRequirementsUnmet, `missing mcpServer "github"`)
+
+	require.Len(t, results, 2)
+	for i, result := range results {
+		assert.True(t, result.Skipped)
+		assert.False(t, result.TaskPassed)
+		assert.False(t, result.SkippedOverBudget)
+		assert.Equal(t, SkipReasonRequirementsUnmet, result.SkipReason)
+		assert.Equal(t, `missing mcpServer "github"`, result.SkipMessage)
+		assert.Equal(t, `skipped: missing mcpServer "github"`, result.TaskError)
+		assert.Equal(t, task.StateQuarantined, result.State)
+		assert.Equal(t, i, result.RunIndex)
+		assert.Equal(t, 2, result.TotalRuns)
+	}
+
+	require.Len(t, events, 2)
+	assert.Equal(t, EventTaskSkipped, events[0].Type)
+}
+
+func TestSkipReasonCountsAsNotPassed(t *testing.T) {
+	assert.True(t, SkipReasonBudgetExceeded.CountsAsNotPassed())
+	assert.False(t, SkipReasonRequirementsUnmet.CountsAsNotPassed())
+	assert.False(t, SkipReasonLocaleUnavailable.CountsAsNotPassed())
+	assert.False(t, SkipReasonAborted.CountsAsNotPassed())
+}
+
+func TestSortResultsByTask(t *testing.T) {
+	makeTask := func(name string) taskConfig {
+		return taskConfig{
+			path: name + ".yaml",
+			spec: &task.TaskConfig{Metadata: task.TaskMetadata{Name: name}},
+		}
+	}
+	tasks := []taskConfig{makeTask("first"), makeTask("skipped"), makeTask("last")}
+
+	result := func(name string, runIndex int, skipped bool) *EvalResult {
+		return &EvalResult{TaskPath: name + ".yaml", TaskName: name, RunIndex: runIndex, Skipped: skipped}
+	}
+	results := []*EvalResult{
+		result("skipped", 0, true),
+		result("skipped", 1, true),
+		result("last", 0, false),
+		result("first", 0, false),
+		result("first", 1, false),
+	}
+
+	sortResultsByTask(results, tasks)
+
+	var order []string
+	for _, r := range results {
+		order = append(order, fmt.Sprintf("%s/%d", r.TaskName, r.RunIndex))
+	}
+	assert.Equal(t, []string{"first/0", "first/1", "skipped/0", "skipped/1", "last/0"}, order)
+}
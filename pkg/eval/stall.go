@@ -0,0 +1,90 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// stallCheckInterval is how often the watchdog checks for progress, as a
+// fraction of the stall timeout
+const stallCheckInterval = 10
+
+// stallWatchdog stops the agent of a task run once it has made no progress
+// for the stall timeout, after capturing diagnostics of its processes.
+type stallWatchdog struct {
+	activity *util.Activity
+	timeout  time.Duration
+	cancel   context.CancelFunc
+	done     chan struct{}
+	stopOnce sync.Once
+
+	// stalled and diagnostics are set before done is closed
+	stalled     bool
+	diagnostics string
+}
+
+// watchForStall returns the context to run the agent of a task run with,
+// which is cancelled once the agent makes no progress for timeout, and the
+// watchdog whose activity the agent must record its progress in. A zero
+// timeout disables the watchdog.
+func watchForStall(ctx context.Context, timeout time.Duration) (context.Context, *stallWatchdog) {
+	if timeout <= 0 {
+		return ctx, nil
+	}
+
+	w := &stallWatchdog{
+		activity: util.NewActivity(),
+		timeout:  timeout,
+		done:     make(chan struct{}),
+	}
+	ctx, w.cancel = context.WithCancel(ctx)
+	go w.watch(ctx)
+	return ctx, w
+}
+
+func (w *stallWatchdog) watch(ctx context.Context) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(max(w.timeout/stallCheckInterval, time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			idle := w.activity.Idle()
+			if idle < w.timeout {
+				continue
+			}
+			w.stalled = true
+			w.diagnostics = fmt.Sprintf("no agent progress for %s\n\n%s", idle.Round(time.Second), util.ProcessDiagnostics(ctx, w.activity.Processes()))
+			util.DebugDirFromContext(ctx).Sub("agent").WriteFile("stall-diagnostics.txt", []byte(w.diagnostics))
+			w.cancel()
+			return
+		}
+	}
+}
+
+// agentActivity returns the activity the agent records its progress in, or
+// nil if the watchdog is disabled.
+func (w *stallWatchdog) agentActivity() *util.Activity {
+	if w == nil {
+		return nil
+	}
+	return w.activity
+}
+
+// stop stops watching the agent and reports whether it stalled. It is safe
+// to call on a nil watchdog.
+func (w *stallWatchdog) stop() bool {
+	if w == nil {
+		return false
+	}
+	w.stopOnce.Do(w.cancel)
+	<-w.done
+	return w.stalled
+}
@@ -0,0 +1,152 @@
+package eval
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// progressingAgentRunner reports progress every interval until delay elapses.
+type progressingAgentRunner struct {
+	fakeAgentRunner
+	interval time.Duration
+	activity *util.Activity
+}
+
+func (f *progressingAgentRunner) RunTask(ctx context.Context, prompt string) (agent.AgentResult, error) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	deadline := time.After(f.delay)
+	for {
+		select {
+		case <-ticker.C:
+			f.activity.Touch()
+		case <-deadline:
+			return &fakeAgentResult{}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (f *progressingAgentRunner) WithMcpServerInfo(_ mcpproxy.ServerManager) agent.Runner {
+	return f
+}
+
+func (f *progressingAgentRunner) WithActivity(activity *util.Activity) agent.Runner {
+	clone := *f
+	clone.activity = activity
+	return &clone
+}
+
+func TestRunTaskStall(t *testing.T) {
+	tests := map[string]struct {
+		agent       agent.Runner
+		limits      *util.Limits
+		cliTimeout  string
+		wantStalled bool
+	}{
+		"silent agent stalls": {
+			agent:       &fakeAgentRunner{delay: 10 * time.Second},
+			limits:      &util.Limits{StallTimeout: "100ms"},
+			wantStalled: true,
+		},
+		"progressing agent completes": {
+			agent:  &progressingAgentRunner{fakeAgentRunner: fakeAgentRunner{delay: 300 * time.Millisecond}, interval: 20 * time.Millisecond},
+			limits: &util.Limits{StallTimeout: "100ms"},
+		},
+		"cli overrides task": {
+			agent:       &fakeAgentRunner{delay: 10 * time.Second},
+			limits:      &util.Limits{StallTimeout: "1h"},
+			cliTimeout:  "100ms",
+			wantStalled: true,
+		},
+		"no stall timeout": {
+			agent: &fakeAgentRunner{delay: 200 * time.Millisecond},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var events []ProgressEventType
+			runner := &evalRunner{
+				spec:             &EvalSpec{Config: EvalConfig{}},
+				stallTimeout:     tc.cliTimeout,
+				progressCallback: func(e ProgressEvent) { events = append(events, e.Type) },
+				deps:             setupTestDeps(),
+			}
+			taskCfg := taskConfig{
+				path: "test.yaml",
+				spec: &task.TaskConfig{
+					Metadata: task.TaskMetadata{Name: "stall-test"},
+					Spec: &task.TaskSpec{
+						Limits: tc.limits,
+						Prompt: &task.Prompt{Step: util.Step{Inline: "do something"}},
+					},
+				},
+			}
+
+			start := time.Now()
+			result, err := runner.runTask(context.Background(), tc.agent, taskCfg)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.wantStalled, result.Stalled)
+			if tc.wantStalled {
+				assert.Less(t, time.Since(start), 5*time.Second, "expected the agent to be stopped")
+				assert.False(t, result.TaskPassed)
+				assert.Equal(t, task.ErrorKindAgent, result.ErrorKind)
+				assert.Contains(t, result.TaskError, "agent made no progress for 100ms")
+				assert.Contains(t, result.StallDiagnostics, "no agent progress for")
+				assert.Contains(t, events, EventTaskStalled)
+				assert.NotNil(t, result.CleanupOutput, "cleanup should run after a stall")
+				assert.Nil(t, result.VerifyOutput, "verification should be skipped after a stall")
+			} else {
+				assert.True(t, result.TaskPassed)
+				assert.NotContains(t, events, EventTaskStalled)
+			}
+		})
+	}
+}
+
+func TestResolveStallTimeout(t *testing.T) {
+	tests := map[string]struct {
+		cli      string
+		task     *util.Limits
+		defaults *util.Limits
+		want     time.Duration
+		wantErr  string
+	}{
+		"unset":             {},
+		"eval default":      {defaults: &util.Limits{StallTimeout: "5m"}, want: 5 * time.Minute},
+		"task":              {task: &util.Limits{StallTimeout: "2m"}, defaults: &util.Limits{StallTimeout: "5m"}, want: 2 * time.Minute},
+		"cli":               {cli: "30s", task: &util.Limits{StallTimeout: "2m"}, want: 30 * time.Second},
+		"invalid cli":       {cli: "soon", wantErr: "invalid --stall-timeout"},
+		"negative task":     {task: &util.Limits{StallTimeout: "-1m"}, wantErr: "invalid stallTimeout"},
+		"task timeout only": {task: &util.Limits{Timeout: "10m"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			runner := &evalRunner{
+				spec:         &EvalSpec{Config: EvalConfig{DefaultTaskLimits: tc.defaults}},
+				stallTimeout: tc.cli,
+			}
+			taskCfg := taskConfig{spec: &task.TaskConfig{Spec: &task.TaskSpec{Limits: tc.task}}}
+
+			got, err := runner.resolveStallTimeout(taskCfg)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
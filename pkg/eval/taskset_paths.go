@@ -0,0 +1,147 @@
+package eval
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// taskFileExts are the file extensions of task files found by recursive task sets
+var taskFileExts = []string{".yaml", ".yml"}
+
+// matchPaths returns the candidate task files of the task set, in lexical order,
+// without the files matched by its Exclude globs.
+func (ts *TaskSet) matchPaths() ([]string, error) {
+	var paths []string
+	var err error
+
+	switch {
+	case ts.Glob != "" && ts.Recursive:
+		paths, err = globRecursive(ts.Glob)
+	case ts.Glob != "":
+		paths, err = filepath.Glob(ts.Glob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to glob %s: %w", ts.Glob, err)
+		}
+	case ts.Path != "" && ts.Recursive:
+		paths, err = walkTaskFiles(ts.Path, func(name string) bool {
+			return slices.Contains(taskFileExts, strings.ToLower(filepath.Ext(name)))
+		})
+	case ts.Path != "":
+		paths = []string{ts.Path}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ts.Exclude) == 0 {
+		return paths, nil
+	}
+
+	included := paths[:0]
+	for _, path := range paths {
+		if !matchesPathOrParent(path, ts.Exclude) {
+			included = append(included, path)
+		}
+	}
+	return included, nil
+}
+
+// globRecursive matches the file name pattern of glob (its last element) against
+// files at any depth below the directories matched by the rest of glob. For
+// example, "tasks/*.yaml" matches tasks/a.yaml and tasks/k8s/pods/b.yaml.
+func globRecursive(glob string) ([]string, error) {
+	dirPattern, filePattern := filepath.Split(glob)
+	if dirPattern == "" {
+		dirPattern = "."
+	}
+
+	dirs, err := filepath.Glob(filepath.Clean(dirPattern))
+	if err != nil {
+		return nil, fmt.Errorf("failed to glob %s: %w", glob, err)
+	}
+
+	var paths []string
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		found, err := walkTaskFiles(dir, func(name string) bool {
+			ok, _ := filepath.Match(filePattern, name)
+			return ok
+		})
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, found...)
+	}
+	return paths, nil
+}
+
+// walkTaskFiles returns the files below root whose name satisfies match. Hidden
+// directories (such as .git) are not descended into.
+func walkTaskFiles(root string, match func(name string) bool) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("recursive task set path %s is not a directory", root)
+	}
+
+	var paths []string
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if match(d.Name()) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+	return paths, nil
+}
+
+// matchesPathOrParent reports whether path, or one of its parent directories,
+// matches one of the glob patterns. Relative paths and patterns are resolved
+// against the current directory.
+func matchesPathOrParent(path string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+	absPatterns := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		if absPattern, err := filepath.Abs(pattern); err == nil {
+			pattern = absPattern
+		}
+		absPatterns = append(absPatterns, pattern)
+	}
+
+	for p := absPath; ; p = filepath.Dir(p) {
+		for _, pattern := range absPatterns {
+			if ok, _ := filepath.Match(pattern, p); ok {
+				return true
+			}
+		}
+		if parent := filepath.Dir(p); parent == p {
+			return false
+		}
+	}
+}
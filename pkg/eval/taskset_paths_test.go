@@ -0,0 +1,112 @@
+package eval
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskSetMatchPaths(t *testing.T) {
+	root := t.TempDir()
+	files := []string{
+		"tasks/a.yaml",
+		"tasks/notes.md",
+		"tasks/k8s/b.yaml",
+		"tasks/k8s/pods/c.yml",
+		"tasks/k8s/pods/task.yaml",
+		"tasks/helm/task.yaml",
+		"tasks/broken/d.yaml",
+		"tasks/.hidden/e.yaml",
+	}
+	for _, f := range files {
+		path := filepath.Join(root, f)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+	}
+
+	tests := map[string]struct {
+		taskSet     TaskSet
+		expected    []string
+		errContains string
+	}{
+		"glob is single level": {
+			taskSet:  TaskSet{Glob: "tasks/*.yaml"},
+			expected: []string{"tasks/a.yaml"},
+		},
+		"recursive glob walks subdirectories": {
+			taskSet: TaskSet{Glob: "tasks/*.yaml", Recursive: true},
+			expected: []string{
+				"tasks/a.yaml",
+				"tasks/broken/d.yaml",
+				"tasks/helm/task.yaml",
+				"tasks/k8s/b.yaml",
+				"tasks/k8s/pods/task.yaml",
+			},
+		},
+		"recursive glob with directory pattern": {
+			taskSet:  TaskSet{Glob: "tasks/k*/task.yaml", Recursive: true},
+			expected: []string{"tasks/k8s/pods/task.yaml"},
+		},
+		"recursive path finds all yaml files": {
+			taskSet: TaskSet{Path: "tasks/k8s", Recursive: true},
+			expected: []string{
+				"tasks/k8s/b.yaml",
+				"tasks/k8s/pods/c.yml",
+				"tasks/k8s/pods/task.yaml",
+			},
+		},
+		"exclude file glob and directory": {
+			taskSet: TaskSet{
+				Glob:      "tasks/*.yaml",
+				Recursive: true,
+				Exclude:   []string{"tasks/broken", "tasks/*/task.yaml"},
+			},
+			expected: []string{
+				"tasks/a.yaml",
+				"tasks/k8s/b.yaml",
+				"tasks/k8s/pods/task.yaml",
+			},
+		},
+		"exclude applies to path": {
+			taskSet: TaskSet{Path: "tasks/a.yaml", Exclude: []string{"tasks/a.yaml"}},
+		},
+		"recursive path must be a directory": {
+			taskSet:     TaskSet{Path: "tasks/a.yaml", Recursive: true},
+			errContains: "is not a directory",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ts := tc.taskSet
+			if ts.Glob != "" {
+				ts.Glob = filepath.Join(root, ts.Glob)
+			}
+			if ts.Path != "" {
+				ts.Path = filepath.Join(root, ts.Path)
+			}
+			for i := range ts.Exclude {
+				ts.Exclude[i] = filepath.Join(root, ts.Exclude[i])
+			}
+
+			paths, err := ts.matchPaths()
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+
+			var relPaths []string
+			for _, p := range paths {
+				rel, err := filepath.Rel(root, p)
+				require.NoError(t, err)
+				relPaths = append(relPaths, filepath.ToSlash(rel))
+			}
+			assert.Equal(t, tc.expected, relPaths)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package eval
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// compileURITemplate converts a URI template into an anchored regular expression.
+// Templates support the following placeholders, everything else matches literally:
+//
+//	{name}   one path segment (no "/", "?" or "#"), as in RFC 6570 simple expansion
+//	{+name}  any characters, including "/", as in RFC 6570 reserved expansion
+//	*        any characters within a path segment
+//	**       any characters, including "/"
+//
+// For example, "k8s://pods/{namespace}/*" matches "k8s://pods/default/nginx-7d9f".
+func compileURITemplate(template string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+
+	for i := 0; i < len(template); {
+		switch {
+		case template[i] == '{':
+			end := strings.IndexByte(template[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid uri template %q: unclosed '{'", template)
+			}
+			name := template[i+1 : i+end]
+			if reserved, ok := strings.CutPrefix(name, "+"); ok {
+				name = reserved
+				b.WriteString(".*")
+			} else {
+				b.WriteString("[^/?#]+")
+			}
+			if name == "" {
+				return nil, fmt.Errorf("invalid uri template %q: empty variable name", template)
+			}
+			i += end + 1
+		case strings.HasPrefix(template[i:], "**"):
+			b.WriteString(".*")
+			i += 2
+		case template[i] == '*':
+			b.WriteString("[^/]*")
+			i++
+		default:
+			next := strings.IndexAny(template[i:], "{*")
+			if next < 0 {
+				next = len(template) - i
+			}
+			b.WriteString(regexp.QuoteMeta(template[i : i+next]))
+			i += next
+		}
+	}
+
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// matchesURITemplate reports whether uri matches the URI template. Invalid
+// templates match nothing.
+func matchesURITemplate(template, uri string) bool {
+	rx, err := compileURITemplate(template)
+	if err != nil {
+		return false
+	}
+	return rx.MatchString(uri)
+}
+
+// matchesWildcard reports whether value matches pattern, where "*" in pattern
+// matches any characters and everything else matches literally.
+func matchesWildcard(pattern, value string) bool {
+	if !strings.Contains(pattern, "*") {
+		return pattern == value
+	}
+
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	matched, _ := regexp.MatchString("^"+strings.Join(parts, ".*")+"$", value)
+	return matched
+}
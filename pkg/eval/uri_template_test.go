@@ -0,0 +1,102 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesURITemplate(t *testing.T) {
+	tests := map[string]struct {
+		template string
+		uri      string
+		want     bool
+	}{
+		"literal": {
+			template: "file:///etc/config.yaml",
+			uri:      "file:///etc/config.yaml",
+			want:     true,
+		},
+		"literal is anchored": {
+			template: "file:///etc/config",
+			uri:      "file:///etc/config.yaml",
+			want:     false,
+		},
+		"regex characters are literal": {
+			template: "file:///etc/config.yaml",
+			uri:      "file:///etc/configXyaml",
+			want:     false,
+		},
+		"wildcard matches segment": {
+			template: "k8s://pods/*",
+			uri:      "k8s://pods/nginx-7d9f8b",
+			want:     true,
+		},
+		"wildcard does not cross segments": {
+			template: "k8s://pods/*",
+			uri:      "k8s://pods/default/nginx",
+			want:     false,
+		},
+		"wildcard within segment": {
+			template: "k8s://pods/nginx-*",
+			uri:      "k8s://pods/nginx-7d9f8b",
+			want:     true,
+		},
+		"double wildcard crosses segments": {
+			template: "k8s://**/logs",
+			uri:      "k8s://namespaces/default/pods/nginx/logs",
+			want:     true,
+		},
+		"variable matches segment": {
+			template: "k8s://namespaces/{namespace}/pods/{name}",
+			uri:      "k8s://namespaces/default/pods/nginx",
+			want:     true,
+		},
+		"variable does not match empty segment": {
+			template: "k8s://namespaces/{namespace}/pods",
+			uri:      "k8s://namespaces//pods",
+			want:     false,
+		},
+		"variable does not cross segments": {
+			template: "k8s://pods/{name}",
+			uri:      "k8s://pods/default/nginx",
+			want:     false,
+		},
+		"reserved variable crosses segments": {
+			template: "file:///{+path}",
+			uri:      "file:///home/user/notes.txt",
+			want:     true,
+		},
+		"invalid template matches nothing": {
+			template: "k8s://pods/{name",
+			uri:      "k8s://pods/{name",
+			want:     false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, matchesURITemplate(tc.template, tc.uri))
+		})
+	}
+}
+
+func TestCompileURITemplateErrors(t *testing.T) {
+	_, err := compileURITemplate("k8s://pods/{name")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unclosed")
+
+	_, err = compileURITemplate("k8s://pods/{}")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "empty variable name")
+}
+
+func TestMatchesWildcard(t *testing.T) {
+	assert.True(t, matchesWildcard("default", "default"))
+	assert.False(t, matchesWildcard("default", "default-2"))
+	assert.True(t, matchesWildcard("test-*", "test-abc123"))
+	assert.True(t, matchesWildcard("*", ""))
+	assert.True(t, matchesWildcard("a*c.d", "abbbc.d"))
+	assert.False(t, matchesWildcard("a*c.d", "abbbcxd"))
+}
@@ -0,0 +1,78 @@
+package eval
+
+import "sync"
+
+// UsageUpdate reports the agent and judge tokens of a completed task run, and
+// of all the task runs of the eval completed so far.
+type UsageUpdate struct {
+	InputTokens  int64
+	OutputTokens int64
+
+	TotalInputTokens  int64
+	TotalOutputTokens int64
+
+	// CostUSD and TotalCostUSD are the estimated costs of the tokens, which
+	// are only set if Priced is
+	CostUSD      float64
+	TotalCostUSD float64
+	Priced       bool
+}
+
+// Tokens returns the input and output tokens of the task run.
+func (u *UsageUpdate) Tokens() int64 {
+	return u.InputTokens + u.OutputTokens
+}
+
+// TotalTokens returns the input and output tokens of the eval so far.
+func (u *UsageUpdate) TotalTokens() int64 {
+	return u.TotalInputTokens + u.TotalOutputTokens
+}
+
+// usageTracker accumulates the usage of the task runs of an eval as they
+// complete, for progress events. It is safe for concurrent use, and a nil
+// tracker reports no usage.
+type usageTracker struct {
+	pricing *TokenPricing
+
+	mu           sync.Mutex
+	inputTokens  int64
+	outputTokens int64
+}
+
+// newUsageTracker returns a usage tracker that estimates costs with the
+// pricing of the run budget, if it has one.
+func newUsageTracker(budget *BudgetConfig) *usageTracker {
+	u := &usageTracker{}
+	if budget != nil {
+		u.pricing = budget.Pricing
+	}
+	return u
+}
+
+// record adds the usage of a completed run, and returns it with the usage
+// of the eval so far.
+func (u *usageTracker) record(result *EvalResult) *UsageUpdate {
+	if u == nil || result == nil {
+		return nil
+	}
+
+	input, output := resultTokens(result)
+
+	u.mu.Lock()
+	u.inputTokens += input
+	u.outputTokens += output
+	update := &UsageUpdate{
+		InputTokens:       input,
+		OutputTokens:      output,
+		TotalInputTokens:  u.inputTokens,
+		TotalOutputTokens: u.outputTokens,
+	}
+	u.mu.Unlock()
+
+	if u.pricing != nil {
+		update.Priced = true
+		update.CostUSD = u.pricing.cost(input, output)
+		update.TotalCostUSD = u.pricing.cost(update.TotalInputTokens, update.TotalOutputTokens)
+	}
+	return update
+}
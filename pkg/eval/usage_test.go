@@ -0,0 +1,82 @@
+package eval
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageTrackerRecord(t *testing.T) {
+	tests := map[string]struct {
+		budget *BudgetConfig
+		want   []*UsageUpdate
+	}{
+		"without pricing": {
+			want: []*UsageUpdate{
+				{InputTokens: 1000, OutputTokens: 200, TotalInputTokens: 1000, TotalOutputTokens: 200},
+				{InputTokens: 3000, OutputTokens: 500, TotalInputTokens: 4000, TotalOutputTokens: 700},
+			},
+		},
+		"with pricing": {
+			budget: &BudgetConfig{Pricing: &TokenPricing{InputPerMillionTokens: 3, OutputPerMillionTokens: 15}},
+			want: []*UsageUpdate{
+				{InputTokens: 1000, OutputTokens: 200, TotalInputTokens: 1000, TotalOutputTokens: 200, CostUSD: 0.006, TotalCostUSD: 0.006, Priced: true},
+				{InputTokens: 3000, OutputTokens: 500, TotalInputTokens: 4000, TotalOutputTokens: 700, CostUSD: 0.0165, TotalCostUSD: 0.0225, Priced: true},
+			},
+		},
+	}
+
+	runs := []*EvalResult{
+		{TokenEstimate: &tokens.Estimate{InputTokens: 1000, OutputTokens: 200}},
+		{
+			// Usage reported by the agent is preferred over the estimate
+			TokenEstimate: &tokens.Estimate{
+				InputTokens: 9999, OutputTokens: 9999,
+				Source: tokens.SourceActual,
+				Actual: &tokens.Usage{InputTokens: 2500, OutputTokens: 400},
+			},
+			JudgeTokenUsage: &tokens.Usage{InputTokens: 500, OutputTokens: 100},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			u := newUsageTracker(tc.budget)
+			for i, result := range runs {
+				got := u.record(result)
+				require.NotNil(t, got)
+				assert.Equal(t, tc.want[i].Tokens(), got.Tokens())
+				assert.Equal(t, tc.want[i].TotalTokens(), got.TotalTokens())
+				assert.Equal(t, tc.want[i].Priced, got.Priced)
+				assert.InDelta(t, tc.want[i].CostUSD, got.CostUSD, 1e-9)
+				assert.InDelta(t, tc.want[i].TotalCostUSD, got.TotalCostUSD, 1e-9)
+			}
+		})
+	}
+}
+
+func TestUsageTrackerConcurrent(t *testing.T) {
+	u := newUsageTracker(nil)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			u.record(&EvalResult{TokenEstimate: &tokens.Estimate{InputTokens: 100, OutputTokens: 10}})
+		}()
+	}
+	wg.Wait()
+
+	last := u.record(&EvalResult{})
+	assert.Equal(t, int64(1100), last.TotalTokens())
+	assert.Zero(t, last.Tokens())
+}
+
+func TestUsageTrackerNil(t *testing.T) {
+	var u *usageTracker
+	assert.Nil(t, u.record(&EvalResult{}))
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension"
+	"github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveBlockingExtension serves an extension with a "wait" operation that
+// stops when canceled, a "hang" operation that ignores cancellation, and a
+// "ping" operation.
+func serveBlockingExtension(t *testing.T) string {
+	t.Helper()
+
+	release := make(chan struct{})
+	ext := sdk.NewExtension(sdk.ExtensionInfo{Name: "blocking", Version: "1.0.0"})
+	ext.AddOperation(sdk.NewOperation("wait"), func(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	ext.AddOperation(sdk.NewOperation("hang"), func(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+		<-release
+		return sdk.Success("released"), nil
+	})
+	ext.AddOperation(sdk.NewOperation("ping"), func(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+		return sdk.Success("pong"), nil
+	})
+
+	address := serveExtension(t, ext)
+	// Cleanups run last in first out, so hung operations are released before
+	// the extension stops serving
+	t.Cleanup(func() { close(release) })
+	return address
+}
+
+func setCancelGracePeriod(t *testing.T, d time.Duration) {
+	t.Helper()
+	prev := cancelGracePeriod
+	cancelGracePeriod = d
+	t.Cleanup(func() { cancelGracePeriod = prev })
+}
+
+func TestClient_ExecuteCanceled(t *testing.T) {
+	setCancelGracePeriod(t, 5*time.Second)
+	address := serveBlockingExtension(t)
+
+	c := New(Options{Address: address})
+	require.NoError(t, c.Start(context.Background(), &protocol.InitializeParams{}))
+	t.Cleanup(func() { _ = c.Shutdown(context.Background()) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.Execute(ctx, &protocol.ExecuteParams{Operation: "wait"}, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	// The extension stopped the operation when it was canceled, without
+	// waiting for the grace period
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	// Later calls are not blocked by the canceled operation
+	res, err := c.Execute(context.Background(), &protocol.ExecuteParams{Operation: "ping"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "pong", res.Message)
+}
+
+func TestClient_ExecuteHungIsStopped(t *testing.T) {
+	setCancelGracePeriod(t, 100*time.Millisecond)
+	address := serveBlockingExtension(t)
+
+	c := New(Options{Address: address})
+	require.NoError(t, c.Start(context.Background(), &protocol.InitializeParams{}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := c.Execute(ctx, &protocol.ExecuteParams{Operation: "hang"}, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "canceled operation did not stop")
+	assert.True(t, isStopped(c))
+
+	_, err = c.Execute(context.Background(), &protocol.ExecuteParams{Operation: "ping"}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "extension was stopped")
+
+	assert.NoError(t, c.Shutdown(context.Background()))
+}
+
+func TestExtensionManager_RestartsStoppedExtension(t *testing.T) {
+	setCancelGracePeriod(t, 100*time.Millisecond)
+	address := serveBlockingExtension(t)
+
+	manager := NewManager(&mockResolver{}, ExtensionOptions{})
+	require.NoError(t, manager.Register("blocking", &extension.ExtensionSpec{Address: address}))
+	t.Cleanup(func() { _ = manager.ShutdownAll(context.Background()) })
+
+	c, err := manager.Get(context.Background(), "blocking")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = c.Execute(ctx, &protocol.ExecuteParams{Operation: "hang"}, nil)
+	require.Error(t, err)
+
+	restarted, err := manager.Get(context.Background(), "blocking")
+	require.NoError(t, err)
+	assert.NotSame(t, c, restarted)
+
+	res, err := restarted.Execute(context.Background(), &protocol.ExecuteParams{Operation: "ping"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "pong", res.Message)
+}
@@ -5,17 +5,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"os/exec"
 	"sync"
 	"time"
 
+	"github.com/mcpchecker/mcpchecker/pkg/extension"
 	"github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
 	"golang.org/x/exp/jsonrpc2"
 )
 
 type Client interface {
 	Start(ctx context.Context, params *protocol.InitializeParams) error
-	Execute(ctx context.Context, params *protocol.ExecuteParams) (*protocol.ExecuteResult, error)
+	// Execute runs an operation, passing its log messages to sink if it
+	// isn't nil
+	Execute(ctx context.Context, params *protocol.ExecuteParams, sink LogSink) (*protocol.ExecuteResult, error)
 	Manifest() *protocol.InitializeResult
 	Shutdown(ctx context.Context) error
 }
@@ -26,14 +30,41 @@ type client struct {
 	manifest *protocol.InitializeResult
 	opts     Options
 	mux      sync.Mutex
+
+	// stopped is set when the extension was force-killed because a canceled
+	// operation did not stop
+	stopped bool
+
+	// sinks route the logs of the running operations to their sinks, by
+	// request ID. While registering calls are being sent, the logs of
+	// requests without a sink are kept in early until their sink is added.
+	sinks       map[jsonrpc2.ID]*logRoute
+	registering int
+	early       map[jsonrpc2.ID][]protocol.LogParams
+	sinksMu     sync.Mutex
 }
 
 var _ Client = &client{}
 
+// dialTimeout bounds connecting to an extension at an address
+const dialTimeout = 10 * time.Second
+
+// cancelGracePeriod is how long a canceled operation has to stop before the
+// extension is force-killed
+var cancelGracePeriod = 5 * time.Second
+
 type Options struct {
 	BinaryPath string
 	Env        []string
 	LogHandler func(level, message string, data map[string]any)
+
+	// Address connects to an already running extension instead of starting
+	// BinaryPath, see extension.ParseAddress
+	Address string
+
+	// LogLevel is the minimum level of the log messages passed to the log sink
+	// of an operation, see Execute. It defaults to info.
+	LogLevel string
 }
 
 func New(opts Options) Client {
@@ -41,6 +72,10 @@ func New(opts Options) Client {
 }
 
 func (c *client) Start(ctx context.Context, params *protocol.InitializeParams) error {
+	if c.opts.Address != "" {
+		return c.connect(ctx, params)
+	}
+
 	c.cmd = exec.CommandContext(ctx, c.opts.BinaryPath)
 	c.cmd.Env = c.opts.Env
 
@@ -62,7 +97,7 @@ func (c *client) Start(ctx context.Context, params *protocol.InitializeParams) e
 
 	c.conn, err = jsonrpc2.Dial(ctx, &cmdDialer{stdin: stdin, stdout: stdout}, &jsonrpc2.ConnectionOptions{
 		Handler: c,
-		Framer:  protocol.NewlineFramer(),
+		Framer:  c.framer(),
 	})
 	if err != nil {
 		_ = c.cmd.Process.Kill()
@@ -78,6 +113,36 @@ func (c *client) Start(ctx context.Context, params *protocol.InitializeParams) e
 	return nil
 }
 
+// connect connects to the running extension at the address of the options.
+func (c *client) connect(ctx context.Context, params *protocol.InitializeParams) error {
+	network, address, err := extension.ParseAddress(c.opts.Address)
+	if err != nil {
+		return err
+	}
+
+	c.conn, err = jsonrpc2.Dial(ctx, jsonrpc2.NetDialer(network, address, net.Dialer{Timeout: dialTimeout}), &jsonrpc2.ConnectionOptions{
+		Handler: c,
+		Framer:  c.framer(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to extension at %s: %w", c.opts.Address, err)
+	}
+
+	c.manifest, err = c.initialize(ctx, params)
+	if err != nil {
+		c.closeConn()
+		return fmt.Errorf("failed to initialize extension at %s: %w", c.opts.Address, err)
+	}
+
+	return nil
+}
+
+// framer returns the framer of the connection, which routes the log messages
+// of operations to their sinks.
+func (c *client) framer() jsonrpc2.Framer {
+	return &logFramer{Framer: protocol.NewlineFramer(), client: c}
+}
+
 func (c *client) Handle(ctx context.Context, req *jsonrpc2.Request) (any, error) {
 	if req.Method == protocol.MethodLog && c.opts.LogHandler != nil {
 		var params protocol.LogParams
@@ -89,20 +154,144 @@ func (c *client) Handle(ctx context.Context, req *jsonrpc2.Request) (any, error)
 	return nil, nil
 }
 
-func (c *client) Execute(ctx context.Context, params *protocol.ExecuteParams) (*protocol.ExecuteResult, error) {
+// Execute runs an operation. If ctx is done before the operation finishes,
+// the extension is asked to cancel it, and is force-killed if the operation
+// does not stop within cancelGracePeriod, so that a hung operation can't
+// block later calls.
+func (c *client) Execute(ctx context.Context, params *protocol.ExecuteParams, sink LogSink) (*protocol.ExecuteResult, error) {
+	conn, err := c.connection()
+	if err != nil {
+		return nil, err
+	}
+
+	call := c.callWithLogs(ctx, conn, params, sink)
+	defer c.removeLogSink(call.ID())
+
 	result := &protocol.ExecuteResult{}
-	if err := c.call(ctx, protocol.MethodExecute, params, result); err != nil {
+	if err := call.Await(ctx, result); err != nil {
+		if ctx.Err() != nil {
+			return nil, errors.Join(err, c.cancelCall(conn, call))
+		}
 		return nil, err
 	}
 
 	return result, nil
 }
 
+// callWithLogs starts the execute call, registering sink, if any, for the
+// logs of the call. The call is sent without holding sinksMu; the logs that
+// arrive before the sink is registered are kept and passed to it first, so
+// none are missed.
+func (c *client) callWithLogs(ctx context.Context, conn *jsonrpc2.Connection, params *protocol.ExecuteParams, sink LogSink) *jsonrpc2.AsyncCall {
+	if sink == nil {
+		return conn.Call(ctx, protocol.MethodExecute, params)
+	}
+
+	c.sinksMu.Lock()
+	c.registering++
+	c.sinksMu.Unlock()
+
+	call := conn.Call(ctx, protocol.MethodExecute, params)
+
+	// Later logs wait for the early ones to be passed to the sink
+	route := &logRoute{sink: sink}
+	route.mu.Lock()
+	defer route.mu.Unlock()
+
+	c.sinksMu.Lock()
+	c.registering--
+	if c.sinks == nil {
+		c.sinks = make(map[jsonrpc2.ID]*logRoute)
+	}
+	c.sinks[call.ID()] = route
+	early := c.early[call.ID()]
+	delete(c.early, call.ID())
+	if c.registering == 0 {
+		c.early = nil
+	}
+	c.sinksMu.Unlock()
+
+	for _, params := range early {
+		sink(params)
+	}
+	return call
+}
+
+// removeLogSink unregisters the log sink of the call with id, waiting for a
+// log being passed to it, so that it isn't called once this returns.
+func (c *client) removeLogSink(id jsonrpc2.ID) {
+	c.sinksMu.Lock()
+	route := c.sinks[id]
+	delete(c.sinks, id)
+	c.sinksMu.Unlock()
+
+	if route != nil {
+		route.close()
+	}
+}
+
+// cancelCall sends a cancel notification for call and waits for the
+// extension to stop handling it, force-killing the extension if it doesn't.
+func (c *client) cancelCall(conn *jsonrpc2.Connection, call *jsonrpc2.AsyncCall) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cancelGracePeriod)
+	defer cancel()
+
+	if err := conn.Notify(ctx, protocol.MethodCancel, &protocol.CancelParams{ID: call.ID().Raw()}); err == nil {
+		// The call is done once the extension responds, whatever the response
+		_ = call.Await(ctx, nil)
+		if ctx.Err() == nil {
+			return nil
+		}
+	}
+
+	return c.forceStop()
+}
+
+// forceStop kills the extension, or closes the connection to an extension at
+// an address, after a canceled operation did not stop.
+func (c *client) forceStop() error {
+	c.mux.Lock()
+	if c.stopped {
+		c.mux.Unlock()
+		return nil
+	}
+	c.stopped = true
+	c.mux.Unlock()
+
+	c.closeConn()
+
+	if c.cmd == nil {
+		return fmt.Errorf("closed the connection to the extension at %s: canceled operation did not stop within %s", c.opts.Address, cancelGracePeriod)
+	}
+
+	killErr := c.cmd.Process.Kill()
+	_ = c.cmd.Wait() // reap the process to avoid zombies
+	return errors.Join(fmt.Errorf("killed the extension: canceled operation did not stop within %s", cancelGracePeriod), killErr)
+}
+
+// isStopped reports whether the extension was force-killed by forceStop.
+func (c *client) isStopped() bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.stopped
+}
+
 func (c *client) Shutdown(ctx context.Context) error {
+	if c.isStopped() {
+		return nil
+	}
+
 	// Use a timeout for the shutdown RPC call to avoid hanging if the extension is unresponsive
 	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
+	// Extensions at an address are shared, so only the connection is shut down
+	if c.cmd == nil {
+		err := c.call(shutdownCtx, protocol.MethodShutdown, struct{}{}, nil)
+		c.closeConn()
+		return err
+	}
+
 	if err := c.call(shutdownCtx, protocol.MethodShutdown, struct{}{}, nil); err != nil {
 		c.closeConn()
 		killErr := c.cmd.Process.Kill()
@@ -154,8 +343,24 @@ func (c *client) initialize(ctx context.Context, params *protocol.InitializePara
 }
 
 func (c *client) call(ctx context.Context, method string, params, result any) error {
+	conn, err := c.connection()
+	if err != nil {
+		return err
+	}
+	return conn.Call(ctx, method, params).Await(ctx, result)
+}
+
+// connection returns the JSON-RPC connection to the extension. Calls don't
+// hold the lock while they wait, so that operations can run concurrently.
+func (c *client) connection() (*jsonrpc2.Connection, error) {
 	c.mux.Lock()
 	defer c.mux.Unlock()
-	call := c.conn.Call(ctx, method, params)
-	return call.Await(ctx, result)
+
+	if c.stopped {
+		return nil, fmt.Errorf("extension was stopped because a canceled operation did not stop")
+	}
+	if c.conn == nil {
+		return nil, fmt.Errorf("extension is not running")
+	}
+	return c.conn, nil
 }
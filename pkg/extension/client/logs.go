@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
+	"golang.org/x/exp/jsonrpc2"
+)
+
+// LogSink receives the log messages an extension sends while it runs an
+// operation. Execute passes it the messages at or above the log level of the
+// client, and calls it before it returns, never after.
+type LogSink func(params protocol.LogParams)
+
+// logFramer routes log messages to the sinks of the operations that sent them
+// as they are read. Messages are read in order, so the logs of an operation
+// reach its sink before its result is read.
+type logFramer struct {
+	jsonrpc2.Framer
+	client *client
+}
+
+func (f *logFramer) Reader(r io.Reader) jsonrpc2.Reader {
+	return &logReader{Reader: f.Framer.Reader(r), client: f.client}
+}
+
+type logReader struct {
+	jsonrpc2.Reader
+	client *client
+}
+
+func (r *logReader) Read(ctx context.Context) (jsonrpc2.Message, int64, error) {
+	msg, n, err := r.Reader.Read(ctx)
+	if req, ok := msg.(*jsonrpc2.Request); ok && err == nil && req.Method == protocol.MethodLog {
+		r.client.routeLog(req.Params)
+	}
+	return msg, n, err
+}
+
+// routeLog passes a log message to the sink of the request it names, if any.
+// The sink is called without holding sinksMu.
+func (c *client) routeLog(raw json.RawMessage) {
+	var params protocol.LogParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return
+	}
+
+	id, ok := requestID(params.RequestID)
+	if !ok || !protocol.LogLevelEnabled(params.Level, c.opts.LogLevel) {
+		return
+	}
+
+	c.sinksMu.Lock()
+	route, ok := c.sinks[id]
+	if !ok && c.registering > 0 {
+		if c.early == nil {
+			c.early = make(map[jsonrpc2.ID][]protocol.LogParams)
+		}
+		c.early[id] = append(c.early[id], params)
+	}
+	c.sinksMu.Unlock()
+
+	if ok {
+		route.log(params)
+	}
+}
+
+// logRoute passes the logs of an operation to its sink one at a time, in
+// order, until it is closed.
+type logRoute struct {
+	mu     sync.Mutex
+	sink   LogSink
+	closed bool
+}
+
+func (r *logRoute) log(params protocol.LogParams) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.closed {
+		r.sink(params)
+	}
+}
+
+func (r *logRoute) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+}
+
+// requestID converts the decoded JSON value of a request ID to a jsonrpc2.ID.
+func requestID(raw any) (jsonrpc2.ID, bool) {
+	switch id := raw.(type) {
+	case float64:
+		return jsonrpc2.Int64ID(int64(id)), true
+	case string:
+		return jsonrpc2.StringID(id), true
+	default:
+		return jsonrpc2.ID{}, false
+	}
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension"
+	"github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveLoggingExtension serves an extension with a "log" operation that logs
+// its "name" argument at every level.
+func serveLoggingExtension(t *testing.T) string {
+	t.Helper()
+
+	ext := sdk.NewExtension(sdk.ExtensionInfo{Name: "logging", Version: "1.0.0"})
+	ext.AddOperation(sdk.NewOperation("log"), func(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+		args, _ := req.Args.(map[string]any)
+		name, _ := args["name"].(string)
+		for _, level := range []string{"debug", "info", "warn", "error"} {
+			if err := ext.Log(ctx, level, level+" "+name, nil); err != nil {
+				return nil, err
+			}
+		}
+		return sdk.Success("logged"), nil
+	})
+
+	return serveExtension(t, ext)
+}
+
+// collectLogs returns a log sink appending the messages it gets to logs.
+func collectLogs(logs *[]string) LogSink {
+	return func(params protocol.LogParams) {
+		*logs = append(*logs, params.Message)
+	}
+}
+
+func TestClient_ExecuteLogSink(t *testing.T) {
+	tt := map[string]struct {
+		logLevel string
+		expected []string
+	}{
+		"default level": {
+			expected: []string{"info op", "warn op", "error op"},
+		},
+		"debug": {
+			logLevel: "debug",
+			expected: []string{"debug op", "info op", "warn op", "error op"},
+		},
+		"error": {
+			logLevel: "error",
+			expected: []string{"error op"},
+		},
+	}
+
+	address := serveLoggingExtension(t)
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			c := New(Options{Address: address, LogLevel: tc.logLevel})
+			require.NoError(t, c.Start(context.Background(), &protocol.InitializeParams{}))
+			t.Cleanup(func() { _ = c.Shutdown(context.Background()) })
+
+			var logs []string
+			_, err := c.Execute(context.Background(), &protocol.ExecuteParams{Operation: "log", Args: map[string]any{"name": "op"}}, collectLogs(&logs))
+			require.NoError(t, err)
+
+			// The logs of the operation are all passed to the sink by the time it returns
+			assert.Equal(t, tc.expected, logs)
+		})
+	}
+}
+
+func TestClient_ExecuteLogSinkConcurrent(t *testing.T) {
+	address := serveLoggingExtension(t)
+
+	c := New(Options{Address: address, LogLevel: "error"})
+	require.NoError(t, c.Start(context.Background(), &protocol.InitializeParams{}))
+	t.Cleanup(func() { _ = c.Shutdown(context.Background()) })
+
+	names := []string{"a", "b", "c", "d"}
+	logs := make([][]string, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.Execute(context.Background(), &protocol.ExecuteParams{Operation: "log", Args: map[string]any{"name": name}}, collectLogs(&logs[i]))
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// Every operation only gets its own logs
+	for i, name := range names {
+		assert.Equal(t, []string{"error " + name}, logs[i])
+	}
+}
+
+func TestExtensionManager_LogLevel(t *testing.T) {
+	address := serveLoggingExtension(t)
+
+	manager := NewManager(&mockResolver{}, ExtensionOptions{})
+	require.NoError(t, manager.Register("logging", &extension.ExtensionSpec{Address: address, LogLevel: "warn"}))
+	t.Cleanup(func() { _ = manager.ShutdownAll(context.Background()) })
+
+	c, err := manager.Get(context.Background(), "logging")
+	require.NoError(t, err)
+
+	var logs []string
+	_, err = c.Execute(context.Background(), &protocol.ExecuteParams{Operation: "log", Args: map[string]any{"name": "op"}}, collectLogs(&logs))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"warn op", "error op"}, logs)
+}
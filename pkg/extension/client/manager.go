@@ -51,8 +51,8 @@ func (m *extensionManager) Register(alias string, spec *extension.ExtensionSpec)
 	if spec == nil {
 		return fmt.Errorf("extension spec is required")
 	}
-	if spec.Package == "" {
-		return fmt.Errorf("extension spec: package field is required")
+	if err := spec.Validate(); err != nil {
+		return err
 	}
 
 	m.mu.Lock()
@@ -70,8 +70,12 @@ func (m *extensionManager) Get(ctx context.Context, alias string) (Client, error
 	// Fast path: return cached client without blocking other callers
 	m.mu.Lock()
 	if c, ok := m.clients[alias]; ok {
-		m.mu.Unlock()
-		return c, nil
+		if !isStopped(c) {
+			m.mu.Unlock()
+			return c, nil
+		}
+		// The extension was force-killed after a hung operation, start it again
+		delete(m.clients, alias)
 	}
 	spec, ok := m.specs[alias]
 	m.mu.Unlock()
@@ -88,25 +92,12 @@ func (m *extensionManager) Get(ctx context.Context, alias string) (Client, error
 	ch := m.group.DoChan(alias, func() (any, error) {
 		startupCtx := context.Background()
 
-		binaryPath, err := m.resolver.Resolve(startupCtx, spec.Package)
-		if err != nil {
-			return nil, err
-		}
-
-		env, err := expandEnv(spec.Env)
+		opts, err := m.clientOptions(startupCtx, spec)
 		if err != nil {
 			return nil, err
 		}
 
-		c := New(Options{
-			BinaryPath: binaryPath,
-			LogHandler: func(level, message string, data map[string]any) {
-				if m.opts.LogHandler != nil {
-					m.opts.LogHandler(spec.Package, level, message, data)
-				}
-			},
-			Env: env,
-		})
+		c := New(opts)
 
 		expandedConfig, err := expandConfig(spec.Config)
 		if err != nil {
@@ -137,6 +128,49 @@ func (m *extensionManager) Get(ctx context.Context, alias string) (Client, error
 	}
 }
 
+// clientOptions returns the options of the client of spec. Extensions with an
+// address are connected to, others are resolved and started.
+func (m *extensionManager) clientOptions(ctx context.Context, spec *extension.ExtensionSpec) (Options, error) {
+	name := spec.Package
+	if name == "" {
+		name = spec.Address
+	}
+
+	opts := Options{
+		LogHandler: func(level, message string, data map[string]any) {
+			if m.opts.LogHandler != nil {
+				m.opts.LogHandler(name, level, message, data)
+			}
+		},
+		LogLevel: spec.LogLevel,
+	}
+
+	if spec.Address != "" {
+		opts.Address = spec.Address
+		return opts, nil
+	}
+
+	binaryPath, err := m.resolver.Resolve(ctx, spec.Package)
+	if err != nil {
+		return Options{}, err
+	}
+
+	env, err := expandEnv(spec.Env)
+	if err != nil {
+		return Options{}, err
+	}
+
+	opts.BinaryPath = binaryPath
+	opts.Env = env
+	return opts, nil
+}
+
+// isStopped reports whether c is a client whose extension was force-killed.
+func isStopped(c Client) bool {
+	sc, ok := c.(*client)
+	return ok && sc.isStopped()
+}
+
 func (m *extensionManager) Has(alias string) bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -161,17 +195,6 @@ func (m *extensionManager) ShutdownAll(ctx context.Context) error {
 	return errors.Join(errs...)
 }
 
-type managerKey struct{}
-
-func ManagerToContext(ctx context.Context, manager ExtensionManager) context.Context {
-	return context.WithValue(ctx, managerKey{}, manager)
-}
-
-func ManagerFromContext(ctx context.Context) (ExtensionManager, bool) {
-	manager, ok := ctx.Value(managerKey{}).(ExtensionManager)
-	return manager, ok
-}
-
 // expandEnv resolves template references in the given env map and returns
 // the result merged with the current OS environment as KEY=VALUE pairs.
 func expandEnv(envMap map[string]string) ([]string, error) {
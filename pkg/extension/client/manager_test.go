@@ -46,7 +46,7 @@ func (m *mockClient) Start(ctx context.Context, params *protocol.InitializeParam
 	return nil
 }
 
-func (m *mockClient) Execute(ctx context.Context, params *protocol.ExecuteParams) (*protocol.ExecuteResult, error) {
+func (m *mockClient) Execute(ctx context.Context, params *protocol.ExecuteParams, sink LogSink) (*protocol.ExecuteResult, error) {
 	if m.executeErr != nil {
 		return nil, m.executeErr
 	}
@@ -210,43 +210,6 @@ func TestExtensionManager_Get_Errors(t *testing.T) {
 	}
 }
 
-func TestManagerContext(t *testing.T) {
-	tt := map[string]struct {
-		addToContext bool
-		expectFound  bool
-	}{
-		"manager in context": {
-			addToContext: true,
-			expectFound:  true,
-		},
-		"manager not in context": {
-			addToContext: false,
-			expectFound:  false,
-		},
-	}
-
-	for tn, tc := range tt {
-		t.Run(tn, func(t *testing.T) {
-			ctx := context.Background()
-
-			if tc.addToContext {
-				resolver := &mockResolver{paths: make(map[string]string)}
-				manager := NewManager(resolver, ExtensionOptions{})
-				ctx = ManagerToContext(ctx, manager)
-			}
-
-			retrieved, ok := ManagerFromContext(ctx)
-
-			assert.Equal(t, tc.expectFound, ok)
-			if tc.expectFound {
-				assert.NotNil(t, retrieved)
-			} else {
-				assert.Nil(t, retrieved)
-			}
-		})
-	}
-}
-
 func TestExpandEnv(t *testing.T) {
 	t.Setenv("KEY1", "value1")
 	t.Setenv("KEY2", "value2")
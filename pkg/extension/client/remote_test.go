@@ -0,0 +1,221 @@
+package client
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension"
+	"github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
+	"github.com/mcpchecker/mcpchecker/pkg/extension/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveTestExtension serves an extension counting its calls on a unix socket
+// and returns the address of the socket.
+func serveTestExtension(t *testing.T) string {
+	t.Helper()
+
+	var calls atomic.Int64
+	ext := sdk.NewExtension(sdk.ExtensionInfo{Name: "counter", Version: "1.0.0"})
+	ext.AddOperation(sdk.NewOperation("count"), func(ctx context.Context, req *sdk.OperationRequest) (*sdk.OperationResult, error) {
+		_ = ext.LogInfo(ctx, "counting", nil)
+		return sdk.SuccessWithOutputs("counted", map[string]string{"calls": strconv.FormatInt(calls.Add(1), 10)}), nil
+	})
+
+	return serveExtension(t, ext)
+}
+
+// serveExtension serves ext on a unix socket and returns the address of the
+// socket.
+func serveExtension(t *testing.T, ext *sdk.Extension) string {
+	t.Helper()
+
+	// Unix socket paths are limited to about 100 bytes, so t.TempDir may be too long
+	dir, err := os.MkdirTemp("", "ext")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "ext.sock")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- ext.Serve(ctx, "unix://"+socket) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("unix", socket)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return "unix://" + socket
+}
+
+func TestClient_Address(t *testing.T) {
+	address := serveTestExtension(t)
+	ctx := context.Background()
+
+	var logs atomic.Int64
+	first := New(Options{
+		Address:    address,
+		LogHandler: func(level, message string, data map[string]any) { logs.Add(1) },
+	})
+	require.NoError(t, first.Start(ctx, &protocol.InitializeParams{}))
+	assert.Equal(t, "counter", first.Manifest().Name)
+
+	second := New(Options{Address: address})
+	require.NoError(t, second.Start(ctx, &protocol.InitializeParams{}))
+
+	// Both clients share the state of the running extension
+	res, err := first.Execute(ctx, &protocol.ExecuteParams{Operation: "count"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "1", res.Outputs["calls"])
+
+	res, err = second.Execute(ctx, &protocol.ExecuteParams{Operation: "count"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "2", res.Outputs["calls"])
+
+	// Shutting down a client leaves the extension running for the others
+	require.NoError(t, first.Shutdown(ctx))
+
+	res, err = second.Execute(ctx, &protocol.ExecuteParams{Operation: "count"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "3", res.Outputs["calls"])
+	require.NoError(t, second.Shutdown(ctx))
+
+	// Logs go to the client whose request logged them
+	assert.Eventually(t, func() bool { return logs.Load() == 1 }, time.Second, 10*time.Millisecond)
+}
+
+func TestServe_SocketInUse(t *testing.T) {
+	address := serveTestExtension(t)
+
+	// A second extension doesn't take over the socket of a running one
+	ext := sdk.NewExtension(sdk.ExtensionInfo{Name: "other", Version: "1.0.0"})
+	err := ext.Serve(context.Background(), address)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already listening")
+
+	c := New(Options{Address: address})
+	require.NoError(t, c.Start(context.Background(), &protocol.InitializeParams{}))
+	assert.Equal(t, "counter", c.Manifest().Name)
+	require.NoError(t, c.Shutdown(context.Background()))
+}
+
+func TestServe_StaleSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "ext")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "ext.sock")
+
+	// The socket of an extension that was not shut down cleanly
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: socket, Net: "unix"})
+	require.NoError(t, err)
+	listener.SetUnlinkOnClose(false)
+	require.NoError(t, listener.Close())
+	require.FileExists(t, socket)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	ext := sdk.NewExtension(sdk.ExtensionInfo{Name: "counter", Version: "1.0.0"})
+	go func() { done <- ext.Serve(ctx, "unix://"+socket) }()
+
+	c := New(Options{Address: "unix://" + socket})
+	require.Eventually(t, func() bool {
+		return c.Start(ctx, &protocol.InitializeParams{}) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, c.Shutdown(ctx))
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestClient_AddressUnreachable(t *testing.T) {
+	c := New(Options{Address: "unix://" + filepath.Join(t.TempDir(), "missing.sock")})
+	err := c.Start(context.Background(), &protocol.InitializeParams{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to connect to extension")
+}
+
+func TestExtensionManager_Address(t *testing.T) {
+	address := serveTestExtension(t)
+
+	// The resolver is not used for extensions with an address
+	manager := NewManager(&mockResolver{}, ExtensionOptions{})
+	require.NoError(t, manager.Register("counter", &extension.ExtensionSpec{Address: address}))
+
+	c, err := manager.Get(context.Background(), "counter")
+	require.NoError(t, err)
+
+	res, err := c.Execute(context.Background(), &protocol.ExecuteParams{Operation: "count"}, nil)
+	require.NoError(t, err)
+	assert.True(t, res.Success)
+
+	require.NoError(t, manager.ShutdownAll(context.Background()))
+}
+
+func TestExtensionManager_RegisterValidation(t *testing.T) {
+	tt := map[string]struct {
+		spec   *extension.ExtensionSpec
+		errMsg string
+	}{
+		"package": {
+			spec: &extension.ExtensionSpec{Package: "github.com/test/k8s"},
+		},
+		"tcp address": {
+			spec: &extension.ExtensionSpec{Address: "tcp://127.0.0.1:9000"},
+		},
+		"unix address": {
+			spec: &extension.ExtensionSpec{Address: "unix:///tmp/ext.sock"},
+		},
+		"neither package nor address": {
+			spec:   &extension.ExtensionSpec{},
+			errMsg: "package or address field is required",
+		},
+		"address without scheme": {
+			spec:   &extension.ExtensionSpec{Address: "127.0.0.1:9000"},
+			errMsg: "expected tcp://host:port",
+		},
+		"unsupported scheme": {
+			spec:   &extension.ExtensionSpec{Address: "http://127.0.0.1:9000"},
+			errMsg: `unsupported scheme "http"`,
+		},
+		"log level": {
+			spec: &extension.ExtensionSpec{Package: "github.com/test/k8s", LogLevel: "debug"},
+		},
+		"invalid log level": {
+			spec:   &extension.ExtensionSpec{Package: "github.com/test/k8s", LogLevel: "verbose"},
+			errMsg: `invalid log level "verbose"`,
+		},
+		"env with address": {
+			spec:   &extension.ExtensionSpec{Address: "tcp://127.0.0.1:9000", Env: map[string]string{"KEY": "value"}},
+			errMsg: "env can't be set",
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			manager := NewManager(&mockResolver{}, ExtensionOptions{})
+			err := manager.Register("ext", tc.spec)
+
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errMsg)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
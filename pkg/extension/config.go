@@ -1,7 +1,62 @@
 package extension
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
+)
+
 type ExtensionSpec struct {
-	Package string            `json:"package"`
+	Package string            `json:"package,omitempty"`
 	Env     map[string]string `json:"env,omitempty"`
 	Config  map[string]any    `json:"config,omitempty"`
+
+	// Address connects to an already running extension instead of starting
+	// Package, given as tcp://host:port or unix:///path/to/socket
+	Address string `json:"address,omitempty"`
+
+	// LogLevel is the minimum level of the extension log messages recorded
+	// with the steps that logged them: debug, info, warn or error. Defaults
+	// to info.
+	LogLevel string `json:"logLevel,omitempty"`
+}
+
+// Validate checks that the spec either names a package to start or the
+// address of a running extension.
+func (s *ExtensionSpec) Validate() error {
+	if s.LogLevel != "" && !protocol.ValidLogLevel(s.LogLevel) {
+		return fmt.Errorf("extension spec: invalid log level %q, expected debug, info, warn or error", s.LogLevel)
+	}
+
+	if s.Address == "" {
+		if s.Package == "" {
+			return fmt.Errorf("extension spec: package or address field is required")
+		}
+		return nil
+	}
+
+	if _, _, err := ParseAddress(s.Address); err != nil {
+		return fmt.Errorf("extension spec: %w", err)
+	}
+	if len(s.Env) > 0 {
+		return fmt.Errorf("extension spec: env can't be set for an extension with an address, as it is not started by mcpchecker")
+	}
+	return nil
+}
+
+// ParseAddress splits an extension address, tcp://host:port or
+// unix:///path/to/socket, into its network and address for net.Dial.
+func ParseAddress(address string) (network, addr string, err error) {
+	scheme, addr, ok := strings.Cut(address, "://")
+	if !ok || addr == "" {
+		return "", "", fmt.Errorf("invalid extension address %q: expected tcp://host:port or unix:///path/to/socket", address)
+	}
+
+	switch scheme {
+	case "tcp", "unix":
+		return scheme, addr, nil
+	default:
+		return "", "", fmt.Errorf("invalid extension address %q: unsupported scheme %q, expected tcp or unix", address, scheme)
+	}
 }
@@ -13,7 +13,8 @@ const (
 	MethodInitialize = "initialize"
 	MethodExecute    = "execute"
 	MethodShutdown   = "shutdown"
-	MethodLog        = "log" // notification only
+	MethodLog        = "log"    // notification only
+	MethodCancel     = "cancel" // notification only
 )
 
 // InitializeParams is sent with the "initialize" method
@@ -36,6 +37,12 @@ type Operation struct {
 	Description string            `json:"description,omitempty"`
 	Params      jsonschema.Schema `json:"params"`
 
+	// Outputs maps the names of the outputs that the operation sets when it
+	// succeeds to their descriptions. Tasks that reference other outputs of the
+	// operation are rejected when they are loaded. If it is not set, the
+	// operation's outputs are not known in advance.
+	Outputs map[string]string `json:"outputs,omitempty"`
+
 	mu     sync.Mutex
 	params *jsonschema.Resolved
 }
@@ -94,4 +101,49 @@ type LogParams struct {
 	Level   string         `json:"level"` // "debug", "info", "warn", "error"
 	Message string         `json:"message"`
 	Data    map[string]any `json:"data,omitempty"`
+
+	// RequestID is the ID of the JSON-RPC request the extension was handling
+	// when it sent the message, so that the client can attribute it to the
+	// operation that logged it
+	RequestID any `json:"requestId,omitempty"`
+}
+
+// Log levels of LogParams, from the least to the most severe
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+var logLevelSeverity = map[string]int{
+	LogLevelDebug: 0,
+	LogLevelInfo:  1,
+	LogLevelWarn:  2,
+	LogLevelError: 3,
+}
+
+// ValidLogLevel reports whether level is one of the log levels.
+func ValidLogLevel(level string) bool {
+	_, ok := logLevelSeverity[level]
+	return ok
+}
+
+// LogLevelEnabled reports whether a message of level is at least as severe as
+// minLevel. Unknown levels are treated as info.
+func LogLevelEnabled(level, minLevel string) bool {
+	return severity(level) >= severity(minLevel)
+}
+
+func severity(level string) int {
+	if s, ok := logLevelSeverity[level]; ok {
+		return s
+	}
+	return logLevelSeverity[LogLevelInfo]
+}
+
+// CancelParams is sent as a notification with the "cancel" method when the
+// client stops waiting for a request, so that the extension can stop handling it
+type CancelParams struct {
+	ID any `json:"id"` // ID of the JSON-RPC request to cancel
 }
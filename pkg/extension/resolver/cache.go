@@ -0,0 +1,343 @@
+package resolver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// cacheEntryFile is the name of the metadata file of each cache entry
+const cacheEntryFile = "entry.json"
+
+// cacheDownloadsDir is the directory of the cache holding the signed release
+// archives the binaries are extracted from
+const cacheDownloadsDir = ".downloads"
+
+// CacheEntry describes an extension binary stored in a BinaryCache.
+type CacheEntry struct {
+	// Package is the package the binary was resolved from, e.g. "github.com/owner/repo"
+	Package  string `json:"package"`
+	Version  string `json:"version"`
+	Platform string `json:"platform"`
+	// Binary is the file name of the binary in the entry directory
+	Binary string `json:"binary"`
+	// SHA256 is the checksum of the binary, verified before every use
+	SHA256   string    `json:"sha256"`
+	Size     int64     `json:"size"`
+	CachedAt time.Time `json:"cachedAt"`
+
+	// Path is the location of the binary, set when the entry is read
+	Path string `json:"path,omitempty"`
+}
+
+// BinaryCache is an on-disk cache of extension binaries shared by all
+// mcpchecker processes of a user, so that parallel runs don't download the
+// same binaries again. Entries are keyed by package, version and platform and
+// written atomically. The cache does not verify signatures, so entries are
+// never used without resolving the binary: resolvers verify binaries before
+// putting them, and Put only reuses entries matching the verified binary.
+type BinaryCache struct {
+	Dir string
+}
+
+// defaultCacheName is the directory of the shared binary cache in the user
+// cache directory
+var defaultCacheName = filepath.Join("mcpchecker", "extensions")
+
+// DefaultCacheDir returns the directory of the shared binary cache,
+// $XDG_CACHE_HOME/mcpchecker/extensions or the platform equivalent.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine cache directory: %w", err)
+	}
+	return filepath.Join(dir, defaultCacheName), nil
+}
+
+// NewBinaryCache returns the binary cache in dir, or in DefaultCacheDir if
+// dir is empty.
+func NewBinaryCache(dir string) (*BinaryCache, error) {
+	if dir == "" {
+		var err error
+		dir, err = DefaultCacheDir()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve cache directory: %w", err)
+	}
+	return &BinaryCache{Dir: dir}, nil
+}
+
+// DownloadDir returns the directory where the release archives of pkg are
+// downloaded to, so that they are removed with the cache. Archives are kept
+// in a directory per version and platform below it, see downloadCacheName.
+func (c *BinaryCache) DownloadDir(pkg string) (string, error) {
+	parts := strings.Split(pkg, "/")
+	if err := validateCacheKey(parts); err != nil {
+		return "", fmt.Errorf("invalid cache key %s: %w", pkg, err)
+	}
+	return filepath.Join(append([]string{c.Dir, cacheDownloadsDir}, parts...)...), nil
+}
+
+// Put copies the binary at binaryPath into the cache as pkg at version for
+// platform and returns the path of the cached copy. If another process cached
+// the same binary first, its copy is used instead; an entry with a different
+// checksum is replaced.
+func (c *BinaryCache) Put(pkg, version, platform, binaryPath string) (string, error) {
+	dir, err := c.entryDir(pkg, version, platform)
+	if err != nil {
+		return "", err
+	}
+
+	parentDir := filepath.Dir(dir)
+	if err := os.MkdirAll(parentDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	tmpDir, err := os.MkdirTemp(parentDir, ".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir) // no-op after successful rename
+
+	binary := filepath.Base(binaryPath)
+	checksum, size, err := copyBinary(binaryPath, filepath.Join(tmpDir, binary))
+	if err != nil {
+		return "", fmt.Errorf("failed to copy binary to cache: %w", err)
+	}
+
+	entry := CacheEntry{
+		Package:  pkg,
+		Version:  version,
+		Platform: platform,
+		Binary:   binary,
+		SHA256:   checksum,
+		Size:     size,
+		CachedAt: time.Now().UTC(),
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, cacheEntryFile), data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	if err := os.Rename(tmpDir, dir); err != nil {
+		// Another process may have cached the binary in the meantime. If its
+		// copy is not the binary being put, it is replaced.
+		if existing, err := readCacheEntry(dir); err == nil && existing.SHA256 == checksum && verifyCacheEntry(existing) == nil {
+			return existing.Path, nil
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return "", fmt.Errorf("failed to remove stale cache entry: %w", err)
+		}
+		if err := os.Rename(tmpDir, dir); err != nil {
+			return "", fmt.Errorf("failed to finalize cache entry: %w", err)
+		}
+	}
+
+	return filepath.Join(dir, binary), nil
+}
+
+// List returns the entries of the cache, sorted by package, version and
+// platform. Entries are not verified.
+func (c *BinaryCache) List() ([]CacheEntry, error) {
+	var entries []CacheEntry
+	err := filepath.WalkDir(c.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == c.Dir {
+				return fs.SkipAll
+			}
+			return err
+		}
+
+		if d.IsDir() && (strings.HasPrefix(d.Name(), ".tmp-") || d.Name() == cacheDownloadsDir) {
+			return fs.SkipDir
+		}
+		if d.IsDir() || d.Name() != cacheEntryFile {
+			return nil
+		}
+
+		entry, err := readCacheEntry(filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		entries = append(entries, *entry)
+		return fs.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cache: %w", err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Package != entries[j].Package {
+			return entries[i].Package < entries[j].Package
+		}
+		if entries[i].Version != entries[j].Version {
+			return entries[i].Version < entries[j].Version
+		}
+		return entries[i].Platform < entries[j].Platform
+	})
+
+	return entries, nil
+}
+
+// Clean removes the entries of the cache matching any of refs, given as
+// package or package@version, along with their downloaded release archives,
+// and returns them. Without refs, the whole cache is removed.
+func (c *BinaryCache) Clean(refs ...string) ([]CacheEntry, error) {
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(refs) == 0 {
+		if err := os.RemoveAll(c.Dir); err != nil {
+			return nil, fmt.Errorf("failed to remove cache: %w", err)
+		}
+		return entries, nil
+	}
+
+	var removed []CacheEntry
+	for _, entry := range entries {
+		if !matchesCacheRef(entry, refs) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Dir(entry.Path)); err != nil {
+			return removed, fmt.Errorf("failed to remove %s@%s: %w", entry.Package, entry.Version, err)
+		}
+		removed = append(removed, entry)
+	}
+
+	for _, ref := range refs {
+		if err := c.cleanDownloads(ref); err != nil {
+			return removed, err
+		}
+	}
+
+	return removed, nil
+}
+
+// cleanDownloads removes the release archives of ref, all of them if ref has
+// no version.
+func (c *BinaryCache) cleanDownloads(ref string) error {
+	pkg, version, _ := strings.Cut(ref, "@")
+	dir, err := c.DownloadDir(pkg)
+	if err != nil {
+		return err
+	}
+
+	if version != "" {
+		if err := validateCacheKey([]string{version}); err != nil {
+			return fmt.Errorf("invalid cache key %s: %w", ref, err)
+		}
+		dir = filepath.Join(dir, version)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove downloads of %s: %w", ref, err)
+	}
+	return nil
+}
+
+func matchesCacheRef(entry CacheEntry, refs []string) bool {
+	for _, ref := range refs {
+		if ref == entry.Package || ref == entry.Package+"@"+entry.Version {
+			return true
+		}
+	}
+	return false
+}
+
+// entryDir returns the directory of the entry of pkg at version for platform.
+func (c *BinaryCache) entryDir(pkg, version, platform string) (string, error) {
+	parts := append(strings.Split(pkg, "/"), version, platform)
+	if err := validateCacheKey(parts); err != nil {
+		return "", fmt.Errorf("invalid cache key %s@%s (%s): %w", pkg, version, platform, err)
+	}
+	return filepath.Join(append([]string{c.Dir}, parts...)...), nil
+}
+
+// validateCacheKey checks that the parts of a cache key are safe path
+// elements that don't collide with the directories of the cache itself.
+func validateCacheKey(parts []string) error {
+	for _, part := range parts {
+		if part == "" || part == "." || part == ".." || strings.ContainsAny(part, `/\`) ||
+			strings.HasPrefix(part, ".tmp-") || part == cacheDownloadsDir {
+			return fmt.Errorf("invalid element %q", part)
+		}
+	}
+	return nil
+}
+
+func readCacheEntry(dir string) (*CacheEntry, error) {
+	data, err := os.ReadFile(filepath.Join(dir, cacheEntryFile))
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &CacheEntry{}
+	if err := json.Unmarshal(data, entry); err != nil {
+		return nil, fmt.Errorf("failed to parse cache entry %s: %w", dir, err)
+	}
+	entry.Path = filepath.Join(dir, filepath.Base(entry.Binary))
+	return entry, nil
+}
+
+// verifyCacheEntry checks that the binary of entry is intact.
+func verifyCacheEntry(entry *CacheEntry) error {
+	f, err := os.Open(entry.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+
+	if checksum := hex.EncodeToString(h.Sum(nil)); checksum != entry.SHA256 {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", entry.Path, entry.SHA256, checksum)
+	}
+	return nil
+}
+
+// copyBinary copies the executable at src to dst and returns the checksum and
+// size of the copy.
+func copyBinary(src, dst string) (string, int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", 0, err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o755)
+	if err != nil {
+		return "", 0, err
+	}
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(out, h), in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
@@ -0,0 +1,233 @@
+package resolver
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestBinary(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o755))
+	return path
+}
+
+func TestBinaryCachePut(t *testing.T) {
+	cache := &BinaryCache{Dir: t.TempDir()}
+	src := writeTestBinary(t, "myext-linux-amd64", "binary")
+
+	path, err := cache.Put("github.com/myorg/myext", "v1.0.0", "linux-amd64", src)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(cache.Dir, "github.com", "myorg", "myext", "v1.0.0", "linux-amd64", "myext-linux-amd64"), path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "binary", string(data))
+
+	entries, err := cache.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, path, entries[0].Path)
+}
+
+func TestBinaryCachePutConcurrent(t *testing.T) {
+	cache := &BinaryCache{Dir: t.TempDir()}
+	src := writeTestBinary(t, "myext", "binary")
+
+	var wg sync.WaitGroup
+	paths := make([]string, 8)
+	errs := make([]error, 8)
+	for i := range paths {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			paths[i], errs[i] = cache.Put("github.com/myorg/myext", "v1.0.0", "linux-amd64", src)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range paths {
+		require.NoError(t, errs[i])
+		assert.Equal(t, paths[0], paths[i])
+	}
+
+	entries, err := cache.List()
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestBinaryCacheInvalidKey(t *testing.T) {
+	tt := map[string]struct {
+		pkg      string
+		version  string
+		platform string
+	}{
+		"parent directory in package": {pkg: "github.com/../myext", version: "v1.0.0", platform: "linux-amd64"},
+		"empty version":               {pkg: "github.com/myorg/myext", version: "", platform: "linux-amd64"},
+		"separator in version":        {pkg: "github.com/myorg/myext", version: "v1/../..", platform: "linux-amd64"},
+		"temp directory name":         {pkg: "github.com/myorg/myext", version: ".tmp-1", platform: "linux-amd64"},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			cache := &BinaryCache{Dir: t.TempDir()}
+			src := writeTestBinary(t, "myext", "binary")
+
+			_, err := cache.Put(tc.pkg, tc.version, tc.platform, src)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestBinaryCacheList(t *testing.T) {
+	cache := &BinaryCache{Dir: filepath.Join(t.TempDir(), "extensions")}
+
+	entries, err := cache.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	src := writeTestBinary(t, "ext", "binary")
+	for _, key := range [][2]string{
+		{"github.com/myorg/zext", "v1.0.0"},
+		{"github.com/myorg/aext", "v2.0.0"},
+		{"github.com/myorg/aext", "v1.0.0"},
+	} {
+		_, err := cache.Put(key[0], key[1], "linux-amd64", src)
+		require.NoError(t, err)
+	}
+	// Interrupted writes are not listed
+	require.NoError(t, os.MkdirAll(filepath.Join(cache.Dir, "github.com", "myorg", "aext", ".tmp-123"), 0o755))
+
+	entries, err = cache.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	var keys []string
+	for _, e := range entries {
+		keys = append(keys, e.Package+"@"+e.Version)
+		assert.Equal(t, "linux-amd64", e.Platform)
+		assert.Equal(t, int64(len("binary")), e.Size)
+		assert.FileExists(t, e.Path)
+	}
+	assert.Equal(t, []string{"github.com/myorg/aext@v1.0.0", "github.com/myorg/aext@v2.0.0", "github.com/myorg/zext@v1.0.0"}, keys)
+}
+
+func TestBinaryCacheClean(t *testing.T) {
+	tt := map[string]struct {
+		refs            []string
+		expectRemoved   []string
+		expectKept      []string
+		expectDownloads []string
+	}{
+		"everything": {
+			expectRemoved: []string{"github.com/myorg/aext@v1.0.0", "github.com/myorg/aext@v2.0.0", "github.com/myorg/zext@v1.0.0"},
+		},
+		"package": {
+			refs:            []string{"github.com/myorg/aext"},
+			expectRemoved:   []string{"github.com/myorg/aext@v1.0.0", "github.com/myorg/aext@v2.0.0"},
+			expectKept:      []string{"github.com/myorg/zext@v1.0.0"},
+			expectDownloads: []string{"zext-v1.0.0-linux-amd64.zip"},
+		},
+		"package version": {
+			refs:            []string{"github.com/myorg/aext@v2.0.0", "github.com/myorg/zext@v9.9.9"},
+			expectRemoved:   []string{"github.com/myorg/aext@v2.0.0"},
+			expectKept:      []string{"github.com/myorg/aext@v1.0.0", "github.com/myorg/zext@v1.0.0"},
+			expectDownloads: []string{"aext-v1.0.0-linux-amd64.zip", "zext-v1.0.0-linux-amd64.zip"},
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			cache := &BinaryCache{Dir: t.TempDir()}
+			src := writeTestBinary(t, "ext", "binary")
+			for _, key := range [][2]string{
+				{"github.com/myorg/aext", "v1.0.0"},
+				{"github.com/myorg/aext", "v2.0.0"},
+				{"github.com/myorg/zext", "v1.0.0"},
+			} {
+				_, err := cache.Put(key[0], key[1], "linux-amd64", src)
+				require.NoError(t, err)
+
+				// The release archive the binary was extracted from
+				dir, err := cache.DownloadDir(key[0])
+				require.NoError(t, err)
+				dir = filepath.Join(dir, key[1], "linux-amd64", "binaries")
+				require.NoError(t, os.MkdirAll(dir, 0o755))
+				archive := fmt.Sprintf("%s-%s-linux-amd64.zip", filepath.Base(key[0]), key[1])
+				require.NoError(t, os.WriteFile(filepath.Join(dir, archive), []byte("zip"), 0o644))
+			}
+
+			removed, err := cache.Clean(tc.refs...)
+			require.NoError(t, err)
+
+			var removedKeys []string
+			for _, e := range removed {
+				removedKeys = append(removedKeys, e.Package+"@"+e.Version)
+			}
+			assert.Equal(t, tc.expectRemoved, removedKeys)
+
+			entries, err := cache.List()
+			require.NoError(t, err)
+			var keptKeys []string
+			for _, e := range entries {
+				keptKeys = append(keptKeys, e.Package+"@"+e.Version)
+			}
+			assert.Equal(t, tc.expectKept, keptKeys)
+
+			archives, err := filepath.Glob(filepath.Join(cache.Dir, cacheDownloadsDir, "*", "*", "*", "*", "*", "binaries", "*.zip"))
+			require.NoError(t, err)
+			var downloads []string
+			for _, archive := range archives {
+				downloads = append(downloads, filepath.Base(archive))
+			}
+			assert.Equal(t, tc.expectDownloads, downloads)
+		})
+	}
+}
+
+func TestBinaryCachePutReplacesDifferentBinary(t *testing.T) {
+	cache := &BinaryCache{Dir: t.TempDir()}
+
+	// An entry that does not match the verified binary, e.g. written by
+	// another user of a shared cache directory
+	path, err := cache.Put("github.com/myorg/myext", "v1.0.0", "linux-amd64", writeTestBinary(t, "myext", "untrusted"))
+	require.NoError(t, err)
+
+	got, err := cache.Put("github.com/myorg/myext", "v1.0.0", "linux-amd64", writeTestBinary(t, "myext", "verified"))
+	require.NoError(t, err)
+	assert.Equal(t, path, got)
+
+	data, err := os.ReadFile(got)
+	require.NoError(t, err)
+	assert.Equal(t, "verified", string(data))
+}
+
+func TestDownloadCacheName(t *testing.T) {
+	userCacheDir, err := os.UserCacheDir()
+	require.NoError(t, err)
+
+	// The archives are downloaded to the default cache, also for other caches
+	cache, err := NewBinaryCache("")
+	require.NoError(t, err)
+
+	name, err := downloadCacheName("github.com/myorg/myext", "v1.0.0", "linux-amd64")
+	require.NoError(t, err)
+	dir, err := cache.DownloadDir("github.com/myorg/myext")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "v1.0.0", "linux-amd64"), filepath.Join(userCacheDir, name))
+
+	for _, key := range [][3]string{
+		{"github.com/../myext", "v1.0.0", "linux-amd64"},
+		{"github.com/myorg/myext", "../../..", "linux-amd64"},
+		{"github.com/myorg/myext", "v1.0.0", ".."},
+	} {
+		_, err := downloadCacheName(key[0], key[1], key[2])
+		assert.Error(t, err, "%v", key)
+	}
+}
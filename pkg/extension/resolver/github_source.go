@@ -3,6 +3,8 @@ package resolver
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 
@@ -10,7 +12,10 @@ import (
 )
 
 // GithubSource resolves extension binaries from GitHub releases
-type GithubSource struct{}
+type GithubSource struct {
+	// CacheDir is the directory of the shared binary cache, DefaultCacheDir if empty
+	CacheDir string
+}
 
 var _ Source = &GithubSource{}
 
@@ -23,12 +28,20 @@ func (s *GithubSource) Scheme() string {
 // Examples:
 //   - "myorg/myext" - resolves to latest version
 //   - "myorg/myext@v1.0.0" - resolves to specific version
+//
+// Every resolution verifies the Sigstore signature of the release archive,
+// which is downloaded once into the shared binary cache. The binaries of
+// specific versions are kept in the cache too, but a cached binary is only
+// used when it matches the verified one.
 func (s *GithubSource) Resolve(ctx context.Context, ref string) (string, error) {
 	owner, repo, version, err := parseGithubRef(ref)
 	if err != nil {
 		return "", err
 	}
 
+	pkg := fmt.Sprintf("github.com/%s/%s", owner, repo)
+	platform := fmt.Sprintf("%s-%s", runtime.GOOS, runtime.GOARCH)
+
 	cfg := &binarycache.Config{
 		CacheName:              fmt.Sprintf(".mcpchecker/%s-%s", owner, repo),
 		BinaryPrefix:           repo,
@@ -38,6 +51,13 @@ func (s *GithubSource) Resolve(ctx context.Context, ref string) (string, error)
 		SigstoreOIDCIssuer:     "https://token.actions.githubusercontent.com",
 	}
 
+	if name, err := downloadCacheName(pkg, version, platform); err == nil {
+		cfg.CacheName = name
+	}
+
+	// Without a cache directory, binaries are not shared between runs
+	cache, _ := NewBinaryCache(s.CacheDir)
+
 	downloader, err := binarycache.NewBinaryDownloader(cfg)
 	if err != nil {
 		return "", fmt.Errorf("failed to create binary downloader: %w", err)
@@ -48,7 +68,32 @@ func (s *GithubSource) Resolve(ctx context.Context, ref string) (string, error)
 		return "", fmt.Errorf("failed to get binary for %s/%s@%s: %w", owner, repo, version, err)
 	}
 
-	return binaryPath, nil
+	if cache == nil || version == "latest" {
+		return binaryPath, nil
+	}
+
+	cachedPath, err := cache.Put(pkg, version, platform, binaryPath)
+	if err != nil {
+		// The downloaded binary is still usable, it is just not shared
+		return binaryPath, nil
+	}
+	// The downloader extracts binaries to a temp directory of their own
+	_ = os.RemoveAll(filepath.Dir(binaryPath))
+
+	return cachedPath, nil
+}
+
+// downloadCacheName returns the cache name, relative to the user cache
+// directory, under which the downloader keeps the release archives of pkg at
+// version for platform: their download directory in the default cache, see
+// BinaryCache.DownloadDir. The downloader always keeps them in the user cache
+// directory, also for caches in other directories.
+func downloadCacheName(pkg, version, platform string) (string, error) {
+	parts := append(strings.Split(pkg, "/"), version, platform)
+	if err := validateCacheKey(parts); err != nil {
+		return "", fmt.Errorf("invalid cache key %s@%s (%s): %w", pkg, version, platform, err)
+	}
+	return filepath.Join(append([]string{defaultCacheName, cacheDownloadsDir}, parts...)...), nil
 }
 
 // parseGithubRef parses a GitHub reference into owner, repo, and version
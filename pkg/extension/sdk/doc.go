@@ -38,15 +38,25 @@
 //	    log.Fatal(err)
 //	}
 //
+// # Serving Connections
+//
+// Besides stdio, an extension can run as a long-lived server that several
+// mcpchecker runs connect to through the address field of their extension
+// config. Start the extension with --listen, or call [Extension.Serve]:
+//
+//	./my-extension --listen tcp://127.0.0.1:9000
+//
 // # Operations
 //
 // Operations are the actions your extension can perform. Each operation has:
 //   - A name (used in execute requests)
 //   - An optional description
 //   - An optional JSON schema defining the expected parameters
+//   - Optional declared outputs, the keys of the outputs it sets on success
 //
-// Create operations with [NewOperation] and functional options like [WithDescription]
-// and [WithParams].
+// Create operations with [NewOperation] and functional options like [WithDescription],
+// [WithParams] and [WithOutputs]. Declaring outputs lets mcpchecker check the
+// {steps.<id>.<output>} references of tasks when they are loaded.
 //
 // # Handling Requests
 //
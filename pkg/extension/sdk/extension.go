@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
 	"golang.org/x/exp/jsonrpc2"
@@ -20,8 +21,10 @@ type Extension struct {
 	operations   map[string]*extensionOperation
 	onInitialize InitializeHandler
 
-	// conn is set when the extension is running
+	// conn is set when the extension is running over stdio
 	conn *jsonrpc2.Connection
+	// served holds the open connections when the extension is running as a server
+	served map[*jsonrpc2.Connection]struct{}
 	// cancel is used to cancel the connection context on shutdown
 	cancel context.CancelFunc
 	// shutdown is set to true when shutdown has been requested
@@ -87,18 +90,28 @@ func (e *Extension) AddOperation(o *Operation, handler OperationHandler) {
 // For one-shot mode, pass --config with a JSON object to pre-initialize:
 //
 //	echo '{"jsonrpc":"2.0",...}' | ./extension --config '{"kubeconfig":"/path/to/config"}'
+//
+// To run the extension as a server shared by several mcpchecker runs, pass
+// --listen with the address to accept connections on, see [Extension.Serve]:
+//
+//	./extension --listen tcp://127.0.0.1:9000
 func (e *Extension) Run(ctx context.Context) error {
 	// Check for --config flag for one-shot mode initialization
 	if err := e.parseAndInitializeFromArgs(); err != nil {
 		return fmt.Errorf("failed to initialize from args: %w", err)
 	}
 
+	if address := listenAddressFromArgs(); address != "" {
+		return e.Serve(ctx, address)
+	}
+
 	// Create a cancellable context so we can interrupt reads on shutdown
 	connCtx, cancel := context.WithCancel(ctx)
 
 	conn, err := jsonrpc2.Dial(connCtx, &stdioDialer{}, &jsonrpc2.ConnectionOptions{
-		Handler: e,
-		Framer:  protocol.NewlineFramer(),
+		Handler:   e,
+		Preempter: e,
+		Framer:    protocol.NewlineFramer(),
 	})
 	if err != nil {
 		cancel()
@@ -158,6 +171,37 @@ func (e *Extension) Handle(ctx context.Context, req *jsonrpc2.Request) (any, err
 	}
 }
 
+// Preempt handles cancel notifications as they arrive, instead of queuing them
+// behind the operation they cancel.
+func (e *Extension) Preempt(_ context.Context, req *jsonrpc2.Request) (any, error) {
+	if req.Method != protocol.MethodCancel {
+		return nil, jsonrpc2.ErrNotHandled
+	}
+
+	e.mu.RLock()
+	conn := e.conn
+	e.mu.RUnlock()
+
+	cancelRequest(conn, req)
+	return nil, nil
+}
+
+// cancelRequest cancels the context of the request on conn named by the
+// params of the cancel notification req.
+func cancelRequest(conn *jsonrpc2.Connection, req *jsonrpc2.Request) {
+	var params protocol.CancelParams
+	if conn == nil || json.Unmarshal(req.Params, &params) != nil {
+		return
+	}
+
+	switch id := params.ID.(type) {
+	case float64:
+		conn.Cancel(jsonrpc2.Int64ID(int64(id)))
+	case string:
+		conn.Cancel(jsonrpc2.StringID(id))
+	}
+}
+
 func (e *Extension) handleInitialize(_ context.Context, req *jsonrpc2.Request) (*protocol.InitializeResult, error) {
 	var params protocol.InitializeParams
 	if err := json.Unmarshal(req.Params, &params); err != nil {
@@ -185,6 +229,7 @@ func (e *Extension) handleInitialize(_ context.Context, req *jsonrpc2.Request) (
 		operations[name] = &protocol.Operation{
 			Description: op.operation.description,
 			Params:      op.operation.params,
+			Outputs:     op.operation.outputs,
 		}
 	}
 
@@ -219,6 +264,18 @@ func (e *Extension) handleExecute(ctx context.Context, req *jsonrpc2.Request) (*
 		Context: params.Context,
 	}
 
+	// Logs of the operation carry the request ID, so that the client can record
+	// them with the step that ran it
+	ctx = context.WithValue(ctx, requestIDContextKey{}, req.ID.Raw())
+
+	// The client cancels the request when the timeout passes, the deadline
+	// lets handlers plan for it
+	if timeout, err := time.ParseDuration(params.Context.Timeout); err == nil && timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	result, err := op.handler(ctx, opReq)
 	if err != nil {
 		return &protocol.ExecuteResult{
@@ -245,21 +302,32 @@ func (e *Extension) handleShutdown(_ context.Context, _ *jsonrpc2.Request) (any,
 	return struct{}{}, nil
 }
 
-// Log sends a log message to the client.
+// requestIDContextKey is the context key of the ID of the execute request an
+// operation handles
+type requestIDContextKey struct{}
+
+// Log sends a log message to the client. When the extension is running as a
+// server, the message goes to the client of the request ctx belongs to. Messages
+// logged with the context of an operation are recorded with the step that ran it.
 func (e *Extension) Log(ctx context.Context, level, message string, data map[string]any) error {
 	e.mu.RLock()
 	conn := e.conn
 	shutdown := e.shutdown
 	e.mu.RUnlock()
 
+	if served, ok := ctx.Value(connContextKey{}).(*jsonrpc2.Connection); ok {
+		conn = served
+	}
+
 	if conn == nil || shutdown {
 		return fmt.Errorf("extension not running")
 	}
 
 	params := protocol.LogParams{
-		Level:   level,
-		Message: message,
-		Data:    data,
+		Level:     level,
+		Message:   message,
+		Data:      data,
+		RequestID: ctx.Value(requestIDContextKey{}),
 	}
 
 	return conn.Notify(ctx, protocol.MethodLog, params)
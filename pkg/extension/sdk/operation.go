@@ -12,6 +12,7 @@ type Operation struct {
 	name        string
 	description string
 	params      jsonschema.Schema
+	outputs     map[string]string
 }
 
 // OperationOption is a functional option for configuring an Operation.
@@ -40,6 +41,15 @@ func WithParams(schema jsonschema.Schema) OperationOption {
 	}
 }
 
+// WithOutputs declares the outputs the operation sets when it succeeds, mapped
+// to their descriptions, so that tasks referencing other outputs fail when
+// they are loaded rather than when they run.
+func WithOutputs(outputs map[string]string) OperationOption {
+	return func(o *Operation) {
+		o.outputs = outputs
+	}
+}
+
 // OperationRequest contains all the context and arguments for an operation execution.
 type OperationRequest struct {
 	// Args contains the arguments passed to the operation.
@@ -0,0 +1,150 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension"
+	"github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
+	"golang.org/x/exp/jsonrpc2"
+)
+
+// connContextKey is the context key of the connection a request arrived on
+type connContextKey struct{}
+
+// Serve starts the extension as a long-lived server accepting JSON-RPC
+// connections on address, given as tcp://host:port or unix:///path/to/socket.
+// Every connection is initialized and shut down on its own, so several
+// mcpchecker runs can share one extension and the state it holds, such as a
+// cloud session. This blocks until ctx is canceled.
+//
+// Extensions started with --listen <address> call Serve from [Extension.Run].
+func (e *Extension) Serve(ctx context.Context, address string) error {
+	network, addr, err := extension.ParseAddress(address)
+	if err != nil {
+		return err
+	}
+
+	if network == "unix" {
+		if err := removeStaleSocket(addr); err != nil {
+			return err
+		}
+	}
+
+	listener, err := jsonrpc2.NetListener(ctx, network, addr, jsonrpc2.NetListenOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	server, err := jsonrpc2.Serve(ctx, listener, &serveBinder{extension: e})
+	if err != nil {
+		_ = listener.Close()
+		return fmt.Errorf("failed to start extension server: %w", err)
+	}
+
+	<-ctx.Done()
+
+	// Stop accepting connections and close the open ones, which ends Wait
+	_ = listener.Close()
+	e.mu.Lock()
+	for conn := range e.served {
+		_ = conn.Close()
+	}
+	e.mu.Unlock()
+
+	if err := server.Wait(); err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	return nil
+}
+
+// removeStaleSocket removes the unix socket at path of a previous run that
+// was not shut down cleanly. A socket that accepts connections belongs to a
+// running extension and is left alone, so listening on it fails.
+func removeStaleSocket(path string) error {
+	info, err := os.Stat(path)
+	if err != nil || info.Mode()&os.ModeSocket == 0 {
+		return nil
+	}
+
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("another extension is already listening on %s", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+	}
+	return nil
+}
+
+// serveBinder sets up the connections accepted by Serve.
+type serveBinder struct {
+	extension *Extension
+}
+
+func (b *serveBinder) Bind(_ context.Context, conn *jsonrpc2.Connection) (jsonrpc2.ConnectionOptions, error) {
+	e := b.extension
+	e.mu.Lock()
+	if e.served == nil {
+		e.served = make(map[*jsonrpc2.Connection]struct{})
+	}
+	e.served[conn] = struct{}{}
+	e.mu.Unlock()
+
+	served := &servedConn{extension: e, conn: conn}
+	return jsonrpc2.ConnectionOptions{
+		Handler:   served,
+		Preempter: served,
+		Framer:    protocol.NewlineFramer(),
+	}, nil
+}
+
+// servedConn handles the requests of a connection accepted by Serve.
+type servedConn struct {
+	extension *Extension
+	conn      *jsonrpc2.Connection
+}
+
+func (s *servedConn) Preempt(_ context.Context, req *jsonrpc2.Request) (any, error) {
+	if req.Method != protocol.MethodCancel {
+		return nil, jsonrpc2.ErrNotHandled
+	}
+	cancelRequest(s.conn, req)
+	return nil, nil
+}
+
+func (s *servedConn) Handle(ctx context.Context, req *jsonrpc2.Request) (any, error) {
+	if req.Method != protocol.MethodShutdown {
+		return s.extension.Handle(context.WithValue(ctx, connContextKey{}, s.conn), req)
+	}
+
+	// Shutting down a served connection only closes it, the extension keeps
+	// serving other connections. The close is done in a goroutine to allow the
+	// response to be sent first.
+	s.extension.mu.Lock()
+	delete(s.extension.served, s.conn)
+	s.extension.mu.Unlock()
+	go func() { _ = s.conn.Close() }()
+
+	return struct{}{}, nil
+}
+
+// listenAddressFromArgs returns the address of the --listen flag, if any.
+func listenAddressFromArgs() string {
+	args := os.Args[1:]
+	for i, arg := range args {
+		if arg == "--listen" && i+1 < len(args) {
+			return args[i+1]
+		}
+		if address, ok := strings.CutPrefix(arg, "--listen="); ok {
+			return address
+		}
+	}
+	return ""
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -23,6 +24,8 @@ type AcpAgent interface {
 type acpAgent struct {
 	model        fantasy.LanguageModel
 	systemPrompt string
+	maxRetries   int
+	temperature  *float64
 	conn         *acp.AgentSideConnection
 	mu           sync.Mutex
 	sessions     map[acp.SessionId]*acpSession
@@ -37,9 +40,30 @@ type acpSession struct {
 	promptCancel  context.CancelFunc
 	promptGen     uint64
 	mcpClients    []McpClient
+
+	// messages are the messages of the completed turns of the session, which
+	// later prompts continue
+	messages []fantasy.Message
 }
 
 func New(ctx context.Context, cfg Config) (AcpAgent, error) {
+	model, err := newLanguageModel(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &acpAgent{
+		model:        model,
+		systemPrompt: cfg.SystemPrompt,
+		maxRetries:   cfg.Retry.GetMaxRetries(),
+		temperature:  cfg.Temperature,
+		sessions:     make(map[acp.SessionId]*acpSession),
+	}, nil
+}
+
+// newLanguageModel creates the model of cfg, limited by its rate limiter and
+// reporting to its health observer, if it has them.
+func newLanguageModel(ctx context.Context, cfg Config) (fantasy.LanguageModel, error) {
 	providerName, modelID, err := cfg.ParseModel()
 	if err != nil {
 		return nil, err
@@ -55,11 +79,15 @@ func New(ctx context.Context, cfg Config) (AcpAgent, error) {
 		return nil, fmt.Errorf("failed to create language model %q: %w", modelID, err)
 	}
 
-	return &acpAgent{
-		model:        model,
-		systemPrompt: cfg.SystemPrompt,
-		sessions:     make(map[acp.SessionId]*acpSession),
-	}, nil
+	// The observer sees the requests once they are through the rate limiter
+	if cfg.HealthObserver != nil {
+		model = &observedModel{LanguageModel: model, observer: cfg.HealthObserver}
+	}
+	if cfg.RateLimiter != nil {
+		model = &rateLimitedModel{LanguageModel: model, limiter: cfg.RateLimiter}
+	}
+
+	return model, nil
 }
 
 func (a *acpAgent) RunACP(ctx context.Context, in io.Reader, out io.Writer) error {
@@ -218,6 +246,7 @@ func (a *acpAgent) Prompt(ctx context.Context, params acp.PromptRequest) (acp.Pr
 	s.promptGen++
 	myGen := s.promptGen
 	s.promptCancel = promptCancel
+	history := slices.Clone(s.messages)
 	s.mu.Unlock()
 
 	var promptBuilder strings.Builder
@@ -242,8 +271,13 @@ func (a *acpAgent) Prompt(ctx context.Context, params acp.PromptRequest) (acp.Pr
 
 	agent := fantasy.NewAgent(a.model, opts...)
 
+	retries := &retryCounter{model: a.model.Model()}
 	result, err := agent.Stream(promptCtx, fantasy.AgentStreamCall{
-		Prompt: prompt,
+		Prompt:      prompt,
+		Messages:    history,
+		MaxRetries:  &a.maxRetries,
+		Temperature: a.temperature,
+		OnRetry:     retries.onRetry,
 		OnStepFinish: func(step fantasy.StepResult) error {
 			text := step.Response.Content.Text()
 			if text == "" {
@@ -279,6 +313,12 @@ func (a *acpAgent) Prompt(ctx context.Context, params acp.PromptRequest) (acp.Pr
 	if s.promptGen == myGen {
 		s.promptCancel = nil
 	}
+	if err == nil {
+		s.messages = append(history, fantasy.NewUserMessage(prompt))
+		for _, step := range result.Steps {
+			s.messages = append(s.messages, step.Messages...)
+		}
+	}
 	s.mu.Unlock()
 
 	if err != nil {
@@ -293,7 +333,8 @@ func (a *acpAgent) Prompt(ctx context.Context, params acp.PromptRequest) (acp.Pr
 	return acp.PromptResponse{
 		StopReason: acp.StopReasonEndTurn,
 		Meta: map[string]any{
-			"usage": result.TotalUsage,
+			"usage":   result.TotalUsage,
+			"retries": retries.count.Load(),
 		},
 	}, nil
 }
@@ -13,6 +13,20 @@ type Config struct {
 
 	// SystemPrompt contains optional system instructions for the agent
 	SystemPrompt string
+
+	// RateLimiter limits the model requests, shared by all models of a run
+	// so that they stay within the provider quotas together. Nil is unlimited.
+	RateLimiter *RateLimiter
+
+	// Retry configures the retries of failed model requests, nil for the defaults
+	Retry *RetryConfig
+
+	// HealthObserver is told about the model requests, if it isn't nil
+	HealthObserver HealthObserver
+
+	// Temperature is the sampling temperature of the model requests, nil for
+	// the provider default
+	Temperature *float64
 }
 
 func (cfg *Config) ParseModel() (provider, modelID string, err error) {
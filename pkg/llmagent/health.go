@@ -0,0 +1,103 @@
+package llmagent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"charm.land/fantasy"
+)
+
+// HealthObserver is told about every model request of builtin LLM agents,
+// including the LLM judge and retries, to adapt the load put on the provider.
+// Implementations must be safe for concurrent use.
+type HealthObserver interface {
+	// ObserveRequest is called when a model request has answered or failed.
+	// latency is the time until the first response of streamed requests, and
+	// until the whole response otherwise. It excludes waiting for the rate
+	// limiter.
+	ObserveRequest(latency time.Duration, err error)
+}
+
+// IsRateLimited reports whether err is a provider error rejecting a request
+// because of its rate limits.
+func IsRateLimited(err error) bool {
+	var providerErr *fantasy.ProviderError
+	return errors.As(err, &providerErr) && providerErr.StatusCode == http.StatusTooManyRequests
+}
+
+// observedModel wraps a fantasy.LanguageModel to report every request to a HealthObserver.
+type observedModel struct {
+	fantasy.LanguageModel
+	observer HealthObserver
+}
+
+var _ fantasy.LanguageModel = &observedModel{}
+
+func (m *observedModel) Generate(ctx context.Context, call fantasy.Call) (*fantasy.Response, error) {
+	start := time.Now()
+	resp, err := m.LanguageModel.Generate(ctx, call)
+	m.observer.ObserveRequest(time.Since(start), err)
+	return resp, err
+}
+
+func (m *observedModel) Stream(ctx context.Context, call fantasy.Call) (fantasy.StreamResponse, error) {
+	start := time.Now()
+	stream, err := m.LanguageModel.Stream(ctx, call)
+	if err != nil {
+		m.observer.ObserveRequest(time.Since(start), err)
+		return nil, err
+	}
+
+	// Providers may only fail once the stream is read
+	return func(yield func(fantasy.StreamPart) bool) {
+		observed := false
+		for part := range stream {
+			if !observed {
+				observed = true
+				var partErr error
+				if part.Type == fantasy.StreamPartTypeError {
+					partErr = part.Error
+				}
+				m.observer.ObserveRequest(time.Since(start), partErr)
+			}
+			if !yield(part) {
+				return
+			}
+		}
+	}, nil
+}
+
+func (m *observedModel) GenerateObject(ctx context.Context, call fantasy.ObjectCall) (*fantasy.ObjectResponse, error) {
+	start := time.Now()
+	resp, err := m.LanguageModel.GenerateObject(ctx, call)
+	m.observer.ObserveRequest(time.Since(start), err)
+	return resp, err
+}
+
+func (m *observedModel) StreamObject(ctx context.Context, call fantasy.ObjectCall) (fantasy.ObjectStreamResponse, error) {
+	start := time.Now()
+	stream, err := m.LanguageModel.StreamObject(ctx, call)
+	if err != nil {
+		m.observer.ObserveRequest(time.Since(start), err)
+		return nil, err
+	}
+
+	return func(yield func(fantasy.ObjectStreamPart) bool) {
+		observed := false
+		for part := range stream {
+			if !observed {
+				observed = true
+				var partErr error
+				if part.Type == fantasy.ObjectStreamPartTypeError {
+					partErr = part.Error
+				}
+				m.observer.ObserveRequest(time.Since(start), partErr)
+			}
+			if !yield(part) {
+				return
+			}
+		}
+	}, nil
+}
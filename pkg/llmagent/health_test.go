@@ -0,0 +1,118 @@
+package llmagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"charm.land/fantasy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingObserver struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (o *recordingObserver) ObserveRequest(_ time.Duration, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.errs = append(o.errs, err)
+}
+
+// failingStreamModel is a fantasy.LanguageModel whose Stream fails, either
+// when it is called or in the first part of the stream
+type failingStreamModel struct {
+	fantasy.LanguageModel
+	err      error
+	inStream bool
+}
+
+func (m *failingStreamModel) Stream(ctx context.Context, call fantasy.Call) (fantasy.StreamResponse, error) {
+	if !m.inStream {
+		return nil, m.err
+	}
+	return func(yield func(fantasy.StreamPart) bool) {
+		yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeError, Error: m.err})
+	}, nil
+}
+
+func TestObservedModelStream(t *testing.T) {
+	rateLimited := &fantasy.ProviderError{Message: "slow down", StatusCode: http.StatusTooManyRequests}
+
+	tests := map[string]struct {
+		model           fantasy.LanguageModel
+		wantErr         error
+		wantRateLimited bool
+	}{
+		"success": {
+			model: &streamModel{},
+		},
+		"rate limited": {
+			model:           &failingStreamModel{err: rateLimited},
+			wantErr:         rateLimited,
+			wantRateLimited: true,
+		},
+		"rate limited in stream": {
+			model:           &failingStreamModel{err: rateLimited, inStream: true},
+			wantErr:         rateLimited,
+			wantRateLimited: true,
+		},
+		"other error": {
+			model:   &failingStreamModel{err: errors.New("connection reset")},
+			wantErr: errors.New("connection reset"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			observer := &recordingObserver{}
+			model := &observedModel{LanguageModel: tc.model, observer: observer}
+
+			stream, err := model.Stream(context.Background(), fantasy.Call{})
+			if err == nil {
+				for range stream {
+				}
+			}
+
+			require.Len(t, observer.errs, 1)
+			if tc.wantErr == nil {
+				assert.NoError(t, observer.errs[0])
+			} else {
+				assert.EqualError(t, observer.errs[0], tc.wantErr.Error())
+			}
+			assert.Equal(t, tc.wantRateLimited, IsRateLimited(observer.errs[0]))
+		})
+	}
+}
+
+func TestIsRateLimited(t *testing.T) {
+	rateLimited := &fantasy.ProviderError{StatusCode: http.StatusTooManyRequests}
+
+	assert.True(t, IsRateLimited(rateLimited))
+	assert.True(t, IsRateLimited(fmt.Errorf("agent failed: %w", rateLimited)))
+	assert.False(t, IsRateLimited(&fantasy.ProviderError{StatusCode: http.StatusInternalServerError}))
+	assert.False(t, IsRateLimited(errors.New("429")))
+	assert.False(t, IsRateLimited(nil))
+}
+
+func TestNewLanguageModelHealthObserver(t *testing.T) {
+	t.Setenv(openaiApiKeyEnvVar, "test-key")
+
+	limiter, err := NewRateLimiter(&RateLimitConfig{MaxConcurrent: 1})
+	require.NoError(t, err)
+	observer := &recordingObserver{}
+
+	// The observer sees the requests once they are through the rate limiter
+	model, err := newLanguageModel(context.Background(), Config{Model: "openai:gpt-4o", RateLimiter: limiter, HealthObserver: observer})
+	require.NoError(t, err)
+	require.IsType(t, &rateLimitedModel{}, model)
+	observed, ok := model.(*rateLimitedModel).LanguageModel.(*observedModel)
+	require.True(t, ok, "expected the rate limited model to be observed")
+	assert.Same(t, observer, observed.observer)
+}
@@ -0,0 +1,88 @@
+package llmagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"charm.land/fantasy"
+)
+
+// DefaultParaphrasePrompt is the system prompt of a Paraphraser without one.
+const DefaultParaphrasePrompt = `You rewrite the prompts of tasks given to an AI agent, to measure how sensitive the agent is to their wording.
+Each paraphrase must ask for exactly the same thing as the original: keep every requirement, constraint, name, identifier, number and value, and copy placeholders in braces such as {steps.setup.name} verbatim.
+Vary the wording, sentence structure, order and tone. Don't add hints, steps or information that the original doesn't give.
+Reply with a JSON array of strings, one paraphrase per element, and nothing else.`
+
+// Paraphraser rewrites task prompts with a model, for runs that measure the
+// sensitivity of agents to the wording of prompts.
+type Paraphraser struct {
+	model        fantasy.LanguageModel
+	systemPrompt string
+	maxRetries   int
+}
+
+// NewParaphraser creates a Paraphraser for the model of cfg. The model calls
+// are rate limited and retried as cfg configures.
+func NewParaphraser(ctx context.Context, cfg Config) (*Paraphraser, error) {
+	model, err := newLanguageModel(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	systemPrompt := cfg.SystemPrompt
+	if systemPrompt == "" {
+		systemPrompt = DefaultParaphrasePrompt
+	}
+
+	return &Paraphraser{
+		model:        model,
+		systemPrompt: systemPrompt,
+		maxRetries:   cfg.Retry.GetMaxRetries(),
+	}, nil
+}
+
+// Paraphrase returns count paraphrases of prompt. The seed is passed to the
+// model to ask for a different set of paraphrases for every seed; models
+// don't reproduce their replies, so reproducible runs cache the paraphrases
+// of each seed.
+func (p *Paraphraser) Paraphrase(ctx context.Context, prompt string, count int, seed int64) ([]string, error) {
+	agent := fantasy.NewAgent(p.model, fantasy.WithSystemPrompt(p.systemPrompt))
+
+	result, err := agent.Generate(ctx, fantasy.AgentCall{
+		Prompt:     fmt.Sprintf("Write %d paraphrases of the prompt below (variation seed: %d).\n\n%s", count, seed, prompt),
+		MaxRetries: &p.maxRetries,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseParaphrases(result.Response.Content.Text(), count)
+}
+
+// parseParaphrases parses the JSON array of a reply, which models sometimes
+// wrap in a code fence, and checks that it holds count non-empty paraphrases.
+func parseParaphrases(reply string, count int) ([]string, error) {
+	reply = strings.TrimSpace(reply)
+	if start, end := strings.Index(reply, "["), strings.LastIndex(reply, "]"); start >= 0 && end > start {
+		reply = reply[start : end+1]
+	}
+
+	var paraphrases []string
+	if err := json.Unmarshal([]byte(reply), &paraphrases); err != nil {
+		return nil, fmt.Errorf("model did not reply with a JSON array of paraphrases: %w", err)
+	}
+	if len(paraphrases) < count {
+		return nil, fmt.Errorf("model returned %d paraphrases, want %d", len(paraphrases), count)
+	}
+
+	paraphrases = paraphrases[:count]
+	for i, paraphrase := range paraphrases {
+		paraphrases[i] = strings.TrimSpace(paraphrase)
+		if paraphrases[i] == "" {
+			return nil, fmt.Errorf("model returned an empty paraphrase")
+		}
+	}
+	return paraphrases, nil
+}
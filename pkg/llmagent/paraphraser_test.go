@@ -0,0 +1,61 @@
+package llmagent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseParaphrases(t *testing.T) {
+	tests := map[string]struct {
+		reply       string
+		count       int
+		expected    []string
+		errContains string
+	}{
+		"json array": {
+			reply:    `["List the pods.", "Show me the pods."]`,
+			count:    2,
+			expected: []string{"List the pods.", "Show me the pods."},
+		},
+		"code fence": {
+			reply:    "```json\n[\"List the pods.\", \" Show me the pods. \"]\n```",
+			count:    2,
+			expected: []string{"List the pods.", "Show me the pods."},
+		},
+		"extra paraphrases are dropped": {
+			reply:    `["a", "b", "c"]`,
+			count:    2,
+			expected: []string{"a", "b"},
+		},
+		"too few paraphrases": {
+			reply:       `["a"]`,
+			count:       2,
+			errContains: "returned 1 paraphrases, want 2",
+		},
+		"empty paraphrase": {
+			reply:       `["a", " "]`,
+			count:       2,
+			errContains: "empty paraphrase",
+		},
+		"not json": {
+			reply:       "Here you go: list the pods",
+			count:       1,
+			errContains: "JSON array",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			paraphrases, err := parseParaphrases(tc.reply, tc.count)
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, paraphrases)
+		})
+	}
+}
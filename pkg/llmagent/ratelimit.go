@@ -0,0 +1,156 @@
+package llmagent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"charm.land/fantasy"
+	"golang.org/x/time/rate"
+)
+
+// RateLimitConfig limits model API calls made by builtin LLM agents, including
+// the LLM judge when it uses one. The limits are shared by all tasks in a run.
+type RateLimitConfig struct {
+	// RequestsPerMinute caps how many model requests are started per minute (0 = unlimited)
+	RequestsPerMinute int `json:"requestsPerMinute,omitempty"`
+
+	// MaxConcurrent caps how many model requests are in flight at once (0 = unlimited)
+	MaxConcurrent int `json:"maxConcurrent,omitempty"`
+}
+
+// Validate checks that the configured limits are not negative.
+func (c *RateLimitConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.RequestsPerMinute < 0 {
+		return fmt.Errorf("requestsPerMinute must be >= 0, got %d", c.RequestsPerMinute)
+	}
+	if c.MaxConcurrent < 0 {
+		return fmt.Errorf("maxConcurrent must be >= 0, got %d", c.MaxConcurrent)
+	}
+	return nil
+}
+
+// RateLimiter gates model API calls on a requests-per-minute budget and a
+// maximum number of concurrent calls. A nil RateLimiter does not limit anything.
+type RateLimiter struct {
+	limiter *rate.Limiter
+	slots   chan struct{}
+}
+
+// NewRateLimiter creates a RateLimiter from cfg. It returns nil if cfg sets no limits.
+func NewRateLimiter(cfg *RateLimitConfig) (*RateLimiter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg == nil || (cfg.RequestsPerMinute == 0 && cfg.MaxConcurrent == 0) {
+		return nil, nil
+	}
+
+	l := &RateLimiter{}
+	if cfg.RequestsPerMinute > 0 {
+		// Spread requests evenly over the minute rather than allowing a burst at the start
+		l.limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(cfg.RequestsPerMinute)), 1)
+	}
+	if cfg.MaxConcurrent > 0 {
+		l.slots = make(chan struct{}, cfg.MaxConcurrent)
+	}
+
+	return l, nil
+}
+
+// Acquire blocks until a request may start, or ctx is done. The returned
+// function must be called once the request has finished.
+func (l *RateLimiter) Acquire(ctx context.Context) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	release = func() {}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		var once sync.Once
+		release = func() { once.Do(func() { <-l.slots }) }
+	}
+
+	if l.limiter != nil {
+		if err := l.limiter.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	return release, nil
+}
+
+// rateLimitedModel wraps a fantasy.LanguageModel so every request goes through a RateLimiter.
+type rateLimitedModel struct {
+	fantasy.LanguageModel
+	limiter *RateLimiter
+}
+
+var _ fantasy.LanguageModel = &rateLimitedModel{}
+
+func (m *rateLimitedModel) Generate(ctx context.Context, call fantasy.Call) (*fantasy.Response, error) {
+	release, err := m.limiter.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return m.LanguageModel.Generate(ctx, call)
+}
+
+func (m *rateLimitedModel) Stream(ctx context.Context, call fantasy.Call) (fantasy.StreamResponse, error) {
+	release, err := m.limiter.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := m.LanguageModel.Stream(ctx, call)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	// The request is in flight until the stream has been consumed
+	return func(yield func(fantasy.StreamPart) bool) {
+		defer release()
+		stream(yield)
+	}, nil
+}
+
+func (m *rateLimitedModel) GenerateObject(ctx context.Context, call fantasy.ObjectCall) (*fantasy.ObjectResponse, error) {
+	release, err := m.limiter.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return m.LanguageModel.GenerateObject(ctx, call)
+}
+
+func (m *rateLimitedModel) StreamObject(ctx context.Context, call fantasy.ObjectCall) (fantasy.ObjectStreamResponse, error) {
+	release, err := m.limiter.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := m.LanguageModel.StreamObject(ctx, call)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	return func(yield func(fantasy.ObjectStreamPart) bool) {
+		defer release()
+		stream(yield)
+	}, nil
+}
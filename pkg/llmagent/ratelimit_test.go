@@ -0,0 +1,173 @@
+package llmagent
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"charm.land/fantasy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	tests := map[string]struct {
+		cfg         *RateLimitConfig
+		expectNil   bool
+		errContains string
+	}{
+		"nil config": {
+			cfg:       nil,
+			expectNil: true,
+		},
+		"no limits": {
+			cfg:       &RateLimitConfig{},
+			expectNil: true,
+		},
+		"requests per minute": {
+			cfg: &RateLimitConfig{RequestsPerMinute: 60},
+		},
+		"max concurrent": {
+			cfg: &RateLimitConfig{MaxConcurrent: 2},
+		},
+		"negative requests per minute": {
+			cfg:         &RateLimitConfig{RequestsPerMinute: -1},
+			errContains: "requestsPerMinute",
+		},
+		"negative max concurrent": {
+			cfg:         &RateLimitConfig{MaxConcurrent: -1},
+			errContains: "maxConcurrent",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			limiter, err := NewRateLimiter(tc.cfg)
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectNil, limiter == nil)
+		})
+	}
+}
+
+func TestRateLimiterMaxConcurrent(t *testing.T) {
+	limiter, err := NewRateLimiter(&RateLimitConfig{MaxConcurrent: 2})
+	require.NoError(t, err)
+
+	var inFlight, peak atomic.Int32
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := limiter.Acquire(context.Background())
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer release()
+
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			inFlight.Add(-1)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), peak.Load())
+}
+
+func TestRateLimiterRequestsPerMinute(t *testing.T) {
+	// 600/min allows one request every 100ms
+	limiter, err := NewRateLimiter(&RateLimitConfig{RequestsPerMinute: 600})
+	require.NoError(t, err)
+
+	start := time.Now()
+	for range 3 {
+		release, err := limiter.Acquire(context.Background())
+		require.NoError(t, err)
+		release()
+	}
+
+	assert.GreaterOrEqual(t, time.Since(start), 190*time.Millisecond)
+}
+
+func TestRateLimiterAcquireCancelled(t *testing.T) {
+	limiter, err := NewRateLimiter(&RateLimitConfig{MaxConcurrent: 1})
+	require.NoError(t, err)
+
+	release, err := limiter.Acquire(context.Background())
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = limiter.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestNewLanguageModelRateLimiter(t *testing.T) {
+	t.Setenv(openaiApiKeyEnvVar, "test-key")
+
+	limiter, err := NewRateLimiter(&RateLimitConfig{MaxConcurrent: 1})
+	require.NoError(t, err)
+
+	model, err := newLanguageModel(context.Background(), Config{Model: "openai:gpt-4o", RateLimiter: limiter})
+	require.NoError(t, err)
+	require.IsType(t, &rateLimitedModel{}, model)
+	assert.Same(t, limiter, model.(*rateLimitedModel).limiter)
+
+	model, err = newLanguageModel(context.Background(), Config{Model: "openai:gpt-4o"})
+	require.NoError(t, err)
+	_, limited := model.(*rateLimitedModel)
+	assert.False(t, limited)
+}
+
+// streamModel is a fantasy.LanguageModel whose Stream yields a single part
+type streamModel struct {
+	fantasy.LanguageModel
+}
+
+func (m *streamModel) Stream(ctx context.Context, call fantasy.Call) (fantasy.StreamResponse, error) {
+	return func(yield func(fantasy.StreamPart) bool) {
+		yield(fantasy.StreamPart{Type: fantasy.StreamPartTypeTextDelta, Delta: "hi"})
+	}, nil
+}
+
+func TestRateLimitedModelStreamHoldsSlotUntilConsumed(t *testing.T) {
+	limiter, err := NewRateLimiter(&RateLimitConfig{MaxConcurrent: 1})
+	require.NoError(t, err)
+
+	model := &rateLimitedModel{LanguageModel: &streamModel{}, limiter: limiter}
+
+	stream, err := model.Stream(context.Background(), fantasy.Call{})
+	require.NoError(t, err)
+
+	// The slot is still held while the stream is unconsumed
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = limiter.Acquire(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var parts int
+	for range stream {
+		parts++
+	}
+	assert.Equal(t, 1, parts)
+
+	release, err := limiter.Acquire(context.Background())
+	require.NoError(t, err)
+	release()
+}
@@ -0,0 +1,60 @@
+package llmagent
+
+import (
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"charm.land/fantasy"
+)
+
+// DefaultMaxRetries is the number of retries per model request when no RetryConfig is set.
+const DefaultMaxRetries = 2
+
+// RetryConfig controls how builtin LLM agents retry model requests that fail with
+// a transient error (429, 408, 409, 5xx, or a network error such as a timeout).
+// Retries back off exponentially, starting at 2s and doubling, unless the provider
+// sends a Retry-After header.
+type RetryConfig struct {
+	// MaxRetries is the number of retries per model request (default: 2, 0 disables retries)
+	MaxRetries *int `json:"maxRetries,omitempty"`
+}
+
+// Validate checks that MaxRetries is not negative.
+func (c *RetryConfig) Validate() error {
+	if c == nil || c.MaxRetries == nil {
+		return nil
+	}
+	if *c.MaxRetries < 0 {
+		return fmt.Errorf("maxRetries must be >= 0, got %d", *c.MaxRetries)
+	}
+	return nil
+}
+
+// GetMaxRetries returns the configured number of retries, or DefaultMaxRetries if unset.
+func (c *RetryConfig) GetMaxRetries() int {
+	if c == nil || c.MaxRetries == nil {
+		return DefaultMaxRetries
+	}
+	return *c.MaxRetries
+}
+
+// retryCounter counts the retries made during a prompt so they can be reported with its usage.
+type retryCounter struct {
+	model string
+	count atomic.Int64
+}
+
+func (c *retryCounter) onRetry(err *fantasy.ProviderError, delay time.Duration) {
+	c.count.Add(1)
+
+	reason := "network error"
+	if err != nil {
+		reason = err.Error()
+		if err.StatusCode != 0 {
+			reason = fmt.Sprintf("status %d: %s", err.StatusCode, reason)
+		}
+	}
+	log.Printf("Retrying %s request in %s after transient error: %s", c.model, delay, reason)
+}
@@ -0,0 +1,74 @@
+package llmagent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryConfig(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+
+	tests := map[string]struct {
+		cfg               *RetryConfig
+		expectMaxRetries  int
+		expectErrContains string
+	}{
+		"nil config uses default": {
+			cfg:              nil,
+			expectMaxRetries: DefaultMaxRetries,
+		},
+		"unset max retries uses default": {
+			cfg:              &RetryConfig{},
+			expectMaxRetries: DefaultMaxRetries,
+		},
+		"explicit max retries": {
+			cfg:              &RetryConfig{MaxRetries: intPtr(5)},
+			expectMaxRetries: 5,
+		},
+		"zero disables retries": {
+			cfg:              &RetryConfig{MaxRetries: intPtr(0)},
+			expectMaxRetries: 0,
+		},
+		"negative max retries": {
+			cfg:               &RetryConfig{MaxRetries: intPtr(-1)},
+			expectErrContains: "maxRetries",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.expectErrContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectErrContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectMaxRetries, tc.cfg.GetMaxRetries())
+		})
+	}
+}
+
+func TestNewRetryConfig(t *testing.T) {
+	t.Setenv(openaiApiKeyEnvVar, "test-key")
+
+	maxRetries := 4
+	agent, err := New(context.Background(), Config{Model: "openai:gpt-4o", Retry: &RetryConfig{MaxRetries: &maxRetries}})
+	require.NoError(t, err)
+	assert.Equal(t, 4, agent.(*acpAgent).maxRetries)
+
+	agent, err = New(context.Background(), Config{Model: "openai:gpt-4o"})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultMaxRetries, agent.(*acpAgent).maxRetries)
+}
+
+func TestRetryCounter(t *testing.T) {
+	counter := &retryCounter{model: "test-model"}
+	counter.onRetry(nil, 0)
+	counter.onRetry(nil, 0)
+
+	assert.Equal(t, int64(2), counter.count.Load())
+}
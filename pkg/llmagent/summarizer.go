@@ -0,0 +1,61 @@
+package llmagent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"charm.land/fantasy"
+)
+
+// DefaultSummarizePrompt is the system prompt of a Summarizer without one.
+const DefaultSummarizePrompt = `You summarize the results of tool calls for an AI agent that can't read them in full.
+Keep every identifier, name, number, status, error message and value the agent may need to act on, and drop repetition and boilerplate.
+Reply with the summary only.`
+
+// Summarizer summarizes tool results with a model, for MCP proxies that
+// shorten large tool results before the agent sees them.
+type Summarizer struct {
+	model        fantasy.LanguageModel
+	systemPrompt string
+	maxRetries   int
+}
+
+// NewSummarizer creates a Summarizer for the model of cfg. The model calls
+// are rate limited and retried as cfg configures.
+func NewSummarizer(ctx context.Context, cfg Config) (*Summarizer, error) {
+	model, err := newLanguageModel(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	systemPrompt := cfg.SystemPrompt
+	if systemPrompt == "" {
+		systemPrompt = DefaultSummarizePrompt
+	}
+
+	return &Summarizer{
+		model:        model,
+		systemPrompt: systemPrompt,
+		maxRetries:   cfg.Retry.GetMaxRetries(),
+	}, nil
+}
+
+// Summarize returns a summary of text, the result of a call to the tool toolName.
+func (s *Summarizer) Summarize(ctx context.Context, toolName, text string) (string, error) {
+	agent := fantasy.NewAgent(s.model, fantasy.WithSystemPrompt(s.systemPrompt))
+
+	result, err := agent.Generate(ctx, fantasy.AgentCall{
+		Prompt:     fmt.Sprintf("Result of the %q tool:\n\n%s", toolName, text),
+		MaxRetries: &s.maxRetries,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	summary := strings.TrimSpace(result.Response.Content.Text())
+	if summary == "" {
+		return "", fmt.Errorf("model returned an empty summary")
+	}
+	return summary, nil
+}
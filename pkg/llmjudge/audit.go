@@ -0,0 +1,59 @@
+package llmjudge
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+)
+
+// AuditEntry records a call to the judge agent: the prompts it was sent, what
+// it responded and the verdict it submitted, if any. Data holds the template
+// inputs, so the case can be replayed against changed prompt templates.
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	Model   string    `json:"model"`
+	Attempt int       `json:"attempt"`
+
+	Data         PromptData `json:"data"`
+	SystemPrompt string     `json:"systemPrompt"`
+	UserPrompt   string     `json:"userPrompt"`
+
+	// Response is the raw output of the judge agent, including its
+	// submit_judgement call
+	Response []agent.OutputStep `json:"response,omitempty"`
+	Verdict  *LLMJudgeResult    `json:"verdict,omitempty"`
+	Error    string             `json:"error,omitempty"`
+}
+
+// AuditLog collects the judge calls of a task run. A nil AuditLog discards
+// them. It is safe for concurrent use.
+type AuditLog struct {
+	mu      sync.Mutex
+	entries []AuditEntry
+}
+
+// NewAuditLog returns an empty audit log.
+func NewAuditLog() *AuditLog {
+	return &AuditLog{}
+}
+
+// Record appends entry to the log.
+func (l *AuditLog) Record(entry AuditEntry) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+}
+
+// Entries returns the recorded judge calls in the order they were made.
+func (l *AuditLog) Entries() []AuditEntry {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]AuditEntry(nil), l.entries...)
+}
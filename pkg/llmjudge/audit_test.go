@@ -0,0 +1,172 @@
+package llmjudge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/tokens"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeJudgeRunner submits its verdicts in turn, as a judge agent calling
+// submit_judgement would. A nil verdict completes without submitting one.
+type fakeJudgeRunner struct {
+	server    *judgeServer
+	requestID string
+	verdicts  []*LLMJudgeResult
+	calls     *int
+}
+
+func (r *fakeJudgeRunner) RunTask(_ context.Context, _ string) (agent.AgentResult, error) {
+	verdict := r.verdicts[*r.calls]
+	*r.calls++
+
+	output := []agent.OutputStep{{Type: "message", Content: "judging"}}
+	if verdict != nil {
+		ch, _ := r.server.requests.Load(r.requestID)
+		ch.(chan *LLMJudgeResult) <- verdict
+		output = append(output, agent.OutputStep{
+			Type:     "tool_call",
+			ToolCall: &agent.ToolCallSummary{Title: submitJudgementTool, RawInput: verdict},
+		})
+	}
+	return &fakeJudgeResult{output: output}, nil
+}
+
+func (r *fakeJudgeRunner) WithMcpServerInfo(mcpServers mcpproxy.ServerManager) agent.Runner {
+	clone := *r
+	clone.requestID = mcpServers.(*judgeServerManager).requestID
+	return &clone
+}
+
+func (r *fakeJudgeRunner) WithSkillInfo(_ *agent.SkillInfo) agent.Runner { return r }
+func (r *fakeJudgeRunner) WithActivity(_ *util.Activity) agent.Runner    { return r }
+func (r *fakeJudgeRunner) AgentName() string                             { return "fake-judge" }
+
+type fakeJudgeResult struct {
+	output []agent.OutputStep
+}
+
+func (r *fakeJudgeResult) GetOutput() []agent.OutputStep         { return r.output }
+func (r *fakeJudgeResult) GetToolCalls() []agent.ToolCallSummary { return nil }
+func (r *fakeJudgeResult) GetRawUpdates() any                    { return nil }
+func (r *fakeJudgeResult) GetTokenEstimate() tokens.Estimate     { return tokens.Estimate{} }
+
+func TestEvaluateTextAuditLog(t *testing.T) {
+	server := newJudgeServer()
+	judge := &llmJudge{
+		runner: &fakeJudgeRunner{
+			server:   server,
+			verdicts: []*LLMJudgeResult{nil, {Passed: false, Reason: "no nginx", FailureCategory: "missing_information"}},
+			calls:    new(int),
+		},
+		name:        "fake-judge",
+		prompts:     DefaultPromptTemplates(),
+		maxAttempts: 2,
+		server:      server,
+	}
+
+	log := NewAuditLog()
+	res, err := judge.EvaluateText(context.Background(), &LLMJudgeStepConfig{Contains: "nginx is running"}, "is nginx running?", "no", nil, log)
+	require.NoError(t, err)
+	assert.False(t, res.Passed)
+
+	entries := log.Entries()
+	require.Len(t, entries, 2)
+
+	// The first attempt didn't submit a verdict and was retried
+	assert.Equal(t, 1, entries[0].Attempt)
+	assert.Contains(t, entries[0].Error, "without calling submit_judgement")
+	assert.Nil(t, entries[0].Verdict)
+	assert.Len(t, entries[0].Response, 1)
+
+	assert.Equal(t, 2, entries[1].Attempt)
+	assert.Empty(t, entries[1].Error)
+	assert.Equal(t, res, entries[1].Verdict)
+	assert.Len(t, entries[1].Response, 2)
+
+	for _, entry := range entries {
+		assert.Equal(t, "fake-judge", entry.Model)
+		assert.Equal(t, "nginx is running", entry.Data.ReferenceAnswer)
+		assert.Equal(t, "no", entry.Data.ModelResponse)
+		assert.Contains(t, entry.SystemPrompt, "nginx is running")
+		assert.Contains(t, entry.UserPrompt, "is nginx running?")
+	}
+}
+
+func TestNilAuditLog(t *testing.T) {
+	var log *AuditLog
+	log.Record(AuditEntry{Attempt: 1})
+	assert.Nil(t, log.Entries())
+}
+
+func TestEvaluateTextTemperature(t *testing.T) {
+	one := 1.0
+	tt := map[string]struct {
+		cfg               *LLMJudgeStepConfig
+		setsTemperature   bool
+		expectTemperature *float64
+		expectErr         bool
+	}{
+		"single sample keeps the judge runner": {
+			cfg:             &LLMJudgeStepConfig{Contains: "ok"},
+			setsTemperature: true,
+		},
+		"samples sample at the default temperature": {
+			cfg:               &LLMJudgeStepConfig{Contains: "ok", Samples: 2},
+			setsTemperature:   true,
+			expectTemperature: &one,
+		},
+		"samples with a judge choosing its temperature": {
+			cfg: &LLMJudgeStepConfig{Contains: "ok", Samples: 2},
+		},
+		"temperature with a judge choosing its temperature": {
+			cfg:       &LLMJudgeStepConfig{Contains: "ok", Temperature: &one},
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			server := newJudgeServer()
+			newFake := func() *fakeJudgeRunner {
+				return &fakeJudgeRunner{server: server, verdicts: []*LLMJudgeResult{{Passed: true}, {Passed: true}}, calls: new(int)}
+			}
+			judge := &llmJudge{
+				runner:      newFake(),
+				name:        "fake-judge",
+				prompts:     DefaultPromptTemplates(),
+				maxAttempts: 1,
+				server:      server,
+			}
+			var temperature *float64
+			created := 0
+			if tc.setsTemperature {
+				judge.newRunner = func(t float64) (agent.Runner, error) {
+					temperature = &t
+					created++
+					return newFake(), nil
+				}
+			}
+
+			// Samples reuse the runner of their temperature
+			for range 2 {
+				res, err := judge.EvaluateText(context.Background(), tc.cfg, "prompt", "output", nil, nil)
+				if tc.expectErr {
+					assert.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				assert.True(t, res.Passed)
+			}
+			assert.Equal(t, tc.expectTemperature, temperature)
+			if tc.expectTemperature != nil {
+				assert.Equal(t, 1, created)
+			}
+		})
+	}
+}
@@ -2,18 +2,91 @@ package llmjudge
 
 import (
 	"fmt"
+	"math"
+	"time"
 
 	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/mcpchecker/mcpchecker/pkg/llmagent"
 )
 
 const (
 	EvaluationModeExact    = "EXACT"
 	EvaluationModeContains = "CONTAINS"
+
+	// DefaultAgreementThreshold is the fraction of samples that must pass when
+	// sampling the judge more than once.
+	DefaultAgreementThreshold = 0.5
+
+	// DefaultSampleTemperature is the temperature of the judge when sampling it
+	// more than once, so that the samples can differ.
+	DefaultSampleTemperature = 1.0
 )
 
 type LLMJudgeEvalConfig struct {
 	Env      *LLMJudgeEnvConfig `json:"env,omitempty"`
 	AgentRef *agent.AgentRef    `json:"ref,omitempty"`
+
+	// Prompts overrides the built-in judge prompt templates
+	Prompts *LLMJudgePromptsConfig `json:"prompts,omitempty"`
+
+	// Retry retries judge calls that fail, e.g. because of provider rate limits.
+	// When set, it replaces the eval-level modelRetry for judge calls.
+	Retry *LLMJudgeRetryConfig `json:"retry,omitempty"`
+}
+
+// LLMJudgeRetryConfig controls how failed judge calls are retried.
+type LLMJudgeRetryConfig struct {
+	// MaxAttempts is the total number of attempts per judge call, including the first.
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+	// Backoff is the delay before the first retry, doubled for each further retry. Defaults to 2s.
+	Backoff string `json:"backoff,omitempty"`
+}
+
+const defaultRetryBackoff = 2 * time.Second
+
+// GetMaxAttempts returns the number of attempts per judge call, at least 1.
+func (r *LLMJudgeRetryConfig) GetMaxAttempts() int {
+	if r == nil || r.MaxAttempts < 1 {
+		return 1
+	}
+
+	return r.MaxAttempts
+}
+
+// GetBackoff parses Backoff, returning the default when it is not set.
+func (r *LLMJudgeRetryConfig) GetBackoff() (time.Duration, error) {
+	if r == nil || r.Backoff == "" {
+		return defaultRetryBackoff, nil
+	}
+
+	d, err := time.ParseDuration(r.Backoff)
+	if err != nil {
+		return 0, fmt.Errorf("invalid llm judge retry backoff %q: %w", r.Backoff, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid llm judge retry backoff: must not be negative, got %q", r.Backoff)
+	}
+
+	return d, nil
+}
+
+// modelRetry returns the retries of the model requests of the judge agent, nil
+// to keep the eval-level modelRetry. Configured judge calls are retried whole,
+// so their model requests aren't also retried.
+func (r *LLMJudgeRetryConfig) modelRetry() *llmagent.RetryConfig {
+	if r == nil {
+		return nil
+	}
+
+	noRetries := 0
+	return &llmagent.RetryConfig{MaxRetries: &noRetries}
+}
+
+// LLMJudgePromptsConfig points to Go template files that replace the built-in
+// system and user prompts. Either may be left empty to keep the built-in one.
+type LLMJudgePromptsConfig struct {
+	SystemFile string `json:"systemFile,omitempty"`
+	UserFile   string `json:"userFile,omitempty"`
 }
 
 type LLMJudgeEnvConfig struct {
@@ -25,6 +98,16 @@ type LLMJudgeEnvConfig struct {
 type LLMJudgeStepConfig struct {
 	Contains string `json:"contains,omitempty"`
 	Exact    string `json:"exact,omitempty"`
+
+	// Samples is the number of times the judge is called. Defaults to 1.
+	Samples int `json:"samples,omitempty"`
+	// AgreementThreshold is the fraction of samples that must pass for the step to
+	// pass. Defaults to DefaultAgreementThreshold.
+	AgreementThreshold float64 `json:"agreementThreshold,omitempty"`
+	// Temperature is the sampling temperature of the judge model. Defaults to
+	// DefaultSampleTemperature with more than one sample, otherwise to the
+	// provider default.
+	Temperature *float64 `json:"temperature,omitempty"`
 }
 
 func (cfg *LLMJudgeStepConfig) EvaluationMode() string {
@@ -43,6 +126,39 @@ func (cfg *LLMJudgeStepConfig) ReferenceAnswer() string {
 	return cfg.Contains
 }
 
+// SampleCount returns the number of judge samples to take.
+func (cfg *LLMJudgeStepConfig) SampleCount() int {
+	if cfg.Samples < 1 {
+		return 1
+	}
+
+	return cfg.Samples
+}
+
+// SampleTemperature returns the temperature to sample the judge at, nil for
+// the provider default.
+func (cfg *LLMJudgeStepConfig) SampleTemperature() *float64 {
+	if cfg.Temperature != nil {
+		return cfg.Temperature
+	}
+	if cfg.SampleCount() > 1 {
+		temperature := DefaultSampleTemperature
+		return &temperature
+	}
+
+	return nil
+}
+
+// RequiredPasses returns how many of the samples must pass for the step to pass.
+func (cfg *LLMJudgeStepConfig) RequiredPasses() int {
+	threshold := cfg.AgreementThreshold
+	if threshold == 0 {
+		threshold = DefaultAgreementThreshold
+	}
+
+	return max(1, int(math.Ceil(threshold*float64(cfg.SampleCount())-1e-9)))
+}
+
 func (cfg *LLMJudgeStepConfig) Validate() error {
 	if cfg.Exact == "" && cfg.Contains == "" {
 		return fmt.Errorf("one of contains or exact must be specified")
@@ -52,5 +168,17 @@ func (cfg *LLMJudgeStepConfig) Validate() error {
 		return fmt.Errorf("only one of contains or exact can be specified, not both")
 	}
 
+	if cfg.Samples < 0 {
+		return fmt.Errorf("samples must not be negative")
+	}
+
+	if cfg.AgreementThreshold < 0 || cfg.AgreementThreshold > 1 {
+		return fmt.Errorf("agreementThreshold must be between 0 and 1")
+	}
+
+	if cfg.Temperature != nil && *cfg.Temperature < 0 {
+		return fmt.Errorf("temperature must not be negative")
+	}
+
 	return nil
 }
@@ -0,0 +1,90 @@
+package llmjudge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLLMJudgeRetryConfig(t *testing.T) {
+	tt := map[string]struct {
+		cfg              *LLMJudgeRetryConfig
+		expectAttempts   int
+		expectBackoff    time.Duration
+		expectBackoffErr bool
+	}{
+		"not configured": {
+			expectAttempts: 1,
+			expectBackoff:  defaultRetryBackoff,
+		},
+		"attempts with default backoff": {
+			cfg:            &LLMJudgeRetryConfig{MaxAttempts: 3},
+			expectAttempts: 3,
+			expectBackoff:  defaultRetryBackoff,
+		},
+		"custom backoff": {
+			cfg:            &LLMJudgeRetryConfig{MaxAttempts: 5, Backoff: "500ms"},
+			expectAttempts: 5,
+			expectBackoff:  500 * time.Millisecond,
+		},
+		"invalid backoff": {
+			cfg:              &LLMJudgeRetryConfig{MaxAttempts: 2, Backoff: "soon"},
+			expectAttempts:   2,
+			expectBackoffErr: true,
+		},
+		"negative backoff": {
+			cfg:              &LLMJudgeRetryConfig{Backoff: "-1s"},
+			expectAttempts:   1,
+			expectBackoffErr: true,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expectAttempts, tc.cfg.GetMaxAttempts())
+
+			// Configured judge calls are retried instead of their model requests
+			if tc.cfg == nil {
+				assert.Nil(t, tc.cfg.modelRetry())
+			} else {
+				assert.Equal(t, 0, tc.cfg.modelRetry().GetMaxRetries())
+			}
+
+			backoff, err := tc.cfg.GetBackoff()
+			if tc.expectBackoffErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectBackoff, backoff)
+		})
+	}
+}
+
+func TestLLMJudgeStepConfigSampleTemperature(t *testing.T) {
+	zero, sampling := 0.0, DefaultSampleTemperature
+	tt := map[string]struct {
+		cfg    LLMJudgeStepConfig
+		expect *float64
+	}{
+		"single sample": {
+			cfg: LLMJudgeStepConfig{Contains: "ok"},
+		},
+		"several samples": {
+			cfg:    LLMJudgeStepConfig{Contains: "ok", Samples: 3},
+			expect: &sampling,
+		},
+		"configured temperature": {
+			cfg:    LLMJudgeStepConfig{Contains: "ok", Samples: 3, Temperature: &zero},
+			expect: &zero,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, tc.cfg.SampleTemperature())
+		})
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/mcpchecker/mcpchecker/pkg/agent"
@@ -12,11 +15,28 @@ import (
 )
 
 type LLMJudge interface {
-	EvaluateText(ctx context.Context, judgeConfig *LLMJudgeStepConfig, prompt, output string) (*LLMJudgeResult, error)
+	// EvaluateText judges the agent output for prompt against the step's reference answer.
+	// toolCalls are the calls the agent made, available to prompt templates as a summary.
+	// The calls to the judge agent are recorded in audit, if it isn't nil.
+	EvaluateText(ctx context.Context, judgeConfig *LLMJudgeStepConfig, prompt, output string, toolCalls []agent.ToolCallSummary, audit *AuditLog) (*LLMJudgeResult, error)
 	ModelName() string
 	Close() error
 }
 
+// JudgeError reports that the judge could not produce a verdict, for example because
+// the provider rejected the request. It is distinct from a failing verdict.
+type JudgeError struct {
+	Err error
+}
+
+func (e *JudgeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *JudgeError) Unwrap() error {
+	return e.Err
+}
+
 type LLMJudgeResult struct {
 	Passed          bool          `json:"passed"`
 	Reason          string        `json:"reason"`
@@ -25,15 +45,28 @@ type LLMJudgeResult struct {
 }
 
 type llmJudge struct {
-	runner agent.Runner
-	name   string
-	server *judgeServer
-	cancel context.CancelFunc
+	runner      agent.Runner
+	name        string
+	prompts     *PromptTemplates
+	maxAttempts int
+	backoff     time.Duration
+	server      *judgeServer
+	cancel      context.CancelFunc
+
+	// newRunner creates a runner of the judge agent sampling at a temperature.
+	// It is nil when the judge agent chooses its temperature itself.
+	newRunner func(temperature float64) (agent.Runner, error)
+	// runners caches the runners of newRunner by temperature, so that the
+	// samples of all steps share them
+	runnersMu sync.Mutex
+	runners   map[float64]agent.Runner
+	// temperatureWarning warns once that the judge agent ignores the sample temperature
+	temperatureWarning sync.Once
 }
 
 type noopLLMJudge struct{}
 
-func (n *noopLLMJudge) EvaluateText(ctx context.Context, judgeConfig *LLMJudgeStepConfig, prompt, output string) (*LLMJudgeResult, error) {
+func (n *noopLLMJudge) EvaluateText(ctx context.Context, judgeConfig *LLMJudgeStepConfig, prompt, output string, toolCalls []agent.ToolCallSummary, audit *AuditLog) (*LLMJudgeResult, error) {
 	return &LLMJudgeResult{
 		Passed:          true,
 		Reason:          "noop judge always passes",
@@ -49,7 +82,10 @@ func (n *noopLLMJudge) Close() error {
 	return nil
 }
 
-func NewLLMJudge(cfg *LLMJudgeEvalConfig) (LLMJudge, error) {
+// NewLLMJudge creates the judge of cfg. opts configure its agent runner, such
+// as the rate limiter it shares with the agent of the run. The retries of cfg,
+// if set, replace the model retries of opts.
+func NewLLMJudge(cfg *LLMJudgeEvalConfig, opts ...agent.RunnerOption) (LLMJudge, error) {
 	if cfg == nil {
 		return &noopLLMJudge{}, nil
 	}
@@ -69,17 +105,38 @@ func NewLLMJudge(cfg *LLMJudgeEvalConfig) (LLMJudge, error) {
 		return nil, fmt.Errorf("llm judge requires either an agent ref or env config")
 	}
 
+	prompts, err := LoadPromptTemplates(cfg.Prompts)
+	if err != nil {
+		return nil, err
+	}
+
+	backoff, err := cfg.Retry.GetBackoff()
+	if err != nil {
+		return nil, err
+	}
+	if retry := cfg.Retry.modelRetry(); retry != nil {
+		opts = append(slices.Clone(opts), agent.WithModelRetry(retry))
+	}
+
 	// Resolve agent ref to spec, then to runner
 	spec, err := agent.ResolveAgentRef(ref)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve judge agent ref: %w", err)
 	}
 
-	runner, err := agent.NewRunnerForSpec(spec)
+	runner, err := agent.NewRunnerForSpec(spec, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create judge agent runner: %w", err)
 	}
 
+	// Only builtin LLM agents can be told their temperature
+	var newRunner func(float64) (agent.Runner, error)
+	if spec.AcpConfig == nil && spec.Builtin != nil && spec.Builtin.Type != "claude-code" {
+		newRunner = func(temperature float64) (agent.Runner, error) {
+			return agent.NewRunnerForSpec(spec, append(slices.Clone(opts), agent.WithTemperature(temperature))...)
+		}
+	}
+
 	// Start the judge MCP server
 	server := newJudgeServer()
 	serverCtx, cancel := context.WithCancel(context.Background())
@@ -94,42 +151,129 @@ func NewLLMJudge(cfg *LLMJudgeEvalConfig) (LLMJudge, error) {
 	}
 
 	return &llmJudge{
-		runner: runner,
-		name:   runner.AgentName(),
-		server: server,
-		cancel: cancel,
+		runner:      runner,
+		newRunner:   newRunner,
+		name:        runner.AgentName(),
+		prompts:     prompts,
+		maxAttempts: cfg.Retry.GetMaxAttempts(),
+		backoff:     backoff,
+		server:      server,
+		cancel:      cancel,
 	}, nil
 }
 
-func (j *llmJudge) EvaluateText(ctx context.Context, judgeConfig *LLMJudgeStepConfig, prompt, output string) (*LLMJudgeResult, error) {
-	systemPrompt, err := BuildSystemPrompt(SystemPromptData{
+func (j *llmJudge) EvaluateText(ctx context.Context, judgeConfig *LLMJudgeStepConfig, prompt, output string, toolCalls []agent.ToolCallSummary, audit *AuditLog) (*LLMJudgeResult, error) {
+	data := PromptData{
 		EvaluationMode:  judgeConfig.EvaluationMode(),
 		ReferenceAnswer: judgeConfig.ReferenceAnswer(),
-	})
+		UserPrompt:      prompt,
+		ModelResponse:   output,
+		ToolSummary:     FormatToolSummary(toolCalls),
+	}
+
+	systemPrompt, err := j.prompts.BuildSystemPrompt(data)
 	if err != nil {
 		return nil, err
 	}
 
-	userPrompt, err := BuildUserPrompt(UserPromptData{
-		UserPrompt:    prompt,
-		ModelResponse: output,
-	})
+	userPrompt, err := j.prompts.BuildUserPrompt(data)
 	if err != nil {
 		return nil, err
 	}
 
 	combinedPrompt := systemPrompt + "\n\n" + userPrompt
 
+	runner := j.runner
+	if temperature := judgeConfig.SampleTemperature(); temperature != nil {
+		switch {
+		case j.newRunner != nil:
+			runner, err = j.runnerAt(*temperature)
+			if err != nil {
+				return nil, err
+			}
+		case judgeConfig.Temperature != nil:
+			return nil, fmt.Errorf("judge agent %q does not support setting a temperature: use a builtin.llm-agent judge or remove temperature from the step", j.name)
+		default:
+			j.temperatureWarning.Do(func() {
+				fmt.Fprintf(os.Stderr, "WARNING: LLM judge %q chooses its own temperature, "+
+					"its samples may all give the same verdict\n", j.name)
+			})
+		}
+	}
+
+	// Judge calls are only retried here when llmJudge.retry is set, in which case
+	// NewLLMJudge turned off the model retries of the judge agent
+	delay := j.backoff
+	for attempt := 1; ; attempt++ {
+		entry := AuditEntry{
+			Time:         time.Now(),
+			Model:        j.name,
+			Attempt:      attempt,
+			Data:         data,
+			SystemPrompt: systemPrompt,
+			UserPrompt:   userPrompt,
+		}
+		res, response, err := j.evaluateOnce(ctx, runner, combinedPrompt)
+		entry.Response = response
+		entry.Verdict = res
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		audit.Record(entry)
+
+		if err == nil {
+			return res, nil
+		}
+		if attempt >= j.maxAttempts {
+			if j.maxAttempts > 1 {
+				err = fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+			}
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("judge retry interrupted: %w (last error: %v)", ctx.Err(), err)
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// runnerAt returns the runner of the judge agent sampling at temperature,
+// creating it on first use.
+func (j *llmJudge) runnerAt(temperature float64) (agent.Runner, error) {
+	j.runnersMu.Lock()
+	defer j.runnersMu.Unlock()
+
+	if runner, ok := j.runners[temperature]; ok {
+		return runner, nil
+	}
+
+	runner, err := j.newRunner(temperature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create judge agent runner: %w", err)
+	}
+	if j.runners == nil {
+		j.runners = make(map[float64]agent.Runner)
+	}
+	j.runners[temperature] = runner
+	return runner, nil
+}
+
+// evaluateOnce runs the judge agent once and waits for its submitted verdict.
+// It also returns the output of the judge agent, if it ran.
+func (j *llmJudge) evaluateOnce(ctx context.Context, runner agent.Runner, combinedPrompt string) (*LLMJudgeResult, []agent.OutputStep, error) {
 	requestID := uuid.New().String()
 	resultCh := j.server.RegisterRequest(requestID)
 	defer j.server.DeregisterRequest(requestID)
 
 	manager := &judgeServerManager{server: j.server, requestID: requestID}
-	judgeRunner := j.runner.WithMcpServerInfo(manager)
+	judgeRunner := runner.WithMcpServerInfo(manager)
 
 	result, err := judgeRunner.RunTask(ctx, combinedPrompt)
 	if err != nil {
-		return nil, fmt.Errorf("failed to run judge agent: %w", err)
+		return nil, nil, fmt.Errorf("failed to run judge agent: %w", err)
 	}
 
 	estimate := result.GetTokenEstimate()
@@ -137,9 +281,9 @@ func (j *llmJudge) EvaluateText(ctx context.Context, judgeConfig *LLMJudgeStepCo
 	select {
 	case res := <-resultCh:
 		res.Usage = estimate.ToUsage()
-		return res, nil
+		return res, result.GetOutput(), nil
 	default:
-		return nil, fmt.Errorf("judge agent completed without calling submit_judgement tool")
+		return nil, result.GetOutput(), fmt.Errorf("judge agent completed without calling submit_judgement tool")
 	}
 }
 
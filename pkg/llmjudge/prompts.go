@@ -2,7 +2,13 @@ package llmjudge
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
 	"text/template"
+
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
 )
 
 var (
@@ -58,33 +64,136 @@ Evaluate whether the content in <model_output_to_evaluate> contains all the core
 `))
 )
 
-type SystemPromptData struct {
+// PromptData is the data available to the system and user prompt templates.
+type PromptData struct {
 	// EvaluationMode should be "CONTAINS" or "EXACT"
-	EvaluationMode  string
-	ReferenceAnswer string
+	EvaluationMode  string `json:"evaluationMode"`
+	ReferenceAnswer string `json:"referenceAnswer"`
+	UserPrompt      string `json:"userPrompt"`
+	ModelResponse   string `json:"modelResponse"`
+	// ToolSummary lists the tool calls the agent made, one per line
+	ToolSummary string `json:"toolSummary"`
 }
 
-type UserPromptData struct {
-	UserPrompt    string
-	ModelResponse string
+// maxToolSummaryValueLength bounds the tool input and output included per call in ToolSummary.
+const maxToolSummaryValueLength = 500
+
+// PromptTemplates holds the system and user prompt templates used by a judge.
+type PromptTemplates struct {
+	system *template.Template
+	user   *template.Template
 }
 
-func BuildSystemPrompt(data SystemPromptData) (string, error) {
-	var out bytes.Buffer
-	err := systemPromptTemplate.Execute(&out, data)
+// DefaultPromptTemplates returns the built-in judge prompt templates.
+func DefaultPromptTemplates() *PromptTemplates {
+	return &PromptTemplates{
+		system: systemPromptTemplate,
+		user:   userPromptTemplate,
+	}
+}
+
+// LoadPromptTemplates returns the built-in templates with the files in cfg replacing
+// them. A nil cfg returns the built-in templates.
+func LoadPromptTemplates(cfg *LLMJudgePromptsConfig) (*PromptTemplates, error) {
+	t := DefaultPromptTemplates()
+	if cfg == nil {
+		return t, nil
+	}
+
+	var err error
+	if cfg.SystemFile != "" {
+		t.system, err = parsePromptFile("systemPrompt", cfg.SystemFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.UserFile != "" {
+		t.user, err = parsePromptFile("userPrompt", cfg.UserFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+func parsePromptFile(name, path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to read judge prompt template: %w", err)
 	}
 
-	return out.String(), nil
+	t, err := template.New(name).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse judge prompt template %s: %w", path, err)
+	}
+
+	return t, nil
 }
 
-func BuildUserPrompt(data UserPromptData) (string, error) {
+// BuildSystemPrompt renders the system prompt template.
+func (t *PromptTemplates) BuildSystemPrompt(data PromptData) (string, error) {
+	return executePromptTemplate(t.system, data)
+}
+
+// BuildUserPrompt renders the user prompt template.
+func (t *PromptTemplates) BuildUserPrompt(data PromptData) (string, error) {
+	return executePromptTemplate(t.user, data)
+}
+
+func executePromptTemplate(t *template.Template, data PromptData) (string, error) {
 	var out bytes.Buffer
-	err := userPromptTemplate.Execute(&out, data)
+	err := t.Execute(&out, data)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to render judge prompt template: %w", err)
 	}
 
 	return out.String(), nil
 }
+
+// FormatToolSummary renders the agent's tool calls for the ToolSummary placeholder.
+func FormatToolSummary(calls []agent.ToolCallSummary) string {
+	if len(calls) == 0 {
+		return "No tool calls were made."
+	}
+
+	var out strings.Builder
+	for i, call := range calls {
+		fmt.Fprintf(&out, "%d. %s", i+1, call.Title)
+		if call.Status != "" {
+			fmt.Fprintf(&out, " (%s)", call.Status)
+		}
+		out.WriteString("\n")
+		if input := formatToolSummaryValue(call.RawInput); input != "" {
+			fmt.Fprintf(&out, "   input: %s\n", input)
+		}
+		if output := formatToolSummaryValue(call.RawOutput); output != "" {
+			fmt.Fprintf(&out, "   output: %s\n", output)
+		}
+	}
+
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+func formatToolSummaryValue(v any) string {
+	if v == nil {
+		return ""
+	}
+
+	var s string
+	if str, ok := v.(string); ok {
+		s = str
+	} else {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		s = string(data)
+	}
+
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > maxToolSummaryValueLength {
+		s = s[:maxToolSummaryValueLength] + "..."
+	}
+	return s
+}
@@ -0,0 +1,119 @@
+package llmjudge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPromptTemplates(t *testing.T) {
+	data := PromptData{
+		EvaluationMode:  EvaluationModeContains,
+		ReferenceAnswer: "nginx is running",
+		UserPrompt:      "is nginx running?",
+		ModelResponse:   "yes, nginx is running",
+		ToolSummary:     "1. pods_list (completed)",
+	}
+
+	tt := map[string]struct {
+		system       string
+		user         string
+		expectErr    bool
+		expectSystem string
+		expectUser   string
+	}{
+		"built-in templates": {
+			expectSystem: "nginx is running",
+			expectUser:   "yes, nginx is running",
+		},
+		"custom system template keeps built-in user template": {
+			system:       "Judge {{.EvaluationMode}} against {{.ReferenceAnswer}}",
+			expectSystem: "Judge CONTAINS against nginx is running",
+			expectUser:   "<model_output_to_evaluate>",
+		},
+		"custom user template with tool summary": {
+			user:       "{{.UserPrompt}}\n{{.ModelResponse}}\n{{.ToolSummary}}",
+			expectUser: "is nginx running?\nyes, nginx is running\n1. pods_list (completed)",
+		},
+		"invalid template": {
+			system:    "{{.ReferenceAnswer",
+			expectErr: true,
+		},
+		"unknown placeholder": {
+			user:      "{{.Reference}}",
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			cfg := &LLMJudgePromptsConfig{}
+			if tc.system != "" {
+				cfg.SystemFile = filepath.Join(dir, "system.tmpl")
+				require.NoError(t, os.WriteFile(cfg.SystemFile, []byte(tc.system), 0644))
+			}
+			if tc.user != "" {
+				cfg.UserFile = filepath.Join(dir, "user.tmpl")
+				require.NoError(t, os.WriteFile(cfg.UserFile, []byte(tc.user), 0644))
+			}
+
+			system, user, err := renderPrompts(cfg, data)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Contains(t, system, tc.expectSystem)
+			assert.Contains(t, user, tc.expectUser)
+		})
+	}
+}
+
+func renderPrompts(cfg *LLMJudgePromptsConfig, data PromptData) (string, string, error) {
+	templates, err := LoadPromptTemplates(cfg)
+	if err != nil {
+		return "", "", err
+	}
+
+	system, err := templates.BuildSystemPrompt(data)
+	if err != nil {
+		return "", "", err
+	}
+
+	user, err := templates.BuildUserPrompt(data)
+	if err != nil {
+		return "", "", err
+	}
+
+	return system, user, nil
+}
+
+func TestFormatToolSummary(t *testing.T) {
+	tt := map[string]struct {
+		calls    []agent.ToolCallSummary
+		expected string
+	}{
+		"no calls": {
+			expected: "No tool calls were made.",
+		},
+		"calls with input and output": {
+			calls: []agent.ToolCallSummary{
+				{Title: "pods_list", Status: "completed", RawInput: map[string]any{"namespace": "default"}, RawOutput: "nginx\n  Running"},
+				{Title: "pods_delete"},
+			},
+			expected: "1. pods_list (completed)\n   input: {\"namespace\":\"default\"}\n   output: nginx Running\n2. pods_delete",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, FormatToolSummary(tc.calls))
+		})
+	}
+}
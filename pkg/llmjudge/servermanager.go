@@ -88,6 +88,10 @@ func (m *judgeServerManager) GetCallHistoryForServer(serverName string) (mcpprox
 	return mcpproxy.CallHistory{}, false
 }
 
+func (m *judgeServerManager) ResetCallHistory() *mcpproxy.CallHistory {
+	return &mcpproxy.CallHistory{}
+}
+
 var _ mcpproxy.Server = &judgeServerProxy{}
 
 // noop for llm judge
@@ -124,6 +128,11 @@ func (s *judgeServerProxy) GetCallHistory() mcpproxy.CallHistory {
 	return mcpproxy.CallHistory{}
 }
 
+// ResetCallHistory returns the MCP calls made since the last reset
+func (s *judgeServerProxy) ResetCallHistory() mcpproxy.CallHistory {
+	return mcpproxy.CallHistory{}
+}
+
 // WaitReady blocks until the server has initialized and is ready to serve
 func (s *judgeServerProxy) WaitReady(ctx context.Context) error {
 	return nil
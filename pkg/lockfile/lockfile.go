@@ -11,6 +11,9 @@ import (
 
 const CurrentVersion = 1
 
+// FileName is the name of the lockfile, next to the eval config
+const FileName = "mcpchecker.lock"
+
 var (
 	commitSHARegex = regexp.MustCompile(`^[0-9a-f]{40}$`)
 	hashRegex      = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
@@ -2,6 +2,8 @@ package mcpclient
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
 	"os"
@@ -23,8 +25,12 @@ func Connect(ctx context.Context, cfg *ServerConfig) (*Client, error) {
 		for k, v := range cfg.Headers {
 			hdrs.Set(k, v)
 		}
+		base, err := httpTransport(cfg)
+		if err != nil {
+			return nil, err
+		}
 		client := &http.Client{
-			Transport: NewHeaderRoundTripper(hdrs, nil),
+			Transport: NewHeaderRoundTripper(hdrs, base),
 		}
 
 		transport = &mcp.StreamableClientTransport{
@@ -83,3 +89,28 @@ func buildEnv(env map[string]string) []string {
 
 	return full
 }
+
+// httpTransport returns the transport for the http server of cfg, trusting
+// its CA file if it has one. It returns nil to use http.DefaultTransport.
+func httpTransport(cfg *ServerConfig) (http.RoundTripper, error) {
+	if cfg.CAFile == "" {
+		return nil, nil
+	}
+
+	caPEM, err := os.ReadFile(cfg.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ca file: %w", err)
+	}
+
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in ca file %q", cfg.CAFile)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: roots}
+	return transport, nil
+}
@@ -49,6 +49,11 @@ type ServerConfig struct {
 	// Used for http servers. Values may contain environment variable references
 	Headers map[string]string `json:"headers,omitempty"`
 
+	// CAFile is a PEM encoded CA certificate to trust in addition to the
+	// system roots, for servers with self-signed certificates
+	// Used for https servers
+	CAFile string `json:"caFile,omitempty"`
+
 	// Disabled indicates whether this server should be skipped
 	Disabled bool `json:"disabled,omitempty"`
 
@@ -1,9 +1,21 @@
 package mcpclient
 
 import (
+	"context"
 	"net/http"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
+type requestClockKey struct{}
+
+// WithRequestClock makes the requests of the MCP calls made with ctx carry the
+// time of clock as their Date header. Proxy servers use it to pass the clock
+// of a task run on to the MCP servers they forward its calls to.
+func WithRequestClock(ctx context.Context, clock *util.Clock) context.Context {
+	return context.WithValue(ctx, requestClockKey{}, clock)
+}
+
 // HeaderRoundTripper wraps an http.RoundTripper and adds custom headers to every request.
 type HeaderRoundTripper struct {
 	// Headers are the multi-value headers to add to each request.
@@ -26,12 +38,16 @@ func NewHeaderRoundTripper(headers http.Header, transport http.RoundTripper) *He
 
 // RoundTrip implements the http.RoundTripper interface.
 // It adds the configured headers to the request before passing it to the underlying transport.
+// Requests made with a clock, see WithRequestClock, also get its time as the
+// Date header.
 func (h *HeaderRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 	for key, values := range h.Headers {
 		for _, value := range values {
 			req.Header.Add(key, value)
 		}
 	}
+	clock, _ := req.Context().Value(requestClockKey{}).(*util.Clock)
+	clock.SetDateHeader(req)
 
 	return h.Transport.RoundTrip(req)
 }
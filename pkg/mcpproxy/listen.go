@@ -0,0 +1,336 @@
+package mcpproxy
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+const (
+	defaultListenHost = "localhost"
+
+	// selfSignedCertFileName is the file the generated certificate is written
+	// to, so that clients of the proxy servers can trust it
+	selfSignedCertFileName = "proxy-ca.pem"
+	selfSignedCertValidity = 7 * 24 * time.Hour
+)
+
+// ListenConfig sets where the proxy servers listen and how agents connect to
+// them. By default they listen on ephemeral localhost ports without TLS.
+type ListenConfig struct {
+	// Host is the address the proxy servers bind to ("localhost" by default),
+	// e.g. "0.0.0.0" for agents running in containers or remote sandboxes
+	Host string `json:"host,omitempty"`
+
+	// AdvertiseHost is the host agents connect to, written to the MCP config
+	// passed to agents. Defaults to Host, or "localhost" if Host binds to all
+	// interfaces
+	AdvertiseHost string `json:"advertiseHost,omitempty"`
+
+	// MinPort and MaxPort limit the ports the proxy servers listen on to a
+	// range, e.g. one that is forwarded to the agent sandbox. Ephemeral ports
+	// are used when unset; MaxPort defaults to MinPort
+	MinPort int `json:"minPort,omitempty"`
+	MaxPort int `json:"maxPort,omitempty"`
+
+	// TLS serves the proxy servers over HTTPS
+	TLS *ListenTLSConfig `json:"tls,omitempty"`
+
+	// Token is the bearer token that requests to the proxy servers must
+	// send, written to the MCP config passed to agents as their Authorization
+	// header. It is required when Host accepts connections from other
+	// machines, since the proxy servers call the MCP servers for anyone who
+	// can reach them
+	Token string `json:"token,omitempty"`
+}
+
+// ListenTLSConfig sets the certificate the proxy servers serve: either a
+// certificate generated for the run, or one read from files.
+type ListenTLSConfig struct {
+	// SelfSigned generates a self-signed certificate for the run. It is
+	// written to a file passed to agents as the caFile of the proxy servers
+	SelfSigned bool `json:"selfSigned,omitempty"`
+
+	// CertFile and KeyFile are the PEM encoded certificate and key to serve
+	CertFile string `json:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+
+	// CAFile is a PEM encoded CA certificate passed to agents as the caFile of
+	// the proxy servers, for certificates that aren't signed by a trusted CA
+	CAFile string `json:"caFile,omitempty"`
+}
+
+// Validate checks the port range and that TLS either generates a certificate
+// or reads one from files.
+func (c *ListenConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.MinPort < 0 || c.MinPort > 65535 || c.MaxPort < 0 || c.MaxPort > 65535 {
+		return fmt.Errorf("ports must be between 1 and 65535, or 0 for ephemeral ports, got minPort %d and maxPort %d", c.MinPort, c.MaxPort)
+	}
+	if c.MaxPort != 0 && c.MinPort == 0 {
+		return fmt.Errorf("maxPort requires minPort")
+	}
+	if c.MaxPort != 0 && c.MaxPort < c.MinPort {
+		return fmt.Errorf("maxPort %d is lower than minPort %d", c.MaxPort, c.MinPort)
+	}
+
+	if host := c.Host; host != "" && !util.IsLoopbackHost(host) && c.Token == "" {
+		return fmt.Errorf("host %q accepts connections from other machines: a token is required", host)
+	}
+
+	if c.TLS != nil {
+		hasFiles := c.TLS.CertFile != "" || c.TLS.KeyFile != ""
+		if c.TLS.SelfSigned == hasFiles {
+			return fmt.Errorf("tls requires either selfSigned or certFile and keyFile")
+		}
+		if hasFiles && (c.TLS.CertFile == "" || c.TLS.KeyFile == "") {
+			return fmt.Errorf("tls requires both certFile and keyFile")
+		}
+		if c.TLS.SelfSigned && c.TLS.CAFile != "" {
+			return fmt.Errorf("tls caFile can't be set with selfSigned, the generated certificate is used")
+		}
+	}
+
+	return nil
+}
+
+// Listener opens the listeners of proxy servers as set by a ListenConfig. A
+// nil *Listener listens on ephemeral localhost ports without TLS.
+type Listener struct {
+	host          string
+	advertiseHost string
+	minPort       int
+	maxPort       int
+
+	tlsConfig *tls.Config
+	caFile    string
+	tmpDir    string
+	token     string
+
+	// next spreads concurrent listeners over the port range
+	next atomic.Uint32
+}
+
+// NewListener creates a Listener for cfg, reading or generating its TLS
+// certificate. Close removes the generated certificate. A nil cfg returns a
+// nil *Listener.
+func NewListener(cfg *ListenConfig) (*Listener, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	l := &Listener{
+		host:          cfg.Host,
+		advertiseHost: cfg.AdvertiseHost,
+		minPort:       cfg.MinPort,
+		maxPort:       cfg.MaxPort,
+		token:         cfg.Token,
+	}
+	util.RegisterSecret(l.token)
+	if l.host == "" {
+		l.host = defaultListenHost
+	}
+	if l.advertiseHost == "" {
+		l.advertiseHost = l.host
+		if ip := net.ParseIP(l.host); ip != nil && ip.IsUnspecified() {
+			l.advertiseHost = defaultListenHost
+		}
+	}
+	if l.minPort != 0 && l.maxPort == 0 {
+		l.maxPort = l.minPort
+	}
+
+	if cfg.TLS == nil {
+		return l, nil
+	}
+
+	if !cfg.TLS.SelfSigned {
+		cert, err := tls.LoadX509KeyPair(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tls certificate: %w", err)
+		}
+		l.tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		l.caFile = cfg.TLS.CAFile
+		return l, nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "mcpchecker-proxy-tls-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create directory for self-signed certificate: %w", err)
+	}
+
+	certFile := filepath.Join(tmpDir, selfSignedCertFileName)
+	cert, err := generateSelfSignedCert(certFile, l.host, l.advertiseHost)
+	if err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return nil, fmt.Errorf("failed to generate self-signed certificate: %w", err)
+	}
+
+	l.tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	l.caFile = certFile
+	l.tmpDir = tmpDir
+	return l, nil
+}
+
+// Listen opens a listener for a proxy server and returns it with the base URL
+// agents connect to, without a path.
+func (l *Listener) Listen() (net.Listener, string, error) {
+	if l == nil {
+		ln, err := net.Listen("tcp", net.JoinHostPort(defaultListenHost, "0"))
+		if err != nil {
+			return nil, "", err
+		}
+		return ln, "http://" + ln.Addr().String(), nil
+	}
+
+	ln, err := l.listen()
+	if err != nil {
+		return nil, "", err
+	}
+
+	scheme := "http"
+	if l.tlsConfig != nil {
+		ln = tls.NewListener(ln, l.tlsConfig)
+		scheme = "https"
+	}
+
+	port := ln.Addr().(*net.TCPAddr).Port
+	return ln, fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(l.advertiseHost, strconv.Itoa(port))), nil
+}
+
+// listen binds to an ephemeral port, or the first free port of the range.
+func (l *Listener) listen() (net.Listener, error) {
+	if l.minPort == 0 {
+		return net.Listen("tcp", net.JoinHostPort(l.host, "0"))
+	}
+
+	size := l.maxPort - l.minPort + 1
+	start := int(l.next.Add(1)-1) % size
+
+	var lastErr error
+	for i := range size {
+		port := l.minPort + (start+i)%size
+		ln, err := net.Listen("tcp", net.JoinHostPort(l.host, strconv.Itoa(port)))
+		if err == nil {
+			return ln, nil
+		}
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("no free port between %d and %d: %w", l.minPort, l.maxPort, lastErr)
+}
+
+// CAFile returns the CA certificate file clients need to trust the proxy
+// servers, if any.
+func (l *Listener) CAFile() string {
+	if l == nil {
+		return ""
+	}
+	return l.caFile
+}
+
+// authorize returns handler, serving only requests with the bearer token of
+// the listener if it has one.
+func (l *Listener) authorize(handler http.Handler) http.Handler {
+	if l == nil || l.token == "" {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(l.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "missing or invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// authorizationHeader returns the Authorization header clients send to the
+// proxy servers, or an empty string if they don't need one.
+func (l *Listener) authorizationHeader() string {
+	if l == nil || l.token == "" {
+		return ""
+	}
+	return "Bearer " + l.token
+}
+
+// Close removes the generated certificate. Proxy servers must be closed first.
+func (l *Listener) Close() error {
+	if l == nil || l.tmpDir == "" {
+		return nil
+	}
+	return os.RemoveAll(l.tmpDir)
+}
+
+// generateSelfSignedCert generates a certificate for hosts and localhost,
+// writing it to certFile so that clients can trust it.
+func generateSelfSignedCert(certFile string, hosts ...string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"mcpchecker"}, CommonName: "mcpchecker proxy"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedCertValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			if !ip.IsUnspecified() {
+				template.IPAddresses = append(template.IPAddresses, ip)
+			}
+		} else if host != "localhost" {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(certFile, certPEM, 0644); err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
@@ -0,0 +1,230 @@
+package mcpproxy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenConfigValidate(t *testing.T) {
+	tt := map[string]struct {
+		cfg       *ListenConfig
+		expectErr string
+	}{
+		"nil": {},
+		"loopback host": {
+			cfg: &ListenConfig{Host: "127.0.0.1"},
+		},
+		"all interfaces with token": {
+			cfg: &ListenConfig{Host: "0.0.0.0", Token: "proxy-t0ken"},
+		},
+		"all interfaces without token": {
+			cfg:       &ListenConfig{Host: "0.0.0.0"},
+			expectErr: `host "0.0.0.0" accepts connections from other machines: a token is required`,
+		},
+		"single port": {
+			cfg: &ListenConfig{MinPort: 30000},
+		},
+		"port range": {
+			cfg: &ListenConfig{MinPort: 30000, MaxPort: 30010},
+		},
+		"max port without min port": {
+			cfg:       &ListenConfig{MaxPort: 30010},
+			expectErr: "maxPort requires minPort",
+		},
+		"inverted port range": {
+			cfg:       &ListenConfig{MinPort: 30010, MaxPort: 30000},
+			expectErr: "lower than minPort",
+		},
+		"port out of range": {
+			cfg:       &ListenConfig{MinPort: 70000},
+			expectErr: "between 1 and 65535",
+		},
+		"negative port": {
+			cfg:       &ListenConfig{MinPort: -1},
+			expectErr: "between 1 and 65535, or 0 for ephemeral ports",
+		},
+		"self-signed": {
+			cfg: &ListenConfig{TLS: &ListenTLSConfig{SelfSigned: true}},
+		},
+		"cert files": {
+			cfg: &ListenConfig{TLS: &ListenTLSConfig{CertFile: "cert.pem", KeyFile: "key.pem", CAFile: "ca.pem"}},
+		},
+		"empty tls": {
+			cfg:       &ListenConfig{TLS: &ListenTLSConfig{}},
+			expectErr: "either selfSigned or certFile and keyFile",
+		},
+		"self-signed and cert files": {
+			cfg:       &ListenConfig{TLS: &ListenTLSConfig{SelfSigned: true, CertFile: "cert.pem", KeyFile: "key.pem"}},
+			expectErr: "either selfSigned or certFile and keyFile",
+		},
+		"cert file without key file": {
+			cfg:       &ListenConfig{TLS: &ListenTLSConfig{CertFile: "cert.pem"}},
+			expectErr: "both certFile and keyFile",
+		},
+		"self-signed with ca file": {
+			cfg:       &ListenConfig{TLS: &ListenTLSConfig{SelfSigned: true, CAFile: "ca.pem"}},
+			expectErr: "caFile can't be set with selfSigned",
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.expectErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestListenerListen(t *testing.T) {
+	tt := map[string]struct {
+		cfg            *ListenConfig
+		expectedScheme string
+		expectedHost   string
+	}{
+		"default": {
+			expectedScheme: "http",
+			expectedHost:   "127.0.0.1",
+		},
+		"all interfaces": {
+			cfg:            &ListenConfig{Host: "0.0.0.0", Token: "proxy-t0ken"},
+			expectedScheme: "http",
+			expectedHost:   "localhost",
+		},
+		"advertised host": {
+			cfg:            &ListenConfig{Host: "0.0.0.0", AdvertiseHost: "host.docker.internal", Token: "proxy-t0ken"},
+			expectedScheme: "http",
+			expectedHost:   "host.docker.internal",
+		},
+		"self-signed": {
+			cfg:            &ListenConfig{TLS: &ListenTLSConfig{SelfSigned: true}},
+			expectedScheme: "https",
+			expectedHost:   "localhost",
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			l, err := NewListener(tc.cfg)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = l.Close() })
+
+			ln, baseURL, err := l.Listen()
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = ln.Close() })
+
+			u, err := url.Parse(baseURL)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedScheme, u.Scheme)
+			assert.Equal(t, tc.expectedHost, u.Hostname())
+			assert.Equal(t, strconv.Itoa(ln.Addr().(*net.TCPAddr).Port), u.Port())
+		})
+	}
+}
+
+func TestListenerPortRange(t *testing.T) {
+	// Find two free consecutive ports
+	var minPort int
+	for range 10 {
+		ln, err := net.Listen("tcp", "localhost:0")
+		require.NoError(t, err)
+		minPort = ln.Addr().(*net.TCPAddr).Port
+		_ = ln.Close()
+
+		next, err := net.Listen("tcp", net.JoinHostPort("localhost", strconv.Itoa(minPort+1)))
+		if err == nil {
+			_ = next.Close()
+			break
+		}
+	}
+
+	l, err := NewListener(&ListenConfig{MinPort: minPort, MaxPort: minPort + 1})
+	require.NoError(t, err)
+
+	ports := map[int]bool{}
+	for range 2 {
+		ln, _, err := l.Listen()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = ln.Close() })
+		ports[ln.Addr().(*net.TCPAddr).Port] = true
+	}
+	assert.Equal(t, map[int]bool{minPort: true, minPort + 1: true}, ports)
+
+	_, _, err = l.Listen()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no free port")
+}
+
+func TestServerManagerSelfSignedTLS(t *testing.T) {
+	ctx := context.Background()
+
+	l, err := NewListener(&ListenConfig{TLS: &ListenTLSConfig{SelfSigned: true}})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	m, err := NewServerManager(ctx, startEchoServer(t), WithListener(l))
+	require.NoError(t, err)
+	require.NoError(t, m.Start(ctx))
+	t.Cleanup(func() { _ = m.Close() })
+
+	// The MCP config passed to agents trusts the generated certificate
+	client := connectThrough(t, m)
+	assert.Equal(t, l.CAFile(), client.GetConfig().CAFile)
+	assert.Contains(t, client.GetConfig().URL, "https://")
+
+	_, err = client.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	assert.Len(t, m.GetAllCallHistory().ToolCalls, 1)
+}
+
+func TestServerManagerToken(t *testing.T) {
+	ctx := context.Background()
+
+	l, err := NewListener(&ListenConfig{Token: "proxy-t0ken"})
+	require.NoError(t, err)
+
+	m, err := NewServerManager(ctx, startEchoServer(t), WithListener(l))
+	require.NoError(t, err)
+	require.NoError(t, m.Start(ctx))
+	t.Cleanup(func() { _ = m.Close() })
+
+	// The MCP config passed to agents sends the token
+	client := connectThrough(t, m)
+	assert.Equal(t, "Bearer proxy-t0ken", client.GetConfig().Headers["Authorization"])
+	_, err = client.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{}})
+	require.NoError(t, err)
+
+	// Requests without it are refused
+	resp, err := http.Post(client.GetConfig().URL, "application/json", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestServerManagerHeaders(t *testing.T) {
+	ctx := context.Background()
+
+	m, err := NewServerManager(ctx, startEchoServer(t), WithHeaders(map[string]string{"Mcpchecker-Run-Id": "run-1"}))
+	require.NoError(t, err)
+	require.NoError(t, m.Start(ctx))
+	t.Cleanup(func() { _ = m.Close() })
+
+	// The MCP config passed to agents sends the headers
+	client := connectThrough(t, m)
+	assert.Equal(t, "run-1", client.GetConfig().Headers["Mcpchecker-Run-Id"])
+	_, err = client.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{}})
+	require.NoError(t, err)
+}
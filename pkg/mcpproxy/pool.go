@@ -0,0 +1,255 @@
+package mcpproxy
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// partitionHeader carries the partition of a request to the recorder of a
+// proxy server. It is only set from the URL path, see partitionHandler, and
+// removed from requests without a partition, see unpartitionedHandler.
+const partitionHeader = "X-Mcpchecker-Partition"
+
+// ServerPool keeps proxy servers running across task runs, so that suites of
+// many short tasks don't start new proxy servers for every task. Each task run
+// acquires a ServerManager that connects to the shared servers through its own
+// URL path, which the servers use to record its calls apart from the calls of
+// other task runs.
+type ServerPool struct {
+	shared  *serverManager
+	servers map[string]*server
+	next    atomic.Uint64
+}
+
+// NewServerPool creates a pool of proxy servers for the clients of manager.
+func NewServerPool(ctx context.Context, manager mcpclient.Manager, opts ...ServerOption) (*ServerPool, error) {
+	o := newServerOptions(opts)
+	clients, shadows := manager.GetAll(), shadowsOf(manager)
+
+	servers := make(map[string]*server, len(clients))
+	shared := make(map[string]Server, len(clients))
+	for name, client := range clients {
+		s, err := newProxyServer(ctx, name, client, shadows[name], o)
+		if err != nil {
+			return nil, err
+		}
+
+		servers[name] = s
+		shared[name] = s
+	}
+
+	return &ServerPool{
+		shared:  &serverManager{servers: shared},
+		servers: servers,
+	}, nil
+}
+
+// Start blocks until all servers of the pool are ready or an error occurs.
+func (p *ServerPool) Start(ctx context.Context) error {
+	return p.shared.Start(ctx)
+}
+
+// Close stops the servers of the pool. Task runs must be done with the
+// managers they acquired.
+func (p *ServerPool) Close() error {
+	return p.shared.Close()
+}
+
+// Acquire returns a ServerManager for a single task run, recording only the
+// calls made by that run. Starting and closing it doesn't start or stop the
+// servers of the pool; closing it stops recording the calls of the run. The
+// requests of the run carry the time of clock, nil for the real time.
+func (p *ServerPool) Acquire(clock *util.Clock) ServerManager {
+	partition := strconv.FormatUint(p.next.Add(1), 10)
+
+	servers := make(map[string]Server, len(p.servers))
+	for name, s := range p.servers {
+		s.setClock(partition, clock)
+		servers[name] = &partitionServer{
+			server:    s,
+			partition: partition,
+			recorder:  s.recorder.add(partition),
+		}
+	}
+
+	return &serverManager{servers: servers}
+}
+
+// partitionServer is the view of a pooled server for a single task run.
+type partitionServer struct {
+	*server
+	partition string
+	recorder  Recorder
+}
+
+var _ Server = &partitionServer{}
+
+// Run blocks until ctx is canceled, the pooled server is run by its pool.
+func (s *partitionServer) Run(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (s *partitionServer) GetConfig() (*mcpclient.ServerConfig, error) {
+	cfg, err := s.server.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.URL = cfg.URL + "/" + s.partition
+	return cfg, nil
+}
+
+// Close stops recording the calls of the partition, the pooled server keeps running.
+func (s *partitionServer) Close() error {
+	s.server.recorder.remove(s.partition)
+	s.server.setClock(s.partition, nil)
+	return nil
+}
+
+func (s *partitionServer) GetCallHistory() CallHistory {
+	return s.recorder.GetHistory()
+}
+
+func (s *partitionServer) ResetCallHistory() CallHistory {
+	return s.recorder.ResetHistory()
+}
+
+// partitionHandler serves requests to /mcp/<partition> with handler, passing
+// the partition on in partitionHeader.
+func partitionHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		partition := strings.TrimPrefix(r.URL.Path, "/mcp/")
+		if partition == "" || strings.Contains(partition, "/") {
+			http.NotFound(w, r)
+			return
+		}
+
+		r = r.Clone(r.Context())
+		r.Header.Set(partitionHeader, partition)
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// unpartitionedHandler serves requests to /mcp with handler, removing the
+// partition header clients may send, so that their calls can't be recorded as
+// calls of a partition.
+func unpartitionedHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(partitionHeader) != "" {
+			r = r.Clone(r.Context())
+			r.Header.Del(partitionHeader)
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// partitionedRecorder records the calls of each partition of a pooled server
+// with its own recorder, and calls without a partition with the embedded one.
+type partitionedRecorder struct {
+	Recorder
+	serverName string
+
+	mu         sync.RWMutex
+	partitions map[string]Recorder
+}
+
+var _ Recorder = &partitionedRecorder{}
+
+func newPartitionedRecorder(serverName string) *partitionedRecorder {
+	return &partitionedRecorder{
+		Recorder:   NewRecorder(serverName),
+		serverName: serverName,
+		partitions: make(map[string]Recorder),
+	}
+}
+
+// add returns a new recorder for the calls of partition.
+func (r *partitionedRecorder) add(partition string) Recorder {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec := NewRecorder(r.serverName)
+	r.partitions[partition] = rec
+	return rec
+}
+
+// remove stops recording the calls of partition.
+func (r *partitionedRecorder) remove(partition string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.partitions, partition)
+}
+
+// recorderFor returns the recorder of the partition of a request. The
+// partition header is removed from the request, so that it isn't recorded.
+// Calls of removed partitions are dropped.
+func (r *partitionedRecorder) recorderFor(extra *mcp.RequestExtra) Recorder {
+	if extra == nil || extra.Header.Get(partitionHeader) == "" {
+		return r.Recorder
+	}
+
+	partition := extra.Header.Get(partitionHeader)
+	extra.Header = extra.Header.Clone()
+	extra.Header.Del(partitionHeader)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if rec, ok := r.partitions[partition]; ok {
+		return rec
+	}
+	return discardRecorder{}
+}
+
+func (r *partitionedRecorder) RecordToolCall(req *mcp.CallToolRequest, res *mcp.CallToolResult, err error, start time.Time) {
+	r.recorderFor(req.Extra).RecordToolCall(req, res, err, start)
+}
+
+func (r *partitionedRecorder) RecordSummarizedToolCall(req *mcp.CallToolRequest, res *mcp.CallToolResult, summary *ToolResultSummary, start time.Time) {
+	r.recorderFor(req.Extra).RecordSummarizedToolCall(req, res, summary, start)
+}
+
+func (r *partitionedRecorder) RecordShadowedToolCall(req *mcp.CallToolRequest, res *mcp.CallToolResult, err error, summary *ToolResultSummary, shadow <-chan *ShadowCall, start time.Time) {
+	r.recorderFor(req.Extra).RecordShadowedToolCall(req, res, err, summary, shadow, start)
+}
+
+func (r *partitionedRecorder) RecordResourceRead(req *mcp.ReadResourceRequest, res *mcp.ReadResourceResult, err error, start time.Time) {
+	r.recorderFor(req.Extra).RecordResourceRead(req, res, err, start)
+}
+
+func (r *partitionedRecorder) RecordPromptGet(req *mcp.GetPromptRequest, res *mcp.GetPromptResult, err error, start time.Time) {
+	r.recorderFor(req.Extra).RecordPromptGet(req, res, err, start)
+}
+
+// discardRecorder drops the calls of partitions that were removed.
+type discardRecorder struct{}
+
+func (discardRecorder) RecordToolCall(*mcp.CallToolRequest, *mcp.CallToolResult, error, time.Time) {}
+
+func (discardRecorder) RecordSummarizedToolCall(*mcp.CallToolRequest, *mcp.CallToolResult, *ToolResultSummary, time.Time) {
+}
+
+func (discardRecorder) RecordShadowedToolCall(*mcp.CallToolRequest, *mcp.CallToolResult, error, *ToolResultSummary, <-chan *ShadowCall, time.Time) {
+}
+
+func (discardRecorder) RecordResourceRead(*mcp.ReadResourceRequest, *mcp.ReadResourceResult, error, time.Time) {
+}
+
+func (discardRecorder) RecordPromptGet(*mcp.GetPromptRequest, *mcp.GetPromptResult, error, time.Time) {
+}
+
+func (discardRecorder) GetHistory() CallHistory { return CallHistory{} }
+
+func (discardRecorder) ResetHistory() CallHistory { return CallHistory{} }
@@ -0,0 +1,240 @@
+package mcpproxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// startEchoServer starts an MCP server with an "echo" tool and returns a
+// manager of clients connected to it.
+func startEchoServer(t *testing.T) mcpclient.Manager {
+	t.Helper()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "echo", Version: "1.0.0"}, nil)
+	server.AddTool(&mcp.Tool{Name: "echo", InputSchema: map[string]any{"type": "object"}},
+		func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(req.Params.Arguments)}}}, nil
+		})
+	server.AddTool(&mcp.Tool{Name: "date", InputSchema: map[string]any{"type": "object"}},
+		func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: req.Extra.Header.Get("Date")}}}, nil
+		})
+
+	srv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil))
+	t.Cleanup(srv.Close)
+
+	manager, err := mcpclient.NewManager(context.Background(), &mcpclient.MCPConfig{
+		MCPServers: map[string]*mcpclient.ServerConfig{
+			"echo": {Type: mcpclient.TransportTypeHttp, URL: srv.URL, EnableAllTools: true},
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = manager.Close(context.Background()) })
+
+	return manager
+}
+
+// connectThrough returns the echo client of a manager of clients connected to
+// the proxy servers of m.
+func connectThrough(t *testing.T, m ServerManager) *mcpclient.Client {
+	t.Helper()
+
+	files, err := m.GetMcpServerFiles()
+	require.NoError(t, err)
+	cfg, err := mcpclient.ParseConfigFile(files[0])
+	require.NoError(t, err)
+
+	clients, err := mcpclient.NewManager(context.Background(), cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = clients.Close(context.Background()) })
+
+	client, ok := clients.Get("echo")
+	require.True(t, ok)
+	return client
+}
+
+func TestServerPool(t *testing.T) {
+	ctx := context.Background()
+
+	pool, err := NewServerPool(ctx, startEchoServer(t))
+	require.NoError(t, err)
+	require.NoError(t, pool.Start(ctx))
+	t.Cleanup(func() { _ = pool.Close() })
+
+	tasks := []string{"first", "second", "third"}
+	managers := make([]ServerManager, len(tasks))
+	for i := range tasks {
+		managers[i] = pool.Acquire(nil)
+		require.NoError(t, managers[i].Start(ctx))
+	}
+
+	// Tasks call the shared servers concurrently
+	var wg sync.WaitGroup
+	for i, name := range tasks {
+		client := connectThrough(t, managers[i])
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 3 {
+				_, err := client.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{"task": name}})
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Each task only records its own calls
+	for i, name := range tasks {
+		history := managers[i].GetAllCallHistory()
+		require.Len(t, history.ToolCalls, 3, name)
+		for _, call := range history.ToolCalls {
+			assert.JSONEq(t, `{"task":"`+name+`"}`, string(call.Request.Params.Arguments))
+			assert.Empty(t, call.Request.Extra.Header.Get(partitionHeader))
+		}
+	}
+
+	// Closing the manager of a task stops recording its calls, but leaves the
+	// servers running for the other tasks
+	client := connectThrough(t, managers[1])
+	require.NoError(t, managers[0].Close())
+	_, err = client.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{"task": "second"}})
+	require.NoError(t, err)
+	assert.Len(t, managers[1].GetAllCallHistory().ToolCalls, 4)
+	assert.Len(t, managers[0].GetAllCallHistory().ToolCalls, 3)
+
+	// Calls of tasks are not recorded with the shared servers
+	assert.Empty(t, pool.shared.GetAllCallHistory().ToolCalls)
+}
+
+func TestServerPoolClock(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, 1, 31, 23, 59, 0, 0, time.UTC)
+
+	pool, err := NewServerPool(ctx, startEchoServer(t))
+	require.NoError(t, err)
+	require.NoError(t, pool.Start(ctx))
+	t.Cleanup(func() { _ = pool.Close() })
+
+	// The MCP server gets the time of the clock of the task run as the Date
+	// header, and no Date header from task runs without a clock
+	for name, clock := range map[string]*util.Clock{"clock": util.NewClock(start), "real time": nil} {
+		t.Run(name, func(t *testing.T) {
+			m := pool.Acquire(clock)
+			require.NoError(t, m.Start(ctx))
+			t.Cleanup(func() { _ = m.Close() })
+
+			res, err := connectThrough(t, m).CallTool(ctx, &mcp.CallToolParams{Name: "date", Arguments: map[string]any{}})
+			require.NoError(t, err)
+			date := res.Content[0].(*mcp.TextContent).Text
+			if clock == nil {
+				assert.Empty(t, date)
+				return
+			}
+			sent, err := http.ParseTime(date)
+			require.NoError(t, err)
+			assert.WithinDuration(t, start, sent, 2*time.Second)
+		})
+	}
+}
+
+func TestServerPoolAcquiredStartOnce(t *testing.T) {
+	ctx := context.Background()
+
+	pool, err := NewServerPool(ctx, startEchoServer(t))
+	require.NoError(t, err)
+	require.NoError(t, pool.Start(ctx))
+	t.Cleanup(func() { _ = pool.Close() })
+
+	m := pool.Acquire(nil)
+	require.NoError(t, m.Start(ctx))
+	t.Cleanup(func() { _ = m.Close() })
+	assert.Error(t, m.Start(ctx))
+}
+
+func TestPartitionHandler(t *testing.T) {
+	tt := map[string]struct {
+		path      string
+		header    string
+		status    int
+		partition string
+	}{
+		"partition": {
+			path:      "/mcp/7",
+			status:    http.StatusOK,
+			partition: "7",
+		},
+		"header set by the client is replaced": {
+			path:      "/mcp/7",
+			header:    "3",
+			status:    http.StatusOK,
+			partition: "7",
+		},
+		"missing partition": {
+			path:   "/mcp/",
+			status: http.StatusNotFound,
+		},
+		"nested path": {
+			path:   "/mcp/7/extra",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			var partition string
+			handler := partitionHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				partition = r.Header.Get(partitionHeader)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, tc.path, nil)
+			if tc.header != "" {
+				req.Header.Set(partitionHeader, tc.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Code)
+			assert.Equal(t, tc.partition, partition)
+		})
+	}
+}
+
+func TestUnpartitionedHandler(t *testing.T) {
+	partition := "unset"
+	handler := unpartitionedHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		partition = r.Header.Get(partitionHeader)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.Header.Set(partitionHeader, "7")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, partition, "a partition sent by the client must not reach the recorder")
+	assert.Equal(t, "7", req.Header.Get(partitionHeader), "the request of the caller is not modified")
+}
+
+func TestPartitionServerConfig(t *testing.T) {
+	s := &partitionServer{server: &server{url: "http://localhost:1234/mcp", proxyClient: &mcpclient.Client{}}, partition: "5"}
+
+	cfg, err := s.GetConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:1234/mcp/5", cfg.URL)
+
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "/mcp/5")
+}
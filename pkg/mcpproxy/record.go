@@ -2,19 +2,36 @@ package mcpproxy
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 type Recorder interface {
 	RecordToolCall(req *mcp.CallToolRequest, res *mcp.CallToolResult, err error, start time.Time)
+	// RecordSummarizedToolCall records a successful tool call whose result was
+	// replaced by a summary before it was returned to the agent
+	RecordSummarizedToolCall(req *mcp.CallToolRequest, res *mcp.CallToolResult, summary *ToolResultSummary, start time.Time)
+	// RecordShadowedToolCall records a tool call that was mirrored to a shadow
+	// server, with the summary of the result, if it was summarized. The call
+	// to the shadow server is added to it once shadow receives it; histories
+	// aren't returned before it is.
+	RecordShadowedToolCall(req *mcp.CallToolRequest, res *mcp.CallToolResult, err error, summary *ToolResultSummary, shadow <-chan *ShadowCall, start time.Time)
 	RecordResourceRead(req *mcp.ReadResourceRequest, res *mcp.ReadResourceResult, err error, start time.Time)
 	RecordPromptGet(req *mcp.GetPromptRequest, res *mcp.GetPromptResult, err error, start time.Time)
+	// GetHistory returns a snapshot of the calls recorded since the last reset
 	GetHistory() CallHistory
+	// ResetHistory returns the calls recorded since the last reset and starts
+	// a new, empty history
+	ResetHistory() CallHistory
 }
 
 // TokenCount provides token count estimates for a single MCP call.
@@ -39,6 +56,12 @@ type CallRecord struct {
 	Timestamp  time.Time `json:"timestamp"`
 	Success    bool      `json:"success"`
 	Error      string    `json:"error,omitempty"`
+	ErrorCode  int64     `json:"errorCode,omitempty"` // JSON-RPC error code, if the server returned one
+
+	// RequestBytes and ResponseBytes are the JSON encoded sizes of the request
+	// params and of the result of the call, before any output truncation
+	RequestBytes  int64 `json:"requestBytes,omitempty"`
+	ResponseBytes int64 `json:"responseBytes,omitempty"`
 }
 
 type SafeServerRequest[P mcp.Params] struct {
@@ -79,6 +102,14 @@ type ToolCall struct {
 	Request  *mcp.CallToolRequest `json:"request,omitempty"`
 	Result   *mcp.CallToolResult  `json:"result,omitempty"`
 	Tokens   *TokenCount          `json:"tokens,omitempty"`
+
+	// Summary is what the agent got instead of Result, if the result was
+	// summarized by the proxy
+	Summary *ToolResultSummary `json:"summary,omitempty"`
+
+	// Shadow is the copy of the call sent to the shadow server of the server,
+	// if it is recorded
+	Shadow *ShadowCall `json:"shadow,omitempty"`
 }
 
 func (c *ToolCall) MarshalJSON() ([]byte, error) {
@@ -93,6 +124,63 @@ func (c *ToolCall) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// ResultText flattens the mixed content of a tool call result into readable text.
+func (c *ToolCall) ResultText() string {
+	if c == nil || c.Result == nil {
+		return ""
+	}
+
+	var builder strings.Builder
+	for _, content := range c.Result.Content {
+		switch v := content.(type) {
+		case *mcp.TextContent:
+			builder.WriteString(v.Text)
+			if !strings.HasSuffix(v.Text, "\n") {
+				builder.WriteString("\n")
+			}
+		case *mcp.ResourceLink:
+			data, err := json.MarshalIndent(v, "", "  ")
+			if err != nil {
+				builder.WriteString(fmt.Sprintf("[ResourceLink marshal error: %v]\n", err))
+				continue
+			}
+			builder.Write(data)
+			builder.WriteString("\n")
+		case *mcp.EmbeddedResource:
+			data, err := json.MarshalIndent(v, "", "  ")
+			if err != nil {
+				builder.WriteString(fmt.Sprintf("[EmbeddedResource marshal error: %v]\n", err))
+				continue
+			}
+			builder.Write(data)
+			builder.WriteString("\n")
+		}
+	}
+
+	return builder.String()
+}
+
+// Failed reports whether the call failed, either with a protocol error or with
+// a tool result that has isError set.
+func (c *ToolCall) Failed() bool {
+	if c == nil {
+		return false
+	}
+	return !c.Success || (c.Result != nil && c.Result.IsError)
+}
+
+// ErrorText returns the error the call failed with: the protocol error, or the
+// result text of a tool error. It is empty if the call did not fail.
+func (c *ToolCall) ErrorText() string {
+	if !c.Failed() {
+		return ""
+	}
+	if c.Error != "" {
+		return c.Error
+	}
+	return c.ResultText()
+}
+
 // ResourceRead records a resource read
 type ResourceRead struct {
 	CallRecord
@@ -135,48 +223,105 @@ func (p *PromptGet) MarshalJSON() ([]byte, error) {
 	})
 }
 
-// CallHistory contains a complete call history for a server
+// CallHistory contains a complete call history for a server. Histories
+// returned by recorders, servers and server managers are snapshots: later
+// calls are not added to them.
 type CallHistory struct {
 	ToolCalls     []*ToolCall
 	ResourceReads []*ResourceRead
 	PromptGets    []*PromptGet
 }
 
+// recorder records the calls made through a proxy server. It is safe for
+// concurrent use, calls are recorded in the order they complete.
 type recorder struct {
 	serverName string
 
-	mu      sync.RWMutex
+	mu      sync.Mutex
 	history *CallHistory
+
+	// pending counts the tool calls of history whose shadow call hasn't
+	// completed yet; settled is signalled when it drops to zero
+	pending int
+	settled *sync.Cond
 }
 
 var _ Recorder = &recorder{}
 
 func NewRecorder(serverName string) Recorder {
-	return &recorder{
+	r := &recorder{
 		serverName: serverName,
-		history: &CallHistory{
-			ToolCalls:     make([]*ToolCall, 0),
-			ResourceReads: make([]*ResourceRead, 0),
-			PromptGets:    make([]*PromptGet, 0),
-		},
+		history:    newCallHistory(),
+	}
+	r.settled = sync.NewCond(&r.mu)
+	return r
+}
+
+func newCallHistory() *CallHistory {
+	return &CallHistory{
+		ToolCalls:     make([]*ToolCall, 0),
+		ResourceReads: make([]*ResourceRead, 0),
+		PromptGets:    make([]*PromptGet, 0),
 	}
 }
 
 func (r *recorder) RecordToolCall(req *mcp.CallToolRequest, res *mcp.CallToolResult, err error, start time.Time) {
+	r.recordToolCall(req, res, nil, err, start)
+}
+
+func (r *recorder) RecordSummarizedToolCall(req *mcp.CallToolRequest, res *mcp.CallToolResult, summary *ToolResultSummary, start time.Time) {
+	r.recordToolCall(req, res, summary, nil, start)
+}
+
+func (r *recorder) RecordShadowedToolCall(req *mcp.CallToolRequest, res *mcp.CallToolResult, err error, summary *ToolResultSummary, shadow <-chan *ShadowCall, start time.Time) {
+	r.mu.Lock()
+	call := r.appendToolCall(req, res, summary, err, start)
+	r.pending++
+	r.mu.Unlock()
+
+	// The call isn't in any snapshot of the history until its shadow call is
+	// added, as snapshots wait for pending shadow calls
+	go func() {
+		shadowCall := (<-shadow).compare(res, err)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		call.Shadow = shadowCall
+		r.pending--
+		if r.pending == 0 {
+			r.settled.Broadcast()
+		}
+	}()
+}
+
+func (r *recorder) recordToolCall(req *mcp.CallToolRequest, res *mcp.CallToolResult, summary *ToolResultSummary, err error, start time.Time) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.history.ToolCalls = append(r.history.ToolCalls, &ToolCall{
+	r.appendToolCall(req, res, summary, err, start)
+}
+
+// appendToolCall adds a tool call to the history and returns it. r.mu must
+// be held.
+func (r *recorder) appendToolCall(req *mcp.CallToolRequest, res *mcp.CallToolResult, summary *ToolResultSummary, err error, start time.Time) *ToolCall {
+	call := &ToolCall{
 		CallRecord: CallRecord{
 			ServerName: r.serverName,
 			Timestamp:  start,
 			Success:    err == nil,
 			Error:      errorToString(err),
+			ErrorCode:  errorCode(err),
+
+			RequestBytes:  jsonSize(req.Params),
+			ResponseBytes: jsonSize(res),
 		},
 		ToolName: req.Params.Name,
 		Request:  req,
 		Result:   res,
-	})
+		Summary:  summary,
+	}
+	r.history.ToolCalls = append(r.history.ToolCalls, call)
+	return call
 }
 
 func (r *recorder) RecordResourceRead(req *mcp.ReadResourceRequest, res *mcp.ReadResourceResult, err error, start time.Time) {
@@ -189,6 +334,10 @@ func (r *recorder) RecordResourceRead(req *mcp.ReadResourceRequest, res *mcp.Rea
 			Timestamp:  start,
 			Success:    err == nil,
 			Error:      errorToString(err),
+			ErrorCode:  errorCode(err),
+
+			RequestBytes:  jsonSize(req.Params),
+			ResponseBytes: jsonSize(res),
 		},
 		URI:     req.Params.URI,
 		Request: req,
@@ -206,6 +355,10 @@ func (r *recorder) RecordPromptGet(req *mcp.GetPromptRequest, res *mcp.GetPrompt
 			Timestamp:  start,
 			Success:    err == nil,
 			Error:      errorToString(err),
+			ErrorCode:  errorCode(err),
+
+			RequestBytes:  jsonSize(req.Params),
+			ResponseBytes: jsonSize(res),
 		},
 		Name:    req.Params.Name,
 		Request: req,
@@ -213,11 +366,63 @@ func (r *recorder) RecordPromptGet(req *mcp.GetPromptRequest, res *mcp.GetPrompt
 	})
 }
 
+// GetHistory returns a snapshot of the history, once the pending shadow
+// calls of its tool calls have completed.
 func (r *recorder) GetHistory() CallHistory {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.waitForShadows()
+
+	// Copy the slices, so that the snapshot doesn't share its backing arrays
+	// with calls recorded later
+	return CallHistory{
+		ToolCalls:     slices.Clone(r.history.ToolCalls),
+		ResourceReads: slices.Clone(r.history.ResourceReads),
+		PromptGets:    slices.Clone(r.history.PromptGets),
+	}
+}
+
+// ResetHistory returns the calls recorded since the last reset and starts a
+// new history. A call in flight during the reset is recorded in the new
+// history, as calls are recorded when they complete.
+func (r *recorder) ResetHistory() CallHistory {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.waitForShadows()
+
+	history := *r.history
+	r.history = newCallHistory()
+	return history
+}
+
+// waitForShadows waits until the shadow calls of the tool calls of the
+// history have completed. r.mu must be held.
+func (r *recorder) waitForShadows() {
+	for r.pending > 0 {
+		r.settled.Wait()
+	}
+}
 
-	return *r.history
+// jsonSize returns the size of the JSON encoding of v, or 0 if v is nil or
+// can't be encoded.
+func jsonSize[T any](v *T) int64 {
+	if v == nil {
+		return 0
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return int64(len(data))
+}
+
+// errorCode returns the JSON-RPC error code of err, or 0 if it has none.
+func errorCode(err error) int64 {
+	var wireErr *jsonrpc.Error
+	if errors.As(err, &wireErr) {
+		return wireErr.Code
+	}
+	return 0
 }
 
 func errorToString(err error) string {
@@ -3,11 +3,13 @@ package mcpproxy
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -36,6 +38,56 @@ func TestErrorToString(t *testing.T) {
 	}
 }
 
+func TestErrorCode(t *testing.T) {
+	assert.Equal(t, int64(0), errorCode(nil))
+	assert.Equal(t, int64(0), errorCode(errors.New("connection timeout")))
+	assert.Equal(t, int64(-32602), errorCode(&jsonrpc.Error{Code: -32602, Message: "invalid params"}))
+	assert.Equal(t, int64(-32602), errorCode(fmt.Errorf("calling tool: %w", &jsonrpc.Error{Code: -32602})))
+}
+
+func TestToolCallErrorText(t *testing.T) {
+	tests := map[string]struct {
+		call           *ToolCall
+		expectedFailed bool
+		expectedText   string
+	}{
+		"nil call": {
+			call: nil,
+		},
+		"successful call": {
+			call: &ToolCall{
+				CallRecord: CallRecord{Success: true},
+				Result:     &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}},
+			},
+		},
+		"protocol error": {
+			call: &ToolCall{
+				CallRecord: CallRecord{Success: false, Error: "invalid params", ErrorCode: -32602},
+			},
+			expectedFailed: true,
+			expectedText:   "invalid params",
+		},
+		"tool error result": {
+			call: &ToolCall{
+				CallRecord: CallRecord{Success: true},
+				Result: &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: "namespace \"prod\" is protected"}},
+				},
+			},
+			expectedFailed: true,
+			expectedText:   "namespace \"prod\" is protected\n",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedFailed, tc.call.Failed())
+			assert.Equal(t, tc.expectedText, tc.call.ErrorText())
+		})
+	}
+}
+
 func TestSafeServerRequestFromUnsafe(t *testing.T) {
 	tests := map[string]struct {
 		input          *mcp.ServerRequest[*mcp.CallToolParamsRaw]
@@ -671,6 +723,59 @@ func TestRecorderGetHistory(t *testing.T) {
 		history2 := rec.GetHistory()
 		assert.Len(t, history2.ToolCalls, 1)
 	})
+
+	t.Run("snapshot does not share entries with later history", func(t *testing.T) {
+		rec := NewRecorder("test-server")
+
+		req := &mcp.ServerRequest[*mcp.CallToolParamsRaw]{
+			Params: &mcp.CallToolParamsRaw{Name: "tool"},
+		}
+		rec.RecordToolCall(req, nil, nil, fixedTime)
+
+		snapshot := rec.GetHistory()
+		snapshot.ToolCalls[0] = nil
+		rec.RecordToolCall(req, nil, nil, fixedTime)
+
+		history := rec.GetHistory()
+		require.Len(t, history.ToolCalls, 2)
+		assert.NotNil(t, history.ToolCalls[0])
+		assert.Len(t, snapshot.ToolCalls, 1)
+	})
+}
+
+func TestRecorderResetHistory(t *testing.T) {
+	fixedTime := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	toolReq := func(name string) *mcp.CallToolRequest {
+		return &mcp.ServerRequest[*mcp.CallToolParamsRaw]{
+			Params: &mcp.CallToolParamsRaw{Name: name},
+		}
+	}
+
+	rec := NewRecorder("test-server")
+	rec.RecordToolCall(toolReq("first_attempt"), nil, nil, fixedTime)
+	rec.RecordPromptGet(&mcp.ServerRequest[*mcp.GetPromptParams]{
+		Params: &mcp.GetPromptParams{Name: "prompt"},
+	}, nil, nil, fixedTime)
+
+	first := rec.ResetHistory()
+	require.Len(t, first.ToolCalls, 1)
+	assert.Equal(t, "first_attempt", first.ToolCalls[0].ToolName)
+	assert.Len(t, first.PromptGets, 1)
+
+	// The new history starts empty
+	history := rec.GetHistory()
+	assert.NotNil(t, history.ToolCalls)
+	assert.Empty(t, history.ToolCalls)
+	assert.Empty(t, history.PromptGets)
+
+	rec.RecordToolCall(toolReq("second_attempt"), nil, nil, fixedTime)
+
+	second := rec.ResetHistory()
+	require.Len(t, second.ToolCalls, 1)
+	assert.Equal(t, "second_attempt", second.ToolCalls[0].ToolName)
+
+	// Earlier resets are not changed by later calls
+	assert.Len(t, first.ToolCalls, 1)
 }
 
 func TestRecorderConcurrency(t *testing.T) {
@@ -740,4 +845,77 @@ func TestRecorderConcurrency(t *testing.T) {
 		history := rec.GetHistory()
 		assert.Len(t, history.ToolCalls, numWriters*callsPerWriter)
 	})
+
+	t.Run("concurrent reset records every call once", func(t *testing.T) {
+		rec := NewRecorder("test-server")
+		var wg sync.WaitGroup
+
+		numWriters := 5
+		callsPerWriter := 100
+
+		wg.Add(numWriters)
+		for i := 0; i < numWriters; i++ {
+			go func() {
+				defer wg.Done()
+				for j := 0; j < callsPerWriter; j++ {
+					req := &mcp.ServerRequest[*mcp.CallToolParamsRaw]{
+						Params: &mcp.CallToolParamsRaw{Name: "tool"},
+					}
+					rec.RecordToolCall(req, nil, nil, fixedTime)
+				}
+			}()
+		}
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+		total := 0
+		for {
+			select {
+			case <-done:
+				total += len(rec.ResetHistory().ToolCalls)
+				assert.Equal(t, numWriters*callsPerWriter, total)
+				return
+			default:
+				total += len(rec.ResetHistory().ToolCalls)
+			}
+		}
+	})
+}
+
+func TestRecorderCallSizes(t *testing.T) {
+	r := NewRecorder("server")
+
+	params := &mcp.CallToolParamsRaw{Name: "echo", Arguments: json.RawMessage(`{"message":"hello"}`)}
+	result := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "hello"}}}
+	r.RecordToolCall(&mcp.CallToolRequest{Params: params}, result, nil, time.Now())
+	r.RecordToolCall(&mcp.CallToolRequest{Params: params}, nil, errors.New("failed"), time.Now())
+	r.RecordResourceRead(&mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: "file:///a"}}, nil, errors.New("not found"), time.Now())
+
+	paramsJSON, err := json.Marshal(params)
+	require.NoError(t, err)
+	resultJSON, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	history := r.GetHistory()
+	require.Len(t, history.ToolCalls, 2)
+	assert.Equal(t, int64(len(paramsJSON)), history.ToolCalls[0].RequestBytes)
+	assert.Equal(t, int64(len(resultJSON)), history.ToolCalls[0].ResponseBytes)
+	assert.Equal(t, int64(0), history.ToolCalls[1].ResponseBytes, "failed calls have no response")
+	require.Len(t, history.ResourceReads, 1)
+	assert.Positive(t, history.ResourceReads[0].RequestBytes)
+
+	sizes := history.SizesByServer()
+	require.Contains(t, sizes, "server")
+	assert.Equal(t, CallSizes{
+		Calls:            3,
+		RequestBytes:     2*int64(len(paramsJSON)) + history.ResourceReads[0].RequestBytes,
+		ResponseBytes:    int64(len(resultJSON)),
+		MaxResponseBytes: int64(len(resultJSON)),
+	}, *sizes["server"])
+
+	assert.Nil(t, (&CallHistory{}).SizesByServer())
 }
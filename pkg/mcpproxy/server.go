@@ -3,11 +3,13 @@ package mcpproxy
 import (
 	"context"
 	"fmt"
-	"net"
+	"maps"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -24,8 +26,12 @@ type Server interface {
 	GetInstructions() string
 	// Close closes the MCP proxy server, but not the underlying client connection
 	Close() error
-	// GetCallHistory returns all the MCP calls made while the proxy server was running
+	// GetCallHistory returns a snapshot of the MCP calls made while the proxy
+	// server was running, since the last reset
 	GetCallHistory() CallHistory
+	// ResetCallHistory returns the MCP calls made since the last reset and
+	// starts recording a new history
+	ResetCallHistory() CallHistory
 	// WaitReady blocks until the server has initialized and is ready to serve
 	WaitReady(ctx context.Context) error
 }
@@ -36,9 +42,16 @@ type server struct {
 	proxyClient  *mcpclient.Client
 	url          string
 	instructions string
+	listener     *Listener
+	toolFilter   ToolFilter
+	headers      map[string]string
 
-	// Call tracking
-	recorder Recorder
+	// Call tracking, by partition for servers shared through a ServerPool
+	recorder *partitionedRecorder
+
+	// Clocks of the task runs using the server, by partition; the empty
+	// partition is the task run that started an unpooled server
+	clocks sync.Map
 
 	// Ready signaling
 	ready    chan struct{}
@@ -51,10 +64,73 @@ type server struct {
 
 var _ Server = &server{}
 
-func NewProxyServerForClient(ctx context.Context, name string, client *mcpclient.Client) (Server, error) {
-	r := NewRecorder(name)
+// ServerOption configures the proxy servers created by NewProxyServerForClient,
+// NewServerManager and NewServerPool.
+type ServerOption func(*serverOptions)
+
+type serverOptions struct {
+	listener   *Listener
+	summarizer *resultSummarizer
+	toolFilter ToolFilter
+	clock      *util.Clock
+	headers    map[string]string
+}
+
+// WithHeaders adds headers to the MCP config of the proxy servers passed to
+// agents, so that the requests of agents to the proxy servers carry them, e.g.
+// the ID of the run. Headers set by the MCP config of a server are kept.
+func WithHeaders(headers map[string]string) ServerOption {
+	return func(o *serverOptions) {
+		o.headers = headers
+	}
+}
+
+// WithClock makes the requests the proxy servers forward to the MCP servers
+// carry the time of clock, the clock of the task run using the servers.
+func WithClock(clock *util.Clock) ServerOption {
+	return func(o *serverOptions) {
+		o.clock = clock
+	}
+}
+
+// ToolFilter reports whether the tool of an MCP server is exposed by its
+// proxy server.
+type ToolFilter func(server, tool string) bool
+
+// WithToolFilter makes the proxy servers only list and serve the tools that
+// filter allows, hiding the other tools of the MCP servers from the agent.
+func WithToolFilter(filter ToolFilter) ServerOption {
+	return func(o *serverOptions) {
+		o.toolFilter = filter
+	}
+}
 
-	s, err := createProxyServer(ctx, client.ClientSession, r)
+// WithListener makes the proxy servers listen with l instead of on ephemeral
+// localhost ports.
+func WithListener(l *Listener) ServerOption {
+	return func(o *serverOptions) {
+		o.listener = l
+	}
+}
+
+func newServerOptions(opts []ServerOption) serverOptions {
+	var o serverOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func NewProxyServerForClient(ctx context.Context, name string, client *mcpclient.Client, opts ...ServerOption) (Server, error) {
+	return newProxyServer(ctx, name, client, nil, newServerOptions(opts))
+}
+
+// newProxyServer creates the proxy server of client, mirroring its tool calls
+// to shadow if it isn't nil.
+func newProxyServer(ctx context.Context, name string, client *mcpclient.Client, shadow *shadowServer, opts serverOptions) (*server, error) {
+	r := newPartitionedRecorder(name)
+
+	s, err := createProxyServer(ctx, name, client.ClientSession, r, opts, shadow)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create proxy server for %q: %w", name, err)
 	}
@@ -64,18 +140,25 @@ func NewProxyServerForClient(ctx context.Context, name string, client *mcpclient
 		instructions = initResult.Instructions
 	}
 
-	return &server{
+	srv := &server{
 		name:         name,
 		proxyServer:  s,
 		proxyClient:  client,
 		instructions: instructions,
+		listener:     opts.listener,
+		toolFilter:   opts.toolFilter,
+		headers:      opts.headers,
 		recorder:     r,
 		ready:        make(chan struct{}),
 		done:         make(chan error, 1),
-	}, nil
+	}
+	srv.setClock("", opts.clock)
+
+	return srv, nil
 }
 
-func createProxyServer(ctx context.Context, cs *mcp.ClientSession, r Recorder) (*mcp.Server, error) {
+func createProxyServer(ctx context.Context, name string, cs *mcp.ClientSession, r Recorder, o serverOptions, shadow *shadowServer) (*mcp.Server, error) {
+	summarizer := o.summarizer
 	serverCaps := cs.InitializeResult().Capabilities
 	opts := &mcp.ServerOptions{
 		Instructions: cs.InitializeResult().Instructions,
@@ -135,15 +218,35 @@ func createProxyServer(ctx context.Context, cs *mcp.ClientSession, r Recorder) (
 			if err != nil {
 				continue
 			}
+			if o.toolFilter != nil && !o.toolFilter(name, t.Name) {
+				continue
+			}
 			s.AddTool(t, func(ctx context.Context, ctr *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 				start := time.Now()
-				res, err := cs.CallTool(ctx, &mcp.CallToolParams{
+				params := &mcp.CallToolParams{
 					Meta:      ctr.Params.Meta,
 					Name:      ctr.Params.Name,
 					Arguments: ctr.Params.Arguments,
-				})
-				r.RecordToolCall(ctr, res, err, start)
-				return res, err
+				}
+				mirrored := shadow.mirror(ctx, params)
+				res, err := cs.CallTool(ctx, params)
+
+				// Large results may be replaced by a summary, the history keeps the original
+				agentRes := res
+				var summary *ToolResultSummary
+				if err == nil {
+					agentRes, summary = summarizer.summarize(ctx, ctr.Params.Name, res)
+				}
+
+				switch {
+				case mirrored != nil:
+					r.RecordShadowedToolCall(ctr, res, err, summary, mirrored, start)
+				case summary != nil:
+					r.RecordSummarizedToolCall(ctr, res, summary, start)
+				default:
+					r.RecordToolCall(ctr, res, err, start)
+				}
+				return agentRes, err
 			})
 		}
 	}
@@ -159,13 +262,14 @@ func (s *server) Run(ctx context.Context) error {
 
 	mux := http.NewServeMux()
 
-	handler := mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server {
+	handler := s.listener.authorize(s.clockHandler(mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server {
 		return s.proxyServer
-	}, &mcp.StreamableHTTPOptions{})
+	}, &mcp.StreamableHTTPOptions{})))
 
-	mux.Handle("/mcp", handler)
+	mux.Handle("/mcp", unpartitionedHandler(handler))
+	mux.Handle("/mcp/", partitionHandler(handler))
 
-	listener, err := net.Listen("tcp", "localhost:0")
+	listener, baseURL, err := s.listener.Listen()
 	if err != nil {
 		s.startErr = fmt.Errorf("failed to start listen: %w", err)
 		close(s.ready)
@@ -173,7 +277,7 @@ func (s *server) Run(ctx context.Context) error {
 		return s.startErr
 	}
 
-	s.url = fmt.Sprintf("http://%s/mcp", listener.Addr().String())
+	s.url = baseURL + "/mcp"
 
 	// Signal that the server is ready (URL is set and listener is ready)
 	close(s.ready)
@@ -216,26 +320,85 @@ func (s *server) GetConfig() (*mcpclient.ServerConfig, error) {
 		URL:  s.url,
 	}
 
+	cfg.CAFile = s.listener.CAFile()
+
 	clientCfg := s.proxyClient.GetConfig()
 	if clientCfg != nil {
-		cfg.Headers = clientCfg.Headers
+		cfg.Headers = maps.Clone(clientCfg.Headers)
+	}
+	for name, value := range s.headers {
+		if hasHeader(cfg.Headers, name) {
+			continue
+		}
+		if cfg.Headers == nil {
+			cfg.Headers = make(map[string]string, len(s.headers))
+		}
+		cfg.Headers[name] = value
+	}
+	if auth := s.listener.authorizationHeader(); auth != "" {
+		if cfg.Headers == nil {
+			cfg.Headers = make(map[string]string, 1)
+		}
+		cfg.Headers["Authorization"] = auth
 	}
 
 	return cfg, nil
 }
 
+// hasHeader reports whether headers has a header called name, ignoring case.
+func hasHeader(headers map[string]string, name string) bool {
+	for key := range headers {
+		if http.CanonicalHeaderKey(key) == http.CanonicalHeaderKey(name) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *server) GetName() string {
 	return s.name
 }
 
 func (s *server) GetAllowedTools(ctx context.Context) []*mcp.Tool {
-	return s.proxyClient.GetAllowedTools(ctx)
+	tools := s.proxyClient.GetAllowedTools(ctx)
+	if s.toolFilter == nil {
+		return tools
+	}
+
+	var allowed []*mcp.Tool
+	for _, t := range tools {
+		if s.toolFilter(s.name, t.Name) {
+			allowed = append(allowed, t)
+		}
+	}
+	return allowed
 }
 
 func (s *server) GetInstructions() string {
 	return s.instructions
 }
 
+// setClock sets the clock of the task run of a partition, nil for the real time.
+func (s *server) setClock(partition string, clock *util.Clock) {
+	if clock == nil {
+		s.clocks.Delete(partition)
+		return
+	}
+	s.clocks.Store(partition, clock)
+}
+
+// clockHandler adds the clock of the task run of a request to its context, so
+// that the requests the proxy forwards to the MCP server carry its time. MCP
+// sessions keep the context values of the request that started them.
+func (s *server) clockHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if clock, ok := s.clocks.Load(r.Header.Get(partitionHeader)); ok {
+			r = r.WithContext(mcpclient.WithRequestClock(r.Context(), clock.(*util.Clock)))
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
 func (s *server) Close() error {
 	if s.cancel == nil {
 		return nil
@@ -249,6 +412,10 @@ func (s *server) GetCallHistory() CallHistory {
 	return s.recorder.GetHistory()
 }
 
+func (s *server) ResetCallHistory() CallHistory {
+	return s.recorder.ResetHistory()
+}
+
 func (s *server) WaitReady(ctx context.Context) error {
 	select {
 	case <-s.ready:
@@ -8,6 +8,7 @@ import (
 	"os"
 	"slices"
 	"sort"
+	"sync/atomic"
 
 	"golang.org/x/sync/errgroup"
 
@@ -18,10 +19,14 @@ const (
 	McpServerFileName = "mcp-server.json"
 )
 
+// ServerManager runs the proxy servers of a single task run and records the
+// calls made through them. A new ServerManager is created for every task run,
+// so that the call history only holds the calls of that run; Start fails if it
+// is called again. The call history methods are safe for concurrent use.
 type ServerManager interface {
 	GetMcpServerFiles() ([]string, error)
 	GetMcpServers() []Server
-	// Start blocks until all servers are ready or an error occurs. Caller must ensure this is only called once, and called before Close
+	// Start blocks until all servers are ready or an error occurs. It fails if called more than once. Caller must ensure it is called before Close
 	Start(ctx context.Context) error
 	// Close closes associated server resources. Caller must ensure this is only called once, and called after Start
 	Close() error
@@ -29,14 +34,18 @@ type ServerManager interface {
 	// aggregate call tracking
 	GetAllCallHistory() *CallHistory
 	GetCallHistoryForServer(serverName string) (CallHistory, bool)
+	// ResetCallHistory returns the calls of all servers since the last reset,
+	// like GetAllCallHistory, and starts recording a new history
+	ResetCallHistory() *CallHistory
 }
 
 type serverManager struct {
 	servers map[string]Server
 	tmpDir  string
 
-	cancel context.CancelFunc
-	eg     *errgroup.Group
+	cancel  context.CancelFunc
+	eg      *errgroup.Group
+	started atomic.Bool
 }
 
 // NewEmptyServerManager creates a ServerManager with no servers.
@@ -55,12 +64,15 @@ func (m *emptyServerManager) GetAllCallHistory() *CallHistory      { return &Cal
 func (m *emptyServerManager) GetCallHistoryForServer(_ string) (CallHistory, bool) {
 	return CallHistory{}, false
 }
+func (m *emptyServerManager) ResetCallHistory() *CallHistory { return &CallHistory{} }
+
+func NewServerManager(ctx context.Context, manager mcpclient.Manager, opts ...ServerOption) (ServerManager, error) {
+	o := newServerOptions(opts)
+	clients, shadows := manager.GetAll(), shadowsOf(manager)
 
-func NewServerManager(ctx context.Context, manager mcpclient.Manager) (ServerManager, error) {
-	clients := manager.GetAll()
 	servers := make(map[string]Server, len(clients))
 	for name, client := range clients {
-		s, err := NewProxyServerForClient(ctx, name, client)
+		s, err := newProxyServer(ctx, name, client, shadows[name], o)
 		if err != nil {
 			return nil, err
 		}
@@ -109,6 +121,10 @@ func (m *serverManager) GetMcpServers() []Server {
 }
 
 func (m *serverManager) Start(ctx context.Context) error {
+	if !m.started.CompareAndSwap(false, true) {
+		return fmt.Errorf("mcp proxy servers were already started: a server manager records the calls of a single task run")
+	}
+
 	ctx, cancel := context.WithCancel(ctx)
 	m.cancel = cancel
 
@@ -165,10 +181,20 @@ func (m *serverManager) Close() error {
 }
 
 func (m *serverManager) GetAllCallHistory() *CallHistory {
+	return combineCallHistories(m.servers, Server.GetCallHistory)
+}
+
+func (m *serverManager) ResetCallHistory() *CallHistory {
+	return combineCallHistories(m.servers, Server.ResetCallHistory)
+}
+
+// combineCallHistories combines the histories that get returns for each of the
+// servers, in chronological order.
+func combineCallHistories(servers map[string]Server, get func(Server) CallHistory) *CallHistory {
 	combined := CallHistory{}
 
-	for _, srv := range m.servers {
-		history := srv.GetCallHistory()
+	for _, srv := range servers {
+		history := get(srv)
 		combined.PromptGets = append(combined.PromptGets, history.PromptGets...)
 		combined.ResourceReads = append(combined.ResourceReads, history.ResourceReads...)
 		combined.ToolCalls = append(combined.ToolCalls, history.ToolCalls...)
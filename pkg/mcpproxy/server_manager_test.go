@@ -0,0 +1,107 @@
+package mcpproxy
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+func TestServerManagerStartOnce(t *testing.T) {
+	m := &serverManager{servers: map[string]Server{}}
+
+	require.NoError(t, m.Start(context.Background()))
+	t.Cleanup(func() { _ = m.Close() })
+
+	err := m.Start(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "single task run")
+}
+
+func TestServerManagerResetCallHistory(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	recordCall := func(r Recorder, name string, offset time.Duration) {
+		r.RecordToolCall(&mcp.ServerRequest[*mcp.CallToolParamsRaw]{
+			Params: &mcp.CallToolParamsRaw{Name: name},
+		}, nil, nil, start.Add(offset))
+	}
+
+	first, second := newPartitionedRecorder("first"), newPartitionedRecorder("second")
+	m := &serverManager{servers: map[string]Server{
+		"first":  &server{name: "first", recorder: first},
+		"second": &server{name: "second", recorder: second},
+	}}
+
+	recordCall(second, "b", 2*time.Second)
+	recordCall(first, "a", time.Second)
+
+	attempt := m.ResetCallHistory()
+	require.Len(t, attempt.ToolCalls, 2)
+	// Calls of all servers are combined in chronological order
+	assert.Equal(t, "a", attempt.ToolCalls[0].ToolName)
+	assert.Equal(t, "b", attempt.ToolCalls[1].ToolName)
+
+	assert.Empty(t, m.GetAllCallHistory().ToolCalls)
+
+	recordCall(first, "c", 3*time.Second)
+
+	history := m.GetAllCallHistory()
+	require.Len(t, history.ToolCalls, 1)
+	assert.Equal(t, "c", history.ToolCalls[0].ToolName)
+
+	forServer, ok := m.GetCallHistoryForServer("first")
+	require.True(t, ok)
+	assert.Len(t, forServer.ToolCalls, 1)
+}
+
+func TestServerManagerToolFilter(t *testing.T) {
+	ctx := context.Background()
+
+	m, err := NewServerManager(ctx, startEchoServer(t), WithToolFilter(func(server, tool string) bool {
+		return server == "echo" && tool == "echo"
+	}))
+	require.NoError(t, err)
+	require.NoError(t, m.Start(ctx))
+	t.Cleanup(func() { _ = m.Close() })
+
+	// The agent only sees and can only call the allowed tools
+	client := connectThrough(t, m)
+	var listed []string
+	for tool, err := range client.Tools(ctx, &mcp.ListToolsParams{}) {
+		require.NoError(t, err)
+		listed = append(listed, tool.Name)
+	}
+	assert.Equal(t, []string{"echo"}, listed)
+
+	_, err = client.CallTool(ctx, &mcp.CallToolParams{Name: "date", Arguments: map[string]any{}})
+	assert.Error(t, err)
+
+	var allowed []string
+	for _, tool := range m.GetMcpServers()[0].GetAllowedTools(ctx) {
+		allowed = append(allowed, tool.Name)
+	}
+	assert.Equal(t, []string{"echo"}, allowed)
+}
+
+func TestServerManagerClock(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, 1, 31, 23, 59, 0, 0, time.UTC)
+
+	m, err := NewServerManager(ctx, startEchoServer(t), WithClock(util.NewClock(start)))
+	require.NoError(t, err)
+	require.NoError(t, m.Start(ctx))
+	t.Cleanup(func() { _ = m.Close() })
+
+	// The MCP server gets the time of the clock as the Date header
+	res, err := connectThrough(t, m).CallTool(ctx, &mcp.CallToolParams{Name: "date", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	sent, err := http.ParseTime(res.Content[0].(*mcp.TextContent).Text)
+	require.NoError(t, err)
+	assert.WithinDuration(t, start, sent, 2*time.Second)
+}
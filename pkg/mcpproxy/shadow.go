@@ -0,0 +1,232 @@
+package mcpproxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
+)
+
+// defaultShadowTimeout bounds the calls to a shadow server without a timeout
+const defaultShadowTimeout = 30 * time.Second
+
+// ShadowConfig mirrors the tool calls made through the proxy server of an MCP
+// server to a shadow server, to validate a new implementation of the server
+// against real traffic. The agent only sees the server and its results.
+type ShadowConfig struct {
+	// Server is the MCP server whose tool calls are mirrored
+	Server string `json:"server"`
+
+	// Shadow is the MCP server of the MCP config that gets a copy of every tool
+	// call of Server. No proxy server is started for it, so it is hidden from
+	// the agent.
+	Shadow string `json:"shadow"`
+
+	// Record records the response of the shadow server with each tool call,
+	// and whether it matches the response of the server. The responses are
+	// discarded otherwise.
+	Record bool `json:"record,omitempty"`
+
+	// Timeout bounds each call to the shadow server, 30s by default. The
+	// agent never waits for the shadow server; recorded calls to it are added
+	// to the call history once it responded or timed out.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// Validate checks that the servers are set and differ and that the timeout
+// is a valid duration.
+func (c *ShadowConfig) Validate() error {
+	if c.Server == "" || c.Shadow == "" {
+		return fmt.Errorf("server and shadow are required")
+	}
+	if c.Server == c.Shadow {
+		return fmt.Errorf("shadow must be a different server than %q", c.Server)
+	}
+	if _, err := c.getTimeout(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *ShadowConfig) getTimeout() (time.Duration, error) {
+	if c.Timeout == "" {
+		return defaultShadowTimeout, nil
+	}
+	timeout, err := time.ParseDuration(c.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q: %w", c.Timeout, err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("timeout must be > 0, got %q", c.Timeout)
+	}
+	return timeout, nil
+}
+
+// ShadowCall records the copy of a tool call that was sent to a shadow server
+type ShadowCall struct {
+	ServerName string `json:"serverName"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	ErrorCode  int64  `json:"errorCode,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+
+	Result *mcp.CallToolResult `json:"result,omitempty"`
+
+	// Matches is set if the shadow server responded like the server: both
+	// calls failed with a protocol error, or both returned the same content,
+	// structured content and isError.
+	Matches bool `json:"matches"`
+}
+
+// shadowServer is the shadow server of the proxy server of an MCP server
+type shadowServer struct {
+	name    string
+	session *mcp.ClientSession
+	record  bool
+	timeout time.Duration
+}
+
+// shadowedManager is the MCP clients of a manager without the shadow servers
+// that the tool calls of its servers are mirrored to.
+type shadowedManager struct {
+	manager mcpclient.Manager
+	shadows map[string]*shadowServer
+	hidden  map[string]bool
+}
+
+var _ mcpclient.Manager = &shadowedManager{}
+
+// NewShadowedManager returns the MCP clients of manager without the shadow
+// servers of shadows, so that agents, steps and judges can't call them. The
+// proxy servers created for it mirror the tool calls of the servers of
+// shadows to their shadow servers. Shadows of servers that manager doesn't
+// have are ignored, since the MCP configs of environments may lack them.
+func NewShadowedManager(manager mcpclient.Manager, shadows []ShadowConfig) (mcpclient.Manager, error) {
+	if len(shadows) == 0 {
+		return manager, nil
+	}
+
+	m := &shadowedManager{
+		manager: manager,
+		shadows: make(map[string]*shadowServer, len(shadows)),
+		hidden:  make(map[string]bool, len(shadows)),
+	}
+	for _, cfg := range shadows {
+		if _, ok := manager.Get(cfg.Server); !ok {
+			continue
+		}
+		shadow, ok := manager.Get(cfg.Shadow)
+		if !ok {
+			return nil, fmt.Errorf("shadow server %q of %q is not configured", cfg.Shadow, cfg.Server)
+		}
+		timeout, err := cfg.getTimeout()
+		if err != nil {
+			return nil, fmt.Errorf("shadow server %q: %w", cfg.Shadow, err)
+		}
+
+		m.shadows[cfg.Server] = &shadowServer{
+			name:    cfg.Shadow,
+			session: shadow.ClientSession,
+			record:  cfg.Record,
+			timeout: timeout,
+		}
+		m.hidden[cfg.Shadow] = true
+	}
+	return m, nil
+}
+
+func (m *shadowedManager) Get(name string) (*mcpclient.Client, bool) {
+	if m.hidden[name] {
+		return nil, false
+	}
+	return m.manager.Get(name)
+}
+
+func (m *shadowedManager) GetAll() map[string]*mcpclient.Client {
+	clients := maps.Clone(m.manager.GetAll())
+	for name := range m.hidden {
+		delete(clients, name)
+	}
+	return clients
+}
+
+func (m *shadowedManager) Close(ctx context.Context) error {
+	return m.manager.Close(ctx)
+}
+
+// shadowsOf returns the shadow server of each server of manager that has one.
+func shadowsOf(manager mcpclient.Manager) map[string]*shadowServer {
+	if m, ok := manager.(*shadowedManager); ok {
+		return m.shadows
+	}
+	return nil
+}
+
+// mirror sends a copy of a tool call to the shadow server, without waiting
+// for it. If the shadow call is recorded, the returned channel receives it
+// once it completes; it is nil otherwise.
+func (s *shadowServer) mirror(ctx context.Context, params *mcp.CallToolParams) <-chan *ShadowCall {
+	if s == nil {
+		return nil
+	}
+
+	// The call to the shadow server may outlive the call of the agent
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.timeout)
+	var done chan *ShadowCall
+	if s.record {
+		done = make(chan *ShadowCall, 1)
+	}
+
+	go func() {
+		defer cancel()
+
+		start := time.Now()
+		res, err := s.session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      params.Name,
+			Arguments: params.Arguments,
+		})
+		if done == nil {
+			return
+		}
+		done <- &ShadowCall{
+			ServerName: s.name,
+			Success:    err == nil,
+			Error:      errorToString(err),
+			ErrorCode:  errorCode(err),
+			DurationMs: time.Since(start).Milliseconds(),
+			Result:     res,
+		}
+	}()
+
+	return done
+}
+
+// compare sets whether the shadow call matches the call of the server, which
+// returned res or failed with err.
+func (c *ShadowCall) compare(res *mcp.CallToolResult, err error) *ShadowCall {
+	if err != nil || !c.Success {
+		c.Matches = err != nil && !c.Success
+		return c
+	}
+	if res == nil || c.Result == nil {
+		c.Matches = res == c.Result
+		return c
+	}
+	c.Matches = res.IsError == c.Result.IsError &&
+		jsonEqual(res.Content, c.Result.Content) &&
+		jsonEqual(res.StructuredContent, c.Result.StructuredContent)
+	return c
+}
+
+// jsonEqual reports whether a and b have the same JSON encoding.
+func jsonEqual(a, b any) bool {
+	aData, aErr := json.Marshal(a)
+	bData, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aData, bData)
+}
@@ -0,0 +1,271 @@
+package mcpproxy
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
+)
+
+func TestShadowConfigValidate(t *testing.T) {
+	tt := map[string]struct {
+		cfg       ShadowConfig
+		expectErr string
+	}{
+		"valid": {
+			cfg: ShadowConfig{Server: "echo", Shadow: "echo-v2", Record: true, Timeout: "5s"},
+		},
+		"missing shadow": {
+			cfg:       ShadowConfig{Server: "echo"},
+			expectErr: "server and shadow are required",
+		},
+		"same server": {
+			cfg:       ShadowConfig{Server: "echo", Shadow: "echo"},
+			expectErr: `shadow must be a different server than "echo"`,
+		},
+		"invalid timeout": {
+			cfg:       ShadowConfig{Server: "echo", Shadow: "echo-v2", Timeout: "soon"},
+			expectErr: `invalid timeout "soon"`,
+		},
+		"negative timeout": {
+			cfg:       ShadowConfig{Server: "echo", Shadow: "echo-v2", Timeout: "-1s"},
+			expectErr: "timeout must be > 0",
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.expectErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestShadowCallCompare(t *testing.T) {
+	tt := map[string]struct {
+		res      *mcp.CallToolResult
+		err      error
+		shadow   *ShadowCall
+		expected bool
+	}{
+		"same result": {
+			res:      textResult("nginx"),
+			shadow:   &ShadowCall{Success: true, Result: textResult("nginx")},
+			expected: true,
+		},
+		"different content": {
+			res:    textResult("nginx"),
+			shadow: &ShadowCall{Success: true, Result: textResult("nginx, redis")},
+		},
+		"different structured content": {
+			res: &mcp.CallToolResult{StructuredContent: map[string]any{"count": 1}},
+			shadow: &ShadowCall{Success: true, Result: &mcp.CallToolResult{
+				StructuredContent: map[string]any{"count": 2},
+			}},
+		},
+		"only the shadow result is an error": {
+			res: textResult("nginx"),
+			shadow: &ShadowCall{Success: true, Result: &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: "nginx"}},
+			}},
+		},
+		"both calls failed": {
+			err:      errors.New("unknown tool"),
+			shadow:   &ShadowCall{Error: "unknown tool"},
+			expected: true,
+		},
+		"only the shadow call failed": {
+			res:    textResult("nginx"),
+			shadow: &ShadowCall{Error: "context deadline exceeded"},
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.shadow.compare(tc.res, tc.err).Matches)
+		})
+	}
+}
+
+// startShadowedEchoServers starts the echo server and a shadow server of it,
+// whose "echo" tool upper cases messages after shadowDelay, and returns a
+// manager of clients connected to them, named "echo" and "echo-v2".
+// shadowCalls counts the tool calls to the shadow server.
+func startShadowedEchoServers(t *testing.T, shadowCalls *atomic.Int32, shadowDelay time.Duration) mcpclient.Manager {
+	t.Helper()
+
+	echo := func(upper bool) func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(_ context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			text := string(req.Params.Arguments)
+			if upper {
+				time.Sleep(shadowDelay)
+				shadowCalls.Add(1)
+				text = strings.ToUpper(text)
+			}
+			return textResult(text), nil
+		}
+	}
+
+	urls := make(map[string]string, 2)
+	for name, upper := range map[string]bool{"echo": false, "echo-v2": true} {
+		server := mcp.NewServer(&mcp.Implementation{Name: name, Version: "1.0.0"}, nil)
+		server.AddTool(&mcp.Tool{Name: "echo", InputSchema: map[string]any{"type": "object"}}, echo(upper))
+
+		srv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil))
+		t.Cleanup(srv.Close)
+		urls[name] = srv.URL
+	}
+
+	manager, err := mcpclient.NewManager(context.Background(), &mcpclient.MCPConfig{
+		MCPServers: map[string]*mcpclient.ServerConfig{
+			"echo":    {Type: mcpclient.TransportTypeHttp, URL: urls["echo"], EnableAllTools: true},
+			"echo-v2": {Type: mcpclient.TransportTypeHttp, URL: urls["echo-v2"], EnableAllTools: true},
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = manager.Close(context.Background()) })
+
+	return manager
+}
+
+func TestServerManagerShadowsToolCalls(t *testing.T) {
+	tt := map[string]struct {
+		record bool
+	}{
+		"responses recorded":  {record: true},
+		"responses discarded": {},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			ctx := context.Background()
+
+			var shadowCalls atomic.Int32
+			clients, err := NewShadowedManager(startShadowedEchoServers(t, &shadowCalls, 0),
+				[]ShadowConfig{{Server: "echo", Shadow: "echo-v2", Record: tc.record}})
+			require.NoError(t, err)
+			m, err := NewServerManager(ctx, clients)
+			require.NoError(t, err)
+			require.NoError(t, m.Start(ctx))
+			t.Cleanup(func() { _ = m.Close() })
+
+			// The agent only sees the server
+			assert.Len(t, m.GetMcpServers(), 1)
+			client := connectThrough(t, m)
+
+			matching, err := client.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{}})
+			require.NoError(t, err)
+			mismatching, err := client.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{"message": "hi"}})
+			require.NoError(t, err)
+
+			assert.Equal(t, "{}", matching.Content[0].(*mcp.TextContent).Text)
+			assert.Equal(t, `{"message":"hi"}`, mismatching.Content[0].(*mcp.TextContent).Text)
+			require.Eventually(t, func() bool { return shadowCalls.Load() == 2 }, time.Second, 10*time.Millisecond)
+
+			history := m.GetAllCallHistory()
+			require.Len(t, history.ToolCalls, 2)
+			if !tc.record {
+				assert.Nil(t, history.ToolCalls[0].Shadow)
+				assert.Nil(t, history.ToolCalls[1].Shadow)
+				return
+			}
+
+			require.NotNil(t, history.ToolCalls[0].Shadow)
+			assert.Equal(t, "echo-v2", history.ToolCalls[0].Shadow.ServerName)
+			assert.True(t, history.ToolCalls[0].Shadow.Success)
+			assert.True(t, history.ToolCalls[0].Shadow.Matches)
+
+			require.NotNil(t, history.ToolCalls[1].Shadow)
+			assert.False(t, history.ToolCalls[1].Shadow.Matches)
+			assert.Equal(t, "{\"MESSAGE\":\"HI\"}\n", (&ToolCall{Result: history.ToolCalls[1].Shadow.Result}).ResultText())
+		})
+	}
+}
+
+func TestServerManagerDoesNotWaitForShadows(t *testing.T) {
+	ctx := context.Background()
+
+	var shadowCalls atomic.Int32
+	clients, err := NewShadowedManager(startShadowedEchoServers(t, &shadowCalls, 500*time.Millisecond),
+		[]ShadowConfig{{Server: "echo", Shadow: "echo-v2", Record: true}})
+	require.NoError(t, err)
+	m, err := NewServerManager(ctx, clients)
+	require.NoError(t, err)
+	require.NoError(t, m.Start(ctx))
+	t.Cleanup(func() { _ = m.Close() })
+	client := connectThrough(t, m)
+
+	start := time.Now()
+	_, err = client.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "the agent got the result before the shadow server responded")
+
+	// The history waits for the shadow call
+	history := m.GetAllCallHistory()
+	require.Len(t, history.ToolCalls, 1)
+	require.NotNil(t, history.ToolCalls[0].Shadow)
+	assert.True(t, history.ToolCalls[0].Shadow.Matches)
+	assert.Equal(t, int32(1), shadowCalls.Load())
+}
+
+func TestNewShadowedManager(t *testing.T) {
+	tt := map[string]struct {
+		shadows         []ShadowConfig
+		expectedClients []string
+		expectShadowed  bool
+		expectErr       string
+	}{
+		"shadow server hidden": {
+			shadows:         []ShadowConfig{{Server: "echo", Shadow: "echo-v2"}},
+			expectedClients: []string{"echo"},
+			expectShadowed:  true,
+		},
+		"no shadows": {
+			expectedClients: []string{"echo", "echo-v2"},
+		},
+		"server not configured": {
+			shadows:         []ShadowConfig{{Server: "ping", Shadow: "echo-v2"}},
+			expectedClients: []string{"echo", "echo-v2"},
+		},
+		"shadow server not configured": {
+			shadows:   []ShadowConfig{{Server: "echo", Shadow: "echo-v3"}},
+			expectErr: `shadow server "echo-v3" of "echo" is not configured`,
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			var shadowCalls atomic.Int32
+			clients, err := NewShadowedManager(startShadowedEchoServers(t, &shadowCalls, 0), tc.shadows)
+			if tc.expectErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.ElementsMatch(t, tc.expectedClients, slices.Collect(maps.Keys(clients.GetAll())))
+			_, ok := clients.Get("echo-v2")
+			assert.Equal(t, !tc.expectShadowed, ok)
+			assert.Equal(t, tc.expectShadowed, shadowsOf(clients)["echo"] != nil)
+		})
+	}
+}
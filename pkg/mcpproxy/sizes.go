@@ -0,0 +1,71 @@
+package mcpproxy
+
+// CallSizes totals the sizes of the requests and responses of MCP calls, to
+// correlate agent failures with oversized tool results. Token counts are only
+// included once ComputeCallHistoryTokens has run.
+type CallSizes struct {
+	Calls          int   `json:"calls"`
+	RequestBytes   int64 `json:"requestBytes"`
+	ResponseBytes  int64 `json:"responseBytes"`
+	RequestTokens  int64 `json:"requestTokens,omitempty"`
+	ResponseTokens int64 `json:"responseTokens,omitempty"`
+
+	// MaxResponseBytes is the size of the largest response
+	MaxResponseBytes int64 `json:"maxResponseBytes"`
+}
+
+// Add adds the calls of other to s.
+func (s *CallSizes) Add(other *CallSizes) {
+	if other == nil {
+		return
+	}
+
+	s.Calls += other.Calls
+	s.RequestBytes += other.RequestBytes
+	s.ResponseBytes += other.ResponseBytes
+	s.RequestTokens += other.RequestTokens
+	s.ResponseTokens += other.ResponseTokens
+	s.MaxResponseBytes = max(s.MaxResponseBytes, other.MaxResponseBytes)
+}
+
+func (s *CallSizes) addCall(record CallRecord, tokens *TokenCount) {
+	s.Calls++
+	s.RequestBytes += record.RequestBytes
+	s.ResponseBytes += record.ResponseBytes
+	s.MaxResponseBytes = max(s.MaxResponseBytes, record.ResponseBytes)
+	if tokens != nil {
+		s.RequestTokens += tokens.InputTokens
+		s.ResponseTokens += tokens.OutputTokens
+	}
+}
+
+// SizesByServer totals the sizes of the tool calls, resource reads and prompt
+// gets of the history by server. It returns nil for an empty history.
+func (h *CallHistory) SizesByServer() map[string]*CallSizes {
+	if h == nil {
+		return nil
+	}
+
+	var sizes map[string]*CallSizes
+	add := func(record CallRecord, tokens *TokenCount) {
+		if sizes == nil {
+			sizes = make(map[string]*CallSizes)
+		}
+		if sizes[record.ServerName] == nil {
+			sizes[record.ServerName] = &CallSizes{}
+		}
+		sizes[record.ServerName].addCall(record, tokens)
+	}
+
+	for _, tc := range h.ToolCalls {
+		add(tc.CallRecord, tc.Tokens)
+	}
+	for _, rr := range h.ResourceReads {
+		add(rr.CallRecord, rr.Tokens)
+	}
+	for _, pg := range h.PromptGets {
+		add(pg.CallRecord, pg.Tokens)
+	}
+
+	return sizes
+}
@@ -0,0 +1,119 @@
+package mcpproxy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/mcpchecker/mcpchecker/pkg/tokenizer"
+)
+
+// Summarizer shortens the text of a tool result before it is returned to the
+// agent.
+type Summarizer interface {
+	Summarize(ctx context.Context, toolName, text string) (string, error)
+}
+
+// SummarizeConfig configures the summarization of large tool results by the
+// proxy servers, to evaluate agents that are helped by a cheaper model reading
+// large tool results for them.
+type SummarizeConfig struct {
+	// Model summarizing the results, in "provider:model-id" format
+	Model string `json:"model"`
+
+	// ThresholdTokens is the size of the tool results, in estimated tokens,
+	// above which they are summarized
+	ThresholdTokens int `json:"thresholdTokens"`
+
+	// Prompt replaces the default system prompt of the model
+	Prompt string `json:"prompt,omitempty"`
+}
+
+// Validate checks that a model and a positive threshold are set.
+func (c *SummarizeConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Model == "" {
+		return fmt.Errorf("model is required")
+	}
+	if c.ThresholdTokens <= 0 {
+		return fmt.Errorf("thresholdTokens must be > 0, got %d", c.ThresholdTokens)
+	}
+	return nil
+}
+
+// ToolResultSummary records the summary that was returned to the agent instead
+// of the result of a tool call. The call keeps the original result.
+type ToolResultSummary struct {
+	// Text is the summary, empty if summarization failed
+	Text string `json:"text,omitempty"`
+
+	// OriginalTokens and SummaryTokens are the estimated sizes of the result
+	// and of the summary
+	OriginalTokens int64 `json:"originalTokens"`
+	SummaryTokens  int64 `json:"summaryTokens,omitempty"`
+
+	// Error is why summarization failed, in which case the agent got the
+	// original result
+	Error string `json:"error,omitempty"`
+}
+
+// WithSummarizer makes the proxy servers return a summary by summarizer
+// instead of tool results larger than thresholdTokens.
+func WithSummarizer(summarizer Summarizer, thresholdTokens int) ServerOption {
+	return func(o *serverOptions) {
+		o.summarizer = &resultSummarizer{
+			summarizer:      summarizer,
+			thresholdTokens: thresholdTokens,
+			countTokens:     estimateTokens,
+		}
+	}
+}
+
+// resultSummarizer summarizes the tool results above its threshold.
+type resultSummarizer struct {
+	summarizer      Summarizer
+	thresholdTokens int
+	countTokens     func(text string) int64
+}
+
+// summarize returns the result to return to the agent for res, and the summary
+// if res was above the threshold. Error results and results with structured
+// content, which can't be replaced by text, are returned as they are.
+func (s *resultSummarizer) summarize(ctx context.Context, toolName string, res *mcp.CallToolResult) (*mcp.CallToolResult, *ToolResultSummary) {
+	if s == nil || res == nil || res.IsError || res.StructuredContent != nil {
+		return res, nil
+	}
+
+	text := (&ToolCall{Result: res}).ResultText()
+	tokens := s.countTokens(text)
+	if tokens <= int64(s.thresholdTokens) {
+		return res, nil
+	}
+
+	summary := &ToolResultSummary{OriginalTokens: tokens}
+	summaryText, err := s.summarizer.Summarize(ctx, toolName, text)
+	if err != nil {
+		summary.Error = err.Error()
+		return res, summary
+	}
+
+	summary.Text = summaryText
+	summary.SummaryTokens = s.countTokens(summaryText)
+	return &mcp.CallToolResult{
+		Meta:    res.Meta,
+		Content: []mcp.Content{&mcp.TextContent{Text: summaryText}},
+	}, summary
+}
+
+// estimateTokens counts the tokens of text, falling back to an estimate of
+// four bytes per token if the tokenizer isn't available.
+func estimateTokens(text string) int64 {
+	count, err := tokenizer.Get().CountTokens(text)
+	if err != nil {
+		return int64(len(text)+3) / 4
+	}
+	return int64(count)
+}
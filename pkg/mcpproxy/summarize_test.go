@@ -0,0 +1,152 @@
+package mcpproxy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSummarizer returns the first word of the text it summarizes, or err.
+type fakeSummarizer struct {
+	err   error
+	calls int
+}
+
+func (s *fakeSummarizer) Summarize(_ context.Context, toolName, text string) (string, error) {
+	s.calls++
+	if s.err != nil {
+		return "", s.err
+	}
+	return toolName + ": " + strings.Fields(text)[0], nil
+}
+
+func textResult(text string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}
+}
+
+func TestResultSummarizerSummarize(t *testing.T) {
+	tt := map[string]struct {
+		res             *mcp.CallToolResult
+		err             error
+		expectedText    string
+		expectedSummary *ToolResultSummary
+	}{
+		"below threshold": {
+			res:          textResult("one two three"),
+			expectedText: "one two three\n",
+		},
+		"above threshold": {
+			res:          textResult("one two three four five six"),
+			expectedText: "echo: one\n",
+			expectedSummary: &ToolResultSummary{
+				Text:           "echo: one",
+				OriginalTokens: 6,
+				SummaryTokens:  2,
+			},
+		},
+		"summarizer error returns the original result": {
+			res:          textResult("one two three four five six"),
+			err:          errors.New("rate limited"),
+			expectedText: "one two three four five six\n",
+			expectedSummary: &ToolResultSummary{
+				OriginalTokens: 6,
+				Error:          "rate limited",
+			},
+		},
+		"error result": {
+			res: &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: "one two three four five six"}},
+			},
+			expectedText: "one two three four five six\n",
+		},
+		"structured content": {
+			res: &mcp.CallToolResult{
+				Content:           []mcp.Content{&mcp.TextContent{Text: "one two three four five six"}},
+				StructuredContent: map[string]any{"count": 6},
+			},
+			expectedText: "one two three four five six\n",
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			s := &resultSummarizer{
+				summarizer:      &fakeSummarizer{err: tc.err},
+				thresholdTokens: 4,
+				countTokens:     func(text string) int64 { return int64(len(strings.Fields(text))) },
+			}
+
+			res, summary := s.summarize(context.Background(), "echo", tc.res)
+			assert.Equal(t, tc.expectedSummary, summary)
+			assert.Equal(t, tc.expectedText, (&ToolCall{Result: res}).ResultText())
+		})
+	}
+}
+
+func TestSummarizeConfigValidate(t *testing.T) {
+	tt := map[string]struct {
+		cfg       *SummarizeConfig
+		expectErr string
+	}{
+		"nil": {},
+		"valid": {
+			cfg: &SummarizeConfig{Model: "openai:gpt-5-mini", ThresholdTokens: 2000},
+		},
+		"missing model": {
+			cfg:       &SummarizeConfig{ThresholdTokens: 2000},
+			expectErr: "model is required",
+		},
+		"missing threshold": {
+			cfg:       &SummarizeConfig{Model: "openai:gpt-5-mini"},
+			expectErr: "thresholdTokens must be > 0",
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.expectErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestServerManagerSummarizesToolResults(t *testing.T) {
+	ctx := context.Background()
+
+	summarizer := &fakeSummarizer{}
+	m, err := NewServerManager(ctx, startEchoServer(t), WithSummarizer(summarizer, 5))
+	require.NoError(t, err)
+	require.NoError(t, m.Start(ctx))
+	t.Cleanup(func() { _ = m.Close() })
+
+	client := connectThrough(t, m)
+
+	// The echo tool returns its arguments, a short and a long result
+	short, err := client.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	long, err := client.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{"message": strings.Repeat("lorem ipsum ", 50)}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "{}", short.Content[0].(*mcp.TextContent).Text)
+	assert.Equal(t, `echo: {"message":"lorem`, long.Content[0].(*mcp.TextContent).Text)
+	assert.Equal(t, 1, summarizer.calls)
+
+	// The history keeps the original result along with the summary
+	history := m.GetAllCallHistory()
+	require.Len(t, history.ToolCalls, 2)
+	assert.Nil(t, history.ToolCalls[0].Summary)
+	require.NotNil(t, history.ToolCalls[1].Summary)
+	assert.Equal(t, `echo: {"message":"lorem`, history.ToolCalls[1].Summary.Text)
+	assert.Contains(t, history.ToolCalls[1].ResultText(), strings.Repeat("lorem ipsum ", 50))
+}
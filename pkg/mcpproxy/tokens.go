@@ -32,8 +32,10 @@ func ComputeCallHistoryTokens(history *CallHistory) string {
 			}
 		}
 
-		// Count output tokens (result content)
-		if tc.Result != nil {
+		// Count output tokens (result content, or the summary the agent got instead)
+		if tc.Summary != nil && tc.Summary.Text != "" {
+			outputTokens = countTextWithErrors(tok, tc.Summary.Text, fmt.Sprintf("tool_summary:%s", tc.ToolName), &errors)
+		} else if tc.Result != nil {
 			if count, err := tok.CountJSONTokens(tc.Result.Content); err != nil {
 				log.Printf("Warning: failed to count tool call output tokens for %q: %v", tc.ToolName, err)
 				errors = append(errors, fmt.Sprintf("tool_output:%s", tc.ToolName))
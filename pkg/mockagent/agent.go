@@ -0,0 +1,131 @@
+package mockagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Agent replays a scenario against a set of MCP servers.
+type Agent struct {
+	scenario *Scenario
+	out      io.Writer
+	log      io.Writer
+}
+
+// New creates an agent for scenario. The final message is written to out and
+// progress about tool calls to log.
+func New(scenario *Scenario, out, log io.Writer) *Agent {
+	if log == nil {
+		log = io.Discard
+	}
+
+	return &Agent{
+		scenario: scenario,
+		out:      out,
+		log:      log,
+	}
+}
+
+// Run handles a single prompt: it finds the matching behavior, makes its tool calls
+// against the servers in mcpConfig, and prints the final message.
+func (a *Agent) Run(ctx context.Context, mcpConfig *mcpclient.MCPConfig, prompt string) error {
+	behavior := a.scenario.Match(prompt)
+	if behavior == nil {
+		if a.scenario.DefaultError != "" {
+			return errors.New(a.scenario.DefaultError)
+		}
+		_, err := fmt.Fprint(a.out, a.scenario.DefaultFinalMessage)
+		return err
+	}
+
+	if behavior.Error != "" {
+		return errors.New(behavior.Error)
+	}
+
+	if len(behavior.ToolCalls) > 0 {
+		if err := a.callTools(ctx, mcpConfig, behavior.ToolCalls); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprint(a.out, behavior.FinalMessage)
+	return err
+}
+
+func (a *Agent) callTools(ctx context.Context, mcpConfig *mcpclient.MCPConfig, calls []ToolCall) error {
+	if mcpConfig == nil {
+		return fmt.Errorf("scenario makes tool calls but no MCP config was provided")
+	}
+
+	manager, err := mcpclient.NewManager(ctx, mcpConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to mcp servers: %w", err)
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		_ = manager.Close(closeCtx)
+	}()
+
+	for i, tc := range calls {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		client, server, err := resolveServer(manager, tc.Server)
+		if err != nil {
+			return fmt.Errorf("toolCalls[%d]: %w", i, err)
+		}
+
+		res, callErr := client.CallTool(ctx, &mcp.CallToolParams{
+			Name:      tc.Tool,
+			Arguments: tc.Arguments,
+		})
+		if callErr == nil && res != nil && res.IsError {
+			callErr = fmt.Errorf("tool returned an error result")
+		}
+
+		switch {
+		case callErr != nil && tc.ExpectError:
+			fmt.Fprintf(a.log, "tool %s::%s failed as expected: %v\n", server, tc.Tool, callErr)
+		case callErr != nil:
+			return fmt.Errorf("tool %s::%s failed: %w", server, tc.Tool, callErr)
+		case tc.ExpectError:
+			return fmt.Errorf("tool %s::%s was expected to fail but succeeded", server, tc.Tool)
+		default:
+			fmt.Fprintf(a.log, "tool %s::%s succeeded\n", server, tc.Tool)
+		}
+	}
+
+	return nil
+}
+
+// resolveServer returns the client for name, or for the first server alphabetically when name is empty.
+func resolveServer(manager mcpclient.Manager, name string) (*mcpclient.Client, string, error) {
+	if name != "" {
+		client, ok := manager.Get(name)
+		if !ok {
+			return nil, "", fmt.Errorf("unknown mcp server %q", name)
+		}
+		return client, name, nil
+	}
+
+	clients := manager.GetAll()
+	names := make([]string, 0, len(clients))
+	for n := range clients {
+		names = append(names, n)
+	}
+	if len(names) == 0 {
+		return nil, "", fmt.Errorf("no mcp servers available")
+	}
+	sort.Strings(names)
+
+	return clients[names[0]], names[0], nil
+}
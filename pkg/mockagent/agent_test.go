@@ -0,0 +1,149 @@
+package mockagent
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startTestServer starts an MCP server with an "echo" tool and a "fail" tool,
+// returning its config and a function that reports the tools called so far.
+func startTestServer(t *testing.T) (*mcpclient.MCPConfig, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var called []string
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	server.AddTool(&mcp.Tool{Name: "echo", InputSchema: map[string]any{"type": "object"}},
+		func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			mu.Lock()
+			called = append(called, req.Params.Name)
+			mu.Unlock()
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil
+		})
+	server.AddTool(&mcp.Tool{Name: "fail", InputSchema: map[string]any{"type": "object"}},
+		func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			mu.Lock()
+			called = append(called, req.Params.Name)
+			mu.Unlock()
+			return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: "boom"}}}, nil
+		})
+
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	cfg := &mcpclient.MCPConfig{
+		MCPServers: map[string]*mcpclient.ServerConfig{
+			"test": {Type: mcpclient.TransportTypeHttp, URL: srv.URL},
+		},
+	}
+
+	return cfg, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), called...)
+	}
+}
+
+func TestAgentRun(t *testing.T) {
+	tt := map[string]struct {
+		scenario      string
+		prompt        string
+		expectErr     bool
+		expectOutput  string
+		expectedCalls []string
+	}{
+		"tool calls then final message": {
+			scenario: `kind: MockScenario
+behaviors:
+  - promptContains: echo
+    toolCalls:
+      - server: test
+        tool: echo
+      - tool: echo
+    finalMessage: all done
+`,
+			prompt:        "please echo",
+			expectOutput:  "all done",
+			expectedCalls: []string{"echo", "echo"},
+		},
+		"expected error is tolerated": {
+			scenario: `kind: MockScenario
+behaviors:
+  - matchAny: true
+    toolCalls:
+      - tool: fail
+        expectError: true
+    finalMessage: recovered
+`,
+			expectOutput:  "recovered",
+			expectedCalls: []string{"fail"},
+		},
+		"unexpected tool error fails": {
+			scenario: `kind: MockScenario
+behaviors:
+  - matchAny: true
+    toolCalls:
+      - tool: fail
+`,
+			expectErr:     true,
+			expectedCalls: []string{"fail"},
+		},
+		"unknown server fails": {
+			scenario: `kind: MockScenario
+behaviors:
+  - matchAny: true
+    toolCalls:
+      - server: missing
+        tool: echo
+`,
+			expectErr: true,
+		},
+		"no match uses default": {
+			scenario: `kind: MockScenario
+behaviors:
+  - promptContains: nothing
+defaultFinalMessage: fallback
+`,
+			prompt:       "hello",
+			expectOutput: "fallback",
+		},
+		"behavior error": {
+			scenario: `kind: MockScenario
+behaviors:
+  - matchAny: true
+    error: scripted failure
+`,
+			expectErr: true,
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			cfg, calls := startTestServer(t)
+
+			scenario, err := Read([]byte(tc.scenario))
+			require.NoError(t, err)
+
+			out := &bytes.Buffer{}
+			err = New(scenario, out, nil).Run(context.Background(), cfg, tc.prompt)
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectOutput, out.String())
+			}
+			assert.Equal(t, tc.expectedCalls, calls())
+		})
+	}
+}
@@ -0,0 +1,368 @@
+package mockagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	DefaultFuzzIterations  = 50
+	DefaultFuzzMaxCalls    = 5
+	DefaultFuzzCallTimeout = 30 * time.Second
+)
+
+// FuzzOptions configures a fuzzing run.
+type FuzzOptions struct {
+	// Seed makes the generated call sequences reproducible.
+	Seed uint64
+	// Iterations is the number of call sequences to generate.
+	Iterations int
+	// MaxCalls is the maximum number of tool calls in a sequence.
+	MaxCalls int
+	// CallTimeout bounds each tool call; exceeding it counts as a failure.
+	CallTimeout time.Duration
+	// Assertions, if set, are evaluated against each sequence's call history.
+	Assertions *eval.TaskAssertions
+}
+
+// FuzzFailure is a call sequence that made the proxy, a server, or an assertion misbehave.
+type FuzzFailure struct {
+	Iteration int
+	Reason    string
+	// Calls is the minimized sequence that still reproduces the failure.
+	Calls []ToolCall
+	// OriginalCalls is the number of calls in the sequence before minimization.
+	OriginalCalls int
+}
+
+// FuzzReport summarizes a fuzzing run.
+type FuzzReport struct {
+	Seed       uint64
+	Iterations int
+	ToolCalls  int
+	// ToolErrors counts calls that returned an error result, which is valid server behavior.
+	ToolErrors int
+	// SkippedTools lists tools whose input schema could not be satisfied, with the reason.
+	SkippedTools map[string]string
+	// AssertionPasses counts, per assertion, how many sequences it passed. An assertion
+	// that passes for random input is likely too lenient.
+	AssertionPasses map[string]int
+	Failure         *FuzzFailure
+}
+
+// fuzzTool is a tool with a generator for its arguments.
+type fuzzTool struct {
+	server    string
+	name      string
+	generator *argumentGenerator
+}
+
+// Fuzzer generates random, schema-valid tool call sequences and runs them through
+// the mcpchecker proxy against the configured MCP servers.
+type Fuzzer struct {
+	manager mcpclient.Manager
+	tools   []fuzzTool
+	opts    FuzzOptions
+	log     io.Writer
+
+	skipped map[string]string
+}
+
+// NewFuzzer lists the allowed tools of every server in manager and prepares argument
+// generators for them. Progress is written to log.
+func NewFuzzer(ctx context.Context, manager mcpclient.Manager, opts FuzzOptions, log io.Writer) (*Fuzzer, error) {
+	if log == nil {
+		log = io.Discard
+	}
+	if opts.Iterations <= 0 {
+		opts.Iterations = DefaultFuzzIterations
+	}
+	if opts.MaxCalls <= 0 {
+		opts.MaxCalls = DefaultFuzzMaxCalls
+	}
+	if opts.CallTimeout <= 0 {
+		opts.CallTimeout = DefaultFuzzCallTimeout
+	}
+
+	f := &Fuzzer{
+		manager: manager,
+		opts:    opts,
+		log:     log,
+		skipped: map[string]string{},
+	}
+
+	clients := manager.GetAll()
+	for _, server := range slices.Sorted(maps.Keys(clients)) {
+		tools := clients[server].GetAllowedTools(ctx)
+		slices.SortFunc(tools, func(a, b *mcp.Tool) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+
+		for _, tool := range tools {
+			gen, err := newArgumentGenerator(tool.InputSchema)
+			if err != nil {
+				f.skipped[server+"::"+tool.Name] = err.Error()
+				continue
+			}
+			f.tools = append(f.tools, fuzzTool{server: server, name: tool.Name, generator: gen})
+		}
+	}
+
+	if len(f.tools) == 0 {
+		return nil, fmt.Errorf("no allowed tools found on the configured mcp servers")
+	}
+
+	return f, nil
+}
+
+// Run generates opts.Iterations call sequences and runs each one. It stops at the
+// first failure, which is minimized before being returned in the report.
+func (f *Fuzzer) Run(ctx context.Context) (*FuzzReport, error) {
+	rng := rand.New(rand.NewPCG(f.opts.Seed, f.opts.Seed))
+
+	report := &FuzzReport{
+		Seed:            f.opts.Seed,
+		SkippedTools:    f.skipped,
+		AssertionPasses: map[string]int{},
+	}
+
+	for i := range f.opts.Iterations {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		calls := f.generate(rng)
+		if len(calls) == 0 {
+			continue
+		}
+
+		run, err := f.execute(ctx, calls)
+		if err != nil {
+			return report, err
+		}
+
+		report.Iterations++
+		report.ToolCalls += len(calls)
+		report.ToolErrors += run.toolErrors
+		for name, passed := range run.assertions {
+			if passed {
+				report.AssertionPasses[name]++
+			} else if _, ok := report.AssertionPasses[name]; !ok {
+				report.AssertionPasses[name] = 0
+			}
+		}
+
+		if run.failure == "" {
+			fmt.Fprintf(f.log, "iteration %d: %d calls ok\n", i, len(calls))
+			continue
+		}
+
+		fmt.Fprintf(f.log, "iteration %d: %s; minimizing %d calls\n", i, run.failure, len(calls))
+		minimized := Minimize(calls, func(candidate []ToolCall) bool {
+			r, err := f.execute(ctx, candidate)
+			return err == nil && r.failure != ""
+		})
+
+		report.Failure = &FuzzFailure{
+			Iteration:     i,
+			Reason:        run.failure,
+			Calls:         minimized,
+			OriginalCalls: len(calls),
+		}
+		return report, nil
+	}
+
+	return report, nil
+}
+
+// generate returns a sequence of up to MaxCalls tool calls with schema-valid arguments.
+// Tools whose arguments cannot be generated are skipped and not called again.
+func (f *Fuzzer) generate(rng *rand.Rand) []ToolCall {
+	n := 1 + rng.IntN(f.opts.MaxCalls)
+	calls := make([]ToolCall, 0, n)
+	for range n {
+		if len(f.tools) == 0 {
+			break
+		}
+		i := rng.IntN(len(f.tools))
+		tool := f.tools[i]
+		args, err := tool.generator.generate(rng)
+		if err != nil {
+			key := tool.server + "::" + tool.name
+			fmt.Fprintf(f.log, "skipping %s: %v\n", key, err)
+			f.skipped[key] = err.Error()
+			f.tools = slices.Delete(f.tools, i, i+1)
+			continue
+		}
+		calls = append(calls, ToolCall{Server: tool.server, Tool: tool.name, Arguments: args})
+	}
+	return calls
+}
+
+// fuzzRun is the outcome of running a single call sequence.
+type fuzzRun struct {
+	// failure describes why the sequence failed, empty if it did not
+	failure    string
+	toolErrors int
+	assertions map[string]bool
+}
+
+// execute runs calls through a fresh proxy, so each sequence gets its own call history.
+// Errors are only returned for problems setting up the proxy.
+func (f *Fuzzer) execute(ctx context.Context, calls []ToolCall) (*fuzzRun, error) {
+	proxy, err := mcpproxy.NewServerManager(ctx, f.manager)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mcp proxy: %w", err)
+	}
+	if err := proxy.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start mcp proxy: %w", err)
+	}
+	defer func() { _ = proxy.Close() }()
+
+	files, err := proxy.GetMcpServerFiles()
+	if err != nil {
+		return nil, err
+	}
+	proxyConfig, err := mcpclient.ParseConfigFile(files[0])
+	if err != nil {
+		return nil, err
+	}
+
+	clients, err := mcpclient.NewManager(ctx, proxyConfig)
+	if err != nil {
+		return &fuzzRun{failure: fmt.Sprintf("connecting through the proxy failed: %v", err)}, nil
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		_ = clients.Close(closeCtx)
+	}()
+
+	run := &fuzzRun{}
+	for i, tc := range calls {
+		client, ok := clients.Get(tc.Server)
+		if !ok {
+			return nil, fmt.Errorf("unknown mcp server %q", tc.Server)
+		}
+
+		callCtx, cancel := context.WithTimeout(ctx, f.opts.CallTimeout)
+		res, err := client.CallTool(callCtx, &mcp.CallToolParams{Name: tc.Tool, Arguments: tc.Arguments})
+		cancel()
+
+		// Error results are valid responses; protocol and transport errors are not
+		if err != nil {
+			run.failure = fmt.Sprintf("call %d to %s::%s failed: %v", i, tc.Server, tc.Tool, err)
+			return run, nil
+		}
+		if res.IsError {
+			run.toolErrors++
+		}
+	}
+
+	history := proxy.GetAllCallHistory()
+	if got := len(history.ToolCalls); got != len(calls) {
+		run.failure = fmt.Sprintf("proxy recorded %d tool calls, expected %d", got, len(calls))
+		return run, nil
+	}
+
+	if f.opts.Assertions != nil {
+		run.assertions, err = evaluateAssertions(f.opts.Assertions, history)
+		if err != nil {
+			run.failure = err.Error()
+		}
+	}
+
+	return run, nil
+}
+
+// evaluateAssertions returns whether each configured assertion passed. Panics in
+// the evaluators are reported as errors.
+func evaluateAssertions(assertions *eval.TaskAssertions, history *mcpproxy.CallHistory) (passed map[string]bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("assertion evaluation panicked: %v", r)
+		}
+	}()
+
+	result := eval.NewCompositeAssertionEvaluator(assertions).Evaluate(history)
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var singles map[string]*eval.SingleAssertionResult
+	if err := json.Unmarshal(data, &singles); err != nil {
+		return nil, err
+	}
+
+	passed = make(map[string]bool, len(singles))
+	for name, single := range singles {
+		if single != nil {
+			passed[name] = single.Passed
+		}
+	}
+	return passed, nil
+}
+
+// Minimize returns a smaller sequence for which fails still returns true, removing
+// calls one at a time until no single call can be removed.
+func Minimize(calls []ToolCall, fails func([]ToolCall) bool) []ToolCall {
+	current := slices.Clone(calls)
+	for {
+		reduced := false
+		for i := 0; i < len(current) && len(current) > 1; i++ {
+			candidate := slices.Delete(slices.Clone(current), i, i+1)
+			if fails(candidate) {
+				current = candidate
+				reduced = true
+				i--
+			}
+		}
+		if !reduced {
+			return current
+		}
+	}
+}
+
+// Scenario returns a MockScenario that replays the failing calls for any prompt.
+func (ff *FuzzFailure) Scenario(seed uint64) *Scenario {
+	return &Scenario{
+		TypeMeta: util.TypeMeta{Kind: KindMockScenario},
+		Metadata: ScenarioMetadata{Name: fmt.Sprintf("fuzz-seed-%d-iteration-%d", seed, ff.Iteration)},
+		Behaviors: []Behavior{{
+			Name:         ff.Reason,
+			MatchAny:     true,
+			ToolCalls:    ff.Calls,
+			FinalMessage: "fuzz reproduction complete",
+		}},
+	}
+}
+
+// WriteFile writes the scenario as YAML to path.
+func (s *Scenario) WriteFile(path string) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal scenario: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write scenario file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,254 @@
+package mockagent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArgumentGenerator(t *testing.T) {
+	tt := map[string]struct {
+		schema    string
+		expectErr bool
+		check     func(t *testing.T, args map[string]any)
+	}{
+		"required properties are always set": {
+			schema: `{"type":"object","required":["name","count"],"properties":{
+				"name":{"type":"string","minLength":2,"maxLength":4},
+				"count":{"type":"integer","minimum":1,"maximum":3},
+				"verbose":{"type":"boolean"}}}`,
+			check: func(t *testing.T, args map[string]any) {
+				assert.Contains(t, args, "name")
+				assert.Contains(t, args, "count")
+			},
+		},
+		"enum values are respected": {
+			schema: `{"type":"object","required":["mode"],"properties":{"mode":{"enum":["fast","slow"]}}}`,
+			check: func(t *testing.T, args map[string]any) {
+				assert.Contains(t, []any{"fast", "slow"}, args["mode"])
+			},
+		},
+		"nested arrays and objects": {
+			schema: `{"type":"object","required":["items"],"properties":{"items":{"type":"array","minItems":1,"maxItems":2,
+				"items":{"type":"object","required":["id"],"properties":{"id":{"type":"number","exclusiveMinimum":0,"exclusiveMaximum":1}}}}}}`,
+			check: func(t *testing.T, args map[string]any) {
+				items, ok := args["items"].([]any)
+				require.True(t, ok)
+				assert.NotEmpty(t, items)
+			},
+		},
+		"empty schema": {
+			schema: `{"type":"object"}`,
+		},
+		"unsatisfiable pattern": {
+			schema:    `{"type":"object","required":["id"],"properties":{"id":{"type":"string","pattern":"^[0-9]{40}$"}}}`,
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			var schema map[string]any
+			require.NoError(t, json.Unmarshal([]byte(tc.schema), &schema))
+
+			gen, err := newArgumentGenerator(schema)
+			require.NoError(t, err)
+
+			rng := rand.New(rand.NewPCG(1, 1))
+			for range 25 {
+				args, err := gen.generate(rng)
+				if tc.expectErr {
+					assert.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				assert.NoError(t, gen.resolved.Validate(args))
+				if tc.check != nil {
+					tc.check(t, args)
+				}
+			}
+		})
+	}
+}
+
+func TestMinimize(t *testing.T) {
+	calls := []ToolCall{
+		{Tool: "a"}, {Tool: "b"}, {Tool: "crash"}, {Tool: "c"}, {Tool: "crash"},
+	}
+
+	attempts := 0
+	minimized := Minimize(calls, func(candidate []ToolCall) bool {
+		attempts++
+		for _, c := range candidate {
+			if c.Tool == "crash" {
+				return true
+			}
+		}
+		return false
+	})
+
+	assert.Equal(t, []ToolCall{{Tool: "crash"}}, minimized)
+	assert.Positive(t, attempts)
+	assert.Len(t, calls, 5, "input should not be modified")
+}
+
+func TestFuzzerGenerateDropsUnsatisfiableTools(t *testing.T) {
+	echo, err := newArgumentGenerator(map[string]any{"type": "object"})
+	require.NoError(t, err)
+	impossible, err := newArgumentGenerator(map[string]any{
+		"type":     "object",
+		"required": []any{"id"},
+		"properties": map[string]any{
+			"id": map[string]any{"type": "string", "minLength": 5, "maxLength": 2},
+		},
+	})
+	require.NoError(t, err)
+
+	f := &Fuzzer{
+		tools: []fuzzTool{
+			{server: "s", name: "echo", generator: echo},
+			{server: "s", name: "impossible", generator: impossible},
+		},
+		opts:    FuzzOptions{MaxCalls: 10},
+		log:     io.Discard,
+		skipped: map[string]string{},
+	}
+
+	rng := rand.New(rand.NewPCG(1, 1))
+	for range 20 {
+		for _, call := range f.generate(rng) {
+			assert.Equal(t, "echo", call.Tool)
+		}
+	}
+
+	assert.Contains(t, f.skipped, "s::impossible")
+	require.Len(t, f.tools, 1)
+	assert.Equal(t, "echo", f.tools[0].name)
+}
+
+// startFuzzServer starts an MCP server with an "echo" tool and, if withCrash is set,
+// a "crash" tool whose handler returns a protocol error.
+func startFuzzServer(t *testing.T, withCrash bool) (*mcpclient.MCPConfig, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var calls []string
+
+	schema := map[string]any{
+		"type":     "object",
+		"required": []any{"message"},
+		"properties": map[string]any{
+			"message": map[string]any{"type": "string", "maxLength": 8},
+			"repeat":  map[string]any{"type": "integer", "minimum": 1, "maximum": 3},
+		},
+	}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "fuzz", Version: "1.0.0"}, nil)
+	server.AddTool(&mcp.Tool{Name: "echo", InputSchema: schema},
+		func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			mu.Lock()
+			calls = append(calls, string(req.Params.Arguments))
+			mu.Unlock()
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil
+		})
+	if withCrash {
+		server.AddTool(&mcp.Tool{Name: "crash", InputSchema: map[string]any{"type": "object"}},
+			func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return nil, errors.New("internal server error")
+			})
+	}
+
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	cfg := &mcpclient.MCPConfig{
+		MCPServers: map[string]*mcpclient.ServerConfig{
+			"fuzz": {Type: mcpclient.TransportTypeHttp, URL: srv.URL, EnableAllTools: true},
+		},
+	}
+
+	return cfg, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), calls...)
+	}
+}
+
+func runFuzzer(t *testing.T, cfg *mcpclient.MCPConfig, opts FuzzOptions) *FuzzReport {
+	t.Helper()
+
+	ctx := context.Background()
+	manager, err := mcpclient.NewManager(ctx, cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = manager.Close(context.Background()) })
+
+	fuzzer, err := NewFuzzer(ctx, manager, opts, nil)
+	require.NoError(t, err)
+
+	report, err := fuzzer.Run(ctx)
+	require.NoError(t, err)
+
+	return report
+}
+
+func TestFuzzerRunIsDeterministic(t *testing.T) {
+	opts := FuzzOptions{Seed: 42, Iterations: 5, MaxCalls: 3}
+
+	cfg1, calls1 := startFuzzServer(t, false)
+	report1 := runFuzzer(t, cfg1, opts)
+
+	cfg2, calls2 := startFuzzServer(t, false)
+	report2 := runFuzzer(t, cfg2, opts)
+
+	assert.Nil(t, report1.Failure)
+	assert.Equal(t, 5, report1.Iterations)
+	assert.Equal(t, report1.ToolCalls, len(calls1()))
+	assert.Equal(t, calls1(), calls2())
+	assert.Equal(t, report1, report2)
+}
+
+func TestFuzzerRunMinimizesFailure(t *testing.T) {
+	cfg, _ := startFuzzServer(t, true)
+	report := runFuzzer(t, cfg, FuzzOptions{Seed: 7, Iterations: 20, MaxCalls: 4})
+
+	require.NotNil(t, report.Failure)
+	assert.Contains(t, report.Failure.Reason, "crash")
+	assert.Equal(t, []ToolCall{{Server: "fuzz", Tool: "crash", Arguments: map[string]any{}}}, report.Failure.Calls)
+
+	// The reproduction scenario can be loaded by the mock agent
+	path := filepath.Join(t.TempDir(), "repro.yaml")
+	require.NoError(t, report.Failure.Scenario(report.Seed).WriteFile(path))
+
+	scenario, err := FromFile(path)
+	require.NoError(t, err)
+	behavior := scenario.Match("any prompt")
+	require.NotNil(t, behavior)
+	assert.Len(t, behavior.ToolCalls, 1)
+}
+
+func TestFuzzerRunCountsAssertionPasses(t *testing.T) {
+	cfg, _ := startFuzzServer(t, false)
+	minCalls := 1
+	report := runFuzzer(t, cfg, FuzzOptions{
+		Seed:       3,
+		Iterations: 4,
+		MaxCalls:   2,
+		Assertions: &eval.TaskAssertions{MinToolCalls: &minCalls},
+	})
+
+	assert.Equal(t, 4, report.AssertionPasses["minToolCalls"])
+}
@@ -0,0 +1,262 @@
+package mockagent
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+const (
+	// maxGenerateDepth bounds nesting of generated objects and arrays.
+	maxGenerateDepth = 3
+	// maxGenerateAttempts is how many candidates are tried before a schema is
+	// considered too restrictive to fuzz (e.g. an unsatisfiable pattern).
+	maxGenerateAttempts = 20
+
+	defaultMaxStringLength = 12
+	defaultMaxArrayItems   = 3
+	defaultIntegerRange    = 100
+)
+
+const generateAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_ "
+
+// argumentGenerator produces random arguments that validate against a tool's input schema.
+type argumentGenerator struct {
+	schema   *jsonschema.Schema
+	resolved *jsonschema.Resolved
+}
+
+// newArgumentGenerator converts a tool input schema, as returned by tools/list, into a generator.
+func newArgumentGenerator(inputSchema any) (*argumentGenerator, error) {
+	data, err := json.Marshal(inputSchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal input schema: %w", err)
+	}
+
+	schema := &jsonschema.Schema{}
+	if string(data) != "null" {
+		if err := json.Unmarshal(data, schema); err != nil {
+			return nil, fmt.Errorf("failed to parse input schema: %w", err)
+		}
+	}
+
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve input schema: %w", err)
+	}
+
+	return &argumentGenerator{schema: schema, resolved: resolved}, nil
+}
+
+// generate returns arguments that validate against the schema. It returns an
+// error if no valid arguments were found within maxGenerateAttempts.
+func (g *argumentGenerator) generate(rng *rand.Rand) (map[string]any, error) {
+	var lastErr error
+	for range maxGenerateAttempts {
+		args, ok := generateValue(rng, g.schema, 0).(map[string]any)
+		if !ok {
+			args = map[string]any{}
+		}
+
+		// Round-trip through JSON so validation sees the same values the server will
+		data, err := json.Marshal(args)
+		if err != nil {
+			return nil, err
+		}
+		var instance map[string]any
+		if err := json.Unmarshal(data, &instance); err != nil {
+			return nil, err
+		}
+
+		if lastErr = g.resolved.Validate(instance); lastErr == nil {
+			return instance, nil
+		}
+	}
+
+	return nil, fmt.Errorf("could not generate valid arguments after %d attempts: %w", maxGenerateAttempts, lastErr)
+}
+
+// generateValue returns a random value for schema. Constraints that cannot be
+// honoured directly (such as patterns) are left to validation in generate.
+func generateValue(rng *rand.Rand, schema *jsonschema.Schema, depth int) any {
+	if schema == nil {
+		return randomString(rng, 0, defaultMaxStringLength)
+	}
+	if schema.Const != nil {
+		return *schema.Const
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[rng.IntN(len(schema.Enum))]
+	}
+
+	switch schemaType(rng, schema) {
+	case "object":
+		return generateObject(rng, schema, depth)
+	case "array":
+		return generateArray(rng, schema, depth)
+	case "integer":
+		return generateInteger(rng, schema)
+	case "number":
+		return generateNumber(rng, schema)
+	case "boolean":
+		return rng.IntN(2) == 0
+	case "null":
+		return nil
+	default:
+		return generateString(rng, schema)
+	}
+}
+
+// schemaType picks the type to generate for schema, inferring it when unset.
+func schemaType(rng *rand.Rand, schema *jsonschema.Schema) string {
+	switch {
+	case schema.Type != "":
+		return schema.Type
+	case len(schema.Types) > 0:
+		return schema.Types[rng.IntN(len(schema.Types))]
+	case len(schema.Properties) > 0 || len(schema.Required) > 0:
+		return "object"
+	case schema.Items != nil:
+		return "array"
+	default:
+		return "string"
+	}
+}
+
+func generateObject(rng *rand.Rand, schema *jsonschema.Schema, depth int) map[string]any {
+	obj := map[string]any{}
+	for _, name := range schema.Required {
+		obj[name] = generateValue(rng, schema.Properties[name], depth+1)
+	}
+
+	if depth >= maxGenerateDepth {
+		return obj
+	}
+
+	// Include each optional property about half of the time. Iterate in a stable
+	// order so the same seed always produces the same arguments.
+	for _, name := range slices.Sorted(maps.Keys(schema.Properties)) {
+		if _, ok := obj[name]; ok || rng.IntN(2) == 0 {
+			continue
+		}
+		obj[name] = generateValue(rng, schema.Properties[name], depth+1)
+	}
+
+	return obj
+}
+
+func generateArray(rng *rand.Rand, schema *jsonschema.Schema, depth int) []any {
+	lo, hi := 0, defaultMaxArrayItems
+	if schema.MinItems != nil {
+		lo = *schema.MinItems
+	}
+	if schema.MaxItems != nil {
+		hi = *schema.MaxItems
+	}
+	if hi < lo {
+		hi = lo
+	}
+	if depth >= maxGenerateDepth {
+		hi = lo
+	}
+
+	n := lo + rng.IntN(hi-lo+1)
+	items := make([]any, n)
+	for i := range items {
+		items[i] = generateValue(rng, schema.Items, depth+1)
+	}
+	return items
+}
+
+func generateInteger(rng *rand.Rand, schema *jsonschema.Schema) int64 {
+	lo, hi := numericBounds(schema, -defaultIntegerRange, defaultIntegerRange)
+	first, last := int64(math.Ceil(lo)), int64(math.Floor(hi))
+	if schema.ExclusiveMinimum != nil && float64(first) <= *schema.ExclusiveMinimum {
+		first++
+	}
+	if schema.ExclusiveMaximum != nil && float64(last) >= *schema.ExclusiveMaximum {
+		last--
+	}
+	if last < first {
+		return first
+	}
+	return first + rng.Int64N(last-first+1)
+}
+
+func generateNumber(rng *rand.Rand, schema *jsonschema.Schema) float64 {
+	lo, hi := numericBounds(schema, -defaultIntegerRange, defaultIntegerRange)
+	if hi <= lo {
+		return lo
+	}
+	// Stay strictly inside the range so exclusive bounds also hold
+	return lo + (hi-lo)*(0.05+0.9*rng.Float64())
+}
+
+// numericBounds returns the inclusive or exclusive range allowed by schema, or the
+// defaults for unset bounds.
+func numericBounds(schema *jsonschema.Schema, defaultMin, defaultMax float64) (float64, float64) {
+	lo, hi := defaultMin, defaultMax
+	switch {
+	case schema.Minimum != nil:
+		lo = *schema.Minimum
+	case schema.ExclusiveMinimum != nil:
+		lo = *schema.ExclusiveMinimum
+	}
+	switch {
+	case schema.Maximum != nil:
+		hi = *schema.Maximum
+	case schema.ExclusiveMaximum != nil:
+		hi = *schema.ExclusiveMaximum
+	}
+	if schema.Minimum != nil || schema.ExclusiveMinimum != nil {
+		if schema.Maximum == nil && schema.ExclusiveMaximum == nil {
+			hi = lo + 2*defaultIntegerRange
+		}
+	} else if schema.Maximum != nil || schema.ExclusiveMaximum != nil {
+		lo = hi - 2*defaultIntegerRange
+	}
+	return lo, hi
+}
+
+func generateString(rng *rand.Rand, schema *jsonschema.Schema) string {
+	switch schema.Format {
+	case "date-time":
+		return time.Unix(rng.Int64N(2_000_000_000), 0).UTC().Format(time.RFC3339)
+	case "date":
+		return time.Unix(rng.Int64N(2_000_000_000), 0).UTC().Format(time.DateOnly)
+	case "uri", "url":
+		return "https://example.com/" + randomString(rng, 1, 8)
+	case "email":
+		return randomString(rng, 1, 8) + "@example.com"
+	case "uuid":
+		return fmt.Sprintf("%08x-%04x-4%03x-8%03x-%012x",
+			rng.Uint32(), rng.Uint32()&0xffff, rng.Uint32()&0xfff, rng.Uint32()&0xfff, rng.Uint64()&0xffffffffffff)
+	}
+
+	lo, hi := 0, defaultMaxStringLength
+	if schema.MinLength != nil {
+		lo = *schema.MinLength
+	}
+	if schema.MaxLength != nil {
+		hi = *schema.MaxLength
+	}
+	if hi < lo {
+		hi = lo + defaultMaxStringLength
+	}
+	return randomString(rng, lo, hi)
+}
+
+func randomString(rng *rand.Rand, minLen, maxLen int) string {
+	n := minLen + rng.IntN(maxLen-minLen+1)
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = generateAlphabet[rng.IntN(len(generateAlphabet))]
+	}
+	return string(b)
+}
@@ -0,0 +1,153 @@
+// Package mockagent implements a scripted agent that replays tool calls and final
+// messages from a YAML scenario. It lets eval configs, assertions, and judges be
+// validated deterministically without calling a real model.
+package mockagent
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	KindMockScenario = "MockScenario"
+)
+
+// Scenario defines how the mock agent responds to prompts.
+type Scenario struct {
+	util.TypeMeta `json:",inline"`
+	Metadata      ScenarioMetadata `json:"metadata"`
+
+	// Behaviors are matched against the prompt in order; the first match wins.
+	Behaviors []Behavior `json:"behaviors"`
+
+	// DefaultFinalMessage is printed when no behavior matches.
+	DefaultFinalMessage string `json:"defaultFinalMessage,omitempty"`
+
+	// DefaultError makes the agent fail when no behavior matches.
+	DefaultError string `json:"defaultError,omitempty"`
+}
+
+type ScenarioMetadata struct {
+	Name string `json:"name"`
+}
+
+// Behavior is a scripted response to a matching prompt.
+type Behavior struct {
+	// Name is an optional identifier used in error messages
+	Name string `json:"name,omitempty"`
+
+	// Match conditions - at least one must be set
+	PromptContains string `json:"promptContains,omitempty"`
+	PromptMatches  string `json:"promptMatches,omitempty"` // regex pattern
+	MatchAny       bool   `json:"matchAny,omitempty"`
+
+	// ToolCalls are made in order before the final message is printed
+	ToolCalls []ToolCall `json:"toolCalls,omitempty"`
+
+	// FinalMessage is printed to stdout once all tool calls have completed
+	FinalMessage string `json:"finalMessage,omitempty"`
+
+	// Error makes the agent exit with an error instead of printing a final message
+	Error string `json:"error,omitempty"`
+
+	promptRegex *regexp.Regexp
+}
+
+// ToolCall is a single scripted call to an MCP tool.
+type ToolCall struct {
+	// Server is the MCP server name. If empty, the first server (alphabetically) is used
+	Server string `json:"server,omitempty"`
+
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+
+	// ExpectError marks the call as expected to fail; the scenario fails if it succeeds
+	ExpectError bool `json:"expectError,omitempty"`
+}
+
+// Read parses and validates a scenario from YAML or JSON data.
+func Read(data []byte) (*Scenario, error) {
+	scenario := &Scenario{}
+
+	if err := yaml.Unmarshal(data, scenario); err != nil {
+		return nil, err
+	}
+
+	if err := scenario.TypeMeta.Validate(KindMockScenario); err != nil {
+		return nil, err
+	}
+
+	if err := scenario.validate(); err != nil {
+		return nil, err
+	}
+
+	return scenario, nil
+}
+
+// FromFile reads a scenario from path.
+func FromFile(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file '%s' for mock scenario: %w", path, err)
+	}
+
+	return Read(data)
+}
+
+func (s *Scenario) validate() error {
+	for i := range s.Behaviors {
+		b := &s.Behaviors[i]
+		if !b.MatchAny && b.PromptContains == "" && b.PromptMatches == "" {
+			return fmt.Errorf("behavior %s: one of promptContains, promptMatches or matchAny is required", b.displayName(i))
+		}
+
+		if b.PromptMatches != "" {
+			re, err := regexp.Compile(b.PromptMatches)
+			if err != nil {
+				return fmt.Errorf("behavior %s: invalid promptMatches: %w", b.displayName(i), err)
+			}
+			b.promptRegex = re
+		}
+
+		for j, tc := range b.ToolCalls {
+			if tc.Tool == "" {
+				return fmt.Errorf("behavior %s: toolCalls[%d]: tool is required", b.displayName(i), j)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Match returns the first behavior matching prompt, or nil if none match.
+func (s *Scenario) Match(prompt string) *Behavior {
+	for i := range s.Behaviors {
+		b := &s.Behaviors[i]
+
+		if b.MatchAny {
+			return b
+		}
+
+		if b.PromptContains != "" && strings.Contains(prompt, b.PromptContains) {
+			return b
+		}
+
+		if b.promptRegex != nil && b.promptRegex.MatchString(prompt) {
+			return b
+		}
+	}
+
+	return nil
+}
+
+func (b *Behavior) displayName(idx int) string {
+	if b.Name != "" {
+		return fmt.Sprintf("%q", b.Name)
+	}
+	return fmt.Sprintf("[%d]", idx)
+}
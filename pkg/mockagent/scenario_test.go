@@ -0,0 +1,100 @@
+package mockagent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	tt := map[string]struct {
+		data      string
+		expectErr bool
+	}{
+		"valid scenario": {
+			data: `kind: MockScenario
+metadata:
+  name: pods
+behaviors:
+  - name: list
+    promptContains: pods
+    toolCalls:
+      - server: kubernetes
+        tool: pods_list
+        arguments:
+          namespace: default
+    finalMessage: done
+`,
+		},
+		"wrong kind": {
+			data:      "kind: Task\nmetadata:\n  name: x\n",
+			expectErr: true,
+		},
+		"behavior without matcher": {
+			data: `kind: MockScenario
+behaviors:
+  - finalMessage: done
+`,
+			expectErr: true,
+		},
+		"invalid regex": {
+			data: `kind: MockScenario
+behaviors:
+  - promptMatches: "("
+`,
+			expectErr: true,
+		},
+		"tool call without tool": {
+			data: `kind: MockScenario
+behaviors:
+  - matchAny: true
+    toolCalls:
+      - server: kubernetes
+`,
+			expectErr: true,
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			_, err := Read([]byte(tc.data))
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestScenarioMatch(t *testing.T) {
+	scenario, err := Read([]byte(`kind: MockScenario
+behaviors:
+  - name: contains
+    promptContains: pods
+  - name: regex
+    promptMatches: "^deploy .+$"
+  - name: fallback
+    matchAny: true
+`))
+	require.NoError(t, err)
+
+	tt := map[string]struct {
+		prompt   string
+		expected string
+	}{
+		"substring match":        {prompt: "list the pods", expected: "contains"},
+		"regex match":            {prompt: "deploy nginx", expected: "regex"},
+		"first match wins":       {prompt: "deploy pods", expected: "contains"},
+		"falls through to match": {prompt: "hello", expected: "fallback"},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			b := scenario.Match(tc.prompt)
+			require.NotNil(t, b)
+			assert.Equal(t, tc.expected, b.Name)
+		})
+	}
+}
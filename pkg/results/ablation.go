@@ -0,0 +1,110 @@
+package results
+
+import (
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// CatalogueVariant aggregates the runs of a task in one variant of a catalogue
+// ablation run.
+type CatalogueVariant struct {
+	Runs     int     `json:"runs"`
+	Passed   int     `json:"passed"`
+	PassRate float64 `json:"passRate"`
+
+	// AvgTokens is the average total token estimate of the runs with token
+	// data, which TasksWithTokens counts
+	AvgTokens       int64 `json:"avgTokens"`
+	TasksWithTokens int   `json:"tasksWithTokens"`
+}
+
+// CatalogueDelta compares the runs of a task whose MCP proxy servers only
+// exposed the tools of its assertions (Required) to its runs with all tools
+// exposed (Full).
+// The deltas are Full minus Required, so a negative PassRateDelta means the
+// agent did worse when it had to pick from the full catalogue.
+type CatalogueDelta struct {
+	TaskID   string           `json:"taskId,omitempty"`
+	TaskName string           `json:"taskName"`
+	Required CatalogueVariant `json:"required"`
+	Full     CatalogueVariant `json:"full"`
+
+	PassRateDelta float64 `json:"passRateDelta"`
+	// TokensDelta is only meaningful if both variants have token data
+	TokensDelta int64 `json:"tokensDelta"`
+}
+
+// CatalogueAblation compares the variants of each task of a catalogue ablation
+// run, in the order the tasks first appear in results. Tasks that were not
+// run in both variants, such as tasks without tool assertions, are left out,
+// and so are runs that don't count towards pass rates.
+func CatalogueAblation(results []*eval.EvalResult) []CatalogueDelta {
+	type variants struct {
+		taskID, taskName string
+		required, full   []*eval.EvalResult
+	}
+
+	var order []string
+	byTask := make(map[string]*variants)
+	for _, r := range results {
+		if !CountsTowardsPassRate(r) {
+			continue
+		}
+
+		key := TaskKey(r)
+		v, ok := byTask[key]
+		if !ok {
+			v = &variants{taskID: r.TaskID, taskName: r.TaskName}
+			byTask[key] = v
+			order = append(order, key)
+		}
+
+		switch r.AllowedToolsMode {
+		case eval.AllowedToolsAssertions:
+			v.required = append(v.required, r)
+		case eval.AllowedToolsAll:
+			v.full = append(v.full, r)
+		}
+	}
+
+	deltas := make([]CatalogueDelta, 0, len(order))
+	for _, key := range order {
+		v := byTask[key]
+		if len(v.required) == 0 || len(v.full) == 0 {
+			continue
+		}
+
+		required := aggregateVariant(v.required)
+		full := aggregateVariant(v.full)
+		deltas = append(deltas, CatalogueDelta{
+			TaskID:        v.taskID,
+			TaskName:      v.taskName,
+			Required:      required,
+			Full:          full,
+			PassRateDelta: full.PassRate - required.PassRate,
+			TokensDelta:   full.AvgTokens - required.AvgTokens,
+		})
+	}
+
+	return deltas
+}
+
+func aggregateVariant(runs []*eval.EvalResult) CatalogueVariant {
+	v := CatalogueVariant{Runs: len(runs)}
+
+	var tokens int64
+	for _, r := range runs {
+		if r.Passed() {
+			v.Passed++
+		}
+		if r.TokenEstimate != nil {
+			tokens += r.TokenEstimate.TotalTokens
+			v.TasksWithTokens++
+		}
+	}
+
+	v.PassRate = float64(v.Passed) / float64(v.Runs)
+	if v.TasksWithTokens > 0 {
+		v.AvgTokens = tokens / int64(v.TasksWithTokens)
+	}
+	return v
+}
@@ -0,0 +1,69 @@
+package results
+
+import (
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/tokens"
+)
+
+func TestCatalogueAblation(t *testing.T) {
+	run := func(name string, mode eval.AllowedToolsMode, passed bool, totalTokens int64) *eval.EvalResult {
+		return &eval.EvalResult{
+			TaskName:            name,
+			TaskPassed:          passed,
+			AllAssertionsPassed: passed,
+			AllowedToolsMode:    mode,
+			TokenEstimate:       &tokens.Estimate{TotalTokens: totalTokens},
+		}
+	}
+
+	evalResults := []*eval.EvalResult{
+		run("list-pods", eval.AllowedToolsAssertions, true, 1000),
+		run("list-pods", eval.AllowedToolsAssertions, true, 2000),
+		run("list-pods", eval.AllowedToolsAll, true, 4000),
+		run("list-pods", eval.AllowedToolsAll, false, 6000),
+		// Only run with the full catalogue, since it has no tool assertions
+		run("no-assertions", eval.AllowedToolsAll, true, 1000),
+		run("create-pod", eval.AllowedToolsAssertions, false, 0),
+		run("create-pod", eval.AllowedToolsAll, true, 0),
+		{TaskName: "create-pod", AllowedToolsMode: eval.AllowedToolsAll, Skipped: true, SkipReason: eval.SkipReasonRequirementsUnmet},
+	}
+	evalResults[5].TokenEstimate = nil
+
+	deltas := CatalogueAblation(evalResults)
+	if len(deltas) != 2 {
+		t.Fatalf("expected 2 deltas, got %d: %+v", len(deltas), deltas)
+	}
+
+	listPods := deltas[0]
+	if listPods.TaskName != "list-pods" {
+		t.Errorf("expected list-pods first, got %s", listPods.TaskName)
+	}
+	if listPods.Required.Passed != 2 || listPods.Required.Runs != 2 || listPods.Full.Passed != 1 || listPods.Full.Runs != 2 {
+		t.Errorf("unexpected pass counts: required %+v, full %+v", listPods.Required, listPods.Full)
+	}
+	if listPods.PassRateDelta != -0.5 {
+		t.Errorf("expected pass rate delta -0.5, got %f", listPods.PassRateDelta)
+	}
+	if listPods.Required.AvgTokens != 1500 || listPods.Full.AvgTokens != 5000 || listPods.TokensDelta != 3500 {
+		t.Errorf("unexpected tokens: required %d, full %d, delta %d", listPods.Required.AvgTokens, listPods.Full.AvgTokens, listPods.TokensDelta)
+	}
+
+	createPod := deltas[1]
+	if createPod.Full.Runs != 1 {
+		t.Errorf("expected the skipped run to be left out, got %d full runs", createPod.Full.Runs)
+	}
+	if createPod.PassRateDelta != 1 {
+		t.Errorf("expected pass rate delta 1, got %f", createPod.PassRateDelta)
+	}
+	if createPod.Required.TasksWithTokens != 0 || createPod.Full.TasksWithTokens != 1 {
+		t.Errorf("unexpected token coverage: required %d, full %d", createPod.Required.TasksWithTokens, createPod.Full.TasksWithTokens)
+	}
+}
+
+func TestCatalogueAblationWithoutVariants(t *testing.T) {
+	if deltas := CatalogueAblation(sampleResults()); len(deltas) != 0 {
+		t.Errorf("expected no deltas for a run without ablation, got %+v", deltas)
+	}
+}
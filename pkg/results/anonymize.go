@@ -0,0 +1,117 @@
+package results
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// AnonymizedPrefix starts the hashes that replace anonymized values.
+const AnonymizedPrefix = "anon:"
+
+// anonymizeKeep lists the keys whose string values are kept when anonymizing:
+// identifiers of tasks, tools and servers, enumerations, models, versions and
+// timestamps. All other strings, such as prompts, outputs, error messages,
+// hostnames and file paths, are hashed.
+var anonymizeKeep = map[string]bool{
+	"taskId": true, "taskName": true, "names": true, "id": true,
+	"name": true, "toolName": true, "tool": true, "server": true, "serverName": true, "allowedTools": true,
+	"type": true, "kind": true, "method": true, "status": true, "level": true, "state": true,
+	"errorKind": true, "skipReason": true, "failureCategory": true, "evaluationMode": true,
+	"difficulty": true, "locale": true, "allowedToolsMode": true, "ci": true,
+	"model": true, "agentModel": true, "judgeModel": true,
+	"version": true, "mcpcheckerVersion": true, "goVersion": true, "platform": true,
+	"commit": true, "taskRepoCommit": true, "lockfileHash": true,
+	"time": true, "timestamp": true, "createdAt": true,
+	"timeout": true, "defaultTask": true, "task": true, "defaultCleanup": true, "cleanup": true, "cleanupTimeout": true,
+}
+
+// anonymizePayload lists the keys of MCP payloads, whose strings are all
+// hashed, except for the content types needed to decode them.
+var anonymizePayload = map[string]bool{
+	"request":     true,
+	"result":      true,
+	"arguments":   true,
+	"inputSchema": true,
+}
+
+// anonymizeDrop lists the keys that are removed when anonymizing.
+var anonymizeDrop = map[string]bool{
+	"rawUpdates": true,
+}
+
+// Anonymize returns a copy of output that can be shared outside of the
+// organization that ran it: prompts, agent and tool output, tool payloads,
+// error messages, hostnames and file paths are replaced by salted hashes,
+// while task and tool names, pass/fail results, token counts, sizes and
+// timings are kept. Equal values get equal hashes, so the same prompt or host
+// can still be recognized across results anonymized with the same salt.
+func Anonymize(output *eval.EvalOutput, salt string) (*eval.EvalOutput, error) {
+	data, err := json.Marshal(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode results: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode results: %w", err)
+	}
+
+	a := &anonymizer{mac: hmac.New(sha256.New, []byte(salt))}
+	doc = a.value(doc, "", false)
+
+	data, err = json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode anonymized results: %w", err)
+	}
+	var anonymized eval.EvalOutput
+	if err := json.Unmarshal(data, &anonymized); err != nil {
+		return nil, fmt.Errorf("failed to decode anonymized results: %w", err)
+	}
+	return &anonymized, nil
+}
+
+type anonymizer struct {
+	mac hash.Hash
+}
+
+// value anonymizes v, the value of key. In payloads, only content types are
+// kept.
+func (a *anonymizer) value(v any, key string, payload bool) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, val := range v {
+			if anonymizeDrop[k] {
+				delete(v, k)
+				continue
+			}
+			v[k] = a.value(val, k, payload || anonymizePayload[k])
+		}
+		return v
+	case []any:
+		for i, val := range v {
+			v[i] = a.value(val, key, payload)
+		}
+		return v
+	case string:
+		if v == "" || key == "type" || (!payload && anonymizeKeep[key]) {
+			return v
+		}
+		return a.hash(v)
+	}
+	return v
+}
+
+func (a *anonymizer) hash(s string) string {
+	a.mac.Reset()
+	_, _ = a.mac.Write([]byte(s))
+	return AnonymizedPrefix + hex.EncodeToString(a.mac.Sum(nil))[:16]
+}
@@ -0,0 +1,126 @@
+package results
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func anonymizeTestOutput() *eval.EvalOutput {
+	return &eval.EvalOutput{
+		Summary: &eval.EvalSummary{
+			Agent: &eval.AgentSummary{Type: "builtin.claude-code", Model: "sonnet", Path: "/home/alice/agents/claude.yaml"},
+			MCPServers: []eval.MCPServerSummary{
+				{Name: "kubernetes", Type: "http", URL: "https://mcp.internal.example.com/mcp"},
+			},
+			Runs:     1,
+			Metadata: map[string]string{"ci": "github-actions", "branch": "alice/secret-feature"},
+		},
+		Results: []*eval.EvalResult{
+			{
+				TaskName:   "create-pod",
+				TaskPath:   "/home/alice/tasks/create-pod/task.yaml",
+				TaskPassed: true,
+				TaskOutput: "Created pod nginx in namespace acme-prod",
+				Difficulty: "easy",
+				CallHistory: &mcpproxy.CallHistory{
+					ToolCalls: []*mcpproxy.ToolCall{
+						{
+							CallRecord: mcpproxy.CallRecord{ServerName: "kubernetes", Success: true, RequestBytes: 42},
+							ToolName:   "pods_create",
+							Request: &mcp.CallToolRequest{
+								Params: &mcp.CallToolParamsRaw{
+									Name:      "pods_create",
+									Arguments: json.RawMessage(`{"namespace":"acme-prod","replicas":3}`),
+								},
+							},
+							Result: &mcp.CallToolResult{
+								Content: []mcp.Content{&mcp.TextContent{Text: "pod acme-prod/nginx created on node-1.acme.internal"}},
+								IsError: true,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestAnonymize(t *testing.T) {
+	anonymized, err := Anonymize(anonymizeTestOutput(), "salt")
+	if err != nil {
+		t.Fatalf("Anonymize() error = %v", err)
+	}
+
+	data, err := json.Marshal(anonymized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sensitive := range []string{"alice", "acme", "mcp.internal.example.com", "nginx"} {
+		if strings.Contains(string(data), sensitive) {
+			t.Errorf("anonymized results contain %q: %s", sensitive, data)
+		}
+	}
+
+	if got := anonymized.Summary.Agent.Model; got != "sonnet" {
+		t.Errorf("agent model = %q, want sonnet", got)
+	}
+	if got := anonymized.Summary.Metadata["ci"]; got != "github-actions" {
+		t.Errorf("ci metadata = %q, want github-actions", got)
+	}
+	if got := anonymized.Summary.MCPServers[0].Name; got != "kubernetes" {
+		t.Errorf("server name = %q, want kubernetes", got)
+	}
+	if got := anonymized.Summary.Runs; got != 1 {
+		t.Errorf("runs = %d, want 1", got)
+	}
+
+	result := anonymized.Results[0]
+	if result.TaskName != "create-pod" || !result.TaskPassed || result.Difficulty != "easy" {
+		t.Errorf("result = %q passed=%v difficulty=%q, want create-pod passed=true difficulty=easy", result.TaskName, result.TaskPassed, result.Difficulty)
+	}
+	if !strings.HasPrefix(result.TaskPath, AnonymizedPrefix) {
+		t.Errorf("task path = %q, want it hashed", result.TaskPath)
+	}
+
+	call := result.CallHistory.ToolCalls[0]
+	if call.ToolName != "pods_create" || call.ServerName != "kubernetes" || call.RequestBytes != 42 {
+		t.Errorf("tool call = %s/%s (%d bytes), want kubernetes/pods_create (42 bytes)", call.ServerName, call.ToolName, call.RequestBytes)
+	}
+	if call.Result == nil || !call.Result.IsError || len(call.Result.Content) != 1 {
+		t.Fatalf("tool call result = %+v, want an error with one content", call.Result)
+	}
+	text, ok := call.Result.Content[0].(*mcp.TextContent)
+	if !ok || !strings.HasPrefix(text.Text, AnonymizedPrefix) {
+		t.Errorf("tool call result content = %#v, want hashed text", call.Result.Content[0])
+	}
+}
+
+func TestAnonymizeHashes(t *testing.T) {
+	first, err := Anonymize(anonymizeTestOutput(), "salt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Anonymize(anonymizeTestOutput(), "salt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := Anonymize(anonymizeTestOutput(), "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Results[0].TaskOutput != second.Results[0].TaskOutput {
+		t.Errorf("hashes differ with the same salt: %q, %q", first.Results[0].TaskOutput, second.Results[0].TaskOutput)
+	}
+	if first.Results[0].TaskOutput == other.Results[0].TaskOutput {
+		t.Errorf("hashes are equal with different salts: %q", first.Results[0].TaskOutput)
+	}
+	if first.Results[0].TaskOutput == first.Results[0].TaskPath {
+		t.Errorf("different values have the same hash %q", first.Results[0].TaskOutput)
+	}
+}
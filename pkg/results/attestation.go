@@ -0,0 +1,428 @@
+package results
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/lockfile"
+)
+
+const (
+	// AttestationSuffix is appended to the path of a results file to get the
+	// path of its attestation
+	AttestationSuffix = ".intoto.json"
+
+	// StatementType is the type of in-toto statements
+	StatementType = "https://in-toto.io/Statement/v1"
+
+	// RunPredicateType is the predicate type of the attestations of runs
+	RunPredicateType = "https://github.com/mcpchecker/mcpchecker/attestation/run/v1"
+
+	// dssePayloadType is the payload type of DSSE envelopes of in-toto
+	// statements
+	dssePayloadType = "application/vnd.in-toto+json"
+)
+
+// Statement is an in-toto statement about a results file, whose digest is its
+// subject. Attestations of runs have a RunPredicate.
+type Statement struct {
+	Type          string               `json:"_type"`
+	Subject       []ResourceDescriptor `json:"subject"`
+	PredicateType string               `json:"predicateType"`
+	Predicate     RunPredicate         `json:"predicate"`
+}
+
+// ResourceDescriptor identifies a file by its name and digests, keyed by
+// algorithm, like in-toto resource descriptors.
+type ResourceDescriptor struct {
+	Name   string            `json:"name,omitempty"`
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// RunPredicate describes the inputs of a run and the outputs it produced, so
+// that a published pass rate can be traced to the configuration that
+// produced it.
+type RunPredicate struct {
+	Eval              string          `json:"eval,omitempty"`
+	MCPCheckerVersion string          `json:"mcpcheckerVersion,omitempty"`
+	Build             *eval.BuildInfo `json:"build,omitempty"`
+	Inputs            RunInputs       `json:"inputs"`
+	Outputs           RunOutputs      `json:"outputs"`
+	CreatedAt         time.Time       `json:"createdAt"`
+}
+
+// RunInputs are the inputs of a run. Names of files are relative to the
+// directory of the eval config.
+type RunInputs struct {
+	EvalConfig ResourceDescriptor  `json:"evalConfig"`
+	Lockfile   *ResourceDescriptor `json:"lockfile,omitempty"`
+
+	// TaskRepo is the git commit of the repository of the eval config
+	TaskRepo *TaskRepo `json:"taskRepo,omitempty"`
+
+	// Tasks are the task files that were run, sorted by name, and
+	// TaskSetDigest the digest of the list of their names and digests
+	Tasks         []ResourceDescriptor `json:"tasks"`
+	TaskSetDigest map[string]string    `json:"taskSetDigest"`
+
+	Agent *ComponentInput `json:"agent,omitempty"`
+	Judge *ComponentInput `json:"judge,omitempty"`
+}
+
+// TaskRepo is the git commit of the repository of the eval config.
+type TaskRepo struct {
+	Commit string `json:"commit"`
+	Dirty  bool   `json:"dirty,omitempty"`
+}
+
+// ComponentInput is the agent or the judge of a run, with its config file if
+// it was configured in one.
+type ComponentInput struct {
+	Type   string              `json:"type,omitempty"`
+	Name   string              `json:"name,omitempty"`
+	Model  string              `json:"model,omitempty"`
+	Config *ResourceDescriptor `json:"config,omitempty"`
+}
+
+// RunOutputs are the results of a run and the pass rates computed from them.
+type RunOutputs struct {
+	Results           ResourceDescriptor `json:"results"`
+	TasksTotal        int                `json:"tasksTotal"`
+	TasksPassed       int                `json:"tasksPassed"`
+	TasksSkipped      int                `json:"tasksSkipped,omitempty"`
+	TaskPassRate      float64            `json:"taskPassRate"`
+	AssertionPassRate float64            `json:"assertionPassRate"`
+	Gates             []eval.GateResult  `json:"gates,omitempty"`
+}
+
+// NewAttestation returns the attestation of the results file at resultsFile,
+// written from output by a run of the eval named evalName, whose config is
+// at configFile.
+func NewAttestation(resultsFile, configFile, evalName string, output *eval.EvalOutput) (*Statement, error) {
+	results, err := fileDescriptor(resultsFile, filepath.Base(resultsFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash results file: %w", err)
+	}
+	inputs, err := attestationInputs(configFile, output)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := CalculateStats(resultsFile, output.Results)
+	predicate := RunPredicate{
+		Eval:   evalName,
+		Inputs: *inputs,
+		Outputs: RunOutputs{
+			Results:           *results,
+			TasksTotal:        stats.TasksTotal,
+			TasksPassed:       stats.TasksPassed,
+			TasksSkipped:      stats.TasksSkipped,
+			TaskPassRate:      stats.TaskPassRate,
+			AssertionPassRate: stats.AssertionPassRate,
+		},
+		CreatedAt: time.Now().UTC(),
+	}
+	if summary := output.Summary; summary != nil {
+		predicate.Build = summary.Build
+		predicate.Outputs.Gates = summary.Gates
+		if summary.Provenance != nil {
+			predicate.MCPCheckerVersion = summary.Provenance.MCPCheckerVersion
+			predicate.CreatedAt = summary.Provenance.CreatedAt
+		}
+	}
+
+	return &Statement{
+		Type:          StatementType,
+		Subject:       []ResourceDescriptor{*results},
+		PredicateType: RunPredicateType,
+		Predicate:     predicate,
+	}, nil
+}
+
+// attestationInputs returns the inputs of the run of the eval config at
+// configFile that wrote output.
+func attestationInputs(configFile string, output *eval.EvalOutput) (*RunInputs, error) {
+	absConfig, err := filepath.Abs(configFile)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(absConfig)
+
+	config, err := fileDescriptor(absConfig, filepath.Base(absConfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash eval config: %w", err)
+	}
+	inputs := &RunInputs{EvalConfig: *config, Tasks: []ResourceDescriptor{}}
+
+	if lock, err := fileDescriptor(filepath.Join(dir, lockfile.FileName), lockfile.FileName); err == nil {
+		inputs.Lockfile = lock
+	}
+
+	seen := make(map[string]bool)
+	for _, r := range output.Results {
+		if r.TaskPath == "" || seen[r.TaskPath] {
+			continue
+		}
+		seen[r.TaskPath] = true
+		task, err := fileDescriptor(r.TaskPath, relativeName(dir, r.TaskPath))
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash task %s: %w", r.TaskPath, err)
+		}
+		inputs.Tasks = append(inputs.Tasks, *task)
+	}
+	slices.SortFunc(inputs.Tasks, func(a, b ResourceDescriptor) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	var taskSet bytes.Buffer
+	for _, task := range inputs.Tasks {
+		fmt.Fprintf(&taskSet, "%s  %s\n", task.Digest["sha256"], task.Name)
+	}
+	inputs.TaskSetDigest = sha256Digest(taskSet.Bytes())
+
+	if summary := output.Summary; summary != nil {
+		if p := summary.Provenance; p != nil && p.TaskRepoCommit != "" {
+			inputs.TaskRepo = &TaskRepo{Commit: p.TaskRepoCommit, Dirty: p.TaskRepoDirty}
+		}
+		if a := summary.Agent; a != nil {
+			inputs.Agent = &ComponentInput{Type: a.Type, Name: a.Name, Model: a.Model, Config: configDescriptor(dir, a.Path)}
+		}
+		if j := summary.Judge; j != nil {
+			inputs.Judge = &ComponentInput{Type: j.Type, Name: j.Name, Model: j.Model, Config: configDescriptor(dir, j.Path)}
+		}
+	}
+
+	return inputs, nil
+}
+
+// CheckInputs hashes the inputs of an attestation again, resolving their
+// names relative to the directory of the eval config at configFile, and
+// returns how they differ from the attested inputs, e.g. "task
+// tasks/a.yaml changed".
+func CheckInputs(attested *RunInputs, configFile string) []string {
+	absConfig, err := filepath.Abs(configFile)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	dir := filepath.Dir(absConfig)
+
+	var diffs []string
+	check := func(what string, d *ResourceDescriptor) {
+		path := filepath.FromSlash(d.Name)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		data, err := os.ReadFile(path)
+		switch {
+		case err != nil:
+			diffs = append(diffs, fmt.Sprintf("%s %s is missing", what, d.Name))
+		case sha256Digest(data)["sha256"] != d.Digest["sha256"]:
+			diffs = append(diffs, fmt.Sprintf("%s %s changed", what, d.Name))
+		}
+	}
+
+	config := attested.EvalConfig
+	config.Name = filepath.Base(absConfig)
+	check("eval config", &config)
+	if attested.Lockfile != nil {
+		check("lockfile", attested.Lockfile)
+	} else if _, err := os.Stat(filepath.Join(dir, lockfile.FileName)); err == nil {
+		diffs = append(diffs, fmt.Sprintf("lockfile %s was added", lockfile.FileName))
+	}
+	for i := range attested.Tasks {
+		check("task", &attested.Tasks[i])
+	}
+	if attested.Agent != nil && attested.Agent.Config != nil {
+		check("agent config", attested.Agent.Config)
+	}
+	if attested.Judge != nil && attested.Judge.Config != nil {
+		check("judge config", attested.Judge.Config)
+	}
+
+	return diffs
+}
+
+// configDescriptor returns the descriptor of the config file at path,
+// relative to dir unless it is absolute, or nil if there is none.
+func configDescriptor(dir, path string) *ResourceDescriptor {
+	if path == "" {
+		return nil
+	}
+	full := path
+	if !filepath.IsAbs(full) {
+		full = filepath.Join(dir, path)
+	}
+	d, err := fileDescriptor(full, relativeName(dir, full))
+	if err != nil {
+		return nil
+	}
+	return d
+}
+
+// relativeName returns path relative to dir, with forward slashes, or its
+// absolute path if it can't be made relative, e.g. on another volume.
+func relativeName(dir, path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil {
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func fileDescriptor(path, name string) (*ResourceDescriptor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &ResourceDescriptor{Name: name, Digest: sha256Digest(data)}, nil
+}
+
+func sha256Digest(data []byte) map[string]string {
+	sum := sha256.Sum256(data)
+	return map[string]string{"sha256": hex.EncodeToString(sum[:])}
+}
+
+// Envelope is a DSSE envelope of a signed in-toto statement.
+type Envelope struct {
+	PayloadType string              `json:"payloadType"`
+	Payload     []byte              `json:"payload"`
+	Signatures  []EnvelopeSignature `json:"signatures"`
+}
+
+// EnvelopeSignature is a signature of a DSSE envelope. KeyID is the SHA-256
+// of the PKIX encoding of the public key, as sha256:<hex>.
+type EnvelopeSignature struct {
+	KeyID string `json:"keyid,omitempty"`
+	Sig   []byte `json:"sig"`
+}
+
+// WriteAttestation writes statement next to the results file at resultsFile,
+// to resultsFile + AttestationSuffix, whose path it returns. With key, the
+// statement is signed and written in a DSSE envelope; otherwise it is
+// written as it is.
+func WriteAttestation(resultsFile string, statement *Statement, key ed25519.PrivateKey) (string, error) {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode attestation: %w", err)
+	}
+
+	out := payload
+	if key != nil {
+		keyID, err := publicKeyID(key.Public().(ed25519.PublicKey))
+		if err != nil {
+			return "", err
+		}
+		out, err = json.Marshal(&Envelope{
+			PayloadType: dssePayloadType,
+			Payload:     payload,
+			Signatures:  []EnvelopeSignature{{KeyID: keyID, Sig: ed25519.Sign(key, pae(dssePayloadType, payload))}},
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to encode attestation: %w", err)
+		}
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, out, "", "  "); err != nil {
+		return "", err
+	}
+	indented.WriteByte('\n')
+
+	path := resultsFile + AttestationSuffix
+	if err := os.WriteFile(path, indented.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("failed to write attestation: %w", err)
+	}
+	return path, nil
+}
+
+// ReadAttestation reads the attestation at path. If it is a DSSE envelope,
+// its signature is verified with key, and it is an error not to give one;
+// signed reports whether it was. Unsigned statements are returned as they
+// are, with signed false.
+func ReadAttestation(path string, key ed25519.PublicKey) (statement *Statement, signed bool, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read attestation: %w", err)
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, false, fmt.Errorf("failed to parse attestation %s: %w", path, err)
+	}
+	if envelope.PayloadType != "" {
+		if envelope.PayloadType != dssePayloadType {
+			return nil, false, fmt.Errorf("unsupported attestation payload type %q", envelope.PayloadType)
+		}
+		if key == nil {
+			return nil, false, fmt.Errorf("attestation %s is signed; a public key is required to verify it", path)
+		}
+		if !verifyEnvelope(&envelope, key) {
+			return nil, false, fmt.Errorf("attestation signature is invalid")
+		}
+		data, signed = envelope.Payload, true
+	}
+
+	statement = &Statement{}
+	if err := json.Unmarshal(data, statement); err != nil {
+		return nil, false, fmt.Errorf("failed to parse attestation %s: %w", path, err)
+	}
+	if statement.Type != StatementType || statement.PredicateType != RunPredicateType {
+		return nil, false, fmt.Errorf("%s is not an attestation of a run (type %q, predicate type %q)", path, statement.Type, statement.PredicateType)
+	}
+	return statement, signed, nil
+}
+
+// VerifySubject checks that the results file at path is the subject of
+// statement.
+func (s *Statement) VerifySubject(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read results file: %w", err)
+	}
+	actual := sha256Digest(data)["sha256"]
+	for _, subject := range s.Subject {
+		if subject.Digest["sha256"] == actual {
+			return nil
+		}
+	}
+	return fmt.Errorf("results file does not match the subject of the attestation: sha256 %s", actual)
+}
+
+func verifyEnvelope(envelope *Envelope, key ed25519.PublicKey) bool {
+	message := pae(envelope.PayloadType, envelope.Payload)
+	for _, sig := range envelope.Signatures {
+		if ed25519.Verify(key, message, sig.Sig) {
+			return true
+		}
+	}
+	return false
+}
+
+// pae returns the DSSE pre-authentication encoding of a payload, which is
+// what is signed.
+func pae(payloadType string, payload []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)
+}
+
+func publicKeyID(key ed25519.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode public key: %w", err)
+	}
+	return digest(der), nil
+}
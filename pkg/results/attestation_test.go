@@ -0,0 +1,263 @@
+package results
+
+import (
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/lockfile"
+)
+
+// attestedRun writes an eval config, its tasks, lockfile and agent config and
+// the results of a run of them to a temp dir, and returns the paths of the
+// eval config and the results file.
+func attestedRun(t *testing.T) (configFile, resultsFile string, output *eval.EvalOutput) {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"eval.yaml":       "kind: Eval\nmetadata:\n  name: kube\n",
+		"tasks/b.yaml":    "kind: Task\nmetadata:\n  name: b\n",
+		"tasks/a.yaml":    "kind: Task\nmetadata:\n  name: a\n",
+		"agent.yaml":      "kind: Agent\n",
+		lockfile.FileName: "version: 1\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output = &eval.EvalOutput{
+		Summary: &eval.EvalSummary{
+			Agent: &eval.AgentSummary{Type: "builtin.llm-agent", Model: "gpt-5", Path: "agent.yaml"},
+			Provenance: &eval.Provenance{
+				MCPCheckerVersion: "v1.2.3",
+				TaskRepoCommit:    "abc123",
+				CreatedAt:         time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		Results: []*eval.EvalResult{
+			{TaskName: "b", TaskPath: filepath.Join(dir, "tasks/b.yaml"), TaskPassed: true, AllAssertionsPassed: true},
+			{TaskName: "a", TaskPath: filepath.Join(dir, "tasks/a.yaml")},
+			{TaskName: "a", TaskPath: filepath.Join(dir, "tasks/a.yaml"), TaskPassed: true, AllAssertionsPassed: true},
+		},
+	}
+	resultsFile = filepath.Join(dir, "results.json")
+	if err := os.WriteFile(resultsFile, []byte(`{"results":[]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "eval.yaml"), resultsFile, output
+}
+
+func TestNewAttestation(t *testing.T) {
+	configFile, resultsFile, output := attestedRun(t)
+
+	statement, err := NewAttestation(resultsFile, configFile, "kube", output)
+	if err != nil {
+		t.Fatalf("NewAttestation() error = %v", err)
+	}
+
+	if statement.Type != StatementType || statement.PredicateType != RunPredicateType {
+		t.Errorf("types = %q, %q", statement.Type, statement.PredicateType)
+	}
+	if len(statement.Subject) != 1 || statement.Subject[0].Name != "results.json" {
+		t.Fatalf("Subject = %+v, want results.json", statement.Subject)
+	}
+
+	p := statement.Predicate
+	if p.Eval != "kube" || p.MCPCheckerVersion != "v1.2.3" || !p.CreatedAt.Equal(output.Summary.Provenance.CreatedAt) {
+		t.Errorf("predicate = %+v", p)
+	}
+	if p.Inputs.EvalConfig.Name != "eval.yaml" {
+		t.Errorf("EvalConfig.Name = %q, want eval.yaml", p.Inputs.EvalConfig.Name)
+	}
+	if p.Inputs.Lockfile == nil {
+		t.Error("expected the lockfile to be attested")
+	}
+	if p.Inputs.TaskRepo == nil || p.Inputs.TaskRepo.Commit != "abc123" {
+		t.Errorf("TaskRepo = %+v, want commit abc123", p.Inputs.TaskRepo)
+	}
+	var names []string
+	for _, task := range p.Inputs.Tasks {
+		names = append(names, task.Name)
+	}
+	if !slices.Equal(names, []string{"tasks/a.yaml", "tasks/b.yaml"}) {
+		t.Errorf("Tasks = %v, want each task once, sorted", names)
+	}
+	if p.Inputs.TaskSetDigest["sha256"] == "" {
+		t.Error("expected a task set digest")
+	}
+	if a := p.Inputs.Agent; a == nil || a.Model != "gpt-5" || a.Config == nil || a.Config.Name != "agent.yaml" {
+		t.Errorf("Agent = %+v, want gpt-5 with agent.yaml", a)
+	}
+	if p.Inputs.Judge != nil {
+		t.Errorf("expected no judge, got %+v", p.Inputs.Judge)
+	}
+	if p.Outputs.TasksTotal != 3 || p.Outputs.TasksPassed != 2 {
+		t.Errorf("Outputs = %+v, want 2/3 tasks passed", p.Outputs)
+	}
+
+	// The task set digest only depends on the tasks
+	other, err := NewAttestation(resultsFile, configFile, "kube", &eval.EvalOutput{Results: output.Results[:2]})
+	if err != nil {
+		t.Fatalf("NewAttestation() error = %v", err)
+	}
+	if other.Predicate.Inputs.TaskSetDigest["sha256"] != p.Inputs.TaskSetDigest["sha256"] {
+		t.Error("expected the same task set digest for the same tasks")
+	}
+}
+
+func TestWriteAndReadAttestation(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		signKey     ed25519.PrivateKey
+		verifyKey   ed25519.PublicKey
+		modify      func(t *testing.T, resultsFile, attestationFile string)
+		wantSigned  bool
+		errContains string
+	}{
+		"unsigned": {},
+		"signed": {
+			signKey:    priv,
+			verifyKey:  pub,
+			wantSigned: true,
+		},
+		"signed without a key": {
+			signKey:     priv,
+			errContains: "a public key is required",
+		},
+		"wrong key": {
+			signKey:     priv,
+			verifyKey:   otherPub,
+			errContains: "attestation signature is invalid",
+		},
+		"modified attestation": {
+			signKey:   priv,
+			verifyKey: pub,
+			modify: func(t *testing.T, _, attestationFile string) {
+				data, err := os.ReadFile(attestationFile)
+				if err != nil {
+					t.Fatal(err)
+				}
+				data = []byte(strings.Replace(string(data), `"payload": "`, `"payload": "e`, 1))
+				if err := os.WriteFile(attestationFile, data, 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			errContains: "attestation",
+		},
+		"modified results": {
+			signKey:   priv,
+			verifyKey: pub,
+			modify: func(t *testing.T, resultsFile, _ string) {
+				if err := os.WriteFile(resultsFile, []byte(`{"results":[{"taskPassed":true}]}`), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			errContains: "results file does not match the subject of the attestation",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			configFile, resultsFile, output := attestedRun(t)
+			statement, err := NewAttestation(resultsFile, configFile, "kube", output)
+			if err != nil {
+				t.Fatalf("NewAttestation() error = %v", err)
+			}
+			path, err := WriteAttestation(resultsFile, statement, tc.signKey)
+			if err != nil {
+				t.Fatalf("WriteAttestation() error = %v", err)
+			}
+			if path != resultsFile+AttestationSuffix {
+				t.Errorf("path = %q, want %q", path, resultsFile+AttestationSuffix)
+			}
+			if tc.modify != nil {
+				tc.modify(t, resultsFile, path)
+			}
+
+			read, signed, err := ReadAttestation(path, tc.verifyKey)
+			if err == nil {
+				err = read.VerifySubject(resultsFile)
+			}
+			if tc.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errContains) {
+					t.Errorf("error = %v, want containing %q", err, tc.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error = %v", err)
+			}
+			if signed != tc.wantSigned {
+				t.Errorf("signed = %v, want %v", signed, tc.wantSigned)
+			}
+			if read.Predicate.Inputs.TaskSetDigest["sha256"] != statement.Predicate.Inputs.TaskSetDigest["sha256"] {
+				t.Error("expected the task set digest to round-trip")
+			}
+		})
+	}
+}
+
+func TestCheckInputs(t *testing.T) {
+	tests := map[string]struct {
+		modify func(t *testing.T, dir string)
+		want   []string
+	}{
+		"unchanged": {},
+		"changed task": {
+			modify: func(t *testing.T, dir string) {
+				if err := os.WriteFile(filepath.Join(dir, "tasks/a.yaml"), []byte("kind: Task\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: []string{"task tasks/a.yaml changed"},
+		},
+		"missing agent config and lockfile": {
+			modify: func(t *testing.T, dir string) {
+				for _, name := range []string{"agent.yaml", lockfile.FileName} {
+					if err := os.Remove(filepath.Join(dir, name)); err != nil {
+						t.Fatal(err)
+					}
+				}
+			},
+			want: []string{"lockfile " + lockfile.FileName + " is missing", "agent config agent.yaml is missing"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			configFile, resultsFile, output := attestedRun(t)
+			statement, err := NewAttestation(resultsFile, configFile, "kube", output)
+			if err != nil {
+				t.Fatalf("NewAttestation() error = %v", err)
+			}
+			if tc.modify != nil {
+				tc.modify(t, filepath.Dir(configFile))
+			}
+
+			got := CheckInputs(&statement.Predicate.Inputs, configFile)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("CheckInputs() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
+)
+
+// JudgeLabels are the verdicts of humans on task runs, the ground truth that
+// judge verdicts are calibrated against.
+type JudgeLabels struct {
+	Labels []JudgeLabel `json:"labels"`
+}
+
+// JudgeLabel is the verdict of a human on the runs of a task.
+type JudgeLabel struct {
+	// Task is the ID or the name of the task
+	Task string `json:"task"`
+	// Run is the 0-indexed run the label is about. A label without a run
+	// applies to every run of the task without a label of its own.
+	Run    *int   `json:"run,omitempty"`
+	Passed bool   `json:"passed"`
+	Note   string `json:"note,omitempty"`
+}
+
+// LoadJudgeLabels reads a YAML or JSON labels file.
+func LoadJudgeLabels(path string) (*JudgeLabels, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read labels file: %w", err)
+	}
+
+	labels := &JudgeLabels{}
+	if err := yaml.Unmarshal(data, labels); err != nil {
+		return nil, fmt.Errorf("failed to parse labels file %s: %w", path, err)
+	}
+	for i, label := range labels.Labels {
+		if label.Task == "" {
+			return nil, fmt.Errorf("invalid label %d in %s: task is required", i, path)
+		}
+		if label.Run != nil && *label.Run < 0 {
+			return nil, fmt.Errorf("invalid label %d in %s: run must not be negative", i, path)
+		}
+	}
+	return labels, nil
+}
+
+// Find returns the label of the task run of r, preferring a label of the run
+// to a label of the task, or nil if it isn't labeled.
+func (l *JudgeLabels) Find(r *eval.EvalResult) *JudgeLabel {
+	var taskLabel *JudgeLabel
+	for i := range l.Labels {
+		label := &l.Labels[i]
+		if label.Task != r.TaskID && label.Task != r.TaskName {
+			continue
+		}
+		if label.Run == nil {
+			taskLabel = label
+		} else if *label.Run == r.RunIndex {
+			return label
+		}
+	}
+	return taskLabel
+}
+
+// JudgeVerdict is the verdict of the LLM judge on a task run.
+type JudgeVerdict struct {
+	Passed bool
+	// FailureCategory is the category of a failing verdict, "n/a" for a
+	// passing one, or "unknown" if the results don't record it
+	FailureCategory string
+}
+
+const unknownFailureCategory = "unknown"
+
+var judgeFailureReason = regexp.MustCompile(`^llm judge failed for reason '([^']*)'`)
+
+// JudgeVerdictOf returns the verdict of the first llmJudge step of the verify
+// phase of r. It returns false if the judge didn't produce a verdict.
+func JudgeVerdictOf(r *eval.EvalResult) (JudgeVerdict, bool) {
+	if r.JudgeError || r.VerifyOutput == nil {
+		return JudgeVerdict{}, false
+	}
+
+	for _, step := range r.VerifyOutput.Steps {
+		if step == nil || step.Type != "llmJudge" {
+			continue
+		}
+		if step.Success {
+			return JudgeVerdict{Passed: true, FailureCategory: "n/a"}, true
+		}
+
+		category := unknownFailureCategory
+		if m := judgeFailureReason.FindStringSubmatch(step.Error); m != nil {
+			category = m[1]
+		} else if verdicts := step.Outputs["verdicts"]; verdicts != "" {
+			category = mostCommonFailureCategory(verdicts)
+		}
+		return JudgeVerdict{FailureCategory: category}, true
+	}
+
+	return JudgeVerdict{}, false
+}
+
+// mostCommonFailureCategory returns the most common category of the failing
+// samples of a sampled judge step, the first one on ties.
+func mostCommonFailureCategory(verdictsJSON string) string {
+	var verdicts []llmjudge.LLMJudgeResult
+	if err := json.Unmarshal([]byte(verdictsJSON), &verdicts); err != nil {
+		return unknownFailureCategory
+	}
+
+	best, bestCount := unknownFailureCategory, 0
+	counts := make(map[string]int)
+	for _, v := range verdicts {
+		if v.Passed {
+			continue
+		}
+		counts[v.FailureCategory]++
+		if counts[v.FailureCategory] > bestCount {
+			best, bestCount = v.FailureCategory, counts[v.FailureCategory]
+		}
+	}
+	return best
+}
+
+// Confusion counts judge verdicts against human labels.
+type Confusion struct {
+	TruePass  int `json:"truePass"`  // both passed the run
+	FalsePass int `json:"falsePass"` // the judge passed a run the human failed
+	FalseFail int `json:"falseFail"` // the judge failed a run the human passed
+	TrueFail  int `json:"trueFail"`  // both failed the run
+}
+
+func (c *Confusion) add(judgePassed, humanPassed bool) {
+	switch {
+	case judgePassed && humanPassed:
+		c.TruePass++
+	case judgePassed:
+		c.FalsePass++
+	case humanPassed:
+		c.FalseFail++
+	default:
+		c.TrueFail++
+	}
+}
+
+// Total returns the number of counted runs.
+func (c Confusion) Total() int {
+	return c.TruePass + c.FalsePass + c.FalseFail + c.TrueFail
+}
+
+// Agreement returns the fraction of runs the judge and the human agree on.
+func (c Confusion) Agreement() float64 {
+	return ratio(c.TruePass+c.TrueFail, c.Total())
+}
+
+// ClassMetrics are the precision and recall of the judge for a verdict.
+type ClassMetrics struct {
+	// Precision is the fraction of the runs the judge gave the verdict that
+	// the human gave it too
+	Precision float64 `json:"precision"`
+	// Recall is the fraction of the runs the human gave the verdict that the
+	// judge gave it too
+	Recall float64 `json:"recall"`
+}
+
+// Disagreement is a labeled run the judge and the human disagree on.
+type Disagreement struct {
+	TaskID          string `json:"taskId,omitempty"`
+	TaskName        string `json:"taskName"`
+	RunIndex        int    `json:"runIndex"`
+	JudgePassed     bool   `json:"judgePassed"`
+	FailureCategory string `json:"failureCategory"`
+	JudgeReason     string `json:"judgeReason,omitempty"`
+	Note            string `json:"note,omitempty"`
+}
+
+// Calibration compares the verdicts of the LLM judge to human labels.
+type Calibration struct {
+	// Labeled is the number of judged runs with a label, Unlabeled the
+	// number without one
+	Labeled   int `json:"labeled"`
+	Unlabeled int `json:"unlabeled"`
+
+	Agreement float64      `json:"agreement"`
+	Confusion Confusion    `json:"confusion"`
+	Pass      ClassMetrics `json:"pass"`
+	Fail      ClassMetrics `json:"fail"`
+
+	// Categories splits Confusion by the failure category of the judge
+	// verdict, "n/a" for passing verdicts
+	Categories map[string]*Confusion `json:"categories,omitempty"`
+
+	Disagreements []Disagreement `json:"disagreements,omitempty"`
+
+	// UnmatchedLabels are the tasks of the labels that match no judged run
+	UnmatchedLabels []string `json:"unmatchedLabels,omitempty"`
+}
+
+// Calibrate compares the judge verdicts of results to labels. Runs without a
+// judge verdict, such as runs that failed before verification or got a judge
+// error, are left out.
+func Calibrate(results []*eval.EvalResult, labels *JudgeLabels) *Calibration {
+	c := &Calibration{Categories: make(map[string]*Confusion)}
+	used := make(map[*JudgeLabel]bool)
+
+	for _, r := range results {
+		verdict, ok := JudgeVerdictOf(r)
+		if !ok {
+			continue
+		}
+		label := labels.Find(r)
+		if label == nil {
+			c.Unlabeled++
+			continue
+		}
+		used[label] = true
+
+		c.Labeled++
+		c.Confusion.add(verdict.Passed, label.Passed)
+		if c.Categories[verdict.FailureCategory] == nil {
+			c.Categories[verdict.FailureCategory] = &Confusion{}
+		}
+		c.Categories[verdict.FailureCategory].add(verdict.Passed, label.Passed)
+
+		if verdict.Passed != label.Passed {
+			c.Disagreements = append(c.Disagreements, Disagreement{
+				TaskID:          r.TaskID,
+				TaskName:        r.TaskName,
+				RunIndex:        r.RunIndex,
+				JudgePassed:     verdict.Passed,
+				FailureCategory: verdict.FailureCategory,
+				JudgeReason:     r.TaskJudgeReason,
+				Note:            label.Note,
+			})
+		}
+	}
+
+	for i := range labels.Labels {
+		if !used[&labels.Labels[i]] {
+			c.UnmatchedLabels = append(c.UnmatchedLabels, labels.Labels[i].Task)
+		}
+	}
+
+	cm := c.Confusion
+	c.Agreement = cm.Agreement()
+	c.Pass = ClassMetrics{
+		Precision: ratio(cm.TruePass, cm.TruePass+cm.FalsePass),
+		Recall:    ratio(cm.TruePass, cm.TruePass+cm.FalseFail),
+	}
+	c.Fail = ClassMetrics{
+		Precision: ratio(cm.TrueFail, cm.TrueFail+cm.FalseFail),
+		Recall:    ratio(cm.TrueFail, cm.TrueFail+cm.FalsePass),
+	}
+
+	return c
+}
+
+// ratio returns n/d, or 0 if d is 0.
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
@@ -0,0 +1,184 @@
+package results
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+func judgedResult(name string, runIndex int, step *steps.StepOutput) *eval.EvalResult {
+	return &eval.EvalResult{
+		TaskName:     name,
+		RunIndex:     runIndex,
+		TaskPassed:   step.Success,
+		VerifyOutput: &task.PhaseOutput{Steps: []*steps.StepOutput{step}},
+	}
+}
+
+func judgePass() *steps.StepOutput {
+	return &steps.StepOutput{Type: "llmJudge", Success: true}
+}
+
+func judgeFail(category string) *steps.StepOutput {
+	return &steps.StepOutput{
+		Type:  "llmJudge",
+		Error: "llm judge failed for reason '" + category + "': some reason",
+	}
+}
+
+func TestJudgeVerdictOf(t *testing.T) {
+	tests := map[string]struct {
+		result   *eval.EvalResult
+		expected JudgeVerdict
+		ok       bool
+	}{
+		"pass": {
+			result:   judgedResult("a", 0, judgePass()),
+			expected: JudgeVerdict{Passed: true, FailureCategory: "n/a"},
+			ok:       true,
+		},
+		"fail": {
+			result:   judgedResult("a", 0, judgeFail("missing_information")),
+			expected: JudgeVerdict{FailureCategory: "missing_information"},
+			ok:       true,
+		},
+		"failed samples": {
+			result: judgedResult("a", 0, &steps.StepOutput{
+				Type:  "llmJudge",
+				Error: "llm judge failed: 1/3 samples passed, 2 required",
+				Outputs: map[string]string{
+					"verdicts": `[{"passed":false,"failureCategory":"semantic_mismatch"},{"passed":true,"failureCategory":"n/a"},{"passed":false,"failureCategory":"semantic_mismatch"}]`,
+				},
+			}),
+			expected: JudgeVerdict{FailureCategory: "semantic_mismatch"},
+			ok:       true,
+		},
+		"unrecognized error": {
+			result:   judgedResult("a", 0, &steps.StepOutput{Type: "llmJudge", Error: "something else"}),
+			expected: JudgeVerdict{FailureCategory: "unknown"},
+			ok:       true,
+		},
+		"judge error": {
+			result: &eval.EvalResult{JudgeError: true},
+		},
+		"no judge step": {
+			result: judgedResult("a", 0, &steps.StepOutput{Type: "script", Success: true}),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			verdict, ok := JudgeVerdictOf(tc.result)
+			if ok != tc.ok {
+				t.Fatalf("JudgeVerdictOf() ok = %v, want %v", ok, tc.ok)
+			}
+			if verdict != tc.expected {
+				t.Errorf("JudgeVerdictOf() = %+v, want %+v", verdict, tc.expected)
+			}
+		})
+	}
+}
+
+func TestCalibrate(t *testing.T) {
+	run1 := 1
+	labels := &JudgeLabels{Labels: []JudgeLabel{
+		{Task: "a", Passed: true},
+		{Task: "b", Run: &run1, Passed: true, Note: "the answer is right"},
+		{Task: "b", Passed: false},
+		{Task: "c", Passed: false, Note: "made up the pod name"},
+		{Task: "removed", Passed: true},
+	}}
+
+	evalResults := []*eval.EvalResult{
+		judgedResult("a", 0, judgePass()),                            // true pass
+		judgedResult("b", 0, judgeFail("missing_information")),       // true fail
+		judgedResult("b", 1, judgeFail("missing_information")),       // false fail
+		judgedResult("c", 0, judgePass()),                            // false pass
+		judgedResult("unlabeled", 0, judgeFail("semantic_mismatch")), // unlabeled
+		{TaskName: "a", JudgeError: true},                            // no verdict
+	}
+
+	c := Calibrate(evalResults, labels)
+
+	if c.Labeled != 4 || c.Unlabeled != 1 {
+		t.Errorf("Labeled = %d, Unlabeled = %d, want 4 and 1", c.Labeled, c.Unlabeled)
+	}
+	expected := Confusion{TruePass: 1, FalsePass: 1, FalseFail: 1, TrueFail: 1}
+	if c.Confusion != expected {
+		t.Errorf("Confusion = %+v, want %+v", c.Confusion, expected)
+	}
+	if c.Agreement != 0.5 {
+		t.Errorf("Agreement = %v, want 0.5", c.Agreement)
+	}
+	if c.Pass.Precision != 0.5 || c.Pass.Recall != 0.5 || c.Fail.Precision != 0.5 || c.Fail.Recall != 0.5 {
+		t.Errorf("Pass = %+v, Fail = %+v, want 0.5 everywhere", c.Pass, c.Fail)
+	}
+
+	if got := c.Categories["missing_information"]; got == nil || *got != (Confusion{FalseFail: 1, TrueFail: 1}) {
+		t.Errorf("missing_information = %+v", got)
+	}
+	if got := c.Categories["n/a"]; got == nil || *got != (Confusion{TruePass: 1, FalsePass: 1}) {
+		t.Errorf("n/a = %+v", got)
+	}
+
+	if len(c.Disagreements) != 2 {
+		t.Fatalf("got %d disagreements, want 2", len(c.Disagreements))
+	}
+	if d := c.Disagreements[0]; d.TaskName != "b" || d.RunIndex != 1 || d.JudgePassed || d.Note != "the answer is right" {
+		t.Errorf("Disagreements[0] = %+v", d)
+	}
+	if d := c.Disagreements[1]; d.TaskName != "c" || !d.JudgePassed {
+		t.Errorf("Disagreements[1] = %+v", d)
+	}
+
+	if len(c.UnmatchedLabels) != 1 || c.UnmatchedLabels[0] != "removed" {
+		t.Errorf("UnmatchedLabels = %v, want [removed]", c.UnmatchedLabels)
+	}
+}
+
+func TestLoadJudgeLabels(t *testing.T) {
+	tests := map[string]struct {
+		content   string
+		expectErr string
+	}{
+		"valid": {
+			content: "labels:\n  - task: a\n    passed: true\n  - task: b\n    run: 2\n    passed: false\n",
+		},
+		"missing task": {
+			content:   "labels:\n  - passed: true\n",
+			expectErr: "task is required",
+		},
+		"negative run": {
+			content:   "labels:\n  - task: a\n    run: -1\n    passed: true\n",
+			expectErr: "run must not be negative",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "labels.yaml")
+			if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			labels, err := LoadJudgeLabels(path)
+			if tc.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErr) {
+					t.Fatalf("LoadJudgeLabels() error = %v, want %q", err, tc.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadJudgeLabels() error = %v", err)
+			}
+			if len(labels.Labels) != 2 || labels.Labels[1].Run == nil || *labels.Labels[1].Run != 2 {
+				t.Errorf("Labels = %+v", labels.Labels)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package results
+
+import (
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// CanaryVersions splits the results of a canary run into the runs against
+// the baseline and against the candidate server, so that they can be
+// compared like two results files. The runs are copies without their
+// environment, so that the runs of a task in both versions have the same
+// TaskKey. Runs in other environments are left out.
+func CanaryVersions(canary *eval.CanaryConfig, results []*eval.EvalResult) (baseline, candidate []*eval.EvalResult) {
+	for _, r := range results {
+		var version *[]*eval.EvalResult
+		switch r.Environment {
+		case canary.Baseline:
+			version = &baseline
+		case canary.Candidate:
+			version = &candidate
+		default:
+			continue
+		}
+
+		run := *r
+		run.Environment = ""
+		*version = append(*version, &run)
+	}
+	return baseline, candidate
+}
@@ -0,0 +1,37 @@
+package results
+
+import (
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+func TestCanaryVersions(t *testing.T) {
+	canary := &eval.CanaryConfig{Server: "kubernetes", Baseline: "kubernetes-v1", Candidate: "kubernetes-v2"}
+	evalResults := []*eval.EvalResult{
+		{TaskID: "list-pods", TaskPassed: true, Environment: "kubernetes-v1"},
+		{TaskID: "create-pod", TaskPassed: true, Environment: "kubernetes-v1"},
+		{TaskID: "list-pods", TaskPassed: true, Environment: "kubernetes-v2"},
+		{TaskID: "create-pod", Environment: "kubernetes-v2"},
+		{TaskID: "list-pods", Environment: "other"},
+	}
+
+	baseline, candidate := CanaryVersions(canary, evalResults)
+	if len(baseline) != 2 || len(candidate) != 2 {
+		t.Fatalf("expected 2 baseline and 2 candidate runs, got %d and %d", len(baseline), len(candidate))
+	}
+	for i, want := range []string{"list-pods", "create-pod"} {
+		if key := TaskKey(baseline[i]); key != want {
+			t.Errorf("baseline[%d] key = %q, want %q", i, key, want)
+		}
+		if key := TaskKey(candidate[i]); key != want {
+			t.Errorf("candidate[%d] key = %q, want %q", i, key, want)
+		}
+	}
+	if candidate[1].TaskPassed {
+		t.Error("expected create-pod to fail with the candidate")
+	}
+	if evalResults[0].Environment != "kubernetes-v1" {
+		t.Error("expected the results to be left unchanged")
+	}
+}
@@ -0,0 +1,163 @@
+package results
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// MetricsSnapshot is a flat document of gauges computed from a results file,
+// printed by 'mcpchecker metrics' for pull-based dashboards, e.g. with
+// Grafana's JSON datasources or telegraf's json_v2 parser.
+type MetricsSnapshot struct {
+	// Timestamp is when the results were created, if they record it
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Metrics   []Metric   `json:"metrics"`
+}
+
+// Metric is a gauge with its labels. Every metric carries all of its labels,
+// including those shared by the snapshot, so it can be read on its own.
+type Metric struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels"`
+	Value  float64           `json:"value"`
+}
+
+// Metrics computes the gauges of the eval as a whole (mcpchecker_eval_*),
+// and of each task (mcpchecker_task_*) labeled with the task and its
+// difficulty. All metrics are labeled with the agent and model of the run,
+// and with labels, which take precedence.
+func Metrics(output *eval.EvalOutput, labels map[string]string) MetricsSnapshot {
+	var snapshot MetricsSnapshot
+	if output.Summary != nil && output.Summary.Provenance != nil && !output.Summary.Provenance.CreatedAt.IsZero() {
+		createdAt := output.Summary.Provenance.CreatedAt
+		snapshot.Timestamp = &createdAt
+	}
+
+	common := make(map[string]string)
+	if agent, model := agentOf(output.Summary); agent != "" {
+		common["agent"] = agent
+		common["model"] = model
+	}
+	maps.Copy(common, labels)
+
+	add := func(name string, value float64, extra ...string) {
+		metricLabels := maps.Clone(common)
+		for i := 0; i+1 < len(extra); i += 2 {
+			metricLabels[extra[i]] = extra[i+1]
+		}
+		snapshot.Metrics = append(snapshot.Metrics, Metric{Name: name, Labels: metricLabels, Value: value})
+	}
+
+	var total metricTotals
+	tasks := make(map[string]*metricTotals)
+	var taskKeys []string
+	for _, r := range output.Results {
+		key := TaskKey(r)
+		task, ok := tasks[key]
+		if !ok {
+			task = &metricTotals{name: r.TaskName, difficulty: r.Difficulty}
+			tasks[key] = task
+			taskKeys = append(taskKeys, key)
+		}
+		row := NewRow(output.Summary, r)
+		total.add(r, row)
+		task.add(r, row)
+	}
+
+	add("mcpchecker_eval_runs", float64(total.runs))
+	add("mcpchecker_eval_runs_passed", float64(total.passed))
+	add("mcpchecker_eval_runs_skipped", float64(total.runs-total.counted))
+	if total.counted > 0 {
+		add("mcpchecker_eval_pass_rate", total.passRate())
+	}
+	for _, kind := range slices.Sorted(maps.Keys(total.errorKinds)) {
+		add("mcpchecker_eval_runs_failed", float64(total.errorKinds[kind]), "kind", kind)
+	}
+	add("mcpchecker_eval_assertions", float64(total.assertions))
+	add("mcpchecker_eval_assertions_passed", float64(total.assertionsPassed))
+	if total.assertions > 0 {
+		add("mcpchecker_eval_assertion_pass_rate", float64(total.assertionsPassed)/float64(total.assertions))
+	}
+	add("mcpchecker_eval_tokens", float64(total.inputTokens), "type", "input")
+	add("mcpchecker_eval_tokens", float64(total.outputTokens), "type", "output")
+	add("mcpchecker_eval_judge_tokens", float64(total.judgeInputTokens), "type", "input")
+	add("mcpchecker_eval_judge_tokens", float64(total.judgeOutputTokens), "type", "output")
+	add("mcpchecker_eval_tool_calls", float64(total.toolCalls))
+	add("mcpchecker_eval_tool_calls_failed", float64(total.failedToolCalls))
+	add("mcpchecker_eval_duration_seconds", float64(total.durationMs)/1000)
+
+	for _, key := range taskKeys {
+		task := tasks[key]
+		taskLabels := []string{"task", task.name}
+		if task.difficulty != "" {
+			taskLabels = append(taskLabels, "difficulty", task.difficulty)
+		}
+		add("mcpchecker_task_runs", float64(task.runs), taskLabels...)
+		add("mcpchecker_task_runs_passed", float64(task.passed), taskLabels...)
+		if task.counted > 0 {
+			add("mcpchecker_task_pass_rate", task.passRate(), taskLabels...)
+		}
+		add("mcpchecker_task_tokens", float64(task.inputTokens+task.outputTokens), taskLabels...)
+		add("mcpchecker_task_tool_calls", float64(task.toolCalls), taskLabels...)
+		if task.timedRuns > 0 {
+			add("mcpchecker_task_duration_seconds", float64(task.durationMs)/1000/float64(task.timedRuns), taskLabels...)
+		}
+	}
+
+	return snapshot
+}
+
+// metricTotals sums the task runs of the eval or of a task.
+type metricTotals struct {
+	name, difficulty string
+
+	runs, counted, passed int
+	errorKinds            map[string]int
+
+	assertions, assertionsPassed        int64
+	inputTokens, outputTokens           int64
+	judgeInputTokens, judgeOutputTokens int64
+	toolCalls, failedToolCalls          int64
+
+	durationMs int64
+	timedRuns  int
+}
+
+func (t *metricTotals) add(r *eval.EvalResult, row Row) {
+	t.runs++
+	if CountsTowardsPassRate(r) {
+		t.counted++
+	}
+	if r.TaskPassed {
+		t.passed++
+	}
+	if row.ErrorKind != "" {
+		if t.errorKinds == nil {
+			t.errorKinds = make(map[string]int)
+		}
+		t.errorKinds[row.ErrorKind]++
+	}
+
+	t.assertions += row.AssertionsTotal
+	t.assertionsPassed += row.AssertionsPassed
+	t.inputTokens += row.InputTokens
+	t.outputTokens += row.OutputTokens
+	t.judgeInputTokens += row.JudgeInputTokens
+	t.judgeOutputTokens += row.JudgeOutputTokens
+	t.toolCalls += row.ToolCalls
+	t.failedToolCalls += row.FailedToolCalls
+
+	// Results written before durations were recorded don't count towards the
+	// mean duration
+	if row.DurationMs > 0 {
+		t.durationMs += row.DurationMs
+		t.timedRuns++
+	}
+}
+
+func (t *metricTotals) passRate() float64 {
+	return float64(t.passed) / float64(t.counted)
+}
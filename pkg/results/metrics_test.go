@@ -0,0 +1,93 @@
+package results
+
+import (
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+func TestMetrics(t *testing.T) {
+	output := tableOutput()
+	createdAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	output.Summary.Provenance = &eval.Provenance{CreatedAt: createdAt}
+	output.Results = append(output.Results, &eval.EvalResult{
+		TaskID:     "pods-create",
+		TaskName:   "create pod",
+		Difficulty: "easy",
+		TaskError:  "agent crashed",
+		ErrorKind:  "agent",
+		DurationMs: 500,
+	})
+
+	snapshot := Metrics(output, map[string]string{"env": "nightly", "model": "gpt-5"})
+	if snapshot.Timestamp == nil || !snapshot.Timestamp.Equal(createdAt) {
+		t.Errorf("Timestamp = %v, want %v", snapshot.Timestamp, createdAt)
+	}
+
+	common := map[string]string{"agent": "builtin.llm-agent", "model": "gpt-5", "env": "nightly"}
+	with := func(extra ...string) map[string]string {
+		labels := maps.Clone(common)
+		for i := 0; i < len(extra); i += 2 {
+			labels[extra[i]] = extra[i+1]
+		}
+		return labels
+	}
+	createPod := []string{"task", "create pod", "difficulty", "easy"}
+	listPods := []string{"task", "list pods", "difficulty", "hard"}
+
+	tests := map[string]struct {
+		name   string
+		labels map[string]string
+		value  float64
+	}{
+		"runs":                    {"mcpchecker_eval_runs", common, 3},
+		"passed runs":             {"mcpchecker_eval_runs_passed", common, 1},
+		"skipped runs":            {"mcpchecker_eval_runs_skipped", common, 0},
+		"pass rate":               {"mcpchecker_eval_pass_rate", common, 1.0 / 3},
+		"failed agent runs":       {"mcpchecker_eval_runs_failed", with("kind", "agent"), 1},
+		"assertion pass rate":     {"mcpchecker_eval_assertion_pass_rate", common, 0.5},
+		"input tokens":            {"mcpchecker_eval_tokens", with("type", "input"), 87},
+		"judge output tokens":     {"mcpchecker_eval_judge_tokens", with("type", "output"), 5},
+		"failed tool calls":       {"mcpchecker_eval_tool_calls_failed", common, 1},
+		"duration":                {"mcpchecker_eval_duration_seconds", common, 2},
+		"task runs":               {"mcpchecker_task_runs", with(createPod...), 2},
+		"task pass rate":          {"mcpchecker_task_pass_rate", with(createPod...), 0.5},
+		"task mean duration":      {"mcpchecker_task_duration_seconds", with(createPod...), 1},
+		"over budget pass rate":   {"mcpchecker_task_pass_rate", with(listPods...), 0},
+		"over budget task tokens": {"mcpchecker_task_tokens", with(listPods...), 10},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, m := range snapshot.Metrics {
+				if m.Name == tc.name && maps.Equal(m.Labels, tc.labels) {
+					if diff := m.Value - tc.value; diff > 1e-9 || diff < -1e-9 {
+						t.Errorf("%s%v = %v, want %v", tc.name, tc.labels, m.Value, tc.value)
+					}
+					return
+				}
+			}
+			t.Errorf("missing metric %s%v", tc.name, tc.labels)
+		})
+	}
+
+	for _, m := range snapshot.Metrics {
+		if m.Name == "mcpchecker_task_duration_seconds" && m.Labels["task"] == "list pods" {
+			t.Errorf("expected no mean duration for a task without timed runs, got %v", m.Value)
+		}
+	}
+}
+
+func TestMetricsWithoutSummary(t *testing.T) {
+	snapshot := Metrics(&eval.EvalOutput{Results: []*eval.EvalResult{{TaskName: "a", TaskPassed: true}}}, nil)
+	if snapshot.Timestamp != nil {
+		t.Errorf("expected no timestamp, got %v", snapshot.Timestamp)
+	}
+	for _, m := range snapshot.Metrics {
+		if _, ok := m.Labels["agent"]; ok {
+			t.Errorf("expected no agent label without a summary, got %v", m.Labels)
+		}
+	}
+}
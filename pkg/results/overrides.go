@@ -0,0 +1,148 @@
+package results
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+// OverridesLog records the verdicts of human reviewers on task runs that the
+// LLM judge failed, as written by 'mcpchecker review'.
+type OverridesLog struct {
+	Overrides []Override `json:"overrides"`
+}
+
+// Override is the verdict of a reviewer on a task run. The run is identified
+// by its task, run index and a hash of the agent output, so an override only
+// applies to that run in other results files, such as a baseline.
+type Override struct {
+	TaskID           string                `json:"taskId,omitempty"`
+	TaskName         string                `json:"taskName"`
+	RunIndex         int                   `json:"runIndex"`
+	AllowedToolsMode eval.AllowedToolsMode `json:"allowedToolsMode,omitempty"`
+	OutputSHA256     string                `json:"outputSha256"`
+
+	// JudgeReason is the reason the judge gave for its verdict
+	JudgeReason string `json:"judgeReason,omitempty"`
+
+	eval.JudgeOverride
+}
+
+// NewOverride returns the override of the judge verdict on r by a reviewer.
+func NewOverride(r *eval.EvalResult, verdict eval.JudgeOverride) Override {
+	return Override{
+		TaskID:           r.TaskID,
+		TaskName:         r.TaskName,
+		RunIndex:         r.RunIndex,
+		AllowedToolsMode: r.AllowedToolsMode,
+		OutputSHA256:     outputSHA256(r),
+		JudgeReason:      r.TaskJudgeReason,
+		JudgeOverride:    verdict,
+	}
+}
+
+// Matches reports whether o is about the task run of r.
+func (o *Override) Matches(r *eval.EvalResult) bool {
+	return o.TaskID == r.TaskID &&
+		o.TaskName == r.TaskName &&
+		o.RunIndex == r.RunIndex &&
+		o.AllowedToolsMode == r.AllowedToolsMode &&
+		o.OutputSHA256 == outputSHA256(r)
+}
+
+func outputSHA256(r *eval.EvalResult) string {
+	sum := sha256.Sum256([]byte(r.TaskOutput))
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadOverrides reads an overrides log.
+func LoadOverrides(path string) (*OverridesLog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overrides log: %w", err)
+	}
+
+	log := &OverridesLog{}
+	if err := json.Unmarshal(data, log); err != nil {
+		return nil, fmt.Errorf("failed to parse overrides log %s: %w", path, err)
+	}
+	return log, nil
+}
+
+// Save writes the log to path.
+func (l *OverridesLog) Save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode overrides log: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write overrides log: %w", err)
+	}
+	return nil
+}
+
+// Find returns the latest override of the task run of r, or nil if it hasn't
+// been reviewed.
+func (l *OverridesLog) Find(r *eval.EvalResult) *Override {
+	for i := len(l.Overrides) - 1; i >= 0; i-- {
+		if l.Overrides[i].Matches(r) {
+			return &l.Overrides[i]
+		}
+	}
+	return nil
+}
+
+// Apply records the overrides of the log on the results they match, and
+// returns how many results were changed. Results overridden to pass count as
+// passed from then on.
+func (l *OverridesLog) Apply(results []*eval.EvalResult) int {
+	applied := 0
+	for _, r := range results {
+		if r.JudgeOverride != nil || !JudgeFailed(r) {
+			continue
+		}
+		if o := l.Find(r); o != nil {
+			ApplyOverride(r, o.JudgeOverride)
+			applied++
+		}
+	}
+	return applied
+}
+
+// ApplyOverride records the verdict of a reviewer on r. If the reviewer
+// passed the run, the failure of the judge no longer fails the task.
+func ApplyOverride(r *eval.EvalResult, verdict eval.JudgeOverride) {
+	r.JudgeOverride = &verdict
+	if verdict.Passed {
+		r.TaskPassed = true
+		r.TaskError = ""
+		r.ErrorKind = ""
+	}
+}
+
+// JudgeFailed reports whether a task run failed only because of the verdict
+// of the LLM judge: at least one llmJudge step of the verify phase failed,
+// and every other step of the phase passed. Runs the judge couldn't judge
+// (judge errors) are not judge-failed.
+func JudgeFailed(r *eval.EvalResult) bool {
+	if r.TaskPassed || r.JudgeError || r.VerifyOutput == nil || ErrorKind(r) != task.ErrorKindVerify {
+		return false
+	}
+
+	judgeFailed := false
+	for _, step := range r.VerifyOutput.Steps {
+		if step == nil || step.Success {
+			continue
+		}
+		if step.Type != "llmJudge" {
+			return false
+		}
+		judgeFailed = true
+	}
+	return judgeFailed
+}
@@ -0,0 +1,112 @@
+package results
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+)
+
+func judgeFailedResult(name, output string) *eval.EvalResult {
+	return &eval.EvalResult{
+		TaskName:   name,
+		TaskOutput: output,
+		TaskError:  "one or more verification steps failed",
+		ErrorKind:  task.ErrorKindVerify,
+		VerifyOutput: &task.PhaseOutput{Steps: []*steps.StepOutput{
+			{Type: "script", Success: true},
+			{Type: "llmJudge", Success: false, Error: "llm judge failed"},
+		}},
+	}
+}
+
+func TestJudgeFailed(t *testing.T) {
+	tests := map[string]struct {
+		result   *eval.EvalResult
+		expected bool
+	}{
+		"judge failed": {
+			result:   judgeFailedResult("a", "out"),
+			expected: true,
+		},
+		"passed": {
+			result: &eval.EvalResult{TaskPassed: true},
+		},
+		"judge error": {
+			result: &eval.EvalResult{JudgeError: true, ErrorKind: task.ErrorKindInfra},
+		},
+		"another step failed too": {
+			result: &eval.EvalResult{
+				ErrorKind: task.ErrorKindVerify,
+				VerifyOutput: &task.PhaseOutput{Steps: []*steps.StepOutput{
+					{Type: "script", Success: false},
+					{Type: "llmJudge", Success: false},
+				}},
+			},
+		},
+		"agent failed": {
+			result: &eval.EvalResult{ErrorKind: task.ErrorKindAgent},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := JudgeFailed(tc.result); got != tc.expected {
+				t.Errorf("JudgeFailed() = %v, want %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestOverridesLogApply(t *testing.T) {
+	reviewed := judgeFailedResult("a", "the pod is running")
+	verdict := eval.JudgeOverride{Passed: true, Note: "judge missed the pod status", Time: time.Now()}
+
+	path := filepath.Join(t.TempDir(), "overrides.json")
+	log := &OverridesLog{Overrides: []Override{NewOverride(reviewed, verdict)}}
+	if err := log.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := LoadOverrides(path)
+	if err != nil {
+		t.Fatalf("LoadOverrides() error = %v", err)
+	}
+
+	// The same run in another results file, a run with a different output and
+	// a run of another task
+	same := judgeFailedResult("a", "the pod is running")
+	changed := judgeFailedResult("a", "the pod is pending")
+	other := judgeFailedResult("b", "the pod is running")
+
+	if applied := loaded.Apply([]*eval.EvalResult{same, changed, other}); applied != 1 {
+		t.Fatalf("Apply() = %d, want 1", applied)
+	}
+
+	if !same.TaskPassed || same.TaskError != "" || same.ErrorKind != "" {
+		t.Errorf("overridden result should pass, got passed=%v error=%q kind=%q", same.TaskPassed, same.TaskError, same.ErrorKind)
+	}
+	if same.JudgeOverride == nil || same.JudgeOverride.Note != verdict.Note {
+		t.Errorf("JudgeOverride = %+v, want note %q", same.JudgeOverride, verdict.Note)
+	}
+	if changed.TaskPassed || changed.JudgeOverride != nil {
+		t.Errorf("override should not apply to a run with a different output")
+	}
+	if other.TaskPassed || other.JudgeOverride != nil {
+		t.Errorf("override should not apply to another task")
+	}
+}
+
+func TestApplyOverrideAccepted(t *testing.T) {
+	r := judgeFailedResult("a", "out")
+	ApplyOverride(r, eval.JudgeOverride{Passed: false, Note: "agreed"})
+
+	if r.TaskPassed || r.ErrorKind != task.ErrorKindVerify {
+		t.Errorf("accepted verdict should keep the failure, got passed=%v kind=%q", r.TaskPassed, r.ErrorKind)
+	}
+	if r.JudgeOverride == nil || r.JudgeOverride.Passed {
+		t.Errorf("JudgeOverride = %+v, want an accepted verdict", r.JudgeOverride)
+	}
+}
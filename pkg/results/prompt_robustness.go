@@ -0,0 +1,115 @@
+package results
+
+import (
+	"math"
+	"sort"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// PromptVariantRuns aggregates the runs of a task with one variant of its
+// prompt in a prompt robustness run.
+type PromptVariantRuns struct {
+	// Variant is 0 for the original prompt, or the number of the paraphrase
+	Variant    int     `json:"variant"`
+	Paraphrase string  `json:"paraphrase,omitempty"`
+	Runs       int     `json:"runs"`
+	Passed     int     `json:"passed"`
+	PassRate   float64 `json:"passRate"`
+}
+
+// PromptSensitivity is the variance of the outcomes of a task across the
+// variants of its prompt. A task whose pass rate doesn't depend on the
+// wording of its prompt has a StdDev and a Spread of 0.
+type PromptSensitivity struct {
+	TaskID   string `json:"taskId,omitempty"`
+	TaskName string `json:"taskName"`
+	// AllowedToolsMode is the catalogue ablation variant of the runs, if any
+	AllowedToolsMode eval.AllowedToolsMode `json:"allowedToolsMode,omitempty"`
+
+	Variants []PromptVariantRuns `json:"variants"`
+
+	// MeanPassRate, StdDev and Spread are the mean, the standard deviation
+	// and the difference between the highest and the lowest of the pass
+	// rates of the variants
+	MeanPassRate float64 `json:"meanPassRate"`
+	StdDev       float64 `json:"stdDev"`
+	Spread       float64 `json:"spread"`
+}
+
+// PromptRobustness compares the prompt variants of each task of a prompt
+// robustness run, in the order the tasks first appear in results. Tasks that
+// were only run with one prompt, such as tasks whose prompt couldn't be
+// paraphrased, are left out, and so are runs that don't count towards pass
+// rates.
+func PromptRobustness(results []*eval.EvalResult) []PromptSensitivity {
+	type task struct {
+		sensitivity PromptSensitivity
+		variants    map[int]*PromptVariantRuns
+	}
+
+	var order []string
+	byTask := make(map[string]*task)
+	for _, r := range results {
+		if !CountsTowardsPassRate(r) {
+			continue
+		}
+
+		// Catalogue ablation variants are compared apart, since their pass
+		// rates differ for reasons other than the prompt
+		key := TaskKey(r) + "\x00" + string(r.AllowedToolsMode)
+		t, ok := byTask[key]
+		if !ok {
+			t = &task{
+				sensitivity: PromptSensitivity{TaskID: r.TaskID, TaskName: r.TaskName, AllowedToolsMode: r.AllowedToolsMode},
+				variants:    make(map[int]*PromptVariantRuns),
+			}
+			byTask[key] = t
+			order = append(order, key)
+		}
+
+		v, ok := t.variants[r.PromptVariant]
+		if !ok {
+			v = &PromptVariantRuns{Variant: r.PromptVariant, Paraphrase: r.PromptParaphrase}
+			t.variants[r.PromptVariant] = v
+		}
+		v.Runs++
+		if r.Passed() {
+			v.Passed++
+		}
+	}
+
+	sensitivities := make([]PromptSensitivity, 0, len(order))
+	for _, key := range order {
+		t := byTask[key]
+		if len(t.variants) < 2 {
+			continue
+		}
+
+		s := t.sensitivity
+		for _, v := range t.variants {
+			v.PassRate = float64(v.Passed) / float64(v.Runs)
+			s.Variants = append(s.Variants, *v)
+		}
+		sort.Slice(s.Variants, func(i, j int) bool { return s.Variants[i].Variant < s.Variants[j].Variant })
+
+		minRate, maxRate := 1.0, 0.0
+		for _, v := range s.Variants {
+			s.MeanPassRate += v.PassRate
+			minRate = math.Min(minRate, v.PassRate)
+			maxRate = math.Max(maxRate, v.PassRate)
+		}
+		s.MeanPassRate /= float64(len(s.Variants))
+
+		var variance float64
+		for _, v := range s.Variants {
+			variance += (v.PassRate - s.MeanPassRate) * (v.PassRate - s.MeanPassRate)
+		}
+		s.StdDev = math.Sqrt(variance / float64(len(s.Variants)))
+		s.Spread = maxRate - minRate
+
+		sensitivities = append(sensitivities, s)
+	}
+
+	return sensitivities
+}
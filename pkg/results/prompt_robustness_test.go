@@ -0,0 +1,95 @@
+package results
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+func TestPromptRobustness(t *testing.T) {
+	run := func(name string, variant int, passed bool) *eval.EvalResult {
+		r := &eval.EvalResult{
+			TaskName:            name,
+			TaskPassed:          passed,
+			AllAssertionsPassed: passed,
+			PromptVariant:       variant,
+		}
+		if variant > 0 {
+			r.PromptParaphrase = "paraphrase of " + name
+		}
+		return r
+	}
+
+	evalResults := []*eval.EvalResult{
+		run("list-pods", 0, true),
+		run("list-pods", 0, true),
+		run("list-pods", 1, true),
+		run("list-pods", 1, false),
+		run("list-pods", 2, false),
+		run("list-pods", 2, false),
+		// Only run with its original prompt, since it couldn't be paraphrased
+		run("create-pod", 0, true),
+		run("stable", 1, true),
+		run("stable", 0, true),
+		{TaskName: "stable", PromptVariant: 2, Skipped: true, SkipReason: eval.SkipReasonRequirementsUnmet},
+	}
+
+	sensitivities := PromptRobustness(evalResults)
+	if len(sensitivities) != 2 {
+		t.Fatalf("expected 2 tasks, got %d: %+v", len(sensitivities), sensitivities)
+	}
+
+	listPods := sensitivities[0]
+	if listPods.TaskName != "list-pods" || len(listPods.Variants) != 3 {
+		t.Fatalf("unexpected first task: %+v", listPods)
+	}
+	for i, want := range []float64{1, 0.5, 0} {
+		if v := listPods.Variants[i]; v.Variant != i || v.Runs != 2 || v.PassRate != want {
+			t.Errorf("variant %d = %+v, want pass rate %v", i, v, want)
+		}
+	}
+	if listPods.Variants[0].Paraphrase != "" || listPods.Variants[1].Paraphrase != "paraphrase of list-pods" {
+		t.Errorf("unexpected paraphrases: %+v", listPods.Variants)
+	}
+	if listPods.MeanPassRate != 0.5 || listPods.Spread != 1 {
+		t.Errorf("mean = %v, spread = %v, want 0.5 and 1", listPods.MeanPassRate, listPods.Spread)
+	}
+	if want := math.Sqrt(1.0 / 6); math.Abs(listPods.StdDev-want) > 1e-9 {
+		t.Errorf("std dev = %v, want %v", listPods.StdDev, want)
+	}
+
+	stable := sensitivities[1]
+	if len(stable.Variants) != 2 || stable.Variants[0].Variant != 0 {
+		t.Errorf("expected the skipped variant to be left out and variants sorted, got %+v", stable.Variants)
+	}
+	if stable.StdDev != 0 || stable.Spread != 0 || stable.MeanPassRate != 1 {
+		t.Errorf("unexpected stable task: %+v", stable)
+	}
+}
+
+func TestPromptRobustnessSplitsCatalogueVariants(t *testing.T) {
+	evalResults := []*eval.EvalResult{
+		{TaskName: "list-pods", AllowedToolsMode: eval.AllowedToolsAssertions, TaskPassed: true, AllAssertionsPassed: true},
+		{TaskName: "list-pods", AllowedToolsMode: eval.AllowedToolsAssertions, PromptVariant: 1},
+		{TaskName: "list-pods", AllowedToolsMode: eval.AllowedToolsAll},
+		{TaskName: "list-pods", AllowedToolsMode: eval.AllowedToolsAll, PromptVariant: 1},
+	}
+
+	sensitivities := PromptRobustness(evalResults)
+	if len(sensitivities) != 2 {
+		t.Fatalf("expected a row per allowedTools mode, got %+v", sensitivities)
+	}
+	if sensitivities[0].AllowedToolsMode != eval.AllowedToolsAssertions || sensitivities[0].Spread != 1 {
+		t.Errorf("unexpected required variant: %+v", sensitivities[0])
+	}
+	if sensitivities[1].AllowedToolsMode != eval.AllowedToolsAll || sensitivities[1].Spread != 0 {
+		t.Errorf("unexpected full variant: %+v", sensitivities[1])
+	}
+}
+
+func TestPromptRobustnessWithoutVariants(t *testing.T) {
+	if sensitivities := PromptRobustness(sampleResults()); len(sensitivities) != 0 {
+		t.Errorf("expected no tasks for a run without prompt variants, got %+v", sensitivities)
+	}
+}
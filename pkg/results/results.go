@@ -3,26 +3,35 @@ package results
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
 )
 
 // Stats holds computed statistics from evaluation results.
 type Stats struct {
-	ResultsFile       string  `json:"resultsFile"`
-	TasksTotal        int     `json:"tasksTotal"`
-	TasksPassed       int     `json:"tasksPassed"`
-	TaskPassRate      float64 `json:"taskPassRate"`
-	AssertionsTotal   int     `json:"assertionsTotal"`
-	AssertionsPassed  int     `json:"assertionsPassed"`
-	AssertionPassRate float64 `json:"assertionPassRate"`
-	TotalTokens       int64   `json:"totalTokens"`
-	McpSchemaTokens   int64   `json:"mcpSchemaTokens"`
-	TasksWithTokens   int     `json:"tasksWithTokens"` // number of tasks that have token data
+	ResultsFile            string  `json:"resultsFile"`
+	TasksTotal             int     `json:"tasksTotal"`
+	TasksPassed            int     `json:"tasksPassed"`
+	TaskPassRate           float64 `json:"taskPassRate"`
+	TasksJudgeErrored      int     `json:"tasksJudgeErrored"`      // failed because the judge could not produce a verdict
+	TasksSkippedOverBudget int     `json:"tasksSkippedOverBudget"` // not started because the run budget was exceeded
+	TasksSkipped           int     `json:"tasksSkipped"`           // not started for another reason, and left out of TaskPassRate
+	AssertionsTotal        int     `json:"assertionsTotal"`
+	AssertionsPassed       int     `json:"assertionsPassed"`
+	AssertionPassRate      float64 `json:"assertionPassRate"`
+	TotalTokens            int64   `json:"totalTokens"`
+	McpSchemaTokens        int64   `json:"mcpSchemaTokens"`
+	TasksWithTokens        int     `json:"tasksWithTokens"` // number of tasks that have token data
+
+	// ErrorKinds counts the runs that did not pass by the kind of their error
+	ErrorKinds ErrorCounts `json:"errorKinds"`
 }
 
 // Load reads a JSON results file and returns the parsed evaluations.
@@ -38,7 +47,7 @@ func Load(path string) ([]*eval.EvalResult, error) {
 
 // LoadOutput reads a JSON results file and returns the full output including summary.
 // Supports both the current format (object with summary + results) and
-// the legacy format (bare array of results).
+// the legacy format (bare array of results), optionally gzip-compressed.
 func LoadOutput(path string) (*eval.EvalOutput, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -49,14 +58,33 @@ func LoadOutput(path string) (*eval.EvalOutput, error) {
 }
 
 // ParseOutput parses JSON data as an EvalOutput.
-// Auto-detects legacy array format vs current object format.
+// Auto-detects legacy array format vs current object format, and also accepts
+// an NDJSON results journal, which may be incomplete if the run did not finish.
+// Gzip-compressed data is decompressed first.
 func ParseOutput(data []byte) (*eval.EvalOutput, error) {
+	if isGzip(data) {
+		decompressed, err := gunzip(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress results: %w", err)
+		}
+		data = decompressed
+	}
+
 	// Trim whitespace to detect format
 	trimmed := bytes.TrimSpace(data)
 	if len(trimmed) == 0 {
 		return nil, fmt.Errorf("empty results data")
 	}
 
+	// Journal written during a run: one JSON entry per line
+	if eval.IsJournal(trimmed) {
+		entries, err := eval.ReadJournal(bytes.NewReader(trimmed))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse results journal: %w", err)
+		}
+		return eval.OutputFromJournal(entries), nil
+	}
+
 	// Legacy format: bare JSON array
 	if trimmed[0] == '[' {
 		var results []*eval.EvalResult
@@ -77,6 +105,101 @@ func ParseOutput(data []byte) (*eval.EvalOutput, error) {
 	return &output, nil
 }
 
+// isGzip reports whether data starts with the gzip magic number.
+func isGzip(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// ErrorCounts counts failed task runs by the kind of their error.
+type ErrorCounts struct {
+	Setup  int `json:"setup"`
+	Agent  int `json:"agent"`
+	Verify int `json:"verify"`
+	Infra  int `json:"infra"`
+}
+
+// Add counts a run that failed with an error of kind. Runs without a kind are ignored.
+func (c *ErrorCounts) Add(kind task.ErrorKind) {
+	switch kind {
+	case task.ErrorKindSetup:
+		c.Setup++
+	case task.ErrorKindAgent:
+		c.Agent++
+	case task.ErrorKindVerify:
+		c.Verify++
+	case task.ErrorKindInfra:
+		c.Infra++
+	}
+}
+
+// Total returns the number of counted runs.
+func (c ErrorCounts) Total() int {
+	return c.Setup + c.Agent + c.Verify + c.Infra
+}
+
+// String returns the counts in a form suitable for a summary line.
+func (c ErrorCounts) String() string {
+	return fmt.Sprintf("setup=%d, agent=%d, verify=%d, infra=%d", c.Setup, c.Agent, c.Verify, c.Infra)
+}
+
+// ErrorKind returns the kind of error a result failed with. For results written
+// before error kinds were recorded, it is inferred from the judge and agent
+// execution error flags.
+func ErrorKind(r *eval.EvalResult) task.ErrorKind {
+	switch {
+	case r.ErrorKind != "":
+		return r.ErrorKind
+	case r.JudgeError:
+		return task.ErrorKindInfra
+	case r.AgentExecutionError:
+		return task.ErrorKindAgent
+	}
+	return ""
+}
+
+// SkipReason returns why a result was skipped, or "" if it was run. For results
+// written before skip reasons were recorded, runs skipped over budget are
+// recognized by their flag.
+func SkipReason(r *eval.EvalResult) eval.SkipReason {
+	switch {
+	case r.SkipReason != "":
+		return r.SkipReason
+	case r.SkippedOverBudget:
+		return eval.SkipReasonBudgetExceeded
+	}
+	return ""
+}
+
+// CountsTowardsPassRate reports whether a result is part of the task pass
+// rate. Skipped runs are left out, unless they were skipped over budget.
+func CountsTowardsPassRate(r *eval.EvalResult) bool {
+	reason := SkipReason(r)
+	return reason == "" || reason.CountsAsNotPassed()
+}
+
+// TaskKey returns the key that identifies the task of a result across runs: its
+// task ID if it has one, and its task name otherwise, followed by @ and the
+// environment it ran against, if any.
+func TaskKey(r *eval.EvalResult) string {
+	key := r.TaskName
+	if r.TaskID != "" {
+		key = r.TaskID
+	}
+	if r.Environment != "" {
+		key += "@" + r.Environment
+	}
+	return key
+}
+
 // Filter returns the subset of results whose task names contain the filter substring.
 func Filter(results []*eval.EvalResult, filter string) []*eval.EvalResult {
 	if filter == "" {
@@ -93,6 +216,64 @@ func Filter(results []*eval.EvalResult, filter string) []*eval.EvalResult {
 	return filtered
 }
 
+// ExcludeQuarantined returns the subset of results that may gate a run,
+// dropping tasks in the quarantined state.
+func ExcludeQuarantined(results []*eval.EvalResult) []*eval.EvalResult {
+	gating := make([]*eval.EvalResult, 0, len(results))
+	for _, r := range results {
+		if r.State == task.StateQuarantined {
+			continue
+		}
+		gating = append(gating, r)
+	}
+	return gating
+}
+
+// ExcludeExpectedFailures returns the subset of results that may gate a run,
+// dropping the runs of tasks that are expected to fail.
+func ExcludeExpectedFailures(results []*eval.EvalResult) []*eval.EvalResult {
+	gating := make([]*eval.EvalResult, 0, len(results))
+	for _, r := range results {
+		if r.ExpectFailure {
+			continue
+		}
+		gating = append(gating, r)
+	}
+	return gating
+}
+
+// XFailed reports whether a run of a task expected to fail failed, as expected.
+func XFailed(r *eval.EvalResult) bool {
+	return r.ExpectFailure && !r.TaskPassed && SkipReason(r) == ""
+}
+
+// XPassed reports whether a run of a task expected to fail unexpectedly passed.
+func XPassed(r *eval.EvalResult) bool {
+	return r.ExpectFailure && r.TaskPassed
+}
+
+// ExpectedFailureCounts counts the runs of tasks that are expected to fail by
+// their outcome.
+type ExpectedFailureCounts struct {
+	XFailed int `json:"xfailed"`
+	XPassed int `json:"xpassed"`
+}
+
+// CountExpectedFailures counts the expected failures and unexpected passes of
+// the results.
+func CountExpectedFailures(results []*eval.EvalResult) ExpectedFailureCounts {
+	var counts ExpectedFailureCounts
+	for _, r := range results {
+		switch {
+		case XFailed(r):
+			counts.XFailed++
+		case XPassed(r):
+			counts.XPassed++
+		}
+	}
+	return counts
+}
+
 // CalculateStats computes statistics from evaluation results.
 func CalculateStats(resultsFile string, results []*eval.EvalResult) Stats {
 	stats := Stats{
@@ -104,6 +285,18 @@ func CalculateStats(resultsFile string, results []*eval.EvalResult) Stats {
 		if result.TaskPassed {
 			stats.TasksPassed++
 		}
+		if !CountsTowardsPassRate(result) {
+			stats.TasksSkipped++
+		}
+		if result.JudgeError {
+			stats.TasksJudgeErrored++
+		}
+		if result.SkippedOverBudget {
+			stats.TasksSkippedOverBudget++
+		}
+		if !result.TaskPassed {
+			stats.ErrorKinds.Add(ErrorKind(result))
+		}
 
 		if result.AssertionResults != nil {
 			stats.AssertionsTotal += result.AssertionResults.TotalAssertions()
@@ -118,8 +311,8 @@ func CalculateStats(resultsFile string, results []*eval.EvalResult) Stats {
 	}
 
 	// Calculate pass rates
-	if stats.TasksTotal > 0 {
-		stats.TaskPassRate = float64(stats.TasksPassed) / float64(stats.TasksTotal)
+	if counted := stats.TasksTotal - stats.TasksSkipped; counted > 0 {
+		stats.TaskPassRate = float64(stats.TasksPassed) / float64(counted)
 	}
 	if stats.AssertionsTotal > 0 {
 		stats.AssertionPassRate = float64(stats.AssertionsPassed) / float64(stats.AssertionsTotal)
@@ -128,6 +321,27 @@ func CalculateStats(resultsFile string, results []*eval.EvalResult) Stats {
 	return stats
 }
 
+// CalculateStatsByEnvironment computes statistics from evaluation results for
+// each environment they ran against. It returns nil if none ran against an
+// environment.
+func CalculateStatsByEnvironment(resultsFile string, results []*eval.EvalResult) map[string]Stats {
+	byEnvironment := make(map[string][]*eval.EvalResult)
+	for _, result := range results {
+		if result.Environment != "" {
+			byEnvironment[result.Environment] = append(byEnvironment[result.Environment], result)
+		}
+	}
+	if len(byEnvironment) == 0 {
+		return nil
+	}
+
+	stats := make(map[string]Stats, len(byEnvironment))
+	for name, envResults := range byEnvironment {
+		stats[name] = CalculateStats(resultsFile, envResults)
+	}
+	return stats
+}
+
 // PassedAssertions returns the number of passed assertions for a result.
 func PassedAssertions(r *eval.EvalResult) int {
 	if r.AssertionResults == nil {
@@ -186,6 +400,15 @@ func FailureReason(r *eval.EvalResult) string {
 	if a.NoDuplicateCalls != nil && !a.NoDuplicateCalls.Passed {
 		return a.NoDuplicateCalls.Reason
 	}
+	if a.ToolOutputs != nil && !a.ToolOutputs.Passed {
+		return a.ToolOutputs.Reason
+	}
+	if a.ReadOnly != nil && !a.ReadOnly.Passed {
+		return a.ReadOnly.Reason
+	}
+	if a.ToolErrors != nil && !a.ToolErrors.Passed {
+		return a.ToolErrors.Reason
+	}
 	return ""
 }
 
@@ -210,6 +433,44 @@ func CollectFailedAssertions(results *eval.CompositeAssertionResult) []string {
 	addFailure("PromptsNotUsed", results.PromptsNotUsed)
 	addFailure("CallOrder", results.CallOrder)
 	addFailure("NoDuplicateCalls", results.NoDuplicateCalls)
+	addFailure("ToolOutputs", results.ToolOutputs)
+	addFailure("ReadOnly", results.ReadOnly)
+	addFailure("ToolErrors", results.ToolErrors)
 
 	return failures
 }
+
+// AssertionOutcomes returns whether each assertion evaluated for a result
+// passed, by the name of the assertion.
+func AssertionOutcomes(r *eval.EvalResult) map[string]bool {
+	outcomes := make(map[string]bool)
+	a := r.AssertionResults
+	if a == nil {
+		return outcomes
+	}
+
+	add := func(name string, result *eval.SingleAssertionResult) {
+		if result != nil {
+			outcomes[name] = result.Passed
+		}
+	}
+
+	add("ToolsUsed", a.ToolsUsed)
+	add("RequireAny", a.RequireAny)
+	add("ToolsNotUsed", a.ToolsNotUsed)
+	add("MinToolCalls", a.MinToolCalls)
+	add("MaxToolCalls", a.MaxToolCalls)
+	add("ResourcesRead", a.ResourcesRead)
+	add("ResourcesNotRead", a.ResourcesNotRead)
+	add("PromptsUsed", a.PromptsUsed)
+	add("PromptsNotUsed", a.PromptsNotUsed)
+	add("CallOrder", a.CallOrder)
+	add("NoDuplicateCalls", a.NoDuplicateCalls)
+	add("ToolOutputs", a.ToolOutputs)
+	add("SkillsLoaded", a.SkillsLoaded)
+	add("SkillsNotLoaded", a.SkillsNotLoaded)
+	add("ReadOnly", a.ReadOnly)
+	add("ToolErrors", a.ToolErrors)
+
+	return outcomes
+}
@@ -1,12 +1,15 @@
 package results
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/task"
 )
 
 // createTestResultsFile creates a temporary results file for testing.
@@ -99,6 +102,111 @@ func TestCalculateStats(t *testing.T) {
 	}
 }
 
+func TestCalculateStatsErrorKinds(t *testing.T) {
+	evalResults := []*eval.EvalResult{
+		{TaskName: "passed", TaskPassed: true},
+		{TaskName: "setup", ErrorKind: task.ErrorKindSetup},
+		{TaskName: "verify", ErrorKind: task.ErrorKindVerify},
+		{TaskName: "verify-2", ErrorKind: task.ErrorKindVerify},
+		// Results written before error kinds were recorded
+		{TaskName: "legacy-agent", AgentExecutionError: true},
+		{TaskName: "legacy-judge", JudgeError: true},
+	}
+
+	stats := CalculateStats("test.json", evalResults)
+
+	want := ErrorCounts{Setup: 1, Agent: 1, Verify: 2, Infra: 1}
+	if stats.ErrorKinds != want {
+		t.Errorf("ErrorKinds = %+v, want %+v", stats.ErrorKinds, want)
+	}
+	if got := stats.ErrorKinds.Total(); got != 5 {
+		t.Errorf("ErrorKinds.Total() = %d, want 5", got)
+	}
+	if got, want := stats.ErrorKinds.String(), "setup=1, agent=1, verify=2, infra=1"; got != want {
+		t.Errorf("ErrorKinds.String() = %q, want %q", got, want)
+	}
+}
+
+func TestCalculateStatsSkipped(t *testing.T) {
+	evalResults := []*eval.EvalResult{
+		{TaskName: "passed", TaskPassed: true},
+		{TaskName: "failed", ErrorKind: task.ErrorKindVerify},
+		{TaskName: "requirements", Skipped: true, SkipReason: eval.SkipReasonRequirementsUnmet},
+		{TaskName: "budget", Skipped: true, SkipReason: eval.SkipReasonBudgetExceeded, SkippedOverBudget: true},
+		// Result written before skip reasons were recorded
+		{TaskName: "legacy-budget", SkippedOverBudget: true},
+	}
+
+	stats := CalculateStats("test.json", evalResults)
+
+	if stats.TasksTotal != 5 {
+		t.Errorf("TasksTotal = %d, want 5", stats.TasksTotal)
+	}
+	if stats.TasksSkipped != 1 {
+		t.Errorf("TasksSkipped = %d, want 1", stats.TasksSkipped)
+	}
+	if stats.TasksSkippedOverBudget != 2 {
+		t.Errorf("TasksSkippedOverBudget = %d, want 2", stats.TasksSkippedOverBudget)
+	}
+
+	// Only the run skipped for unmet requirements is left out of the pass rate
+	expectedTaskRate := 1.0 / 4.0
+	if stats.TaskPassRate != expectedTaskRate {
+		t.Errorf("TaskPassRate = %f, want %f", stats.TaskPassRate, expectedTaskRate)
+	}
+	if got := stats.ErrorKinds.Total(); got != 1 {
+		t.Errorf("ErrorKinds.Total() = %d, want 1", got)
+	}
+}
+
+func TestCalculateStatsByEnvironment(t *testing.T) {
+	if stats := CalculateStatsByEnvironment("test.json", sampleResults()); stats != nil {
+		t.Errorf("CalculateStatsByEnvironment() = %v, want nil without environments", stats)
+	}
+
+	evalResults := []*eval.EvalResult{
+		{TaskName: "a", Environment: "dev", TaskPassed: true},
+		{TaskName: "b", Environment: "dev", TaskPassed: true},
+		{TaskName: "a", Environment: "prod", TaskPassed: true},
+		{TaskName: "b", Environment: "prod", ErrorKind: task.ErrorKindVerify},
+		{TaskName: "c"},
+	}
+
+	stats := CalculateStatsByEnvironment("test.json", evalResults)
+	if len(stats) != 2 {
+		t.Fatalf("got stats for %d environments, want 2", len(stats))
+	}
+	if dev := stats["dev"]; dev.TasksTotal != 2 || dev.TasksPassed != 2 || dev.TaskPassRate != 1 {
+		t.Errorf("dev = %+v, want 2/2 passed", dev)
+	}
+	if prod := stats["prod"]; prod.TasksTotal != 2 || prod.TasksPassed != 1 || prod.TaskPassRate != 0.5 || prod.ErrorKinds.Verify != 1 {
+		t.Errorf("prod = %+v, want 1/2 passed with a verify failure", prod)
+	}
+}
+
+func TestSkipReason(t *testing.T) {
+	tests := []struct {
+		result  *eval.EvalResult
+		want    eval.SkipReason
+		counted bool
+	}{
+		{&eval.EvalResult{TaskPassed: true}, "", true},
+		{&eval.EvalResult{ErrorKind: task.ErrorKindAgent}, "", true},
+		{&eval.EvalResult{Skipped: true, SkipReason: eval.SkipReasonRequirementsUnmet}, eval.SkipReasonRequirementsUnmet, false},
+		{&eval.EvalResult{Skipped: true, SkipReason: eval.SkipReasonBudgetExceeded}, eval.SkipReasonBudgetExceeded, true},
+		{&eval.EvalResult{SkippedOverBudget: true}, eval.SkipReasonBudgetExceeded, true},
+	}
+
+	for _, tt := range tests {
+		if got := SkipReason(tt.result); got != tt.want {
+			t.Errorf("SkipReason(%+v) = %q, want %q", tt.result, got, tt.want)
+		}
+		if got := CountsTowardsPassRate(tt.result); got != tt.counted {
+			t.Errorf("CountsTowardsPassRate(%+v) = %v, want %v", tt.result, got, tt.counted)
+		}
+	}
+}
+
 func TestCalculateStatsEmptyResults(t *testing.T) {
 	stats := CalculateStats("empty.json", []*eval.EvalResult{})
 
@@ -220,6 +328,23 @@ func TestParseOutput(t *testing.T) {
 			input:   `{"summary":{"parallelWorkers":1}}`,
 			wantErr: true,
 		},
+		{
+			name: "journal",
+			input: `{"type":"start","time":"2025-01-15T10:30:00Z","summary":{"parallelWorkers":1,"runs":1}}
+{"type":"result","time":"2025-01-15T10:31:00Z","result":{"taskName":"t1","taskPassed":true}}
+{"type":"result","time":"2025-01-15T10:32:00Z","result":{"taskName":"t2","taskPassed":false}}
+`,
+			wantResults: 2,
+			wantSummary: true,
+		},
+		{
+			name: "journal with partial last line",
+			input: `{"type":"start","time":"2025-01-15T10:30:00Z","summary":{"parallelWorkers":1,"runs":1}}
+{"type":"result","time":"2025-01-15T10:31:00Z","result":{"taskName":"t1","taskPassed":true}}
+{"type":"result","time":"2025-01-15T10:32:00Z","result":{"taskNa`,
+			wantResults: 1,
+			wantSummary: true,
+		},
 		{
 			name:    "invalid JSON",
 			input:   `{broken`,
@@ -284,6 +409,35 @@ func TestLoadOutput(t *testing.T) {
 	}
 }
 
+func TestLoadOutputGzip(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "output.json.gz")
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(`{"summary":{"parallelWorkers":1,"runs":1},"results":[{"taskName":"task-1","taskPassed":true}]}`)); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	if err := os.WriteFile(filePath, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	loaded, err := Load(filePath)
+	if err != nil {
+		t.Fatalf("Load failed on compressed file: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].TaskName != "task-1" {
+		t.Errorf("got %+v, want task-1", loaded)
+	}
+
+	if _, err := ParseOutput([]byte{0x1f, 0x8b, 0x00}); err == nil {
+		t.Error("expected error for corrupt gzip data")
+	}
+}
+
 func TestLoadBackwardCompat(t *testing.T) {
 	// Load using legacy format through results.Load (returns []*EvalResult)
 	evalResults := sampleResults()
@@ -314,3 +468,59 @@ func TestCollectFailedAssertions(t *testing.T) {
 		t.Errorf("failures[0] = %s, want 'ToolsUsed: Tool not called'", failures[0])
 	}
 }
+
+func TestExcludeQuarantined(t *testing.T) {
+	evalResults := sampleResults()
+	evalResults[2].State = task.StateQuarantined
+
+	gating := ExcludeQuarantined(evalResults)
+	if len(gating) != 2 {
+		t.Fatalf("ExcludeQuarantined() returned %d results, want 2", len(gating))
+	}
+	for _, r := range gating {
+		if r.TaskName == "task-3" {
+			t.Errorf("quarantined task %q should have been excluded", r.TaskName)
+		}
+	}
+}
+
+func TestTaskKey(t *testing.T) {
+	if got := TaskKey(&eval.EvalResult{TaskName: "task-name"}); got != "task-name" {
+		t.Errorf("TaskKey() = %q, want task-name", got)
+	}
+	if got := TaskKey(&eval.EvalResult{TaskID: "task-id", TaskName: "task-name"}); got != "task-id" {
+		t.Errorf("TaskKey() = %q, want task-id", got)
+	}
+	if got := TaskKey(&eval.EvalResult{TaskID: "task-id", TaskName: "task-name", Environment: "prod"}); got != "task-id@prod" {
+		t.Errorf("TaskKey() = %q, want task-id@prod", got)
+	}
+}
+
+func TestExpectedFailures(t *testing.T) {
+	evalResults := []*eval.EvalResult{
+		{TaskName: "passed", TaskPassed: true},
+		{TaskName: "failed"},
+		{TaskName: "xfailed", ExpectFailure: true},
+		{TaskName: "xpassed", ExpectFailure: true, TaskPassed: true},
+		{TaskName: "skipped", ExpectFailure: true, Skipped: true, SkipReason: eval.SkipReasonRequirementsUnmet},
+	}
+
+	gating := ExcludeExpectedFailures(evalResults)
+	if len(gating) != 2 || gating[0].TaskName != "passed" || gating[1].TaskName != "failed" {
+		t.Errorf("ExcludeExpectedFailures() kept %d results, want passed and failed", len(gating))
+	}
+
+	want := ExpectedFailureCounts{XFailed: 1, XPassed: 1}
+	if got := CountExpectedFailures(evalResults); got != want {
+		t.Errorf("CountExpectedFailures() = %+v, want %+v", got, want)
+	}
+
+	for _, r := range evalResults {
+		if got, want := XFailed(r), r.TaskName == "xfailed"; got != want {
+			t.Errorf("XFailed(%s) = %v, want %v", r.TaskName, got, want)
+		}
+		if got, want := XPassed(r), r.TaskName == "xpassed"; got != want {
+			t.Errorf("XPassed(%s) = %v, want %v", r.TaskName, got, want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package results
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	// SignatureSuffix is appended to the path of a results file to get the
+	// path of its ed25519 signature
+	SignatureSuffix = ".sig"
+
+	// SigstoreBundleSuffix is appended to the path of a results file to get
+	// the path of its sigstore bundle
+	SigstoreBundleSuffix = ".sigstore.json"
+
+	algorithmEd25519 = "ed25519"
+)
+
+// Signature is the ed25519 signature of a results file, written next to it.
+// The signature covers the exact bytes of the file, so it must be verified
+// before the file is modified, e.g. by 'mcpchecker review'.
+type Signature struct {
+	Algorithm string `json:"algorithm"`
+
+	// Digest is the SHA-256 of the signed file, as sha256:<hex>
+	Digest string `json:"digest"`
+
+	// PublicKey is the key that made the signature. It identifies the key but
+	// is not trusted when verifying; the verifier supplies the key.
+	PublicKey []byte `json:"publicKey"`
+
+	Signature []byte `json:"signature"`
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// LoadPrivateKey reads an ed25519 private key in PKCS #8 PEM format, as
+// written by 'openssl genpkey -algorithm ed25519'.
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %w", path, err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key %s is not an ed25519 key", path)
+	}
+	return edKey, nil
+}
+
+// LoadPublicKey reads an ed25519 public key in PKIX PEM format, as written by
+// 'openssl pkey -pubout'.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", path, err)
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s is not an ed25519 key", path)
+	}
+	return edKey, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %s is not PEM encoded", path)
+	}
+	return block, nil
+}
+
+// SignFile signs the results file at path with key and writes the signature
+// to path + SignatureSuffix, whose path it returns.
+func SignFile(path string, key ed25519.PrivateKey) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read results file: %w", err)
+	}
+
+	sig := &Signature{
+		Algorithm: algorithmEd25519,
+		Digest:    digest(data),
+		PublicKey: key.Public().(ed25519.PublicKey),
+		Signature: ed25519.Sign(key, data),
+	}
+	out, err := json.MarshalIndent(sig, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	sigPath := path + SignatureSuffix
+	if err := os.WriteFile(sigPath, append(out, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("failed to write signature: %w", err)
+	}
+	return sigPath, nil
+}
+
+// VerifyFile checks that the signature at sigPath was made over the results
+// file at path with the private key of key.
+func VerifyFile(path, sigPath string, key ed25519.PublicKey) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read results file: %w", err)
+	}
+	sigData, err := os.ReadFile(sigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %w", err)
+	}
+
+	var sig Signature
+	if err := json.Unmarshal(sigData, &sig); err != nil {
+		return fmt.Errorf("failed to parse signature %s: %w", sigPath, err)
+	}
+	if sig.Algorithm != algorithmEd25519 {
+		return fmt.Errorf("unsupported signature algorithm %q", sig.Algorithm)
+	}
+
+	if !bytes.Equal(sig.PublicKey, key) {
+		return fmt.Errorf("results were signed with a different key")
+	}
+	if d := digest(data); d != sig.Digest {
+		return fmt.Errorf("results file was modified after signing (digest %s, signed %s)", d, sig.Digest)
+	}
+	if !ed25519.Verify(key, data, sig.Signature) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+// SignFileSigstore signs the results file at path keylessly with cosign,
+// which must be in PATH, and writes the sigstore bundle to
+// path + SigstoreBundleSuffix, whose path it returns.
+func SignFileSigstore(ctx context.Context, path string) (string, error) {
+	bundle := path + SigstoreBundleSuffix
+	if err := cosign(ctx, "sign-blob", "--yes", "--bundle", bundle, path); err != nil {
+		return "", err
+	}
+	return bundle, nil
+}
+
+// VerifyFileSigstore checks the sigstore bundle of the results file at path
+// with cosign. The signing certificate must have been issued to identity by
+// the OIDC issuer.
+func VerifyFileSigstore(ctx context.Context, path, bundle, identity, issuer string) error {
+	if identity == "" || issuer == "" {
+		return fmt.Errorf("the certificate identity and OIDC issuer must be set to verify sigstore bundles")
+	}
+	return cosign(ctx, "verify-blob",
+		"--bundle", bundle,
+		"--certificate-identity", identity,
+		"--certificate-oidc-issuer", issuer,
+		path)
+}
+
+func cosign(ctx context.Context, args ...string) error {
+	bin, err := exec.LookPath("cosign")
+	if err != nil {
+		return fmt.Errorf("cosign not found in PATH: %w", err)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("cosign %s failed: %w: %s", args[0], err, msg)
+		}
+		return fmt.Errorf("cosign %s failed: %w", args[0], err)
+	}
+	return nil
+}
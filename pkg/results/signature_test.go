@@ -0,0 +1,170 @@
+package results
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func writeKeyPair(t *testing.T, dir string) (privPath, pubPath string) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privPath = filepath.Join(dir, "key.pem")
+	pubPath = filepath.Join(dir, "key.pub")
+	if err := os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return privPath, pubPath
+}
+
+func TestSignAndVerifyFile(t *testing.T) {
+	dir := t.TempDir()
+	privPath, pubPath := writeKeyPair(t, dir)
+	_, otherPubPath := writeKeyPair(t, t.TempDir())
+
+	tests := map[string]struct {
+		modify      func(t *testing.T, resultsPath string)
+		otherKey    bool
+		errContains string
+	}{
+		"valid": {},
+		"modified results": {
+			modify: func(t *testing.T, resultsPath string) {
+				if err := os.WriteFile(resultsPath, []byte(`{"results":[{"taskPassed":true}]}`), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			errContains: "results file was modified after signing",
+		},
+		"other key": {
+			otherKey:    true,
+			errContains: "results were signed with a different key",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			resultsPath := filepath.Join(t.TempDir(), "results.json")
+			if err := os.WriteFile(resultsPath, []byte(`{"results":[{"taskPassed":false}]}`), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			key, err := LoadPrivateKey(privPath)
+			if err != nil {
+				t.Fatalf("LoadPrivateKey() error = %v", err)
+			}
+			sigPath, err := SignFile(resultsPath, key)
+			if err != nil {
+				t.Fatalf("SignFile() error = %v", err)
+			}
+			if sigPath != resultsPath+SignatureSuffix {
+				t.Errorf("signature path = %q, want %q", sigPath, resultsPath+SignatureSuffix)
+			}
+
+			if tc.modify != nil {
+				tc.modify(t, resultsPath)
+			}
+			verifyKeyPath := pubPath
+			if tc.otherKey {
+				verifyKeyPath = otherPubPath
+			}
+			pub, err := LoadPublicKey(verifyKeyPath)
+			if err != nil {
+				t.Fatalf("LoadPublicKey() error = %v", err)
+			}
+
+			err = VerifyFile(resultsPath, sigPath, pub)
+			if tc.errContains == "" {
+				if err != nil {
+					t.Errorf("VerifyFile() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errContains) {
+				t.Errorf("VerifyFile() error = %v, want containing %q", err, tc.errContains)
+			}
+		})
+	}
+}
+
+func TestLoadKeyErrors(t *testing.T) {
+	dir := t.TempDir()
+	privPath, pubPath := writeKeyPair(t, dir)
+	notPEM := filepath.Join(dir, "key.txt")
+	if err := os.WriteFile(notPEM, []byte("not a key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadPrivateKey(pubPath); err == nil {
+		t.Error("LoadPrivateKey() of a public key should fail")
+	}
+	if _, err := LoadPublicKey(privPath); err == nil {
+		t.Error("LoadPublicKey() of a private key should fail")
+	}
+	if _, err := LoadPrivateKey(notPEM); err == nil || !strings.Contains(err.Error(), "is not PEM encoded") {
+		t.Errorf("LoadPrivateKey() error = %v, want not PEM encoded", err)
+	}
+}
+
+func TestSigstore(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake cosign is a shell script")
+	}
+
+	// A fake cosign records its arguments
+	bin := t.TempDir()
+	argsFile := filepath.Join(bin, "args")
+	script := "#!/bin/sh\necho \"$@\" >> " + argsFile + "\n"
+	if err := os.WriteFile(filepath.Join(bin, "cosign"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	resultsPath := filepath.Join(t.TempDir(), "results.json")
+	bundle, err := SignFileSigstore(context.Background(), resultsPath)
+	if err != nil {
+		t.Fatalf("SignFileSigstore() error = %v", err)
+	}
+	if bundle != resultsPath+SigstoreBundleSuffix {
+		t.Errorf("bundle = %q, want %q", bundle, resultsPath+SigstoreBundleSuffix)
+	}
+
+	if err := VerifyFileSigstore(context.Background(), resultsPath, bundle, "", ""); err == nil {
+		t.Error("VerifyFileSigstore() without identity should fail")
+	}
+	if err := VerifyFileSigstore(context.Background(), resultsPath, bundle, "ci@example.com", "https://accounts.example.com"); err != nil {
+		t.Fatalf("VerifyFileSigstore() error = %v", err)
+	}
+
+	data, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "sign-blob --yes --bundle " + bundle + " " + resultsPath + "\n" +
+		"verify-blob --bundle " + bundle + " --certificate-identity ci@example.com --certificate-oidc-issuer https://accounts.example.com " + resultsPath + "\n"
+	if string(data) != want {
+		t.Errorf("cosign calls =\n%s\nwant\n%s", data, want)
+	}
+}
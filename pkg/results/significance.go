@@ -0,0 +1,197 @@
+package results
+
+import (
+	"math"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// DefaultAlpha is the significance level used to compare runs, for 95%
+// confidence intervals.
+const DefaultAlpha = 0.05
+
+// Interval is a confidence interval of a pass rate.
+type Interval struct {
+	Low  float64 `json:"low"`
+	High float64 `json:"high"`
+}
+
+// PassRateComparison compares the pass rates of a task, or of all tasks, in
+// two runs. With repeated runs, a difference between pass rates can be noise:
+// PValue is the two-sided p-value of Fisher's exact test of both runs having
+// the same pass rate, and Significant is set if it is below the significance
+// level.
+type PassRateComparison struct {
+	TaskID   string `json:"taskId,omitempty"`
+	TaskName string `json:"taskName,omitempty"`
+
+	BaseRuns     int      `json:"baseRuns"`
+	BasePassed   int      `json:"basePassed"`
+	BasePassRate float64  `json:"basePassRate"`
+	BaseInterval Interval `json:"baseInterval"`
+
+	HeadRuns     int      `json:"headRuns"`
+	HeadPassed   int      `json:"headPassed"`
+	HeadPassRate float64  `json:"headPassRate"`
+	HeadInterval Interval `json:"headInterval"`
+
+	PValue      float64 `json:"pValue"`
+	Significant bool    `json:"significant"`
+}
+
+// Change returns the difference between the head and base pass rates.
+func (c PassRateComparison) Change() float64 {
+	return c.HeadPassRate - c.BasePassRate
+}
+
+// RunComparison compares the pass rates of two runs, overall and per task.
+type RunComparison struct {
+	// Alpha is the significance level; the confidence intervals are at the
+	// 1-Alpha level
+	Alpha float64 `json:"alpha"`
+
+	// Repeated is set if a task was run more than once in either run. Without
+	// repeated runs, no change of a task's pass rate can be significant.
+	Repeated bool `json:"repeated"`
+
+	// Overall compares the task pass rates of all the runs of both runs
+	Overall PassRateComparison `json:"overall"`
+
+	// Tasks compares the tasks of both runs, in the order they first appear
+	// in the head run
+	Tasks []PassRateComparison `json:"tasks"`
+}
+
+// CompareRuns compares the pass rates of the runs of base and head, overall
+// and for each task in both, with Wilson score confidence intervals and
+// Fisher's exact test at the significance level alpha. Tasks are matched by
+// task ID, falling back to the name for base runs from before the task had an
+// ID. Runs that don't count towards pass rates are left out. A task run passes
+// if the task and all its assertions passed, like in 'result diff', and the
+// overall pass rate counts passed tasks, like the task pass rate of Stats.
+func CompareRuns(base, head []*eval.EvalResult, alpha float64) RunComparison {
+	comparison := RunComparison{Alpha: alpha}
+	z := math.Sqrt2 * math.Erfinv(1-alpha)
+
+	type counts struct {
+		taskID, taskName string
+		runs, passed     int
+	}
+	group := func(results []*eval.EvalResult) (map[string]*counts, []string, int, int) {
+		byTask := make(map[string]*counts)
+		var order []string
+		var runs, passed int
+		for _, r := range results {
+			if !CountsTowardsPassRate(r) {
+				continue
+			}
+			runs++
+			if r.TaskPassed {
+				passed++
+			}
+
+			key := TaskKey(r)
+			c, ok := byTask[key]
+			if !ok {
+				c = &counts{taskID: r.TaskID, taskName: r.TaskName}
+				byTask[key] = c
+				order = append(order, key)
+			}
+			c.runs++
+			if r.Passed() {
+				c.passed++
+			}
+			if c.runs > 1 {
+				comparison.Repeated = true
+			}
+		}
+		return byTask, order, runs, passed
+	}
+
+	baseTasks, _, baseRuns, basePassed := group(base)
+	headTasks, headOrder, headRuns, headPassed := group(head)
+	comparison.Overall = comparePassRates(baseRuns, basePassed, headRuns, headPassed, alpha, z)
+
+	comparison.Tasks = make([]PassRateComparison, 0, len(headOrder))
+	for _, key := range headOrder {
+		h := headTasks[key]
+		b, ok := baseTasks[key]
+		if !ok {
+			if b, ok = baseTasks[h.taskName]; !ok || b.taskID != "" {
+				continue
+			}
+		}
+
+		c := comparePassRates(b.runs, b.passed, h.runs, h.passed, alpha, z)
+		c.TaskID = h.taskID
+		c.TaskName = h.taskName
+		comparison.Tasks = append(comparison.Tasks, c)
+	}
+
+	return comparison
+}
+
+func comparePassRates(baseRuns, basePassed, headRuns, headPassed int, alpha, z float64) PassRateComparison {
+	c := PassRateComparison{
+		BaseRuns:     baseRuns,
+		BasePassed:   basePassed,
+		BasePassRate: ratio(basePassed, baseRuns),
+		BaseInterval: wilsonInterval(basePassed, baseRuns, z),
+		HeadRuns:     headRuns,
+		HeadPassed:   headPassed,
+		HeadPassRate: ratio(headPassed, headRuns),
+		HeadInterval: wilsonInterval(headPassed, headRuns, z),
+		PValue:       fisherExact(basePassed, baseRuns-basePassed, headPassed, headRuns-headPassed),
+	}
+	c.Significant = c.PValue < alpha
+	return c
+}
+
+// wilsonInterval returns the Wilson score interval of passed out of runs for
+// the standard normal quantile z. Unlike the normal approximation, it stays
+// within [0, 1] and is meaningful for the small numbers of runs and the pass
+// rates of 0 or 1 common in evals.
+func wilsonInterval(passed, runs int, z float64) Interval {
+	if runs == 0 {
+		return Interval{Low: 0, High: 1}
+	}
+
+	n := float64(runs)
+	p := float64(passed) / n
+	denominator := 1 + z*z/n
+	center := (p + z*z/(2*n)) / denominator
+	margin := z * math.Sqrt(p*(1-p)/n+z*z/(4*n*n)) / denominator
+	return Interval{Low: math.Max(0, center-margin), High: math.Min(1, center+margin)}
+}
+
+// fisherExact returns the two-sided p-value of Fisher's exact test for the
+// 2x2 contingency table [[a, b], [c, d]]: the probability, with the row and
+// column sums fixed, of a table at most as likely as this one.
+func fisherExact(a, b, c, d int) float64 {
+	rowA, rowC, colA := a+b, c+d, a+c
+	n := rowA + rowC
+	if rowA == 0 || rowC == 0 || colA == 0 || colA == n {
+		return 1
+	}
+
+	logChoose := func(n, k int) float64 {
+		x, _ := math.Lgamma(float64(n + 1))
+		y, _ := math.Lgamma(float64(k + 1))
+		z, _ := math.Lgamma(float64(n - k + 1))
+		return x - y - z
+	}
+	logTotal := logChoose(n, colA)
+	probability := func(x int) float64 {
+		return math.Exp(logChoose(rowA, x) + logChoose(rowC, colA-x) - logTotal)
+	}
+
+	observed := probability(a)
+	var p float64
+	for x := max(0, colA-rowC); x <= min(rowA, colA); x++ {
+		// Allow for rounding errors when comparing to the observed table
+		if px := probability(x); px <= observed*(1+1e-7) {
+			p += px
+		}
+	}
+	return math.Min(1, p)
+}
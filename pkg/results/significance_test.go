@@ -0,0 +1,108 @@
+package results
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+func TestFisherExact(t *testing.T) {
+	tests := map[string]struct {
+		a, b, c, d int
+		want       float64
+	}{
+		"all passed to all failed": {a: 5, b: 0, c: 0, d: 5, want: 2.0 / 252},
+		"three to one":             {a: 3, b: 1, c: 1, d: 3, want: 34.0 / 70},
+		"same counts":              {a: 4, b: 1, c: 4, d: 1, want: 1},
+		"no runs in one row":       {a: 3, b: 2, c: 0, d: 0, want: 1},
+		"all passed":               {a: 3, b: 0, c: 2, d: 0, want: 1},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := fisherExact(tc.a, tc.b, tc.c, tc.d); math.Abs(got-tc.want) > 1e-9 {
+				t.Errorf("fisherExact(%d, %d, %d, %d) = %v, want %v", tc.a, tc.b, tc.c, tc.d, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWilsonInterval(t *testing.T) {
+	tests := map[string]struct {
+		passed, runs int
+		want         Interval
+	}{
+		"none passed": {passed: 0, runs: 10, want: Interval{Low: 0, High: 0.2775}},
+		"half passed": {passed: 5, runs: 10, want: Interval{Low: 0.2366, High: 0.7634}},
+		"all passed":  {passed: 10, runs: 10, want: Interval{Low: 0.7225, High: 1}},
+		"no runs":     {passed: 0, runs: 0, want: Interval{Low: 0, High: 1}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := wilsonInterval(tc.passed, tc.runs, 1.959964)
+			if math.Abs(got.Low-tc.want.Low) > 1e-4 || math.Abs(got.High-tc.want.High) > 1e-4 {
+				t.Errorf("wilsonInterval(%d, %d) = %+v, want %+v", tc.passed, tc.runs, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCompareRuns(t *testing.T) {
+	runs := func(id, name string, passed, failed int) []*eval.EvalResult {
+		var results []*eval.EvalResult
+		for i := 0; i < passed+failed; i++ {
+			results = append(results, &eval.EvalResult{
+				TaskID:              id,
+				TaskName:            name,
+				TaskPassed:          i < passed,
+				AllAssertionsPassed: i < passed,
+			})
+		}
+		return results
+	}
+
+	var base, head []*eval.EvalResult
+	base = append(base, runs("", "regressed", 5, 0)...)
+	base = append(base, runs("", "flaky", 3, 2)...)
+	base = append(base, runs("", "removed", 5, 0)...)
+	head = append(head, runs("regressed-id", "regressed", 0, 5)...)
+	head = append(head, runs("", "flaky", 2, 3)...)
+	head = append(head, runs("", "new", 0, 5)...)
+	// Left out of the pass rates
+	head = append(head, &eval.EvalResult{TaskName: "flaky", Skipped: true, SkipReason: eval.SkipReasonRequirementsUnmet})
+
+	comparison := CompareRuns(base, head, DefaultAlpha)
+	if !comparison.Repeated || comparison.Alpha != DefaultAlpha {
+		t.Errorf("Repeated = %v, Alpha = %v, want true and %v", comparison.Repeated, comparison.Alpha, DefaultAlpha)
+	}
+
+	if len(comparison.Tasks) != 2 {
+		t.Fatalf("expected the 2 tasks in both runs, got %+v", comparison.Tasks)
+	}
+	regressed := comparison.Tasks[0]
+	if regressed.TaskID != "regressed-id" || regressed.BasePassed != 5 || regressed.HeadPassed != 0 || regressed.Change() != -1 {
+		t.Errorf("unexpected regressed task: %+v", regressed)
+	}
+	if !regressed.Significant || math.Abs(regressed.PValue-2.0/252) > 1e-9 {
+		t.Errorf("expected 5/5 to 0/5 to be significant, got p=%v", regressed.PValue)
+	}
+	flaky := comparison.Tasks[1]
+	if flaky.TaskName != "flaky" || flaky.HeadRuns != 5 || flaky.Significant || flaky.PValue < 0.99 {
+		t.Errorf("expected 3/5 to 2/5 not to be significant, got %+v", flaky)
+	}
+
+	overall := comparison.Overall
+	if overall.BaseRuns != 15 || overall.BasePassed != 13 || overall.HeadRuns != 15 || overall.HeadPassed != 2 {
+		t.Errorf("unexpected overall counts: %+v", overall)
+	}
+	if !overall.Significant || overall.HeadInterval.High >= overall.BaseInterval.High {
+		t.Errorf("unexpected overall comparison: %+v", overall)
+	}
+
+	single := CompareRuns(runs("", "task", 1, 0), runs("", "task", 0, 1), DefaultAlpha)
+	if single.Repeated || single.Tasks[0].Significant {
+		t.Errorf("expected a single run not to be significant, got %+v", single)
+	}
+}
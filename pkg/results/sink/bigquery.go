@@ -0,0 +1,149 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+// bigQueryWriter streams records into a BigQuery table with the insertAll
+// API, creating the table on the first write if it doesn't exist.
+type bigQueryWriter struct {
+	service                 *bigquery.Service
+	project, dataset, table string
+
+	mu      sync.Mutex
+	created bool
+}
+
+func newBigQueryWriter(ctx context.Context, cfg *eval.ResultsSinkConfig, opts ...option.ClientOption) (*bigQueryWriter, error) {
+	service, err := bigquery.NewService(ctx, append([]option.ClientOption{option.WithScopes(bigquery.BigqueryInsertdataScope, bigquery.BigqueryScope)}, opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create BigQuery client: %w", err)
+	}
+	return &bigQueryWriter{service: service, project: cfg.Project, dataset: cfg.Dataset, table: cfg.Table}, nil
+}
+
+func (w *bigQueryWriter) write(ctx context.Context, records []Record) error {
+	if err := w.ensureTable(ctx); err != nil {
+		return err
+	}
+
+	request := &bigquery.TableDataInsertAllRequest{}
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to encode record: %w", err)
+		}
+		var row map[string]bigquery.JsonValue
+		if err := json.Unmarshal(data, &row); err != nil {
+			return fmt.Errorf("failed to encode record: %w", err)
+		}
+		// The insert ID lets BigQuery drop rows sent twice by retries
+		request.Rows = append(request.Rows, &bigquery.TableDataInsertAllRequestRows{
+			InsertId: fmt.Sprintf("%s-%d", record.RunID, record.Seq),
+			Json:     row,
+		})
+	}
+
+	response, err := w.service.Tabledata.InsertAll(w.project, w.dataset, w.table, request).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	if len(response.InsertErrors) > 0 {
+		var messages []string
+		for _, insertError := range response.InsertErrors {
+			for _, e := range insertError.Errors {
+				messages = append(messages, fmt.Sprintf("row %d: %s", insertError.Index, e.Message))
+			}
+		}
+		return fmt.Errorf("%d row(s) were rejected: %s", len(response.InsertErrors), strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+// ensureTable creates the table with the schema of Record if it doesn't exist.
+func (w *bigQueryWriter) ensureTable(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.created {
+		return nil
+	}
+
+	_, err := w.service.Tables.Get(w.project, w.dataset, w.table).Context(ctx).Do()
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		fields, schemaErr := bigQuerySchema(reflect.TypeFor[Record]())
+		if schemaErr != nil {
+			return fmt.Errorf("failed to create table %s.%s.%s: %w", w.project, w.dataset, w.table, schemaErr)
+		}
+		table := &bigquery.Table{
+			TableReference: &bigquery.TableReference{ProjectId: w.project, DatasetId: w.dataset, TableId: w.table},
+			Schema:         &bigquery.TableSchema{Fields: fields},
+		}
+		_, err = w.service.Tables.Insert(w.project, w.dataset, table).Context(ctx).Do()
+		if err != nil {
+			err = fmt.Errorf("failed to create table %s.%s.%s: %w", w.project, w.dataset, w.table, err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	w.created = true
+	return nil
+}
+
+// bigQuerySchema returns the columns of the JSON fields of t, a struct, with
+// embedded structs flattened like encoding/json does. It fails if a field has
+// no BigQuery column type.
+func bigQuerySchema(t reflect.Type) ([]*bigquery.TableFieldSchema, error) {
+	var fields []*bigquery.TableFieldSchema
+	for field := range t.Fields() {
+		if field.Anonymous {
+			embedded, err := bigQuerySchema(field.Type)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, embedded...)
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		var columnType string
+		switch {
+		case fieldType == reflect.TypeFor[time.Time]():
+			columnType = "TIMESTAMP"
+		case fieldType.Kind() == reflect.String:
+			columnType = "STRING"
+		case fieldType.Kind() == reflect.Bool:
+			columnType = "BOOLEAN"
+		case fieldType.Kind() == reflect.Int64 || fieldType.Kind() == reflect.Int:
+			columnType = "INTEGER"
+		case fieldType.Kind() == reflect.Float64:
+			columnType = "FLOAT"
+		default:
+			return nil, fmt.Errorf("no BigQuery type for field %s of type %s", field.Name, field.Type)
+		}
+		fields = append(fields, &bigquery.TableFieldSchema{Name: name, Type: columnType, Mode: "NULLABLE"})
+	}
+	return fields, nil
+}
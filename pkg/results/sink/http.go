@@ -0,0 +1,56 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+)
+
+// httpWriter POSTs records as NDJSON, one JSON object per line, which bulk
+// endpoints such as ClickHouse's JSONEachRow format accept.
+type httpWriter struct {
+	client  *http.Client
+	url     string
+	headers map[string]string
+}
+
+func newHTTPWriter(cfg *eval.ResultsSinkConfig) *httpWriter {
+	return &httpWriter{client: &http.Client{}, url: cfg.URL, headers: cfg.Headers}
+}
+
+func (w *httpWriter) write(ctx context.Context, records []Record) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to encode record: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	for name, value := range w.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sink returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+// Package sink streams records of task runs to data warehouses as a run
+// progresses, as configured by the resultsSink of the eval config.
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/results"
+)
+
+// DefaultBatchSize is the number of records sent at once if the config
+// doesn't set one.
+const DefaultBatchSize = 50
+
+// sendTimeout bounds the time to send a batch of records.
+const sendTimeout = 30 * time.Second
+
+// Record is the record of a task run sent to sinks: the flat row of the run,
+// with the run of the eval that it belongs to.
+type Record struct {
+	// RunID identifies the run of the eval, like the runId of its summary,
+	// and is random for journal entries without one
+	RunID        string    `json:"run_id"`
+	RunStartedAt time.Time `json:"run_started_at"`
+	Eval         string    `json:"eval"`
+	// Metadata is the metadata of the run (check --metadata) as a JSON object
+	Metadata string `json:"metadata"`
+	// Seq is the order in which the records of a run were sent, from 0
+	Seq int64 `json:"seq"`
+
+	results.Row
+}
+
+// Sink batches the records of the task runs of a run and sends them to a
+// warehouse from a background goroutine, so that task runs don't wait for the
+// warehouse. It implements eval.ResultsSink, and is safe for concurrent use.
+// Close must be called to send the remaining records and stop the goroutine.
+type Sink struct {
+	evalName  string
+	batchSize int
+	send      func(ctx context.Context, records []Record) error
+
+	mu        sync.Mutex
+	runID     string
+	startedAt time.Time
+	summary   *eval.EvalSummary
+	metadata  string
+	seq       int64
+	pending   []Record
+	// batches are the batches waiting to be sent, in order, and errs the
+	// errors of sending batches that weren't returned yet
+	batches [][]Record
+	errs    []error
+	closed  bool
+
+	wake chan struct{}
+	done chan struct{}
+}
+
+var _ eval.ResultsSink = &Sink{}
+
+// New returns the sink of cfg for the runs of the eval named evalName.
+// BigQuery sinks authenticate with Application Default Credentials.
+func New(ctx context.Context, cfg *eval.ResultsSinkConfig, evalName string) (*Sink, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	var send func(ctx context.Context, records []Record) error
+	switch cfg.Type {
+	case eval.ResultsSinkHTTP:
+		send = newHTTPWriter(cfg).write
+	case eval.ResultsSinkBigQuery:
+		w, err := newBigQueryWriter(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		send = w.write
+	}
+	return newSink(cfg, evalName, send), nil
+}
+
+func newSink(cfg *eval.ResultsSinkConfig, evalName string, send func(ctx context.Context, records []Record) error) *Sink {
+	batchSize := cfg.BatchSize
+	if batchSize == 0 {
+		batchSize = DefaultBatchSize
+	}
+	s := &Sink{
+		evalName:  evalName,
+		batchSize: batchSize,
+		send:      send,
+		wake:      make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Write starts a new run on JournalStart entries, queues the record of the
+// result of JournalResult entries, and queues the records for sending once a
+// batch is full or the run completes. It returns the errors of sending
+// earlier batches that weren't returned yet.
+func (s *Sink) Write(entry eval.JournalEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch entry.Type {
+	case eval.JournalStart:
+		s.runID = entry.RunID
+		if s.runID == "" {
+			s.runID = uuid.NewString()
+		}
+		s.startedAt = entry.Time.UTC().Truncate(time.Microsecond)
+		s.summary = entry.Summary
+		s.metadata = ""
+		if entry.Summary != nil && len(entry.Summary.Metadata) > 0 {
+			data, _ := json.Marshal(entry.Summary.Metadata)
+			s.metadata = string(data)
+		}
+		s.seq = 0
+	case eval.JournalResult:
+		if entry.Result != nil {
+			s.pending = append(s.pending, Record{
+				RunID:        s.runID,
+				RunStartedAt: s.startedAt,
+				Eval:         s.evalName,
+				Metadata:     s.metadata,
+				Seq:          s.seq,
+				Row:          results.NewRow(s.summary, entry.Result),
+			})
+			s.seq++
+		}
+		if len(s.pending) >= s.batchSize {
+			s.queuePendingLocked()
+		}
+	case eval.JournalComplete:
+		s.queuePendingLocked()
+	}
+
+	return s.takeErrorsLocked()
+}
+
+// Close sends the queued records, e.g. of a run that failed before it
+// completed, waits for all records to be sent and returns the errors of
+// sending them that Write didn't return.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	s.queuePendingLocked()
+	s.closed = true
+	s.mu.Unlock()
+	s.wakeUp()
+
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.takeErrorsLocked()
+}
+
+// run sends the queued batches in order until the sink is closed.
+func (s *Sink) run() {
+	defer close(s.done)
+	for {
+		s.mu.Lock()
+		var batch []Record
+		if len(s.batches) > 0 {
+			batch = s.batches[0]
+			s.batches = s.batches[1:]
+		}
+		closed := s.closed
+		s.mu.Unlock()
+
+		if batch == nil {
+			if closed {
+				return
+			}
+			<-s.wake
+			continue
+		}
+
+		if err := s.sendBatch(batch); err != nil {
+			s.mu.Lock()
+			s.errs = append(s.errs, err)
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *Sink) queuePendingLocked() {
+	if len(s.pending) == 0 {
+		return
+	}
+	s.batches = append(s.batches, s.pending)
+	s.pending = nil
+	s.wakeUp()
+}
+
+// wakeUp tells run that there are batches to send or that the sink was closed.
+func (s *Sink) wakeUp() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Sink) takeErrorsLocked() error {
+	err := errors.Join(s.errs...)
+	s.errs = nil
+	return err
+}
+
+func (s *Sink) sendBatch(batch []Record) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	if err := s.send(ctx, batch); err != nil {
+		return fmt.Errorf("failed to send %d record(s): %w", len(batch), err)
+	}
+	return nil
+}
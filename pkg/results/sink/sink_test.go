@@ -0,0 +1,247 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"google.golang.org/api/option"
+)
+
+func TestSinkBatches(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]Record
+	send := func(ctx context.Context, records []Record) error {
+		mu.Lock()
+		defer mu.Unlock()
+		batches = append(batches, records)
+		return nil
+	}
+	s := newSink(&eval.ResultsSinkConfig{BatchSize: 2}, "k8s", send)
+
+	started := time.Date(2026, 10, 1, 12, 0, 0, 123456789, time.UTC)
+	summary := &eval.EvalSummary{
+		Agent:    &eval.AgentSummary{Type: "builtin.claude-code", Model: "claude"},
+		Metadata: map[string]string{"branch": "main"},
+	}
+	writes := []eval.JournalEntry{
+		{Type: eval.JournalStart, Time: started, RunID: "run-1", Summary: summary},
+		{Type: eval.JournalResult, Result: &eval.EvalResult{TaskName: "a", TaskPassed: true}},
+		{Type: eval.JournalResult, Result: &eval.EvalResult{TaskName: "b"}},
+		{Type: eval.JournalResult, Result: &eval.EvalResult{TaskName: "c"}},
+	}
+	for _, entry := range writes {
+		if err := s.Write(entry); err != nil {
+			t.Fatalf("Write(%s): %v", entry.Type, err)
+		}
+	}
+	if err := s.Write(eval.JournalEntry{Type: eval.JournalComplete}); err != nil {
+		t.Fatalf("Write(complete): %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close(): %v", err)
+	}
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 || batches[1][0].Task != "c" {
+		t.Fatalf("expected a full batch of 2 records and the remaining record on completion, got %+v", batches)
+	}
+
+	first := batches[0][0]
+	if first.RunID != "run-1" || first.Eval != "k8s" || first.Seq != 0 || batches[1][0].Seq != 2 {
+		t.Errorf("unexpected run fields: %+v", first)
+	}
+	if !first.RunStartedAt.Equal(started.Truncate(time.Microsecond)) {
+		t.Errorf("RunStartedAt = %v, want %v truncated to microseconds", first.RunStartedAt, started)
+	}
+	if first.Metadata != `{"branch":"main"}` || first.Agent != "builtin.claude-code" || first.Model != "claude" || !first.Passed {
+		t.Errorf("unexpected record: %+v", first)
+	}
+	if batches[1][0].RunID != first.RunID {
+		t.Errorf("expected the records of a run to share its ID")
+	}
+}
+
+func TestSinkSendsInBackground(t *testing.T) {
+	release := make(chan struct{})
+	sent := make(chan []Record, 2)
+	s := newSink(&eval.ResultsSinkConfig{BatchSize: 1}, "k8s", func(ctx context.Context, records []Record) error {
+		<-release
+		sent <- records
+		if records[0].Task == "a" {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	_ = s.Write(eval.JournalEntry{Type: eval.JournalStart})
+	for _, task := range []string{"a", "b"} {
+		if err := s.Write(eval.JournalEntry{Type: eval.JournalResult, Result: &eval.EvalResult{TaskName: task}}); err != nil {
+			t.Fatalf("Write(%s): %v", task, err)
+		}
+	}
+	select {
+	case records := <-sent:
+		t.Fatalf("expected Write not to wait for sending, got %+v", records)
+	default:
+	}
+
+	close(release)
+	err := s.Close()
+	if err == nil || err.Error() != "failed to send 1 record(s): connection refused" {
+		t.Errorf("Close() = %v", err)
+	}
+	if a, b := <-sent, <-sent; a[0].Task != "a" || b[0].Task != "b" {
+		t.Errorf("expected the batches to be sent in order, got %+v and %+v", a, b)
+	}
+}
+
+func TestSinkReportsSendErrors(t *testing.T) {
+	s := newSink(&eval.ResultsSinkConfig{}, "k8s", func(ctx context.Context, records []Record) error {
+		return errors.New("connection refused")
+	})
+	_ = s.Write(eval.JournalEntry{Type: eval.JournalStart})
+	_ = s.Write(eval.JournalEntry{Type: eval.JournalResult, Result: &eval.EvalResult{TaskName: "a"}})
+
+	err := s.Close()
+	if err == nil || err.Error() != "failed to send 1 record(s): connection refused" {
+		t.Errorf("Close() = %v", err)
+	}
+}
+
+func TestHTTPWriter(t *testing.T) {
+	var lines []map[string]any
+	var contentType, auth string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		auth = r.Header.Get("Authorization")
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var line map[string]any
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				t.Errorf("invalid NDJSON line %q: %v", scanner.Text(), err)
+			}
+			lines = append(lines, line)
+		}
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, "table is read-only\n")
+	}))
+	defer server.Close()
+
+	w := newHTTPWriter(&eval.ResultsSinkConfig{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}})
+	records := []Record{{RunID: "run", Seq: 0}, {RunID: "run", Seq: 1}}
+	records[0].Task = "a"
+	records[1].Task = "b"
+
+	if err := w.write(context.Background(), records); err != nil {
+		t.Fatalf("write(): %v", err)
+	}
+	if contentType != "application/x-ndjson" || auth != "Bearer token" {
+		t.Errorf("Content-Type = %q, Authorization = %q", contentType, auth)
+	}
+	if len(lines) != 2 || lines[1]["task"] != "b" || lines[1]["seq"] != 1.0 || lines[0]["run_id"] != "run" {
+		t.Errorf("unexpected records: %+v", lines)
+	}
+
+	status = http.StatusForbidden
+	err := w.write(context.Background(), records)
+	if err == nil || err.Error() != "sink returned 403 Forbidden: table is read-only" {
+		t.Errorf("write() = %v", err)
+	}
+}
+
+func TestBigQuerySchemaUnmappedType(t *testing.T) {
+	type row struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	_, err := bigQuerySchema(reflect.TypeFor[row]())
+	if err == nil || !strings.Contains(err.Error(), "no BigQuery type for field Tags") {
+		t.Errorf("bigQuerySchema() = %v, want an error for the Tags field", err)
+	}
+}
+
+func TestBigQueryWriter(t *testing.T) {
+	var requests []string
+	var table map[string]any
+	var insert struct {
+		Rows []struct {
+			InsertID string         `json:"insertId"`
+			JSON     map[string]any `json:"json"`
+		} `json:"rows"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/tables/runs"):
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"error": {"code": 404, "message": "Not found: Table data:evals.runs"}}`)
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/datasets/evals/tables"):
+			_ = json.NewDecoder(r.Body).Decode(&table)
+			_, _ = io.WriteString(w, `{}`)
+		case strings.HasSuffix(r.URL.Path, "/insertAll"):
+			_ = json.NewDecoder(r.Body).Decode(&insert)
+			if len(insert.Rows) > 1 {
+				_, _ = io.WriteString(w, `{"insertErrors": [{"index": 1, "errors": [{"message": "no such field: extra"}]}]}`)
+				return
+			}
+			_, _ = io.WriteString(w, `{}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &eval.ResultsSinkConfig{Type: eval.ResultsSinkBigQuery, Project: "data", Dataset: "evals", Table: "runs"}
+	w, err := newBigQueryWriter(context.Background(), cfg, option.WithEndpoint(server.URL+"/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("newBigQueryWriter(): %v", err)
+	}
+
+	record := Record{RunID: "run", Seq: 3, RunStartedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	record.Task = "a"
+	if err := w.write(context.Background(), []Record{record}); err != nil {
+		t.Fatalf("write(): %v", err)
+	}
+	if err := w.write(context.Background(), []Record{record, record}); err == nil || !strings.Contains(err.Error(), "row 1: no such field: extra") {
+		t.Errorf("expected rejected rows to be reported, got %v", err)
+	}
+
+	want := []string{
+		"GET /projects/data/datasets/evals/tables/runs",
+		"POST /projects/data/datasets/evals/tables",
+		"POST /projects/data/datasets/evals/tables/runs/insertAll",
+		"POST /projects/data/datasets/evals/tables/runs/insertAll",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests =\n%s\nwant\n%s", strings.Join(requests, "\n"), strings.Join(want, "\n"))
+	}
+
+	if insert.Rows[0].InsertID != "run-3" || insert.Rows[0].JSON["task"] != "a" || insert.Rows[0].JSON["run_started_at"] != "2026-10-01T12:00:00Z" {
+		t.Errorf("unexpected row: %+v", insert.Rows[0])
+	}
+
+	columns := make(map[string]string)
+	for _, field := range table["schema"].(map[string]any)["fields"].([]any) {
+		f := field.(map[string]any)
+		columns[f["name"].(string)] = f["type"].(string)
+	}
+	for name, columnType := range map[string]string{
+		"run_id": "STRING", "run_started_at": "TIMESTAMP", "seq": "INTEGER",
+		"task": "STRING", "passed": "BOOLEAN", "score": "FLOAT", "duration_ms": "INTEGER",
+	} {
+		if columns[name] != columnType {
+			t.Errorf("column %s has type %q, want %s", name, columns[name], columnType)
+		}
+	}
+}
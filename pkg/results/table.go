@@ -0,0 +1,211 @@
+package results
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/parquet-go/parquet-go"
+)
+
+// Row is the flat record of a task run exported by 'mcpchecker export
+// --format csv|parquet', for analysis in tools such as pandas, and sent to
+// results sinks. Columns are named after the parquet tags, and are the same
+// in all formats.
+type Row struct {
+	Task       string `parquet:"task" json:"task"`
+	TaskID     string `parquet:"task_id" json:"task_id"`
+	TaskPath   string `parquet:"task_path" json:"task_path"`
+	Run        int64  `parquet:"run" json:"run"`
+	Agent      string `parquet:"agent" json:"agent"`
+	Model      string `parquet:"model" json:"model"`
+	Difficulty string `parquet:"difficulty" json:"difficulty"`
+	// Labels are the labels of the task as "key=value" pairs sorted by key and
+	// separated by commas, like a label selector
+	Labels string `parquet:"labels" json:"labels"`
+	State  string `parquet:"state" json:"state"`
+
+	Passed     bool   `parquet:"passed" json:"passed"`
+	Skipped    bool   `parquet:"skipped" json:"skipped"`
+	SkipReason string `parquet:"skip_reason" json:"skip_reason"`
+	ErrorKind  string `parquet:"error_kind" json:"error_kind"`
+	TimedOut   bool   `parquet:"timed_out" json:"timed_out"`
+
+	AssertionsPassed int64 `parquet:"assertions_passed" json:"assertions_passed"`
+	AssertionsTotal  int64 `parquet:"assertions_total" json:"assertions_total"`
+	// Score is the fraction of the assertions that passed, or nil if the
+	// task has no assertions
+	Score *float64 `parquet:"score,optional" json:"score"`
+
+	// InputTokens, OutputTokens and TotalTokens are the tokens used by the
+	// agent as reported by it, or estimated otherwise if TokensEstimated
+	InputTokens       int64 `parquet:"input_tokens" json:"input_tokens"`
+	OutputTokens      int64 `parquet:"output_tokens" json:"output_tokens"`
+	TotalTokens       int64 `parquet:"total_tokens" json:"total_tokens"`
+	TokensEstimated   bool  `parquet:"tokens_estimated" json:"tokens_estimated"`
+	JudgeInputTokens  int64 `parquet:"judge_input_tokens" json:"judge_input_tokens"`
+	JudgeOutputTokens int64 `parquet:"judge_output_tokens" json:"judge_output_tokens"`
+
+	DurationMs int64 `parquet:"duration_ms" json:"duration_ms"`
+
+	ToolCalls       int64 `parquet:"tool_calls" json:"tool_calls"`
+	FailedToolCalls int64 `parquet:"failed_tool_calls" json:"failed_tool_calls"`
+	DistinctTools   int64 `parquet:"distinct_tools" json:"distinct_tools"`
+	ResourceReads   int64 `parquet:"resource_reads" json:"resource_reads"`
+	PromptGets      int64 `parquet:"prompt_gets" json:"prompt_gets"`
+
+	AllowedToolsMode string `parquet:"allowed_tools_mode" json:"allowed_tools_mode"`
+	PromptVariant    int64  `parquet:"prompt_variant" json:"prompt_variant"`
+	Locale           string `parquet:"locale" json:"locale"`
+}
+
+// Rows flattens the results of output into one row per task run, with the
+// agent and model of its summary.
+func Rows(output *eval.EvalOutput) []Row {
+	rows := make([]Row, 0, len(output.Results))
+	for _, r := range output.Results {
+		rows = append(rows, NewRow(output.Summary, r))
+	}
+	return rows
+}
+
+// NewRow flattens the result of a task run, with the agent and model of the
+// summary of its run, which may be nil.
+func NewRow(summary *eval.EvalSummary, r *eval.EvalResult) Row {
+	row := Row{
+		Task:             r.TaskName,
+		TaskID:           r.TaskID,
+		TaskPath:         r.TaskPath,
+		Run:              int64(r.RunIndex),
+		Difficulty:       r.Difficulty,
+		Labels:           formatLabels(r.Labels),
+		State:            r.State,
+		Passed:           r.TaskPassed,
+		Skipped:          r.Skipped,
+		SkipReason:       string(SkipReason(r)),
+		TimedOut:         r.TimedOut,
+		AssertionsPassed: int64(PassedAssertions(r)),
+		AssertionsTotal:  int64(TotalAssertions(r)),
+		DurationMs:       r.DurationMs,
+		AllowedToolsMode: string(r.AllowedToolsMode),
+		PromptVariant:    int64(r.PromptVariant),
+		Locale:           r.Locale,
+	}
+	row.Agent, row.Model = agentOf(summary)
+	if !r.TaskPassed {
+		row.ErrorKind = string(ErrorKind(r))
+	}
+	if row.AssertionsTotal > 0 {
+		score := float64(row.AssertionsPassed) / float64(row.AssertionsTotal)
+		row.Score = &score
+	}
+
+	if r.TokenEstimate != nil {
+		usage := r.TokenEstimate.ToUsage()
+		row.InputTokens = usage.InputTokens
+		row.OutputTokens = usage.OutputTokens
+		row.TotalTokens = usage.TotalTokens
+		row.TokensEstimated = r.TokenEstimate.Actual == nil
+	}
+	if r.JudgeTokenUsage != nil {
+		row.JudgeInputTokens = r.JudgeTokenUsage.InputTokens
+		row.JudgeOutputTokens = r.JudgeTokenUsage.OutputTokens
+	}
+
+	if r.CallHistory != nil {
+		tools := make(map[string]bool)
+		for _, call := range r.CallHistory.ToolCalls {
+			row.ToolCalls++
+			if !call.Success {
+				row.FailedToolCalls++
+			}
+			tools[call.ServerName+"/"+call.ToolName] = true
+		}
+		row.DistinctTools = int64(len(tools))
+		row.ResourceReads = int64(len(r.CallHistory.ResourceReads))
+		row.PromptGets = int64(len(r.CallHistory.PromptGets))
+	}
+
+	return row
+}
+
+// agentOf returns the agent of summary, by its name or else its type, and
+// its model.
+func agentOf(summary *eval.EvalSummary) (agent, model string) {
+	if summary == nil || summary.Agent == nil {
+		return "", ""
+	}
+	agent = summary.Agent.Name
+	if agent == "" {
+		agent = summary.Agent.Type
+	}
+	return agent, summary.Agent.Model
+}
+
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		pairs = append(pairs, key+"="+labels[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// WriteCSV writes rows as CSV with a header row. Booleans are written as
+// true or false, and a missing score as an empty field.
+func WriteCSV(w io.Writer, rows []Row) error {
+	writer := csv.NewWriter(w)
+	header := make([]string, 0, len(rowSchema.Fields()))
+	for _, field := range rowSchema.Fields() {
+		header = append(header, field.Name())
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		score := ""
+		if row.Score != nil {
+			score = strconv.FormatFloat(*row.Score, 'f', -1, 64)
+		}
+		record := []string{
+			row.Task, row.TaskID, row.TaskPath, itoa(row.Run), row.Agent, row.Model, row.Difficulty, row.Labels, row.State,
+			strconv.FormatBool(row.Passed), strconv.FormatBool(row.Skipped), row.SkipReason, row.ErrorKind, strconv.FormatBool(row.TimedOut),
+			itoa(row.AssertionsPassed), itoa(row.AssertionsTotal), score,
+			itoa(row.InputTokens), itoa(row.OutputTokens), itoa(row.TotalTokens), strconv.FormatBool(row.TokensEstimated),
+			itoa(row.JudgeInputTokens), itoa(row.JudgeOutputTokens),
+			itoa(row.DurationMs),
+			itoa(row.ToolCalls), itoa(row.FailedToolCalls), itoa(row.DistinctTools), itoa(row.ResourceReads), itoa(row.PromptGets),
+			row.AllowedToolsMode, itoa(row.PromptVariant), row.Locale,
+		}
+		if len(record) != len(header) {
+			// Guards against a column added to Row but not here
+			return fmt.Errorf("CSV record has %d fields, want %d", len(record), len(header))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func itoa(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
+
+var rowSchema = parquet.SchemaOf(Row{})
+
+// WriteParquet writes rows as a Parquet file.
+func WriteParquet(w io.Writer, rows []Row) error {
+	writer := parquet.NewGenericWriter[Row](w)
+	if _, err := writer.Write(rows); err != nil {
+		return err
+	}
+	return writer.Close()
+}
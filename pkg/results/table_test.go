@@ -0,0 +1,150 @@
+package results
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/eval"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/tokens"
+	"github.com/parquet-go/parquet-go"
+)
+
+func tableOutput() *eval.EvalOutput {
+	return &eval.EvalOutput{
+		Summary: &eval.EvalSummary{Agent: &eval.AgentSummary{Type: "builtin.llm-agent", Model: "openai:gpt-5"}},
+		Results: []*eval.EvalResult{
+			{
+				TaskID:     "pods-create",
+				TaskName:   "create pod",
+				TaskPath:   "tasks/create-pod.yaml",
+				RunIndex:   1,
+				Difficulty: "easy",
+				Labels:     map[string]string{"suite": "k8s", "area": "pods"},
+				TaskPassed: true,
+				DurationMs: 1500,
+				AssertionResults: &eval.CompositeAssertionResult{
+					ToolsUsed:    &eval.SingleAssertionResult{Passed: true},
+					MinToolCalls: &eval.SingleAssertionResult{Passed: false},
+				},
+				TokenEstimate:   &tokens.Estimate{InputTokens: 90, OutputTokens: 10, TotalTokens: 100, Actual: &tokens.Usage{InputTokens: 80, OutputTokens: 20, TotalTokens: 100}},
+				JudgeTokenUsage: &tokens.Usage{InputTokens: 30, OutputTokens: 5},
+				CallHistory: &mcpproxy.CallHistory{
+					ToolCalls: []*mcpproxy.ToolCall{
+						{CallRecord: mcpproxy.CallRecord{ServerName: "kube", Success: true}, ToolName: "pods_create"},
+						{CallRecord: mcpproxy.CallRecord{ServerName: "kube"}, ToolName: "pods_create"},
+						{CallRecord: mcpproxy.CallRecord{ServerName: "kube", Success: true}, ToolName: "pods_get"},
+					},
+					ResourceReads: []*mcpproxy.ResourceRead{{}},
+				},
+			},
+			{
+				TaskName:      "list pods",
+				Difficulty:    "hard",
+				TaskError:     "skipped: over budget",
+				Skipped:       true,
+				SkipReason:    eval.SkipReasonBudgetExceeded,
+				TokenEstimate: &tokens.Estimate{InputTokens: 7, OutputTokens: 3, TotalTokens: 10},
+			},
+		},
+	}
+}
+
+func TestRows(t *testing.T) {
+	rows := Rows(tableOutput())
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+
+	score := 0.5
+	want := Row{
+		Task:              "create pod",
+		TaskID:            "pods-create",
+		TaskPath:          "tasks/create-pod.yaml",
+		Run:               1,
+		Agent:             "builtin.llm-agent",
+		Model:             "openai:gpt-5",
+		Difficulty:        "easy",
+		Labels:            "area=pods,suite=k8s",
+		Passed:            true,
+		AssertionsPassed:  1,
+		AssertionsTotal:   2,
+		Score:             &score,
+		InputTokens:       80,
+		OutputTokens:      20,
+		TotalTokens:       100,
+		JudgeInputTokens:  30,
+		JudgeOutputTokens: 5,
+		DurationMs:        1500,
+		ToolCalls:         3,
+		FailedToolCalls:   1,
+		DistinctTools:     2,
+		ResourceReads:     1,
+	}
+	if !reflect.DeepEqual(rows[0], want) {
+		t.Errorf("Rows()[0] =\n%+v\nwant\n%+v", rows[0], want)
+	}
+
+	skipped := rows[1]
+	if !skipped.Skipped || skipped.SkipReason != string(eval.SkipReasonBudgetExceeded) || skipped.Score != nil {
+		t.Errorf("unexpected skipped row: %+v", skipped)
+	}
+	if skipped.TotalTokens != 10 || !skipped.TokensEstimated {
+		t.Errorf("expected estimated tokens, got %+v", skipped)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, Rows(tableOutput())); err != nil {
+		t.Fatalf("WriteCSV(): %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected a header and 2 rows, got %d records", len(records))
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[name] = i
+	}
+	for column, want := range map[string]string{
+		"task":        "create pod",
+		"labels":      "area=pods,suite=k8s",
+		"passed":      "true",
+		"score":       "0.5",
+		"tool_calls":  "3",
+		"duration_ms": "1500",
+		"model":       "openai:gpt-5",
+	} {
+		if got := records[1][columns[column]]; got != want {
+			t.Errorf("column %s = %q, want %q", column, got, want)
+		}
+	}
+	if got := records[2][columns["score"]]; got != "" {
+		t.Errorf("expected an empty score without assertions, got %q", got)
+	}
+}
+
+func TestWriteParquet(t *testing.T) {
+	rows := Rows(tableOutput())
+
+	var buf bytes.Buffer
+	if err := WriteParquet(&buf, rows); err != nil {
+		t.Fatalf("WriteParquet(): %v", err)
+	}
+
+	read, err := parquet.Read[Row](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("failed to read Parquet: %v", err)
+	}
+	if !reflect.DeepEqual(read, rows) {
+		t.Errorf("read back\n%+v\nwant\n%+v", read, rows)
+	}
+}
@@ -0,0 +1,103 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// awsProvider reads secrets from AWS Secrets Manager. ref.Name is the name or
+// ARN of the secret, and ref.Key selects a field of a secret stored as a JSON
+// object. Credentials and region come from the default AWS configuration
+// chain (AWS_PROFILE, AWS_REGION, ...); AWS_ENDPOINT_URL_SECRETS_MANAGER or
+// AWS_ENDPOINT_URL override the endpoint.
+type awsProvider struct {
+	client *http.Client
+}
+
+func (p *awsProvider) Resolve(ctx context.Context, ref *Ref) (string, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	if cfg.Region == "" {
+		return "", fmt.Errorf("AWS region is not set")
+	}
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get AWS credentials: %w", err)
+	}
+
+	endpoint := os.Getenv("AWS_ENDPOINT_URL_SECRETS_MANAGER")
+	if endpoint == "" && cfg.BaseEndpoint != nil {
+		endpoint = *cfg.BaseEndpoint
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", cfg.Region)
+	}
+
+	body, err := json.Marshal(map[string]string{"SecretId": ref.Name})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+
+	hash := sha256.Sum256(body)
+	if err := v4.NewSigner().SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "secretsmanager", cfg.Region, time.Now()); err != nil {
+		return "", fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	client := p.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var secret struct {
+		SecretString *string `json:"SecretString"`
+		SecretBinary []byte  `json:"SecretBinary"`
+		Type         string  `json:"__type"`
+		Message      string  `json:"message"`
+	}
+	if err := json.Unmarshal(data, &secret); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("invalid response from Secrets Manager: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if secret.Type != "" {
+			// Error types may be prefixed with a namespace
+			errType := secret.Type[strings.LastIndex(secret.Type, "#")+1:]
+			return "", fmt.Errorf("secrets manager returned %s: %s", errType, secret.Message)
+		}
+		return "", fmt.Errorf("secrets manager returned %s", resp.Status)
+	}
+
+	if secret.SecretString != nil {
+		return selectKey(*secret.SecretString, ref.Key)
+	}
+	return selectKey(string(secret.SecretBinary), ref.Key)
+}
@@ -0,0 +1,75 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWSProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDTEST/"), "request should be signed")
+		assert.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/secretsmanager/aws4_request")
+
+		var body struct{ SecretId string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		switch body.SecretId {
+		case "mcpchecker/token":
+			_, _ = w.Write([]byte(`{"Name":"mcpchecker/token","SecretString":"aws-token-3f6e"}`))
+		case "mcpchecker/db":
+			_, _ = w.Write([]byte(`{"Name":"mcpchecker/db","SecretString":"{\"password\":\"aws-password-a92c\"}"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"com.amazonaws.secretsmanager#ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`))
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDTEST")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test-secret-key")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_ENDPOINT_URL_SECRETS_MANAGER", server.URL)
+
+	tests := map[string]struct {
+		ref         *Ref
+		expected    string
+		errContains string
+	}{
+		"secret string": {
+			ref:      &Ref{Provider: "aws", Name: "mcpchecker/token"},
+			expected: "aws-token-3f6e",
+		},
+		"json secret with key": {
+			ref:      &Ref{Provider: "aws", Name: "mcpchecker/db", Key: "password"},
+			expected: "aws-password-a92c",
+		},
+		"not found": {
+			ref:         &Ref{Provider: "aws", Name: "mcpchecker/missing"},
+			errContains: "secrets manager returned ResourceNotFoundException: Secrets Manager can't find the specified secret.",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			value, err := NewResolver("").Resolve(context.Background(), tc.ref)
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, value)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// envProvider reads secrets from the environment variable ref.Name.
+type envProvider struct{}
+
+func (envProvider) Resolve(_ context.Context, ref *Ref) (string, error) {
+	value, ok := os.LookupEnv(ref.Name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", ref.Name)
+	}
+	return selectKey(value, ref.Key)
+}
+
+// fileProvider reads secrets from the file ref.Name, e.g. a mounted
+// Kubernetes secret. A trailing newline is removed.
+type fileProvider struct {
+	basePath string
+}
+
+func (p fileProvider) Resolve(_ context.Context, ref *Ref) (string, error) {
+	path := ref.Name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.basePath, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+	return selectKey(value, ref.Key)
+}
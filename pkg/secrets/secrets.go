@@ -0,0 +1,249 @@
+// Package secrets resolves secretRef values in configuration files from
+// secret providers, such as environment variables, files, HashiCorp Vault and
+// AWS Secrets Manager.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// RefKey is the key of a secret reference. A mapping whose only key is
+// secretRef is replaced by the value of the secret:
+//
+//	env:
+//	  API_TOKEN:
+//	    secretRef:
+//	      provider: vault
+//	      name: secret/data/mcpchecker
+//	      key: token
+const RefKey = "secretRef"
+
+// Ref references a secret held by a provider.
+type Ref struct {
+	// Provider is the name of the provider, e.g. "env" or "vault"
+	Provider string `json:"provider"`
+
+	// Name identifies the secret within the provider, e.g. the name of an
+	// environment variable or the path of a Vault secret
+	Name string `json:"name"`
+
+	// Key selects a field of a secret holding several values, such as a
+	// Vault secret or a JSON object
+	Key string `json:"key,omitempty"`
+}
+
+// Validate checks that the provider and name are set.
+func (r *Ref) Validate() error {
+	if r.Provider == "" {
+		return fmt.Errorf("provider must be set")
+	}
+	if r.Name == "" {
+		return fmt.Errorf("name must be set")
+	}
+	return nil
+}
+
+func (r *Ref) String() string {
+	s := r.Provider + ":" + r.Name
+	if r.Key != "" {
+		s += "#" + r.Key
+	}
+	return s
+}
+
+// Provider resolves secret references to their values.
+type Provider interface {
+	Resolve(ctx context.Context, ref *Ref) (string, error)
+}
+
+// Resolver resolves the secret references of configuration files with its
+// providers. Resolved values are registered with util.RegisterSecret, so that
+// they are redacted from all outputs.
+type Resolver struct {
+	providers map[string]Provider
+}
+
+// NewResolver returns a resolver with the builtin providers: env, file (with
+// relative paths resolved against basePath), vault and aws.
+func NewResolver(basePath string) *Resolver {
+	return &Resolver{
+		providers: map[string]Provider{
+			"env":   envProvider{},
+			"file":  fileProvider{basePath: basePath},
+			"vault": &vaultProvider{},
+			"aws":   &awsProvider{},
+		},
+	}
+}
+
+// Register adds a provider, replacing any provider with the same name.
+func (r *Resolver) Register(name string, provider Provider) {
+	r.providers[name] = provider
+}
+
+// Resolve returns the value of the secret ref and registers it for redaction.
+func (r *Resolver) Resolve(ctx context.Context, ref *Ref) (string, error) {
+	if err := ref.Validate(); err != nil {
+		return "", err
+	}
+
+	provider, ok := r.providers[ref.Provider]
+	if !ok {
+		names := make([]string, 0, len(r.providers))
+		for name := range r.providers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("unknown secret provider %q (must be one of %s)", ref.Provider, strings.Join(names, ", "))
+	}
+
+	value, err := provider.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret %s: %w", ref, err)
+	}
+	util.RegisterSecret(value)
+	return value, nil
+}
+
+// ResolveYAML replaces the secret references in the YAML or JSON document data
+// with their values. It returns data unchanged if it has no references, and
+// the document as JSON otherwise.
+func (r *Resolver) ResolveYAML(ctx context.Context, data []byte) ([]byte, error) {
+	if !bytes.Contains(data, []byte(RefKey)) {
+		return data, nil
+	}
+
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Numbers are kept as written, since the document is encoded again
+	dec := json.NewDecoder(bytes.NewReader(jsonData))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	doc, found, err := r.resolveTree(ctx, doc, "")
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return data, nil
+	}
+	return json.Marshal(doc)
+}
+
+// resolveTree replaces the secret references in the decoded JSON value v.
+// path is the location of v in the document, for error messages.
+func (r *Resolver) resolveTree(ctx context.Context, v any, path string) (any, bool, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		if raw, ok := v[RefKey]; ok {
+			if len(v) > 1 {
+				return nil, false, fmt.Errorf("%s: %s must be the only key", joinPath(path, RefKey), RefKey)
+			}
+			ref, err := toRef(raw)
+			if err != nil {
+				return nil, false, fmt.Errorf("%s: %w", joinPath(path, RefKey), err)
+			}
+			value, err := r.Resolve(ctx, ref)
+			if err != nil {
+				return nil, false, fmt.Errorf("%s: %w", path, err)
+			}
+			return value, true, nil
+		}
+
+		var found bool
+		for key, val := range v {
+			resolved, ok, err := r.resolveTree(ctx, val, joinPath(path, key))
+			if err != nil {
+				return nil, false, err
+			}
+			v[key] = resolved
+			found = found || ok
+		}
+		return v, found, nil
+
+	case []any:
+		var found bool
+		for i, val := range v {
+			resolved, ok, err := r.resolveTree(ctx, val, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, false, err
+			}
+			v[i] = resolved
+			found = found || ok
+		}
+		return v, found, nil
+	}
+
+	return v, false, nil
+}
+
+func toRef(raw any) (*Ref, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	ref := &Ref{}
+	if err := dec.Decode(ref); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", RefKey, err)
+	}
+	return ref, nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// selectKey returns the value of secret, or of its field key if key is set,
+// in which case secret must be a JSON or YAML object.
+func selectKey(secret, key string) (string, error) {
+	if key == "" {
+		return secret, nil
+	}
+
+	var fields map[string]any
+	if err := yaml.Unmarshal([]byte(secret), &fields); err != nil || fields == nil {
+		return "", fmt.Errorf("key %q is set but the secret is not an object", key)
+	}
+	return fieldValue(fields, key)
+}
+
+// fieldValue returns the field key of a secret as a string.
+func fieldValue(fields map[string]any, key string) (string, error) {
+	value, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("secret has no key %q", key)
+	}
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case json.Number:
+		return value.String(), nil
+	default:
+		return "", fmt.Errorf("key %q of the secret is not a string", key)
+	}
+}
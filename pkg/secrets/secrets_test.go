@@ -0,0 +1,165 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+type staticProvider map[string]string
+
+func (p staticProvider) Resolve(_ context.Context, ref *Ref) (string, error) {
+	return selectKey(p[ref.Name], ref.Key)
+}
+
+func TestResolverResolve(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("file-token-9c2e\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "creds.yaml"), []byte("user: admin\npassword: file-password-77d1\n"), 0600))
+	t.Setenv("MCPCHECKER_TEST_SECRET", "env-secret-4b8a")
+	t.Setenv("MCPCHECKER_TEST_JSON", `{"apiKey": "env-key-0e5f", "port": 8443}`)
+
+	tests := map[string]struct {
+		ref         *Ref
+		expected    string
+		errContains string
+	}{
+		"env": {
+			ref:      &Ref{Provider: "env", Name: "MCPCHECKER_TEST_SECRET"},
+			expected: "env-secret-4b8a",
+		},
+		"env with key": {
+			ref:      &Ref{Provider: "env", Name: "MCPCHECKER_TEST_JSON", Key: "apiKey"},
+			expected: "env-key-0e5f",
+		},
+		"env with number key": {
+			ref:      &Ref{Provider: "env", Name: "MCPCHECKER_TEST_JSON", Key: "port"},
+			expected: "8443",
+		},
+		"env unset": {
+			ref:         &Ref{Provider: "env", Name: "MCPCHECKER_TEST_UNSET"},
+			errContains: "failed to resolve secret env:MCPCHECKER_TEST_UNSET: environment variable MCPCHECKER_TEST_UNSET is not set",
+		},
+		"env missing key": {
+			ref:         &Ref{Provider: "env", Name: "MCPCHECKER_TEST_JSON", Key: "token"},
+			errContains: `secret has no key "token"`,
+		},
+		"env key of plain value": {
+			ref:         &Ref{Provider: "env", Name: "MCPCHECKER_TEST_SECRET", Key: "token"},
+			errContains: `key "token" is set but the secret is not an object`,
+		},
+		"relative file": {
+			ref:      &Ref{Provider: "file", Name: "token"},
+			expected: "file-token-9c2e",
+		},
+		"file with key": {
+			ref:      &Ref{Provider: "file", Name: filepath.Join(dir, "creds.yaml"), Key: "password"},
+			expected: "file-password-77d1",
+		},
+		"missing file": {
+			ref:         &Ref{Provider: "file", Name: "missing"},
+			errContains: "no such file or directory",
+		},
+		"registered provider": {
+			ref:      &Ref{Provider: "static", Name: "db"},
+			expected: "static-secret-6a1d",
+		},
+		"unknown provider": {
+			ref:         &Ref{Provider: "gcp", Name: "db"},
+			errContains: `unknown secret provider "gcp" (must be one of aws, env, file, static, vault)`,
+		},
+		"missing name": {
+			ref:         &Ref{Provider: "env"},
+			errContains: "name must be set",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := NewResolver(dir)
+			r.Register("static", staticProvider{"db": "static-secret-6a1d"})
+
+			value, err := r.Resolve(context.Background(), tc.ref)
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, value)
+			assert.Equal(t, util.RedactedSecret, util.RedactSecrets(value), "resolved secrets should be redacted")
+		})
+	}
+}
+
+func TestResolverResolveYAML(t *testing.T) {
+	tests := map[string]struct {
+		data        string
+		expected    string
+		errContains string
+	}{
+		"no references": {
+			data:     "config:\n  count: 10000000000000001\n",
+			expected: "config:\n  count: 10000000000000001\n",
+		},
+		"nested references": {
+			data: `config:
+  count: 10000000000000001
+  agent:
+    headers:
+    - name: Authorization
+      value:
+        secretRef:
+          provider: static
+          name: header
+  env:
+    TOKEN:
+      secretRef: {provider: static, name: token}
+`,
+			expected: `{"config":{"agent":{"headers":[{"name":"Authorization","value":"Bearer yaml-header-2d9c"}]},"count":10000000000000001,"env":{"TOKEN":"yaml-token-81fb"}}}`,
+		},
+		"extra keys": {
+			data: `env:
+  TOKEN:
+    secretRef: {provider: static, name: token}
+    value: plain
+`,
+			errContains: "env.TOKEN.secretRef: secretRef must be the only key",
+		},
+		"unknown field": {
+			data: `env:
+  TOKEN:
+    secretRef: {provider: static, path: token}
+`,
+			errContains: `env.TOKEN.secretRef: invalid secretRef: json: unknown field "path"`,
+		},
+		"failing reference": {
+			data: `items:
+- secretRef: {provider: static, name: token, key: value}
+`,
+			errContains: "items[0]: failed to resolve secret static:token#value",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := NewResolver("")
+			r.Register("static", staticProvider{"header": "Bearer yaml-header-2d9c", "token": "yaml-token-81fb"})
+
+			data, err := r.ResolveYAML(context.Background(), []byte(tc.data))
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(data))
+		})
+	}
+}
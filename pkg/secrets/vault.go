@@ -0,0 +1,101 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// vaultProvider reads secrets from HashiCorp Vault. ref.Name is the API path
+// of the secret, e.g. secret/data/mcpchecker for a KV v2 engine mounted at
+// secret, and ref.Key is the field of the secret. The server and token are
+// taken from VAULT_ADDR and VAULT_TOKEN (or ~/.vault-token), like the vault
+// CLI, and VAULT_NAMESPACE is sent if set.
+type vaultProvider struct {
+	client *http.Client
+}
+
+func (p *vaultProvider) Resolve(ctx context.Context, ref *Ref) (string, error) {
+	if ref.Key == "" {
+		return "", fmt.Errorf("key must be set for vault secrets")
+	}
+
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR is not set")
+	}
+	token, err := vaultToken()
+	if err != nil {
+		return "", err
+	}
+
+	url := strings.TrimSuffix(addr, "/") + "/v1/" + strings.TrimPrefix(ref.Name, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	client := p.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var secret struct {
+		Data   map[string]any `json:"data"`
+		Errors []string       `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("invalid response from vault: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if len(secret.Errors) > 0 {
+			return "", fmt.Errorf("vault returned %s: %s", resp.Status, strings.Join(secret.Errors, "; "))
+		}
+		return "", fmt.Errorf("vault returned %s", resp.Status)
+	}
+
+	// KV v2 secrets nest their fields under data.data, next to the version
+	// metadata
+	fields := secret.Data
+	if inner, ok := fields["data"].(map[string]any); ok {
+		if _, ok := fields["metadata"]; ok {
+			fields = inner
+		}
+	}
+	return fieldValue(fields, ref.Key)
+}
+
+func vaultToken() (string, error) {
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err == nil {
+		if data, err := os.ReadFile(filepath.Join(home, ".vault-token")); err == nil {
+			if token := strings.TrimSpace(string(data)); token != "" {
+				return token, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("VAULT_TOKEN is not set")
+}
@@ -0,0 +1,82 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/mcpchecker":
+			assert.Equal(t, "team-a", r.Header.Get("X-Vault-Namespace"))
+			_, _ = w.Write([]byte(`{"data":{"data":{"token":"vault-kv2-5e7b"},"metadata":{"version":3}}}`))
+		case "/v1/kv/mcpchecker":
+			_, _ = w.Write([]byte(`{"data":{"token":"vault-kv1-c40d"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	tests := map[string]struct {
+		token       string
+		ref         *Ref
+		expected    string
+		errContains string
+	}{
+		"kv v2": {
+			ref:      &Ref{Provider: "vault", Name: "secret/data/mcpchecker", Key: "token"},
+			expected: "vault-kv2-5e7b",
+		},
+		"kv v1": {
+			ref:      &Ref{Provider: "vault", Name: "/kv/mcpchecker", Key: "token"},
+			expected: "vault-kv1-c40d",
+		},
+		"missing key": {
+			ref:         &Ref{Provider: "vault", Name: "kv/mcpchecker"},
+			errContains: "key must be set for vault secrets",
+		},
+		"not found": {
+			ref:         &Ref{Provider: "vault", Name: "kv/missing", Key: "token"},
+			errContains: "vault returned 404 Not Found",
+		},
+		"permission denied": {
+			token:       "wrong",
+			ref:         &Ref{Provider: "vault", Name: "kv/mcpchecker", Key: "token"},
+			errContains: "vault returned 403 Forbidden: permission denied",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			token := tc.token
+			if token == "" {
+				token = "test-token"
+			}
+			t.Setenv("VAULT_ADDR", server.URL+"/")
+			t.Setenv("VAULT_TOKEN", token)
+			t.Setenv("VAULT_NAMESPACE", "team-a")
+
+			value, err := NewResolver("").Resolve(context.Background(), tc.ref)
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, value)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package steps
+
+import (
+	"github.com/mcpchecker/mcpchecker/pkg/extension/client"
+	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
+)
+
+// Dependencies holds the shared services that steps use during an eval run.
+// It is created once by the eval runner and passed explicitly to task runners
+// and steps; task runs that record what they do, such as their judge calls,
+// get a copy of their own. Any field may be nil when the run does not
+// configure it.
+type Dependencies struct {
+	Extensions client.ExtensionManager
+	McpClients mcpclient.Manager
+	Judge      llmjudge.LLMJudge
+	// JudgeAudit records the judge calls of a task run, if they are audited
+	JudgeAudit *llmjudge.AuditLog
+}
+
+// ExtensionManager returns the extension manager, if one is configured.
+func (d *Dependencies) ExtensionManager() (client.ExtensionManager, bool) {
+	if d == nil || d.Extensions == nil {
+		return nil, false
+	}
+	return d.Extensions, true
+}
+
+// McpManager returns the MCP client manager, if one is configured.
+func (d *Dependencies) McpManager() (mcpclient.Manager, bool) {
+	if d == nil || d.McpClients == nil {
+		return nil, false
+	}
+	return d.McpClients, true
+}
+
+// LLMJudge returns the LLM judge, if one is configured.
+func (d *Dependencies) LLMJudge() (llmjudge.LLMJudge, bool) {
+	if d == nil || d.Judge == nil {
+		return nil, false
+	}
+	return d.Judge, true
+}
+
+// JudgeAuditLog returns the audit log of the judge calls, nil if they are not
+// audited.
+func (d *Dependencies) JudgeAuditLog() *llmjudge.AuditLog {
+	if d == nil {
+		return nil
+	}
+	return d.JudgeAudit
+}
@@ -0,0 +1,43 @@
+package steps
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDependencies(t *testing.T) {
+	tt := map[string]struct {
+		deps            *Dependencies
+		expectExtension bool
+		expectMcp       bool
+		expectJudge     bool
+	}{
+		"nil dependencies": {
+			deps: nil,
+		},
+		"empty dependencies": {
+			deps: &Dependencies{},
+		},
+		"judge only": {
+			deps:        &Dependencies{Judge: &fakeLLMJudge{}},
+			expectJudge: true,
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			_, ok := tc.deps.ExtensionManager()
+			assert.Equal(t, tc.expectExtension, ok)
+
+			_, ok = tc.deps.McpManager()
+			assert.Equal(t, tc.expectMcp, ok)
+
+			judge, ok := tc.deps.LLMJudge()
+			assert.Equal(t, tc.expectJudge, ok)
+			if !tc.expectJudge {
+				assert.Nil(t, judge)
+			}
+		})
+	}
+}
@@ -3,15 +3,17 @@ package steps
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
-	"github.com/mcpchecker/mcpchecker/pkg/extension/client"
 	extprotocol "github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
 )
 
 const (
-	// extensionTimeout is the timeout for extension operation calls
+	// extensionTimeout is the default timeout for extension operation calls
 	extensionTimeout = 30 * time.Second
 )
 
@@ -19,13 +21,18 @@ type extensionStep struct {
 	alias     string
 	operation string
 	args      map[string]any
+	timeout   time.Duration
+
+	// outputs are the outputs declared by the operation in the manifest, nil
+	// if it declares none
+	outputs map[string]string
 }
 
-func NewExtensionParser(ctx context.Context, alias string) PrefixParser {
+func NewExtensionParser(ctx context.Context, deps *Dependencies, alias string) PrefixParser {
 	return func(operation string, raw json.RawMessage) (StepRunner, error) {
-		manager, ok := client.ManagerFromContext(ctx)
+		manager, ok := deps.ExtensionManager()
 		if !ok {
-			return nil, fmt.Errorf("failed to get extension manager from context")
+			return nil, fmt.Errorf("no extension manager configured")
 		}
 
 		ext, err := manager.Get(ctx, alias)
@@ -60,20 +67,40 @@ func NewExtensionParser(ctx context.Context, alias string) PrefixParser {
 			alias:     alias,
 			operation: operation,
 			args:      args,
+			timeout:   extensionTimeout,
+			outputs:   op.Outputs,
 		}, nil
 	}
 }
 
-var _ StepRunner = &extensionStep{}
+var (
+	_ StepRunner     = &extensionStep{}
+	_ OutputDeclarer = &extensionStep{}
+)
+
+func (r *extensionStep) setTimeout(timeout time.Duration) {
+	r.timeout = timeout
+}
+
+// DeclaredOutputs returns the outputs that the operation declares in the
+// manifest of the extension. They are unknown if it declares none.
+func (r *extensionStep) DeclaredOutputs() DeclaredOutputs {
+	return DeclaredOutputs{
+		Type:    r.alias + "." + r.operation,
+		Keys:    slices.Sorted(maps.Keys(r.outputs)),
+		Unknown: r.outputs == nil,
+	}
+}
 
 func (r *extensionStep) Execute(ctx context.Context, input *StepInput) (*StepOutput, error) {
-	// Apply timeout to prevent extension calls from hanging indefinitely
-	ctx, cancel := context.WithTimeout(ctx, extensionTimeout)
+	// Apply timeout to prevent extension calls from hanging indefinitely. When
+	// it passes, the client cancels the operation in the extension.
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	manager, ok := client.ManagerFromContext(ctx)
+	manager, ok := input.Deps.ExtensionManager()
 	if !ok {
-		return nil, fmt.Errorf("failed to get extension manager from context")
+		return nil, fmt.Errorf("no extension manager configured")
 	}
 
 	ext, err := manager.Get(ctx, r.alias)
@@ -86,6 +113,7 @@ func (r *extensionStep) Execute(ctx context.Context, input *StepInput) (*StepOut
 		Args:      r.args,
 		Context: extprotocol.ExecuteContext{
 			Workdir: input.Workdir,
+			Timeout: r.timeout.String(),
 		},
 	}
 
@@ -96,9 +124,22 @@ func (r *extensionStep) Execute(ctx context.Context, input *StepInput) (*StepOut
 		}
 	}
 
-	res, err := ext.Execute(ctx, params)
+	// Record the logs of the operation with the step, so that failures on the
+	// extension side can be diagnosed from the results
+	var logs []StepLog
+	res, err := ext.Execute(ctx, params, func(params extprotocol.LogParams) {
+		logs = append(logs, StepLog{Level: params.Level, Message: params.Message, Data: params.Data})
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute %s.%s: %w", r.alias, r.operation, err)
+		// Keep the logs of a failed call, they may tell why it failed
+		var out *StepOutput
+		if len(logs) > 0 {
+			out = &StepOutput{Type: r.alias + "." + r.operation, Logs: logs}
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return out, fmt.Errorf("%s.%s timed out after %s: %w", r.alias, r.operation, r.timeout, err)
+		}
+		return out, fmt.Errorf("failed to execute %s.%s: %w", r.alias, r.operation, err)
 	}
 
 	return &StepOutput{
@@ -107,5 +148,6 @@ func (r *extensionStep) Execute(ctx context.Context, input *StepInput) (*StepOut
 		Message: res.Message,
 		Error:   res.Error,
 		Outputs: res.Outputs,
+		Logs:    logs,
 	}, nil
 }
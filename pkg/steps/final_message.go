@@ -0,0 +1,173 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// FinalMessageStepConfig checks the final message of the agent without an LLM
+// judge: against regular expressions, as JSON, and as a number.
+type FinalMessageStepConfig struct {
+	// Match is a regular expression the final message must match
+	Match string `json:"match,omitempty"`
+	// NotMatch is a regular expression the final message must not match
+	NotMatch string `json:"notMatch,omitempty"`
+	// Number checks the number that Match captured: its first group, or the
+	// whole match if it has no groups
+	Number *NumberAssertion `json:"number,omitempty"`
+	// Fields are checked against the final message parsed as JSON
+	Fields []FieldAssertion `json:"fields,omitempty"`
+}
+
+type FinalMessageStep struct {
+	match    *regexp.Regexp
+	notMatch *regexp.Regexp
+	number   *NumberAssertion
+	fields   []FieldAssertion
+}
+
+var (
+	_ StepRunner     = &FinalMessageStep{}
+	_ OutputDeclarer = &FinalMessageStep{}
+)
+
+// jsonFencePattern matches a fenced code block, which agents often wrap the
+// JSON they are asked to answer with in
+var jsonFencePattern = regexp.MustCompile("(?s)```(?:json)?[ \t]*\n(.*?)\n[ \t]*```")
+
+func ParseFinalMessageStep(raw json.RawMessage) (StepRunner, error) {
+	cfg := &FinalMessageStepConfig{}
+
+	err := json.Unmarshal(raw, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFinalMessageStep(cfg)
+}
+
+func NewFinalMessageStep(cfg *FinalMessageStepConfig) (*FinalMessageStep, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	step := &FinalMessageStep{number: cfg.Number, fields: cfg.Fields}
+
+	var err error
+	if cfg.Match != "" {
+		if step.match, err = regexp.Compile(cfg.Match); err != nil {
+			return nil, fmt.Errorf("invalid match regex %q: %w", cfg.Match, err)
+		}
+	}
+	if cfg.NotMatch != "" {
+		if step.notMatch, err = regexp.Compile(cfg.NotMatch); err != nil {
+			return nil, fmt.Errorf("invalid notMatch regex %q: %w", cfg.NotMatch, err)
+		}
+	}
+
+	return step, nil
+}
+
+func (cfg *FinalMessageStepConfig) Validate() error {
+	if cfg.Match == "" && cfg.NotMatch == "" && len(cfg.Fields) == 0 {
+		return fmt.Errorf("at least one of match, notMatch or fields must be set")
+	}
+	if cfg.Number != nil && cfg.Match == "" {
+		return fmt.Errorf("number requires match to capture the number")
+	}
+	if err := cfg.Number.Validate(); err != nil {
+		return fmt.Errorf("invalid number: %w", err)
+	}
+	for i, field := range cfg.Fields {
+		if field.Path == "" {
+			return fmt.Errorf("fields[%d]: path is required", i)
+		}
+		if err := field.Number.Validate(); err != nil {
+			return fmt.Errorf("fields[%d]: invalid number: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// DeclaredOutputs returns the outputs of the step: the value that match
+// captured.
+func (s *FinalMessageStep) DeclaredOutputs() DeclaredOutputs {
+	return DeclaredOutputs{Type: "finalMessage", Keys: []string{"match"}}
+}
+
+func (s *FinalMessageStep) Execute(ctx context.Context, input *StepInput) (*StepOutput, error) {
+	if input.Agent == nil {
+		return nil, fmt.Errorf("cannot run finalMessage step before agent (must be in verification)")
+	}
+	message := strings.TrimSpace(input.Agent.Output)
+
+	var errors []string
+	out := &StepOutput{Type: "finalMessage"}
+
+	if s.match != nil {
+		groups := s.match.FindStringSubmatch(message)
+		if groups == nil {
+			errors = append(errors, fmt.Sprintf("final message did not match pattern %q", s.match))
+		} else {
+			captured := groups[0]
+			if len(groups) > 1 {
+				captured = groups[1]
+			}
+			out.Outputs = map[string]string{"match": captured}
+
+			if s.number != nil {
+				if err := s.number.CheckString(captured); err != nil {
+					errors = append(errors, fmt.Sprintf("captured number: %s", err))
+				}
+			}
+		}
+	}
+
+	if s.notMatch != nil {
+		if loc := s.notMatch.FindStringIndex(message); loc != nil {
+			errors = append(errors, fmt.Sprintf("final message matched pattern %q: %q", s.notMatch, message[loc[0]:loc[1]]))
+		}
+	}
+
+	if len(s.fields) > 0 {
+		parsed, err := parseMessageJSON(message)
+		if err != nil {
+			errors = append(errors, err.Error())
+		} else {
+			for _, field := range s.fields {
+				errors = append(errors, field.Validate(parsed)...)
+			}
+		}
+	}
+
+	out.Success = len(errors) == 0
+	if out.Success {
+		out.Message = "final message passed all checks"
+	} else {
+		out.Error = fmt.Sprintf("final message failed check: %s", strings.Join(errors, "; "))
+	}
+
+	return out, nil
+}
+
+// parseMessageJSON parses message as JSON, or else the first fenced code
+// block in it.
+func parseMessageJSON(message string) (any, error) {
+	var parsed any
+	err := json.Unmarshal([]byte(message), &parsed)
+	if err == nil {
+		return parsed, nil
+	}
+
+	if block := jsonFencePattern.FindStringSubmatch(message); block != nil {
+		if json.Unmarshal([]byte(block[1]), &parsed) == nil {
+			return parsed, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to parse final message as JSON: %s", err)
+}
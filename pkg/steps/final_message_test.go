@@ -0,0 +1,139 @@
+package steps
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/ptr"
+)
+
+func TestFinalMessageStepConfig_Validate(t *testing.T) {
+	tt := map[string]struct {
+		config  *FinalMessageStepConfig
+		wantErr string
+	}{
+		"match": {
+			config: &FinalMessageStepConfig{Match: "created"},
+		},
+		"fields": {
+			config: &FinalMessageStepConfig{Fields: []FieldAssertion{{Path: "name", Equals: "web"}}},
+		},
+		"no checks": {
+			config:  &FinalMessageStepConfig{},
+			wantErr: "at least one of match, notMatch or fields must be set",
+		},
+		"number without match": {
+			config:  &FinalMessageStepConfig{NotMatch: "error", Number: &NumberAssertion{Equals: 3}},
+			wantErr: "number requires match",
+		},
+		"negative tolerance": {
+			config:  &FinalMessageStepConfig{Match: `(\d+)`, Number: &NumberAssertion{Equals: 3, Tolerance: -1}},
+			wantErr: "tolerances must not be negative",
+		},
+		"field without path": {
+			config:  &FinalMessageStepConfig{Fields: []FieldAssertion{{Equals: "web"}}},
+			wantErr: "fields[0]: path is required",
+		},
+		"invalid regex": {
+			config:  &FinalMessageStepConfig{Match: "("},
+			wantErr: "invalid match regex",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewFinalMessageStep(tc.config)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestFinalMessageStep_Execute(t *testing.T) {
+	tt := map[string]struct {
+		config      *FinalMessageStepConfig
+		output      string
+		wantErr     string
+		wantOutputs map[string]string
+	}{
+		"match captures the first group": {
+			config:      &FinalMessageStepConfig{Match: `created pod (\S+)`},
+			output:      "I created pod web-1 in default.",
+			wantOutputs: map[string]string{"match": "web-1"},
+		},
+		"match fails": {
+			config:  &FinalMessageStepConfig{Match: `created pod`},
+			output:  "I could not create the pod.",
+			wantErr: `final message did not match pattern "created pod"`,
+		},
+		"not match fails": {
+			config:  &FinalMessageStepConfig{NotMatch: `(?i)error`},
+			output:  "There was an Error.",
+			wantErr: `final message matched pattern "(?i)error": "Error"`,
+		},
+		"number within relative tolerance": {
+			config: &FinalMessageStepConfig{Match: `([\d,.]+) requests`, Number: &NumberAssertion{Equals: 1200, RelativeTolerance: 0.05}},
+			output: "The service handled 1,240 requests.",
+		},
+		"number outside tolerance": {
+			config:  &FinalMessageStepConfig{Match: `([\d.]+)%`, Number: &NumberAssertion{Equals: 50, Tolerance: 2}},
+			output:  "CPU usage is 57.5%",
+			wantErr: "captured number: expected 50 ± 2, got 57.5",
+		},
+		"json fields": {
+			config: &FinalMessageStepConfig{Fields: []FieldAssertion{
+				{Path: "pods[1].name", Equals: "api"},
+				{Path: "count", Number: &NumberAssertion{Equals: 2}},
+				{Path: "namespace", Match: ptr.To("^def")},
+			}},
+			output: `{"namespace": "default", "count": 2, "pods": [{"name": "web"}, {"name": "api"}]}`,
+		},
+		"json in a fenced block": {
+			config: &FinalMessageStepConfig{Fields: []FieldAssertion{{Path: "count", Equals: 2}}},
+			output: "Here is the answer:\n\n```json\n{\"count\": 2}\n```\n",
+		},
+		"json field mismatch": {
+			config:  &FinalMessageStepConfig{Fields: []FieldAssertion{{Path: "count", Equals: 3}}},
+			output:  `{"count": 2}`,
+			wantErr: `field "count": expected 3, got 2`,
+		},
+		"not json": {
+			config:  &FinalMessageStepConfig{Fields: []FieldAssertion{{Path: "count", Equals: 2}}},
+			output:  "There are 2 pods.",
+			wantErr: "failed to parse final message as JSON",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			step, err := NewFinalMessageStep(tc.config)
+			require.NoError(t, err)
+
+			out, err := step.Execute(context.Background(), &StepInput{Agent: &AgentContext{Output: tc.output}})
+			require.NoError(t, err)
+			assert.Equal(t, "finalMessage", out.Type)
+			if tc.wantErr != "" {
+				assert.False(t, out.Success)
+				assert.Contains(t, out.Error, tc.wantErr)
+				return
+			}
+			assert.True(t, out.Success, out.Error)
+			if tc.wantOutputs != nil {
+				assert.Equal(t, tc.wantOutputs, out.Outputs)
+			}
+		})
+	}
+}
+
+func TestFinalMessageStep_ExecuteBeforeAgent(t *testing.T) {
+	step, err := NewFinalMessageStep(&FinalMessageStepConfig{Match: "done"})
+	require.NoError(t, err)
+
+	_, err = step.Execute(context.Background(), &StepInput{})
+	assert.ErrorContains(t, err, "must be in verification")
+}
@@ -44,6 +44,9 @@ type FieldAssertion struct {
 	Type   string  `json:"type,omitempty"`   // "string", "number", "array", "object", "bool", "null"
 	Match  *string `json:"match,omitempty"`  // regex for string values
 	Exists *bool   `json:"exists,omitempty"` // field presence check
+
+	// Number checks a number, or a string holding one, within a tolerance
+	Number *NumberAssertion `json:"number,omitempty"`
 }
 
 type HttpStep struct {
@@ -55,7 +58,10 @@ type HttpStep struct {
 	Timeout time.Duration
 }
 
-var _ StepRunner = &HttpStep{}
+var (
+	_ StepRunner     = &HttpStep{}
+	_ OutputDeclarer = &HttpStep{}
+)
 
 func ParseHttpStep(raw json.RawMessage) (StepRunner, error) {
 	cfg := &HttpStepConfig{}
@@ -131,6 +137,11 @@ func NewHttpStep(cfg *HttpStepConfig) (*HttpStep, error) {
 	return step, nil
 }
 
+// DeclaredOutputs returns the outputs of the step: HTTP steps set none.
+func (s *HttpStep) DeclaredOutputs() DeclaredOutputs {
+	return DeclaredOutputs{Type: "http"}
+}
+
 func (s *HttpStep) Execute(ctx context.Context, input *StepInput) (*StepOutput, error) {
 	if input.Random != nil {
 		s.URL.SetSourceResolver("random", input.Random)
@@ -184,6 +195,9 @@ func (s *HttpStep) Execute(ctx context.Context, input *StepInput) (*StepOutput,
 		req.Header.Set("Content-Type", body.ContentType)
 	}
 
+	// Tasks with a clock send its time as the Date header
+	input.Clock.SetDateHeader(req)
+
 	client := http.DefaultClient
 
 	resp, err := client.Do(req)
@@ -352,7 +366,7 @@ func (f *FieldAssertion) Validate(data any) []string {
 
 	// If field doesn't exist and we're not checking existence, skip other validations
 	if !exists {
-		if f.Equals != nil || f.Type != "" || f.Match != nil {
+		if f.Equals != nil || f.Type != "" || f.Match != nil || f.Number != nil {
 			return []string{fmt.Sprintf("field %q does not exist", f.Path)}
 		}
 		return nil
@@ -389,6 +403,22 @@ func (f *FieldAssertion) Validate(data any) []string {
 		}
 	}
 
+	// Check number
+	if f.Number != nil {
+		var err error
+		switch v := value.(type) {
+		case float64:
+			err = f.Number.Check(v)
+		case string:
+			err = f.Number.CheckString(v)
+		default:
+			err = fmt.Errorf("expected a number, got %T", value)
+		}
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("field %q: %s", f.Path, err))
+		}
+	}
+
 	return errors
 }
 
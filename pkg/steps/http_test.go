@@ -7,10 +7,13 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"k8s.io/utils/ptr"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 func TestSplitPath(t *testing.T) {
@@ -331,6 +334,20 @@ func TestExpectBody_Validate(t *testing.T) {
 			body:       `{"email": "invalid"}`,
 			wantErrors: []string{`field "email": value "invalid" did not match pattern "^[a-z]+@example\\.com$"`},
 		},
+		"field number within tolerance succeeds": {
+			expect: &ExpectBody{
+				Fields: []FieldAssertion{{Path: "latency", Number: &NumberAssertion{Equals: 100, RelativeTolerance: 0.1}}},
+			},
+			body:       `{"latency": 108.5}`,
+			wantErrors: nil,
+		},
+		"field number outside tolerance fails": {
+			expect: &ExpectBody{
+				Fields: []FieldAssertion{{Path: "latency", Number: &NumberAssertion{Equals: 100, Tolerance: 5}}},
+			},
+			body:       `{"latency": "108.5"}`,
+			wantErrors: []string{`field "latency": expected 100 ± 5, got 108.5`},
+		},
 		"empty body with field assertions fails": {
 			expect: &ExpectBody{
 				Fields: []FieldAssertion{{Path: "id", Type: "number"}},
@@ -532,3 +549,27 @@ func TestHttpStep_Execute(t *testing.T) {
 		})
 	}
 }
+
+func TestHttpStep_ExecuteClock(t *testing.T) {
+	start := time.Date(2025, 1, 31, 23, 59, 0, 0, time.UTC)
+
+	var date string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		date = r.Header.Get("Date")
+	}))
+	defer server.Close()
+
+	step, err := NewHttpStep(&HttpStepConfig{
+		URL:    server.URL,
+		Method: "GET",
+		Body:   &HttpBody{Raw: ptr.To("")},
+		Expect: &HttpExpect{Status: 200},
+	})
+	require.NoError(t, err)
+
+	_, err = step.Execute(context.Background(), &StepInput{Clock: util.NewClock(start)})
+	require.NoError(t, err)
+	sent, err := http.ParseTime(date)
+	require.NoError(t, err)
+	assert.WithinDuration(t, start, sent, 2*time.Second)
+}
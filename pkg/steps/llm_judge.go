@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/genmcp/gen-mcp/pkg/template"
 	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
+	"github.com/mcpchecker/mcpchecker/pkg/tokens"
 	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
@@ -15,9 +18,16 @@ type LLMJudgeStep struct {
 	cfg              *llmjudge.LLMJudgeStepConfig
 	containsTemplate *template.TemplateBuilder
 	exactTemplate    *template.TemplateBuilder
+
+	// refs are the {steps.*} references of Contains and Exact
+	refs []string
 }
 
-var _ StepRunner = &LLMJudgeStep{}
+var (
+	_ StepRunner     = &LLMJudgeStep{}
+	_ OutputDeclarer = &LLMJudgeStep{}
+	_ StepReferencer = &LLMJudgeStep{}
+)
 
 // ParseLLMJudgeStep parses an LLM judge step from JSON configuration.
 func ParseLLMJudgeStep(raw json.RawMessage) (StepRunner, error) {
@@ -54,6 +64,7 @@ func NewLLMJudgeStep(cfg *llmjudge.LLMJudgeStepConfig) (*LLMJudgeStep, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse contains template: %w", err)
 		}
+		step.refs = append(step.refs, StepReferences(containsTemplate)...)
 
 		step.containsTemplate, err = template.NewTemplateBuilder(containsTemplate, false)
 		if err != nil {
@@ -69,6 +80,7 @@ func NewLLMJudgeStep(cfg *llmjudge.LLMJudgeStepConfig) (*LLMJudgeStep, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse exact template: %w", err)
 		}
+		step.refs = append(step.refs, StepReferences(exactTemplate)...)
 
 		step.exactTemplate, err = template.NewTemplateBuilder(exactTemplate, false)
 		if err != nil {
@@ -79,9 +91,20 @@ func NewLLMJudgeStep(cfg *llmjudge.LLMJudgeStepConfig) (*LLMJudgeStep, error) {
 	return step, nil
 }
 
+// DeclaredOutputs returns the outputs that aggregateJudgeVerdicts sets.
+func (s *LLMJudgeStep) DeclaredOutputs() DeclaredOutputs {
+	return DeclaredOutputs{Type: "llmJudge", Keys: []string{"samples", "passedSamples", "verdicts"}}
+}
+
+// StepReferences returns the {steps.*} references of the contains and exact
+// templates of the step.
+func (s *LLMJudgeStep) StepReferences() []string {
+	return s.refs
+}
+
 // Execute runs the LLM judge step with template expansion for step outputs.
 func (s *LLMJudgeStep) Execute(ctx context.Context, input *StepInput) (*StepOutput, error) {
-	judge, ok := llmjudge.FromContext(ctx)
+	judge, ok := input.Deps.LLMJudge()
 	if !ok {
 		return nil, fmt.Errorf("no llm judge configured for llmJudge step")
 	}
@@ -143,18 +166,36 @@ func (s *LLMJudgeStep) Execute(ctx context.Context, input *StepInput) (*StepOutp
 		expandedCfg.Exact = str
 	}
 
+	util.DebugDirFromContext(ctx).WriteJSON("judge-config.json", &expandedCfg)
+
 	if util.IsVerbose(ctx) {
-		fmt.Printf("  → LLM judge '%s' is evaluating…\n", judge.ModelName())
+		out := util.OutputFromContext(ctx)
+		fmt.Fprintf(out, "  → LLM judge '%s' is evaluating…\n", judge.ModelName())
 		if expandedCfg.Contains != s.cfg.Contains || expandedCfg.Exact != s.cfg.Exact {
-			fmt.Printf("  → Template expansion: %s -> %s\n", s.cfg.ReferenceAnswer(), expandedCfg.ReferenceAnswer())
+			fmt.Fprintf(out, "  → Template expansion: %s -> %s\n", s.cfg.ReferenceAnswer(), expandedCfg.ReferenceAnswer())
 		}
 	}
 
-	res, err := judge.EvaluateText(ctx, &expandedCfg, input.Agent.Prompt, input.Agent.Output)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call llm judge: %w", err)
+	samples := expandedCfg.SampleCount()
+	results := make([]*llmjudge.LLMJudgeResult, 0, samples)
+	for i := range samples {
+		res, err := judge.EvaluateText(ctx, &expandedCfg, input.Agent.Prompt, input.Agent.Output, input.Agent.ToolCalls, input.Deps.JudgeAuditLog())
+		if err != nil {
+			// Surface as a judge error so the run does not report it as a task failure
+			judgeErr := &llmjudge.JudgeError{Err: err}
+			if samples > 1 {
+				return nil, fmt.Errorf("failed to call llm judge for sample %d/%d: %w", i+1, samples, judgeErr)
+			}
+			return nil, fmt.Errorf("failed to call llm judge: %w", judgeErr)
+		}
+		results = append(results, res)
 	}
 
+	if len(results) > 1 {
+		return aggregateJudgeVerdicts(results, expandedCfg.RequiredPasses()), nil
+	}
+
+	res := results[0]
 	out := &StepOutput{
 		Type:    "llmJudge",
 		Success: res.Passed,
@@ -169,6 +210,50 @@ func (s *LLMJudgeStep) Execute(ctx context.Context, input *StepInput) (*StepOutp
 	return out, nil
 }
 
+// aggregateJudgeVerdicts combines several judge samples into one step output that
+// passes when at least required samples passed. Every verdict is recorded.
+func aggregateJudgeVerdicts(results []*llmjudge.LLMJudgeResult, required int) *StepOutput {
+	passed := 0
+	var usage *tokens.Usage
+	lines := make([]string, len(results))
+	for i, res := range results {
+		verdict := "pass"
+		if res.Passed {
+			passed++
+		} else {
+			verdict = fmt.Sprintf("fail (%s)", res.FailureCategory)
+		}
+		lines[i] = fmt.Sprintf("[%d] %s: %s", i+1, verdict, res.Reason)
+
+		if res.Usage != nil {
+			if usage == nil {
+				usage = &tokens.Usage{}
+			}
+			usage.Add(res.Usage)
+		}
+	}
+
+	verdicts, _ := json.Marshal(results)
+
+	out := &StepOutput{
+		Type:    "llmJudge",
+		Success: passed >= required,
+		Message: fmt.Sprintf("%d/%d judge samples passed (%d required)\n%s", passed, len(results), required, strings.Join(lines, "\n")),
+		Outputs: map[string]string{
+			"samples":       strconv.Itoa(len(results)),
+			"passedSamples": strconv.Itoa(passed),
+			"verdicts":      string(verdicts),
+		},
+		Usage: usage,
+	}
+
+	if !out.Success {
+		out.Error = fmt.Sprintf("llm judge failed: %d/%d samples passed, %d required", passed, len(results), required)
+	}
+
+	return out
+}
+
 // StepOutputResolver resolves template variables from step outputs.
 // It implements the template.SourceResolver interface.
 type StepOutputResolver struct {
@@ -181,10 +266,11 @@ func NewStepOutputResolver(outputs map[string]map[string]string) *StepOutputReso
 }
 
 // Resolve looks up a field in the step outputs.
-// Field names use the format "stepType.outputKey" where stepType can contain dots.
+// Field names use the format "step.outputKey", where step is a step ID or, for
+// backward compatibility, a step type. Step types can contain dots.
 // Example: "kubernetes.listContexts.current" looks up outputs["kubernetes.listContexts"]["current"]
 func (r *StepOutputResolver) Resolve(fieldName string) (string, error) {
-	// Split on the last dot to separate stepType from outputKey
+	// Split on the last dot to separate the step from outputKey
 	lastDot := -1
 	for i := len(fieldName) - 1; i >= 0; i-- {
 		if fieldName[i] == '.' {
@@ -194,20 +280,20 @@ func (r *StepOutputResolver) Resolve(fieldName string) (string, error) {
 	}
 
 	if lastDot == -1 {
-		return "", fmt.Errorf("invalid field name %q: must be in format stepType.outputKey", fieldName)
+		return "", fmt.Errorf("invalid field name %q: must be in format step.outputKey", fieldName)
 	}
 
-	stepType := fieldName[:lastDot]  // e.g., "kubernetes.listContexts"
+	step := fieldName[:lastDot]        // e.g., "kubernetes.listContexts" or "create_ns"
 	outputKey := fieldName[lastDot+1:] // e.g., "current"
 
-	stepOutputs, ok := r.outputs[stepType]
+	stepOutputs, ok := r.outputs[step]
 	if !ok {
-		return "", fmt.Errorf("step type %q not found in outputs", stepType)
+		return "", fmt.Errorf("step %q not found in outputs", step)
 	}
 
 	value, ok := stepOutputs[outputKey]
 	if !ok {
-		return "", fmt.Errorf("output key %q not found for step type %q", outputKey, stepType)
+		return "", fmt.Errorf("output key %q not found for step %q", outputKey, step)
 	}
 
 	return value, nil
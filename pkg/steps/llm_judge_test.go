@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
 	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
+	"github.com/mcpchecker/mcpchecker/pkg/tokens"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -14,12 +16,24 @@ type fakeLLMJudge struct {
 	result *llmjudge.LLMJudgeResult
 	err    error
 	model  string
+
+	// results, if set, are returned in turn by successive calls
+	results []*llmjudge.LLMJudgeResult
+	calls   int
+
+	// audit is the audit log of the last call
+	audit *llmjudge.AuditLog
 }
 
-func (f *fakeLLMJudge) EvaluateText(ctx context.Context, judgeConfig *llmjudge.LLMJudgeStepConfig, prompt, output string) (*llmjudge.LLMJudgeResult, error) {
+func (f *fakeLLMJudge) EvaluateText(ctx context.Context, judgeConfig *llmjudge.LLMJudgeStepConfig, prompt, output string, toolCalls []agent.ToolCallSummary, audit *llmjudge.AuditLog) (*llmjudge.LLMJudgeResult, error) {
+	f.calls++
+	f.audit = audit
 	if f.err != nil {
 		return nil, f.err
 	}
+	if len(f.results) > 0 {
+		return f.results[(f.calls-1)%len(f.results)], nil
+	}
 	return f.result, nil
 }
 
@@ -59,6 +73,29 @@ func TestLLMJudgeStepConfig_Validate(t *testing.T) {
 			config:    &llmjudge.LLMJudgeStepConfig{},
 			expectErr: true,
 		},
+		"valid samples with threshold": {
+			config: &llmjudge.LLMJudgeStepConfig{
+				Contains:           "content",
+				Samples:            3,
+				AgreementThreshold: 0.67,
+			},
+			expectErr: false,
+		},
+		"invalid: negative samples": {
+			config: &llmjudge.LLMJudgeStepConfig{
+				Contains: "content",
+				Samples:  -1,
+			},
+			expectErr: true,
+		},
+		"invalid: threshold above 1": {
+			config: &llmjudge.LLMJudgeStepConfig{
+				Contains:           "content",
+				Samples:            3,
+				AgreementThreshold: 1.5,
+			},
+			expectErr: true,
+		},
 	}
 
 	for tn, tc := range tt {
@@ -73,6 +110,29 @@ func TestLLMJudgeStepConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestLLMJudgeStepConfig_RequiredPasses(t *testing.T) {
+	tt := map[string]struct {
+		samples   int
+		threshold float64
+		expected  int
+	}{
+		"single sample":             {expected: 1},
+		"default majority of 3":     {samples: 3, expected: 2},
+		"default half of 4":         {samples: 4, expected: 2},
+		"two thirds of 3":           {samples: 3, threshold: 0.67, expected: 3},
+		"exact fraction of 3":       {samples: 3, threshold: 2.0 / 3.0, expected: 2},
+		"unanimous":                 {samples: 5, threshold: 1, expected: 5},
+		"small threshold needs one": {samples: 5, threshold: 0.01, expected: 1},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			cfg := &llmjudge.LLMJudgeStepConfig{Samples: tc.samples, AgreementThreshold: tc.threshold}
+			assert.Equal(t, tc.expected, cfg.RequiredPasses())
+		})
+	}
+}
+
 func TestNewLLMJudgeStep(t *testing.T) {
 	tt := map[string]struct {
 		config    *llmjudge.LLMJudgeStepConfig
@@ -104,6 +164,21 @@ func TestNewLLMJudgeStep(t *testing.T) {
 	}
 }
 
+func TestLLMJudgeStep_ExecuteAuditLog(t *testing.T) {
+	judge := &fakeLLMJudge{model: "test-model", result: &llmjudge.LLMJudgeResult{Passed: true, FailureCategory: "n/a"}}
+	step, err := NewLLMJudgeStep(&llmjudge.LLMJudgeStepConfig{Contains: "content"})
+	require.NoError(t, err)
+
+	// The judge records its calls in the audit log of the task run
+	audit := llmjudge.NewAuditLog()
+	_, err = step.Execute(context.Background(), &StepInput{
+		Agent: &AgentContext{Prompt: "test prompt", Output: "test output"},
+		Deps:  &Dependencies{Judge: judge, JudgeAudit: audit},
+	})
+	require.NoError(t, err)
+	assert.Same(t, audit, judge.audit)
+}
+
 func TestLLMJudgeStep_Execute(t *testing.T) {
 	tt := map[string]struct {
 		config    *llmjudge.LLMJudgeStepConfig
@@ -179,6 +254,70 @@ func TestLLMJudgeStep_Execute(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		"samples pass when the threshold is met": {
+			config: &llmjudge.LLMJudgeStepConfig{
+				Contains: "content",
+				Samples:  3,
+			},
+			judge: &fakeLLMJudge{
+				model: "test-model",
+				results: []*llmjudge.LLMJudgeResult{
+					{Passed: true, Reason: "has content", FailureCategory: "n/a", Usage: &tokens.Usage{InputTokens: 10, TotalTokens: 10}},
+					{Passed: false, Reason: "missing content", FailureCategory: "missing_information", Usage: &tokens.Usage{InputTokens: 10, TotalTokens: 10}},
+					{Passed: true, Reason: "has content", FailureCategory: "n/a"},
+				},
+			},
+			input: &StepInput{
+				Agent: &AgentContext{
+					Prompt: "test prompt",
+					Output: "test output",
+				},
+			},
+			expected: &StepOutput{
+				Type:    "llmJudge",
+				Success: true,
+				Message: "2/3 judge samples passed (2 required)\n[1] pass: has content\n[2] fail (missing_information): missing content\n[3] pass: has content",
+				Outputs: map[string]string{
+					"samples":       "3",
+					"passedSamples": "2",
+					"verdicts": `[{"passed":true,"reason":"has content","failureCategory":"n/a","usage":{"inputTokens":10,"outputTokens":0,"totalTokens":10}},` +
+						`{"passed":false,"reason":"missing content","failureCategory":"missing_information","usage":{"inputTokens":10,"outputTokens":0,"totalTokens":10}},` +
+						`{"passed":true,"reason":"has content","failureCategory":"n/a"}]`,
+				},
+				Usage: &tokens.Usage{InputTokens: 20, TotalTokens: 20},
+			},
+		},
+		"samples fail below the threshold": {
+			config: &llmjudge.LLMJudgeStepConfig{
+				Contains:           "content",
+				Samples:            2,
+				AgreementThreshold: 1,
+			},
+			judge: &fakeLLMJudge{
+				model: "test-model",
+				results: []*llmjudge.LLMJudgeResult{
+					{Passed: true, Reason: "ok", FailureCategory: "n/a"},
+					{Passed: false, Reason: "wrong", FailureCategory: "semantic_mismatch"},
+				},
+			},
+			input: &StepInput{
+				Agent: &AgentContext{
+					Prompt: "test prompt",
+					Output: "test output",
+				},
+			},
+			expected: &StepOutput{
+				Type:    "llmJudge",
+				Success: false,
+				Message: "1/2 judge samples passed (2 required)\n[1] pass: ok\n[2] fail (semantic_mismatch): wrong",
+				Outputs: map[string]string{
+					"samples":       "2",
+					"passedSamples": "1",
+					"verdicts":      `[{"passed":true,"reason":"ok","failureCategory":"n/a"},{"passed":false,"reason":"wrong","failureCategory":"semantic_mismatch"}]`,
+				},
+				Error: "llm judge failed: 1/2 samples passed, 2 required",
+			},
+		},
 		"no judge in context": {
 			config: &llmjudge.LLMJudgeStepConfig{
 				Contains: "content",
@@ -237,12 +376,11 @@ func TestLLMJudgeStep_Execute(t *testing.T) {
 			step, err := NewLLMJudgeStep(tc.config)
 			require.NoError(t, err)
 
-			ctx := context.Background()
 			if tc.judge != nil {
-				ctx = llmjudge.WithJudge(ctx, tc.judge)
+				tc.input.Deps = &Dependencies{Judge: tc.judge}
 			}
 
-			got, err := step.Execute(ctx, tc.input)
+			got, err := step.Execute(context.Background(), tc.input)
 			if tc.expectErr {
 				assert.Error(t, err)
 				return
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -22,13 +21,16 @@ type McpExpect struct {
 	Content *ExpectBody `json:"content,omitempty"`
 }
 
-var _ StepRunner = &McpStep{}
+var (
+	_ StepRunner     = &McpStep{}
+	_ OutputDeclarer = &McpStep{}
+)
 
-func NewMcpServerParser(ctx context.Context, serverName string) PrefixParser {
+func NewMcpServerParser(ctx context.Context, deps *Dependencies, serverName string) PrefixParser {
 	return func(toolName string, raw json.RawMessage) (StepRunner, error) {
-		manager, ok := mcpclient.ManagerFromContext(ctx)
+		manager, ok := deps.McpManager()
 		if !ok {
-			return nil, fmt.Errorf("unable to get mcpclient.Manager from context")
+			return nil, fmt.Errorf("no mcp client manager configured")
 		}
 
 		client, ok := manager.Get(serverName)
@@ -57,10 +59,16 @@ func NewMcpServerParser(ctx context.Context, serverName string) PrefixParser {
 	}
 }
 
+// DeclaredOutputs returns the outputs of the step: the content of the result
+// of the tool call.
+func (s *McpStep) DeclaredOutputs() DeclaredOutputs {
+	return DeclaredOutputs{Type: s.serverName + "." + s.toolName, Keys: []string{"content"}}
+}
+
 func (s *McpStep) Execute(ctx context.Context, input *StepInput) (*StepOutput, error) {
-	manager, ok := mcpclient.ManagerFromContext(ctx)
+	manager, ok := input.Deps.McpManager()
 	if !ok {
-		return nil, fmt.Errorf("unable to get mcpclient.Manager from context")
+		return nil, fmt.Errorf("no mcp client manager configured")
 	}
 
 	client, ok := manager.Get(s.serverName)
@@ -0,0 +1,363 @@
+package steps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+)
+
+const (
+	NumberSourceOutput     = "output"
+	NumberSourceToolResult = "toolResult"
+)
+
+// NumberAssertion checks that a number differs from Equals by at most
+// Tolerance plus RelativeTolerance times the absolute value of Equals.
+// Without tolerances the number must equal Equals.
+type NumberAssertion struct {
+	Equals            float64 `json:"equals"`
+	Tolerance         float64 `json:"tolerance,omitempty"`
+	RelativeTolerance float64 `json:"relativeTolerance,omitempty"`
+	// Unit is the unit of Equals and Tolerance. Numbers with another unit of
+	// the same kind, such as MiB for GiB or ms for s, are converted to it, and
+	// numbers without a unit are taken to be in it.
+	Unit string `json:"unit,omitempty"`
+}
+
+// NumberStepConfig extracts a number from the final message of the agent or
+// from the results of its tool calls and checks it.
+type NumberStepConfig struct {
+	NumberAssertion
+	// Source is where the number is extracted from: "output" (the default)
+	// for the final message, or "toolResult" for tool call results
+	Source string `json:"source,omitempty"`
+	// Tool is a regular expression the title of tool calls must match for
+	// their results to be searched. Only valid with the toolResult source
+	Tool string `json:"tool,omitempty"`
+	// Match is a regular expression that narrows the search to its first
+	// group, or the whole match if it has no groups
+	Match string `json:"match,omitempty"`
+}
+
+type NumberStep struct {
+	assertion NumberAssertion
+	source    string
+	tool      *regexp.Regexp
+	match     *regexp.Regexp
+}
+
+var (
+	_ StepRunner     = &NumberStep{}
+	_ OutputDeclarer = &NumberStep{}
+)
+
+type unitInfo struct {
+	kind   string
+	factor float64
+}
+
+// units maps the units numbers can be converted between to their kind and
+// their size in the base unit of the kind: bytes, seconds or percent
+var units = map[string]unitInfo{
+	"B": {"bytes", 1}, "bytes": {"bytes", 1},
+	"kB": {"bytes", 1e3}, "KB": {"bytes", 1e3}, "MB": {"bytes", 1e6}, "GB": {"bytes", 1e9}, "TB": {"bytes", 1e12},
+	"Ki": {"bytes", 1 << 10}, "KiB": {"bytes", 1 << 10},
+	"Mi": {"bytes", 1 << 20}, "MiB": {"bytes", 1 << 20},
+	"Gi": {"bytes", 1 << 30}, "GiB": {"bytes", 1 << 30},
+	"Ti": {"bytes", 1 << 40}, "TiB": {"bytes", 1 << 40},
+
+	"ns": {"time", 1e-9}, "nanoseconds": {"time", 1e-9},
+	"us": {"time", 1e-6}, "µs": {"time", 1e-6}, "microseconds": {"time", 1e-6},
+	"ms": {"time", 1e-3}, "milliseconds": {"time", 1e-3},
+	"s": {"time", 1}, "sec": {"time", 1}, "second": {"time", 1}, "seconds": {"time", 1},
+	"min": {"time", 60}, "minute": {"time", 60}, "minutes": {"time", 60},
+	"h": {"time", 3600}, "hour": {"time", 3600}, "hours": {"time", 3600},
+
+	"%": {"percent", 1}, "percent": {"percent", 1},
+}
+
+// quantityPattern matches a number, optionally with thousands separators, and
+// the word or percent sign that follows it, which may be its unit
+var quantityPattern = regexp.MustCompile(`(?:^|[^\w.])((-?(?:\d{1,3}(?:,\d{3})+|\d+)(?:\.\d+)?(?:[eE][-+]?\d+)?)(?:\s?(%|[A-Za-zµ]+))?)`)
+
+// exactQuantityPattern matches a string that holds only a number and its unit
+var exactQuantityPattern = regexp.MustCompile(`^\s*(-?[\d,]*\.?\d+(?:[eE][-+]?\d+)?)\s*(%|[A-Za-zµ]+)?\s*$`)
+
+// quantity is a number found in text, with the unit that followed it
+type quantity struct {
+	text  string
+	value float64
+	unit  string
+}
+
+func ParseNumberStep(raw json.RawMessage) (StepRunner, error) {
+	cfg := &NumberStepConfig{}
+
+	err := json.Unmarshal(raw, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewNumberStep(cfg)
+}
+
+func NewNumberStep(cfg *NumberStepConfig) (*NumberStep, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	step := &NumberStep{assertion: cfg.NumberAssertion, source: cfg.Source}
+	if step.source == "" {
+		step.source = NumberSourceOutput
+	}
+
+	var err error
+	if cfg.Tool != "" {
+		if step.tool, err = regexp.Compile(cfg.Tool); err != nil {
+			return nil, fmt.Errorf("invalid tool regex %q: %w", cfg.Tool, err)
+		}
+	}
+	if cfg.Match != "" {
+		if step.match, err = regexp.Compile(cfg.Match); err != nil {
+			return nil, fmt.Errorf("invalid match regex %q: %w", cfg.Match, err)
+		}
+	}
+
+	return step, nil
+}
+
+func (cfg *NumberStepConfig) Validate() error {
+	switch cfg.Source {
+	case "", NumberSourceOutput:
+		if cfg.Tool != "" {
+			return fmt.Errorf("tool is only valid with source %q", NumberSourceToolResult)
+		}
+	case NumberSourceToolResult:
+	default:
+		return fmt.Errorf("invalid source %q: must be %q or %q", cfg.Source, NumberSourceOutput, NumberSourceToolResult)
+	}
+
+	return cfg.NumberAssertion.Validate()
+}
+
+// DeclaredOutputs returns the outputs of the step: the number it extracted,
+// in the unit of the step, and the text it was extracted from.
+func (s *NumberStep) DeclaredOutputs() DeclaredOutputs {
+	return DeclaredOutputs{Type: "number", Keys: []string{"value", "text"}}
+}
+
+func (s *NumberStep) Execute(ctx context.Context, input *StepInput) (*StepOutput, error) {
+	if input.Agent == nil {
+		return nil, fmt.Errorf("cannot run number step before agent (must be in verification)")
+	}
+
+	out := &StepOutput{Type: "number"}
+
+	texts, where := s.texts(input.Agent)
+	for _, text := range texts {
+		q, ok := s.find(text)
+		if !ok {
+			continue
+		}
+
+		value, err := s.assertion.convert(q.value, q.unit)
+		if err == nil {
+			out.Outputs = map[string]string{"value": formatNumber(value), "text": q.text}
+			err = s.assertion.Check(value)
+		}
+		if err != nil {
+			out.Error = fmt.Sprintf("number %q in %s: %s", q.text, where, err)
+			return out, nil
+		}
+
+		out.Success = true
+		out.Message = fmt.Sprintf("number %q in %s is within tolerance of %s", q.text, where, s.assertion.expected())
+		return out, nil
+	}
+
+	out.Error = fmt.Sprintf("no number found in %s", where)
+	if s.match != nil {
+		out.Error = fmt.Sprintf("no number found in %s matching %q", where, s.match)
+	}
+	return out, nil
+}
+
+// texts returns the texts to search for the number, in order, and what they
+// are for messages.
+func (s *NumberStep) texts(agentCtx *AgentContext) ([]string, string) {
+	if s.source == NumberSourceOutput {
+		return []string{agentCtx.Output}, "the final message"
+	}
+
+	// Search the most recent results first, as they reflect the latest state
+	var texts []string
+	for i := len(agentCtx.ToolCalls) - 1; i >= 0; i-- {
+		call := agentCtx.ToolCalls[i]
+		if s.tool != nil && !s.tool.MatchString(call.Title) {
+			continue
+		}
+		if text := toolResultText(call); text != "" {
+			texts = append(texts, text)
+		}
+	}
+
+	if s.tool != nil {
+		return texts, fmt.Sprintf("the results of tool calls matching %q", s.tool)
+	}
+	return texts, "the tool call results"
+}
+
+// find returns the first number in text, or in the part that s.match selects,
+// that has the unit of the step or one of the same kind. Failing that, it
+// returns the first number without a known unit.
+func (s *NumberStep) find(text string) (quantity, bool) {
+	if s.match != nil {
+		groups := s.match.FindStringSubmatch(text)
+		if groups == nil {
+			return quantity{}, false
+		}
+		text = groups[0]
+		if len(groups) > 1 {
+			text = groups[1]
+		}
+	}
+
+	var fallback *quantity
+	for _, m := range quantityPattern.FindAllStringSubmatch(text, -1) {
+		value, err := strconv.ParseFloat(strings.ReplaceAll(m[2], ",", ""), 64)
+		if err != nil {
+			continue
+		}
+		q := quantity{text: m[1], value: value, unit: m[3]}
+
+		if _, known := units[q.unit]; !known {
+			q.unit = ""
+		}
+		if s.assertion.Unit == "" || (q.unit != "" && units[q.unit].kind == units[s.assertion.Unit].kind) {
+			return q, true
+		}
+		if q.unit == "" && fallback == nil {
+			fallback = &q
+		}
+	}
+
+	if fallback != nil {
+		return *fallback, true
+	}
+	return quantity{}, false
+}
+
+// toolResultText returns the text content of the result of a tool call, or
+// the result as JSON if it has none.
+func toolResultText(call agent.ToolCallSummary) string {
+	switch v := call.RawOutput.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]any:
+		if content, ok := v["content"].([]any); ok {
+			var texts []string
+			for _, c := range content {
+				if part, ok := c.(map[string]any); ok && part["type"] == "text" {
+					if text, ok := part["text"].(string); ok {
+						texts = append(texts, text)
+					}
+				}
+			}
+			if len(texts) > 0 {
+				return strings.Join(texts, "\n")
+			}
+		}
+	}
+
+	data, err := json.Marshal(call.RawOutput)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func (n *NumberAssertion) Validate() error {
+	if n == nil {
+		return nil
+	}
+	if n.Tolerance < 0 || n.RelativeTolerance < 0 {
+		return fmt.Errorf("tolerances must not be negative")
+	}
+	if _, ok := units[n.Unit]; n.Unit != "" && !ok {
+		return fmt.Errorf("unknown unit %q", n.Unit)
+	}
+	return nil
+}
+
+// Check returns an error if actual is not within the tolerance of n.
+func (n *NumberAssertion) Check(actual float64) error {
+	allowed := n.Tolerance + n.RelativeTolerance*math.Abs(n.Equals)
+	if diff := math.Abs(actual - n.Equals); diff > allowed || math.IsNaN(diff) {
+		if allowed == 0 {
+			return fmt.Errorf("expected %s, got %s", n.expected(), n.format(actual))
+		}
+		return fmt.Errorf("expected %s ± %s, got %s", n.expected(), n.format(allowed), n.format(actual))
+	}
+	return nil
+}
+
+// CheckString parses s as a number, ignoring thousands separators and
+// converting it from the unit that follows it, and checks it.
+func (n *NumberAssertion) CheckString(s string) error {
+	m := exactQuantityPattern.FindStringSubmatch(s)
+	if m == nil {
+		return fmt.Errorf("%q is not a number", s)
+	}
+	actual, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64)
+	if err != nil {
+		return fmt.Errorf("%q is not a number", s)
+	}
+	if actual, err = n.convert(actual, m[2]); err != nil {
+		return err
+	}
+	return n.Check(actual)
+}
+
+// convert converts value from unit to the unit of n. Values without a unit,
+// and all values when n has no unit, are returned as is.
+func (n *NumberAssertion) convert(value float64, unit string) (float64, error) {
+	if n.Unit == "" || unit == "" || unit == n.Unit {
+		return value, nil
+	}
+
+	from, ok := units[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", unit)
+	}
+	to := units[n.Unit]
+	if from.kind != to.kind {
+		return 0, fmt.Errorf("cannot convert %s to %s", unit, n.Unit)
+	}
+	return value * from.factor / to.factor, nil
+}
+
+func (n *NumberAssertion) expected() string {
+	return n.format(n.Equals)
+}
+
+func (n *NumberAssertion) format(f float64) string {
+	if n.Unit == "" {
+		return formatNumber(f)
+	}
+	if n.Unit == "%" {
+		return formatNumber(f) + "%"
+	}
+	return formatNumber(f) + " " + n.Unit
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
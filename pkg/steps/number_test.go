@@ -0,0 +1,182 @@
+package steps
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumberAssertion_CheckString(t *testing.T) {
+	tt := map[string]struct {
+		assertion NumberAssertion
+		value     string
+		wantErr   string
+	}{
+		"exact": {
+			assertion: NumberAssertion{Equals: 3},
+			value:     "3",
+		},
+		"thousands separators": {
+			assertion: NumberAssertion{Equals: 1240},
+			value:     "1,240",
+		},
+		"converted unit": {
+			assertion: NumberAssertion{Equals: 1.5, Unit: "GiB"},
+			value:     "1536Mi",
+		},
+		"no unit is taken to be in the unit": {
+			assertion: NumberAssertion{Equals: 250, Tolerance: 10, Unit: "ms"},
+			value:     "255",
+		},
+		"outside tolerance with unit": {
+			assertion: NumberAssertion{Equals: 0.5, Tolerance: 0.1, Unit: "s"},
+			value:     "750ms",
+			wantErr:   "expected 0.5 s ± 0.1 s, got 0.75 s",
+		},
+		"unit of another kind": {
+			assertion: NumberAssertion{Equals: 1, Unit: "GB"},
+			value:     "1 s",
+			wantErr:   "cannot convert s to GB",
+		},
+		"unknown unit": {
+			assertion: NumberAssertion{Equals: 1, Unit: "GB"},
+			value:     "1 parsecs",
+			wantErr:   `unknown unit "parsecs"`,
+		},
+		"not a number": {
+			assertion: NumberAssertion{Equals: 1},
+			value:     "one",
+			wantErr:   `"one" is not a number`,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			err := tc.assertion.CheckString(tc.value)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNumberStepConfig_Validate(t *testing.T) {
+	tt := map[string]struct {
+		config  *NumberStepConfig
+		wantErr string
+	}{
+		"output": {
+			config: &NumberStepConfig{NumberAssertion: NumberAssertion{Equals: 1}},
+		},
+		"tool result": {
+			config: &NumberStepConfig{NumberAssertion: NumberAssertion{Equals: 1}, Source: NumberSourceToolResult, Tool: "prometheus_query"},
+		},
+		"invalid source": {
+			config:  &NumberStepConfig{Source: "stdout"},
+			wantErr: `invalid source "stdout"`,
+		},
+		"tool with output source": {
+			config:  &NumberStepConfig{Tool: "prometheus_query"},
+			wantErr: `tool is only valid with source "toolResult"`,
+		},
+		"unknown unit": {
+			config:  &NumberStepConfig{NumberAssertion: NumberAssertion{Unit: "furlongs"}},
+			wantErr: `unknown unit "furlongs"`,
+		},
+		"negative tolerance": {
+			config:  &NumberStepConfig{NumberAssertion: NumberAssertion{RelativeTolerance: -0.1}},
+			wantErr: "tolerances must not be negative",
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewNumberStep(tc.config)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNumberStep_Execute(t *testing.T) {
+	toolCalls := []agent.ToolCallSummary{
+		{Title: "prometheus_query", RawOutput: map[string]any{
+			"content": []any{map[string]any{"type": "text", "text": "p99 latency: 310ms"}},
+		}},
+		{Title: "pods_top", RawOutput: "NAME   CPU   MEMORY\nweb-1  12m   1536Mi"},
+		{Title: "prometheus_query", RawOutput: map[string]any{
+			"content": []any{map[string]any{"type": "text", "text": "p99 latency: 0.42s"}},
+		}},
+	}
+
+	tt := map[string]struct {
+		config      *NumberStepConfig
+		output      string
+		wantErr     string
+		wantOutputs map[string]string
+	}{
+		"first number in the output": {
+			config:      &NumberStepConfig{NumberAssertion: NumberAssertion{Equals: 3}},
+			output:      "There are 3 pods using 1.5 GiB.",
+			wantOutputs: map[string]string{"value": "3", "text": "3 pods"},
+		},
+		"number with the unit of the step": {
+			config:      &NumberStepConfig{NumberAssertion: NumberAssertion{Equals: 1.5, RelativeTolerance: 0.1, Unit: "GiB"}},
+			output:      "There are 3 pods using 1,600 MiB.",
+			wantOutputs: map[string]string{"value": "1.5625", "text": "1,600 MiB"},
+		},
+		"match narrows the search": {
+			config:      &NumberStepConfig{NumberAssertion: NumberAssertion{Equals: 45, Tolerance: 5, Unit: "%"}, Match: `CPU.*`},
+			output:      "Memory is at 80%. CPU usage is 47.5%.",
+			wantOutputs: map[string]string{"value": "47.5", "text": "47.5%"},
+		},
+		"outside tolerance": {
+			config:  &NumberStepConfig{NumberAssertion: NumberAssertion{Equals: 250, Tolerance: 20, Unit: "ms"}},
+			output:  "The p99 latency is 0.3 seconds.",
+			wantErr: `number "0.3 seconds" in the final message: expected 250 ms ± 20 ms, got 300 ms`,
+		},
+		"no number": {
+			config:  &NumberStepConfig{NumberAssertion: NumberAssertion{Equals: 3}},
+			output:  "There are no pods.",
+			wantErr: "no number found in the final message",
+		},
+		"most recent tool result": {
+			config:      &NumberStepConfig{NumberAssertion: NumberAssertion{Equals: 400, Tolerance: 50, Unit: "ms"}, Source: NumberSourceToolResult, Tool: "^prometheus"},
+			wantOutputs: map[string]string{"value": "420", "text": "0.42s"},
+		},
+		"tool result with the unit": {
+			config:      &NumberStepConfig{NumberAssertion: NumberAssertion{Equals: 1.5, Unit: "Gi"}, Source: NumberSourceToolResult, Tool: "pods_top"},
+			wantOutputs: map[string]string{"value": "1.5", "text": "1536Mi"},
+		},
+		"no matching tool call": {
+			config:  &NumberStepConfig{NumberAssertion: NumberAssertion{Equals: 1}, Source: NumberSourceToolResult, Tool: "nodes_top"},
+			wantErr: `no number found in the results of tool calls matching "nodes_top"`,
+		},
+	}
+
+	for name, tc := range tt {
+		t.Run(name, func(t *testing.T) {
+			step, err := NewNumberStep(tc.config)
+			require.NoError(t, err)
+
+			out, err := step.Execute(context.Background(), &StepInput{Agent: &AgentContext{Output: tc.output, ToolCalls: toolCalls}})
+			require.NoError(t, err)
+			assert.Equal(t, "number", out.Type)
+			if tc.wantErr != "" {
+				assert.False(t, out.Success)
+				assert.Equal(t, tc.wantErr, out.Error)
+				return
+			}
+			assert.True(t, out.Success, out.Error)
+			assert.Equal(t, tc.wantOutputs, out.Outputs)
+		})
+	}
+}
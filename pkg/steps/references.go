@@ -0,0 +1,69 @@
+package steps
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/genmcp/gen-mcp/pkg/template"
+)
+
+// DeclaredOutputs describes the outputs that a step sets when it succeeds, so
+// that {steps.*} references to them can be checked before the step runs.
+type DeclaredOutputs struct {
+	// Type is the step type that the outputs are also recorded under, such as
+	// "llmJudge" or "kubernetes.create-namespace"
+	Type string
+
+	// Keys are the keys of the outputs the step sets
+	Keys []string
+
+	// Unknown is set if the outputs of the step are only known when it runs.
+	// Keys are ignored then.
+	Unknown bool
+}
+
+// Has reports whether the step can set the output key.
+func (o DeclaredOutputs) Has(key string) bool {
+	return o.Unknown || slices.Contains(o.Keys, key)
+}
+
+// OutputDeclarer is implemented by steps that know which outputs they set
+// before they run.
+type OutputDeclarer interface {
+	DeclaredOutputs() DeclaredOutputs
+}
+
+// StepReferencer is implemented by steps whose templates can reference the
+// outputs of other steps.
+type StepReferencer interface {
+	// StepReferences returns the fields of the {steps.<field>} references of
+	// the step, such as "create_ns.name", in the order they appear.
+	StepReferences() []string
+}
+
+// StepReferences returns the fields of the {steps.<field>} references in the
+// parsed templates, in the order they appear.
+func StepReferences(templates ...*template.ParsedTemplate) []string {
+	var refs []string
+	for _, t := range templates {
+		if t == nil {
+			continue
+		}
+		for _, v := range t.Variables {
+			if field, ok := strings.CutPrefix(v.Name, "steps."); ok && v.Type == template.VariableTypeSource {
+				refs = append(refs, field)
+			}
+		}
+	}
+	return refs
+}
+
+// SplitStepReference splits the field of a {steps.<field>} reference into the
+// step, a step ID or type, and the output key, like StepOutputResolver does.
+func SplitStepReference(field string) (step, key string, ok bool) {
+	i := strings.LastIndex(field, ".")
+	if i <= 0 || i == len(field)-1 {
+		return "", "", false
+	}
+	return field[:i], field[i+1:], true
+}
@@ -7,12 +7,19 @@ import (
 	"maps"
 	"strings"
 	"sync"
+	"time"
 )
 
 type Parser func(raw json.RawMessage) (StepRunner, error)
 
 type PrefixParser func(suffix string, raw json.RawMessage) (StepRunner, error)
 
+// timeoutSetter is implemented by steps whose timeout can be set with the
+// timeout field of their StepConfig.
+type timeoutSetter interface {
+	setTimeout(timeout time.Duration)
+}
+
 type Registry struct {
 	mu            sync.RWMutex
 	parsers       map[string]Parser
@@ -47,7 +54,7 @@ func (r *Registry) RegisterPrefix(prefix string, parser PrefixParser) error {
 	return nil
 }
 
-func (r *Registry) WithExtensions(ctx context.Context, aliases map[string]string) *Registry {
+func (r *Registry) WithExtensions(ctx context.Context, deps *Dependencies, aliases map[string]string) *Registry {
 	r.mu.RLock()
 	reg := &Registry{
 		parsers:       make(map[string]Parser, len(r.parsers)),
@@ -58,13 +65,13 @@ func (r *Registry) WithExtensions(ctx context.Context, aliases map[string]string
 	r.mu.RUnlock()
 
 	for alias, extension := range aliases {
-		reg.prefixParsers[alias] = NewExtensionParser(ctx, extension)
+		reg.prefixParsers[alias] = NewExtensionParser(ctx, deps, extension)
 	}
 
 	return reg
 }
 
-func (r *Registry) WithMcpServers(ctx context.Context, aliases map[string]string) *Registry {
+func (r *Registry) WithMcpServers(ctx context.Context, deps *Dependencies, aliases map[string]string) *Registry {
 	r.mu.RLock()
 	reg := &Registry{
 		parsers:       make(map[string]Parser, len(r.parsers)),
@@ -75,7 +82,7 @@ func (r *Registry) WithMcpServers(ctx context.Context, aliases map[string]string
 	r.mu.RUnlock()
 
 	for alias, mcpServer := range aliases {
-		reg.prefixParsers[alias] = NewMcpServerParser(ctx, mcpServer)
+		reg.prefixParsers[alias] = NewMcpServerParser(ctx, deps, mcpServer)
 	}
 
 	return reg
@@ -87,16 +94,48 @@ func (r *Registry) Parse(cfg *StepConfig) (StepRunner, error) {
 	}
 
 	for stepType, stepCfg := range cfg.Config {
+		var runner StepRunner
+		var err error
 		if strings.Contains(stepType, ".") {
-			return r.parsePrefix(stepType, stepCfg)
+			runner, err = r.parsePrefix(stepType, stepCfg)
+		} else {
+			runner, err = r.parse(stepType, stepCfg)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if cfg.Timeout != "" {
+			if err := applyTimeout(runner, stepType, cfg.Timeout); err != nil {
+				return nil, err
+			}
 		}
 
-		return r.parse(stepType, stepCfg)
+		return runner, nil
 	}
 
 	return nil, fmt.Errorf("no step type found")
 }
 
+// applyTimeout sets the timeout of the step config on runner.
+func applyTimeout(runner StepRunner, stepType, timeout string) error {
+	setter, ok := runner.(timeoutSetter)
+	if !ok {
+		return fmt.Errorf("timeout is not supported for %s steps", stepType)
+	}
+
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return fmt.Errorf("failed to parse timeout: %w", err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("timeout must be positive, got %s", timeout)
+	}
+
+	setter.setTimeout(d)
+	return nil
+}
+
 func (r *Registry) parse(stepType string, stepCfg json.RawMessage) (StepRunner, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
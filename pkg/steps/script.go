@@ -4,13 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
+	"slices"
 	"time"
 
 	"github.com/genmcp/gen-mcp/pkg/template"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 // TODO: Add template support for File and Inline fields once we figure out
@@ -29,9 +31,16 @@ type ScriptStep struct {
 	Env             map[string]*template.TemplateBuilder
 	Timeout         time.Duration
 	ContinueOnError bool
+
+	// refs are the {steps.*} references of Env
+	refs []string
 }
 
-var _ StepRunner = &ScriptStep{}
+var (
+	_ StepRunner     = &ScriptStep{}
+	_ OutputDeclarer = &ScriptStep{}
+	_ StepReferencer = &ScriptStep{}
+)
 
 func ParseScriptStep(raw json.RawMessage) (StepRunner, error) {
 	cfg := &ScriptStepConfig{}
@@ -57,11 +66,13 @@ func NewScriptStep(cfg *ScriptStepConfig) (*ScriptStep, error) {
 	parseOpts := template.TemplateParserOptions{Sources: sources}
 
 	env := make(map[string]*template.TemplateBuilder, len(cfg.Env))
-	for k, v := range cfg.Env {
-		parsed, err := template.ParseTemplate(v, parseOpts)
+	var refs []string
+	for _, k := range slices.Sorted(maps.Keys(cfg.Env)) {
+		parsed, err := template.ParseTemplate(cfg.Env[k], parseOpts)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse env var %q template: %w", k, err)
 		}
+		refs = append(refs, StepReferences(parsed)...)
 		builder, err := template.NewTemplateBuilder(parsed, false)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create template builder for env var %q: %w", k, err)
@@ -74,6 +85,7 @@ func NewScriptStep(cfg *ScriptStepConfig) (*ScriptStep, error) {
 		Inline:          cfg.Inline,
 		Env:             env,
 		ContinueOnError: cfg.ContinueOnError,
+		refs:            refs,
 	}
 
 	if cfg.Timeout != "" {
@@ -89,6 +101,16 @@ func NewScriptStep(cfg *ScriptStepConfig) (*ScriptStep, error) {
 	return step, nil
 }
 
+// DeclaredOutputs returns the outputs of the step: scripts set none.
+func (s *ScriptStep) DeclaredOutputs() DeclaredOutputs {
+	return DeclaredOutputs{Type: "script"}
+}
+
+// StepReferences returns the {steps.*} references of the env of the step.
+func (s *ScriptStep) StepReferences() []string {
+	return s.refs
+}
+
 func (s *ScriptStep) Execute(ctx context.Context, input *StepInput) (*StepOutput, error) {
 	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
 	defer cancel()
@@ -110,7 +132,18 @@ func (s *ScriptStep) Execute(ctx context.Context, input *StepInput) (*StepOutput
 		return s.handleError(fmt.Errorf("failed to resolve env templates: %w", err))
 	}
 
-	applyEnv(cmd, resolvedEnv)
+	// Tasks with a clock pass its time on, unless the step sets the variables itself
+	env := input.Clock.Env()
+	if env == nil {
+		env = resolvedEnv
+	} else {
+		maps.Copy(env, resolvedEnv)
+	}
+
+	if len(env) > 0 {
+		util.DebugDirFromContext(ctx).WriteJSON("env.json", env)
+	}
+	applyEnv(cmd, env)
 
 	out, err := cmd.CombinedOutput()
 	if err != nil {
@@ -125,41 +158,9 @@ func (s *ScriptStep) Execute(ctx context.Context, input *StepInput) (*StepOutput
 }
 
 // createInlineCommand executes inline scripts with shebang support.
-// Scripts with shebangs are written to temp files in the current directory to preserve relative paths.
+// Scripts with shebangs are written to temp files in the workdir to preserve relative paths.
 func (s *ScriptStep) createInlineCommand(ctx context.Context, workdir string) (*exec.Cmd, error) {
-	if strings.HasPrefix(strings.TrimSpace(s.Inline), "#!") {
-		tmpFile, err := os.CreateTemp(workdir, ".mcpchecker-step-*.sh")
-		if err != nil {
-			return nil, fmt.Errorf("failed to create temp script file: %w", err)
-		}
-		tmpPath := tmpFile.Name()
-
-		if _, err := tmpFile.WriteString(s.Inline); err != nil {
-			tmpFile.Close()
-			os.Remove(tmpPath)
-			return nil, fmt.Errorf("failed to write temp script: %w", err)
-		}
-		tmpFile.Close()
-
-		if err := ensureExecutable(tmpPath); err != nil {
-			os.Remove(tmpPath)
-			return nil, err
-		}
-
-		cmd := exec.CommandContext(ctx, tmpPath)
-		cmd.Dir = workdir
-		go func() {
-			<-ctx.Done()
-			os.Remove(tmpPath)
-		}()
-		return cmd, nil
-	}
-
-	shell := getShell()
-	cmd := exec.CommandContext(ctx, shell)
-	cmd.Stdin = strings.NewReader(s.Inline)
-	cmd.Dir = workdir
-	return cmd, nil
+	return util.InlineScriptCommand(ctx, workdir, s.Inline)
 }
 
 // createFileCommand executes a script file directly to respect its shebang.
@@ -171,14 +172,7 @@ func (s *ScriptStep) createFileCommand(ctx context.Context, workdir string) (*ex
 		file = filepath.Join(workdir, file)
 	}
 
-	if err := ensureExecutable(file); err != nil {
-		return nil, err
-	}
-
-	cmd := exec.CommandContext(ctx, file)
-	// Set working directory to the script's directory so relative paths work
-	cmd.Dir = filepath.Dir(file)
-	return cmd, nil
+	return util.ScriptFileCommand(ctx, file)
 }
 
 func (s *ScriptStep) handleError(err error) (*StepOutput, error) {
@@ -192,23 +186,6 @@ func (s *ScriptStep) handleError(err error) (*StepOutput, error) {
 	return nil, err
 }
 
-func ensureExecutable(path string) error {
-	info, err := os.Stat(path)
-	if err != nil {
-		return fmt.Errorf("failed to stat file: %w", err)
-	}
-
-	if info.Mode()&0100 != 0 {
-		return nil
-	}
-
-	if err := os.Chmod(path, info.Mode()|0111); err != nil {
-		return fmt.Errorf("failed to make script executable: %w", err)
-	}
-
-	return nil
-}
-
 func (cfg *ScriptStepConfig) Validate() error {
 	numDefined := 0
 	if cfg.File != "" {
@@ -276,12 +253,3 @@ func applyEnv(cmd *exec.Cmd, env map[string]string) {
 		cmd.Env = append(cmd.Env, k+"="+v)
 	}
 }
-
-func getShell() string {
-	shell, ok := os.LookupEnv("SHELL")
-	if !ok {
-		shell = "/usr/bin/bash"
-	}
-
-	return shell
-}
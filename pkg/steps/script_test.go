@@ -5,9 +5,12 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 func TestScriptStepConfig_Validate(t *testing.T) {
@@ -272,3 +275,21 @@ func TestScriptStep_Execute_File(t *testing.T) {
 		})
 	}
 }
+
+func TestScriptStep_ExecuteClock(t *testing.T) {
+	ctx := context.Background()
+	clock := util.NewClock(time.Date(2025, 1, 31, 23, 59, 0, 0, time.UTC))
+
+	step, err := NewScriptStep(&ScriptStepConfig{Inline: `echo "$MCPCHECKER_NOW"`})
+	require.NoError(t, err)
+	got, err := step.Execute(ctx, &StepInput{Clock: clock})
+	require.NoError(t, err)
+	assert.Contains(t, got.Message, "2025-01-31T23:5")
+
+	// The env of the step takes precedence over the clock
+	step, err = NewScriptStep(&ScriptStepConfig{Inline: `echo "$FAKETIME"`, Env: map[string]string{"FAKETIME": "+0"}})
+	require.NoError(t, err)
+	got, err = step.Execute(ctx, &StepInput{Clock: clock})
+	require.NoError(t, err)
+	assert.Equal(t, "+0\n", got.Message)
+}
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
 	"github.com/mcpchecker/mcpchecker/pkg/tokens"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 const (
@@ -26,27 +28,43 @@ type StepRunner interface {
 type StepInput struct {
 	Workdir     string
 	Agent       *AgentContext
-	StepOutputs map[string]map[string]string // Maps step type to its outputs
+	StepOutputs map[string]map[string]string // Maps step ID (and step type) to its outputs
 	Random      *RandomResolver              // Memoized random value generator
+	Deps        *Dependencies                // Shared managers and judge for the run
+	Clock       *util.Clock                  // Clock of the task run, nil for the real time
 }
 
 type StepOutput struct {
+	ID      string            `json:"id,omitempty"`
 	Type    string            `json:"type,omitempty"`
 	Success bool              `json:"success"`
 	Message string            `json:"message,omitempty"`
 	Outputs map[string]string `json:"outputs,omitempty"`
 	Error   string            `json:"error,omitempty"`
 	Usage   *tokens.Usage     `json:"usage,omitempty"`
+	Logs    []StepLog         `json:"logs,omitempty"`
+}
+
+// StepLog is a log message sent by an extension while it ran a step
+type StepLog struct {
+	Level   string         `json:"level"`
+	Message string         `json:"message"`
+	Data    map[string]any `json:"data,omitempty"`
 }
 
 type AgentContext struct {
-	Prompt string
-	Output string
+	Prompt    string
+	Output    string
+	ToolCalls []agent.ToolCallSummary
 }
 
 type StepConfig struct {
 	ID     string                     `json:"id"`
 	Config map[string]json.RawMessage `json:"_"`
+
+	// Timeout overrides the timeout of steps that support it, such as
+	// extension operations, as a duration string like "2m"
+	Timeout string `json:"timeout,omitempty"`
 }
 
 func (cfg *StepConfig) UnmarshalJSON(data []byte) error {
@@ -67,6 +85,7 @@ func (cfg *StepConfig) UnmarshalJSON(data []byte) error {
 	}
 
 	delete(rawMap, "id")
+	delete(rawMap, "timeout")
 
 	cfg.Config = rawMap
 
@@ -87,6 +106,14 @@ func (cfg *StepConfig) MarshalJSON() ([]byte, error) {
 		rawMap["id"] = idBytes
 	}
 
+	if cfg.Timeout != "" {
+		timeoutBytes, err := json.Marshal(cfg.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		rawMap["timeout"] = timeoutBytes
+	}
+
 	return json.Marshal(rawMap)
 }
 
@@ -94,4 +121,6 @@ func init() {
 	DefaultRegistry.Register("http", ParseHttpStep)
 	DefaultRegistry.Register("script", ParseScriptStep)
 	DefaultRegistry.Register("llmJudge", ParseLLMJudgeStep)
+	DefaultRegistry.Register("finalMessage", ParseFinalMessageStep)
+	DefaultRegistry.Register("number", ParseNumberStep)
 }
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"unicode"
 
 	"github.com/mcpchecker/mcpchecker/pkg/llmjudge"
 	"github.com/mcpchecker/mcpchecker/pkg/steps"
@@ -16,6 +18,13 @@ const (
 	DifficultyEasy   = "easy"
 	DifficultyMedium = "medium"
 	DifficultyHard   = "hard"
+
+	// StateActive tasks run and count towards pass-rate gates (default).
+	StateActive = "active"
+	// StateQuarantined tasks run and are reported, but never gate a run.
+	StateQuarantined = "quarantined"
+	// StateDeprecated tasks are skipped with a notice.
+	StateDeprecated = "deprecated"
 )
 
 type TaskConfig struct {
@@ -24,14 +33,75 @@ type TaskConfig struct {
 	Spec          *TaskSpec    `json:"spec"`
 
 	basePath string
+
+	// path is the file the task was read from, to point errors at their line.
+	// It is only set by FromFile.
+	path string
+
+	// template is the task as JSON, for tasks with parameters to expand
+	template []byte
+	// params are the values of the parameters of instances of task templates
+	params map[string]any
 }
 
 type TaskMetadata struct {
+	// ID is an optional stable identifier that keys the task's results instead of
+	// its name, so the task can be renamed without losing its history
+	ID         string            `json:"id,omitempty"`
 	Name       string            `json:"name"`
 	Difficulty string            `json:"difficulty"`
 	Labels     map[string]string `json:"labels,omitempty"`
 	Parallel   bool              `json:"parallel,omitempty"`
-	Runs       int               `json:"runs,omitempty"` // Number of times to run this task (default: 1)
+	Runs       int               `json:"runs,omitempty"`  // Number of times to run this task (default: 1)
+	State      string            `json:"state,omitempty"` // Lifecycle state: active (default), quarantined, or deprecated
+
+	// Priority orders parallel tasks: tasks with a higher priority are
+	// scheduled first, so their results arrive sooner (default: 0)
+	Priority int `json:"priority,omitempty"`
+
+	// ExpectFailure marks a task that is known to fail, e.g. because of a
+	// limitation of the agent, like pytest's xfail. Its failures don't gate a
+	// run, and runs that pass are flagged as unexpected passes.
+	ExpectFailure bool `json:"expectFailure,omitempty"`
+	// ExpectFailureReason says why the task is expected to fail, e.g. a link
+	// to the issue tracking it
+	ExpectFailureReason string `json:"expectFailureReason,omitempty"`
+}
+
+// GetState returns the lifecycle state of the task, defaulting to active.
+func (m *TaskMetadata) GetState() string {
+	if m.State == "" {
+		return StateActive
+	}
+
+	return m.State
+}
+
+// Key returns the ID of the task, or its name if it has no ID.
+func (m *TaskMetadata) Key() string {
+	if m.ID != "" {
+		return m.ID
+	}
+	return m.Name
+}
+
+// ValidateID checks that a task ID, if set, has no whitespace.
+func ValidateID(id string) error {
+	if strings.ContainsFunc(id, unicode.IsSpace) {
+		return fmt.Errorf("invalid task id %q: must not contain whitespace", id)
+	}
+	return nil
+}
+
+// ValidateState checks that a lifecycle state is one of the known values.
+// An empty state is valid and means active.
+func ValidateState(state string) error {
+	switch state {
+	case "", StateActive, StateQuarantined, StateDeprecated:
+		return nil
+	default:
+		return fmt.Errorf("unknown task state %q (must be one of %s, %s, %s)", state, StateActive, StateQuarantined, StateDeprecated)
+	}
 }
 
 type TaskSpec struct {
@@ -40,7 +110,21 @@ type TaskSpec struct {
 	Setup    []*steps.StepConfig `json:"setup,omitempty"`
 	Cleanup  []*steps.StepConfig `json:"cleanup,omitempty"`
 	Verify   []*steps.StepConfig `json:"verify,omitempty"`
-	Prompt   *util.Step          `json:"prompt,omitempty"`
+	Prompt   *Prompt             `json:"prompt,omitempty"`
+
+	// CleanupVerify checks that cleanup left nothing behind. Its steps only
+	// run with check --verify-cleanup, and each failing step is a leak.
+	CleanupVerify []*steps.StepConfig `json:"cleanupVerify,omitempty"`
+
+	// Interject makes the task multi-turn, see Interjection
+	Interject []*Interjection `json:"interject,omitempty"`
+
+	// Clock makes the task run at a fixed point in time
+	Clock *util.ClockConfig `json:"clock,omitempty"`
+
+	// Parameters makes the task a template, which expands into a task for
+	// every combination of the values of the parameters, see Instances
+	Parameters map[string][]any `json:"parameters,omitempty"`
 }
 
 type Requirements struct {
@@ -117,6 +201,13 @@ func Read(data []byte, basePath string) (*TaskConfig, error) {
 		return nil, err
 	}
 
+	if err := ValidateState(spec.Metadata.State); err != nil {
+		return nil, err
+	}
+	if err := ValidateID(spec.Metadata.ID); err != nil {
+		return nil, err
+	}
+
 	spec.basePath = basePath
 
 	if wrapper.GetAPIVersion() == util.APIVersionV1Alpha1 {
@@ -147,11 +238,38 @@ func Read(data []byte, basePath string) (*TaskConfig, error) {
 	}
 
 	if spec.Spec.Prompt != nil {
-		if err := util.ResolveRelativePath(&spec.Spec.Prompt.File, basePath); err != nil {
+		if err := spec.Spec.Prompt.Validate(); err != nil {
+			return nil, err
+		}
+		if err := spec.Spec.Prompt.resolveRelativePaths(basePath); err != nil {
 			return nil, fmt.Errorf("failed to resolve prompt path: %w", err)
 		}
 	}
 
+	if spec.Spec.Clock != nil {
+		if _, err := spec.Spec.Clock.GetStart(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(spec.Spec.Parameters) > 0 {
+		if spec.template, err = yaml.YAMLToJSON(data); err != nil {
+			return nil, err
+		}
+		if err := spec.validateParameters(); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, in := range spec.Spec.Interject {
+		if err := in.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid interject[%d]: %w", i, err)
+		}
+		if err := util.ResolveRelativePath(&in.Reply.File, basePath); err != nil {
+			return nil, fmt.Errorf("failed to resolve interject[%d] reply path: %w", i, err)
+		}
+	}
+
 	return spec, nil
 }
 
@@ -169,5 +287,21 @@ func FromFile(path string) (*TaskConfig, error) {
 
 	basePath := filepath.Dir(absPath)
 
-	return Read(data, basePath)
+	spec, err := Read(data, basePath)
+	if err != nil {
+		return nil, err
+	}
+	spec.path = path
+
+	return spec, nil
+}
+
+// DeprecationWarning returns a warning with the command that migrates the task
+// if it uses the deprecated v1alpha1 format, or "" if it doesn't.
+func (t *TaskConfig) DeprecationWarning() string {
+	if t.GetAPIVersion() != util.APIVersionV1Alpha1 {
+		return ""
+	}
+	return fmt.Sprintf("task %s uses the deprecated %s format, which will be removed in a future release.\n"+
+		"  Migrate it with: mcpchecker migrate task %s", t.path, util.APIVersionV1Alpha1, t.path)
 }
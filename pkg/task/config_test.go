@@ -68,11 +68,12 @@ func TestFromFile(t *testing.T) {
 							"script": json.RawMessage(`{"inline":"echo cleanup"}`),
 						},
 					}},
-					Prompt: &util.Step{
+					Prompt: &Prompt{Step: util.Step{
 						Inline: "Do something",
-					},
+					}},
 				},
 				basePath: basePath,
+				path:     filepath.Join(basePath, "task-with-limits.yaml"),
 			},
 		},
 		"create pod inline no verify": {
@@ -104,11 +105,12 @@ kubectl delete namespace create-pod-test --ignore-not-found`,
 							}),
 						},
 					}},
-					Prompt: &util.Step{
+					Prompt: &Prompt{Step: util.Step{
 						Inline: "Please create a nginx pod named web-server in the create-pod-test namespace",
-					},
+					}},
 				},
 				basePath: basePath,
+				path:     filepath.Join(basePath, "create-pod-inline-no-verify.yaml"),
 			},
 		},
 		"create pod inline": {
@@ -152,11 +154,12 @@ kubectl delete namespace create-pod-test --ignore-not-found`,
 							}),
 						},
 					}},
-					Prompt: &util.Step{
+					Prompt: &Prompt{Step: util.Step{
 						Inline: "Please create a nginx pod named web-server in the create-pod-test namespace",
-					},
+					}},
 				},
 				basePath: basePath,
+				path:     filepath.Join(basePath, "create-pod-inline.yaml"),
 			},
 		},
 	}
@@ -174,3 +177,159 @@ kubectl delete namespace create-pod-test --ignore-not-found`,
 		})
 	}
 }
+
+func TestDeprecationWarning(t *testing.T) {
+	legacy, err := FromFile(filepath.Join(testCasePath, "create-pod-inline.yaml"))
+	require.NoError(t, err)
+	warning := legacy.DeprecationWarning()
+	assert.Contains(t, warning, "uses the deprecated mcpchecker/v1alpha1 format")
+	assert.Contains(t, warning, "mcpchecker migrate task "+filepath.Join(testCasePath, "create-pod-inline.yaml"))
+
+	current, err := FromFile(filepath.Join(testCasePath, "task-with-limits.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, current.DeprecationWarning())
+}
+
+func TestReadTaskState(t *testing.T) {
+	tt := map[string]struct {
+		state     string
+		expected  string
+		expectErr bool
+	}{
+		"default is active": {
+			state:    "",
+			expected: StateActive,
+		},
+		"quarantined": {
+			state:    StateQuarantined,
+			expected: StateQuarantined,
+		},
+		"deprecated": {
+			state:    StateDeprecated,
+			expected: StateDeprecated,
+		},
+		"unknown state": {
+			state:     "retired",
+			expectErr: true,
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			data := fmt.Sprintf(`kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: stateful
+  state: %q
+spec:
+  verify:
+    - script:
+        inline: echo ok
+  prompt:
+    inline: Do something
+`, tc.state)
+
+			cfg, err := Read([]byte(data), "")
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, cfg.Metadata.GetState())
+		})
+	}
+}
+
+func TestReadTaskID(t *testing.T) {
+	tt := map[string]struct {
+		id          string
+		expectedKey string
+		expectErr   bool
+	}{
+		"no id keys by name": {
+			expectedKey: "renamable",
+		},
+		"id keys by id": {
+			id:          "k8s/create-pod",
+			expectedKey: "k8s/create-pod",
+		},
+		"id with whitespace": {
+			id:        "create pod",
+			expectErr: true,
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			data := fmt.Sprintf(`kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  id: %q
+  name: renamable
+spec:
+  verify:
+    - script:
+        inline: echo ok
+  prompt:
+    inline: Do something
+`, tc.id)
+
+			cfg, err := Read([]byte(data), "")
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.id, cfg.Metadata.ID)
+			assert.Equal(t, tc.expectedKey, cfg.Metadata.Key())
+		})
+	}
+}
+
+func TestReadTaskClock(t *testing.T) {
+	tt := map[string]struct {
+		clock     string
+		expected  *util.ClockConfig
+		expectErr bool
+	}{
+		"no clock": {},
+		"clock": {
+			clock:    "\n  clock:\n    start: 2025-01-31T23:59:00Z",
+			expected: &util.ClockConfig{Start: "2025-01-31T23:59:00Z"},
+		},
+		"invalid start": {
+			clock:     "\n  clock:\n    start: tomorrow",
+			expectErr: true,
+		},
+		"missing start": {
+			clock:     "\n  clock: {}",
+			expectErr: true,
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			data := `kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: expiry
+spec:
+  verify:
+    - script:
+        inline: echo ok
+  prompt:
+    inline: Which certificates expire this week?` + tc.clock + "\n"
+
+			cfg, err := Read([]byte(data), "")
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, cfg.Spec.Clock)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// ErrorKind classifies why a task run failed, so that problems with the test
+// environment can be told apart from genuine agent failures.
+type ErrorKind string
+
+const (
+	// ErrorKindSetup means the task could not be prepared: its steps could not
+	// be parsed or a setup step failed
+	ErrorKindSetup ErrorKind = "setup"
+	// ErrorKindAgent means the agent failed to run to completion
+	ErrorKindAgent ErrorKind = "agent"
+	// ErrorKindVerify means the agent ran but verification of its work failed
+	ErrorKindVerify ErrorKind = "verify"
+	// ErrorKindInfra means mcpchecker's own infrastructure failed, such as the
+	// MCP proxy servers or the LLM judge
+	ErrorKindInfra ErrorKind = "infra"
+)
+
+// ErrorKinds lists the error kinds in the order tasks run into them.
+var ErrorKinds = []ErrorKind{ErrorKindSetup, ErrorKindAgent, ErrorKindVerify, ErrorKindInfra}
+
+// SetupError is returned when a task could not be prepared.
+type SetupError struct {
+	Err error
+}
+
+func (e *SetupError) Error() string { return e.Err.Error() }
+func (e *SetupError) Unwrap() error { return e.Err }
+
+// AgentError is returned when the agent failed to run to completion.
+type AgentError struct {
+	Err error
+}
+
+func (e *AgentError) Error() string { return e.Err.Error() }
+func (e *AgentError) Unwrap() error { return e.Err }
+
+// VerifyError is returned when a verification step failed to run.
+type VerifyError struct {
+	Err error
+}
+
+func (e *VerifyError) Error() string { return e.Err.Error() }
+func (e *VerifyError) Unwrap() error { return e.Err }
+
+// InfraError is returned when mcpchecker's own infrastructure failed.
+type InfraError struct {
+	Err error
+}
+
+func (e *InfraError) Error() string { return e.Err.Error() }
+func (e *InfraError) Unwrap() error { return e.Err }
+
+// PanicError is returned when a step or the agent panicked, so that the task
+// run fails and its cleanup still runs, instead of mcpchecker crashing.
+type PanicError struct {
+	Value any
+	// Stack is the stack of the goroutine that panicked
+	Stack string
+}
+
+func (e *PanicError) Error() string { return fmt.Sprintf("panic: %v", e.Value) }
+
+// RecoverPanic turns a panic of the function it is deferred in into a
+// *PanicError in err:
+//
+//	defer task.RecoverPanic(&err)
+func RecoverPanic(err *error) {
+	if v := recover(); v != nil {
+		*err = &PanicError{Value: v, Stack: string(debug.Stack())}
+	}
+}
+
+// PanicStack returns the stack of the panic that caused err, or an empty
+// string if err is not caused by a panic.
+func PanicStack(err error) string {
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		return panicErr.Stack
+	}
+	return ""
+}
+
+// ErrorKindOf returns the kind of err, or an empty kind if err is nil or not
+// one of the typed task errors. An InfraError anywhere in the chain takes
+// precedence, since it means the other failures are not the task's fault.
+func ErrorKindOf(err error) ErrorKind {
+	if err == nil {
+		return ""
+	}
+
+	var infraErr *InfraError
+	var setupErr *SetupError
+	var agentErr *AgentError
+	var verifyErr *VerifyError
+	switch {
+	case errors.As(err, &infraErr):
+		return ErrorKindInfra
+	case errors.As(err, &setupErr):
+		return ErrorKindSetup
+	case errors.As(err, &agentErr):
+		return ErrorKindAgent
+	case errors.As(err, &verifyErr):
+		return ErrorKindVerify
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorKindOf(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		expected ErrorKind
+	}{
+		"nil error": {
+			err:      nil,
+			expected: "",
+		},
+		"untyped error": {
+			err:      errors.New("boom"),
+			expected: "",
+		},
+		"setup error": {
+			err:      &SetupError{Err: errors.New("boom")},
+			expected: ErrorKindSetup,
+		},
+		"wrapped agent error": {
+			err:      fmt.Errorf("task failed: %w", &AgentError{Err: errors.New("boom")}),
+			expected: ErrorKindAgent,
+		},
+		"verify error": {
+			err:      &VerifyError{Err: errors.New("boom")},
+			expected: ErrorKindVerify,
+		},
+		"infra error inside a setup error": {
+			err:      &SetupError{Err: &InfraError{Err: errors.New("proxy down")}},
+			expected: ErrorKindInfra,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ErrorKindOf(tc.err))
+		})
+	}
+}
+
+func TestTypedErrorsKeepMessage(t *testing.T) {
+	cause := errors.New("setup[0] failed: exit status 1")
+	err := &SetupError{Err: cause}
+
+	assert.Equal(t, cause.Error(), err.Error())
+	assert.ErrorIs(t, err, cause)
+}
+
+func TestRecoverPanic(t *testing.T) {
+	run := func() (err error) {
+		defer RecoverPanic(&err)
+		panic("nil map")
+	}
+
+	err := run()
+	var panicErr *PanicError
+	assert.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, "panic: nil map", err.Error())
+
+	stack := PanicStack(fmt.Errorf("setup[0] failed: %w", err))
+	assert.Contains(t, stack, "TestRecoverPanic")
+	assert.Empty(t, PanicStack(errors.New("boom")))
+}
@@ -0,0 +1,78 @@
+package task
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+)
+
+// Files returns the files that the task reads when it runs, other than the
+// task file itself: its prompt and reply files and the files of its script
+// steps. Paths are absolute if the task was read with an absolute base path.
+func (c *TaskConfig) Files() []string {
+	if c.Spec == nil {
+		return nil
+	}
+
+	var files []string
+	if p := c.Spec.Prompt; p != nil {
+		if p.File != "" {
+			files = append(files, p.File)
+		}
+		for _, locale := range p.LocaleNames() {
+			if file := p.Locales[locale].File; file != "" {
+				files = append(files, file)
+			}
+		}
+	}
+
+	phases := [][]*steps.StepConfig{c.Spec.Setup, c.Spec.Verify, c.Spec.Cleanup, c.Spec.CleanupVerify}
+	for _, in := range c.Spec.Interject {
+		if in == nil {
+			continue
+		}
+		phases = append(phases, in.Steps)
+		if in.Reply != nil && in.Reply.File != "" {
+			files = append(files, in.Reply.File)
+		}
+	}
+	for _, phase := range phases {
+		files = append(files, scriptFiles(phase, c.basePath)...)
+	}
+	return files
+}
+
+// Files returns the script files of the steps of the sequences of l.
+func (l *StepLibrary) Files() []string {
+	var files []string
+	for _, seq := range l.Spec.Sequences {
+		files = append(files, scriptFiles(seq.Steps, l.basePath)...)
+	}
+	return files
+}
+
+// scriptFiles returns the files of the script steps of cfgs, relative to
+// basePath, which script steps run in.
+func scriptFiles(cfgs []*steps.StepConfig, basePath string) []string {
+	var files []string
+	for _, cfg := range cfgs {
+		if cfg == nil {
+			continue
+		}
+		raw, ok := cfg.Config["script"]
+		if !ok {
+			continue
+		}
+		var script steps.ScriptStepConfig
+		if err := json.Unmarshal(raw, &script); err != nil || script.File == "" {
+			continue
+		}
+		file := script.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(basePath, file)
+		}
+		files = append(files, file)
+	}
+	return files
+}
@@ -0,0 +1,45 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFiles(t *testing.T) {
+	data := `kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: files
+spec:
+  setup:
+    - script:
+        file: setup.sh
+    - script:
+        inline: echo
+  verify:
+    - script:
+        file: /abs/verify.sh
+  prompt:
+    en:
+      file: prompts/en.md
+    de: Mach etwas
+  interject:
+    - steps:
+        - script:
+            file: check.sh
+      reply:
+        file: reply.md
+`
+	cfg, err := Read([]byte(data), "/tasks")
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{
+		"/tasks/prompts/en.md",
+		"/tasks/reply.md",
+		"/tasks/setup.sh",
+		"/abs/verify.sh",
+		"/tasks/check.sh",
+	}, cfg.Files())
+}
@@ -0,0 +1,244 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// agentBenchTask is a task of the OS interaction environment of AgentBench,
+// which asks the agent a question about, or a change to, a Linux machine.
+type agentBenchTask struct {
+	Description string `json:"description"`
+	Create      *struct {
+		Init json.RawMessage `json:"init"`
+	} `json:"create"`
+	Start      json.RawMessage `json:"start"`
+	Evaluation *struct {
+		Match   json.RawMessage   `json:"match"`
+		Check   []json.RawMessage `json:"check"`
+		Example json.RawMessage   `json:"example"`
+	} `json:"evaluation"`
+}
+
+// agentBenchScript is a script of an AgentBench task: a string of bash code,
+// or its code or file and language.
+type agentBenchScript struct {
+	Code     string `json:"code"`
+	File     string `json:"file"`
+	Language string `json:"language"`
+}
+
+// agentBenchMatch is the expected answer of an AgentBench task, as a string
+// or an object with the answer or a regular expression.
+type agentBenchMatch struct {
+	Answer *string `json:"answer"`
+	Regex  *string `json:"regex"`
+}
+
+// heredocDelimiter ends the here-documents that the code of AgentBench
+// scripts is embedded in, followed by a number if the code contains it
+const heredocDelimiter = "MCPCHECKER_EOF"
+
+// answerPrompt is appended to the prompts of AgentBench tasks, whose checks
+// compare the agent's response with the expected answer
+const answerPrompt = "If the task asks a question, reply with only the answer, without any explanation."
+
+// trimAnswer sets answer to the agent's response without surrounding spaces
+const trimAnswer = `answer="${ANSWER#"${ANSWER%%[![:space:]]*}"}"
+answer="${answer%"${answer##*[![:space:]]}"}"
+`
+
+// importAgentBench translates an AgentBench OS interaction task. The init
+// scripts of the task become setup scripts, and its check scripts or expected
+// answer a verify script, which gets the agent's response as the answer.
+func importAgentBench(i *importer, source string, index int, raw json.RawMessage) error {
+	var t agentBenchTask
+	if err := json.Unmarshal(raw, &t); err != nil || t.Description == "" || t.Evaluation == nil {
+		return nil
+	}
+	if isSet(t.Start) {
+		return fmt.Errorf("background processes started with start are not supported")
+	}
+
+	spec := map[string]any{
+		"prompt": map[string]any{"inline": t.Description + "\n\n" + answerPrompt},
+	}
+
+	if t.Create != nil && isSet(t.Create.Init) {
+		scripts, err := parseAgentBenchScripts(t.Create.Init)
+		if err != nil {
+			return fmt.Errorf("invalid create.init: %w", err)
+		}
+		var setup []any
+		for _, script := range scripts {
+			inline, err := i.agentBenchInline(script)
+			if err != nil {
+				return fmt.Errorf("invalid create.init: %w", err)
+			}
+			setup = append(setup, scriptStep(inline, nil))
+		}
+		spec["setup"] = setup
+	}
+
+	var verify string
+	var err error
+	switch {
+	case isSet(t.Evaluation.Match):
+		verify, err = agentBenchMatchScript(t.Evaluation.Match)
+	case len(t.Evaluation.Check) > 0:
+		verify, err = i.agentBenchCheckScript(t.Evaluation.Check, t.Evaluation.Example)
+	default:
+		return fmt.Errorf("evaluation has neither match nor check")
+	}
+	if err != nil {
+		return fmt.Errorf("invalid evaluation: %w", err)
+	}
+	spec["verify"] = []any{scriptStep(verify, map[string]string{"ANSWER": "{agent.output}"})}
+
+	name := taskName(fileBase(i.file), strconv.Itoa(index))
+	return i.add(source, name, map[string]string{"benchmark": "agentbench"}, spec)
+}
+
+// agentBenchMatchScript returns a verify script that compares the answer with
+// the expected answer match.
+func agentBenchMatchScript(raw json.RawMessage) (string, error) {
+	var match agentBenchMatch
+	var answer string
+	if err := json.Unmarshal(raw, &answer); err == nil {
+		match.Answer = &answer
+	} else if err := json.Unmarshal(raw, &match); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("#!/usr/bin/env bash\n")
+	b.WriteString(trimAnswer)
+	switch {
+	case match.Answer != nil:
+		writeHeredoc(&b, "expected", strings.TrimSpace(*match.Answer))
+		b.WriteString(`[ "$answer" = "$expected" ]` + "\n")
+	case match.Regex != nil:
+		writeHeredoc(&b, "pattern", *match.Regex)
+		b.WriteString(`python3 -c 'import re, sys; sys.exit(0 if re.search(sys.argv[1], sys.argv[2]) else 1)' "$pattern" "$answer"` + "\n")
+	default:
+		return "", fmt.Errorf("match has neither answer nor regex")
+	}
+	return b.String(), nil
+}
+
+// agentBenchCheckScript returns a verify script that runs the check scripts
+// of an AgentBench task in turn, as AgentBench does: each gets the answer and
+// the outputs of the scripts before it as arguments, and must succeed. A null
+// check runs the example script, which outputs the expected answer.
+func (i *importer) agentBenchCheckScript(checks []json.RawMessage, example json.RawMessage) (string, error) {
+	var b strings.Builder
+	b.WriteString("#!/usr/bin/env bash\n")
+	b.WriteString(trimAnswer)
+	b.WriteString(`params=("$answer")
+check() {
+	local out
+	out=$("$@" "${params[@]}") || exit 1
+	params+=("$out")
+}
+`)
+
+	for n, raw := range checks {
+		if !isSet(raw) {
+			if !isSet(example) {
+				return "", fmt.Errorf("check[%d] runs the example, but there is none", n)
+			}
+			raw = example
+		}
+		scripts, err := parseAgentBenchScripts(raw)
+		if err != nil || len(scripts) != 1 {
+			return "", fmt.Errorf("check[%d] must be a script", n)
+		}
+		code, language, err := i.agentBenchCode(scripts[0])
+		if err != nil {
+			return "", fmt.Errorf("check[%d]: %w", n, err)
+		}
+
+		writeHeredoc(&b, "code", code)
+		if language == "python" {
+			b.WriteString(`check python3 -c "$code"` + "\n")
+		} else {
+			b.WriteString(`check bash -c "$code" --` + "\n")
+		}
+	}
+	return b.String(), nil
+}
+
+// agentBenchInline returns the code of script as an inline script step.
+func (i *importer) agentBenchInline(script *agentBenchScript) (string, error) {
+	code, language, err := i.agentBenchCode(script)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(code, "#!") {
+		return code, nil
+	}
+	if language == "python" {
+		return "#!/usr/bin/env python3\n" + code, nil
+	}
+	return "#!/usr/bin/env bash\n" + code, nil
+}
+
+// agentBenchCode returns the code of script, read from its file if it has
+// one, and its language.
+func (i *importer) agentBenchCode(script *agentBenchScript) (string, string, error) {
+	language := script.Language
+	if language == "" {
+		language = "bash"
+	}
+	if language != "bash" && language != "python" {
+		return "", "", fmt.Errorf("scripts in %s are not supported", language)
+	}
+
+	code := script.Code
+	if script.File != "" {
+		var err error
+		if code, err = i.readScriptFile(script.File); err != nil {
+			return "", "", err
+		}
+	}
+	if code == "" {
+		return "", "", fmt.Errorf("script has neither code nor file")
+	}
+	return code, language, nil
+}
+
+// parseAgentBenchScripts parses raw, a script or a list of scripts.
+func parseAgentBenchScripts(raw json.RawMessage) ([]*agentBenchScript, error) {
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err != nil {
+		list = []json.RawMessage{raw}
+	}
+
+	scripts := make([]*agentBenchScript, 0, len(list))
+	for _, item := range list {
+		script := &agentBenchScript{}
+		if err := json.Unmarshal(item, &script.Code); err != nil {
+			if err := json.Unmarshal(item, script); err != nil {
+				return nil, err
+			}
+		}
+		scripts = append(scripts, script)
+	}
+	return scripts, nil
+}
+
+// writeHeredoc writes a bash command that sets name to value.
+func writeHeredoc(b *strings.Builder, name, value string) {
+	delimiter := heredocDelimiter
+	for i := 1; strings.Contains(value, delimiter); i++ {
+		delimiter = fmt.Sprintf("%s_%d", heredocDelimiter, i)
+	}
+	fmt.Fprintf(b, "%s=$(cat <<'%s'\n%s\n%s\n)\n", name, delimiter, strings.TrimRight(value, "\n"), delimiter)
+}
+
+// isSet returns whether raw holds a value other than null.
+func isSet(raw json.RawMessage) bool {
+	return len(raw) > 0 && string(raw) != "null"
+}
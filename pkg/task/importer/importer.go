@@ -0,0 +1,240 @@
+// Package importer translates the tasks of public agent benchmarks into
+// mcpchecker tasks, so that their tasks can be run against MCP servers.
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"sigs.k8s.io/yaml"
+)
+
+// Options configure an import.
+type Options struct {
+	// ScriptsDir is the directory that the script files of the benchmark are
+	// relative to. It defaults to the directory of each task file.
+	ScriptsDir string
+}
+
+// Result is the outcome of an import.
+type Result struct {
+	// Tasks are the translated tasks, in the order of their source files.
+	Tasks []*Task
+	// Skipped are the benchmark tasks that could not be translated.
+	Skipped []*Skipped
+}
+
+// Task is a benchmark task translated into an mcpchecker task.
+type Task struct {
+	// Name is the name of the task, which is also the name of its file.
+	Name string
+	// Source is the file and index of the benchmark task.
+	Source string
+	// Data is the task in YAML.
+	Data []byte
+}
+
+// Skipped is a benchmark task that could not be translated.
+type Skipped struct {
+	Source string
+	Reason string
+}
+
+// importFunc translates the benchmark task raw, at index of the file being
+// imported, and adds it to the import. Values that are not tasks of its format
+// are ignored.
+type importFunc func(i *importer, source string, index int, raw json.RawMessage) error
+
+var formats = map[string]importFunc{
+	"agentbench": importAgentBench,
+	"tau-bench":  importTauBench,
+}
+
+// Formats returns the names of the benchmark formats that can be imported.
+func Formats() []string {
+	return slices.Sorted(maps.Keys(formats))
+}
+
+// Import translates the tasks in format of the JSON files at path, a file or
+// a directory that is searched recursively.
+func Import(format, path string, opts Options) (*Result, error) {
+	fn, ok := formats[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q (must be one of %s)", format, strings.Join(Formats(), ", "))
+	}
+
+	files, err := jsonFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	i := &importer{opts: opts, result: &Result{}, names: make(map[string]bool)}
+	for _, file := range files {
+		i.file = file
+		if err := i.importFile(fn, file); err != nil {
+			return nil, err
+		}
+	}
+	return i.result, nil
+}
+
+// importer holds the state of an import.
+type importer struct {
+	opts   Options
+	result *Result
+	// names are the names of the tasks translated so far
+	names map[string]bool
+	// file is the benchmark file being translated
+	file string
+}
+
+// importFile translates the tasks of file, which holds a task or a list of
+// tasks. Files that don't parse are skipped, since benchmarks keep other
+// JSON files next to their tasks.
+func (i *importer) importFile(fn importFunc, file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		var item map[string]json.RawMessage
+		if json.Unmarshal(data, &item) != nil {
+			return nil
+		}
+		items = []json.RawMessage{data}
+	}
+
+	for index, raw := range items {
+		source := fmt.Sprintf("%s[%d]", file, index)
+		if err := fn(i, source, index, raw); err != nil {
+			i.result.Skipped = append(i.result.Skipped, &Skipped{Source: source, Reason: err.Error()})
+		}
+	}
+	return nil
+}
+
+// add records the task with name, spec and labels translated from source. The
+// task is checked to be valid, and its name is made unique.
+func (i *importer) add(source, name string, labels map[string]string, spec map[string]any) error {
+	name = i.uniqueName(name)
+	metadata := map[string]any{"name": name}
+	if len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+	doc := map[string]any{
+		"kind":       task.KindTask,
+		"apiVersion": util.APIVersionV1Alpha2,
+		"metadata":   metadata,
+		"spec":       spec,
+	}
+
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to write task: %w", err)
+	}
+	if _, err := task.Read(data, filepath.Dir(i.file)); err != nil {
+		return fmt.Errorf("translated task is invalid: %w", err)
+	}
+
+	i.names[name] = true
+	i.result.Tasks = append(i.result.Tasks, &Task{Name: name, Source: source, Data: data})
+	return nil
+}
+
+// uniqueName returns name, suffixed with a number if a task already has it.
+func (i *importer) uniqueName(name string) string {
+	unique := name
+	for n := 2; i.names[unique]; n++ {
+		unique = fmt.Sprintf("%s-%d", name, n)
+	}
+	return unique
+}
+
+// readScriptFile reads the benchmark script file at path, relative to the
+// scripts directory.
+func (i *importer) readScriptFile(path string) (string, error) {
+	dir := i.opts.ScriptsDir
+	if dir == "" {
+		dir = filepath.Dir(i.file)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read script file: %w", err)
+	}
+	return string(data), nil
+}
+
+var nameSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// taskName returns a task name made of parts, such as the name of the
+// benchmark file and the index of the task in it.
+func taskName(parts ...string) string {
+	var clean []string
+	for _, part := range parts {
+		part = strings.Trim(nameSeparators.ReplaceAllString(strings.ToLower(part), "-"), "-")
+		if part != "" {
+			clean = append(clean, part)
+		}
+	}
+	return strings.Join(clean, "-")
+}
+
+// fileBase returns the name of file without its extension.
+func fileBase(file string) string {
+	return strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+}
+
+// jsonFiles returns path, if it is a file, or the JSON files under it.
+func jsonFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && filepath.Ext(file) == ".json" {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// llmJudgeStep returns a verify step that passes if the agent's response
+// contains the information in contains.
+func llmJudgeStep(contains string) map[string]any {
+	return map[string]any{"llmJudge": map[string]any{"contains": contains}}
+}
+
+// scriptStep returns a step that runs the inline script with env.
+func scriptStep(inline string, env map[string]string) map[string]any {
+	script := map[string]any{"inline": inline}
+	if len(env) > 0 {
+		script["env"] = env
+	}
+	return map[string]any{"script": script}
+}
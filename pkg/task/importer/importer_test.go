@@ -0,0 +1,240 @@
+package importer
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+// readTask reads the translated task t.
+func readTask(t *testing.T, imported *Task) *task.TaskConfig {
+	t.Helper()
+	cfg, err := task.Read(imported.Data, t.TempDir())
+	require.NoError(t, err)
+	return cfg
+}
+
+const agentBenchTasks = `[
+  {
+    "description": "How many hidden files are in /tmp/ab?",
+    "create": {"local": "default", "init": {"code": "mkdir -p /tmp/ab && touch /tmp/ab/.a /tmp/ab/.b"}},
+    "evaluation": {
+      "check": [null, {"language": "python", "file": "check/integer-match.py"}],
+      "example": {"code": "printf 2"}
+    },
+    "labels": ["file"]
+  },
+  {
+    "description": "What is the name of the user {whoami}?",
+    "evaluation": {"match": "root"}
+  },
+  {
+    "description": "Which shell does root use?",
+    "evaluation": {"match": {"regex": "(ba)?sh$"}}
+  },
+  {
+    "description": "Find the process",
+    "start": "python3 -c 'import time; time.sleep(1000)'",
+    "evaluation": {"match": "42"}
+  },
+  {
+    "description": "Compile it",
+    "evaluation": {"check": [{"language": "c++", "code": "int main() {}"}]}
+  },
+  {"unrelated": true}
+]`
+
+const integerMatch = `import sys
+sys.exit(0 if int(sys.argv[1]) == int(sys.argv[2]) else 1)
+`
+
+func TestImportAgentBench(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"data/dev.json":                  agentBenchTasks,
+		"data/notes.txt":                 "not a task",
+		"scripts/check/integer-match.py": integerMatch,
+	})
+
+	result, err := Import("agentbench", filepath.Join(dir, "data"), Options{ScriptsDir: filepath.Join(dir, "scripts")})
+	require.NoError(t, err)
+
+	var names []string
+	for _, imported := range result.Tasks {
+		names = append(names, imported.Name)
+	}
+	assert.Equal(t, []string{"dev-0", "dev-1", "dev-2"}, names)
+	assert.Equal(t, filepath.Join(dir, "data/dev.json")+"[0]", result.Tasks[0].Source)
+
+	require.Len(t, result.Skipped, 2)
+	assert.Contains(t, result.Skipped[0].Reason, "background processes")
+	assert.Contains(t, result.Skipped[1].Reason, "scripts in c++ are not supported")
+
+	first := readTask(t, result.Tasks[0])
+	assert.Equal(t, map[string]string{"benchmark": "agentbench"}, first.Metadata.Labels)
+	assert.Equal(t, "How many hidden files are in /tmp/ab?\n\n"+answerPrompt, first.Spec.Prompt.Inline)
+	require.Len(t, first.Spec.Setup, 1)
+	assert.Contains(t, string(first.Spec.Setup[0].Config["script"]), "mkdir -p /tmp/ab")
+	require.Len(t, first.Spec.Verify, 1)
+	assert.Contains(t, string(first.Spec.Verify[0].Config["script"]), `"ANSWER":"{agent.output}"`)
+
+	second := readTask(t, result.Tasks[1])
+	assert.Contains(t, second.Spec.Prompt.Inline, "{whoami}")
+}
+
+func TestAgentBenchVerifyScripts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("verify scripts need bash")
+	}
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("verify scripts need python3")
+	}
+
+	dir := writeFiles(t, map[string]string{
+		"dev.json":               agentBenchTasks,
+		"check/integer-match.py": integerMatch,
+	})
+	result, err := Import("agentbench", filepath.Join(dir, "dev.json"), Options{})
+	require.NoError(t, err)
+	require.Len(t, result.Tasks, 3)
+
+	tests := map[string]struct {
+		task   int
+		answer string
+		want   bool
+	}{
+		"check pipeline passes": {task: 0, answer: " 2\n", want: true},
+		"check pipeline fails":  {task: 0, answer: "3", want: false},
+		"match passes":          {task: 1, answer: "root\n", want: true},
+		"match fails":           {task: 1, answer: "The user is root", want: false},
+		"regex match passes":    {task: 2, answer: "/bin/bash", want: true},
+		"regex match fails":     {task: 2, answer: "/usr/bin/python3", want: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var script struct {
+				Inline string `json:"inline"`
+			}
+			cfg := readTask(t, result.Tasks[tc.task])
+			require.NoError(t, json.Unmarshal(cfg.Spec.Verify[0].Config["script"], &script))
+
+			cmd := exec.Command("bash", "-c", script.Inline)
+			cmd.Env = append(os.Environ(), "ANSWER="+tc.answer)
+			out, err := cmd.CombinedOutput()
+			assert.Equal(t, tc.want, err == nil, string(out))
+		})
+	}
+}
+
+func TestImportTauBench(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"tasks_test.json": `[
+  {
+    "annotator": 0,
+    "user_id": "mia_li_3668",
+    "instruction": "You are mia_li_3668. You want to cancel order #W1.",
+    "actions": [{"name": "cancel_pending_order", "kwargs": {"order_id": "#W1", "reason": "no longer needed"}}],
+    "outputs": ["10.5"]
+  },
+  {"user_id": "x", "instruction": "Say hello", "actions": [], "outputs": []}
+]`,
+		"tau2/tasks.json": `[
+  {
+    "id": "0",
+    "user_scenario": {"instructions": {"domain": "airline", "reason_for_call": "You want to change your flight.", "known_info": "Your user id is {raj_1}.", "task_instructions": ""}},
+    "evaluation_criteria": {
+      "actions": [{"action_id": "0_0", "name": "update_reservation_flights", "arguments": {"reservation_id": "ABC", "flights": [{"flight_number": "HAT1"}]}}],
+      "communicate_info": [],
+      "nl_assertions": ["Agent confirms the new flight."]
+    }
+  }
+]`,
+	})
+
+	result, err := Import("tau-bench", dir, Options{})
+	require.NoError(t, err)
+
+	require.Len(t, result.Tasks, 2)
+	require.Len(t, result.Skipped, 1)
+	assert.Equal(t, "task has no actions or outputs to verify", result.Skipped[0].Reason)
+
+	airline := readTask(t, result.Tasks[1])
+	assert.Equal(t, "0", airline.Metadata.Name)
+	assert.Equal(t, map[string]string{"benchmark": "tau-bench", "domain": "airline"}, airline.Metadata.Labels)
+	assert.Equal(t, "You want to change your flight.\n\nYour user id is {raj_1}.", airline.Spec.Prompt.Inline)
+	require.Len(t, airline.Spec.Verify, 2)
+	assert.JSONEq(t, `{"contains": "Agent confirms the new flight."}`, string(airline.Spec.Verify[0].Config["llmJudge"]))
+	assert.JSONEq(t, `{"contains": "Confirmation that the following actions were completed:\n- update_reservation_flights(flights=[(flight_number=\"HAT1\")], reservation_id=\"ABC\")"}`,
+		string(airline.Spec.Verify[1].Config["llmJudge"]))
+
+	retail := readTask(t, result.Tasks[0])
+	assert.Equal(t, "tasks-test-0", retail.Metadata.Name)
+	assert.Equal(t, "You are mia_li_3668. You want to cancel order #W1.", retail.Spec.Prompt.Inline)
+	require.Len(t, retail.Spec.Verify, 2)
+	assert.JSONEq(t, `{"contains": "10.5"}`, string(retail.Spec.Verify[0].Config["llmJudge"]))
+}
+
+func TestImport(t *testing.T) {
+	_, err := Import("swe-bench", t.TempDir(), Options{})
+	assert.ErrorContains(t, err, `unknown format "swe-bench" (must be one of agentbench, tau-bench)`)
+
+	_, err = Import("tau-bench", filepath.Join(t.TempDir(), "missing"), Options{})
+	assert.Error(t, err)
+}
+
+func TestTaskName(t *testing.T) {
+	tests := map[string]struct {
+		parts []string
+		want  string
+	}{
+		"file and index": {parts: []string{"dev", "3"}, want: "dev-3"},
+		"separators":     {parts: []string{"[mobile_data_issue]user_abroad"}, want: "mobile-data-issue-user-abroad"},
+		"empty part":     {parts: []string{"", "0"}, want: "0"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, taskName(tc.parts...))
+		})
+	}
+}
+
+func TestUniqueName(t *testing.T) {
+	i := &importer{names: map[string]bool{"a": true, "a-2": true}}
+	assert.Equal(t, "a-3", i.uniqueName("a"))
+	assert.Equal(t, "b", i.uniqueName("b"))
+}
+
+func TestWriteHeredoc(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("heredocs need bash")
+	}
+
+	value := "echo start\nMCPCHECKER_EOF\nMCPCHECKER_EOF_1\necho end"
+	var b strings.Builder
+	writeHeredoc(&b, "code", value)
+	assert.Contains(t, b.String(), "<<'MCPCHECKER_EOF_2'")
+
+	out, err := exec.Command("bash", "-c", b.String()+`printf '%s' "$code"`).Output()
+	require.NoError(t, err)
+	assert.Equal(t, value, string(out))
+}
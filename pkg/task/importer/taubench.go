@@ -0,0 +1,184 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// tauBenchTask is a task of tau-bench, exported as JSON, or of tau2-bench. In
+// both, a simulated user asks a customer service agent for help, and the
+// agent must take actions with the tools of the domain.
+type tauBenchTask struct {
+	// tau-bench tasks have the instruction of the user, the actions the agent
+	// should take and information its responses must contain
+	Instruction string           `json:"instruction"`
+	Actions     []tauBenchAction `json:"actions"`
+	Outputs     []string         `json:"outputs"`
+
+	// tau2-bench tasks have an ID, the scenario of the user and the criteria
+	// that the conversation is evaluated by
+	ID                 string                  `json:"id"`
+	UserScenario       *tauBenchScenario       `json:"user_scenario"`
+	EvaluationCriteria *tauBenchEvaluationSpec `json:"evaluation_criteria"`
+}
+
+type tauBenchAction struct {
+	Name      string         `json:"name"`
+	Kwargs    map[string]any `json:"kwargs"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+type tauBenchScenario struct {
+	Instructions json.RawMessage `json:"instructions"`
+}
+
+// tauBenchInstructions are the structured instructions of tau2-bench users.
+type tauBenchInstructions struct {
+	Domain           string `json:"domain"`
+	ReasonForCall    string `json:"reason_for_call"`
+	KnownInfo        string `json:"known_info"`
+	TaskInstructions string `json:"task_instructions"`
+}
+
+type tauBenchEvaluationSpec struct {
+	Actions         []tauBenchAction `json:"actions"`
+	CommunicateInfo []string         `json:"communicate_info"`
+	NLAssertions    []string         `json:"nl_assertions"`
+}
+
+// importTauBench translates a tau-bench or tau2-bench task. The instructions
+// of the simulated user become the prompt, since mcpchecker prompts the agent
+// once, and the information the agent must communicate, the natural language
+// assertions and the actions it must take become llmJudge verify steps.
+func importTauBench(i *importer, source string, index int, raw json.RawMessage) error {
+	var t tauBenchTask
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return nil
+	}
+
+	var prompt, domain string
+	var actions []tauBenchAction
+	var contains []string
+	switch {
+	case t.Instruction != "":
+		prompt = t.Instruction
+		actions = t.Actions
+		contains = t.Outputs
+	case t.UserScenario != nil:
+		var err error
+		if prompt, domain, err = tau2Prompt(t.UserScenario.Instructions); err != nil {
+			return err
+		}
+		if c := t.EvaluationCriteria; c != nil {
+			actions = c.Actions
+			contains = append(append(contains, c.CommunicateInfo...), c.NLAssertions...)
+		}
+	default:
+		return nil
+	}
+
+	var verify []any
+	for _, info := range contains {
+		verify = append(verify, llmJudgeStep(judgeText(info)))
+	}
+	if len(actions) > 0 {
+		verify = append(verify, llmJudgeStep(tauBenchActionsJudge(actions)))
+	}
+	if len(verify) == 0 {
+		return fmt.Errorf("task has no actions or outputs to verify")
+	}
+
+	name := taskName(t.ID)
+	if name == "" {
+		name = taskName(fileBase(i.file), strconv.Itoa(index))
+	}
+	labels := map[string]string{"benchmark": "tau-bench"}
+	if domain != "" {
+		labels["domain"] = domain
+	}
+	return i.add(source, name, labels, map[string]any{
+		"prompt": map[string]any{"inline": prompt},
+		"verify": verify,
+	})
+}
+
+// tau2Prompt returns the prompt made of the instructions of a tau2-bench user,
+// which are either text or structured, and the domain of the task.
+func tau2Prompt(raw json.RawMessage) (string, string, error) {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		if text == "" {
+			return "", "", fmt.Errorf("user scenario has no instructions")
+		}
+		return text, "", nil
+	}
+
+	var in tauBenchInstructions
+	if err := json.Unmarshal(raw, &in); err != nil {
+		return "", "", fmt.Errorf("invalid user scenario instructions: %w", err)
+	}
+	var parts []string
+	for _, part := range []string{in.ReasonForCall, in.KnownInfo, in.TaskInstructions} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return "", "", fmt.Errorf("user scenario has no instructions")
+	}
+	return strings.Join(parts, "\n\n"), in.Domain, nil
+}
+
+// tauBenchActionsJudge returns what the agent's response must contain for the
+// agent to have taken actions. tau-bench checks the state of the database of
+// the domain after the conversation instead, which mcpchecker can't.
+func tauBenchActionsJudge(actions []tauBenchAction) string {
+	var b strings.Builder
+	b.WriteString("Confirmation that the following actions were completed:")
+	for _, action := range actions {
+		args := action.Kwargs
+		if args == nil {
+			args = action.Arguments
+		}
+		fmt.Fprintf(&b, "\n- %s(%s)", action.Name, formatArgs(args))
+	}
+	return judgeText(b.String())
+}
+
+// formatArgs formats the arguments of an action as key=value pairs, with
+// objects in parentheses.
+func formatArgs(args map[string]any) string {
+	pairs := make([]string, 0, len(args))
+	for _, key := range slices.Sorted(maps.Keys(args)) {
+		pairs = append(pairs, key+"="+formatArg(args[key]))
+	}
+	return strings.Join(pairs, ", ")
+}
+
+func formatArg(value any) string {
+	switch v := value.(type) {
+	case map[string]any:
+		return "(" + formatArgs(v) + ")"
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, formatArg(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case string:
+		return strconv.Quote(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// judgeText returns text without braces, which llmJudge steps would take for
+// template references.
+func judgeText(text string) string {
+	return strings.NewReplacer("{", "(", "}", ")").Replace(text)
+}
@@ -0,0 +1,113 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// Interjection makes a task multi-turn: after the agent finishes its turn, the
+// steps of the interjection run, e.g. to change the cluster state behind the
+// agent's back, and its reply is sent to the agent as the next user message.
+// The interjections of a task run in order, one between each pair of turns.
+type Interjection struct {
+	// Steps run before the reply is sent. They see the agent's output so far
+	// and the outputs of setup and of earlier interjection steps.
+	Steps []*steps.StepConfig `json:"steps,omitempty"`
+
+	// Reply is the user message of the next turn. It may reference
+	// {steps.<id>.<key>} outputs like the prompt, including the outputs of
+	// interjection steps.
+	Reply *util.Step `json:"reply"`
+}
+
+// Validate checks that the interjection has a reply.
+func (i *Interjection) Validate() error {
+	if i == nil {
+		return fmt.Errorf("interjection is empty")
+	}
+	if i.Reply.IsEmpty() {
+		return fmt.Errorf("reply.inline or reply.file must be set")
+	}
+	return nil
+}
+
+// interjection is a parsed Interjection.
+type interjection struct {
+	steps []steps.StepRunner
+	ids   []string
+	reply string // Unresolved reply; may contain {steps.*} templates
+}
+
+// conversation tracks the state of one multi-turn agent run, so that RunAgent
+// can still be called concurrently.
+type conversation struct {
+	r           *taskRunner
+	prompt      string
+	debug       *util.DebugDir
+	stepOutputs map[string]map[string]string
+
+	// turn is the index of the next interjection
+	turn int
+
+	// steps are the outputs of the interjection steps that ran
+	steps []*steps.StepOutput
+}
+
+func (r *taskRunner) newConversation(prompt string, debug *util.DebugDir) *conversation {
+	return &conversation{
+		r:           r,
+		prompt:      prompt,
+		debug:       debug,
+		stepOutputs: maps.Clone(r.setupOutputs),
+	}
+}
+
+// nextTurn runs the steps of the next interjection and returns its resolved
+// reply. It is an agent.NextTurn.
+func (c *conversation) nextTurn(ctx context.Context, result agent.AgentResult) (string, bool, error) {
+	if c.turn >= len(c.r.interject) {
+		return "", false, nil
+	}
+	i := c.turn
+	in := c.r.interject[i]
+	c.turn++
+
+	if c.stepOutputs == nil {
+		c.stepOutputs = make(map[string]map[string]string)
+	}
+	agentCtx := &steps.AgentContext{
+		Prompt:    c.prompt,
+		Output:    agent.FinalMessageFromSteps(result.GetOutput()),
+		ToolCalls: result.GetToolCalls(),
+	}
+
+	for j, s := range in.steps {
+		res, err := executeStep(ctx, "interject", in.ids[j], s, &steps.StepInput{
+			Agent:       agentCtx,
+			Workdir:     c.r.baseDir,
+			StepOutputs: c.stepOutputs,
+			Random:      c.r.random,
+			Deps:        c.r.deps,
+			Clock:       c.r.clock,
+		})
+
+		if res != nil {
+			res.ID = in.ids[j]
+		}
+		c.steps = append(c.steps, res)
+		if err != nil {
+			return "", false, fmt.Errorf("interject[%d].steps[%d] failed: %w", i, j, err)
+		}
+
+		c.r.recordStepOutputs(c.stepOutputs, in.ids[j], res)
+	}
+
+	reply := resolveTemplates(in.reply, c.stepOutputs)
+	c.debug.WriteFile(fmt.Sprintf("reply-%d.txt", i), []byte(reply))
+	return reply, true, nil
+}
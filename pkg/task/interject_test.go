@@ -0,0 +1,201 @@
+package task
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// conversationAgent is an agent.ConversationRunner whose reply to each turn
+// is its prompt
+type conversationAgent struct {
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (a *conversationAgent) RunTask(ctx context.Context, prompt string) (agent.AgentResult, error) {
+	return a.RunConversation(ctx, prompt, nil)
+}
+
+func (a *conversationAgent) RunConversation(ctx context.Context, prompt string, next agent.NextTurn) (agent.AgentResult, error) {
+	var prompts []string
+	for {
+		prompts = append(prompts, prompt)
+		if next == nil {
+			break
+		}
+		var ok bool
+		var err error
+		prompt, ok, err = next(ctx, &echoAgentResult{prompt: prompts[len(prompts)-1]})
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+	}
+
+	a.mu.Lock()
+	a.prompts = append(a.prompts, prompts...)
+	a.mu.Unlock()
+	return &echoAgentResult{prompt: prompts[len(prompts)-1]}, nil
+}
+
+func (a *conversationAgent) WithMcpServerInfo(mcpproxy.ServerManager) agent.Runner { return a }
+func (a *conversationAgent) WithSkillInfo(*agent.SkillInfo) agent.Runner           { return a }
+func (a *conversationAgent) WithActivity(*util.Activity) agent.Runner              { return a }
+func (a *conversationAgent) AgentName() string                                     { return "conversation" }
+
+func TestRunAgentInterject(t *testing.T) {
+	root := t.TempDir()
+	debug, err := util.NewDebugDir(root)
+	require.NoError(t, err)
+	ctx := util.WithDebugDir(context.Background(), debug)
+
+	observer := &agentContextStep{}
+	scale := &outputStep{stepType: "script", outputs: map[string]string{"replicas": "5"}}
+	r := &taskRunner{
+		prompt: "Scale web in {steps.create_ns.namespace} to 3 replicas",
+		interject: []*interjection{
+			{
+				steps: []steps.StepRunner{observer, scale},
+				ids:   []string{"observe", "scale"},
+				reply: "Someone scaled it to {steps.scale.replicas}, scale it back",
+			},
+			{reply: "Thanks, now delete it"},
+		},
+		stepIDs:      map[string]struct{}{"observe": {}, "scale": {}},
+		setupOutputs: map[string]map[string]string{"create_ns": {"namespace": "test-abc"}},
+	}
+	runner := &conversationAgent{}
+
+	out, err := r.RunAgent(ctx, runner)
+	require.NoError(t, err)
+	assert.True(t, out.Success)
+
+	assert.Equal(t, []string{
+		"Scale web in test-abc to 3 replicas",
+		"Someone scaled it to 5, scale it back",
+		"Thanks, now delete it",
+	}, runner.prompts)
+
+	// Interjection steps see the agent's turn and the setup outputs
+	require.NotNil(t, observer.agent)
+	assert.Equal(t, "Scale web in test-abc to 3 replicas", observer.agent.Output)
+	assert.Equal(t, map[string]string{"namespace": "test-abc"}, scale.seen["create_ns"])
+
+	// Their outputs follow the agent's steps, and the final message is the last turn's
+	require.Len(t, out.Steps, 3)
+	assert.Equal(t, "observe", out.Steps[1].ID)
+	assert.Equal(t, "scale", out.Steps[2].ID)
+	assert.Equal(t, "Thanks, now delete it", r.output)
+
+	assert.FileExists(t, filepath.Join(root, "agent", "interject", "scale", "output.json"))
+	reply, err := os.ReadFile(filepath.Join(root, "agent", "reply-0.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "Someone scaled it to 5, scale it back", string(reply))
+}
+
+func TestRunAgentInterjectErrors(t *testing.T) {
+	tests := map[string]struct {
+		runner      agent.Runner
+		interject   *interjection
+		errContains string
+	}{
+		"agent without multi-turn support": {
+			runner:      &echoAgent{},
+			interject:   &interjection{reply: "Try again"},
+			errContains: `agent "echo" does not support multi-turn tasks`,
+		},
+		"failing step": {
+			runner:      &conversationAgent{},
+			interject:   &interjection{steps: []steps.StepRunner{failingStep{}}, ids: []string{"break"}, reply: "Try again"},
+			errContains: "interject[0].steps[0] failed: exit status 1",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &taskRunner{prompt: "List the pods", interject: []*interjection{tc.interject}}
+
+			out, err := r.RunAgent(context.Background(), tc.runner)
+			require.Error(t, err)
+			assert.ErrorContains(t, err, tc.errContains)
+			assert.Equal(t, ErrorKindAgent, ErrorKindOf(err))
+			assert.False(t, out.Success)
+		})
+	}
+}
+
+func TestReadInterject(t *testing.T) {
+	tt := map[string]struct {
+		interject     string
+		expectStepIDs []string
+		expectReply   *util.Step
+		errContains   string
+	}{
+		"steps and reply": {
+			interject: `
+    - steps:
+        - id: scale
+          script:
+            inline: kubectl scale deployment web --replicas=5
+      reply:
+        file: reply.md`,
+			expectStepIDs: []string{"scale"},
+			expectReply:   &util.Step{File: filepath.Join("/tasks", "reply.md")},
+		},
+		"reply only": {
+			interject: `
+    - reply:
+        inline: Try again`,
+			expectReply: &util.Step{Inline: "Try again"},
+		},
+		"missing reply": {
+			interject: `
+    - steps:
+        - script:
+            inline: echo hi`,
+			errContains: "invalid interject[0]: reply.inline or reply.file must be set",
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			data := `kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: interject
+spec:
+  prompt:
+    inline: Scale web to 3 replicas
+  interject:` + tc.interject + "\n"
+
+			cfg, err := Read([]byte(data), "/tasks")
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, cfg.Spec.Interject, 1)
+			var stepIDs []string
+			for _, step := range cfg.Spec.Interject[0].Steps {
+				stepIDs = append(stepIDs, step.ID)
+			}
+			assert.Equal(t, tc.expectStepIDs, stepIDs)
+			assert.Equal(t, tc.expectReply, cfg.Spec.Interject[0].Reply)
+		})
+	}
+}
@@ -0,0 +1,409 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	KindStepLibrary = "StepLibrary"
+
+	// useKey is the key of steps that run a sequence of a step library, as
+	// use: <library>/<sequence>
+	useKey = "use"
+	// withKey is the key of the parameters of a use step
+	withKey = "with"
+)
+
+// StepLibrary is a file of named step sequences that tasks share instead of
+// repeating the same setup or verify steps. The libraries of an eval are
+// listed under stepLibraries in its config, and tasks run a sequence with a
+// step like use: <library>/<sequence>.
+type StepLibrary struct {
+	util.TypeMeta `json:",inline"`
+	Spec          *StepLibrarySpec `json:"spec"`
+
+	// basePath is the directory of the library file, which the script files
+	// of its steps are relative to
+	basePath string
+}
+
+type StepLibrarySpec struct {
+	Sequences map[string]*StepSequence `json:"sequences"`
+}
+
+// StepSequence is a named list of steps. Its steps may reference its
+// parameters as {params.<name>}, which are replaced by the values that the
+// use step passes with with: before the task runs.
+type StepSequence struct {
+	Description string                    `json:"description,omitempty"`
+	Params      map[string]*SequenceParam `json:"params,omitempty"`
+	Steps       []*steps.StepConfig       `json:"steps"`
+}
+
+// SequenceParam is a parameter of a step sequence. Parameters without a
+// default are required.
+type SequenceParam struct {
+	Description string `json:"description,omitempty"`
+	Default     any    `json:"default,omitempty"`
+}
+
+// paramReference matches the {params.<name>} references of sequence steps
+var paramReference = regexp.MustCompile(`\{params\.([A-Za-z0-9_-]+)\}`)
+
+// stepReference matches the {steps.<step>.<output>} references of sequence
+// steps
+var stepReference = regexp.MustCompile(`\{steps\.([^{}\s]+)\}`)
+
+// ReadLibrary reads a step library. Script files of its steps are resolved
+// relative to basePath.
+func ReadLibrary(data []byte, basePath string) (*StepLibrary, error) {
+	lib := &StepLibrary{}
+	if err := yaml.Unmarshal(data, lib); err != nil {
+		return nil, err
+	}
+	if err := lib.TypeMeta.Validate(KindStepLibrary); err != nil {
+		return nil, err
+	}
+	if lib.Spec == nil || len(lib.Spec.Sequences) == 0 {
+		return nil, fmt.Errorf("step library has no sequences")
+	}
+	lib.basePath = basePath
+
+	for _, name := range slices.Sorted(maps.Keys(lib.Spec.Sequences)) {
+		if err := lib.Spec.Sequences[name].validate(); err != nil {
+			return nil, fmt.Errorf("invalid sequence %q: %w", name, err)
+		}
+	}
+	return lib, nil
+}
+
+// LibraryFromFile reads the step library at path.
+func LibraryFromFile(path string) (*StepLibrary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file '%s' for step library: %w", path, err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path for '%s': %w", path, err)
+	}
+
+	lib, err := ReadLibrary(data, filepath.Dir(absPath))
+	if err != nil {
+		return nil, fmt.Errorf("invalid step library %s: %w", path, err)
+	}
+	return lib, nil
+}
+
+// LoadLibraries reads the step libraries at paths, keyed by the names that
+// use steps refer to them by.
+func LoadLibraries(paths map[string]string) (map[string]*StepLibrary, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	libraries := make(map[string]*StepLibrary, len(paths))
+	for _, name := range slices.Sorted(maps.Keys(paths)) {
+		if strings.Contains(name, "/") {
+			return nil, fmt.Errorf("step library name %q cannot contain slashes", name)
+		}
+		lib, err := LibraryFromFile(paths[name])
+		if err != nil {
+			return nil, err
+		}
+		libraries[name] = lib
+	}
+	return libraries, nil
+}
+
+func (s *StepSequence) validate() error {
+	if s == nil || len(s.Steps) == 0 {
+		return fmt.Errorf("sequence has no steps")
+	}
+	for i, step := range s.Steps {
+		if step == nil {
+			return fmt.Errorf("steps[%d] is empty", i)
+		}
+		if _, ok := step.Config[useKey]; ok {
+			return fmt.Errorf("steps[%d]: sequences cannot use other sequences", i)
+		}
+		for _, raw := range step.Config {
+			for _, match := range paramReference.FindAllSubmatch(raw, -1) {
+				if _, ok := s.Params[string(match[1])]; !ok {
+					return fmt.Errorf("steps[%d] references undeclared parameter %q", i, match[1])
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ExpandSteps replaces the use steps of the task with the steps of the
+// sequences they use from libraries, with their parameters filled in.
+func (c *TaskConfig) ExpandSteps(libraries map[string]*StepLibrary) error {
+	if c.Spec == nil {
+		return nil
+	}
+
+	var err error
+	expand := func(phase string, cfgs *[]*steps.StepConfig) {
+		expanded, expandErr := expandSteps(phase, *cfgs, libraries)
+		if expandErr != nil {
+			err = errors.Join(err, expandErr)
+			return
+		}
+		*cfgs = expanded
+	}
+
+	expand("setup", &c.Spec.Setup)
+	expand("verify", &c.Spec.Verify)
+	expand("cleanup", &c.Spec.Cleanup)
+	expand("cleanupVerify", &c.Spec.CleanupVerify)
+	for i, in := range c.Spec.Interject {
+		if in != nil {
+			expand(fmt.Sprintf("interject[%d].steps", i), &in.Steps)
+		}
+	}
+	return err
+}
+
+// expandSteps returns cfgs, the steps of phase, with each use step replaced
+// by the steps of its sequence.
+func expandSteps(phase string, cfgs []*steps.StepConfig, libraries map[string]*StepLibrary) ([]*steps.StepConfig, error) {
+	if !slices.ContainsFunc(cfgs, isUseStep) {
+		return cfgs, nil
+	}
+
+	expanded := make([]*steps.StepConfig, 0, len(cfgs))
+	for i, cfg := range cfgs {
+		if !isUseStep(cfg) {
+			expanded = append(expanded, cfg)
+			continue
+		}
+		seqSteps, err := expandUse(cfg, libraries)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s[%d]: %w", phase, i, err)
+		}
+		expanded = append(expanded, seqSteps...)
+	}
+	return expanded, nil
+}
+
+func isUseStep(cfg *steps.StepConfig) bool {
+	if cfg == nil {
+		return false
+	}
+	_, ok := cfg.Config[useKey]
+	return ok
+}
+
+// expandUse returns the steps of the sequence that the use step cfg runs.
+// The IDs of the steps are prefixed with the ID of the use step, if it has
+// one, so that a sequence can be used several times by a task, and so are the
+// references of the steps to the outputs of the sequence's other steps.
+func expandUse(cfg *steps.StepConfig, libraries map[string]*StepLibrary) ([]*steps.StepConfig, error) {
+	var ref string
+	if err := json.Unmarshal(cfg.Config[useKey], &ref); err != nil {
+		return nil, fmt.Errorf("use must be a string: %w", err)
+	}
+	for key := range cfg.Config {
+		if key != useKey && key != withKey {
+			return nil, fmt.Errorf("use step %q cannot have %q, only with", ref, key)
+		}
+	}
+
+	libName, seqName, ok := strings.Cut(ref, "/")
+	if !ok || libName == "" || seqName == "" {
+		return nil, fmt.Errorf("use must be in format <library>/<sequence>, got %q", ref)
+	}
+	lib, ok := libraries[libName]
+	if !ok {
+		return nil, fmt.Errorf("unknown step library %q in use %q", libName, ref)
+	}
+	seq, ok := lib.Spec.Sequences[seqName]
+	if !ok {
+		return nil, fmt.Errorf("step library %q has no sequence %q", libName, seqName)
+	}
+
+	var with map[string]any
+	if raw, ok := cfg.Config[withKey]; ok {
+		if err := json.Unmarshal(raw, &with); err != nil {
+			return nil, fmt.Errorf("with of use %q must be a map: %w", ref, err)
+		}
+	}
+	params, err := seq.resolveParams(with)
+	if err != nil {
+		return nil, fmt.Errorf("use %q: %w", ref, err)
+	}
+
+	var prefix string
+	seqIDs := make(map[string]bool, len(seq.Steps))
+	if cfg.ID != "" {
+		prefix = cfg.ID + "_"
+		for _, step := range seq.Steps {
+			if step.ID != "" {
+				seqIDs[step.ID] = true
+			}
+		}
+	}
+
+	expanded := make([]*steps.StepConfig, 0, len(seq.Steps))
+	for i, step := range seq.Steps {
+		out := &steps.StepConfig{
+			ID:      step.ID,
+			Config:  make(map[string]json.RawMessage, len(step.Config)),
+			Timeout: step.Timeout,
+		}
+		if seqIDs[step.ID] {
+			out.ID = prefix + step.ID
+		}
+		if out.Timeout == "" {
+			out.Timeout = cfg.Timeout
+		}
+		for stepType, raw := range step.Config {
+			// References are prefixed before the parameters are filled in,
+			// since values may reference the steps of the task
+			raw, err := substituteParams(prefixStepReferences(raw, seqIDs, prefix), params)
+			if err != nil {
+				return nil, fmt.Errorf("use %q: steps[%d]: %w", ref, i, err)
+			}
+			if stepType == "script" {
+				raw, err = resolveScriptFile(raw, lib.basePath)
+				if err != nil {
+					return nil, fmt.Errorf("use %q: steps[%d]: %w", ref, i, err)
+				}
+			}
+			out.Config[stepType] = raw
+		}
+		expanded = append(expanded, out)
+	}
+	return expanded, nil
+}
+
+// prefixStepReferences prefixes the step of the {steps.<step>.<output>}
+// references in the step config raw with prefix, if it is one of ids.
+func prefixStepReferences(raw json.RawMessage, ids map[string]bool, prefix string) json.RawMessage {
+	if len(ids) == 0 {
+		return raw
+	}
+	return stepReference.ReplaceAllFunc(raw, func(ref []byte) []byte {
+		step, key, ok := steps.SplitStepReference(string(stepReference.FindSubmatch(ref)[1]))
+		if !ok || !ids[step] {
+			return ref
+		}
+		return []byte("{steps." + prefix + step + "." + key + "}")
+	})
+}
+
+// resolveParams returns the values of the parameters of s, from with or their
+// defaults.
+func (s *StepSequence) resolveParams(with map[string]any) (map[string]any, error) {
+	for _, name := range slices.Sorted(maps.Keys(with)) {
+		if _, ok := s.Params[name]; !ok {
+			return nil, fmt.Errorf("unknown parameter %q", name)
+		}
+	}
+
+	params := make(map[string]any, len(s.Params))
+	for _, name := range slices.Sorted(maps.Keys(s.Params)) {
+		value, ok := with[name]
+		if !ok {
+			param := s.Params[name]
+			if param == nil || param.Default == nil {
+				return nil, fmt.Errorf("missing required parameter %q", name)
+			}
+			value = param.Default
+		}
+		params[name] = value
+	}
+	return params, nil
+}
+
+// substituteParams replaces the {params.<name>} references in the strings of
+// the step config raw. A string that is only a reference is replaced by the
+// value of the parameter, whatever its type.
+func substituteParams(raw json.RawMessage, params map[string]any) (json.RawMessage, error) {
+	if !paramReference.Match(raw) {
+		return raw, nil
+	}
+
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+	substituted, err := json.Marshal(substituteValue(value, params))
+	if err != nil {
+		return nil, err
+	}
+	return substituted, nil
+}
+
+func substituteValue(value any, params map[string]any) any {
+	switch v := value.(type) {
+	case string:
+		if match := paramReference.FindStringSubmatch(v); match != nil && match[0] == v {
+			return params[match[1]]
+		}
+		return substituteString(v, params)
+	case map[string]any:
+		for key, item := range v {
+			v[key] = substituteValue(item, params)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = substituteValue(item, params)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// substituteString replaces the {params.<name>} references in text with the
+// values of params.
+func substituteString(text string, params map[string]any) string {
+	return paramReference.ReplaceAllStringFunc(text, func(ref string) string {
+		return paramString(params[paramReference.FindStringSubmatch(ref)[1]])
+	})
+}
+
+// paramString returns value as it is inserted into a string: strings as they
+// are, and other values as JSON.
+func paramString(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// resolveScriptFile resolves the file of the script step config raw relative
+// to basePath, since the steps of a sequence run in the directory of the task.
+func resolveScriptFile(raw json.RawMessage, basePath string) (json.RawMessage, error) {
+	var cfg map[string]any
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return raw, nil
+	}
+	file, ok := cfg["file"].(string)
+	if !ok || file == "" || filepath.IsAbs(file) {
+		return raw, nil
+	}
+	if err := util.ResolveRelativePath(&file, basePath); err != nil {
+		return nil, err
+	}
+	cfg["file"] = file
+	return json.Marshal(cfg)
+}
@@ -0,0 +1,286 @@
+package task
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLibrary = `kind: StepLibrary
+apiVersion: mcpchecker/v1alpha2
+spec:
+  sequences:
+    wait-for-deployment:
+      description: Wait for a deployment to roll out
+      params:
+        namespace: {}
+        name: {}
+        timeout:
+          default: 120s
+      steps:
+        - id: rollout
+          script:
+            inline: kubectl rollout status deployment/{params.name} -n {params.namespace} --timeout={params.timeout}
+        - script:
+            file: scripts/check-pods.sh
+            env:
+              NAMESPACE: "{params.namespace}"
+    create-namespace:
+      params:
+        labels:
+          default: {}
+      steps:
+        - id: ns
+          k8s.createNamespace:
+            labels: "{params.labels}"
+`
+
+func TestReadLibrary(t *testing.T) {
+	tests := map[string]struct {
+		data    string
+		wantErr string
+	}{
+		"valid": {
+			data: testLibrary,
+		},
+		"wrong kind": {
+			data:    "kind: Task\napiVersion: mcpchecker/v1alpha2\nspec:\n  sequences:\n    a:\n      steps:\n        - script:\n            inline: echo\n",
+			wantErr: "expected 'StepLibrary'",
+		},
+		"no sequences": {
+			data:    "kind: StepLibrary\napiVersion: mcpchecker/v1alpha2\nspec: {}\n",
+			wantErr: "no sequences",
+		},
+		"no steps": {
+			data:    "kind: StepLibrary\napiVersion: mcpchecker/v1alpha2\nspec:\n  sequences:\n    a: {}\n",
+			wantErr: `invalid sequence "a": sequence has no steps`,
+		},
+		"undeclared parameter": {
+			data:    "kind: StepLibrary\napiVersion: mcpchecker/v1alpha2\nspec:\n  sequences:\n    a:\n      steps:\n        - script:\n            inline: echo {params.name}\n",
+			wantErr: `references undeclared parameter "name"`,
+		},
+		"nested use": {
+			data:    "kind: StepLibrary\napiVersion: mcpchecker/v1alpha2\nspec:\n  sequences:\n    a:\n      steps:\n        - use: common/b\n",
+			wantErr: "cannot use other sequences",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			lib, err := ReadLibrary([]byte(tc.data), "/libs")
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, lib.Spec.Sequences, 2)
+		})
+	}
+}
+
+func TestExpandSteps(t *testing.T) {
+	lib, err := ReadLibrary([]byte(testLibrary), "/libs")
+	require.NoError(t, err)
+	libraries := map[string]*StepLibrary{"common": lib}
+
+	tests := map[string]struct {
+		setup   string
+		want    []*steps.StepConfig
+		wantErr string
+	}{
+		"parameters and defaults": {
+			setup: `[{"script": {"inline": "echo first"}}, {"use": "common/wait-for-deployment", "with": {"namespace": "{steps.ns.namespace}", "name": "web"}}]`,
+			want: []*steps.StepConfig{
+				{Config: map[string]json.RawMessage{"script": json.RawMessage(`{"inline": "echo first"}`)}},
+				{ID: "rollout", Config: map[string]json.RawMessage{
+					"script": json.RawMessage(`{"inline":"kubectl rollout status deployment/web -n {steps.ns.namespace} --timeout=120s"}`),
+				}},
+				{Config: map[string]json.RawMessage{
+					"script": json.RawMessage(`{"env":{"NAMESPACE":"{steps.ns.namespace}"},"file":"/libs/scripts/check-pods.sh"}`),
+				}},
+			},
+		},
+		"id and timeout of the use step": {
+			setup: `[{"id": "web", "timeout": "5m", "use": "common/wait-for-deployment", "with": {"namespace": "apps", "name": "web", "timeout": 60}}]`,
+			want: []*steps.StepConfig{
+				{ID: "web_rollout", Timeout: "5m", Config: map[string]json.RawMessage{
+					"script": json.RawMessage(`{"inline":"kubectl rollout status deployment/web -n apps --timeout=60"}`),
+				}},
+				{Timeout: "5m", Config: map[string]json.RawMessage{
+					"script": json.RawMessage(`{"env":{"NAMESPACE":"apps"},"file":"/libs/scripts/check-pods.sh"}`),
+				}},
+			},
+		},
+		"whole value keeps its type": {
+			setup: `[{"use": "common/create-namespace", "with": {"labels": {"team": "a"}}}]`,
+			want: []*steps.StepConfig{
+				{ID: "ns", Config: map[string]json.RawMessage{
+					"k8s.createNamespace": json.RawMessage(`{"labels":{"team":"a"}}`),
+				}},
+			},
+		},
+		"missing parameter": {
+			setup:   `[{"use": "common/wait-for-deployment", "with": {"name": "web"}}]`,
+			wantErr: `invalid setup[0]: use "common/wait-for-deployment": missing required parameter "namespace"`,
+		},
+		"unknown parameter": {
+			setup:   `[{"use": "common/create-namespace", "with": {"name": "web"}}]`,
+			wantErr: `unknown parameter "name"`,
+		},
+		"unknown library": {
+			setup:   `[{"use": "other/create-namespace"}]`,
+			wantErr: `unknown step library "other"`,
+		},
+		"unknown sequence": {
+			setup:   `[{"use": "common/delete-namespace"}]`,
+			wantErr: `step library "common" has no sequence "delete-namespace"`,
+		},
+		"bad reference": {
+			setup:   `[{"use": "create-namespace"}]`,
+			wantErr: "use must be in format <library>/<sequence>",
+		},
+		"other keys": {
+			setup:   `[{"use": "common/create-namespace", "script": {"inline": "echo"}}]`,
+			wantErr: `cannot have "script"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var setup []*steps.StepConfig
+			require.NoError(t, json.Unmarshal([]byte(tc.setup), &setup))
+			cfg := &TaskConfig{Spec: &TaskSpec{Setup: setup}}
+
+			err := cfg.ExpandSteps(libraries)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, cfg.Spec.Setup)
+		})
+	}
+}
+
+func TestExpandStepsChainsSequenceOutputs(t *testing.T) {
+	lib, err := ReadLibrary([]byte(`kind: StepLibrary
+apiVersion: mcpchecker/v1alpha2
+spec:
+  sequences:
+    deploy:
+      params:
+        namespace: {}
+      steps:
+        - id: ns
+          script:
+            inline: kubectl create namespace {params.namespace} && echo name={params.namespace}
+        - id: app
+          script:
+            inline: kubectl create deployment web -n {steps.ns.name}
+`), "/libs")
+	require.NoError(t, err)
+	libraries := map[string]*StepLibrary{"common": lib}
+
+	tests := map[string]struct {
+		setup string
+		want  []*steps.StepConfig
+	}{
+		"use step with an id": {
+			setup: `[{"id": "web", "use": "common/deploy", "with": {"namespace": "apps"}}]`,
+			want: []*steps.StepConfig{
+				{ID: "web_ns", Config: map[string]json.RawMessage{
+					"script": json.RawMessage(`{"inline":"kubectl create namespace apps \u0026\u0026 echo name=apps"}`),
+				}},
+				{ID: "web_app", Config: map[string]json.RawMessage{
+					"script": json.RawMessage(`{"inline":"kubectl create deployment web -n {steps.web_ns.name}"}`),
+				}},
+			},
+		},
+		"parameter referencing a task step": {
+			setup: `[{"id": "web", "use": "common/deploy", "with": {"namespace": "{steps.ns.name}"}}]`,
+			want: []*steps.StepConfig{
+				{ID: "web_ns", Config: map[string]json.RawMessage{
+					"script": json.RawMessage(`{"inline":"kubectl create namespace {steps.ns.name} \u0026\u0026 echo name={steps.ns.name}"}`),
+				}},
+				{ID: "web_app", Config: map[string]json.RawMessage{
+					"script": json.RawMessage(`{"inline":"kubectl create deployment web -n {steps.web_ns.name}"}`),
+				}},
+			},
+		},
+		"use step without an id": {
+			setup: `[{"use": "common/deploy", "with": {"namespace": "apps"}}]`,
+			want: []*steps.StepConfig{
+				{ID: "ns", Config: map[string]json.RawMessage{
+					"script": json.RawMessage(`{"inline":"kubectl create namespace apps \u0026\u0026 echo name=apps"}`),
+				}},
+				{ID: "app", Config: map[string]json.RawMessage{
+					"script": json.RawMessage(`{"inline":"kubectl create deployment web -n {steps.ns.name}"}`),
+				}},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var setup []*steps.StepConfig
+			require.NoError(t, json.Unmarshal([]byte(tc.setup), &setup))
+			cfg := &TaskConfig{Spec: &TaskSpec{Setup: setup}}
+
+			require.NoError(t, cfg.ExpandSteps(libraries))
+			assert.Equal(t, tc.want, cfg.Spec.Setup)
+		})
+	}
+}
+
+func TestExpandStepsInAllPhases(t *testing.T) {
+	lib, err := ReadLibrary([]byte(testLibrary), "/libs")
+	require.NoError(t, err)
+
+	use := func(id string) *steps.StepConfig {
+		return &steps.StepConfig{ID: id, Config: map[string]json.RawMessage{
+			"use":  json.RawMessage(`"common/create-namespace"`),
+			"with": json.RawMessage(`{}`),
+		}}
+	}
+	cfg := &TaskConfig{Spec: &TaskSpec{
+		Setup:         []*steps.StepConfig{use("a")},
+		Verify:        []*steps.StepConfig{use("b")},
+		Cleanup:       []*steps.StepConfig{use("c")},
+		CleanupVerify: []*steps.StepConfig{use("d")},
+		Interject:     []*Interjection{{Steps: []*steps.StepConfig{use("e")}}},
+	}}
+
+	require.NoError(t, cfg.ExpandSteps(map[string]*StepLibrary{"common": lib}))
+	for id, phase := range map[string][]*steps.StepConfig{
+		"a_ns": cfg.Spec.Setup,
+		"b_ns": cfg.Spec.Verify,
+		"c_ns": cfg.Spec.Cleanup,
+		"d_ns": cfg.Spec.CleanupVerify,
+		"e_ns": cfg.Spec.Interject[0].Steps,
+	} {
+		require.Len(t, phase, 1)
+		assert.Equal(t, id, phase[0].ID)
+	}
+}
+
+func TestLoadLibraries(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "common.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testLibrary), 0644))
+
+	libraries, err := LoadLibraries(map[string]string{"common": path})
+	require.NoError(t, err)
+	require.Contains(t, libraries, "common")
+	assert.Equal(t, dir, libraries["common"].basePath)
+
+	_, err = LoadLibraries(map[string]string{"a/b": path})
+	assert.ErrorContains(t, err, "cannot contain slashes")
+
+	_, err = LoadLibraries(map[string]string{"missing": filepath.Join(dir, "missing.yaml")})
+	assert.ErrorContains(t, err, "failed to read file")
+}
@@ -0,0 +1,51 @@
+package task
+
+import (
+	"fmt"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+	"sigs.k8s.io/yaml"
+)
+
+// Migrate rewrites a task in the deprecated mcpchecker/v1alpha1 format, with a
+// single script per phase under steps, to the current format, with lists of
+// setup, verify and cleanup steps under spec. Fields other than steps are
+// kept, but comments are not, and keys are written in alphabetical order.
+// It returns false, and data unchanged, if the task is in the current format.
+func Migrate(data []byte) ([]byte, bool, error) {
+	wrapper := &struct {
+		util.TypeMeta `json:",inline"`
+		Steps         *TaskStepsV1Alpha1 `json:"steps,omitempty"`
+	}{}
+	if err := yaml.Unmarshal(data, wrapper); err != nil {
+		return nil, false, err
+	}
+	if err := wrapper.TypeMeta.Validate(KindTask); err != nil {
+		return nil, false, err
+	}
+	if wrapper.GetAPIVersion() != util.APIVersionV1Alpha1 {
+		return data, false, nil
+	}
+	if wrapper.Steps == nil {
+		return nil, false, fmt.Errorf("v1alpha1 requires steps field")
+	}
+
+	spec, err := translateV1Alpha1ToSteps(wrapper.Steps)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to convert v1alpha1 steps: %w", err)
+	}
+
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, false, err
+	}
+	delete(doc, "steps")
+	doc["apiVersion"] = util.APIVersionV1Alpha2
+	doc["spec"] = spec
+
+	migrated, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to write migrated task: %w", err)
+	}
+	return migrated, true, nil
+}
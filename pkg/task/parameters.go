@@ -0,0 +1,200 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// maxTaskInstances limits the number of tasks that the parameters matrix of a
+// task template expands into, against matrices grown by mistake
+const maxTaskInstances = 1000
+
+// parameterName matches valid names of task parameters
+var parameterName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// validateParameters checks the parameters matrix of c and that its template
+// only references declared parameters.
+func (c *TaskConfig) validateParameters() error {
+	params := c.Spec.Parameters
+	instances := 1
+	for _, name := range slices.Sorted(maps.Keys(params)) {
+		if !parameterName.MatchString(name) {
+			return fmt.Errorf("invalid parameter name %q: must only contain letters, digits, '-' and '_'", name)
+		}
+		if len(params[name]) == 0 {
+			return fmt.Errorf("parameter %q has no values", name)
+		}
+		instances *= len(params[name])
+		if instances > maxTaskInstances {
+			return fmt.Errorf("parameters expand into more than %d tasks", maxTaskInstances)
+		}
+	}
+
+	for _, match := range paramReference.FindAllSubmatch(c.template, -1) {
+		if _, ok := params[string(match[1])]; !ok {
+			return fmt.Errorf("task references undeclared parameter %q", match[1])
+		}
+	}
+	return nil
+}
+
+// Parameters returns the values of the parameters that the task was expanded
+// with, or nil if it is not an instance of a task template.
+func (c *TaskConfig) Parameters() map[string]any {
+	return c.params
+}
+
+// Instances returns the tasks that c expands into: one per combination of the
+// values of its parameters matrix, with the {params.<name>} references of the
+// task replaced by the values. Tasks without parameters expand into
+// themselves.
+//
+// Instances get their name from the template, which is suffixed with the
+// values of the parameters unless it references them itself. So do their IDs,
+// with whitespace in the values replaced by underscores.
+func (c *TaskConfig) Instances() ([]*TaskConfig, error) {
+	if c.Spec == nil || len(c.Spec.Parameters) == 0 {
+		return []*TaskConfig{c}, nil
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(c.template, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode task template: %w", err)
+	}
+	if spec, ok := doc["spec"].(map[string]any); ok {
+		delete(spec, "parameters")
+	}
+	template, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode task template: %w", err)
+	}
+
+	combinations := parameterCombinations(c.Spec.Parameters)
+	instances := make([]*TaskConfig, 0, len(combinations))
+	for _, params := range combinations {
+		instance, err := c.instance(template, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand task with %s: %w", parameterSuffix(params), err)
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+// instance returns the task of template with the values of params.
+func (c *TaskConfig) instance(template []byte, params map[string]any) (*TaskConfig, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(template, &doc); err != nil {
+		return nil, err
+	}
+
+	// Derive the name and ID of the instance before the template's own
+	// references are replaced. IDs can't contain whitespace, which values of
+	// parameters may, so it is replaced in IDs.
+	if metadata, ok := doc["metadata"].(map[string]any); ok {
+		for _, key := range []string{"name", "id"} {
+			value, ok := metadata[key].(string)
+			if !ok || value == "" {
+				continue
+			}
+			if !paramReference.MatchString(value) {
+				value += parameterSuffix(params)
+			} else if key == "id" {
+				value = substituteString(value, params)
+			}
+			if key == "id" {
+				value = replaceSpace(value)
+			}
+			metadata[key] = value
+		}
+	}
+
+	data, err := json.Marshal(substituteValue(doc, params))
+	if err != nil {
+		return nil, err
+	}
+	instance, err := Read(data, c.basePath)
+	if err != nil {
+		return nil, err
+	}
+	instance.path = c.path
+	instance.params = params
+
+	if err := instance.Spec.Prompt.substituteFiles(params); err != nil {
+		return nil, fmt.Errorf("failed to read prompt: %w", err)
+	}
+	return instance, nil
+}
+
+// substituteFiles makes the prompt files of p that reference parameters inline
+// prompts with the values of params.
+func (p *Prompt) substituteFiles(params map[string]any) error {
+	if p == nil {
+		return nil
+	}
+	prompts := []*util.Step{&p.Step}
+	for _, locale := range p.LocaleNames() {
+		prompts = append(prompts, p.Locales[locale])
+	}
+
+	for _, prompt := range prompts {
+		if prompt.Inline != "" || prompt.File == "" {
+			continue
+		}
+		data, err := os.ReadFile(prompt.File)
+		if err != nil {
+			return err
+		}
+		if text := string(data); paramReference.MatchString(text) {
+			prompt.Inline = substituteString(text, params)
+			prompt.File = ""
+		}
+	}
+	return nil
+}
+
+// parameterCombinations returns every combination of the values of params, in
+// the order of the values, varying the last parameter by name fastest.
+func parameterCombinations(params map[string][]any) []map[string]any {
+	combinations := []map[string]any{{}}
+	for _, name := range slices.Sorted(maps.Keys(params)) {
+		next := make([]map[string]any, 0, len(combinations)*len(params[name]))
+		for _, combination := range combinations {
+			for _, value := range params[name] {
+				extended := maps.Clone(combination)
+				extended[name] = value
+				next = append(next, extended)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// replaceSpace replaces the whitespace in s with underscores.
+func replaceSpace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// parameterSuffix returns the suffix of the names of task instances, such as
+// [image=nginx,replicas=3].
+func parameterSuffix(params map[string]any) string {
+	values := make([]string, 0, len(params))
+	for _, name := range slices.Sorted(maps.Keys(params)) {
+		values = append(values, name+"="+paramString(params[name]))
+	}
+	return "[" + strings.Join(values, ",") + "]"
+}
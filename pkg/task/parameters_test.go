@@ -0,0 +1,187 @@
+package task
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTemplate = `kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: deploy
+  id: deploy
+spec:
+  parameters:
+    image: [nginx, redis]
+    replicas: [1, 3]
+  setup:
+    - script:
+        inline: echo {params.image}
+        env:
+          IMAGE: "{params.image}"
+  verify:
+    - k8s.checkReplicas:
+        replicas: "{params.replicas}"
+  prompt:
+    inline: Deploy {params.image} with {params.replicas} replicas
+`
+
+func TestInstances(t *testing.T) {
+	cfg, err := Read([]byte(testTemplate), "/tasks")
+	require.NoError(t, err)
+
+	instances, err := cfg.Instances()
+	require.NoError(t, err)
+
+	var names, ids, prompts []string
+	for _, instance := range instances {
+		names = append(names, instance.Metadata.Name)
+		ids = append(ids, instance.Metadata.ID)
+		prompts = append(prompts, instance.Spec.Prompt.Inline)
+		assert.Empty(t, instance.Spec.Parameters)
+	}
+	assert.Equal(t, []string{
+		"deploy[image=nginx,replicas=1]",
+		"deploy[image=nginx,replicas=3]",
+		"deploy[image=redis,replicas=1]",
+		"deploy[image=redis,replicas=3]",
+	}, names)
+	assert.Equal(t, []string{
+		"deploy[image=nginx,replicas=1]",
+		"deploy[image=nginx,replicas=3]",
+		"deploy[image=redis,replicas=1]",
+		"deploy[image=redis,replicas=3]",
+	}, ids)
+	assert.Equal(t, "Deploy redis with 3 replicas", prompts[3])
+
+	last := instances[3]
+	assert.Equal(t, map[string]any{"image": "redis", "replicas": float64(3)}, last.Parameters())
+	assert.JSONEq(t, `{"inline":"echo redis","env":{"IMAGE":"redis"}}`, string(last.Spec.Setup[0].Config["script"]))
+	assert.JSONEq(t, `{"replicas":3}`, string(last.Spec.Verify[0].Config["k8s.checkReplicas"]), "whole values keep their type")
+
+	assert.Nil(t, cfg.Parameters())
+}
+
+func TestInstancesWithoutParameters(t *testing.T) {
+	cfg, err := Read([]byte("kind: Task\napiVersion: mcpchecker/v1alpha2\nmetadata:\n  name: a\nspec:\n  prompt:\n    inline: Do {params.x}\n"), "/tasks")
+	require.NoError(t, err)
+
+	instances, err := cfg.Instances()
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Same(t, cfg, instances[0])
+}
+
+func TestInstancesNamedByParameters(t *testing.T) {
+	data := "kind: Task\napiVersion: mcpchecker/v1alpha2\nmetadata:\n  name: deploy-{params.image}\nspec:\n  parameters:\n    image: [nginx]\n  prompt:\n    inline: Deploy\n"
+	cfg, err := Read([]byte(data), "/tasks")
+	require.NoError(t, err)
+
+	instances, err := cfg.Instances()
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Equal(t, "deploy-nginx", instances[0].Metadata.Name)
+}
+
+func TestInstancesIDsWithWhitespace(t *testing.T) {
+	data := "kind: Task\napiVersion: mcpchecker/v1alpha2\nmetadata:\n  name: ask\n  id: ask\nspec:\n  parameters:\n    question: [\"what time is it\"]\n  prompt:\n    inline: \"{params.question}\"\n"
+	cfg, err := Read([]byte(data), "/tasks")
+	require.NoError(t, err)
+
+	instances, err := cfg.Instances()
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Equal(t, "ask[question=what time is it]", instances[0].Metadata.Name)
+	assert.Equal(t, "ask[question=what_time_is_it]", instances[0].Metadata.ID)
+	assert.NoError(t, ValidateID(instances[0].Metadata.ID))
+
+	data = "kind: Task\napiVersion: mcpchecker/v1alpha2\nmetadata:\n  name: ask\n  id: ask-{params.question}\nspec:\n  parameters:\n    question: [\"what time is it\"]\n  prompt:\n    inline: Ask\n"
+	cfg, err = Read([]byte(data), "/tasks")
+	require.NoError(t, err)
+
+	instances, err = cfg.Instances()
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Equal(t, "ask-what_time_is_it", instances[0].Metadata.ID)
+}
+
+func TestInstancesPromptFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "prompt.md"), []byte("Deploy {params.image}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plain.md"), []byte("Deploy it"), 0644))
+
+	for file, want := range map[string]string{"prompt.md": "Deploy nginx", "plain.md": ""} {
+		data := "kind: Task\napiVersion: mcpchecker/v1alpha2\nmetadata:\n  name: deploy\nspec:\n  parameters:\n    image: [nginx]\n  prompt:\n    file: " + file + "\n"
+		cfg, err := Read([]byte(data), dir)
+		require.NoError(t, err)
+
+		instances, err := cfg.Instances()
+		require.NoError(t, err)
+		require.Len(t, instances, 1)
+
+		prompt := instances[0].Spec.Prompt
+		assert.Equal(t, want, prompt.Inline)
+		if want == "" {
+			assert.Equal(t, filepath.Join(dir, file), prompt.File, "prompt files without references are kept")
+		}
+	}
+}
+
+func TestReadParameters(t *testing.T) {
+	tests := map[string]struct {
+		parameters string
+		prompt     string
+		wantErr    string
+	}{
+		"valid": {
+			parameters: `{"image": ["nginx"]}`,
+			prompt:     "Deploy {params.image}",
+		},
+		"undeclared parameter": {
+			parameters: `{"image": ["nginx"]}`,
+			prompt:     "Deploy {params.tag}",
+			wantErr:    `task references undeclared parameter "tag"`,
+		},
+		"no values": {
+			parameters: `{"image": []}`,
+			prompt:     "Deploy",
+			wantErr:    `parameter "image" has no values`,
+		},
+		"invalid name": {
+			parameters: `{"the image": ["nginx"]}`,
+			prompt:     "Deploy",
+			wantErr:    `invalid parameter name "the image"`,
+		},
+		"too many instances": {
+			parameters: `{"a": [` + numbers(100) + `], "b": [` + numbers(11) + `]}`,
+			prompt:     "Deploy",
+			wantErr:    "parameters expand into more than 1000 tasks",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			prompt, err := json.Marshal(tc.prompt)
+			require.NoError(t, err)
+			data := `{"kind": "Task", "apiVersion": "mcpchecker/v1alpha2", "metadata": {"name": "a"}, "spec": {"parameters": ` +
+				tc.parameters + `, "prompt": {"inline": ` + string(prompt) + `}}}`
+
+			_, err = Read([]byte(data), "/tasks")
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func numbers(n int) string {
+	data, _ := json.Marshal(make([]int, n))
+	return string(data[1 : len(data)-1])
+}
@@ -0,0 +1,165 @@
+package task
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// DefaultLocale is the locale of the prompt that runs select when they don't
+// select one.
+const DefaultLocale = "en"
+
+// localeTagPattern matches locale tags such as "en", "pt-BR" or "zh_Hant_TW":
+// a language code followed by optional script, region or variant subtags.
+var localeTagPattern = regexp.MustCompile(`^[a-zA-Z]{2,3}([-_][a-zA-Z0-9]{2,8})*$`)
+
+// Prompt is the prompt of a task. It is either a single prompt, set with
+// inline or file, or a prompt per locale, keyed by locale:
+//
+//	prompt:
+//	  en: List the pods in the default namespace.
+//	  de:
+//	    file: prompts/de.md
+//
+// Each locale is an inline string or an object with inline or file.
+type Prompt struct {
+	util.Step
+
+	// Locales are the variants of the prompt by locale. Runs select the one to
+	// prompt the agent with by locale.
+	Locales map[string]*util.Step
+}
+
+// IsEmpty reports whether p sets neither a prompt nor a locale.
+func (p *Prompt) IsEmpty() bool {
+	return p == nil || (p.Step.IsEmpty() && len(p.Locales) == 0)
+}
+
+// GetValue returns the text of a single prompt. Prompts with locales must be
+// resolved to a locale with ForLocale first.
+func (p *Prompt) GetValue() (string, error) {
+	if len(p.Locales) > 0 {
+		return "", fmt.Errorf("prompt has variants for locales %v: a locale must be selected", p.LocaleNames())
+	}
+	return p.Step.GetValue()
+}
+
+// HasLocales reports whether p has variants by locale.
+func (p *Prompt) HasLocales() bool {
+	return p != nil && len(p.Locales) > 0
+}
+
+// LocaleNames returns the sorted locales of p.
+func (p *Prompt) LocaleNames() []string {
+	names := make([]string, 0, len(p.Locales))
+	for locale := range p.Locales {
+		names = append(names, locale)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ForLocale returns the single prompt of locale, or false if p has no variant
+// for it.
+func (p *Prompt) ForLocale(locale string) (*Prompt, bool) {
+	step, ok := p.Locales[locale]
+	if !ok {
+		return nil, false
+	}
+	return &Prompt{Step: *step}, true
+}
+
+// Validate checks that p is either a single prompt or a set of non-empty
+// locale variants.
+func (p *Prompt) Validate() error {
+	if p == nil || len(p.Locales) == 0 {
+		return nil
+	}
+	if !p.Step.IsEmpty() {
+		return fmt.Errorf("prompt can't set inline or file together with locales")
+	}
+	for _, locale := range p.LocaleNames() {
+		if locale == "" {
+			return fmt.Errorf("prompt locale can't be empty")
+		}
+		if p.Locales[locale].IsEmpty() {
+			return fmt.Errorf("prompt for locale %q must set inline or file", locale)
+		}
+	}
+	return nil
+}
+
+// resolveRelativePaths makes the prompt files of p relative to basePath.
+func (p *Prompt) resolveRelativePaths(basePath string) error {
+	if err := util.ResolveRelativePath(&p.File, basePath); err != nil {
+		return err
+	}
+	for _, step := range p.Locales {
+		if err := util.ResolveRelativePath(&step.File, basePath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON reads a single prompt if the keys of the object are inline or
+// file, or the prompts of locales if they are locale tags. Objects mixing both
+// kinds of keys are rejected, so that a misspelled inline or file isn't read as
+// a locale.
+func (p *Prompt) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	var stepKeys, localeKeys []string
+	for key := range fields {
+		if key == "inline" || key == "file" {
+			stepKeys = append(stepKeys, key)
+		} else {
+			localeKeys = append(localeKeys, key)
+		}
+	}
+	sort.Strings(stepKeys)
+	sort.Strings(localeKeys)
+	if len(stepKeys) > 0 && len(localeKeys) > 0 {
+		return fmt.Errorf("prompt can't set %v together with other keys %v: a prompt is either inline or file, or a prompt per locale", stepKeys, localeKeys)
+	}
+	if len(localeKeys) == 0 {
+		p.Locales = nil
+		return json.Unmarshal(data, &p.Step)
+	}
+	for _, locale := range localeKeys {
+		if !localeTagPattern.MatchString(locale) {
+			return fmt.Errorf("invalid prompt locale %q: must be a locale tag such as \"en\" or \"pt-BR\"", locale)
+		}
+	}
+
+	p.Step = util.Step{}
+	p.Locales = make(map[string]*util.Step, len(fields))
+	for locale, raw := range fields {
+		step := &util.Step{}
+		if bytes.HasPrefix(bytes.TrimSpace(raw), []byte(`"`)) {
+			if err := json.Unmarshal(raw, &step.Inline); err != nil {
+				return fmt.Errorf("invalid prompt for locale %q: %w", locale, err)
+			}
+		} else if err := json.Unmarshal(raw, step); err != nil {
+			return fmt.Errorf("invalid prompt for locale %q: %w", locale, err)
+		}
+		p.Locales[locale] = step
+	}
+	return nil
+}
+
+// MarshalJSON writes p in the form it was read in.
+func (p Prompt) MarshalJSON() ([]byte, error) {
+	if len(p.Locales) > 0 {
+		return json.Marshal(p.Locales)
+	}
+	return json.Marshal(p.Step)
+}
@@ -0,0 +1,142 @@
+package task
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+func TestReadPrompt(t *testing.T) {
+	tt := map[string]struct {
+		prompt      string
+		expected    *Prompt
+		errContains string
+	}{
+		"inline": {
+			prompt:   "inline: List the pods",
+			expected: &Prompt{Step: util.Step{Inline: "List the pods"}},
+		},
+		"file": {
+			prompt:   "file: prompt.md",
+			expected: &Prompt{Step: util.Step{File: filepath.Join("/tasks", "prompt.md")}},
+		},
+		"locales": {
+			prompt: `en: List the pods
+    de:
+      file: prompts/de.md
+    ja:
+      inline: ポッドを一覧表示して`,
+			expected: &Prompt{Locales: map[string]*util.Step{
+				"en": {Inline: "List the pods"},
+				"de": {File: filepath.Join("/tasks", "prompts", "de.md")},
+				"ja": {Inline: "ポッドを一覧表示して"},
+			}},
+		},
+		"empty locale": {
+			prompt: `en: List the pods
+    de: ""`,
+			errContains: `prompt for locale "de" must set inline or file`,
+		},
+		"locale without inline or file": {
+			prompt: `en: List the pods
+    de:
+      text: Liste die Pods auf`,
+			errContains: `prompt for locale "de" must set inline or file`,
+		},
+		"inline with locales": {
+			prompt: `inline: List the pods
+    de: Liste die Pods auf`,
+			errContains: "prompt can't set [inline] together with other keys [de]",
+		},
+		"misspelled file": {
+			prompt: `file: prompt.md
+    flie: prompt.md`,
+			errContains: "prompt can't set [file] together with other keys [flie]",
+		},
+		"key is not a locale tag": {
+			prompt:      `text: List the pods`,
+			errContains: `invalid prompt locale "text": must be a locale tag`,
+		},
+		"regional locales": {
+			prompt: `pt-BR: Liste os pods
+    zh_Hant: 列出 Pod`,
+			expected: &Prompt{Locales: map[string]*util.Step{
+				"pt-BR":   {Inline: "Liste os pods"},
+				"zh_Hant": {Inline: "列出 Pod"},
+			}},
+		},
+	}
+
+	for tn, tc := range tt {
+		t.Run(tn, func(t *testing.T) {
+			data := `kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: localized
+spec:
+  verify:
+    - script:
+        inline: echo ok
+  prompt:
+    ` + tc.prompt + "\n"
+
+			cfg, err := Read([]byte(data), "/tasks")
+			if tc.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errContains)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, cfg.Spec.Prompt)
+		})
+	}
+}
+
+func TestPromptForLocale(t *testing.T) {
+	prompt := &Prompt{Locales: map[string]*util.Step{
+		"en": {Inline: "List the pods"},
+		"de": {Inline: "Liste die Pods auf"},
+	}}
+
+	assert.True(t, prompt.HasLocales())
+	assert.Equal(t, []string{"de", "en"}, prompt.LocaleNames())
+
+	_, err := prompt.GetValue()
+	assert.ErrorContains(t, err, "a locale must be selected")
+
+	de, ok := prompt.ForLocale("de")
+	require.True(t, ok)
+	assert.False(t, de.HasLocales())
+	text, err := de.GetValue()
+	require.NoError(t, err)
+	assert.Equal(t, "Liste die Pods auf", text)
+
+	_, ok = prompt.ForLocale("ja")
+	assert.False(t, ok)
+
+	var single *Prompt
+	assert.True(t, single.IsEmpty())
+	assert.False(t, single.HasLocales())
+}
+
+func TestPromptMarshalJSON(t *testing.T) {
+	for name, prompt := range map[string]*Prompt{
+		"single":  {Step: util.Step{Inline: "List the pods"}},
+		"locales": {Locales: map[string]*util.Step{"en": {Inline: "List the pods"}, "de": {File: "de.md"}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(prompt)
+			require.NoError(t, err)
+
+			roundTripped := &Prompt{}
+			require.NoError(t, json.Unmarshal(data, roundTripped))
+			assert.Equal(t, prompt, roundTripped)
+		})
+	}
+}
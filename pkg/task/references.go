@@ -0,0 +1,182 @@
+package task
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+
+	"github.com/genmcp/gen-mcp/pkg/template"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
+)
+
+// outputScope is the outputs that the {steps.*} references of a step, the
+// prompt or a reply can resolve to, by step ID and step type, like the step
+// outputs that the task runner records.
+type outputScope struct {
+	outputs map[string]steps.DeclaredOutputs
+
+	// open is set if a step in scope doesn't declare its outputs, so a
+	// reference to an unknown step may still resolve to its type
+	open bool
+}
+
+func (s *outputScope) clone() *outputScope {
+	return &outputScope{outputs: maps.Clone(s.outputs), open: s.open}
+}
+
+// addToScope adds the outputs of the step with ID id to s, under its ID and
+// type like recordStepOutputs records them.
+func (r *taskRunner) addToScope(s *outputScope, id string, runner steps.StepRunner) {
+	declarer, ok := runner.(steps.OutputDeclarer)
+	if !ok {
+		s.outputs[id] = steps.DeclaredOutputs{Unknown: true}
+		s.open = true
+		return
+	}
+
+	outputs := declarer.DeclaredOutputs()
+	s.outputs[id] = outputs
+	if _, isID := r.stepIDs[outputs.Type]; outputs.Type != "" && !isID {
+		s.outputs[outputs.Type] = outputs
+	}
+}
+
+// checkReference checks that the {steps.<field>} reference of what resolves to
+// an output in scope, returning why it can't otherwise.
+func (r *taskRunner) checkReference(s *outputScope, what, field string) error {
+	step, key, ok := steps.SplitStepReference(field)
+	if !ok {
+		return fmt.Errorf("must be in format {steps.<step>.<output>}")
+	}
+
+	outputs, ok := s.outputs[step]
+	if !ok {
+		if _, isID := r.stepIDs[step]; isID {
+			return fmt.Errorf("step %q does not run before %s", step, what)
+		}
+		if s.open {
+			return nil
+		}
+		return fmt.Errorf("no step with ID or type %q runs before %s", step, what)
+	}
+
+	if outputs.Has(key) {
+		return nil
+	}
+	if len(outputs.Keys) == 0 {
+		return fmt.Errorf("step %q sets no outputs", step)
+	}
+	return fmt.Errorf("step %q has no output %q (it sets %s)", step, key, strings.Join(outputs.Keys, ", "))
+}
+
+// checkStepReferences checks that the {steps.*} references of the steps, the
+// prompt and the replies of cfg resolve to outputs of steps that run before
+// them, so that a task that can't resolve them fails when it is loaded rather
+// than halfway through its run. Steps that don't declare their outputs, such
+// as extension operations without declared outputs, may set any output.
+func (r *taskRunner) checkStepReferences(cfg *TaskConfig) error {
+	var errs []error
+	// The task file is only read to point errors at their line
+	var taskData []byte
+	if cfg.path != "" {
+		taskData, _ = os.ReadFile(cfg.path)
+	}
+	check := func(s *outputScope, what string, refs []string, path string, data []byte) {
+		for _, field := range refs {
+			if err := r.checkReference(s, what, field); err != nil {
+				errs = append(errs, fmt.Errorf("%s%s references {steps.%s}: %w", referenceLocation(path, data, field), what, field, err))
+			}
+		}
+	}
+	checkStep := func(s *outputScope, what, id string, runner steps.StepRunner) {
+		if referencer, ok := runner.(steps.StepReferencer); ok {
+			check(s, what, referencer.StepReferences(), cfg.path, taskData)
+		}
+		r.addToScope(s, id, runner)
+	}
+	checkText := func(s *outputScope, what, text string, source *util.Step) {
+		path, data := cfg.path, taskData
+		if source != nil && source.File != "" {
+			path, data = source.File, []byte(text)
+		}
+		refs, err := textStepReferences(text)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s%s is not a valid template, so its {steps.*} references can't be resolved: %w", referenceLocation(path, nil, ""), what, err))
+			return
+		}
+		check(s, what, refs, path, data)
+	}
+
+	setup := &outputScope{outputs: make(map[string]steps.DeclaredOutputs)}
+	for i, runner := range r.setup {
+		checkStep(setup, fmt.Sprintf("setup[%d]", i), r.setupIDs[i], runner)
+	}
+
+	var prompt *util.Step
+	if cfg.Spec.Prompt != nil {
+		prompt = &cfg.Spec.Prompt.Step
+	}
+	checkText(setup, "the prompt", r.prompt, prompt)
+
+	interject := setup.clone()
+	for i, in := range r.interject {
+		for j, runner := range in.steps {
+			checkStep(interject, fmt.Sprintf("interject[%d].steps[%d]", i, j), in.ids[j], runner)
+		}
+		checkText(interject, fmt.Sprintf("interject[%d].reply", i), in.reply, cfg.Spec.Interject[i].Reply)
+	}
+
+	verify := setup.clone()
+	for i, runner := range r.verify {
+		checkStep(verify, fmt.Sprintf("verify[%d]", i), r.verifyIDs[i], runner)
+	}
+
+	cleanup := setup.clone()
+	for i, runner := range r.cleanup {
+		checkStep(cleanup, fmt.Sprintf("cleanup[%d]", i), r.cleanupIDs[i], runner)
+	}
+
+	cleanupVerify := setup.clone()
+	for i, runner := range r.cleanupVerify {
+		checkStep(cleanupVerify, fmt.Sprintf("cleanupVerify[%d]", i), r.cleanupVerifyIDs[i], runner)
+	}
+
+	return errors.Join(errs...)
+}
+
+// textStepReferences returns the fields of the {steps.*} references of a
+// prompt or reply, which are resolved like resolveTemplates does.
+func textStepReferences(text string) ([]string, error) {
+	if !strings.Contains(text, "{steps.") {
+		return nil, nil
+	}
+
+	parsed, err := template.ParseTemplate(text, template.TemplateParserOptions{
+		Sources: map[string]template.SourceFactory{
+			"steps": template.NewSourceFactory("steps"),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return steps.StepReferences(parsed), nil
+}
+
+// referenceLocation returns the "<path>:<line>: " prefix that points to the
+// first {steps.<field>} reference in data, the content of the file at path.
+// It returns only the path if data doesn't contain the reference, and an
+// empty string if the path is not known.
+func referenceLocation(path string, data []byte, field string) string {
+	if path == "" {
+		return ""
+	}
+	i := bytes.Index(data, []byte("{steps."+field+"}"))
+	if field == "" || i < 0 {
+		return path + ": "
+	}
+	return fmt.Sprintf("%s:%d: ", path, bytes.Count(data[:i], []byte("\n"))+1)
+}
@@ -0,0 +1,219 @@
+package task
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mcpchecker/mcpchecker/pkg/extension"
+	"github.com/mcpchecker/mcpchecker/pkg/extension/client"
+	extprotocol "github.com/mcpchecker/mcpchecker/pkg/extension/protocol"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// manifestExtensions is an extension manager whose extensions only have a
+// manifest.
+type manifestExtensions map[string]*extprotocol.InitializeResult
+
+func (m manifestExtensions) Register(string, *extension.ExtensionSpec) error { return nil }
+func (m manifestExtensions) Get(_ context.Context, name string) (client.Client, error) {
+	return &manifestClient{manifest: m[name]}, nil
+}
+func (m manifestExtensions) Has(name string) bool {
+	_, ok := m[name]
+	return ok
+}
+func (m manifestExtensions) ShutdownAll(context.Context) error { return nil }
+
+type manifestClient struct {
+	manifest *extprotocol.InitializeResult
+}
+
+func (c *manifestClient) Start(context.Context, *extprotocol.InitializeParams) error { return nil }
+func (c *manifestClient) Execute(context.Context, *extprotocol.ExecuteParams, client.LogSink) (*extprotocol.ExecuteResult, error) {
+	return &extprotocol.ExecuteResult{Success: true}, nil
+}
+func (c *manifestClient) Manifest() *extprotocol.InitializeResult { return c.manifest }
+func (c *manifestClient) Shutdown(context.Context) error          { return nil }
+
+func TestCheckStepReferences(t *testing.T) {
+	deps := &steps.Dependencies{
+		Extensions: manifestExtensions{
+			"k8s": {
+				Name: "k8s",
+				Operations: map[string]*extprotocol.Operation{
+					"createNamespace": {Outputs: map[string]string{"namespace": "Name of the namespace"}},
+					"apply":           {},
+				},
+			},
+		},
+	}
+
+	const header = `kind: Task
+apiVersion: mcpchecker/v1alpha2
+metadata:
+  name: refs
+spec:
+  requires:
+    - extension: k8s
+  setup:
+    - id: create_ns
+      k8s.createNamespace: {}
+    - id: apply
+      k8s.apply: {}
+`
+
+	tests := map[string]struct {
+		spec        string
+		errContains []string
+	}{
+		"valid references": {
+			spec: `  prompt:
+    inline: Scale web in {steps.create_ns.namespace}
+  interject:
+    - steps:
+        - id: scale_down
+          k8s.apply: {}
+      reply:
+        inline: It was scaled to {steps.scale_down.replicas} replicas
+  verify:
+    - id: check
+      script:
+        inline: echo $NS
+        env:
+          NS: "{steps.create_ns.namespace}"
+          MANIFEST: "{steps.apply.manifest}"
+    - llmJudge:
+        contains: "{steps.k8s.createNamespace.namespace}"
+  cleanup:
+    - script:
+        inline: echo $NS
+        env:
+          NS: "{steps.create_ns.namespace}"
+`,
+		},
+		"unknown step": {
+			spec: `  prompt:
+    inline: Scale web in {steps.create_nss.namespace}
+`,
+			errContains: []string{`task.yaml:14: the prompt references {steps.create_nss.namespace}: no step with ID or type "create_nss" runs before the prompt`},
+		},
+		"unknown output": {
+			spec: `  prompt:
+    inline: Scale web
+  verify:
+    - llmJudge:
+        contains: "{steps.create_ns.name}"
+`,
+			errContains: []string{`task.yaml:17: verify[0] references {steps.create_ns.name}: step "create_ns" has no output "name" (it sets namespace)`},
+		},
+		"step of a later phase": {
+			spec: `  prompt:
+    inline: Scale web to {steps.judge.samples}
+  verify:
+    - id: judge
+      llmJudge:
+        contains: scaled
+  cleanup:
+    - script:
+        inline: echo $SAMPLES
+        env:
+          SAMPLES: "{steps.judge.passedSamples}"
+`,
+			errContains: []string{
+				`the prompt references {steps.judge.samples}: step "judge" does not run before the prompt`,
+				`task.yaml:23: cleanup[0] references {steps.judge.passedSamples}: step "judge" does not run before cleanup[0]`,
+			},
+		},
+		"cleanup step in cleanupVerify": {
+			spec: `  prompt:
+    inline: Scale web
+  cleanup:
+    - id: delete_ns
+      k8s.apply: {}
+  cleanupVerify:
+    - script:
+        inline: test -z "$(kubectl get ns $NS --ignore-not-found)"
+        env:
+          NS: "{steps.create_ns.namespace}"
+          DELETED: "{steps.delete_ns.name}"
+`,
+			errContains: []string{`cleanupVerify[0] references {steps.delete_ns.name}: step "delete_ns" does not run before cleanupVerify[0]`},
+		},
+		"later step of the same phase": {
+			spec: `  prompt:
+    inline: Scale web
+  verify:
+    - script:
+        inline: echo $SAMPLES
+        env:
+          SAMPLES: "{steps.judge.samples}"
+    - id: judge
+      llmJudge:
+        contains: scaled
+`,
+			errContains: []string{`verify[0] references {steps.judge.samples}: step "judge" does not run before verify[0]`},
+		},
+		"step without outputs": {
+			spec: `  prompt:
+    inline: Scale web
+  verify:
+    - script:
+        inline: echo ok
+    - llmJudge:
+        contains: "{steps.verify_0.stdout} and {steps.script.stdout}"
+`,
+			errContains: []string{
+				`{steps.verify_0.stdout}: step "verify_0" sets no outputs`,
+				`{steps.script.stdout}: step "script" sets no outputs`,
+			},
+		},
+		"reply references a verify step": {
+			spec: `  prompt:
+    inline: Scale web
+  interject:
+    - reply:
+        inline: Check {steps.judge.samples}
+  verify:
+    - id: judge
+      llmJudge:
+        contains: scaled
+`,
+			errContains: []string{`interject[0].reply references {steps.judge.samples}: step "judge" does not run before interject[0].reply`},
+		},
+		"malformed reference": {
+			spec: `  prompt:
+    inline: Scale web in {steps.create_ns}
+`,
+			errContains: []string{`the prompt references {steps.create_ns}: must be in format {steps.<step>.<output>}`},
+		},
+		"prompt that is not a valid template": {
+			spec: `  prompt:
+    inline: 'Apply {"replicas": 3} in {steps.create_ns.namespace}'
+`,
+			errContains: []string{"the prompt is not a valid template"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "task.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(header+tc.spec), 0644))
+			cfg, err := FromFile(path)
+			require.NoError(t, err)
+
+			_, err = NewTaskRunner(context.Background(), cfg, deps)
+			if len(tc.errContains) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, want := range tc.errContains {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
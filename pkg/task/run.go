@@ -5,13 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/genmcp/gen-mcp/pkg/template"
 	"github.com/mcpchecker/mcpchecker/pkg/agent"
-	"github.com/mcpchecker/mcpchecker/pkg/extension/client"
-	"github.com/mcpchecker/mcpchecker/pkg/mcpclient"
 	"github.com/mcpchecker/mcpchecker/pkg/steps"
 	"github.com/mcpchecker/mcpchecker/pkg/tokens"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 )
 
 // AgentDetails captures structured information from the agent execution.
@@ -19,6 +19,14 @@ type AgentDetails struct {
 	TokenEstimate *tokens.Estimate        `json:"tokenEstimate,omitempty"`
 	ToolCalls     []agent.ToolCallSummary `json:"toolCalls,omitempty"`
 	OutputSteps   []agent.OutputStep      `json:"outputSteps,omitempty"`
+	ResourceUsage *util.ResourceUsage     `json:"resourceUsage,omitempty"`
+
+	// RawUpdates are the raw session updates reported by the agent. They are only
+	// persisted when the run asks for them, as EvalResult.RawUpdates.
+	RawUpdates any `json:"-"`
+
+	// Prompt is the prompt the agent was given, with its templates resolved
+	Prompt string `json:"prompt,omitempty"`
 }
 
 // PhaseOutput represents the output from a task phase (setup, agent, verify, or cleanup).
@@ -39,45 +47,118 @@ type PhaseOutput struct {
 	AgentDetails *AgentDetails `json:"agentDetails,omitempty"`
 }
 
+// TaskRunner runs the phases of a task. When their phase fails, Setup, RunAgent
+// and Verify return a SetupError, AgentError and VerifyError respectively.
 type TaskRunner interface {
 	Setup(ctx context.Context) (*PhaseOutput, error)
 	Cleanup(ctx context.Context) (*PhaseOutput, error)
+	// VerifyCleanup runs the cleanupVerify steps after Cleanup. Its failing
+	// steps report the resources that cleanup left behind.
+	VerifyCleanup(ctx context.Context) (*PhaseOutput, error)
+	// RestoreSetup makes the outputs of setup, as recorded by an earlier
+	// run of Setup, available to the cleanup steps without running setup
+	// again, so that the cleanup of a crashed run can be replayed.
+	RestoreSetup(setup *PhaseOutput)
 	RunAgent(ctx context.Context, agent agent.Runner) (*PhaseOutput, error)
 	Verify(ctx context.Context) (*PhaseOutput, error)
+	// Clock returns the clock of the task run, nil for the real time. The
+	// steps of the task see its time, and so should its MCP servers.
+	Clock() *util.Clock
 }
 
 type taskRunner struct {
 	setup   []steps.StepRunner
 	verify  []steps.StepRunner
 	cleanup []steps.StepRunner
-	prompt  string
-	output  string
+	prompt  string // Unresolved prompt; may contain {steps.*} templates
 	baseDir string
 
+	// Steps that check that cleanup left nothing behind
+	cleanupVerify []steps.StepRunner
+
+	// Interjections between the turns of a multi-turn task
+	interject []*interjection
+
+	// Result of the most recent agent run, read by Verify. RunAgent may be
+	// called several times, including concurrently.
+	mu        sync.Mutex
+	output    string
+	toolCalls []agent.ToolCallSummary
+
+	// Step IDs, index-aligned with the setup, verify, cleanup and
+	// cleanupVerify runners
+	setupIDs         []string
+	verifyIDs        []string
+	cleanupIDs       []string
+	cleanupVerifyIDs []string
+	stepIDs          map[string]struct{}
+
 	setupOutputs map[string]map[string]string
 	random       *steps.RandomResolver
+	deps         *steps.Dependencies
+	clock        *util.Clock
 }
 
-func NewTaskRunner(ctx context.Context, cfg *TaskConfig) (TaskRunner, error) {
+// MissingRequirements returns the extensions and MCP servers that cfg requires
+// but deps does not provide, such as `mcpServer "github"`.
+func MissingRequirements(cfg *TaskConfig, deps *steps.Dependencies) []string {
+	if cfg.Spec == nil {
+		return nil
+	}
+
+	extensionManager, hasExtensionManager := deps.ExtensionManager()
+	mcpClientManager, hasMcpManager := deps.McpManager()
+
+	var missing []string
+	for _, req := range cfg.Spec.Requires {
+		if req.Extension != nil && (!hasExtensionManager || !extensionManager.Has(*req.Extension)) {
+			missing = append(missing, fmt.Sprintf("extension %q", *req.Extension))
+		}
+		if req.McpServer != nil {
+			var registered bool
+			if hasMcpManager {
+				_, registered = mcpClientManager.Get(*req.McpServer)
+			}
+			if !registered {
+				missing = append(missing, fmt.Sprintf("mcpServer %q", *req.McpServer))
+			}
+		}
+	}
+
+	return missing
+}
+
+// NewTaskRunner parses the steps of cfg. deps provides the extension manager, MCP
+// clients and judge that the steps use; ctx is only used for cancellation.
+func NewTaskRunner(ctx context.Context, cfg *TaskConfig, deps *steps.Dependencies) (TaskRunner, error) {
 	if cfg.Spec.Prompt.IsEmpty() {
 		return nil, fmt.Errorf("prompt.inline or prompt.file must be set on a task to run it")
 	}
 
-	var err error
+	clock, err := cfg.Spec.Clock.NewClock()
+	if err != nil {
+		return nil, err
+	}
+
 	r := &taskRunner{
 		setup:   make([]steps.StepRunner, len(cfg.Spec.Setup)),
 		verify:  make([]steps.StepRunner, len(cfg.Spec.Verify)),
 		cleanup: make([]steps.StepRunner, len(cfg.Spec.Cleanup)),
+		stepIDs: make(map[string]struct{}),
 		baseDir: cfg.basePath,
 		random:  steps.NewRandomResolver(),
+		deps:    deps,
+		clock:   clock,
+
+		cleanupVerify: make([]steps.StepRunner, len(cfg.Spec.CleanupVerify)),
 	}
 
-	extensionManager, ok := client.ManagerFromContext(ctx)
+	extensionManager, ok := deps.ExtensionManager()
 	if !ok {
-		return nil, fmt.Errorf("failed to get extension manager from context")
+		return nil, fmt.Errorf("no extension manager configured")
 	}
 
-	mcpClientManager, hasMcpManager := mcpclient.ManagerFromContext(ctx)
+	mcpClientManager, hasMcpManager := deps.McpManager()
 	if !hasMcpManager {
 		for _, req := range cfg.Spec.Requires {
 			if req.McpServer != nil {
@@ -146,12 +227,16 @@ func NewTaskRunner(ctx context.Context, cfg *TaskConfig) (TaskRunner, error) {
 		}
 	}
 
-	parser := steps.DefaultRegistry.WithExtensions(ctx, extensions).WithMcpServers(ctx, mcpServers)
+	parser := steps.DefaultRegistry.WithExtensions(ctx, deps, extensions).WithMcpServers(ctx, deps, mcpServers)
 
 	for i, stepCfg := range cfg.Spec.Setup {
 		if stepCfg.ID == "" {
 			stepCfg.ID = fmt.Sprintf("setup_%d", i)
 		}
+		if idErr := r.addStepID(stepCfg.ID); idErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid setup[%d]: %w", i, idErr))
+		}
+		r.setupIDs = append(r.setupIDs, stepCfg.ID)
 		var stepErr error
 		r.setup[i], stepErr = parser.Parse(stepCfg)
 		if stepErr != nil {
@@ -163,6 +248,10 @@ func NewTaskRunner(ctx context.Context, cfg *TaskConfig) (TaskRunner, error) {
 		if stepCfg.ID == "" {
 			stepCfg.ID = fmt.Sprintf("verify_%d", i)
 		}
+		if idErr := r.addStepID(stepCfg.ID); idErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid verify[%d]: %w", i, idErr))
+		}
+		r.verifyIDs = append(r.verifyIDs, stepCfg.ID)
 		var stepErr error
 		r.verify[i], stepErr = parser.Parse(stepCfg)
 		if stepErr != nil {
@@ -174,6 +263,10 @@ func NewTaskRunner(ctx context.Context, cfg *TaskConfig) (TaskRunner, error) {
 		if stepCfg.ID == "" {
 			stepCfg.ID = fmt.Sprintf("cleanup_%d", i)
 		}
+		if idErr := r.addStepID(stepCfg.ID); idErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid cleanup[%d]: %w", i, idErr))
+		}
+		r.cleanupIDs = append(r.cleanupIDs, stepCfg.ID)
 		var stepErr error
 		r.cleanup[i], stepErr = parser.Parse(stepCfg)
 		if stepErr != nil {
@@ -181,6 +274,46 @@ func NewTaskRunner(ctx context.Context, cfg *TaskConfig) (TaskRunner, error) {
 		}
 	}
 
+	for i, stepCfg := range cfg.Spec.CleanupVerify {
+		if stepCfg.ID == "" {
+			stepCfg.ID = fmt.Sprintf("cleanupVerify_%d", i)
+		}
+		if idErr := r.addStepID(stepCfg.ID); idErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid cleanupVerify[%d]: %w", i, idErr))
+		}
+		r.cleanupVerifyIDs = append(r.cleanupVerifyIDs, stepCfg.ID)
+		var stepErr error
+		r.cleanupVerify[i], stepErr = parser.Parse(stepCfg)
+		if stepErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to parse cleanupVerify[%d]: %w", i, stepErr))
+		}
+	}
+
+	for i, in := range cfg.Spec.Interject {
+		parsed := &interjection{}
+		for j, stepCfg := range in.Steps {
+			if stepCfg.ID == "" {
+				stepCfg.ID = fmt.Sprintf("interject_%d_%d", i, j)
+			}
+			if idErr := r.addStepID(stepCfg.ID); idErr != nil {
+				err = errors.Join(err, fmt.Errorf("invalid interject[%d].steps[%d]: %w", i, j, idErr))
+			}
+			parsed.ids = append(parsed.ids, stepCfg.ID)
+			stepRunner, stepErr := parser.Parse(stepCfg)
+			if stepErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to parse interject[%d].steps[%d]: %w", i, j, stepErr))
+			}
+			parsed.steps = append(parsed.steps, stepRunner)
+		}
+
+		var replyErr error
+		parsed.reply, replyErr = in.Reply.GetValue()
+		if replyErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to get reply of interject[%d]: %w", i, replyErr))
+		}
+		r.interject = append(r.interject, parsed)
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse task steps: %w", err)
 	}
@@ -190,9 +323,74 @@ func NewTaskRunner(ctx context.Context, cfg *TaskConfig) (TaskRunner, error) {
 		return nil, fmt.Errorf("failed to get prompt for task: %w", err)
 	}
 
+	if err := r.checkStepReferences(cfg); err != nil {
+		return nil, fmt.Errorf("invalid step references: %w", err)
+	}
+
 	return r, nil
 }
 
+// addStepID registers a step ID, rejecting IDs already used by another step of the task.
+func (r *taskRunner) addStepID(id string) error {
+	if _, ok := r.stepIDs[id]; ok {
+		return fmt.Errorf("duplicate step id %q", id)
+	}
+	r.stepIDs[id] = struct{}{}
+	return nil
+}
+
+// recordStepOutputs stores the outputs of a successful step under its ID. They are
+// also stored under the step type so that {steps.<type>.<key>} references keep
+// working; the most recent step of a type wins, and a type never shadows a step ID.
+func (r *taskRunner) recordStepOutputs(outputs map[string]map[string]string, id string, res *steps.StepOutput) {
+	if res == nil || !res.Success || len(res.Outputs) == 0 {
+		return
+	}
+
+	outputs[id] = res.Outputs
+	if _, isID := r.stepIDs[res.Type]; res.Type != "" && !isID {
+		outputs[res.Type] = res.Outputs
+	}
+}
+
+// stepDebugInput is the input of a step, as recorded in debug mode
+type stepDebugInput struct {
+	Workdir     string                       `json:"workdir"`
+	StepOutputs map[string]map[string]string `json:"stepOutputs,omitempty"`
+	Agent       *steps.AgentContext          `json:"agent,omitempty"`
+}
+
+// stepDebugOutput is the result of a step, as recorded in debug mode
+type stepDebugOutput struct {
+	Output *steps.StepOutput `json:"output,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// executeStep runs a step. In debug mode, its input and output are written to
+// <phase>/<id> in the debug directory of the task, which is also passed on to
+// the step so that it can record the values of its resolved templates.
+func executeStep(ctx context.Context, phase, id string, s steps.StepRunner, input *steps.StepInput) (*steps.StepOutput, error) {
+	debug := util.DebugDirFromContext(ctx).Sub(phase).Sub(id)
+	debug.WriteJSON("input.json", stepDebugInput{
+		Workdir:     input.Workdir,
+		StepOutputs: input.StepOutputs,
+		Agent:       input.Agent,
+	})
+
+	res, err := func() (res *steps.StepOutput, err error) {
+		defer RecoverPanic(&err)
+		return s.Execute(util.WithDebugDir(ctx, debug), input)
+	}()
+
+	record := stepDebugOutput{Output: res}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	debug.WriteJSON("output.json", record)
+
+	return res, err
+}
+
 func (r *taskRunner) Setup(ctx context.Context) (*PhaseOutput, error) {
 	out := &PhaseOutput{
 		Steps:   make([]*steps.StepOutput, 0),
@@ -202,26 +400,29 @@ func (r *taskRunner) Setup(ctx context.Context) (*PhaseOutput, error) {
 	stepOutputs := make(map[string]map[string]string)
 
 	for i, s := range r.setup {
-		res, err := s.Execute(ctx, &steps.StepInput{
+		res, err := executeStep(ctx, "setup", r.setupIDs[i], s, &steps.StepInput{
 			Workdir:     r.baseDir,
 			StepOutputs: stepOutputs,
 			Random:      r.random,
+			Deps:        r.deps,
+			Clock:       r.clock,
 		})
 
+		if res != nil {
+			res.ID = r.setupIDs[i]
+		}
 		out.Steps = append(out.Steps, res)
 		if err != nil {
 			out.Success = false
 			out.Error = err.Error()
-			return out, fmt.Errorf("setup[%d] failed: %w", i, err)
+			return out, &SetupError{Err: fmt.Errorf("setup[%d] failed: %w", i, err)}
 		}
 		if res != nil && !res.Success {
 			out.Success = false
 		}
 
 		// Accumulate outputs from this step
-		if res != nil && res.Success && len(res.Outputs) > 0 && res.Type != "" {
-			stepOutputs[res.Type] = res.Outputs
-		}
+		r.recordStepOutputs(stepOutputs, r.setupIDs[i], res)
 	}
 
 	r.setupOutputs = stepOutputs
@@ -229,6 +430,28 @@ func (r *taskRunner) Setup(ctx context.Context) (*PhaseOutput, error) {
 	return out, nil
 }
 
+func (r *taskRunner) Clock() *util.Clock {
+	return r.clock
+}
+
+func (r *taskRunner) RestoreSetup(setup *PhaseOutput) {
+	stepOutputs := make(map[string]map[string]string)
+	if setup != nil {
+		for i, res := range setup.Steps {
+			if res == nil {
+				continue
+			}
+			id := res.ID
+			// Results written before steps recorded their IDs
+			if id == "" && i < len(r.setupIDs) {
+				id = r.setupIDs[i]
+			}
+			r.recordStepOutputs(stepOutputs, id, res)
+		}
+	}
+	r.setupOutputs = stepOutputs
+}
+
 func (r *taskRunner) Cleanup(ctx context.Context) (*PhaseOutput, error) {
 	out := &PhaseOutput{
 		Steps:   make([]*steps.StepOutput, 0),
@@ -243,12 +466,17 @@ func (r *taskRunner) Cleanup(ctx context.Context) (*PhaseOutput, error) {
 	}
 
 	for i, s := range r.cleanup {
-		res, err := s.Execute(ctx, &steps.StepInput{
+		res, err := executeStep(ctx, "cleanup", r.cleanupIDs[i], s, &steps.StepInput{
 			Workdir:     r.baseDir,
 			StepOutputs: stepOutputs,
 			Random:      r.random,
+			Deps:        r.deps,
+			Clock:       r.clock,
 		})
 
+		if res != nil {
+			res.ID = r.cleanupIDs[i]
+		}
 		out.Steps = append(out.Steps, res)
 		if err != nil {
 			out.Success = false
@@ -260,19 +488,73 @@ func (r *taskRunner) Cleanup(ctx context.Context) (*PhaseOutput, error) {
 		}
 
 		// Accumulate outputs from this step
-		if res != nil && res.Success && len(res.Outputs) > 0 && res.Type != "" {
-			stepOutputs[res.Type] = res.Outputs
-		}
+		r.recordStepOutputs(stepOutputs, r.cleanupIDs[i], res)
 	}
 
 	return out, nil
 }
 
+// VerifyCleanup runs every cleanupVerify step, also after one of them failed,
+// so that all the resources cleanup left behind are reported. A step that
+// fails to run is recorded with its error, since it can't show that nothing
+// was left behind either.
+func (r *taskRunner) VerifyCleanup(ctx context.Context) (*PhaseOutput, error) {
+	out := &PhaseOutput{
+		Steps:   make([]*steps.StepOutput, 0),
+		Success: true,
+	}
+
+	// Like cleanup steps, the steps can reference setup outputs, such as the
+	// names of the resources to check for
+	stepOutputs := make(map[string]map[string]string)
+	for k, v := range r.setupOutputs {
+		stepOutputs[k] = v
+	}
+
+	var errs []error
+	for i, s := range r.cleanupVerify {
+		res, err := executeStep(ctx, "cleanupVerify", r.cleanupVerifyIDs[i], s, &steps.StepInput{
+			Workdir:     r.baseDir,
+			StepOutputs: stepOutputs,
+			Random:      r.random,
+			Deps:        r.deps,
+			Clock:       r.clock,
+		})
+
+		if err != nil {
+			res = &steps.StepOutput{Error: err.Error()}
+			errs = append(errs, fmt.Errorf("cleanupVerify[%d] failed: %w", i, err))
+		} else if res == nil {
+			res = &steps.StepOutput{Success: true}
+		}
+		res.ID = r.cleanupVerifyIDs[i]
+		out.Steps = append(out.Steps, res)
+		if !res.Success {
+			out.Success = false
+		}
+
+		r.recordStepOutputs(stepOutputs, r.cleanupVerifyIDs[i], res)
+	}
+
+	err := errors.Join(errs...)
+	if err != nil {
+		out.Error = err.Error()
+	}
+	return out, err
+}
+
 // resolvePromptTemplates resolves {steps.*} template variables in the prompt
 // using outputs collected during setup. Returns the original prompt if no
-// templates are present or if resolution fails.
+// templates are present or if resolution fails. It does not modify r, so the
+// agent phase can be run repeatedly with the same resolved prompt.
 func (r *taskRunner) resolvePromptTemplates(prompt string) string {
-	if len(r.setupOutputs) == 0 || !strings.Contains(prompt, "{steps.") {
+	return resolveTemplates(prompt, r.setupOutputs)
+}
+
+// resolveTemplates resolves {steps.*} template variables in prompt using
+// stepOutputs, returning prompt unchanged if resolution fails.
+func resolveTemplates(prompt string, stepOutputs map[string]map[string]string) string {
+	if len(stepOutputs) == 0 || !strings.Contains(prompt, "{steps.") {
 		return prompt
 	}
 
@@ -292,7 +574,7 @@ func (r *taskRunner) resolvePromptTemplates(prompt string) string {
 		return prompt
 	}
 
-	resolver := steps.NewStepOutputResolver(r.setupOutputs)
+	resolver := steps.NewStepOutputResolver(stepOutputs)
 	builder.SetSourceResolver("steps", resolver)
 
 	result, err := builder.GetResult()
@@ -309,8 +591,28 @@ func (r *taskRunner) resolvePromptTemplates(prompt string) string {
 }
 
 func (r *taskRunner) RunAgent(ctx context.Context, agentRunner agent.Runner) (*PhaseOutput, error) {
-	r.prompt = r.resolvePromptTemplates(r.prompt)
-	result, err := agentRunner.RunTask(ctx, r.prompt)
+	prompt := r.resolvePromptTemplates(r.prompt)
+
+	debug := util.DebugDirFromContext(ctx).Sub("agent")
+	debug.WriteFile("prompt.txt", []byte(prompt))
+	if prompt != r.prompt {
+		debug.WriteFile("prompt-template.txt", []byte(r.prompt))
+	}
+
+	var result agent.AgentResult
+	var err error
+	var conv *conversation
+	func() {
+		defer RecoverPanic(&err)
+		if len(r.interject) == 0 {
+			result, err = agentRunner.RunTask(util.WithDebugDir(ctx, debug), prompt)
+		} else if convRunner, ok := agentRunner.(agent.ConversationRunner); ok {
+			conv = r.newConversation(prompt, debug)
+			result, err = convRunner.RunConversation(util.WithDebugDir(ctx, debug), prompt, conv.nextTurn)
+		} else {
+			err = fmt.Errorf("agent %q does not support multi-turn tasks", agentRunner.AgentName())
+		}
+	}()
 	if err != nil {
 		detailErr := fmt.Errorf("failed to run agent: %w", err)
 		return &PhaseOutput{
@@ -324,19 +626,28 @@ func (r *taskRunner) RunAgent(ctx context.Context, agentRunner agent.Runner) (*P
 					"output": err.Error(),
 				},
 			}},
-		}, detailErr
+		}, &AgentError{Err: detailErr}
 	}
 
 	outputSteps := result.GetOutput()
-	finalMessage := agent.FinalMessageFromSteps(outputSteps)
-	r.output = finalMessage
+	toolCalls := result.GetToolCalls()
+
+	r.mu.Lock()
+	r.output = agent.FinalMessageFromSteps(outputSteps)
+	r.toolCalls = toolCalls
+	r.mu.Unlock()
 
 	// Capture structured agent details
 	tokenEstimate := result.GetTokenEstimate()
 	agentDetails := &AgentDetails{
 		TokenEstimate: &tokenEstimate,
-		ToolCalls:     result.GetToolCalls(),
+		ToolCalls:     toolCalls,
 		OutputSteps:   outputSteps,
+		RawUpdates:    result.GetRawUpdates(),
+		Prompt:        prompt,
+	}
+	if reporter, ok := result.(agent.ResourceUsageReporter); ok {
+		agentDetails.ResourceUsage = reporter.GetResourceUsage()
 	}
 
 	// Convert each OutputStep to a StepOutput for the phase
@@ -367,6 +678,11 @@ func (r *taskRunner) RunAgent(ctx context.Context, agentRunner agent.Runner) (*P
 		}
 	}
 
+	// The steps of interjections follow the steps of the agent
+	if conv != nil {
+		phaseSteps = append(phaseSteps, conv.steps...)
+	}
+
 	return &PhaseOutput{
 		Success:      true,
 		AgentDetails: agentDetails,
@@ -380,33 +696,47 @@ func (r *taskRunner) Verify(ctx context.Context) (*PhaseOutput, error) {
 		Success: true,
 	}
 
+	// Seed verify step outputs with setup outputs; outputs of earlier verify steps
+	// are added as the phase runs, so e.g. an llmJudge step can reference a value
+	// computed by a preceding script step.
 	stepOutputs := make(map[string]map[string]string)
+	for k, v := range r.setupOutputs {
+		stepOutputs[k] = v
+	}
+
+	r.mu.Lock()
+	agentCtx := &steps.AgentContext{
+		Prompt:    r.resolvePromptTemplates(r.prompt),
+		Output:    r.output,
+		ToolCalls: r.toolCalls,
+	}
+	r.mu.Unlock()
 
 	for i, s := range r.verify {
-		res, err := s.Execute(ctx, &steps.StepInput{
-			Agent: &steps.AgentContext{
-				Prompt: r.prompt,
-				Output: r.output,
-			},
+		res, err := executeStep(ctx, "verify", r.verifyIDs[i], s, &steps.StepInput{
+			Agent:       agentCtx,
 			Workdir:     r.baseDir,
 			StepOutputs: stepOutputs,
 			Random:      r.random,
+			Deps:        r.deps,
+			Clock:       r.clock,
 		})
 
+		if res != nil {
+			res.ID = r.verifyIDs[i]
+		}
 		out.Steps = append(out.Steps, res)
 		if err != nil {
 			out.Success = false
 			out.Error = err.Error()
-			return out, fmt.Errorf("verify[%d] failed: %w", i, err)
+			return out, &VerifyError{Err: fmt.Errorf("verify[%d] failed: %w", i, err)}
 		}
 		if res != nil && !res.Success {
 			out.Success = false
 		}
 
 		// Accumulate outputs from this step
-		if res != nil && res.Success && len(res.Outputs) > 0 && res.Type != "" {
-			stepOutputs[res.Type] = res.Outputs
-		}
+		r.recordStepOutputs(stepOutputs, r.verifyIDs[i], res)
 	}
 
 	return out, nil
@@ -1,9 +1,20 @@
 package task
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 
+	"github.com/mcpchecker/mcpchecker/pkg/agent"
+	"github.com/mcpchecker/mcpchecker/pkg/mcpproxy"
+	"github.com/mcpchecker/mcpchecker/pkg/steps"
+	"github.com/mcpchecker/mcpchecker/pkg/tokens"
+	"github.com/mcpchecker/mcpchecker/pkg/util"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestResolvePromptTemplates(t *testing.T) {
@@ -75,3 +86,319 @@ func TestResolvePromptTemplates(t *testing.T) {
 		})
 	}
 }
+
+// outputStep is a steps.StepRunner that returns fixed outputs and records the
+// step outputs it was given
+type outputStep struct {
+	stepType string
+	outputs  map[string]string
+	seen     map[string]map[string]string
+}
+
+func (s *outputStep) Execute(_ context.Context, input *steps.StepInput) (*steps.StepOutput, error) {
+	s.seen = make(map[string]map[string]string, len(input.StepOutputs))
+	for k, v := range input.StepOutputs {
+		s.seen[k] = v
+	}
+	return &steps.StepOutput{Type: s.stepType, Success: true, Outputs: s.outputs}, nil
+}
+
+// panicStep is a steps.StepRunner that panics
+type panicStep struct{}
+
+func (panicStep) Execute(context.Context, *steps.StepInput) (*steps.StepOutput, error) {
+	panic("index out of range")
+}
+
+func TestSetupStepPanic(t *testing.T) {
+	first := &outputStep{stepType: "script"}
+	r := &taskRunner{
+		setup:    []steps.StepRunner{first, panicStep{}},
+		setupIDs: []string{"create_ns", "setup_1"},
+		stepIDs:  map[string]struct{}{"create_ns": {}, "setup_1": {}},
+	}
+
+	out, err := r.Setup(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, ErrorKindSetup, ErrorKindOf(err))
+	assert.Contains(t, err.Error(), "panic: index out of range")
+	assert.NotEmpty(t, PanicStack(err))
+
+	// The phase output records the steps up to the panicking one
+	assert.False(t, out.Success)
+	assert.Equal(t, "panic: index out of range", out.Error)
+	require.Len(t, out.Steps, 2)
+	assert.True(t, out.Steps[0].Success)
+}
+
+// leakStep is a steps.StepRunner that fails, like a cleanupVerify step that
+// finds a resource left behind
+type leakStep struct {
+	message string
+}
+
+func (s leakStep) Execute(context.Context, *steps.StepInput) (*steps.StepOutput, error) {
+	return &steps.StepOutput{Type: "script", Success: false, Message: s.message}, nil
+}
+
+func TestVerifyCleanupRunsEveryStep(t *testing.T) {
+	last := &outputStep{stepType: "script"}
+	r := &taskRunner{
+		cleanupVerify:    []steps.StepRunner{leakStep{message: "namespace test-abc still exists"}, panicStep{}, last},
+		cleanupVerifyIDs: []string{"ns_gone", "cleanupVerify_1", "cleanupVerify_2"},
+		stepIDs:          map[string]struct{}{"create_ns": {}, "ns_gone": {}, "cleanupVerify_1": {}, "cleanupVerify_2": {}},
+		setupOutputs:     map[string]map[string]string{"create_ns": {"namespace": "test-abc"}},
+	}
+
+	out, err := r.VerifyCleanup(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cleanupVerify[1] failed: panic: index out of range")
+	assert.False(t, out.Success)
+
+	// A failing step doesn't stop the others from running
+	require.Len(t, out.Steps, 3)
+	assert.Equal(t, "ns_gone", out.Steps[0].ID)
+	assert.Equal(t, "namespace test-abc still exists", out.Steps[0].Message)
+	assert.Equal(t, "cleanupVerify_1", out.Steps[1].ID)
+	assert.Equal(t, "panic: index out of range", out.Steps[1].Error)
+	assert.True(t, out.Steps[2].Success)
+	assert.Equal(t, "test-abc", last.seen["create_ns"]["namespace"])
+}
+
+func TestSetupKeysOutputsByStepID(t *testing.T) {
+	first := &outputStep{stepType: "script", outputs: map[string]string{"name": "first"}}
+	second := &outputStep{stepType: "script", outputs: map[string]string{"name": "second"}}
+	third := &outputStep{stepType: "http"}
+
+	r := &taskRunner{
+		setup:    []steps.StepRunner{first, second, third},
+		setupIDs: []string{"create_ns", "setup_1", "setup_2"},
+		stepIDs:  map[string]struct{}{"create_ns": {}, "setup_1": {}, "setup_2": {}},
+	}
+
+	out, err := r.Setup(context.Background())
+	require.NoError(t, err)
+	assert.True(t, out.Success)
+
+	// Both steps of the same type keep their outputs under their IDs
+	assert.Equal(t, "first", r.setupOutputs["create_ns"]["name"])
+	assert.Equal(t, "second", r.setupOutputs["setup_1"]["name"])
+	// Type-based lookup resolves to the most recent step of that type
+	assert.Equal(t, "second", r.setupOutputs["script"]["name"])
+	// Later steps see the outputs of earlier steps
+	assert.Equal(t, "first", second.seen["create_ns"]["name"])
+	// Steps without outputs are not recorded
+	assert.NotContains(t, r.setupOutputs, "setup_2")
+
+	assert.Equal(t, "first and second", r.resolvePromptTemplates("{steps.create_ns.name} and {steps.script.name}"))
+}
+
+func TestRestoreSetup(t *testing.T) {
+	cleanup := &outputStep{stepType: "script"}
+	r := &taskRunner{
+		cleanup:    []steps.StepRunner{cleanup},
+		cleanupIDs: []string{"cleanup_0"},
+		setupIDs:   []string{"create_ns", "setup_1", "setup_2"},
+		stepIDs:    map[string]struct{}{"create_ns": {}, "setup_1": {}, "setup_2": {}, "cleanup_0": {}},
+	}
+
+	r.RestoreSetup(&PhaseOutput{Steps: []*steps.StepOutput{
+		{ID: "create_ns", Type: "script", Success: true, Outputs: map[string]string{"namespace": "test-abc"}},
+		// Results written before steps recorded their IDs
+		{Type: "http", Success: true, Outputs: map[string]string{"token": "abc"}},
+		// Steps that errored have no output
+		nil,
+	}})
+
+	out, err := r.Cleanup(context.Background())
+	require.NoError(t, err)
+	assert.True(t, out.Success)
+	assert.Equal(t, "test-abc", cleanup.seen["create_ns"]["namespace"])
+	assert.Equal(t, "test-abc", cleanup.seen["script"]["namespace"])
+	assert.Equal(t, "abc", cleanup.seen["setup_1"]["token"])
+}
+
+func TestRecordStepOutputsTypeDoesNotShadowID(t *testing.T) {
+	r := &taskRunner{stepIDs: map[string]struct{}{"script": {}, "other": {}}}
+	outputs := map[string]map[string]string{}
+
+	r.recordStepOutputs(outputs, "script", &steps.StepOutput{Type: "http", Success: true, Outputs: map[string]string{"v": "by-id"}})
+	r.recordStepOutputs(outputs, "other", &steps.StepOutput{Type: "script", Success: true, Outputs: map[string]string{"v": "by-type"}})
+
+	assert.Equal(t, "by-id", outputs["script"]["v"])
+	assert.Equal(t, "by-type", outputs["other"]["v"])
+	assert.Equal(t, "by-id", outputs["http"]["v"])
+}
+
+func TestAddStepIDRejectsDuplicates(t *testing.T) {
+	r := &taskRunner{stepIDs: make(map[string]struct{})}
+	require.NoError(t, r.addStepID("create_ns"))
+	require.NoError(t, r.addStepID("setup_1"))
+
+	err := r.addStepID("create_ns")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `duplicate step id "create_ns"`)
+}
+
+func TestVerifySeesSetupAndEarlierVerifyOutputs(t *testing.T) {
+	compute := &outputStep{stepType: "script", outputs: map[string]string{"expected": "3 pods"}}
+	judge := &outputStep{stepType: "llmJudge"}
+
+	r := &taskRunner{
+		verify:       []steps.StepRunner{compute, judge},
+		verifyIDs:    []string{"count_pods", "verify_1"},
+		stepIDs:      map[string]struct{}{"create_ns": {}, "count_pods": {}, "verify_1": {}},
+		setupOutputs: map[string]map[string]string{"create_ns": {"namespace": "test-abc"}},
+	}
+
+	out, err := r.Verify(context.Background())
+	require.NoError(t, err)
+	require.Len(t, out.Steps, 2)
+
+	assert.Equal(t, "test-abc", compute.seen["create_ns"]["namespace"])
+	assert.Equal(t, "test-abc", judge.seen["create_ns"]["namespace"])
+	assert.Equal(t, "3 pods", judge.seen["count_pods"]["expected"])
+
+	// Step IDs are recorded on the step outputs, and setup outputs are left untouched
+	assert.Equal(t, "count_pods", out.Steps[0].ID)
+	assert.Equal(t, "verify_1", out.Steps[1].ID)
+	assert.NotContains(t, r.setupOutputs, "count_pods")
+}
+
+// echoAgentResult is an agent.AgentResult whose final message is the prompt
+type echoAgentResult struct {
+	prompt string
+}
+
+func (r *echoAgentResult) GetOutput() []agent.OutputStep {
+	return []agent.OutputStep{{Type: "message", Content: r.prompt}}
+}
+func (r *echoAgentResult) GetToolCalls() []agent.ToolCallSummary { return nil }
+func (r *echoAgentResult) GetRawUpdates() any                    { return nil }
+func (r *echoAgentResult) GetTokenEstimate() tokens.Estimate     { return tokens.Estimate{} }
+
+// echoAgent is an agent.Runner that records the prompts it receives
+type echoAgent struct {
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (a *echoAgent) RunTask(_ context.Context, prompt string) (agent.AgentResult, error) {
+	a.mu.Lock()
+	a.prompts = append(a.prompts, prompt)
+	a.mu.Unlock()
+	return &echoAgentResult{prompt: prompt}, nil
+}
+func (a *echoAgent) WithMcpServerInfo(mcpproxy.ServerManager) agent.Runner { return a }
+func (a *echoAgent) WithSkillInfo(*agent.SkillInfo) agent.Runner           { return a }
+func (a *echoAgent) WithActivity(*util.Activity) agent.Runner              { return a }
+func (a *echoAgent) AgentName() string                                     { return "echo" }
+
+func TestRunAgentRepeatable(t *testing.T) {
+	const prompt = "Create a VM in {steps.create_ns.namespace}"
+	const resolved = "Create a VM in vm-test-abc123"
+
+	judge := &agentContextStep{}
+	r := &taskRunner{
+		prompt:       prompt,
+		verify:       []steps.StepRunner{judge},
+		verifyIDs:    []string{"verify_0"},
+		stepIDs:      map[string]struct{}{"verify_0": {}},
+		setupOutputs: map[string]map[string]string{"create_ns": {"namespace": "vm-test-abc123"}},
+	}
+	runner := &echoAgent{}
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out, err := r.RunAgent(context.Background(), runner)
+			if assert.NoError(t, err) {
+				assert.True(t, out.Success)
+			}
+		}()
+	}
+	wg.Wait()
+
+	out, err := r.RunAgent(context.Background(), runner)
+	require.NoError(t, err)
+	require.NotNil(t, out.AgentDetails)
+	assert.Equal(t, resolved, out.AgentDetails.Prompt, "the resolved prompt is recorded")
+
+	// Every run receives the resolved prompt, and the template itself is kept
+	require.Len(t, runner.prompts, 5)
+	for _, p := range runner.prompts {
+		assert.Equal(t, resolved, p)
+	}
+	assert.Equal(t, prompt, r.prompt)
+
+	_, err = r.Verify(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, judge.agent)
+	assert.Equal(t, resolved, judge.agent.Prompt)
+	assert.Equal(t, resolved, judge.agent.Output)
+}
+
+// agentContextStep is a steps.StepRunner that records the agent context it was given
+type agentContextStep struct {
+	agent *steps.AgentContext
+}
+
+func (s *agentContextStep) Execute(_ context.Context, input *steps.StepInput) (*steps.StepOutput, error) {
+	s.agent = input.Agent
+	return &steps.StepOutput{Type: "llmJudge", Success: true}, nil
+}
+
+func TestDebugArtifacts(t *testing.T) {
+	root := t.TempDir()
+	debug, err := util.NewDebugDir(root)
+	require.NoError(t, err)
+	ctx := util.WithDebugDir(context.Background(), debug)
+
+	step := &outputStep{stepType: "script", outputs: map[string]string{"namespace": "test-abc"}}
+	r := &taskRunner{
+		prompt:   "Create a VM in {steps.create_ns.namespace}",
+		setup:    []steps.StepRunner{step},
+		setupIDs: []string{"create_ns"},
+		stepIDs:  map[string]struct{}{"create_ns": {}},
+	}
+
+	_, err = r.Setup(ctx)
+	require.NoError(t, err)
+	_, err = r.RunAgent(ctx, &echoAgent{})
+	require.NoError(t, err)
+
+	assert.FileExists(t, filepath.Join(root, "setup", "create_ns", "input.json"))
+	output, err := os.ReadFile(filepath.Join(root, "setup", "create_ns", "output.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(output), `"namespace": "test-abc"`)
+
+	prompt, err := os.ReadFile(filepath.Join(root, "agent", "prompt.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "Create a VM in test-abc", string(prompt))
+	assert.FileExists(t, filepath.Join(root, "agent", "prompt-template.txt"))
+}
+
+// failingStep is a steps.StepRunner that always returns an error
+type failingStep struct{}
+
+func (failingStep) Execute(context.Context, *steps.StepInput) (*steps.StepOutput, error) {
+	return nil, errors.New("exit status 1")
+}
+
+func TestPhaseErrorKinds(t *testing.T) {
+	r := &taskRunner{
+		setup:     []steps.StepRunner{failingStep{}},
+		setupIDs:  []string{"setup_0"},
+		verify:    []steps.StepRunner{failingStep{}},
+		verifyIDs: []string{"verify_0"},
+		stepIDs:   map[string]struct{}{"setup_0": {}, "verify_0": {}},
+	}
+
+	_, err := r.Setup(context.Background())
+	assert.Equal(t, ErrorKindSetup, ErrorKindOf(err))
+
+	_, err = r.Verify(context.Background())
+	assert.Equal(t, ErrorKindVerify, ErrorKindOf(err))
+}
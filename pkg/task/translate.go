@@ -9,8 +9,9 @@ import (
 
 func translateV1Alpha1ToSteps(legacy *TaskStepsV1Alpha1) (*TaskSpec, error) {
 	var err error
-	spec := &TaskSpec{
-		Prompt: legacy.Prompt,
+	spec := &TaskSpec{}
+	if legacy.Prompt != nil {
+		spec.Prompt = &Prompt{Step: *legacy.Prompt}
 	}
 
 	spec.Setup, err = translateLegacyStep(legacy.SetupScript)
@@ -23,6 +23,8 @@ type Usage struct {
 	ThoughtTokens     *int64 `json:"thoughtTokens,omitempty"`
 	CachedReadTokens  *int64 `json:"cachedReadTokens,omitempty"`
 	CachedWriteTokens *int64 `json:"cachedWriteTokens,omitempty"`
+	// Retries is the number of model requests retried after transient provider errors
+	Retries int64 `json:"retries,omitempty"`
 }
 
 // Estimate provides token count estimates for different components.
@@ -101,6 +103,7 @@ func (u *Usage) Add(other *Usage) {
 	u.InputTokens += other.InputTokens
 	u.OutputTokens += other.OutputTokens
 	u.TotalTokens += other.TotalTokens
+	u.Retries += other.Retries
 
 	// take the only non nil field if one is set, else accumulate
 	if u.ThoughtTokens == nil && other.ThoughtTokens != nil {
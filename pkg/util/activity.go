@@ -0,0 +1,100 @@
+package util
+
+import (
+	"io"
+	"os/exec"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Activity records when the agent of a task run last made progress, such as
+// writing output or sending an ACP update, and the processes it runs in, so
+// that a run whose agent stalls can be stopped and diagnosed. A nil Activity
+// ignores everything, so callers don't need to check whether stalls are
+// watched.
+type Activity struct {
+	mu        sync.Mutex
+	last      time.Time
+	processes []int
+}
+
+// NewActivity returns an Activity whose last progress is now.
+func NewActivity() *Activity {
+	return &Activity{last: time.Now()}
+}
+
+// Touch records progress.
+func (a *Activity) Touch() {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	a.last = time.Now()
+	a.mu.Unlock()
+}
+
+// Idle returns how long ago progress was last recorded.
+func (a *Activity) Idle() time.Duration {
+	if a == nil {
+		return 0
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return time.Since(a.last)
+}
+
+// TrackProcess adds the process pid to the processes that are diagnosed when
+// the agent stalls, until the returned function is called.
+func (a *Activity) TrackProcess(pid int) (untrack func()) {
+	if a == nil {
+		return func() {}
+	}
+	a.mu.Lock()
+	a.processes = append(a.processes, pid)
+	a.mu.Unlock()
+
+	return func() {
+		a.mu.Lock()
+		a.processes = slices.DeleteFunc(a.processes, func(p int) bool { return p == pid })
+		a.mu.Unlock()
+	}
+}
+
+// Processes returns the processes being tracked.
+func (a *Activity) Processes() []int {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Clone(a.processes)
+}
+
+// Writer returns a writer that writes to w and records progress on every
+// write.
+func (a *Activity) Writer(w io.Writer) io.Writer {
+	if a == nil {
+		return w
+	}
+	return &activityWriter{w: w, activity: a}
+}
+
+type activityWriter struct {
+	w        io.Writer
+	activity *Activity
+}
+
+func (w *activityWriter) Write(p []byte) (int, error) {
+	w.activity.Touch()
+	return w.w.Write(p)
+}
+
+// Run runs cmd like cmd.Run, tracking its process while it runs.
+func (a *Activity) Run(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	defer a.TrackProcess(cmd.Process.Pid)()
+	return cmd.Wait()
+}
@@ -0,0 +1,67 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivity(t *testing.T) {
+	a := NewActivity()
+	a.last = time.Now().Add(-time.Minute)
+	assert.GreaterOrEqual(t, a.Idle(), time.Minute)
+
+	var out bytes.Buffer
+	_, err := a.Writer(&out).Write([]byte("progress"))
+	require.NoError(t, err)
+	assert.Equal(t, "progress", out.String())
+	assert.Less(t, a.Idle(), time.Minute, "expected writes to count as progress")
+
+	untrack := a.TrackProcess(42)
+	a.TrackProcess(43)
+	assert.Equal(t, []int{42, 43}, a.Processes())
+	untrack()
+	assert.Equal(t, []int{43}, a.Processes())
+}
+
+func TestNilActivity(t *testing.T) {
+	var a *Activity
+	a.Touch()
+	a.TrackProcess(1)()
+	assert.Zero(t, a.Idle())
+	assert.Nil(t, a.Processes())
+
+	var out bytes.Buffer
+	assert.Same(t, &out, a.Writer(&out))
+}
+
+func TestProcessDiagnostics(t *testing.T) {
+	assert.Equal(t, "no agent processes were running\n", ProcessDiagnostics(context.Background(), nil))
+
+	if runtime.GOOS != "linux" {
+		t.Skip("process diagnostics are only captured on Linux")
+	}
+	cmd := exec.Command("/bin/sh", "-c", "sleep 30 & wait")
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+	t.Setenv("PATH", "") // keep strace out of the test
+
+	// Wait for the shell to start sleep
+	var diagnostics string
+	require.Eventually(t, func() bool {
+		diagnostics = ProcessDiagnostics(context.Background(), []int{cmd.Process.Pid})
+		return bytes.Contains([]byte(diagnostics), []byte("sleep 30\n"))
+	}, 5*time.Second, 50*time.Millisecond)
+
+	assert.Contains(t, diagnostics, "/bin/sh -c sleep 30 & wait")
+	assert.Contains(t, diagnostics, "State:")
+}
@@ -0,0 +1,85 @@
+package util
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ClockConfig makes a task run at a fixed point in time, so that tasks which
+// depend on time windows, such as expiry or schedules, are reproducible.
+type ClockConfig struct {
+	// Start is the time at the start of the task run, in RFC 3339 format.
+	// The clock then advances in real time.
+	Start string `json:"start"`
+}
+
+// GetStart parses the Start field. It returns an error if Start is empty or
+// cannot be parsed.
+func (c *ClockConfig) GetStart() (time.Time, error) {
+	if c == nil || c.Start == "" {
+		return time.Time{}, fmt.Errorf("clock.start must be set")
+	}
+
+	start, err := time.Parse(time.RFC3339, c.Start)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid clock.start %q: %w", c.Start, err)
+	}
+	return start, nil
+}
+
+// NewClock returns the clock of c, starting now. It returns nil, the real
+// time, if c is nil.
+func (c *ClockConfig) NewClock() (*Clock, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	start, err := c.GetStart()
+	if err != nil {
+		return nil, err
+	}
+	return NewClock(start), nil
+}
+
+// Clock is the time seen by the steps of a task run and by the MCP servers
+// it calls. A nil Clock is the real time.
+type Clock struct {
+	offset time.Duration
+}
+
+// NewClock returns a clock that reads start now and advances in real time.
+func NewClock(start time.Time) *Clock {
+	return &Clock{offset: time.Until(start)}
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	return time.Now().Add(c.offset)
+}
+
+// Env returns the environment variables that pass the clock on to
+// subprocesses: FAKETIME, the offset from the real time in seconds in the
+// format of libfaketime, and MCPCHECKER_NOW, the current time of the clock in
+// RFC 3339 format. It returns nil for the real time.
+func (c *Clock) Env() map[string]string {
+	if c == nil {
+		return nil
+	}
+	return map[string]string{
+		"FAKETIME":       fmt.Sprintf("%+d", int64(c.offset/time.Second)),
+		"MCPCHECKER_NOW": c.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// SetDateHeader sets the Date header of req to the current time of the clock,
+// unless the header is already set or the clock is the real time.
+func (c *Clock) SetDateHeader(req *http.Request) {
+	if c == nil || req.Header.Get("Date") != "" {
+		return
+	}
+	req.Header.Set("Date", c.Now().UTC().Format(http.TimeFormat))
+}
@@ -0,0 +1,96 @@
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockConfig_NewClock(t *testing.T) {
+	tests := map[string]struct {
+		config    *ClockConfig
+		expected  time.Time
+		expectNil bool
+		hasErr    bool
+	}{
+		"nil config": {
+			config:    nil,
+			expectNil: true,
+		},
+		"UTC": {
+			config:   &ClockConfig{Start: "2025-01-31T23:59:00Z"},
+			expected: time.Date(2025, 1, 31, 23, 59, 0, 0, time.UTC),
+		},
+		"offset": {
+			config:   &ClockConfig{Start: "2025-01-31T23:59:00+02:00"},
+			expected: time.Date(2025, 1, 31, 21, 59, 0, 0, time.UTC),
+		},
+		"empty start": {
+			config: &ClockConfig{},
+			hasErr: true,
+		},
+		"date only": {
+			config: &ClockConfig{Start: "2025-01-31"},
+			hasErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			clock, err := tc.config.NewClock()
+			if tc.hasErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tc.expectNil {
+				assert.Nil(t, clock)
+				return
+			}
+			assert.WithinDuration(t, tc.expected, clock.Now(), time.Second)
+		})
+	}
+}
+
+func TestClock(t *testing.T) {
+	start := time.Now().Add(-48 * time.Hour)
+	clock := NewClock(start)
+
+	assert.WithinDuration(t, start, clock.Now(), time.Second)
+	env := clock.Env()
+	assert.Contains(t, []string{"-172800", "-172799"}, env["FAKETIME"])
+	now, err := time.Parse(time.RFC3339, env["MCPCHECKER_NOW"])
+	require.NoError(t, err)
+	assert.WithinDuration(t, start, now, time.Second)
+
+	var real *Clock
+	assert.WithinDuration(t, time.Now(), real.Now(), time.Second)
+	assert.Nil(t, real.Env())
+}
+
+func TestClockSetDateHeader(t *testing.T) {
+	start := time.Date(2025, 1, 31, 23, 59, 0, 0, time.UTC)
+	clock := NewClock(start)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	clock.SetDateHeader(req)
+	date, err := http.ParseTime(req.Header.Get("Date"))
+	require.NoError(t, err)
+	assert.WithinDuration(t, start, date, 2*time.Second)
+
+	// A Date header set by the request is kept
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Date", "Mon, 02 Jan 2006 15:04:05 GMT")
+	clock.SetDateHeader(req)
+	assert.Equal(t, "Mon, 02 Jan 2006 15:04:05 GMT", req.Header.Get("Date"))
+
+	// Requests of tasks without a clock get no Date header
+	var real *Clock
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	real.SetDateHeader(req)
+	assert.Empty(t, req.Header.Get("Date"))
+}
@@ -2,11 +2,16 @@ package util
 
 import (
 	"context"
+	"io"
+	"os"
 )
 
 type contextKey string
 
-const verboseKey contextKey = "verbose"
+const (
+	verboseKey contextKey = "verbose"
+	outputKey  contextKey = "output"
+)
 
 // WithVerbose adds the verbose flag to the context
 func WithVerbose(ctx context.Context, verbose bool) context.Context {
@@ -22,3 +27,19 @@ func IsVerbose(ctx context.Context) bool {
 	return ok && v
 }
 
+// WithOutput adds the writer for the verbose output of a task run to the
+// context
+func WithOutput(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, outputKey, w)
+}
+
+// OutputFromContext returns the writer for verbose output of the context, or
+// os.Stdout if it has none.
+func OutputFromContext(ctx context.Context) io.Writer {
+	if ctx != nil {
+		if w, ok := ctx.Value(outputKey).(io.Writer); ok {
+			return w
+		}
+	}
+	return os.Stdout
+}
@@ -0,0 +1,145 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DebugEnv is the environment variable that enables debug mode
+const DebugEnv = "MCPCHECKER_DEBUG"
+
+const debugDirKey contextKey = "debugDir"
+
+// DebugEnabled returns true if MCPCHECKER_DEBUG is set
+func DebugEnabled() bool {
+	return os.Getenv(DebugEnv) != ""
+}
+
+// DebugDir is a directory that debug artifacts are written to. A nil DebugDir
+// discards everything, so callers don't need to check whether debug mode is on.
+// Write errors are reported as warnings, since debug artifacts are best effort.
+type DebugDir struct {
+	path string
+}
+
+// NewDebugDir returns the debug directory at path, creating it if needed.
+func NewDebugDir(path string) (*DebugDir, error) {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create debug directory: %w", err)
+	}
+	return &DebugDir{path: path}, nil
+}
+
+// NewDebugRoot creates a new temporary directory for the debug artifacts of the
+// run with ID runID, which its name starts with.
+func NewDebugRoot(runID string) (*DebugDir, error) {
+	path, err := os.MkdirTemp("", "mcpchecker-debug-"+runID+"-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create debug directory: %w", err)
+	}
+	return &DebugDir{path: path}, nil
+}
+
+// Path returns the path of the directory, or an empty string for a nil DebugDir.
+func (d *DebugDir) Path() string {
+	if d == nil {
+		return ""
+	}
+	return d.path
+}
+
+// Sub returns the subdirectory name of d, creating it if needed.
+func (d *DebugDir) Sub(name string) *DebugDir {
+	if d == nil {
+		return nil
+	}
+	path := filepath.Join(d.path, debugFileName(name))
+	if err := os.MkdirAll(path, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to create debug directory: %v\n", err)
+		return nil
+	}
+	return &DebugDir{path: path}
+}
+
+// NewSub creates a new subdirectory of d named after name. If name is already
+// taken, a numeric suffix is added, so concurrent callers get distinct directories.
+func (d *DebugDir) NewSub(name string) *DebugDir {
+	if d == nil {
+		return nil
+	}
+	base := debugFileName(name)
+	for i := 1; ; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, i)
+		}
+		path := filepath.Join(d.path, candidate)
+		err := os.Mkdir(path, 0755)
+		if err == nil {
+			return &DebugDir{path: path}
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			fmt.Fprintf(os.Stderr, "Warning: failed to create debug directory: %v\n", err)
+			return nil
+		}
+	}
+}
+
+// WriteFile writes data to the file name in d, with secrets redacted.
+func (d *DebugDir) WriteFile(name string, data []byte) {
+	if d == nil {
+		return
+	}
+	if err := os.WriteFile(filepath.Join(d.path, name), RedactSecretBytes(data), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write debug file: %v\n", err)
+	}
+}
+
+// WriteJSON writes v as indented JSON to the file name in d.
+func (d *DebugDir) WriteJSON(name string, v any) {
+	if d == nil {
+		return
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to encode debug file %s: %v\n", name, err)
+		return
+	}
+	d.WriteFile(name, append(data, '\n'))
+}
+
+// debugFileName replaces the characters of name that are awkward in file names.
+func debugFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|', ' ':
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == ".." {
+		return "_"
+	}
+	return name
+}
+
+// WithDebugDir adds the debug directory to the context
+func WithDebugDir(ctx context.Context, dir *DebugDir) context.Context {
+	return context.WithValue(ctx, debugDirKey, dir)
+}
+
+// DebugDirFromContext returns the debug directory of the context, or nil if
+// debug mode is off.
+func DebugDirFromContext(ctx context.Context) *DebugDir {
+	if ctx == nil {
+		return nil
+	}
+	dir, _ := ctx.Value(debugDirKey).(*DebugDir)
+	return dir
+}
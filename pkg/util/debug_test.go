@@ -0,0 +1,50 @@
+package util
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugDir(t *testing.T) {
+	root, err := NewDebugDir(t.TempDir())
+	require.NoError(t, err)
+
+	// Names are made safe to use as file names
+	task := root.Sub("k8s/create pod")
+	require.NotNil(t, task)
+	assert.Equal(t, filepath.Join(root.Path(), "k8s_create_pod"), task.Path())
+
+	task.WriteJSON("result.json", map[string]string{"taskName": "create-pod"})
+	data, err := os.ReadFile(filepath.Join(task.Path(), "result.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"taskName": "create-pod"}`, string(data))
+
+	// NewSub never reuses a directory
+	first := root.NewSub("create-pod-run0")
+	second := root.NewSub("create-pod-run0")
+	require.NotNil(t, first)
+	require.NotNil(t, second)
+	assert.Equal(t, filepath.Join(root.Path(), "create-pod-run0"), first.Path())
+	assert.Equal(t, filepath.Join(root.Path(), "create-pod-run0-2"), second.Path())
+}
+
+func TestNilDebugDir(t *testing.T) {
+	var d *DebugDir
+	assert.Empty(t, d.Path())
+	assert.Nil(t, d.Sub("agent"))
+	assert.Nil(t, d.NewSub("agent"))
+	d.WriteFile("output.log", []byte("discarded"))
+	d.WriteJSON("result.json", struct{}{})
+}
+
+func TestDebugDirContext(t *testing.T) {
+	assert.Nil(t, DebugDirFromContext(context.Background()))
+
+	d := &DebugDir{path: t.TempDir()}
+	assert.Same(t, d, DebugDirFromContext(WithDebugDir(context.Background(), d)))
+}
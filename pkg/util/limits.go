@@ -9,6 +9,10 @@ import (
 type Limits struct {
 	Timeout        string `json:"timeout,omitempty"`
 	CleanupTimeout string `json:"cleanupTimeout,omitempty"`
+
+	// StallTimeout stops the agent once it has made no progress, such as
+	// writing output or sending an ACP update, for this long
+	StallTimeout string `json:"stallTimeout,omitempty"`
 }
 
 // GetTimeout parses the Timeout field as a time.Duration.
@@ -50,3 +54,23 @@ func (l *Limits) GetCleanupTimeout() (time.Duration, bool, error) {
 
 	return d, true, nil
 }
+
+// GetStallTimeout parses the StallTimeout field as a time.Duration.
+// Returns (duration, true, nil) if set and valid, or (0, false, nil) if not set.
+// Returns an error if the string is set but cannot be parsed.
+func (l *Limits) GetStallTimeout() (time.Duration, bool, error) {
+	if l == nil || l.StallTimeout == "" {
+		return 0, false, nil
+	}
+
+	d, err := time.ParseDuration(l.StallTimeout)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid stallTimeout %q: %w", l.StallTimeout, err)
+	}
+
+	if d <= 0 {
+		return 0, false, fmt.Errorf("invalid stallTimeout: duration must be > 0, got %q", l.StallTimeout)
+	}
+
+	return d, true, nil
+}
@@ -130,3 +130,46 @@ func TestLimits_GetCleanupTimeout(t *testing.T) {
 		})
 	}
 }
+
+func TestLimits_GetStallTimeout(t *testing.T) {
+	tests := map[string]struct {
+		limits   *Limits
+		expected time.Duration
+		isSet    bool
+		hasErr   bool
+	}{
+		"nil Limits": {
+			limits: nil,
+		},
+		"empty stallTimeout": {
+			limits: &Limits{Timeout: "10m"},
+		},
+		"valid minutes": {
+			limits:   &Limits{StallTimeout: "5m"},
+			expected: 5 * time.Minute,
+			isSet:    true,
+		},
+		"invalid duration": {
+			limits: &Limits{StallTimeout: "abc"},
+			hasErr: true,
+		},
+		"zero": {
+			limits: &Limits{StallTimeout: "0s"},
+			hasErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, ok, err := tc.limits.GetStallTimeout()
+			if tc.hasErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "invalid stallTimeout")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, d)
+			assert.Equal(t, tc.isSet, ok)
+		})
+	}
+}
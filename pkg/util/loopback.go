@@ -0,0 +1,18 @@
+package util
+
+import (
+	"net"
+	"strings"
+)
+
+// IsLoopbackHost reports whether host, a host name or IP address without a
+// port, only accepts connections from the local machine. An empty host binds
+// to all interfaces.
+func IsLoopbackHost(host string) bool {
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
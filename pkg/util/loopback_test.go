@@ -0,0 +1,30 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsLoopbackHost(t *testing.T) {
+	tests := map[string]struct {
+		host     string
+		expected bool
+	}{
+		"localhost":           {host: "localhost", expected: true},
+		"ipv4 loopback":       {host: "127.0.0.1", expected: true},
+		"ipv6 loopback":       {host: "::1", expected: true},
+		"bracketed ipv6":      {host: "[::1]", expected: true},
+		"all interfaces":      {host: "0.0.0.0"},
+		"empty":               {host: ""},
+		"private address":     {host: "10.0.0.5"},
+		"host name":           {host: "eval.example.com"},
+		"localhost lookalike": {host: "localhost.example.com"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsLoopbackHost(tc.host))
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package util
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// straceDuration is how long the system calls of a stalled process are traced
+	straceDuration = 3 * time.Second
+
+	// maxStraceOutput is the most output of strace kept in the diagnostics
+	maxStraceOutput = 64 * 1024
+)
+
+// ProcessDiagnostics describes the processes pids and their descendants, to
+// show what a stalled agent is waiting for. Where the platform allows it, this
+// includes the state, wait channel and kernel stack of each process, and a few
+// seconds of strace output when strace is installed. It is best effort:
+// whatever cannot be read is left out.
+func ProcessDiagnostics(ctx context.Context, pids []int) string {
+	return processDiagnostics(ctx, pids)
+}
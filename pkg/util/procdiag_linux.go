@@ -0,0 +1,140 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func processDiagnostics(ctx context.Context, pids []int) string {
+	if len(pids) == 0 {
+		return "no agent processes were running\n"
+	}
+
+	var sb strings.Builder
+	for _, pid := range processTree(pids) {
+		describeProcess(&sb, pid)
+	}
+
+	for _, pid := range pids {
+		sb.WriteString(traceProcess(ctx, pid))
+	}
+	return sb.String()
+}
+
+// processTree returns pids and their descendants, parents first.
+func processTree(pids []int) []int {
+	children := make(map[int][]int)
+	entries, _ := os.ReadDir("/proc")
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		if ppid, ok := parentPID(pid); ok {
+			children[ppid] = append(children[ppid], pid)
+		}
+	}
+
+	var tree []int
+	queue := slices.Clone(pids)
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+		if slices.Contains(tree, pid) {
+			continue
+		}
+		tree = append(tree, pid)
+		queue = append(queue, children[pid]...)
+	}
+	return tree
+}
+
+// parentPID returns the parent of pid from /proc/<pid>/stat.
+func parentPID(pid int) (int, bool) {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, false
+	}
+	// The command name in parentheses may contain spaces, the fields after it don't
+	i := bytes.LastIndexByte(stat, ')')
+	if i < 0 {
+		return 0, false
+	}
+	fields := strings.Fields(string(stat[i+1:]))
+	if len(fields) < 2 {
+		return 0, false
+	}
+	ppid, err := strconv.Atoi(fields[1])
+	return ppid, err == nil
+}
+
+func describeProcess(sb *strings.Builder, pid int) {
+	dir := fmt.Sprintf("/proc/%d", pid)
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(data))
+	}
+
+	cmdline := strings.Join(strings.Split(strings.TrimRight(read("cmdline"), "\x00"), "\x00"), " ")
+	if cmdline == "" {
+		cmdline = "[" + read("comm") + "]"
+	}
+	fmt.Fprintf(sb, "== process %d: %s\n", pid, cmdline)
+
+	for _, line := range strings.Split(read("status"), "\n") {
+		for _, field := range []string{"State:", "PPid:", "Threads:", "VmRSS:"} {
+			if strings.HasPrefix(line, field) {
+				fmt.Fprintf(sb, "%s\n", strings.Join(strings.Fields(line), " "))
+			}
+		}
+	}
+	if wchan := read("wchan"); wchan != "" && wchan != "0" {
+		fmt.Fprintf(sb, "Waiting in: %s\n", wchan)
+	}
+	if syscall := read("syscall"); syscall != "" {
+		fmt.Fprintf(sb, "System call: %s\n", syscall)
+	}
+	if stack := read("stack"); stack != "" {
+		fmt.Fprintf(sb, "Kernel stack:\n%s\n", stack)
+	}
+	sb.WriteString("\n")
+}
+
+// traceProcess returns a few seconds of the system calls of pid and its
+// threads, or nothing if strace is not installed.
+func traceProcess(ctx context.Context, pid int) string {
+	path, err := exec.LookPath("strace")
+	if err != nil {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, straceDuration)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, "-f", "-tt", "-p", strconv.Itoa(pid))
+	cmd.Stdout, cmd.Stderr = &out, &out
+	// strace detaches from the process when interrupted
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = straceDuration
+	err = cmd.Run()
+
+	trace := out.Bytes()
+	if len(trace) > maxStraceOutput {
+		trace = append(trace[:maxStraceOutput:maxStraceOutput], "\n[truncated]\n"...)
+	}
+	if ctx.Err() == nil && err != nil {
+		return fmt.Sprintf("== strace of process %d failed: %v\n%s\n", pid, err, trace)
+	}
+	return fmt.Sprintf("== strace of process %d for %s\n%s\n", pid, straceDuration, trace)
+}
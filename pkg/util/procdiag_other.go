@@ -0,0 +1,16 @@
+//go:build !linux
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+func processDiagnostics(_ context.Context, pids []int) string {
+	if len(pids) == 0 {
+		return "no agent processes were running\n"
+	}
+	return fmt.Sprintf("agent processes: %v\nprocess diagnostics are not captured on %s\n", pids, runtime.GOOS)
+}
@@ -0,0 +1,253 @@
+package util
+
+import (
+	"cmp"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// RedactedSecret replaces secret values in outputs
+const RedactedSecret = "***"
+
+// minSecretLen is the length below which values are not redacted, since
+// replacing short strings everywhere would mangle unrelated output.
+const minSecretLen = 4
+
+var secretValues struct {
+	mu       sync.RWMutex
+	values   map[string]struct{}
+	replacer *strings.Replacer
+}
+
+// RegisterSecret adds value to the secrets that are redacted from outputs.
+// Values shorter than 4 characters are ignored.
+func RegisterSecret(value string) {
+	if len(value) < minSecretLen {
+		return
+	}
+
+	secretValues.mu.Lock()
+	defer secretValues.mu.Unlock()
+
+	if _, ok := secretValues.values[value]; ok {
+		return
+	}
+	if secretValues.values == nil {
+		secretValues.values = make(map[string]struct{})
+	}
+	secretValues.values[value] = struct{}{}
+
+	// Secrets also appear JSON-escaped, e.g. in recorded tool arguments
+	var olds []string
+	for v := range secretValues.values {
+		olds = append(olds, v)
+		if escaped, err := json.Marshal(v); err == nil {
+			if e := string(escaped[1 : len(escaped)-1]); e != v {
+				olds = append(olds, e)
+			}
+		}
+	}
+
+	// The replacer tries its pairs in order, so longer secrets go first to be
+	// redacted whole when a shorter secret is a prefix of them
+	slices.SortFunc(olds, func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), strings.Compare(a, b))
+	})
+	oldnew := make([]string, 0, 2*len(olds))
+	for _, old := range olds {
+		oldnew = append(oldnew, old, RedactedSecret)
+	}
+	secretValues.replacer = strings.NewReplacer(oldnew...)
+}
+
+func secretReplacer() *strings.Replacer {
+	secretValues.mu.RLock()
+	defer secretValues.mu.RUnlock()
+	return secretValues.replacer
+}
+
+// RedactSecrets returns s with all registered secrets replaced by "***".
+func RedactSecrets(s string) string {
+	replacer := secretReplacer()
+	if replacer == nil {
+		return s
+	}
+	return replacer.Replace(s)
+}
+
+// RedactSecretBytes returns data with all registered secrets replaced by "***".
+func RedactSecretBytes(data []byte) []byte {
+	replacer := secretReplacer()
+	if replacer == nil {
+		return data
+	}
+	return []byte(replacer.Replace(string(data)))
+}
+
+// RedactSecretsIn replaces the registered secrets in all exported strings and
+// byte slices reachable from v, which should be a pointer, in place.
+func RedactSecretsIn(v any) {
+	if secretReplacer() == nil || v == nil {
+		return
+	}
+	redactValue(reflect.ValueOf(v), make(map[uintptr]struct{}))
+}
+
+// RedactedCopy returns a deep copy of *v with the registered secrets replaced
+// in all exported strings and byte slices, like RedactSecretsIn, leaving v
+// unchanged. Unexported fields are copied as they are. If no secrets are
+// registered, v is returned.
+func RedactedCopy[T any](v *T) *T {
+	if secretReplacer() == nil || v == nil {
+		return v
+	}
+	c := new(T)
+	copyRedacted(reflect.ValueOf(c).Elem(), reflect.ValueOf(v).Elem(), make(map[uintptr]reflect.Value))
+	return c
+}
+
+// copyRedacted sets dst to a redacted deep copy of src. Pointers that are
+// reachable several times from src are copied once.
+func copyRedacted(dst, src reflect.Value, seen map[uintptr]reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		if c, ok := seen[src.Pointer()]; ok {
+			dst.Set(c)
+			return
+		}
+		c := reflect.New(src.Type().Elem())
+		seen[src.Pointer()] = c
+		copyRedacted(c.Elem(), src.Elem(), seen)
+		dst.Set(c)
+
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		c := reflect.New(src.Elem().Type()).Elem()
+		copyRedacted(c, src.Elem(), seen)
+		dst.Set(c)
+
+	case reflect.Struct:
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if f := dst.Field(i); f.CanSet() {
+				copyRedacted(f, src.Field(i), seen)
+			}
+		}
+
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		if src.Type().Elem().Kind() == reflect.Uint8 {
+			dst.SetBytes(RedactSecretBytes(src.Bytes()))
+			return
+		}
+		c := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			copyRedacted(c.Index(i), src.Index(i), seen)
+		}
+		dst.Set(c)
+
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			copyRedacted(dst.Index(i), src.Index(i), seen)
+		}
+
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		c := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			value := reflect.New(iter.Value().Type()).Elem()
+			copyRedacted(value, iter.Value(), seen)
+			c.SetMapIndex(iter.Key(), value)
+		}
+		dst.Set(c)
+
+	case reflect.String:
+		dst.SetString(RedactSecrets(src.String()))
+
+	default:
+		dst.Set(src)
+	}
+}
+
+func redactValue(v reflect.Value, seen map[uintptr]struct{}) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		if _, ok := seen[v.Pointer()]; ok {
+			return
+		}
+		seen[v.Pointer()] = struct{}{}
+		redactValue(v.Elem(), seen)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		// The value of an interface can't be changed in place, so a copy is
+		// redacted and stored back
+		elem := v.Elem()
+		if elem.Kind() == reflect.Pointer {
+			redactValue(elem, seen)
+			return
+		}
+		if !v.CanSet() {
+			return
+		}
+		c := reflect.New(elem.Type()).Elem()
+		c.Set(elem)
+		redactValue(c, seen)
+		v.Set(c)
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Field(i); f.CanSet() {
+				redactValue(f, seen)
+			}
+		}
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if v.CanSet() && !v.IsNil() {
+				v.SetBytes(RedactSecretBytes(v.Bytes()))
+			}
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			redactValue(v.Index(i), seen)
+		}
+
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			redactValue(v.Index(i), seen)
+		}
+
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			c := reflect.New(iter.Value().Type()).Elem()
+			c.Set(iter.Value())
+			redactValue(c, seen)
+			v.SetMapIndex(iter.Key(), c)
+		}
+
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(RedactSecrets(v.String()))
+		}
+	}
+}
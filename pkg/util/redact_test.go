@@ -0,0 +1,126 @@
+package util
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	RegisterSecret(`s3cr"et-value`)
+	RegisterSecret("abc")
+
+	tests := map[string]struct {
+		input    string
+		expected string
+	}{
+		"raw": {
+			input:    `token=s3cr"et-value`,
+			expected: "token=***",
+		},
+		"json escaped": {
+			input:    `{"token":"s3cr\"et-value"}`,
+			expected: `{"token":"***"}`,
+		},
+		"short values are not redacted": {
+			input:    "abc",
+			expected: "abc",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, RedactSecrets(tc.input))
+			assert.Equal(t, tc.expected, string(RedactSecretBytes([]byte(tc.input))))
+		})
+	}
+}
+
+func TestRedactOverlappingSecrets(t *testing.T) {
+	RegisterSecret("tok3n")
+	RegisterSecret("tok3n-ab")
+	RegisterSecret("tok3n-abcd")
+	RegisterSecret("tok3n-abcdef")
+
+	// Each secret is redacted whole, even when a shorter one is its prefix
+	for _, secret := range []string{"tok3n", "tok3n-ab", "tok3n-abcd", "tok3n-abcdef"} {
+		assert.Equal(t, "key=*** end", RedactSecrets("key="+secret+" end"), secret)
+	}
+}
+
+func TestRedactSecretsIn(t *testing.T) {
+	RegisterSecret("hunter2-password")
+
+	type inner struct {
+		Values map[string]string
+		Any    any
+	}
+	type outer struct {
+		Message string
+		Raw     json.RawMessage
+		Items   []*inner
+		Ptr     *inner
+		private string
+	}
+
+	v := &outer{
+		Message: "login with hunter2-password",
+		Raw:     json.RawMessage(`{"password":"hunter2-password"}`),
+		Items: []*inner{{
+			Values: map[string]string{"password": "hunter2-password"},
+			Any:    map[string]any{"nested": []any{"hunter2-password"}},
+		}},
+		private: "hunter2-password",
+	}
+	v.Ptr = v.Items[0]
+
+	RedactSecretsIn(v)
+
+	assert.Equal(t, "login with ***", v.Message)
+	assert.JSONEq(t, `{"password":"***"}`, string(v.Raw))
+	assert.Equal(t, map[string]string{"password": "***"}, v.Items[0].Values)
+	assert.Equal(t, map[string]any{"nested": []any{"***"}}, v.Items[0].Any)
+	assert.Equal(t, "hunter2-password", v.private, "unexported fields are not changed")
+}
+
+func TestRedactedCopy(t *testing.T) {
+	RegisterSecret("hunter2-password")
+
+	type inner struct {
+		Values map[string]string
+		Any    any
+	}
+	type outer struct {
+		Message string
+		Raw     json.RawMessage
+		Items   []*inner
+		Ptr     *inner
+		private string
+	}
+
+	v := &outer{
+		Message: "login with hunter2-password",
+		Raw:     json.RawMessage(`{"password":"hunter2-password"}`),
+		Items: []*inner{{
+			Values: map[string]string{"password": "hunter2-password"},
+			Any:    map[string]any{"nested": []any{"hunter2-password"}},
+		}},
+		private: "hunter2-password",
+	}
+	v.Ptr = v.Items[0]
+
+	c := RedactedCopy(v)
+
+	assert.Equal(t, "login with ***", c.Message)
+	assert.JSONEq(t, `{"password":"***"}`, string(c.Raw))
+	assert.Equal(t, map[string]string{"password": "***"}, c.Items[0].Values)
+	assert.Equal(t, map[string]any{"nested": []any{"***"}}, c.Items[0].Any)
+	assert.Same(t, c.Items[0], c.Ptr, "shared pointers stay shared")
+	assert.Equal(t, "hunter2-password", c.private, "unexported fields are copied")
+
+	assert.Equal(t, "login with hunter2-password", v.Message, "the original is not changed")
+	assert.JSONEq(t, `{"password":"hunter2-password"}`, string(v.Raw))
+	assert.Equal(t, map[string]string{"password": "hunter2-password"}, v.Items[0].Values)
+	assert.Equal(t, map[string]any{"nested": []any{"hunter2-password"}}, v.Items[0].Any)
+}
@@ -0,0 +1,51 @@
+package util
+
+import (
+	"os"
+	"time"
+)
+
+// ResourceUsage is the runtime footprint of an agent subprocess. CPU times include
+// the descendants of the process that it waited for.
+type ResourceUsage struct {
+	WallTimeMs  int64 `json:"wallTimeMs"`
+	UserCPUMs   int64 `json:"userCpuMs"`
+	SystemCPUMs int64 `json:"systemCpuMs"`
+	// MaxRSSBytes is the peak resident set size of the process, or 0 if the
+	// platform does not report it
+	MaxRSSBytes int64 `json:"maxRssBytes,omitempty"`
+}
+
+// NewResourceUsage returns the resource usage of an exited process that ran for
+// wallTime. It returns nil if state is nil, e.g. when the process never started.
+func NewResourceUsage(state *os.ProcessState, wallTime time.Duration) *ResourceUsage {
+	if state == nil {
+		return nil
+	}
+
+	return &ResourceUsage{
+		WallTimeMs:  wallTime.Milliseconds(),
+		UserCPUMs:   state.UserTime().Milliseconds(),
+		SystemCPUMs: state.SystemTime().Milliseconds(),
+		MaxRSSBytes: maxRSSBytes(state),
+	}
+}
+
+// CPUTimeMs returns the total user and system CPU time.
+func (u *ResourceUsage) CPUTimeMs() int64 {
+	if u == nil {
+		return 0
+	}
+	return u.UserCPUMs + u.SystemCPUMs
+}
+
+// Add accumulates other into u. Times are summed and MaxRSSBytes keeps the peak.
+func (u *ResourceUsage) Add(other *ResourceUsage) {
+	if u == nil || other == nil {
+		return
+	}
+	u.WallTimeMs += other.WallTimeMs
+	u.UserCPUMs += other.UserCPUMs
+	u.SystemCPUMs += other.SystemCPUMs
+	u.MaxRSSBytes = max(u.MaxRSSBytes, other.MaxRSSBytes)
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package util
+
+import "os"
+
+func maxRSSBytes(_ *os.ProcessState) int64 {
+	return 0
+}
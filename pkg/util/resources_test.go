@@ -0,0 +1,42 @@
+package util
+
+import (
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewResourceUsage(t *testing.T) {
+	assert.Nil(t, NewResourceUsage(nil, time.Second))
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	cmd := exec.Command("/bin/sh", "-c", "true")
+	require.NoError(t, cmd.Run())
+
+	usage := NewResourceUsage(cmd.ProcessState, 1500*time.Millisecond)
+	require.NotNil(t, usage)
+	assert.Equal(t, int64(1500), usage.WallTimeMs)
+	assert.GreaterOrEqual(t, usage.UserCPUMs, int64(0))
+	assert.GreaterOrEqual(t, usage.SystemCPUMs, int64(0))
+	assert.Positive(t, usage.MaxRSSBytes)
+}
+
+func TestResourceUsageAdd(t *testing.T) {
+	usage := &ResourceUsage{WallTimeMs: 100, UserCPUMs: 10, SystemCPUMs: 5, MaxRSSBytes: 2048}
+	usage.Add(&ResourceUsage{WallTimeMs: 50, UserCPUMs: 20, SystemCPUMs: 1, MaxRSSBytes: 1024})
+	usage.Add(nil)
+
+	assert.Equal(t, &ResourceUsage{WallTimeMs: 150, UserCPUMs: 30, SystemCPUMs: 6, MaxRSSBytes: 2048}, usage)
+	assert.Equal(t, int64(36), usage.CPUTimeMs())
+
+	var nilUsage *ResourceUsage
+	nilUsage.Add(usage)
+	assert.Zero(t, nilUsage.CPUTimeMs())
+}
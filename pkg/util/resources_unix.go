@@ -0,0 +1,22 @@
+//go:build unix
+
+package util
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+)
+
+func maxRSSBytes(state *os.ProcessState) int64 {
+	rusage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok || rusage == nil {
+		return 0
+	}
+
+	// ru_maxrss is reported in bytes on macOS and in kilobytes elsewhere
+	if runtime.GOOS == "darwin" || runtime.GOOS == "ios" {
+		return int64(rusage.Maxrss)
+	}
+	return int64(rusage.Maxrss) * 1024
+}
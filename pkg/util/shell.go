@@ -0,0 +1,288 @@
+package util
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ShellKind identifies the command line syntax of a shell.
+type ShellKind string
+
+const (
+	ShellPosix      ShellKind = "posix"
+	ShellPowerShell ShellKind = "powershell"
+	ShellCmd        ShellKind = "cmd"
+)
+
+// Shell runs command strings and scripts. Use DefaultShell to get the shell for
+// the current platform.
+type Shell struct {
+	Path string
+	Kind ShellKind
+}
+
+// NewShell returns a shell for the executable at path, detecting its kind from
+// the executable name. Unknown shells are assumed to be POSIX compatible.
+func NewShell(path string) *Shell {
+	name := strings.ToLower(filepath.Base(strings.ReplaceAll(path, `\`, "/")))
+	name = strings.TrimSuffix(name, ".exe")
+
+	kind := ShellPosix
+	switch name {
+	case "pwsh", "powershell":
+		kind = ShellPowerShell
+	case "cmd":
+		kind = ShellCmd
+	}
+
+	return &Shell{Path: path, Kind: kind}
+}
+
+// DefaultShell returns the shell from $SHELL. Without $SHELL it defaults to
+// /usr/bin/bash, or on Windows to pwsh, powershell or %ComSpec%, whichever is
+// found first.
+func DefaultShell() *Shell {
+	return defaultShell(runtime.GOOS, os.LookupEnv, exec.LookPath)
+}
+
+func defaultShell(goos string, lookupEnv func(string) (string, bool), lookPath func(string) (string, error)) *Shell {
+	if shell, ok := lookupEnv("SHELL"); ok && shell != "" {
+		return NewShell(shell)
+	}
+
+	if goos != "windows" {
+		return NewShell("/usr/bin/bash")
+	}
+
+	if path, ok := findPowerShell(lookPath); ok {
+		return NewShell(path)
+	}
+	if comspec, ok := lookupEnv("ComSpec"); ok && comspec != "" {
+		return NewShell(comspec)
+	}
+	return NewShell("cmd.exe")
+}
+
+// findPowerShell returns the path of pwsh, or of Windows PowerShell if pwsh is
+// not installed.
+func findPowerShell(lookPath func(string) (string, error)) (string, bool) {
+	for _, name := range []string{"pwsh", "powershell"} {
+		if path, err := lookPath(name); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// commandArgs returns the arguments that make the shell run command.
+func (s *Shell) commandArgs(command string) []string {
+	switch s.Kind {
+	case ShellPowerShell:
+		return []string{"-NoProfile", "-NonInteractive", "-Command", command}
+	case ShellCmd:
+		return []string{"/C", command}
+	default:
+		return []string{"-c", command}
+	}
+}
+
+// Command returns a command that runs the command string with the shell.
+func (s *Shell) Command(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, s.Path, s.commandArgs(command)...)
+}
+
+// Quote quotes arg as a single argument in the command line syntax of the
+// shell. Arguments of only letters, digits and common punctuation are left as
+// they are.
+func (s *Shell) Quote(arg string) string {
+	if arg != "" && strings.Trim(arg, safeShellChars) == "" {
+		return arg
+	}
+
+	switch s.Kind {
+	case ShellPowerShell:
+		return "'" + strings.ReplaceAll(arg, "'", "''") + "'"
+	case ShellCmd:
+		return `"` + strings.ReplaceAll(arg, `"`, `""`) + `"`
+	default:
+		return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+}
+
+// QuoteArgs quotes each of args with Quote and joins them with spaces.
+func (s *Shell) QuoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = s.Quote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// safeShellChars are the characters that no shell gives a special meaning
+// in an argument
+const safeShellChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-+=./:,@"
+
+// ScriptExt returns the file extension the shell expects for script files.
+func (s *Shell) ScriptExt() string {
+	switch s.Kind {
+	case ShellPowerShell:
+		return ".ps1"
+	case ShellCmd:
+		return ".cmd"
+	default:
+		return ".sh"
+	}
+}
+
+// InlineScriptCommand returns a command that runs script in dir. Scripts with a
+// shebang, and all scripts for shells that cannot read a script from stdin, are
+// written to a temp file in dir so that relative paths keep working. The temp
+// file is removed once ctx is done.
+func InlineScriptCommand(ctx context.Context, dir, script string) (*exec.Cmd, error) {
+	shell := DefaultShell()
+
+	hasShebang := strings.HasPrefix(strings.TrimSpace(script), "#!")
+	if !hasShebang && shell.Kind == ShellPosix {
+		cmd := exec.CommandContext(ctx, shell.Path)
+		cmd.Stdin = strings.NewReader(script)
+		cmd.Dir = dir
+		return cmd, nil
+	}
+
+	ext := shell.ScriptExt()
+	if hasShebang {
+		ext = ".sh"
+	}
+
+	tmpDir := dir
+	if tmpDir == "" {
+		tmpDir = "."
+	}
+	tmpFile, err := os.CreateTemp(tmpDir, ".mcpchecker-step-*"+ext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp script file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+
+	if _, err := tmpFile.WriteString(script); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to write temp script: %w", err)
+	}
+	tmpFile.Close()
+
+	var cmd *exec.Cmd
+	if hasShebang {
+		cmd, err = ScriptFileCommand(ctx, tmpPath)
+		if err != nil {
+			os.Remove(tmpPath)
+			return nil, err
+		}
+	} else {
+		cmd = exec.CommandContext(ctx, shell.Path, shell.scriptArgs(tmpPath)...)
+	}
+	cmd.Dir = dir
+
+	go func() {
+		<-ctx.Done()
+		os.Remove(tmpPath)
+	}()
+	return cmd, nil
+}
+
+// scriptArgs returns the arguments that make the shell run the script file at path.
+func (s *Shell) scriptArgs(path string) []string {
+	switch s.Kind {
+	case ShellPowerShell:
+		return []string{"-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File", path}
+	case ShellCmd:
+		return []string{"/C", path}
+	default:
+		return []string{path}
+	}
+}
+
+// ScriptFileCommand returns a command that runs the script file at path, with
+// its working directory set to the script's directory so relative paths work.
+// On Windows, where shebangs are not honored, the interpreter is chosen from the
+// file extension or the shebang line.
+func ScriptFileCommand(ctx context.Context, path string) (*exec.Cmd, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		name, args, err := windowsInterpreter(path, readShebang(path), exec.LookPath)
+		if err != nil {
+			return nil, err
+		}
+		cmd = exec.CommandContext(ctx, name, args...)
+	} else {
+		if err := ensureExecutable(path); err != nil {
+			return nil, err
+		}
+		cmd = exec.CommandContext(ctx, path)
+	}
+
+	cmd.Dir = filepath.Dir(path)
+	return cmd, nil
+}
+
+// windowsInterpreter returns the program and arguments used to run the script
+// at path on Windows, based on its extension or, failing that, its shebang line.
+// PowerShell scripts run with pwsh, or with Windows PowerShell if pwsh is not
+// installed.
+func windowsInterpreter(path, shebang string, lookPath func(string) (string, error)) (string, []string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ps1":
+		name, ok := findPowerShell(lookPath)
+		if !ok {
+			name = "powershell"
+		}
+		return name, NewShell(name).scriptArgs(path), nil
+	case ".cmd", ".bat":
+		return "cmd.exe", NewShell("cmd.exe").scriptArgs(path), nil
+	case ".exe", ".com":
+		return path, nil, nil
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(shebang, "#!"))
+	if len(fields) == 0 {
+		// Without a shebang, fall back to running the script with bash (e.g. Git Bash)
+		return "bash", []string{path}, nil
+	}
+
+	// "/usr/bin/env python3 -u" runs python3, "/bin/bash -e" runs bash
+	interpreter := filepath.Base(fields[0])
+	args := fields[1:]
+	if interpreter == "env" {
+		if len(args) > 0 && args[0] == "-S" {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			return "", nil, fmt.Errorf("invalid shebang in %s: %q", path, shebang)
+		}
+		interpreter, args = args[0], args[1:]
+	}
+
+	return interpreter, append(args, path), nil
+}
+
+// readShebang returns the first line of the file at path if it is a shebang line.
+func readShebang(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	line, _ := bufio.NewReader(f).ReadString('\n')
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "#!") {
+		return ""
+	}
+	return line
+}
@@ -0,0 +1,279 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewShell(t *testing.T) {
+	tests := map[string]struct {
+		path         string
+		expectedKind ShellKind
+		expectedArgs []string
+		expectedExt  string
+	}{
+		"bash": {
+			path:         "/usr/bin/bash",
+			expectedKind: ShellPosix,
+			expectedArgs: []string{"-c", "echo hi"},
+			expectedExt:  ".sh",
+		},
+		"zsh": {
+			path:         "/bin/zsh",
+			expectedKind: ShellPosix,
+			expectedArgs: []string{"-c", "echo hi"},
+			expectedExt:  ".sh",
+		},
+		"pwsh": {
+			path:         `C:\Program Files\PowerShell\7\pwsh.exe`,
+			expectedKind: ShellPowerShell,
+			expectedArgs: []string{"-NoProfile", "-NonInteractive", "-Command", "echo hi"},
+			expectedExt:  ".ps1",
+		},
+		"windows powershell": {
+			path:         "powershell",
+			expectedKind: ShellPowerShell,
+			expectedArgs: []string{"-NoProfile", "-NonInteractive", "-Command", "echo hi"},
+			expectedExt:  ".ps1",
+		},
+		"cmd": {
+			path:         `C:\Windows\System32\CMD.EXE`,
+			expectedKind: ShellCmd,
+			expectedArgs: []string{"/C", "echo hi"},
+			expectedExt:  ".cmd",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			shell := NewShell(tc.path)
+			assert.Equal(t, tc.expectedKind, shell.Kind)
+			assert.Equal(t, tc.expectedArgs, shell.commandArgs("echo hi"))
+			assert.Equal(t, tc.expectedExt, shell.ScriptExt())
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]struct {
+		arg        string
+		posix      string
+		powershell string
+		cmd        string
+	}{
+		"plain flag":   {arg: "--max-turns=5", posix: "--max-turns=5", powershell: "--max-turns=5", cmd: "--max-turns=5"},
+		"empty":        {arg: "", posix: "''", powershell: "''", cmd: `""`},
+		"spaces":       {arg: "a b", posix: "'a b'", powershell: "'a b'", cmd: `"a b"`},
+		"command":      {arg: "x; rm -rf ~", posix: "'x; rm -rf ~'", powershell: "'x; rm -rf ~'", cmd: `"x; rm -rf ~"`},
+		"substitution": {arg: "$(id)", posix: "'$(id)'", powershell: "'$(id)'", cmd: `"$(id)"`},
+		"single quote": {arg: "it's", posix: `'it'\''s'`, powershell: "'it''s'", cmd: `"it's"`},
+		"double quote": {arg: `say "hi"`, posix: `'say "hi"'`, powershell: `'say "hi"'`, cmd: `"say ""hi"""`},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.posix, NewShell("/bin/sh").Quote(tc.arg))
+			assert.Equal(t, tc.powershell, NewShell("pwsh").Quote(tc.arg))
+			assert.Equal(t, tc.cmd, NewShell("cmd.exe").Quote(tc.arg))
+		})
+	}
+
+	assert.Equal(t, "--verbose 'a b'", NewShell("/bin/sh").QuoteArgs([]string{"--verbose", "a b"}))
+}
+
+func TestShellQuoteRoundTrip(t *testing.T) {
+	shell := NewShell("/bin/sh")
+	args := []string{"plain", "with space", "it's", `"double"`, "$HOME", "`id`", "a\nb", ""}
+
+	out, err := shell.Command(context.Background(), `printf '%s\n' `+shell.QuoteArgs(args)).Output()
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join(args, "\n")+"\n", string(out))
+}
+
+func TestDefaultShell(t *testing.T) {
+	tests := map[string]struct {
+		goos         string
+		env          map[string]string
+		onPath       []string
+		expectedPath string
+	}{
+		"SHELL is used when set": {
+			goos:         "windows",
+			env:          map[string]string{"SHELL": "/usr/bin/zsh"},
+			onPath:       []string{"pwsh"},
+			expectedPath: "/usr/bin/zsh",
+		},
+		"linux default": {
+			goos:         "linux",
+			expectedPath: "/usr/bin/bash",
+		},
+		"windows prefers pwsh": {
+			goos:         "windows",
+			env:          map[string]string{"ComSpec": `C:\Windows\system32\cmd.exe`},
+			onPath:       []string{"pwsh", "powershell"},
+			expectedPath: "pwsh",
+		},
+		"windows falls back to powershell": {
+			goos:         "windows",
+			onPath:       []string{"powershell"},
+			expectedPath: "powershell",
+		},
+		"windows falls back to ComSpec": {
+			goos:         "windows",
+			env:          map[string]string{"ComSpec": `C:\Windows\system32\cmd.exe`},
+			expectedPath: `C:\Windows\system32\cmd.exe`,
+		},
+		"windows without ComSpec": {
+			goos:         "windows",
+			expectedPath: "cmd.exe",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			lookupEnv := func(key string) (string, bool) {
+				v, ok := tc.env[key]
+				return v, ok
+			}
+			lookPath := func(file string) (string, error) {
+				for _, p := range tc.onPath {
+					if p == file {
+						return p, nil
+					}
+				}
+				return "", errors.New("not found")
+			}
+
+			shell := defaultShell(tc.goos, lookupEnv, lookPath)
+			assert.Equal(t, tc.expectedPath, shell.Path)
+		})
+	}
+}
+
+func TestWindowsInterpreter(t *testing.T) {
+	tests := map[string]struct {
+		path         string
+		shebang      string
+		onPath       []string
+		expectedName string
+		expectedArgs []string
+		expectErr    bool
+	}{
+		"powershell script": {
+			path:         `C:\task\verify.ps1`,
+			onPath:       []string{"pwsh", "powershell"},
+			expectedName: "pwsh",
+			expectedArgs: []string{"-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File", `C:\task\verify.ps1`},
+		},
+		"powershell script without pwsh": {
+			path:         `C:\task\verify.ps1`,
+			onPath:       []string{"powershell"},
+			expectedName: "powershell",
+			expectedArgs: []string{"-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-File", `C:\task\verify.ps1`},
+		},
+		"batch script": {
+			path:         `C:\task\setup.BAT`,
+			expectedName: "cmd.exe",
+			expectedArgs: []string{"/C", `C:\task\setup.BAT`},
+		},
+		"env shebang": {
+			path:         `C:\task\verify.py`,
+			shebang:      "#!/usr/bin/env python3 -u",
+			expectedName: "python3",
+			expectedArgs: []string{"-u", `C:\task\verify.py`},
+		},
+		"absolute shebang": {
+			path:         `C:\task\verify.sh`,
+			shebang:      "#!/bin/bash -e",
+			expectedName: "bash",
+			expectedArgs: []string{"-e", `C:\task\verify.sh`},
+		},
+		"no shebang": {
+			path:         `C:\task\verify.sh`,
+			expectedName: "bash",
+			expectedArgs: []string{`C:\task\verify.sh`},
+		},
+		"env without interpreter": {
+			path:      `C:\task\verify.sh`,
+			shebang:   "#!/usr/bin/env",
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			lookPath := func(file string) (string, error) {
+				for _, p := range tc.onPath {
+					if p == file {
+						return p, nil
+					}
+				}
+				return "", errors.New("not found")
+			}
+
+			gotName, gotArgs, err := windowsInterpreter(tc.path, tc.shebang, lookPath)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedName, gotName)
+			assert.Equal(t, tc.expectedArgs, gotArgs)
+		})
+	}
+}
+
+func TestReadShebang(t *testing.T) {
+	dir := t.TempDir()
+
+	withShebang := filepath.Join(dir, "with.sh")
+	require.NoError(t, os.WriteFile(withShebang, []byte("#!/usr/bin/env python3\nprint('hi')\n"), 0644))
+	assert.Equal(t, "#!/usr/bin/env python3", readShebang(withShebang))
+
+	withoutShebang := filepath.Join(dir, "without.sh")
+	require.NoError(t, os.WriteFile(withoutShebang, []byte("echo hi\n"), 0644))
+	assert.Equal(t, "", readShebang(withoutShebang))
+
+	assert.Equal(t, "", readShebang(filepath.Join(dir, "missing.sh")))
+}
+
+func TestInlineScriptCommand(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data.txt"), []byte("from-file"), 0644))
+
+	tests := map[string]struct {
+		script   string
+		expected string
+	}{
+		"stdin script": {
+			script:   "cat data.txt",
+			expected: "from-file",
+		},
+		"shebang script": {
+			script:   "#!/bin/sh\ncat data.txt\n",
+			expected: "from-file",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			cmd, err := InlineScriptCommand(ctx, dir, tc.script)
+			require.NoError(t, err)
+
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(out))
+			assert.Equal(t, tc.expected, string(out))
+		})
+	}
+}
@@ -5,13 +5,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
-	"strings"
 )
 
 type Step struct {
-	Inline string `json:"inline"`
-	File   string `json:"file"`
+	Inline string `json:"inline,omitempty"`
+	File   string `json:"file,omitempty"`
 }
 
 func (s *Step) IsEmpty() bool {
@@ -45,48 +43,12 @@ func (s *Step) Run(ctx context.Context) (string, error) {
 // createInlineCommand executes inline scripts with shebang support.
 // Scripts with shebangs are written to temp files in the current directory to preserve relative paths.
 func (s *Step) createInlineCommand(ctx context.Context) (*exec.Cmd, error) {
-	if strings.HasPrefix(strings.TrimSpace(s.Inline), "#!") {
-		tmpFile, err := os.CreateTemp(".", ".mcpchecker-step-*.sh")
-		if err != nil {
-			return nil, fmt.Errorf("failed to create temp script file: %w", err)
-		}
-		tmpPath := tmpFile.Name()
-
-		if _, err := tmpFile.WriteString(s.Inline); err != nil {
-			tmpFile.Close()
-			os.Remove(tmpPath)
-			return nil, fmt.Errorf("failed to write temp script: %w", err)
-		}
-		tmpFile.Close()
-
-		if err := ensureExecutable(tmpPath); err != nil {
-			os.Remove(tmpPath)
-			return nil, err
-		}
-
-		cmd := exec.CommandContext(ctx, tmpPath)
-		go func() {
-			<-ctx.Done()
-			os.Remove(tmpPath)
-		}()
-		return cmd, nil
-	}
-
-	shell := GetShell()
-	cmd := exec.CommandContext(ctx, shell)
-	cmd.Stdin = strings.NewReader(s.Inline)
-	return cmd, nil
+	return InlineScriptCommand(ctx, "", s.Inline)
 }
 
 // createFileCommand executes a script file directly to respect its shebang.
 func (s *Step) createFileCommand(ctx context.Context) (*exec.Cmd, error) {
-	if err := ensureExecutable(s.File); err != nil {
-		return nil, err
-	}
-	cmd := exec.CommandContext(ctx, s.File)
-	// Set working directory to the script's directory so relative paths work
-	cmd.Dir = filepath.Dir(s.File)
-	return cmd, nil
+	return ScriptFileCommand(ctx, s.File)
 }
 
 func ensureExecutable(path string) error {
@@ -118,14 +80,3 @@ func (s *Step) GetValue() (string, error) {
 
 	return string(b), nil
 }
-
-// GetShell returns the shell to use for executing scripts.
-// It checks the SHELL environment variable and defaults to /usr/bin/bash if not set.
-func GetShell() string {
-	shell, ok := os.LookupEnv("SHELL")
-	if !ok {
-		shell = "/usr/bin/bash"
-	}
-
-	return shell
-}
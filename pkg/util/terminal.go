@@ -0,0 +1,10 @@
+package util
+
+import (
+	"errors"
+	"runtime"
+)
+
+// ErrTerminalUnsupported is returned by StartInTerminal on platforms without
+// pseudo-terminals.
+var ErrTerminalUnsupported = errors.New("pseudo-terminals are not supported on " + runtime.GOOS)
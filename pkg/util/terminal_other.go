@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package util
+
+import (
+	"os"
+	"os/exec"
+)
+
+// StartInTerminal is not supported on this platform, see terminal_unix.go.
+func StartInTerminal(cmd *exec.Cmd, columns, rows int) (*os.File, error) {
+	return nil, ErrTerminalUnsupported
+}
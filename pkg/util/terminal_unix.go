@@ -0,0 +1,45 @@
+//go:build linux || darwin
+
+package util
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// StartInTerminal starts cmd in a new session with a new pseudo-terminal of
+// columns by rows as its controlling terminal, stdin, stdout and stderr. The
+// returned file is the controller side of the terminal: reading it returns
+// what cmd writes to the terminal. The caller must close it once cmd exited.
+func StartInTerminal(cmd *exec.Cmd, columns, rows int) (*os.File, error) {
+	ptmx, tty, err := openPTY()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open a pseudo-terminal: %w", err)
+	}
+	defer tty.Close()
+
+	size := &unix.Winsize{Col: uint16(columns), Row: uint16(rows)}
+	if err := unix.IoctlSetWinsize(int(ptmx.Fd()), unix.TIOCSWINSZ, size); err != nil {
+		ptmx.Close()
+		return nil, fmt.Errorf("failed to set the size of the pseudo-terminal: %w", err)
+	}
+
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = tty, tty, tty
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	// The child's stdin, fd 0, becomes its controlling terminal
+	cmd.SysProcAttr.Setsid = true
+	cmd.SysProcAttr.Setctty = true
+	cmd.SysProcAttr.Ctty = 0
+
+	if err := cmd.Start(); err != nil {
+		ptmx.Close()
+		return nil, err
+	}
+	return ptmx, nil
+}